@@ -0,0 +1,120 @@
+package mcpagent
+
+import (
+	"strings"
+
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+// MaxTokensTaskHint describes what an LLM call is expected to produce, which
+// determines how large its output budget should be.
+type MaxTokensTaskHint string
+
+const (
+	// MaxTokensHintToolOrchestration is a turn where tools are offered and the
+	// model usually only emits tool calls and short reasoning.
+	MaxTokensHintToolOrchestration MaxTokensTaskHint = "tool_orchestration"
+	// MaxTokensHintFinalSynthesis is a turn expected to produce the final answer.
+	MaxTokensHintFinalSynthesis MaxTokensTaskHint = "final_synthesis"
+)
+
+// Defaults for AdaptiveMaxTokensConfig fields left at zero.
+const (
+	DefaultToolOrchestrationMaxTokens = 4096
+	DefaultFinalSynthesisMaxTokens    = 16384
+	DefaultResponseTokenReserve       = 1024
+	DefaultMinAdaptiveMaxTokens       = 256
+)
+
+// AdaptiveMaxTokensConfig configures per-call max output tokens.
+// Zero values fall back to the Default* constants above.
+type AdaptiveMaxTokensConfig struct {
+	ToolOrchestrationTokens int // Output cap for tool-orchestration turns
+	FinalSynthesisTokens    int // Output cap for final synthesis turns
+	ResponseReserve         int // Tokens of the context window always left unused as a safety margin
+	MinTokens               int // Floor so a nearly-full context still gets a usable budget
+}
+
+func (c AdaptiveMaxTokensConfig) withDefaults() AdaptiveMaxTokensConfig {
+	if c.ToolOrchestrationTokens <= 0 {
+		c.ToolOrchestrationTokens = DefaultToolOrchestrationMaxTokens
+	}
+	if c.FinalSynthesisTokens <= 0 {
+		c.FinalSynthesisTokens = DefaultFinalSynthesisMaxTokens
+	}
+	if c.ResponseReserve < 0 {
+		c.ResponseReserve = 0
+	} else if c.ResponseReserve == 0 {
+		c.ResponseReserve = DefaultResponseTokenReserve
+	}
+	if c.MinTokens <= 0 {
+		c.MinTokens = DefaultMinAdaptiveMaxTokens
+	}
+	return c
+}
+
+// computeAdaptiveMaxTokens returns the output budget for a call: the cap for
+// hint, limited by what is left of the context window after the input and
+// the reserve. contextWindow <= 0 means unknown, in which case only the cap
+// applies.
+func computeAdaptiveMaxTokens(cfg AdaptiveMaxTokensConfig, hint MaxTokensTaskHint, contextWindow, inputTokens int) int {
+	cfg = cfg.withDefaults()
+
+	budget := cfg.FinalSynthesisTokens
+	if hint == MaxTokensHintToolOrchestration {
+		budget = cfg.ToolOrchestrationTokens
+	}
+
+	if contextWindow > 0 {
+		if remaining := contextWindow - inputTokens - cfg.ResponseReserve; remaining < budget {
+			budget = remaining
+		}
+	}
+	if budget < cfg.MinTokens {
+		budget = cfg.MinTokens
+	}
+	return budget
+}
+
+// maxTokensHintForTurn picks the task hint for a conversation turn. Turns that
+// offer tools are orchestration turns; turns without tools can only answer.
+func maxTokensHintForTurn(toolsOffered bool) MaxTokensTaskHint {
+	if toolsOffered {
+		return MaxTokensHintToolOrchestration
+	}
+	return MaxTokensHintFinalSynthesis
+}
+
+// adaptiveMaxTokens returns the max output tokens for a call with messages,
+// or 0 when adaptive max tokens is disabled or not applicable to the provider.
+func (a *Agent) adaptiveMaxTokens(messages []llmtypes.MessageContent, hint MaxTokensTaskHint) int {
+	if a.AdaptiveMaxTokens == nil || isCodingCLIProvider(a.provider, a.ModelID) {
+		return 0
+	}
+
+	contextWindow := a.GetModelContextWindow()
+	a.tokenTrackingMutex.RLock()
+	inputTokens := a.currentContextWindowUsage
+	a.tokenTrackingMutex.RUnlock()
+
+	if a.toolOutputHandler != nil {
+		if estimate := a.toolOutputHandler.EstimateMessagesTokenCount(messages, a.ModelID); estimate > inputTokens {
+			inputTokens = estimate
+		}
+	}
+
+	return computeAdaptiveMaxTokens(*a.AdaptiveMaxTokens, hint, contextWindow, inputTokens)
+}
+
+// isTruncatedResponse reports whether the first choice stopped because it hit
+// the max tokens limit without emitting any tool calls.
+func isTruncatedResponse(resp *llmtypes.ContentResponse) bool {
+	if resp == nil || len(resp.Choices) == 0 || len(resp.Choices[0].ToolCalls) > 0 {
+		return false
+	}
+	switch strings.ToLower(resp.Choices[0].StopReason) {
+	case "length", "max_tokens", "max_output_tokens":
+		return true
+	}
+	return false
+}
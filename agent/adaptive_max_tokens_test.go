@@ -0,0 +1,95 @@
+package mcpagent
+
+import (
+	"context"
+	"testing"
+
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+	"github.com/manishiitg/mcpagent/observability"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+func TestComputeAdaptiveMaxTokens(t *testing.T) {
+	cfg := AdaptiveMaxTokensConfig{}
+	tests := []struct {
+		name          string
+		hint          MaxTokensTaskHint
+		contextWindow int
+		inputTokens   int
+		want          int
+	}{
+		{"orchestration cap", MaxTokensHintToolOrchestration, 200000, 10000, DefaultToolOrchestrationMaxTokens},
+		{"synthesis cap", MaxTokensHintFinalSynthesis, 200000, 10000, DefaultFinalSynthesisMaxTokens},
+		{"limited by remaining context", MaxTokensHintFinalSynthesis, 128000, 120000, 128000 - 120000 - DefaultResponseTokenReserve},
+		{"floor when context is full", MaxTokensHintFinalSynthesis, 128000, 130000, DefaultMinAdaptiveMaxTokens},
+		{"unknown context window", MaxTokensHintFinalSynthesis, 0, 500000, DefaultFinalSynthesisMaxTokens},
+	}
+	for _, tt := range tests {
+		if got := computeAdaptiveMaxTokens(cfg, tt.hint, tt.contextWindow, tt.inputTokens); got != tt.want {
+			t.Errorf("%s: got %d, want %d", tt.name, got, tt.want)
+		}
+	}
+
+	custom := AdaptiveMaxTokensConfig{ToolOrchestrationTokens: 1000, ResponseReserve: -1}
+	if got := computeAdaptiveMaxTokens(custom, MaxTokensHintToolOrchestration, 10500, 10000); got != 500 {
+		t.Errorf("custom config without reserve: got %d, want 500", got)
+	}
+}
+
+func TestIsTruncatedResponse(t *testing.T) {
+	truncated := &llmtypes.ContentResponse{Choices: []*llmtypes.ContentChoice{{Content: "partial", StopReason: "max_tokens"}}}
+	if !isTruncatedResponse(truncated) {
+		t.Error("max_tokens stop reason should be treated as truncated")
+	}
+
+	withTools := &llmtypes.ContentResponse{Choices: []*llmtypes.ContentChoice{{
+		StopReason: "length",
+		ToolCalls:  []llmtypes.ToolCall{{ID: "1"}},
+	}}}
+	if isTruncatedResponse(withTools) {
+		t.Error("responses with tool calls should not be retried")
+	}
+
+	done := &llmtypes.ContentResponse{Choices: []*llmtypes.ContentChoice{{Content: "done", StopReason: "end_turn"}}}
+	if isTruncatedResponse(done) {
+		t.Error("end_turn should not be treated as truncated")
+	}
+}
+
+// billedGenerateStage returns its responses in order with 100/50 tokens of usage each
+type billedGenerateStage struct {
+	responses []*llmtypes.ContentResponse
+	calls     int
+}
+
+func (s *billedGenerateStage) Generate(_ context.Context, _ *Agent, _ []llmtypes.MessageContent, _ []llmtypes.CallOption, _ int) (*llmtypes.ContentResponse, observability.UsageMetrics, error) {
+	resp := s.responses[s.calls]
+	s.calls++
+	resp.Usage = &llmtypes.Usage{InputTokens: 100, OutputTokens: 50, TotalTokens: 150}
+	return resp, observability.UsageMetrics{InputTokens: 100, OutputTokens: 50, TotalTokens: 150}, nil
+}
+
+func TestTruncatedResponseRetryCountsBothCalls(t *testing.T) {
+	generate := &billedGenerateStage{responses: []*llmtypes.ContentResponse{
+		{Choices: []*llmtypes.ContentChoice{{Content: "par", StopReason: "max_tokens"}}},
+		{Choices: []*llmtypes.ContentChoice{{Content: "partial answer, finished", StopReason: "end_turn"}}},
+	}}
+	a := &Agent{Logger: loggerv2.NewNoop(), LLM: &providerKeyCarrierModel{}, ModelID: "test-model", MaxTurns: 5}
+	a.Tools = []llmtypes.Tool{{Type: "function", Function: &llmtypes.FunctionDefinition{Name: "lookup"}}}
+	WithAdaptiveMaxTokens(AdaptiveMaxTokensConfig{})(a)
+	WithAskPipeline(AskPipeline{Generate: generate})(a)
+
+	answer, _, err := AskWithHistory(a, context.Background(), []llmtypes.MessageContent{
+		llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "answer at length"),
+	})
+	if err != nil {
+		t.Fatalf("AskWithHistory: %v", err)
+	}
+	if answer != "partial answer, finished" || generate.calls != 2 {
+		t.Fatalf("answer = %q after %d calls", answer, generate.calls)
+	}
+	prompt, completion, total, _, _, calls, _ := a.GetTokenUsage()
+	if prompt != 200 || completion != 100 || total != 300 || calls != 2 {
+		t.Errorf("usage = %d/%d/%d over %d calls, want 200/100/300 over 2", prompt, completion, total, calls)
+	}
+}
@@ -2,9 +2,12 @@ package mcpagent
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"os"
+	"regexp"
 	"runtime"
 	"strings"
 	"sync"
@@ -97,6 +100,41 @@ func WithTracer(tracer observability.Tracer) AgentOption {
 	}
 }
 
+// WithStreamReplay retains the last size streaming events so subscribers that
+// join a running conversation (e.g. a second dashboard watching the same
+// session) receive them first via SubscribeWithReplay and then go live.
+//
+// Parameters:
+//   - size: Number of events to retain per streaming tracer; <= 0 disables replay.
+//
+// Default: 0 (Disabled)
+func WithStreamReplay(size int) AgentOption {
+	return func(a *Agent) {
+		a.StreamReplaySize = size
+	}
+}
+
+// WithSampledTracer adds an observability tracer that only records a sample of
+// conversations.
+//
+// A sampling decision is rolled at the start of every conversation using the
+// config's rate (or the per-tenant override for the agent's user ID). The
+// decision is recorded on the ConversationStart event. Streaming subscribers
+// still receive every event; only the wrapped tracer is sampled.
+//
+// Parameters:
+//   - tracer: The observability tracer implementation (e.g., Langfuse).
+//   - config: Sampling rate, always-sample-on-error and per-tenant overrides.
+//
+// Default: No tracers. Tracers added via WithTracer record every conversation.
+func WithSampledTracer(tracer observability.Tracer, config observability.SamplingConfig) AgentOption {
+	return func(a *Agent) {
+		if tracer != nil {
+			a.Tracers = append(a.Tracers, NewStreamingTracer(observability.NewSamplingTracer(tracer, config), 100))
+		}
+	}
+}
+
 // WithTraceID sets a specific Trace ID for the agent session.
 //
 // Useful for correlating agent activities with external systems or requests
@@ -331,6 +369,73 @@ func WithCleanupToolOutputOnSessionEnd(enabled bool) AgentOption {
 	}
 }
 
+// WithAdaptiveMaxTokens computes max output tokens per LLM call instead of
+// relying on the provider default.
+//
+// Each call's budget is the cap for its task type (small for turns that offer
+// tools, large for final synthesis turns), limited by the context window left
+// after the input and the response reserve. If an orchestration turn is cut
+// off while writing a final answer, it is retried once with the synthesis
+// budget. Ignored for coding-agent CLI providers.
+//
+// Parameters:
+//   - config: Caps and reserve; zero fields use the Default* constants.
+//
+// Default: nil (Disabled, provider default max tokens)
+func WithAdaptiveMaxTokens(config AdaptiveMaxTokensConfig) AgentOption {
+	return func(a *Agent) {
+		a.AdaptiveMaxTokens = &config
+	}
+}
+
+// WithAutosave checkpoints the conversation to store every everyTurns turns so
+// it can be recovered after a crash.
+//
+// A checkpoint holds the full message history at the end of a turn. It is
+// deleted when the conversation completes and kept (with the error) when it
+// fails. After a restart, use RecoverConversations to list unfinished
+// conversations and Agent.ResumeConversation to continue one.
+//
+// Parameters:
+//   - store: Where checkpoints are kept (e.g. NewFileConversationStore).
+//   - everyTurns: Checkpoint interval; values <= 0 checkpoint every turn.
+//
+// Default: nil (Disabled)
+func WithAutosave(store ConversationStore, everyTurns int) AgentOption {
+	return func(a *Agent) {
+		a.AutosaveStore = store
+		a.AutosaveEveryTurns = everyTurns
+	}
+}
+
+// WithCheckpointOwner records owner, e.g. the ID of the client a server
+// created the agent for, in the agent's conversation checkpoints so that
+// servers can show recovered conversations only to their owner.
+//
+// Default: "" (No owner)
+func WithCheckpointOwner(owner string) AgentOption {
+	return func(a *Agent) {
+		a.CheckpointOwner = owner
+	}
+}
+
+// WithToolImages passes images returned by tools (e.g. screenshots) to the
+// model instead of stringifying them.
+//
+// Images are downscaled to the configured limits. Vision-capable models
+// receive them as image parts; for other models each image is replaced by a
+// description from config.VisionModel (or a placeholder when it is nil).
+//
+// Parameters:
+//   - config: Mode, size limits and vision model; zero fields use the Default* constants.
+//
+// Default: nil (Disabled, images are stringified into the tool result)
+func WithToolImages(config ToolImageConfig) AgentOption {
+	return func(a *Agent) {
+		a.ToolImages = &config
+	}
+}
+
 // WithContextSummarization enables automatic conversation summarization.
 //
 // When the context window fills up (based on TokenThresholdPercent), the agent will
@@ -406,6 +511,23 @@ func WithParallelToolExecution(enabled bool) AgentOption {
 	}
 }
 
+// WithMaxParallelToolCalls enables parallel tool execution and bounds how many
+// tool calls of one LLM response run at once; the rest start as earlier calls
+// finish. Useful for browsing or research agents that issue many calls per
+// turn against rate-limited servers. n = 1 executes tool calls sequentially,
+// n <= 0 removes the bound.
+//
+// Default: 0 (no bound when parallel execution is enabled)
+func WithMaxParallelToolCalls(n int) AgentOption {
+	return func(a *Agent) {
+		if n < 0 {
+			n = 0
+		}
+		a.MaxParallelToolCalls = n
+		a.EnableParallelToolExecution = n != 1
+	}
+}
+
 // WithContextEditing enables dynamic context reduction.
 //
 // Unlike summarization (which compresses history), context editing targets specific
@@ -492,6 +614,19 @@ func WithDiscoverPrompt(enabled bool) AgentOption {
 	}
 }
 
+// WithServerInstructions enables/disables MCP server instructions in the system prompt.
+//
+// MCP servers can describe how to use them in their initialize response
+// (e.g. playwright's browsing guidance). If enabled, the agent adds a
+// per-server instructions section to the system prompt.
+//
+// Default: true
+func WithServerInstructions(enabled bool) AgentOption {
+	return func(a *Agent) {
+		a.IncludeServerInstructions = enabled
+	}
+}
+
 // WithLLMConfig sets the full LLM configuration (primary + fallbacks).
 // This is the canonical configuration for provider and model fallback routing.
 func WithLLMConfig(config AgentLLMConfiguration) AgentOption {
@@ -503,6 +638,23 @@ func WithLLMConfig(config AgentLLMConfiguration) AgentOption {
 	}
 }
 
+// WithFallbackLLMs sets LLM instances to try in order when the primary model
+// fails with a retryable error (429, 5xx, context overflow that summarization
+// did not prevent). They are tried after the Fallbacks of WithLLMConfig and
+// replace the provider default fallbacks. Each switch emits fallback_attempt
+// events, and the ConversationEnd event records the model that answered.
+//
+// Example:
+//
+//	mcpagent.WithFallbackLLMs(gpt41, geminiFlash)
+//
+// Default: provider default fallbacks
+func WithFallbackLLMs(models ...llmtypes.Model) AgentOption {
+	return func(a *Agent) {
+		a.fallbackLLMs = append(a.fallbackLLMs, models...)
+	}
+}
+
 // WithSelectedTools restricts the agent to a specific subset of tools.
 //
 // Parameters:
@@ -709,16 +861,21 @@ func WithAPIConfig(baseURL, token string) AgentOption {
 	}
 }
 
-// WithUserID sets the user ID for per-user OAuth token isolation.
+// WithUserID scopes everything the agent persists to a single end-user.
 //
-// When set, OAuth tokens for MCP servers are stored at user-specific paths:
-// ~/.config/mcpagent/tokens/{userID}/{serverName}.json
+// When set, per-user data is keyed by the user ID:
+//   - OAuth tokens: ~/.config/mcpagent/tokens/{userID}/{serverName}.json
+//   - Offloaded tool outputs: tool_output_folder/users/{userID}/{sessionID}/
+//   - Stored conversations and sessions: IDs prefixed "{userID}.", resumable only by the same user
+//   - Cached tool results (see WithToolResultCache)
 //
-// This enables multi-user deployments where each user's OAuth credentials
-// are isolated from other users.
+// This enables multi-user deployments on one agent pool where each user's
+// credentials, history and tool outputs are isolated from other users. Use
+// DeleteUserData to erase a user's data. The ID must be usable as a single
+// path segment (no "/" or "\"); NewAgent returns an error otherwise.
 //
 // When empty (default): OAuth tokens use the path from MCP server configuration
-// (typically a shared default path).
+// (typically a shared default path) and tool outputs are shared.
 func WithUserID(userID string) AgentOption {
 	return func(a *Agent) {
 		a.UserID = userID
@@ -766,12 +923,27 @@ type Agent struct {
 	selectedServers []string      // Selected servers list for "all tools" mode determination
 	toolFilter      *ToolFilter   // Unified tool filter for consistent filtering
 
+	// Per-tool timeout overrides by tool name (see tool_timeouts.go)
+	perToolTimeouts map[string]time.Duration
+
 	// Enhanced tracking info
 	systemPrompt string
 	TraceID      observability.TraceID
 	configPath   string // Path to MCP config file for on-demand connections
 	serverName   string // Server name(s) to connect to (default: AllServers)
 
+	// MCP server configuration the agent connected with, by server name, and
+	// hot-reload state (see config_reload.go)
+	serverConfigs map[string]mcpclient.MCPServerConfig
+	watchConfig   bool
+	configWatcher *configWatcher
+	reloadMu      sync.Mutex
+
+	// Conversations in progress, and a reload waiting for them to end (see config_reload.go)
+	conversationMu      sync.Mutex
+	activeConversations int
+	pendingReload       *reloadedState
+
 	// cached list of server names (for metadata convenience)
 	servers []string
 
@@ -865,6 +1037,9 @@ type Agent struct {
 	// Context offloading: handles offloading large tool outputs to filesystem
 	toolOutputHandler *ToolOutputHandler
 
+	// Token counting for context management (nil = TokenizerForModel, see tokenizer.go)
+	tokenizer Tokenizer
+
 	// Context offloading configuration: enables virtual tools for accessing offloaded outputs
 	EnableContextOffloading bool
 
@@ -877,6 +1052,159 @@ type Agent struct {
 	cleanupTicker                 *time.Ticker  // Ticker for periodic cleanup of old tool output files
 	cleanupDone                   chan bool     // Channel to signal cleanup routine to stop
 
+	// Retention policy for tool outputs and workspaces (see retention.go); nil = ToolOutputRetentionPeriod cleanup
+	Retention *RetentionPolicy
+	janitor   *StorageJanitor
+
+	// Adaptive max output tokens (see adaptive_max_tokens.go); nil = provider default
+	AdaptiveMaxTokens *AdaptiveMaxTokensConfig
+
+	// Output controls (see reasoning.go); zero values = provider default
+	MaxOutputTokens int    // Cap on output tokens per LLM call
+	ReasoningEffort string // Reasoning effort level ("minimal", "low", "medium", "high")
+
+	// Conversation autosave (see autosave.go); nil store = disabled
+	AutosaveStore      ConversationStore
+	AutosaveEveryTurns int    // Checkpoint interval in turns (<= 0 = every turn)
+	CheckpointOwner    string // Recorded in checkpoints (see WithCheckpointOwner)
+	autosaveID         string // ID of the resumed record; set by ResumeConversation and ResumeSession
+	checkpointMu       sync.Mutex
+	checkpointSeq      int    // Conversations given a trace-based checkpoint ID so far
+	lastCheckpointID   string // Checkpoint ID of the latest conversation
+
+	// Persistent conversation sessions (see session_store.go); nil store = disabled
+	SessionStore     ConversationStore
+	storedSessionID  string    // Set on first save or by ResumeSession
+	sessionCreatedAt time.Time // Creation time of the stored session
+	sessionSummaries []string  // Context summaries produced in the session
+	sessionStateMu   sync.Mutex
+
+	// Tool image handling (see tool_images.go); nil = images are stringified
+	ToolImages *ToolImageConfig
+
+	// Streaming events retained for late subscribers (see streaming_tracer.go); 0 = no replay
+	StreamReplaySize int
+
+	// History of the last completed conversation, kept with
+	// WithHistoryRetention for forks, compaction and spilling under pressure,
+	// with its estimated size (see memory.go)
+	historyRetention     bool
+	retainedHistory      []llmtypes.MessageContent
+	retainedHistoryBytes int64
+	retainedHistoryMu    sync.Mutex
+
+	// Per-tool permissions for virtual and custom tools (see tool_permissions.go); nil = unrestricted
+	ToolPermissions map[string]ToolPermission
+
+	// Per-tool argument size limits, keyed by tool name or "*" (see tool_arg_limits.go); nil = unlimited
+	ToolArgLimits map[string]ToolArgLimit
+
+	// Skip checking MCP and custom tool arguments against the tool's input schema (see tool_arg_validation.go)
+	DisableToolArgValidation bool
+
+	// Run only read tools; write tool calls get a simulated result (see dry_run.go)
+	DryRun bool
+
+	// MCP tool annotations by server and tool, listed on first use (see tool_annotations.go)
+	toolAnnotationsMu sync.Mutex
+	toolAnnotations   map[string]map[string]mcp.ToolAnnotation
+
+	// Offer the update_plan tool and emit plan events (see plan.go)
+	EnablePlanTracking bool
+	plan               []events.PlanStep
+	planRevision       int
+	planMu             sync.Mutex
+
+	// Context-window size for the model, overriding the registry (see model_context.go); 0 = resolve
+	ContextWindowOverride int
+
+	// Prices of the model, overriding the pricing table (see model_pricing.go); nil = resolve
+	ModelPricingOverride *ModelPricing
+	// Conversations abort once the cumulative cost crosses this (see model_pricing.go); 0 = no limit
+	BudgetLimitUSD float64
+
+	// Replaced Ask pipeline stages (see pipeline.go); nil stages use the defaults
+	pipeline AskPipeline
+
+	// Incrementally maintained code-execution tool structure (see tool_structure.go); nil = not built yet
+	toolStructure   *toolStructureIndex
+	toolStructureMu sync.Mutex
+
+	// Terminology enforced in final answers (see glossary.go); nil = no glossary
+	Glossary        map[string]string
+	glossaryMatcher *regexp.Regexp
+	glossaryMu      sync.Mutex
+
+	// Event webhooks (see webhook.go); WebhookDeadLetterFile "" = DefaultWebhookDeadLetterFile
+	webhooks              []*webhookSink
+	WebhookDeadLetterFile string
+
+	// Opt-in usage reports (see telemetry.go); nil = disabled
+	telemetry *telemetryReporter
+
+	// Diagnostic bundles of failed conversations (see postmortem.go); nil = disabled
+	postMortem *postMortemRecorder
+
+	// Failure injection for resilience testing (see chaos.go); nil = disabled
+	chaos *chaosMonkey
+
+	// Session recording or replay (see replay.go); nil = disabled
+	recorder *sessionRecorder
+
+	// Embedding-based selection of the MCP servers offered (see smart_routing.go); nil = disabled
+	smartRouter *smartRouter
+
+	// Cache of MCP tool results, possibly shared with other agents (see tool_result_cache.go); nil = disabled
+	toolResultCache *ToolResultCache
+
+	// Preset the agent was created from (see preset.go); "" = none
+	PresetName      string
+	OutputSchema    string // JSON schema of final answers, added to the system prompt
+	presetTemplate  string
+	presetVariables map[string]string
+
+	// Experiment the agent is enrolled in and its assigned variant (see experiments.go); "" = none
+	experimentName    string
+	experimentVariant string
+
+	// Final answers must satisfy this contract (see answer_contract.go); nil = none
+	answerContract *answerContract
+
+	// Final answers are checked by a validator with repair re-asks (see output_guard.go); nil = disabled
+	outputGuard *outputGuard
+
+	// Repeated identical tool results are replaced by references (see tool_result_dedup.go); nil = disabled
+	ToolResultDeduplication *ToolResultDeduplicationConfig
+	dedupStats              ToolResultDeduplicationStats // guarded by tokenTrackingMutex
+
+	// Raw provider request/response logging (see raw_llm_log.go); nil = disabled
+	rawLLMLog *rawLLMLogger
+
+	// Final answers are checked against tool evidence (see grounding.go); nil = disabled
+	Grounding *GroundingConfig
+
+	// Tool usage analytics aggregated from published events (see tool_stats.go)
+	toolStats toolStatsCollector
+
+	// Long-term memory across conversations (see long_term_memory.go); nil = disabled
+	memory       Memory
+	memoryTopK   int
+	memoryWrites sync.WaitGroup
+
+	// Minimum and adaptive spacing of LLM calls (see turn_pacing.go); nil = disabled
+	pacer *turnPacer
+
+	// Final answers citing offloaded outputs get a references section (see output_references.go)
+	outputReferences  bool
+	outputURLResolver OutputURLResolver
+
+	// Shared MCP discovery and compact spec entries for code execution mode (see code_exec_snapshot.go)
+	codeExecSnapshotEnabled bool
+	codeExecSnapshotDir     string
+	codeExecSnapshot        *CodeExecutionSnapshot
+	codeExecSnapshotHash    string   // config hash to save a snapshot under when none was found
+	codeExecSnapshotServers []string // servers that config hash covers
+
 	// Context summarization configuration (see context_summarization.go)
 	EnableContextSummarization     bool    // Enable context summarization feature
 	SummaryKeepLastMessages        int     // Number of recent messages to keep when summarizing (0 = use default)
@@ -886,6 +1214,26 @@ type Agent struct {
 	FixedTokenThreshold            int     // Fixed token threshold to trigger summarization (e.g., 200000 = 200k tokens)
 	SummarizationCooldownTurns     int     // Number of turns to wait after summarization before allowing another (0 = use default: 3)
 	lastSummarizationTurn          int     // Track when last summarization occurred (turn number)
+	// How old messages are condensed (nil = FullSummarization, see summarization_strategy.go)
+	summarizationStrategy SummarizationStrategy
+	// Calls in progress, so SummarizeNow can reach them (see compact_context.go)
+	activeCalls   map[*callState]struct{}
+	activeCallsMu sync.Mutex
+
+	// MCP sampling (nil = refuse sampling requests, see mcp_sampling.go)
+	mcpSampling *SamplingConfig
+
+	// Prompt-injection guard for tool results (nil = disabled, see tool_output_guard.go)
+	toolOutputGuard *ToolOutputGuardConfig
+
+	// Storage for offloaded tool outputs (nil = local disk, see output_storage.go)
+	toolOutputStorage OutputStorage
+
+	// Fallback LLM instances tried in order after the configured models (see WithFallbackLLMs)
+	fallbackLLMs []llmtypes.Model
+
+	// Hash-chained audit log of the decision trail (nil = disabled, see audit_log.go)
+	auditLog *auditLogger
 
 	// Context editing configuration (see context_editing.go)
 	EnableContextEditing        bool // Enable context editing (dynamic context reduction)
@@ -898,11 +1246,18 @@ type Agent struct {
 	// Results are collected in deterministic order matching the original tool call order.
 	// When disabled (default): tool calls execute sequentially as before.
 	EnableParallelToolExecution bool
+	// MaxParallelToolCalls bounds how many tool calls of one response run at
+	// once in parallel execution. 0 = no limit.
+	MaxParallelToolCalls int
 
 	// Mutex for concurrent access to Clients map during parallel tool execution
 	// Used by broken pipe recovery to safely read/write the Clients map
 	clientsMu sync.RWMutex
 
+	// Connection state subscriptions of Clients, by server (see mcp_connection_state.go)
+	connStateMu      sync.Mutex
+	connStateWatches map[string]func()
+
 	// Mutex for concurrent access to event hierarchy state during parallel tool execution
 	// Protects currentParentEventID and currentHierarchyLevel in EmitTypedEvent
 	eventMu sync.Mutex
@@ -930,6 +1285,12 @@ type Agent struct {
 	prompts   map[string][]mcp.Prompt
 	resources map[string][]mcp.Resource
 
+	// Resource templates discovered per server (see mcp_resources.go)
+	resourceTemplates map[string][]mcp.ResourceTemplate
+
+	// Usage instructions MCP servers sent on initialize, by server name
+	serverInstructions map[string]string
+
 	// Flag to track if a custom system prompt was provided
 	hasCustomSystemPrompt bool
 
@@ -943,6 +1304,17 @@ type Agent struct {
 	// branches (virtual tools, custom tools, MCP tools via session/codeexec/mcpcache).
 	toolArgTransformers map[string]func(args map[string]interface{})
 
+	// Hooks wrapping every tool execution, outermost first (see tool_middleware.go)
+	toolMiddleware []ToolMiddleware
+
+	// Failed calls after which a tool is disabled for the rest of the
+	// conversation (see tool_disablement.go); 0 = never
+	ToolFailureLimit int
+	toolFailures     toolFailureTracker
+
+	// In-process calculator, date, unit, random and base64 tools (see utility_tools.go)
+	EnableBuiltinUtilityTools bool
+
 	// Custom logger (optional) - uses v2.Logger interface
 	Logger loggerv2.Logger
 
@@ -974,8 +1346,10 @@ type Agent struct {
 	attachedSkills []*llmtypes.Skill
 
 	// Hierarchy tracking fields for event tree structure
-	currentParentEventID  string // Track current parent event ID
-	currentHierarchyLevel int    // Track current hierarchy level (0=root, 1=child, etc.)
+	currentParentEventID  string        // Track current parent event ID
+	currentHierarchyLevel int           // Track current hierarchy level (0=root, 1=child, etc.)
+	subAgent              *subAgentLink // Set on agents created by SpawnSubAgent
+	forkID                string        // Set on agents created by Fork
 
 	// Resource discovery configuration
 	DiscoverResource bool // If true, include resource details in system prompt (default: true)
@@ -983,6 +1357,12 @@ type Agent struct {
 	// Prompt discovery configuration
 	DiscoverPrompt bool // If true, include prompt details in system prompt (default: true)
 
+	// Server instructions configuration
+	IncludeServerInstructions bool // If true, include MCP server usage instructions in system prompt (default: true)
+
+	// Resource template discovery configuration (see mcp_resources.go)
+	DiscoverResourceTemplates bool // If true, list MCP resource templates when the agent is created (default: true)
+
 	// Code execution mode configuration
 	// When enabled: Custom tools + get_api_spec virtual tool are exposed to the LLM
 	// MCP server tools are accessed via HTTP API (documented in OpenAPI specs from get_api_spec)
@@ -1045,11 +1425,16 @@ type Agent struct {
 	SuppressGenerationStreamingEvents bool                             // Suppress generation streaming events (default: false)
 	StreamingCallback                 func(chunk llmtypes.StreamChunk) // Optional callback for streaming chunks
 
-	// Folder guard paths for code execution mode
-	// These paths are validated at AST level before code execution
+	// Folder guard paths. In code execution mode they are validated at AST level
+	// before code execution; in normal mode filesystem MCP tool calls are checked
+	// before they run (see folder_guard.go)
 	FolderGuardReadPaths  []string // Paths allowed for read operations
 	FolderGuardWritePaths []string // Paths allowed for write operations
 
+	// Where ExecuteShellCommand runs commands (see code_execution_sandbox.go); "" = host
+	CodeExecutionSandbox string
+	DockerSandboxConfig  codeexec.DockerSandboxConfig
+
 	// API keys for providers (used for fallback LLM creation)
 	APIKeys *AgentAPIKeys
 
@@ -1079,7 +1464,7 @@ type Agent struct {
 	// all conversation phases (never reset) for accurate pricing and overall usage reporting.
 	// Context window is based on input tokens only, not output tokens.
 	currentContextWindowUsage int
-	modelContextWindow        int // Cached model context window size (0 = not cached yet)
+	modelContextWindow        int // Cached model context window size (0 = not cached yet; see model_context.go)
 
 	// LLM Configuration
 	LLMConfig AgentLLMConfiguration
@@ -1102,6 +1487,10 @@ type LLMModel struct {
 	// Model-specific options
 	Temperature *float64               `json:"temperature,omitempty"` // Override default temperature (0.0-1.0)
 	Options     map[string]interface{} `json:"options,omitempty"`     // Provider-specific options (reasoning_effort, thinking_level, etc.)
+
+	// Model is called as is instead of an instance initialized from Provider
+	// and ModelID (set for the models of WithFallbackLLMs)
+	Model llmtypes.Model `json:"-"`
 }
 
 // AgentLLMConfiguration holds the primary and fallback LLM configurations
@@ -1166,6 +1555,30 @@ func (a *Agent) GetToolToServer() map[string]string {
 	return a.toolToServer
 }
 
+// ToolsetHash returns a short hash of the tools currently offered to the LLM:
+// their order, names, descriptions and parameter schemas. Two conversations
+// with the same hash send byte-identical tool definitions, so a changing hash
+// explains prompt-cache misses. It is included in ConversationStart events.
+//
+// Tool order is deterministic: MCP tools grouped by server (alphabetical when
+// all servers are used), sorted by name within a server, followed by virtual
+// tools and then custom tools in registration order. In tool search mode the
+// tool search tools come first, followed by discovered tools sorted by name.
+func (a *Agent) ToolsetHash() string {
+	return toolsetHash(a.filteredTools)
+}
+
+func toolsetHash(tools []llmtypes.Tool) string {
+	h := sha256.New()
+	enc := json.NewEncoder(h)
+	for _, tool := range tools {
+		// Map keys in parameter schemas are sorted by encoding/json, so equal
+		// tools always encode identically
+		_ = enc.Encode(tool)
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
 // SetProvider sets the provider
 func (a *Agent) SetProvider(provider llm.Provider) {
 	a.provider = provider
@@ -1219,11 +1632,16 @@ func (a *Agent) SetToolOutputHandler(handler *ToolOutputHandler) {
 }
 
 // SetFolderGuardPaths sets the folder guard paths for code execution validation
+// and for filesystem MCP tool calls (read_file, write_file, ...) in normal mode.
+// While write paths are set, normal and tool search mode also get the file
+// edit tools apply_patch, insert_at_line and replace_between_markers.
 // readPaths: paths allowed for read operations (workspace package read functions)
 // writePaths: paths allowed for write operations (workspace package write functions)
 func (a *Agent) SetFolderGuardPaths(readPaths, writePaths []string) {
 	a.FolderGuardReadPaths = readPaths
 	a.FolderGuardWritePaths = writePaths
+	// File edit tools work inside the write paths (see workspace_edit_tools.go)
+	a.syncWorkspaceEditTools()
 	if a.Logger != nil {
 		a.Logger.Info("🔒 [CODE_EXECUTION] Folder guard paths set",
 			loggerv2.Any("read_paths", readPaths),
@@ -1365,6 +1783,12 @@ func NewAgent(ctx context.Context, llm llmtypes.Model, configPath string, option
 		// Initialize prompt discovery (default: true - include prompts in system prompt)
 		DiscoverPrompt: true,
 
+		// Initialize server instructions (default: true - include server usage instructions in system prompt)
+		IncludeServerInstructions: true,
+
+		// Initialize resource template discovery (default: true - list templates of connected servers)
+		DiscoverResourceTemplates: true,
+
 		// Initialize cache (default: false - caching enabled by default)
 		DisableCache: false,
 
@@ -1382,11 +1806,38 @@ func NewAgent(ctx context.Context, llm llmtypes.Model, configPath string, option
 		option(ag)
 	}
 
+	// Retain recent events for late subscribers regardless of option order
+	if ag.StreamReplaySize > 0 {
+		for _, tracer := range ag.Tracers {
+			if streamingTracer, ok := tracer.(StreamingTracer); ok {
+				streamingTracer.SetReplaySize(ag.StreamReplaySize)
+			}
+		}
+	}
+
+	// User IDs become path segments for per-user token and tool output storage
+	if ag.UserID != "" {
+		if err := validateUserID(ag.UserID); err != nil {
+			return nil, fmt.Errorf("invalid user ID: %w", err)
+		}
+	}
+
 	// If provider is not set, try to extract it from LLM
 	if ag.provider == "" {
 		ag.provider = extractProviderFromLLM(llm)
 	}
 
+	// Reasoning effort must map to a knob of the model (see reasoning.go)
+	if err := ag.validateOutputControls(); err != nil {
+		return nil, fmt.Errorf("invalid output controls: %w", err)
+	}
+
+	// Dry run is enforced in the tool middleware chain. Generated code and
+	// coding CLIs also change state outside tool calls (see dry_run.go)
+	if ag.DryRun && (ag.UseCodeExecutionMode || isCodingCLIProvider(ag.provider, ag.ModelID)) {
+		return nil, fmt.Errorf("dry-run mode is not supported with code execution mode or coding CLI providers: they change state outside tool calls")
+	}
+
 	// Extract API keys from LLM if available
 	// This allows users to pass keys only when creating the LLM
 	if ag.APIKeys == nil {
@@ -1446,6 +1897,7 @@ func NewAgent(ctx context.Context, llm llmtypes.Model, configPath string, option
 	var servers []string
 	var prompts map[string][]mcp.Prompt
 	var resources map[string][]mcp.Resource
+	var serverInstructions map[string]string
 	var systemPrompt string
 
 	// SessionID is mandatory for connection management via the session registry.
@@ -1456,9 +1908,22 @@ func NewAgent(ctx context.Context, llm llmtypes.Model, configPath string, option
 		logger.Warn("SessionID not set — defaulting to 'global' for shared connection management")
 	}
 
-	logger.Info("Using session-scoped connection management", loggerv2.String("session_id", ag.SessionID))
-	clients, toolToServer, allLLMTools, servers, prompts, resources, systemPrompt, err =
-		NewAgentConnectionWithSession(ctx, llm, serverName, configPath, ag.SessionID, string(ag.TraceID), ag.Tracers, logger, ag.DisableCache, ag.RuntimeOverrides, ag.UserID)
+	// Experiment variants are assigned from the final session ID
+	if err := ag.applyExperiment(); err != nil {
+		return nil, err
+	}
+	if err := ag.validateCodeExecutionSandbox(); err != nil {
+		return nil, err
+	}
+
+	// A code execution snapshot of this configuration replaces discovery (see code_exec_snapshot.go)
+	if snapshot := ag.findCodeExecutionSnapshot(config, serverName); snapshot != nil {
+		clients, toolToServer, allLLMTools, servers, prompts, resources, serverInstructions = snapshot.discovery()
+	} else {
+		logger.Info("Using session-scoped connection management", loggerv2.String("session_id", ag.SessionID))
+		clients, toolToServer, allLLMTools, servers, prompts, resources, serverInstructions, systemPrompt, err =
+			NewAgentConnectionWithSession(ctx, llm, serverName, configPath, ag.SessionID, string(ag.TraceID), ag.Tracers, logger, ag.DisableCache, ag.RuntimeOverrides, ag.UserID)
+	}
 
 	connectionDuration := time.Since(connectionStartTime)
 	if err != nil {
@@ -1480,21 +1945,33 @@ func NewAgent(ctx context.Context, llm llmtypes.Model, configPath string, option
 	// Virtual tools are enabled by default and handle file operations directly
 	toolOutputHandler.SetServerAvailable(true) // Always available with virtual tools
 
+	// Partition offloaded tool outputs by end-user so agents in a shared pool
+	// cannot read each other's files
+	if ag.UserID != "" {
+		toolOutputHandler.SetOutputFolder(UserToolOutputFolder(ag.UserID))
+	}
+
 	// Set session ID for organizing files by conversation
 	toolOutputHandler.SetSessionID(string(ag.TraceID))
 
 	// Set LLM for provider-aware token counting
 	toolOutputHandler.SetLLM(llm)
+	toolOutputHandler.Tokenizer = ag.tokenizer
+	toolOutputHandler.Storage = ag.toolOutputStorage
 
 	// Update the existing agent with connection data
 	ag.Clients = clients
+	ag.watchConnectionStates(clients)
 	ag.toolToServer = toolToServer
 	ag.systemPrompt = systemPrompt
 	ag.servers = servers
 	ag.toolOutputHandler = toolOutputHandler
 	ag.prompts = prompts
 	ag.resources = resources
+	ag.serverInstructions = serverInstructions
 	ag.configPath = configPath
+	ag.serverConfigs = config.MCPServers
+	ag.discoverResourceTemplates(ctx, config)
 
 	// Start periodic cleanup routine for tool output files
 	ag.startCleanupRoutine()
@@ -1585,6 +2062,7 @@ func NewAgent(ctx context.Context, llm llmtypes.Model, configPath string, option
 		logger.Debug("Code execution mode: tools available (virtual only, MCP + custom excluded)",
 			loggerv2.Int("tool_count", len(toolsToUse)),
 			loggerv2.Int("mcp_tool_defs_stored", len(ag.allMCPToolDefs)))
+		ag.loadCodeExecutionSnapshot()
 	} else if ag.UseToolSearchMode {
 		// Tool search mode: Store filtered tools as deferred, expose only search_tools
 		logger.Debug("Tool search mode enabled - storing tools as deferred (with filtering)")
@@ -1819,7 +2297,7 @@ func NewAgent(ctx context.Context, llm llmtypes.Model, configPath string, option
 		logger.Debug("Code execution mode: virtual tools after filtering",
 			loggerv2.Int("count", len(virtualTools)))
 	} else if ag.UseToolSearchMode {
-		// In tool search mode, only include search_tools and context offloading tools
+		// In tool search mode, only include search_tools, update_plan and context offloading tools
 		var filteredVirtualTools []llmtypes.Tool
 		for _, tool := range virtualTools {
 			if tool.Function != nil {
@@ -1830,10 +2308,10 @@ func NewAgent(ctx context.Context, llm llmtypes.Model, configPath string, option
 					continue
 				}
 
-				// Context offloading tools must be immediately available
-				isContextOffloadingTool := toolName == "search_large_output"
+				// Context offloading and compaction tools must be immediately available
+				isContextTool := toolName == "search_large_output" || toolName == "compact_context"
 
-				if toolName == "search_tools" || isContextOffloadingTool {
+				if toolName == "search_tools" || toolName == "update_plan" || isContextTool {
 					filteredVirtualTools = append(filteredVirtualTools, tool)
 				} else {
 					ag.allDeferredTools = append(ag.allDeferredTools, tool)
@@ -1902,6 +2380,7 @@ func NewAgent(ctx context.Context, llm llmtypes.Model, configPath string, option
 			loggerv2.Int("custom_tool_count", len(ag.customTools)),
 			loggerv2.String("agent_ptr", fmt.Sprintf("%p", ag)))
 	}
+	ag.registerCodeExecToolCallHook()
 
 	// In code execution mode, build tool index from agent internal state
 	var toolStructureJSON string
@@ -1929,6 +2408,7 @@ func NewAgent(ctx context.Context, llm llmtypes.Model, configPath string, option
 			}
 		}
 		ag.systemPrompt = prompt.BuildSystemPromptWithoutTools(ag.prompts, ag.resources, string(ag.AgentMode), ag.DiscoverResource, ag.DiscoverPrompt, ag.UseCodeExecutionMode, toolStructureJSON, preDiscoveredToolSpecs, ag.UseToolSearchMode, toolCategories, ag.Logger, ag.EnableParallelToolExecution)
+		ag.systemPrompt = ag.withServerInstructions(ag.systemPrompt, nil)
 	}
 
 	// Initialize the filtered-tool set used by the outgoing LLM call.
@@ -2138,6 +2618,18 @@ func NewAgent(ctx context.Context, llm llmtypes.Model, configPath string, option
 		}
 	}
 
+	if ag.EnableBuiltinUtilityTools {
+		if err := ag.registerBuiltinUtilityTools(); err != nil {
+			return nil, err
+		}
+	}
+
+	if ag.watchConfig {
+		if err := ag.startConfigWatcher(); err != nil {
+			return nil, err
+		}
+	}
+
 	// Agent initialization complete
 
 	return ag, nil
@@ -2264,47 +2756,44 @@ func (a *Agent) accumulateTokenUsage(ctx context.Context, usageMetrics events.Us
 
 	// Calculate costs for this turn
 	var inputCost, outputCost, reasoningCost, cacheCost float64
-	if a.LLM != nil {
-		metadata, err := a.LLM.GetModelMetadata(modelID)
-		if err == nil && metadata != nil {
-			// Cache context window if not already cached
-			if a.modelContextWindow == 0 {
-				a.modelContextWindow = metadata.ContextWindow
-			}
-
-			// Calculate input cost (excluding cached tokens which are charged separately)
-			// Input tokens = total prompt tokens - cached tokens (cached tokens are charged separately at a different rate)
-			inputTokens := usageMetrics.PromptTokens - cacheTokens
-			if inputTokens < 0 {
-				// Safety check: cache tokens should not exceed prompt tokens
-				// This could indicate a data inconsistency, but we'll clamp to 0 to prevent negative costs
-				inputTokens = 0
-			}
-			if inputTokens > 0 {
-				inputCost = calculateCostFromTokens(inputTokens, metadata.InputCostPer1MTokens)
-			}
-
-			// Calculate output cost
-			if usageMetrics.CompletionTokens > 0 {
-				outputCost = calculateCostFromTokens(usageMetrics.CompletionTokens, metadata.OutputCostPer1MTokens)
-			}
-
-			// Calculate reasoning cost
-			// If model has specific reasoning cost, use it; otherwise fallback to input token rate
-			if reasoningTokens > 0 {
-				if metadata.ReasoningCostPer1MTokens > 0 {
-					reasoningCost = calculateCostFromTokens(reasoningTokens, metadata.ReasoningCostPer1MTokens)
-				} else {
-					// Fallback to input token rate when reasoning cost is not specified
-					// Reasoning tokens are part of input processing, so charge at input rate
-					reasoningCost = calculateCostFromTokens(reasoningTokens, metadata.InputCostPer1MTokens)
-				}
+	// Cache context window if not already cached (see model_context.go)
+	if a.modelContextWindow == 0 {
+		a.modelContextWindow = a.resolveModelContextWindow()
+	}
+	// Prices come from the pricing table (see model_pricing.go)
+	if pricing, ok := a.resolveModelPricing(modelID); ok {
+		// Calculate input cost (excluding cached tokens which are charged separately)
+		// Input tokens = total prompt tokens - cached tokens (cached tokens are charged separately at a different rate)
+		inputTokens := usageMetrics.PromptTokens - cacheTokens
+		if inputTokens < 0 {
+			// Safety check: cache tokens should not exceed prompt tokens
+			// This could indicate a data inconsistency, but we'll clamp to 0 to prevent negative costs
+			inputTokens = 0
+		}
+		if inputTokens > 0 {
+			inputCost = calculateCostFromTokens(inputTokens, pricing.InputPer1M)
+		}
+
+		// Calculate output cost
+		if usageMetrics.CompletionTokens > 0 {
+			outputCost = calculateCostFromTokens(usageMetrics.CompletionTokens, pricing.OutputPer1M)
+		}
+
+		// Calculate reasoning cost
+		// If model has specific reasoning cost, use it; otherwise fallback to input token rate
+		if reasoningTokens > 0 {
+			if pricing.ReasoningPer1M > 0 {
+				reasoningCost = calculateCostFromTokens(reasoningTokens, pricing.ReasoningPer1M)
+			} else {
+				// Fallback to input token rate when reasoning cost is not specified
+				// Reasoning tokens are part of input processing, so charge at input rate
+				reasoningCost = calculateCostFromTokens(reasoningTokens, pricing.InputPer1M)
 			}
+		}
 
-			// Calculate cache cost (cached tokens are charged at a different rate)
-			if cacheTokens > 0 && metadata.CachedInputCostPer1MTokens > 0 {
-				cacheCost = calculateCostFromTokens(cacheTokens, metadata.CachedInputCostPer1MTokens)
-			}
+		// Calculate cache cost (cached tokens are charged at a different rate)
+		if cacheTokens > 0 && pricing.CachedInputPer1M > 0 {
+			cacheCost = calculateCostFromTokens(cacheTokens, pricing.CachedInputPer1M)
 		}
 	}
 
@@ -2376,11 +2865,12 @@ func (a *Agent) accumulateTokenUsage(ctx context.Context, usageMetrics events.Us
 //   - resp: The full content response object (optional, for detailed metrics).
 func (a *Agent) EndLLMGeneration(ctx context.Context, result string, turn int, toolCalls int, duration time.Duration, usageMetrics events.UsageMetrics, resp *llmtypes.ContentResponse) {
 	// Accumulate token usage (including cache tokens) - uses unified Usage field
+	costBefore := a.GetTotalCost()
 	a.accumulateTokenUsage(ctx, usageMetrics, resp, turn)
 
 	// Extract cache and reasoning tokens to include in UsageMetrics
 	// Use unified extraction from multi-llm-provider-go
-	cacheTokens, _, reasoningTokens := extractAllTokenTypes(resp)
+	cacheTokens, thoughtsTokens, reasoningTokens := extractAllTokenTypes(resp)
 
 	// Add cache and reasoning tokens to usage metrics
 	usageMetrics.CacheTokens = cacheTokens
@@ -2398,6 +2888,7 @@ func (a *Agent) EndLLMGeneration(ctx context.Context, result string, turn int, t
 	if a.SummarizeOnFixedTokenThreshold && a.FixedTokenThreshold > 0 {
 		fixedThresholdPercent = (float64(currentUsage) / float64(a.FixedTokenThreshold)) * 100.0
 	}
+	cumulativeCost := a.cumulativeTotalCost
 	a.tokenTrackingMutex.RUnlock()
 
 	// Emit LLM generation end event with complete token information
@@ -2415,6 +2906,21 @@ func (a *Agent) EndLLMGeneration(ctx context.Context, result string, turn int, t
 		llmEndEvent.Metadata["fixed_threshold_percent"] = fixedThresholdPercent
 		llmEndEvent.Metadata["fixed_threshold_tokens"] = a.FixedTokenThreshold
 	}
+	// Reasoning effort of the call and Gemini's reasoning usage (see reasoning.go)
+	if control, ok := a.reasoningControl(); ok {
+		llmEndEvent.Metadata["reasoning_effort"] = control.value
+		if control.budget > 0 {
+			llmEndEvent.Metadata["thinking_budget"] = control.budget
+		}
+	}
+	if thoughtsTokens > 0 {
+		llmEndEvent.Metadata["thoughts_tokens"] = thoughtsTokens
+	}
+	// Cost of this call and of the agent so far (see model_pricing.go)
+	if cumulativeCost > 0 {
+		llmEndEvent.Metadata["call_cost_usd"] = cumulativeCost - costBefore
+		llmEndEvent.Metadata["cumulative_cost_usd"] = cumulativeCost
+	}
 
 	// Propagate provider-specific metadata from GenerationInfo.Additional
 	// This captures CLI provider info like resolved model, duration, tool calls, etc.
@@ -2636,10 +3142,13 @@ func (a *Agent) EndAgentSession(ctx context.Context, conversationDuration time.D
 		a.cleanupAgentGeneratedDir()
 	}
 
+	// Apply the retention policy's session-end cleanup
+	a.cleanupSessionStorage(ctx)
+
 	// Cleanup tool output files
 	if a.toolOutputHandler != nil {
-		// Clean up old files if retention period is configured
-		if a.ToolOutputRetentionPeriod > 0 {
+		// Clean up old files if retention period is configured (superseded by a retention policy)
+		if a.ToolOutputRetentionPeriod > 0 && a.Retention == nil {
 			if err := a.toolOutputHandler.CleanupOldFiles(a.ToolOutputRetentionPeriod); err != nil {
 				if a.Logger != nil {
 					a.Logger.Warn("Failed to cleanup old tool output files", loggerv2.Error(err))
@@ -2686,6 +3195,12 @@ func (a *Agent) cleanupAgentGeneratedDir() {
 // It runs periodically (every hour by default) to clean up files older than the retention period.
 // This ensures cleanup happens even if sessions don't end properly or agents run for long periods.
 func (a *Agent) startCleanupRoutine() {
+	// A retention policy replaces the retention-period cleanup
+	if a.Retention != nil {
+		a.startRetentionJanitor()
+		return
+	}
+
 	// Only start if context offloading is enabled and retention period is set
 	if !a.EnableContextOffloading || a.toolOutputHandler == nil {
 		return
@@ -2703,12 +3218,14 @@ func (a *Agent) startCleanupRoutine() {
 	}
 
 	// Create ticker for periodic cleanup (default: every hour)
-	a.cleanupTicker = time.NewTicker(DefaultToolOutputCleanupInterval)
+	// The goroutine keeps its own reference: stopCleanupRoutine clears the field
+	ticker := time.NewTicker(DefaultToolOutputCleanupInterval)
+	a.cleanupTicker = ticker
 
 	go func() {
 		for {
 			select {
-			case <-a.cleanupTicker.C:
+			case <-ticker.C:
 				// Perform periodic cleanup
 				if a.toolOutputHandler != nil && retentionPeriod > 0 {
 					if err := a.toolOutputHandler.CleanupOldFiles(retentionPeriod); err != nil {
@@ -2732,6 +3249,9 @@ func (a *Agent) startCleanupRoutine() {
 // stopCleanupRoutine stops the background cleanup routine.
 // This should be called when the agent is closed or session ends to prevent resource leaks.
 func (a *Agent) stopCleanupRoutine() {
+	if a.janitor != nil {
+		a.janitor.Stop()
+	}
 	if a.cleanupTicker != nil {
 		a.cleanupTicker.Stop()
 		a.cleanupTicker = nil
@@ -2842,7 +3362,7 @@ func (a *Agent) RebuildSystemPromptWithFilteredServers(ctx context.Context, rele
 	)
 
 	// Update the agent's system prompt
-	a.systemPrompt = newSystemPrompt
+	a.systemPrompt = a.withServerInstructions(newSystemPrompt, relevantServers)
 
 	logger.Info("✅ System prompt rebuilt with filtered servers",
 		loggerv2.Int("filtered_prompts_count", len(filteredPrompts)),
@@ -2852,6 +3372,29 @@ func (a *Agent) RebuildSystemPromptWithFilteredServers(ctx context.Context, rele
 	return nil
 }
 
+// withServerInstructions appends the usage instructions of servers (nil = all
+// connected servers) to systemPrompt when IncludeServerInstructions is set
+func (a *Agent) withServerInstructions(systemPrompt string, servers []string) string {
+	if !a.IncludeServerInstructions {
+		return systemPrompt
+	}
+	section := prompt.BuildServerInstructionsSection(a.serverInstructions, servers)
+	if section == "" {
+		return systemPrompt
+	}
+	return systemPrompt + "\n" + section
+}
+
+// GetServerInstructions returns the usage instructions each connected MCP
+// server sent on initialize, keyed by server name
+func (a *Agent) GetServerInstructions() map[string]string {
+	instructions := make(map[string]string, len(a.serverInstructions))
+	for serverName, text := range a.serverInstructions {
+		instructions[serverName] = text
+	}
+	return instructions
+}
+
 // NewAgentWithObservability creates a new Agent with simplified observability defaults.
 //
 // Unlike NewAgent, this constructor automatically ensures a tracer is configured
@@ -2940,6 +3483,13 @@ func (a *Agent) RemoveEventListener(listener AgentEventListener) {
 
 // initializeHierarchyForContext sets the initial hierarchy level based on calling context
 func (a *Agent) initializeHierarchyForContext(ctx context.Context) {
+	// Sub-agent: the tree continues below the parent's current event
+	if a.subAgent != nil {
+		a.currentHierarchyLevel = 0
+		a.currentParentEventID = a.subAgent.parentEventID
+		return
+	}
+
 	// ✅ SIMPLIFIED APPROACH: Detect context by checking stack trace or other indicators
 
 	// Check if we're in orchestrator context by looking for orchestrator-related context values
@@ -2983,12 +3533,25 @@ func (a *Agent) EmitTypedEvent(ctx context.Context, eventData events.EventData)
 		if sessionIDForEvents == "" {
 			sessionIDForEvents = string(a.TraceID)
 		}
-		baseEventData.SetHierarchyFields(a.currentParentEventID, a.currentHierarchyLevel, sessionIDForEvents, events.GetComponentFromEventType(eventData.GetEventType()))
+		level := a.currentHierarchyLevel
+		// Sub-agent events are nested under the parent's tree (see sub_agent.go)
+		if a.subAgent != nil {
+			sessionIDForEvents = a.subAgent.eventSessionID
+			level += a.subAgent.levelOffset
+		}
+		baseEventData.SetHierarchyFields(a.currentParentEventID, level, sessionIDForEvents, events.GetComponentFromEventType(eventData.GetEventType()))
+	}
+	if a.experimentVariant != "" {
+		if baseEventData, ok := eventData.(interface{ GetBaseEventData() *events.BaseEventData }); ok {
+			baseData := baseEventData.GetBaseEventData()
+			baseData.Metadata = a.tagExperiment(baseData.Metadata)
+		}
 	}
 
 	// Create event with correlation ID for start/end event pairs
 	event := events.NewAgentEvent(eventData)
 	event.TraceID = string(a.TraceID)
+	event.ForkID = a.forkID
 
 	// Generate a unique SpanID for this event
 	event.SpanID = fmt.Sprintf("span_%s_%d", string(eventData.GetEventType()), time.Now().UnixNano())
@@ -3060,10 +3623,17 @@ func (a *Agent) EmitTypedEvent(ctx context.Context, eventData events.EventData)
 		}
 	}
 
+	a.publishEvent(ctx, event)
+}
+
+// publishEvent sends a finished event to all tracers and listeners
+func (a *Agent) publishEvent(ctx context.Context, event *events.AgentEvent) {
+	a.toolStats.observe(event)
+
 	// Send to all tracers (multiple tracer support)
 	// The streaming tracer will automatically forward events to subscribers
 	for _, tracer := range a.Tracers {
-		if err := tracer.EmitEvent(event); err != nil {
+		if err := observability.EmitEvent(ctx, tracer, event); err != nil {
 			a.Logger.Warn("Failed to emit event to tracer", loggerv2.Error(err), loggerv2.String("tracer_type", fmt.Sprintf("%T", tracer)))
 		}
 	}
@@ -3132,6 +3702,28 @@ func (a *Agent) SubscribeToEvents(ctx context.Context) (<-chan *events.AgentEven
 	return nil, func() {}, false
 }
 
+// SubscribeWithReplay subscribes to agent events, first delivering the events
+// retained by WithStreamReplay so late joiners catch up before going live
+func (a *Agent) SubscribeWithReplay(ctx context.Context) (<-chan *events.AgentEvent, func(), bool) {
+	if streamingTracer, hasStreaming := a.GetStreamingTracer(); hasStreaming {
+		eventChan, unsubscribe := streamingTracer.SubscribeWithReplay(ctx)
+		return eventChan, unsubscribe, true
+	}
+	return nil, func() {}, false
+}
+
+// SubscribeWithOptions subscribes to agent events with a per-subscriber
+// verbosity (full, chunks or milestones) and optional replay, e.g. a dashboard
+// with StreamVerbosityFull and a mobile client with StreamVerbosityMilestones
+// on the same agent
+func (a *Agent) SubscribeWithOptions(ctx context.Context, opts SubscribeOptions) (<-chan *events.AgentEvent, func(), bool) {
+	if streamingTracer, hasStreaming := a.GetStreamingTracer(); hasStreaming {
+		eventChan, unsubscribe := streamingTracer.SubscribeWithOptions(ctx, opts)
+		return eventChan, unsubscribe, true
+	}
+	return nil, func() {}, false
+}
+
 // getClientNames returns a list of client names for debugging
 func getClientNames(clients map[string]mcpclient.ClientInterface) []string {
 	names := make([]string, 0, len(clients))
@@ -3148,7 +3740,16 @@ func getClientNames(clients map[string]mcpclient.ClientInterface) []string {
 func (a *Agent) Close() {
 	// Stop periodic cleanup routine
 	a.stopCleanupRoutine()
+	a.waitMemoryWrites()
+	a.cleanupSessionStorage(context.Background())
 	a.closeStreamingTracers()
+	a.closeWebhooks()
+	a.closeTelemetry()
+	a.closeRawLLMLog()
+	a.closeConfigWatcher()
+	a.dropPendingReload()
+	a.unwatchConnectionStates()
+	codeexec.ClearSessionToolCallHook(a.SessionID, a)
 
 	// Connections are shared and managed by the session registry. Do not close
 	// them here; they persist until CloseSession(sessionID) is called.
@@ -3230,11 +3831,12 @@ func (a *Agent) GetConnectionStats() map[string]interface{} {
 // Parameters:
 //   - ctx: Context for the request (can be used for cancellation).
 //   - question: The user's input question.
+//   - opts: Per-call options (e.g. CallWithToolHints).
 //
 // Returns:
 //   - string: The final text response from the agent.
 //   - error: An error if the interaction fails.
-func (a *Agent) Ask(ctx context.Context, question string) (string, error) {
+func (a *Agent) Ask(ctx context.Context, question string, opts ...CallOption) (string, error) {
 	// Create a single user message for the question
 	userMessage := llmtypes.MessageContent{
 		Role:  llmtypes.ChatMessageTypeHuman,
@@ -3242,7 +3844,7 @@ func (a *Agent) Ask(ctx context.Context, question string) (string, error) {
 	}
 
 	// Call AskWithHistory with the single message
-	answer, _, err := AskWithHistory(a, ctx, []llmtypes.MessageContent{userMessage})
+	answer, _, err := AskWithHistory(a, ctx, []llmtypes.MessageContent{userMessage}, opts...)
 	return answer, err
 }
 
@@ -3255,13 +3857,14 @@ func (a *Agent) Ask(ctx context.Context, question string) (string, error) {
 // Parameters:
 //   - ctx: Context for the request.
 //   - messages: The conversation history, including the new user message.
+//   - opts: Per-call options (e.g. CallWithToolHints).
 //
 // Returns:
 //   - string: The final text response from the agent.
 //   - []llmtypes.MessageContent: The updated conversation history (including the new response).
 //   - error: An error if the interaction fails.
-func (a *Agent) AskWithHistory(ctx context.Context, messages []llmtypes.MessageContent) (string, []llmtypes.MessageContent, error) {
-	return AskWithHistory(a, ctx, messages)
+func (a *Agent) AskWithHistory(ctx context.Context, messages []llmtypes.MessageContent, opts ...CallOption) (string, []llmtypes.MessageContent, error) {
+	return AskWithHistory(a, ctx, messages, opts...)
 }
 
 // AskStructured processes a single question and strictly forces the output to match a structured schema.
@@ -3556,6 +4159,12 @@ func (a *Agent) GetSelectedTools() []string {
 	return a.selectedTools
 }
 
+// GetSelectedServers returns the servers the agent's tools are limited to
+// (empty means all servers)
+func (a *Agent) GetSelectedServers() []string {
+	return a.selectedServers
+}
+
 // GetContext returns the agent's context for cancellation and lifecycle management
 func (a *Agent) GetContext() context.Context {
 	return a.ctx
@@ -3786,10 +4395,11 @@ func (a *Agent) RegisterCustomTool(name string, description string, parameters m
 		},
 	}
 
-	// Store both definition and execution function with category
+	// Store both definition and execution function with category.
+	// Execution is wrapped so tool permissions are enforced on every call path.
 	a.customTools[name] = CustomTool{
 		Definition: tool,
-		Execution:  executionFunc,
+		Execution:  a.withToolPermission(name, executionFunc),
 		Category:   toolCategory,
 	}
 
@@ -3980,7 +4590,7 @@ func (a *Agent) RegisterCustomTool(name string, description string, parameters m
 	// This ensures custom tools appear in the system prompt's tool structure JSON
 	// so the LLM knows they exist and can use them via HTTP API
 	if a.UseCodeExecutionMode {
-		if err := a.rebuildSystemPromptWithUpdatedToolStructure(); err != nil {
+		if err := a.rebuildSystemPromptWithUpdatedToolStructure(name); err != nil {
 			if a.Logger != nil {
 				a.Logger.Warn("⚠️ [CODE_EXECUTION] Failed to rebuild system prompt with updated tool structure", loggerv2.Error(err))
 			}
@@ -4184,7 +4794,7 @@ func (a *Agent) UpdateCodeExecutionRegistry() error {
 	// 🔧 CRITICAL: Rebuild system prompt with updated tool structure in code execution mode
 	// This ensures workspace and human tools appear in the system prompt
 	if a.UseCodeExecutionMode {
-		if err := a.rebuildSystemPromptWithUpdatedToolStructure(); err != nil {
+		if err := a.rebuildSystemPromptWithUpdatedToolStructure(""); err != nil {
 			if a.Logger != nil {
 				a.Logger.Warn("⚠️ [CODE_EXECUTION] Failed to rebuild system prompt with updated tool structure", loggerv2.Error(err))
 			}
@@ -4200,16 +4810,21 @@ func (a *Agent) UpdateCodeExecutionRegistry() error {
 }
 
 // rebuildSystemPromptWithUpdatedToolStructure rebuilds the system prompt with the latest tool structure
-// This is called after custom tools are registered to ensure they appear in the system prompt
-func (a *Agent) rebuildSystemPromptWithUpdatedToolStructure() error {
+// This is called after custom tools are registered to ensure they appear in the system prompt.
+// When toolName is set only that tool is re-indexed and the tool structure block is patched
+// in place (see tool_structure.go); an empty toolName rebuilds the index and the prompt.
+func (a *Agent) rebuildSystemPromptWithUpdatedToolStructure(toolName string) error {
 	if !a.UseCodeExecutionMode {
 		return nil // Only needed in code execution mode
 	}
 
-	toolStructure, err := a.buildToolIndex()
+	previousToolStructure, toolStructure, err := a.updateToolStructure(toolName)
 	if err != nil {
 		return fmt.Errorf("failed to build tool index: %w", err)
 	}
+	if !a.isPreDiscoveredTool(toolName) && a.patchToolStructureInPrompt(previousToolStructure, toolStructure) {
+		return nil
+	}
 
 	// Rebuild system prompt with updated tool structure
 	// Note: This function is only called in code execution mode, so UseToolSearchMode is false
@@ -4258,7 +4873,7 @@ func (a *Agent) rebuildSystemPromptWithUpdatedToolStructure() error {
 		// Start from a clean base: strip the AI Staff Engineer persona so our custom
 		// prompts lead. The tool-structure section (available_tools JSON) from
 		// newSystemPrompt is kept so the agent still knows its tools.
-		cleanBase := prompt.RemoveAIStaffEngineerText(newSystemPrompt)
+		cleanBase := prompt.RemoveAIStaffEngineerText(a.withServerInstructions(newSystemPrompt, nil))
 		if cleanBase != "" {
 			a.systemPrompt = cleanBase
 			for _, p := range a.appendedSystemPrompts {
@@ -4268,7 +4883,7 @@ func (a *Agent) rebuildSystemPromptWithUpdatedToolStructure() error {
 			a.systemPrompt = strings.Join(a.appendedSystemPrompts, "\n\n")
 		}
 	} else {
-		a.systemPrompt = newSystemPrompt
+		a.systemPrompt = a.withServerInstructions(newSystemPrompt, nil)
 	}
 
 	if a.Logger != nil {
@@ -4285,6 +4900,7 @@ func (a *Agent) rebuildSystemPromptWithUpdatedToolStructure() error {
 // This is applied per-turn in conversation.go (filteredTools) and in buildToolIndex()
 // (code execution mode). Call ClearToolAllowList to remove the restriction.
 func (a *Agent) SetToolAllowList(toolNames []string) {
+	a.invalidateToolStructure()
 	a.toolAllowListMu.Lock()
 	defer a.toolAllowListMu.Unlock()
 	if len(toolNames) == 0 {
@@ -4312,6 +4928,7 @@ func (a *Agent) SetToolAllowList(toolNames []string) {
 
 // ClearToolAllowList removes any tool restriction, making all registered tools available.
 func (a *Agent) ClearToolAllowList() {
+	a.invalidateToolStructure()
 	a.toolAllowListMu.Lock()
 	defer a.toolAllowListMu.Unlock()
 	a.toolAllowList = nil
@@ -0,0 +1,401 @@
+// answer_contract.go
+//
+// This file enforces a final answer contract, so agents can feed pipelines
+// that parse their output. The contract is a JSON schema checked in one of
+// two modes:
+//
+//   - strict: the answer must be a JSON document valid against the schema.
+//     A single surrounding ```json fence is tolerated and removed; the
+//     returned answer is the bare JSON.
+//   - lenient: the answer is markdown that must contain a section (heading
+//     or bold line) for every required top-level property of the schema,
+//     e.g. "summary" and "next_steps" → "## Summary", "**Next steps**".
+//
+// A violating answer is sent back to the agent with the list of violations,
+// at most MaxRepairs times. When the repairs are used up the conversation
+// ends with an *AnswerContractError carrying the last answer. Every check is
+// reported in an AnswerContractCheck event.
+//
+// The schema check covers the keywords pipelines rely on: type, properties,
+// required, additionalProperties (false), items, enum, minItems and maxItems.
+//
+// Exported:
+//   - AnswerContractMode: AnswerContractStrict / AnswerContractLenient
+//   - WithAnswerContract: Enforce the contract when creating an agent
+//   - WithAnswerContractRepairs: Change the number of repair re-asks
+//   - AnswerContractError: Returned when the answer still violates the contract
+
+package mcpagent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/manishiitg/mcpagent/events"
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+// AnswerContractMode selects how the answer contract is checked
+type AnswerContractMode string
+
+const (
+	// AnswerContractStrict requires a JSON answer valid against the schema
+	AnswerContractStrict AnswerContractMode = "strict"
+	// AnswerContractLenient requires a markdown section per required property
+	AnswerContractLenient AnswerContractMode = "lenient"
+)
+
+// DefaultAnswerContractRepairs is the number of repair re-asks per conversation
+const DefaultAnswerContractRepairs = 2
+
+// answerContract is the contract set by WithAnswerContract
+type answerContract struct {
+	schema     string
+	mode       AnswerContractMode
+	maxRepairs int
+}
+
+// AnswerContractError is returned when the final answer still violates the
+// contract after the repair re-asks
+type AnswerContractError struct {
+	Mode       AnswerContractMode
+	Violations []string
+	// Answer is the last answer the agent gave
+	Answer string
+}
+
+func (e *AnswerContractError) Error() string {
+	return fmt.Sprintf("final answer violates the %s answer contract: %s", e.Mode, strings.Join(e.Violations, "; "))
+}
+
+// WithAnswerContract requires every final answer to satisfy schema (a JSON
+// schema): as a JSON document in AnswerContractStrict mode, or as markdown
+// with a section per required property in AnswerContractLenient mode. The
+// contract is added to the system prompt, and violations trigger up to
+// DefaultAnswerContractRepairs repair re-asks (see WithAnswerContractRepairs).
+//
+// Example:
+//
+//	mcpagent.WithAnswerContract(`{"type": "object", "required": ["summary", "risks"],
+//	    "properties": {"summary": {"type": "string"}, "risks": {"type": "array", "items": {"type": "string"}}}}`,
+//	    mcpagent.AnswerContractStrict)
+//
+// Default: disabled
+func WithAnswerContract(schema string, mode AnswerContractMode) AgentOption {
+	return func(a *Agent) {
+		if mode != AnswerContractLenient {
+			mode = AnswerContractStrict
+		}
+		maxRepairs := DefaultAnswerContractRepairs
+		if a.answerContract != nil {
+			maxRepairs = a.answerContract.maxRepairs
+		}
+		a.answerContract = &answerContract{schema: schema, mode: mode, maxRepairs: maxRepairs}
+		if mode == AnswerContractStrict {
+			a.OutputSchema = schema
+		}
+	}
+}
+
+// WithAnswerContractRepairs sets how many times a final answer violating the
+// answer contract is sent back for repair (0 = fail on the first violation).
+// Only applies with WithAnswerContract, in either order.
+//
+// Default: DefaultAnswerContractRepairs (2)
+func WithAnswerContractRepairs(n int) AgentOption {
+	return func(a *Agent) {
+		if n < 0 {
+			n = 0
+		}
+		if a.answerContract == nil {
+			a.answerContract = &answerContract{}
+		}
+		a.answerContract.maxRepairs = n
+	}
+}
+
+// answerContractPromptSection describes a lenient contract in the system
+// prompt; strict contracts use the Final Answer Format section (OutputSchema)
+func (a *Agent) answerContractPromptSection() string {
+	c := a.answerContract
+	if c == nil || c.mode != AnswerContractLenient {
+		return ""
+	}
+	sections, err := contractSections(c.schema)
+	if err != nil || len(sections) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString("\n## Final Answer Format\n\nYour final answer must be markdown with a heading for each of these sections:\n")
+	for _, section := range sections {
+		sb.WriteString("- " + sectionTitle(section) + "\n")
+	}
+	return sb.String()
+}
+
+// checkAnswerContract checks answer against the contract. It returns the
+// answer to hand out (the bare JSON in strict mode) and the violations. An
+// error means the contract itself is invalid.
+func (a *Agent) checkAnswerContract(ctx context.Context, answer string, turn, repairs int) (string, []string, error) {
+	c := a.answerContract
+	if c == nil || c.schema == "" {
+		return answer, nil, nil
+	}
+	var violations []string
+	var err error
+	if c.mode == AnswerContractLenient {
+		violations, err = checkContractSections(c.schema, answer)
+	} else {
+		answer, violations, err = checkContractJSON(c.schema, answer)
+	}
+	if err != nil {
+		return answer, nil, fmt.Errorf("invalid answer contract: %w", err)
+	}
+
+	satisfied := len(violations) == 0
+	exhausted := !satisfied && repairs >= c.maxRepairs
+	logger := getLogger(a)
+	if satisfied {
+		logger.Info("📜 [ANSWER_CONTRACT] Final answer satisfies the contract",
+			loggerv2.String("mode", string(c.mode)),
+			loggerv2.Int("repairs", repairs))
+	} else {
+		logger.Warn("📜 [ANSWER_CONTRACT] Final answer violates the contract",
+			loggerv2.String("mode", string(c.mode)),
+			loggerv2.Int("violations", len(violations)),
+			loggerv2.Int("repairs", repairs),
+			loggerv2.Any("repairs_exhausted", exhausted))
+	}
+	a.EmitTypedEvent(ctx, events.NewAnswerContractCheckEvent(turn+1, string(c.mode), violations, repairs, exhausted))
+	return answer, violations, nil
+}
+
+// answerContractRepairMessage asks the agent to fix the listed violations
+func (a *Agent) answerContractRepairMessage(violations []string) llmtypes.MessageContent {
+	var sb strings.Builder
+	sb.WriteString("Your final answer does not satisfy the required answer format:\n")
+	for _, v := range violations {
+		sb.WriteString("- " + v + "\n")
+	}
+	if a.answerContract.mode == AnswerContractLenient {
+		sb.WriteString("\nRewrite your final answer so it contains all required sections as markdown headings. Do not call tools unless information is missing.")
+	} else {
+		sb.WriteString("\nRespond again with ONLY a JSON document that conforms to this JSON schema, without any other text or markdown formatting. Do not call tools unless information is missing.\n\nJSON Schema:\n" + a.answerContract.schema)
+	}
+	return llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, sb.String())
+}
+
+// checkContractJSON parses answer as JSON (tolerating one code fence) and
+// validates it against schema
+func checkContractJSON(schema, answer string) (string, []string, error) {
+	var s map[string]interface{}
+	if err := json.Unmarshal([]byte(schema), &s); err != nil {
+		return answer, nil, err
+	}
+	doc := stripJSONFence(answer)
+	var value interface{}
+	if err := json.Unmarshal([]byte(doc), &value); err != nil {
+		return answer, []string{fmt.Sprintf("the answer is not a JSON document (%v)", err)}, nil
+	}
+	var violations []string
+	validateContractValue(s, value, "$", &violations)
+	return doc, violations, nil
+}
+
+// stripJSONFence removes a ```json ... ``` fence around the whole answer
+func stripJSONFence(answer string) string {
+	trimmed := strings.TrimSpace(answer)
+	if !strings.HasPrefix(trimmed, "```") || !strings.HasSuffix(trimmed, "```") || len(trimmed) < 6 {
+		return trimmed
+	}
+	body := strings.TrimSuffix(trimmed[3:], "```")
+	if nl := strings.Index(body, "\n"); nl >= 0 && !strings.ContainsAny(body[:nl], "{[") {
+		body = body[nl+1:] // language tag
+	}
+	return strings.TrimSpace(body)
+}
+
+// validateContractValue appends the violations of value against schema
+func validateContractValue(schema map[string]interface{}, value interface{}, path string, violations *[]string) {
+	if types := schemaTypes(schema["type"]); len(types) > 0 {
+		actual := jsonTypeOf(value)
+		matched := false
+		for _, t := range types {
+			if t == actual || (t == "number" && actual == "integer") {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			*violations = append(*violations, fmt.Sprintf("%s: expected %s, got %s", path, strings.Join(types, " or "), actual))
+			return
+		}
+	}
+
+	if enum, ok := schema["enum"].([]interface{}); ok && len(enum) > 0 {
+		found := false
+		for _, allowed := range enum {
+			if jsonEqual(allowed, value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			encoded, _ := json.Marshal(enum)
+			*violations = append(*violations, fmt.Sprintf("%s: must be one of %s", path, encoded))
+		}
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		properties, _ := schema["properties"].(map[string]interface{})
+		if required, ok := schema["required"].([]interface{}); ok {
+			for _, r := range required {
+				name, _ := r.(string)
+				if _, present := v[name]; name != "" && !present {
+					*violations = append(*violations, fmt.Sprintf("%s: missing required property %q", path, name))
+				}
+			}
+		}
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if propSchema, ok := properties[key].(map[string]interface{}); ok {
+				validateContractValue(propSchema, v[key], path+"."+key, violations)
+			} else if additional, ok := schema["additionalProperties"].(bool); ok && !additional {
+				*violations = append(*violations, fmt.Sprintf("%s: unexpected property %q", path, key))
+			}
+		}
+	case []interface{}:
+		if minItems, ok := schema["minItems"].(float64); ok && float64(len(v)) < minItems {
+			*violations = append(*violations, fmt.Sprintf("%s: expected at least %d items, got %d", path, int(minItems), len(v)))
+		}
+		if maxItems, ok := schema["maxItems"].(float64); ok && float64(len(v)) > maxItems {
+			*violations = append(*violations, fmt.Sprintf("%s: expected at most %d items, got %d", path, int(maxItems), len(v)))
+		}
+		if items, ok := schema["items"].(map[string]interface{}); ok {
+			for i, item := range v {
+				validateContractValue(items, item, fmt.Sprintf("%s[%d]", path, i), violations)
+			}
+		}
+	}
+}
+
+// schemaTypes returns the allowed types of a "type" keyword
+func schemaTypes(t interface{}) []string {
+	switch v := t.(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		var types []string
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				types = append(types, s)
+			}
+		}
+		return types
+	}
+	return nil
+}
+
+// jsonTypeOf returns the JSON schema type of a decoded value
+func jsonTypeOf(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		if v == math.Trunc(v) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// jsonEqual compares two decoded JSON values
+func jsonEqual(a, b interface{}) bool {
+	ea, errA := json.Marshal(a)
+	eb, errB := json.Marshal(b)
+	return errA == nil && errB == nil && string(ea) == string(eb)
+}
+
+// contractSections returns the sections a lenient contract requires: the
+// schema's required top-level properties, or all of them when none are required
+func contractSections(schema string) ([]string, error) {
+	var s struct {
+		Required   []string                   `json:"required"`
+		Properties map[string]json.RawMessage `json:"properties"`
+	}
+	if err := json.Unmarshal([]byte(schema), &s); err != nil {
+		return nil, err
+	}
+	if len(s.Required) > 0 {
+		return s.Required, nil
+	}
+	sections := make([]string, 0, len(s.Properties))
+	for name := range s.Properties {
+		sections = append(sections, name)
+	}
+	sort.Strings(sections)
+	return sections, nil
+}
+
+// markdownSectionPattern matches headings and lines that are entirely bold
+var markdownSectionPattern = regexp.MustCompile(`(?m)^\s*(?:#{1,6}\s+(.+?)\s*#*|\*\*(.+?)\*\*:?)\s*$`)
+
+// checkContractSections reports the required sections missing from answer
+func checkContractSections(schema, answer string) ([]string, error) {
+	sections, err := contractSections(schema)
+	if err != nil {
+		return nil, err
+	}
+	present := make(map[string]bool)
+	for _, m := range markdownSectionPattern.FindAllStringSubmatch(answer, -1) {
+		title := m[1]
+		if title == "" {
+			title = m[2]
+		}
+		present[normalizeSectionName(title)] = true
+	}
+	var violations []string
+	for _, section := range sections {
+		if !present[normalizeSectionName(section)] {
+			violations = append(violations, fmt.Sprintf("missing section %q", sectionTitle(section)))
+		}
+	}
+	return violations, nil
+}
+
+// sectionTitle renders a property name as a heading: "next_steps" → "Next steps"
+func sectionTitle(name string) string {
+	title := strings.TrimSpace(strings.NewReplacer("_", " ", "-", " ").Replace(name))
+	if title == "" {
+		return name
+	}
+	return strings.ToUpper(title[:1]) + title[1:]
+}
+
+// normalizeSectionName compares section names case- and separator-insensitively
+func normalizeSectionName(name string) string {
+	name = strings.ToLower(strings.TrimRight(strings.TrimSpace(name), ":"))
+	return strings.Join(strings.FieldsFunc(name, func(r rune) bool {
+		return r == ' ' || r == '_' || r == '-'
+	}), " ")
+}
@@ -0,0 +1,150 @@
+package mcpagent
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/manishiitg/mcpagent/events"
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+const contractTestSchema = `{
+	"type": "object",
+	"required": ["summary", "next_steps"],
+	"additionalProperties": false,
+	"properties": {
+		"summary": {"type": "string"},
+		"next_steps": {"type": "array", "items": {"type": "string"}, "minItems": 1},
+		"severity": {"enum": ["low", "high"]}
+	}
+}`
+
+func TestCheckContractJSON(t *testing.T) {
+	tests := []struct {
+		name       string
+		answer     string
+		violations []string
+	}{
+		{"valid", `{"summary": "ok", "next_steps": ["ship"]}`, nil},
+		{"fenced", "```json\n{\"summary\": \"ok\", \"next_steps\": [\"ship\"], \"severity\": \"low\"}\n```", nil},
+		{"not json", "All good.", []string{"the answer is not a JSON document"}},
+		{"missing and wrong types", `{"next_steps": [1], "severity": "medium", "extra": true}`, []string{
+			`$: missing required property "summary"`,
+			`$: unexpected property "extra"`,
+			`$.next_steps[0]: expected string, got integer`,
+			`$.severity: must be one of ["low","high"]`,
+		}},
+		{"empty array", `{"summary": "ok", "next_steps": []}`, []string{"$.next_steps: expected at least 1 items, got 0"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, violations, err := checkContractJSON(contractTestSchema, tt.answer)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(violations) != len(tt.violations) {
+				t.Fatalf("violations = %q, want %q", violations, tt.violations)
+			}
+			for i := range violations {
+				if !strings.HasPrefix(violations[i], tt.violations[i]) {
+					t.Errorf("violation %d = %q, want %q", i, violations[i], tt.violations[i])
+				}
+			}
+		})
+	}
+
+	if doc, _, _ := checkContractJSON(contractTestSchema, "```json\n{\"summary\": \"ok\"}\n```"); doc != `{"summary": "ok"}` {
+		t.Errorf("fenced answer normalized to %q", doc)
+	}
+	if _, _, err := checkContractJSON("{not a schema", "{}"); err == nil {
+		t.Error("expected an error for an invalid schema")
+	}
+}
+
+func TestCheckContractSections(t *testing.T) {
+	answer := "## Summary\nAll services are healthy.\n\n**Next steps:**\n- nothing"
+	violations, err := checkContractSections(contractTestSchema, answer)
+	if err != nil || len(violations) != 0 {
+		t.Fatalf("violations = %q, err = %v", violations, err)
+	}
+	violations, _ = checkContractSections(contractTestSchema, "# Summary\nhealthy")
+	if len(violations) != 1 || violations[0] != `missing section "Next steps"` {
+		t.Errorf("violations = %q, want the missing next steps section", violations)
+	}
+}
+
+func TestAnswerContractRepairsViolatingAnswers(t *testing.T) {
+	generate := &scriptedGenerateStage{responses: []*llmtypes.ContentResponse{
+		{Choices: []*llmtypes.ContentChoice{{Content: "Everything is fine."}}},
+		{Choices: []*llmtypes.ContentChoice{{Content: "```json\n{\"summary\": \"fine\", \"next_steps\": [\"none\"]}\n```"}}},
+	}}
+	listener := &recordingAgentEventListener{}
+	a := &Agent{Logger: loggerv2.NewNoop(), LLM: &providerKeyCarrierModel{}, ModelID: "test-model", MaxTurns: 5}
+	a.AddEventListener(listener)
+	WithAskPipeline(AskPipeline{Generate: generate, DispatchTools: &recordingToolDispatcher{}})(a)
+	WithAnswerContract(contractTestSchema, AnswerContractStrict)(a)
+	if a.OutputSchema != contractTestSchema {
+		t.Error("a strict contract should describe the schema in the system prompt")
+	}
+
+	answer, history, err := AskWithHistory(a, context.Background(), []llmtypes.MessageContent{
+		llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "status?"),
+	})
+	if err != nil {
+		t.Fatalf("AskWithHistory: %v", err)
+	}
+	if answer != `{"summary": "fine", "next_steps": ["none"]}` || generate.calls != 2 {
+		t.Fatalf("got %q after %d calls, want the repaired JSON after one re-ask", answer, generate.calls)
+	}
+	repaired := false
+	for _, msg := range history {
+		if msg.Role == llmtypes.ChatMessageTypeHuman && strings.Contains(msg.Parts[0].(llmtypes.TextContent).Text, "not a JSON document") {
+			repaired = true
+		}
+	}
+	if !repaired {
+		t.Error("expected a repair message listing the violation")
+	}
+
+	var checks []*events.AnswerContractCheckEvent
+	for _, event := range listener.events {
+		if data, ok := event.Data.(*events.AnswerContractCheckEvent); ok {
+			checks = append(checks, data)
+		}
+	}
+	if len(checks) != 2 || checks[0].Satisfied || !checks[1].Satisfied || checks[1].Repairs != 1 {
+		t.Errorf("unexpected contract check events: %+v", checks)
+	}
+}
+
+func TestAnswerContractFailsWhenRepairsAreExhausted(t *testing.T) {
+	generate := &scriptedGenerateStage{responses: []*llmtypes.ContentResponse{
+		{Choices: []*llmtypes.ContentChoice{{Content: "Summary: fine"}}},
+		{Choices: []*llmtypes.ContentChoice{{Content: "## Summary\nfine"}}},
+	}}
+	a := &Agent{Logger: loggerv2.NewNoop(), LLM: &providerKeyCarrierModel{}, ModelID: "test-model", MaxTurns: 5}
+	WithAskPipeline(AskPipeline{Generate: generate, DispatchTools: &recordingToolDispatcher{}})(a)
+	WithAnswerContractRepairs(1)(a)
+	WithAnswerContract(contractTestSchema, AnswerContractLenient)(a)
+
+	if section := a.answerContractPromptSection(); !strings.Contains(section, "- Next steps") {
+		t.Errorf("prompt section = %q, want the required sections", section)
+	}
+
+	answer, _, err := AskWithHistory(a, context.Background(), []llmtypes.MessageContent{
+		llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "status?"),
+	})
+	var contractErr *AnswerContractError
+	if !errors.As(err, &contractErr) {
+		t.Fatalf("err = %v, want an AnswerContractError", err)
+	}
+	if answer != "## Summary\nfine" || contractErr.Answer != answer || generate.calls != 2 {
+		t.Errorf("got %q after %d calls, want the last answer after one repair", answer, generate.calls)
+	}
+	if len(contractErr.Violations) != 1 || !strings.Contains(contractErr.Violations[0], "Next steps") {
+		t.Errorf("violations = %q", contractErr.Violations)
+	}
+}
@@ -0,0 +1,368 @@
+// audit_log.go
+//
+// This file provides the audit log: an append-only record of an agent's full
+// decision trail per session (user prompts, system prompts, LLM responses,
+// tool calls with their arguments, tool results and errors, and how each
+// conversation ended) for compliance in enterprise deployments. Records are
+// derived from the agent's events and written to an AuditSink as they
+// happen.
+//
+// The log is tamper-evident: every record carries the SHA-256 hash of its own
+// content and the hash of the previous record of the same session, so
+// editing, deleting or reordering a stored record breaks the chain.
+// GetAuditTrail verifies the chain before returning a session's records.
+// Sub-agent records belong to the parent's session, like their events.
+//
+// Exported:
+//   - WithAuditLog: Record the decision trail to an AuditSink
+//   - AuditSink: Storage interface for audit records
+//   - AuditRecord / AuditKind: One recorded step of the trail
+//   - FileAuditSink: Append-only JSON Lines files, one per session
+//   - MemoryAuditSink: In-process sink (tests, short-lived agents)
+//   - VerifyAuditTrail / ErrAuditTrailTampered: Chain verification
+//   - Agent.GetAuditTrail: Read and verify a session's records
+
+package mcpagent
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/manishiitg/mcpagent/events"
+)
+
+// AuditKind is the kind of step an AuditRecord records
+type AuditKind string
+
+const (
+	// AuditSystemPrompt records the system prompt, whenever it changes within a session
+	AuditSystemPrompt AuditKind = "system_prompt"
+	// AuditPrompt records a user message (the query, steering or corrections)
+	AuditPrompt AuditKind = "prompt"
+	// AuditLLMResponse records the text of an LLM response
+	AuditLLMResponse AuditKind = "llm_response"
+	// AuditToolCall records a tool call and its arguments
+	AuditToolCall AuditKind = "tool_call"
+	// AuditToolResult records the result of a tool call
+	AuditToolResult AuditKind = "tool_result"
+	// AuditToolError records a failed tool call
+	AuditToolError AuditKind = "tool_error"
+	// AuditConversationEnd records the final answer and status of a conversation
+	AuditConversationEnd AuditKind = "conversation_end"
+	// AuditConversationError records a conversation that failed
+	AuditConversationError AuditKind = "conversation_error"
+)
+
+// ErrAuditTrailTampered is returned when the hash chain of an audit trail is broken
+var ErrAuditTrailTampered = errors.New("audit trail tampered")
+
+// AuditRecord is one step of a session's decision trail
+type AuditRecord struct {
+	// Sequence numbers the records of a session from 1
+	Sequence  int64     `json:"seq"`
+	SessionID string    `json:"session_id"`
+	Timestamp time.Time `json:"timestamp"`
+	Kind      AuditKind `json:"kind"`
+	Turn      int       `json:"turn,omitempty"`
+
+	ToolName   string `json:"tool_name,omitempty"`
+	ServerName string `json:"server_name,omitempty"`
+	ToolCallID string `json:"tool_call_id,omitempty"`
+	// Status of a conversation_end record ("completed", "cancelled", ...)
+	Status string `json:"status,omitempty"`
+
+	// Content is the prompt, response, tool arguments, result or error, in full
+	Content string `json:"content"`
+
+	// PrevHash is the Hash of the previous record of the session ("" for the first)
+	PrevHash string `json:"prev_hash"`
+	// Hash is the hex SHA-256 of the record with Hash empty
+	Hash string `json:"hash"`
+}
+
+// computeHash returns the hash of the record's content and PrevHash
+func (r AuditRecord) computeHash() string {
+	r.Hash = ""
+	data, _ := json.Marshal(r)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// AuditSink stores audit records. Implementations must be safe for concurrent
+// use and must never modify or drop stored records.
+type AuditSink interface {
+	// Append stores record after the session's previous records
+	Append(ctx context.Context, record AuditRecord) error
+	// Records returns the records of sessionID in the order they were appended
+	Records(ctx context.Context, sessionID string) ([]AuditRecord, error)
+}
+
+// WithAuditLog records the agent's decision trail (prompts, LLM responses,
+// tool calls, arguments and results) as a hash-chained log in sink. Records
+// are written synchronously as events happen; a failed write is logged and
+// leaves the chain at the last stored record. Read a session back with
+// Agent.GetAuditTrail.
+//
+// Example:
+//
+//	mcpagent.WithAuditLog(mcpagent.FileAuditSink{Dir: "/var/log/agent-audit"})
+//
+// Default: disabled
+func WithAuditLog(sink AuditSink) AgentOption {
+	return func(a *Agent) {
+		if sink == nil {
+			return
+		}
+		a.auditLog = &auditLogger{
+			sink:          sink,
+			heads:         make(map[string]AuditRecord),
+			systemPrompts: make(map[string]string),
+		}
+		a.listeners = append(a.listeners, a.auditLog)
+	}
+}
+
+// GetAuditTrail returns the audit records of sessionID ("" = the agent's own
+// session) after verifying their hash chain. When the chain is broken the
+// records are returned together with an error matching ErrAuditTrailTampered.
+func (a *Agent) GetAuditTrail(sessionID string) ([]AuditRecord, error) {
+	if a.auditLog == nil {
+		return nil, fmt.Errorf("audit log is not enabled (see WithAuditLog)")
+	}
+	if sessionID == "" {
+		sessionID = a.SessionID
+		if sessionID == "" {
+			sessionID = string(a.TraceID)
+		}
+	}
+	records, err := a.auditLog.sink.Records(context.Background(), sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read audit trail: %w", err)
+	}
+	return records, VerifyAuditTrail(records)
+}
+
+// VerifyAuditTrail checks that records form an unbroken hash chain starting at
+// the first record of a session
+func VerifyAuditTrail(records []AuditRecord) error {
+	prevHash := ""
+	for i, record := range records {
+		if record.Sequence != int64(i+1) {
+			return fmt.Errorf("%w: record %d has sequence %d", ErrAuditTrailTampered, i+1, record.Sequence)
+		}
+		if record.PrevHash != prevHash {
+			return fmt.Errorf("%w: record %d does not follow record %d", ErrAuditTrailTampered, record.Sequence, record.Sequence-1)
+		}
+		if record.computeHash() != record.Hash {
+			return fmt.Errorf("%w: record %d was modified", ErrAuditTrailTampered, record.Sequence)
+		}
+		prevHash = record.Hash
+	}
+	return nil
+}
+
+// auditLogger is the AgentEventListener that turns events into audit records
+type auditLogger struct {
+	sink AuditSink
+
+	mu            sync.Mutex
+	heads         map[string]AuditRecord // last stored record per session
+	systemPrompts map[string]string      // last recorded system prompt per session
+}
+
+// Name implements AgentEventListener
+func (l *auditLogger) Name() string {
+	return "audit_log"
+}
+
+// HandleEvent implements AgentEventListener
+func (l *auditLogger) HandleEvent(ctx context.Context, event *events.AgentEvent) error {
+	if event == nil || event.Data == nil {
+		return nil
+	}
+	sessionID := event.SessionID
+	if sessionID == "" {
+		sessionID = event.TraceID
+	}
+
+	var records []AuditRecord
+	switch e := event.Data.(type) {
+	case *events.ConversationStartEvent:
+		l.mu.Lock()
+		changed := e.SystemPrompt != "" && l.systemPrompts[sessionID] != e.SystemPrompt
+		l.mu.Unlock()
+		if changed {
+			records = append(records, AuditRecord{Kind: AuditSystemPrompt, Content: e.SystemPrompt})
+		}
+	case *events.UserMessageEvent:
+		records = append(records, AuditRecord{Kind: AuditPrompt, Turn: e.Turn, Content: e.Content})
+	case *events.LLMGenerationEndEvent:
+		records = append(records, AuditRecord{Kind: AuditLLMResponse, Turn: e.Turn, Content: e.Content})
+	case *events.ToolCallStartEvent:
+		records = append(records, AuditRecord{Kind: AuditToolCall, Turn: e.Turn, ToolName: e.ToolName,
+			ServerName: e.ServerName, ToolCallID: e.ToolCallID, Content: e.ToolParams.Arguments})
+	case *events.ToolCallEndEvent:
+		records = append(records, AuditRecord{Kind: AuditToolResult, Turn: e.Turn, ToolName: e.ToolName,
+			ServerName: e.ServerName, ToolCallID: e.ToolCallID, Content: e.Result})
+	case *events.ToolCallErrorEvent:
+		records = append(records, AuditRecord{Kind: AuditToolError, Turn: e.Turn, ToolName: e.ToolName,
+			ServerName: e.ServerName, ToolCallID: e.ToolCallID, Content: e.Error})
+	case *events.ConversationEndEvent:
+		content := e.Result
+		if e.Error != "" {
+			content = e.Error
+		}
+		records = append(records, AuditRecord{Kind: AuditConversationEnd, Status: e.Status, Content: content})
+	case *events.ConversationErrorEvent:
+		records = append(records, AuditRecord{Kind: AuditConversationError, Turn: e.Turn, Content: e.Error})
+	}
+
+	timestamp := event.Timestamp.UTC()
+	if event.Timestamp.IsZero() {
+		timestamp = time.Now().UTC()
+	}
+	for _, record := range records {
+		record.SessionID = sessionID
+		record.Timestamp = timestamp
+		if err := l.append(ctx, record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// append chains record to the session's last record and stores it
+func (l *auditLogger) append(ctx context.Context, record AuditRecord) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	head, ok := l.heads[record.SessionID]
+	if !ok {
+		// Continue the chain of a session recorded by an earlier process
+		existing, err := l.sink.Records(ctx, record.SessionID)
+		if err != nil {
+			return fmt.Errorf("failed to read audit trail: %w", err)
+		}
+		if len(existing) > 0 {
+			head = existing[len(existing)-1]
+		}
+	}
+	record.Sequence = head.Sequence + 1
+	record.PrevHash = head.Hash
+	record.Hash = record.computeHash()
+	if err := l.sink.Append(ctx, record); err != nil {
+		return fmt.Errorf("failed to write audit record: %w", err)
+	}
+	l.heads[record.SessionID] = record
+	if record.Kind == AuditSystemPrompt {
+		l.systemPrompts[record.SessionID] = record.Content
+	}
+	return nil
+}
+
+// FileAuditSink appends records to {Dir}/audit_{session}.jsonl. Files are
+// only ever opened for appending, and created with mode 0600.
+type FileAuditSink struct {
+	Dir string
+}
+
+var fileAuditSinkMu sync.Mutex
+
+func (s FileAuditSink) path(sessionID string) string {
+	if sessionID == "" {
+		sessionID = "default"
+	}
+	return filepath.Join(s.Dir, "audit_"+sanitizeRawLLMLogName(sessionID)+".jsonl")
+}
+
+// Append implements AuditSink
+func (s FileAuditSink) Append(_ context.Context, record AuditRecord) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	fileAuditSinkMu.Lock()
+	defer fileAuditSinkMu.Unlock()
+	if err := os.MkdirAll(s.Dir, 0o700); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(s.path(record.SessionID), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600) //nolint:gosec // Dir is supplied by the caller
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(line); err != nil {
+		_ = file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// Records implements AuditSink
+func (s FileAuditSink) Records(_ context.Context, sessionID string) ([]AuditRecord, error) {
+	fileAuditSinkMu.Lock()
+	defer fileAuditSinkMu.Unlock()
+	file, err := os.Open(s.path(sessionID)) //nolint:gosec // Dir is supplied by the caller
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var records []AuditRecord
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64<<20)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var record AuditRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return nil, fmt.Errorf("%w: unreadable record after sequence %d", ErrAuditTrailTampered, len(records))
+		}
+		// Sanitized names can collide; keep only this session's records
+		if record.SessionID == sessionID {
+			records = append(records, record)
+		}
+	}
+	return records, scanner.Err()
+}
+
+// MemoryAuditSink keeps records in process memory
+type MemoryAuditSink struct {
+	mu      sync.RWMutex
+	records map[string][]AuditRecord
+}
+
+// NewMemoryAuditSink creates an empty MemoryAuditSink
+func NewMemoryAuditSink() *MemoryAuditSink {
+	return &MemoryAuditSink{records: make(map[string][]AuditRecord)}
+}
+
+// Append implements AuditSink
+func (s *MemoryAuditSink) Append(_ context.Context, record AuditRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records[record.SessionID] = append(s.records[record.SessionID], record)
+	return nil
+}
+
+// Records implements AuditSink
+func (s *MemoryAuditSink) Records(_ context.Context, sessionID string) ([]AuditRecord, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]AuditRecord(nil), s.records[sessionID]...), nil
+}
@@ -0,0 +1,98 @@
+package mcpagent
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/manishiitg/mcpagent/events"
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+)
+
+func emitAuditTestConversation(a *Agent, question string) {
+	ctx := context.Background()
+	a.EmitTypedEvent(ctx, events.NewConversationStartEvent(question, "You are a careful analyst.", 1, "crm"))
+	a.EmitTypedEvent(ctx, events.NewUserMessageEvent(0, question, "user"))
+	a.EmitTypedEvent(ctx, events.NewLLMGenerationEndEvent(1, "Looking up the account.", 1, time.Second, events.UsageMetrics{}))
+	a.EmitTypedEvent(ctx, events.NewToolCallStartEvent(1, "get_account", events.ToolParams{Arguments: `{"id":"acme"}`}, "crm", ""))
+	a.EmitTypedEvent(ctx, events.NewToolCallEndEvent(1, "get_account", `{"tier":"gold"}`, "crm", time.Millisecond, ""))
+	a.EmitTypedEvent(ctx, events.NewConversationEndEvent(question, "Acme is a gold account.", time.Second, 2, ConversationCompletedStatus, ""))
+}
+
+func TestAuditLogRecordsHashChainedTrail(t *testing.T) {
+	dir := t.TempDir()
+	a := &Agent{Logger: loggerv2.NewNoop(), SessionID: "session-1"}
+	WithAuditLog(FileAuditSink{Dir: dir})(a)
+	emitAuditTestConversation(a, "What tier is acme?")
+	emitAuditTestConversation(a, "And its owner?")
+
+	trail, err := a.GetAuditTrail("")
+	if err != nil {
+		t.Fatalf("GetAuditTrail: %v", err)
+	}
+	var kinds []string
+	for _, record := range trail {
+		kinds = append(kinds, string(record.Kind))
+	}
+	// The unchanged system prompt is only recorded once per session
+	want := "system_prompt prompt llm_response tool_call tool_result conversation_end " +
+		"prompt llm_response tool_call tool_result conversation_end"
+	if got := strings.Join(kinds, " "); got != want {
+		t.Fatalf("kinds = %s\nwant    %s", got, want)
+	}
+	if call := trail[3]; call.ToolName != "get_account" || call.ServerName != "crm" || call.Content != `{"id":"acme"}` {
+		t.Errorf("tool call record = %+v", call)
+	}
+	if end := trail[5]; end.Status != ConversationCompletedStatus || end.Content != "Acme is a gold account." {
+		t.Errorf("conversation end record = %+v", end)
+	}
+
+	// A new process continues the chain of the stored session
+	b := &Agent{Logger: loggerv2.NewNoop(), SessionID: "session-1"}
+	WithAuditLog(FileAuditSink{Dir: dir})(b)
+	b.EmitTypedEvent(context.Background(), events.NewUserMessageEvent(0, "Thanks", "user"))
+	trail, err = b.GetAuditTrail("session-1")
+	if err != nil || len(trail) != 12 || trail[11].Sequence != 12 {
+		t.Fatalf("continued trail: %d records, %v", len(trail), err)
+	}
+
+	// Editing a stored record breaks the chain
+	path := filepath.Join(dir, "audit_session-1.jsonl")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tampered := strings.Replace(string(data), `{\"tier\":\"gold\"}`, `{\"tier\":\"basic\"}`, 1)
+	if tampered == string(data) {
+		t.Fatal("tool result not found in the audit file")
+	}
+	if err := os.WriteFile(path, []byte(tampered), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.GetAuditTrail("session-1"); !errors.Is(err, ErrAuditTrailTampered) || !strings.Contains(err.Error(), "record 5 was modified") {
+		t.Errorf("GetAuditTrail after tampering = %v", err)
+	}
+}
+
+func TestVerifyAuditTrailDetectsRemovedRecords(t *testing.T) {
+	sink := NewMemoryAuditSink()
+	a := &Agent{Logger: loggerv2.NewNoop(), SessionID: "session-2"}
+	WithAuditLog(sink)(a)
+	emitAuditTestConversation(a, "q")
+
+	records, _ := sink.Records(context.Background(), "session-2")
+	if err := VerifyAuditTrail(records); err != nil {
+		t.Fatalf("intact trail: %v", err)
+	}
+	removed := append(append([]AuditRecord(nil), records[:2]...), records[3:]...)
+	if err := VerifyAuditTrail(removed); !errors.Is(err, ErrAuditTrailTampered) {
+		t.Errorf("removed record not detected: %v", err)
+	}
+	if trail, err := (&Agent{}).GetAuditTrail("session-2"); err == nil || trail != nil {
+		t.Error("GetAuditTrail should fail when the audit log is disabled")
+	}
+}
@@ -0,0 +1,441 @@
+// autosave.go
+//
+// This file provides conversation autosave for crash recovery. When an agent
+// is created WithAutosave, the conversation state is checkpointed to a
+// ConversationStore every N turns. If the process dies mid-conversation,
+// RecoverConversations lists the unfinished checkpoints after restart and
+// ResumeConversation continues one of them from its last saved turn.
+//
+// The same store keeps persistent sessions (see session_store.go): with
+// WithSessionStore the checkpoint of a running conversation and its session
+// are one record under the session ID, marked Completed when the call
+// finishes instead of being deleted.
+//
+// Exported:
+//   - ConversationStore: Checkpoint and session persistence interface
+//   - FileConversationStore: ConversationStore backed by one JSON file per conversation
+//   - MemoryConversationStore: In-process ConversationStore (tests, single-process servers)
+//   - ConversationCheckpoint: Saved conversation state
+//   - RecoverConversations: List resumable conversations in a store
+//   - Agent.ResumeConversation: Continue a conversation from a checkpoint
+//   - Agent.CheckpointRetainedHistory: Save the last completed conversation, e.g. before shutdown
+
+package mcpagent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+// ErrCheckpointNotFound is returned by ConversationStore.Load for unknown IDs
+var ErrCheckpointNotFound = errors.New("conversation checkpoint not found")
+
+// ConversationCheckpoint is the saved state of a conversation: a checkpoint
+// of one in progress, or a session (see WithSessionStore)
+type ConversationCheckpoint struct {
+	ID        string                    `json:"id"`
+	SessionID string                    `json:"session_id,omitempty"`
+	UserID    string                    `json:"user_id,omitempty"`
+	Owner     string                    `json:"owner,omitempty"` // See WithCheckpointOwner
+	Provider  string                    `json:"provider,omitempty"`
+	ModelID   string                    `json:"model_id,omitempty"`
+	Question  string                    `json:"question,omitempty"` // Latest user message
+	Turn      int                       `json:"turn"`               // Turns completed when the checkpoint was taken
+	Messages  []llmtypes.MessageContent `json:"messages"`
+	LastError string                    `json:"last_error,omitempty"` // Set when the conversation failed
+	CreatedAt time.Time                 `json:"created_at,omitzero"`
+	UpdatedAt time.Time                 `json:"updated_at"`
+
+	// Session state, carried over by ResumeSession and ResumeConversation
+	TokenUsage        SessionTokenUsage `json:"token_usage,omitzero"`
+	Summaries         []string          `json:"summaries,omitempty"`          // Context summaries, oldest first
+	Experiment        string            `json:"experiment,omitempty"`         // Experiment the session is enrolled in (see WithExperiment)
+	ExperimentVariant string            `json:"experiment_variant,omitempty"` // Variant the session was assigned
+	// Completed marks a session saved after a call that finished; it is kept
+	// for ResumeSession but not returned by RecoverConversations
+	Completed bool `json:"completed,omitempty"`
+}
+
+// ConversationStore persists conversation checkpoints and sessions.
+// Implementations must be safe for concurrent use by multiple agents.
+type ConversationStore interface {
+	// Save creates or replaces the checkpoint with checkpoint.ID
+	Save(ctx context.Context, checkpoint *ConversationCheckpoint) error
+	// Load returns the checkpoint for id, or ErrCheckpointNotFound
+	Load(ctx context.Context, id string) (*ConversationCheckpoint, error)
+	// List returns all stored checkpoints
+	List(ctx context.Context) ([]*ConversationCheckpoint, error)
+	// Delete removes the checkpoint for id; deleting an unknown id is not an error
+	Delete(ctx context.Context, id string) error
+}
+
+// FileConversationStore stores each checkpoint as {Dir}/{id}.json. Writes go
+// through a temporary file and a rename so a crash never leaves a truncated
+// checkpoint behind.
+type FileConversationStore struct {
+	Dir string
+}
+
+// NewFileConversationStore creates a FileConversationStore, creating dir if needed
+func NewFileConversationStore(dir string) (*FileConversationStore, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create checkpoint directory: %w", err)
+	}
+	return &FileConversationStore{Dir: dir}, nil
+}
+
+func (s *FileConversationStore) path(id string) (string, error) {
+	if id == "" || id == "." || id == ".." || strings.ContainsAny(id, `/\`) {
+		return "", fmt.Errorf("invalid checkpoint id %q", id)
+	}
+	return filepath.Join(s.Dir, id+".json"), nil
+}
+
+// Save implements ConversationStore
+func (s *FileConversationStore) Save(_ context.Context, checkpoint *ConversationCheckpoint) error {
+	path, err := s.path(checkpoint.ID)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(checkpoint)
+	if err != nil {
+		return fmt.Errorf("failed to encode checkpoint: %w", err)
+	}
+	tmp, err := os.CreateTemp(s.Dir, checkpoint.ID+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	return nil
+}
+
+// Load implements ConversationStore
+func (s *FileConversationStore) Load(_ context.Context, id string) (*ConversationCheckpoint, error) {
+	path, err := s.path(id)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path) //nolint:gosec // G304: path is built from a validated checkpoint id
+	if os.IsNotExist(err) {
+		return nil, ErrCheckpointNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+	var checkpoint ConversationCheckpoint
+	if err := json.Unmarshal(data, &checkpoint); err != nil {
+		return nil, fmt.Errorf("failed to decode checkpoint %s: %w", id, err)
+	}
+	return &checkpoint, nil
+}
+
+// List implements ConversationStore. Unreadable files are skipped.
+func (s *FileConversationStore) List(ctx context.Context) ([]*ConversationCheckpoint, error) {
+	entries, err := os.ReadDir(s.Dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to list checkpoints: %w", err)
+	}
+	var checkpoints []*ConversationCheckpoint
+	for _, entry := range entries {
+		id, ok := strings.CutSuffix(entry.Name(), ".json")
+		if entry.IsDir() || !ok {
+			continue
+		}
+		checkpoint, err := s.Load(ctx, id)
+		if err != nil {
+			continue
+		}
+		checkpoints = append(checkpoints, checkpoint)
+	}
+	return checkpoints, nil
+}
+
+// Delete implements ConversationStore
+func (s *FileConversationStore) Delete(_ context.Context, id string) error {
+	path, err := s.path(id)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete checkpoint: %w", err)
+	}
+	return nil
+}
+
+// DeleteUserData implements UserDataStore
+func (s *FileConversationStore) DeleteUserData(ctx context.Context, userID string) error {
+	return deleteUserConversations(ctx, s, userID)
+}
+
+// MemoryConversationStore keeps checkpoints in process memory. They are lost
+// when the process exits; use it for tests or when the store is shared by
+// agents of one long-running process.
+type MemoryConversationStore struct {
+	mu          sync.RWMutex
+	checkpoints map[string][]byte // JSON-encoded, so callers never share message slices
+}
+
+// NewMemoryConversationStore creates an empty MemoryConversationStore
+func NewMemoryConversationStore() *MemoryConversationStore {
+	return &MemoryConversationStore{checkpoints: make(map[string][]byte)}
+}
+
+// Save implements ConversationStore
+func (s *MemoryConversationStore) Save(_ context.Context, checkpoint *ConversationCheckpoint) error {
+	if checkpoint.ID == "" {
+		return fmt.Errorf("invalid checkpoint id %q", checkpoint.ID)
+	}
+	data, err := json.Marshal(checkpoint)
+	if err != nil {
+		return fmt.Errorf("failed to encode checkpoint: %w", err)
+	}
+	s.mu.Lock()
+	s.checkpoints[checkpoint.ID] = data
+	s.mu.Unlock()
+	return nil
+}
+
+// Load implements ConversationStore
+func (s *MemoryConversationStore) Load(_ context.Context, id string) (*ConversationCheckpoint, error) {
+	s.mu.RLock()
+	data, ok := s.checkpoints[id]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, ErrCheckpointNotFound
+	}
+	var checkpoint ConversationCheckpoint
+	if err := json.Unmarshal(data, &checkpoint); err != nil {
+		return nil, fmt.Errorf("failed to decode checkpoint %s: %w", id, err)
+	}
+	return &checkpoint, nil
+}
+
+// List implements ConversationStore
+func (s *MemoryConversationStore) List(ctx context.Context) ([]*ConversationCheckpoint, error) {
+	s.mu.RLock()
+	ids := make([]string, 0, len(s.checkpoints))
+	for id := range s.checkpoints {
+		ids = append(ids, id)
+	}
+	s.mu.RUnlock()
+	var checkpoints []*ConversationCheckpoint
+	for _, id := range ids {
+		if checkpoint, err := s.Load(ctx, id); err == nil {
+			checkpoints = append(checkpoints, checkpoint)
+		}
+	}
+	return checkpoints, nil
+}
+
+// Delete implements ConversationStore
+func (s *MemoryConversationStore) Delete(_ context.Context, id string) error {
+	s.mu.Lock()
+	delete(s.checkpoints, id)
+	s.mu.Unlock()
+	return nil
+}
+
+// DeleteUserData implements UserDataStore
+func (s *MemoryConversationStore) DeleteUserData(ctx context.Context, userID string) error {
+	return deleteUserConversations(ctx, s, userID)
+}
+
+// RecoverConversations returns the conversations in store that did not finish,
+// most recently updated first. Completed conversations are removed from the
+// store by the agent, or kept as Completed sessions (see WithSessionStore),
+// so every returned checkpoint can be resumed by an agent of its UserID.
+//
+// Example usage:
+//
+//	checkpoints, _ := mcpagent.RecoverConversations(ctx, store)
+//	for _, cp := range checkpoints {
+//	    agent, _ := mcpagent.NewAgent(ctx, llm, configPath, mcpagent.WithAutosave(store, 5))
+//	    go agent.ResumeConversation(ctx, cp)
+//	}
+func RecoverConversations(ctx context.Context, store ConversationStore) ([]*ConversationCheckpoint, error) {
+	all, err := store.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	checkpoints := all[:0]
+	for _, checkpoint := range all {
+		if !checkpoint.Completed {
+			checkpoints = append(checkpoints, checkpoint)
+		}
+	}
+	sort.Slice(checkpoints, func(i, j int) bool {
+		return checkpoints[i].UpdatedAt.After(checkpoints[j].UpdatedAt)
+	})
+	return checkpoints, nil
+}
+
+// ResumeConversation continues a conversation from checkpoint, carrying over
+// its token usage and summaries. Further autosaves overwrite the same
+// checkpoint, which is removed (or marked Completed with WithSessionStore)
+// once the conversation completes. The checkpoint must belong to the agent's
+// user (see WithUserID).
+func (a *Agent) ResumeConversation(ctx context.Context, checkpoint *ConversationCheckpoint) (string, []llmtypes.MessageContent, error) {
+	if checkpoint == nil || len(checkpoint.Messages) == 0 {
+		return "", nil, errors.New("checkpoint has no messages to resume")
+	}
+	if checkpoint.UserID != a.UserID {
+		return "", nil, fmt.Errorf("checkpoint %s belongs to another user", checkpoint.ID)
+	}
+	a.adoptConversation(checkpoint)
+	return a.AskWithHistory(ctx, checkpoint.Messages)
+}
+
+// CheckpointRetainedHistory saves the history of the agent's last completed
+// conversation (see RetainedHistory) to store under the autosave checkpoint
+// ID, so it shows up in RecoverConversations. The turn and error of an
+// existing checkpoint under that ID, left by a failed conversation, are kept.
+// Returns the checkpoint ID, or "" if there is no retained history.
+func (a *Agent) CheckpointRetainedHistory(ctx context.Context, store ConversationStore) (string, error) {
+	history := a.RetainedHistory()
+	if len(history) == 0 {
+		return "", nil
+	}
+	saveCtx := context.WithoutCancel(ctx)
+	checkpoint := a.conversationRecord(a.autosaveCheckpointID(ctx), history)
+	checkpoint.Question = lastUserText(history)
+	if previous, err := store.Load(saveCtx, checkpoint.ID); err == nil {
+		checkpoint.Turn, checkpoint.LastError = previous.Turn, previous.LastError
+	}
+	if err := store.Save(saveCtx, checkpoint); err != nil {
+		return "", err
+	}
+	return checkpoint.ID, nil
+}
+
+// autosaveCheckpointID returns the ID under which the conversation of ctx is
+// checkpointed, fixed on its first save. Outside a conversation it returns
+// the ID of the latest one.
+func (a *Agent) autosaveCheckpointID(ctx context.Context) string {
+	call, inCall := ctx.Value(callStateKey{}).(*callState)
+	a.checkpointMu.Lock()
+	defer a.checkpointMu.Unlock()
+	if inCall && call.checkpointID != "" {
+		return call.checkpointID
+	}
+	if !inCall && a.lastCheckpointID != "" {
+		return a.lastCheckpointID
+	}
+	id := a.newCheckpointIDLocked()
+	if inCall {
+		call.checkpointID = id
+	}
+	a.lastCheckpointID = id
+	return id
+}
+
+// newCheckpointIDLocked returns the checkpoint ID of a new conversation: the
+// resumed record's ID, else the session ID when sessions are stored (so the
+// checkpoint and the session are one record), else the agent's trace ID,
+// numbered from the second conversation on so concurrent conversations of
+// one agent do not overwrite each other's checkpoint. a.checkpointMu is held.
+func (a *Agent) newCheckpointIDLocked() string {
+	switch {
+	case a.autosaveID != "":
+		return a.autosaveID
+	case a.SessionStore != nil:
+		return userConversationID(a.UserID, a.ConversationSessionID())
+	}
+	a.checkpointSeq++
+	id := string(a.TraceID)
+	if a.checkpointSeq > 1 {
+		id = fmt.Sprintf("%s-%d", id, a.checkpointSeq)
+	}
+	return userConversationID(a.UserID, id)
+}
+
+// autosave checkpoints messages after turnsCompleted turns when autosave is
+// enabled and the interval has elapsed. Failures are logged, never returned:
+// autosave must not break the conversation it protects.
+func (a *Agent) autosave(ctx context.Context, messages []llmtypes.MessageContent, turnsCompleted int, question string) {
+	if a.AutosaveStore == nil || turnsCompleted == 0 || turnsCompleted%a.autosaveEveryTurns() != 0 {
+		return
+	}
+	a.saveCheckpoint(ctx, messages, turnsCompleted, question, nil)
+}
+
+// finishAutosave removes the checkpoint of a completed conversation, or
+// records the final state and error of a failed one so it can be resumed.
+func (a *Agent) finishAutosave(ctx context.Context, messages []llmtypes.MessageContent, err error) {
+	if a.AutosaveStore == nil {
+		return
+	}
+	if err == nil {
+		if delErr := a.AutosaveStore.Delete(context.WithoutCancel(ctx), a.autosaveCheckpointID(ctx)); delErr != nil {
+			a.Logger.Warn("Failed to delete conversation checkpoint", loggerv2.Error(delErr))
+		}
+		return
+	}
+	if len(messages) > 0 {
+		a.saveCheckpoint(ctx, messages, -1, "", err)
+	}
+}
+
+func (a *Agent) saveCheckpoint(ctx context.Context, messages []llmtypes.MessageContent, turnsCompleted int, question string, convErr error) {
+	// Save even if the conversation context was canceled: that is exactly
+	// when the checkpoint matters most.
+	saveCtx := context.WithoutCancel(ctx)
+	id := a.autosaveCheckpointID(ctx)
+
+	checkpoint := a.conversationRecord(id, messages)
+	checkpoint.Question = question
+	checkpoint.Turn = turnsCompleted
+	if convErr != nil {
+		checkpoint.LastError = convErr.Error()
+	}
+	// Carry over fields the final save does not know
+	if turnsCompleted < 0 || question == "" {
+		if previous, err := a.AutosaveStore.Load(saveCtx, id); err == nil {
+			if turnsCompleted < 0 {
+				checkpoint.Turn = previous.Turn
+			}
+			if question == "" {
+				checkpoint.Question = previous.Question
+			}
+		} else if turnsCompleted < 0 {
+			checkpoint.Turn = 0
+		}
+	}
+
+	if err := a.AutosaveStore.Save(saveCtx, checkpoint); err != nil {
+		a.Logger.Warn("Failed to save conversation checkpoint", loggerv2.String("checkpoint_id", id), loggerv2.Error(err))
+	}
+}
+
+func (a *Agent) autosaveEveryTurns() int {
+	if a.AutosaveEveryTurns <= 0 {
+		return 1
+	}
+	return a.AutosaveEveryTurns
+}
@@ -0,0 +1,149 @@
+package mcpagent
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+func TestFileConversationStoreRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewFileConversationStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checkpoint := &ConversationCheckpoint{
+		ID:   "trace-1",
+		Turn: 3,
+		Messages: []llmtypes.MessageContent{
+			llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "open example.com"),
+			{Role: llmtypes.ChatMessageTypeAI, Parts: []llmtypes.ContentPart{llmtypes.ToolCall{
+				ID: "call_1", Type: "function",
+				FunctionCall: &llmtypes.FunctionCall{Name: "browser_navigate", Arguments: `{"url":"https://example.com"}`},
+			}}},
+			{Role: llmtypes.ChatMessageTypeTool, Parts: []llmtypes.ContentPart{llmtypes.ToolCallResponse{
+				ToolCallID: "call_1", Name: "browser_navigate", Content: "ok",
+			}}},
+		},
+		UpdatedAt: time.Now(),
+	}
+	if err := store.Save(ctx, checkpoint); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	loaded, err := store.Load(ctx, "trace-1")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if loaded.Turn != 3 || len(loaded.Messages) != 3 {
+		t.Fatalf("unexpected checkpoint: %+v", loaded)
+	}
+	if tc, ok := loaded.Messages[1].Parts[0].(llmtypes.ToolCall); !ok || tc.FunctionCall.Name != "browser_navigate" {
+		t.Errorf("tool call part not restored: %#v", loaded.Messages[1].Parts[0])
+	}
+
+	if err := store.Delete(ctx, "trace-1"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := store.Load(ctx, "trace-1"); !errors.Is(err, ErrCheckpointNotFound) {
+		t.Errorf("Load after delete = %v, want ErrCheckpointNotFound", err)
+	}
+	if err := store.Save(ctx, &ConversationCheckpoint{ID: "../escape"}); err == nil {
+		t.Error("expected invalid checkpoint id to be rejected")
+	}
+}
+
+func TestAgentAutosaveLifecycle(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewFileConversationStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := &Agent{AutosaveStore: store, AutosaveEveryTurns: 2, TraceID: "trace-2", ModelID: "model"}
+	messages := []llmtypes.MessageContent{llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "run the crawl")}
+
+	a.autosave(ctx, messages, 1, "run the crawl")
+	if checkpoints, _ := RecoverConversations(ctx, store); len(checkpoints) != 0 {
+		t.Fatalf("turn 1 should not be checkpointed with interval 2, got %d", len(checkpoints))
+	}
+
+	a.autosave(ctx, messages, 2, "run the crawl")
+	checkpoints, err := RecoverConversations(ctx, store)
+	if err != nil || len(checkpoints) != 1 || checkpoints[0].ID != "trace-2" || checkpoints[0].Turn != 2 {
+		t.Fatalf("expected checkpoint after turn 2, got %+v, %v", checkpoints, err)
+	}
+
+	a.finishAutosave(ctx, messages, errors.New("provider unavailable"))
+	checkpoint, err := store.Load(ctx, "trace-2")
+	if err != nil || checkpoint.LastError != "provider unavailable" || checkpoint.Turn != 2 || checkpoint.Question != "run the crawl" {
+		t.Fatalf("failed conversation should keep a resumable checkpoint, got %+v, %v", checkpoint, err)
+	}
+
+	a.finishAutosave(ctx, messages, nil)
+	if checkpoints, _ := RecoverConversations(ctx, store); len(checkpoints) != 0 {
+		t.Errorf("completed conversation should be removed, got %d checkpoints", len(checkpoints))
+	}
+}
+
+func TestConcurrentConversationsKeepSeparateCheckpoints(t *testing.T) {
+	store := NewMemoryConversationStore()
+	a := &Agent{AutosaveStore: store, TraceID: "trace-4", ModelID: "model"}
+	first, firstCall := a.beginCall(context.Background(), nil)
+	second, secondCall := a.beginCall(context.Background(), nil)
+	defer a.endCall(firstCall)
+	defer a.endCall(secondCall)
+
+	a.autosave(first, []llmtypes.MessageContent{llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "crawl the site")}, 1, "crawl the site")
+	a.autosave(second, []llmtypes.MessageContent{llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "summarize the report")}, 1, "summarize the report")
+	a.autosave(first, []llmtypes.MessageContent{llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "crawl the site")}, 2, "crawl the site")
+
+	checkpoints, err := RecoverConversations(context.Background(), store)
+	if err != nil || len(checkpoints) != 2 {
+		t.Fatalf("expected one checkpoint per conversation, got %+v, %v", checkpoints, err)
+	}
+	if id := a.autosaveCheckpointID(first); id != "trace-4" {
+		t.Errorf("first conversation checkpoint = %q", id)
+	}
+	if checkpoint, err := store.Load(context.Background(), "trace-4-2"); err != nil || checkpoint.Question != "summarize the report" {
+		t.Errorf("second conversation checkpoint = %+v, %v", checkpoint, err)
+	}
+
+	a.finishAutosave(second, nil, nil)
+	if checkpoint, err := store.Load(context.Background(), "trace-4"); err != nil || checkpoint.Turn != 2 {
+		t.Errorf("finishing one conversation touched the other's checkpoint: %+v, %v", checkpoint, err)
+	}
+}
+
+func TestCheckpointRetainedHistoryKeepsAutosaveProgress(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewFileConversationStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := &Agent{AutosaveStore: store, TraceID: "trace-3", ModelID: "model"}
+	if id, err := a.CheckpointRetainedHistory(ctx, store); err != nil || id != "" {
+		t.Fatalf("empty history should not be checkpointed, got %q, %v", id, err)
+	}
+
+	messages := []llmtypes.MessageContent{
+		llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "summarize the report"),
+		llmtypes.TextParts(llmtypes.ChatMessageTypeAI, "Reading the report"),
+	}
+	a.autosave(ctx, messages, 1, "summarize the report")
+	a.finishAutosave(ctx, messages, errors.New("context canceled"))
+	a.retainHistory(messages)
+
+	id, err := a.CheckpointRetainedHistory(ctx, store)
+	if err != nil || id != "trace-3" {
+		t.Fatalf("CheckpointRetainedHistory = %q, %v", id, err)
+	}
+	checkpoint, err := store.Load(ctx, id)
+	if err != nil || len(checkpoint.Messages) != 2 || checkpoint.Question != "summarize the report" ||
+		checkpoint.Turn != 1 || checkpoint.LastError != "context canceled" {
+		t.Fatalf("unexpected checkpoint: %+v, %v", checkpoint, err)
+	}
+}
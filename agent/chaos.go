@@ -0,0 +1,257 @@
+// chaos.go
+//
+// This file provides chaos-testing hooks. An agent created WithChaos injects
+// controlled failures into its own runs — random tool latency, dropped MCP
+// connections, malformed LLM tool calls and provider 429s — so retry,
+// fallback, broken-pipe recovery and summarization behavior can be validated
+// under realistic failure conditions. Every decision is drawn from a seeded
+// random source, so the same seed and the same sequence of calls reproduce
+// the same failure schedule. Chaos is disabled unless configured.
+//
+// Exported:
+//   - ChaosConfig: Failure rates, latency bounds and seed
+//   - WithChaos: Enable chaos injection when creating an agent
+//   - ChaosStats / Agent.ChaosStats: Counts of injected failures
+
+package mcpagent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+	"github.com/manishiitg/mcpagent/mcpclient"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Injected errors. Their messages match what the recovery paths look for: a
+// dropped connection reads as a broken pipe, a throttle as a provider 429.
+var (
+	errChaosConnectionDropped = errors.New("chaos: injected MCP connection drop: broken pipe")
+	errChaosProviderThrottled = errors.New("chaos: injected provider error: status code: 429 Too Many Requests")
+)
+
+// ChaosConfig configures failure injection. Rates are probabilities in [0, 1]
+// evaluated per tool call or per LLM call; a zero rate disables that failure.
+type ChaosConfig struct {
+	// Seed makes the failure schedule reproducible. 0 uses the current time.
+	Seed int64
+	// ToolLatencyRate is the probability a tool call is delayed by a random
+	// duration in [ToolLatencyMin, ToolLatencyMax] before it runs
+	ToolLatencyRate float64
+	ToolLatencyMin  time.Duration
+	ToolLatencyMax  time.Duration
+	// DropConnectionRate is the probability an MCP tool call fails as if the
+	// server connection dropped (exercises broken-pipe recovery)
+	DropConnectionRate float64
+	// MalformedToolCallRate is the probability the arguments of a tool call
+	// returned by the LLM are corrupted into invalid JSON
+	MalformedToolCallRate float64
+	// ProviderThrottleRate is the probability an LLM call fails with a 429
+	// before reaching the provider (exercises retry and fallback)
+	ProviderThrottleRate float64
+}
+
+// ChaosStats counts the failures injected so far
+type ChaosStats struct {
+	ToolDelays         int
+	DroppedConnections int
+	MalformedToolCalls int
+	ProviderThrottles  int
+}
+
+// chaosMonkey draws failure decisions from a seeded source
+type chaosMonkey struct {
+	config ChaosConfig
+	mu     sync.Mutex
+	rng    *rand.Rand
+	stats  ChaosStats
+}
+
+// WithChaos injects failures per config into tool calls and LLM calls. Use it
+// in tests and staging to validate resilience; never in production.
+//
+// Example:
+//
+//	mcpagent.WithChaos(mcpagent.ChaosConfig{
+//	    Seed:                 42,
+//	    ToolLatencyRate:      0.3,
+//	    ToolLatencyMax:       5 * time.Second,
+//	    DropConnectionRate:   0.1,
+//	    ProviderThrottleRate: 0.2,
+//	})
+//
+// Default: disabled
+func WithChaos(config ChaosConfig) AgentOption {
+	return func(a *Agent) {
+		seed := config.Seed
+		if seed == 0 {
+			seed = time.Now().UnixNano()
+		}
+		if config.ToolLatencyMax < config.ToolLatencyMin {
+			config.ToolLatencyMax = config.ToolLatencyMin
+		}
+		a.chaos = &chaosMonkey{
+			config: config,
+			rng:    rand.New(rand.NewSource(seed)), //nolint:gosec // deterministic schedule, not security
+		}
+	}
+}
+
+// ChaosStats returns the failures injected so far; zero when chaos is disabled
+func (a *Agent) ChaosStats() ChaosStats {
+	if a.chaos == nil {
+		return ChaosStats{}
+	}
+	a.chaos.mu.Lock()
+	defer a.chaos.mu.Unlock()
+	return a.chaos.stats
+}
+
+// roll reports whether an event with probability rate happens
+func (c *chaosMonkey) roll(rate float64) bool {
+	if rate <= 0 {
+		return false
+	}
+	return c.rng.Float64() < rate
+}
+
+// toolDelay returns the latency to inject before a tool call, or 0
+func (c *chaosMonkey) toolDelay() time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.roll(c.config.ToolLatencyRate) {
+		return 0
+	}
+	c.stats.ToolDelays++
+	delay := c.config.ToolLatencyMin
+	if span := c.config.ToolLatencyMax - c.config.ToolLatencyMin; span > 0 {
+		delay += time.Duration(c.rng.Int63n(int64(span) + 1))
+	}
+	return delay
+}
+
+func (c *chaosMonkey) dropConnection() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.roll(c.config.DropConnectionRate) {
+		return false
+	}
+	c.stats.DroppedConnections++
+	return true
+}
+
+func (c *chaosMonkey) throttleProvider() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.roll(c.config.ProviderThrottleRate) {
+		return false
+	}
+	c.stats.ProviderThrottles++
+	return true
+}
+
+// corruptToolCalls truncates the JSON arguments of tool calls picked by the schedule
+func (c *chaosMonkey) corruptToolCalls(resp *llmtypes.ContentResponse) int {
+	if resp == nil || c.config.MalformedToolCallRate <= 0 {
+		return 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	corrupted := 0
+	for _, choice := range resp.Choices {
+		if choice == nil {
+			continue
+		}
+		for i := range choice.ToolCalls {
+			call := &choice.ToolCalls[i]
+			if call.FunctionCall == nil || !c.roll(c.config.MalformedToolCallRate) {
+				continue
+			}
+			args := call.FunctionCall.Arguments
+			if len(args) > 1 {
+				args = args[:len(args)/2]
+			}
+			call.FunctionCall.Arguments = args + `{"`
+			corrupted++
+		}
+	}
+	c.stats.MalformedToolCalls += corrupted
+	return corrupted
+}
+
+// callMCPTool calls an MCP tool through callToolWithTimeoutWrapper, first
+// injecting the latency or connection drop scheduled by chaos, if enabled.
+// With a tool result cache, repeated calls are answered from the cache
+// without reaching the server (see tool_result_cache.go).
+func (a *Agent) callMCPTool(
+	ctx context.Context,
+	client mcpclient.ClientInterface,
+	toolName string,
+	args map[string]interface{},
+	logger loggerv2.Logger,
+	serverName string,
+) (*mcp.CallToolResult, error) {
+	cacheable := a.toolResultCache != nil && a.cachesToolResult(ctx, client, serverName, toolName)
+	if cacheable {
+		if cached, ok := a.toolResultCache.Get(a.UserID, serverName, toolName, args); ok {
+			logger.Debug("🔧 [TOOL_CALL] Tool result served from cache",
+				loggerv2.String("tool_name", toolName),
+				loggerv2.String("server_name", serverName))
+			markToolResultCacheHit(ctx)
+			return cached, nil
+		}
+	}
+	ctx = a.withMCPSampling(ctx, serverName)
+	if a.chaos != nil {
+		if delay := a.chaos.toolDelay(); delay > 0 {
+			logger.Warn("🐒 [CHAOS] Delaying tool call",
+				loggerv2.String("tool_name", toolName),
+				loggerv2.String("delay", delay.String()))
+			timer := time.NewTimer(delay)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				return nil, ctx.Err()
+			}
+		}
+		if a.chaos.dropConnection() {
+			logger.Warn("🐒 [CHAOS] Dropping MCP connection for tool call",
+				loggerv2.String("tool_name", toolName),
+				loggerv2.String("server_name", serverName))
+			return nil, fmt.Errorf("tool %s: %w", toolName, errChaosConnectionDropped)
+		}
+	}
+	result, err := callToolWithTimeoutWrapper(ctx, client, toolName, args, logger, serverName)
+	if err == nil && cacheable {
+		a.toolResultCache.Put(a.UserID, serverName, toolName, args, result)
+	}
+	return result, err
+}
+
+// chaosBeforeLLM returns the provider error chaos schedules for this LLM call, if any
+func (a *Agent) chaosBeforeLLM(model LLMModel) error {
+	if a.chaos == nil || !a.chaos.throttleProvider() {
+		return nil
+	}
+	getLogger(a).Warn("🐒 [CHAOS] Throttling LLM call",
+		loggerv2.String("provider", model.Provider),
+		loggerv2.String("model", model.ModelID))
+	return errChaosProviderThrottled
+}
+
+// chaosAfterLLM corrupts tool calls in resp as chaos schedules
+func (a *Agent) chaosAfterLLM(resp *llmtypes.ContentResponse) {
+	if a.chaos == nil {
+		return
+	}
+	if n := a.chaos.corruptToolCalls(resp); n > 0 {
+		getLogger(a).Warn("🐒 [CHAOS] Corrupted tool call arguments", loggerv2.Int("tool_calls", n))
+	}
+}
@@ -0,0 +1,81 @@
+package mcpagent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+func TestChaosScheduleIsReproducible(t *testing.T) {
+	schedule := func() []bool {
+		a := &Agent{}
+		WithChaos(ChaosConfig{Seed: 7, DropConnectionRate: 0.5})(a)
+		drops := make([]bool, 32)
+		for i := range drops {
+			drops[i] = a.chaos.dropConnection()
+		}
+		return drops
+	}
+	first, second := schedule(), schedule()
+	dropped := 0
+	for i := range first {
+		if first[i] != second[i] {
+			t.Fatalf("schedules diverge at call %d", i)
+		}
+		if first[i] {
+			dropped++
+		}
+	}
+	if dropped == 0 || dropped == len(first) {
+		t.Errorf("expected a mix of drops at rate 0.5, got %d/%d", dropped, len(first))
+	}
+}
+
+func TestChaosInjectedFailuresMatchRecoveryPaths(t *testing.T) {
+	a := &Agent{Logger: loggerv2.NewNoop()}
+	WithChaos(ChaosConfig{Seed: 1, DropConnectionRate: 1, ProviderThrottleRate: 1, MalformedToolCallRate: 1})(a)
+
+	_, err := a.callMCPTool(context.Background(), nil, "read_file", nil, a.Logger, "fs")
+	if !IsBrokenPipeError(err) {
+		t.Errorf("dropped connection should look like a broken pipe, got %v", err)
+	}
+
+	if err := a.chaosBeforeLLM(LLMModel{Provider: "openai", ModelID: "gpt-4.1"}); !isThrottlingError(err) {
+		t.Errorf("provider failure should be classified as throttling, got %v", err)
+	}
+
+	resp := &llmtypes.ContentResponse{Choices: []*llmtypes.ContentChoice{{ToolCalls: []llmtypes.ToolCall{
+		{ID: "1", FunctionCall: &llmtypes.FunctionCall{Name: "read_file", Arguments: `{"path":"a.txt"}`}},
+	}}}}
+	a.chaosAfterLLM(resp)
+	if json.Valid([]byte(resp.Choices[0].ToolCalls[0].FunctionCall.Arguments)) {
+		t.Errorf("expected corrupted arguments, got %s", resp.Choices[0].ToolCalls[0].FunctionCall.Arguments)
+	}
+
+	stats := a.ChaosStats()
+	if stats.DroppedConnections != 1 || stats.ProviderThrottles != 1 || stats.MalformedToolCalls != 1 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
+
+func TestChaosToolLatencyRespectsContext(t *testing.T) {
+	a := &Agent{Logger: loggerv2.NewNoop()}
+	WithChaos(ChaosConfig{Seed: 1, ToolLatencyRate: 1, ToolLatencyMin: time.Hour})(a)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := a.callMCPTool(ctx, nil, "slow", nil, a.Logger, "fs"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the injected delay to end with the context, got %v", err)
+	}
+	if a.ChaosStats().ToolDelays != 1 {
+		t.Errorf("expected one delay, got %+v", a.ChaosStats())
+	}
+	if (&Agent{}).ChaosStats() != (ChaosStats{}) {
+		t.Error("expected zero stats without chaos")
+	}
+}
@@ -0,0 +1,387 @@
+// code_exec_snapshot.go
+//
+// This file implements code execution snapshots: what connecting to the MCP
+// servers of one configuration returned (servers, tools, prompts, resources,
+// instructions) together with the compact get_api_spec entries of every tool.
+// A snapshot is keyed by a hash of the resolved server configs the agent
+// selects (runtime overrides and per-user OAuth paths included), so any edit
+// to those servers in mcp_servers.json yields a new snapshot.
+//
+// An agent whose configuration has a snapshot skips discovery: it takes the
+// tools from the snapshot and registers each server for on-demand connection,
+// the way a server with cached tools is connected lazily (see
+// connection_session.go). Without a snapshot the agent connects normally and
+// saves one once every selected server was discovered. Snapshots expire with
+// the MCP tool cache TTL (MCP_CACHE_TTL_MINUTES), so servers whose tools change
+// without a config edit are picked up again.
+//
+// Snapshots are written to <generated>/snapshots/code_exec_<hash>.json, so a
+// restarted server or a new worker starts from them, and the most recently
+// used ones are also kept in memory. Entries do not contain the API base URL
+// (it carries the session ID); the header is added per request.
+//
+// Exported:
+//   - CodeExecutionSnapshot: Discovery results and compact spec entries for one configuration
+//   - WithCodeExecutionSnapshot: Build / reuse snapshots in code execution mode
+
+package mcpagent
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+	"github.com/mark3labs/mcp-go/mcp"
+
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+	"github.com/manishiitg/mcpagent/mcpcache"
+	"github.com/manishiitg/mcpagent/mcpcache/openapi"
+	"github.com/manishiitg/mcpagent/mcpclient"
+)
+
+// maxCodeExecSnapshots bounds the snapshots kept in memory; the least recently
+// used one is dropped first and read from disk again when needed
+const maxCodeExecSnapshots = 32
+
+// CodeExecutionSnapshot holds what discovering the MCP servers of one
+// configuration returned and the compact spec entries of their tools
+type CodeExecutionSnapshot struct {
+	// Hash is the hash of the resolved server configs (see codeExecutionConfigHash)
+	Hash      string    `json:"hash"`
+	CreatedAt time.Time `json:"created_at"`
+
+	Servers      []string                  `json:"servers"`
+	Tools        []llmtypes.Tool           `json:"tools"`
+	ToolToServer map[string]string         `json:"tool_to_server"`
+	Prompts      map[string][]mcp.Prompt   `json:"prompts,omitempty"`
+	Resources    map[string][]mcp.Resource `json:"resources,omitempty"`
+	Instructions map[string]string         `json:"instructions,omitempty"`
+
+	// Entries maps server name (hyphens as underscores) -> tool name -> entry
+	Entries map[string]map[string]string `json:"entries"`
+}
+
+// ToolCount returns the number of tools in the snapshot
+func (s *CodeExecutionSnapshot) ToolCount() int {
+	n := 0
+	for _, tools := range s.Entries {
+		n += len(tools)
+	}
+	return n
+}
+
+// spec assembles the compact spec of tools on serverName, reporting false when
+// any tool is missing from the snapshot
+func (s *CodeExecutionSnapshot) spec(serverName string, tools []llmtypes.Tool, baseURL string) (string, bool) {
+	entries := s.Entries[serverName]
+	var sb strings.Builder
+	sb.WriteString(openapi.CompactSpecHeader(baseURL))
+	for _, tool := range tools {
+		if tool.Function == nil {
+			continue
+		}
+		entry, ok := entries[tool.Function.Name]
+		if !ok {
+			return "", false
+		}
+		sb.WriteString(entry)
+	}
+	return sb.String(), true
+}
+
+// codeExecSnapshots caches the most recently used snapshots by hash for the process
+var codeExecSnapshots = struct {
+	sync.Mutex
+	byHash map[string]*CodeExecutionSnapshot
+	order  []string // least recently used first
+}{byHash: make(map[string]*CodeExecutionSnapshot)}
+
+// cachedCodeExecutionSnapshot returns the in-memory snapshot for hash
+func cachedCodeExecutionSnapshot(hash string) (*CodeExecutionSnapshot, bool) {
+	codeExecSnapshots.Lock()
+	defer codeExecSnapshots.Unlock()
+	snapshot, ok := codeExecSnapshots.byHash[hash]
+	if ok {
+		touchCodeExecutionSnapshot(hash)
+	}
+	return snapshot, ok
+}
+
+// cacheCodeExecutionSnapshot keeps snapshot in memory, evicting the least
+// recently used snapshot beyond maxCodeExecSnapshots. When another agent cached
+// a snapshot for the same hash first, that one is returned.
+func cacheCodeExecutionSnapshot(snapshot *CodeExecutionSnapshot) *CodeExecutionSnapshot {
+	codeExecSnapshots.Lock()
+	defer codeExecSnapshots.Unlock()
+	if existing, ok := codeExecSnapshots.byHash[snapshot.Hash]; ok && !existing.CreatedAt.Before(snapshot.CreatedAt) {
+		touchCodeExecutionSnapshot(snapshot.Hash)
+		return existing
+	}
+	codeExecSnapshots.byHash[snapshot.Hash] = snapshot
+	touchCodeExecutionSnapshot(snapshot.Hash)
+	for len(codeExecSnapshots.order) > maxCodeExecSnapshots {
+		delete(codeExecSnapshots.byHash, codeExecSnapshots.order[0])
+		codeExecSnapshots.order = codeExecSnapshots.order[1:]
+	}
+	return snapshot
+}
+
+// touchCodeExecutionSnapshot marks hash as most recently used; the caller holds the lock
+func touchCodeExecutionSnapshot(hash string) {
+	codeExecSnapshots.order = slices.DeleteFunc(codeExecSnapshots.order, func(h string) bool { return h == hash })
+	codeExecSnapshots.order = append(codeExecSnapshots.order, hash)
+}
+
+// WithCodeExecutionSnapshot records the MCP tool discovery of a configuration
+// and the rendered get_api_spec entries of its tools once, and lets every later
+// agent with the same configuration start from them without connecting to the
+// servers, in this process and, through dir, across restarts. dir = "" uses
+// <generated>/snapshots (see MCP_GENERATED_DIR). Only applies in code
+// execution mode; WithDisableCache skips the lookup but still refreshes the
+// snapshot.
+//
+// Default: disabled (servers are discovered and specs rendered per agent)
+func WithCodeExecutionSnapshot(dir string) AgentOption {
+	return func(a *Agent) {
+		a.codeExecSnapshotEnabled = true
+		a.codeExecSnapshotDir = dir
+	}
+}
+
+// findCodeExecutionSnapshot returns the unexpired snapshot of the MCP server
+// configs serverName selects, from memory or disk. Without one it returns nil
+// and remembers the hash and servers, so the snapshot is saved once discovery
+// finished (see loadCodeExecutionSnapshot).
+func (a *Agent) findCodeExecutionSnapshot(config *mcpclient.MCPConfig, serverName string) *CodeExecutionSnapshot {
+	if !a.codeExecSnapshotEnabled || !(a.UseCodeExecutionMode || isCodingCLIBridgeProvider(a.provider, a.ModelID)) {
+		return nil
+	}
+	servers := sessionServers(config, serverName)
+	configs := make(map[string]mcpclient.MCPServerConfig, len(servers))
+	for _, srvName := range servers {
+		if serverConfig, err := sessionServerConfig(config, srvName, a.RuntimeOverrides, a.UserID); err == nil {
+			configs[srvName] = serverConfig
+		}
+	}
+	hash, err := codeExecutionConfigHash(servers, configs)
+	if err != nil {
+		a.Logger.Warn("Failed to hash MCP server configs for the code execution snapshot", loggerv2.Error(err))
+		return nil
+	}
+	a.codeExecSnapshotHash, a.codeExecSnapshotServers = hash, servers
+	if a.DisableCache {
+		return nil
+	}
+
+	source := "memory"
+	snapshot, ok := cachedCodeExecutionSnapshot(hash)
+	if !ok {
+		source = "disk"
+		snapshot, err = readCodeExecutionSnapshot(a.codeExecutionSnapshotPath(hash), hash)
+		if err != nil {
+			return nil
+		}
+	}
+	ttl := time.Duration(mcpcache.GetCacheManager(a.Logger).GetTTL()) * time.Minute
+	if ttl > 0 && time.Since(snapshot.CreatedAt) > ttl {
+		a.Logger.Info("Code execution snapshot expired, discovering MCP servers",
+			loggerv2.String("hash", hash[:12]),
+			loggerv2.Any("age", time.Since(snapshot.CreatedAt)))
+		return nil
+	}
+	if source == "disk" {
+		snapshot = cacheCodeExecutionSnapshot(snapshot)
+	}
+
+	// Servers connect on first use, like servers whose tools are cached
+	registry := mcpclient.GetSessionRegistry()
+	for _, srvName := range snapshot.Servers {
+		if serverConfig, ok := configs[srvName]; ok {
+			registry.StoreServerConfig(a.SessionID, srvName, serverConfig)
+		}
+	}
+	a.codeExecSnapshot = snapshot
+	a.Logger.Info("Code execution snapshot found, skipping MCP server discovery",
+		loggerv2.String("hash", hash[:12]),
+		loggerv2.String("source", source),
+		loggerv2.Int("servers", len(snapshot.Servers)),
+		loggerv2.Int("tools", len(snapshot.Tools)))
+	return snapshot
+}
+
+// discovery returns copies of the snapshot's discovery results in the shape
+// NewAgentConnectionWithSession returns them, with no connected clients
+func (s *CodeExecutionSnapshot) discovery() (map[string]mcpclient.ClientInterface, map[string]string, []llmtypes.Tool, []string, map[string][]mcp.Prompt, map[string][]mcp.Resource, map[string]string) {
+	toolToServer := maps.Clone(s.ToolToServer)
+	if toolToServer == nil {
+		toolToServer = make(map[string]string)
+	}
+	prompts := maps.Clone(s.Prompts)
+	if prompts == nil {
+		prompts = make(map[string][]mcp.Prompt)
+	}
+	resources := maps.Clone(s.Resources)
+	if resources == nil {
+		resources = make(map[string][]mcp.Resource)
+	}
+	instructions := maps.Clone(s.Instructions)
+	if instructions == nil {
+		instructions = make(map[string]string)
+	}
+	return make(map[string]mcpclient.ClientInterface), toolToServer, slices.Clone(s.Tools), slices.Clone(s.Servers), prompts, resources, instructions
+}
+
+// loadCodeExecutionSnapshot builds and saves the snapshot of a configuration
+// that had none, once every selected server was discovered
+func (a *Agent) loadCodeExecutionSnapshot() {
+	if a.codeExecSnapshot != nil || a.codeExecSnapshotHash == "" {
+		return
+	}
+	for _, srvName := range a.codeExecSnapshotServers {
+		if !slices.Contains(a.servers, srvName) {
+			a.Logger.Info("Not saving code execution snapshot, MCP server was not discovered",
+				loggerv2.String("server", srvName))
+			return
+		}
+	}
+	start := time.Now()
+	snapshot := buildCodeExecutionSnapshot(a.codeExecSnapshotHash, a)
+	path := a.codeExecutionSnapshotPath(snapshot.Hash)
+	if err := writeCodeExecutionSnapshot(path, snapshot); err != nil {
+		a.Logger.Warn("Failed to save code execution snapshot", loggerv2.String("path", path), loggerv2.Error(err))
+	}
+	a.codeExecSnapshot = cacheCodeExecutionSnapshot(snapshot)
+
+	a.Logger.Info("Code execution snapshot saved",
+		loggerv2.String("hash", snapshot.Hash[:12]),
+		loggerv2.Int("tools", snapshot.ToolCount()),
+		loggerv2.Any("duration", time.Since(start)))
+}
+
+// codeExecutionSnapshotPath returns where the snapshot for hash is saved
+func (a *Agent) codeExecutionSnapshotPath(hash string) string {
+	dir := a.codeExecSnapshotDir
+	if dir == "" {
+		dir = filepath.Join(a.getGeneratedDir(), "snapshots")
+	}
+	return filepath.Join(dir, "code_exec_"+hash+".json")
+}
+
+// compactSpec renders the compact spec of MCP tools on serverName, from the
+// code execution snapshot when it has every tool
+func (a *Agent) compactSpec(serverName string, tools []llmtypes.Tool, baseURL string) string {
+	if a.codeExecSnapshot != nil {
+		if spec, ok := a.codeExecSnapshot.spec(serverName, tools, baseURL); ok {
+			return spec
+		}
+	}
+	return openapi.GenerateCompactSpec(serverName, tools, baseURL)
+}
+
+// codeExecutionConfigHash hashes the selected servers, in order, with their
+// resolved configs; servers missing from configs hash as absent
+func codeExecutionConfigHash(servers []string, configs map[string]mcpclient.MCPServerConfig) (string, error) {
+	type serverKey struct {
+		Name     string `json:"name"`
+		CacheKey string `json:"cache_key,omitempty"`
+	}
+	keys := make([]serverKey, 0, len(servers))
+	for _, srvName := range servers {
+		key := serverKey{Name: srvName}
+		if serverConfig, ok := configs[srvName]; ok {
+			key.CacheKey = mcpcache.GenerateUnifiedCacheKey(srvName, serverConfig)
+		}
+		keys = append(keys, key)
+	}
+	data, err := json.Marshal(keys)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// buildCodeExecutionSnapshot records the agent's discovered MCP servers and
+// renders the compact entry of every MCP tool
+func buildCodeExecutionSnapshot(hash string, a *Agent) *CodeExecutionSnapshot {
+	snapshot := &CodeExecutionSnapshot{
+		Hash:         hash,
+		CreatedAt:    time.Now(),
+		Servers:      slices.Clone(a.servers),
+		Tools:        slices.Clone(a.allMCPToolDefs),
+		ToolToServer: make(map[string]string, len(a.allMCPToolDefs)),
+		Prompts:      maps.Clone(a.prompts),
+		Resources:    maps.Clone(a.resources),
+		Instructions: maps.Clone(a.serverInstructions),
+		Entries:      make(map[string]map[string]string),
+	}
+	for _, tool := range a.allMCPToolDefs {
+		if tool.Function == nil {
+			continue
+		}
+		serverName := a.toolToServer[tool.Function.Name]
+		snapshot.ToolToServer[tool.Function.Name] = serverName
+		server := snapshotServerName(serverName)
+		if snapshot.Entries[server] == nil {
+			snapshot.Entries[server] = make(map[string]string)
+		}
+		snapshot.Entries[server][tool.Function.Name] = openapi.CompactToolEntry(server, tool)
+	}
+	return snapshot
+}
+
+// snapshotServerName normalizes a server name the way get_api_spec does
+func snapshotServerName(serverName string) string {
+	return strings.ReplaceAll(serverName, "-", "_")
+}
+
+// readCodeExecutionSnapshot loads a saved snapshot, rejecting one whose hash
+// does not match
+func readCodeExecutionSnapshot(path, hash string) (*CodeExecutionSnapshot, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // path is built from the snapshot directory and a hex hash
+	if err != nil {
+		return nil, err
+	}
+	var snapshot CodeExecutionSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("invalid code execution snapshot %s: %w", path, err)
+	}
+	if snapshot.Hash != hash {
+		return nil, fmt.Errorf("code execution snapshot %s has hash %s, want %s", path, snapshot.Hash, hash)
+	}
+	return &snapshot, nil
+}
+
+// writeCodeExecutionSnapshot saves a snapshot atomically, so concurrent
+// agents never read a partial file
+func writeCodeExecutionSnapshot(path string, snapshot *CodeExecutionSnapshot) error {
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil { //nolint:gosec // 0755 permissions are intentional for generated artifact directories
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".code_exec_*.tmp")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
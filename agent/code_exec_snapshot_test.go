@@ -0,0 +1,178 @@
+package mcpagent
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+	"github.com/manishiitg/mcpagent/mcpcache/openapi"
+	"github.com/manishiitg/mcpagent/mcpclient"
+)
+
+var snapshotTestTools = []llmtypes.Tool{
+	{Type: "function", Function: &llmtypes.FunctionDefinition{
+		Name:        "search_issues",
+		Description: "Search issues",
+		Parameters: llmtypes.NewParameters(map[string]interface{}{
+			"type":       "object",
+			"properties": map[string]interface{}{"query": map[string]interface{}{"type": "string"}},
+			"required":   []interface{}{"query"},
+		}),
+	}},
+	{Type: "function", Function: &llmtypes.FunctionDefinition{Name: "get_issue", Description: "Get one issue"}},
+}
+
+// snapshotTestAgent is an agent that discovered tools on git-hub without
+// finding a snapshot for hash
+func snapshotTestAgent(dir, hash string, tools []llmtypes.Tool) *Agent {
+	a := &Agent{
+		Logger:                  loggerv2.NewNoop(),
+		UseCodeExecutionMode:    true,
+		allMCPToolDefs:          tools,
+		servers:                 []string{"git-hub"},
+		toolToServer:            map[string]string{"search_issues": "git-hub", "get_issue": "git-hub"},
+		codeExecSnapshotHash:    hash,
+		codeExecSnapshotServers: []string{"git-hub"},
+	}
+	WithCodeExecutionSnapshot(dir)(a)
+	return a
+}
+
+// testSnapshotHash returns the config hash of a server absent from the config
+func testSnapshotHash(t *testing.T, server string) string {
+	t.Helper()
+	hash, err := codeExecutionConfigHash([]string{server}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hash
+}
+
+func TestCodeExecutionSnapshotSavedAfterDiscovery(t *testing.T) {
+	dir := t.TempDir()
+	hash := testSnapshotHash(t, "git-hub")
+	a := snapshotTestAgent(dir, hash, snapshotTestTools)
+	a.loadCodeExecutionSnapshot()
+	if a.codeExecSnapshot == nil || a.codeExecSnapshot.ToolCount() != 2 {
+		t.Fatalf("snapshot = %+v, want 2 tools", a.codeExecSnapshot)
+	}
+
+	baseURL := "http://localhost:8000/s/abc"
+	if got, want := a.compactSpec("git_hub", snapshotTestTools, baseURL), openapi.GenerateCompactSpec("git_hub", snapshotTestTools, baseURL); got != want {
+		t.Errorf("snapshot spec differs from the generated spec:\n%s\nwant:\n%s", got, want)
+	}
+
+	saved, err := readCodeExecutionSnapshot(filepath.Join(dir, "code_exec_"+hash+".json"), hash)
+	if err != nil {
+		t.Fatalf("snapshot was not saved: %v", err)
+	}
+	_, toolToServer, tools, servers, _, _, _ := saved.discovery()
+	if len(tools) != 2 || len(servers) != 1 || toolToServer["get_issue"] != "git-hub" {
+		t.Errorf("saved discovery = %v %v %v", tools, servers, toolToServer)
+	}
+
+	// A server that failed to connect would be missing from every agent using the snapshot
+	partialHash := testSnapshotHash(t, "slack")
+	partial := snapshotTestAgent(dir, partialHash, snapshotTestTools)
+	partial.codeExecSnapshotServers = []string{"git-hub", "slack"}
+	partial.loadCodeExecutionSnapshot()
+	if partial.codeExecSnapshot != nil {
+		t.Error("snapshot saved although slack was not discovered")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "code_exec_"+partialHash+".json")); !os.IsNotExist(err) {
+		t.Errorf("partial snapshot file: %v", err)
+	}
+}
+
+func TestCodeExecutionSnapshotSkipsDiscovery(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "mcp.json")
+	if err := os.WriteFile(configPath, []byte(`{"mcpServers": {"git-hub": {"command": "/nonexistent/github-mcp"}}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	config, err := mcpclient.LoadMergedConfig(configPath, loggerv2.NewNoop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	serverConfig, err := sessionServerConfig(config, "git-hub", nil, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash, err := codeExecutionConfigHash([]string{"git-hub"}, map[string]mcpclient.MCPServerConfig{"git-hub": serverConfig})
+	if err != nil {
+		t.Fatal(err)
+	}
+	snapshot := buildCodeExecutionSnapshot(hash, snapshotTestAgent(dir, hash, snapshotTestTools))
+	if err := writeCodeExecutionSnapshot(filepath.Join(dir, "code_exec_"+hash+".json"), snapshot); err != nil {
+		t.Fatal(err)
+	}
+
+	// The server cannot start, so its tools can only come from the snapshot
+	sessionID := fmt.Sprintf("snapshot-%d", time.Now().UnixNano())
+	a, err := NewAgent(context.Background(), &providerKeyCarrierModel{}, configPath,
+		WithLogger(loggerv2.NewNoop()),
+		WithSessionID(sessionID),
+		WithCodeExecutionMode(true),
+		WithCodeExecutionSnapshot(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer a.Close()
+	if a.codeExecSnapshot == nil || a.codeExecSnapshot.Hash != hash || len(a.allMCPToolDefs) != 2 {
+		t.Fatalf("agent did not start from the snapshot: %d tools", len(a.allMCPToolDefs))
+	}
+	if len(a.Clients) != 0 {
+		t.Errorf("agent connected to %d servers", len(a.Clients))
+	}
+	if _, ok := mcpclient.GetSessionRegistry().GetServerConfig(sessionID, "git-hub"); !ok {
+		t.Error("git-hub was not registered for on-demand connection")
+	}
+	a.toolToServer["my_tool"] = "custom"
+	if _, shared := a.codeExecSnapshot.ToolToServer["my_tool"]; shared {
+		t.Error("agent changed the shared snapshot")
+	}
+
+	// Without the cache the agent discovers the servers and refreshes the snapshot
+	uncached := &Agent{Logger: loggerv2.NewNoop(), UseCodeExecutionMode: true, DisableCache: true}
+	WithCodeExecutionSnapshot(dir)(uncached)
+	if uncached.findCodeExecutionSnapshot(config, "all") != nil || uncached.codeExecSnapshotHash != hash {
+		t.Error("WithDisableCache agent used the snapshot")
+	}
+}
+
+func TestCodeExecutionSnapshotCacheIsBounded(t *testing.T) {
+	for i := 0; i <= maxCodeExecSnapshots; i++ {
+		cacheCodeExecutionSnapshot(&CodeExecutionSnapshot{Hash: fmt.Sprintf("bounded-%d", i), CreatedAt: time.Now()})
+		if i == 0 {
+			continue
+		}
+		// Keep the first snapshot in use so the second is the least recently used
+		cachedCodeExecutionSnapshot("bounded-0")
+	}
+	if _, ok := cachedCodeExecutionSnapshot("bounded-0"); !ok {
+		t.Error("recently used snapshot was evicted")
+	}
+	if _, ok := cachedCodeExecutionSnapshot("bounded-1"); ok {
+		t.Error("least recently used snapshot was kept")
+	}
+	codeExecSnapshots.Lock()
+	defer codeExecSnapshots.Unlock()
+	if len(codeExecSnapshots.byHash) > maxCodeExecSnapshots {
+		t.Errorf("%d snapshots in memory, want at most %d", len(codeExecSnapshots.byHash), maxCodeExecSnapshots)
+	}
+}
+
+func TestCodeExecutionSnapshotFallsBackForUnknownTools(t *testing.T) {
+	a := snapshotTestAgent(t.TempDir(), testSnapshotHash(t, "fallback"), []llmtypes.Tool{{Type: "function", Function: &llmtypes.FunctionDefinition{Name: "get_issue"}}})
+	a.loadCodeExecutionSnapshot()
+	extra := []llmtypes.Tool{{Type: "function", Function: &llmtypes.FunctionDefinition{Name: "close_issue", Description: "Close"}}}
+	if got, want := a.compactSpec("git_hub", extra, "http://x"), openapi.GenerateCompactSpec("git_hub", extra, "http://x"); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,95 @@
+// code_execution_sandbox.go
+//
+// This file selects where code execution mode runs generated code. The
+// consumer registers execute_shell_command and calls Agent.ExecuteShellCommand
+// from it; by default commands run on the host, and with
+// WithCodeExecutionSandbox("docker") they run in a constrained container (see
+// codeexec.DockerSandbox). The container mounts the folder guard paths (write
+// paths read-write, read paths read-only) and can only reach the MCP API
+// bridge configured with WithAPIConfig.
+//
+// Exported:
+//   - CodeExecutionSandboxHost / CodeExecutionSandboxDocker: Sandbox kinds
+//   - WithCodeExecutionSandbox / WithDockerSandboxConfig: Options
+//   - Agent.ExecuteShellCommand: Run an execute_shell_command call in the configured sandbox
+
+package mcpagent
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/manishiitg/mcpagent/agent/codeexec"
+)
+
+// Code execution sandboxes accepted by WithCodeExecutionSandbox
+const (
+	CodeExecutionSandboxHost   = "host"
+	CodeExecutionSandboxDocker = "docker"
+)
+
+// WithCodeExecutionSandbox selects where Agent.ExecuteShellCommand runs
+// commands: CodeExecutionSandboxHost or CodeExecutionSandboxDocker. NewAgent
+// fails for any other value.
+//
+// Default: "host"
+func WithCodeExecutionSandbox(sandbox string) AgentOption {
+	return func(a *Agent) {
+		a.CodeExecutionSandbox = sandbox
+	}
+}
+
+// WithDockerSandboxConfig sets the image and resource limits of the docker
+// sandbox. Empty ReadPaths/WritePaths default to the folder guard paths and
+// an empty BridgeURL to the WithAPIConfig base URL.
+//
+// Default: codeexec defaults (golang image, 1 CPU, 512m memory)
+func WithDockerSandboxConfig(config codeexec.DockerSandboxConfig) AgentOption {
+	return func(a *Agent) {
+		a.DockerSandboxConfig = config
+	}
+}
+
+// ExecuteShellCommand runs an execute_shell_command call in the configured
+// sandbox. Register it as the tool's execution function:
+//
+//	agent.RegisterCustomTool("execute_shell_command", codeexec.ShellCommandDescription, codeexec.ShellCommandParams,
+//	    func(ctx context.Context, args map[string]interface{}) (string, error) {
+//	        return agent.ExecuteShellCommand(ctx, args, shellEnv)
+//	    }, "workspace_advanced")
+//
+// env has the same meaning as for codeexec.ExecuteShellCommand.
+func (a *Agent) ExecuteShellCommand(ctx context.Context, args map[string]interface{}, env []string) (string, error) {
+	switch a.CodeExecutionSandbox {
+	case "", CodeExecutionSandboxHost:
+		return codeexec.ExecuteShellCommand(ctx, args, env)
+	case CodeExecutionSandboxDocker:
+		return a.dockerSandbox().ExecuteShellCommand(ctx, args, env)
+	default:
+		return "", fmt.Errorf("unknown code execution sandbox: %s", a.CodeExecutionSandbox)
+	}
+}
+
+// dockerSandbox builds the sandbox from the current folder guard paths and
+// API config, so later SetFolderGuardPaths calls take effect
+func (a *Agent) dockerSandbox() *codeexec.DockerSandbox {
+	config := a.DockerSandboxConfig
+	if len(config.ReadPaths) == 0 && len(config.WritePaths) == 0 {
+		config.ReadPaths, config.WritePaths = a.GetFolderGuardPaths()
+	}
+	if config.BridgeURL == "" {
+		config.BridgeURL = a.APIBaseURL
+	}
+	return codeexec.NewDockerSandbox(config)
+}
+
+// validateCodeExecutionSandbox rejects unknown sandbox kinds at creation time
+func (a *Agent) validateCodeExecutionSandbox() error {
+	switch a.CodeExecutionSandbox {
+	case "", CodeExecutionSandboxHost, CodeExecutionSandboxDocker:
+		return nil
+	default:
+		return fmt.Errorf("unknown code execution sandbox %q (want %q or %q)",
+			a.CodeExecutionSandbox, CodeExecutionSandboxHost, CodeExecutionSandboxDocker)
+	}
+}
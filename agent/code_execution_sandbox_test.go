@@ -0,0 +1,24 @@
+package mcpagent
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestCodeExecutionSandboxSelection(t *testing.T) {
+	a := &Agent{}
+	WithCodeExecutionSandbox("vm")(a)
+	if err := a.validateCodeExecutionSandbox(); err == nil {
+		t.Error("expected an error for an unknown sandbox")
+	}
+
+	WithCodeExecutionSandbox(CodeExecutionSandboxHost)(a)
+	if err := a.validateCodeExecutionSandbox(); err != nil {
+		t.Fatal(err)
+	}
+	got, err := a.ExecuteShellCommand(context.Background(), map[string]interface{}{"command": "echo on-host"}, nil)
+	if err != nil || !strings.Contains(got, "stdout:\non-host") {
+		t.Errorf("ExecuteShellCommand() = %q, %v", got, err)
+	}
+}
@@ -2,7 +2,6 @@ package mcpagent
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -125,7 +124,7 @@ func (a *Agent) handleGetAPISpec(ctx context.Context, args map[string]interface{
 				}
 			}
 			sort.Strings(availableInCategory)
-			return "", fmt.Errorf("tool(s) %v not found in category %q. Available tools in %q: %v", toolNames, serverName, serverName, availableInCategory)
+			return "", withSentinel(ErrToolNotFound, fmt.Errorf("tool(s) %v not found in category %q. Available tools in %q: %v", toolNames, serverName, serverName, availableInCategory))
 		}
 
 		spec := openapi.GenerateCustomToolsCompactSpec(serverName, customToolsForSpec, baseURL)
@@ -195,7 +194,7 @@ func (a *Agent) handleGetAPISpec(ctx context.Context, args map[string]interface{
 		return "", fmt.Errorf("tool(s) %v not found on server %q. Available tools on %q: %v", toolNames, serverName, serverName, availableOnServer)
 	}
 
-	spec := openapi.GenerateCompactSpec(serverName, serverTools, baseURL)
+	spec := a.compactSpec(serverName, serverTools, baseURL)
 	a.cacheSpec(cacheKey, []byte(spec))
 
 	if a.Logger != nil {
@@ -305,7 +304,7 @@ func (a *Agent) buildPreDiscoveredToolSpecs() string {
 
 	for _, serverName := range serverNames {
 		tools := mcpToolsByServer[serverName]
-		spec := openapi.GenerateCompactSpec(serverName, tools, baseURL)
+		spec := a.compactSpec(serverName, tools, baseURL)
 		sb.WriteString(spec)
 		sb.WriteString("\n")
 	}
@@ -367,12 +366,6 @@ func (a *Agent) getCodeExecutionAPIBaseURL() string {
 // This is included in the system prompt so the LLM knows what's available.
 // It builds the index purely from agent internal state (no filesystem scanning).
 func (a *Agent) buildToolIndex() (string, error) {
-	type ServerInfo struct {
-		Tools []string `json:"tools"`
-	}
-
-	index := make(map[string]ServerInfo)
-
 	// Build MCP server tool index from toolToServer mapping
 	serverToolsMap := make(map[string]map[string]bool)
 	for toolName, serverName := range a.toolToServer {
@@ -397,33 +390,27 @@ func (a *Agent) buildToolIndex() (string, error) {
 		serverToolsMap[normalized][toolName] = true
 	}
 
-	for serverName, toolsSet := range serverToolsMap {
-		tools := make([]string, 0, len(toolsSet))
-		for toolName := range toolsSet {
-			tools = append(tools, toolName)
-		}
-		sort.Strings(tools)
-		index[serverName] = ServerInfo{Tools: tools}
-	}
-
 	// Add custom tools grouped by category to the tool index.
 	// Even in code execution mode, custom tools must appear here so that Claude Code
 	// (which uses the MCP bridge and can only discover tools via get_api_spec) can
 	// find and call them via HTTP API. For non-Claude-Code providers, the tools are
 	// also available as direct LLM calls — having them in the index is harmless.
-	// Respect toolAllowList: if set, only include allowed custom tools in the index.
-	customToolsByCategory := make(map[string][]string)
+	// Respect toolAllowList and tool permissions: only include allowed, enabled custom tools in the index.
+	customToolsByCategory := make(map[string]map[string]bool)
 	var blockedCustomTools []string
 	for toolName, ct := range a.customTools {
 		category := ct.Category
 		if category == "" {
 			continue
 		}
-		if !a.isToolAllowed(toolName) {
+		if !a.isToolAllowed(toolName) || !a.isToolEnabled(toolName) {
 			blockedCustomTools = append(blockedCustomTools, toolName)
 			continue
 		}
-		customToolsByCategory[category] = append(customToolsByCategory[category], toolName)
+		if customToolsByCategory[category] == nil {
+			customToolsByCategory[category] = make(map[string]bool)
+		}
+		customToolsByCategory[category][toolName] = true
 	}
 	if a.Logger != nil && len(blockedCustomTools) > 0 {
 		sort.Strings(blockedCustomTools)
@@ -431,27 +418,25 @@ func (a *Agent) buildToolIndex() (string, error) {
 			loggerv2.Int("blocked_count", len(blockedCustomTools)),
 			loggerv2.Any("blocked", blockedCustomTools))
 	}
-	for category, tools := range customToolsByCategory {
-		sort.Strings(tools)
-		index[category] = ServerInfo{Tools: tools}
-	}
 
-	jsonData, err := json.MarshalIndent(index, "", "  ")
+	// Seed the incremental index (see tool_structure.go) so later custom tool
+	// registrations only re-render the section they change
+	index := newToolStructureIndex(serverToolsMap, customToolsByCategory)
+	jsonData, err := index.render()
 	if err != nil {
 		return "", fmt.Errorf("failed to marshal tool index: %w", err)
 	}
+	a.toolStructureMu.Lock()
+	a.toolStructure = index
+	a.toolStructureMu.Unlock()
 
 	if a.Logger != nil {
-		totalTools := 0
-		for _, pkg := range index {
-			totalTools += len(pkg.Tools)
-		}
 		a.Logger.Info("Built tool index",
-			loggerv2.Int("servers", len(index)),
-			loggerv2.Int("total_tools", totalTools))
+			loggerv2.Int("servers", index.sectionCount()),
+			loggerv2.Int("total_tools", index.toolCount()))
 	}
 
-	return string(jsonData), nil
+	return jsonData, nil
 }
 
 // getAgentGeneratedDir returns the agent-specific generated directory
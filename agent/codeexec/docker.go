@@ -0,0 +1,294 @@
+package codeexec
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Defaults applied by NewDockerSandbox to zero DockerSandboxConfig fields.
+const (
+	DefaultSandboxImage     = "golang:1.25-alpine"
+	DefaultSandboxCPUs      = 1.0
+	DefaultSandboxMemory    = "512m"
+	DefaultSandboxPidsLimit = 256
+	DefaultSandboxNetwork   = "mcpagent-sandbox"
+)
+
+// DockerSandboxConfig constrains the container shell commands run in.
+type DockerSandboxConfig struct {
+	// Image must provide sh and the toolchains the generated code needs
+	// (default DefaultSandboxImage, which can compile and run Go).
+	Image string
+	// CPUs and Memory are passed to docker run --cpus and --memory.
+	CPUs   float64
+	Memory string
+	// PidsLimit caps the number of processes in the container.
+	PidsLimit int
+
+	// ReadPaths are bind-mounted read-only and WritePaths read-write, each at
+	// the same path inside the container, so host paths in commands still
+	// resolve. Nothing else of the host filesystem is visible.
+	ReadPaths  []string
+	WritePaths []string
+
+	// BridgeURL is the MCP API bridge (MCP_API_URL). When set, the container
+	// joins Network, an internal Docker network without external access, and
+	// a loopback host in MCP_API_URL is rewritten to the network's gateway;
+	// the bridge must listen on an address reachable from it (e.g. 0.0.0.0).
+	// Without a bridge the container has no network at all.
+	BridgeURL string
+	// Network is the internal network created on first use (default DefaultSandboxNetwork).
+	Network string
+}
+
+// DockerSandbox runs execute_shell_command commands in a short-lived,
+// constrained Docker container instead of on the host: read-only root
+// filesystem, no capabilities, CPU/memory/process limits, only the configured
+// paths mounted and no network except the MCP API bridge.
+type DockerSandbox struct {
+	config DockerSandboxConfig
+
+	// docker runs the docker CLI; replaced in tests
+	docker func(ctx context.Context, args ...string) ([]byte, error)
+}
+
+// sandboxGateways caches the gateway of each sandbox network, so sandboxes
+// created per command only inspect the network once per process
+var (
+	sandboxGatewaysMu sync.Mutex
+	sandboxGateways   = map[string]string{}
+)
+
+// NewDockerSandbox creates a sandbox. It is cheap; the docker CLI is only
+// invoked when a command runs.
+func NewDockerSandbox(config DockerSandboxConfig) *DockerSandbox {
+	if config.Image == "" {
+		config.Image = DefaultSandboxImage
+	}
+	if config.CPUs <= 0 {
+		config.CPUs = DefaultSandboxCPUs
+	}
+	if config.Memory == "" {
+		config.Memory = DefaultSandboxMemory
+	}
+	if config.PidsLimit <= 0 {
+		config.PidsLimit = DefaultSandboxPidsLimit
+	}
+	if config.Network == "" {
+		config.Network = DefaultSandboxNetwork
+	}
+	return &DockerSandbox{config: config, docker: runDocker}
+}
+
+// ExecuteShellCommand is ExecuteShellCommand run inside the sandbox. env is
+// passed into the container except PATH, which is left to the image.
+// working_directory must be inside ReadPaths or WritePaths; by default
+// commands run in the first write path.
+func (s *DockerSandbox) ExecuteShellCommand(ctx context.Context, args map[string]interface{}, env []string) (string, error) {
+	command, ok := args["command"].(string)
+	if !ok {
+		return "", fmt.Errorf("command must be a string")
+	}
+	workingDirectory, err := shellWorkingDirectory(args)
+	if err != nil {
+		return "", err
+	}
+	if env == nil {
+		env = BuildSafeEnvironment()
+	}
+
+	gateway := ""
+	if s.config.BridgeURL != "" {
+		if gateway, err = s.networkGateway(ctx); err != nil {
+			return "", fmt.Errorf("failed to prepare sandbox network: %w", err)
+		}
+	}
+	name, err := containerName()
+	if err != nil {
+		return "", err
+	}
+	dockerArgs, err := s.runArgs(name, command, workingDirectory, env, gateway)
+	if err != nil {
+		return "", err
+	}
+	cmd := exec.CommandContext(ctx, "docker", dockerArgs...) //nolint:gosec // G204: intentional — the command runs inside the sandbox container
+	// Killing the docker client leaves the container running, so a cancelled
+	// command removes it by name
+	cmd.Cancel = func() error {
+		rmCtx, cancel := context.WithTimeout(context.Background(), containerRemoveTimeout)
+		defer cancel()
+		_, rmErr := s.docker(rmCtx, "rm", "-f", name)
+		if err := cmd.Process.Kill(); err != nil && !errors.Is(err, os.ErrProcessDone) {
+			return err
+		}
+		return rmErr
+	}
+	cmd.WaitDelay = containerRemoveTimeout
+	return runCommand(cmd)
+}
+
+// containerRemoveTimeout bounds removing the container of a cancelled command
+const containerRemoveTimeout = 10 * time.Second
+
+// containerName returns a unique name for a command's container
+func containerName() (string, error) {
+	suffix := make([]byte, 8)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", fmt.Errorf("failed to name sandbox container: %w", err)
+	}
+	return "mcpagent-sandbox-" + hex.EncodeToString(suffix), nil
+}
+
+// runArgs builds the docker run arguments for command, run in a container
+// named name
+func (s *DockerSandbox) runArgs(name, command, workingDirectory string, env []string, gateway string) ([]string, error) {
+	args := []string{
+		"run", "--rm", "-i",
+		"--name", name,
+		"--read-only",
+		"--tmpfs", "/tmp:rw,exec,size=256m",
+		"--cap-drop", "ALL",
+		"--security-opt", "no-new-privileges",
+		"--cpus", strconv.FormatFloat(s.config.CPUs, 'f', -1, 64),
+		"--memory", s.config.Memory,
+		"--pids-limit", strconv.Itoa(s.config.PidsLimit),
+		"--user", fmt.Sprintf("%d:%d", os.Getuid(), os.Getgid()),
+	}
+	if gateway == "" {
+		args = append(args, "--network", "none")
+	} else {
+		args = append(args, "--network", s.config.Network)
+	}
+
+	mounted := make(map[string]bool)
+	var roots []string
+	for _, mount := range []struct {
+		paths    []string
+		readOnly bool
+	}{{s.config.WritePaths, false}, {s.config.ReadPaths, true}} {
+		for _, path := range mount.paths {
+			abs, err := filepath.Abs(path)
+			if err != nil {
+				return nil, fmt.Errorf("sandbox path %q: %w", path, err)
+			}
+			if mounted[abs] {
+				continue // Write access wins over read access
+			}
+			mounted[abs] = true
+			roots = append(roots, abs)
+			volume := abs + ":" + abs
+			if mount.readOnly {
+				volume += ":ro"
+			}
+			args = append(args, "--volume", volume)
+		}
+	}
+
+	if workingDirectory == "" && len(s.config.WritePaths) > 0 {
+		workingDirectory = roots[0]
+	}
+	if workingDirectory != "" {
+		abs, err := filepath.Abs(workingDirectory)
+		if err != nil {
+			return nil, err
+		}
+		if !withinAny(abs, roots) {
+			return nil, fmt.Errorf("working_directory %q is not mounted in the sandbox", workingDirectory)
+		}
+		args = append(args, "--workdir", abs)
+	} else {
+		args = append(args, "--workdir", "/tmp")
+	}
+
+	for _, kv := range env {
+		if strings.HasPrefix(kv, "PATH=") {
+			continue
+		}
+		if value, ok := strings.CutPrefix(kv, "MCP_API_URL="); ok && gateway != "" {
+			kv = "MCP_API_URL=" + rewriteLoopbackHost(value, gateway)
+		}
+		args = append(args, "--env", kv)
+	}
+
+	return append(args, s.config.Image, "sh", "-c", command), nil
+}
+
+// networkGateway creates the internal sandbox network if needed and returns its gateway
+func (s *DockerSandbox) networkGateway(ctx context.Context) (string, error) {
+	sandboxGatewaysMu.Lock()
+	defer sandboxGatewaysMu.Unlock()
+	if gateway := sandboxGateways[s.config.Network]; gateway != "" {
+		return gateway, nil
+	}
+	inspect := func() ([]byte, error) {
+		return s.docker(ctx, "network", "inspect", s.config.Network, "--format", "{{range .IPAM.Config}}{{.Gateway}} {{end}}")
+	}
+	out, err := inspect()
+	if err != nil {
+		if _, err := s.docker(ctx, "network", "create", "--internal", s.config.Network); err != nil {
+			return "", err
+		}
+		if out, err = inspect(); err != nil {
+			return "", err
+		}
+	}
+	fields := strings.Fields(string(out))
+	if len(fields) == 0 {
+		return "", fmt.Errorf("network %s has no gateway", s.config.Network)
+	}
+	sandboxGateways[s.config.Network] = fields[0]
+	return fields[0], nil
+}
+
+func runDocker(ctx context.Context, args ...string) ([]byte, error) {
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "docker", args...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("docker %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
+
+// rewriteLoopbackHost points a URL on the host's loopback interface at host
+// instead; other URLs are returned unchanged
+func rewriteLoopbackHost(rawURL, host string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	switch u.Hostname() {
+	case "localhost", "127.0.0.1", "::1", "0.0.0.0":
+	default:
+		return rawURL
+	}
+	if port := u.Port(); port != "" {
+		u.Host = net.JoinHostPort(host, port)
+	} else {
+		u.Host = host
+	}
+	return u.String()
+}
+
+func withinAny(path string, roots []string) bool {
+	for _, root := range roots {
+		if rel, err := filepath.Rel(root, path); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,129 @@
+package codeexec
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDockerSandboxRunArgs(t *testing.T) {
+	sandbox := NewDockerSandbox(DockerSandboxConfig{
+		ReadPaths:  []string{"/data/docs", "/work"},
+		WritePaths: []string{"/work"},
+		BridgeURL:  "http://127.0.0.1:8000",
+	})
+
+	args, err := sandbox.runArgs("mcpagent-sandbox-test", "go run main.go", "", []string{"PATH=/bin", "MCP_API_URL=http://127.0.0.1:8000", "MCP_API_TOKEN=t"}, "172.30.0.1")
+	if err != nil {
+		t.Fatalf("runArgs() error = %v", err)
+	}
+	got := strings.Join(args, " ")
+	for _, want := range []string{
+		"--name mcpagent-sandbox-test", "--read-only", "--cap-drop ALL", "--cpus 1", "--memory 512m", "--pids-limit 256",
+		"--network mcpagent-sandbox",
+		"--volume /work:/work --volume /data/docs:/data/docs:ro",
+		"--workdir /work",
+		"--env MCP_API_URL=http://172.30.0.1:8000 --env MCP_API_TOKEN=t",
+		DefaultSandboxImage + " sh -c go run main.go",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("docker args missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "PATH=") || strings.Contains(got, "/work:/work:ro") {
+		t.Errorf("PATH should be left to the image and /work mounted once, read-write:\n%s", got)
+	}
+
+	if _, err := sandbox.runArgs("mcpagent-sandbox-test", "ls", "/etc", nil, "172.30.0.1"); err == nil {
+		t.Error("expected an error for a working directory outside the mounts")
+	}
+
+	offline, err := NewDockerSandbox(DockerSandboxConfig{}).runArgs("mcpagent-sandbox-test", "ls", "", nil, "")
+	if err != nil {
+		t.Fatalf("runArgs() error = %v", err)
+	}
+	if got := strings.Join(offline, " "); !strings.Contains(got, "--network none") || !strings.Contains(got, "--workdir /tmp") {
+		t.Errorf("sandbox without a bridge should have no network:\n%s", got)
+	}
+}
+
+func TestDockerSandboxCreatesInternalNetworkOnce(t *testing.T) {
+	sandbox := NewDockerSandbox(DockerSandboxConfig{Network: "mcpagent-sandbox-test"})
+	var calls []string
+	created := false
+	sandbox.docker = func(_ context.Context, args ...string) ([]byte, error) {
+		calls = append(calls, strings.Join(args[:2], " "))
+		switch args[1] {
+		case "inspect":
+			if !created {
+				return nil, errors.New("no such network")
+			}
+			return []byte("172.30.0.1 \n"), nil
+		case "create":
+			if args[2] != "--internal" {
+				t.Errorf("network created without --internal: %v", args)
+			}
+			created = true
+		}
+		return nil, nil
+	}
+
+	for i := 0; i < 2; i++ {
+		gateway, err := sandbox.networkGateway(context.Background())
+		if err != nil || gateway != "172.30.0.1" {
+			t.Fatalf("networkGateway() = %q, %v", gateway, err)
+		}
+	}
+	if want := "network inspect,network create,network inspect"; strings.Join(calls, ",") != want {
+		t.Errorf("docker calls = %v, want %s", calls, want)
+	}
+}
+
+func TestDockerSandboxRemovesContainerOnCancel(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake docker CLI is a shell script")
+	}
+	// A docker CLI whose run hangs until killed
+	bin := t.TempDir()
+	if err := os.WriteFile(filepath.Join(bin, "docker"), []byte("#!/bin/sh\nexec sleep 30\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	sandbox := NewDockerSandbox(DockerSandboxConfig{})
+	removed := make(chan []string, 1)
+	sandbox.docker = func(_ context.Context, args ...string) ([]byte, error) {
+		removed <- args
+		return nil, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	if _, err := sandbox.ExecuteShellCommand(ctx, map[string]interface{}{"command": "sleep 60"}, nil); err != nil {
+		t.Fatalf("ExecuteShellCommand() error = %v", err)
+	}
+	select {
+	case args := <-removed:
+		if len(args) != 3 || args[0] != "rm" || args[1] != "-f" || !strings.HasPrefix(args[2], "mcpagent-sandbox-") {
+			t.Errorf("docker calls on cancel = %v, want rm -f <container>", args)
+		}
+	default:
+		t.Error("cancelled command did not remove its container")
+	}
+}
+
+func TestRewriteLoopbackHost(t *testing.T) {
+	for in, want := range map[string]string{
+		"http://localhost:8000/api": "http://172.30.0.1:8000/api",
+		"http://127.0.0.1":          "http://172.30.0.1",
+		"https://bridge.internal":   "https://bridge.internal",
+	} {
+		if got := rewriteLoopbackHost(in, "172.30.0.1"); got != want {
+			t.Errorf("rewriteLoopbackHost(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -702,6 +702,59 @@ func SetSessionToolAllowList(sessionID string, allowList map[string]bool) {
 	}
 }
 
+// ToolCallHook runs a tool call that generated code made through the HTTP
+// API. kind is "MCP", "custom" or "virtual"; execute runs the call with the
+// arguments the hook passes it. A hook may block the call by not calling
+// execute.
+type ToolCallHook func(ctx context.Context, kind, server, tool string, args map[string]interface{}, execute func(ctx context.Context, args map[string]interface{}) (string, error)) (string, error)
+
+// Session-scoped tool call hooks, by session ID. Kept outside the registry,
+// which may not be initialized yet, under their own lock so long-running
+// calls do not block SetSessionToolCallHook.
+var (
+	toolCallHooksMu sync.RWMutex
+	toolCallHooks   = map[string]toolCallHookEntry{}
+)
+
+type toolCallHookEntry struct {
+	owner interface{}
+	hook  ToolCallHook
+}
+
+// SetSessionToolCallHook routes the HTTP API tool calls of a session through
+// hook, replacing the hook of a previous owner. owner identifies the hook for
+// ClearSessionToolCallHook.
+func SetSessionToolCallHook(sessionID string, owner interface{}, hook ToolCallHook) {
+	toolCallHooksMu.Lock()
+	defer toolCallHooksMu.Unlock()
+	toolCallHooks[sessionID] = toolCallHookEntry{owner: owner, hook: hook}
+}
+
+// ClearSessionToolCallHook removes the hook of a session if owner set it
+func ClearSessionToolCallHook(sessionID string, owner interface{}) {
+	toolCallHooksMu.Lock()
+	defer toolCallHooksMu.Unlock()
+	if entry, ok := toolCallHooks[sessionID]; ok && entry.owner == owner {
+		delete(toolCallHooks, sessionID)
+	}
+}
+
+// RunToolCall runs an HTTP API tool call of a session through the session's
+// hook, or directly when it has none. Virtual tool scope IDs
+// (<session>:vt:<trace>) use the hook of their base session.
+func RunToolCall(ctx context.Context, sessionID, kind, server, tool string, args map[string]interface{}, execute func(ctx context.Context, args map[string]interface{}) (string, error)) (string, error) {
+	toolCallHooksMu.RLock()
+	entry, ok := toolCallHooks[sessionID]
+	if !ok {
+		entry, ok = toolCallHooks[baseSessionFromVirtualScopeID(sessionID)]
+	}
+	toolCallHooksMu.RUnlock()
+	if !ok || sessionID == "" {
+		return execute(ctx, args)
+	}
+	return entry.hook(ctx, kind, server, tool, args, execute)
+}
+
 // CallCustomToolWithSession calls a custom tool with session scoping.
 // Once a session registry exists it is authoritative: a missing tool must fail
 // instead of borrowing the most recently registered global executor, which may
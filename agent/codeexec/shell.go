@@ -52,9 +52,6 @@ func ExecuteShellCommand(ctx context.Context, args map[string]interface{}, env [
 	}
 
 	cmd := exec.CommandContext(ctx, "sh", "-c", command) //nolint:gosec // G204: intentional — this tool's purpose is to execute user-provided commands
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
 	if workingDirectory != "" {
 		cmd.Dir = workingDirectory
 	}
@@ -65,7 +62,17 @@ func ExecuteShellCommand(ctx context.Context, args map[string]interface{}, env [
 		cmd.Env = BuildSafeEnvironment()
 	}
 
-	err = cmd.Run()
+	return runCommand(cmd)
+}
+
+// runCommand runs cmd and formats its exit code and capped output the way
+// execute_shell_command reports them. A non-zero exit is not an error.
+func runCommand(cmd *exec.Cmd) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
 
 	exitCode := 0
 	if err != nil {
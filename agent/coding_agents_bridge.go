@@ -19,6 +19,9 @@ type BridgeToolDef struct {
 	InputSchema json.RawMessage `json:"input_schema"`
 	Server      string          `json:"server,omitempty"` // MCP server name (empty for custom/virtual)
 	Type        string          `json:"type"`             // "mcp", "custom", or "virtual"
+	// TimeoutMs is the bridge's HTTP timeout for the tool's calls, set from
+	// WithPerToolTimeout: > 0 milliseconds, < 0 no timeout, 0 the bridge default
+	TimeoutMs int64 `json:"timeout_ms,omitempty"`
 }
 
 // bridgeTools is the explicit list of tools exposed through the coding-agent MCP
@@ -77,6 +80,7 @@ func (a *Agent) BuildBridgeMCPConfig() (string, error) {
 			def = defaultBridgeToolDef(want.name, want.toolType, logger)
 		}
 		if def != nil {
+			def.TimeoutMs = a.bridgeToolTimeoutMs(want.name)
 			toolDefs = append(toolDefs, *def)
 		} else {
 			logger.Warn("Bridge tool not found — skipping",
@@ -163,6 +167,20 @@ func (a *Agent) BuildBridgeMCPConfig() (string, error) {
 	return string(configJSON), nil
 }
 
+// bridgeToolTimeoutMs returns the BridgeToolDef.TimeoutMs of name: its
+// WithPerToolTimeout override, or 0 to keep the bridge default
+func (a *Agent) bridgeToolTimeoutMs(name string) int64 {
+	timeout, ok := a.perToolTimeouts[name]
+	switch {
+	case !ok:
+		return 0
+	case timeout <= 0:
+		return -1
+	default:
+		return timeout.Milliseconds()
+	}
+}
+
 func normalizeBridgeAPIURL(raw string) (string, error) {
 	trimmed := strings.TrimSpace(raw)
 	if trimmed == "" {
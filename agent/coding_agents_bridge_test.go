@@ -5,6 +5,7 @@ import (
 	"os"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/manishiitg/mcpagent/llm"
 	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
@@ -163,6 +164,32 @@ func TestBuildBridgeMCPConfigNormalizesMarkdownAPIURL(t *testing.T) {
 	}
 }
 
+func TestBuildBridgeMCPConfigPassesPerToolTimeouts(t *testing.T) {
+	t.Setenv("MCP_BRIDGE_BINARY", "/usr/local/bin/mcpbridge")
+	t.Setenv("MCP_API_URL", "http://localhost:8080")
+	t.Setenv("MCP_API_TOKEN", "test-token-123")
+
+	agent := bridgeTestAgent()
+	WithPerToolTimeout(map[string]time.Duration{
+		"execute_shell_command": 20 * time.Minute,
+		"agent_browser":         0,
+	})(agent)
+	configJSON, err := agent.BuildBridgeMCPConfig()
+	if err != nil {
+		t.Fatalf("BuildBridgeMCPConfig() error: %v", err)
+	}
+	tools := bridgeToolsFromConfig(t, configJSON)
+	if got := tools["execute_shell_command"].TimeoutMs; got != 20*60*1000 {
+		t.Errorf("execute_shell_command timeout_ms = %d", got)
+	}
+	if got := tools["agent_browser"].TimeoutMs; got != -1 {
+		t.Errorf("agent_browser timeout_ms = %d, want -1 (no timeout)", got)
+	}
+	if got := tools["diff_patch_workspace_file"].TimeoutMs; got != 0 {
+		t.Errorf("diff_patch_workspace_file timeout_ms = %d, want 0 (bridge default)", got)
+	}
+}
+
 func TestBuildBridgeMCPConfigRejectsInvalidAPIURL(t *testing.T) {
 	t.Setenv("MCP_BRIDGE_BINARY", "/usr/local/bin/mcpbridge")
 	t.Setenv("MCP_API_URL", "not a url")
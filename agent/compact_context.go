@@ -0,0 +1,112 @@
+// compact_context.go
+//
+// This file provides on-demand context summarization. Besides the token
+// threshold and max turns triggers (see context_summarization.go), the
+// conversation can be compacted when the application asks for it
+// (Agent.SummarizeNow) or when the LLM asks for it with the compact_context
+// virtual tool, e.g. after reading large outputs it no longer needs.
+//
+// A request made while a conversation is running is served before its next
+// LLM call, with the same summarization strategy, kept messages and events
+// as threshold-triggered summarization, and starts the cooldown. Outside a
+// conversation, SummarizeNow compacts the retained history of the last one.
+//
+// Exported:
+//   - Agent.SummarizeNow: Summarize the conversation now, regardless of thresholds
+
+package mcpagent
+
+import (
+	"context"
+	"fmt"
+
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+// SummarizeNow summarizes the conversation history regardless of the token
+// threshold and max turns conditions. While a conversation is running, its
+// history is summarized before the next LLM call (of every running call);
+// otherwise the retained
+// history of the last conversation (RetainedHistory, with
+// WithHistoryRetention) is summarized in place.
+// Works whether or not WithContextSummarization is enabled.
+func (a *Agent) SummarizeNow(ctx context.Context) error {
+	a.activeCallsMu.Lock()
+	running := len(a.activeCalls)
+	for call := range a.activeCalls {
+		call.summarizeRequested.Store(true)
+	}
+	a.activeCallsMu.Unlock()
+	if running > 0 {
+		getLogger(a).Info("📊 [CONTEXT_SUMMARIZATION] Summarization requested, running before the next LLM call",
+			loggerv2.Int("calls", running))
+		return nil
+	}
+	history := a.RetainedHistory()
+	if len(history) == 0 {
+		return fmt.Errorf("no conversation history to summarize")
+	}
+	summarized, err := rebuildMessagesWithSummary(a, ctx, history, GetSummaryKeepLastMessages(a))
+	if err != nil {
+		return err
+	}
+	a.retainHistory(summarized)
+	return nil
+}
+
+// createCompactContextTool returns the compact_context virtual tool
+func createCompactContextTool() llmtypes.Tool {
+	return llmtypes.Tool{
+		Type: "function",
+		Function: &llmtypes.FunctionDefinition{
+			Name:        "compact_context",
+			Description: "Replace the older part of this conversation with a summary to free context space, e.g. after large tool outputs you no longer need in full. The most recent messages are kept as they are. Takes effect before your next step.",
+			Parameters: llmtypes.NewParameters(map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"reason": map[string]interface{}{
+						"type":        "string",
+						"description": "Why the context should be compacted now (optional)",
+					},
+				},
+			}),
+		},
+	}
+}
+
+// handleCompactContext handles the compact_context virtual tool
+func (a *Agent) handleCompactContext(ctx context.Context, args map[string]interface{}) (string, error) {
+	reason, _ := args["reason"].(string)
+	currentCall(ctx).summarizeRequested.Store(true)
+	getLogger(a).Info("📊 [CONTEXT_SUMMARIZATION] compact_context called, running before the next LLM call",
+		loggerv2.String("reason", reason))
+	return fmt.Sprintf("Context compaction scheduled: before your next step, the conversation except the last %d messages will be replaced with a summary. Restate anything from older tool outputs you still need verbatim.",
+		GetSummaryKeepLastMessages(a)), nil
+}
+
+// summarizeOnRequest serves a pending SummarizeNow or compact_context
+// request before the LLM call of turn. Returns the summarized messages, or
+// false when nothing was requested or summarization failed.
+func (a *Agent) summarizeOnRequest(ctx context.Context, messages []llmtypes.MessageContent, turn int) ([]llmtypes.MessageContent, bool) {
+	if !currentCall(ctx).summarizeRequested.Swap(false) {
+		return nil, false
+	}
+	summarized, err := rebuildMessagesWithSummary(a, ctx, messages, GetSummaryKeepLastMessages(a))
+	if err != nil {
+		getLogger(a).Warn("Failed to summarize conversation history on request, continuing with original messages",
+			loggerv2.Error(err))
+		return nil, false
+	}
+	getLogger(a).Info("Conversation history summarized on request",
+		loggerv2.Int("turn", turn),
+		loggerv2.Int("original_count", len(messages)),
+		loggerv2.Int("new_count", len(summarized)))
+
+	a.lastSummarizationTurn = turn
+	// The next LLM call reports the usage of the summarized messages
+	a.tokenTrackingMutex.Lock()
+	a.currentContextWindowUsage = 0
+	a.tokenTrackingMutex.Unlock()
+	return summarized, true
+}
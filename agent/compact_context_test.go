@@ -0,0 +1,85 @@
+package mcpagent
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+)
+
+func TestSummarizeNowCompactsRetainedHistory(t *testing.T) {
+	a := &Agent{Logger: loggerv2.NewNoop(), ModelID: "test-model", SummaryKeepLastMessages: 2}
+	WithSummarizationStrategy(SelectiveSummarization{})(a)
+
+	if err := a.SummarizeNow(context.Background()); err == nil {
+		t.Error("expected an error without history")
+	}
+	a.retainHistory(summarizationTestHistory())
+	if err := a.SummarizeNow(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := len(a.RetainedHistory()); got != 6 {
+		t.Errorf("retained %d messages after summarization, want 6", got)
+	}
+	if len(a.activeCalls) != 0 {
+		t.Error("no call should be running outside a conversation")
+	}
+}
+
+func TestCompactContextToolSummarizesBeforeNextCall(t *testing.T) {
+	a := &Agent{Logger: loggerv2.NewNoop(), ModelID: "test-model", SummaryKeepLastMessages: 2, lastSummarizationTurn: -1}
+	WithSummarizationStrategy(SelectiveSummarization{})(a)
+
+	ctx, call := a.beginCall(context.Background(), nil)
+	if _, ok := a.summarizeOnRequest(ctx, summarizationTestHistory(), 1); ok {
+		t.Fatal("nothing was requested")
+	}
+	result, err := a.HandleVirtualTool(ctx, "compact_context", map[string]interface{}{"reason": "large outputs"})
+	if err != nil || !strings.Contains(result, "last 2 messages") {
+		t.Fatalf("compact_context = %q, %v", result, err)
+	}
+
+	// Another call on the same agent is not compacted
+	other, otherCall := a.beginCall(context.Background(), nil)
+	if _, ok := a.summarizeOnRequest(other, summarizationTestHistory(), 1); ok {
+		t.Error("compact_context of one call compacted another")
+	}
+	a.endCall(otherCall)
+
+	messages, ok := a.summarizeOnRequest(ctx, summarizationTestHistory(), 3)
+	if !ok || len(messages) != 6 {
+		t.Fatalf("summarizeOnRequest = %d messages, %v", len(messages), ok)
+	}
+	if a.lastSummarizationTurn != 3 {
+		t.Errorf("lastSummarizationTurn = %d, want 3", a.lastSummarizationTurn)
+	}
+	if _, ok := a.summarizeOnRequest(ctx, messages, 4); ok {
+		t.Error("a request is served once")
+	}
+
+	// SummarizeNow during a conversation defers to the conversation loop
+	defer a.endCall(call)
+	if err := a.SummarizeNow(context.Background()); err != nil || !call.summarizeRequested.Load() {
+		t.Errorf("SummarizeNow during a call: err=%v requested=%v", err, call.summarizeRequested.Load())
+	}
+}
+
+func TestCompactContextToolOfferedWithSummarization(t *testing.T) {
+	hasTool := func(a *Agent) bool {
+		for _, tool := range a.CreateVirtualTools() {
+			if tool.Function.Name == "compact_context" {
+				return true
+			}
+		}
+		return false
+	}
+	a := &Agent{Logger: loggerv2.NewNoop()}
+	if hasTool(a) {
+		t.Error("compact_context offered without context summarization")
+	}
+	a.EnableContextSummarization = true
+	if !hasTool(a) {
+		t.Error("compact_context not offered with context summarization")
+	}
+}
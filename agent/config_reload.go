@@ -0,0 +1,463 @@
+// config_reload.go
+//
+// This file implements hot-reloading of the MCP server configuration. When
+// servers are added to, removed from or modified in the config file (or its
+// _user.json companion), ReloadConfig closes the connections of removed and
+// modified servers, invalidates their cache entries, connects the new set,
+// and rebuilds the tool lists and the system prompt — without recreating the
+// Agent. Custom and virtual tools are kept. WithConfigWatch calls it whenever
+// the config files change on disk.
+//
+// The new servers are connected before anything is swapped, and the swap
+// waits until no conversation is in progress: a conversation keeps the
+// toolset it started with, and a reload that fails to connect leaves the
+// previous servers in use.
+//
+// Exported:
+//   - ConfigReloadResult: Servers changed by a reload
+//   - WithConfigWatch: Reload automatically when the config file changes
+//   - Agent.ReloadConfig: Reload the MCP server configuration now
+
+package mcpagent
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/manishiitg/mcpagent/agent/prompt"
+	"github.com/manishiitg/mcpagent/events"
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+	"github.com/manishiitg/mcpagent/mcpcache"
+	"github.com/manishiitg/mcpagent/mcpclient"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// configWatchDebounce lets an editor finish writing the config before it is reloaded
+const configWatchDebounce = 500 * time.Millisecond
+
+// ConfigReloadResult lists the servers a reload changed
+type ConfigReloadResult struct {
+	Added    []string `json:"added,omitempty"`
+	Removed  []string `json:"removed,omitempty"`
+	Modified []string `json:"modified,omitempty"`
+	// ToolCount is the number of tools available after the reload (0 when deferred)
+	ToolCount int `json:"tool_count"`
+	// Deferred is true when conversations were in progress: the reload is
+	// applied when the last of them ends
+	Deferred bool `json:"deferred,omitempty"`
+}
+
+// Changed reports whether any server was added, removed or modified
+func (r *ConfigReloadResult) Changed() bool {
+	return len(r.Added) > 0 || len(r.Removed) > 0 || len(r.Modified) > 0
+}
+
+// WithConfigWatch watches the MCP config file (and its _user.json companion)
+// and calls ReloadConfig when it changes. Reload errors are logged and
+// reported with an mcp_config_reloaded event; the previous servers stay in
+// use. The watcher stops when the agent is closed.
+//
+// Default: false
+func WithConfigWatch(enabled bool) AgentOption {
+	return func(a *Agent) {
+		a.watchConfig = enabled
+	}
+}
+
+// ReloadConfig reloads the MCP server configuration. Servers whose
+// configuration is unchanged keep their connections; added and modified
+// servers are connected first, and only once every server is connected is
+// the new set swapped in: the tool lists and the system prompt are rebuilt,
+// the old connections of removed and modified servers are closed and their
+// cached tool definitions invalidated, and an mcp_config_reloaded event is
+// emitted. When connecting fails, the previous servers stay in use.
+//
+// The swap waits until no conversation is in progress, so a conversation
+// keeps the toolset it started with; ConfigReloadResult.Deferred reports a
+// reload that will be applied when the last one ends.
+func (a *Agent) ReloadConfig(ctx context.Context) (*ConfigReloadResult, error) {
+	a.reloadMu.Lock()
+	defer a.reloadMu.Unlock()
+
+	logger := getLogger(a)
+	config, err := mcpclient.LoadMergedConfig(a.configPath, logger)
+	if err != nil {
+		err = fmt.Errorf("failed to load merged MCP config: %w", err)
+		a.EmitTypedEvent(ctx, events.NewMCPConfigReloadedEvent(a.configPath, nil, nil, nil, 0, err.Error()))
+		return nil, err
+	}
+
+	// Diff against the reload still waiting to be applied, if any
+	a.conversationMu.Lock()
+	pending := a.pendingReload
+	previous, toolCount := a.serverConfigs, len(a.Tools)
+	if pending != nil {
+		previous, toolCount = pending.config, 0
+	}
+	a.conversationMu.Unlock()
+
+	result := diffServerConfigs(previous, config.MCPServers)
+	if !result.Changed() {
+		result.ToolCount = toolCount
+		result.Deferred = pending != nil
+		return result, nil
+	}
+	logger.Info("Reloading MCP config",
+		loggerv2.Any("added", result.Added),
+		loggerv2.Any("removed", result.Removed),
+		loggerv2.Any("modified", result.Modified))
+
+	// Detach (without closing) the connections of removed and modified
+	// servers, so the modified ones connect anew; they are restored if the
+	// reload fails
+	registry := mcpclient.GetSessionRegistry()
+	detached := make(map[string]mcpclient.ClientInterface)
+	for _, name := range append(append([]string{}, result.Removed...), result.Modified...) {
+		connSessionID := registry.ResolveConnectionSessionID(a.SessionID, name)
+		if client := registry.GetSessionConnections(connSessionID)[name]; client != nil {
+			registry.DetachSessionServer(connSessionID, name)
+			detached[name] = client
+		}
+	}
+
+	serverName := a.serverName
+	if serverName == "" {
+		serverName = mcpclient.AllServers
+	}
+	clients, toolToServer, mcpTools, servers, prompts, resources, serverInstructions, _, err :=
+		NewAgentConnectionWithSession(ctx, a.LLM, serverName, a.configPath, a.SessionID, string(a.TraceID), a.Tracers, logger, a.DisableCache, a.RuntimeOverrides, a.UserID)
+	if err != nil {
+		// Drop whatever the failed attempt connected in place of the old set
+		for _, name := range append(append([]string{}, result.Added...), result.Modified...) {
+			registry.CloseSessionServer(registry.ResolveConnectionSessionID(a.SessionID, name), name)
+		}
+		for name, client := range detached {
+			registry.StoreConnection(registry.ResolveConnectionSessionID(a.SessionID, name), name, client)
+		}
+		err = fmt.Errorf("failed to reconnect MCP servers: %w", err)
+		a.EmitTypedEvent(ctx, events.NewMCPConfigReloadedEvent(a.configPath, result.Added, result.Removed, result.Modified, toolCount, err.Error()))
+		return nil, err
+	}
+
+	next := &reloadedState{
+		config:             config.MCPServers,
+		previousConfig:     previous,
+		result:             result,
+		clients:            clients,
+		toolToServer:       toolToServer,
+		mcpTools:           mcpTools,
+		servers:            servers,
+		prompts:            prompts,
+		resources:          resources,
+		resourceTemplates:  a.listResourceTemplates(ctx, clients, config),
+		serverInstructions: serverInstructions,
+	}
+	for _, client := range detached {
+		next.retired = append(next.retired, client)
+	}
+
+	a.conversationMu.Lock()
+	defer a.conversationMu.Unlock()
+	if a.pendingReload != nil {
+		// Connections of the superseded reload that this one replaced were never used
+		next.retired = append(a.pendingReload.retired, next.retired...)
+		next.previousConfig = a.pendingReload.previousConfig
+	}
+	if a.activeConversations > 0 {
+		a.pendingReload = next
+		result.Deferred = true
+		logger.Info("MCP config reload deferred until the conversations in progress end",
+			loggerv2.Int("conversations", a.activeConversations))
+		return result, nil
+	}
+	a.pendingReload = nil
+	a.applyReload(ctx, next)
+	return result, nil
+}
+
+// reloadedState is a connected server set waiting to be swapped in
+type reloadedState struct {
+	config             map[string]mcpclient.MCPServerConfig
+	previousConfig     map[string]mcpclient.MCPServerConfig // In use before the first unapplied reload
+	result             *ConfigReloadResult
+	clients            map[string]mcpclient.ClientInterface
+	toolToServer       map[string]string
+	mcpTools           []llmtypes.Tool
+	servers            []string
+	prompts            map[string][]mcp.Prompt
+	resources          map[string][]mcp.Resource
+	resourceTemplates  map[string][]mcp.ResourceTemplate
+	serverInstructions map[string]string
+	retired            []mcpclient.ClientInterface // Replaced connections, closed after the swap
+}
+
+// applyReload swaps in a reloaded server set; the caller holds
+// a.conversationMu and no conversation is in progress
+func (a *Agent) applyReload(ctx context.Context, next *reloadedState) {
+	logger := getLogger(a)
+	a.applyReloadedServers(next.clients, next.toolToServer, next.mcpTools)
+	a.servers = next.servers
+	a.prompts = next.prompts
+	a.resources = next.resources
+	a.resourceTemplates = next.resourceTemplates
+	a.serverInstructions = next.serverInstructions
+	a.serverConfigs = next.config
+	a.rebuildSystemPromptAfterReload()
+
+	for _, client := range next.retired {
+		_ = client.Close()
+	}
+	cacheManager := mcpcache.GetCacheManager(logger)
+	for name, config := range next.previousConfig {
+		if current, ok := next.config[name]; ok && mcpcache.GenerateServerConfigHash(current) == mcpcache.GenerateServerConfigHash(config) {
+			continue
+		}
+		if err := cacheManager.Invalidate(mcpcache.GenerateUnifiedCacheKey(name, config)); err != nil {
+			logger.Debug("Failed to invalidate cache entry", loggerv2.String("server", name), loggerv2.Error(err))
+		}
+		a.openAPISpecCacheMu.Lock()
+		delete(a.openAPISpecCache, name)
+		a.openAPISpecCacheMu.Unlock()
+		a.toolAnnotationsMu.Lock()
+		delete(a.toolAnnotations, name)
+		a.toolAnnotationsMu.Unlock()
+	}
+
+	result := next.result
+	result.ToolCount = len(a.Tools)
+	a.EmitTypedEvent(ctx, events.NewMCPConfigReloadedEvent(a.configPath, result.Added, result.Removed, result.Modified, result.ToolCount, ""))
+	logger.Info("MCP config reloaded", loggerv2.Int("tool_count", result.ToolCount))
+}
+
+// enterConversation registers a conversation in progress, first applying a
+// reload that waited for the previous conversations to end
+func (a *Agent) enterConversation(ctx context.Context) {
+	a.conversationMu.Lock()
+	defer a.conversationMu.Unlock()
+	if a.activeConversations == 0 && a.pendingReload != nil {
+		next := a.pendingReload
+		a.pendingReload = nil
+		a.applyReload(context.WithoutCancel(ctx), next)
+	}
+	a.activeConversations++
+}
+
+// leaveConversation ends a conversation, applying a waiting reload when it
+// was the last one in progress
+func (a *Agent) leaveConversation(ctx context.Context) {
+	a.conversationMu.Lock()
+	defer a.conversationMu.Unlock()
+	a.activeConversations--
+	if a.activeConversations == 0 && a.pendingReload != nil {
+		next := a.pendingReload
+		a.pendingReload = nil
+		a.applyReload(context.WithoutCancel(ctx), next)
+	}
+}
+
+// diffServerConfigs compares two server configurations by name
+func diffServerConfigs(previous, current map[string]mcpclient.MCPServerConfig) *ConfigReloadResult {
+	result := &ConfigReloadResult{}
+	for name, config := range current {
+		old, ok := previous[name]
+		switch {
+		case !ok:
+			result.Added = append(result.Added, name)
+		case mcpcache.GenerateServerConfigHash(old) != mcpcache.GenerateServerConfigHash(config):
+			result.Modified = append(result.Modified, name)
+		}
+	}
+	for name := range previous {
+		if _, ok := current[name]; !ok {
+			result.Removed = append(result.Removed, name)
+		}
+	}
+	sort.Strings(result.Added)
+	sort.Strings(result.Removed)
+	sort.Strings(result.Modified)
+	return result
+}
+
+// applyReloadedServers swaps in the reconnected clients and MCP tools. Custom
+// and virtual tools are kept; the MCP tools go where the agent's mode keeps
+// them (the LLM toolset, the code execution tool index, or the deferred tools
+// of tool search mode), filtered by the selected tools and servers.
+func (a *Agent) applyReloadedServers(clients map[string]mcpclient.ClientInterface, toolToServer map[string]string, mcpTools []llmtypes.Tool) {
+	previousToolToServer := a.toolToServer
+	isPreviousMCPTool := func(tool llmtypes.Tool) bool {
+		if tool.Function == nil {
+			return false
+		}
+		server, ok := previousToolToServer[tool.Function.Name]
+		return ok && server != "custom"
+	}
+
+	// Custom tools are registered on the agent, not discovered from servers
+	for name, server := range previousToolToServer {
+		if server == "custom" {
+			toolToServer[name] = server
+		}
+	}
+
+	a.clientsMu.Lock()
+	a.Clients = clients
+	a.clientsMu.Unlock()
+	a.watchConnectionStates(clients)
+	a.toolToServer = toolToServer
+	a.toolFilter = NewToolFilter(a.selectedTools, a.selectedServers, clients, a.GetCustomToolCategories(), a.Logger)
+
+	var included []llmtypes.Tool
+	var includedServers []string
+	for _, tool := range mcpTools {
+		if tool.Function == nil {
+			continue
+		}
+		server := toolToServer[tool.Function.Name]
+		if a.toolFilter.IsNoFilteringActive() || a.toolFilter.ShouldIncludeTool(server, tool.Function.Name, false, false) {
+			included = append(included, tool)
+			includedServers = append(includedServers, server)
+		}
+	}
+
+	switch {
+	case a.UseCodeExecutionMode:
+		a.allMCPToolDefs = nil
+		for _, tool := range mcpTools {
+			if tool.Function != nil && toolToServer[tool.Function.Name] != "custom" {
+				a.allMCPToolDefs = append(a.allMCPToolDefs, tool)
+			}
+		}
+		if err := a.UpdateCodeExecutionRegistry(); err != nil {
+			getLogger(a).Warn("Failed to update code execution registry after reload", loggerv2.Error(err))
+		}
+	case a.UseToolSearchMode:
+		deferred := make([]llmtypes.Tool, 0, len(a.allDeferredTools))
+		deferredServers := make([]string, 0, len(a.allDeferredTools))
+		for i, tool := range a.allDeferredTools {
+			if !isPreviousMCPTool(tool) {
+				deferred = append(deferred, tool)
+				if i < len(a.allDeferredToolServers) {
+					deferredServers = append(deferredServers, a.allDeferredToolServers[i])
+				} else {
+					deferredServers = append(deferredServers, "")
+				}
+			}
+		}
+		a.allDeferredTools = append(deferred, included...)
+		a.allDeferredToolServers = append(deferredServers, includedServers...)
+		for name := range a.discoveredTools {
+			if server, ok := previousToolToServer[name]; ok && server != "custom" {
+				if _, still := toolToServer[name]; !still {
+					delete(a.discoveredTools, name)
+				}
+			}
+		}
+	default:
+		tools := make([]llmtypes.Tool, 0, len(a.Tools)+len(included))
+		tools = append(tools, included...)
+		for _, tool := range a.Tools {
+			if !isPreviousMCPTool(tool) {
+				tools = append(tools, tool)
+			}
+		}
+		a.Tools = tools
+		a.filteredTools = tools
+	}
+}
+
+// rebuildSystemPromptAfterReload rebuilds the system prompt for the reloaded
+// servers. In code execution mode the tool index was already rebuilt by
+// UpdateCodeExecutionRegistry; a custom system prompt is left as it is.
+func (a *Agent) rebuildSystemPromptAfterReload() {
+	if a.UseCodeExecutionMode || a.hasCustomSystemPrompt {
+		return
+	}
+	var toolCategories []string
+	if a.UseToolSearchMode {
+		for serverName := range a.Clients {
+			toolCategories = append(toolCategories, serverName)
+		}
+	}
+	a.systemPrompt = a.withServerInstructions(prompt.BuildSystemPromptWithoutTools(a.prompts, a.resources, string(a.AgentMode), a.DiscoverResource, a.DiscoverPrompt, false, "", "", a.UseToolSearchMode, toolCategories, a.Logger, a.EnableParallelToolExecution), nil)
+}
+
+// dropPendingReload closes the replaced connections of a reload that was
+// never applied; they are no longer in the session registry
+func (a *Agent) dropPendingReload() {
+	a.conversationMu.Lock()
+	defer a.conversationMu.Unlock()
+	if a.pendingReload != nil {
+		for _, client := range a.pendingReload.retired {
+			_ = client.Close()
+		}
+		a.pendingReload = nil
+	}
+}
+
+// configWatcher reloads the agent's MCP config when its files change
+type configWatcher struct {
+	watcher *fsnotify.Watcher
+	done    chan struct{}
+}
+
+// startConfigWatcher watches the directory of the config file, so edits that
+// replace the file (as most editors do) are seen too
+func (a *Agent) startConfigWatcher() error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create config watcher: %w", err)
+	}
+	if err := watcher.Add(filepath.Dir(a.configPath)); err != nil {
+		_ = watcher.Close()
+		return fmt.Errorf("failed to watch %s: %w", a.configPath, err)
+	}
+	watched := map[string]bool{
+		filepath.Clean(a.configPath): true,
+		filepath.Clean(strings.Replace(a.configPath, ".json", "_user.json", 1)): true,
+	}
+
+	w := &configWatcher{watcher: watcher, done: make(chan struct{})}
+	a.configWatcher = w
+	go func() {
+		defer close(w.done)
+		var debounce <-chan time.Time
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if watched[filepath.Clean(event.Name)] && event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Remove|fsnotify.Rename) != 0 {
+					debounce = time.After(configWatchDebounce)
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				getLogger(a).Warn("Config watcher error", loggerv2.Error(err))
+			case <-debounce:
+				debounce = nil
+				if _, err := a.ReloadConfig(context.Background()); err != nil {
+					getLogger(a).Warn("Failed to reload MCP config", loggerv2.Error(err))
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+// closeConfigWatcher stops the config watcher, if any
+func (a *Agent) closeConfigWatcher() {
+	if a.configWatcher != nil {
+		_ = a.configWatcher.watcher.Close()
+		<-a.configWatcher.done
+		a.configWatcher = nil
+	}
+}
@@ -0,0 +1,172 @@
+package mcpagent
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/manishiitg/mcpagent/events"
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+	"github.com/manishiitg/mcpagent/mcpclient"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+// syncEventListener records events from any goroutine
+type syncEventListener struct {
+	mu     sync.Mutex
+	events []*events.AgentEvent
+}
+
+func (l *syncEventListener) HandleEvent(_ context.Context, event *events.AgentEvent) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.events = append(l.events, event)
+	return nil
+}
+
+func (l *syncEventListener) Name() string {
+	return "sync-event-listener"
+}
+
+func (l *syncEventListener) reloaded() *events.MCPConfigReloadedEvent {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, e := range l.events {
+		if data, ok := e.Data.(*events.MCPConfigReloadedEvent); ok {
+			return data
+		}
+	}
+	return nil
+}
+
+// reloadTestAgent is connected to a "crm" server that the config file no longer lists
+func reloadTestAgent(t *testing.T) (*Agent, string) {
+	t.Helper()
+	configPath := filepath.Join(t.TempDir(), "mcp_servers.json")
+	if err := os.WriteFile(configPath, []byte(`{"mcpServers":{"crm":{"command":"crm-mcp"}}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	a := &Agent{
+		Logger:        loggerv2.NewNoop(),
+		SessionID:     "config-reload-test",
+		configPath:    configPath,
+		serverConfigs: map[string]mcpclient.MCPServerConfig{"crm": {Command: "crm-mcp"}},
+		toolToServer:  map[string]string{"lookup_customer": "crm", "my_custom": "custom"},
+		Tools:         []llmtypes.Tool{hintTestTool("lookup_customer"), hintTestTool("my_custom"), hintTestTool("search_large_output")},
+	}
+	return a, configPath
+}
+
+func TestDiffServerConfigs(t *testing.T) {
+	previous := map[string]mcpclient.MCPServerConfig{
+		"crm":    {Command: "crm-mcp"},
+		"github": {Command: "github-mcp", Args: []string{"--v1"}},
+		"slack":  {Command: "slack-mcp"},
+	}
+	current := map[string]mcpclient.MCPServerConfig{
+		"crm":    {Command: "crm-mcp"},
+		"github": {Command: "github-mcp", Args: []string{"--v2"}},
+		"jira":   {Command: "jira-mcp"},
+	}
+	result := diffServerConfigs(previous, current)
+	if strings.Join(result.Added, ",") != "jira" || strings.Join(result.Removed, ",") != "slack" || strings.Join(result.Modified, ",") != "github" {
+		t.Errorf("diff = %+v", result)
+	}
+	if diffServerConfigs(previous, previous).Changed() {
+		t.Error("identical configs should not report changes")
+	}
+}
+
+func TestReloadConfigRemovesServerTools(t *testing.T) {
+	a, configPath := reloadTestAgent(t)
+	listener := &syncEventListener{}
+	a.AddEventListener(listener)
+
+	result, err := a.ReloadConfig(context.Background())
+	if err != nil || result.Changed() {
+		t.Fatalf("unchanged config: result = %+v, err = %v", result, err)
+	}
+
+	if err := os.WriteFile(configPath, []byte(`{"mcpServers":{}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	result, err = a.ReloadConfig(context.Background())
+	if err != nil {
+		t.Fatalf("ReloadConfig: %v", err)
+	}
+	if strings.Join(result.Removed, ",") != "crm" {
+		t.Errorf("removed = %v, want crm", result.Removed)
+	}
+	if got := strings.Join(hintToolNames(a.Tools), ","); got != "my_custom,search_large_output" {
+		t.Errorf("tools = %s, want the custom and virtual tools only", got)
+	}
+	if _, ok := a.toolToServer["lookup_customer"]; ok || a.toolToServer["my_custom"] != "custom" {
+		t.Errorf("toolToServer = %v", a.toolToServer)
+	}
+	if event := listener.reloaded(); event == nil || strings.Join(event.Removed, ",") != "crm" || event.Error != "" {
+		t.Errorf("event = %+v", event)
+	}
+
+	if err := os.WriteFile(configPath, []byte(`not json`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.ReloadConfig(context.Background()); err == nil {
+		t.Error("expected an error for an invalid config")
+	}
+}
+
+func TestReloadConfigWaitsForConversationsInProgress(t *testing.T) {
+	a, configPath := reloadTestAgent(t)
+	listener := &syncEventListener{}
+	a.AddEventListener(listener)
+
+	ctx := context.Background()
+	a.enterConversation(ctx)
+	if err := os.WriteFile(configPath, []byte(`{"mcpServers":{}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	result, err := a.ReloadConfig(ctx)
+	if err != nil || !result.Deferred || strings.Join(result.Removed, ",") != "crm" {
+		t.Fatalf("reload during a conversation: result = %+v, err = %v", result, err)
+	}
+	if len(a.Tools) != 3 || a.toolToServer["lookup_customer"] != "crm" || listener.reloaded() != nil {
+		t.Error("the conversation in progress must keep its toolset")
+	}
+	// Reloading again compares against the waiting reload
+	if result, err := a.ReloadConfig(ctx); err != nil || result.Changed() || !result.Deferred {
+		t.Errorf("second reload: result = %+v, err = %v", result, err)
+	}
+
+	a.leaveConversation(ctx)
+	if got := strings.Join(hintToolNames(a.Tools), ","); got != "my_custom,search_large_output" {
+		t.Errorf("tools after the conversation = %s", got)
+	}
+	if event := listener.reloaded(); event == nil || strings.Join(event.Removed, ",") != "crm" {
+		t.Errorf("event = %+v", event)
+	}
+}
+
+func TestConfigWatcherReloadsOnChange(t *testing.T) {
+	a, configPath := reloadTestAgent(t)
+	listener := &syncEventListener{}
+	a.AddEventListener(listener)
+	if err := a.startConfigWatcher(); err != nil {
+		t.Fatalf("startConfigWatcher: %v", err)
+	}
+	defer a.closeConfigWatcher()
+
+	if err := os.WriteFile(configPath, []byte(`{"mcpServers":{}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for listener.reloaded() == nil {
+		if time.Now().After(deadline) {
+			t.Fatal("config change was not reloaded")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
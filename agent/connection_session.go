@@ -12,6 +12,7 @@ package mcpagent
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -29,16 +30,17 @@ import (
 
 // serverConnectionResult holds the per-server results from parallel connection + discovery.
 type serverConnectionResult struct {
-	serverName string
-	client     mcpclient.ClientInterface
-	tools      []llmtypes.Tool
-	toolNames  []string // tool names in order, for toolToServer mapping
-	prompts    []mcp.Prompt
-	resources  []mcp.Resource
-	wasCreated bool
-	isLazy     bool // true = tools loaded from cache, connection deferred until first tool call
-	mcpCount   int  // number of MCP tools discovered (for logging)
-	err        error
+	serverName   string
+	client       mcpclient.ClientInterface
+	tools        []llmtypes.Tool
+	toolNames    []string // tool names in order, for toolToServer mapping
+	prompts      []mcp.Prompt
+	resources    []mcp.Resource
+	instructions string // Server usage instructions from MCP initialize
+	wasCreated   bool
+	isLazy       bool // true = tools loaded from cache, connection deferred until first tool call
+	mcpCount     int  // number of MCP tools discovered (for logging)
+	err          error
 }
 
 // NewAgentConnectionWithSession creates MCP connections using the session registry.
@@ -69,6 +71,7 @@ type serverConnectionResult struct {
 //   - servers: List of server names
 //   - prompts: Map of server name to prompts
 //   - resources: Map of server name to resources
+//   - instructions: Map of server name to the usage instructions it sent on initialize
 //   - systemPrompt: Combined system prompt from servers
 //   - error: Error if connection failed
 func NewAgentConnectionWithSession(
@@ -82,7 +85,7 @@ func NewAgentConnectionWithSession(
 	disableCache bool,
 	runtimeOverrides mcpclient.RuntimeOverrides,
 	userID string,
-) (map[string]mcpclient.ClientInterface, map[string]string, []llmtypes.Tool, []string, map[string][]mcp.Prompt, map[string][]mcp.Resource, string, error) {
+) (map[string]mcpclient.ClientInterface, map[string]string, []llmtypes.Tool, []string, map[string][]mcp.Prompt, map[string][]mcp.Resource, map[string]string, string, error) {
 
 	connectionStartTime := time.Now()
 
@@ -114,31 +117,17 @@ func NewAgentConnectionWithSession(
 	// Load merged MCP configuration
 	config, err := mcpclient.LoadMergedConfig(configPath, logger)
 	if err != nil {
-		return nil, nil, nil, nil, nil, nil, "", fmt.Errorf("failed to load merged MCP config: %w", err)
+		return nil, nil, nil, nil, nil, nil, nil, "", fmt.Errorf("failed to load merged MCP config: %w", err)
 	}
 
 	// Determine which servers to connect to
-	var servers []string
-	if serverName == "all" || serverName == "" {
-		servers = config.ListServers()
-		logger.Info("Using all servers", loggerv2.Int("server_count", len(servers)))
-	} else if serverName == mcpclient.NoServers {
-		servers = []string{}
-		logger.Info("NoServers specified, returning empty connections")
-	} else {
-		for _, s := range strings.Split(serverName, ",") {
-			trimmed := strings.TrimSpace(s)
-			if trimmed != "" {
-				servers = append(servers, trimmed)
-			}
-		}
-		logger.Info("Using specific servers", loggerv2.Any("servers", servers))
-	}
+	servers := sessionServers(config, serverName)
+	logger.Info("Using servers", loggerv2.String("server_name", serverName), loggerv2.Any("servers", servers))
 
 	// Handle special case: no servers requested
 	if len(servers) == 0 {
 		logger.Info("No servers requested, returning empty result")
-		return make(map[string]mcpclient.ClientInterface), make(map[string]string), nil, servers, make(map[string][]mcp.Prompt), make(map[string][]mcp.Resource), "", nil
+		return make(map[string]mcpclient.ClientInterface), make(map[string]string), nil, servers, make(map[string][]mcp.Prompt), make(map[string][]mcp.Resource), make(map[string]string), "", nil
 	}
 
 	registry := mcpclient.GetSessionRegistry()
@@ -154,33 +143,25 @@ func NewAgentConnectionWithSession(
 			result := &results[idx]
 			result.serverName = srvName
 
-			serverConfig, err := config.GetServer(srvName)
+			serverConfig, err := sessionServerConfig(config, srvName, runtimeOverrides, userID)
 			if err != nil {
 				logger.Warn(fmt.Sprintf("Server %s not found in config, skipping", srvName),
 					loggerv2.Error(err))
 				result.err = err
 				return
 			}
-			// Apply runtime overrides if provided for this server
-			if runtimeOverrides != nil {
-				if override, hasOverride := runtimeOverrides[srvName]; hasOverride {
-					serverConfig = serverConfig.ApplyOverride(override)
-					logger.Info("Applied runtime overrides to server config",
-						loggerv2.String("server", srvName),
-						loggerv2.Any("args_replace", override.ArgsReplace),
-						loggerv2.Any("args_append", override.ArgsAppend),
-						loggerv2.Any("env_override", override.EnvOverride))
-				}
+			if override, hasOverride := runtimeOverrides[srvName]; hasOverride {
+				logger.Info("Applied runtime overrides to server config",
+					loggerv2.String("server", srvName),
+					loggerv2.Any("args_replace", override.ArgsReplace),
+					loggerv2.Any("args_append", override.ArgsAppend),
+					loggerv2.Any("env_override", override.EnvOverride))
 			}
-
-			// Override OAuth token file path for per-user isolation
 			if userID != "" && serverConfig.OAuth != nil {
-				userTokenFile := fmt.Sprintf("~/.config/mcpagent/tokens/%s/%s.json", userID, srvName)
-				serverConfig.OAuth.TokenFile = userTokenFile
 				logger.Info("Using per-user OAuth token path",
 					loggerv2.String("server", srvName),
 					loggerv2.String("user_id", userID),
-					loggerv2.String("token_file", userTokenFile))
+					loggerv2.String("token_file", serverConfig.OAuth.TokenFile))
 			}
 
 			// Lazy connection: if tool definitions are cached, defer subprocess spawn
@@ -208,6 +189,7 @@ func NewAgentConnectionWithSession(
 						result.toolNames = append(result.toolNames, toolName)
 					}
 					result.prompts = cachedEntry.Prompts
+					result.instructions = cachedEntry.Instructions
 					result.isLazy = true
 					// Store config so on-demand connect knows how to spawn the server
 					registry.StoreServerConfig(sessionID, srvName, serverConfig)
@@ -223,12 +205,13 @@ func NewAgentConnectionWithSession(
 			if err != nil {
 				logger.Error(fmt.Sprintf("Failed to get/create connection for %s", srvName), err,
 					loggerv2.String("session_id", sessionID))
-				result.err = err
+				result.err = withSentinel(ErrMCPConnectionFailed, err)
 				return
 			}
 
 			result.client = client
 			result.wasCreated = wasCreated
+			result.instructions = mcpclient.Instructions(client)
 
 			// Discover tools using ListTools (correct interface method)
 			mcpTools, err := client.ListTools(ctx)
@@ -304,12 +287,16 @@ func NewAgentConnectionWithSession(
 
 	wg.Wait()
 
-	// Merge results from all goroutines (serial — preserves server order, deduplicates tools)
+	// Merge results from all goroutines (serial — preserves server order, deduplicates tools).
+	// Tool order is deterministic: servers in the requested order (alphabetical for "all"),
+	// each server's tools sorted by name. A stable order keeps prompts, and prompt-cache
+	// hits, identical across runs; see Agent.ToolsetHash.
 	clients := make(map[string]mcpclient.ClientInterface)
 	toolToServer := make(map[string]string)
 	var allTools []llmtypes.Tool
 	prompts := make(map[string][]mcp.Prompt)
 	resources := make(map[string][]mcp.Resource)
+	instructions := make(map[string]string)
 	var connectedServers []string
 	seenTools := make(map[string]bool)
 
@@ -329,6 +316,7 @@ func NewAgentConnectionWithSession(
 		connectedServers = append(connectedServers, result.serverName)
 
 		// Merge tools with deduplication
+		sortServerTools(&result)
 		for i, llmTool := range result.tools {
 			toolName := result.toolNames[i]
 			if seenTools[toolName] {
@@ -346,6 +334,9 @@ func NewAgentConnectionWithSession(
 		if len(result.resources) > 0 {
 			resources[result.serverName] = result.resources
 		}
+		if result.instructions != "" {
+			instructions[result.serverName] = result.instructions
+		}
 
 		if result.isLazy {
 			logger.Info(fmt.Sprintf("💤 Lazy server %s (session=%s): %d tools registered, connection deferred",
@@ -397,7 +388,64 @@ func NewAgentConnectionWithSession(
 		loggerv2.Int("tools_count", len(allTools)),
 		loggerv2.String("duration", connectionDuration.String()))
 
-	return clients, toolToServer, allTools, connectedServers, prompts, resources, systemPrompt, nil
+	return clients, toolToServer, allTools, connectedServers, prompts, resources, instructions, systemPrompt, nil
+}
+
+// sessionServers returns the servers serverName selects: every configured
+// server for "all" or "", none for mcpclient.NoServers, else the
+// comma-separated names
+func sessionServers(config *mcpclient.MCPConfig, serverName string) []string {
+	switch serverName {
+	case "all", "":
+		return config.ListServers()
+	case mcpclient.NoServers:
+		return []string{}
+	}
+	var servers []string
+	for _, s := range strings.Split(serverName, ",") {
+		if trimmed := strings.TrimSpace(s); trimmed != "" {
+			servers = append(servers, trimmed)
+		}
+	}
+	return servers
+}
+
+// sessionServerConfig returns the config of srvName with its runtime override
+// applied and, for userID, the OAuth token file moved to the user's directory
+func sessionServerConfig(config *mcpclient.MCPConfig, srvName string, runtimeOverrides mcpclient.RuntimeOverrides, userID string) (mcpclient.MCPServerConfig, error) {
+	serverConfig, err := config.GetServer(srvName)
+	if err != nil {
+		return mcpclient.MCPServerConfig{}, err
+	}
+	if override, hasOverride := runtimeOverrides[srvName]; hasOverride {
+		serverConfig = serverConfig.ApplyOverride(override)
+	}
+	// Copy the OAuth config so the per-user path does not leak into the shared config
+	if userID != "" && serverConfig.OAuth != nil {
+		oauthConfig := *serverConfig.OAuth
+		oauthConfig.TokenFile = fmt.Sprintf("~/%s/%s/%s.json", userTokenDir, userID, srvName)
+		serverConfig.OAuth = &oauthConfig
+	}
+	return serverConfig, nil
+}
+
+// sortServerTools orders a server's tools by name so the merged tool list does
+// not depend on the order the server happens to list them in
+func sortServerTools(result *serverConnectionResult) {
+	order := make([]int, len(result.tools))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return result.toolNames[order[i]] < result.toolNames[order[j]]
+	})
+	tools := make([]llmtypes.Tool, len(order))
+	names := make([]string, len(order))
+	for i, idx := range order {
+		tools[i] = result.tools[idx]
+		names[i] = result.toolNames[idx]
+	}
+	result.tools, result.toolNames = tools, names
 }
 
 // resolveOnDemandMCPClient returns the MCP client for an on-demand server connection.
@@ -8,6 +8,9 @@
 // 1. Splits messages into "old" (to summarize) and "recent" (to keep intact)
 // 2. Generates a summary of old messages using LLM
 // 3. Rebuilds the message array with: system prompt + summary + recent messages
+//
+// How old messages are condensed is decided by the SummarizationStrategy (see
+// summarization_strategy.go); the default summarizes all of them in one call.
 
 package mcpagent
 
@@ -33,28 +36,28 @@ const (
 )
 
 // summarizeConversationHistory summarizes old conversation messages using LLM
-// Returns: (summary, promptTokens, completionTokens, totalTokens, cacheTokens, reasoningTokens, response, error)
-func summarizeConversationHistory(a *Agent, ctx context.Context, oldMessages []llmtypes.MessageContent) (string, int, int, int, int, int, *llmtypes.ContentResponse, error) {
-	v2Logger := a.Logger
-
-	// Build a text representation of old messages for summarization
-	conversationText := buildConversationTextForSummarization(oldMessages)
+func summarizeConversationHistory(a *Agent, ctx context.Context, oldMessages []llmtypes.MessageContent) (string, events.UsageMetrics, error) {
+	return GenerateSummary(a, ctx, buildSummarizationPrompt(), buildConversationTextForSummarization(oldMessages))
+}
 
-	// Create summarization prompt
-	summaryPrompt := buildSummarizationPrompt()
+// GenerateSummary asks the agent's LLM to condense text following instructions
+// (the system prompt of the call). The call is added to the agent's token
+// usage. Custom SummarizationStrategy implementations use it for their LLM calls.
+func GenerateSummary(a *Agent, ctx context.Context, instructions, text string) (string, events.UsageMetrics, error) {
+	v2Logger := a.Logger
 
 	// Create messages for summarization LLM call
 	summaryMessages := []llmtypes.MessageContent{
 		{
 			Role: llmtypes.ChatMessageTypeSystem,
 			Parts: []llmtypes.ContentPart{
-				llmtypes.TextContent{Text: summaryPrompt},
+				llmtypes.TextContent{Text: instructions},
 			},
 		},
 		{
 			Role: llmtypes.ChatMessageTypeHuman,
 			Parts: []llmtypes.ContentPart{
-				llmtypes.TextContent{Text: conversationText},
+				llmtypes.TextContent{Text: text},
 			},
 		},
 	}
@@ -65,65 +68,73 @@ func summarizeConversationHistory(a *Agent, ctx context.Context, oldMessages []l
 	}
 
 	v2Logger.Info("📊 [CONTEXT_SUMMARIZATION] Generating conversation summary via LLM",
-		loggerv2.Int("old_messages_count", len(oldMessages)),
-		loggerv2.Int("conversation_text_length", len(conversationText)),
+		loggerv2.Int("conversation_text_length", len(text)),
 		loggerv2.String("model_id", a.ModelID))
 
 	resp, _, err := GenerateContentWithRetry(a, ctx, summaryMessages, summaryOpts, 0)
 	if err != nil {
-		return "", 0, 0, 0, 0, 0, nil, fmt.Errorf("failed to generate conversation summary: %w", err)
+		return "", events.UsageMetrics{}, fmt.Errorf("failed to generate conversation summary: %w", err)
 	}
 
 	if resp == nil || len(resp.Choices) == 0 || resp.Choices[0].Content == "" {
-		return "", 0, 0, 0, 0, 0, nil, fmt.Errorf("empty summary generated")
+		return "", events.UsageMetrics{}, fmt.Errorf("empty summary generated")
 	}
 
 	summary := resp.Choices[0].Content
 
 	// Extract token usage from response
-	var promptTokens, completionTokens, totalTokens, cacheTokens, reasoningTokens int
+	var usage events.UsageMetrics
 	if resp.Usage != nil {
-		promptTokens = resp.Usage.InputTokens
-		completionTokens = resp.Usage.OutputTokens
-		totalTokens = resp.Usage.TotalTokens
+		usage.PromptTokens = resp.Usage.InputTokens
+		usage.CompletionTokens = resp.Usage.OutputTokens
+		usage.TotalTokens = resp.Usage.TotalTokens
 		// If total is 0, calculate it
-		if totalTokens == 0 {
-			totalTokens = promptTokens + completionTokens
+		if usage.TotalTokens == 0 {
+			usage.TotalTokens = usage.PromptTokens + usage.CompletionTokens
 		}
 		// Extract cache tokens
 		if resp.Usage.CacheTokens != nil {
-			cacheTokens = *resp.Usage.CacheTokens
+			usage.CacheTokens = *resp.Usage.CacheTokens
 		}
 		// Extract reasoning tokens
 		if resp.Usage.ReasoningTokens != nil {
-			reasoningTokens = *resp.Usage.ReasoningTokens
+			usage.ReasoningTokens = *resp.Usage.ReasoningTokens
 		}
 	}
 
 	// Fallback to GenerationInfo for cache/reasoning tokens if not in Usage
-	if (cacheTokens == 0 || reasoningTokens == 0) && len(resp.Choices) > 0 && resp.Choices[0].GenerationInfo != nil {
+	if (usage.CacheTokens == 0 || usage.ReasoningTokens == 0) && len(resp.Choices) > 0 && resp.Choices[0].GenerationInfo != nil {
 		genInfo := resp.Choices[0].GenerationInfo
-		if cacheTokens == 0 && genInfo.CachedContentTokens != nil {
-			cacheTokens = *genInfo.CachedContentTokens
+		if usage.CacheTokens == 0 && genInfo.CachedContentTokens != nil {
+			usage.CacheTokens = *genInfo.CachedContentTokens
 		}
-		if reasoningTokens == 0 && genInfo.ReasoningTokens != nil {
-			reasoningTokens = *genInfo.ReasoningTokens
+		if usage.ReasoningTokens == 0 && genInfo.ReasoningTokens != nil {
+			usage.ReasoningTokens = *genInfo.ReasoningTokens
 		}
 	}
 
 	v2Logger.Info("✅ [CONTEXT_SUMMARIZATION] Conversation summary generated successfully",
 		loggerv2.Int("summary_length_chars", len(summary)),
-		loggerv2.Int("prompt_tokens", promptTokens),
-		loggerv2.Int("completion_tokens", completionTokens),
-		loggerv2.Int("total_tokens", totalTokens),
-		loggerv2.Int("cache_tokens", cacheTokens),
-		loggerv2.Int("reasoning_tokens", reasoningTokens))
+		loggerv2.Int("prompt_tokens", usage.PromptTokens),
+		loggerv2.Int("completion_tokens", usage.CompletionTokens),
+		loggerv2.Int("total_tokens", usage.TotalTokens),
+		loggerv2.Int("cache_tokens", usage.CacheTokens),
+		loggerv2.Int("reasoning_tokens", usage.ReasoningTokens))
 
-	return summary, promptTokens, completionTokens, totalTokens, cacheTokens, reasoningTokens, resp, nil
+	// Accumulate summarization token usage into agent's cumulative tracking
+	// This ensures summarization LLM calls are included in total token usage
+	a.accumulateTokenUsage(ctx, usage, resp, 0) // Use turn 0 for summarization calls
+
+	return summary, usage, nil
 }
 
 // buildConversationTextForSummarization converts messages to a text format for summarization
 func buildConversationTextForSummarization(messages []llmtypes.MessageContent) string {
+	return strings.Join(conversationTextEntries(messages), "\n\n")
+}
+
+// conversationTextEntries renders each message with content as one "[Turn N] Role: content" entry
+func conversationTextEntries(messages []llmtypes.MessageContent) []string {
 	var parts []string
 
 	for i, msg := range messages {
@@ -140,7 +151,7 @@ func buildConversationTextForSummarization(messages []llmtypes.MessageContent) s
 		}
 	}
 
-	return strings.Join(parts, "\n\n")
+	return parts
 }
 
 // getRoleLabel returns a human-readable label for a message role
@@ -378,34 +389,27 @@ func rebuildMessagesWithSummary(
 		return messages, nil
 	}
 
+	strategy := a.summarizationStrategy
+	if strategy == nil {
+		strategy = FullSummarization{}
+	}
 	v2Logger.Info("📊 [CONTEXT_SUMMARIZATION] Starting summarization",
 		loggerv2.Int("old_messages_to_summarize", len(oldMessages)),
-		loggerv2.Any("has_system_message", systemMessage != nil))
+		loggerv2.Any("has_system_message", systemMessage != nil),
+		loggerv2.String("strategy", fmt.Sprintf("%T", strategy)))
 
-	// Generate summary
-	summary, promptTokens, completionTokens, totalTokens, cacheTokens, reasoningTokens, summaryResp, err := summarizeConversationHistory(a, ctx, oldMessages)
+	result, err := strategy.Summarize(ctx, a, oldMessages)
 	if err != nil {
 		// Emit error event
 		errorEvent := events.NewContextSummarizationErrorEvent(err.Error(), len(messages), keepLastMessages)
 		a.EmitTypedEvent(ctx, errorEvent)
 		return nil, fmt.Errorf("failed to summarize conversation history: %w", err)
 	}
+	summary, usage := result.Summary, result.Usage
 
-	// Accumulate summarization token usage into agent's cumulative tracking
-	// This ensures summarization LLM calls are included in total token usage
-	v2Logger.Info("📊 [CONTEXT_SUMMARIZATION] Accumulating summarization tokens",
-		loggerv2.Int("prompt_tokens", promptTokens),
-		loggerv2.Int("completion_tokens", completionTokens),
-		loggerv2.Int("total_tokens", totalTokens),
-		loggerv2.Int("cache_tokens", cacheTokens),
-		loggerv2.Int("reasoning_tokens", reasoningTokens))
-	a.accumulateTokenUsage(ctx, events.UsageMetrics{
-		PromptTokens:     promptTokens,
-		CompletionTokens: completionTokens,
-		TotalTokens:      totalTokens,
-		CacheTokens:      cacheTokens,
-		ReasoningTokens:  reasoningTokens,
-	}, summaryResp, 0) // Use turn 0 for summarization calls
+	if summary != "" {
+		a.recordSessionSummary(summary)
+	}
 
 	// Build new messages array
 	newMessages := []llmtypes.MessageContent{}
@@ -415,17 +419,8 @@ func rebuildMessagesWithSummary(
 		newMessages = append(newMessages, *systemMessage)
 	}
 
-	// 2. Add summary as a user message
-	summaryMessage := llmtypes.MessageContent{
-		Role: llmtypes.ChatMessageTypeHuman,
-		Parts: []llmtypes.ContentPart{
-			llmtypes.TextContent{
-				Text: fmt.Sprintf("=== CONVERSATION SUMMARY (Previous %d messages) ===\n\n%s\n\n=== END SUMMARY ===",
-					len(oldMessages), summary),
-			},
-		},
-	}
-	newMessages = append(newMessages, summaryMessage)
+	// 2. Add what the strategy made of the old messages (usually one summary message)
+	newMessages = append(newMessages, result.Messages...)
 
 	// 3. Add recent messages (unchanged)
 	newMessages = append(newMessages, recentMessages...)
@@ -448,11 +443,11 @@ func rebuildMessagesWithSummary(
 		splitIndex,
 		desiredSplitIndex,
 		summary, // Include summary in event for observability
-		promptTokens,
-		completionTokens,
-		totalTokens,
-		cacheTokens,
-		reasoningTokens,
+		usage.PromptTokens,
+		usage.CompletionTokens,
+		usage.TotalTokens,
+		usage.CacheTokens,
+		usage.ReasoningTokens,
 	)
 	a.EmitTypedEvent(ctx, completedEvent)
 
@@ -481,19 +476,10 @@ func ShouldSummarizeOnTokenThreshold(a *Agent, currentTokenUsage int) (bool, err
 		return false, nil
 	}
 
-	// Get model metadata to determine context window
-	if a.LLM == nil {
-		return false, fmt.Errorf("LLM is nil, cannot determine context window")
-	}
-
-	modelID := a.ModelID
-	if modelID == "" {
-		modelID = a.LLM.GetModelID()
-	}
-
-	metadata, err := a.LLM.GetModelMetadata(modelID)
-	if err != nil || metadata == nil {
-		// Metadata unavailable: caller can fall back to max-turns (treat as "no decision" not hard error)
+	// Determine the model's context window (see model_context.go)
+	contextWindow := a.GetModelContextWindow()
+	if contextWindow <= 0 {
+		// Window unknown: caller can fall back to max-turns (treat as "no decision" not hard error)
 		return false, nil
 	}
 
@@ -502,7 +488,7 @@ func ShouldSummarizeOnTokenThreshold(a *Agent, currentTokenUsage int) (bool, err
 	if percent <= 0 || percent > 1 {
 		percent = 0.8
 	}
-	thresholdTokens := int(float64(metadata.ContextWindow) * percent)
+	thresholdTokens := int(float64(contextWindow) * percent)
 
 	// Check if current usage exceeds threshold
 	shouldSummarize := currentTokenUsage >= thresholdTokens
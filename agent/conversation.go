@@ -13,6 +13,7 @@ package mcpagent
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"os"
@@ -21,6 +22,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/manishiitg/mcpagent/events"
@@ -106,6 +108,7 @@ func (a *Agent) annotateUnifiedCompletionEvent(event *events.UnifiedCompletionEv
 	}
 	event.Metadata["provider"] = string(a.provider)
 	event.Metadata["model_id"] = a.ModelID
+	event.Metadata = a.tagExperiment(event.Metadata)
 	if isCodingCLIProvider(a.provider, a.ModelID) {
 		event.Metadata["coding_agent_terminal_format"] = true
 	}
@@ -115,10 +118,13 @@ func (a *Agent) annotateUnifiedCompletionEvent(event *events.UnifiedCompletionEv
 func isVirtualTool(toolName string) bool {
 	// Check hardcoded virtual tools (includes all possible virtual tools)
 	virtualTools := []string{
-		"get_prompt", "get_resource",
+		"get_prompt", "use_prompt", "get_resource", "read_resource",
 		"search_large_output",
 		"get_api_spec",                                              // Code execution mode tools
 		"search_tools", "add_tool", "remove_tool", "show_all_tools", // Tool search mode tools
+		"compact_context",                                          // On-demand summarization
+		"apply_patch", "insert_at_line", "replace_between_markers", // Workspace edit tools
+		"update_plan", // Plan tracking
 	}
 	for _, vt := range virtualTools {
 		if vt == toolName {
@@ -155,6 +161,8 @@ func getToolExecutionTimeout(a *Agent) time.Duration {
 // callToolWithTimeoutWrapper wraps MCP client CallTool with explicit timeout monitoring.
 // This ensures timeouts work even if the underlying library doesn't properly respect context cancellation.
 // The wrapper runs the call in a goroutine and monitors the context deadline explicitly.
+// If the call times out after the server reported progress, the progress is returned
+// as a partial result instead of a bare timeout error.
 func callToolWithTimeoutWrapper(
 	ctx context.Context,
 	client mcpclient.ClientInterface,
@@ -163,6 +171,14 @@ func callToolWithTimeoutWrapper(
 	logger loggerv2.Logger,
 	serverName string,
 ) (*mcp.CallToolResult, error) {
+	// Collect progress notifications so a timeout can still return partial output
+	// (the caller may already collect them to emit progress events)
+	progress := mcpclient.ToolProgressCollectorFrom(ctx)
+	if progress == nil {
+		progress = mcpclient.NewToolProgressCollector()
+		ctx = mcpclient.WithToolProgressCollector(ctx, progress)
+	}
+
 	// Get deadline from context
 	deadline, hasDeadline := ctx.Deadline()
 	if !hasDeadline {
@@ -261,6 +277,11 @@ func callToolWithTimeoutWrapper(
 		select {
 		case res := <-resultChan:
 			// Call completed
+			if errors.Is(res.err, context.DeadlineExceeded) {
+				if partial := partialToolResultOnTimeout(toolName, serverName, time.Since(startTime), progress, logger); partial != nil {
+					return partial, nil
+				}
+			}
 			return res.res, res.err
 
 		case <-ctx.Done():
@@ -270,13 +291,44 @@ func callToolWithTimeoutWrapper(
 				loggerv2.String("server_name", serverName),
 				loggerv2.String("elapsed", time.Since(startTime).String()),
 				loggerv2.Error(ctx.Err()))
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				if partial := partialToolResultOnTimeout(toolName, serverName, time.Since(startTime), progress, logger); partial != nil {
+					return partial, nil
+				}
+			}
 			return nil, ctx.Err()
 		}
 	}
 }
 
+// partialToolResultOnTimeout returns the progress a timed-out tool reported,
+// annotated as partial, or nil if it reported none
+func partialToolResultOnTimeout(toolName, serverName string, elapsed time.Duration, progress *mcpclient.ToolProgressCollector, logger loggerv2.Logger) *mcp.CallToolResult {
+	updates := progress.Updates()
+	partial := mcpclient.PartialToolResult(toolName, elapsed, updates)
+	if partial != nil {
+		logger.Info("🔧 [TOOL_TIMEOUT] Returning partial tool result from progress notifications",
+			loggerv2.String("tool_name", toolName),
+			loggerv2.String("server_name", serverName),
+			loggerv2.Int("progress_updates", len(updates)))
+	}
+	return partial
+}
+
+// withToolProgressEvents returns a context in which the progress notifications
+// of an MCP tool call are emitted as ToolCallProgress events while it runs
+func (a *Agent) withToolProgressEvents(ctx context.Context, turn int, toolName, serverName, toolCallID string) context.Context {
+	start := time.Now()
+	progress := mcpclient.NewToolProgressCollectorWithCallback(func(update mcpclient.ToolProgress) {
+		progressEvent := events.NewToolCallProgressEvent(turn, toolName, serverName, update.Progress, update.Total, update.Message, time.Since(start))
+		progressEvent.ToolCallID = toolCallID
+		a.EmitTypedEvent(ctx, progressEvent)
+	})
+	return mcpclient.WithToolProgressCollector(ctx, progress)
+}
+
 // ensureSystemPrompt ensures that the system prompt is included in the messages
-func ensureSystemPrompt(a *Agent, messages []llmtypes.MessageContent) []llmtypes.MessageContent {
+func ensureSystemPrompt(ctx context.Context, a *Agent, messages []llmtypes.MessageContent) []llmtypes.MessageContent {
 	// Always use the agent's current system prompt — it reflects the latest mode
 	// (code execution, tool search, etc.) which may differ from a stale system
 	// message carried over in conversation history from a previous turn.
@@ -296,6 +348,27 @@ func ensureSystemPrompt(a *Agent, messages []llmtypes.MessageContent) []llmtypes
 		}
 	}
 
+	// Glossary terminology contract (see glossary.go); the final answer is
+	// also rewritten after the postprocess stage.
+	if section := a.glossaryPromptSection(); section != "" {
+		systemPrompt = systemPrompt + "\n" + section
+	}
+
+	// Facts recalled from long-term memory (see long_term_memory.go)
+	if section := a.memoryPromptSection(ctx); section != "" {
+		systemPrompt = systemPrompt + "\n" + section
+	}
+
+	// Final answer schema from the agent's preset (see preset.go)
+	if section := a.outputSchemaPromptSection(); section != "" {
+		systemPrompt = systemPrompt + "\n" + section
+	}
+
+	// Required sections of a lenient answer contract (see answer_contract.go)
+	if section := a.answerContractPromptSection(); section != "" {
+		systemPrompt = systemPrompt + "\n" + section
+	}
+
 	systemMessage := llmtypes.MessageContent{
 		Role:  llmtypes.ChatMessageTypeSystem,
 		Parts: []llmtypes.ContentPart{llmtypes.TextContent{Text: systemPrompt}},
@@ -320,7 +393,71 @@ func ensureSystemPrompt(a *Agent, messages []llmtypes.MessageContent) []llmtypes
 }
 
 // AskWithHistory runs an interaction using the provided message history (multi-turn conversation).
-func AskWithHistory(a *Agent, ctx context.Context, messages []llmtypes.MessageContent) (string, []llmtypes.MessageContent, error) {
+func AskWithHistory(a *Agent, ctx context.Context, messages []llmtypes.MessageContent, opts ...CallOption) (string, []llmtypes.MessageContent, error) {
+	// A config reload is not swapped in while the conversation runs
+	a.enterConversation(ctx)
+	defer a.leaveConversation(ctx)
+	ctx, call := a.beginCall(ctx, opts)
+	defer a.endCall(call)
+	// Roll trace sampling before any event is emitted so the whole
+	// conversation is either traced or not.
+	ctx, call.traceSampling = a.beginTraceSampling(ctx)
+	startTime := time.Now()
+	a.recallMemory(ctx, messages)
+	pipeline := a.AskPipeline()
+	answer, updatedMessages, err := askWithHistory(a, ctx, messages, pipeline)
+	if err == nil {
+		answer, updatedMessages, err = pipeline.Postprocess.Postprocess(ctx, a, answer, updatedMessages)
+	}
+	err = classifyError(err)
+	pipeline.Finalize.Finalize(ctx, a, answer, updatedMessages, err)
+	if err != nil {
+		a.recordPostMortem(updatedMessages, err)
+		if errors.Is(ctx.Err(), context.Canceled) {
+			a.emitConversationCancelled(ctx, messages, updatedMessages, startTime)
+		}
+	} else {
+		a.rememberConversation(ctx, updatedMessages)
+		a.emitConversationEnd(ctx, messages, updatedMessages, startTime, answer, ConversationCompletedStatus, "")
+	}
+	return answer, updatedMessages, err
+}
+
+// ConversationCompletedStatus is the ConversationEnd status of a conversation
+// that returned an answer
+const ConversationCompletedStatus = "completed"
+
+// ConversationCancelledStatus is the ConversationEnd status of a conversation
+// whose context was cancelled (e.g. through the gRPC CancelRequest RPC)
+const ConversationCancelledStatus = "cancelled"
+
+// emitConversationCancelled emits the ConversationEnd event of a cancelled
+// conversation. Its error is the cancellation cause, if one was given.
+func (a *Agent) emitConversationCancelled(ctx context.Context, messages, updatedMessages []llmtypes.MessageContent, startTime time.Time) {
+	a.emitConversationEnd(ctx, messages, updatedMessages, startTime, "", ConversationCancelledStatus, context.Cause(ctx).Error())
+}
+
+// emitConversationEnd emits the ConversationEnd event with the model that
+// answered last (a fallback model when the primary failed)
+func (a *Agent) emitConversationEnd(ctx context.Context, messages, updatedMessages []llmtypes.MessageContent, startTime time.Time, result, status, errMsg string) {
+	turns := 0
+	if len(updatedMessages) > len(messages) {
+		for _, msg := range updatedMessages[len(messages):] {
+			if msg.Role == llmtypes.ChatMessageTypeAI {
+				turns++
+			}
+		}
+	}
+	event := events.NewConversationEndEvent(lastUserText(messages), result, time.Since(startTime), turns, status, errMsg)
+	event.ModelID = a.ModelID
+	event.Provider = string(a.provider)
+	// The conversation context may be done; listeners still need the event
+	a.EmitTypedEvent(context.WithoutCancel(ctx), event)
+}
+
+// askWithHistory runs the turn loop with the pipeline's prepare, route,
+// generate and dispatch stages (see pipeline.go)
+func askWithHistory(a *Agent, ctx context.Context, messages []llmtypes.MessageContent, pipeline AskPipeline) (string, []llmtypes.MessageContent, error) {
 	// Use agent's logger if available, otherwise use default
 	v2Logger := a.Logger
 	v2Logger.Debug("Entered AskWithHistory", loggerv2.Int("message_count", len(messages)))
@@ -355,8 +492,11 @@ func AskWithHistory(a *Agent, ctx context.Context, messages []llmtypes.MessageCo
 	// This ensures hierarchy reflects the actual calling context
 	a.initializeHierarchyForContext(ctx)
 
-	// Ensure system prompt is included in messages
-	messages = ensureSystemPrompt(a, messages)
+	// Prepare stage: ensure system prompt is included in messages
+	messages, prepareErr := pipeline.Prepare.Prepare(ctx, a, messages)
+	if prepareErr != nil {
+		return "", messages, fmt.Errorf("prepare stage: %w", prepareErr)
+	}
 
 	// Log prompts to disk when LOG_AGENT_PROMPTS is enabled:
 	// - Start: system prompt + user message (written now)
@@ -479,6 +619,10 @@ func AskWithHistory(a *Agent, ctx context.Context, messages []llmtypes.MessageCo
 
 	// Emit user message event - this will appear in basic events (user_message is not in ADVANCED_MODE_EVENTS)
 	userMessageEvent := events.NewUserMessageEvent(0, userMessageForEvent, "user")
+	if len(messages) > 0 && messages[len(messages)-1].Role == llmtypes.ChatMessageTypeHuman {
+		// Images and documents of the current query (see multimodal.go), metadata only
+		userMessageEvent.Attachments = events.AttachmentInfoFromParts(messages[len(messages)-1].Parts)
+	}
 	a.EmitTypedEvent(ctx, userMessageEvent)
 	v2Logger.Debug("🔄 Emitted user_message event for first user message",
 		loggerv2.String("content", userMessageForEvent),
@@ -514,38 +658,37 @@ func AskWithHistory(a *Agent, ctx context.Context, messages []llmtypes.MessageCo
 	// Use conversationMetadata to avoid unused variable error
 	_ = conversationMetadata
 
-	// Emit conversation start event with correlation (child of agent start)
-	conversationStartEvent := events.NewConversationStartEventWithCorrelation(lastUserMessage, a.systemPrompt, len(a.Tools), serverList, traceID, agentStartEventID)
-	a.EmitTypedEvent(ctx, conversationStartEvent)
-
-	// Store conversation start event ID for correlation
-	// conversationStartEventID := conversationStartEvent.EventID
-	// Metadata for processing tracking
-
-	// Reset filtered tools at the start of each conversation to ensure fresh evaluation.
-	// In tool search mode, use getToolsForToolSearchMode() to include discovered tools
-	if a.UseToolSearchMode {
-		a.filteredTools = a.applyToolAllowList(a.getToolsForToolSearchMode())
-		v2Logger.Debug("🔍 Tool search mode: using getToolsForToolSearchMode()",
-			loggerv2.Int("filtered_count", len(a.filteredTools)))
-	} else {
-		a.filteredTools = a.applyToolAllowList(a.Tools) // Start with all tools, apply allow list if set
-		v2Logger.Debug("🔧 Normal/Code execution mode: using a.Tools",
-			loggerv2.Int("tools_count", len(a.Tools)),
-			loggerv2.Int("filtered_count", len(a.filteredTools)))
-		// Log tool names for debugging
-		toolNames := make([]string, 0, len(a.filteredTools))
-		for _, t := range a.filteredTools {
-			if t.Function != nil {
-				toolNames = append(toolNames, t.Function.Name)
-			}
+	// Route stage: reset filtered tools at the start of each conversation to ensure fresh evaluation.
+	// In tool search mode the default route uses getToolsForToolSearchMode() to include discovered tools
+	a.filteredTools = pipeline.Route.Route(ctx, a, messages)
+	a.resetToolFailures()
+	// An agent that already spent its budget makes no further LLM calls
+	if err := a.checkBudget(ctx, 0); err != nil {
+		return "", messages, err
+	}
+	v2Logger.Debug("🔧 Routed tools for conversation",
+		loggerv2.Any("tool_search_mode", a.UseToolSearchMode),
+		loggerv2.Int("tools_count", len(a.Tools)),
+		loggerv2.Int("filtered_count", len(a.filteredTools)))
+	// Log tool names for debugging
+	routedToolNames := make([]string, 0, len(a.filteredTools))
+	for _, t := range a.filteredTools {
+		if t.Function != nil {
+			routedToolNames = append(routedToolNames, t.Function.Name)
 		}
-		v2Logger.Debug("🔧 Available tools", loggerv2.Any("tools", toolNames))
 	}
+	v2Logger.Debug("🔧 Available tools", loggerv2.Any("tools", routedToolNames))
 
 	// filteredTools was set above (tool-search mode or full Tools), so what
 	// was selected during pre-call setup is what the LLM will see.
 
+	// Emit conversation start event with correlation (child of agent start).
+	// Emitted after the toolset is resolved so ToolsetHash matches what the LLM sees.
+	conversationStartEvent := events.NewConversationStartEventWithCorrelation(lastUserMessage, a.systemPrompt, len(a.Tools), serverList, traceID, agentStartEventID)
+	conversationStartEvent.TraceSampling = currentCall(ctx).traceSampling
+	conversationStartEvent.ToolsetHash = a.ToolsetHash()
+	a.EmitTypedEvent(ctx, conversationStartEvent)
+
 	// Calculate token count for the system prompt if tool output handler is available
 	var tokenCount int
 	if a.ModelID != "" && a.shouldUseWrapperTokenCounting() {
@@ -558,11 +701,18 @@ func AskWithHistory(a *Agent, ctx context.Context, messages []llmtypes.MessageCo
 	loopDetector := NewToolLoopDetector(DefaultLoopDetectionThreshold)
 
 	var lastResponse string
+	var grounding *events.GroundingReport
+	groundingReasked := false
+	contractRepairs := 0
+	guardRepairs := 0
 	for turn := 0; ; turn++ {
 		if a.MaxTurns > 0 && turn >= a.MaxTurns {
 			break
 		}
 
+		// Checkpoint the completed turns for crash recovery
+		a.autosave(ctx, messages, turn, lastUserMessage)
+
 		// Extract the last message from the conversation (could be user, assistant, or tool)
 		var lastMessage string
 
@@ -597,6 +747,11 @@ func AskWithHistory(a *Agent, ctx context.Context, messages []llmtypes.MessageCo
 			return "", messages, fmt.Errorf("conversation cancelled: %w", agentCtx.Err())
 		}
 
+		// Replace repeated identical tool results with references (see tool_result_dedup.go)
+		if a.ToolResultDeduplication != nil {
+			messages = deduplicateToolResults(a, ctx, messages, turn+1)
+		}
+
 		// Use the current messages that include tool results from previous turns
 		llmMessages := messages
 
@@ -660,6 +815,12 @@ func AskWithHistory(a *Agent, ctx context.Context, messages []llmtypes.MessageCo
 			}
 		}
 
+		// Summarization requested by SummarizeNow or compact_context (see compact_context.go)
+		if summarized, ok := a.summarizeOnRequest(ctx, llmMessages, turn); ok {
+			llmMessages = summarized
+			messages = summarized
+		}
+
 		// Check if token-based summarization should be triggered
 		// Support both percentage-based and fixed token thresholds (OR logic)
 		if a.EnableContextSummarization && (a.SummarizeOnTokenThreshold || a.SummarizeOnFixedTokenThreshold) {
@@ -669,21 +830,17 @@ func AskWithHistory(a *Agent, ctx context.Context, messages []llmtypes.MessageCo
 			a.tokenTrackingMutex.RLock()
 			currentInputTokens := a.currentContextWindowUsage // Actual from previous LLM call
 			a.tokenTrackingMutex.RUnlock()
+			// The previous call did not see the tool results added since; count
+			// them with the model's tokenizer so a large result triggers now
+			if estimate := a.EstimateTokens(llmMessages); estimate > currentInputTokens {
+				currentInputTokens = estimate
+			}
 
 			// Get model metadata for detailed logging
-			var modelContextWindow int
 			var thresholdTokens int
-			modelID := a.ModelID
-			if modelID == "" && a.LLM != nil {
-				modelID = a.LLM.GetModelID()
-			}
-			if a.LLM != nil {
-				if metadata, err := a.LLM.GetModelMetadata(modelID); err == nil && metadata != nil {
-					modelContextWindow = metadata.ContextWindow
-					if a.SummarizeOnTokenThreshold {
-						thresholdTokens = int(float64(metadata.ContextWindow) * a.TokenThresholdPercent)
-					}
-				}
+			modelContextWindow := a.GetModelContextWindow()
+			if a.SummarizeOnTokenThreshold {
+				thresholdTokens = int(float64(modelContextWindow) * a.TokenThresholdPercent)
 			}
 
 			usagePercent := 0.0
@@ -802,6 +959,11 @@ func AskWithHistory(a *Agent, ctx context.Context, messages []llmtypes.MessageCo
 				opts = append(opts, llmtypes.WithToolChoice(toolChoiceOpt))
 			}
 		}
+		if schema := currentCall(ctx).responseSchema; schema != nil {
+			opts = append(opts, llmtypes.WithJSONSchema(schema.Schema, schema.Name, schema.Description, schema.Strict))
+		}
+		maxTokensHint := maxTokensHintForTurn(len(a.filteredTools) > 0)
+		opts = append(opts, a.outputControlOptions(llmMessages, maxTokensHint)...)
 		toolNames := make([]string, len(a.filteredTools))
 		for i, tool := range a.filteredTools {
 			toolNames[i] = tool.Function.Name
@@ -847,7 +1009,23 @@ func AskWithHistory(a *Agent, ctx context.Context, messages []llmtypes.MessageCo
 		// Use GenerateContentWithRetry for robust fallback handling
 		log.Printf("[LATENCY_DEBUG] Turn %d | T+%dms | Sending to LLM API | provider=%s model=%s",
 			turn+1, time.Since(conversationStartTime).Milliseconds(), a.provider, a.ModelID)
-		resp, usage, genErr := GenerateContentWithRetry(a, ctx, llmMessages, opts, turn)
+		resp, usage, genErr := pipeline.Generate.Generate(ctx, a, llmMessages, opts, turn)
+		// The orchestration budget can cut off a turn that turned out to be the
+		// final answer; retry once with the synthesis budget if it is larger.
+		if genErr == nil && maxTokensHint == MaxTokensHintToolOrchestration && isTruncatedResponse(resp) {
+			if maxTokens := a.callMaxTokens(llmMessages, MaxTokensHintFinalSynthesis); maxTokens > a.callMaxTokens(llmMessages, maxTokensHint) {
+				v2Logger.Info("Response truncated by tool-orchestration max tokens, retrying with synthesis budget",
+					loggerv2.Int("turn", turn+1),
+					loggerv2.Int("max_tokens", maxTokens))
+				// The truncated call was billed too; count it before its response is replaced
+				a.accumulateTokenUsage(ctx, events.UsageMetrics{
+					PromptTokens:     usage.InputTokens,
+					CompletionTokens: usage.OutputTokens,
+					TotalTokens:      usage.TotalTokens,
+				}, resp, turn+1)
+				resp, usage, genErr = pipeline.Generate.Generate(ctx, a, llmMessages, append(opts, a.outputControlOptions(llmMessages, MaxTokensHintFinalSynthesis)...), turn)
+			}
+		}
 		log.Printf("[LATENCY_DEBUG] Turn %d | T+%dms | LLM API responded | llm_duration=%dms err=%v",
 			turn+1, time.Since(conversationStartTime).Milliseconds(), time.Since(llmStartTime).Milliseconds(), genErr)
 
@@ -881,6 +1059,11 @@ func AskWithHistory(a *Agent, ctx context.Context, messages []llmtypes.MessageCo
 			}, resp)
 		}
 
+		// Abort once the cumulative cost crossed the budget (see model_pricing.go)
+		if err := a.checkBudget(ctx, turn+1); err != nil {
+			return "", messages, err
+		}
+
 		// Check for context cancellation after LLM generation
 		if agentCtx.Err() != nil {
 			v2Logger.Debug("Context cancelled after LLM generation",
@@ -906,7 +1089,7 @@ func AskWithHistory(a *Agent, ctx context.Context, messages []llmtypes.MessageCo
 					loggerv2.Int("turn", turn+1))
 
 				// Try fallback models by calling GenerateContentWithRetry again with fallback
-				fallbackResp, fallbackUsage, fallbackErr := GenerateContentWithRetry(a, ctx, llmMessages, opts, turn)
+				fallbackResp, fallbackUsage, fallbackErr := pipeline.Generate.Generate(ctx, a, llmMessages, opts, turn)
 
 				if fallbackErr == nil && fallbackResp != nil && len(fallbackResp.Choices) > 0 &&
 					fallbackResp.Choices[0].Content != "" {
@@ -952,6 +1135,7 @@ func AskWithHistory(a *Agent, ctx context.Context, messages []llmtypes.MessageCo
 					a.EmitTypedEvent(ctx, llmErrorEvent)
 
 					conversationErrorEvent := events.NewConversationErrorEvent(lastUserMessage, genErr.Error(), turn+1, "conversation_error", time.Since(conversationStartTime))
+					conversationErrorEvent.ErrorCode = ErrorCode(genErr)
 					a.EmitTypedEvent(ctx, conversationErrorEvent)
 				}
 
@@ -1012,689 +1196,40 @@ func AskWithHistory(a *Agent, ctx context.Context, messages []llmtypes.MessageCo
 				})
 			}
 
-			// 2. For each tool call, execute and append the tool result as a new message
-			// Use parallel execution when enabled and there are multiple tool calls
+			// 3. Dispatch stage: execute each tool call and append its result as a new message.
+			// The default dispatcher runs them in parallel when enabled and there are several.
 			toolDispatchMode := "sequential"
 			if a.EnableParallelToolExecution && len(choice.ToolCalls) > 1 {
 				toolDispatchMode = "parallel"
 			}
 			v2Logger.Info(fmt.Sprintf("⏱️  TOOL DISPATCH START - Time: %s, Count: %d, Mode: %s, Turn: %d",
 				time.Now().Format(time.RFC3339), len(choice.ToolCalls), toolDispatchMode, turn+1))
-			if a.EnableParallelToolExecution && len(choice.ToolCalls) > 1 {
-				var parallelErr error
-				messages, parallelErr = executeToolCallsParallel(ctx, a, choice.ToolCalls, messages, turn, traceID, conversationStartTime, lastUserMessage, loopDetector, agentCtx)
-				if parallelErr != nil {
-					return "", messages, parallelErr
-				}
-				// Drain and inject any pending steer messages from the user
-				if steerMsgs := a.DrainSteerMessages(); len(steerMsgs) > 0 {
-					messages = injectSteerMessages(ctx, a, messages, steerMsgs, turn, "Injected steer message after parallel tool execution")
-				}
-				// After parallel execution, continue to next turn
-				continue
+			var dispatchErr error
+			dispatched := len(messages)
+			messages, dispatchErr = pipeline.DispatchTools.DispatchTools(ctx, a, &ToolDispatch{
+				ToolCalls:    choice.ToolCalls,
+				Messages:     messages,
+				Turn:         turn,
+				TraceID:      traceID,
+				Question:     lastUserMessage,
+				StartTime:    conversationStartTime,
+				LoopDetector: loopDetector,
+			})
+			if dispatchErr != nil {
+				return "", messages, dispatchErr
 			}
 
-			// Sequential execution (default path, or single tool call)
-			for i, tc := range choice.ToolCalls {
-				functionCall, err := requireFunctionCall(tc)
-				if err != nil {
-					v2Logger.Warn("Tool call has nil FunctionCall", loggerv2.Int("tool_call_index", i+1))
-					v2Logger.Error("AskWithHistory Early return: invalid tool call: nil function call", nil)
-
-					conversationErrorEvent := events.NewConversationErrorEvent(lastUserMessage, "invalid tool call: nil function call", turn+1, "invalid_tool_call", time.Since(conversationStartTime))
-					a.EmitTypedEvent(ctx, conversationErrorEvent)
-
-					return "", messages, err
-				}
-
-				// Determine server name for tool call events
-				serverName := a.toolToServer[functionCall.Name]
-				if isVirtualTool(functionCall.Name) {
-					// For get_api_spec, extract the actual server_name from arguments
-					// so error events show the correct server, making debugging easier.
-					if functionCall.Name == "get_api_spec" && functionCall.Arguments != "" {
-						var args map[string]interface{}
-						if err := json.Unmarshal([]byte(functionCall.Arguments), &args); err == nil {
-							if srvName, ok := args["server_name"].(string); ok && srvName != "" {
-								serverName = srvName
-							} else {
-								serverName = "virtual-tools"
-							}
-						} else {
-							serverName = "virtual-tools"
-						}
-					} else {
-						serverName = "virtual-tools"
-					}
-				}
-
-				// Emit tool call start event using typed event data with correlation
-				toolStartEvent := events.NewToolCallStartEventWithCorrelation(turn+1, functionCall.Name, events.ToolParams{
-					Arguments: functionCall.Arguments,
-				}, serverName, traceID, traceID) // Using traceID for both traceID and parentID correlation
-				toolStartEvent.ToolCallID = tc.ID
-
-				a.EmitTypedEvent(ctx, toolStartEvent)
-
-				// 🔧 ENHANCED: Check for empty tool name and provide feedback to LLM for self-correction
-				if tc.FunctionCall.Name == "" {
-					v2Logger.Error("AskWithHistory: Empty tool name detected in tool call", nil,
-						loggerv2.Int("turn", turn+1),
-						loggerv2.String("arguments", tc.FunctionCall.Arguments))
-
-					// Generate feedback message for empty tool name
-					feedbackMessage := generateEmptyToolNameFeedback(tc.FunctionCall.Arguments)
-
-					// Emit tool call error event for observability (after tool start event)
-					toolNameErrorEvent := events.NewToolCallErrorEvent(turn+1, "", "empty tool name", "", time.Since(conversationStartTime))
-					toolNameErrorEvent.ToolCallID = tc.ID
-					a.EmitTypedEvent(ctx, toolNameErrorEvent)
-
-					// Add feedback to conversation so LLM can correct itself
-					toolName := ""
-					if tc.FunctionCall != nil {
-						toolName = tc.FunctionCall.Name
-					}
-					messages = append(messages, llmtypes.MessageContent{
-						Role:  llmtypes.ChatMessageTypeTool,
-						Parts: []llmtypes.ContentPart{llmtypes.ToolCallResponse{ToolCallID: tc.ID, Name: toolName, Content: feedbackMessage, IsError: true}},
-					})
-
-					continue
-				}
-				args, err := mcpclient.ParseToolArguments(tc.FunctionCall.Arguments)
-				if err != nil {
-					v2Logger.Error("AskWithHistory Tool args parsing error", err)
-
-					// 🔧 ENHANCED: Instead of failing, provide feedback to LLM for self-correction
-					feedbackMessage := generateToolArgsParsingFeedback(tc.FunctionCall.Name, tc.FunctionCall.Arguments, err)
-
-					// Emit tool call error event for observability
-					toolArgsParsingErrorEvent := events.NewToolCallErrorEvent(turn+1, tc.FunctionCall.Name, fmt.Sprintf("parse tool args: %v", err), "", time.Since(conversationStartTime))
-					toolArgsParsingErrorEvent.ToolCallID = tc.ID
-					a.EmitTypedEvent(ctx, toolArgsParsingErrorEvent)
-
-					// Add feedback to conversation so LLM can correct itself
-					messages = append(messages, llmtypes.MessageContent{
-						Role:  llmtypes.ChatMessageTypeTool,
-						Parts: []llmtypes.ContentPart{llmtypes.ToolCallResponse{ToolCallID: tc.ID, Name: tc.FunctionCall.Name, Content: feedbackMessage, IsError: true}},
-					})
-
-					continue
-				}
-
-				// 🔧 FIX: Check custom tools FIRST before MCP client lookup
-				// Custom tools don't need MCP clients, so check them early
-				isCustomTool := false
-				if a.customTools != nil {
-					if _, exists := a.customTools[tc.FunctionCall.Name]; exists {
-						isCustomTool = true
-						v2Logger.Debug(fmt.Sprintf("🔧 [TOOL_LOOKUP] Tool '%s' identified as custom tool (customTools map has %d tools)", tc.FunctionCall.Name, len(a.customTools)))
-					} else {
-						v2Logger.Debug(fmt.Sprintf("🔧 [TOOL_LOOKUP] Tool '%s' not found in customTools (map has %d tools)", tc.FunctionCall.Name, len(a.customTools)))
-					}
-				} else {
-					v2Logger.Debug(fmt.Sprintf("🔧 [TOOL_LOOKUP] customTools map is nil for tool '%s'", tc.FunctionCall.Name))
-				}
-
-				// Check if it's a virtual tool
-				isVirtual := isVirtualTool(tc.FunctionCall.Name)
-				if isVirtual {
-					v2Logger.Debug(fmt.Sprintf("🔧 [TOOL_LOOKUP] Tool '%s' identified as virtual tool", tc.FunctionCall.Name))
-				}
-
-				var client mcpclient.ClientInterface
-				mappedServerName := ""
-				hasMappedServer := false
-				if a.toolToServer != nil {
-					if mapped, ok := a.toolToServer[tc.FunctionCall.Name]; ok {
-						v2Logger.Debug(fmt.Sprintf("🔧 [TOOL_LOOKUP] Tool '%s' mapped to server '%s' in toolToServer", tc.FunctionCall.Name, mapped))
-						if mapped == "custom" {
-							// Custom tool - no client needed
-							v2Logger.Debug(fmt.Sprintf("🔧 [TOOL_LOOKUP] Tool '%s' is a custom tool (mapped to 'custom'), skipping client lookup", tc.FunctionCall.Name))
-							isCustomTool = true // Ensure it's marked as custom
-						} else {
-							client, mappedServerName, hasMappedServer = a.mappedMCPClient(tc.FunctionCall.Name)
-							if client != nil {
-								v2Logger.Debug(fmt.Sprintf("🔧 [TOOL_LOOKUP] Found client for tool '%s' from server '%s'", tc.FunctionCall.Name, mapped))
-							} else {
-								v2Logger.Debug(fmt.Sprintf("🔧 [TOOL_LOOKUP] Server '%s' mapped for tool '%s' but no client found in Clients map", mapped, tc.FunctionCall.Name))
-							}
-						}
-					} else {
-						v2Logger.Debug(fmt.Sprintf("🔧 [TOOL_LOOKUP] Tool '%s' not found in toolToServer mapping (map has %d entries)", tc.FunctionCall.Name, len(a.toolToServer)))
-					}
-				} else {
-					v2Logger.Debug(fmt.Sprintf("🔧 [TOOL_LOOKUP] toolToServer map is nil for tool '%s'", tc.FunctionCall.Name))
-				}
-
-				// Only check for client errors for non-custom tools and non-virtual tools
-				if !isCustomTool && !isVirtual && client == nil {
-					v2Logger.Debug(fmt.Sprintf("🔧 [TOOL_LOOKUP] Tool '%s' requires client but none found (isCustomTool=%v, isVirtual=%v, client=nil)", tc.FunctionCall.Name, isCustomTool, isVirtual))
-					v2Logger.Debug(fmt.Sprintf("🔧 [TOOL_LOOKUP] Attempting on-demand connection for tool '%s'", tc.FunctionCall.Name))
-
-					// Create a connection on demand for the mapped server even when other
-					// servers already have active clients.
-					if !hasMappedServer || mappedServerName == "" {
-						// Calculate counts for logging
-						customToolsCount := 0
-						if a.customTools != nil {
-							customToolsCount = len(a.customTools)
-						}
-						toolToServerCount := 0
-						if a.toolToServer != nil {
-							toolToServerCount = len(a.toolToServer)
-						}
-						v2Logger.Warn(fmt.Sprintf("🔧 [TOOL_LOOKUP] Tool '%s' not mapped to any server. isCustomTool=%v, isVirtual=%v, customTools has %d tools, toolToServer has %d entries",
-							tc.FunctionCall.Name, isCustomTool, isVirtual, customToolsCount, toolToServerCount))
-						v2Logger.Warn(fmt.Sprintf("[AGENT DEBUG] AskWithHistory Turn %d: Tool '%s' not mapped to any server. Providing feedback to LLM.", turn+1, tc.FunctionCall.Name))
-
-						// Generate helpful feedback instead of failing
-						feedbackMessage := fmt.Sprintf("❌ Tool '%s' is not available in this system.\n\n🔧 Available tools include:\n- get_prompt, get_resource (virtual tools)\n- search_large_output (read/search/query operations for offloaded files)\n- MCP server tools (check system prompt for full list)\n\n💡 Please use one of the available tools listed above.", tc.FunctionCall.Name)
-
-						// Emit tool call error event for observability
-						toolNotFoundEvent := events.NewToolCallErrorEvent(turn+1, tc.FunctionCall.Name, fmt.Sprintf("tool '%s' not found", tc.FunctionCall.Name), "", time.Since(conversationStartTime))
-						toolNotFoundEvent.ToolCallID = tc.ID
-						a.EmitTypedEvent(ctx, toolNotFoundEvent)
-
-						// Add feedback to conversation so LLM can correct itself
-						messages = append(messages, llmtypes.MessageContent{
-							Role:  llmtypes.ChatMessageTypeTool,
-							Parts: []llmtypes.ContentPart{llmtypes.ToolCallResponse{ToolCallID: tc.ID, Name: tc.FunctionCall.Name, Content: feedbackMessage, IsError: true}},
-						})
-
-						continue
-					}
-
-					onDemandClient, err := a.resolveOnDemandMCPClient(ctx, mappedServerName, v2Logger)
-					if err != nil {
-						v2Logger.Error("AskWithHistory Early return: failed to create on-demand connection",
-							err,
-							loggerv2.String("server", mappedServerName))
-						conversationErrorEvent := events.NewConversationErrorEvent(lastUserMessage, fmt.Sprintf("failed to create on-demand connection for server %s: %v", mappedServerName, err), turn+1, "on_demand_connection_failed", time.Since(conversationStartTime))
-						a.EmitTypedEvent(ctx, conversationErrorEvent)
-						return "", messages, fmt.Errorf("failed to create on-demand connection for server %s: %w", mappedServerName, err)
-					}
-
-					// Store the on-demand client back into a.Clients so subsequent tool calls
-					// reuse this connection instead of spawning a new MCP process each time.
-					// Without this, every call to this server would create a duplicate connection
-					// (e.g. many duplicate subprocesses for a single workflow).
-					a.clientsMu.Lock()
-					if a.Clients == nil {
-						a.Clients = make(map[string]mcpclient.ClientInterface)
-					}
-					a.Clients[mappedServerName] = onDemandClient
-					a.clientsMu.Unlock()
-
-					// Use the on-demand client
-					client = onDemandClient
-				}
-
-				// Check for context cancellation before tool execution
-				if agentCtx.Err() != nil {
-					v2Logger.Debug("Context cancelled before tool execution",
-						loggerv2.Int("turn", turn+1),
-						loggerv2.String("tool_name", tc.FunctionCall.Name),
-						loggerv2.Error(agentCtx.Err()),
-						loggerv2.String("duration", time.Since(conversationStartTime).String()))
-					// Emit context cancellation event instead of just returning error
-					cancellationEvent := events.NewContextCancelledEvent(
-						turn+1,
-						fmt.Sprintf("cancelled before tool execution: %s", tc.FunctionCall.Name),
-						time.Since(conversationStartTime),
-					)
-					a.EmitTypedEvent(ctx, cancellationEvent)
-					return "", messages, fmt.Errorf("conversation cancelled before tool execution: %w", agentCtx.Err())
-				}
-
-				// Create timeout context for tool execution
-				// Check if this is a custom tool with a per-tool timeout
-				toolTimeout := getToolExecutionTimeout(a)
-				hasNoTimeout := toolTimeout <= 0
-				if isCustomTool {
-					if customTool, exists := a.customTools[tc.FunctionCall.Name]; exists && customTool.Timeout != -1 {
-						if customTool.Timeout == 0 {
-							// No timeout - tool runs indefinitely
-							hasNoTimeout = true
-							v2Logger.Debug("🔧 [TOOL_TIMEOUT] Custom tool has NO timeout (runs indefinitely)",
-								loggerv2.String("tool_name", tc.FunctionCall.Name))
-						} else if customTool.Timeout > 0 {
-							// Custom per-tool timeout
-							hasNoTimeout = false
-							toolTimeout = customTool.Timeout
-							v2Logger.Debug("🔧 [TOOL_TIMEOUT] Custom tool has per-tool timeout",
-								loggerv2.String("tool_name", tc.FunctionCall.Name),
-								loggerv2.String("timeout", customTool.Timeout.String()))
-						}
-					}
-				}
-
-				var toolCtx context.Context
-				var cancel context.CancelFunc
-				if hasNoTimeout {
-					// No timeout - use the parent context directly (will run until agent context is cancelled)
-					toolCtx = ctx
-					cancel = func() {} // No-op cancel
-				} else {
-					toolCtx, cancel = context.WithTimeout(ctx, toolTimeout)
-				}
-				defer cancel()
-
-				startTime := time.Now()
-				v2Logger.Info(fmt.Sprintf("⏱️  TOOL EXECUTION START - Time: %s, Tool: %s, Turn: %d",
-					startTime.Format(time.RFC3339), tc.FunctionCall.Name, turn+1))
-
-				// 🔧 DEBUG: Log tool call with arguments
-				toolType := "MCP"
-				if isVirtualTool(tc.FunctionCall.Name) {
-					toolType = "virtual"
-				} else if isCustomTool {
-					toolType = "custom"
-				}
-				argsJSON, _ := json.Marshal(args)
-				timeoutStr := toolTimeout.String()
-				if hasNoTimeout {
-					timeoutStr = "none (indefinite)"
-				}
-				v2Logger.Debug("🔧 [TOOL_CALL] Tool called",
-					loggerv2.String("tool_name", tc.FunctionCall.Name),
-					loggerv2.String("tool_type", toolType),
-					loggerv2.String("server_name", serverName),
-					loggerv2.String("tool_call_id", tc.ID),
-					loggerv2.Int("turn", turn+1),
-					loggerv2.String("arguments", string(argsJSON)),
-					loggerv2.String("timeout", timeoutStr))
-
-				// Add cache hit event during tool execution to show cached connection usage
-				if len(a.Tracers) > 0 && serverName != "" && serverName != "virtual-tools" {
-					// Emit connection cache hit event to show we're using cached MCP server connection
-					// Note: We do NOT cache tool execution results - only server connections
-					connectionCacheHitEvent := events.NewCacheHitEvent(serverName, fmt.Sprintf("unified_%s", serverName), "unified_cache", 1, time.Duration(0))
-
-					// Debug: Log the connection cache hit event structure
-					v2Logger.Debug("Connection cache hit")
-
-					a.EmitTypedEvent(ctx, connectionCacheHitEvent)
-
-				}
-
-				// Inject generic tool execution metadata into context
-				// Any tool can extract these values if needed (e.g., workspace tools for event emission)
-				// Using generic keys keeps conversation.go agnostic about specific tool implementations
-				toolCtx = context.WithValue(toolCtx, ToolExecutionAgentKey, a)
-				toolCtx = context.WithValue(toolCtx, ToolExecutionTurnKey, turn+1)
-				toolCtx = context.WithValue(toolCtx, ToolExecutionServerKey, serverName)
-				toolCtx = context.WithValue(toolCtx, ToolExecutionLLMConfigKey, a.GetLLMModelConfig())
-
-				// Apply per-tool argument transformer if registered.
-				// This runs BEFORE any execution branch (virtual → custom → MCP) so all paths
-				// see transformed args. Primary use case: resolve workspace-relative paths
-				// (e.g. "Downloads/file.pdf") to absolute host paths for tools that require them.
-				// Transformers are registered via Agent.SetToolArgTransformer().
-				if a.toolArgTransformers != nil {
-					if transformer, ok := a.toolArgTransformers[tc.FunctionCall.Name]; ok {
-						v2Logger.Info("[TOOL_ARGS] Applying tool arg transformer",
-							loggerv2.String("tool_name", tc.FunctionCall.Name))
-						transformer(args)
-					}
-				}
-
-				var result *mcp.CallToolResult
-				var toolErr error
-
-				// Resolve the LLM-facing disambiguated name to the name registered by MCP.
-				actualToolName := actualMCPToolName(tc.FunctionCall.Name, serverName)
-				if actualToolName != tc.FunctionCall.Name {
-					v2Logger.Debug(fmt.Sprintf("🔧 [TOOL_LOOKUP] Resolved disambiguated tool '%s' -> '%s' (server: %s)", tc.FunctionCall.Name, actualToolName, serverName))
-				}
-
-				// Check if this is a virtual tool
-				if isVirtualTool(tc.FunctionCall.Name) {
-					// Handle virtual tool execution
-					v2Logger.Debug("🔧 [TOOL_CALL] Executing virtual tool",
-						loggerv2.String("tool_name", tc.FunctionCall.Name))
-					resultText, toolErr := a.HandleVirtualTool(toolCtx, tc.FunctionCall.Name, args)
-					if toolErr != nil {
-						result = &mcp.CallToolResult{
-							IsError: true,
-							Content: []mcp.Content{&mcp.TextContent{Text: toolErr.Error()}},
-						}
-					} else {
-						// Ensure resultText is never empty for virtual tools
-						// This prevents empty content from being sent to LLM
-						if resultText == "" {
-							v2Logger.Warn("Virtual tool returned empty result - using default message",
-								loggerv2.String("tool", tc.FunctionCall.Name))
-							resultText = fmt.Sprintf("Tool '%s' executed successfully but returned no output.", tc.FunctionCall.Name)
-						}
-						result = &mcp.CallToolResult{
-							IsError: false,
-							Content: []mcp.Content{&mcp.TextContent{Text: resultText}},
-						}
-
-						// If this was add_tool in tool search mode, refresh the tools list
-						// to include newly discovered tools
-						if a.UseToolSearchMode && tc.FunctionCall.Name == "add_tool" {
-							a.filteredTools = a.getToolsForToolSearchMode()
-							v2Logger.Debug("🔍 [TOOL_SEARCH] Tools refreshed after add_tool",
-								loggerv2.Int("discovered_count", a.GetDiscoveredToolCount()),
-								loggerv2.Int("total_available", len(a.filteredTools)))
-						}
-					}
-				} else if a.customTools != nil {
-					// Check if this is a custom tool
-					if customTool, exists := a.customTools[tc.FunctionCall.Name]; exists {
-						v2Logger.Debug(fmt.Sprintf("🔧 [TOOL_EXECUTION] Executing custom tool '%s' (category: %s)", tc.FunctionCall.Name, customTool.Category))
-						// Handle custom tool execution using the stored execution function
-						resultText, toolErr := customTool.Execution(toolCtx, args)
-
-						if toolErr != nil {
-							v2Logger.Error(fmt.Sprintf("🔧 [TOOL_EXECUTION] Custom tool '%s' execution failed: %v", tc.FunctionCall.Name, toolErr), toolErr)
-							result = &mcp.CallToolResult{
-								IsError: true,
-								Content: []mcp.Content{&mcp.TextContent{Text: toolErr.Error()}},
-							}
-						} else {
-							v2Logger.Debug(fmt.Sprintf("🔧 [TOOL_EXECUTION] Custom tool '%s' executed successfully (result length: %d chars)", tc.FunctionCall.Name, len(resultText)))
-							result = &mcp.CallToolResult{
-								IsError: false,
-								Content: []mcp.Content{&mcp.TextContent{Text: resultText}},
-							}
-						}
-					} else {
-						// Handle regular MCP tool execution
-						v2Logger.Debug("🔧 [TOOL_CALL] About to call MCP tool via client (from customTools fallback)",
-							loggerv2.String("tool_name", actualToolName),
-							loggerv2.String("server_name", serverName),
-							loggerv2.String("timeout", toolTimeout.String()))
-						callStart := time.Now()
-						result, toolErr = callToolWithTimeoutWrapper(toolCtx, client, actualToolName, args, v2Logger, serverName)
-						callDuration := time.Since(callStart)
-						v2Logger.Debug("🔧 [TOOL_CALL] MCP tool call completed (from customTools fallback)",
-							loggerv2.String("tool_name", tc.FunctionCall.Name),
-							loggerv2.String("server_name", serverName),
-							loggerv2.String("duration", callDuration.String()),
-							loggerv2.Any("ctx_done", toolCtx.Err() != nil),
-							loggerv2.Any("has_error", toolErr != nil))
-					}
-				} else {
-					// Handle regular MCP tool execution
-					v2Logger.Debug("🔧 [TOOL_CALL] About to execute MCP tool",
-						loggerv2.String("tool_name", actualToolName),
-						loggerv2.String("server_name", serverName),
-						loggerv2.String("timeout", toolTimeout.String()))
-					callStart := time.Now()
-					result, toolErr = callToolWithTimeoutWrapper(toolCtx, client, actualToolName, args, v2Logger, serverName)
-					callDuration := time.Since(callStart)
-					v2Logger.Debug("🔧 [TOOL_CALL] MCP tool call completed",
-						loggerv2.String("tool_name", tc.FunctionCall.Name),
-						loggerv2.String("server_name", serverName),
-						loggerv2.String("duration", callDuration.String()),
-						loggerv2.Any("ctx_done", toolCtx.Err() != nil),
-						loggerv2.Any("has_error", toolErr != nil))
-				}
-
-				duration := time.Since(startTime)
-				v2Logger.Info(fmt.Sprintf("⏱️  TOOL EXECUTION END - Time: %s, Tool: %s, Duration: %v, Turn: %d",
-					time.Now().Format(time.RFC3339), tc.FunctionCall.Name, duration, turn+1))
-				log.Printf("[LATENCY_DEBUG] Turn %d | T+%dms | Tool executed: %s | tool_duration=%dms err=%v",
-					turn+1, time.Since(conversationStartTime).Milliseconds(), tc.FunctionCall.Name, duration.Milliseconds(), toolErr)
-
-				// Check for timeout
-				if toolCtx.Err() == context.DeadlineExceeded {
-					toolErr = fmt.Errorf("tool execution timed out after %s: %s", toolTimeout.String(), tc.FunctionCall.Name)
-					v2Logger.Debug("Tool call timed out",
-						loggerv2.Int("turn", turn+1),
-						loggerv2.String("tool_name", tc.FunctionCall.Name),
-						loggerv2.String("timeout", toolTimeout.String()))
-				}
-
-				if agentCtx.Err() != nil {
-					v2Logger.Debug("Context cancelled after tool execution (will stop after appending result)",
-						loggerv2.Int("turn", turn+1),
-						loggerv2.String("tool_name", tc.FunctionCall.Name),
-						loggerv2.Error(agentCtx.Err()))
-					// Don't return early here — the tool result must be appended to messages
-					// first (line ~1613) to keep the conversation state valid (every tool call
-					// needs a response). The cancellation will be caught at the start of the
-					// next turn (line 533) or before the next tool execution (line 1222).
-				}
-
-				// Handle tool execution errors gracefully - provide feedback to LLM and continue
-				if toolErr != nil {
-					// 🔧 DEBUG: Log tool error
-					v2Logger.Debug("🔧 [TOOL_RESPONSE] Tool execution error",
-						loggerv2.String("tool_name", tc.FunctionCall.Name),
-						loggerv2.String("tool_type", toolType),
-						loggerv2.String("server_name", serverName),
-						loggerv2.String("tool_call_id", tc.ID),
-						loggerv2.Int("turn", turn+1),
-						loggerv2.String("error", toolErr.Error()),
-						loggerv2.String("duration", duration.String()))
-
-					// 🔧 ENHANCED ERROR RECOVERY HANDLING
-					errorRecoveryHandler := NewErrorRecoveryHandler(a)
-
-					// Attempt error recovery for recoverable errors
-					recoveredResult, recoveredDuration, wasRecovered, recoveredErr := errorRecoveryHandler.HandleError(
-						ctx, &tc, serverName, toolErr, startTime, isCustomTool, isVirtualTool(tc.FunctionCall.Name))
-
-					if wasRecovered && recoveredErr == nil {
-						// Successfully recovered - use recovered result and continue normal flow
-						v2Logger.Debug("Successfully recovered from error for tool",
-							loggerv2.String("tool", tc.FunctionCall.Name))
-						result = recoveredResult
-						toolErr = nil
-						duration = recoveredDuration
-						// Continue to normal result processing below (outside this if block)
-					} else {
-						// Recovery failed or not attempted - proceed with error handling
-						if wasRecovered {
-							v2Logger.Error("Recovery failed for tool", recoveredErr,
-								loggerv2.String("tool", tc.FunctionCall.Name))
-							toolErr = recoveredErr
-							duration = recoveredDuration
-						}
-
-						// Emit tool call error event using typed event data
-						toolErrorEvent := events.NewToolCallErrorEvent(turn+1, tc.FunctionCall.Name, toolErr.Error(), serverName, duration)
-						toolErrorEvent.ToolCallID = tc.ID
-						a.EmitTypedEvent(ctx, toolErrorEvent)
-
-						// Instead of failing the entire conversation, provide feedback to the LLM
-						errorResultText := fmt.Sprintf("Tool execution failed - %v", toolErr)
-
-						// Add the error result to the conversation so the LLM can continue
-						messages = append(messages, llmtypes.MessageContent{
-							Role:  llmtypes.ChatMessageTypeTool, // Use "tool" role for tool responses
-							Parts: []llmtypes.ContentPart{llmtypes.ToolCallResponse{ToolCallID: tc.ID, Name: tc.FunctionCall.Name, Content: errorResultText, IsError: true}},
-						})
-
-						// Continue to next turn instead of returning error
-						continue
-					}
-				}
-				var resultText string
-				if result != nil {
-
-					// Get the tool result as string (without prefix)
-					resultText = mcpclient.ToolResultAsString(result)
-
-					// 🔧 DEBUG: Log tool response
-					resultPreview := resultText
-					if len(resultPreview) > 500 {
-						resultPreview = resultPreview[:500] + "... (truncated)"
-					}
-					v2Logger.Debug("🔧 [TOOL_RESPONSE] Tool response received",
-						loggerv2.String("tool_name", tc.FunctionCall.Name),
-						loggerv2.String("tool_type", toolType),
-						loggerv2.String("server_name", serverName),
-						loggerv2.String("tool_call_id", tc.ID),
-						loggerv2.Int("turn", turn+1),
-						loggerv2.Int("result_length", len(resultText)),
-						loggerv2.Any("is_error", result.IsError),
-						loggerv2.String("duration", duration.String()),
-						loggerv2.String("result_preview", resultPreview))
-
-					// Ensure resultText is never empty when sending to LLM
-					// This is a safety check for all tool types (virtual, custom, MCP)
-					if resultText == "" && !result.IsError {
-						v2Logger.Warn("Tool returned empty result - using default message",
-							loggerv2.String("tool", tc.FunctionCall.Name))
-						resultText = fmt.Sprintf("Tool '%s' executed successfully but returned no output.", tc.FunctionCall.Name)
-					}
-
-					// 🔧 BROKEN PIPE DETECTION IN RESULT CONTENT (regardless of IsError flag)
-					// Check for broken pipe errors in content text, even when IsError is false
-					// This handles cases where the MCP server returns broken pipe errors in content rather than as error flags
-					if mcpclient.IsBrokenPipeInContent(resultText) {
-						v2Logger.Info(fmt.Sprintf("🔧 [BROKEN PIPE DETECTED IN RESULT] Turn %d, Tool: %s, Server: %s, IsError: %v - Attempting immediate connection recreation", turn+1, tc.FunctionCall.Name, serverName, result.IsError))
-
-						// Create error recovery handler
-						errorRecoveryHandler := NewErrorRecoveryHandler(a)
-
-						// Create a fake error for the recovery handler
-						fakeErr := fmt.Errorf("broken pipe detected in result: %s", resultText)
-
-						// Attempt error recovery
-						recoveredResult, recoveredDuration, wasRecovered, recoveredErr := errorRecoveryHandler.HandleError(
-							ctx, &tc, serverName, fakeErr, startTime, isCustomTool, isVirtualTool(tc.FunctionCall.Name))
-
-						if wasRecovered && recoveredErr == nil {
-							v2Logger.Debug("Broken pipe recovery successful for tool",
-								loggerv2.String("tool", tc.FunctionCall.Name))
-							result = recoveredResult
-							duration = recoveredDuration
-							resultText = mcpclient.ToolResultAsString(result)
-						} else if wasRecovered {
-							v2Logger.Error("Broken pipe recovery failed for tool", recoveredErr,
-								loggerv2.String("tool", tc.FunctionCall.Name))
-						}
-					}
-
-					// Context offloading: Check if tool output should be offloaded to filesystem
-					if a.EnableContextOffloading && a.shouldUseWrapperTokenCounting() {
-						// Check if output exceeds threshold for context offloading
-						if a.toolOutputHandler.IsLargeToolOutputWithModel(resultText, a.ModelID) {
-
-							// Emit context offloading detection event
-							detectedEvent := events.NewLargeToolOutputDetectedEvent(tc.FunctionCall.Name, len(resultText), a.toolOutputHandler.GetToolOutputFolder())
-							detectedEvent.ServerAvailable = a.toolOutputHandler.IsServerAvailable()
-							a.EmitTypedEvent(ctx, detectedEvent)
-
-							// Offload large output to filesystem (context offloading)
-							filePath, writeErr := a.toolOutputHandler.WriteToolOutputToFile(resultText, tc.FunctionCall.Name)
-							if writeErr == nil {
-								// Extract first 100 characters for Langfuse observability
-								preview := a.toolOutputHandler.ExtractFirstNCharacters(resultText, 100)
-
-								// Emit successful file write event with preview
-								fileWrittenEvent := events.NewLargeToolOutputFileWrittenEvent(tc.FunctionCall.Name, filePath, len(resultText), preview)
-								a.EmitTypedEvent(ctx, fileWrittenEvent)
-
-								// Create message with file path, first 50% of threshold, and instructions
-								fileMessage := a.toolOutputHandler.CreateToolOutputMessageWithPreview(tc.ID, filePath, resultText, 50, false)
-
-								// Replace the result text with the file message
-								resultText = fileMessage
-
-							} else {
-								// Emit file write error event
-								fileErrorEvent := events.NewLargeToolOutputFileWriteErrorEvent(tc.FunctionCall.Name, writeErr.Error(), len(resultText))
-								a.EmitTypedEvent(ctx, fileErrorEvent)
-							}
-						}
-					}
-
-					// Safety check: Apply max token limit truncation regardless of context offloading setting
-					// This prevents API errors from prompts exceeding model context limits
-					if a.shouldUseWrapperTokenCounting() && a.toolOutputHandler.ExceedsMaxTokenLimit(resultText, a.ModelID) {
-						truncatedResult, wasTruncated := a.toolOutputHandler.TruncateToMaxTokenLimit(resultText, a.ModelID, tc.FunctionCall.Name)
-						if wasTruncated {
-							v2Logger.Warn("Tool output exceeded max token limit, truncated",
-								loggerv2.String("tool", tc.FunctionCall.Name),
-								loggerv2.Int("original_length", len(resultText)),
-								loggerv2.Int("truncated_length", len(truncatedResult)),
-								loggerv2.Int("max_tokens", a.toolOutputHandler.GetMaxToolOutputTokens()))
-							resultText = truncatedResult
-						}
-					}
-				} else {
-					resultText = "Tool execution completed but no result returned"
-				}
-				// 3. Append the tool result as a new message (after the AI tool_call message)
-				// Add recover block to catch panics
-				func() {
-					defer func() {
-						if r := recover(); r != nil {
-							v2Logger.Error("Panic while appending tool result message", fmt.Errorf("%v", r))
-						}
-					}()
-					// Use the exact tool call ID from the LLM response
-					messages = append(messages, llmtypes.MessageContent{
-						Role:  llmtypes.ChatMessageTypeTool, // Use "tool" role for tool responses
-						Parts: []llmtypes.ContentPart{llmtypes.ToolCallResponse{ToolCallID: tc.ID, Name: tc.FunctionCall.Name, Content: resultText, IsError: result != nil && result.IsError}},
-					})
-				}()
-
-				// End the tool execution span with output and error information
-				toolOutput := map[string]interface{}{
-					"tool_name":   tc.FunctionCall.Name,
-					"server_name": a.toolToServer[tc.FunctionCall.Name],
-					"result":      resultText,
-					"duration":    duration,
-					"turn":        turn + 1,
-					"success":     toolErr == nil,
-					"timeout":     getToolExecutionTimeout(a).String(),
-				}
-				if toolErr != nil {
-					toolOutput["error"] = toolErr.Error()
-					if strings.Contains(toolErr.Error(), "timed out") {
-						toolOutput["error_type"] = "tool_execution_timeout"
-					} else {
-						toolOutput["error_type"] = "tool_execution_error"
-					}
-				}
-
-				// Tool execution completed - emit tool call end event
-				// Only emit ToolCallEndEvent if result is not an error (errors should emit ToolCallErrorEvent)
-				if result == nil || !result.IsError {
-					// Get current token usage information for the tool call end event
-					_, _, _, _, _, _, _, _, _, _, _, _, contextUsagePercent := a.GetTokenUsageWithPricing()
-					a.tokenTrackingMutex.RLock()
-					modelContextWindow := a.modelContextWindow
-					contextWindowUsage := a.currentContextWindowUsage
-					a.tokenTrackingMutex.RUnlock()
-
-					// Emit tool call end event using typed event data (consolidated - contains all tool information)
-					toolEndEvent := events.NewToolCallEndEventWithTokenUsageAndModel(turn+1, tc.FunctionCall.Name, resultText, serverName, duration, "", contextUsagePercent, modelContextWindow, contextWindowUsage, a.ModelID)
-					toolEndEvent.ToolCallID = tc.ID
-					a.EmitTypedEvent(ctx, toolEndEvent)
-				} else if result.IsError {
-					// Result contains an error - emit tool call error event
-					// This handles the case where tool execution succeeded but the tool returned an error result
-					toolErrorEvent := events.NewToolCallErrorEvent(turn+1, tc.FunctionCall.Name, resultText, serverName, duration)
-					toolErrorEvent.ToolCallID = tc.ID
-					a.EmitTypedEvent(ctx, toolErrorEvent)
-				}
-
-				// Note: Removed redundant tool_output and tool_response events
-				// tool_call_end now contains all necessary tool information
-
-				// 🔄 LOOP DETECTION: Check if this tool call + args + response matches recent calls
-				if tc.FunctionCall != nil {
-					loopResult := loopDetector.CheckAndHandleLoop(tc.FunctionCall.Name, tc.FunctionCall.Arguments, resultText)
-					if loopResult.Detected {
-						HandleLoopDetection(a, ctx, loopResult, lastUserMessage, turn+1, conversationStartTime, &messages, v2Logger)
-						// Continue to next turn so LLM can respond to the correction message
-					}
-				}
+			// Streaming structured output ends once its tool was called (see structured_stream.go)
+			if a.captureStructuredOutput(ctx, choice.ToolCalls, lastUserMessage, conversationStartTime, turn) {
+				return "", messages, nil
 			}
 
+			// Tools that failed too often are dropped for the remaining turns
+			messages = a.recordToolFailures(ctx, messages, dispatched, turn)
+
 			// Drain and inject any pending steer messages from the user
 			if steerMsgs := a.DrainSteerMessages(); len(steerMsgs) > 0 {
-				messages = injectSteerMessages(ctx, a, messages, steerMsgs, turn, "Injected steer message after sequential tool execution")
+				messages = injectSteerMessages(ctx, a, messages, steerMsgs, turn, "Injected steer message after "+toolDispatchMode+" tool execution")
 			}
 
 			// After processing all tool calls, continue to next turn
@@ -1781,6 +1316,45 @@ func AskWithHistory(a *Agent, ctx context.Context, messages []llmtypes.MessageCo
 				continue
 			}
 
+			// Check the answer against tool evidence; unsupported claims get one corrective re-ask
+			grounding = a.checkGrounding(ctx, messages, choice.Content, turn, groundingReasked)
+			if grounding != nil && grounding.UnsupportedClaims > 0 && a.Grounding.CorrectiveReask && !groundingReasked {
+				groundingReasked = true
+				messages = append(messages, groundingCorrectionMessage(grounding))
+				continue
+			}
+
+			// Enforce the answer contract; violations get bounded repair re-asks
+			if a.answerContract != nil {
+				answer, violations, err := a.checkAnswerContract(ctx, choice.Content, turn, contractRepairs)
+				if err != nil {
+					return "", messages, err
+				}
+				if len(violations) > 0 {
+					if contractRepairs < a.answerContract.maxRepairs {
+						contractRepairs++
+						messages = append(messages, a.answerContractRepairMessage(violations))
+						continue
+					}
+					a.EndAgentSession(ctx, time.Since(conversationStartTime))
+					return choice.Content, messages, &AnswerContractError{Mode: a.answerContract.mode, Violations: violations, Answer: choice.Content}
+				}
+				choice.Content = answer
+			}
+
+			// Run the output guard; rejected answers get bounded repair re-asks
+			if a.outputGuard != nil {
+				if err := a.checkOutputGuard(ctx, choice.Content, turn, guardRepairs); err != nil {
+					if guardRepairs < a.outputGuard.maxRepairs {
+						guardRepairs++
+						messages = append(messages, outputGuardRepairMessage(err))
+						continue
+					}
+					a.EndAgentSession(ctx, time.Since(conversationStartTime))
+					return choice.Content, messages, &OutputValidationError{Err: err, Repairs: guardRepairs, Answer: choice.Content}
+				}
+			}
+
 			// Simple agent - return immediately when no tool calls
 			v2Logger.Debug("No tool calls detected, returning final answer", loggerv2.Int("turn", turn+1))
 
@@ -1795,6 +1369,7 @@ func AskWithHistory(a *Agent, ctx context.Context, messages []llmtypes.MessageCo
 				turn+1,                            // turns
 			)
 			a.annotateUnifiedCompletionEvent(unifiedCompletionEvent)
+			unifiedCompletionEvent.Grounding = grounding
 			a.EmitTypedEvent(ctx, unifiedCompletionEvent)
 
 			// NEW: End agent session for hierarchy tracking
@@ -1841,8 +1416,12 @@ func AskWithHistory(a *Agent, ctx context.Context, messages []llmtypes.MessageCo
 		finalOpts = append(finalOpts, llmtypes.WithTemperature(a.Temperature))
 	}
 	finalOpts = a.appendCodingAgentInteractiveOptions(finalOpts)
+	if schema := currentCall(ctx).responseSchema; schema != nil {
+		finalOpts = append(finalOpts, llmtypes.WithJSONSchema(schema.Schema, schema.Name, schema.Description, schema.Strict))
+	}
+	finalOpts = append(finalOpts, a.outputControlOptions(messages, MaxTokensHintFinalSynthesis)...)
 
-	finalResp, finalUsage, err := GenerateContentWithRetry(a, ctx, messages, finalOpts, a.MaxTurns+1)
+	finalResp, finalUsage, err := pipeline.Generate.Generate(ctx, a, messages, finalOpts, a.MaxTurns+1)
 
 	// Log finalUsage for debugging
 	v2Logger.Info(fmt.Sprintf("🔍 [FINAL LLM CALL DEBUG] finalUsage from GenerateContentWithRetry:"))
@@ -2008,6 +1587,697 @@ func AskWithHistory(a *Agent, ctx context.Context, messages []llmtypes.MessageCo
 	return finalChoice.Content, messages, nil
 }
 
+// executeToolCallsSequential executes tool calls one after another and appends
+// each tool result to messages. It is the default dispatch path (and the only
+// one when parallel tool execution is disabled or the LLM made a single call).
+func executeToolCallsSequential(
+	ctx context.Context,
+	a *Agent,
+	toolCalls []llmtypes.ToolCall,
+	messages []llmtypes.MessageContent,
+	turn int,
+	traceID string,
+	conversationStartTime time.Time,
+	lastUserMessage string,
+	loopDetector *ToolLoopDetector,
+	agentCtx context.Context,
+) ([]llmtypes.MessageContent, error) {
+	v2Logger := a.Logger
+
+	var toolImageBatches []toolImageBatch
+	for i, tc := range toolCalls {
+		functionCall, err := requireFunctionCall(tc)
+		if err != nil {
+			v2Logger.Warn("Tool call has nil FunctionCall", loggerv2.Int("tool_call_index", i+1))
+			v2Logger.Error("AskWithHistory Early return: invalid tool call: nil function call", nil)
+
+			conversationErrorEvent := events.NewConversationErrorEvent(lastUserMessage, "invalid tool call: nil function call", turn+1, "invalid_tool_call", time.Since(conversationStartTime))
+			a.EmitTypedEvent(ctx, conversationErrorEvent)
+
+			return messages, err
+		}
+
+		// Determine server name for tool call events
+		serverName := a.toolToServer[functionCall.Name]
+		if isVirtualTool(functionCall.Name) {
+			// For get_api_spec, extract the actual server_name from arguments
+			// so error events show the correct server, making debugging easier.
+			if functionCall.Name == "get_api_spec" && functionCall.Arguments != "" {
+				var args map[string]interface{}
+				if err := json.Unmarshal([]byte(functionCall.Arguments), &args); err == nil {
+					if srvName, ok := args["server_name"].(string); ok && srvName != "" {
+						serverName = srvName
+					} else {
+						serverName = "virtual-tools"
+					}
+				} else {
+					serverName = "virtual-tools"
+				}
+			} else {
+				serverName = "virtual-tools"
+			}
+		}
+
+		// Emit tool call start event using typed event data with correlation
+		toolStartEvent := events.NewToolCallStartEventWithCorrelation(turn+1, functionCall.Name, events.ToolParams{
+			Arguments: functionCall.Arguments,
+		}, serverName, traceID, traceID) // Using traceID for both traceID and parentID correlation
+		toolStartEvent.ToolCallID = tc.ID
+
+		a.EmitTypedEvent(ctx, toolStartEvent)
+
+		// 🔧 ENHANCED: Check for empty tool name and provide feedback to LLM for self-correction
+		if tc.FunctionCall.Name == "" {
+			v2Logger.Error("AskWithHistory: Empty tool name detected in tool call", nil,
+				loggerv2.Int("turn", turn+1),
+				loggerv2.String("arguments", tc.FunctionCall.Arguments))
+
+			// Generate feedback message for empty tool name
+			feedbackMessage := generateEmptyToolNameFeedback(tc.FunctionCall.Arguments)
+
+			// Emit tool call error event for observability (after tool start event)
+			toolNameErrorEvent := events.NewToolCallErrorEvent(turn+1, "", "empty tool name", "", time.Since(conversationStartTime))
+			toolNameErrorEvent.ToolCallID = tc.ID
+			a.EmitTypedEvent(ctx, toolNameErrorEvent)
+
+			// Add feedback to conversation so LLM can correct itself
+			toolName := ""
+			if tc.FunctionCall != nil {
+				toolName = tc.FunctionCall.Name
+			}
+			messages = append(messages, llmtypes.MessageContent{
+				Role:  llmtypes.ChatMessageTypeTool,
+				Parts: []llmtypes.ContentPart{llmtypes.ToolCallResponse{ToolCallID: tc.ID, Name: toolName, Content: feedbackMessage, IsError: true}},
+			})
+
+			continue
+		}
+		args, err := mcpclient.ParseToolArguments(tc.FunctionCall.Arguments)
+		if err != nil {
+			v2Logger.Error("AskWithHistory Tool args parsing error", err)
+
+			// 🔧 ENHANCED: Instead of failing, provide feedback to LLM for self-correction
+			feedbackMessage := generateToolArgsParsingFeedback(tc.FunctionCall.Name, tc.FunctionCall.Arguments, err)
+
+			// Emit tool call error event for observability
+			toolArgsParsingErrorEvent := events.NewToolCallErrorEvent(turn+1, tc.FunctionCall.Name, fmt.Sprintf("parse tool args: %v", err), "", time.Since(conversationStartTime))
+			toolArgsParsingErrorEvent.ToolCallID = tc.ID
+			a.EmitTypedEvent(ctx, toolArgsParsingErrorEvent)
+
+			// Add feedback to conversation so LLM can correct itself
+			messages = append(messages, llmtypes.MessageContent{
+				Role:  llmtypes.ChatMessageTypeTool,
+				Parts: []llmtypes.ContentPart{llmtypes.ToolCallResponse{ToolCallID: tc.ID, Name: tc.FunctionCall.Name, Content: feedbackMessage, IsError: true}},
+			})
+
+			continue
+		}
+
+		// 🔧 FIX: Check custom tools FIRST before MCP client lookup
+		// Custom tools don't need MCP clients, so check them early
+		isCustomTool := false
+		if a.customTools != nil {
+			if _, exists := a.customTools[tc.FunctionCall.Name]; exists {
+				isCustomTool = true
+				v2Logger.Debug(fmt.Sprintf("🔧 [TOOL_LOOKUP] Tool '%s' identified as custom tool (customTools map has %d tools)", tc.FunctionCall.Name, len(a.customTools)))
+			} else {
+				v2Logger.Debug(fmt.Sprintf("🔧 [TOOL_LOOKUP] Tool '%s' not found in customTools (map has %d tools)", tc.FunctionCall.Name, len(a.customTools)))
+			}
+		} else {
+			v2Logger.Debug(fmt.Sprintf("🔧 [TOOL_LOOKUP] customTools map is nil for tool '%s'", tc.FunctionCall.Name))
+		}
+
+		// Check if it's a virtual tool
+		isVirtual := isVirtualTool(tc.FunctionCall.Name)
+		if isVirtual {
+			v2Logger.Debug(fmt.Sprintf("🔧 [TOOL_LOOKUP] Tool '%s' identified as virtual tool", tc.FunctionCall.Name))
+		}
+
+		var client mcpclient.ClientInterface
+		mappedServerName := ""
+		hasMappedServer := false
+		if a.toolToServer != nil {
+			if mapped, ok := a.toolToServer[tc.FunctionCall.Name]; ok {
+				v2Logger.Debug(fmt.Sprintf("🔧 [TOOL_LOOKUP] Tool '%s' mapped to server '%s' in toolToServer", tc.FunctionCall.Name, mapped))
+				if mapped == "custom" {
+					// Custom tool - no client needed
+					v2Logger.Debug(fmt.Sprintf("🔧 [TOOL_LOOKUP] Tool '%s' is a custom tool (mapped to 'custom'), skipping client lookup", tc.FunctionCall.Name))
+					isCustomTool = true // Ensure it's marked as custom
+				} else {
+					client, mappedServerName, hasMappedServer = a.mappedMCPClient(tc.FunctionCall.Name)
+					if client != nil {
+						v2Logger.Debug(fmt.Sprintf("🔧 [TOOL_LOOKUP] Found client for tool '%s' from server '%s'", tc.FunctionCall.Name, mapped))
+					} else {
+						v2Logger.Debug(fmt.Sprintf("🔧 [TOOL_LOOKUP] Server '%s' mapped for tool '%s' but no client found in Clients map", mapped, tc.FunctionCall.Name))
+					}
+				}
+			} else {
+				v2Logger.Debug(fmt.Sprintf("🔧 [TOOL_LOOKUP] Tool '%s' not found in toolToServer mapping (map has %d entries)", tc.FunctionCall.Name, len(a.toolToServer)))
+			}
+		} else {
+			v2Logger.Debug(fmt.Sprintf("🔧 [TOOL_LOOKUP] toolToServer map is nil for tool '%s'", tc.FunctionCall.Name))
+		}
+
+		// Only check for client errors for non-custom tools and non-virtual tools
+		if !isCustomTool && !isVirtual && client == nil {
+			v2Logger.Debug(fmt.Sprintf("🔧 [TOOL_LOOKUP] Tool '%s' requires client but none found (isCustomTool=%v, isVirtual=%v, client=nil)", tc.FunctionCall.Name, isCustomTool, isVirtual))
+			v2Logger.Debug(fmt.Sprintf("🔧 [TOOL_LOOKUP] Attempting on-demand connection for tool '%s'", tc.FunctionCall.Name))
+
+			// Create a connection on demand for the mapped server even when other
+			// servers already have active clients.
+			if !hasMappedServer || mappedServerName == "" {
+				// Calculate counts for logging
+				customToolsCount := 0
+				if a.customTools != nil {
+					customToolsCount = len(a.customTools)
+				}
+				toolToServerCount := 0
+				if a.toolToServer != nil {
+					toolToServerCount = len(a.toolToServer)
+				}
+				v2Logger.Warn(fmt.Sprintf("🔧 [TOOL_LOOKUP] Tool '%s' not mapped to any server. isCustomTool=%v, isVirtual=%v, customTools has %d tools, toolToServer has %d entries",
+					tc.FunctionCall.Name, isCustomTool, isVirtual, customToolsCount, toolToServerCount))
+				v2Logger.Warn(fmt.Sprintf("[AGENT DEBUG] AskWithHistory Turn %d: Tool '%s' not mapped to any server. Providing feedback to LLM.", turn+1, tc.FunctionCall.Name))
+
+				// Generate helpful feedback instead of failing
+				feedbackMessage := fmt.Sprintf("❌ Tool '%s' is not available in this system.\n\n🔧 Available tools include:\n- get_prompt, read_resource (virtual tools)\n- search_large_output (read/search/query operations for offloaded files)\n- MCP server tools (check system prompt for full list)\n\n💡 Please use one of the available tools listed above.", tc.FunctionCall.Name)
+
+				// Emit tool call error event for observability
+				toolNotFoundEvent := events.NewToolCallErrorEvent(turn+1, tc.FunctionCall.Name, fmt.Sprintf("tool '%s' not found", tc.FunctionCall.Name), "", time.Since(conversationStartTime))
+				toolNotFoundEvent.ToolCallID = tc.ID
+				a.EmitTypedEvent(ctx, toolNotFoundEvent)
+
+				// Add feedback to conversation so LLM can correct itself
+				messages = append(messages, llmtypes.MessageContent{
+					Role:  llmtypes.ChatMessageTypeTool,
+					Parts: []llmtypes.ContentPart{llmtypes.ToolCallResponse{ToolCallID: tc.ID, Name: tc.FunctionCall.Name, Content: feedbackMessage, IsError: true}},
+				})
+
+				continue
+			}
+
+			onDemandClient, err := a.resolveOnDemandMCPClient(ctx, mappedServerName, v2Logger)
+			if err != nil {
+				v2Logger.Error("AskWithHistory Early return: failed to create on-demand connection",
+					err,
+					loggerv2.String("server", mappedServerName))
+				conversationErrorEvent := events.NewConversationErrorEvent(lastUserMessage, fmt.Sprintf("failed to create on-demand connection for server %s: %v", mappedServerName, err), turn+1, "on_demand_connection_failed", time.Since(conversationStartTime))
+				conversationErrorEvent.ErrorCode = ErrorCode(ErrMCPConnectionFailed)
+				a.EmitTypedEvent(ctx, conversationErrorEvent)
+				return messages, withSentinel(ErrMCPConnectionFailed, fmt.Errorf("failed to create on-demand connection for server %s: %w", mappedServerName, err))
+			}
+
+			// Store the on-demand client back into a.Clients so subsequent tool calls
+			// reuse this connection instead of spawning a new MCP process each time.
+			// Without this, every call to this server would create a duplicate connection
+			// (e.g. many duplicate subprocesses for a single workflow).
+			a.clientsMu.Lock()
+			if a.Clients == nil {
+				a.Clients = make(map[string]mcpclient.ClientInterface)
+			}
+			a.Clients[mappedServerName] = onDemandClient
+			a.clientsMu.Unlock()
+			a.watchConnectionState(mappedServerName, onDemandClient)
+
+			// Use the on-demand client
+			client = onDemandClient
+		}
+
+		// Check for context cancellation before tool execution
+		if agentCtx.Err() != nil {
+			v2Logger.Debug("Context cancelled before tool execution",
+				loggerv2.Int("turn", turn+1),
+				loggerv2.String("tool_name", tc.FunctionCall.Name),
+				loggerv2.Error(agentCtx.Err()),
+				loggerv2.String("duration", time.Since(conversationStartTime).String()))
+			// Emit context cancellation event instead of just returning error
+			cancellationEvent := events.NewContextCancelledEvent(
+				turn+1,
+				fmt.Sprintf("cancelled before tool execution: %s", tc.FunctionCall.Name),
+				time.Since(conversationStartTime),
+			)
+			a.EmitTypedEvent(ctx, cancellationEvent)
+			return messages, fmt.Errorf("conversation cancelled before tool execution: %w", agentCtx.Err())
+		}
+
+		// Create timeout context for tool execution, with the tool's own
+		// timeout if one is configured (see tool_timeouts.go)
+		toolTimeout := a.resolveToolTimeout(tc.FunctionCall.Name, serverName)
+		hasNoTimeout := toolTimeout <= 0
+
+		var toolCtx context.Context
+		var cancel context.CancelFunc
+		if hasNoTimeout {
+			// No timeout - use the parent context directly (will run until agent context is cancelled)
+			toolCtx = ctx
+			cancel = func() {} // No-op cancel
+		} else {
+			toolCtx, cancel = context.WithTimeout(ctx, toolTimeout)
+		}
+		defer cancel()
+
+		startTime := time.Now()
+		v2Logger.Info(fmt.Sprintf("⏱️  TOOL EXECUTION START - Time: %s, Tool: %s, Turn: %d",
+			startTime.Format(time.RFC3339), tc.FunctionCall.Name, turn+1))
+
+		// 🔧 DEBUG: Log tool call with arguments
+		toolType := "MCP"
+		if isVirtualTool(tc.FunctionCall.Name) {
+			toolType = "virtual"
+		} else if isCustomTool {
+			toolType = "custom"
+		}
+		argsJSON, _ := json.Marshal(args)
+		timeoutStr := toolTimeout.String()
+		if hasNoTimeout {
+			timeoutStr = "none (indefinite)"
+		}
+		v2Logger.Debug("🔧 [TOOL_CALL] Tool called",
+			loggerv2.String("tool_name", tc.FunctionCall.Name),
+			loggerv2.String("tool_type", toolType),
+			loggerv2.String("server_name", serverName),
+			loggerv2.String("tool_call_id", tc.ID),
+			loggerv2.Int("turn", turn+1),
+			loggerv2.String("arguments", string(argsJSON)),
+			loggerv2.String("timeout", timeoutStr))
+
+		// Add cache hit event during tool execution to show cached connection usage
+		if len(a.Tracers) > 0 && serverName != "" && serverName != "virtual-tools" {
+			// Emit connection cache hit event to show we're using cached MCP server connection
+			// Note: We do NOT cache tool execution results - only server connections
+			connectionCacheHitEvent := events.NewCacheHitEvent(serverName, fmt.Sprintf("unified_%s", serverName), "unified_cache", 1, time.Duration(0))
+
+			// Debug: Log the connection cache hit event structure
+			v2Logger.Debug("Connection cache hit")
+
+			a.EmitTypedEvent(ctx, connectionCacheHitEvent)
+
+		}
+
+		// Inject generic tool execution metadata into context
+		// Any tool can extract these values if needed (e.g., workspace tools for event emission)
+		// Using generic keys keeps conversation.go agnostic about specific tool implementations
+		toolCtx = context.WithValue(toolCtx, ToolExecutionAgentKey, a)
+		toolCtx = context.WithValue(toolCtx, ToolExecutionTurnKey, turn+1)
+		toolCtx = context.WithValue(toolCtx, ToolExecutionServerKey, serverName)
+		toolCtx = context.WithValue(toolCtx, ToolExecutionLLMConfigKey, a.GetLLMModelConfig())
+		var fromCache atomic.Bool
+		toolCtx = withToolResultCacheHit(toolCtx, &fromCache)
+		toolCtx = a.withToolProgressEvents(toolCtx, turn+1, tc.FunctionCall.Name, serverName, tc.ID)
+
+		// Apply per-tool argument transformer if registered.
+		// This runs BEFORE any execution branch (virtual → custom → MCP) so all paths
+		// see transformed args. Primary use case: resolve workspace-relative paths
+		// (e.g. "Downloads/file.pdf") to absolute host paths for tools that require them.
+		// Transformers are registered via Agent.SetToolArgTransformer().
+		if a.toolArgTransformers != nil {
+			if transformer, ok := a.toolArgTransformers[tc.FunctionCall.Name]; ok {
+				v2Logger.Info("[TOOL_ARGS] Applying tool arg transformer",
+					loggerv2.String("tool_name", tc.FunctionCall.Name))
+				transformer(args)
+			}
+		}
+
+		// Resolve the LLM-facing disambiguated name to the name registered by MCP.
+		actualToolName := actualMCPToolName(tc.FunctionCall.Name, serverName)
+		if actualToolName != tc.FunctionCall.Name {
+			v2Logger.Debug(fmt.Sprintf("🔧 [TOOL_LOOKUP] Resolved disambiguated tool '%s' -> '%s' (server: %s)", tc.FunctionCall.Name, actualToolName, serverName))
+		}
+
+		execute := func(toolCtx context.Context, call *ToolInvocation) (*mcp.CallToolResult, error) {
+			var result *mcp.CallToolResult
+			var toolErr error
+			// Check if this is a virtual tool
+			if isVirtualTool(tc.FunctionCall.Name) {
+				// Handle virtual tool execution
+				v2Logger.Debug("🔧 [TOOL_CALL] Executing virtual tool",
+					loggerv2.String("tool_name", tc.FunctionCall.Name))
+				resultText, toolErr := a.HandleVirtualTool(toolCtx, tc.FunctionCall.Name, call.Arguments)
+				if toolErr != nil {
+					result = &mcp.CallToolResult{
+						IsError: true,
+						Content: []mcp.Content{&mcp.TextContent{Text: toolErr.Error()}},
+					}
+				} else {
+					// Ensure resultText is never empty for virtual tools
+					// This prevents empty content from being sent to LLM
+					if resultText == "" {
+						v2Logger.Warn("Virtual tool returned empty result - using default message",
+							loggerv2.String("tool", tc.FunctionCall.Name))
+						resultText = fmt.Sprintf("Tool '%s' executed successfully but returned no output.", tc.FunctionCall.Name)
+					}
+					result = &mcp.CallToolResult{
+						IsError: false,
+						Content: []mcp.Content{&mcp.TextContent{Text: resultText}},
+					}
+
+					// If this was add_tool in tool search mode, refresh the tools list
+					// to include newly discovered tools
+					if a.UseToolSearchMode && tc.FunctionCall.Name == "add_tool" {
+						a.filteredTools = a.applyToolHints(ctx, a.applyToolPermissions(a.getToolsForToolSearchMode()))
+						v2Logger.Debug("🔍 [TOOL_SEARCH] Tools refreshed after add_tool",
+							loggerv2.Int("discovered_count", a.GetDiscoveredToolCount()),
+							loggerv2.Int("total_available", len(a.filteredTools)))
+					}
+				}
+			} else if a.customTools != nil {
+				// Check if this is a custom tool
+				if customTool, exists := a.customTools[tc.FunctionCall.Name]; exists {
+					v2Logger.Debug(fmt.Sprintf("🔧 [TOOL_EXECUTION] Executing custom tool '%s' (category: %s)", tc.FunctionCall.Name, customTool.Category))
+					// Handle custom tool execution using the stored execution function
+					resultText, toolErr := customTool.Execution(toolCtx, call.Arguments)
+
+					if toolErr != nil {
+						v2Logger.Error(fmt.Sprintf("🔧 [TOOL_EXECUTION] Custom tool '%s' execution failed: %v", tc.FunctionCall.Name, toolErr), toolErr)
+						result = &mcp.CallToolResult{
+							IsError: true,
+							Content: []mcp.Content{&mcp.TextContent{Text: toolErr.Error()}},
+						}
+					} else {
+						v2Logger.Debug(fmt.Sprintf("🔧 [TOOL_EXECUTION] Custom tool '%s' executed successfully (result length: %d chars)", tc.FunctionCall.Name, len(resultText)))
+						result = &mcp.CallToolResult{
+							IsError: false,
+							Content: []mcp.Content{&mcp.TextContent{Text: resultText}},
+						}
+					}
+				} else {
+					// Handle regular MCP tool execution
+					v2Logger.Debug("🔧 [TOOL_CALL] About to call MCP tool via client (from customTools fallback)",
+						loggerv2.String("tool_name", actualToolName),
+						loggerv2.String("server_name", serverName),
+						loggerv2.String("timeout", toolTimeout.String()))
+					callStart := time.Now()
+					result, toolErr = a.callMCPTool(toolCtx, client, actualToolName, call.Arguments, v2Logger, serverName)
+					callDuration := time.Since(callStart)
+					v2Logger.Debug("🔧 [TOOL_CALL] MCP tool call completed (from customTools fallback)",
+						loggerv2.String("tool_name", tc.FunctionCall.Name),
+						loggerv2.String("server_name", serverName),
+						loggerv2.String("duration", callDuration.String()),
+						loggerv2.Any("ctx_done", toolCtx.Err() != nil),
+						loggerv2.Any("has_error", toolErr != nil))
+				}
+			} else {
+				// Handle regular MCP tool execution
+				v2Logger.Debug("🔧 [TOOL_CALL] About to execute MCP tool",
+					loggerv2.String("tool_name", actualToolName),
+					loggerv2.String("server_name", serverName),
+					loggerv2.String("timeout", toolTimeout.String()))
+				callStart := time.Now()
+				result, toolErr = a.callMCPTool(toolCtx, client, actualToolName, call.Arguments, v2Logger, serverName)
+				callDuration := time.Since(callStart)
+				v2Logger.Debug("🔧 [TOOL_CALL] MCP tool call completed",
+					loggerv2.String("tool_name", tc.FunctionCall.Name),
+					loggerv2.String("server_name", serverName),
+					loggerv2.String("duration", callDuration.String()),
+					loggerv2.Any("ctx_done", toolCtx.Err() != nil),
+					loggerv2.Any("has_error", toolErr != nil))
+			}
+			return result, toolErr
+		}
+		invocation := &ToolInvocation{
+			ID:         tc.ID,
+			Name:       tc.FunctionCall.Name,
+			ServerName: serverName,
+			Type:       toolType,
+			Turn:       turn + 1,
+			Arguments:  args,
+		}
+		result, toolErr := a.executeWithToolMiddleware(toolCtx, invocation, execute)
+
+		duration := time.Since(startTime)
+		v2Logger.Info(fmt.Sprintf("⏱️  TOOL EXECUTION END - Time: %s, Tool: %s, Duration: %v, Turn: %d",
+			time.Now().Format(time.RFC3339), tc.FunctionCall.Name, duration, turn+1))
+		log.Printf("[LATENCY_DEBUG] Turn %d | T+%dms | Tool executed: %s | tool_duration=%dms err=%v",
+			turn+1, time.Since(conversationStartTime).Milliseconds(), tc.FunctionCall.Name, duration.Milliseconds(), toolErr)
+
+		// Check for timeout: the model gets a structured timeout result with
+		// any partial output instead of an error
+		if toolCtx.Err() == context.DeadlineExceeded {
+			v2Logger.Debug("Tool call timed out",
+				loggerv2.Int("turn", turn+1),
+				loggerv2.String("tool_name", tc.FunctionCall.Name),
+				loggerv2.String("timeout", toolTimeout.String()))
+			result, toolErr = toolTimeoutResult(tc.FunctionCall.Name, serverName, toolTimeout, result), nil
+		}
+
+		if agentCtx.Err() != nil {
+			v2Logger.Debug("Context cancelled after tool execution (will stop after appending result)",
+				loggerv2.Int("turn", turn+1),
+				loggerv2.String("tool_name", tc.FunctionCall.Name),
+				loggerv2.Error(agentCtx.Err()))
+			// Don't return early here — the tool result must be appended to messages
+			// first (line ~1613) to keep the conversation state valid (every tool call
+			// needs a response). The cancellation will be caught at the start of the
+			// next turn (line 533) or before the next tool execution (line 1222).
+		}
+
+		// Handle tool execution errors gracefully - provide feedback to LLM and continue
+		if toolErr != nil {
+			// 🔧 DEBUG: Log tool error
+			v2Logger.Debug("🔧 [TOOL_RESPONSE] Tool execution error",
+				loggerv2.String("tool_name", tc.FunctionCall.Name),
+				loggerv2.String("tool_type", toolType),
+				loggerv2.String("server_name", serverName),
+				loggerv2.String("tool_call_id", tc.ID),
+				loggerv2.Int("turn", turn+1),
+				loggerv2.String("error", toolErr.Error()),
+				loggerv2.String("duration", duration.String()))
+
+			// 🔧 ENHANCED ERROR RECOVERY HANDLING
+			errorRecoveryHandler := NewErrorRecoveryHandler(a)
+
+			// Attempt error recovery for recoverable errors
+			recoveredResult, recoveredDuration, wasRecovered, recoveredErr := errorRecoveryHandler.HandleError(
+				ctx, &tc, serverName, toolErr, startTime, isCustomTool, isVirtualTool(tc.FunctionCall.Name))
+
+			if wasRecovered && recoveredErr == nil {
+				// Successfully recovered - use recovered result and continue normal flow
+				v2Logger.Debug("Successfully recovered from error for tool",
+					loggerv2.String("tool", tc.FunctionCall.Name))
+				result = recoveredResult
+				toolErr = nil
+				duration = recoveredDuration
+				// Continue to normal result processing below (outside this if block)
+			} else {
+				// Recovery failed or not attempted - proceed with error handling
+				if wasRecovered {
+					v2Logger.Error("Recovery failed for tool", recoveredErr,
+						loggerv2.String("tool", tc.FunctionCall.Name))
+					toolErr = recoveredErr
+					duration = recoveredDuration
+				}
+
+				// Emit tool call error event using typed event data
+				toolErrorEvent := events.NewToolCallErrorEvent(turn+1, tc.FunctionCall.Name, toolErr.Error(), serverName, duration)
+				toolErrorEvent.ToolCallID = tc.ID
+				a.EmitTypedEvent(ctx, toolErrorEvent)
+
+				// Instead of failing the entire conversation, provide feedback to the LLM
+				errorResultText := fmt.Sprintf("Tool execution failed - %v", toolErr)
+
+				// Add the error result to the conversation so the LLM can continue
+				messages = append(messages, llmtypes.MessageContent{
+					Role:  llmtypes.ChatMessageTypeTool, // Use "tool" role for tool responses
+					Parts: []llmtypes.ContentPart{llmtypes.ToolCallResponse{ToolCallID: tc.ID, Name: tc.FunctionCall.Name, Content: errorResultText, IsError: true}},
+				})
+
+				// Continue to next turn instead of returning error
+				continue
+			}
+		}
+		var resultText string
+		var resultImages []llmtypes.ImageContent
+		if result != nil {
+
+			// Separate image content so it reaches the model as images, not base64 text
+			result, resultImages = a.processToolImages(ctx, tc.FunctionCall.Name, result)
+
+			// Get the tool result as string (without prefix)
+			resultText = mcpclient.ToolResultAsString(result)
+
+			// 🔧 DEBUG: Log tool response
+			resultPreview := resultText
+			if len(resultPreview) > 500 {
+				resultPreview = resultPreview[:500] + "... (truncated)"
+			}
+			v2Logger.Debug("🔧 [TOOL_RESPONSE] Tool response received",
+				loggerv2.String("tool_name", tc.FunctionCall.Name),
+				loggerv2.String("tool_type", toolType),
+				loggerv2.String("server_name", serverName),
+				loggerv2.String("tool_call_id", tc.ID),
+				loggerv2.Int("turn", turn+1),
+				loggerv2.Int("result_length", len(resultText)),
+				loggerv2.Any("is_error", result.IsError),
+				loggerv2.String("duration", duration.String()),
+				loggerv2.String("result_preview", resultPreview))
+
+			// Ensure resultText is never empty when sending to LLM
+			// This is a safety check for all tool types (virtual, custom, MCP)
+			if resultText == "" && !result.IsError {
+				v2Logger.Warn("Tool returned empty result - using default message",
+					loggerv2.String("tool", tc.FunctionCall.Name))
+				resultText = fmt.Sprintf("Tool '%s' executed successfully but returned no output.", tc.FunctionCall.Name)
+			}
+
+			// 🔧 BROKEN PIPE DETECTION IN RESULT CONTENT (regardless of IsError flag)
+			// Check for broken pipe errors in content text, even when IsError is false
+			// This handles cases where the MCP server returns broken pipe errors in content rather than as error flags
+			if mcpclient.IsBrokenPipeInContent(resultText) {
+				v2Logger.Info(fmt.Sprintf("🔧 [BROKEN PIPE DETECTED IN RESULT] Turn %d, Tool: %s, Server: %s, IsError: %v - Attempting immediate connection recreation", turn+1, tc.FunctionCall.Name, serverName, result.IsError))
+
+				// Create error recovery handler
+				errorRecoveryHandler := NewErrorRecoveryHandler(a)
+
+				// Create a fake error for the recovery handler
+				fakeErr := fmt.Errorf("broken pipe detected in result: %s", resultText)
+
+				// Attempt error recovery
+				recoveredResult, recoveredDuration, wasRecovered, recoveredErr := errorRecoveryHandler.HandleError(
+					ctx, &tc, serverName, fakeErr, startTime, isCustomTool, isVirtualTool(tc.FunctionCall.Name))
+
+				if wasRecovered && recoveredErr == nil {
+					v2Logger.Debug("Broken pipe recovery successful for tool",
+						loggerv2.String("tool", tc.FunctionCall.Name))
+					result, resultImages = a.processToolImages(ctx, tc.FunctionCall.Name, recoveredResult)
+					duration = recoveredDuration
+					resultText = mcpclient.ToolResultAsString(result)
+				} else if wasRecovered {
+					v2Logger.Error("Broken pipe recovery failed for tool", recoveredErr,
+						loggerv2.String("tool", tc.FunctionCall.Name))
+				}
+			}
+
+			// Context offloading: Check if tool output should be offloaded to filesystem
+			if a.EnableContextOffloading && a.shouldUseWrapperTokenCounting() {
+				// Check if output exceeds threshold for context offloading
+				if a.toolOutputHandler.IsLargeToolOutputWithModel(resultText, a.ModelID) {
+
+					// Emit context offloading detection event
+					detectedEvent := events.NewLargeToolOutputDetectedEvent(tc.FunctionCall.Name, len(resultText), a.toolOutputHandler.GetToolOutputFolder())
+					detectedEvent.ServerAvailable = a.toolOutputHandler.IsServerAvailable()
+					a.EmitTypedEvent(ctx, detectedEvent)
+
+					// Offload large output to filesystem (context offloading)
+					filePath, writeErr := a.toolOutputHandler.WriteToolOutputToFile(resultText, tc.FunctionCall.Name)
+					if writeErr == nil {
+						// Extract first 100 characters for Langfuse observability
+						preview := a.toolOutputHandler.ExtractFirstNCharacters(resultText, 100)
+
+						// Emit successful file write event with preview
+						fileWrittenEvent := events.NewLargeToolOutputFileWrittenEvent(tc.FunctionCall.Name, filePath, len(resultText), preview)
+						a.EmitTypedEvent(ctx, fileWrittenEvent)
+
+						// Create message with file path, first 50% of threshold, and instructions
+						fileMessage := a.toolOutputHandler.CreateToolOutputMessageWithPreview(tc.ID, filePath, resultText, 50, false)
+
+						// Replace the result text with the file message
+						resultText = fileMessage
+
+					} else {
+						// Emit file write error event
+						fileErrorEvent := events.NewLargeToolOutputFileWriteErrorEvent(tc.FunctionCall.Name, writeErr.Error(), len(resultText))
+						a.EmitTypedEvent(ctx, fileErrorEvent)
+					}
+				}
+			}
+
+			// Safety check: Apply max token limit truncation regardless of context offloading setting
+			// This prevents API errors from prompts exceeding model context limits
+			if a.shouldUseWrapperTokenCounting() && a.toolOutputHandler.ExceedsMaxTokenLimit(resultText, a.ModelID) {
+				truncatedResult, wasTruncated := a.toolOutputHandler.TruncateToMaxTokenLimit(resultText, a.ModelID, tc.FunctionCall.Name)
+				if wasTruncated {
+					v2Logger.Warn("Tool output exceeded max token limit, truncated",
+						loggerv2.String("tool", tc.FunctionCall.Name),
+						loggerv2.Int("original_length", len(resultText)),
+						loggerv2.Int("truncated_length", len(truncatedResult)),
+						loggerv2.Int("max_tokens", a.toolOutputHandler.GetMaxToolOutputTokens()))
+					resultText = truncatedResult
+				}
+			}
+		} else {
+			resultText = "Tool execution completed but no result returned"
+		}
+		// 3. Append the tool result as a new message (after the AI tool_call message)
+		// Add recover block to catch panics
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					v2Logger.Error("Panic while appending tool result message", fmt.Errorf("%v", r))
+				}
+			}()
+			// Use the exact tool call ID from the LLM response
+			toolResponse := llmtypes.ToolCallResponse{ToolCallID: tc.ID, Name: tc.FunctionCall.Name, Content: resultText, IsError: result != nil && result.IsError}
+			if len(resultImages) > 0 {
+				if a.toolImagesInToolResult() {
+					toolResponse.Images = resultImages
+				} else {
+					toolImageBatches = append(toolImageBatches, toolImageBatch{toolName: tc.FunctionCall.Name, images: resultImages})
+				}
+			}
+			messages = append(messages, llmtypes.MessageContent{
+				Role:  llmtypes.ChatMessageTypeTool, // Use "tool" role for tool responses
+				Parts: []llmtypes.ContentPart{toolResponse},
+			})
+		}()
+
+		// End the tool execution span with output and error information
+		toolOutput := map[string]interface{}{
+			"tool_name":   tc.FunctionCall.Name,
+			"server_name": a.toolToServer[tc.FunctionCall.Name],
+			"result":      resultText,
+			"duration":    duration,
+			"turn":        turn + 1,
+			"success":     toolErr == nil,
+			"timeout":     toolTimeout.String(),
+		}
+		if toolErr != nil {
+			toolOutput["error"] = toolErr.Error()
+			if strings.Contains(toolErr.Error(), "timed out") {
+				toolOutput["error_type"] = "tool_execution_timeout"
+			} else {
+				toolOutput["error_type"] = "tool_execution_error"
+			}
+		}
+
+		// Tool execution completed - emit tool call end event
+		// Only emit ToolCallEndEvent if result is not an error (errors should emit ToolCallErrorEvent)
+		if result == nil || !result.IsError {
+			// Get current token usage information for the tool call end event
+			_, _, _, _, _, _, _, _, _, _, _, _, contextUsagePercent := a.GetTokenUsageWithPricing()
+			a.tokenTrackingMutex.RLock()
+			modelContextWindow := a.modelContextWindow
+			contextWindowUsage := a.currentContextWindowUsage
+			a.tokenTrackingMutex.RUnlock()
+
+			// Emit tool call end event using typed event data (consolidated - contains all tool information)
+			toolEndEvent := events.NewToolCallEndEventWithTokenUsageAndModel(turn+1, tc.FunctionCall.Name, resultText, serverName, duration, "", contextUsagePercent, modelContextWindow, contextWindowUsage, a.ModelID)
+			toolEndEvent.ToolCallID = tc.ID
+			toolEndEvent.FromCache = fromCache.Load()
+			a.EmitTypedEvent(ctx, toolEndEvent)
+		} else if result.IsError {
+			// Result contains an error - emit tool call error event
+			// This handles the case where tool execution succeeded but the tool returned an error result
+			toolErrorEvent := events.NewToolCallErrorEvent(turn+1, tc.FunctionCall.Name, resultText, serverName, duration)
+			toolErrorEvent.ToolCallID = tc.ID
+			a.EmitTypedEvent(ctx, toolErrorEvent)
+		}
+
+		// Note: Removed redundant tool_output and tool_response events
+		// tool_call_end now contains all necessary tool information
+
+		// 🔄 LOOP DETECTION: Check if this tool call + args + response matches recent calls
+		if tc.FunctionCall != nil {
+			loopResult := loopDetector.CheckAndHandleLoop(tc.FunctionCall.Name, tc.FunctionCall.Arguments, resultText)
+			if loopResult.Detected {
+				HandleLoopDetection(a, ctx, loopResult, lastUserMessage, turn+1, conversationStartTime, &messages, v2Logger)
+				// Continue to next turn so LLM can respond to the correction message
+			}
+		}
+	}
+	messages = appendToolImageMessage(messages, toolImageBatches)
+	return messages, nil
+}
+
 // promptLogCounter is a global counter for ordering prompt log files within a session.
 var promptLogCounter uint64
 var promptLogCounterMu sync.Mutex
@@ -0,0 +1,191 @@
+// dry_run.go
+//
+// This file implements dry-run mode: the agent runs read tools as usual but
+// does not run tools that change state. A write tool call gets a simulated
+// "would have executed" result, so the LLM carries on with its plan, and a
+// dry_run_tool_blocked event records what would have run. This previews an
+// agent's plan against production MCP servers safely.
+//
+// A tool is classified, in order, by:
+//   - "read_only_tools" / "write_tools" of its server in the MCP config
+//   - the readOnlyHint / destructiveHint annotations of an MCP tool
+//   - virtual tools: read, except the workspace edit tools
+//   - the verbs in its name (get_issue and list_files read, create_issue,
+//     send_message and execute_shell_command write)
+//   - the category of a custom tool, the same way
+//
+// A tool none of these classify counts as a write, so dry-run mode fails
+// safe for unfamiliar tools. Dry run only sees tool calls, made through the
+// tool middleware chain, so NewAgent refuses it in code execution mode and
+// with coding CLI providers: generated code and the CLIs' built-in tools
+// change state without a tool call.
+//
+// Exported:
+//   - WithDryRun: Turn dry-run mode on or off
+
+package mcpagent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/manishiitg/mcpagent/events"
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// WithDryRun turns dry-run mode on or off. In dry-run mode only tools that
+// read are run; calls to tools that write get a simulated "would have
+// executed" result instead. List a server's tools under "read_only_tools" or
+// "write_tools" in the MCP config where the annotations or the name-based
+// guess are wrong. Not supported in code execution mode or with coding CLI
+// providers: NewAgent returns an error.
+//
+// Default: false
+func WithDryRun(enabled bool) AgentOption {
+	return func(a *Agent) {
+		a.DryRun = enabled
+	}
+}
+
+// readToolVerbs are name words of tools that only read. Words that also name
+// writes (resolve an issue, log an event, export or download to a file, check
+// a box) are left out.
+var readToolVerbs = map[string]bool{
+	"get": true, "list": true, "read": true, "search": true, "find": true, "query": true,
+	"fetch": true, "describe": true, "show": true, "view": true, "lookup": true, "count": true,
+	"inspect": true, "status": true, "diff": true, "browse": true, "explain": true,
+	"preview": true, "validate": true, "info": true, "tree": true, "stat": true, "retrieve": true,
+}
+
+// writeToolVerbs are name words of tools that change state
+var writeToolVerbs = map[string]bool{
+	"create": true, "update": true, "delete": true, "remove": true, "write": true, "edit": true,
+	"set": true, "put": true, "post": true, "patch": true, "send": true, "insert": true,
+	"add": true, "move": true, "rename": true, "copy": true, "commit": true, "push": true,
+	"merge": true, "execute": true, "exec": true, "run": true, "apply": true, "upload": true,
+	"deploy": true, "publish": true, "cancel": true, "close": true, "approve": true, "reject": true,
+	"archive": true, "restore": true, "reset": true, "replace": true, "modify": true, "save": true,
+	"submit": true, "trigger": true, "invite": true, "assign": true, "start": true, "stop": true,
+	"kill": true, "drop": true, "truncate": true, "clear": true, "purge": true, "install": true,
+	"uninstall": true, "enable": true, "disable": true, "lock": true, "unlock": true, "transfer": true,
+	"pay": true, "refund": true, "schedule": true, "reply": true, "comment": true, "mkdir": true,
+}
+
+// dryRunExec wraps execute, the innermost step of the tool middleware chain,
+// so only calls that passed every other check are simulated
+func (a *Agent) dryRunExec(execute ToolExecFunc) ToolExecFunc {
+	return func(ctx context.Context, call *ToolInvocation) (*mcp.CallToolResult, error) {
+		if !a.DryRun {
+			return execute(ctx, call)
+		}
+		readOnly, classification := a.classifyToolAccess(ctx, call)
+		if readOnly {
+			return execute(ctx, call)
+		}
+
+		arguments := "{}"
+		if len(call.Arguments) > 0 {
+			if encoded, err := json.Marshal(call.Arguments); err == nil {
+				arguments = truncateUTF8(string(encoded), 2000)
+			}
+		}
+		a.EmitTypedEvent(ctx, events.NewDryRunToolBlockedEvent(call.Turn, call.Name, call.ServerName, call.ID, arguments, classification))
+		getLogger(a).Info("🧪 [DRY_RUN] Write tool call simulated, not run",
+			loggerv2.String("tool", call.Name),
+			loggerv2.String("server", call.ServerName),
+			loggerv2.String("classification", classification))
+		return mcp.NewToolResultText(fmt.Sprintf("DRY RUN: would have executed %s with arguments %s. Tools that change state are not run in dry-run mode. "+
+			"Continue your plan as if the call succeeded, without relying on output it would have returned.", call.Name, arguments)), nil
+	}
+}
+
+// classifyToolAccess reports whether call's tool only reads, and what
+// decided it: "config", "annotation", "virtual", "name", "category" or
+// "unknown"
+func (a *Agent) classifyToolAccess(ctx context.Context, call *ToolInvocation) (readOnly bool, classification string) {
+	if call.Type == "MCP" {
+		tool := actualMCPToolName(call.Name, call.ServerName)
+		if config, ok := a.serverConfigs[call.ServerName]; ok {
+			if readOnly, ok := config.GetToolReadOnly(tool); ok {
+				return readOnly, "config"
+			}
+		}
+		if annotation, ok := a.mcpToolAnnotations(ctx, a.Clients[call.ServerName], call.ServerName, tool); ok {
+			if readOnly, ok := classifyToolAnnotation(annotation); ok {
+				return readOnly, "annotation"
+			}
+		}
+	}
+	if call.Type == "virtual" {
+		return !isWorkspaceEditTool(call.Name), "virtual"
+	}
+	if readOnly, ok := classifyToolName(actualMCPToolName(call.Name, call.ServerName)); ok {
+		return readOnly, "name"
+	}
+	if tool, ok := a.customTools[call.Name]; ok && call.Type == "custom" {
+		if readOnly, ok := classifyToolName(tool.Category); ok {
+			return readOnly, "category"
+		}
+	}
+	return false, "unknown"
+}
+
+// classifyToolAnnotation reads the MCP annotations of a tool: a declared
+// readOnlyHint decides, otherwise a destructiveHint marks a write
+func classifyToolAnnotation(annotation mcp.ToolAnnotation) (readOnly, ok bool) {
+	if annotation.ReadOnlyHint != nil {
+		return *annotation.ReadOnlyHint, true
+	}
+	if annotation.DestructiveHint != nil && *annotation.DestructiveHint {
+		return false, true
+	}
+	return false, false
+}
+
+// classifyToolName guesses from its words whether a tool named name only
+// reads: any write verb makes it a write, otherwise a read verb a read
+func classifyToolName(name string) (readOnly, ok bool) {
+	words := toolNameWords(name)
+	for _, word := range words {
+		if writeToolVerbs[word] {
+			return false, true
+		}
+	}
+	for _, word := range words {
+		if readToolVerbs[word] {
+			return true, true
+		}
+	}
+	return false, false
+}
+
+// toolNameWords splits a snake_case, kebab-case, dotted or camelCase name
+// into lower-case words
+func toolNameWords(name string) []string {
+	var words []string
+	var word []rune
+	flush := func() {
+		if len(word) > 0 {
+			words = append(words, strings.ToLower(string(word)))
+			word = word[:0]
+		}
+	}
+	runes := []rune(name)
+	for i, r := range runes {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			flush()
+		case unicode.IsUpper(r) && i > 0 && (unicode.IsLower(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))):
+			flush()
+			word = append(word, r)
+		default:
+			word = append(word, r)
+		}
+	}
+	flush()
+	return words
+}
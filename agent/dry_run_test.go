@@ -0,0 +1,152 @@
+package mcpagent
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/manishiitg/mcpagent/events"
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+	"github.com/manishiitg/mcpagent/mcpclient"
+)
+
+func TestClassifyToolName(t *testing.T) {
+	cases := []struct {
+		name     string
+		readOnly bool
+		ok       bool
+	}{
+		{"get_issue", true, true},
+		{"list_directory", true, true},
+		{"searchRepositories", true, true},
+		{"create_issue", false, true},
+		{"execute_shell_command", false, true},
+		{"sendSlackMessage", false, true},
+		{"get_or_create_label", false, true}, // any write verb wins
+		{"HTTPRequestPost", false, true},
+		{"resolve_incident", false, false}, // ambiguous verbs do not mark reads
+		{"export_report", false, false},
+		{"weather", false, false},
+	}
+	for _, c := range cases {
+		if readOnly, ok := classifyToolName(c.name); readOnly != c.readOnly || ok != c.ok {
+			t.Errorf("classifyToolName(%q) = %v, %v; want %v, %v", c.name, readOnly, ok, c.readOnly, c.ok)
+		}
+	}
+}
+
+func TestDryRunSimulatesWriteToolCalls(t *testing.T) {
+	listener := &recordingAgentEventListener{}
+	a := &Agent{Logger: loggerv2.NewNoop(), listeners: []AgentEventListener{listener}}
+	WithDryRun(true)(a)
+	a.serverConfigs = map[string]mcpclient.MCPServerConfig{
+		"github": {ReadOnlyTools: []string{"run_query"}, WriteTools: []string{"get_or_fork"}},
+	}
+
+	var ran []string
+	execute := func(_ context.Context, call *ToolInvocation) (*mcp.CallToolResult, error) {
+		ran = append(ran, call.Name)
+		return mcp.NewToolResultText("ok"), nil
+	}
+	call := func(name, server, toolType string) string {
+		result, err := a.executeWithToolMiddleware(context.Background(),
+			&ToolInvocation{ID: "call_" + name, Name: name, ServerName: server, Type: toolType, Turn: 1,
+				Arguments: map[string]interface{}{"title": "Crash on start"}}, execute)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result.Content[0].(mcp.TextContent).Text
+	}
+
+	for _, c := range []struct{ name, server, toolType string }{
+		{"get_issue", "github", "MCP"},
+		{"run_query", "github", "MCP"}, // config overrides the "run" verb
+		{"search_large_output", "virtual-tools", "virtual"},
+	} {
+		if text := call(c.name, c.server, c.toolType); text != "ok" {
+			t.Errorf("read tool %s not run: %q", c.name, text)
+		}
+	}
+	for _, c := range []struct{ name, server, toolType string }{
+		{"create_issue", "github", "MCP"},
+		{"get_or_fork", "github", "MCP"},
+		{"ping", "github", "MCP"}, // unclassified tools count as writes
+		{"apply_patch", "virtual-tools", "virtual"},
+	} {
+		if text := call(c.name, c.server, c.toolType); !strings.HasPrefix(text, "DRY RUN: would have executed "+c.name) {
+			t.Errorf("write tool %s: %q", c.name, text)
+		}
+	}
+	if strings.Join(ran, ",") != "get_issue,run_query,search_large_output" {
+		t.Errorf("tools run = %v", ran)
+	}
+
+	var blocked []string
+	for _, e := range listener.events {
+		if ev, ok := e.Data.(*events.DryRunToolBlockedEvent); ok {
+			blocked = append(blocked, ev.ToolName+":"+ev.Classification)
+			if !strings.Contains(ev.Arguments, "Crash on start") {
+				t.Errorf("event arguments = %q", ev.Arguments)
+			}
+		}
+	}
+	if got := strings.Join(blocked, ","); got != "create_issue:name,get_or_fork:config,ping:unknown,apply_patch:virtual" {
+		t.Errorf("dry_run_tool_blocked events = %s", got)
+	}
+}
+
+// annotatedClient lists tools with MCP annotations
+type annotatedClient struct {
+	mcpclient.ClientInterface
+	tools []mcp.Tool
+	lists int
+}
+
+func (c *annotatedClient) ListTools(context.Context) ([]mcp.Tool, error) {
+	c.lists++
+	return c.tools, nil
+}
+
+func TestDryRunHonorsToolAnnotations(t *testing.T) {
+	client := &annotatedClient{tools: []mcp.Tool{
+		mcp.NewTool("get_quota", mcp.WithReadOnlyHintAnnotation(false)),
+		mcp.NewTool("run_report", mcp.WithReadOnlyHintAnnotation(true)),
+		{Name: "wipe_cache", Annotations: mcp.ToolAnnotation{DestructiveHint: mcp.ToBoolPtr(true)}},
+		{Name: "list_users"},
+	}}
+	a := &Agent{Logger: loggerv2.NewNoop(), Clients: map[string]mcpclient.ClientInterface{"ops": client}}
+	for _, c := range []struct {
+		tool           string
+		readOnly       bool
+		classification string
+	}{
+		{"get_quota", false, "annotation"}, // the declared hint beats the "get" verb
+		{"run_report", true, "annotation"},
+		{"wipe_cache", false, "annotation"},
+		{"list_users", true, "name"}, // no hints: the name decides
+	} {
+		readOnly, classification := a.classifyToolAccess(context.Background(), &ToolInvocation{Name: c.tool, ServerName: "ops", Type: "MCP"})
+		if readOnly != c.readOnly || classification != c.classification {
+			t.Errorf("%s: got %v, %s; want %v, %s", c.tool, readOnly, classification, c.readOnly, c.classification)
+		}
+	}
+	if client.lists != 1 {
+		t.Errorf("tools listed %d times, want once", client.lists)
+	}
+}
+
+func TestDryRunRefusedWhereToolCallsBypassMiddleware(t *testing.T) {
+	config := filepath.Join(t.TempDir(), "mcp.json")
+	if err := os.WriteFile(config, []byte(`{"mcpServers": {}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	_, err := NewAgent(context.Background(), &providerKeyCarrierModel{}, config,
+		WithLogger(loggerv2.NewNoop()), WithDryRun(true), WithCodeExecutionMode(true))
+	if err == nil || !strings.Contains(err.Error(), "dry-run") {
+		t.Errorf("dry run in code execution mode: err = %v", err)
+	}
+}
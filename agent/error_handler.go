@@ -104,6 +104,7 @@ func (h *BrokenPipeHandler) HandleBrokenPipeError(
 	h.agent.clientsMu.Lock()
 	h.agent.Clients[serverName] = freshClient
 	h.agent.clientsMu.Unlock()
+	h.agent.watchConnectionState(serverName, freshClient)
 	h.logger.Info(fmt.Sprintf("🔧 [BROKEN PIPE] Updated agent's client map with fresh connection for server: %s", serverName),
 		loggerv2.String("server", serverName))
 
@@ -150,7 +151,7 @@ func (h *BrokenPipeHandler) recreateViaRegistry(ctx context.Context, serverName
 	// handlers from spawning duplicate connections for the same server.
 	client, _, err := registry.GetOrCreateConnection(ctx, connSessionID, serverName, serverConfig, h.logger)
 	if err != nil {
-		return nil, fmt.Errorf("registry GetOrCreateConnection failed: %w", err)
+		return nil, withSentinel(ErrMCPConnectionFailed, fmt.Errorf("registry GetOrCreateConnection failed: %w", err))
 	}
 	return client, nil
 }
@@ -0,0 +1,101 @@
+// errors.go
+//
+// This file defines the agent's error taxonomy: sentinel errors for the
+// failures callers commonly branch on. Errors returned by Ask, AskWithHistory
+// and NewAgent match them with errors.Is, while errors.As still reaches the
+// underlying cause (e.g. *llmerrors.Error or *BudgetExceededError).
+//
+// Exported:
+//   - ErrMCPConnectionFailed, ErrToolNotFound, ErrContextWindowExceeded,
+//     ErrLLMRateLimited, ErrBudgetExceeded: Sentinel errors
+//   - ErrorCode: Stable code of an error, as carried in event payloads
+
+package mcpagent
+
+import (
+	"errors"
+
+	"github.com/manishiitg/mcpagent/llm"
+	"github.com/manishiitg/multi-llm-provider-go/llmerrors"
+)
+
+var (
+	// ErrMCPConnectionFailed is returned when an MCP server cannot be connected
+	ErrMCPConnectionFailed = errors.New("mcp connection failed")
+	// ErrToolNotFound is returned when a tool is called that the agent does not have
+	ErrToolNotFound = errors.New("tool not found")
+	// ErrContextWindowExceeded is returned when the conversation no longer fits
+	// the model's context window
+	ErrContextWindowExceeded = errors.New("context window exceeded")
+	// ErrLLMRateLimited is returned when the LLM provider rate limits the agent,
+	// or a call would wait longer than llm.RateLimit.MaxWait
+	ErrLLMRateLimited = errors.New("llm rate limited")
+	// ErrBudgetExceeded is matched by *BudgetExceededError
+	ErrBudgetExceeded = errors.New("budget exceeded")
+)
+
+// errorCodes are the codes ErrorCode returns, in match order
+var errorCodes = []struct {
+	sentinel error
+	code     string
+}{
+	{ErrBudgetExceeded, "budget_exceeded"},
+	{ErrContextWindowExceeded, "context_window_exceeded"},
+	{ErrLLMRateLimited, "llm_rate_limited"},
+	{ErrToolNotFound, "tool_not_found"},
+	{ErrMCPConnectionFailed, "mcp_connection_failed"},
+}
+
+// ErrorCode returns the code of the sentinel err matches, such as
+// "budget_exceeded" or "mcp_connection_failed", or "" if it matches none
+func ErrorCode(err error) string {
+	err = classifyError(err)
+	for _, c := range errorCodes {
+		if errors.Is(err, c.sentinel) {
+			return c.code
+		}
+	}
+	return ""
+}
+
+// taxonomyError attaches a sentinel to an error without changing its message
+type taxonomyError struct {
+	sentinel error
+	err      error
+}
+
+func (e *taxonomyError) Error() string { return e.err.Error() }
+
+func (e *taxonomyError) Unwrap() []error { return []error{e.sentinel, e.err} }
+
+// withSentinel makes err match sentinel with errors.Is
+func withSentinel(sentinel, err error) error {
+	if err == nil || errors.Is(err, sentinel) {
+		return err
+	}
+	return &taxonomyError{sentinel: sentinel, err: err}
+}
+
+// classifyError attaches the sentinel of a provider failure (context window,
+// rate limit) to err; errors that already match a sentinel are returned as is
+func classifyError(err error) error {
+	if err == nil {
+		return nil
+	}
+	for _, c := range errorCodes {
+		if errors.Is(err, c.sentinel) {
+			return err
+		}
+	}
+	switch llmerrors.KindOf(err) {
+	case llmerrors.KindContextTooLong:
+		return withSentinel(ErrContextWindowExceeded, err)
+	case llmerrors.KindRateLimit:
+		return withSentinel(ErrLLMRateLimited, err)
+	}
+	var waitErr *llm.ErrRateLimitWait
+	if errors.As(err, &waitErr) {
+		return withSentinel(ErrLLMRateLimited, err)
+	}
+	return err
+}
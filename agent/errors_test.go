@@ -0,0 +1,100 @@
+package mcpagent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/manishiitg/mcpagent/events"
+	"github.com/manishiitg/mcpagent/llm"
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+	"github.com/manishiitg/multi-llm-provider-go/llmerrors"
+)
+
+func TestClassifyErrorAttachesSentinels(t *testing.T) {
+	contextErr := &llmerrors.Error{Kind: llmerrors.KindContextTooLong, Err: errors.New("prompt is too long")}
+	rateErr := &llmerrors.Error{Kind: llmerrors.KindRateLimit, Err: errors.New("429")}
+	tests := []struct {
+		name string
+		err  error
+		want error
+		code string
+	}{
+		{"context window", fmt.Errorf("llm error: %w", contextErr), ErrContextWindowExceeded, "context_window_exceeded"},
+		{"rate limit", fmt.Errorf("llm error: %w", rateErr), ErrLLMRateLimited, "llm_rate_limited"},
+		{"rate limit wait", &llm.ErrRateLimitWait{Provider: "openai", Wait: time.Minute, MaxWait: time.Second}, ErrLLMRateLimited, "llm_rate_limited"},
+		{"budget", fmt.Errorf("turn 3: %w", &BudgetExceededError{LimitUSD: 1, SpentUSD: 1.5}), ErrBudgetExceeded, "budget_exceeded"},
+		{"connection", withSentinel(ErrMCPConnectionFailed, errors.New("dial tcp: refused")), ErrMCPConnectionFailed, "mcp_connection_failed"},
+		{"tool", fmt.Errorf("unknown virtual tool: x: %w", ErrToolNotFound), ErrToolNotFound, "tool_not_found"},
+	}
+	for _, tt := range tests {
+		err := classifyError(tt.err)
+		if !errors.Is(err, tt.want) {
+			t.Errorf("%s: errors.Is(%v, %v) = false", tt.name, err, tt.want)
+		}
+		if err.Error() != tt.err.Error() {
+			t.Errorf("%s: message = %q, want %q", tt.name, err.Error(), tt.err.Error())
+		}
+		if got := ErrorCode(tt.err); got != tt.code {
+			t.Errorf("%s: ErrorCode() = %q, want %q", tt.name, got, tt.code)
+		}
+	}
+
+	if got := ErrorCode(errors.New("boom")); got != "" {
+		t.Errorf("ErrorCode(unclassified) = %q, want empty", got)
+	}
+	if classifyError(nil) != nil {
+		t.Error("classifyError(nil) != nil")
+	}
+}
+
+func TestClassifyErrorKeepsCauseReachable(t *testing.T) {
+	err := classifyError(fmt.Errorf("llm error: %w", &llmerrors.Error{Kind: llmerrors.KindRateLimit, Provider: "anthropic", Err: errors.New("429")}))
+
+	var llmErr *llmerrors.Error
+	if !errors.As(err, &llmErr) || llmErr.Provider != "anthropic" {
+		t.Fatalf("errors.As(*llmerrors.Error) failed for %v", err)
+	}
+	if errors.Is(err, ErrContextWindowExceeded) {
+		t.Error("rate limit error matches ErrContextWindowExceeded")
+	}
+
+	budget := classifyError(&BudgetExceededError{LimitUSD: 2, SpentUSD: 3})
+	var budgetErr *BudgetExceededError
+	if !errors.As(budget, &budgetErr) || budgetErr.SpentUSD != 3 {
+		t.Fatalf("errors.As(*BudgetExceededError) failed for %v", budget)
+	}
+}
+
+func TestAskWithHistoryReturnsTypedErrors(t *testing.T) {
+	contextErr := &llmerrors.Error{Kind: llmerrors.KindContextTooLong, Err: errors.New("prompt is too long")}
+	finalize := &recordingFinalizeStage{}
+	listener := &recordingAgentEventListener{}
+	a := &Agent{Logger: loggerv2.NewNoop(), LLM: &providerKeyCarrierModel{}, ModelID: "test-model", MaxTurns: 5}
+	WithAskPipeline(AskPipeline{Generate: failingGenerateStage{err: contextErr}, Finalize: finalize})(a)
+	a.AddEventListener(listener)
+
+	_, err := a.Ask(context.Background(), "hello")
+	if !errors.Is(err, ErrContextWindowExceeded) {
+		t.Fatalf("Ask err = %v, want ErrContextWindowExceeded", err)
+	}
+	var llmErr *llmerrors.Error
+	if !errors.As(err, &llmErr) {
+		t.Fatalf("Ask err = %v, want the *llmerrors.Error reachable", err)
+	}
+	if !errors.Is(finalize.err, ErrContextWindowExceeded) {
+		t.Errorf("finalize saw err = %v, want ErrContextWindowExceeded", finalize.err)
+	}
+
+	var code string
+	for _, event := range listener.events {
+		if e, ok := event.Data.(*events.ConversationErrorEvent); ok {
+			code = e.ErrorCode
+		}
+	}
+	if code != "context_window_exceeded" {
+		t.Errorf("conversation_error error_code = %q, want %q", code, "context_window_exceeded")
+	}
+}
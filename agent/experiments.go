@@ -0,0 +1,324 @@
+// experiments.go
+//
+// This file provides A/B experiments over prompts and agent options. An
+// experiment is a named set of weighted variants, each replacing the system
+// prompt and/or applying extra options. An agent created WithExperiment is
+// assigned one variant deterministically from its session ID, so every
+// conversation of a session sees the same variant across restarts and
+// servers. All events the agent emits, and the sessions it stores, are tagged
+// with the experiment and variant; ExperimentAggregator compares cost,
+// latency and validation pass-rate per variant from those events.
+//
+// Exported:
+//   - Experiment / ExperimentVariant: Experiment definition
+//   - RegisterExperiment / GetExperiment / ListExperiments: Registry
+//   - AssignExperimentVariant: The variant a session is assigned
+//   - WithExperiment: Enroll an agent in a registered experiment
+//   - Agent.ExperimentVariant: The agent's experiment and variant
+//   - ExperimentAggregator / VariantStats / AggregateExperiment: Per-variant comparison
+
+package mcpagent
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/manishiitg/mcpagent/events"
+)
+
+// Event metadata keys carrying the experiment assignment
+const (
+	ExperimentMetadataKey        = "experiment"
+	ExperimentVariantMetadataKey = "experiment_variant"
+)
+
+// Experiment is a named set of weighted variants
+type Experiment struct {
+	Name     string              `json:"name"`
+	Variants []ExperimentVariant `json:"variants"`
+}
+
+// ExperimentVariant is one arm of an experiment
+type ExperimentVariant struct {
+	Name string `json:"name"`
+	// Weight is the variant's relative share of sessions; variants with
+	// weight 0 receive no sessions
+	Weight int `json:"weight"`
+	// SystemPrompt replaces the agent's system prompt; "" keeps it
+	SystemPrompt string `json:"system_prompt,omitempty"`
+	// Options are applied after the agent's own options (Go only)
+	Options []AgentOption `json:"-"`
+}
+
+var (
+	experimentsMu sync.RWMutex
+	experiments   = map[string]Experiment{}
+)
+
+// RegisterExperiment adds or replaces an experiment in the process-wide registry
+func RegisterExperiment(experiment Experiment) error {
+	if strings.TrimSpace(experiment.Name) == "" {
+		return fmt.Errorf("experiment name is required")
+	}
+	total := 0
+	seen := make(map[string]bool, len(experiment.Variants))
+	for _, variant := range experiment.Variants {
+		if strings.TrimSpace(variant.Name) == "" {
+			return fmt.Errorf("experiment %s: variant name is required", experiment.Name)
+		}
+		if seen[variant.Name] {
+			return fmt.Errorf("experiment %s: duplicate variant %s", experiment.Name, variant.Name)
+		}
+		seen[variant.Name] = true
+		if variant.Weight < 0 {
+			return fmt.Errorf("experiment %s: variant %s has a negative weight", experiment.Name, variant.Name)
+		}
+		total += variant.Weight
+	}
+	if total == 0 {
+		return fmt.Errorf("experiment %s: at least one variant needs a positive weight", experiment.Name)
+	}
+	experimentsMu.Lock()
+	defer experimentsMu.Unlock()
+	experiments[experiment.Name] = experiment
+	return nil
+}
+
+// GetExperiment returns the registered experiment with the given name
+func GetExperiment(name string) (Experiment, bool) {
+	experimentsMu.RLock()
+	defer experimentsMu.RUnlock()
+	experiment, ok := experiments[name]
+	return experiment, ok
+}
+
+// ListExperiments returns the names of all registered experiments, sorted
+func ListExperiments() []string {
+	experimentsMu.RLock()
+	defer experimentsMu.RUnlock()
+	names := make([]string, 0, len(experiments))
+	for name := range experiments {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// AssignExperimentVariant returns the variant sessionID is assigned in
+// experiment. The assignment depends only on the experiment name, the
+// session ID and the variant weights.
+func AssignExperimentVariant(experiment Experiment, sessionID string) ExperimentVariant {
+	total := 0
+	for _, variant := range experiment.Variants {
+		total += variant.Weight
+	}
+	if total <= 0 {
+		return ExperimentVariant{}
+	}
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(experiment.Name + "\x00" + sessionID))
+	bucket := int(h.Sum64() % uint64(total))
+	for _, variant := range experiment.Variants {
+		if bucket < variant.Weight {
+			return variant
+		}
+		bucket -= variant.Weight
+	}
+	return ExperimentVariant{}
+}
+
+// WithExperiment enrolls the agent in the registered experiment name. The
+// variant is assigned from the session ID (see WithSessionID) once all other
+// options are applied; its system prompt and options then override them.
+// NewAgent fails if the experiment is not registered.
+//
+// Default: "" (no experiment)
+func WithExperiment(name string) AgentOption {
+	return func(a *Agent) {
+		a.experimentName = name
+	}
+}
+
+// ExperimentVariant returns the experiment the agent is enrolled in and its
+// assigned variant, or empty strings
+func (a *Agent) ExperimentVariant() (experiment, variant string) {
+	return a.experimentName, a.experimentVariant
+}
+
+// applyExperiment assigns the agent's variant and applies it
+func (a *Agent) applyExperiment() error {
+	if a.experimentName == "" {
+		return nil
+	}
+	experiment, ok := GetExperiment(a.experimentName)
+	if !ok {
+		return fmt.Errorf("experiment not found: %s", a.experimentName)
+	}
+	variant := AssignExperimentVariant(experiment, a.SessionID)
+	a.experimentVariant = variant.Name
+	if variant.SystemPrompt != "" {
+		WithSystemPrompt(variant.SystemPrompt)(a)
+	}
+	for _, option := range variant.Options {
+		option(a)
+	}
+	return nil
+}
+
+// tagExperiment records the agent's experiment assignment in event metadata
+func (a *Agent) tagExperiment(metadata map[string]interface{}) map[string]interface{} {
+	if a.experimentVariant == "" {
+		return metadata
+	}
+	if metadata == nil {
+		metadata = make(map[string]interface{}, 2)
+	}
+	metadata[ExperimentMetadataKey] = a.experimentName
+	metadata[ExperimentVariantMetadataKey] = a.experimentVariant
+	return metadata
+}
+
+// VariantStats compares one variant of an experiment
+type VariantStats struct {
+	Experiment string `json:"experiment"`
+	Variant    string `json:"variant"`
+
+	Conversations int `json:"conversations"` // Started
+	Completed     int `json:"completed"`
+	Failed        int `json:"failed"`
+
+	LLMCalls     int     `json:"llm_calls"`
+	TotalTokens  int     `json:"total_tokens"`
+	TotalCostUSD float64 `json:"total_cost_usd"`
+	// AvgCostUSD is the cost per started conversation
+	AvgCostUSD float64 `json:"avg_cost_usd"`
+	// AvgLatency is the mean duration of completed conversations
+	AvgLatency time.Duration `json:"avg_latency"`
+
+	// Validation covers answer contract checks and JSON schema validations
+	ValidationChecks   int     `json:"validation_checks"`
+	ValidationPassed   int     `json:"validation_passed"`
+	ValidationPassRate float64 `json:"validation_pass_rate"` // 0 when there were no checks
+
+	totalLatency time.Duration
+}
+
+// ExperimentAggregator accumulates VariantStats from the events of agents
+// enrolled in experiments. It is an AgentEventListener, so it can be added to
+// every agent of a process, and events recorded elsewhere (e.g. a stream
+// replay or an event log) can be replayed into HandleEvent.
+type ExperimentAggregator struct {
+	mu    sync.Mutex
+	stats map[[2]string]*VariantStats
+}
+
+// NewExperimentAggregator creates an empty aggregator
+func NewExperimentAggregator() *ExperimentAggregator {
+	return &ExperimentAggregator{stats: make(map[[2]string]*VariantStats)}
+}
+
+// Name implements AgentEventListener
+func (g *ExperimentAggregator) Name() string {
+	return "experiment-aggregator"
+}
+
+// HandleEvent implements AgentEventListener. Events without an experiment
+// assignment are ignored.
+func (g *ExperimentAggregator) HandleEvent(_ context.Context, event *events.AgentEvent) error {
+	if event == nil {
+		return nil
+	}
+	experiment, variant := experimentTags(event.Data)
+	if variant == "" {
+		return nil
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	key := [2]string{experiment, variant}
+	stats, ok := g.stats[key]
+	if !ok {
+		stats = &VariantStats{Experiment: experiment, Variant: variant}
+		g.stats[key] = stats
+	}
+
+	switch data := event.Data.(type) {
+	case *events.ConversationStartEvent:
+		stats.Conversations++
+	case *events.UnifiedCompletionEvent:
+		stats.Completed++
+		stats.totalLatency += data.Duration
+	case *events.ConversationErrorEvent:
+		stats.Failed++
+	case *events.LLMGenerationEndEvent:
+		stats.LLMCalls++
+		stats.TotalTokens += data.UsageMetrics.TotalTokens
+		if cost, ok := data.Metadata["call_cost_usd"].(float64); ok {
+			stats.TotalCostUSD += cost
+		}
+	case *events.AnswerContractCheckEvent:
+		stats.ValidationChecks++
+		if data.Satisfied {
+			stats.ValidationPassed++
+		}
+	case *events.JSONValidationEndEvent:
+		stats.ValidationChecks++
+		if data.Valid {
+			stats.ValidationPassed++
+		}
+	}
+	return nil
+}
+
+// Results returns the stats of every variant of experiment, sorted by
+// variant name
+func (g *ExperimentAggregator) Results(experiment string) []VariantStats {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	var results []VariantStats
+	for key, stats := range g.stats {
+		if key[0] != experiment {
+			continue
+		}
+		result := *stats
+		if result.Conversations > 0 {
+			result.AvgCostUSD = result.TotalCostUSD / float64(result.Conversations)
+		}
+		if result.Completed > 0 {
+			result.AvgLatency = result.totalLatency / time.Duration(result.Completed)
+		}
+		if result.ValidationChecks > 0 {
+			result.ValidationPassRate = float64(result.ValidationPassed) / float64(result.ValidationChecks)
+		}
+		results = append(results, result)
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].Variant < results[j].Variant })
+	return results
+}
+
+// AggregateExperiment compares the variants of experiment over recorded events
+func AggregateExperiment(experiment string, recorded []*events.AgentEvent) []VariantStats {
+	aggregator := NewExperimentAggregator()
+	for _, event := range recorded {
+		_ = aggregator.HandleEvent(context.Background(), event)
+	}
+	return aggregator.Results(experiment)
+}
+
+// experimentTags reads the experiment assignment from event metadata
+func experimentTags(data events.EventData) (experiment, variant string) {
+	var metadata map[string]interface{}
+	if completion, ok := data.(*events.UnifiedCompletionEvent); ok {
+		metadata = completion.Metadata
+	} else if base, ok := data.(interface{ GetBaseEventData() *events.BaseEventData }); ok {
+		metadata = base.GetBaseEventData().Metadata
+	}
+	experiment, _ = metadata[ExperimentMetadataKey].(string)
+	variant, _ = metadata[ExperimentVariantMetadataKey].(string)
+	return experiment, variant
+}
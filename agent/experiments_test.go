@@ -0,0 +1,102 @@
+package mcpagent
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/manishiitg/mcpagent/events"
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+)
+
+func TestRegisterExperimentValidates(t *testing.T) {
+	for _, experiment := range []Experiment{
+		{Name: ""},
+		{Name: "no-weight", Variants: []ExperimentVariant{{Name: "a"}}},
+		{Name: "duplicate", Variants: []ExperimentVariant{{Name: "a", Weight: 1}, {Name: "a", Weight: 1}}},
+		{Name: "negative", Variants: []ExperimentVariant{{Name: "a", Weight: 2}, {Name: "b", Weight: -1}}},
+	} {
+		if err := RegisterExperiment(experiment); err == nil {
+			t.Errorf("RegisterExperiment(%+v) should fail", experiment)
+		}
+	}
+}
+
+func TestAssignExperimentVariantIsDeterministicAndWeighted(t *testing.T) {
+	experiment := Experiment{Name: "weights", Variants: []ExperimentVariant{
+		{Name: "control", Weight: 3},
+		{Name: "paused", Weight: 0},
+		{Name: "treatment", Weight: 1},
+	}}
+	counts := map[string]int{}
+	for i := 0; i < 4000; i++ {
+		session := fmt.Sprintf("session-%d", i)
+		variant := AssignExperimentVariant(experiment, session)
+		if again := AssignExperimentVariant(experiment, session); again.Name != variant.Name {
+			t.Fatalf("%s assigned %s then %s", session, variant.Name, again.Name)
+		}
+		counts[variant.Name]++
+	}
+	if counts["paused"] != 0 {
+		t.Errorf("zero-weight variant got %d sessions", counts["paused"])
+	}
+	if share := float64(counts["treatment"]) / 4000; share < 0.2 || share > 0.3 {
+		t.Errorf("treatment share = %.2f, want about 0.25 (counts %v)", share, counts)
+	}
+}
+
+func TestExperimentTagsEventsAndAggregates(t *testing.T) {
+	if err := RegisterExperiment(Experiment{Name: "tagging", Variants: []ExperimentVariant{
+		{Name: "short", Weight: 1, SystemPrompt: "Answer in one line.", Options: []AgentOption{WithMaxTurns(3)}},
+	}}); err != nil {
+		t.Fatal(err)
+	}
+	a := &Agent{Logger: loggerv2.NewNoop(), SessionID: "session-1"}
+	WithExperiment("tagging")(a)
+	if err := a.applyExperiment(); err != nil {
+		t.Fatal(err)
+	}
+	if experiment, variant := a.ExperimentVariant(); experiment != "tagging" || variant != "short" {
+		t.Fatalf("ExperimentVariant() = %s, %s", experiment, variant)
+	}
+	if a.systemPrompt != "Answer in one line." || a.MaxTurns != 3 {
+		t.Errorf("variant not applied: prompt %q, max turns %d", a.systemPrompt, a.MaxTurns)
+	}
+
+	aggregator := NewExperimentAggregator()
+	a.AddEventListener(aggregator)
+	ctx := context.Background()
+	a.EmitTypedEvent(ctx, events.NewConversationStartEvent("q", "", 0, ""))
+	end := events.NewLLMGenerationEndEvent(1, "a", 0, time.Second, events.UsageMetrics{TotalTokens: 50})
+	end.Metadata = map[string]interface{}{"call_cost_usd": 0.02}
+	a.EmitTypedEvent(ctx, end)
+	a.EmitTypedEvent(ctx, &events.AnswerContractCheckEvent{Satisfied: true})
+	a.EmitTypedEvent(ctx, &events.JSONValidationEndEvent{Valid: false})
+	completion := events.NewUnifiedCompletionEvent("simple", "simple", "q", "a", "completed", 2*time.Second, 1)
+	a.annotateUnifiedCompletionEvent(completion)
+	a.EmitTypedEvent(ctx, completion)
+
+	results := aggregator.Results("tagging")
+	if len(results) != 1 {
+		t.Fatalf("results = %+v", results)
+	}
+	got := results[0]
+	if got.Conversations != 1 || got.Completed != 1 || got.TotalTokens != 50 || got.AvgCostUSD != 0.02 ||
+		got.AvgLatency != 2*time.Second || got.ValidationChecks != 2 || got.ValidationPassRate != 0.5 {
+		t.Errorf("stats = %+v", got)
+	}
+
+	untagged := events.NewAgentEvent(events.NewConversationStartEvent("q", "", 0, ""))
+	if stats := AggregateExperiment("tagging", []*events.AgentEvent{untagged}); len(stats) != 0 {
+		t.Errorf("untagged events should be ignored: %+v", stats)
+	}
+}
+
+func TestApplyExperimentUnknown(t *testing.T) {
+	a := &Agent{SessionID: "s"}
+	WithExperiment("not-registered")(a)
+	if err := a.applyExperiment(); err == nil {
+		t.Error("expected an error for an unregistered experiment")
+	}
+}
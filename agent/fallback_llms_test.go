@@ -0,0 +1,170 @@
+package mcpagent
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/manishiitg/mcpagent/events"
+	"github.com/manishiitg/mcpagent/llm"
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+// fallbackTestModel answers with answer, or fails with err
+type fallbackTestModel struct {
+	id       string
+	provider llm.Provider
+	answer   string
+	err      error
+	calls    int
+	options  llmtypes.CallOptions // Of the last call
+}
+
+func (m *fallbackTestModel) GenerateContent(ctx context.Context, messages []llmtypes.MessageContent, options ...llmtypes.CallOption) (*llmtypes.ContentResponse, error) {
+	m.calls++
+	m.options = llmtypes.CallOptions{}
+	for _, opt := range options {
+		opt(&m.options)
+	}
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &llmtypes.ContentResponse{Choices: []*llmtypes.ContentChoice{{Content: m.answer}}}, nil
+}
+
+func (m *fallbackTestModel) GetModelID() string { return m.id }
+
+func (m *fallbackTestModel) GetModelMetadata(modelID string) (*llmtypes.ModelMetadata, error) {
+	return nil, nil
+}
+
+func (m *fallbackTestModel) GetProvider() llm.Provider { return m.provider }
+
+func fallbackDetailOperations(listener *recordingAgentEventListener) ([]string, []*events.FallbackDetailEvent) {
+	var operations []string
+	var details []*events.FallbackDetailEvent
+	for _, event := range listener.events {
+		if detail, ok := event.Data.(*events.FallbackDetailEvent); ok {
+			operations = append(operations, detail.Operation)
+			details = append(details, detail)
+		}
+	}
+	return operations, details
+}
+
+func TestWithFallbackLLMsTriesModelsInOrder(t *testing.T) {
+	overflow := errors.New("prompt is too long: 210000 tokens > 200000 maximum")
+	primary := &fallbackTestModel{id: "primary", provider: llm.ProviderOpenAI, err: overflow}
+	second := &fallbackTestModel{id: "second", provider: llm.ProviderOpenAI, err: overflow}
+	third := &fallbackTestModel{id: "third", provider: llm.ProviderAnthropic, answer: "done"}
+
+	listener := &recordingAgentEventListener{}
+	a := &Agent{
+		Logger:    loggerv2.NewNoop(),
+		listeners: []AgentEventListener{listener},
+		provider:  llm.ProviderOpenAI,
+		ModelID:   "primary",
+		LLMConfig: AgentLLMConfiguration{Primary: LLMModel{Provider: "openai", ModelID: "primary", Model: primary}},
+	}
+	WithFallbackLLMs(second, third)(a)
+
+	if fallbacks := a.getEffectiveLLMConfig().Fallbacks; len(fallbacks) != 2 || fallbacks[0].Model != second || fallbacks[1].Provider != "anthropic" {
+		t.Fatalf("fallback instances should replace the provider defaults, got %+v", fallbacks)
+	}
+
+	messages := []llmtypes.MessageContent{llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "summarize the thread")}
+	resp, _, err := GenerateContentWithRetry(a, context.Background(), messages, nil, 1)
+	if err != nil {
+		t.Fatalf("GenerateContentWithRetry: %v", err)
+	}
+	if resp.Choices[0].Content != "done" || primary.calls != 1 || second.calls != 1 || third.calls != 1 {
+		t.Fatalf("answer = %q, calls = %d/%d/%d", resp.Choices[0].Content, primary.calls, second.calls, third.calls)
+	}
+	if a.ModelID != "third" || a.provider != llm.ProviderAnthropic {
+		t.Errorf("agent should continue on the model that answered, got %s/%s", a.provider, a.ModelID)
+	}
+
+	operations, details := fallbackDetailOperations(listener)
+	want := []string{"fallback_attempt", "fallback_failure", "fallback_attempt", "fallback_success"}
+	if len(operations) != len(want) {
+		t.Fatalf("fallback detail events = %v, want %v", operations, want)
+	}
+	for i := range want {
+		if operations[i] != want[i] {
+			t.Fatalf("fallback detail events = %v, want %v", operations, want)
+		}
+	}
+	if details[0].FallbackPhase != "same_provider" || details[0].ErrorType != "max_token_error" || details[0].TotalFallbacks != 2 {
+		t.Errorf("unexpected first attempt: %+v", details[0])
+	}
+	if details[3].SuccessfulLLM != "third" || details[3].SuccessfulPhase != "cross_provider" || details[3].Attempts != 3 {
+		t.Errorf("unexpected success: %+v", details[3])
+	}
+
+	a.emitConversationEnd(context.Background(), messages, append(messages, llmtypes.TextParts(llmtypes.ChatMessageTypeAI, "done")),
+		time.Now(), "done", ConversationCompletedStatus, "")
+	end, ok := listener.events[len(listener.events)-1].Data.(*events.ConversationEndEvent)
+	if !ok || end.ModelID != "third" || end.Provider != "anthropic" || end.Turns != 1 || end.Status != ConversationCompletedStatus {
+		t.Errorf("ConversationEnd should record the model that answered, got %+v", listener.events[len(listener.events)-1].Data)
+	}
+}
+
+func TestWithFallbackLLMsReportsAllFailed(t *testing.T) {
+	overflow := errors.New("prompt is too long")
+	listener := &recordingAgentEventListener{}
+	a := &Agent{
+		Logger:    loggerv2.NewNoop(),
+		listeners: []AgentEventListener{listener},
+		LLMConfig: AgentLLMConfiguration{Primary: LLMModel{Provider: "openai", ModelID: "primary",
+			Model: &fallbackTestModel{id: "primary", err: overflow}}},
+	}
+	WithFallbackLLMs(&fallbackTestModel{id: "custom", err: overflow})(a)
+
+	messages := []llmtypes.MessageContent{llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "hi")}
+	if _, _, err := GenerateContentWithRetry(a, context.Background(), messages, nil, 1); !errors.Is(err, overflow) {
+		t.Fatalf("expected the last error, got %v", err)
+	}
+	operations, details := fallbackDetailOperations(listener)
+	if len(operations) != 3 || operations[2] != "all_failed" {
+		t.Fatalf("fallback detail events = %v", operations)
+	}
+	if failed := details[2].FailedModels; len(failed) != 2 || failed[0] != "openai/primary" || failed[1] != "/custom" {
+		t.Errorf("FailedModels = %v", failed)
+	}
+	if details[2].CrossProviderAttempts != 1 {
+		t.Errorf("a fallback without a provider is a cross-provider fallback, got %+v", details[2])
+	}
+}
+
+func TestFallbackModelsGetTheirOwnReasoningOptions(t *testing.T) {
+	overflow := errors.New("prompt is too long: 210000 tokens > 200000 maximum")
+	primary := &fallbackTestModel{id: "gpt-5", provider: llm.ProviderOpenAI, err: overflow}
+	legacy := &fallbackTestModel{id: "claude-3-5-sonnet-20241022", provider: llm.ProviderAnthropic, err: overflow}
+	claude := &fallbackTestModel{id: "claude-sonnet-4-5", provider: llm.ProviderAnthropic, answer: "done"}
+
+	a := &Agent{
+		Logger:          loggerv2.NewNoop(),
+		provider:        llm.ProviderOpenAI,
+		ModelID:         "gpt-5",
+		ReasoningEffort: ReasoningEffortHigh,
+		MaxOutputTokens: 32000,
+		LLMConfig:       AgentLLMConfiguration{Primary: LLMModel{Provider: "openai", ModelID: "gpt-5", Model: primary}},
+	}
+	WithFallbackLLMs(legacy, claude)(a)
+
+	messages := []llmtypes.MessageContent{llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "plan the migration")}
+	if _, _, err := GenerateContentWithRetry(a, context.Background(), messages, a.outputControlOptions(messages, MaxTokensHintFinalSynthesis), 1); err != nil {
+		t.Fatalf("GenerateContentWithRetry: %v", err)
+	}
+	if got := primary.options; got.ReasoningEffort != "high" || got.ThinkingBudget != 0 {
+		t.Errorf("primary options = effort %q, budget %d", got.ReasoningEffort, got.ThinkingBudget)
+	}
+	if got := legacy.options; got.ReasoningEffort != "" || got.ThinkingBudget != 0 {
+		t.Errorf("model without extended thinking got effort %q, budget %d", got.ReasoningEffort, got.ThinkingBudget)
+	}
+	if got := claude.options; got.ReasoningEffort != "" || got.ThinkingBudget != 16384 || got.MaxTokens != 32000 {
+		t.Errorf("claude options = effort %q, budget %d, max tokens %d", got.ReasoningEffort, got.ThinkingBudget, got.MaxTokens)
+	}
+}
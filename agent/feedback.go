@@ -0,0 +1,70 @@
+// feedback.go
+//
+// This file attaches user feedback to the agent's trace in tracers that
+// support scores (observability.Scorer, e.g. Langfuse), so apps can record
+// thumbs-up/down on a completed answer without a separate tracing SDK.
+// Evaluation scores with other names go through the tracer's Score method
+// with the agent's TraceID.
+//
+// Exported:
+//   - FeedbackScoreName: Name of the score RecordFeedback records
+//   - RecordFeedback: Attach a user feedback score to the agent's trace
+
+package mcpagent
+
+import (
+	"errors"
+	"fmt"
+
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+	"github.com/manishiitg/mcpagent/observability"
+)
+
+// FeedbackScoreName is the name of the score RecordFeedback records
+const FeedbackScoreName = "user_feedback"
+
+// RecordFeedback attaches a user feedback score (e.g. 1 for thumbs-up, 0 for
+// thumbs-down) with an optional comment to the agent's trace, in every tracer
+// that supports scores. Call it after the conversation ended; the trace does
+// not need to be open. Returns an error when no tracer supports scores or
+// one of them fails to record it.
+//
+// Example usage:
+//
+//	answer, err := agent.Ask(ctx, question)
+//	// ... later, when the user clicks thumbs-down:
+//	_ = agent.RecordFeedback(0, "wrong repository")
+func (a *Agent) RecordFeedback(score float64, comment string) error {
+	if a.TraceID == "" {
+		return errors.New("record feedback: agent has no trace ID")
+	}
+	recorded := 0
+	var errs []error
+	for _, tracer := range a.Tracers {
+		if st, ok := tracer.(*streamingTracerImpl); ok {
+			tracer = st.baseTracer
+		}
+		scorer, ok := tracer.(observability.Scorer)
+		if !ok {
+			continue
+		}
+		if err := scorer.Score(a.TraceID, FeedbackScoreName, score, comment); err != nil {
+			if !errors.Is(err, observability.ErrScoresNotSupported) {
+				errs = append(errs, err)
+			}
+			continue
+		}
+		recorded++
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("record feedback: %w", errors.Join(errs...))
+	}
+	if recorded == 0 {
+		return fmt.Errorf("record feedback: %w", observability.ErrScoresNotSupported)
+	}
+	getLogger(a).Info("👍 [FEEDBACK] User feedback recorded",
+		loggerv2.String("trace_id", string(a.TraceID)),
+		loggerv2.Any("score", score),
+		loggerv2.Int("tracers", recorded))
+	return nil
+}
@@ -0,0 +1,40 @@
+package mcpagent
+
+import (
+	"errors"
+	"testing"
+
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+	"github.com/manishiitg/mcpagent/observability"
+)
+
+type scoringTracer struct {
+	observability.NoopTracer
+	scores []string
+}
+
+func (s *scoringTracer) Score(traceID observability.TraceID, name string, value float64, comment string) error {
+	s.scores = append(s.scores, string(traceID)+" "+name+" "+comment)
+	return nil
+}
+
+func TestRecordFeedbackScoresTheAgentTrace(t *testing.T) {
+	scorer := &scoringTracer{}
+	a := &Agent{Logger: loggerv2.NewNoop(), TraceID: "trace-7"}
+	WithTracer(scorer)(a)
+	// A sampled tracer without score support is skipped
+	WithSampledTracer(observability.NoopTracer{}, observability.SamplingConfig{Rate: 1})(a)
+
+	if err := a.RecordFeedback(0, "wrong repository"); err != nil {
+		t.Fatal(err)
+	}
+	if len(scorer.scores) != 1 || scorer.scores[0] != "trace-7 user_feedback wrong repository" {
+		t.Errorf("scores = %v", scorer.scores)
+	}
+
+	plain := &Agent{Logger: loggerv2.NewNoop(), TraceID: "trace-8"}
+	WithTracer(observability.NoopTracer{})(plain)
+	if err := plain.RecordFeedback(1, ""); !errors.Is(err, observability.ErrScoresNotSupported) {
+		t.Errorf("feedback without a scoring tracer: %v", err)
+	}
+}
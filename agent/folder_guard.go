@@ -0,0 +1,220 @@
+// folder_guard.go
+//
+// This file enforces the folder guard paths (SetFolderGuardPaths) on
+// filesystem MCP tool calls in normal mode. Code execution mode validates
+// workspace reads and writes at AST level; here, calls to the tools of
+// filesystem servers (servers run from server-filesystem or configured with
+// "filesystem": true) are checked before they run, and a call touching a
+// path outside the allowed folders is rejected with an error result the LLM
+// can act on, plus a folder_guard_blocked event. Tools of other servers are
+// not checked, whatever their names.
+//
+// Reads are allowed inside the read and write paths, writes only inside the
+// write paths. Paths are resolved like the filesystem server resolves them
+// (~ expanded, relative to the server's working directory) and through
+// symlinks, so a link inside an allowed folder cannot reach outside it. A
+// call the guard cannot check (an unknown tool, a missing path argument, a
+// relative path on a remote server) is rejected. The guard is off while no
+// folder guard path is set.
+
+package mcpagent
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/manishiitg/mcpagent/events"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// folderGuardAccess is the access a filesystem tool needs on its path arguments
+type folderGuardAccess string
+
+const (
+	folderGuardRead  folderGuardAccess = "read"
+	folderGuardWrite folderGuardAccess = "write"
+)
+
+// folderGuardTool is the access a filesystem tool needs and the arguments it
+// takes paths in; "paths" holds a list
+type folderGuardTool struct {
+	access    folderGuardAccess
+	pathNames []string
+}
+
+// folderGuardTools maps the filesystem MCP server's tools to the access they
+// need. Calls to other tools of a filesystem server are rejected.
+var folderGuardTools = map[string]folderGuardTool{
+	"read_file":                 {folderGuardRead, []string{"path"}},
+	"read_text_file":            {folderGuardRead, []string{"path"}},
+	"read_media_file":           {folderGuardRead, []string{"path"}},
+	"read_multiple_files":       {folderGuardRead, []string{"paths"}},
+	"list_directory":            {folderGuardRead, []string{"path"}},
+	"list_directory_with_sizes": {folderGuardRead, []string{"path"}},
+	"directory_tree":            {folderGuardRead, []string{"path"}},
+	"search_files":              {folderGuardRead, []string{"path"}},
+	"get_file_info":             {folderGuardRead, []string{"path"}},
+	"list_allowed_directories":  {folderGuardRead, nil},
+	"write_file":                {folderGuardWrite, []string{"path"}},
+	"edit_file":                 {folderGuardWrite, []string{"path"}},
+	"create_directory":          {folderGuardWrite, []string{"path"}},
+	"move_file":                 {folderGuardWrite, []string{"source", "destination"}},
+}
+
+// folderGuardedExec wraps execute, the innermost step of the tool middleware
+// chain, so the guard sees the arguments as middleware left them
+func (a *Agent) folderGuardedExec(execute ToolExecFunc) ToolExecFunc {
+	return func(ctx context.Context, call *ToolInvocation) (*mcp.CallToolResult, error) {
+		if rejected := a.enforceFolderGuard(ctx, call); rejected != nil {
+			return rejected, nil
+		}
+		return execute(ctx, call)
+	}
+}
+
+// enforceFolderGuard returns an error result when call is a filesystem tool
+// call accessing a path outside the folder guard paths, or one the guard
+// cannot check; nil otherwise
+func (a *Agent) enforceFolderGuard(ctx context.Context, call *ToolInvocation) *mcp.CallToolResult {
+	if call.Type != "MCP" {
+		return nil
+	}
+	config, ok := a.serverConfigs[call.ServerName]
+	if !ok || !config.IsFilesystemServer() {
+		return nil
+	}
+	readPaths, writePaths := a.GetFolderGuardPaths()
+	if len(readPaths) == 0 && len(writePaths) == 0 {
+		return nil
+	}
+
+	tool, known := folderGuardTools[actualMCPToolName(call.Name, call.ServerName)]
+	if !known {
+		a.EmitTypedEvent(ctx, events.NewFolderGuardBlockedEvent(call.Turn, call.Name, call.ServerName, call.ID, "", ""))
+		return mcp.NewToolResultError(fmt.Sprintf("Access denied: %s was not run because the folder guard cannot tell which paths it accesses.", call.Name))
+	}
+	paths, err := folderGuardPaths(tool.pathNames, call.Arguments)
+	if err != nil {
+		a.EmitTypedEvent(ctx, events.NewFolderGuardBlockedEvent(call.Turn, call.Name, call.ServerName, call.ID, "", string(tool.access)))
+		return mcp.NewToolResultError(fmt.Sprintf("Access denied: %s was not run because %v.", call.Name, err))
+	}
+
+	allowed := writePaths
+	if tool.access == folderGuardRead {
+		allowed = append(append([]string{}, readPaths...), writePaths...)
+	}
+	roots := make([]string, 0, len(allowed))
+	for _, path := range allowed {
+		if root, err := resolveGuardedPath(path, ""); err == nil {
+			roots = append(roots, root)
+		}
+	}
+	// Relative paths resolve against the server's working directory, which
+	// is unknown for remote servers
+	baseDir := config.WorkingDir
+	remote := config.URL != ""
+
+	for _, path := range paths {
+		if remote && !filepath.IsAbs(path) && path != "~" && !strings.HasPrefix(path, "~/") {
+			a.EmitTypedEvent(ctx, events.NewFolderGuardBlockedEvent(call.Turn, call.Name, call.ServerName, call.ID, path, string(tool.access)))
+			return mcp.NewToolResultError(fmt.Sprintf("Access denied: %s was not run because %q is relative; use an absolute path inside the folders allowed for %s access: %s.",
+				call.Name, path, tool.access, strings.Join(allowed, ", ")))
+		}
+		resolved, err := resolveGuardedPath(path, baseDir)
+		if err == nil && withinFolders(resolved, roots) {
+			continue
+		}
+		a.EmitTypedEvent(ctx, events.NewFolderGuardBlockedEvent(call.Turn, call.Name, call.ServerName, call.ID, path, string(tool.access)))
+		if len(allowed) == 0 {
+			return mcp.NewToolResultError(fmt.Sprintf("Access denied: %s was not run because %s access to %q is not allowed; no folder is writable in this session.",
+				call.Name, tool.access, path))
+		}
+		return mcp.NewToolResultError(fmt.Sprintf("Access denied: %s was not run because %q is outside the folders allowed for %s access: %s. Use a path inside one of them.",
+			call.Name, path, tool.access, strings.Join(allowed, ", ")))
+	}
+	return nil
+}
+
+// folderGuardPaths returns the values of the path arguments names of a
+// filesystem tool call. Each must be a path, or a list of paths for "paths".
+func folderGuardPaths(names []string, args map[string]interface{}) ([]string, error) {
+	var paths []string
+	for _, name := range names {
+		switch value := args[name].(type) {
+		case string:
+			if name == "paths" {
+				return nil, fmt.Errorf("argument %q must be a list of paths", name)
+			}
+			paths = append(paths, value)
+		case []interface{}:
+			if name != "paths" {
+				return nil, fmt.Errorf("argument %q must be a path", name)
+			}
+			for _, item := range value {
+				path, ok := item.(string)
+				if !ok {
+					return nil, fmt.Errorf("argument %q must be a list of paths", name)
+				}
+				paths = append(paths, path)
+			}
+		case []string:
+			if name != "paths" {
+				return nil, fmt.Errorf("argument %q must be a path", name)
+			}
+			paths = append(paths, value...)
+		case nil:
+			return nil, fmt.Errorf("the path argument %q is missing", name)
+		default:
+			return nil, fmt.Errorf("argument %q must be a path", name)
+		}
+	}
+	return paths, nil
+}
+
+// resolveGuardedPath returns the absolute, symlink-free form of path, taken
+// relative to baseDir ("" = the working directory). For a path that does not
+// exist yet (e.g. a file to write), its deepest existing ancestor is resolved
+// and the rest appended.
+func resolveGuardedPath(path, baseDir string) (string, error) {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		path = filepath.Join(home, path[1:])
+	}
+	if baseDir != "" && !filepath.IsAbs(path) {
+		path = filepath.Join(baseDir, path)
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	var missing []string
+	for dir := abs; ; dir = filepath.Dir(dir) {
+		if resolved, err := filepath.EvalSymlinks(dir); err == nil {
+			for i := len(missing) - 1; i >= 0; i-- {
+				resolved = filepath.Join(resolved, missing[i])
+			}
+			return resolved, nil
+		}
+		if parent := filepath.Dir(dir); parent == dir {
+			return abs, nil
+		}
+		missing = append(missing, filepath.Base(dir))
+	}
+}
+
+// withinFolders reports whether path is one of roots or inside one
+func withinFolders(path string, roots []string) bool {
+	for _, root := range roots {
+		rel, err := filepath.Rel(root, path)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) && !filepath.IsAbs(rel) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,143 @@
+package mcpagent
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/manishiitg/mcpagent/events"
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+	"github.com/manishiitg/mcpagent/mcpclient"
+)
+
+func TestFolderGuardRejectsFilesystemCallsOutsideAllowedPaths(t *testing.T) {
+	root := t.TempDir()
+	readDir, writeDir, outside := filepath.Join(root, "docs"), filepath.Join(root, "out"), filepath.Join(root, "secret")
+	for _, dir := range []string{readDir, writeDir, outside} {
+		if err := os.Mkdir(dir, 0700); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// A link inside an allowed folder must not reach outside it
+	if err := os.Symlink(outside, filepath.Join(writeDir, "escape")); err != nil {
+		t.Fatal(err)
+	}
+
+	listener := &recordingAgentEventListener{}
+	a := &Agent{Logger: loggerv2.NewNoop(), listeners: []AgentEventListener{listener}, serverConfigs: map[string]mcpclient.MCPServerConfig{
+		"filesystem": {Command: "npx", Args: []string{"-y", "@modelcontextprotocol/server-filesystem", root}, WorkingDir: writeDir},
+	}}
+	a.SetFolderGuardPaths([]string{readDir}, []string{writeDir})
+
+	ran := 0
+	execute := func(context.Context, *ToolInvocation) (*mcp.CallToolResult, error) {
+		ran++
+		return mcp.NewToolResultText("ok"), nil
+	}
+	call := func(name string, args map[string]interface{}) *mcp.CallToolResult {
+		result, err := a.executeWithToolMiddleware(context.Background(),
+			&ToolInvocation{ID: "call_1", Name: name, ServerName: "filesystem", Type: "MCP", Turn: 1, Arguments: args}, execute)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result
+	}
+
+	allowed := []struct {
+		name string
+		args map[string]interface{}
+	}{
+		{"read_file", map[string]interface{}{"path": filepath.Join(readDir, "a.md")}},
+		{"read_file", map[string]interface{}{"path": filepath.Join(writeDir, "report.md")}},
+		{"write_file", map[string]interface{}{"path": filepath.Join(writeDir, "new", "report.md"), "content": "x"}},
+		{"read_multiple_files", map[string]interface{}{"paths": []interface{}{filepath.Join(readDir, "a.md"), readDir}}},
+		{"list_allowed_directories", map[string]interface{}{}},
+		// Relative to the server's working directory
+		{"write_file", map[string]interface{}{"path": "report.md", "content": "x"}},
+	}
+	for _, c := range allowed {
+		if result := call(c.name, c.args); result.IsError {
+			t.Errorf("%s %v rejected: %+v", c.name, c.args, result.Content)
+		}
+	}
+
+	blocked := []struct {
+		name string
+		args map[string]interface{}
+	}{
+		{"write_file", map[string]interface{}{"path": filepath.Join(readDir, "a.md"), "content": "x"}},
+		{"read_file", map[string]interface{}{"path": filepath.Join(outside, "key")}},
+		{"read_file", map[string]interface{}{"path": filepath.Join(writeDir, "escape", "key")}},
+		{"read_file", map[string]interface{}{"path": filepath.Join(writeDir, "..", "secret", "key")}},
+		{"move_file", map[string]interface{}{"source": filepath.Join(writeDir, "a"), "destination": filepath.Join(outside, "a")}},
+		{"read_multiple_files", map[string]interface{}{"paths": []interface{}{filepath.Join(readDir, "a.md"), "/etc/passwd"}}},
+		{"write_file", map[string]interface{}{"path": "../docs/a.md", "content": "x"}},
+		// Calls the guard cannot check
+		{"read_file", map[string]interface{}{"file_path": filepath.Join(outside, "key")}},
+		{"read_multiple_files", map[string]interface{}{"paths": filepath.Join(outside, "key")}},
+		{"zip_directory", map[string]interface{}{"folder": outside}},
+	}
+	for _, c := range blocked {
+		result := call(c.name, c.args)
+		text := result.Content[0].(mcp.TextContent).Text
+		if !result.IsError || !strings.Contains(text, "Access denied") {
+			t.Errorf("%s %v not rejected: %q", c.name, c.args, text)
+		}
+	}
+	if ran != len(allowed) {
+		t.Errorf("tool ran %d times, want %d", ran, len(allowed))
+	}
+
+	var blockedEvents int
+	for _, e := range listener.events {
+		if _, ok := e.Data.(*events.FolderGuardBlockedEvent); ok {
+			blockedEvents++
+		}
+	}
+	if blockedEvents != len(blocked) {
+		t.Errorf("folder_guard_blocked events = %d, want %d", blockedEvents, len(blocked))
+	}
+}
+
+func TestFolderGuardIgnoresUnguardedCalls(t *testing.T) {
+	a := &Agent{Logger: loggerv2.NewNoop(), serverConfigs: map[string]mcpclient.MCPServerConfig{
+		"filesystem": {Command: "mcp-fs", Filesystem: true},
+		"github":     {Command: "github-mcp-server"},
+	}}
+	outside := map[string]interface{}{"path": "/etc/passwd"}
+	if a.enforceFolderGuard(context.Background(), &ToolInvocation{Name: "read_file", ServerName: "filesystem", Type: "MCP", Arguments: outside}) != nil {
+		t.Error("guard should be off without folder guard paths")
+	}
+	a.SetFolderGuardPaths([]string{t.TempDir()}, nil)
+	if a.enforceFolderGuard(context.Background(), &ToolInvocation{Name: "read_file", ServerName: "filesystem", Type: "MCP", Arguments: outside}) == nil {
+		t.Error("servers configured as filesystem servers are guarded")
+	}
+	if a.enforceFolderGuard(context.Background(), &ToolInvocation{Name: "read_file", Type: "custom", Arguments: outside}) != nil {
+		t.Error("custom tools are not filesystem server tools")
+	}
+	if a.enforceFolderGuard(context.Background(), &ToolInvocation{Name: "read_file", ServerName: "github", Type: "MCP", Arguments: outside}) != nil {
+		t.Error("tools of other servers are not guarded")
+	}
+}
+
+func TestFolderGuardRejectsRelativePathsOnRemoteServers(t *testing.T) {
+	dir := t.TempDir()
+	a := &Agent{Logger: loggerv2.NewNoop(), serverConfigs: map[string]mcpclient.MCPServerConfig{
+		"files": {URL: "https://files.example.com/mcp", Filesystem: true},
+	}}
+	a.SetFolderGuardPaths([]string{dir}, nil)
+	call := func(path string) *mcp.CallToolResult {
+		return a.enforceFolderGuard(context.Background(), &ToolInvocation{Name: "files__read_file", ServerName: "files", Type: "MCP",
+			Arguments: map[string]interface{}{"path": path}})
+	}
+	if call("notes.md") == nil {
+		t.Error("relative path on a remote server not rejected")
+	}
+	if result := call(filepath.Join(dir, "notes.md")); result != nil {
+		t.Errorf("absolute path inside the read path rejected: %+v", result.Content)
+	}
+}
@@ -0,0 +1,270 @@
+// fork.go
+//
+// This file implements conversation forking. Fork clones an agent's
+// conversation state into a new agent so callers can explore different
+// follow-ups in parallel (e.g. two strategies for the same task) and keep
+// the better one. The fork:
+//
+//   - shares the parent's MCP connections: it joins the parent's connection
+//     session, and closing it leaves the connections open
+//   - inherits the parent's configuration as a whole (see inheritConfig):
+//     model and fallbacks, tool filters, permissions, guards, middleware,
+//     sandbox, summarization, limits and pipeline. Recording, autosave,
+//     webhooks, telemetry and other outputs bound to the parent are not
+//     inherited
+//   - starts from the parent's conversation state: the retained history of
+//     the last conversation (RetainedHistory), the tools discovered in tool
+//     search mode, the context summaries of the session, the system prompt,
+//     custom tools, tool allow list and folder guard paths
+//   - reports its events through the parent's tracers and listeners, with
+//     ForkID set on every event so the branches can be told apart
+//   - keeps its own token usage; it may spend what is left of the parent's
+//     budget limit
+//
+// Exported:
+//   - Agent.Fork: Clone the conversation state into a new agent
+//   - Agent.ForkID: ID of a forked agent
+
+package mcpagent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/manishiitg/mcpagent/events"
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+// Fork returns a new agent holding a copy of this agent's conversation state
+// and sharing its MCP connections. It inherits the parent's configuration
+// (model, fallbacks, servers, tool filters, permissions, guards, middleware,
+// sandbox, modes, limits, summarization settings and ask pipeline); options
+// are applied after it, e.g. to try another model or temperature on the
+// fork. The parent's history is only there to copy with
+// WithHistoryRetention. Continue the forked conversation with
+// fork.AskWithHistory(ctx, append(fork.RetainedHistory(), next...)). Close
+// the fork when done with it.
+//
+// Example:
+//
+//	history := agent.RetainedHistory()
+//	fork, err := agent.Fork(ctx, mcpagent.WithTemperature(0.9))
+//	...
+//	go agent.AskWithHistory(ctx, append(history, strategyA))
+//	go fork.AskWithHistory(ctx, append(fork.RetainedHistory(), strategyB))
+func (a *Agent) Fork(ctx context.Context, options ...AgentOption) (*Agent, error) {
+	id := "fork_" + events.GenerateEventID()
+	sessionID := a.SessionID
+	if sessionID == "" {
+		sessionID = "global"
+	}
+
+	inherited := []AgentOption{
+		WithLogger(a.Logger),
+		WithTraceID(a.TraceID),
+		WithSessionID(sessionID),
+		WithUserID(a.UserID),
+		WithRuntimeOverrides(a.RuntimeOverrides),
+		WithDisableCache(a.DisableCache),
+		WithServerName(a.serverName),
+		WithProvider(a.provider),
+		WithSelectedServers(a.selectedServers),
+		WithSelectedTools(a.selectedTools),
+		WithCodeExecutionMode(a.UseCodeExecutionMode),
+		WithToolSearchMode(a.UseToolSearchMode),
+		WithDryRun(a.DryRun),
+		WithPlanTracking(a.EnablePlanTracking),
+		WithMaxOutputTokens(a.MaxOutputTokens),
+		WithReasoningEffort(a.ReasoningEffort),
+		WithMaxTurns(a.MaxTurns),
+		WithTemperature(a.Temperature),
+		WithContextSummarization(a.EnableContextSummarization),
+		WithSummarizeOnTokenThreshold(a.SummarizeOnTokenThreshold, a.TokenThresholdPercent),
+		WithSummarizeOnFixedTokenThreshold(a.SummarizeOnFixedTokenThreshold, a.FixedTokenThreshold),
+		WithSummaryKeepLastMessages(a.SummaryKeepLastMessages),
+		WithSummarizationCooldown(a.SummarizationCooldownTurns),
+		WithAskPipeline(a.pipeline),
+	}
+	if a.summarizationStrategy != nil {
+		inherited = append(inherited, WithSummarizationStrategy(a.summarizationStrategy))
+	}
+	if a.ModelPricingOverride != nil {
+		inherited = append(inherited, WithModelPricing(*a.ModelPricingOverride))
+	}
+	// The fork may spend what is left of the parent's budget
+	if a.BudgetLimitUSD > 0 {
+		spent := a.GetTotalCost()
+		if spent >= a.BudgetLimitUSD {
+			return nil, &BudgetExceededError{LimitUSD: a.BudgetLimitUSD, SpentUSD: spent}
+		}
+		inherited = append(inherited, WithBudgetLimit(a.BudgetLimitUSD-spent))
+	}
+	inherited = append(inherited, inheritConfig(a))
+	inherited = append(inherited, options...)
+	inherited = append(inherited, func(fork *Agent) { fork.forkID = id })
+
+	fork, err := NewAgent(ctx, a.LLM, a.configPath, inherited...)
+	if err != nil {
+		return nil, fmt.Errorf("create fork: %w", err)
+	}
+	if err := a.copyConversationState(fork); err != nil {
+		fork.Close()
+		return nil, fmt.Errorf("copy conversation state to fork: %w", err)
+	}
+	fork.AddEventListener(subAgentEventForwarder{parent: a})
+
+	getLogger(a).Info("🍴 [FORK] Forked conversation",
+		loggerv2.String("fork_id", id),
+		loggerv2.String("parent_fork_id", a.forkID),
+		loggerv2.Int("messages", len(fork.RetainedHistory())))
+	return fork, nil
+}
+
+// ForkID returns the ID of an agent created with Fork, "" for other agents.
+// Events emitted by the fork carry it in AgentEvent.ForkID.
+func (a *Agent) ForkID() string {
+	return a.forkID
+}
+
+// inheritConfig copies the configuration of parent not covered by the
+// options Fork passes explicitly. Maps and slices are cloned so options given
+// to Fork (e.g. WithToolPermissions, which merges) leave the parent alone.
+// Outputs bound to the parent (recording, replay, autosave, session store,
+// webhooks, telemetry, audit and raw LLM logs, tracers and listeners) are not
+// copied: the fork reports through the parent's listeners instead.
+func inheritConfig(parent *Agent) AgentOption {
+	return func(a *Agent) {
+		a.LLMConfig = parent.LLMConfig
+		a.fallbackLLMs = slices.Clone(parent.fallbackLLMs)
+		a.APIKeys = parent.APIKeys
+		a.APIBaseURL = parent.APIBaseURL
+		a.APIToken = parent.APIToken
+		a.AgentMode = parent.AgentMode
+		a.ToolChoice = parent.ToolChoice
+		a.ContextWindowOverride = parent.ContextWindowOverride
+		a.AdaptiveMaxTokens = parent.AdaptiveMaxTokens
+		a.PromptLogLabel = parent.PromptLogLabel
+		a.CheckpointOwner = parent.CheckpointOwner
+		a.historyRetention = parent.historyRetention
+		a.CodingAgentWorkingDir = parent.CodingAgentWorkingDir
+		a.EnableStreaming = parent.EnableStreaming
+		a.SuppressGenerationStreamingEvents = parent.SuppressGenerationStreamingEvents
+		a.StreamingCallback = parent.StreamingCallback
+
+		// Tool execution
+		a.ToolTimeout = parent.ToolTimeout
+		a.perToolTimeouts = maps.Clone(parent.perToolTimeouts)
+		a.ToolPermissions = maps.Clone(parent.ToolPermissions)
+		a.ToolArgLimits = maps.Clone(parent.ToolArgLimits)
+		a.DisableToolArgValidation = parent.DisableToolArgValidation
+		a.toolArgTransformers = maps.Clone(parent.toolArgTransformers)
+		a.toolMiddleware = slices.Clone(parent.toolMiddleware)
+		a.ToolFailureLimit = parent.ToolFailureLimit
+		a.EnableParallelToolExecution = parent.EnableParallelToolExecution
+		a.MaxParallelToolCalls = parent.MaxParallelToolCalls
+		a.EnableBuiltinUtilityTools = parent.EnableBuiltinUtilityTools
+		a.CodeExecutionSandbox = parent.CodeExecutionSandbox
+		a.DockerSandboxConfig = parent.DockerSandboxConfig
+		a.mcpSampling = parent.mcpSampling
+		a.toolResultCache = parent.toolResultCache
+		a.ToolResultDeduplication = parent.ToolResultDeduplication
+		a.ToolImages = parent.ToolImages
+
+		// Guards and answer checks
+		if parent.outputGuard != nil {
+			guard := *parent.outputGuard
+			a.outputGuard = &guard
+		}
+		if parent.toolOutputGuard != nil {
+			guard := *parent.toolOutputGuard
+			a.toolOutputGuard = &guard
+		}
+		if parent.answerContract != nil {
+			contract := *parent.answerContract
+			a.answerContract = &contract
+		}
+		a.OutputSchema = parent.OutputSchema
+		a.Grounding = parent.Grounding
+		a.Glossary = maps.Clone(parent.Glossary)
+
+		// Context and prompt
+		a.DiscoverResource = parent.DiscoverResource
+		a.DiscoverPrompt = parent.DiscoverPrompt
+		a.IncludeServerInstructions = parent.IncludeServerInstructions
+		a.DiscoverResourceTemplates = parent.DiscoverResourceTemplates
+		a.EnableContextOffloading = parent.EnableContextOffloading
+		a.LargeOutputThreshold = parent.LargeOutputThreshold
+		a.toolOutputStorage = parent.toolOutputStorage
+		a.outputReferences = parent.outputReferences
+		a.outputURLResolver = parent.outputURLResolver
+		a.EnableContextEditing = parent.EnableContextEditing
+		a.ContextEditingThreshold = parent.ContextEditingThreshold
+		a.ContextEditingTurnThreshold = parent.ContextEditingTurnThreshold
+		a.memory = parent.memory
+		a.memoryTopK = parent.memoryTopK
+		if parent.smartRouter != nil {
+			WithSmartRouting(parent.smartRouter.config)(a)
+		}
+	}
+}
+
+// copyConversationState copies the conversation state of a into fork
+func (a *Agent) copyConversationState(fork *Agent) error {
+	if systemPrompt := a.GetSystemPrompt(); systemPrompt != "" {
+		fork.SetSystemPrompt(systemPrompt)
+	}
+
+	for name, tool := range a.customTools {
+		var parameters map[string]interface{}
+		if tool.Definition.Function.Parameters != nil {
+			raw, err := json.Marshal(tool.Definition.Function.Parameters)
+			if err != nil {
+				return fmt.Errorf("custom tool %s: %w", name, err)
+			}
+			if err := json.Unmarshal(raw, &parameters); err != nil {
+				return fmt.Errorf("custom tool %s: %w", name, err)
+			}
+		}
+		if err := fork.RegisterCustomToolWithTimeout(name, tool.Definition.Function.Description, parameters, tool.Execution, tool.Timeout, tool.Category); err != nil {
+			return err
+		}
+	}
+
+	a.toolAllowListMu.RLock()
+	allowList := make([]string, 0, len(a.toolAllowList))
+	for name := range a.toolAllowList {
+		allowList = append(allowList, name)
+	}
+	a.toolAllowListMu.RUnlock()
+	if len(allowList) > 0 {
+		fork.SetToolAllowList(allowList)
+	}
+
+	readPaths, writePaths := a.GetFolderGuardPaths()
+	if len(readPaths) > 0 || len(writePaths) > 0 {
+		fork.SetFolderGuardPaths(append([]string(nil), readPaths...), append([]string(nil), writePaths...))
+	}
+
+	if len(a.discoveredTools) > 0 {
+		if fork.discoveredTools == nil {
+			fork.discoveredTools = make(map[string]llmtypes.Tool, len(a.discoveredTools))
+		}
+		for name, tool := range a.discoveredTools {
+			fork.discoveredTools[name] = tool
+		}
+	}
+
+	a.sessionStateMu.Lock()
+	summaries := append([]string(nil), a.sessionSummaries...)
+	a.sessionStateMu.Unlock()
+	fork.sessionStateMu.Lock()
+	fork.sessionSummaries = summaries
+	fork.sessionStateMu.Unlock()
+
+	fork.retainHistory(a.RetainedHistory())
+	return nil
+}
@@ -0,0 +1,126 @@
+package mcpagent
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestForkClonesConversationStateAndTagsEvents(t *testing.T) {
+	config := filepath.Join(t.TempDir(), "mcp.json")
+	if err := os.WriteFile(config, []byte(`{"mcpServers": {}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	parent, err := NewAgent(ctx, &providerKeyCarrierModel{}, config, WithLogger(loggerv2.NewNoop()), WithSessionID("fork-test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer parent.Close()
+	listener := &recordingAgentEventListener{}
+	parent.AddEventListener(listener)
+
+	parent.SetSystemPrompt("You triage bugs.")
+	if err := parent.RegisterCustomTool("label_issue", "Label an issue", map[string]interface{}{
+		"type":       "object",
+		"properties": map[string]interface{}{"label": map[string]interface{}{"type": "string"}},
+	}, func(context.Context, map[string]interface{}) (string, error) { return "ok", nil }, "triage"); err != nil {
+		t.Fatal(err)
+	}
+	parent.SetToolAllowList([]string{"label_issue"})
+	parent.retainHistory(summarizationTestHistory())
+	parent.sessionSummaries = []string{"The user asked for the open bugs."}
+
+	generate := &scriptedGenerateStage{responses: []*llmtypes.ContentResponse{{
+		Choices: []*llmtypes.ContentChoice{{Content: "Fixed #12 another way"}},
+	}}}
+	fork, err := parent.Fork(ctx, WithAskPipeline(AskPipeline{Generate: generate}))
+	if err != nil {
+		t.Fatalf("Fork: %v", err)
+	}
+	defer fork.Close()
+
+	if fork.ForkID() == "" || parent.ForkID() != "" {
+		t.Errorf("fork ID = %q, parent fork ID = %q", fork.ForkID(), parent.ForkID())
+	}
+	if fork.SessionID != parent.SessionID {
+		t.Errorf("fork session = %q, want the parent's for shared connections", fork.SessionID)
+	}
+	if fork.GetSystemPrompt() != "You triage bugs." {
+		t.Errorf("fork system prompt = %q", fork.GetSystemPrompt())
+	}
+	if _, ok := fork.customTools["label_issue"]; !ok || !fork.isToolAllowed("label_issue") || fork.isToolAllowed("delete_issue") {
+		t.Error("custom tools and tool allow list not copied")
+	}
+	if len(fork.sessionSummaries) != 1 {
+		t.Errorf("fork summaries = %v", fork.sessionSummaries)
+	}
+	history := fork.RetainedHistory()
+	if len(history) != len(summarizationTestHistory()) {
+		t.Fatalf("fork history has %d messages", len(history))
+	}
+
+	answer, _, err := fork.AskWithHistory(ctx, append(history, llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "Try another fix")))
+	if err != nil || answer != "Fixed #12 another way" {
+		t.Fatalf("fork answer = %q, %v", answer, err)
+	}
+	if len(parent.RetainedHistory()) != len(summarizationTestHistory()) {
+		t.Error("the fork's conversation changed the parent's history")
+	}
+	if len(listener.events) == 0 {
+		t.Fatal("fork events not forwarded to the parent")
+	}
+	for _, event := range listener.events {
+		if event.ForkID != fork.ForkID() {
+			t.Errorf("event %s has fork ID %q, want %q", event.Type, event.ForkID, fork.ForkID())
+		}
+	}
+}
+
+func TestForkInheritsParentConfiguration(t *testing.T) {
+	config := filepath.Join(t.TempDir(), "mcp.json")
+	if err := os.WriteFile(config, []byte(`{"mcpServers": {}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	passThrough := func(ctx context.Context, call *ToolInvocation, next ToolExecFunc) (*mcp.CallToolResult, error) {
+		return next(ctx, call)
+	}
+	parent, err := NewAgent(ctx, &providerKeyCarrierModel{}, config,
+		WithLogger(loggerv2.NewNoop()),
+		WithToolPermissions(map[string]ToolPermission{"delete_issue": {Disabled: true}}),
+		WithToolArgLimits(map[string]ToolArgLimit{"label_issue": {MaxBytes: 64}}),
+		WithToolMiddleware(passThrough),
+		WithCodeExecutionSandbox("docker"),
+		WithOutputGuard(OutputValidatorFunc(func(context.Context, string) error { return nil }), 1),
+		WithToolOutputGuard(ToolOutputGuardConfig{}),
+		WithFallbackLLMs(&providerKeyCarrierModel{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer parent.Close()
+
+	fork, err := parent.Fork(ctx, WithToolPermissions(map[string]ToolPermission{"close_issue": {Disabled: true}}))
+	if err != nil {
+		t.Fatalf("Fork: %v", err)
+	}
+	defer fork.Close()
+
+	if !fork.ToolPermissions["delete_issue"].Disabled || !fork.ToolPermissions["close_issue"].Disabled {
+		t.Errorf("fork permissions = %v", fork.ToolPermissions)
+	}
+	if _, ok := parent.ToolPermissions["close_issue"]; ok {
+		t.Error("a fork option changed the parent's permissions")
+	}
+	if fork.ToolArgLimits["label_issue"].MaxBytes != 64 || len(fork.toolMiddleware) != 1 {
+		t.Errorf("fork arg limits = %v, middleware = %d", fork.ToolArgLimits, len(fork.toolMiddleware))
+	}
+	if fork.CodeExecutionSandbox != "docker" || fork.outputGuard == nil || fork.toolOutputGuard == nil || len(fork.fallbackLLMs) != 1 {
+		t.Error("sandbox, guards or fallback models not inherited")
+	}
+}
@@ -0,0 +1,202 @@
+// glossary.go
+//
+// This file provides glossary enforcement for final answers. A glossary maps
+// terms (brand names, product terms, or their variants and translations) to
+// the rendering that must appear in answers, e.g. {"Acme Cloud": "Acme Cloud",
+// "nube de Acme": "Acme Cloud"}. It is enforced twice: the system prompt
+// carries a terminology contract so the model renders terms correctly in any
+// language, and the final answer gets a post-processing pass that rewrites any
+// remaining variant (case-insensitive, whole words, outside code). The pass
+// runs in DefaultPostprocessStage, so a custom PostprocessStage that wraps the
+// default one keeps it.
+//
+// Exported:
+//   - WithGlossary: Configure the glossary when creating an agent
+//   - Agent.ApplyGlossary: Run the post-processing pass on any text
+
+package mcpagent
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/manishiitg/mcpagent/agent/prompt"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+// WithGlossary enforces consistent terminology in final answers. Keys are the
+// terms or variants to look for, values the required rendering; map a term to
+// itself (or to "") to keep it verbatim, e.g. a brand name that must never be
+// translated. Can be used multiple times; later entries win.
+//
+// Example:
+//
+//	mcpagent.WithGlossary(map[string]string{
+//	    "Acme Cloud":   "",           // never translate
+//	    "nube de Acme": "Acme Cloud", // rewrite the translation
+//	})
+//
+// Default: nil (no glossary)
+func WithGlossary(glossary map[string]string) AgentOption {
+	return func(a *Agent) {
+		if a.Glossary == nil {
+			a.Glossary = make(map[string]string, len(glossary))
+		}
+		for term, rendering := range glossary {
+			if strings.TrimSpace(term) == "" {
+				continue
+			}
+			if rendering == "" {
+				rendering = term
+			}
+			a.Glossary[term] = rendering
+		}
+		a.glossaryMatcher = nil
+	}
+}
+
+// ApplyGlossary rewrites glossary terms in text to their required rendering.
+// Matching is case-insensitive and on whole words; fenced and inline code is
+// left untouched.
+func (a *Agent) ApplyGlossary(text string) string {
+	if len(a.Glossary) == 0 || text == "" {
+		return text
+	}
+	matcher := a.glossaryRegexp()
+
+	var b strings.Builder
+	// Even segments are prose, odd segments are inside ``` fences
+	for i, fenced := range strings.Split(text, "```") {
+		if i > 0 {
+			b.WriteString("```")
+		}
+		if i%2 == 1 {
+			b.WriteString(fenced)
+			continue
+		}
+		for j, inline := range strings.Split(fenced, "`") {
+			if j > 0 {
+				b.WriteString("`")
+			}
+			if j%2 == 1 {
+				b.WriteString(inline)
+				continue
+			}
+			b.WriteString(a.replaceGlossaryTerms(matcher, inline))
+		}
+	}
+	return b.String()
+}
+
+// applyGlossaryToAnswer rewrites the final answer and the assistant message
+// carrying it at the end of the history
+func (a *Agent) applyGlossaryToAnswer(answer string, messages []llmtypes.MessageContent) (string, []llmtypes.MessageContent) {
+	if len(a.Glossary) == 0 || answer == "" {
+		return answer, messages
+	}
+	rendered := a.ApplyGlossary(answer)
+	if rendered == answer {
+		return answer, messages
+	}
+	if n := len(messages); n > 0 && messages[n-1].Role == llmtypes.ChatMessageTypeAI {
+		parts := make([]llmtypes.ContentPart, len(messages[n-1].Parts))
+		for i, part := range messages[n-1].Parts {
+			if text, ok := part.(llmtypes.TextContent); ok && text.Text == answer {
+				part = llmtypes.TextContent{Text: rendered}
+			}
+			parts[i] = part
+		}
+		messages[n-1] = llmtypes.MessageContent{Role: llmtypes.ChatMessageTypeAI, Parts: parts}
+	}
+	return rendered, messages
+}
+
+// glossaryPromptSection returns the terminology contract for the system prompt
+func (a *Agent) glossaryPromptSection() string {
+	return prompt.BuildGlossarySection(a.Glossary)
+}
+
+// glossaryRegexp matches any glossary term, longest first, case-insensitively
+func (a *Agent) glossaryRegexp() *regexp.Regexp {
+	a.glossaryMu.Lock()
+	defer a.glossaryMu.Unlock()
+	if a.glossaryMatcher != nil {
+		return a.glossaryMatcher
+	}
+	terms := make([]string, 0, len(a.Glossary))
+	for term := range a.Glossary {
+		terms = append(terms, term)
+	}
+	sort.Slice(terms, func(i, j int) bool {
+		if len(terms[i]) != len(terms[j]) {
+			return len(terms[i]) > len(terms[j])
+		}
+		return terms[i] < terms[j]
+	})
+	quoted := make([]string, len(terms))
+	for i, term := range terms {
+		quoted[i] = regexp.QuoteMeta(term)
+	}
+	a.glossaryMatcher = regexp.MustCompile("(?i)(?:" + strings.Join(quoted, "|") + ")")
+	return a.glossaryMatcher
+}
+
+// replaceGlossaryTerms replaces matches that stand as whole words
+func (a *Agent) replaceGlossaryTerms(matcher *regexp.Regexp, text string) string {
+	matches := matcher.FindAllStringIndex(text, -1)
+	if len(matches) == 0 {
+		return text
+	}
+	var b strings.Builder
+	last := 0
+	for _, m := range matches {
+		start, end := m[0], m[1]
+		if !glossaryBoundary(text, start, end) {
+			continue
+		}
+		rendering, ok := a.glossaryRendering(text[start:end])
+		if !ok {
+			continue
+		}
+		b.WriteString(text[last:start])
+		b.WriteString(rendering)
+		last = end
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+// glossaryRendering looks up the rendering of a matched term, ignoring case
+func (a *Agent) glossaryRendering(match string) (string, bool) {
+	if rendering, ok := a.Glossary[match]; ok {
+		return rendering, true
+	}
+	for term, rendering := range a.Glossary {
+		if strings.EqualFold(term, match) {
+			return rendering, true
+		}
+	}
+	return "", false
+}
+
+// glossaryBoundary reports whether text[start:end] is not part of a longer word
+func glossaryBoundary(text string, start, end int) bool {
+	if start > 0 {
+		if r, _ := utf8.DecodeLastRuneInString(text[:start]); isGlossaryWordRune(r) {
+			return false
+		}
+	}
+	if end < len(text) {
+		if r, _ := utf8.DecodeRuneInString(text[end:]); isGlossaryWordRune(r) {
+			return false
+		}
+	}
+	return true
+}
+
+func isGlossaryWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
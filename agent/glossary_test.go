@@ -0,0 +1,99 @@
+package mcpagent
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+func glossaryTestAgent() *Agent {
+	a := &Agent{Logger: loggerv2.NewNoop()}
+	WithGlossary(map[string]string{
+		"Acme Cloud":   "",
+		"nube de Acme": "Acme Cloud",
+		"Acme":         "ACME",
+	})(a)
+	return a
+}
+
+func TestApplyGlossaryRewritesVariants(t *testing.T) {
+	a := glossaryTestAgent()
+
+	cases := map[string]string{
+		"Despliega en la nube de Acme hoy.": "Despliega en la Acme Cloud hoy.",
+		"acme cloud is ready":               "Acme Cloud is ready",
+		"Ask acme support":                  "Ask ACME support",
+		"Acmeville stays as is":             "Acmeville stays as is",
+		"Run `acme cloud login` first":      "Run `acme cloud login` first",
+		"```\nacme deploy\n```\nthen acme":  "```\nacme deploy\n```\nthen ACME",
+	}
+	for in, want := range cases {
+		if got := a.ApplyGlossary(in); got != want {
+			t.Errorf("ApplyGlossary(%q) = %q, want %q", in, got, want)
+		}
+	}
+	if got := (&Agent{}).ApplyGlossary("acme cloud"); got != "acme cloud" {
+		t.Errorf("expected no change without a glossary, got %q", got)
+	}
+}
+
+func TestGlossaryPromptContract(t *testing.T) {
+	a := glossaryTestAgent()
+	a.systemPrompt = "BASE"
+
+	messages := ensureSystemPrompt(context.Background(), a, []llmtypes.MessageContent{llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "hola")})
+	system := messages[0].Parts[0].(llmtypes.TextContent).Text
+	for _, want := range []string{"<glossary>", "- Acme Cloud (keep verbatim)", "- nube de Acme → Acme Cloud"} {
+		if !strings.Contains(system, want) {
+			t.Errorf("system prompt missing %q:\n%s", want, system)
+		}
+	}
+}
+
+func TestAskWithHistoryEnforcesGlossary(t *testing.T) {
+	a := glossaryTestAgent()
+	a.LLM = &providerKeyCarrierModel{}
+	a.ModelID = "test-model"
+	WithAskPipeline(AskPipeline{Generate: &scriptedGenerateStage{responses: []*llmtypes.ContentResponse{
+		{Choices: []*llmtypes.ContentChoice{{Content: "Usa la nube de Acme."}}},
+	}}})(a)
+
+	answer, history, err := AskWithHistory(a, context.Background(), []llmtypes.MessageContent{
+		llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "¿Dónde despliego?"),
+	})
+	if err != nil {
+		t.Fatalf("AskWithHistory: %v", err)
+	}
+	if answer != "Usa la Acme Cloud." {
+		t.Errorf("answer = %q", answer)
+	}
+	last := history[len(history)-1].Parts[0].(llmtypes.TextContent).Text
+	if last != answer {
+		t.Errorf("history answer = %q, want it to match the rewritten answer", last)
+	}
+}
+
+func TestCustomPostprocessStageReplacesGlossaryPass(t *testing.T) {
+	a := glossaryTestAgent()
+	a.LLM = &providerKeyCarrierModel{}
+	a.ModelID = "test-model"
+	WithAskPipeline(AskPipeline{
+		Generate: &scriptedGenerateStage{responses: []*llmtypes.ContentResponse{
+			{Choices: []*llmtypes.ContentChoice{{Content: "Usa la nube de Acme."}}},
+		}},
+		Postprocess: upperPostprocessStage{},
+	})(a)
+
+	answer, _, err := AskWithHistory(a, context.Background(), []llmtypes.MessageContent{
+		llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "¿Dónde despliego?"),
+	})
+	if err != nil {
+		t.Fatalf("AskWithHistory: %v", err)
+	}
+	if answer != "USA LA NUBE DE ACME." {
+		t.Errorf("answer = %q, want only the custom stage applied", answer)
+	}
+}
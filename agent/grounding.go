@@ -0,0 +1,185 @@
+// grounding.go
+//
+// This file provides the final-answer grounding check. When enabled, every
+// final answer is sent with the tool outputs collected in the conversation to
+// a checker model (typically a small, cheap one), which lists the answer's
+// factual claims and whether the tool outputs support each. The result is a
+// GroundingReport, emitted in a GroundingCheck event and attached to the
+// completion event. Optionally, an answer with unsupported claims triggers
+// one corrective re-ask asking the agent to verify or remove them.
+//
+// Answers produced without any tool output are not checked: there is no
+// evidence to ground them in.
+//
+// Exported:
+//   - GroundingConfig: Checker model, evidence cap and re-ask behavior
+//   - WithGroundingCheck: Enable the check when creating an agent
+
+package mcpagent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/manishiitg/mcpagent/events"
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+// DefaultGroundingMaxEvidenceChars caps the tool output sent to the checker
+const DefaultGroundingMaxEvidenceChars = 60000
+
+// GroundingConfig configures the final-answer grounding check
+type GroundingConfig struct {
+	// Model runs the check; a small model is enough (nil = the agent's LLM)
+	Model llmtypes.Model
+	// MaxEvidenceChars caps the tool output given to the checker, keeping the
+	// most recent results (0 = DefaultGroundingMaxEvidenceChars)
+	MaxEvidenceChars int
+	// CorrectiveReask asks the agent once to revise an answer with
+	// unsupported claims before the conversation ends
+	CorrectiveReask bool
+}
+
+// WithGroundingCheck checks final answers against the tool outputs collected
+// in the conversation and reports unsupported claims.
+//
+// Example:
+//
+//	mcpagent.WithGroundingCheck(mcpagent.GroundingConfig{
+//	    Model:           cheapLLM,
+//	    CorrectiveReask: true,
+//	})
+//
+// Default: disabled
+func WithGroundingCheck(config GroundingConfig) AgentOption {
+	return func(a *Agent) {
+		a.Grounding = &config
+	}
+}
+
+// groundingCheckPrompt instructs the checker model
+const groundingCheckPrompt = `You verify whether an AI assistant's answer is supported by the tool outputs it collected.
+
+List every factual claim in the answer (figures, names, dates, statuses, quotes and other verifiable statements; skip opinions, advice and formatting). For each claim decide whether the tool outputs support it. A claim is supported only if the tool outputs state it or it follows directly from them.
+
+Respond with ONLY a JSON object, without markdown formatting:
+{"claims": [{"claim": "<claim>", "supported": true, "evidence": "<short quote of the supporting tool output, or why it is unsupported>"}]}`
+
+// checkGrounding checks answer against the tool results in messages. It
+// returns nil when the check is disabled or there is no tool evidence.
+func (a *Agent) checkGrounding(ctx context.Context, messages []llmtypes.MessageContent, answer string, turn int, reasked bool) *events.GroundingReport {
+	config := a.Grounding
+	if config == nil || strings.TrimSpace(answer) == "" {
+		return nil
+	}
+	maxChars := config.MaxEvidenceChars
+	if maxChars <= 0 {
+		maxChars = DefaultGroundingMaxEvidenceChars
+	}
+	evidence, count := collectGroundingEvidence(messages, maxChars)
+	if count == 0 {
+		return nil
+	}
+
+	model := config.Model
+	if model == nil {
+		model = a.LLM
+	}
+	report := &events.GroundingReport{Model: model.GetModelID(), EvidenceCount: count, Reasked: reasked}
+
+	checkMessages := []llmtypes.MessageContent{
+		llmtypes.TextParts(llmtypes.ChatMessageTypeSystem, groundingCheckPrompt),
+		llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "Tool outputs:\n\n"+evidence+"\n\nAnswer to verify:\n\n"+answer),
+	}
+	resp, err := model.GenerateContent(ctx, checkMessages, llmtypes.WithTemperature(0), llmtypes.WithJSONMode())
+	if err == nil && (resp == nil || len(resp.Choices) == 0 || resp.Choices[0] == nil) {
+		err = fmt.Errorf("checker returned no response")
+	}
+	if err == nil {
+		report.Claims, err = parseGroundingClaims(resp.Choices[0].Content)
+	}
+	if err != nil {
+		report.Error = err.Error()
+	}
+	for _, claim := range report.Claims {
+		if !claim.Supported {
+			report.UnsupportedClaims++
+		}
+	}
+
+	logger := getLogger(a)
+	if report.Error != "" {
+		logger.Warn("🔎 [GROUNDING] Check failed", loggerv2.String("error", report.Error))
+	} else {
+		logger.Info("🔎 [GROUNDING] Checked final answer",
+			loggerv2.Int("claims", len(report.Claims)),
+			loggerv2.Int("unsupported_claims", report.UnsupportedClaims),
+			loggerv2.Int("current_turn", turn+1))
+	}
+	a.EmitTypedEvent(ctx, events.NewGroundingCheckEvent(turn+1, *report))
+	return report
+}
+
+// collectGroundingEvidence renders the tool results of messages, newest
+// first within maxChars, in conversation order
+func collectGroundingEvidence(messages []llmtypes.MessageContent, maxChars int) (string, int) {
+	var blocks []string
+	total := 0
+	for i := len(messages) - 1; i >= 0 && total < maxChars; i-- {
+		if messages[i].Role != llmtypes.ChatMessageTypeTool {
+			continue
+		}
+		for j := len(messages[i].Parts) - 1; j >= 0 && total < maxChars; j-- {
+			tr, ok := messages[i].Parts[j].(llmtypes.ToolCallResponse)
+			if !ok || tr.IsError || strings.TrimSpace(tr.Content) == "" || strings.HasPrefix(tr.Content, duplicateResultPrefix) {
+				continue
+			}
+			content := tr.Content
+			if remaining := maxChars - total; len(content) > remaining {
+				content = content[:remaining] + "\n[truncated]"
+			}
+			total += len(content)
+			blocks = append(blocks, fmt.Sprintf("[%s]\n%s", tr.Name, content))
+		}
+	}
+	for i, j := 0, len(blocks)-1; i < j; i, j = i+1, j-1 {
+		blocks[i], blocks[j] = blocks[j], blocks[i]
+	}
+	return strings.Join(blocks, "\n\n"), len(blocks)
+}
+
+// parseGroundingClaims extracts the claims from the checker's JSON response
+func parseGroundingClaims(content string) ([]events.GroundingClaim, error) {
+	start, end := strings.Index(content, "{"), strings.LastIndex(content, "}")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("checker response is not JSON")
+	}
+	var parsed struct {
+		Claims []events.GroundingClaim `json:"claims"`
+	}
+	if err := json.Unmarshal([]byte(content[start:end+1]), &parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse checker response: %w", err)
+	}
+	return parsed.Claims, nil
+}
+
+// groundingCorrectionMessage asks the agent to revise an answer with unsupported claims
+func groundingCorrectionMessage(report *events.GroundingReport) llmtypes.MessageContent {
+	var b strings.Builder
+	b.WriteString("A verification step found claims in your answer that are not supported by the tool results in this conversation:\n")
+	for _, claim := range report.Claims {
+		if claim.Supported {
+			continue
+		}
+		b.WriteString("- " + claim.Claim)
+		if claim.Evidence != "" {
+			b.WriteString(" (" + claim.Evidence + ")")
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("\nVerify these claims with the available tools, or remove or clearly qualify them. Then respond with your complete revised answer.")
+	return llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, b.String())
+}
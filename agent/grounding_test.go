@@ -0,0 +1,125 @@
+package mcpagent
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/manishiitg/mcpagent/events"
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+// scriptedCheckerModel returns its responses in order and records the prompts it saw
+type scriptedCheckerModel struct {
+	responses []string
+	prompts   []string
+}
+
+func (m *scriptedCheckerModel) GenerateContent(_ context.Context, messages []llmtypes.MessageContent, _ ...llmtypes.CallOption) (*llmtypes.ContentResponse, error) {
+	m.prompts = append(m.prompts, messages[len(messages)-1].Parts[0].(llmtypes.TextContent).Text)
+	resp := m.responses[0]
+	m.responses = m.responses[1:]
+	return &llmtypes.ContentResponse{Choices: []*llmtypes.ContentChoice{{Content: resp}}}, nil
+}
+
+func (m *scriptedCheckerModel) GetModelID() string { return "checker" }
+
+func (m *scriptedCheckerModel) GetModelMetadata(string) (*llmtypes.ModelMetadata, error) {
+	return nil, nil
+}
+
+func TestGroundingCheckReasksOnceForUnsupportedClaims(t *testing.T) {
+	generate := &scriptedGenerateStage{responses: []*llmtypes.ContentResponse{
+		{Choices: []*llmtypes.ContentChoice{{ToolCalls: []llmtypes.ToolCall{{
+			ID:           "call-1",
+			Type:         "function",
+			FunctionCall: &llmtypes.FunctionCall{Name: "lookup", Arguments: `{}`},
+		}}}}},
+		{Choices: []*llmtypes.ContentChoice{{Content: "Revenue was $5M and the status is ok."}}},
+		{Choices: []*llmtypes.ContentChoice{{Content: "The status is ok; revenue is not in the data."}}},
+	}}
+	checker := &scriptedCheckerModel{responses: []string{
+		"```json\n" + `{"claims":[{"claim":"Revenue was $5M","supported":false,"evidence":"no revenue in tool output"},{"claim":"status is ok","supported":true,"evidence":"ok"}]}` + "\n```",
+		`{"claims":[{"claim":"status is ok","supported":true,"evidence":"ok"}]}`,
+	}}
+	listener := &recordingAgentEventListener{}
+
+	a := &Agent{Logger: loggerv2.NewNoop(), LLM: &providerKeyCarrierModel{}, ModelID: "test-model", MaxTurns: 5}
+	a.AddEventListener(listener)
+	WithAskPipeline(AskPipeline{Generate: generate, DispatchTools: &recordingToolDispatcher{}})(a)
+	WithGroundingCheck(GroundingConfig{Model: checker, CorrectiveReask: true})(a)
+
+	answer, history, err := AskWithHistory(a, context.Background(), []llmtypes.MessageContent{
+		llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "what is the revenue?"),
+	})
+	if err != nil {
+		t.Fatalf("AskWithHistory: %v", err)
+	}
+	if answer != "The status is ok; revenue is not in the data." || generate.calls != 3 {
+		t.Fatalf("expected the revised answer after one re-ask, got %q after %d calls", answer, generate.calls)
+	}
+	if len(checker.prompts) != 2 || !strings.Contains(checker.prompts[0], "[lookup]\nok") {
+		t.Errorf("expected the checker to see the tool output twice, got %q", checker.prompts)
+	}
+
+	reasked := false
+	for _, msg := range history {
+		if msg.Role == llmtypes.ChatMessageTypeHuman && strings.Contains(msg.Parts[0].(llmtypes.TextContent).Text, "- Revenue was $5M") {
+			reasked = true
+		}
+	}
+	if !reasked {
+		t.Error("expected a corrective message listing the unsupported claim")
+	}
+
+	var checks []*events.GroundingCheckEvent
+	var completion *events.UnifiedCompletionEvent
+	for _, event := range listener.events {
+		switch data := event.Data.(type) {
+		case *events.GroundingCheckEvent:
+			checks = append(checks, data)
+		case *events.UnifiedCompletionEvent:
+			completion = data
+		}
+	}
+	if len(checks) != 2 || checks[0].Report.UnsupportedClaims != 1 {
+		t.Fatalf("expected two grounding checks, the first with an unsupported claim, got %+v", checks)
+	}
+	if completion == nil || completion.Grounding == nil || !completion.Grounding.Reasked || completion.Grounding.UnsupportedClaims != 0 {
+		t.Errorf("expected the final report on the completion event, got %+v", completion)
+	}
+}
+
+func TestGroundingCheckSkipsAnswersWithoutEvidence(t *testing.T) {
+	checker := &scriptedCheckerModel{}
+	a := &Agent{Logger: loggerv2.NewNoop()}
+	WithGroundingCheck(GroundingConfig{Model: checker})(a)
+
+	messages := []llmtypes.MessageContent{llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "hi")}
+	if report := a.checkGrounding(context.Background(), messages, "Hello!", 0, false); report != nil {
+		t.Errorf("expected no report without tool evidence, got %+v", report)
+	}
+	if len(checker.prompts) != 0 {
+		t.Error("checker should not be called")
+	}
+}
+
+func TestCollectGroundingEvidenceKeepsNewestWithinCap(t *testing.T) {
+	toolMessage := func(name, content string) llmtypes.MessageContent {
+		return llmtypes.MessageContent{Role: llmtypes.ChatMessageTypeTool, Parts: []llmtypes.ContentPart{
+			llmtypes.ToolCallResponse{ToolCallID: name, Name: name, Content: content},
+		}}
+	}
+	evidence, count := collectGroundingEvidence([]llmtypes.MessageContent{
+		toolMessage("old", strings.Repeat("a", 50)),
+		toolMessage("mid", "middle"),
+		toolMessage("new", "newest"),
+	}, 20)
+	if count != 3 || !strings.HasPrefix(evidence, "[old]") || !strings.HasSuffix(evidence, "[new]\nnewest") {
+		t.Errorf("unexpected evidence (%d blocks): %q", count, evidence)
+	}
+	if !strings.Contains(evidence, "[truncated]") || strings.Count(evidence, "a") > 20 {
+		t.Errorf("expected the oldest result to be truncated to the cap: %q", evidence)
+	}
+}
@@ -0,0 +1,141 @@
+// introspection.go
+//
+// This file provides read-only views of the agent's live capabilities — its
+// tools with their schemas and its MCP servers — for UIs that render tool
+// pickers and capability views. They reflect the current state, including
+// config reloads and tools registered after creation.
+//
+// Exported:
+//   - ToolInfo / Agent.ListTools / Agent.ToolSchema: Tools available to the agent
+//   - ServerInfo / Agent.ListServers: MCP servers the agent uses
+
+package mcpagent
+
+import (
+	"encoding/json"
+	"slices"
+	"sort"
+
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+// Tool types reported by ToolInfo.Type
+const (
+	ToolTypeMCP     = "mcp"
+	ToolTypeCustom  = "custom"
+	ToolTypeVirtual = "virtual"
+)
+
+// ToolInfo describes a tool available to the agent
+type ToolInfo struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	// Server is the MCP server of an MCP tool, the category of a custom tool,
+	// and empty for virtual tools
+	Server string `json:"server,omitempty"`
+	// Type is ToolTypeMCP, ToolTypeCustom or ToolTypeVirtual
+	Type string `json:"type"`
+	// Parameters is the JSON schema of the tool's arguments
+	Parameters map[string]interface{} `json:"parameters,omitempty"`
+}
+
+// ServerInfo describes an MCP server the agent uses
+type ServerInfo struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	// Connected is false for servers whose tools come from the cache and
+	// that are connected on their first tool call
+	Connected     bool   `json:"connected"`
+	ToolCount     int    `json:"tool_count"`
+	PromptCount   int    `json:"prompt_count"`
+	ResourceCount int    `json:"resource_count"`
+	Instructions  string `json:"instructions,omitempty"`
+}
+
+// ListTools returns the tools available to the agent, sorted by name: the
+// tools offered to the LLM plus the MCP tools reached through code execution
+// or tool search. Tools disabled by WithToolPermissions are left out.
+func (a *Agent) ListTools() []ToolInfo {
+	seen := make(map[string]bool)
+	var infos []ToolInfo
+	for _, group := range [][]llmtypes.Tool{a.Tools, a.allMCPToolDefs, a.allDeferredTools} {
+		for _, tool := range group {
+			if tool.Function == nil || seen[tool.Function.Name] || !a.isToolEnabled(tool.Function.Name) {
+				continue
+			}
+			seen[tool.Function.Name] = true
+			infos = append(infos, a.toolInfo(tool))
+		}
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos
+}
+
+// ToolSchema returns the tool named name and whether the agent has it
+func (a *Agent) ToolSchema(name string) (ToolInfo, bool) {
+	for _, info := range a.ListTools() {
+		if info.Name == name {
+			return info, true
+		}
+	}
+	return ToolInfo{}, false
+}
+
+// toolInfo describes tool, resolving its type and server
+func (a *Agent) toolInfo(tool llmtypes.Tool) ToolInfo {
+	info := ToolInfo{Name: tool.Function.Name, Description: tool.Function.Description, Type: ToolTypeVirtual}
+	if server, ok := a.toolToServer[info.Name]; ok {
+		if server == "custom" {
+			info.Type = ToolTypeCustom
+			info.Server = a.customTools[info.Name].Category
+		} else {
+			info.Type = ToolTypeMCP
+			info.Server = server
+		}
+	}
+	if tool.Function.Parameters != nil {
+		if data, err := json.Marshal(tool.Function.Parameters); err == nil {
+			_ = json.Unmarshal(data, &info.Parameters)
+		}
+	}
+	return info
+}
+
+// ListServers returns the MCP servers the agent uses, sorted by name
+func (a *Agent) ListServers() []ServerInfo {
+	toolCounts := make(map[string]int)
+	for name, server := range a.toolToServer {
+		if server != "custom" && a.isToolEnabled(name) {
+			toolCounts[server]++
+		}
+	}
+
+	a.clientsMu.RLock()
+	connected := make(map[string]bool, len(a.Clients))
+	for name, client := range a.Clients {
+		connected[name] = client != nil
+	}
+	a.clientsMu.RUnlock()
+
+	names := append([]string(nil), a.servers...)
+	for name := range connected {
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	infos := make([]ServerInfo, 0, len(names))
+	for _, name := range names {
+		infos = append(infos, ServerInfo{
+			Name:          name,
+			Description:   a.serverConfigs[name].Description,
+			Connected:     connected[name],
+			ToolCount:     toolCounts[name],
+			PromptCount:   len(a.prompts[name]),
+			ResourceCount: len(a.resources[name]),
+			Instructions:  a.serverInstructions[name],
+		})
+	}
+	return infos
+}
@@ -0,0 +1,76 @@
+package mcpagent
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+	"github.com/manishiitg/mcpagent/mcpclient"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+func introspectionTestAgent(t *testing.T) *Agent {
+	t.Helper()
+	a := &Agent{
+		Logger:           loggerv2.NewNoop(),
+		servers:          []string{"crm"},
+		serverConfigs:    map[string]mcpclient.MCPServerConfig{"crm": {Command: "crm-mcp", Description: "Customer records"}},
+		toolToServer:     map[string]string{"lookup_customer": "crm", "delete_customer": "crm"},
+		Tools:            []llmtypes.Tool{hintTestTool("search_large_output"), hintTestTool("get_prompt")},
+		allDeferredTools: []llmtypes.Tool{hintTestTool("lookup_customer"), hintTestTool("delete_customer")},
+		prompts:          map[string][]mcp.Prompt{"crm": {{Name: "summarize"}}},
+		ToolPermissions:  map[string]ToolPermission{"get_prompt": {Disabled: true}},
+	}
+	params := map[string]interface{}{
+		"type":       "object",
+		"properties": map[string]interface{}{"note": map[string]interface{}{"type": "string"}},
+	}
+	run := func(context.Context, map[string]interface{}) (string, error) { return "", nil }
+	if err := a.RegisterCustomTool("add_note", "Adds a note", params, run, "notes"); err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
+
+func TestListTools(t *testing.T) {
+	a := introspectionTestAgent(t)
+	tools := a.ListTools()
+
+	var names []string
+	for _, tool := range tools {
+		names = append(names, tool.Name)
+	}
+	if got := fmt.Sprint(names); got != "[add_note delete_customer lookup_customer search_large_output]" {
+		t.Fatalf("tools = %s", got)
+	}
+	types := map[string]string{}
+	for _, tool := range tools {
+		types[tool.Name] = tool.Type + ":" + tool.Server
+	}
+	if types["add_note"] != "custom:notes" || types["lookup_customer"] != "mcp:crm" || types["search_large_output"] != "virtual:" {
+		t.Errorf("types = %v", types)
+	}
+
+	info, ok := a.ToolSchema("add_note")
+	if !ok || info.Description != "Adds a note" || info.Parameters["type"] != "object" {
+		t.Errorf("ToolSchema(add_note) = %+v, %v", info, ok)
+	}
+	if _, ok := a.ToolSchema("get_prompt"); ok {
+		t.Error("disabled tools should not be reported")
+	}
+}
+
+func TestListServers(t *testing.T) {
+	a := introspectionTestAgent(t)
+	servers := a.ListServers()
+	if len(servers) != 1 {
+		t.Fatalf("servers = %+v", servers)
+	}
+	crm := servers[0]
+	if crm.Name != "crm" || crm.Description != "Customer records" || crm.Connected || crm.ToolCount != 2 || crm.PromptCount != 1 {
+		t.Errorf("crm = %+v", crm)
+	}
+}
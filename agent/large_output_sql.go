@@ -0,0 +1,373 @@
+// large_output_sql.go
+//
+// This file implements search_large_output with operation="sql": SQL over an
+// offloaded tabular output. The output is loaded into a table named "output"
+// of a throwaway in-memory SQLite database (pure Go, no cgo), so the model can
+// filter, group and aggregate thousands of rows and only the result enters
+// the context. Supported formats:
+//   - JSON array of objects, or an object whose only array field holds the rows
+//   - JSON lines (one object per line)
+//   - CSV with a header row (TSV for .tsv files)
+//
+// JSON keys become columns; nested values are stored as JSON text, usable
+// with SQLite's json_extract. CSV columns have NUMERIC affinity so numeric
+// cells compare as numbers. Only a single SELECT (or WITH ... SELECT)
+// statement is accepted.
+
+package mcpagent
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite" // registers the "sqlite" database/sql driver
+)
+
+const (
+	// largeOutputSQLTable is the table an offloaded output is loaded into
+	largeOutputSQLTable = "output"
+	// defaultLargeOutputSQLMaxRows caps the rows returned by operation="sql"
+	defaultLargeOutputSQLMaxRows = 100
+	// largeOutputSQLTimeout bounds loading and querying one output
+	largeOutputSQLTimeout = 30 * time.Second
+)
+
+// handleSQLLargeOutput handles search_large_output with operation="sql".
+func (a *Agent) handleSQLLargeOutput(ctx context.Context, args map[string]interface{}) (string, error) {
+	filename, ok := args["filename"].(string)
+	if !ok {
+		return "", fmt.Errorf("filename parameter is required")
+	}
+
+	query, ok := args["sql"].(string)
+	if !ok || strings.TrimSpace(query) == "" {
+		return "", fmt.Errorf("sql parameter is required")
+	}
+	if err := validateLargeOutputSQL(query); err != nil {
+		return "", err
+	}
+
+	maxRows := defaultLargeOutputSQLMaxRows
+	if val, ok := args["max_rows"].(float64); ok && val > 0 {
+		maxRows = int(val)
+	}
+
+	// Build file path
+	filePath := a.BuildLargeOutputFilePath(filename)
+	if filePath == "" {
+		return "", fmt.Errorf("invalid filename: %s", filename)
+	}
+
+	// Validate file path is within allowed directory
+	if a.toolOutputHandler != nil {
+		baseDir := a.toolOutputHandler.OutputFolder
+		if err := validateFilePath(filePath, baseDir); err != nil {
+			return "", fmt.Errorf("file path validation failed: %w", err)
+		}
+	}
+
+	content, err := a.toolOutputHandler.ReadOutput(ctx, filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read file %s: %w", filePath, err)
+	}
+	table, err := parseTabularOutput(content, filepath.Ext(filePath))
+	if err != nil {
+		return "", fmt.Errorf("cannot query %s with SQL: %w", filename, err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, largeOutputSQLTimeout)
+	defer cancel()
+	return table.query(ctx, query, maxRows)
+}
+
+// validateLargeOutputSQL accepts a single read-only statement
+func validateLargeOutputSQL(query string) error {
+	statement := strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(query), ";"))
+	if strings.Contains(statement, "\x00") {
+		return fmt.Errorf("invalid sql: contains null byte")
+	}
+	if strings.Contains(statement, ";") {
+		return fmt.Errorf("invalid sql: only a single statement is allowed")
+	}
+	fields := strings.Fields(statement)
+	if len(fields) == 0 {
+		return fmt.Errorf("sql parameter is required")
+	}
+	keyword := strings.ToUpper(fields[0])
+	if keyword != "SELECT" && keyword != "WITH" {
+		return fmt.Errorf("invalid sql: only SELECT queries are allowed (the rows are in table %q)", largeOutputSQLTable)
+	}
+	return nil
+}
+
+// tabularOutput is an offloaded output parsed into rows
+type tabularOutput struct {
+	columns []string
+	rows    [][]interface{}
+	numeric bool // columns get NUMERIC affinity (CSV cells are all text)
+}
+
+// parseTabularOutput parses JSON (array, object with one array field, JSON
+// lines) or CSV content
+func parseTabularOutput(content []byte, ext string) (*tabularOutput, error) {
+	trimmed := bytes.TrimSpace(content)
+	if len(trimmed) == 0 {
+		return nil, fmt.Errorf("output is empty")
+	}
+	switch trimmed[0] {
+	case '[':
+		var records []json.RawMessage
+		if err := json.Unmarshal(trimmed, &records); err != nil {
+			return nil, fmt.Errorf("invalid JSON array: %w", err)
+		}
+		return tabularFromJSON(records)
+	case '{':
+		var object map[string]json.RawMessage
+		if err := json.Unmarshal(trimmed, &object); err == nil {
+			var arrays []string
+			for key, value := range object {
+				if v := bytes.TrimSpace(value); len(v) > 0 && v[0] == '[' {
+					arrays = append(arrays, key)
+				}
+			}
+			if len(arrays) != 1 {
+				return nil, fmt.Errorf("JSON object must have exactly one array field holding the rows, found %d", len(arrays))
+			}
+			var records []json.RawMessage
+			if err := json.Unmarshal(object[arrays[0]], &records); err != nil {
+				return nil, fmt.Errorf("invalid JSON array %q: %w", arrays[0], err)
+			}
+			return tabularFromJSON(records)
+		}
+		var records []json.RawMessage
+		scanner := bufio.NewScanner(bytes.NewReader(trimmed))
+		scanner.Buffer(make([]byte, 0, 64*1024), len(trimmed)+1)
+		for scanner.Scan() {
+			if line := bytes.TrimSpace(scanner.Bytes()); len(line) > 0 {
+				records = append(records, json.RawMessage(append([]byte(nil), line...)))
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+		return tabularFromJSON(records)
+	default:
+		reader := csv.NewReader(bytes.NewReader(trimmed))
+		if strings.EqualFold(ext, ".tsv") {
+			reader.Comma = '\t'
+		}
+		reader.FieldsPerRecord = -1
+		records, err := reader.ReadAll()
+		if err != nil {
+			return nil, fmt.Errorf("output is neither JSON nor valid CSV: %w", err)
+		}
+		if len(records) < 2 || len(records[0]) < 2 {
+			return nil, fmt.Errorf("output is neither JSON nor CSV with a header row")
+		}
+		table := &tabularOutput{columns: records[0], numeric: true}
+		for _, record := range records[1:] {
+			row := make([]interface{}, len(table.columns))
+			for i := range row {
+				if i < len(record) {
+					row[i] = record[i]
+				}
+			}
+			table.rows = append(table.rows, row)
+		}
+		return table, nil
+	}
+}
+
+// tabularFromJSON turns JSON objects into rows, with the union of their keys
+// as columns in first-seen order
+func tabularFromJSON(records []json.RawMessage) (*tabularOutput, error) {
+	table := &tabularOutput{}
+	index := map[string]int{}
+	var objects []map[string]interface{}
+	for i, record := range records {
+		decoder := json.NewDecoder(bytes.NewReader(record))
+		decoder.UseNumber()
+		var object map[string]interface{}
+		if err := decoder.Decode(&object); err != nil || object == nil {
+			return nil, fmt.Errorf("row %d is not a JSON object", i+1)
+		}
+		for _, key := range orderedJSONKeys(record) {
+			if _, ok := index[key]; !ok {
+				index[key] = len(table.columns)
+				table.columns = append(table.columns, key)
+			}
+		}
+		objects = append(objects, object)
+	}
+	if len(table.columns) == 0 {
+		return nil, fmt.Errorf("no rows found")
+	}
+	for _, object := range objects {
+		row := make([]interface{}, len(table.columns))
+		for key, value := range object {
+			row[index[key]] = sqlValue(value)
+		}
+		table.rows = append(table.rows, row)
+	}
+	return table, nil
+}
+
+// orderedJSONKeys returns the top-level keys of a JSON object in document order
+func orderedJSONKeys(object json.RawMessage) []string {
+	decoder := json.NewDecoder(bytes.NewReader(object))
+	if _, err := decoder.Token(); err != nil {
+		return nil
+	}
+	var keys []string
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return keys
+		}
+		if key, ok := token.(string); ok {
+			keys = append(keys, key)
+		}
+		var skip json.RawMessage
+		if err := decoder.Decode(&skip); err != nil {
+			return keys
+		}
+	}
+	return keys
+}
+
+// sqlValue converts a decoded JSON value for SQLite: numbers stay numbers,
+// booleans become 0/1 and nested values JSON text
+func sqlValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case nil, string:
+		return v
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		if f, err := v.Float64(); err == nil {
+			return f
+		}
+		return v.String()
+	case bool:
+		if v {
+			return 1
+		}
+		return 0
+	default:
+		encoded, _ := json.Marshal(v)
+		return string(encoded)
+	}
+}
+
+// query loads the rows into an in-memory database and runs query, returning
+// at most maxRows rows as JSON lines
+func (t *tabularOutput) query(ctx context.Context, query string, maxRows int) (string, error) {
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		return "", fmt.Errorf("failed to open SQL engine: %w", err)
+	}
+	defer db.Close()
+	// Every connection to :memory: is a separate database
+	db.SetMaxOpenConns(1)
+
+	columns := make([]string, len(t.columns))
+	placeholders := make([]string, len(t.columns))
+	for i, column := range t.columns {
+		columns[i] = quoteSQLIdentifier(column)
+		if t.numeric {
+			columns[i] += " NUMERIC"
+		}
+		placeholders[i] = "?"
+	}
+	if _, err := db.ExecContext(ctx, fmt.Sprintf("CREATE TABLE %s (%s)", largeOutputSQLTable, strings.Join(columns, ", "))); err != nil {
+		return "", fmt.Errorf("failed to create table: %w", err)
+	}
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return "", err
+	}
+	insert, err := tx.PrepareContext(ctx, fmt.Sprintf("INSERT INTO %s VALUES (%s)", largeOutputSQLTable, strings.Join(placeholders, ", ")))
+	if err != nil {
+		_ = tx.Rollback()
+		return "", fmt.Errorf("failed to load rows: %w", err)
+	}
+	for _, row := range t.rows {
+		if _, err := insert.ExecContext(ctx, row...); err != nil {
+			_ = tx.Rollback()
+			return "", fmt.Errorf("failed to load rows: %w", err)
+		}
+	}
+	_ = insert.Close()
+	if err := tx.Commit(); err != nil {
+		return "", fmt.Errorf("failed to load rows: %w", err)
+	}
+
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return "", fmt.Errorf("sql query failed: %w (table %s has %d rows and columns: %s)",
+			err, largeOutputSQLTable, len(t.rows), strings.Join(t.columns, ", "))
+	}
+	defer rows.Close()
+	resultColumns, err := rows.Columns()
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	count := 0
+	for rows.Next() {
+		count++
+		if count > maxRows {
+			continue
+		}
+		values := make([]interface{}, len(resultColumns))
+		pointers := make([]interface{}, len(values))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+		if err := rows.Scan(pointers...); err != nil {
+			return "", err
+		}
+		// Keep the column order of the SELECT
+		b.WriteString("{")
+		for i, column := range resultColumns {
+			if i > 0 {
+				b.WriteString(",")
+			}
+			key, _ := json.Marshal(column)
+			value := values[i]
+			if raw, ok := value.([]byte); ok {
+				value = string(raw)
+			}
+			encoded, _ := json.Marshal(value)
+			b.Write(key)
+			b.WriteString(":")
+			b.Write(encoded)
+		}
+		b.WriteString("}\n")
+	}
+	if err := rows.Err(); err != nil {
+		return "", fmt.Errorf("sql query failed: %w", err)
+	}
+	if count == 0 {
+		return "No rows returned.", nil
+	}
+	if count > maxRows {
+		b.WriteString(fmt.Sprintf("[showing %d of %d rows; aggregate, filter or add LIMIT/OFFSET to see others]\n", maxRows, count))
+	}
+	return b.String(), nil
+}
+
+// quoteSQLIdentifier quotes a column name for SQLite
+func quoteSQLIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
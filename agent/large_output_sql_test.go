@@ -0,0 +1,84 @@
+package mcpagent
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSQLLargeOutputAggregatesRows(t *testing.T) {
+	ctx := context.Background()
+	handler := NewToolOutputHandlerWithConfig(DefaultLargeToolOutputThreshold, DefaultToolOutputFolder, "session-sql", true, true)
+	handler.Storage = NewMemoryOutputStorage()
+	a := &Agent{EnableContextOffloading: true, toolOutputHandler: handler}
+
+	var jsonRows, jsonLines []string
+	csvRows := []string{"region,amount"}
+	for i := 0; i < 1000; i++ {
+		status := []string{"open", "closed"}[i%2]
+		jsonRows = append(jsonRows, fmt.Sprintf(`{"id": %d, "status": %q, "meta": {"team": "t%d"}}`, i, status, i%3))
+		jsonLines = append(jsonLines, fmt.Sprintf(`{"id": %d, "ok": %t}`, i, i < 10))
+		csvRows = append(csvRows, fmt.Sprintf("r%d,%d", i%4, i))
+	}
+	outputs := map[string]string{
+		"array":  `{"total": 1000, "items": [` + strings.Join(jsonRows, ",") + `]}`,
+		"lines":  strings.Join(jsonLines, "\n"),
+		"report": strings.Join(csvRows, "\n"),
+	}
+	sqlQuery := func(tool, query string, maxRows int) (string, error) {
+		path, err := handler.WriteToolOutputToFile(outputs[tool], tool)
+		if err != nil {
+			t.Fatal(err)
+		}
+		args := map[string]interface{}{"operation": "sql", "filename": filepath.Base(path), "sql": query}
+		if maxRows > 0 {
+			args["max_rows"] = float64(maxRows)
+		}
+		return a.HandleLargeOutputVirtualTool(ctx, "search_large_output", args)
+	}
+
+	tests := []struct {
+		tool, sql string
+		maxRows   int
+		want      string
+	}{
+		{"array", "SELECT status, COUNT(*) AS n FROM output GROUP BY status ORDER BY status", 0,
+			"{\"status\":\"closed\",\"n\":500}\n{\"status\":\"open\",\"n\":500}\n"},
+		{"array", "SELECT json_extract(meta, '$.team') AS team FROM output WHERE id = 5", 0, "{\"team\":\"t2\"}\n"},
+		{"lines", "SELECT SUM(ok) AS ok FROM output;", 0, "{\"ok\":10}\n"},
+		{"report", "SELECT region, SUM(amount) AS total FROM output WHERE amount >= 996 GROUP BY region", 0,
+			"{\"region\":\"r0\",\"total\":996}\n{\"region\":\"r1\",\"total\":997}\n{\"region\":\"r2\",\"total\":998}\n{\"region\":\"r3\",\"total\":999}\n"},
+		{"report", "SELECT amount FROM output ORDER BY amount", 2,
+			"{\"amount\":0}\n{\"amount\":1}\n[showing 2 of 1000 rows; aggregate, filter or add LIMIT/OFFSET to see others]\n"},
+		{"report", "SELECT * FROM output WHERE amount > 5000", 0, "No rows returned."},
+	}
+	for _, tt := range tests {
+		got, err := sqlQuery(tt.tool, tt.sql, tt.maxRows)
+		if err != nil || got != tt.want {
+			t.Errorf("%s: %s\n got %q, %v\nwant %q", tt.tool, tt.sql, got, err, tt.want)
+		}
+	}
+
+	if _, err := sqlQuery("array", "SELECT missing FROM output", 0); err == nil || !strings.Contains(err.Error(), "columns: id, status, meta") {
+		t.Errorf("a failing query should list the columns, got %v", err)
+	}
+}
+
+func TestValidateLargeOutputSQL(t *testing.T) {
+	for _, query := range []string{
+		"SELECT 1", "  select * from output;", "WITH t AS (SELECT 1) SELECT * FROM t",
+	} {
+		if err := validateLargeOutputSQL(query); err != nil {
+			t.Errorf("%q should be allowed: %v", query, err)
+		}
+	}
+	for _, query := range []string{
+		"DROP TABLE output", "ATTACH DATABASE '/tmp/x.db' AS x", "SELECT 1; DELETE FROM output", "PRAGMA table_info(output)", ";",
+	} {
+		if err := validateLargeOutputSQL(query); err == nil {
+			t.Errorf("%q should be rejected", query)
+		}
+	}
+}
@@ -74,12 +74,12 @@ func (a *Agent) CreateLargeOutputVirtualTools() []llmtypes.Tool {
 
 	var virtualTools []llmtypes.Tool
 
-	// Unified search_large_output tool that supports read, search, and query operations
+	// Unified search_large_output tool that supports read, search, query, and sql operations
 	searchLargeOutputTool := llmtypes.Tool{
 		Type: "function",
 		Function: &llmtypes.FunctionDefinition{
 			Name:        "search_large_output",
-			Description: "Access offloaded tool output files through read, search, or query operations (context offloading). Use 'read' to read character ranges, 'search' for regex pattern matching, or 'query' for jq JSON queries.",
+			Description: "Access offloaded tool output files through read, search, query, or sql operations (context offloading). Use 'read' to read character ranges, 'search' for regex pattern matching, 'query' for jq JSON queries, or 'sql' to filter and aggregate JSON/CSV rows with SQL.",
 			Parameters: llmtypes.NewParameters(map[string]interface{}{
 				"type": "object",
 				"properties": map[string]interface{}{
@@ -89,8 +89,8 @@ func (a *Agent) CreateLargeOutputVirtualTools() []llmtypes.Tool {
 					},
 					"operation": map[string]interface{}{
 						"type":        "string",
-						"enum":        []string{"read", "search", "query"},
-						"description": "Operation type: 'read' for character range reading, 'search' for regex pattern matching, 'query' for jq JSON queries",
+						"enum":        []string{"read", "search", "query", "sql"},
+						"description": "Operation type: 'read' for character range reading, 'search' for regex pattern matching, 'query' for jq JSON queries, 'sql' for SQL over JSON/CSV rows",
 					},
 					// Parameters for operation="read"
 					"start": map[string]interface{}{
@@ -131,6 +131,16 @@ func (a *Agent) CreateLargeOutputVirtualTools() []llmtypes.Tool {
 						"description": "Output raw string values. Used when operation='query'",
 						"default":     false,
 					},
+					// Parameters for operation="sql"
+					"sql": map[string]interface{}{
+						"type":        "string",
+						"description": "SQLite SELECT over the rows, loaded into table 'output' with one column per JSON key or CSV header (e.g., 'SELECT status, COUNT(*) FROM output GROUP BY status'). Nested JSON values are JSON text (use json_extract). Required when operation='sql'",
+					},
+					"max_rows": map[string]interface{}{
+						"type":        "integer",
+						"description": "Maximum number of result rows to return. Used when operation='sql'",
+						"default":     100,
+					},
 				},
 				"required": []string{"filename", "operation"},
 			}),
@@ -164,11 +174,13 @@ func (a *Agent) HandleLargeOutputVirtualTool(ctx context.Context, toolName strin
 			return a.handleSearchLargeOutput(ctx, args)
 		case "query":
 			return a.handleQueryLargeOutput(ctx, args)
+		case "sql":
+			return a.handleSQLLargeOutput(ctx, args)
 		default:
-			return "", fmt.Errorf("invalid operation: %s. Must be 'read', 'search', or 'query'", operation)
+			return "", fmt.Errorf("invalid operation: %s. Must be 'read', 'search', 'query', or 'sql'", operation)
 		}
 	default:
-		return "", fmt.Errorf("unknown context offloading virtual tool: %s", toolName)
+		return "", fmt.Errorf("unknown context offloading virtual tool: %s: %w", toolName, ErrToolNotFound)
 	}
 }
 
@@ -215,9 +227,8 @@ func (a *Agent) handleReadLargeOutput(ctx context.Context, args map[string]inter
 		}
 	}
 
-	// Read file content
-	//nolint:gosec // G304: filePath is validated above to be within allowed directory
-	content, err := os.ReadFile(filePath)
+	// Read file content (from the output storage when one is configured)
+	content, err := a.toolOutputHandler.ReadOutput(ctx, filePath)
 	if err != nil {
 		return "", fmt.Errorf("failed to read file %s: %w", filePath, err)
 	}
@@ -277,8 +288,14 @@ func (a *Agent) handleSearchLargeOutput(ctx context.Context, args map[string]int
 		}
 	}
 
+	localPath, cleanup, err := a.toolOutputHandler.localOutputPath(ctx, filePath)
+	if err != nil {
+		return "", fmt.Errorf("tool output file not found: %s (file may have been cleaned up or the filename may be incorrect): %w", filePath, err)
+	}
+	defer cleanup()
+
 	// Search using ripgrep
-	results, err := a.searchWithRipgrep(filePath, pattern, maxResults, caseSensitive, false)
+	results, err := a.searchWithRipgrep(localPath, pattern, maxResults, caseSensitive, false)
 	if err != nil {
 		return "", fmt.Errorf("search failed: %w", err)
 	}
@@ -327,8 +344,14 @@ func (a *Agent) handleQueryLargeOutput(ctx context.Context, args map[string]inte
 		}
 	}
 
+	localPath, cleanup, err := a.toolOutputHandler.localOutputPath(ctx, filePath)
+	if err != nil {
+		return "", fmt.Errorf("tool output file not found: %s (file may have been cleaned up or the filename may be incorrect): %w", filePath, err)
+	}
+	defer cleanup()
+
 	// Execute jq query
-	result, err := a.executeJqQuery(filePath, query, compact, raw)
+	result, err := a.executeJqQuery(localPath, query, compact, raw)
 	if err != nil {
 		return "", fmt.Errorf("jq query failed: %w", err)
 	}
@@ -465,6 +465,9 @@ type streamingManager struct {
 	// Claude Code, Codex CLI). Used by AskWithHistory to reconstruct conversation history
 	// with tool calls that ran inside the CLI subprocess.
 	CLIToolCalls []llmtypes.StreamChunk
+	// structured parses the content of structured output calls into
+	// StreamingStructuredChunkEvents (see partial_json.go); nil otherwise
+	structured *structuredChunkStreamer
 	// streamDebugFile is an optional per-turn append-only log of every
 	// chunk.Content emitted by the adapter. Off by default; toggled on by
 	// MCP_AGENT_STREAM_DEBUG=1. Useful when you need to verify "did the
@@ -486,6 +489,9 @@ func (a *Agent) startStreaming(ctx context.Context, attempt int, turn int, opts
 		turn:           turn,
 		suppressEvents: a.SuppressGenerationStreamingEvents,
 	}
+	if currentCall(ctx).partialJSON {
+		sm.structured = &structuredChunkStreamer{}
+	}
 
 	// Per-session/turn raw-stream debug log. Reuses the LOG_AGENT_PROMPTS
 	// toggle and the existing logs/agent_prompts/<session>/ directory so
@@ -555,6 +561,11 @@ func (sm *streamingManager) processChunks(ctx context.Context, a *Agent) {
 						ChunkIndex:    sm.contentChunkIndex,
 						IsToolCall:    false,
 					})
+					if sm.structured != nil {
+						if event := sm.structured.write(chunk.Content); event != nil {
+							a.EmitTypedEvent(ctx, event)
+						}
+					}
 				}
 
 				if a.StreamingCallback != nil {
@@ -852,6 +863,14 @@ func (a *Agent) getEffectiveLLMConfig() AgentLLMConfiguration {
 		}
 	}
 
+	for _, model := range a.fallbackLLMs {
+		config.Fallbacks = append(config.Fallbacks, LLMModel{
+			Provider: string(extractProviderFromLLM(model)),
+			ModelID:  model.GetModelID(),
+			Model:    model,
+		})
+	}
+
 	// If no explicit fallbacks were provided, apply provider defaults.
 	// This keeps behavior aligned with older initialization paths that used
 	// default same-provider and cross-provider fallback env configuration.
@@ -870,6 +889,14 @@ func (a *Agent) getEffectiveLLMConfig() AgentLLMConfiguration {
 	return config
 }
 
+// fallbackPhase reports whether fallback is a same-provider or cross-provider fallback of primary
+func fallbackPhase(primary, fallback LLMModel) string {
+	if fallback.Provider == primary.Provider {
+		return "same_provider"
+	}
+	return "cross_provider"
+}
+
 func parseFallbackModelRef(primaryProvider, fallbackRef string) (LLMModel, bool) {
 	ref := strings.TrimSpace(fallbackRef)
 	if ref == "" {
@@ -904,7 +931,8 @@ func dedupeFallbacks(fallbacks []LLMModel) []LLMModel {
 	for _, fallback := range fallbacks {
 		provider := strings.TrimSpace(fallback.Provider)
 		modelID := strings.TrimSpace(fallback.ModelID)
-		if provider == "" || modelID == "" {
+		// An instance does not need a provider to be initialized
+		if (provider == "" && fallback.Model == nil) || modelID == "" {
 			continue
 		}
 
@@ -924,7 +952,40 @@ func dedupeFallbacks(fallbacks []LLMModel) []LLMModel {
 
 // executeLLM creates an LLM instance and executes it.
 func (a *Agent) executeLLM(ctx context.Context, model LLMModel, messages []llmtypes.MessageContent, opts []llmtypes.CallOption) (*llmtypes.ContentResponse, error) {
-	return a.executeLLMInner(ctx, model, messages, opts, false)
+	if a.recorder != nil && a.recorder.replay {
+		return a.replayLLM(ctx, messages, opts)
+	}
+	if err := a.chaosBeforeLLM(model); err != nil {
+		return nil, err
+	}
+	resp, err := a.executeLLMInner(a.withRawLLMLogging(ctx, model), model, messages, opts, false)
+	if err == nil {
+		a.chaosAfterLLM(resp)
+		a.recordLLMCall(model, messages, resp)
+	}
+	return resp, err
+}
+
+// withRateLimitWaitEvents emits a RateLimitWait event whenever the call waits
+// for the provider's process-wide rate limit (see llm.RateLimit)
+func (a *Agent) withRateLimitWaitEvents(ctx context.Context, turn int) context.Context {
+	return llm.WithRateLimitWaitHandler(ctx, func(wait llm.RateLimitWait) {
+		a.Logger.Info("⏳ [RATE_LIMIT] Waiting for provider rate limit",
+			loggerv2.String("provider", string(wait.Provider)),
+			loggerv2.String("model", wait.ModelID),
+			loggerv2.String("reason", wait.Reason),
+			loggerv2.String("wait", wait.Wait.String()),
+			loggerv2.Int("queued", wait.Queued))
+		a.EmitTypedEvent(ctx, &events.RateLimitWaitEvent{
+			BaseEventData: events.BaseEventData{Timestamp: time.Now()},
+			Turn:          turn,
+			ModelID:       wait.ModelID,
+			Provider:      string(wait.Provider),
+			Reason:        wait.Reason,
+			Wait:          wait.Wait.String(),
+			Queued:        wait.Queued,
+		})
+	})
 }
 
 func (a *Agent) executeLLMForCodingAgentTransportLaunch(ctx context.Context, model LLMModel, opts []llmtypes.CallOption) (*llmtypes.ContentResponse, error) {
@@ -1016,19 +1077,23 @@ func (a *Agent) executeLLMInner(ctx context.Context, model LLMModel, messages []
 	modelProvider := llm.Provider(model.Provider)
 	opts = a.appendCodingAgentInteractiveOptionsForProvider(opts, modelProvider, model.ModelID)
 
-	llmInstance, err := llm.InitializeLLM(llm.Config{
-		Provider:            modelProvider,
-		ModelID:             model.ModelID,
-		Temperature:         temperature,
-		Logger:              a.Logger,
-		APIKeys:             apiKeys,
-		Tracers:             a.Tracers,
-		TraceID:             a.TraceID,
-		Context:             ctx,
-		ClaudeCodeTransport: a.ClaudeCodeTransport,
-	})
-	if err != nil {
-		return nil, fmt.Errorf("failed to initialize LLM: %w", err)
+	llmInstance := model.Model
+	if llmInstance == nil {
+		var err error
+		llmInstance, err = llm.InitializeLLM(llm.Config{
+			Provider:            modelProvider,
+			ModelID:             model.ModelID,
+			Temperature:         temperature,
+			Logger:              a.Logger,
+			APIKeys:             apiKeys,
+			Tracers:             a.Tracers,
+			TraceID:             a.TraceID,
+			Context:             ctx,
+			ClaudeCodeTransport: a.ClaudeCodeTransport,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize LLM: %w", err)
+		}
 	}
 
 	if appender, ok := codingAgentIntegrationAppenders[llmproviders.Provider(model.Provider)]; ok {
@@ -1215,6 +1280,9 @@ func GenerateContentWithRetry(a *Agent, ctx context.Context, messages []llmtypes
 	}
 	maxDelay := time.Duration(maxDelaySeconds) * time.Second
 	var lastErr error
+	var lastErrorType string
+	var failedModels []string
+	sameProviderAttempts, crossProviderAttempts := 0, 0
 	var usage observability.UsageMetrics
 
 	// Get effective configuration (supports new and legacy)
@@ -1254,9 +1322,16 @@ func GenerateContentWithRetry(a *Agent, ctx context.Context, messages []llmtypes
 	// Iterate through models
 	for modelIndex, model := range modelsToTry {
 		isFallback := modelIndex > 0
+		phase := fallbackPhase(llmConfig.Primary, model)
 		if isFallback {
 			logger.Info(fmt.Sprintf("🔄 Trying fallback %d/%d: %s/%s",
 				modelIndex, len(llmConfig.Fallbacks), model.Provider, model.ModelID))
+			a.EmitTypedEvent(ctx, events.NewFallbackAttemptDetailEvent(turn, modelIndex, len(modelsToTry)-1, model.ModelID, model.Provider, phase, lastErrorType))
+			if phase == "same_provider" {
+				sameProviderAttempts++
+			} else {
+				crossProviderAttempts++
+			}
 
 			// Emit fallback model used event
 			fallbackEvent := events.NewFallbackModelUsedEvent(turn, llmConfig.Primary.ModelID, model.ModelID, model.Provider, "fallback_chain", time.Since(generationStartTime))
@@ -1276,9 +1351,18 @@ func GenerateContentWithRetry(a *Agent, ctx context.Context, messages []llmtypes
 				return nil, usage, a.handleContextCancellation(ctx, turn, generationStartTime)
 			}
 
+			// Turn pacing: hold the call for the minimum interval or a pending throttle
+			if _, err := a.pacer.wait(ctx, model.Provider+"/"+model.ModelID, currentCall(ctx).currentPriority()); err != nil {
+				return nil, usage, a.handleContextCancellation(ctx, turn, generationStartTime)
+			}
+
 			// Create a copy of options for this attempt
 			currentOpts := make([]llmtypes.CallOption, len(opts))
 			copy(currentOpts, opts)
+			if isFallback {
+				// opts carry the reasoning options of the primary model
+				currentOpts = append(currentOpts, a.fallbackReasoningOption(model))
+			}
 
 			// Start streaming (only on first attempt of primary model, or maybe disable for fallbacks?)
 			// Original logic: streaming enabled for primary, disabled for fallbacks in loop
@@ -1295,7 +1379,7 @@ func GenerateContentWithRetry(a *Agent, ctx context.Context, messages []llmtypes
 			sm := a.startStreaming(ctx, attempt, turn, &currentOpts)
 
 			// Execute LLM
-			resp, err := a.executeLLM(ctx, model, messages, currentOpts)
+			resp, err := a.executeLLM(a.withRateLimitWaitEvents(ctx, turn), model, messages, currentOpts)
 
 			a.finishStreaming(ctx, sm, resp)
 
@@ -1317,6 +1401,7 @@ func GenerateContentWithRetry(a *Agent, ctx context.Context, messages []llmtypes
 			}
 
 			if err == nil {
+				a.pacer.recordSuccess()
 				usage = extractUsageMetricsWithMessages(resp, messages)
 
 				if isFallback {
@@ -1327,6 +1412,7 @@ func GenerateContentWithRetry(a *Agent, ctx context.Context, messages []llmtypes
 						true, time.Since(generationStartTime), "",
 					)
 					a.EmitTypedEvent(ctx, fallbackAttemptEvent)
+					a.EmitTypedEvent(ctx, events.NewFallbackSuccessDetailEvent(turn, model.ModelID, model.Provider, phase, lastErrorType, modelIndex+1, time.Since(generationStartTime)))
 
 					// Emit model change event to track the permanent model change
 					modelChangeEvent := events.NewModelChangeEvent(turn, llmConfig.Primary.ModelID, model.ModelID, "fallback_success", model.Provider, time.Since(generationStartTime))
@@ -1362,12 +1448,31 @@ func GenerateContentWithRetry(a *Agent, ctx context.Context, messages []llmtypes
 			}
 
 			errorType := classifyLLMError(err)
-			lastErr = err
+			lastErr, lastErrorType = err, errorType
 
 			// Special handling for retrying SAME model (throttling/zero candidates/internal errors)
 			// For zero_candidates errors: limit to 3 retries before fallback
 			// For throttling/internal errors: use full 5 retries
 			shouldRetrySameModel := false
+			if errorType == "throttling_error" && attempt < maxRetries-1 {
+				// With turn pacing, a short throttle is waited out on the same
+				// model (the wait happens in pacer.wait) instead of failing over
+				if hold, ok := a.pacer.absorbThrottle(model.Provider+"/"+model.ModelID, err); ok {
+					logger.Info(fmt.Sprintf("⏱️ [PACING] Throttled on %s/%s; pacing the retry by %v (attempt %d/%d)", model.Provider, model.ModelID, hold, attempt+1, maxRetries))
+					a.EmitTypedEvent(ctx, &events.ThrottlingDetectedEvent{
+						BaseEventData: events.BaseEventData{Timestamp: time.Now()},
+						Turn:          turn,
+						ModelID:       model.ModelID,
+						Provider:      model.Provider,
+						Attempt:       attempt + 1,
+						MaxAttempts:   maxRetries,
+						Duration:      time.Since(generationStartTime).String(),
+						ErrorType:     "throttling",
+						RetryDelay:    hold.String(),
+					})
+					continue
+				}
+			}
 			if shouldSkipSameModelRetry(model.Provider, errorType) {
 				logger.Info(fmt.Sprintf("⏭️ [FAST_FALLBACK] Skipping same-model retry for %s/%s on %s; moving directly to fallback chain",
 					model.Provider, model.ModelID, errorType))
@@ -1457,9 +1562,18 @@ func GenerateContentWithRetry(a *Agent, ctx context.Context, messages []llmtypes
 
 			break // Break retry loop, proceed to next model
 		}
+
+		// Success returns above: this model failed
+		failedModels = append(failedModels, model.Provider+"/"+model.ModelID)
+		if isFallback && lastErr != nil {
+			a.EmitTypedEvent(ctx, events.NewFallbackFailureDetailEvent(turn, model.ModelID, model.Provider, phase, "generation", lastErrorType, lastErr.Error(), time.Since(generationStartTime)))
+		}
 	}
 
 	// If all models failed
+	if len(modelsToTry) > 1 && lastErr != nil {
+		a.EmitTypedEvent(ctx, events.NewAllFallbacksFailedEvent(turn, lastErrorType, sameProviderAttempts, crossProviderAttempts, failedModels, lastErr.Error()))
+	}
 	return nil, usage, fmt.Errorf("all LLMs failed (primary + %d fallbacks): %w", len(llmConfig.Fallbacks), lastErr)
 }
 
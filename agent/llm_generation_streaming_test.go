@@ -2,6 +2,7 @@ package mcpagent
 
 import (
 	"context"
+	"sync"
 	"testing"
 	"time"
 
@@ -11,10 +12,13 @@ import (
 )
 
 type recordingAgentEventListener struct {
+	mu     sync.Mutex
 	events []*events.AgentEvent
 }
 
 func (l *recordingAgentEventListener) HandleEvent(_ context.Context, event *events.AgentEvent) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
 	l.events = append(l.events, event)
 	return nil
 }
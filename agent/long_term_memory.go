@@ -0,0 +1,440 @@
+// long_term_memory.go
+//
+// This file provides long-term memory across conversations. Before each
+// conversation the agent searches the configured Memory store with the user's
+// question and injects the most relevant facts into the system prompt. After a
+// successful conversation the agent's LLM extracts durable facts (user
+// preferences, decisions, stable facts about the environment) in the
+// background and saves them to the store, so the extraction does not delay the
+// answer; Close waits for pending extractions. Facts belong to the agent's
+// user (WithUserID) and are only recalled for that user. Both steps emit
+// events (memory_read, memory_write); store and extraction errors are logged
+// and never fail the conversation.
+//
+// Exported:
+//   - Memory: Pluggable store interface (vector store, key-value store, ...)
+//   - MemoryFact: A remembered fact
+//   - InMemoryMemory, NewInMemoryMemory: Built-in process-local store
+//   - WithMemory: Enable long-term memory when creating an agent
+
+package mcpagent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/manishiitg/mcpagent/events"
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+// DefaultMemoryTopK is the number of facts recalled when WithMemory is given topK <= 0
+const DefaultMemoryTopK = 5
+
+// maxMemoryTranscriptChars caps the conversation sent to fact extraction,
+// keeping the most recent part
+const maxMemoryTranscriptChars = 40000
+
+// MemoryFact is a fact remembered across conversations
+type MemoryFact struct {
+	// ID identifies the fact in the store; stores assign one when empty
+	ID   string
+	Text string
+	// UserID owns the fact (the agent's WithUserID; empty = no user)
+	UserID string
+	// Metadata is free-form (the agent sets "session_id" and "model")
+	Metadata map[string]string
+	// Score is the relevance to the query, set by Search
+	Score     float64
+	CreatedAt time.Time
+}
+
+// Memory is a long-term memory store. Implementations may be backed by a
+// vector database (semantic search) or a key-value store (keyword search) and
+// must be safe for concurrent use. Stores serving several users should also
+// implement UserDataStore so DeleteUserData can erase a user's facts.
+type Memory interface {
+	// Search returns up to topK facts of userID relevant to query, most
+	// relevant first; facts of other users are never returned
+	Search(ctx context.Context, userID, query string, topK int) ([]MemoryFact, error)
+	// Save stores new facts under their UserID
+	Save(ctx context.Context, facts []MemoryFact) error
+}
+
+// WithMemory gives the agent long-term memory: the topK facts most relevant to
+// the question are injected into the system prompt, and durable facts from
+// each successful conversation are saved to store.
+//
+// Example:
+//
+//	store := mcpagent.NewInMemoryMemory(embedTexts)
+//	agent, err := mcpagent.NewAgent(ctx, llm, configPath, mcpagent.WithMemory(store, 5))
+//
+// Default: disabled (topK <= 0 = DefaultMemoryTopK)
+func WithMemory(store Memory, topK int) AgentOption {
+	return func(a *Agent) {
+		if topK <= 0 {
+			topK = DefaultMemoryTopK
+		}
+		a.memory = store
+		a.memoryTopK = topK
+	}
+}
+
+// EmbedFunc returns one embedding vector per text
+type EmbedFunc func(ctx context.Context, texts []string) ([][]float32, error)
+
+// InMemoryMemory is a process-local Memory and UserDataStore. With an
+// EmbedFunc it ranks facts by cosine similarity of their embeddings; without
+// one it ranks them by keyword overlap with the query.
+type InMemoryMemory struct {
+	embed EmbedFunc
+
+	mu      sync.RWMutex
+	facts   []MemoryFact
+	vectors [][]float32
+	nextID  int
+}
+
+// NewInMemoryMemory returns an empty in-memory store (embed may be nil)
+func NewInMemoryMemory(embed EmbedFunc) *InMemoryMemory {
+	return &InMemoryMemory{embed: embed}
+}
+
+// Save implements Memory
+func (m *InMemoryMemory) Save(ctx context.Context, facts []MemoryFact) error {
+	if len(facts) == 0 {
+		return nil
+	}
+	var vectors [][]float32
+	if m.embed != nil {
+		texts := make([]string, len(facts))
+		for i, fact := range facts {
+			texts[i] = fact.Text
+		}
+		var err error
+		if vectors, err = m.embed(ctx, texts); err != nil {
+			return fmt.Errorf("embed facts: %w", err)
+		}
+		if len(vectors) != len(facts) {
+			return fmt.Errorf("embed facts: got %d vectors for %d facts", len(vectors), len(facts))
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, fact := range facts {
+		if fact.ID == "" {
+			m.nextID++
+			fact.ID = fmt.Sprintf("mem_%d", m.nextID)
+		}
+		if fact.CreatedAt.IsZero() {
+			fact.CreatedAt = time.Now()
+		}
+		fact.Score = 0
+		m.facts = append(m.facts, fact)
+		if vectors != nil {
+			m.vectors = append(m.vectors, vectors[i])
+		} else {
+			m.vectors = append(m.vectors, nil)
+		}
+	}
+	return nil
+}
+
+// Search implements Memory
+func (m *InMemoryMemory) Search(ctx context.Context, userID, query string, topK int) ([]MemoryFact, error) {
+	var queryVector []float32
+	if m.embed != nil {
+		vectors, err := m.embed(ctx, []string{query})
+		if err != nil {
+			return nil, fmt.Errorf("embed query: %w", err)
+		}
+		if len(vectors) != 1 {
+			return nil, fmt.Errorf("embed query: got %d vectors", len(vectors))
+		}
+		queryVector = vectors[0]
+	}
+	queryWords := memoryKeywords(query)
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	results := make([]MemoryFact, 0, len(m.facts))
+	for i, fact := range m.facts {
+		if fact.UserID != userID {
+			continue
+		}
+		if queryVector != nil && m.vectors[i] != nil {
+			fact.Score = cosineSimilarity(queryVector, m.vectors[i])
+		} else {
+			fact.Score = keywordOverlap(queryWords, memoryKeywords(fact.Text))
+		}
+		if fact.Score > 0 {
+			results = append(results, fact)
+		}
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].CreatedAt.After(results[j].CreatedAt)
+	})
+	if topK > 0 && len(results) > topK {
+		results = results[:topK]
+	}
+	return results, nil
+}
+
+// DeleteUserData implements UserDataStore: it removes the facts of userID
+func (m *InMemoryMemory) DeleteUserData(_ context.Context, userID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	facts, vectors := m.facts[:0], m.vectors[:0]
+	for i, fact := range m.facts {
+		if fact.UserID != userID {
+			facts = append(facts, fact)
+			vectors = append(vectors, m.vectors[i])
+		}
+	}
+	clear(m.facts[len(facts):])
+	clear(m.vectors[len(vectors):])
+	m.facts, m.vectors = facts, vectors
+	return nil
+}
+
+// Facts returns all stored facts in save order
+func (m *InMemoryMemory) Facts() []MemoryFact {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return append([]MemoryFact(nil), m.facts...)
+}
+
+// memoryStopwords are ignored by keyword search
+var memoryStopwords = map[string]bool{
+	"the": true, "and": true, "for": true, "with": true, "you": true, "are": true, "was": true,
+	"this": true, "that": true, "from": true, "what": true, "how": true, "who": true, "can": true,
+	"does": true, "have": true, "has": true, "not": true, "but": true, "all": true, "any": true,
+	"user": true, "users": true,
+}
+
+// memoryKeywords returns the lowercase words of text longer than two
+// characters, without stopwords
+func memoryKeywords(text string) map[string]bool {
+	words := make(map[string]bool)
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if len(word) > 2 && !memoryStopwords[word] {
+			words[word] = true
+		}
+	}
+	return words
+}
+
+// keywordOverlap is the share of query words found in the fact
+func keywordOverlap(query, fact map[string]bool) float64 {
+	if len(query) == 0 {
+		return 0
+	}
+	shared := 0
+	for word := range query {
+		if fact[word] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(query))
+}
+
+func cosineSimilarity(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+// recallMemory searches the store with the conversation's question and keeps
+// the facts for the system prompt of this call
+func (a *Agent) recallMemory(ctx context.Context, messages []llmtypes.MessageContent) {
+	call := currentCall(ctx)
+	call.memoryFacts = nil
+	if a.memory == nil {
+		return
+	}
+	query := lastUserText(messages)
+	if strings.TrimSpace(query) == "" {
+		return
+	}
+	startTime := time.Now()
+	facts, err := a.memory.Search(ctx, a.UserID, query, a.memoryTopK)
+	if len(facts) > a.memoryTopK {
+		facts = facts[:a.memoryTopK]
+	}
+	event := &events.MemoryReadEvent{
+		BaseEventData: events.BaseEventData{Timestamp: time.Now()},
+		Query:         query,
+		TopK:          a.memoryTopK,
+		Duration:      time.Since(startTime),
+	}
+	if err != nil {
+		event.Error = err.Error()
+		getLogger(a).Warn("🧠 [MEMORY] Search failed", loggerv2.Error(err))
+	} else {
+		call.memoryFacts = facts
+		for _, fact := range facts {
+			event.FactIDs = append(event.FactIDs, fact.ID)
+			event.Facts = append(event.Facts, fact.Text)
+		}
+		getLogger(a).Info("🧠 [MEMORY] Recalled facts", loggerv2.Int("facts", len(facts)))
+	}
+	a.EmitTypedEvent(ctx, event)
+}
+
+// memoryPromptSection returns the facts recalled for the call of ctx
+func (a *Agent) memoryPromptSection(ctx context.Context) string {
+	facts := currentCall(ctx).memoryFacts
+	if len(facts) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("\n## Memory\n\nFacts remembered from earlier conversations, most relevant first. Use them when they help; the current conversation and tool results take precedence if they conflict.\n\n")
+	for _, fact := range facts {
+		b.WriteString("- " + fact.Text + "\n")
+	}
+	return b.String()
+}
+
+// memoryExtractionPrompt instructs the LLM to extract durable facts
+const memoryExtractionPrompt = `You maintain the long-term memory of an AI assistant.
+
+From the conversation, extract the facts worth remembering in future conversations: user preferences, decisions, goals, names, and stable facts about the user's projects and environment. Skip transient details, tool output that will go stale, small talk, and facts already in memory. Each fact must be one self-contained sentence.
+
+Respond with ONLY a JSON object, without markdown formatting:
+{"facts": ["<fact>", ...]}
+
+Return {"facts": []} if nothing is worth remembering.`
+
+// rememberConversation extracts durable facts from a finished conversation
+// and saves them to the store in the background; waitMemoryWrites waits for it
+func (a *Agent) rememberConversation(ctx context.Context, messages []llmtypes.MessageContent) {
+	if a.memory == nil {
+		return
+	}
+	// The caller may cancel ctx as soon as the answer is returned
+	ctx = context.WithoutCancel(ctx)
+	messages = append([]llmtypes.MessageContent(nil), messages...)
+	a.memoryWrites.Add(1)
+	go func() {
+		defer a.memoryWrites.Done()
+		a.saveMemoryFacts(ctx, messages)
+	}()
+}
+
+// waitMemoryWrites waits for the fact extractions still running
+func (a *Agent) waitMemoryWrites() {
+	a.memoryWrites.Wait()
+}
+
+// saveMemoryFacts extracts the durable facts of messages and saves them
+func (a *Agent) saveMemoryFacts(ctx context.Context, messages []llmtypes.MessageContent) {
+	startTime := time.Now()
+	event := &events.MemoryWriteEvent{}
+	facts, err := a.extractMemoryFacts(ctx, messages)
+	if err == nil && len(facts) > 0 {
+		err = a.memory.Save(ctx, facts)
+	}
+	event.Timestamp = time.Now()
+	event.Duration = time.Since(startTime)
+	logger := getLogger(a)
+	if err != nil {
+		event.Error = err.Error()
+		logger.Warn("🧠 [MEMORY] Failed to save facts", loggerv2.Error(err))
+	} else {
+		for _, fact := range facts {
+			event.Facts = append(event.Facts, fact.Text)
+		}
+		logger.Info("🧠 [MEMORY] Saved facts", loggerv2.Int("facts", len(facts)))
+	}
+	a.EmitTypedEvent(ctx, event)
+}
+
+// extractMemoryFacts asks the agent's LLM for the durable facts of messages
+func (a *Agent) extractMemoryFacts(ctx context.Context, messages []llmtypes.MessageContent) ([]MemoryFact, error) {
+	transcript := buildConversationTextForSummarization(messages)
+	if len(transcript) > maxMemoryTranscriptChars {
+		transcript = "[earlier conversation truncated]\n\n" + transcript[len(transcript)-maxMemoryTranscriptChars:]
+	}
+	if strings.TrimSpace(transcript) == "" {
+		return nil, nil
+	}
+	var known strings.Builder
+	for _, fact := range currentCall(ctx).memoryFacts {
+		known.WriteString("- " + fact.Text + "\n")
+	}
+	if known.Len() == 0 {
+		known.WriteString("(none)\n")
+	}
+
+	extractMessages := []llmtypes.MessageContent{
+		llmtypes.TextParts(llmtypes.ChatMessageTypeSystem, memoryExtractionPrompt),
+		llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "Already in memory:\n"+known.String()+"\nConversation:\n\n"+transcript),
+	}
+	resp, err := a.LLM.GenerateContent(ctx, extractMessages, llmtypes.WithTemperature(0), llmtypes.WithJSONMode())
+	if err != nil {
+		return nil, fmt.Errorf("extract facts: %w", err)
+	}
+	if resp == nil || len(resp.Choices) == 0 || resp.Choices[0] == nil {
+		return nil, fmt.Errorf("extract facts: no response")
+	}
+	texts, err := parseMemoryFacts(resp.Choices[0].Content)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	facts := make([]MemoryFact, 0, len(texts))
+	for _, text := range texts {
+		facts = append(facts, MemoryFact{
+			Text:      text,
+			UserID:    a.UserID,
+			Metadata:  map[string]string{"session_id": a.SessionID, "model": a.ModelID},
+			CreatedAt: now,
+		})
+	}
+	return facts, nil
+}
+
+// parseMemoryFacts extracts the fact texts from the extraction response
+func parseMemoryFacts(content string) ([]string, error) {
+	start, end := strings.Index(content, "{"), strings.LastIndex(content, "}")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("fact extraction response is not JSON")
+	}
+	var parsed struct {
+		Facts []string `json:"facts"`
+	}
+	if err := json.Unmarshal([]byte(content[start:end+1]), &parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse fact extraction response: %w", err)
+	}
+	texts := make([]string, 0, len(parsed.Facts))
+	for _, text := range parsed.Facts {
+		if text = strings.TrimSpace(text); text != "" {
+			texts = append(texts, text)
+		}
+	}
+	return texts, nil
+}
@@ -0,0 +1,142 @@
+package mcpagent
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/manishiitg/mcpagent/events"
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+	"github.com/manishiitg/mcpagent/observability"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+// systemPromptRecordingStage answers immediately and records the system prompt it saw
+type systemPromptRecordingStage struct {
+	systemPrompts []string
+}
+
+func (s *systemPromptRecordingStage) Generate(_ context.Context, _ *Agent, messages []llmtypes.MessageContent, _ []llmtypes.CallOption, _ int) (*llmtypes.ContentResponse, observability.UsageMetrics, error) {
+	s.systemPrompts = append(s.systemPrompts, messages[0].Parts[0].(llmtypes.TextContent).Text)
+	return &llmtypes.ContentResponse{Choices: []*llmtypes.ContentChoice{{Content: "Done."}}}, observability.UsageMetrics{}, nil
+}
+
+func TestInMemoryMemoryRanksByKeywordsOrEmbeddings(t *testing.T) {
+	ctx := context.Background()
+	store := NewInMemoryMemory(nil)
+	if err := store.Save(ctx, []MemoryFact{
+		{Text: "The user deploys with Terraform on AWS."},
+		{Text: "The user prefers answers in German."},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	facts, err := store.Search(ctx, "", "How do I use Terraform here?", 5)
+	if err != nil || len(facts) != 1 || facts[0].ID != "mem_1" {
+		t.Fatalf("keyword Search() = %+v, %v", facts, err)
+	}
+
+	// Two-dimensional embeddings: "deploy" facts point along x, the rest along y
+	embed := func(_ context.Context, texts []string) ([][]float32, error) {
+		vectors := make([][]float32, len(texts))
+		for i, text := range texts {
+			if strings.Contains(strings.ToLower(text), "deploy") || strings.Contains(text, "ship") {
+				vectors[i] = []float32{1, 0.1}
+			} else {
+				vectors[i] = []float32{0.1, 1}
+			}
+		}
+		return vectors, nil
+	}
+	store = NewInMemoryMemory(embed)
+	_ = store.Save(ctx, []MemoryFact{{Text: "The user prefers answers in German."}, {Text: "The user deploys with Terraform."}})
+	facts, err = store.Search(ctx, "", "how do we ship it", 1)
+	if err != nil || len(facts) != 1 || facts[0].Text != "The user deploys with Terraform." {
+		t.Fatalf("embedding Search() = %+v, %v", facts, err)
+	}
+}
+
+func TestWithMemoryInjectsAndSavesFacts(t *testing.T) {
+	store := NewInMemoryMemory(nil)
+	_ = store.Save(context.Background(), []MemoryFact{{Text: "The user's production cluster is called atlas."}})
+	extractor := &scriptedCheckerModel{responses: []string{`{"facts": ["The user wants weekly cluster reports.", " "]}`}}
+	generate := &systemPromptRecordingStage{}
+	listener := &recordingAgentEventListener{}
+
+	a := &Agent{Logger: loggerv2.NewNoop(), LLM: extractor, ModelID: "test-model", SessionID: "s1", MaxTurns: 3}
+	a.AddEventListener(listener)
+	WithAskPipeline(AskPipeline{Generate: generate})(a)
+	WithMemory(store, 0)(a)
+
+	if _, _, err := AskWithHistory(a, context.Background(), []llmtypes.MessageContent{
+		llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "Send me a weekly report on the production cluster"),
+	}); err != nil {
+		t.Fatalf("AskWithHistory: %v", err)
+	}
+	a.waitMemoryWrites()
+
+	if len(generate.systemPrompts) != 1 || !strings.Contains(generate.systemPrompts[0], "- The user's production cluster is called atlas.") {
+		t.Errorf("system prompt = %q, want the recalled fact", generate.systemPrompts)
+	}
+	if len(extractor.prompts) != 1 || !strings.Contains(extractor.prompts[0], "Already in memory:\n- The user's production cluster is called atlas.") {
+		t.Errorf("extraction prompt = %q, want the recalled facts listed", extractor.prompts)
+	}
+	facts := store.Facts()
+	if len(facts) != 2 || facts[1].Text != "The user wants weekly cluster reports." || facts[1].Metadata["session_id"] != "s1" {
+		t.Errorf("stored facts = %+v, want the extracted fact saved", facts)
+	}
+	if len(a.activeCalls) != 0 || a.memoryPromptSection(context.Background()) != "" {
+		t.Error("recalled facts outlived the call")
+	}
+
+	var read *events.MemoryReadEvent
+	var write *events.MemoryWriteEvent
+	for _, event := range listener.events {
+		switch data := event.Data.(type) {
+		case *events.MemoryReadEvent:
+			read = data
+		case *events.MemoryWriteEvent:
+			write = data
+		}
+	}
+	if read == nil || read.TopK != DefaultMemoryTopK || len(read.FactIDs) != 1 {
+		t.Errorf("memory_read = %+v", read)
+	}
+	if write == nil || len(write.Facts) != 1 || write.Error != "" {
+		t.Errorf("memory_write = %+v", write)
+	}
+}
+
+func TestMemoryFactsAreScopedByUser(t *testing.T) {
+	ctx := context.Background()
+	store := NewInMemoryMemory(nil)
+	_ = store.Save(ctx, []MemoryFact{
+		{Text: "The production cluster is called atlas.", UserID: "alice"},
+		{Text: "The production cluster is called zeus.", UserID: "bob"},
+	})
+	extractor := &scriptedCheckerModel{responses: []string{`{"facts": ["Bob wants cluster reports on Mondays."]}`}}
+	generate := &systemPromptRecordingStage{}
+	a := &Agent{Logger: loggerv2.NewNoop(), LLM: extractor, ModelID: "test-model", UserID: "bob", MaxTurns: 3}
+	WithAskPipeline(AskPipeline{Generate: generate})(a)
+	WithMemory(store, 5)(a)
+
+	if _, _, err := AskWithHistory(a, ctx, []llmtypes.MessageContent{
+		llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "Report on the production cluster"),
+	}); err != nil {
+		t.Fatalf("AskWithHistory: %v", err)
+	}
+	a.Close()
+
+	if prompt := generate.systemPrompts[0]; !strings.Contains(prompt, "zeus") || strings.Contains(prompt, "atlas") {
+		t.Errorf("system prompt = %q, want only bob's facts", prompt)
+	}
+	if facts, _ := store.Search(ctx, "bob", "cluster reports", 5); len(facts) != 2 || facts[0].UserID != "bob" {
+		t.Errorf("bob's facts = %+v, want the extracted fact saved for bob", facts)
+	}
+
+	if err := DeleteUserData(ctx, "bob", store); err != nil {
+		t.Fatal(err)
+	}
+	if facts := store.Facts(); len(facts) != 1 || facts[0].UserID != "alice" {
+		t.Errorf("facts after deleting bob = %+v, want only alice's", facts)
+	}
+}
@@ -0,0 +1,92 @@
+// mcp_connection_state.go
+//
+// This file forwards the connection state changes of the agent's MCP clients
+// as mcp_server_connection_state events. Remote (SSE and streamable HTTP)
+// clients reconnect on their own when a server restarts (see
+// mcpclient/remote_connection.go) and crashed stdio servers are restarted
+// (mcpclient/supervisor.go, also emitting mcp_server_restarted); the events
+// let UIs and operators see a server drop, the reconnect attempts and
+// whether the session was resumed.
+// The agent subscribes whenever it stores a client in Clients and
+// unsubscribes on Close, since clients are shared with other agents through
+// the session registry.
+
+package mcpagent
+
+import (
+	"context"
+
+	"github.com/manishiitg/mcpagent/events"
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+	"github.com/manishiitg/mcpagent/mcpclient"
+)
+
+// connectionStateSource is implemented by clients that report connection
+// state changes (*mcpclient.Client)
+type connectionStateSource interface {
+	AddConnectionStateListener(listener func(mcpclient.ConnectionStateChange)) (remove func())
+}
+
+// watchConnectionStates replaces the agent's subscriptions with ones for clients
+func (a *Agent) watchConnectionStates(clients map[string]mcpclient.ClientInterface) {
+	a.unwatchConnectionStates()
+	for serverName, client := range clients {
+		a.watchConnectionState(serverName, client)
+	}
+}
+
+// watchConnectionState subscribes to the connection state of the client of
+// serverName, replacing the subscription to a previous client of the server
+func (a *Agent) watchConnectionState(serverName string, client mcpclient.ClientInterface) {
+	source, ok := client.(connectionStateSource)
+	if !ok {
+		return
+	}
+	remove := source.AddConnectionStateListener(func(change mcpclient.ConnectionStateChange) {
+		a.emitConnectionState(serverName, change)
+	})
+
+	a.connStateMu.Lock()
+	defer a.connStateMu.Unlock()
+	if previous := a.connStateWatches[serverName]; previous != nil {
+		previous()
+	}
+	if a.connStateWatches == nil {
+		a.connStateWatches = make(map[string]func())
+	}
+	a.connStateWatches[serverName] = remove
+}
+
+// unwatchConnectionStates removes all connection state subscriptions
+func (a *Agent) unwatchConnectionStates() {
+	a.connStateMu.Lock()
+	defer a.connStateMu.Unlock()
+	for _, remove := range a.connStateWatches {
+		remove()
+	}
+	a.connStateWatches = nil
+}
+
+// emitConnectionState emits an mcp_server_connection_state event. It runs on
+// the client's keep-alive or reconnect goroutine, outside any conversation.
+func (a *Agent) emitConnectionState(serverName string, change mcpclient.ConnectionStateChange) {
+	errMsg := ""
+	if change.Err != nil {
+		errMsg = change.Err.Error()
+	}
+	fields := []loggerv2.Field{
+		loggerv2.String("server", serverName),
+		loggerv2.String("state", string(change.State)),
+		loggerv2.Int("attempt", change.Attempt),
+	}
+	switch change.State {
+	case mcpclient.ConnectionStateDisconnected, mcpclient.ConnectionStateReconnectFailed:
+		getLogger(a).Warn("🔌 [MCP] Server connection "+string(change.State), append(fields, loggerv2.String("error", errMsg))...)
+	default:
+		getLogger(a).Info("🔌 [MCP] Server connection "+string(change.State), fields...)
+	}
+	a.EmitTypedEvent(context.Background(), events.NewMCPServerConnectionStateEvent(serverName, string(change.State), change.Attempt, change.SessionResumed, errMsg))
+	if change.State == mcpclient.ConnectionStateRestarted {
+		a.EmitTypedEvent(context.Background(), events.NewMCPServerRestartedEvent(serverName, change.Attempt, change.Downtime, errMsg))
+	}
+}
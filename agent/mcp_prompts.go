@@ -0,0 +1,183 @@
+// mcp_prompts.go
+//
+// This file invokes MCP prompt templates. GetPrompt executes prompts/get on a
+// server with argument values and returns the rendered messages, ready to be
+// added to a conversation. The use_prompt virtual tool lets the LLM do the same
+// mid-conversation for prompts that take arguments; get_prompt still returns
+// the plain text of argument-free prompts.
+//
+// Exported:
+//   - RenderedPrompt: The messages of a rendered prompt
+//   - Agent.GetPrompt: Render a server's prompt template with arguments
+
+package mcpagent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/manishiitg/mcpagent/mcpclient"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// RenderedPrompt is an MCP prompt rendered by GetPrompt
+type RenderedPrompt struct {
+	Server      string
+	Name        string
+	Description string
+	// Messages are the rendered messages; assistant messages have the AI role,
+	// all others the human role
+	Messages []llmtypes.MessageContent
+}
+
+// GetPrompt executes prompts/get for the prompt name of server with args and
+// returns the rendered messages. Arguments the prompt declares as required
+// must be present in args.
+func (a *Agent) GetPrompt(ctx context.Context, server, name string, args map[string]string) (*RenderedPrompt, error) {
+	if name == "" {
+		return nil, fmt.Errorf("prompt name is required")
+	}
+	client := a.Clients[server]
+	if client == nil {
+		return nil, fmt.Errorf("server %s is not connected", server)
+	}
+	for _, prompt := range a.prompts[server] {
+		if prompt.Name != name {
+			continue
+		}
+		var missing []string
+		for _, argument := range prompt.Arguments {
+			if _, ok := args[argument.Name]; argument.Required && !ok {
+				missing = append(missing, argument.Name)
+			}
+		}
+		if len(missing) > 0 {
+			return nil, fmt.Errorf("prompt %s is missing required arguments: %s", name, strings.Join(missing, ", "))
+		}
+		break
+	}
+
+	var result *mcp.GetPromptResult
+	var err error
+	if renderer, ok := client.(mcpclient.PromptRenderer); ok {
+		result, err = renderer.GetPromptWithArguments(ctx, name, args)
+	} else if len(args) == 0 {
+		result, err = client.GetPrompt(ctx, name)
+	} else {
+		return nil, fmt.Errorf("the client of server %s cannot pass prompt arguments", server)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get prompt %s from server %s: %w", name, server, err)
+	}
+	rendered := &RenderedPrompt{Server: server, Name: name, Description: result.Description}
+	for _, message := range result.Messages {
+		role := llmtypes.ChatMessageTypeHuman
+		if message.Role == mcp.RoleAssistant {
+			role = llmtypes.ChatMessageTypeAI
+		}
+		rendered.Messages = append(rendered.Messages, llmtypes.MessageContent{
+			Role:  role,
+			Parts: []llmtypes.ContentPart{promptContentPart(message.Content)},
+		})
+	}
+	return rendered, nil
+}
+
+// promptContentPart converts the content of a prompt message to a message part
+func promptContentPart(content mcp.Content) llmtypes.ContentPart {
+	switch c := content.(type) {
+	case mcp.TextContent:
+		return llmtypes.TextContent{Text: c.Text}
+	case *mcp.TextContent:
+		return llmtypes.TextContent{Text: c.Text}
+	case mcp.ImageContent:
+		return llmtypes.ImageContent{SourceType: "base64", MediaType: c.MIMEType, Data: c.Data}
+	case *mcp.ImageContent:
+		return llmtypes.ImageContent{SourceType: "base64", MediaType: c.MIMEType, Data: c.Data}
+	case mcp.EmbeddedResource:
+		return llmtypes.TextContent{Text: formatResourceContents(c.Resource)}
+	case *mcp.EmbeddedResource:
+		return llmtypes.TextContent{Text: formatResourceContents(c.Resource)}
+	default:
+		return llmtypes.TextContent{Text: fmt.Sprintf("[Unsupported prompt content: %T]", content)}
+	}
+}
+
+// hasPromptsWithArguments reports whether a listed prompt declares arguments,
+// which is when use_prompt is offered
+func (a *Agent) hasPromptsWithArguments() bool {
+	for _, serverPrompts := range a.prompts {
+		for _, prompt := range serverPrompts {
+			if len(prompt.Arguments) > 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// usePromptToolDescription describes use_prompt, naming the prompts with arguments
+func (a *Agent) usePromptToolDescription() string {
+	var b strings.Builder
+	b.WriteString("Render an MCP prompt template with arguments and return its messages. Prompts with arguments:")
+	for _, server := range sortedKeys(a.prompts) {
+		for _, prompt := range a.prompts[server] {
+			if len(prompt.Arguments) == 0 {
+				continue
+			}
+			var arguments []string
+			for _, argument := range prompt.Arguments {
+				if argument.Required {
+					arguments = append(arguments, argument.Name+" (required)")
+				} else {
+					arguments = append(arguments, argument.Name)
+				}
+			}
+			fmt.Fprintf(&b, "\n- %s: %s [%s]", server, prompt.Name, strings.Join(arguments, ", "))
+		}
+	}
+	return b.String()
+}
+
+// handleUsePrompt handles the use_prompt virtual tool
+func (a *Agent) handleUsePrompt(ctx context.Context, args map[string]interface{}) (string, error) {
+	server, ok := args["server"].(string)
+	if !ok {
+		return "", fmt.Errorf("server parameter is required")
+	}
+	name, ok := args["name"].(string)
+	if !ok {
+		return "", fmt.Errorf("name parameter is required")
+	}
+	params := map[string]string{}
+	if arguments, ok := args["arguments"].(map[string]interface{}); ok {
+		for key, value := range arguments {
+			params[key] = fmt.Sprint(value)
+		}
+	}
+
+	rendered, err := a.GetPrompt(ctx, server, name, params)
+	if err != nil {
+		return "", err
+	}
+	var blocks []string
+	for _, message := range rendered.Messages {
+		for _, part := range message.Parts {
+			text := "[image]"
+			if textPart, ok := part.(llmtypes.TextContent); ok {
+				text = textPart.Text
+			}
+			role := "user"
+			if message.Role == llmtypes.ChatMessageTypeAI {
+				role = "assistant"
+			}
+			blocks = append(blocks, fmt.Sprintf("[%s]\n%s", role, text))
+		}
+	}
+	if len(blocks) == 0 {
+		return fmt.Sprintf("Prompt %s returned no messages.", name), nil
+	}
+	return strings.Join(blocks, "\n\n"), nil
+}
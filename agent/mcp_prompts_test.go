@@ -0,0 +1,74 @@
+package mcpagent
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+	"github.com/manishiitg/mcpagent/mcpclient"
+)
+
+func TestGetPromptRendersMessages(t *testing.T) {
+	mcpServer := server.NewMCPServer("github", "1.0.0", server.WithPromptCapabilities(false))
+	reviewPR := mcp.NewPrompt("review_pr", mcp.WithPromptDescription("Review a pull request"),
+		mcp.WithArgument("number", mcp.RequiredArgument()), mcp.WithArgument("focus"))
+	mcpServer.AddPrompt(reviewPR, func(_ context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		args := request.Params.Arguments
+		return mcp.NewGetPromptResult("Review PR", []mcp.PromptMessage{
+			mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent("Review PR #"+args["number"]+" focusing on "+args["focus"])),
+			mcp.NewPromptMessage(mcp.RoleAssistant, mcp.NewTextContent("Fetching the diff first.")),
+		}), nil
+	})
+	httpServer := server.NewTestStreamableHTTPServer(mcpServer)
+	t.Cleanup(httpServer.Close)
+
+	client := mcpclient.New(mcpclient.MCPServerConfig{URL: httpServer.URL + "/mcp", Protocol: mcpclient.ProtocolHTTP}, loggerv2.NewNoop())
+	if err := client.Connect(context.Background()); err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	t.Cleanup(func() { _ = client.Close() })
+	a := &Agent{
+		Logger:  loggerv2.NewNoop(),
+		Clients: map[string]mcpclient.ClientInterface{"github": client},
+		prompts: map[string][]mcp.Prompt{"github": {reviewPR}},
+	}
+	ctx := context.Background()
+
+	rendered, err := a.GetPrompt(ctx, "github", "review_pr", map[string]string{"number": "42", "focus": "tests"})
+	if err != nil {
+		t.Fatalf("GetPrompt: %v", err)
+	}
+	if len(rendered.Messages) != 2 || rendered.Description != "Review PR" {
+		t.Fatalf("rendered = %+v", rendered)
+	}
+	if first := rendered.Messages[0]; first.Role != llmtypes.ChatMessageTypeHuman || first.Parts[0] != (llmtypes.TextContent{Text: "Review PR #42 focusing on tests"}) {
+		t.Errorf("first message = %+v", first)
+	}
+	if rendered.Messages[1].Role != llmtypes.ChatMessageTypeAI {
+		t.Errorf("assistant message role = %s", rendered.Messages[1].Role)
+	}
+	if _, err := a.GetPrompt(ctx, "github", "review_pr", nil); err == nil || !strings.Contains(err.Error(), "missing required arguments: number") {
+		t.Errorf("missing argument: %v", err)
+	}
+
+	var description string
+	for _, def := range a.CreateVirtualTools() {
+		if def.Function.Name == "use_prompt" {
+			description = def.Function.Description
+		}
+	}
+	if !strings.Contains(description, "- github: review_pr [number (required), focus]") {
+		t.Errorf("use_prompt description = %q", description)
+	}
+	result, err := a.HandleVirtualTool(ctx, "use_prompt", map[string]interface{}{
+		"server": "github", "name": "review_pr", "arguments": map[string]interface{}{"number": 7},
+	})
+	if err != nil || result != "[user]\nReview PR #7 focusing on \n\n[assistant]\nFetching the diff first." {
+		t.Errorf("use_prompt = %q, %v", result, err)
+	}
+}
@@ -0,0 +1,445 @@
+// mcp_resources.go
+//
+// This file makes MCP resources first-class tool context. The read_resource
+// virtual tool, and Agent.ReadResource for callers, fetch a resource on
+// demand by URI: the server is the one listing the URI, else the one whose
+// resource template matches it, else every connected server is tried.
+// Resource templates (RFC 6570 URI templates such as "db://tables/{name}")
+// are discovered when the agent connects and listed in the tool description;
+// ReadResourceTemplate expands one with parameters and reads the result.
+// Discovered templates are cached per server configuration for the MCP cache
+// TTL, so agents created for the same servers do not list them again;
+// WithResourceTemplateDiscovery(false) skips the discovery.
+//
+// read_resource results go through the same pipeline as tool results, so
+// large resources are offloaded to a file (see WithContextOffloading) and
+// read back with search_large_output.
+//
+// Exported:
+//   - ResourceContent: A fetched resource
+//   - Agent.ReadResource: Fetch a resource by URI
+//   - Agent.ReadResourceTemplate: Expand a resource template and fetch the result
+//   - Agent.GetResourceTemplates: The discovered resource templates by server
+//   - WithResourceTemplateDiscovery: Enable/disable resource template discovery
+
+package mcpagent
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/yosida95/uritemplate/v3"
+
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+	"github.com/manishiitg/mcpagent/mcpcache"
+	"github.com/manishiitg/mcpagent/mcpclient"
+)
+
+const (
+	// resourceTemplateDiscoveryTimeout bounds listing the templates of all servers
+	resourceTemplateDiscoveryTimeout = 10 * time.Second
+	// readResourceMaxListedTemplates caps the templates named in the read_resource description
+	readResourceMaxListedTemplates = 20
+	// maxCachedResourceTemplateServers bounds the resource template cache
+	maxCachedResourceTemplateServers = 256
+)
+
+// WithResourceTemplateDiscovery enables/disables listing the resource
+// templates of the connected MCP servers when the agent is created. Without
+// it, read_resource still reads resources by URI.
+//
+// Default: true
+func WithResourceTemplateDiscovery(enabled bool) AgentOption {
+	return func(a *Agent) {
+		a.DiscoverResourceTemplates = enabled
+	}
+}
+
+// ResourceContent is an MCP resource fetched by ReadResource
+type ResourceContent struct {
+	// Server the resource was read from
+	Server string
+	URI    string
+	// MIMEType of the first content part, if the server reported one
+	MIMEType string
+	// Text of all content parts; binary parts are described, not included
+	Text string
+	// Contents are the content parts as returned by the server
+	Contents []mcp.ResourceContents
+}
+
+// GetResourceTemplates returns the resource templates discovered per server
+func (a *Agent) GetResourceTemplates() map[string][]mcp.ResourceTemplate {
+	return a.resourceTemplates
+}
+
+// ReadResource fetches the MCP resource at uri from the server that lists it,
+// the server with a matching resource template, or else the first connected
+// server that can read it
+func (a *Agent) ReadResource(ctx context.Context, uri string) (*ResourceContent, error) {
+	return a.readResource(ctx, "", uri)
+}
+
+// ReadResourceTemplate expands a resource template, given by its URI template
+// or name, with params and fetches the resulting resource. Every variable of
+// the template must have a parameter.
+func (a *Agent) ReadResourceTemplate(ctx context.Context, template string, params map[string]string) (*ResourceContent, error) {
+	return a.readResourceTemplate(ctx, "", template, params)
+}
+
+// discoverResourceTemplates lists the resource templates of the connected
+// servers of config; servers without resource support simply have none
+func (a *Agent) discoverResourceTemplates(ctx context.Context, config *mcpclient.MCPConfig) {
+	a.resourceTemplates = a.listResourceTemplates(ctx, a.Clients, config)
+}
+
+// resourceTemplateCache holds the templates listed per server configuration
+// (mcpcache.GenerateUnifiedCacheKey), shared by the agents of the process
+var resourceTemplateCache = struct {
+	mu      sync.Mutex
+	entries map[string]cachedResourceTemplates
+}{entries: make(map[string]cachedResourceTemplates)}
+
+type cachedResourceTemplates struct {
+	templates []mcp.ResourceTemplate
+	listedAt  time.Time
+}
+
+// cachedResourceTemplatesFor returns the templates cached under key, unless
+// they are older than ttl
+func cachedResourceTemplatesFor(key string, ttl time.Duration) ([]mcp.ResourceTemplate, bool) {
+	resourceTemplateCache.mu.Lock()
+	defer resourceTemplateCache.mu.Unlock()
+	entry, ok := resourceTemplateCache.entries[key]
+	if !ok || time.Since(entry.listedAt) > ttl {
+		return nil, false
+	}
+	return entry.templates, true
+}
+
+// cacheResourceTemplates stores the templates listed under key, dropping
+// expired entries and then the oldest when the cache is full
+func cacheResourceTemplates(key string, templates []mcp.ResourceTemplate, ttl time.Duration) {
+	resourceTemplateCache.mu.Lock()
+	defer resourceTemplateCache.mu.Unlock()
+	entries := resourceTemplateCache.entries
+	if _, ok := entries[key]; !ok && len(entries) >= maxCachedResourceTemplateServers {
+		oldestKey, oldest := "", time.Now()
+		for k, entry := range entries {
+			if time.Since(entry.listedAt) > ttl {
+				delete(entries, k)
+			} else if entry.listedAt.Before(oldest) {
+				oldestKey, oldest = k, entry.listedAt
+			}
+		}
+		if len(entries) >= maxCachedResourceTemplateServers {
+			delete(entries, oldestKey)
+		}
+	}
+	entries[key] = cachedResourceTemplates{templates: templates, listedAt: time.Now()}
+}
+
+// listResourceTemplates lists the resource templates of clients, by server.
+// Templates of a server in config are cached under its session configuration
+// unless DisableCache is set. Clients without ResourceTemplateLister have none.
+func (a *Agent) listResourceTemplates(ctx context.Context, clients map[string]mcpclient.ClientInterface, config *mcpclient.MCPConfig) map[string][]mcp.ResourceTemplate {
+	if !a.DiscoverResourceTemplates {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, resourceTemplateDiscoveryTimeout)
+	defer cancel()
+	ttl := time.Duration(mcpcache.GetCacheManager(getLogger(a)).GetTTL()) * time.Minute
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	templates := make(map[string][]mcp.ResourceTemplate)
+	for name, client := range clients {
+		lister, ok := client.(mcpclient.ResourceTemplateLister)
+		if !ok {
+			continue
+		}
+		cacheKey := ""
+		if config != nil && !a.DisableCache {
+			if serverConfig, err := sessionServerConfig(config, name, a.RuntimeOverrides, a.UserID); err == nil {
+				cacheKey = mcpcache.GenerateUnifiedCacheKey(name, serverConfig)
+			}
+		}
+		if cacheKey != "" {
+			if cached, ok := cachedResourceTemplatesFor(cacheKey, ttl); ok {
+				if len(cached) > 0 {
+					templates[name] = cached
+				}
+				continue
+			}
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			serverTemplates, err := lister.ListResourceTemplates(ctx)
+			if err != nil {
+				return
+			}
+			if cacheKey != "" {
+				cacheResourceTemplates(cacheKey, serverTemplates, ttl)
+			}
+			if len(serverTemplates) == 0 {
+				return
+			}
+			mu.Lock()
+			templates[name] = serverTemplates
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	return templates
+}
+
+// readResource reads uri from server, or from the servers resourceServers picks
+func (a *Agent) readResource(ctx context.Context, server, uri string) (*ResourceContent, error) {
+	if uri == "" {
+		return nil, fmt.Errorf("uri is required")
+	}
+	servers, err := a.resourceServers(server, uri)
+	if err != nil {
+		return nil, err
+	}
+	var lastErr error
+	for _, name := range servers {
+		result, err := a.Clients[name].GetResource(ctx, uri)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		content := &ResourceContent{Server: name, URI: uri}
+		var parts []string
+		for _, part := range result.Contents {
+			parts = append(parts, formatResourceContents(part))
+			if content.MIMEType != "" {
+				continue
+			}
+			switch r := part.(type) {
+			case mcp.TextResourceContents:
+				content.MIMEType = r.MIMEType
+			case *mcp.TextResourceContents:
+				content.MIMEType = r.MIMEType
+			case mcp.BlobResourceContents:
+				content.MIMEType = r.MIMEType
+			case *mcp.BlobResourceContents:
+				content.MIMEType = r.MIMEType
+			}
+		}
+		content.Text = strings.Join(parts, "\n")
+		content.Contents = result.Contents
+		return content, nil
+	}
+	if a.Logger != nil {
+		a.Logger.Warn("Failed to read MCP resource", loggerv2.String("uri", uri), loggerv2.Any("servers", servers), loggerv2.Error(lastErr))
+	}
+	return nil, fmt.Errorf("failed to read resource %s: %w", uri, lastErr)
+}
+
+// resourceServers returns the servers to read uri from, in order of preference
+func (a *Agent) resourceServers(server, uri string) ([]string, error) {
+	if server != "" {
+		if a.Clients[server] == nil {
+			return nil, fmt.Errorf("server %s is not connected", server)
+		}
+		return []string{server}, nil
+	}
+	if len(a.Clients) == 0 {
+		return nil, fmt.Errorf("no MCP server is connected")
+	}
+	connected := make([]string, 0, len(a.Clients))
+	for name, client := range a.Clients {
+		if client != nil {
+			connected = append(connected, name)
+		}
+	}
+	sort.Strings(connected)
+
+	for _, name := range connected {
+		for _, resource := range a.resources[name] {
+			if resource.URI == uri {
+				return []string{name}, nil
+			}
+		}
+	}
+	var matching []string
+	for _, name := range connected {
+		for _, template := range a.resourceTemplates[name] {
+			if template.URITemplate != nil && template.URITemplate.Template != nil && template.URITemplate.Match(uri) != nil {
+				matching = append(matching, name)
+				break
+			}
+		}
+	}
+	if len(matching) > 0 {
+		return matching, nil
+	}
+	return connected, nil
+}
+
+// readResourceTemplate expands the template named template (its URI template
+// or name) of server ("" = any server) with params and reads the result
+func (a *Agent) readResourceTemplate(ctx context.Context, server, template string, params map[string]string) (*ResourceContent, error) {
+	var found *mcp.ResourceTemplate
+	var foundServer string
+	names := make([]string, 0, len(a.resourceTemplates))
+	for name := range a.resourceTemplates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if server != "" && name != server {
+			continue
+		}
+		for i, t := range a.resourceTemplates[name] {
+			if t.URITemplate == nil || t.URITemplate.Template == nil {
+				continue
+			}
+			if t.URITemplate.Raw() == template || t.Name == template {
+				found, foundServer = &a.resourceTemplates[name][i], name
+				break
+			}
+		}
+		if found != nil {
+			break
+		}
+	}
+	if found == nil {
+		return nil, fmt.Errorf("resource template %q not found; call read_resource without uri or template to list the available templates", template)
+	}
+
+	values := uritemplate.Values{}
+	var missing []string
+	for _, name := range found.URITemplate.Varnames() {
+		value, ok := params[name]
+		if !ok {
+			missing = append(missing, name)
+			continue
+		}
+		values.Set(name, uritemplate.String(value))
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("resource template %s is missing arguments: %s", found.URITemplate.Raw(), strings.Join(missing, ", "))
+	}
+	uri, err := found.URITemplate.Expand(values)
+	if err != nil {
+		return nil, fmt.Errorf("failed to expand resource template %s: %w", found.URITemplate.Raw(), err)
+	}
+	return a.readResource(ctx, foundServer, uri)
+}
+
+// readResourceToolDescription describes read_resource, naming the discovered templates
+func (a *Agent) readResourceToolDescription() string {
+	description := "Read an MCP resource by URI, or expand a resource template with arguments and read the result. " +
+		"Large resources are saved to a file like large tool outputs. Call without uri and template to list the available resources and templates."
+	var lines []string
+	for _, server := range sortedKeys(a.resourceTemplates) {
+		for _, template := range a.resourceTemplates[server] {
+			if template.URITemplate == nil || template.URITemplate.Template == nil {
+				continue
+			}
+			lines = append(lines, "- "+formatResourceTemplate(server, template))
+		}
+	}
+	if len(lines) == 0 {
+		return description
+	}
+	if len(lines) > readResourceMaxListedTemplates {
+		lines = append(lines[:readResourceMaxListedTemplates], fmt.Sprintf("- ... %d more (list them by calling without uri)", len(lines)-readResourceMaxListedTemplates))
+	}
+	return description + "\n\nResource templates:\n" + strings.Join(lines, "\n")
+}
+
+// formatResourceTemplate renders a template as "server: uriTemplate (name): description"
+func formatResourceTemplate(server string, template mcp.ResourceTemplate) string {
+	line := fmt.Sprintf("%s: %s (%s)", server, template.URITemplate.Raw(), template.Name)
+	if template.Description != "" {
+		line += ": " + template.Description
+	}
+	return line
+}
+
+// listResourcesText lists the resources and templates for read_resource
+func (a *Agent) listResourcesText() string {
+	var b strings.Builder
+	for _, server := range sortedKeys(a.resources) {
+		for _, resource := range a.resources[server] {
+			if b.Len() == 0 {
+				b.WriteString("Resources:\n")
+			}
+			fmt.Fprintf(&b, "- %s: %s (%s)", server, resource.URI, resource.Name)
+			if resource.Description != "" {
+				b.WriteString(": " + resource.Description)
+			}
+			b.WriteString("\n")
+		}
+	}
+	header := false
+	for _, server := range sortedKeys(a.resourceTemplates) {
+		for _, template := range a.resourceTemplates[server] {
+			if template.URITemplate == nil || template.URITemplate.Template == nil {
+				continue
+			}
+			if !header {
+				if b.Len() > 0 {
+					b.WriteString("\n")
+				}
+				b.WriteString("Resource templates (pass template and arguments):\n")
+				header = true
+			}
+			b.WriteString("- " + formatResourceTemplate(server, template) + "\n")
+		}
+	}
+	if b.Len() == 0 {
+		return "No MCP resources or resource templates are available."
+	}
+	return b.String()
+}
+
+// handleReadResource handles the read_resource virtual tool
+func (a *Agent) handleReadResource(ctx context.Context, args map[string]interface{}) (string, error) {
+	server, _ := args["server"].(string)
+	uri, _ := args["uri"].(string)
+	template, _ := args["template"].(string)
+
+	var content *ResourceContent
+	var err error
+	switch {
+	case template != "":
+		params := map[string]string{}
+		if arguments, ok := args["arguments"].(map[string]interface{}); ok {
+			for name, value := range arguments {
+				params[name] = fmt.Sprint(value)
+			}
+		}
+		content, err = a.readResourceTemplate(ctx, server, template, params)
+	case uri != "":
+		content, err = a.readResource(ctx, server, uri)
+	default:
+		return a.listResourcesText(), nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if content.Text == "" {
+		return fmt.Sprintf("Resource %s is empty.", content.URI), nil
+	}
+	return content.Text, nil
+}
+
+// sortedKeys returns the keys of a map by server name, sorted
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
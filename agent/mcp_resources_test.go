@@ -0,0 +1,140 @@
+package mcpagent
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+	"github.com/manishiitg/mcpagent/mcpclient"
+)
+
+// resourceTestAgent connects an agent to an in-process MCP server with one
+// resource and one resource template
+func resourceTestAgent(t *testing.T) *Agent {
+	t.Helper()
+	mcpServer := server.NewMCPServer("docs", "1.0.0", server.WithResourceCapabilities(false, false))
+	mcpServer.AddResource(mcp.NewResource("docs://guide", "guide", mcp.WithMIMEType("text/markdown")),
+		func(_ context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+			return []mcp.ResourceContents{mcp.TextResourceContents{URI: request.Params.URI, MIMEType: "text/markdown", Text: "# Guide"}}, nil
+		})
+	mcpServer.AddResourceTemplate(mcp.NewResourceTemplate("db://tables/{table}/schema", "table_schema", mcp.WithTemplateDescription("Schema of a table")),
+		func(_ context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+			return []mcp.ResourceContents{mcp.TextResourceContents{URI: request.Params.URI, MIMEType: "application/json", Text: `{"uri": "` + request.Params.URI + `"}`}}, nil
+		})
+	httpServer := server.NewTestStreamableHTTPServer(mcpServer)
+	t.Cleanup(httpServer.Close)
+
+	client := mcpclient.New(mcpclient.MCPServerConfig{URL: httpServer.URL + "/mcp", Protocol: mcpclient.ProtocolHTTP}, loggerv2.NewNoop())
+	if err := client.Connect(context.Background()); err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	t.Cleanup(func() { _ = client.Close() })
+	resources, err := client.ListResources(context.Background())
+	if err != nil {
+		t.Fatalf("ListResources: %v", err)
+	}
+
+	a := &Agent{
+		Logger:                    loggerv2.NewNoop(),
+		Clients:                   map[string]mcpclient.ClientInterface{"docs": client},
+		resources:                 map[string][]mcp.Resource{"docs": resources},
+		DiscoverResourceTemplates: true,
+	}
+	a.discoverResourceTemplates(context.Background(), nil)
+	return a
+}
+
+func TestReadResourceAndTemplates(t *testing.T) {
+	a := resourceTestAgent(t)
+	ctx := context.Background()
+
+	content, err := a.ReadResource(ctx, "docs://guide")
+	if err != nil || content.Text != "# Guide" || content.MIMEType != "text/markdown" || content.Server != "docs" {
+		t.Fatalf("ReadResource = %+v, %v", content, err)
+	}
+	content, err = a.ReadResourceTemplate(ctx, "table_schema", map[string]string{"table": "orders"})
+	if err != nil || content.URI != "db://tables/orders/schema" || content.Text != `{"uri": "db://tables/orders/schema"}` {
+		t.Fatalf("ReadResourceTemplate = %+v, %v", content, err)
+	}
+	if _, err := a.ReadResourceTemplate(ctx, "db://tables/{table}/schema", nil); err == nil || !strings.Contains(err.Error(), "missing arguments: table") {
+		t.Errorf("missing template argument: %v", err)
+	}
+	// A URI matching a template is read from the template's server
+	if servers, _ := a.resourceServers("", "db://tables/users/schema"); len(servers) != 1 || servers[0] != "docs" {
+		t.Errorf("resourceServers = %v", servers)
+	}
+
+	var tool bool
+	for _, def := range a.CreateVirtualTools() {
+		if def.Function.Name == "read_resource" {
+			tool = strings.Contains(def.Function.Description, "docs: db://tables/{table}/schema (table_schema): Schema of a table")
+		}
+	}
+	if !tool {
+		t.Error("read_resource should be offered and list the template")
+	}
+	listing, err := a.HandleVirtualTool(ctx, "read_resource", map[string]interface{}{})
+	if err != nil || !strings.Contains(listing, "- docs: docs://guide (guide)") || !strings.Contains(listing, "Resource templates") {
+		t.Errorf("listing = %q, %v", listing, err)
+	}
+	result, err := a.HandleVirtualTool(ctx, "read_resource", map[string]interface{}{
+		"template": "table_schema", "arguments": map[string]interface{}{"table": "users"},
+	})
+	if err != nil || !strings.Contains(result, "db://tables/users/schema") {
+		t.Errorf("read_resource with template = %q, %v", result, err)
+	}
+	// get_resource reads the same resources
+	if result, err := a.HandleVirtualTool(ctx, "get_resource", map[string]interface{}{"server": "docs", "uri": "docs://guide"}); err != nil || result != "# Guide" {
+		t.Errorf("get_resource = %q, %v", result, err)
+	}
+}
+
+// templateCountingClient counts ListResourceTemplates calls
+type templateCountingClient struct {
+	mcpclient.ClientInterface
+	calls int
+}
+
+func (c *templateCountingClient) ListResourceTemplates(context.Context) ([]mcp.ResourceTemplate, error) {
+	c.calls++
+	return []mcp.ResourceTemplate{mcp.NewResourceTemplate("db://tables/{table}", "table")}, nil
+}
+
+func TestResourceTemplatesAreCachedPerServerConfig(t *testing.T) {
+	config := &mcpclient.MCPConfig{MCPServers: map[string]mcpclient.MCPServerConfig{
+		"db": {URL: "http://localhost/" + t.Name(), Protocol: mcpclient.ProtocolHTTP},
+	}}
+	client := &templateCountingClient{}
+	newAgent := func() *Agent {
+		return &Agent{Logger: loggerv2.NewNoop(), Clients: map[string]mcpclient.ClientInterface{"db": client}, DiscoverResourceTemplates: true}
+	}
+
+	for i := 0; i < 2; i++ {
+		a := newAgent()
+		a.discoverResourceTemplates(context.Background(), config)
+		if len(a.GetResourceTemplates()["db"]) != 1 {
+			t.Fatalf("agent %d templates = %v", i, a.GetResourceTemplates())
+		}
+	}
+	if client.calls != 1 {
+		t.Errorf("ListResourceTemplates called %d times, want once for the same server config", client.calls)
+	}
+
+	uncached := newAgent()
+	uncached.DisableCache = true
+	uncached.discoverResourceTemplates(context.Background(), config)
+	if client.calls != 2 {
+		t.Errorf("DisableCache: ListResourceTemplates called %d times, want 2", client.calls)
+	}
+
+	disabled := newAgent()
+	disabled.DiscoverResourceTemplates = false
+	disabled.discoverResourceTemplates(context.Background(), config)
+	if client.calls != 2 || len(disabled.GetResourceTemplates()) != 0 {
+		t.Errorf("discovery disabled: %d calls, templates %v", client.calls, disabled.GetResourceTemplates())
+	}
+}
@@ -0,0 +1,233 @@
+// mcp_sampling.go
+//
+// This file lets MCP servers sample the agent's LLM. A server configured with
+// "sampling": true may send sampling/createMessage requests while one of its
+// tools runs; with WithMCPSampling, the agent answers them with its own model,
+// after an optional approval hook. Each request emits an mcp_sampling_request
+// event and its outcome an mcp_sampling_response event, and the tokens count
+// towards the agent's usage.
+//
+// Requests arriving outside a tool call of a sampling-enabled agent are
+// refused by mcpclient (ErrNoSamplingHandler), and so are requests on a
+// connection shared with another agent's running tool call, since they could
+// belong to either (ErrAmbiguousSamplingHandler).
+//
+// Exported:
+//   - SamplingConfig, SamplingRequest: Approval hook and limits, and what the hook sees
+//   - WithMCPSampling: Enable sampling when creating an agent
+
+package mcpagent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/manishiitg/mcpagent/events"
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+	"github.com/manishiitg/mcpagent/mcpclient"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// DefaultSamplingMaxTokens caps sampling completions when neither the request
+// nor SamplingConfig.MaxTokens does
+const DefaultSamplingMaxTokens = 4096
+
+// samplingResponsePreviewChars bounds the completion text in the response event
+const samplingResponsePreviewChars = 500
+
+// ErrSamplingRejected is returned to the server when the approval hook
+// rejects a sampling request
+var ErrSamplingRejected = errors.New("sampling request rejected by the client")
+
+// SamplingRequest is a server's sampling request, converted for the approval hook
+type SamplingRequest struct {
+	ServerName   string
+	SystemPrompt string
+	// Messages are the request's messages; images and audio are replaced by a placeholder text
+	Messages   []llmtypes.MessageContent
+	MaxTokens  int
+	ModelHints []string
+}
+
+// SamplingConfig configures how the agent answers sampling requests
+type SamplingConfig struct {
+	// Approve decides whether a request is answered. Returning false (or an
+	// error) refuses it without calling the LLM. nil approves every request.
+	Approve func(ctx context.Context, request SamplingRequest) (bool, error)
+	// MaxTokens caps the completion, also when the server asks for more;
+	// 0 = DefaultSamplingMaxTokens
+	MaxTokens int
+}
+
+// WithMCPSampling answers the sampling requests of MCP servers with the
+// agent's LLM. Only servers configured with "sampling": true can send them,
+// and only while one of their tools runs.
+//
+// Example:
+//
+//	mcpagent.WithMCPSampling(mcpagent.SamplingConfig{
+//	    Approve: func(ctx context.Context, r mcpagent.SamplingRequest) (bool, error) {
+//	        return r.ServerName == "research", nil
+//	    },
+//	})
+//
+// Default: nil (sampling requests are refused)
+func WithMCPSampling(config SamplingConfig) AgentOption {
+	return func(a *Agent) {
+		a.mcpSampling = &config
+	}
+}
+
+// withMCPSampling installs the agent's sampling handler for a tool call on serverName
+func (a *Agent) withMCPSampling(ctx context.Context, serverName string) context.Context {
+	if a.mcpSampling == nil {
+		return ctx
+	}
+	return mcpclient.WithSamplingHandler(ctx, a, func(samplingCtx context.Context, request mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
+		return a.handleSamplingRequest(samplingCtx, serverName, request)
+	})
+}
+
+// handleSamplingRequest answers one sampling request of serverName
+func (a *Agent) handleSamplingRequest(ctx context.Context, serverName string, request mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
+	logger := getLogger(a)
+	startTime := time.Now()
+	samplingRequest := samplingRequestFromMCP(serverName, request)
+
+	a.EmitTypedEvent(ctx, &events.MCPSamplingRequestEvent{
+		BaseEventData: events.BaseEventData{Timestamp: startTime},
+		ServerName:    serverName,
+		MessageCount:  len(samplingRequest.Messages),
+		SystemPrompt:  samplingRequest.SystemPrompt,
+		MaxTokens:     samplingRequest.MaxTokens,
+		ModelHints:    samplingRequest.ModelHints,
+	})
+	response := &events.MCPSamplingResponseEvent{ServerName: serverName}
+	defer func() {
+		response.Timestamp = time.Now()
+		response.Duration = time.Since(startTime)
+		a.EmitTypedEvent(ctx, response)
+	}()
+
+	if approve := a.mcpSampling.Approve; approve != nil {
+		approved, err := approve(ctx, samplingRequest)
+		if err != nil {
+			response.Error = err.Error()
+			return nil, fmt.Errorf("%w: %v", ErrSamplingRejected, err)
+		}
+		if !approved {
+			response.Error = ErrSamplingRejected.Error()
+			logger.Info("🧪 [MCP_SAMPLING] Sampling request rejected",
+				loggerv2.String("server_name", serverName))
+			return nil, ErrSamplingRejected
+		}
+	}
+	response.Approved = true
+
+	messages := samplingRequest.Messages
+	if samplingRequest.SystemPrompt != "" {
+		messages = append([]llmtypes.MessageContent{llmtypes.TextParts(llmtypes.ChatMessageTypeSystem, samplingRequest.SystemPrompt)}, messages...)
+	}
+	opts := []llmtypes.CallOption{llmtypes.WithMaxTokens(a.samplingMaxTokens(samplingRequest.MaxTokens))}
+	if request.Temperature != 0 {
+		opts = append(opts, llmtypes.WithTemperature(request.Temperature))
+	}
+	if len(request.StopSequences) > 0 {
+		opts = append(opts, llmtypes.WithStopSequences(request.StopSequences))
+	}
+
+	logger.Info("🧪 [MCP_SAMPLING] Answering sampling request",
+		loggerv2.String("server_name", serverName),
+		loggerv2.Int("messages", len(messages)),
+		loggerv2.String("model_id", a.ModelID))
+	resp, usage, err := GenerateContentWithRetry(a, ctx, messages, opts, 0)
+	if err != nil {
+		response.Error = err.Error()
+		return nil, fmt.Errorf("sampling failed: %w", err)
+	}
+	if resp == nil || len(resp.Choices) == 0 {
+		response.Error = "empty response"
+		return nil, fmt.Errorf("sampling failed: empty response")
+	}
+	response.Usage = events.UsageMetrics{
+		PromptTokens:     usage.InputTokens,
+		CompletionTokens: usage.OutputTokens,
+		TotalTokens:      usage.TotalTokens,
+	}
+	a.accumulateTokenUsage(ctx, response.Usage, resp, 0)
+
+	text := resp.Choices[0].Content
+	response.Model = a.ModelID
+	response.Response = truncateUTF8(text, samplingResponsePreviewChars)
+
+	stopReason := "endTurn"
+	if resp.Choices[0].StopReason == "max_tokens" || resp.Choices[0].StopReason == "length" {
+		stopReason = "maxTokens"
+	}
+	return &mcp.CreateMessageResult{
+		SamplingMessage: mcp.SamplingMessage{
+			Role:    mcp.RoleAssistant,
+			Content: mcp.NewTextContent(text),
+		},
+		Model:      a.ModelID,
+		StopReason: stopReason,
+	}, nil
+}
+
+// samplingMaxTokens caps the tokens a server asked for by the configured maximum
+func (a *Agent) samplingMaxTokens(requested int) int {
+	limit := a.mcpSampling.MaxTokens
+	if limit <= 0 {
+		limit = DefaultSamplingMaxTokens
+	}
+	if requested > 0 && requested < limit {
+		return requested
+	}
+	return limit
+}
+
+// samplingRequestFromMCP converts a sampling/createMessage request
+func samplingRequestFromMCP(serverName string, request mcp.CreateMessageRequest) SamplingRequest {
+	converted := SamplingRequest{
+		ServerName:   serverName,
+		SystemPrompt: request.SystemPrompt,
+		MaxTokens:    request.MaxTokens,
+	}
+	if prefs := request.ModelPreferences; prefs != nil {
+		for _, hint := range prefs.Hints {
+			converted.ModelHints = append(converted.ModelHints, hint.Name)
+		}
+	}
+	for _, msg := range request.Messages {
+		role := llmtypes.ChatMessageTypeHuman
+		if msg.Role == mcp.RoleAssistant {
+			role = llmtypes.ChatMessageTypeAI
+		}
+		converted.Messages = append(converted.Messages, llmtypes.TextParts(role, samplingContentText(msg.Content)))
+	}
+	return converted
+}
+
+// samplingContentText returns the text of a sampling message's content
+func samplingContentText(content any) string {
+	switch c := content.(type) {
+	case mcp.TextContent:
+		return c.Text
+	case *mcp.TextContent:
+		return c.Text
+	case mcp.ImageContent, *mcp.ImageContent:
+		return "[image omitted]"
+	case mcp.AudioContent, *mcp.AudioContent:
+		return "[audio omitted]"
+	case map[string]any:
+		if text, ok := c["text"].(string); ok {
+			return text
+		}
+		return fmt.Sprintf("[%v content omitted]", c["type"])
+	default:
+		return fmt.Sprintf("%v", c)
+	}
+}
@@ -0,0 +1,96 @@
+package mcpagent
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/manishiitg/mcpagent/events"
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestSamplingRequestFromMCP(t *testing.T) {
+	request := mcp.CreateMessageRequest{}
+	request.SystemPrompt = "You classify documents."
+	request.MaxTokens = 200
+	request.ModelPreferences = &mcp.ModelPreferences{Hints: []mcp.ModelHint{{Name: "claude-3-haiku"}}}
+	request.Messages = []mcp.SamplingMessage{
+		{Role: mcp.RoleUser, Content: mcp.NewTextContent("Classify: invoice #42")},
+		{Role: mcp.RoleAssistant, Content: map[string]any{"type": "text", "text": "invoice"}},
+		{Role: mcp.RoleUser, Content: mcp.NewImageContent("aGVsbG8=", "image/png")},
+	}
+
+	got := samplingRequestFromMCP("docs", request)
+	if got.ServerName != "docs" || got.SystemPrompt != "You classify documents." || got.MaxTokens != 200 {
+		t.Errorf("unexpected request: %+v", got)
+	}
+	if len(got.ModelHints) != 1 || got.ModelHints[0] != "claude-3-haiku" {
+		t.Errorf("ModelHints = %v", got.ModelHints)
+	}
+	want := []struct {
+		role llmtypes.ChatMessageType
+		text string
+	}{
+		{llmtypes.ChatMessageTypeHuman, "Classify: invoice #42"},
+		{llmtypes.ChatMessageTypeAI, "invoice"},
+		{llmtypes.ChatMessageTypeHuman, "[image omitted]"},
+	}
+	if len(got.Messages) != len(want) {
+		t.Fatalf("got %d messages, want %d", len(got.Messages), len(want))
+	}
+	for i, w := range want {
+		msg := got.Messages[i]
+		if msg.Role != w.role || msg.Parts[0].(llmtypes.TextContent).Text != w.text {
+			t.Errorf("message %d = %+v, want %s %q", i, msg, w.role, w.text)
+		}
+	}
+}
+
+func TestSamplingMaxTokens(t *testing.T) {
+	a := &Agent{}
+	WithMCPSampling(SamplingConfig{MaxTokens: 1000})(a)
+	for requested, want := range map[int]int{0: 1000, 300: 300, 5000: 1000} {
+		if got := a.samplingMaxTokens(requested); got != want {
+			t.Errorf("samplingMaxTokens(%d) = %d, want %d", requested, got, want)
+		}
+	}
+	WithMCPSampling(SamplingConfig{})(a)
+	if got := a.samplingMaxTokens(0); got != DefaultSamplingMaxTokens {
+		t.Errorf("samplingMaxTokens(0) = %d, want the default", got)
+	}
+}
+
+func TestSamplingRejectedByApprovalHook(t *testing.T) {
+	listener := &recordingAgentEventListener{}
+	a := &Agent{Logger: loggerv2.NewNoop(), ModelID: "test-model"}
+	a.AddEventListener(listener)
+	var seen SamplingRequest
+	WithMCPSampling(SamplingConfig{Approve: func(_ context.Context, r SamplingRequest) (bool, error) {
+		seen = r
+		return false, nil
+	}})(a)
+
+	request := mcp.CreateMessageRequest{}
+	request.Messages = []mcp.SamplingMessage{{Role: mcp.RoleUser, Content: mcp.NewTextContent("hi")}}
+	if _, err := a.handleSamplingRequest(context.Background(), "docs", request); !errors.Is(err, ErrSamplingRejected) {
+		t.Fatalf("error = %v, want ErrSamplingRejected", err)
+	}
+	if seen.ServerName != "docs" || len(seen.Messages) != 1 {
+		t.Errorf("approval hook saw %+v", seen)
+	}
+
+	var requested, responded bool
+	for _, event := range listener.events {
+		switch data := event.Data.(type) {
+		case *events.MCPSamplingRequestEvent:
+			requested = data.ServerName == "docs" && data.MessageCount == 1
+		case *events.MCPSamplingResponseEvent:
+			responded = !data.Approved && data.Error != ""
+		}
+	}
+	if !requested || !responded {
+		t.Errorf("missing sampling events (request %v, rejected response %v)", requested, responded)
+	}
+}
@@ -0,0 +1,193 @@
+// memory.go
+//
+// This file provides memory accounting for the session state an agent keeps
+// between conversations: the last conversation history, the stream replay
+// buffer, the tool call log and the OpenAPI spec cache. Agent pools (such as
+// the gRPC server's AgentManager) use it to enforce a memory ceiling by
+// spilling or dropping the state of idle agents.
+//
+// The history is only retained with WithHistoryRetention, and its size is
+// estimated once when it is retained, so accounting a pool of agents does not
+// re-encode every history.
+//
+// Exported:
+//   - MemoryUsage: Estimated bytes per kind of retained state
+//   - MemoryPolicy: What to do with evicted state (spill or drop)
+//   - WithHistoryRetention: Keep the last conversation history after it ends
+//   - Agent.MemoryUsage: Current estimate for one agent
+//   - Agent.RetainedHistory: Last conversation history kept by the agent
+//   - Agent.EvictSessionState: Spill or drop retained state and emit MemoryPressure
+
+package mcpagent
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/manishiitg/mcpagent/events"
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+// MemoryUsage estimates the memory held by an agent's retained session state.
+// Sizes are JSON-encoded byte counts, a stable proxy for heap usage.
+type MemoryUsage struct {
+	HistoryBytes int64 `json:"history_bytes"` // Last conversation history
+	EventBytes   int64 `json:"event_bytes"`   // Stream replay buffers
+	LogBytes     int64 `json:"log_bytes"`     // Tool call log
+	CacheBytes   int64 `json:"cache_bytes"`   // OpenAPI spec cache
+}
+
+// Total returns the sum of all retained state
+func (u MemoryUsage) Total() int64 {
+	return u.HistoryBytes + u.EventBytes + u.LogBytes + u.CacheBytes
+}
+
+// MemoryPolicy selects what happens to session state evicted under memory pressure
+type MemoryPolicy string
+
+const (
+	// MemoryPolicySpill saves the retained history to a ConversationStore
+	// before releasing it, so the conversation can be resumed later
+	MemoryPolicySpill MemoryPolicy = "spill"
+	// MemoryPolicyDrop releases the retained state without saving it
+	MemoryPolicyDrop MemoryPolicy = "drop"
+)
+
+// MemoryUsage returns the estimated memory held by the agent's retained session state
+func (a *Agent) MemoryUsage() MemoryUsage {
+	var usage MemoryUsage
+
+	a.retainedHistoryMu.Lock()
+	usage.HistoryBytes = a.retainedHistoryBytes
+	a.retainedHistoryMu.Unlock()
+
+	for _, tracer := range a.Tracers {
+		if st, ok := tracer.(*streamingTracerImpl); ok {
+			usage.EventBytes += st.replayBytes()
+		}
+	}
+
+	a.toolCallLogMu.Lock()
+	for _, entry := range a.ToolCallLog {
+		usage.LogBytes += int64(len(entry))
+	}
+	a.toolCallLogMu.Unlock()
+
+	a.openAPISpecCacheMu.RLock()
+	for _, spec := range a.openAPISpecCache {
+		usage.CacheBytes += int64(len(spec))
+	}
+	a.openAPISpecCacheMu.RUnlock()
+
+	return usage
+}
+
+// WithHistoryRetention keeps the history of each completed conversation on the
+// agent (see RetainedHistory), for Fork, SummarizeNow and
+// CheckpointRetainedHistory. Agent pools enforcing a memory ceiling account
+// and evict it. Without it the history is released when the conversation
+// ends.
+//
+// Default: disabled
+func WithHistoryRetention(enabled bool) AgentOption {
+	return func(a *Agent) {
+		a.historyRetention = enabled
+	}
+}
+
+// RetainedHistory returns a copy of the history of the agent's last completed
+// conversation, or nil if there is none, it was evicted or history retention
+// is off (see WithHistoryRetention)
+func (a *Agent) RetainedHistory() []llmtypes.MessageContent {
+	a.retainedHistoryMu.Lock()
+	defer a.retainedHistoryMu.Unlock()
+	if len(a.retainedHistory) == 0 {
+		return nil
+	}
+	return append([]llmtypes.MessageContent(nil), a.retainedHistory...)
+}
+
+// retainHistory replaces the retained history and its size estimate
+func (a *Agent) retainHistory(messages []llmtypes.MessageContent) {
+	var size int64
+	if len(messages) > 0 {
+		size = estimateJSONBytes(messages)
+	}
+	a.retainedHistoryMu.Lock()
+	defer a.retainedHistoryMu.Unlock()
+	a.retainedHistory = messages
+	a.retainedHistoryBytes = size
+}
+
+// EvictSessionState releases the agent's retained session state and emits a
+// MemoryPressure event. With MemoryPolicySpill and a non-nil store the retained
+// history is first saved as a checkpoint (under the autosave checkpoint ID) so
+// it shows up in RecoverConversations; if saving fails nothing is released.
+// limitBytes is the ceiling that was exceeded, reported in the event.
+// Returns the usage that was freed.
+func (a *Agent) EvictSessionState(ctx context.Context, policy MemoryPolicy, store ConversationStore, limitBytes int64) (MemoryUsage, error) {
+	usage := a.MemoryUsage()
+	event := &events.MemoryPressureEvent{
+		BaseEventData: events.BaseEventData{Timestamp: time.Now()},
+		Policy:        string(policy),
+		UsageBytes:    usage.Total(),
+		LimitBytes:    limitBytes,
+	}
+
+	if policy == MemoryPolicySpill && store != nil {
+		checkpointID, err := a.CheckpointRetainedHistory(ctx, store)
+		if err != nil {
+			event.Error = err.Error()
+			a.EmitTypedEvent(ctx, event)
+			return MemoryUsage{}, err
+		}
+		event.CheckpointID = checkpointID
+	}
+
+	a.retainHistory(nil)
+	for _, tracer := range a.Tracers {
+		if st, ok := tracer.(*streamingTracerImpl); ok {
+			st.clearReplay()
+		}
+	}
+	a.toolCallLogMu.Lock()
+	a.ToolCallLog = nil
+	a.toolCallLogMu.Unlock()
+	a.openAPISpecCacheMu.Lock()
+	a.openAPISpecCache = make(map[string][]byte)
+	a.openAPISpecCacheMu.Unlock()
+
+	event.FreedBytes = usage.Total()
+	a.EmitTypedEvent(ctx, event)
+	a.Logger.Info("Evicted agent session state under memory pressure",
+		loggerv2.String("policy", string(policy)),
+		loggerv2.Any("freed_bytes", event.FreedBytes),
+		loggerv2.String("checkpoint_id", event.CheckpointID))
+	return usage, nil
+}
+
+// lastUserText returns the text of the last user message in messages
+func lastUserText(messages []llmtypes.MessageContent) string {
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role != llmtypes.ChatMessageTypeHuman {
+			continue
+		}
+		for _, part := range messages[i].Parts {
+			if text, ok := part.(llmtypes.TextContent); ok {
+				return text.Text
+			}
+		}
+	}
+	return ""
+}
+
+func estimateJSONBytes(v interface{}) int64 {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return 0
+	}
+	return int64(len(data))
+}
@@ -0,0 +1,128 @@
+package mcpagent
+
+import (
+	"context"
+	"testing"
+
+	"github.com/manishiitg/mcpagent/events"
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+func memoryTestAgent(listener AgentEventListener) *Agent {
+	a := &Agent{
+		Logger:           loggerv2.NewNoop(),
+		TraceID:          "trace-memory",
+		SessionID:        "session-memory",
+		ToolCallLog:      []string{"browser_navigate(https://example.com)"},
+		openAPISpecCache: map[string][]byte{"browser": []byte(`{"openapi":"3.0.0"}`)},
+		listeners:        []AgentEventListener{listener},
+	}
+	a.retainHistory([]llmtypes.MessageContent{
+		llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "open example.com"),
+		llmtypes.TextParts(llmtypes.ChatMessageTypeAI, "Opened example.com"),
+	})
+	return a
+}
+
+func memoryPressureEvents(listener *recordingAgentEventListener) []*events.MemoryPressureEvent {
+	var out []*events.MemoryPressureEvent
+	for _, event := range listener.events {
+		if data, ok := event.Data.(*events.MemoryPressureEvent); ok {
+			out = append(out, data)
+		}
+	}
+	return out
+}
+
+func TestAgentMemoryUsageCountsRetainedState(t *testing.T) {
+	a := memoryTestAgent(&recordingAgentEventListener{})
+
+	usage := a.MemoryUsage()
+	if usage.HistoryBytes == 0 || usage.LogBytes == 0 || usage.CacheBytes == 0 {
+		t.Fatalf("expected history, log and cache to be counted: %+v", usage)
+	}
+	if usage.Total() != usage.HistoryBytes+usage.EventBytes+usage.LogBytes+usage.CacheBytes {
+		t.Errorf("Total() = %d does not match its parts %+v", usage.Total(), usage)
+	}
+	if (&Agent{}).MemoryUsage().Total() != 0 {
+		t.Error("expected an agent without retained state to use nothing")
+	}
+}
+
+func TestFinalizeRetainsHistoryOnlyWhenEnabled(t *testing.T) {
+	history := []llmtypes.MessageContent{llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "open example.com")}
+	a := &Agent{Logger: loggerv2.NewNoop()}
+	DefaultFinalizeStage{}.Finalize(context.Background(), a, "", history, nil)
+	if a.RetainedHistory() != nil || a.MemoryUsage().HistoryBytes != 0 {
+		t.Error("history retained without WithHistoryRetention")
+	}
+
+	WithHistoryRetention(true)(a)
+	DefaultFinalizeStage{}.Finalize(context.Background(), a, "", history, nil)
+	if len(a.RetainedHistory()) != 1 || a.MemoryUsage().HistoryBytes != estimateJSONBytes(history) {
+		t.Errorf("retained %d messages, %d bytes", len(a.RetainedHistory()), a.MemoryUsage().HistoryBytes)
+	}
+}
+
+func TestEvictSessionStateSpillsHistoryToStore(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewFileConversationStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	listener := &recordingAgentEventListener{}
+	a := memoryTestAgent(listener)
+	before := a.MemoryUsage()
+
+	freed, err := a.EvictSessionState(ctx, MemoryPolicySpill, store, 1024)
+	if err != nil {
+		t.Fatalf("EvictSessionState: %v", err)
+	}
+	if freed != before {
+		t.Errorf("freed = %+v, want %+v", freed, before)
+	}
+	if after := a.MemoryUsage(); after.Total() != 0 {
+		t.Errorf("expected all retained state to be released, got %+v", after)
+	}
+
+	checkpoint, err := store.Load(ctx, "trace-memory")
+	if err != nil {
+		t.Fatalf("expected spilled checkpoint: %v", err)
+	}
+	if len(checkpoint.Messages) != 2 || checkpoint.Question != "open example.com" || checkpoint.SessionID != "session-memory" {
+		t.Errorf("unexpected spilled checkpoint: %+v", checkpoint)
+	}
+
+	pressure := memoryPressureEvents(listener)
+	if len(pressure) != 1 {
+		t.Fatalf("expected one MemoryPressure event, got %d", len(pressure))
+	}
+	if pressure[0].Policy != "spill" || pressure[0].CheckpointID != "trace-memory" ||
+		pressure[0].FreedBytes != before.Total() || pressure[0].LimitBytes != 1024 {
+		t.Errorf("unexpected MemoryPressure event: %+v", pressure[0])
+	}
+}
+
+func TestEvictSessionStateDropDoesNotSave(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewFileConversationStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	listener := &recordingAgentEventListener{}
+	a := memoryTestAgent(listener)
+
+	if _, err := a.EvictSessionState(ctx, MemoryPolicyDrop, store, 0); err != nil {
+		t.Fatalf("EvictSessionState: %v", err)
+	}
+	if a.RetainedHistory() != nil {
+		t.Error("expected retained history to be dropped")
+	}
+	if checkpoints, _ := store.List(ctx); len(checkpoints) != 0 {
+		t.Errorf("drop policy saved %d checkpoints", len(checkpoints))
+	}
+	if pressure := memoryPressureEvents(listener); len(pressure) != 1 || pressure[0].CheckpointID != "" {
+		t.Errorf("unexpected MemoryPressure events: %+v", pressure)
+	}
+}
@@ -0,0 +1,216 @@
+// model_context.go
+//
+// This file provides the registry of model context-window sizes used by
+// token-threshold summarization, adaptive max tokens and ContextUsagePercent.
+// Provider metadata often lags behind newly released models, so a window can
+// be overridden without code changes, either per process (environment
+// variables, an override file, RegisterModelContextWindow) or per agent
+// (WithModelContextWindow).
+//
+// Resolution order for Agent.GetModelContextWindow:
+//  1. WithModelContextWindow on the agent
+//  2. Overrides: RegisterModelContextWindow, LoadModelContextWindows,
+//     MCPAGENT_MODEL_CONTEXT_WINDOWS and MCPAGENT_MODEL_CONTEXT_FILE
+//  3. The provider's model metadata
+//  4. Built-in defaults for well-known model families
+//
+// Model IDs match exactly or by longest prefix, so "claude-sonnet-4" covers
+// "claude-sonnet-4-20250514".
+//
+// Exported:
+//   - WithModelContextWindow: Per-agent override
+//   - RegisterModelContextWindow / LoadModelContextWindows: Process-wide overrides
+//   - LookupModelContextWindow: Resolve a model ID without an agent
+//   - Agent.GetModelContextWindow: Resolved window for the agent's model
+
+package mcpagent
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+)
+
+const (
+	// ModelContextWindowsEnv lists overrides as "model=tokens,model=tokens"
+	ModelContextWindowsEnv = "MCPAGENT_MODEL_CONTEXT_WINDOWS"
+	// ModelContextFileEnv names a JSON override file ({"model": tokens, ...})
+	ModelContextFileEnv = "MCPAGENT_MODEL_CONTEXT_FILE"
+)
+
+// builtinModelContextWindows are used when neither an override nor provider
+// metadata knows the model. Keys are matched by longest prefix.
+var builtinModelContextWindows = map[string]int{
+	"gpt-4o":            128000,
+	"gpt-4.1":           1047576,
+	"gpt-5":             400000,
+	"o3":                200000,
+	"o4-mini":           200000,
+	"claude-3-5":        200000,
+	"claude-3-7":        200000,
+	"claude-sonnet-4":   200000,
+	"claude-opus-4":     200000,
+	"claude-haiku-4":    200000,
+	"gemini-1.5-pro":    2097152,
+	"gemini-1.5-flash":  1048576,
+	"gemini-2.0-flash":  1048576,
+	"gemini-2.5-pro":    1048576,
+	"gemini-2.5-flash":  1048576,
+	"deepseek-chat":     128000,
+	"deepseek-reasoner": 128000,
+}
+
+var (
+	modelContextOverridesMu sync.RWMutex
+	modelContextOverrides   = map[string]int{}
+	modelContextEnvOnce     sync.Once
+	modelContextEnvErr      error // error loading ModelContextFileEnv, reported by agents
+)
+
+// WithModelContextWindow sets the context-window size (in tokens) for this
+// agent's model, taking precedence over overrides and provider metadata.
+//
+// Default: 0 (resolve from overrides, provider metadata, then built-in defaults)
+func WithModelContextWindow(tokens int) AgentOption {
+	return func(a *Agent) {
+		if tokens > 0 {
+			a.ContextWindowOverride = tokens
+		}
+	}
+}
+
+// RegisterModelContextWindow sets a process-wide context-window override for
+// modelID (or a model ID prefix). tokens <= 0 removes the override.
+func RegisterModelContextWindow(modelID string, tokens int) {
+	modelContextEnvOnce.Do(loadModelContextEnv)
+	setModelContextOverride(modelID, tokens)
+}
+
+func setModelContextOverride(modelID string, tokens int) {
+	modelContextOverridesMu.Lock()
+	defer modelContextOverridesMu.Unlock()
+	if tokens <= 0 {
+		delete(modelContextOverrides, modelID)
+		return
+	}
+	modelContextOverrides[modelID] = tokens
+}
+
+// LoadModelContextWindows registers the overrides in a JSON file mapping model
+// IDs (or prefixes) to context-window sizes, e.g. {"gpt-6": 1000000}.
+func LoadModelContextWindows(path string) error {
+	modelContextEnvOnce.Do(loadModelContextEnv)
+	return loadModelContextFile(path)
+}
+
+func loadModelContextFile(path string) error {
+	data, err := os.ReadFile(path) //nolint:gosec // path is supplied by the operator
+	if err != nil {
+		return fmt.Errorf("failed to read model context file: %w", err)
+	}
+	var windows map[string]int
+	if err := json.Unmarshal(data, &windows); err != nil {
+		return fmt.Errorf("failed to parse model context file %s: %w", path, err)
+	}
+	for modelID, tokens := range windows {
+		setModelContextOverride(modelID, tokens)
+	}
+	return nil
+}
+
+// LookupModelContextWindow resolves modelID against the overrides and the
+// built-in defaults. It returns 0 when the model is unknown.
+func LookupModelContextWindow(modelID string) int {
+	if tokens := lookupModelContextOverride(modelID); tokens > 0 {
+		return tokens
+	}
+	return matchModelContextWindow(builtinModelContextWindows, modelID)
+}
+
+// GetModelContextWindow returns the context-window size of the agent's model
+// in tokens, or 0 if it is unknown. The result is cached on the agent.
+func (a *Agent) GetModelContextWindow() int {
+	a.tokenTrackingMutex.RLock()
+	cached := a.modelContextWindow
+	a.tokenTrackingMutex.RUnlock()
+	if cached > 0 {
+		return cached
+	}
+
+	window := a.resolveModelContextWindow()
+	if window > 0 {
+		a.tokenTrackingMutex.Lock()
+		a.modelContextWindow = window
+		a.tokenTrackingMutex.Unlock()
+	}
+	return window
+}
+
+func (a *Agent) resolveModelContextWindow() int {
+	if a.ContextWindowOverride > 0 {
+		return a.ContextWindowOverride
+	}
+	modelID := a.ModelID
+	if modelID == "" && a.LLM != nil {
+		modelID = a.LLM.GetModelID()
+	}
+	tokens := lookupModelContextOverride(modelID)
+	if modelContextEnvErr != nil && a.Logger != nil {
+		a.Logger.Warn("Ignoring "+ModelContextFileEnv, loggerv2.Error(modelContextEnvErr))
+	}
+	if tokens > 0 {
+		return tokens
+	}
+	if a.LLM != nil {
+		if metadata, err := a.LLM.GetModelMetadata(modelID); err == nil && metadata != nil && metadata.ContextWindow > 0 {
+			return metadata.ContextWindow
+		}
+	}
+	return matchModelContextWindow(builtinModelContextWindows, modelID)
+}
+
+func lookupModelContextOverride(modelID string) int {
+	modelContextEnvOnce.Do(loadModelContextEnv)
+	modelContextOverridesMu.RLock()
+	defer modelContextOverridesMu.RUnlock()
+	return matchModelContextWindow(modelContextOverrides, modelID)
+}
+
+// loadModelContextEnv registers the overrides from the environment. The file
+// is loaded first so the inline variable wins for models listed in both.
+func loadModelContextEnv() {
+	if path := strings.TrimSpace(os.Getenv(ModelContextFileEnv)); path != "" {
+		modelContextEnvErr = loadModelContextFile(path)
+	}
+	for _, entry := range strings.Split(os.Getenv(ModelContextWindowsEnv), ",") {
+		modelID, value, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok {
+			continue
+		}
+		if tokens, err := strconv.Atoi(strings.TrimSpace(value)); err == nil {
+			setModelContextOverride(strings.TrimSpace(modelID), tokens)
+		}
+	}
+}
+
+// matchModelContextWindow returns the window of the exact or longest prefix match for modelID
+func matchModelContextWindow(windows map[string]int, modelID string) int {
+	if modelID == "" {
+		return 0
+	}
+	if tokens, ok := windows[modelID]; ok {
+		return tokens
+	}
+	best, bestLen := 0, 0
+	for prefix, tokens := range windows {
+		if len(prefix) > bestLen && strings.HasPrefix(modelID, prefix) {
+			best, bestLen = tokens, len(prefix)
+		}
+	}
+	return best
+}
@@ -0,0 +1,71 @@
+package mcpagent
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+)
+
+func TestMatchModelContextWindowPrefersLongestPrefix(t *testing.T) {
+	windows := map[string]int{"gemini-2.5": 1000, "gemini-2.5-pro": 2000, "exact": 5}
+
+	cases := map[string]int{
+		"gemini-2.5-pro-preview": 2000,
+		"gemini-2.5-flash":       1000,
+		"exact":                  5,
+		"unknown-model":          0,
+		"":                       0,
+	}
+	for modelID, want := range cases {
+		if got := matchModelContextWindow(windows, modelID); got != want {
+			t.Errorf("matchModelContextWindow(%q) = %d, want %d", modelID, got, want)
+		}
+	}
+}
+
+func TestModelContextWindowOverrides(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "windows.json")
+	if err := os.WriteFile(path, []byte(`{"test-model-from-file": 64000}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := LoadModelContextWindows(path); err != nil {
+		t.Fatalf("LoadModelContextWindows: %v", err)
+	}
+	RegisterModelContextWindow("claude-sonnet-4", 1000000)
+	t.Cleanup(func() {
+		RegisterModelContextWindow("test-model-from-file", 0)
+		RegisterModelContextWindow("claude-sonnet-4", 0)
+	})
+
+	if got := LookupModelContextWindow("test-model-from-file"); got != 64000 {
+		t.Errorf("file override = %d, want 64000", got)
+	}
+	if got := LookupModelContextWindow("claude-sonnet-4-20250514"); got != 1000000 {
+		t.Errorf("registered override should beat the built-in default, got %d", got)
+	}
+	if got := LookupModelContextWindow("gpt-4o-mini"); got != 128000 {
+		t.Errorf("built-in default = %d, want 128000", got)
+	}
+	if err := LoadModelContextWindows(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("expected an error for a missing override file")
+	}
+}
+
+func TestAgentGetModelContextWindow(t *testing.T) {
+	a := &Agent{Logger: loggerv2.NewNoop(), ModelID: "gpt-4o"}
+	if got := a.GetModelContextWindow(); got != 128000 {
+		t.Errorf("GetModelContextWindow() = %d, want built-in 128000", got)
+	}
+
+	a = &Agent{Logger: loggerv2.NewNoop(), ModelID: "gpt-4o"}
+	WithModelContextWindow(32000)(a)
+	if got := a.GetModelContextWindow(); got != 32000 {
+		t.Errorf("GetModelContextWindow() = %d, want agent override 32000", got)
+	}
+
+	if got := (&Agent{ModelID: "brand-new-model"}).GetModelContextWindow(); got != 0 {
+		t.Errorf("expected 0 for an unknown model, got %d", got)
+	}
+}
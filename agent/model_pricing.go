@@ -0,0 +1,328 @@
+// model_pricing.go
+//
+// This file provides the model pricing table used to compute the cost of LLM
+// calls, and the budget limit that aborts conversations once the agent has
+// spent too much. Provider metadata rarely carries per-token prices, so
+// without the table the cost fields of token usage events stay at zero.
+//
+// Resolution order for the price of the agent's model:
+//  1. WithModelPricing on the agent
+//  2. Overrides: RegisterModelPricing, LoadModelPricing and
+//     MCPAGENT_MODEL_PRICING_FILE, keyed by "provider/model" or model ID
+//  3. The provider's model metadata (when it has prices)
+//  4. Built-in prices of well-known model families
+//
+// Model IDs match exactly first. Failing that, the longest key followed only
+// by version and date segments matches, so "claude-sonnet-4" covers
+// "claude-sonnet-4-20250514" and "...-v1:0", but "o3" does not cover the
+// differently priced "o3-pro". Routed IDs such as "anthropic/claude-sonnet-4"
+// (OpenRouter) or "us.anthropic.claude-sonnet-4-..." (Bedrock) also match
+// the built-in entry of the underlying model.
+//
+// Exported:
+//   - ModelPricing: Prices in USD per 1M tokens
+//   - WithModelPricing: Per-agent price override
+//   - RegisterModelPricing / LoadModelPricing: Process-wide overrides
+//   - LookupModelPricing: Resolve a provider and model without an agent
+//   - WithBudgetLimit / BudgetExceededError: Abort conversations over budget
+//   - Agent.GetTotalCost: Cumulative cost of the agent in USD
+
+package mcpagent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/manishiitg/mcpagent/events"
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+)
+
+// ModelPricingFileEnv names a JSON override file ({"provider/model": {...}, ...})
+const ModelPricingFileEnv = "MCPAGENT_MODEL_PRICING_FILE"
+
+// ModelPricing holds the prices of a model in USD per 1M tokens
+type ModelPricing struct {
+	InputPer1M  float64 `json:"input_per_1m"`
+	OutputPer1M float64 `json:"output_per_1m"`
+	// CachedInputPer1M prices cache reads; 0 = cached tokens are free
+	CachedInputPer1M float64 `json:"cached_input_per_1m,omitempty"`
+	// ReasoningPer1M prices reasoning tokens; 0 = the input price
+	ReasoningPer1M float64 `json:"reasoning_per_1m,omitempty"`
+}
+
+// isZero reports whether no price is set
+func (p ModelPricing) isZero() bool {
+	return p.InputPer1M <= 0 && p.OutputPer1M <= 0 && p.CachedInputPer1M <= 0 && p.ReasoningPer1M <= 0
+}
+
+// builtinModelPricing are used when neither an override nor provider
+// metadata prices the model. Keys also match their dated versions.
+var builtinModelPricing = map[string]ModelPricing{
+	"gpt-4o":            {InputPer1M: 2.5, OutputPer1M: 10, CachedInputPer1M: 1.25},
+	"gpt-4o-mini":       {InputPer1M: 0.15, OutputPer1M: 0.6, CachedInputPer1M: 0.075},
+	"gpt-4.1":           {InputPer1M: 2, OutputPer1M: 8, CachedInputPer1M: 0.5},
+	"gpt-4.1-mini":      {InputPer1M: 0.4, OutputPer1M: 1.6, CachedInputPer1M: 0.1},
+	"gpt-4.1-nano":      {InputPer1M: 0.1, OutputPer1M: 0.4, CachedInputPer1M: 0.025},
+	"gpt-5":             {InputPer1M: 1.25, OutputPer1M: 10, CachedInputPer1M: 0.125},
+	"gpt-5-mini":        {InputPer1M: 0.25, OutputPer1M: 2, CachedInputPer1M: 0.025},
+	"gpt-5-nano":        {InputPer1M: 0.05, OutputPer1M: 0.4, CachedInputPer1M: 0.005},
+	"o3":                {InputPer1M: 2, OutputPer1M: 8, CachedInputPer1M: 0.5},
+	"o3-mini":           {InputPer1M: 1.1, OutputPer1M: 4.4, CachedInputPer1M: 0.55},
+	"o4-mini":           {InputPer1M: 1.1, OutputPer1M: 4.4, CachedInputPer1M: 0.275},
+	"claude-3-5-haiku":  {InputPer1M: 0.8, OutputPer1M: 4, CachedInputPer1M: 0.08},
+	"claude-3-7-sonnet": {InputPer1M: 3, OutputPer1M: 15, CachedInputPer1M: 0.3},
+	"claude-sonnet-4":   {InputPer1M: 3, OutputPer1M: 15, CachedInputPer1M: 0.3},
+	"claude-opus-4":     {InputPer1M: 15, OutputPer1M: 75, CachedInputPer1M: 1.5},
+	"claude-haiku-4":    {InputPer1M: 1, OutputPer1M: 5, CachedInputPer1M: 0.1},
+	"gemini-2.0-flash":  {InputPer1M: 0.1, OutputPer1M: 0.4, CachedInputPer1M: 0.025},
+	"gemini-2.5-pro":    {InputPer1M: 1.25, OutputPer1M: 10, CachedInputPer1M: 0.31},
+	"gemini-2.5-flash":  {InputPer1M: 0.3, OutputPer1M: 2.5, CachedInputPer1M: 0.075},
+	"deepseek-chat":     {InputPer1M: 0.27, OutputPer1M: 1.1, CachedInputPer1M: 0.07},
+	"deepseek-reasoner": {InputPer1M: 0.55, OutputPer1M: 2.19, CachedInputPer1M: 0.14},
+}
+
+var (
+	modelPricingOverridesMu sync.RWMutex
+	modelPricingOverrides   = map[string]ModelPricing{}
+	modelPricingEnvOnce     sync.Once
+	modelPricingEnvErr      error // error loading ModelPricingFileEnv, reported by agents
+	modelPricingEnvWarnOnce sync.Once
+)
+
+// WithModelPricing sets the prices of this agent's model, taking precedence
+// over overrides, provider metadata and the built-in table.
+//
+// Default: unset (resolve from overrides, provider metadata, then built-in prices)
+func WithModelPricing(pricing ModelPricing) AgentOption {
+	return func(a *Agent) {
+		if !pricing.isZero() {
+			a.ModelPricingOverride = &pricing
+		}
+	}
+}
+
+// WithBudgetLimit aborts the conversation with a *BudgetExceededError once
+// the agent's cumulative cost (including sub-agents) crosses usd. The limit is
+// checked after every LLM call, so the call that crosses it completes and is
+// counted. Costs come from the pricing table; calls to models without a known
+// price cost nothing and never trigger the limit. usd <= 0 disables the limit.
+//
+// Default: 0 (no limit)
+func WithBudgetLimit(usd float64) AgentOption {
+	return func(a *Agent) {
+		if usd < 0 {
+			usd = 0
+		}
+		a.BudgetLimitUSD = usd
+	}
+}
+
+// BudgetExceededError is returned when a conversation is aborted because the
+// agent's cumulative cost crossed its budget limit
+type BudgetExceededError struct {
+	LimitUSD float64
+	SpentUSD float64
+}
+
+func (e *BudgetExceededError) Error() string {
+	return fmt.Sprintf("budget limit of $%.4f exceeded: spent $%.4f", e.LimitUSD, e.SpentUSD)
+}
+
+// Is makes a *BudgetExceededError match ErrBudgetExceeded
+func (e *BudgetExceededError) Is(target error) bool {
+	return target == ErrBudgetExceeded
+}
+
+// RegisterModelPricing sets process-wide prices for key, which is either
+// "provider/model" (e.g. "openrouter/gpt-4o") or a model ID, which also
+// covers the dated versions of the model. A zero ModelPricing removes the
+// override.
+func RegisterModelPricing(key string, pricing ModelPricing) {
+	modelPricingEnvOnce.Do(loadModelPricingEnv)
+	setModelPricingOverride(key, pricing)
+}
+
+func setModelPricingOverride(key string, pricing ModelPricing) {
+	modelPricingOverridesMu.Lock()
+	defer modelPricingOverridesMu.Unlock()
+	if pricing.isZero() {
+		delete(modelPricingOverrides, key)
+		return
+	}
+	modelPricingOverrides[key] = pricing
+}
+
+// LoadModelPricing registers the overrides in a JSON file mapping keys (see
+// RegisterModelPricing) to prices, e.g.
+// {"gpt-6": {"input_per_1m": 5, "output_per_1m": 20}}.
+func LoadModelPricing(path string) error {
+	modelPricingEnvOnce.Do(loadModelPricingEnv)
+	return loadModelPricingFile(path)
+}
+
+func loadModelPricingFile(path string) error {
+	data, err := os.ReadFile(path) //nolint:gosec // path is supplied by the operator
+	if err != nil {
+		return fmt.Errorf("failed to read model pricing file: %w", err)
+	}
+	var prices map[string]ModelPricing
+	if err := json.Unmarshal(data, &prices); err != nil {
+		return fmt.Errorf("failed to parse model pricing file %s: %w", path, err)
+	}
+	for key, pricing := range prices {
+		setModelPricingOverride(key, pricing)
+	}
+	return nil
+}
+
+// loadModelPricingEnv registers the overrides of ModelPricingFileEnv
+func loadModelPricingEnv() {
+	if path := strings.TrimSpace(os.Getenv(ModelPricingFileEnv)); path != "" {
+		modelPricingEnvErr = loadModelPricingFile(path)
+	}
+}
+
+// LookupModelPricing resolves the prices of modelID served by provider
+// against the overrides and the built-in table. ok is false when the model
+// is unknown.
+func LookupModelPricing(provider, modelID string) (pricing ModelPricing, ok bool) {
+	if pricing, ok := lookupModelPricingOverride(provider, modelID); ok {
+		return pricing, true
+	}
+	return matchModelPricing(builtinModelPricing, baseModelID(modelID))
+}
+
+// GetTotalCost returns the agent's cumulative cost in USD
+func (a *Agent) GetTotalCost() float64 {
+	a.tokenTrackingMutex.RLock()
+	defer a.tokenTrackingMutex.RUnlock()
+	return a.cumulativeTotalCost
+}
+
+// resolveModelPricing returns the prices of modelID for this agent
+func (a *Agent) resolveModelPricing(modelID string) (ModelPricing, bool) {
+	if a.ModelPricingOverride != nil {
+		return *a.ModelPricingOverride, true
+	}
+	pricing, ok := lookupModelPricingOverride(string(a.provider), modelID)
+	if modelPricingEnvErr != nil && a.Logger != nil {
+		modelPricingEnvWarnOnce.Do(func() {
+			a.Logger.Warn("Ignoring "+ModelPricingFileEnv, loggerv2.Error(modelPricingEnvErr))
+		})
+	}
+	if ok {
+		return pricing, true
+	}
+	if a.LLM != nil {
+		if metadata, err := a.LLM.GetModelMetadata(modelID); err == nil && metadata != nil {
+			pricing = ModelPricing{
+				InputPer1M:       metadata.InputCostPer1MTokens,
+				OutputPer1M:      metadata.OutputCostPer1MTokens,
+				CachedInputPer1M: metadata.CachedInputCostPer1MTokens,
+				ReasoningPer1M:   metadata.ReasoningCostPer1MTokens,
+			}
+			if !pricing.isZero() {
+				return pricing, true
+			}
+		}
+	}
+	return matchModelPricing(builtinModelPricing, baseModelID(modelID))
+}
+
+func lookupModelPricingOverride(provider, modelID string) (ModelPricing, bool) {
+	modelPricingEnvOnce.Do(loadModelPricingEnv)
+	modelPricingOverridesMu.RLock()
+	defer modelPricingOverridesMu.RUnlock()
+	if provider != "" {
+		if pricing, ok := matchModelPricing(modelPricingOverrides, provider+"/"+modelID); ok {
+			return pricing, true
+		}
+	}
+	return matchModelPricing(modelPricingOverrides, modelID)
+}
+
+// baseModelID strips routing prefixes ("anthropic/", "us.anthropic.") so
+// routed model IDs match the built-in entry of the underlying model
+func baseModelID(modelID string) string {
+	if i := strings.LastIndex(modelID, "/"); i >= 0 {
+		modelID = modelID[i+1:]
+	}
+	if i := strings.Index(modelID, "anthropic."); i >= 0 {
+		modelID = modelID[i+len("anthropic."):]
+	}
+	return modelID
+}
+
+// matchModelPricing returns the prices of the exact match for modelID, else
+// of the longest key modelID extends with version segments only
+func matchModelPricing(prices map[string]ModelPricing, modelID string) (ModelPricing, bool) {
+	if modelID == "" {
+		return ModelPricing{}, false
+	}
+	if pricing, ok := prices[modelID]; ok {
+		return pricing, true
+	}
+	var best ModelPricing
+	bestLen := 0
+	for key, pricing := range prices {
+		if len(key) > bestLen && strings.HasPrefix(modelID, key) && isModelVersionSuffix(modelID[len(key):]) {
+			best, bestLen = pricing, len(key)
+		}
+	}
+	return best, bestLen > 0
+}
+
+// isModelVersionSuffix reports whether suffix, what follows a pricing key in
+// a model ID, only names a version of the same model: "-" or "@" separated
+// segments that are numbers (dates, minor versions), "latest", "preview",
+// "exp" or "v1"-style revisions ("-20250514-v1:0", "@20250514", "-001").
+// Anything else ("-pro", "-mini") names a different model.
+func isModelVersionSuffix(suffix string) bool {
+	if suffix == "" || (suffix[0] != '-' && suffix[0] != '@') {
+		return false
+	}
+	for _, segment := range strings.FieldsFunc(suffix, func(r rune) bool { return r == '-' || r == '@' }) {
+		switch {
+		case segment == "latest" || segment == "preview" || segment == "exp":
+		case isDigits(segment):
+		case len(segment) > 1 && segment[0] == 'v' && isDigits(strings.Replace(segment[1:], ":", "", 1)):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// checkBudget returns a *BudgetExceededError, and emits a BudgetExceeded
+// event, when the agent's cumulative cost has crossed its budget limit
+func (a *Agent) checkBudget(ctx context.Context, turn int) error {
+	if a.BudgetLimitUSD <= 0 {
+		return nil
+	}
+	spent := a.GetTotalCost()
+	if spent <= a.BudgetLimitUSD {
+		return nil
+	}
+	getLogger(a).Warn("💰 [BUDGET] Budget limit exceeded, aborting conversation",
+		loggerv2.Any("limit_usd", a.BudgetLimitUSD),
+		loggerv2.Any("spent_usd", spent),
+		loggerv2.Int("turn", turn))
+	a.EmitTypedEvent(ctx, events.NewBudgetExceededEvent(turn, a.BudgetLimitUSD, spent))
+	return &BudgetExceededError{LimitUSD: a.BudgetLimitUSD, SpentUSD: spent}
+}
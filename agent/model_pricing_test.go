@@ -0,0 +1,127 @@
+package mcpagent
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/manishiitg/mcpagent/events"
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+func TestLookupModelPricing(t *testing.T) {
+	cases := map[string]float64{
+		"claude-sonnet-4-20250514":                 3,
+		"anthropic/claude-sonnet-4":                3,
+		"us.anthropic.claude-opus-4-20250514-v1:0": 15,
+		"gpt-4o-mini-2024-07-18":                   0.15,
+		"gpt-4o-2024-08-06":                        2.5,
+		"gemini-2.5-flash-preview-05-20":           0.3,
+		"deepseek/deepseek-reasoner":               0.55,
+		"claude-sonnet-4@20250514":                 3,
+		"gemini-2.0-flash-001":                     0.1,
+		"o3-2025-04-16":                            2,
+		"o3-pro":                                   0,
+		"gpt-4o-realtime-preview":                  0,
+		"gemini-2.5-flash-lite":                    0,
+		"some-unknown-model":                       0,
+	}
+	for modelID, want := range cases {
+		pricing, ok := LookupModelPricing("", modelID)
+		if pricing.InputPer1M != want || ok != (want > 0) {
+			t.Errorf("LookupModelPricing(%q) = %+v, %v; want input price %v", modelID, pricing, ok, want)
+		}
+	}
+}
+
+func TestModelPricingOverrides(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pricing.json")
+	if err := os.WriteFile(path, []byte(`{"test-pricing-from-file": {"input_per_1m": 4, "output_per_1m": 16}}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := LoadModelPricing(path); err != nil {
+		t.Fatal(err)
+	}
+	RegisterModelPricing("test-provider/gpt-4o", ModelPricing{InputPer1M: 9, OutputPer1M: 9})
+	defer func() {
+		RegisterModelPricing("test-pricing-from-file", ModelPricing{})
+		RegisterModelPricing("test-provider/gpt-4o", ModelPricing{})
+	}()
+
+	if pricing, _ := LookupModelPricing("", "test-pricing-from-file-v2"); pricing.OutputPer1M != 16 {
+		t.Errorf("file override = %+v", pricing)
+	}
+	if pricing, _ := LookupModelPricing("test-provider", "gpt-4o"); pricing.InputPer1M != 9 {
+		t.Errorf("provider override = %+v", pricing)
+	}
+	if pricing, _ := LookupModelPricing("openai", "gpt-4o"); pricing.InputPer1M != 2.5 {
+		t.Errorf("other providers should keep the built-in price, got %+v", pricing)
+	}
+
+	a := &Agent{Logger: loggerv2.NewNoop(), provider: "test-provider"}
+	WithModelPricing(ModelPricing{InputPer1M: 1, OutputPer1M: 2})(a)
+	if pricing, _ := a.resolveModelPricing("gpt-4o"); pricing.InputPer1M != 1 {
+		t.Errorf("agent override = %+v, want it to take precedence", pricing)
+	}
+}
+
+func TestBudgetLimitAbortsConversation(t *testing.T) {
+	config := filepath.Join(t.TempDir(), "mcp.json")
+	if err := os.WriteFile(config, []byte(`{"mcpServers": {}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	generate := &usageGenerateStage{scriptedGenerateStage{responses: []*llmtypes.ContentResponse{{
+		Choices: []*llmtypes.ContentChoice{{Content: "partial answer"}},
+		Usage:   &llmtypes.Usage{InputTokens: 2000, OutputTokens: 500, TotalTokens: 2500},
+	}}}}
+	ctx := context.Background()
+	a, err := NewAgent(ctx, &providerKeyCarrierModel{}, config,
+		WithLogger(loggerv2.NewNoop()),
+		WithModelPricing(ModelPricing{InputPer1M: 1, OutputPer1M: 2}),
+		WithBudgetLimit(0.002),
+		WithAskPipeline(AskPipeline{Generate: generate}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer a.Close()
+	listener := &recordingAgentEventListener{}
+	a.AddEventListener(listener)
+
+	_, _, err = a.AskWithHistory(ctx, []llmtypes.MessageContent{llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "hi")})
+	var budgetErr *BudgetExceededError
+	if !errors.As(err, &budgetErr) {
+		t.Fatalf("err = %v, want a *BudgetExceededError", err)
+	}
+	if budgetErr.LimitUSD != 0.002 || budgetErr.SpentUSD < 0.00299 || budgetErr.SpentUSD > 0.00301 {
+		t.Errorf("err = %+v, want $0.003 spent of $0.002", budgetErr)
+	}
+	if cost := a.GetTotalCost(); cost != budgetErr.SpentUSD {
+		t.Errorf("GetTotalCost = %v, want %v", cost, budgetErr.SpentUSD)
+	}
+
+	var exceeded, callCost int
+	for _, event := range listener.events {
+		switch data := event.Data.(type) {
+		case *events.BudgetExceededEvent:
+			exceeded++
+		case *events.LLMGenerationEndEvent:
+			if data.Metadata["call_cost_usd"] != nil {
+				callCost++
+			}
+		}
+	}
+	if exceeded != 1 || callCost != 1 {
+		t.Errorf("got %d budget_exceeded events and %d priced generations, want 1 each", exceeded, callCost)
+	}
+
+	// An agent over budget makes no further LLM calls
+	if _, _, err = a.AskWithHistory(ctx, []llmtypes.MessageContent{llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "again")}); !errors.As(err, &budgetErr) {
+		t.Errorf("second call err = %v, want a *BudgetExceededError", err)
+	}
+	if generate.calls != 1 {
+		t.Errorf("generate called %d times, want 1", generate.calls)
+	}
+}
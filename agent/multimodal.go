@@ -0,0 +1,230 @@
+// multimodal.go
+//
+// This file lets callers send images and PDFs with a question. An Attachment
+// is given as raw bytes, base64 (optionally a data: URL), a file path or a
+// public URL; it is resolved to an llmtypes.ImageContent or DocumentContent
+// part and checked against what the agent's model and provider accept before
+// anything is sent. Events only carry attachment metadata (type, media type,
+// size or URL), never the data.
+//
+// Exported:
+//   - Attachment: An image or document to send with a question
+//   - ErrAttachmentNotSupported: The model or provider cannot take an attachment
+//   - Agent.AskMultiModal: Ask with attachments
+//   - Agent.MultiModalMessage: Build the user message for AskWithHistory
+
+package mcpagent
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/manishiitg/mcpagent/llm"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+// MaxAttachmentBytes is the largest attachment accepted, after base64 decoding
+const MaxAttachmentBytes = 32 * 1024 * 1024
+
+// ErrAttachmentNotSupported is returned when the agent's model or provider
+// cannot take an attachment
+var ErrAttachmentNotSupported = errors.New("attachment not supported")
+
+// attachmentImageTypes are the image media types providers accept
+var attachmentImageTypes = map[string]bool{
+	"image/png":  true,
+	"image/jpeg": true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+// pdfMediaType is the only document type providers accept
+const pdfMediaType = "application/pdf"
+
+// Attachment is an image or PDF sent with a question. Set exactly one of
+// Data, Base64, Path or URL.
+type Attachment struct {
+	// Name labels the attachment (documents pass it to the provider as title);
+	// defaults to the file name of Path or URL
+	Name string
+	// MediaType such as "image/png" or "application/pdf"; detected from the
+	// file extension or content when empty
+	MediaType string
+
+	Data   []byte // Raw bytes
+	Base64 string // Base64-encoded bytes, or a "data:<type>;base64,..." URL
+	Path   string // File to read
+	URL    string // Public URL the provider fetches
+}
+
+// AskMultiModal asks a question with images or PDFs attached. Attachments
+// are validated before the conversation starts: an image for a model without
+// vision, or a PDF for a provider without document input, fails with
+// ErrAttachmentNotSupported.
+//
+// Example:
+//
+//	answer, err := agent.AskMultiModal(ctx, "What does this chart show?", []mcpagent.Attachment{
+//	    {Path: "reports/q3-revenue.png"},
+//	    {URL: "https://example.com/q3-report.pdf"},
+//	})
+func (a *Agent) AskMultiModal(ctx context.Context, text string, attachments []Attachment, opts ...CallOption) (string, error) {
+	userMessage, err := a.MultiModalMessage(text, attachments)
+	if err != nil {
+		return "", err
+	}
+	answer, _, err := AskWithHistory(a, ctx, []llmtypes.MessageContent{userMessage}, opts...)
+	return answer, err
+}
+
+// MultiModalMessage builds a user message with text and attachments, validated
+// for the agent's model and provider, to append to the history passed to
+// AskWithHistory.
+func (a *Agent) MultiModalMessage(text string, attachments []Attachment) (llmtypes.MessageContent, error) {
+	parts := []llmtypes.ContentPart{llmtypes.TextContent{Text: text}}
+	for i, attachment := range attachments {
+		part, err := a.attachmentPart(attachment)
+		if err != nil {
+			return llmtypes.MessageContent{}, fmt.Errorf("attachment %d (%s): %w", i+1, attachment.label(), err)
+		}
+		parts = append(parts, part)
+	}
+	return llmtypes.MessageContent{Role: llmtypes.ChatMessageTypeHuman, Parts: parts}, nil
+}
+
+// label names the attachment in errors
+func (at Attachment) label() string {
+	switch {
+	case at.Name != "":
+		return at.Name
+	case at.Path != "":
+		return at.Path
+	case at.URL != "":
+		return at.URL
+	default:
+		return "inline data"
+	}
+}
+
+// attachmentPart resolves an attachment into an image or document part
+func (a *Agent) attachmentPart(at Attachment) (llmtypes.ContentPart, error) {
+	sources := 0
+	for _, set := range []bool{at.Data != nil, at.Base64 != "", at.Path != "", at.URL != ""} {
+		if set {
+			sources++
+		}
+	}
+	if sources != 1 {
+		return nil, fmt.Errorf("set exactly one of Data, Base64, Path or URL")
+	}
+
+	mediaType, name := at.MediaType, at.Name
+	sourceType, data := "base64", ""
+	switch {
+	case at.URL != "":
+		parsed, err := url.Parse(at.URL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+			return nil, fmt.Errorf("invalid URL %q", at.URL)
+		}
+		sourceType, data = "url", at.URL
+		if mediaType == "" {
+			mediaType = mime.TypeByExtension(path.Ext(parsed.Path))
+		}
+		if name == "" {
+			name = path.Base(parsed.Path)
+		}
+	default:
+		raw, detected, err := at.bytes()
+		if err != nil {
+			return nil, err
+		}
+		if len(raw) == 0 {
+			return nil, fmt.Errorf("attachment is empty")
+		}
+		if len(raw) > MaxAttachmentBytes {
+			return nil, fmt.Errorf("attachment is %d bytes, the limit is %d", len(raw), MaxAttachmentBytes)
+		}
+		if mediaType == "" {
+			mediaType = detected
+		}
+		if mediaType == "" {
+			mediaType = http.DetectContentType(raw)
+		}
+		if name == "" && at.Path != "" {
+			name = filepath.Base(at.Path)
+		}
+		data = base64.StdEncoding.EncodeToString(raw)
+	}
+	mediaType, _, _ = mime.ParseMediaType(mediaType)
+
+	switch {
+	case attachmentImageTypes[mediaType]:
+		if !modelSupportsVision(a.ModelID) {
+			return nil, fmt.Errorf("%w: model %q does not accept images", ErrAttachmentNotSupported, a.ModelID)
+		}
+		return llmtypes.ImageContent{SourceType: sourceType, MediaType: mediaType, Data: data}, nil
+	case mediaType == pdfMediaType:
+		if err := a.checkDocumentSupport(sourceType); err != nil {
+			return nil, err
+		}
+		return llmtypes.DocumentContent{SourceType: sourceType, MediaType: mediaType, Data: data, Title: name}, nil
+	case mediaType == "":
+		return nil, fmt.Errorf("unknown media type, set Attachment.MediaType")
+	default:
+		return nil, fmt.Errorf("%w: media type %q (supported: PNG, JPEG, GIF and WebP images, PDF)", ErrAttachmentNotSupported, mediaType)
+	}
+}
+
+// bytes returns the raw bytes of an inline or file attachment and the media
+// type they declare (data: URL or file extension)
+func (at Attachment) bytes() ([]byte, string, error) {
+	switch {
+	case at.Data != nil:
+		return at.Data, "", nil
+	case at.Path != "":
+		raw, err := os.ReadFile(at.Path)
+		if err != nil {
+			return nil, "", err
+		}
+		return raw, mime.TypeByExtension(filepath.Ext(at.Path)), nil
+	default:
+		encoded, mediaType := at.Base64, ""
+		if rest, ok := strings.CutPrefix(encoded, "data:"); ok {
+			header, payload, found := strings.Cut(rest, ",")
+			if !found || !strings.HasSuffix(header, ";base64") {
+				return nil, "", fmt.Errorf("data URL is not base64-encoded")
+			}
+			encoded, mediaType = payload, strings.TrimSuffix(header, ";base64")
+		}
+		raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid base64: %w", err)
+		}
+		return raw, mediaType, nil
+	}
+}
+
+// checkDocumentSupport reports whether the agent's provider accepts PDFs from sourceType
+func (a *Agent) checkDocumentSupport(sourceType string) error {
+	switch a.provider {
+	case llm.ProviderAnthropic:
+		return nil
+	case llm.ProviderOpenAI:
+		// Chat Completions only takes inline PDFs
+		if sourceType == "base64" {
+			return nil
+		}
+		return fmt.Errorf("%w: provider %q does not accept PDF URLs, attach the file instead", ErrAttachmentNotSupported, a.provider)
+	default:
+		return fmt.Errorf("%w: provider %q does not accept PDF documents", ErrAttachmentNotSupported, a.provider)
+	}
+}
@@ -0,0 +1,111 @@
+package mcpagent
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/manishiitg/mcpagent/events"
+	"github.com/manishiitg/mcpagent/llm"
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+// pngHeader is enough for content sniffing to detect a PNG
+var pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+func TestMultiModalMessageResolvesAttachments(t *testing.T) {
+	pdfPath := filepath.Join(t.TempDir(), "q3-report.pdf")
+	if err := os.WriteFile(pdfPath, []byte("%PDF-1.7 report"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	a := &Agent{ModelID: "claude-sonnet-4-5", provider: llm.ProviderAnthropic}
+
+	msg, err := a.MultiModalMessage("compare these", []Attachment{
+		{Data: pngHeader},
+		{Base64: "data:image/jpeg;base64," + base64.StdEncoding.EncodeToString([]byte("jpeg bytes"))},
+		{URL: "https://example.com/charts/revenue.webp"},
+		{Path: pdfPath},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.Role != llmtypes.ChatMessageTypeHuman || len(msg.Parts) != 5 {
+		t.Fatalf("unexpected message: %+v", msg)
+	}
+	if text, ok := msg.Parts[0].(llmtypes.TextContent); !ok || text.Text != "compare these" {
+		t.Errorf("first part = %+v, want the text", msg.Parts[0])
+	}
+	wantImages := []llmtypes.ImageContent{
+		{SourceType: "base64", MediaType: "image/png", Data: base64.StdEncoding.EncodeToString(pngHeader)},
+		{SourceType: "base64", MediaType: "image/jpeg", Data: base64.StdEncoding.EncodeToString([]byte("jpeg bytes"))},
+		{SourceType: "url", MediaType: "image/webp", Data: "https://example.com/charts/revenue.webp"},
+	}
+	for i, want := range wantImages {
+		if got := msg.Parts[i+1]; got != want {
+			t.Errorf("part %d = %+v, want %+v", i+1, got, want)
+		}
+	}
+	doc, ok := msg.Parts[4].(llmtypes.DocumentContent)
+	if !ok || doc.MediaType != "application/pdf" || doc.Title != "q3-report.pdf" || doc.SourceType != "base64" {
+		t.Errorf("PDF part = %+v", msg.Parts[4])
+	}
+}
+
+func TestMultiModalMessageValidatesSupport(t *testing.T) {
+	textOnly := &Agent{ModelID: "llama-3-70b", provider: llm.ProviderOpenAI}
+	if _, err := textOnly.MultiModalMessage("what is this?", []Attachment{{Data: pngHeader}}); !errors.Is(err, ErrAttachmentNotSupported) {
+		t.Errorf("image for a text-only model: error = %v, want ErrAttachmentNotSupported", err)
+	}
+
+	openAI := &Agent{ModelID: "gpt-4o", provider: llm.ProviderOpenAI}
+	if _, err := openAI.MultiModalMessage("summarize", []Attachment{{URL: "https://example.com/report.pdf"}}); !errors.Is(err, ErrAttachmentNotSupported) {
+		t.Errorf("PDF URL on OpenAI: error = %v, want ErrAttachmentNotSupported", err)
+	}
+	if _, err := openAI.MultiModalMessage("summarize", []Attachment{{Data: []byte("%PDF-1.7"), MediaType: "application/pdf"}}); err != nil {
+		t.Errorf("inline PDF on OpenAI: %v", err)
+	}
+	if _, err := openAI.MultiModalMessage("read", []Attachment{{Data: []byte("plain text"), MediaType: "text/plain"}}); !errors.Is(err, ErrAttachmentNotSupported) {
+		t.Errorf("text/plain attachment: error = %v, want ErrAttachmentNotSupported", err)
+	}
+	if _, err := openAI.MultiModalMessage("read", []Attachment{{Data: pngHeader, URL: "https://example.com/a.png"}}); err == nil {
+		t.Error("an attachment with two sources must be rejected")
+	}
+}
+
+func TestAskMultiModalEmitsAttachmentMetadataOnly(t *testing.T) {
+	listener := &recordingAgentEventListener{}
+	a := &Agent{Logger: loggerv2.NewNoop(), LLM: &providerKeyCarrierModel{}, ModelID: "gpt-4o", provider: llm.ProviderOpenAI, MaxTurns: 2}
+	a.AddEventListener(listener)
+	WithAskPipeline(AskPipeline{Generate: &scriptedGenerateStage{responses: []*llmtypes.ContentResponse{
+		{Choices: []*llmtypes.ContentChoice{{Content: "a revenue chart"}}},
+	}}})(a)
+
+	answer, err := a.AskMultiModal(context.Background(), "what is this?", []Attachment{{Data: pngHeader}})
+	if err != nil || answer != "a revenue chart" {
+		t.Fatalf("AskMultiModal() = %q, %v", answer, err)
+	}
+
+	encoded := base64.StdEncoding.EncodeToString(pngHeader)
+	var found bool
+	for _, event := range listener.events {
+		data, err := json.Marshal(event)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(string(data), encoded) {
+			t.Errorf("%s event carries the attachment data", event.Type)
+		}
+		if msg, ok := event.Data.(*events.UserMessageEvent); ok && len(msg.Attachments) == 1 {
+			found = msg.Attachments[0].Type == "image" && msg.Attachments[0].MediaType == "image/png"
+		}
+	}
+	if !found {
+		t.Error("user_message event has no attachment metadata")
+	}
+}
@@ -0,0 +1,189 @@
+// output_guard.go
+//
+// This file runs final answers through a user-supplied validator before they
+// are returned. Validators can match a regular expression, validate JSON
+// against a schema (the checks of answer_contract.go), ask an LLM critic, or
+// be any OutputValidator. A rejected answer is sent back to the agent with
+// the validation error, at most MaxRepairs times; when the repairs are used
+// up the conversation ends with an *OutputValidationError carrying the last
+// answer. Rejections emit OutputValidationFailed events, and an answer that
+// passes after at least one rejection emits OutputValidationRepaired.
+//
+// The guard runs after the answer contract (see WithAnswerContract), so it
+// sees the answer the contract hands out.
+//
+// Exported:
+//   - OutputValidator / OutputValidatorFunc: Validator interface
+//   - RegexOutputValidator / JSONSchemaOutputValidator / LLMCriticOutputValidator: Built-in validators
+//   - WithOutputGuard: Enable the guard when creating an agent
+//   - OutputValidationError: Returned when the answer still fails validation
+
+package mcpagent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/manishiitg/mcpagent/events"
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+// DefaultOutputGuardRepairs is the number of repair re-asks per conversation
+const DefaultOutputGuardRepairs = 2
+
+// OutputValidator checks a final answer. It returns nil to accept the answer,
+// or an error telling the agent what to fix.
+type OutputValidator interface {
+	ValidateOutput(ctx context.Context, answer string) error
+}
+
+// OutputValidatorFunc adapts a function to OutputValidator
+type OutputValidatorFunc func(ctx context.Context, answer string) error
+
+// ValidateOutput calls f
+func (f OutputValidatorFunc) ValidateOutput(ctx context.Context, answer string) error {
+	return f(ctx, answer)
+}
+
+// RegexOutputValidator accepts answers matching pattern
+func RegexOutputValidator(pattern *regexp.Regexp) OutputValidator {
+	return OutputValidatorFunc(func(_ context.Context, answer string) error {
+		if !pattern.MatchString(answer) {
+			return fmt.Errorf("the answer must match the pattern %s", pattern)
+		}
+		return nil
+	})
+}
+
+// JSONSchemaOutputValidator accepts answers that are a JSON document (one
+// surrounding ```json fence is tolerated) valid against schema
+func JSONSchemaOutputValidator(schema string) OutputValidator {
+	return OutputValidatorFunc(func(_ context.Context, answer string) error {
+		_, violations, err := checkContractJSON(schema, answer)
+		if err != nil {
+			return fmt.Errorf("invalid output schema: %w", err)
+		}
+		if len(violations) > 0 {
+			return errors.New(strings.Join(violations, "; "))
+		}
+		return nil
+	})
+}
+
+// outputCriticPrompt instructs the LLM critic
+const outputCriticPrompt = `You review the final answer of an AI assistant against acceptance criteria.
+
+Acceptance criteria:
+%s
+
+Answer:
+%s
+
+Respond with ONLY a JSON object: {"valid": true} when the answer meets every criterion, or {"valid": false, "problems": ["..."]} listing what the assistant must fix.`
+
+// LLMCriticOutputValidator asks model whether answers meet criteria. If the
+// critic fails or responds with something other than a verdict, the answer
+// is accepted, so an unavailable critic does not block conversations.
+func LLMCriticOutputValidator(model llmtypes.Model, criteria string) OutputValidator {
+	return OutputValidatorFunc(func(ctx context.Context, answer string) error {
+		messages := []llmtypes.MessageContent{
+			llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, fmt.Sprintf(outputCriticPrompt, criteria, answer)),
+		}
+		resp, err := model.GenerateContent(ctx, messages, llmtypes.WithTemperature(0), llmtypes.WithJSONMode())
+		if err != nil || resp == nil || len(resp.Choices) == 0 {
+			return nil
+		}
+		var verdict struct {
+			Valid    *bool    `json:"valid"`
+			Problems []string `json:"problems"`
+		}
+		if err := json.Unmarshal([]byte(stripJSONFence(resp.Choices[0].Content)), &verdict); err != nil || verdict.Valid == nil || *verdict.Valid {
+			return nil
+		}
+		if len(verdict.Problems) == 0 {
+			return errors.New("the answer does not meet the acceptance criteria")
+		}
+		return errors.New(strings.Join(verdict.Problems, "; "))
+	})
+}
+
+// outputGuard is the guard set by WithOutputGuard
+type outputGuard struct {
+	validator  OutputValidator
+	maxRepairs int
+}
+
+// OutputValidationError is returned when the final answer still fails
+// validation after the repair re-asks
+type OutputValidationError struct {
+	Err     error
+	Repairs int
+	// Answer is the last answer the agent gave
+	Answer string
+}
+
+func (e *OutputValidationError) Error() string {
+	return fmt.Sprintf("final answer failed output validation after %d repairs: %v", e.Repairs, e.Err)
+}
+
+func (e *OutputValidationError) Unwrap() error {
+	return e.Err
+}
+
+// WithOutputGuard runs every final answer through validator. A rejected
+// answer is sent back with the validation error up to maxRepairs times
+// (0 = fail on the first rejection, negative = DefaultOutputGuardRepairs)
+// before the conversation fails with an *OutputValidationError.
+//
+// Example:
+//
+//	mcpagent.WithOutputGuard(mcpagent.LLMCriticOutputValidator(cheapLLM,
+//	    "Cites a source URL for every figure. Does not recommend specific stocks."), 2)
+//
+// Default: disabled
+func WithOutputGuard(validator OutputValidator, maxRepairs int) AgentOption {
+	return func(a *Agent) {
+		if validator == nil {
+			a.outputGuard = nil
+			return
+		}
+		if maxRepairs < 0 {
+			maxRepairs = DefaultOutputGuardRepairs
+		}
+		a.outputGuard = &outputGuard{validator: validator, maxRepairs: maxRepairs}
+	}
+}
+
+// checkOutputGuard validates answer and emits the outcome. It returns the
+// validation error, nil when the answer is accepted.
+func (a *Agent) checkOutputGuard(ctx context.Context, answer string, turn, repairs int) error {
+	g := a.outputGuard
+	err := g.validator.ValidateOutput(ctx, answer)
+	logger := getLogger(a)
+	if err == nil {
+		if repairs > 0 {
+			logger.Info("🛡️ [OUTPUT_GUARD] Final answer repaired", loggerv2.Int("repairs", repairs))
+			a.EmitTypedEvent(ctx, events.NewOutputValidationRepairedEvent(turn+1, repairs))
+		}
+		return nil
+	}
+
+	exhausted := repairs >= g.maxRepairs
+	logger.Warn("🛡️ [OUTPUT_GUARD] Final answer failed validation",
+		loggerv2.Error(err),
+		loggerv2.Int("repairs", repairs),
+		loggerv2.Any("repairs_exhausted", exhausted))
+	a.EmitTypedEvent(ctx, events.NewOutputValidationFailedEvent(turn+1, err.Error(), repairs, exhausted))
+	return err
+}
+
+// outputGuardRepairMessage asks the agent to fix the validation error
+func outputGuardRepairMessage(err error) llmtypes.MessageContent {
+	return llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "Your final answer failed validation:\n"+err.Error()+
+		"\n\nRewrite your final answer so it passes. Do not call tools unless information is missing.")
+}
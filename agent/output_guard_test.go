@@ -0,0 +1,78 @@
+package mcpagent
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/manishiitg/mcpagent/events"
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+func TestOutputGuardRepairsRejectedAnswers(t *testing.T) {
+	generate := &scriptedGenerateStage{responses: []*llmtypes.ContentResponse{
+		{Choices: []*llmtypes.ContentChoice{{Content: "Revenue grew a lot."}}},
+		{Choices: []*llmtypes.ContentChoice{{Content: "Revenue grew 12% (source: https://example.com/q3)."}}},
+	}}
+	listener := &recordingAgentEventListener{}
+	a := &Agent{Logger: loggerv2.NewNoop(), LLM: &providerKeyCarrierModel{}, ModelID: "test-model", MaxTurns: 5}
+	a.AddEventListener(listener)
+	WithAskPipeline(AskPipeline{Generate: generate, DispatchTools: &recordingToolDispatcher{}})(a)
+	WithOutputGuard(RegexOutputValidator(regexp.MustCompile(`source: https?://`)), -1)(a)
+
+	answer, history, err := AskWithHistory(a, context.Background(), []llmtypes.MessageContent{
+		llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "How did revenue do?"),
+	})
+	if err != nil {
+		t.Fatalf("AskWithHistory: %v", err)
+	}
+	if !strings.Contains(answer, "12%") || generate.calls != 2 {
+		t.Fatalf("got %q after %d calls, want the repaired answer after one re-ask", answer, generate.calls)
+	}
+	repair := history[len(history)-2].Parts[0].(llmtypes.TextContent).Text
+	if !strings.Contains(repair, "failed validation") || !strings.Contains(repair, "must match the pattern") {
+		t.Errorf("repair message = %q", repair)
+	}
+
+	var failed, repaired int
+	for _, event := range listener.events {
+		switch data := event.Data.(type) {
+		case *events.OutputValidationFailedEvent:
+			failed++
+		case *events.OutputValidationRepairedEvent:
+			repaired++
+			if data.Repairs != 1 {
+				t.Errorf("repaired event = %+v", data)
+			}
+		}
+	}
+	if failed != 1 || repaired != 1 {
+		t.Errorf("got %d failed and %d repaired events", failed, repaired)
+	}
+}
+
+func TestOutputGuardFailsWhenRepairsAreExhausted(t *testing.T) {
+	generate := &scriptedGenerateStage{responses: []*llmtypes.ContentResponse{
+		{Choices: []*llmtypes.ContentChoice{{Content: `{"verdict": 1}`}}},
+	}}
+	a := &Agent{Logger: loggerv2.NewNoop(), LLM: &providerKeyCarrierModel{}, ModelID: "test-model", MaxTurns: 5}
+	WithAskPipeline(AskPipeline{Generate: generate, DispatchTools: &recordingToolDispatcher{}})(a)
+	WithOutputGuard(JSONSchemaOutputValidator(`{"type": "object", "properties": {"verdict": {"type": "string"}}}`), 0)(a)
+
+	answer, _, err := AskWithHistory(a, context.Background(), []llmtypes.MessageContent{
+		llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "verdict?"),
+	})
+	var validationErr *OutputValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("err = %v, want an OutputValidationError", err)
+	}
+	if answer != `{"verdict": 1}` || validationErr.Answer != answer || generate.calls != 1 {
+		t.Errorf("got %q after %d calls, want the first answer without repairs", answer, generate.calls)
+	}
+	if !strings.Contains(validationErr.Error(), "$.verdict: expected string, got integer") {
+		t.Errorf("error = %v", validationErr)
+	}
+}
@@ -0,0 +1,121 @@
+// output_references.go
+//
+// This file appends a references section to final answers that cite
+// offloaded tool outputs. The section is built from the ToolOutputHandler's
+// index of the files it wrote (path, size, producing tool), not from what the
+// model remembers, so the listed paths are exact. With an OutputURLResolver
+// (presigned URLs for remote storage, the gRPC artifact server, ...) every
+// entry also carries a download link.
+//
+// An answer cites an output when it mentions the output's file name (the ID
+// shown to the model in the offload preview), with or without its folder.
+//
+// Exported:
+//   - OffloadedOutput: A tool output written to the tool output folder
+//   - OutputURLResolver: Maps an offloaded file to a download URL
+//   - WithOutputReferences: Append references to final answers
+
+package mcpagent
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// OffloadedOutput is a tool output written to the tool output folder
+type OffloadedOutput struct {
+	Path      string
+	ToolName  string
+	Size      int64 // bytes
+	CreatedAt time.Time
+}
+
+// ID is the file name, which the offload preview shows the model
+func (o OffloadedOutput) ID() string {
+	return filepath.Base(o.Path)
+}
+
+// OutputURLResolver returns a download URL for an offloaded file, reporting
+// false when the file has none
+type OutputURLResolver func(path string) (string, bool)
+
+// WithOutputReferences appends a "References" section to final answers that
+// mention offloaded tool outputs, listing each cited file with its path, size
+// and the tool that produced it. resolveURL, when set, adds a download link
+// per file, e.g. a presigned URL when outputs are mirrored to remote storage.
+//
+// Example:
+//
+//	mcpagent.WithOutputReferences(func(path string) (string, bool) {
+//	    return bucket.PresignedURL(path, time.Hour)
+//	})
+//
+// Default: disabled (answers are returned as the model wrote them)
+func WithOutputReferences(resolveURL OutputURLResolver) AgentOption {
+	return func(a *Agent) {
+		a.outputReferences = true
+		a.outputURLResolver = resolveURL
+	}
+}
+
+// citedOffloadedOutputs returns the offloaded outputs answer mentions that
+// still exist on disk, in the order they were written
+func (a *Agent) citedOffloadedOutputs(answer string) []OffloadedOutput {
+	if a.toolOutputHandler == nil || answer == "" {
+		return nil
+	}
+	var cited []OffloadedOutput
+	for _, output := range a.toolOutputHandler.OffloadedOutputs() {
+		if !strings.Contains(answer, output.ID()) {
+			continue
+		}
+		if !a.toolOutputHandler.outputExists(context.Background(), output.Path) {
+			continue
+		}
+		cited = append(cited, output)
+	}
+	return cited
+}
+
+// appendOutputReferences appends the references section for the offloaded
+// outputs answer cites; answers citing none are returned unchanged
+func (a *Agent) appendOutputReferences(answer string) string {
+	if !a.outputReferences {
+		return answer
+	}
+	cited := a.citedOffloadedOutputs(answer)
+	if len(cited) == 0 {
+		return answer
+	}
+
+	var b strings.Builder
+	b.WriteString(strings.TrimRight(answer, "\n"))
+	b.WriteString("\n\n**References**\n")
+	for _, output := range cited {
+		b.WriteString(fmt.Sprintf("- `%s` (%s, from `%s`)", output.Path, formatByteSize(output.Size), output.ToolName))
+		if a.outputURLResolver != nil {
+			if url, ok := a.outputURLResolver(output.Path); ok {
+				b.WriteString(fmt.Sprintf(" — [download](%s)", url))
+			}
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// formatByteSize formats n bytes for display, e.g. "12.3 KB"
+func formatByteSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,65 @@
+package mcpagent
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestOutputReferencesListCitedOffloadedOutputs(t *testing.T) {
+	handler := NewToolOutputHandlerWithConfig(DefaultLargeToolOutputThreshold, t.TempDir(), "session-1", true, true)
+	cited, err := handler.WriteToolOutputToFile(strings.Repeat("row\n", 512), "query_db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	uncited, err := handler.WriteToolOutputToFile("other output", "search")
+	if err != nil {
+		t.Fatal(err)
+	}
+	removed, err := handler.WriteToolOutputToFile("gone", "fetch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(removed); err != nil {
+		t.Fatal(err)
+	}
+	if got := len(handler.OffloadedOutputs()); got != 3 {
+		t.Fatalf("index has %d outputs, want 3", got)
+	}
+
+	a := &Agent{toolOutputHandler: handler}
+	answer := "The full result set is in " + OffloadedOutput{Path: cited}.ID() + " and " + OffloadedOutput{Path: removed}.ID() + "."
+	if got := a.appendOutputReferences(answer); got != answer {
+		t.Error("answers should be unchanged without WithOutputReferences")
+	}
+
+	WithOutputReferences(func(path string) (string, bool) {
+		return "https://storage.example.com/" + OffloadedOutput{Path: path}.ID() + "?sig=abc", true
+	})(a)
+	got, _, err := DefaultPostprocessStage{}.Postprocess(context.Background(), a, answer, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(got, answer+"\n\n**References**\n") {
+		t.Fatalf("expected a references section after the answer, got %q", got)
+	}
+	if !strings.Contains(got, "`"+cited+"` (2.0 KB, from `query_db`) — [download](https://storage.example.com/") {
+		t.Errorf("missing reference for the cited output: %q", got)
+	}
+	if strings.Contains(got, uncited) || strings.Contains(got, removed) {
+		t.Errorf("only cited outputs that still exist should be listed: %q", got)
+	}
+
+	if got := a.appendOutputReferences("No files were needed."); got != "No files were needed." {
+		t.Errorf("answers citing no outputs should be unchanged, got %q", got)
+	}
+}
+
+func TestFormatByteSize(t *testing.T) {
+	for n, want := range map[int64]string{512: "512 B", 2048: "2.0 KB", 5 << 20: "5.0 MB"} {
+		if got := formatByteSize(n); got != want {
+			t.Errorf("formatByteSize(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
@@ -0,0 +1,327 @@
+// output_storage.go
+//
+// This file abstracts where offloaded tool outputs are stored. By default the
+// ToolOutputHandler writes them to tool_output_folder on local disk, which
+// containerized and serverless deployments without a shared disk cannot rely
+// on: the call that reads an output back (search_large_output) may run on
+// another instance than the one that wrote it. WithToolOutputStorage routes
+// writes, reads and cleanup through an OutputStorage instead, such as
+// S3OutputStorage or GCSOutputStorage (see output_storage_s3.go and
+// output_storage_gcs.go). Object keys are the slash-separated paths
+// shown to the model ("tool_output_folder/<session>/tool_....json"), so
+// previews and output references are the same for every backend.
+//
+// Reads fetch the whole object; the search and query operations copy it to a
+// temporary file for rg and jq.
+//
+// Exported:
+//   - OutputStorage: Storage backend interface for offloaded outputs
+//   - OutputObject: A stored output, as listed by OutputStorage.List
+//   - LocalOutputStorage: OutputStorage on local disk (the default)
+//   - MemoryOutputStorage: In-process OutputStorage (tests, single-process servers)
+//   - WithToolOutputStorage: Use an OutputStorage when creating an agent
+
+package mcpagent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// OutputObject is a stored output listed by OutputStorage.List
+type OutputObject struct {
+	Key     string
+	Size    int64
+	ModTime time.Time
+}
+
+// OutputStorage stores offloaded tool outputs under slash-separated keys.
+// Implementations must be safe for concurrent use by multiple agents.
+type OutputStorage interface {
+	// Put creates or replaces the object at key
+	Put(ctx context.Context, key string, data []byte) error
+	// Get returns the object at key, or an error matching fs.ErrNotExist
+	Get(ctx context.Context, key string) ([]byte, error)
+	// List returns the objects whose key starts with prefix, sorted by key
+	List(ctx context.Context, prefix string) ([]OutputObject, error)
+	// Delete removes the object at key; deleting an unknown key is not an error
+	Delete(ctx context.Context, key string) error
+}
+
+// WithToolOutputStorage stores offloaded tool outputs in storage instead of
+// on local disk, so search_large_output works across instances without a
+// shared disk. NewS3OutputStorage and NewGCSOutputStorage return adapters for
+// S3 (and S3-compatible stores) and GCS buckets; other stores plug in by
+// implementing OutputStorage.
+//
+// Example:
+//
+//	storage, err := mcpagent.NewS3OutputStorage(ctx, "agent-outputs", "prod/")
+//	mcpagent.WithToolOutputStorage(storage)
+//
+// The retention janitor (see WithRetentionPolicy) only sweeps local disk; use
+// WithToolOutputRetentionPeriod or the bucket's lifecycle rules instead.
+//
+// Default: local disk (tool_output_folder)
+func WithToolOutputStorage(storage OutputStorage) AgentOption {
+	return func(a *Agent) {
+		a.toolOutputStorage = storage
+	}
+}
+
+// LocalOutputStorage stores each object as a file at {Root}/{key}
+type LocalOutputStorage struct {
+	// Root the keys are relative to ("" = the working directory)
+	Root string
+}
+
+func (s LocalOutputStorage) path(key string) (string, error) {
+	clean := path.Clean(strings.ReplaceAll(key, "\\", "/"))
+	if key == "" || clean == ".." || strings.HasPrefix(clean, "../") || (path.IsAbs(clean) && s.Root != "") {
+		return "", fmt.Errorf("invalid output key %q", key)
+	}
+	return filepath.Join(s.Root, filepath.FromSlash(clean)), nil
+}
+
+// Put implements OutputStorage
+func (s LocalOutputStorage) Put(_ context.Context, key string, data []byte) error {
+	p, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil { //nolint:gosec // 0755 permissions are intentional for user-accessible directories
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+	return os.WriteFile(p, data, 0644) //nolint:gosec // 0644 permissions are intentional for user-accessible files
+}
+
+// Get implements OutputStorage
+func (s LocalOutputStorage) Get(_ context.Context, key string) ([]byte, error) {
+	p, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	return os.ReadFile(p) //nolint:gosec // G304: path is built from a validated key
+}
+
+// List implements OutputStorage
+func (s LocalOutputStorage) List(_ context.Context, prefix string) ([]OutputObject, error) {
+	dir := prefix
+	if !strings.HasSuffix(dir, "/") {
+		dir = path.Dir(dir)
+	}
+	root, err := s.path(dir)
+	if err != nil {
+		return nil, err
+	}
+	var objects []OutputObject
+	err = filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		rel := p
+		if !filepath.IsAbs(p) || s.Root != "" {
+			if rel, err = filepath.Rel(s.Root, p); err != nil {
+				return nil
+			}
+		}
+		key := filepath.ToSlash(rel)
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		objects = append(objects, OutputObject{Key: key, Size: info.Size(), ModTime: info.ModTime()})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return objects, nil
+}
+
+// Delete implements OutputStorage
+func (s LocalOutputStorage) Delete(_ context.Context, key string) error {
+	p, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(p); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// MemoryOutputStorage keeps objects in process memory
+type MemoryOutputStorage struct {
+	mu      sync.RWMutex
+	objects map[string]memoryOutputObject
+}
+
+type memoryOutputObject struct {
+	data    []byte
+	modTime time.Time
+}
+
+// NewMemoryOutputStorage creates an empty MemoryOutputStorage
+func NewMemoryOutputStorage() *MemoryOutputStorage {
+	return &MemoryOutputStorage{objects: make(map[string]memoryOutputObject)}
+}
+
+// Put implements OutputStorage
+func (s *MemoryOutputStorage) Put(_ context.Context, key string, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.objects[key] = memoryOutputObject{data: append([]byte(nil), data...), modTime: time.Now()}
+	return nil
+}
+
+// Get implements OutputStorage
+func (s *MemoryOutputStorage) Get(_ context.Context, key string) ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	object, ok := s.objects[key]
+	if !ok {
+		return nil, fmt.Errorf("output %q: %w", key, fs.ErrNotExist)
+	}
+	return append([]byte(nil), object.data...), nil
+}
+
+// List implements OutputStorage
+func (s *MemoryOutputStorage) List(_ context.Context, prefix string) ([]OutputObject, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var objects []OutputObject
+	for key, object := range s.objects {
+		if strings.HasPrefix(key, prefix) {
+			objects = append(objects, OutputObject{Key: key, Size: int64(len(object.data)), ModTime: object.modTime})
+		}
+	}
+	sort.Slice(objects, func(i, j int) bool { return objects[i].Key < objects[j].Key })
+	return objects, nil
+}
+
+// Delete implements OutputStorage
+func (s *MemoryOutputStorage) Delete(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.objects, key)
+	return nil
+}
+
+// readStorageResponse returns the body of a successful object store
+// response; a 404 becomes an error matching fs.ErrNotExist
+func readStorageResponse(resp *http.Response, op, key string) ([]byte, error) {
+	defer func() { _ = resp.Body.Close() }()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to %s output %q: %w", op, key, err)
+	}
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, fmt.Errorf("output %q: %w", key, fs.ErrNotExist)
+	case resp.StatusCode >= 300:
+		return nil, fmt.Errorf("failed to %s output %q: %s: %s", op, key, resp.Status, truncateUTF8(string(body), 500))
+	}
+	return body, nil
+}
+
+// closeStorageResponse checks an object store response without a body
+func closeStorageResponse(resp *http.Response, op, key string) error {
+	_, err := readStorageResponse(resp, op, key)
+	return err
+}
+
+// outputKey is the storage key of an offloaded output path
+func outputKey(p string) string {
+	return path.Clean(strings.TrimPrefix(filepath.ToSlash(p), "./"))
+}
+
+// ReadOutput returns the content of an offloaded output
+func (h *ToolOutputHandler) ReadOutput(ctx context.Context, p string) ([]byte, error) {
+	if h == nil || h.Storage == nil {
+		return os.ReadFile(p) //nolint:gosec // G304: callers validate p against the output folder
+	}
+	return h.Storage.Get(ctx, outputKey(p))
+}
+
+// localOutputPath returns a local file with the content of an offloaded
+// output for rg and jq, and a func removing it when it is a temporary copy
+func (h *ToolOutputHandler) localOutputPath(ctx context.Context, p string) (string, func(), error) {
+	if h == nil || h.Storage == nil {
+		return p, func() {}, nil
+	}
+	data, err := h.Storage.Get(ctx, outputKey(p))
+	if err != nil {
+		return "", nil, err
+	}
+	tmp, err := os.CreateTemp("", "tool_output_*"+filepath.Ext(p))
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to copy output: %w", err)
+	}
+	remove := func() { _ = os.Remove(tmp.Name()) }
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		remove()
+		return "", nil, fmt.Errorf("failed to copy output: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		remove()
+		return "", nil, fmt.Errorf("failed to copy output: %w", err)
+	}
+	return tmp.Name(), remove, nil
+}
+
+// outputExists reports whether an offloaded output is still stored
+func (h *ToolOutputHandler) outputExists(ctx context.Context, p string) bool {
+	if h.Storage == nil {
+		_, err := os.Stat(p)
+		return err == nil
+	}
+	key := outputKey(p)
+	objects, err := h.Storage.List(ctx, key)
+	if err != nil {
+		return false
+	}
+	for _, object := range objects {
+		if object.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// deleteStoredOutputs deletes the stored outputs under folder, all of them or
+// only those last modified before cutoff when it is set
+func (h *ToolOutputHandler) deleteStoredOutputs(ctx context.Context, folder string, cutoff time.Time) error {
+	objects, err := h.Storage.List(ctx, outputKey(folder)+"/")
+	if err != nil {
+		return fmt.Errorf("failed to list stored outputs: %w", err)
+	}
+	var failed int
+	for _, object := range objects {
+		if !cutoff.IsZero() && !object.ModTime.Before(cutoff) {
+			continue
+		}
+		if err := h.Storage.Delete(ctx, object.Key); err != nil {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to delete %d of %d stored outputs", failed, len(objects))
+	}
+	return nil
+}
@@ -0,0 +1,160 @@
+// output_storage_gcs.go
+//
+// This file provides the Google Cloud Storage OutputStorage. It uses the GCS
+// JSON API with an OAuth2 client, by default authorized through Application
+// Default Credentials, so no Cloud Storage SDK is needed.
+//
+// Exported:
+//   - GCSOutputStorage: OutputStorage backed by a GCS bucket
+//   - NewGCSOutputStorage: GCSOutputStorage with Application Default Credentials
+
+package mcpagent
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2/google"
+)
+
+// gcsReadWriteScope is the OAuth2 scope GCSOutputStorage needs
+const gcsReadWriteScope = "https://www.googleapis.com/auth/devstorage.read_write"
+
+// GCSOutputStorage stores offloaded outputs as objects of a GCS bucket, at
+// {Prefix}{key}
+type GCSOutputStorage struct {
+	Bucket string
+	// Prefix of the object names, e.g. "mcpagent/" ("" = bucket root)
+	Prefix string
+	// Endpoint of the JSON API, e.g. an emulator ("" = https://storage.googleapis.com)
+	Endpoint string
+	// HTTPClient sends authorized requests (see NewGCSOutputStorage)
+	HTTPClient *http.Client
+}
+
+// NewGCSOutputStorage returns a GCSOutputStorage for bucket authorized with
+// Application Default Credentials (GOOGLE_APPLICATION_CREDENTIALS, gcloud
+// login, or the metadata server).
+//
+// Example:
+//
+//	storage, err := mcpagent.NewGCSOutputStorage(ctx, "agent-outputs", "prod/")
+//	agent, err := mcpagent.NewAgent(ctx, llm, configPath, mcpagent.WithToolOutputStorage(storage))
+func NewGCSOutputStorage(ctx context.Context, bucket, prefix string) (*GCSOutputStorage, error) {
+	if bucket == "" {
+		return nil, fmt.Errorf("GCS bucket is required")
+	}
+	client, err := google.DefaultClient(ctx, gcsReadWriteScope)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load Google credentials: %w", err)
+	}
+	return &GCSOutputStorage{Bucket: bucket, Prefix: prefix, HTTPClient: client}, nil
+}
+
+// Put implements OutputStorage
+func (s *GCSOutputStorage) Put(ctx context.Context, key string, data []byte) error {
+	query := url.Values{"uploadType": {"media"}, "name": {s.Prefix + key}}
+	resp, err := s.do(ctx, http.MethodPost, "/upload/storage/v1/b/"+url.PathEscape(s.Bucket)+"/o", query, data)
+	if err != nil {
+		return err
+	}
+	return closeStorageResponse(resp, "put", key)
+}
+
+// Get implements OutputStorage
+func (s *GCSOutputStorage) Get(ctx context.Context, key string) ([]byte, error) {
+	resp, err := s.do(ctx, http.MethodGet, s.objectPath(key), url.Values{"alt": {"media"}}, nil)
+	if err != nil {
+		return nil, err
+	}
+	return readStorageResponse(resp, "get", key)
+}
+
+// gcsListResult is the objects.list response
+type gcsListResult struct {
+	Items []struct {
+		Name    string    `json:"name"`
+		Size    string    `json:"size"`
+		Updated time.Time `json:"updated"`
+	} `json:"items"`
+	NextPageToken string `json:"nextPageToken"`
+}
+
+// List implements OutputStorage
+func (s *GCSOutputStorage) List(ctx context.Context, prefix string) ([]OutputObject, error) {
+	var objects []OutputObject
+	query := url.Values{"prefix": {s.Prefix + prefix}, "fields": {"items(name,size,updated),nextPageToken"}}
+	for {
+		resp, err := s.do(ctx, http.MethodGet, "/storage/v1/b/"+url.PathEscape(s.Bucket)+"/o", query, nil)
+		if err != nil {
+			return nil, err
+		}
+		body, err := readStorageResponse(resp, "list", prefix)
+		if err != nil {
+			return nil, err
+		}
+		var result gcsListResult
+		if err := json.Unmarshal(body, &result); err != nil {
+			return nil, fmt.Errorf("failed to parse GCS listing: %w", err)
+		}
+		for _, item := range result.Items {
+			size, _ := strconv.ParseInt(item.Size, 10, 64)
+			objects = append(objects, OutputObject{Key: strings.TrimPrefix(item.Name, s.Prefix), Size: size, ModTime: item.Updated})
+		}
+		if result.NextPageToken == "" {
+			return objects, nil
+		}
+		query.Set("pageToken", result.NextPageToken)
+	}
+}
+
+// Delete implements OutputStorage
+func (s *GCSOutputStorage) Delete(ctx context.Context, key string) error {
+	resp, err := s.do(ctx, http.MethodDelete, s.objectPath(key), nil, nil)
+	if err != nil {
+		return err
+	}
+	if err := closeStorageResponse(resp, "delete", key); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// objectPath is the JSON API path of the object of key; the object name is
+// one path segment, so its slashes are escaped
+func (s *GCSOutputStorage) objectPath(key string) string {
+	return "/storage/v1/b/" + url.PathEscape(s.Bucket) + "/o/" + url.PathEscape(s.Prefix+key)
+}
+
+// do sends a request to the JSON API; escapedPath is already escaped
+func (s *GCSOutputStorage) do(ctx context.Context, method, escapedPath string, query url.Values, body []byte) (*http.Response, error) {
+	endpoint := "https://storage.googleapis.com"
+	if s.Endpoint != "" {
+		endpoint = strings.TrimSuffix(s.Endpoint, "/")
+	}
+	target := endpoint + escapedPath
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/octet-stream")
+	}
+	client := s.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return client.Do(req)
+}
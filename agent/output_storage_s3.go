@@ -0,0 +1,185 @@
+// output_storage_s3.go
+//
+// This file provides the S3 OutputStorage. It speaks the S3 REST API
+// directly, signing requests with AWS Signature Version 4, so it works with
+// Amazon S3 and S3-compatible stores (MinIO, Cloudflare R2, ...) without
+// pulling in the S3 SDK.
+//
+// Exported:
+//   - S3OutputStorage: OutputStorage backed by an S3 bucket
+//   - NewS3OutputStorage: S3OutputStorage with the default AWS credential chain
+
+package mcpagent
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/aws/aws-sdk-go-v2/config"
+)
+
+// S3OutputStorage stores offloaded outputs as objects of an S3 bucket, at
+// {Prefix}{key}
+type S3OutputStorage struct {
+	Bucket string
+	// Prefix of the object names, e.g. "mcpagent/" ("" = bucket root)
+	Prefix string
+	Region string
+	// Endpoint of an S3-compatible store, addressed path-style
+	// ("" = Amazon S3, virtual-hosted https://<bucket>.s3.<region>.amazonaws.com)
+	Endpoint    string
+	Credentials aws.CredentialsProvider
+	// HTTPClient sends the requests (nil = http.DefaultClient)
+	HTTPClient *http.Client
+}
+
+// NewS3OutputStorage returns an S3OutputStorage for bucket whose region and
+// credentials come from the default AWS chain (environment, shared config,
+// IAM role).
+//
+// Example:
+//
+//	storage, err := mcpagent.NewS3OutputStorage(ctx, "agent-outputs", "prod/")
+//	agent, err := mcpagent.NewAgent(ctx, llm, configPath, mcpagent.WithToolOutputStorage(storage))
+func NewS3OutputStorage(ctx context.Context, bucket, prefix string) (*S3OutputStorage, error) {
+	if bucket == "" {
+		return nil, fmt.Errorf("S3 bucket is required")
+	}
+	cfg, err := config.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+	if cfg.Region == "" {
+		return nil, fmt.Errorf("AWS region is not configured")
+	}
+	return &S3OutputStorage{Bucket: bucket, Prefix: prefix, Region: cfg.Region, Credentials: cfg.Credentials}, nil
+}
+
+// Put implements OutputStorage
+func (s *S3OutputStorage) Put(ctx context.Context, key string, data []byte) error {
+	resp, err := s.do(ctx, http.MethodPut, s.Prefix+key, nil, data)
+	if err != nil {
+		return err
+	}
+	return closeStorageResponse(resp, "put", key)
+}
+
+// Get implements OutputStorage
+func (s *S3OutputStorage) Get(ctx context.Context, key string) ([]byte, error) {
+	resp, err := s.do(ctx, http.MethodGet, s.Prefix+key, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return readStorageResponse(resp, "get", key)
+}
+
+// s3ListResult is the ListObjectsV2 response
+type s3ListResult struct {
+	Contents []struct {
+		Key          string
+		Size         int64
+		LastModified time.Time
+	}
+	IsTruncated           bool
+	NextContinuationToken string
+}
+
+// List implements OutputStorage
+func (s *S3OutputStorage) List(ctx context.Context, prefix string) ([]OutputObject, error) {
+	var objects []OutputObject
+	query := url.Values{"list-type": {"2"}, "prefix": {s.Prefix + prefix}}
+	for {
+		resp, err := s.do(ctx, http.MethodGet, "", query, nil)
+		if err != nil {
+			return nil, err
+		}
+		body, err := readStorageResponse(resp, "list", prefix)
+		if err != nil {
+			return nil, err
+		}
+		var result s3ListResult
+		if err := xml.Unmarshal(body, &result); err != nil {
+			return nil, fmt.Errorf("failed to parse S3 listing: %w", err)
+		}
+		for _, object := range result.Contents {
+			objects = append(objects, OutputObject{Key: strings.TrimPrefix(object.Key, s.Prefix), Size: object.Size, ModTime: object.LastModified})
+		}
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return objects, nil
+		}
+		query.Set("continuation-token", result.NextContinuationToken)
+	}
+}
+
+// Delete implements OutputStorage
+func (s *S3OutputStorage) Delete(ctx context.Context, key string) error {
+	resp, err := s.do(ctx, http.MethodDelete, s.Prefix+key, nil, nil)
+	if err != nil {
+		return err
+	}
+	if err := closeStorageResponse(resp, "delete", key); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// do sends a signed request for the object name (or the bucket, name "")
+func (s *S3OutputStorage) do(ctx context.Context, method, name string, query url.Values, body []byte) (*http.Response, error) {
+	endpoint := fmt.Sprintf("https://%s.s3.%s.amazonaws.com/", s.Bucket, s.Region)
+	if s.Endpoint != "" {
+		endpoint = strings.TrimSuffix(s.Endpoint, "/") + "/" + s3EscapePath(s.Bucket) + "/"
+	}
+	target := endpoint + s3EscapePath(name)
+	if len(query) > 0 {
+		target += "?" + strings.ReplaceAll(query.Encode(), "+", "%20")
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(body)
+	payloadHash := hex.EncodeToString(sum[:])
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if s.Credentials != nil {
+		credentials, err := s.Credentials.Retrieve(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve AWS credentials: %w", err)
+		}
+		signer := v4.NewSigner(func(o *v4.SignerOptions) { o.DisableURIPathEscaping = true })
+		if err := signer.SignHTTP(ctx, credentials, req, payloadHash, "s3", s.Region, time.Now()); err != nil {
+			return nil, fmt.Errorf("failed to sign S3 request: %w", err)
+		}
+	}
+	client := s.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return client.Do(req)
+}
+
+// s3EscapePath URI-encodes an object name as S3 expects in the canonical
+// request: everything but unreserved characters and "/"
+func s3EscapePath(name string) string {
+	var b strings.Builder
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' || strings.IndexByte("-._~/", c) >= 0 {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
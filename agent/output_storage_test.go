@@ -0,0 +1,203 @@
+package mcpagent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/credentials"
+)
+
+func TestToolOutputStorageServesVirtualTools(t *testing.T) {
+	ctx := context.Background()
+	storage := NewMemoryOutputStorage()
+	handler := NewToolOutputHandlerWithConfig(DefaultLargeToolOutputThreshold, DefaultToolOutputFolder, "session-1", true, true)
+	handler.Storage = storage
+
+	path, err := handler.WriteToolOutputToFile(`{"rows": [{"id": 1}, {"id": 2}]}`, "query_db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("output should not be written to local disk, stat err = %v", err)
+	}
+	objects, _ := storage.List(ctx, "tool_output_folder/session-1/")
+	if len(objects) != 1 || objects[0].Key != filepath.ToSlash(path) {
+		t.Fatalf("expected the output under its path as key, got %+v", objects)
+	}
+
+	a := &Agent{EnableContextOffloading: true, toolOutputHandler: handler}
+	content, err := a.HandleLargeOutputVirtualTool(ctx, "search_large_output", map[string]interface{}{
+		"operation": "read", "filename": filepath.Base(path), "start": float64(1), "end": float64(9),
+	})
+	if err != nil || content != `{"rows": ` {
+		t.Fatalf("read = %q, %v", content, err)
+	}
+	localPath, cleanup, err := handler.localOutputPath(ctx, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, err := os.ReadFile(localPath); err != nil || !strings.Contains(string(data), `"id": 2`) {
+		t.Fatalf("search and query should get a local copy, got %q, %v", data, err)
+	}
+	cleanup()
+	if _, err := os.Stat(localPath); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("local copy should be removed, stat err = %v", err)
+	}
+	if !handler.outputExists(ctx, path) {
+		t.Error("stored output should exist")
+	}
+
+	if err := handler.CleanupOldFiles(time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if objects, _ := storage.List(ctx, ""); len(objects) != 1 {
+		t.Fatalf("recent outputs should survive CleanupOldFiles, got %d", len(objects))
+	}
+	if err := handler.CleanupCurrentSessionFolder(); err != nil {
+		t.Fatal(err)
+	}
+	if objects, _ := storage.List(ctx, ""); len(objects) != 0 {
+		t.Fatalf("session cleanup should delete stored outputs, got %+v", objects)
+	}
+	if _, err := a.HandleLargeOutputVirtualTool(ctx, "search_large_output", map[string]interface{}{
+		"operation": "read", "filename": path, "start": float64(1), "end": float64(9),
+	}); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("reading a deleted output should fail with fs.ErrNotExist, got %v", err)
+	}
+}
+
+func TestLocalOutputStorage(t *testing.T) {
+	storage := LocalOutputStorage{Root: t.TempDir()}
+	checkOutputStorageRoundTrip(t, storage)
+	if err := storage.Put(context.Background(), "../escape.txt", nil); err == nil {
+		t.Error("keys must not escape the root")
+	}
+}
+
+func TestS3OutputStorage(t *testing.T) {
+	objects := map[string][]byte{}
+	var mu sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/") {
+			t.Errorf("unsigned request %s %s", r.Method, r.URL)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		name := strings.TrimPrefix(r.URL.Path, "/agent-outputs/")
+		switch {
+		case r.Method == http.MethodGet && name == "":
+			listing := "<ListBucketResult>"
+			for key, data := range objects {
+				if strings.HasPrefix(key, r.URL.Query().Get("prefix")) {
+					listing += fmt.Sprintf("<Contents><Key>%s</Key><Size>%d</Size><LastModified>2026-01-02T03:04:05.000Z</LastModified></Contents>", key, len(data))
+				}
+			}
+			fmt.Fprint(w, listing+"<IsTruncated>false</IsTruncated></ListBucketResult>")
+		case r.Method == http.MethodPut:
+			objects[name], _ = io.ReadAll(r.Body)
+		case r.Method == http.MethodGet:
+			if data, ok := objects[name]; ok {
+				w.Write(data)
+			} else {
+				http.Error(w, "<Error><Code>NoSuchKey</Code></Error>", http.StatusNotFound)
+			}
+		case r.Method == http.MethodDelete:
+			delete(objects, name)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+
+	checkOutputStorageRoundTrip(t, &S3OutputStorage{
+		Bucket: "agent-outputs", Prefix: "prod/", Region: "us-east-1", Endpoint: server.URL,
+		Credentials: credentials.NewStaticCredentialsProvider("AKID", "secret", ""),
+	})
+	if _, ok := objects["prod/tool_output_folder/s2/tool_b.txt"]; !ok {
+		t.Errorf("objects should be stored under the prefix, got %v", objects)
+	}
+}
+
+func TestGCSOutputStorage(t *testing.T) {
+	objects := map[string][]byte{}
+	var mu sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		const objectsPath = "/storage/v1/b/agent-outputs/o"
+		name, _ := url.PathUnescape(strings.TrimPrefix(r.URL.EscapedPath(), objectsPath+"/"))
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/upload"+objectsPath:
+			objects[r.URL.Query().Get("name")], _ = io.ReadAll(r.Body)
+			fmt.Fprint(w, "{}")
+		case r.Method == http.MethodGet && r.URL.Path == objectsPath:
+			var items []string
+			for key, data := range objects {
+				if strings.HasPrefix(key, r.URL.Query().Get("prefix")) {
+					items = append(items, fmt.Sprintf(`{"name": %q, "size": "%d", "updated": "2026-01-02T03:04:05.000Z"}`, key, len(data)))
+				}
+			}
+			fmt.Fprintf(w, `{"items": [%s]}`, strings.Join(items, ","))
+		case r.Method == http.MethodGet && r.URL.Query().Get("alt") == "media":
+			if data, ok := objects[name]; ok {
+				w.Write(data)
+			} else {
+				http.Error(w, "No such object", http.StatusNotFound)
+			}
+		case r.Method == http.MethodDelete:
+			if _, ok := objects[name]; !ok {
+				http.Error(w, "No such object", http.StatusNotFound)
+				return
+			}
+			delete(objects, name)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+		}
+	}))
+	defer server.Close()
+
+	checkOutputStorageRoundTrip(t, &GCSOutputStorage{Bucket: "agent-outputs", Prefix: "prod/", Endpoint: server.URL})
+	if _, ok := objects["prod/tool_output_folder/s2/tool_b.txt"]; !ok {
+		t.Errorf("objects should be stored under the prefix, got %v", objects)
+	}
+}
+
+// checkOutputStorageRoundTrip exercises the OutputStorage contract
+func checkOutputStorageRoundTrip(t *testing.T, storage OutputStorage) {
+	t.Helper()
+	ctx := context.Background()
+	if err := storage.Put(ctx, "tool_output_folder/s1/tool_a.txt", []byte("alpha")); err != nil {
+		t.Fatal(err)
+	}
+	if err := storage.Put(ctx, "tool_output_folder/s2/tool_b.txt", []byte("beta")); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := storage.Get(ctx, "tool_output_folder/s1/tool_a.txt"); err != nil || string(data) != "alpha" {
+		t.Fatalf("Get = %q, %v", data, err)
+	}
+	objects, err := storage.List(ctx, "tool_output_folder/s1/")
+	if err != nil || len(objects) != 1 || objects[0].Key != "tool_output_folder/s1/tool_a.txt" || objects[0].Size != 5 {
+		t.Fatalf("List = %+v, %v", objects, err)
+	}
+	if err := storage.Delete(ctx, "tool_output_folder/s1/tool_a.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if err := storage.Delete(ctx, "tool_output_folder/s1/tool_a.txt"); err != nil {
+		t.Errorf("deleting an unknown key should not fail: %v", err)
+	}
+	if _, err := storage.Get(ctx, "tool_output_folder/s1/tool_a.txt"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Get after Delete = %v, want fs.ErrNotExist", err)
+	}
+}
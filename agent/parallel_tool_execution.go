@@ -2,7 +2,8 @@
 //
 // This file implements parallel (concurrent) execution of multiple tool calls
 // returned by the LLM in a single response. When EnableParallelToolExecution is true
-// and the LLM returns >1 tool calls, they execute concurrently using a fork-join pattern.
+// and the LLM returns >1 tool calls, they execute concurrently using a fork-join pattern,
+// at most MaxParallelToolCalls at a time when set.
 // Results are collected in pre-allocated indexed slots and assembled in deterministic order.
 //
 // Exported:
@@ -15,6 +16,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/manishiitg/mcpagent/events"
@@ -56,8 +58,10 @@ type toolExecutionResult struct {
 	// Raw result from tool execution (for event emission and loop detection)
 	result     *mcp.CallToolResult
 	resultText string
+	images     []llmtypes.ImageContent // Tool images delivered after the turn's tool results
 	duration   time.Duration
 	toolErr    error
+	fromCache  bool // Served from the tool result cache
 
 	// If set, the entire conversation should return this error
 	fatalError error
@@ -120,6 +124,16 @@ func executeToolCallsParallel(
 	results := make([]toolExecutionResult, len(plans))
 	var wg sync.WaitGroup
 
+	// Bound concurrency with MaxParallelToolCalls: each goroutine takes a slot
+	// before executing, so later calls start as earlier ones finish
+	var slots chan struct{}
+	if a.MaxParallelToolCalls > 0 && a.MaxParallelToolCalls < len(plans) {
+		slots = make(chan struct{}, a.MaxParallelToolCalls)
+		v2Logger.Debug("Bounding parallel tool execution",
+			loggerv2.Int("tool_calls", len(plans)),
+			loggerv2.Int("max_parallel", a.MaxParallelToolCalls))
+	}
+
 	for i, plan := range plans {
 		if plan.skipExecution {
 			// Pre-error: already have the message, no goroutine needed
@@ -134,6 +148,15 @@ func executeToolCallsParallel(
 		wg.Add(1)
 		go func(idx int, p toolExecutionPlan) {
 			defer wg.Done()
+			if slots != nil {
+				select {
+				case slots <- struct{}{}:
+					defer func() { <-slots }()
+				case <-agentCtx.Done():
+					results[idx] = cancelledToolExecution(p, agentCtx.Err())
+					return
+				}
+			}
 			results[idx] = executeToolCall(ctx, a, p, turn, conversationStartTime, agentCtx)
 		}(i, plan)
 	}
@@ -143,6 +166,7 @@ func executeToolCallsParallel(
 	// ─── Phase 3: Sequential assembly ──────────────────────────────────────
 
 	needToolRefresh := false
+	var toolImageBatches []toolImageBatch
 
 	for i, plan := range plans {
 		res := results[i]
@@ -155,6 +179,9 @@ func executeToolCallsParallel(
 
 		// Append messages in order
 		messages = append(messages, res.messages...)
+		if len(res.images) > 0 {
+			toolImageBatches = append(toolImageBatches, toolImageBatch{toolName: tc.FunctionCall.Name, images: res.images})
+		}
 
 		// Emit end/error events
 		if plan.skipExecution {
@@ -167,6 +194,7 @@ func executeToolCallsParallel(
 			// Tool execution error — emit error event
 			toolErrorEvent := events.NewToolCallErrorEvent(turn+1, tc.FunctionCall.Name, res.toolErr.Error(), plan.serverName, res.duration)
 			toolErrorEvent.ToolCallID = tc.ID
+			toolErrorEvent.IsParallel = true
 			a.EmitTypedEvent(ctx, toolErrorEvent)
 		} else if res.result == nil || !res.result.IsError {
 			// Success — emit tool call end event
@@ -178,11 +206,14 @@ func executeToolCallsParallel(
 
 			toolEndEvent := events.NewToolCallEndEventWithTokenUsageAndModel(turn+1, tc.FunctionCall.Name, res.resultText, plan.serverName, res.duration, "", contextUsagePercent, modelContextWindow, contextWindowUsage, a.ModelID)
 			toolEndEvent.ToolCallID = tc.ID
+			toolEndEvent.IsParallel = true
+			toolEndEvent.FromCache = res.fromCache
 			a.EmitTypedEvent(ctx, toolEndEvent)
 		} else if res.result != nil && res.result.IsError {
 			// Tool returned error in result
 			toolErrorEvent := events.NewToolCallErrorEvent(turn+1, tc.FunctionCall.Name, res.resultText, plan.serverName, res.duration)
 			toolErrorEvent.ToolCallID = tc.ID
+			toolErrorEvent.IsParallel = true
 			a.EmitTypedEvent(ctx, toolErrorEvent)
 		}
 
@@ -200,9 +231,11 @@ func executeToolCallsParallel(
 		}
 	}
 
+	messages = appendToolImageMessage(messages, toolImageBatches)
+
 	// Refresh tools if any add_tool was in the batch
 	if needToolRefresh {
-		a.filteredTools = a.getToolsForToolSearchMode()
+		a.filteredTools = a.applyToolHints(ctx, a.applyToolPermissions(a.getToolsForToolSearchMode()))
 		v2Logger.Debug("🔍 [TOOL_SEARCH] Tools refreshed after parallel add_tool",
 			loggerv2.Int("discovered_count", a.GetDiscoveredToolCount()),
 			loggerv2.Int("total_available", len(a.filteredTools)))
@@ -211,6 +244,21 @@ func executeToolCallsParallel(
 	return messages, nil
 }
 
+// cancelledToolExecution is the result of a tool call that was still waiting
+// for a parallel execution slot when the conversation was cancelled
+func cancelledToolExecution(plan toolExecutionPlan, err error) toolExecutionResult {
+	tc := plan.toolCall
+	text := fmt.Sprintf("Tool execution failed - not started: %v", err)
+	return toolExecutionResult{
+		toolErr:    fmt.Errorf("not started: %w", err),
+		resultText: text,
+		messages: []llmtypes.MessageContent{{
+			Role:  llmtypes.ChatMessageTypeTool,
+			Parts: []llmtypes.ContentPart{llmtypes.ToolCallResponse{ToolCallID: tc.ID, Name: tc.FunctionCall.Name, Content: text, IsError: true}},
+		}},
+	}
+}
+
 // prepareToolExecution extracts the pre-processing logic from the sequential loop.
 // It parses arguments, resolves clients, validates the tool, and builds a plan.
 // Does NOT execute the tool or emit end events.
@@ -327,7 +375,7 @@ func prepareToolExecution(
 	// Check for client requirement for non-custom, non-virtual tools
 	if !plan.isCustomTool && !plan.isVirtual && plan.client == nil {
 		if !hasMappedServer || mappedServerName == "" {
-			feedbackMessage := fmt.Sprintf("❌ Tool '%s' is not available in this system.\n\n🔧 Available tools include:\n- get_prompt, get_resource (virtual tools)\n- search_large_output (read/search/query operations for offloaded files)\n- MCP server tools (check system prompt for full list)\n\n💡 Please use one of the available tools listed above.", tc.FunctionCall.Name)
+			feedbackMessage := fmt.Sprintf("❌ Tool '%s' is not available in this system.\n\n🔧 Available tools include:\n- get_prompt, read_resource (virtual tools)\n- search_large_output (read/search/query operations for offloaded files)\n- MCP server tools (check system prompt for full list)\n\n💡 Please use one of the available tools listed above.", tc.FunctionCall.Name)
 
 			toolNotFoundEvent := events.NewToolCallErrorEvent(turn+1, tc.FunctionCall.Name, fmt.Sprintf("tool '%s' not found", tc.FunctionCall.Name), "", time.Since(conversationStartTime))
 			toolNotFoundEvent.ToolCallID = tc.ID
@@ -351,6 +399,7 @@ func prepareToolExecution(
 				err,
 				loggerv2.String("server", mappedServerName))
 			conversationErrorEvent := events.NewConversationErrorEvent(getLastUserMessageForEvent(), fmt.Sprintf("failed to create on-demand connection for server %s: %v", mappedServerName, err), turn+1, "on_demand_connection_failed", time.Since(conversationStartTime))
+			conversationErrorEvent.ErrorCode = ErrorCode(ErrMCPConnectionFailed)
 			a.EmitTypedEvent(ctx, conversationErrorEvent)
 			plan.skipExecution = true
 			msg := llmtypes.MessageContent{
@@ -368,22 +417,13 @@ func prepareToolExecution(
 		}
 		a.Clients[mappedServerName] = onDemandClient
 		a.clientsMu.Unlock()
+		a.watchConnectionState(mappedServerName, onDemandClient)
 		plan.client = onDemandClient
 	}
 
-	// Determine tool timeout
-	plan.toolTimeout = getToolExecutionTimeout(a)
+	// Determine tool timeout (see tool_timeouts.go)
+	plan.toolTimeout = a.resolveToolTimeout(tc.FunctionCall.Name, plan.serverName)
 	plan.hasNoTimeout = plan.toolTimeout <= 0
-	if plan.isCustomTool {
-		if customTool, exists := a.customTools[tc.FunctionCall.Name]; exists && customTool.Timeout != -1 {
-			if customTool.Timeout == 0 {
-				plan.hasNoTimeout = true
-			} else if customTool.Timeout > 0 {
-				plan.hasNoTimeout = false
-				plan.toolTimeout = customTool.Timeout
-			}
-		}
-	}
 
 	// Determine tool type
 	plan.toolType = "MCP"
@@ -459,58 +499,74 @@ func executeToolCall(
 	toolCtx = context.WithValue(toolCtx, ToolExecutionTurnKey, turn+1)
 	toolCtx = context.WithValue(toolCtx, ToolExecutionServerKey, plan.serverName)
 	toolCtx = context.WithValue(toolCtx, ToolExecutionLLMConfigKey, a.GetLLMModelConfig())
+	var fromCache atomic.Bool
+	toolCtx = withToolResultCacheHit(toolCtx, &fromCache)
+	toolCtx = a.withToolProgressEvents(toolCtx, turn+1, tc.FunctionCall.Name, plan.serverName, tc.ID)
 
 	// ─── Execute the tool ──────────────────────────────────────────────
 
-	var mcpResult *mcp.CallToolResult
-	var toolErr error
 	actualToolName := actualMCPToolName(tc.FunctionCall.Name, plan.serverName)
-
-	if isVirtualTool(tc.FunctionCall.Name) {
-		v2Logger.Debug("🔧 [TOOL_CALL] Executing virtual tool (parallel)",
-			loggerv2.String("tool_name", tc.FunctionCall.Name))
-		resultText, vtErr := a.HandleVirtualTool(toolCtx, tc.FunctionCall.Name, plan.args)
-		if vtErr != nil {
-			mcpResult = &mcp.CallToolResult{
-				IsError: true,
-				Content: []mcp.Content{&mcp.TextContent{Text: vtErr.Error()}},
-			}
-		} else {
-			if resultText == "" {
-				resultText = fmt.Sprintf("Tool '%s' executed successfully but returned no output.", tc.FunctionCall.Name)
-			}
-			mcpResult = &mcp.CallToolResult{
-				IsError: false,
-				Content: []mcp.Content{&mcp.TextContent{Text: resultText}},
-			}
-		}
-	} else if a.customTools != nil {
-		if customTool, exists := a.customTools[tc.FunctionCall.Name]; exists {
-			resultText, ctErr := customTool.Execution(toolCtx, plan.args)
-			if ctErr != nil {
+	execute := func(toolCtx context.Context, call *ToolInvocation) (*mcp.CallToolResult, error) {
+		var mcpResult *mcp.CallToolResult
+		if isVirtualTool(tc.FunctionCall.Name) {
+			v2Logger.Debug("🔧 [TOOL_CALL] Executing virtual tool (parallel)",
+				loggerv2.String("tool_name", tc.FunctionCall.Name))
+			resultText, vtErr := a.HandleVirtualTool(toolCtx, tc.FunctionCall.Name, call.Arguments)
+			if vtErr != nil {
 				mcpResult = &mcp.CallToolResult{
 					IsError: true,
-					Content: []mcp.Content{&mcp.TextContent{Text: ctErr.Error()}},
+					Content: []mcp.Content{&mcp.TextContent{Text: vtErr.Error()}},
 				}
 			} else {
+				if resultText == "" {
+					resultText = fmt.Sprintf("Tool '%s' executed successfully but returned no output.", tc.FunctionCall.Name)
+				}
 				mcpResult = &mcp.CallToolResult{
 					IsError: false,
 					Content: []mcp.Content{&mcp.TextContent{Text: resultText}},
 				}
 			}
+		} else if a.customTools != nil {
+			if customTool, exists := a.customTools[tc.FunctionCall.Name]; exists {
+				resultText, ctErr := customTool.Execution(toolCtx, call.Arguments)
+				if ctErr != nil {
+					mcpResult = &mcp.CallToolResult{
+						IsError: true,
+						Content: []mcp.Content{&mcp.TextContent{Text: ctErr.Error()}},
+					}
+				} else {
+					mcpResult = &mcp.CallToolResult{
+						IsError: false,
+						Content: []mcp.Content{&mcp.TextContent{Text: resultText}},
+					}
+				}
+			} else {
+				// Fallback to MCP client
+				return a.callMCPTool(toolCtx, plan.client, actualToolName, call.Arguments, v2Logger, plan.serverName)
+			}
 		} else {
-			// Fallback to MCP client
-			mcpResult, toolErr = callToolWithTimeoutWrapper(toolCtx, plan.client, actualToolName, plan.args, v2Logger, plan.serverName)
+			return a.callMCPTool(toolCtx, plan.client, actualToolName, call.Arguments, v2Logger, plan.serverName)
 		}
-	} else {
-		mcpResult, toolErr = callToolWithTimeoutWrapper(toolCtx, plan.client, actualToolName, plan.args, v2Logger, plan.serverName)
+
+		return mcpResult, nil
+	}
+	invocation := &ToolInvocation{
+		ID:         tc.ID,
+		Name:       tc.FunctionCall.Name,
+		ServerName: plan.serverName,
+		Type:       plan.toolType,
+		Turn:       turn + 1,
+		Arguments:  plan.args,
 	}
+	mcpResult, toolErr := a.executeWithToolMiddleware(toolCtx, invocation, execute)
 
 	result.duration = time.Since(startTime)
+	result.fromCache = fromCache.Load()
 
-	// Check for timeout
+	// Check for timeout: the model gets a structured timeout result with any
+	// partial output instead of an error
 	if toolCtx.Err() == context.DeadlineExceeded {
-		toolErr = fmt.Errorf("tool execution timed out after %s: %s", plan.toolTimeout.String(), tc.FunctionCall.Name)
+		mcpResult, toolErr = toolTimeoutResult(tc.FunctionCall.Name, plan.serverName, plan.toolTimeout, mcpResult), nil
 	}
 
 	// Handle tool execution errors
@@ -542,7 +598,9 @@ func executeToolCall(
 
 	// Process result
 	var resultText string
+	var resultImages []llmtypes.ImageContent
 	if mcpResult != nil {
+		mcpResult, resultImages = a.processToolImages(ctx, tc.FunctionCall.Name, mcpResult)
 		resultText = mcpclient.ToolResultAsString(mcpResult)
 
 		if resultText == "" && !mcpResult.IsError {
@@ -558,7 +616,7 @@ func executeToolCall(
 				ctx, &tc, plan.serverName, fakeErr, time.Now().Add(-result.duration), plan.isCustomTool, plan.isVirtual)
 
 			if wasRecovered && recoveredErr == nil {
-				mcpResult = recoveredResult
+				mcpResult, resultImages = a.processToolImages(ctx, tc.FunctionCall.Name, recoveredResult)
 				result.duration = recoveredDuration
 				resultText = mcpclient.ToolResultAsString(mcpResult)
 			}
@@ -602,11 +660,20 @@ func executeToolCall(
 		resultText = "Tool execution completed but no result returned"
 	}
 
+	toolResponse := llmtypes.ToolCallResponse{ToolCallID: tc.ID, Name: tc.FunctionCall.Name, Content: resultText, IsError: mcpResult != nil && mcpResult.IsError}
+	if len(resultImages) > 0 {
+		if a.toolImagesInToolResult() {
+			toolResponse.Images = resultImages
+		} else {
+			result.images = resultImages
+		}
+	}
+
 	result.result = mcpResult
 	result.resultText = resultText
 	result.messages = []llmtypes.MessageContent{{
 		Role:  llmtypes.ChatMessageTypeTool,
-		Parts: []llmtypes.ContentPart{llmtypes.ToolCallResponse{ToolCallID: tc.ID, Name: tc.FunctionCall.Name, Content: resultText, IsError: mcpResult != nil && mcpResult.IsError}},
+		Parts: []llmtypes.ContentPart{toolResponse},
 	}}
 	return result
 }
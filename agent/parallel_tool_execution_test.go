@@ -0,0 +1,97 @@
+package mcpagent
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/manishiitg/mcpagent/events"
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+func TestParallelToolExecutionHonorsMaxParallelToolCalls(t *testing.T) {
+	var mu sync.Mutex
+	running, peak := 0, 0
+	slowTool := CustomTool{
+		Definition: hintTestTool("fetch"),
+		Category:   "custom",
+		Timeout:    -1,
+		Execution: func(_ context.Context, args map[string]interface{}) (string, error) {
+			mu.Lock()
+			running++
+			if running > peak {
+				peak = running
+			}
+			mu.Unlock()
+			time.Sleep(20 * time.Millisecond)
+			mu.Lock()
+			running--
+			mu.Unlock()
+			return fmt.Sprintf("page %v", args["n"]), nil
+		},
+	}
+	a := &Agent{Logger: loggerv2.NewNoop(), customTools: map[string]CustomTool{"fetch": slowTool}}
+	WithMaxParallelToolCalls(2)(a)
+	if !a.EnableParallelToolExecution {
+		t.Fatal("WithMaxParallelToolCalls should enable parallel execution")
+	}
+	listener := &recordingAgentEventListener{}
+	a.AddEventListener(listener)
+
+	var calls []llmtypes.ToolCall
+	for i := 0; i < 5; i++ {
+		calls = append(calls, llmtypes.ToolCall{
+			ID:           fmt.Sprintf("call_%d", i),
+			FunctionCall: &llmtypes.FunctionCall{Name: "fetch", Arguments: fmt.Sprintf(`{"n":%d}`, i)},
+		})
+	}
+	ctx := context.Background()
+	messages, err := DefaultToolDispatcher{}.DispatchTools(ctx, a, &ToolDispatch{ToolCalls: calls, StartTime: time.Now()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if peak != 2 {
+		t.Errorf("peak concurrency = %d, want 2", peak)
+	}
+	if len(messages) != 5 {
+		t.Fatalf("got %d tool results, want 5", len(messages))
+	}
+	for i, msg := range messages {
+		resp := msg.Parts[0].(llmtypes.ToolCallResponse)
+		if resp.ToolCallID != calls[i].ID || resp.Content != fmt.Sprintf("page %d", i) {
+			t.Errorf("result %d = %+v, want results in call order", i, resp)
+		}
+	}
+
+	starts, ends := 0, 0
+	for _, event := range listener.events {
+		switch ev := event.Data.(type) {
+		case *events.ToolCallStartEvent:
+			starts++
+			if !ev.IsParallel {
+				t.Errorf("start event for %s is not marked parallel", ev.ToolCallID)
+			}
+		case *events.ToolCallEndEvent:
+			ends++
+			if !ev.IsParallel {
+				t.Errorf("end event for %s is not marked parallel", ev.ToolCallID)
+			}
+		}
+	}
+	if starts != 5 || ends != 5 {
+		t.Errorf("got %d start and %d end events, want 5 each", starts, ends)
+	}
+}
+
+func TestMaxParallelToolCallsOfOneRunsSequentially(t *testing.T) {
+	a := &Agent{}
+	WithParallelToolExecution(true)(a)
+	WithMaxParallelToolCalls(1)(a)
+	if a.EnableParallelToolExecution {
+		t.Error("a bound of 1 should execute tool calls sequentially")
+	}
+}
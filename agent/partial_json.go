@@ -0,0 +1,369 @@
+// partial_json.go
+//
+// This file implements an incremental JSON parser for streamed structured
+// output. Native structured output (AskWithHistoryStructuredNative) and CLI
+// structured output stream the answer as JSON text; the parser consumes the
+// content chunks as they arrive and keeps a partial object built from the
+// text seen so far, without re-parsing the whole buffer per chunk. Each chunk
+// that completes a value or extends a string is reported as a
+// StreamingStructuredChunkEvent, so UIs can render forms field by field while
+// the LLM is still generating.
+//
+// Text before the first '{' or '[' (e.g. a ```json fence) and after the
+// closing bracket of the root value is ignored.
+
+package mcpagent
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/manishiitg/mcpagent/events"
+)
+
+// partialJSONNode is a value of the partial object. Containers and strings
+// are attached to their parent as soon as they open; other scalars once they
+// are complete.
+type partialJSONNode struct {
+	kind     byte // '{', '[', '"' or 's' (number, true, false, null)
+	path     string
+	keys     []string // Object keys in order of appearance
+	fields   map[string]*partialJSONNode
+	items    []*partialJSONNode
+	text     strings.Builder // String contents decoded so far
+	scalar   interface{}
+	complete bool
+}
+
+// partialJSONParser incrementally parses one JSON value
+type partialJSONParser struct {
+	root  *partialJSONNode
+	stack []*partialJSONNode // Open containers, innermost last
+	done  bool
+
+	// Object key state of the innermost open object
+	key    string
+	hasKey bool
+
+	// Token in progress
+	inString    bool
+	stringIsKey bool
+	keyText     strings.Builder
+	str         *partialJSONNode // String value being read; nil for keys
+	escape      bool
+	unicode     []byte // Hex digits of a \u escape being read
+	highSurr    rune   // Pending high surrogate of a \u escape pair
+	literal     strings.Builder
+	inLiteral   bool
+	completed   []string // Paths of the values completed by the current Write
+	grewPending bool     // The current Write extended a string in progress
+}
+
+// Write consumes the next chunk of JSON text. It reports the paths of the
+// values completed by the chunk and whether the chunk changed the partial
+// object at all (completed a value, opened one or extended a string).
+func (p *partialJSONParser) Write(chunk string) (completed []string, changed bool) {
+	p.completed, p.grewPending = nil, false
+	opened := false
+	for i := 0; i < len(chunk) && !p.done; i++ {
+		c := chunk[i]
+		if p.inString {
+			p.readStringByte(c)
+			continue
+		}
+		if p.inLiteral {
+			if isJSONLiteralByte(c) {
+				p.literal.WriteByte(c)
+				continue
+			}
+			p.finishLiteral()
+			if p.done {
+				break
+			}
+		}
+		if p.root == nil && c != '{' && c != '[' {
+			continue // Preamble such as a markdown fence
+		}
+		switch c {
+		case '{', '[':
+			p.attach(&partialJSONNode{kind: c, fields: map[string]*partialJSONNode{}})
+			opened = true
+		case '}', ']':
+			p.closeContainer()
+		case '"':
+			p.inString = true
+			p.stringIsKey = p.expectingKey()
+			if p.stringIsKey {
+				p.keyText.Reset()
+			} else {
+				p.str = &partialJSONNode{kind: '"'}
+				p.attach(p.str)
+				opened = true
+			}
+		case ':', ',', ' ', '\t', '\n', '\r':
+		default:
+			if isJSONLiteralByte(c) {
+				p.inLiteral = true
+				p.literal.Reset()
+				p.literal.WriteByte(c)
+			}
+		}
+	}
+	return p.completed, opened || p.grewPending || len(p.completed) > 0
+}
+
+// Done reports whether the root value is complete
+func (p *partialJSONParser) Done() bool {
+	return p.done
+}
+
+// Pending returns the path of the string value still being written, "" when
+// no string is in progress
+func (p *partialJSONParser) Pending() string {
+	if p.inString && p.str != nil {
+		return p.str.path
+	}
+	return ""
+}
+
+// Value returns the partial object: objects, arrays and strings as far as
+// they have been written, and complete numbers, booleans and nulls. nil until
+// the root value has started.
+func (p *partialJSONParser) Value() interface{} {
+	if p.root == nil {
+		return nil
+	}
+	return p.root.value()
+}
+
+func (n *partialJSONNode) value() interface{} {
+	switch n.kind {
+	case '{':
+		obj := make(map[string]interface{}, len(n.keys))
+		for _, key := range n.keys {
+			obj[key] = n.fields[key].value()
+		}
+		return obj
+	case '[':
+		arr := make([]interface{}, len(n.items))
+		for i, item := range n.items {
+			arr[i] = item.value()
+		}
+		return arr
+	case '"':
+		s := n.text.String()
+		if !n.complete {
+			s = trimIncompleteRune(s)
+		}
+		return s
+	}
+	return n.scalar
+}
+
+// expectingKey reports whether the next string is an object key
+func (p *partialJSONParser) expectingKey() bool {
+	return len(p.stack) > 0 && p.stack[len(p.stack)-1].kind == '{' && !p.hasKey
+}
+
+// attach adds n to the innermost open container (or makes it the root) and
+// opens it if it is a container
+func (p *partialJSONParser) attach(n *partialJSONNode) {
+	if len(p.stack) == 0 {
+		if p.root != nil {
+			return
+		}
+		p.root = n
+	} else {
+		parent := p.stack[len(p.stack)-1]
+		switch parent.kind {
+		case '{':
+			if !p.hasKey {
+				return // Value without a key; malformed, skip
+			}
+			n.path = joinPartialPath(parent.path, p.key)
+			if _, exists := parent.fields[p.key]; !exists {
+				parent.keys = append(parent.keys, p.key)
+			}
+			parent.fields[p.key] = n
+			p.hasKey = false
+		case '[':
+			n.path = joinPartialPath(parent.path, strconv.Itoa(len(parent.items)))
+			parent.items = append(parent.items, n)
+		}
+	}
+	if n.kind == '{' || n.kind == '[' {
+		p.stack = append(p.stack, n)
+		p.hasKey = false
+	}
+	if n.kind == 's' {
+		p.complete(n)
+	}
+}
+
+// complete marks n complete and records its path
+func (p *partialJSONParser) complete(n *partialJSONNode) {
+	n.complete = true
+	if n == p.root {
+		p.done = true
+		return
+	}
+	p.completed = append(p.completed, n.path)
+}
+
+func (p *partialJSONParser) closeContainer() {
+	if len(p.stack) == 0 {
+		return
+	}
+	n := p.stack[len(p.stack)-1]
+	p.stack = p.stack[:len(p.stack)-1]
+	p.hasKey = false
+	p.complete(n)
+}
+
+func (p *partialJSONParser) finishLiteral() {
+	p.inLiteral = false
+	var v interface{}
+	if err := json.Unmarshal([]byte(p.literal.String()), &v); err != nil {
+		return // Malformed literal; skip it
+	}
+	p.attach(&partialJSONNode{kind: 's', scalar: v})
+}
+
+// readStringByte consumes one byte inside a string
+func (p *partialJSONParser) readStringByte(c byte) {
+	switch {
+	case p.unicode != nil:
+		p.unicode = append(p.unicode, c)
+		if len(p.unicode) < 4 {
+			return
+		}
+		code, err := strconv.ParseUint(string(p.unicode), 16, 32)
+		p.unicode = nil
+		if err != nil {
+			return
+		}
+		r := rune(code)
+		switch {
+		case utf16.IsSurrogate(r) && p.highSurr == 0:
+			p.highSurr = r
+			return
+		case p.highSurr != 0:
+			r = utf16.DecodeRune(p.highSurr, r)
+			p.highSurr = 0
+		}
+		p.writeString(string(r))
+	case p.escape:
+		p.escape = false
+		switch c {
+		case 'u':
+			p.unicode = make([]byte, 0, 4)
+		case 'n':
+			p.writeString("\n")
+		case 't':
+			p.writeString("\t")
+		case 'r':
+			p.writeString("\r")
+		case 'b':
+			p.writeString("\b")
+		case 'f':
+			p.writeString("\f")
+		default: // '"', '\\', '/'
+			p.writeString(string(c))
+		}
+	case c == '\\':
+		p.escape = true
+	case c == '"':
+		p.inString = false
+		if p.stringIsKey {
+			p.key, p.hasKey = p.keyText.String(), true
+			return
+		}
+		str := p.str
+		p.str = nil
+		p.complete(str)
+	default:
+		p.writeString(string([]byte{c})) // Raw byte; multi-byte characters span calls
+	}
+}
+
+func (p *partialJSONParser) writeString(s string) {
+	if p.stringIsKey {
+		p.keyText.WriteString(s)
+		return
+	}
+	if p.str != nil {
+		p.str.text.WriteString(s)
+		p.grewPending = true
+	}
+}
+
+func isJSONLiteralByte(c byte) bool {
+	return c == '-' || c == '+' || c == '.' || (c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// joinPartialPath joins a parent path and a key or array index with '.'
+func joinPartialPath(parent, key string) string {
+	if parent == "" {
+		return key
+	}
+	return parent + "." + key
+}
+
+// trimIncompleteRune drops a multi-byte character split across chunks from
+// the end of s
+func trimIncompleteRune(s string) string {
+	for i := 0; i < utf8.UTFMax-1 && len(s) > 0 && !utf8.ValidString(s); i++ {
+		s = s[:len(s)-1]
+	}
+	return s
+}
+
+// callWithPartialJSON streams the answer of this call, which is expected to
+// be JSON, as StreamingStructuredChunkEvents. Calls with a native response
+// schema (callWithResponseSchema) always do.
+func callWithPartialJSON() CallOption {
+	return func(o *callOptions) {
+		o.partialJSON = true
+	}
+}
+
+// newCallPartialJSON reports whether opts enable partial JSON streaming
+func newCallPartialJSON(opts []CallOption) bool {
+	var o callOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o.partialJSON
+}
+
+// structuredChunkStreamer turns the content chunks of a structured output
+// call into StreamingStructuredChunkEvents
+type structuredChunkStreamer struct {
+	parser     partialJSONParser
+	chunkIndex int
+}
+
+// write parses chunk and returns the event to emit, nil when the partial
+// object did not change
+func (s *structuredChunkStreamer) write(chunk string) *events.StreamingStructuredChunkEvent {
+	if s.parser.Done() {
+		return nil
+	}
+	completed, changed := s.parser.Write(chunk)
+	if !changed && !s.parser.Done() {
+		return nil
+	}
+	s.chunkIndex++
+	return &events.StreamingStructuredChunkEvent{
+		BaseEventData:   events.BaseEventData{Timestamp: time.Now()},
+		Partial:         s.parser.Value(),
+		CompletedFields: completed,
+		PendingField:    s.parser.Pending(),
+		ChunkIndex:      s.chunkIndex,
+		Done:            s.parser.Done(),
+	}
+}
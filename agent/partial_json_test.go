@@ -0,0 +1,106 @@
+package mcpagent
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/manishiitg/mcpagent/events"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+func TestPartialJSONParserMatchesFullParse(t *testing.T) {
+	doc := "```json\n" + `{"title": "Q3 \"plan\" é😀", "score": -1.5e2, "ok": true, "none": null,
+	"items": [{"name": "a", "tags": ["x", "y"]}, {"name": "b", "tags": []}], "nested": {"deep": {"n": 3}}}` + "\n```"
+
+	var want interface{}
+	if err := json.Unmarshal([]byte(doc[len("```json\n"):len(doc)-len("\n```")]), &want); err != nil {
+		t.Fatal(err)
+	}
+	// Every split size, including byte-by-byte through multi-byte characters
+	for size := 1; size <= len(doc); size++ {
+		var p partialJSONParser
+		for i := 0; i < len(doc); i += size {
+			end := min(i+size, len(doc))
+			p.Write(doc[i:end])
+			if _, err := json.Marshal(p.Value()); err != nil {
+				t.Fatalf("size %d: partial value does not marshal: %v", size, err)
+			}
+		}
+		if !p.Done() {
+			t.Fatalf("size %d: parser not done", size)
+		}
+		if got := p.Value(); !reflect.DeepEqual(got, want) {
+			t.Fatalf("size %d: value = %#v, want %#v", size, got, want)
+		}
+	}
+}
+
+func TestPartialJSONParserReportsFieldsAsTheyComplete(t *testing.T) {
+	var p partialJSONParser
+
+	completed, changed := p.Write(`{"name": "Ad`)
+	if !changed || len(completed) != 0 || p.Pending() != "name" {
+		t.Fatalf("completed = %v, changed = %v, pending = %q", completed, changed, p.Pending())
+	}
+	if got := p.Value(); !reflect.DeepEqual(got, map[string]interface{}{"name": "Ad"}) {
+		t.Fatalf("partial = %#v", got)
+	}
+
+	completed, _ = p.Write(`a", "age": 3`)
+	if !reflect.DeepEqual(completed, []string{"name"}) || p.Pending() != "" {
+		t.Fatalf("completed = %v, pending = %q", completed, p.Pending())
+	}
+	if _, ok := p.Value().(map[string]interface{})["age"]; ok {
+		t.Fatal("incomplete number should not be reported")
+	}
+
+	completed, _ = p.Write(`6, "pets": [{"kind": "cat"}]}`)
+	if !reflect.DeepEqual(completed, []string{"age", "pets.0.kind", "pets.0", "pets"}) || !p.Done() {
+		t.Fatalf("completed = %v, done = %v", completed, p.Done())
+	}
+
+	if _, changed := p.Write(`{"ignored": true}`); changed {
+		t.Fatal("text after the root value should be ignored")
+	}
+}
+
+func TestStreamingManagerEmitsStructuredChunks(t *testing.T) {
+	listener := &recordingAgentEventListener{}
+	agent := &Agent{
+		SessionID: "session-structured-stream-test",
+		listeners: []AgentEventListener{listener},
+	}
+	sm := &streamingManager{
+		streamChan:    make(chan llmtypes.StreamChunk, 4),
+		streamingDone: make(chan bool, 1),
+		startTime:     time.Now(),
+		structured:    &structuredChunkStreamer{},
+	}
+	go sm.processChunks(context.Background(), agent)
+
+	for _, content := range []string{`{"summary": "Rev`, `enue up", "risk": `, `"low"}`} {
+		sm.streamChan <- llmtypes.StreamChunk{Type: llmtypes.StreamChunkTypeContent, Content: content}
+	}
+	close(sm.streamChan)
+	<-sm.streamingDone
+
+	var chunks []*events.StreamingStructuredChunkEvent
+	for _, e := range listener.events {
+		if chunk, ok := e.Data.(*events.StreamingStructuredChunkEvent); ok {
+			chunks = append(chunks, chunk)
+		}
+	}
+	if len(chunks) != 3 {
+		t.Fatalf("structured chunks = %d, want 3", len(chunks))
+	}
+	if got := chunks[0].Partial; !reflect.DeepEqual(got, map[string]interface{}{"summary": "Rev"}) || chunks[0].PendingField != "summary" {
+		t.Errorf("first chunk = %+v", chunks[0])
+	}
+	last := chunks[2]
+	if !last.Done || !reflect.DeepEqual(last.Partial, map[string]interface{}{"summary": "Revenue up", "risk": "low"}) {
+		t.Errorf("last chunk = %+v", last)
+	}
+}
@@ -0,0 +1,234 @@
+// pipeline.go
+//
+// This file defines the stages of the Ask pipeline and the extension points
+// for replacing them. AskWithHistory runs a conversation as:
+//
+//	prepare → route → [generate → dispatch tools]* → postprocess → finalize
+//
+//   - Prepare:     shapes the incoming history once (default: inject the system prompt)
+//   - Route:       selects the tools offered to the LLM for the conversation
+//     (default: allow list, tool permissions and call hints, or the tool search set)
+//   - Generate:    one LLM call per turn (default: GenerateContentWithRetry with fallbacks)
+//   - Dispatch:    executes the tool calls of a turn and appends their results
+//     (default: parallel or sequential execution, see parallel_tool_execution.go)
+//   - Postprocess: adjusts the final answer before it is returned
+//     (default: glossary pass and references to cited offloaded outputs, see
+//     glossary.go and output_references.go)
+//   - Finalize:    runs after every conversation, successful or not
+//     (default: autosave bookkeeping and retained history, see autosave.go and memory.go)
+//
+// Turn bookkeeping (events, context editing, summarization, steering, loop
+// detection and the max-turns final call) stays in conversation.go so custom
+// stages inherit it. A custom stage can wrap the default one, e.g. a
+// ToolDispatcher that audits calls and then delegates to DefaultToolDispatcher.
+//
+// Exported:
+//   - PrepareStage, RouteStage, GenerateStage, ToolDispatcher, PostprocessStage, FinalizeStage
+//   - Default* implementations of each stage
+//   - AskPipeline / WithAskPipeline: Replace stages when creating an agent
+
+package mcpagent
+
+import (
+	"context"
+	"time"
+
+	"github.com/manishiitg/mcpagent/observability"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+// PrepareStage shapes the message history before the first turn
+type PrepareStage interface {
+	Prepare(ctx context.Context, a *Agent, messages []llmtypes.MessageContent) ([]llmtypes.MessageContent, error)
+}
+
+// RouteStage selects the tools offered to the LLM for a conversation
+type RouteStage interface {
+	Route(ctx context.Context, a *Agent, messages []llmtypes.MessageContent) []llmtypes.Tool
+}
+
+// GenerateStage makes the LLM call for one turn
+type GenerateStage interface {
+	Generate(ctx context.Context, a *Agent, messages []llmtypes.MessageContent, opts []llmtypes.CallOption, turn int) (*llmtypes.ContentResponse, observability.UsageMetrics, error)
+}
+
+// ToolDispatch is the input of a ToolDispatcher for one turn
+type ToolDispatch struct {
+	ToolCalls []llmtypes.ToolCall
+	// Messages is the history including the assistant's tool call message
+	Messages []llmtypes.MessageContent
+	Turn     int
+	TraceID  string
+	// Question is the user message the conversation answers
+	Question  string
+	StartTime time.Time
+	// LoopDetector tracks repeated identical calls across turns
+	LoopDetector *ToolLoopDetector
+}
+
+// ToolDispatcher executes the tool calls of a turn and returns the history
+// with a tool result message appended for every call. An error ends the
+// conversation.
+type ToolDispatcher interface {
+	DispatchTools(ctx context.Context, a *Agent, dispatch *ToolDispatch) ([]llmtypes.MessageContent, error)
+}
+
+// PostprocessStage adjusts the final answer and history before they are returned
+type PostprocessStage interface {
+	Postprocess(ctx context.Context, a *Agent, answer string, messages []llmtypes.MessageContent) (string, []llmtypes.MessageContent, error)
+}
+
+// FinalizeStage runs after every conversation with its outcome. It must not
+// change the result; use PostprocessStage for that.
+type FinalizeStage interface {
+	Finalize(ctx context.Context, a *Agent, answer string, messages []llmtypes.MessageContent, err error)
+}
+
+// AskPipeline holds the stages AskWithHistory runs. Nil stages use the default.
+type AskPipeline struct {
+	Prepare       PrepareStage
+	Route         RouteStage
+	Generate      GenerateStage
+	DispatchTools ToolDispatcher
+	Postprocess   PostprocessStage
+	Finalize      FinalizeStage
+}
+
+// WithAskPipeline replaces stages of the Ask pipeline. Only the non-nil stages
+// of pipeline are replaced; it can be used multiple times.
+//
+// Example:
+//
+//	mcpagent.WithAskPipeline(mcpagent.AskPipeline{DispatchTools: auditingDispatcher{}})
+//
+// Default: the built-in stages (DefaultPrepareStage, DefaultRouteStage, ...)
+func WithAskPipeline(pipeline AskPipeline) AgentOption {
+	return func(a *Agent) {
+		if pipeline.Prepare != nil {
+			a.pipeline.Prepare = pipeline.Prepare
+		}
+		if pipeline.Route != nil {
+			a.pipeline.Route = pipeline.Route
+		}
+		if pipeline.Generate != nil {
+			a.pipeline.Generate = pipeline.Generate
+		}
+		if pipeline.DispatchTools != nil {
+			a.pipeline.DispatchTools = pipeline.DispatchTools
+		}
+		if pipeline.Postprocess != nil {
+			a.pipeline.Postprocess = pipeline.Postprocess
+		}
+		if pipeline.Finalize != nil {
+			a.pipeline.Finalize = pipeline.Finalize
+		}
+	}
+}
+
+// DefaultAskPipeline returns the built-in stages
+func DefaultAskPipeline() AskPipeline {
+	return AskPipeline{
+		Prepare:       DefaultPrepareStage{},
+		Route:         DefaultRouteStage{},
+		Generate:      DefaultGenerateStage{},
+		DispatchTools: DefaultToolDispatcher{},
+		Postprocess:   DefaultPostprocessStage{},
+		Finalize:      DefaultFinalizeStage{},
+	}
+}
+
+// AskPipeline returns the stages the agent runs, with defaults for unset stages
+func (a *Agent) AskPipeline() AskPipeline {
+	p := a.pipeline
+	defaults := DefaultAskPipeline()
+	if p.Prepare == nil {
+		p.Prepare = defaults.Prepare
+	}
+	if p.Route == nil {
+		p.Route = defaults.Route
+	}
+	if p.Generate == nil {
+		p.Generate = defaults.Generate
+	}
+	if p.DispatchTools == nil {
+		p.DispatchTools = defaults.DispatchTools
+	}
+	if p.Postprocess == nil {
+		p.Postprocess = defaults.Postprocess
+	}
+	if p.Finalize == nil {
+		p.Finalize = defaults.Finalize
+	}
+	return p
+}
+
+// DefaultPrepareStage ensures the agent's system prompt leads the history
+type DefaultPrepareStage struct{}
+
+// Prepare implements PrepareStage
+func (DefaultPrepareStage) Prepare(ctx context.Context, a *Agent, messages []llmtypes.MessageContent) ([]llmtypes.MessageContent, error) {
+	return ensureSystemPrompt(ctx, a, messages), nil
+}
+
+// DefaultRouteStage offers the agent's tools after the allow list, smart
+// routing, tool permissions and call hints; in tool search mode the search
+// tools plus the tools discovered so far
+type DefaultRouteStage struct{}
+
+// Route implements RouteStage
+func (DefaultRouteStage) Route(ctx context.Context, a *Agent, messages []llmtypes.MessageContent) []llmtypes.Tool {
+	if a.UseToolSearchMode {
+		return a.applyToolHints(ctx, a.applyToolPermissions(a.applyToolAllowList(a.getToolsForToolSearchMode())))
+	}
+	return a.applyToolHints(ctx, a.applyToolPermissions(a.applySmartRouting(ctx, messages, a.applyToolAllowList(a.Tools))))
+}
+
+// DefaultGenerateStage calls the LLM through GenerateContentWithRetry
+type DefaultGenerateStage struct{}
+
+// Generate implements GenerateStage
+func (DefaultGenerateStage) Generate(ctx context.Context, a *Agent, messages []llmtypes.MessageContent, opts []llmtypes.CallOption, turn int) (*llmtypes.ContentResponse, observability.UsageMetrics, error) {
+	return GenerateContentWithRetry(a, ctx, messages, opts, turn)
+}
+
+// DefaultToolDispatcher executes tool calls concurrently (up to
+// MaxParallelToolCalls at once) when parallel tool execution is enabled and
+// there are several, otherwise one at a time
+type DefaultToolDispatcher struct{}
+
+// DispatchTools implements ToolDispatcher
+func (DefaultToolDispatcher) DispatchTools(ctx context.Context, a *Agent, d *ToolDispatch) ([]llmtypes.MessageContent, error) {
+	loopDetector := d.LoopDetector
+	if loopDetector == nil {
+		loopDetector = NewToolLoopDetector(DefaultLoopDetectionThreshold)
+	}
+	if a.EnableParallelToolExecution && len(d.ToolCalls) > 1 {
+		return executeToolCallsParallel(ctx, a, d.ToolCalls, d.Messages, d.Turn, d.TraceID, d.StartTime, d.Question, loopDetector, ctx)
+	}
+	return executeToolCallsSequential(ctx, a, d.ToolCalls, d.Messages, d.Turn, d.TraceID, d.StartTime, d.Question, loopDetector, ctx)
+}
+
+// DefaultPostprocessStage rewrites glossary variants in the answer and its
+// history message (WithGlossary), then appends references to the offloaded
+// outputs the answer cites (WithOutputReferences); otherwise the answer is
+// unchanged
+type DefaultPostprocessStage struct{}
+
+// Postprocess implements PostprocessStage
+func (DefaultPostprocessStage) Postprocess(_ context.Context, a *Agent, answer string, messages []llmtypes.MessageContent) (string, []llmtypes.MessageContent, error) {
+	answer, messages = a.applyGlossaryToAnswer(answer, messages)
+	return a.appendOutputReferences(answer), messages, nil
+}
+
+// DefaultFinalizeStage completes autosave, saves the conversation session and
+// retains the history (with WithHistoryRetention)
+type DefaultFinalizeStage struct{}
+
+// Finalize implements FinalizeStage
+func (DefaultFinalizeStage) Finalize(ctx context.Context, a *Agent, _ string, messages []llmtypes.MessageContent, err error) {
+	a.finishAutosave(ctx, messages, err)
+	a.saveSession(ctx, messages, err)
+	if a.historyRetention {
+		a.retainHistory(messages)
+	}
+}
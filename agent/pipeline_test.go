@@ -0,0 +1,153 @@
+package mcpagent
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/manishiitg/mcpagent/events"
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+	"github.com/manishiitg/mcpagent/observability"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+// scriptedGenerateStage returns its responses in order, one per turn
+type scriptedGenerateStage struct {
+	responses []*llmtypes.ContentResponse
+	calls     int
+}
+
+func (s *scriptedGenerateStage) Generate(_ context.Context, _ *Agent, _ []llmtypes.MessageContent, _ []llmtypes.CallOption, _ int) (*llmtypes.ContentResponse, observability.UsageMetrics, error) {
+	resp := s.responses[s.calls]
+	s.calls++
+	return resp, observability.UsageMetrics{}, nil
+}
+
+type recordingToolDispatcher struct {
+	dispatched []string
+}
+
+func (d *recordingToolDispatcher) DispatchTools(_ context.Context, _ *Agent, dispatch *ToolDispatch) ([]llmtypes.MessageContent, error) {
+	messages := dispatch.Messages
+	for _, tc := range dispatch.ToolCalls {
+		d.dispatched = append(d.dispatched, tc.FunctionCall.Name)
+		messages = append(messages, llmtypes.MessageContent{
+			Role:  llmtypes.ChatMessageTypeTool,
+			Parts: []llmtypes.ContentPart{llmtypes.ToolCallResponse{ToolCallID: tc.ID, Name: tc.FunctionCall.Name, Content: "ok"}},
+		})
+	}
+	return messages, nil
+}
+
+type upperPostprocessStage struct{}
+
+func (upperPostprocessStage) Postprocess(_ context.Context, _ *Agent, answer string, messages []llmtypes.MessageContent) (string, []llmtypes.MessageContent, error) {
+	return strings.ToUpper(answer), messages, nil
+}
+
+type recordingFinalizeStage struct {
+	answer string
+	err    error
+}
+
+func (f *recordingFinalizeStage) Finalize(_ context.Context, _ *Agent, answer string, _ []llmtypes.MessageContent, err error) {
+	f.answer, f.err = answer, err
+}
+
+func TestAskPipelineUsesReplacedStages(t *testing.T) {
+	generate := &scriptedGenerateStage{responses: []*llmtypes.ContentResponse{
+		{Choices: []*llmtypes.ContentChoice{{ToolCalls: []llmtypes.ToolCall{{
+			ID:           "call-1",
+			Type:         "function",
+			FunctionCall: &llmtypes.FunctionCall{Name: "lookup", Arguments: `{}`},
+		}}}}},
+		{Choices: []*llmtypes.ContentChoice{{Content: "done"}}},
+	}}
+	dispatcher := &recordingToolDispatcher{}
+	finalize := &recordingFinalizeStage{}
+
+	a := &Agent{Logger: loggerv2.NewNoop(), LLM: &providerKeyCarrierModel{}, ModelID: "test-model", MaxTurns: 5}
+	WithAskPipeline(AskPipeline{
+		Generate:      generate,
+		DispatchTools: dispatcher,
+		Postprocess:   upperPostprocessStage{},
+		Finalize:      finalize,
+	})(a)
+
+	answer, history, err := AskWithHistory(a, context.Background(), []llmtypes.MessageContent{
+		llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "look it up"),
+	})
+	if err != nil {
+		t.Fatalf("AskWithHistory: %v", err)
+	}
+	if answer != "DONE" {
+		t.Errorf("answer = %q, want the postprocessed %q", answer, "DONE")
+	}
+	if generate.calls != 2 {
+		t.Errorf("expected 2 generate calls, got %d", generate.calls)
+	}
+	if len(dispatcher.dispatched) != 1 || dispatcher.dispatched[0] != "lookup" {
+		t.Errorf("custom dispatcher saw %v", dispatcher.dispatched)
+	}
+	if finalize.answer != "DONE" || finalize.err != nil {
+		t.Errorf("finalize saw answer=%q err=%v", finalize.answer, finalize.err)
+	}
+	if last := history[len(history)-1]; last.Role != llmtypes.ChatMessageTypeAI {
+		t.Errorf("expected the assistant answer to end the history, got %s", last.Role)
+	}
+}
+
+func TestAgentAskPipelineFillsDefaults(t *testing.T) {
+	a := &Agent{}
+	WithAskPipeline(AskPipeline{Postprocess: upperPostprocessStage{}})(a)
+
+	p := a.AskPipeline()
+	if _, ok := p.Postprocess.(upperPostprocessStage); !ok {
+		t.Errorf("expected the replaced postprocess stage, got %T", p.Postprocess)
+	}
+	if _, ok := p.DispatchTools.(DefaultToolDispatcher); !ok {
+		t.Errorf("expected the default dispatcher, got %T", p.DispatchTools)
+	}
+	if p.Prepare == nil || p.Route == nil || p.Generate == nil || p.Finalize == nil {
+		t.Errorf("expected every stage to be set: %+v", p)
+	}
+}
+
+// cancellingGenerateStage cancels the conversation the way the gRPC
+// CancelRequest RPC does and fails with the context error
+type cancellingGenerateStage struct {
+	cancel context.CancelCauseFunc
+}
+
+func (s cancellingGenerateStage) Generate(ctx context.Context, _ *Agent, _ []llmtypes.MessageContent, _ []llmtypes.CallOption, _ int) (*llmtypes.ContentResponse, observability.UsageMetrics, error) {
+	s.cancel(errors.New("user pressed stop"))
+	return nil, observability.UsageMetrics{}, ctx.Err()
+}
+
+func TestAskWithHistoryEmitsCancelledConversationEnd(t *testing.T) {
+	ctx, cancel := context.WithCancelCause(context.Background())
+	a := &Agent{Logger: loggerv2.NewNoop(), LLM: &providerKeyCarrierModel{}, ModelID: "test-model", MaxTurns: 5}
+	WithAskPipeline(AskPipeline{Generate: cancellingGenerateStage{cancel: cancel}})(a)
+	listener := &syncEventListener{}
+	a.AddEventListener(listener)
+
+	_, _, err := AskWithHistory(a, ctx, []llmtypes.MessageContent{
+		llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "crawl the docs"),
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("AskWithHistory error = %v, want context.Canceled", err)
+	}
+
+	listener.mu.Lock()
+	defer listener.mu.Unlock()
+	for _, e := range listener.events {
+		if end, ok := e.Data.(*events.ConversationEndEvent); ok {
+			if end.Status != ConversationCancelledStatus || end.Error != "user pressed stop" || end.Question != "crawl the docs" {
+				t.Errorf("conversation end = %+v", end)
+			}
+			return
+		}
+	}
+	t.Fatal("no conversation_end event emitted")
+}
@@ -0,0 +1,198 @@
+// plan.go
+//
+// This file implements plan tracking: with it enabled, the LLM gets an
+// update_plan virtual tool to keep a checklist of the steps of a long task
+// (e.g. "fetch the filings", "compare peers", "write the report"). Every call
+// sends the whole plan; the agent compares it with the previous one and emits
+// plan_created when the steps change and plan_step_started /
+// plan_step_completed when a step's status changes, so UIs can render a live
+// task checklist.
+//
+// Exported:
+//   - WithPlanTracking: Offer the update_plan tool and emit plan events
+//   - Agent.GetPlan: The current plan
+
+package mcpagent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/manishiitg/mcpagent/events"
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+// Plan step statuses accepted by update_plan
+const (
+	planStepPending    = "pending"
+	planStepInProgress = "in_progress"
+	planStepCompleted  = "completed"
+)
+
+// WithPlanTracking offers the LLM the update_plan tool to keep a checklist of
+// the steps of a multi-step task, and emits plan_created, plan_step_started
+// and plan_step_completed events as it updates it. Not available in code
+// execution mode.
+//
+// Default: false
+func WithPlanTracking(enabled bool) AgentOption {
+	return func(a *Agent) {
+		a.EnablePlanTracking = enabled
+	}
+}
+
+// GetPlan returns a copy of the plan last set with update_plan, or nil when
+// the LLM has not made one
+func (a *Agent) GetPlan() []events.PlanStep {
+	a.planMu.Lock()
+	defer a.planMu.Unlock()
+	if a.plan == nil {
+		return nil
+	}
+	return append([]events.PlanStep(nil), a.plan...)
+}
+
+// createUpdatePlanTool returns the update_plan virtual tool
+func createUpdatePlanTool() llmtypes.Tool {
+	return llmtypes.Tool{
+		Type: "function",
+		Function: &llmtypes.FunctionDefinition{
+			Name: "update_plan",
+			Description: "Create or update your plan for a task that takes several steps. Send the full list of steps every time, in order, " +
+				"with the status of each. Keep exactly one step in_progress while working, and mark a step completed as soon as it is done. " +
+				"Skip this tool for simple tasks that take one or two steps.",
+			Parameters: llmtypes.NewParameters(map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"steps": map[string]interface{}{
+						"type":        "array",
+						"description": "All steps of the plan, in order",
+						"items": map[string]interface{}{
+							"type": "object",
+							"properties": map[string]interface{}{
+								"title": map[string]interface{}{
+									"type":        "string",
+									"description": "Short description of the step",
+								},
+								"status": map[string]interface{}{
+									"type": "string",
+									"enum": []string{planStepPending, planStepInProgress, planStepCompleted},
+								},
+							},
+							"required": []string{"title", "status"},
+						},
+					},
+					"explanation": map[string]interface{}{
+						"type":        "string",
+						"description": "Why the plan was created or changed (optional)",
+					},
+				},
+				"required": []string{"steps"},
+			}),
+		},
+	}
+}
+
+// handleUpdatePlan handles the update_plan virtual tool
+func (a *Agent) handleUpdatePlan(ctx context.Context, args map[string]interface{}) (string, error) {
+	steps, err := parsePlanSteps(args["steps"])
+	if err != nil {
+		return "", err
+	}
+	explanation, _ := args["explanation"].(string)
+	turn, _ := ctx.Value(ToolExecutionTurnKey).(int)
+
+	a.planMu.Lock()
+	previous := a.plan
+	a.plan = steps
+	revised := !samePlanTitles(previous, steps)
+	if revised {
+		a.planRevision++
+	}
+	revision := a.planRevision
+	a.planMu.Unlock()
+
+	completed := 0
+	for _, step := range steps {
+		if step.Status == planStepCompleted {
+			completed++
+		}
+	}
+
+	if revised {
+		a.EmitTypedEvent(ctx, events.NewPlanCreatedEvent(turn, append([]events.PlanStep(nil), steps...), explanation, revision))
+		getLogger(a).Info("📋 [PLAN] Plan set",
+			loggerv2.Int("steps", len(steps)),
+			loggerv2.Int("revision", revision))
+	}
+	// Report status changes; after a revision, steps are compared with the
+	// same title in the previous plan so moved steps do not repeat events
+	previousStatus := make(map[string]string, len(previous))
+	for _, step := range previous {
+		previousStatus[step.Title] = step.Status
+	}
+	for i, step := range steps {
+		before := previousStatus[step.Title]
+		if step.Status == before {
+			continue
+		}
+		switch step.Status {
+		case planStepInProgress:
+			a.EmitTypedEvent(ctx, events.NewPlanStepStartedEvent(turn, i, step.Title, len(steps)))
+		case planStepCompleted:
+			a.EmitTypedEvent(ctx, events.NewPlanStepCompletedEvent(turn, i, step.Title, len(steps), completed))
+		}
+	}
+
+	return fmt.Sprintf("Plan updated: %d of %d steps completed.", completed, len(steps)), nil
+}
+
+// parsePlanSteps validates the steps argument of update_plan
+func parsePlanSteps(raw interface{}) ([]events.PlanStep, error) {
+	items, ok := raw.([]interface{})
+	if !ok || len(items) == 0 {
+		return nil, fmt.Errorf("steps must be a non-empty array of {title, status}")
+	}
+	steps := make([]events.PlanStep, 0, len(items))
+	seen := make(map[string]bool, len(items))
+	for i, item := range items {
+		fields, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("step %d must be an object with title and status", i+1)
+		}
+		title, _ := fields["title"].(string)
+		title = strings.TrimSpace(title)
+		if title == "" {
+			return nil, fmt.Errorf("step %d has no title", i+1)
+		}
+		if seen[title] {
+			return nil, fmt.Errorf("step %d repeats the title %q; step titles must be unique", i+1, title)
+		}
+		seen[title] = true
+		status, _ := fields["status"].(string)
+		switch status {
+		case planStepPending, planStepInProgress, planStepCompleted:
+		case "":
+			status = planStepPending
+		default:
+			return nil, fmt.Errorf("step %d has status %q; use pending, in_progress or completed", i+1, status)
+		}
+		steps = append(steps, events.PlanStep{Title: title, Status: status})
+	}
+	return steps, nil
+}
+
+// samePlanTitles reports whether two plans have the same steps in the same order
+func samePlanTitles(a, b []events.PlanStep) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Title != b[i].Title {
+			return false
+		}
+	}
+	return true
+}
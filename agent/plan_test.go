@@ -0,0 +1,73 @@
+package mcpagent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/manishiitg/mcpagent/events"
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+)
+
+func TestUpdatePlanEmitsPlanEvents(t *testing.T) {
+	listener := &recordingAgentEventListener{}
+	a := &Agent{Logger: loggerv2.NewNoop(), listeners: []AgentEventListener{listener}, EnablePlanTracking: true}
+	ctx := context.WithValue(context.Background(), ToolExecutionTurnKey, 3)
+	steps := func(statuses ...string) map[string]interface{} {
+		titles := []string{"Fetch filings", "Compare peers", "Write report"}
+		var items []interface{}
+		for i, status := range statuses {
+			items = append(items, map[string]interface{}{"title": titles[i], "status": status})
+		}
+		return map[string]interface{}{"steps": items}
+	}
+
+	for _, args := range []map[string]interface{}{
+		steps("in_progress", "pending", "pending"),
+		steps("completed", "in_progress", "pending"),
+		steps("completed", "in_progress", "pending"), // no change, no events
+		steps("completed", "completed", "completed"),
+	} {
+		if _, err := a.HandleVirtualTool(ctx, "update_plan", args); err != nil {
+			t.Fatalf("update_plan: %v", err)
+		}
+	}
+	// A new step revises the plan without repeating the events of known steps
+	revised := steps("completed", "completed", "completed")
+	revised["steps"] = append(revised["steps"].([]interface{}), map[string]interface{}{"title": "Review", "status": "in_progress"})
+	if _, err := a.HandleVirtualTool(ctx, "update_plan", revised); err != nil {
+		t.Fatalf("update_plan: %v", err)
+	}
+
+	var got []string
+	for _, e := range listener.events {
+		switch ev := e.Data.(type) {
+		case *events.PlanCreatedEvent:
+			if ev.Turn != 3 {
+				t.Errorf("plan_created turn = %d", ev.Turn)
+			}
+			got = append(got, fmt.Sprintf("created r%d", ev.Revision))
+		case *events.PlanStepStartedEvent:
+			got = append(got, "started "+ev.Title)
+		case *events.PlanStepCompletedEvent:
+			got = append(got, fmt.Sprintf("completed %s %d/%d", ev.Title, ev.CompletedSteps, ev.TotalSteps))
+		}
+	}
+	want := "created r1, started Fetch filings, completed Fetch filings 1/3, started Compare peers, " +
+		"completed Compare peers 3/3, completed Write report 3/3, created r2, started Review"
+	if strings.Join(got, ", ") != want {
+		t.Errorf("plan events = %s\nwant %s", strings.Join(got, ", "), want)
+	}
+	if plan := a.GetPlan(); len(plan) != 4 || plan[3].Status != "in_progress" {
+		t.Errorf("GetPlan() = %+v", plan)
+	}
+
+	bad := map[string]interface{}{"steps": []interface{}{map[string]interface{}{"title": "x", "status": "done"}}}
+	if _, err := a.HandleVirtualTool(ctx, "update_plan", bad); err == nil || !strings.Contains(err.Error(), "in_progress") {
+		t.Errorf("invalid status error = %v", err)
+	}
+	if len(a.GetPlan()) != 4 {
+		t.Error("invalid update_plan call changed the plan")
+	}
+}
@@ -0,0 +1,472 @@
+// postmortem.go
+//
+// This file provides failure post-mortem bundles: when a conversation of an
+// agent created WithPostMortemBundles ends in an error, a zip archive is built
+// with everything needed to file an actionable bug report:
+//
+//	error.json        the error and the provider/tool errors that preceded it
+//	events.jsonl      the last MaxEvents agent events
+//	messages.json     the last MaxMessages conversation messages
+//	config.json       provider, model, mode, limits, enabled features, servers
+//	environment.json  Go version, OS, architecture, module versions
+//
+// Every text is passed through DefaultRedactionRules and the caller's rules,
+// and message parts are truncated, so bundles can be attached to issues. The
+// bundle is written to Dir (when set) and kept in memory for
+// LastPostMortemBundle, which the gRPC server exposes as GetPostMortemBundle.
+//
+// Exported:
+//   - WithPostMortemBundles / PostMortemConfig: Enable bundles
+//   - PostMortemBundle: A generated bundle
+//   - Agent.LastPostMortemBundle / Agent.BuildPostMortemBundle
+
+package mcpagent
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/manishiitg/mcpagent/events"
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+const (
+	// DefaultPostMortemMaxEvents is the number of recent events in a bundle
+	DefaultPostMortemMaxEvents = 200
+	// DefaultPostMortemMaxMessages is the number of recent messages in a bundle
+	DefaultPostMortemMaxMessages = 20
+
+	// postMortemMaxTextBytes caps each message part and event in a bundle
+	postMortemMaxTextBytes = 8 << 10
+)
+
+// PostMortemConfig configures failure post-mortem bundles
+type PostMortemConfig struct {
+	// Dir receives the bundles as postmortem-<session>-<time>.zip; "" keeps
+	// only the last bundle in memory (see Agent.LastPostMortemBundle)
+	Dir string
+	// MaxEvents is the number of recent events included; 0 = DefaultPostMortemMaxEvents
+	MaxEvents int
+	// MaxMessages is the number of recent messages included; 0 = DefaultPostMortemMaxMessages
+	MaxMessages int
+	// RedactionRules mask anything else sensitive (e.g. PII); applied in
+	// addition to DefaultRedactionRules
+	RedactionRules []RedactionRule
+}
+
+// PostMortemBundle is a generated diagnostic bundle
+type PostMortemBundle struct {
+	// Path is where the bundle was written; "" when PostMortemConfig.Dir is empty
+	Path string
+	// Data is the zip archive
+	Data []byte
+	// Error is the (redacted) error the conversation ended with
+	Error     string
+	CreatedAt time.Time
+}
+
+// WithPostMortemBundles builds a diagnostic bundle (see postmortem.go) every
+// time a conversation ends in an error.
+//
+// Default: disabled
+func WithPostMortemBundles(config PostMortemConfig) AgentOption {
+	return func(a *Agent) {
+		if config.MaxEvents <= 0 {
+			config.MaxEvents = DefaultPostMortemMaxEvents
+		}
+		if config.MaxMessages <= 0 {
+			config.MaxMessages = DefaultPostMortemMaxMessages
+		}
+		a.postMortem = &postMortemRecorder{
+			config: config,
+			rules:  append(append([]RedactionRule{}, DefaultRedactionRules...), config.RedactionRules...),
+		}
+		a.listeners = append(a.listeners, a.postMortem)
+	}
+}
+
+// postMortemRecorder keeps the recent events for bundles and the last bundle
+type postMortemRecorder struct {
+	config PostMortemConfig
+	rules  []RedactionRule
+
+	mu     sync.Mutex
+	events []json.RawMessage // ring of redacted events, oldest first
+	last   *PostMortemBundle
+}
+
+// Name implements AgentEventListener
+func (r *postMortemRecorder) Name() string {
+	return "postmortem"
+}
+
+// HandleEvent implements AgentEventListener
+func (r *postMortemRecorder) HandleEvent(_ context.Context, event *events.AgentEvent) error {
+	if event == nil {
+		return nil
+	}
+	data, err := json.Marshal(event)
+	if err != nil {
+		return nil
+	}
+	line := json.RawMessage(r.redactJSON(data))
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.events) >= r.config.MaxEvents {
+		r.events = append(r.events[:0], r.events[len(r.events)-r.config.MaxEvents+1:]...)
+	}
+	r.events = append(r.events, line)
+	return nil
+}
+
+// redact applies the redaction rules to s
+func (r *postMortemRecorder) redact(s string) string {
+	return applyRedactionRules(r.rules, s)
+}
+
+// redactJSON redacts and caps an encoded event, keeping it valid JSON
+func (r *postMortemRecorder) redactJSON(data []byte) []byte {
+	redacted := []byte(r.redact(string(data)))
+	if len(redacted) <= postMortemMaxTextBytes && json.Valid(redacted) {
+		return redacted
+	}
+	truncated, _ := json.Marshal(map[string]string{"truncated_event": truncatePostMortemText(string(redacted))})
+	return truncated
+}
+
+// truncatePostMortemText caps s at postMortemMaxTextBytes
+func truncatePostMortemText(s string) string {
+	if len(s) <= postMortemMaxTextBytes {
+		return s
+	}
+	return strings.ToValidUTF8(s[:postMortemMaxTextBytes], "") + fmt.Sprintf("... [truncated %d bytes]", len(s)-postMortemMaxTextBytes)
+}
+
+// LastPostMortemBundle returns the bundle of the last failed conversation
+func (a *Agent) LastPostMortemBundle() (PostMortemBundle, bool) {
+	if a.postMortem == nil {
+		return PostMortemBundle{}, false
+	}
+	a.postMortem.mu.Lock()
+	defer a.postMortem.mu.Unlock()
+	if a.postMortem.last == nil {
+		return PostMortemBundle{}, false
+	}
+	return *a.postMortem.last, true
+}
+
+// recordPostMortem builds, stores and writes the bundle of a failed conversation
+func (a *Agent) recordPostMortem(messages []llmtypes.MessageContent, convErr error) {
+	// A cancelled conversation did not fail
+	if a.postMortem == nil || convErr == nil || errors.Is(convErr, context.Canceled) {
+		return
+	}
+	logger := getLogger(a)
+	bundle, err := a.BuildPostMortemBundle(messages, convErr)
+	if err != nil {
+		logger.Warn("Failed to build post-mortem bundle", loggerv2.Error(err))
+		return
+	}
+	if dir := a.postMortem.config.Dir; dir != "" {
+		name := fmt.Sprintf("postmortem-%s-%s.zip", sanitizePostMortemName(a.SessionID), bundle.CreatedAt.UTC().Format("20060102T150405.000Z"))
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(dir, 0o755); err != nil { //nolint:gosec // 0755 permissions are intentional for user-accessible directories
+			logger.Warn("Failed to create post-mortem folder", loggerv2.String("dir", dir), loggerv2.Error(err))
+		} else if err := os.WriteFile(path, bundle.Data, 0o600); err != nil {
+			logger.Warn("Failed to write post-mortem bundle", loggerv2.String("path", path), loggerv2.Error(err))
+		} else {
+			bundle.Path = path
+			logger.Info("Post-mortem bundle written", loggerv2.String("path", path))
+		}
+	}
+	a.postMortem.mu.Lock()
+	a.postMortem.last = &bundle
+	a.postMortem.mu.Unlock()
+}
+
+var postMortemNameUnsafe = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// sanitizePostMortemName makes a session ID safe for a file name
+func sanitizePostMortemName(s string) string {
+	s = postMortemNameUnsafe.ReplaceAllString(s, "_")
+	if s == "" {
+		return "session"
+	}
+	if len(s) > 64 {
+		s = s[:64]
+	}
+	return s
+}
+
+// postMortemError is error.json
+type postMortemError struct {
+	Error        string                 `json:"error"`
+	SessionID    string                 `json:"session_id,omitempty"`
+	TraceID      string                 `json:"trace_id,omitempty"`
+	Time         time.Time              `json:"time"`
+	RecentErrors []postMortemErrorEvent `json:"recent_errors,omitempty"`
+}
+
+// postMortemErrorEvent is a provider or tool error that preceded the failure
+type postMortemErrorEvent struct {
+	Type      string    `json:"type"`
+	Timestamp time.Time `json:"timestamp"`
+	Turn      int       `json:"turn"`
+	Model     string    `json:"model,omitempty"`
+	Tool      string    `json:"tool,omitempty"`
+	Server    string    `json:"server,omitempty"`
+	Context   string    `json:"context,omitempty"`
+	Error     string    `json:"error"`
+}
+
+// postMortemMessage is one message in messages.json
+type postMortemMessage struct {
+	Role  string           `json:"role"`
+	Parts []postMortemPart `json:"parts"`
+}
+
+// postMortemPart is one content part of a message
+type postMortemPart struct {
+	Type       string `json:"type"`
+	Text       string `json:"text,omitempty"`
+	ToolName   string `json:"tool_name,omitempty"`
+	ToolCallID string `json:"tool_call_id,omitempty"`
+	Arguments  string `json:"arguments,omitempty"`
+	IsError    bool   `json:"is_error,omitempty"`
+	MediaType  string `json:"media_type,omitempty"`
+}
+
+// postMortemConfigSnapshot is config.json
+type postMortemConfigSnapshot struct {
+	Provider          string   `json:"provider"`
+	ModelID           string   `json:"model_id"`
+	FallbackModels    []string `json:"fallback_models,omitempty"`
+	AgentMode         string   `json:"agent_mode"`
+	Temperature       float64  `json:"temperature"`
+	MaxTurns          int      `json:"max_turns"`
+	ToolTimeout       string   `json:"tool_timeout,omitempty"`
+	Features          []string `json:"features"`
+	Servers           []string `json:"servers"`
+	CustomTools       []string `json:"custom_tools,omitempty"`
+	ToolCount         int      `json:"tool_count"`
+	DisabledTools     []string `json:"disabled_tools,omitempty"`
+	SystemPromptBytes int      `json:"system_prompt_bytes"`
+}
+
+// postMortemEnvironment is environment.json
+type postMortemEnvironment struct {
+	GoVersion string            `json:"go_version"`
+	OS        string            `json:"os"`
+	Arch      string            `json:"arch"`
+	NumCPU    int               `json:"num_cpu"`
+	Module    string            `json:"module,omitempty"`
+	Version   string            `json:"version,omitempty"`
+	Deps      map[string]string `json:"dependencies,omitempty"`
+}
+
+// BuildPostMortemBundle builds a diagnostic bundle for convErr from the
+// recent events and the last messages of the conversation. Requires
+// WithPostMortemBundles; the bundle is not stored or written.
+func (a *Agent) BuildPostMortemBundle(messages []llmtypes.MessageContent, convErr error) (PostMortemBundle, error) {
+	r := a.postMortem
+	if r == nil {
+		return PostMortemBundle{}, errors.New("post-mortem bundles are not enabled; use WithPostMortemBundles")
+	}
+	now := time.Now()
+	errText := ""
+	if convErr != nil {
+		errText = r.redact(convErr.Error())
+	}
+
+	r.mu.Lock()
+	recent := append([]json.RawMessage(nil), r.events...)
+	r.mu.Unlock()
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	files := []struct {
+		name    string
+		content interface{}
+	}{
+		{"error.json", postMortemError{
+			Error:        errText,
+			SessionID:    a.SessionID,
+			TraceID:      string(a.TraceID),
+			Time:         now.UTC(),
+			RecentErrors: postMortemErrors(recent),
+		}},
+		{"messages.json", r.messages(messages)},
+		{"config.json", a.postMortemConfig()},
+		{"environment.json", postMortemEnv()},
+	}
+	for _, f := range files {
+		data, err := json.MarshalIndent(f.content, "", "  ")
+		if err != nil {
+			return PostMortemBundle{}, fmt.Errorf("failed to encode %s: %w", f.name, err)
+		}
+		if err := writePostMortemFile(zw, f.name, data, now); err != nil {
+			return PostMortemBundle{}, err
+		}
+	}
+	var eventLog bytes.Buffer
+	for _, line := range recent {
+		eventLog.Write(line)
+		eventLog.WriteByte('\n')
+	}
+	if err := writePostMortemFile(zw, "events.jsonl", eventLog.Bytes(), now); err != nil {
+		return PostMortemBundle{}, err
+	}
+	if err := zw.Close(); err != nil {
+		return PostMortemBundle{}, fmt.Errorf("failed to finish post-mortem bundle: %w", err)
+	}
+	return PostMortemBundle{Data: buf.Bytes(), Error: errText, CreatedAt: now}, nil
+}
+
+func writePostMortemFile(zw *zip.Writer, name string, data []byte, modified time.Time) error {
+	w, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: modified})
+	if err != nil {
+		return fmt.Errorf("failed to add %s to post-mortem bundle: %w", name, err)
+	}
+	if _, err := w.Write(data); err != nil {
+		return fmt.Errorf("failed to add %s to post-mortem bundle: %w", name, err)
+	}
+	return nil
+}
+
+// postMortemErrors extracts the provider, tool and conversation errors from
+// the recorded events
+func postMortemErrors(recent []json.RawMessage) []postMortemErrorEvent {
+	var out []postMortemErrorEvent
+	for _, line := range recent {
+		var event struct {
+			Type      events.EventType `json:"type"`
+			Timestamp time.Time        `json:"timestamp"`
+			Data      struct {
+				Turn       int    `json:"turn"`
+				ModelID    string `json:"model_id"`
+				ToolName   string `json:"tool_name"`
+				ServerName string `json:"server_name"`
+				Context    string `json:"context"`
+				Error      string `json:"error"`
+			} `json:"data"`
+		}
+		if err := json.Unmarshal(line, &event); err != nil {
+			continue
+		}
+		switch event.Type {
+		case events.LLMGenerationError, events.ToolCallError, events.ConversationError:
+			out = append(out, postMortemErrorEvent{
+				Type:      string(event.Type),
+				Timestamp: event.Timestamp,
+				Turn:      event.Data.Turn,
+				Model:     event.Data.ModelID,
+				Tool:      event.Data.ToolName,
+				Server:    event.Data.ServerName,
+				Context:   event.Data.Context,
+				Error:     event.Data.Error,
+			})
+		}
+	}
+	return out
+}
+
+// messages converts the last MaxMessages messages, redacted and truncated.
+// Image data is never included.
+func (r *postMortemRecorder) messages(messages []llmtypes.MessageContent) []postMortemMessage {
+	if len(messages) > r.config.MaxMessages {
+		messages = messages[len(messages)-r.config.MaxMessages:]
+	}
+	text := func(s string) string { return truncatePostMortemText(r.redact(s)) }
+	out := make([]postMortemMessage, 0, len(messages))
+	for _, msg := range messages {
+		m := postMortemMessage{Role: string(msg.Role), Parts: []postMortemPart{}}
+		for _, part := range msg.Parts {
+			switch p := part.(type) {
+			case llmtypes.TextContent:
+				m.Parts = append(m.Parts, postMortemPart{Type: "text", Text: text(p.Text)})
+			case llmtypes.ToolCall:
+				call := postMortemPart{Type: "tool_call", ToolCallID: p.ID}
+				if p.FunctionCall != nil {
+					call.ToolName = p.FunctionCall.Name
+					call.Arguments = text(p.FunctionCall.Arguments)
+				}
+				m.Parts = append(m.Parts, call)
+			case llmtypes.ToolCallResponse:
+				m.Parts = append(m.Parts, postMortemPart{Type: "tool_result", ToolName: p.Name, ToolCallID: p.ToolCallID, Text: text(p.Content), IsError: p.IsError})
+			case llmtypes.ImageContent:
+				m.Parts = append(m.Parts, postMortemPart{Type: "image", MediaType: p.MediaType})
+			default:
+				m.Parts = append(m.Parts, postMortemPart{Type: fmt.Sprintf("%T", part)})
+			}
+		}
+		out = append(out, m)
+	}
+	return out
+}
+
+// postMortemConfig snapshots the agent configuration without secrets or prompts
+func (a *Agent) postMortemConfig() postMortemConfigSnapshot {
+	snapshot := postMortemConfigSnapshot{
+		Provider:          string(a.provider),
+		ModelID:           a.ModelID,
+		AgentMode:         string(a.AgentMode),
+		Temperature:       a.Temperature,
+		MaxTurns:          a.MaxTurns,
+		Features:          a.telemetryFeatures(),
+		Servers:           getClientNames(a.Clients),
+		ToolCount:         len(a.Tools),
+		SystemPromptBytes: len(a.systemPrompt),
+	}
+	if a.ToolTimeout > 0 {
+		snapshot.ToolTimeout = a.ToolTimeout.String()
+	}
+	for _, model := range a.LLMConfig.Fallbacks {
+		snapshot.FallbackModels = append(snapshot.FallbackModels, string(model.Provider)+"/"+model.ModelID)
+	}
+	for name := range a.customTools {
+		snapshot.CustomTools = append(snapshot.CustomTools, name)
+	}
+	snapshot.DisabledTools = a.DisabledToolsForConversation()
+	sort.Strings(snapshot.Servers)
+	sort.Strings(snapshot.CustomTools)
+	return snapshot
+}
+
+// postMortemEnv describes the process the agent runs in
+func postMortemEnv() postMortemEnvironment {
+	env := postMortemEnvironment{
+		GoVersion: runtime.Version(),
+		OS:        runtime.GOOS,
+		Arch:      runtime.GOARCH,
+		NumCPU:    runtime.NumCPU(),
+	}
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return env
+	}
+	env.Module = info.Main.Path
+	env.Version = info.Main.Version
+	for _, dep := range info.Deps {
+		if strings.HasPrefix(dep.Path, "github.com/manishiitg/") || strings.HasPrefix(dep.Path, "github.com/mark3labs/") {
+			if env.Deps == nil {
+				env.Deps = make(map[string]string)
+			}
+			env.Deps[dep.Path] = dep.Version
+		}
+	}
+	return env
+}
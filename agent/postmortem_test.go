@@ -0,0 +1,113 @@
+package mcpagent
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/manishiitg/mcpagent/events"
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+	"github.com/manishiitg/mcpagent/observability"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+type failingGenerateStage struct {
+	err error
+}
+
+func (s failingGenerateStage) Generate(_ context.Context, _ *Agent, _ []llmtypes.MessageContent, _ []llmtypes.CallOption, _ int) (*llmtypes.ContentResponse, observability.UsageMetrics, error) {
+	return nil, observability.UsageMetrics{}, s.err
+}
+
+func readPostMortemBundle(t *testing.T, data []byte) map[string]string {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("bundle is not a zip archive: %v", err)
+	}
+	files := make(map[string]string)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, _ := io.ReadAll(rc)
+		_ = rc.Close()
+		files[f.Name] = string(content)
+	}
+	return files
+}
+
+func TestPostMortemBundleOnConversationError(t *testing.T) {
+	dir := t.TempDir()
+	a := &Agent{Logger: loggerv2.NewNoop(), LLM: &providerKeyCarrierModel{}, ModelID: "test-model", MaxTurns: 3, SessionID: "session/1"}
+	WithPostMortemBundles(PostMortemConfig{
+		Dir:            dir,
+		MaxMessages:    1,
+		RedactionRules: []RedactionRule{{Pattern: regexp.MustCompile(`\d{3}-\d{2}-\d{4}`), Replacement: "[SSN]"}},
+	})(a)
+	WithAskPipeline(AskPipeline{Generate: failingGenerateStage{err: errors.New("provider returned 500 for key sk-abcdefghijklmnopqrstuvwxyz")}})(a)
+	emitWebhookTestEvent(t, a, events.NewToolCallErrorEvent(1, "lookup", "connection refused", "crm", 0))
+
+	_, _, err := AskWithHistory(a, context.Background(), []llmtypes.MessageContent{
+		llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "my SSN is 123-45-6789"),
+	})
+	if err == nil {
+		t.Fatal("expected the conversation to fail")
+	}
+
+	bundle, ok := a.LastPostMortemBundle()
+	if !ok {
+		t.Fatal("expected a post-mortem bundle")
+	}
+	if filepath.Dir(bundle.Path) != dir || !strings.HasPrefix(filepath.Base(bundle.Path), "postmortem-session_1-") {
+		t.Errorf("bundle path = %q", bundle.Path)
+	}
+	if written, err := os.ReadFile(bundle.Path); err != nil || !bytes.Equal(written, bundle.Data) {
+		t.Errorf("written bundle differs from the returned one: %v", err)
+	}
+
+	files := readPostMortemBundle(t, bundle.Data)
+	for _, name := range []string{"error.json", "events.jsonl", "messages.json", "config.json", "environment.json"} {
+		if _, ok := files[name]; !ok {
+			t.Errorf("bundle is missing %s", name)
+		}
+	}
+	for name, content := range files {
+		if strings.Contains(content, "sk-abcdefghijklmnopqrstuvwxyz") || strings.Contains(content, "123-45-6789") {
+			t.Errorf("%s leaks a secret: %s", name, content)
+		}
+	}
+	if !strings.Contains(files["error.json"], "provider returned 500") || !strings.Contains(files["error.json"], `"tool": "lookup"`) {
+		t.Errorf("error.json = %s", files["error.json"])
+	}
+	if !strings.Contains(files["messages.json"], "[SSN]") {
+		t.Errorf("messages.json = %s", files["messages.json"])
+	}
+	if !strings.Contains(files["config.json"], `"model_id": "test-model"`) {
+		t.Errorf("config.json = %s", files["config.json"])
+	}
+}
+
+func TestPostMortemSkipsCancelledConversations(t *testing.T) {
+	a := &Agent{Logger: loggerv2.NewNoop()}
+	WithPostMortemBundles(PostMortemConfig{})(a)
+	a.recordPostMortem(nil, context.Canceled)
+	if _, ok := a.LastPostMortemBundle(); ok {
+		t.Error("a cancelled conversation should not produce a bundle")
+	}
+
+	for i := 0; i < DefaultPostMortemMaxEvents+10; i++ {
+		emitWebhookTestEvent(t, a, events.NewToolCallErrorEvent(i, "lookup", "boom", "crm", 0))
+	}
+	if n := len(a.postMortem.events); n != DefaultPostMortemMaxEvents {
+		t.Errorf("recorded %d events, want %d", n, DefaultPostMortemMaxEvents)
+	}
+}
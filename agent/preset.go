@@ -0,0 +1,235 @@
+// preset.go
+//
+// This file provides agent presets: named, reusable agent configurations that
+// bundle a system prompt template, toolset, budget, summarization settings
+// and a structured-output schema, so every service creating, say, an
+// "ipo-research" agent gets the same configuration. Presets are registered
+// process-wide (RegisterPreset, or LoadPresets from a JSON file) and used by
+// NewAgentFromPreset and the gRPC CreateAgentFromPreset RPC.
+//
+// System prompt templates use {{NAME}} placeholders, filled from the preset's
+// Variables and overridden per agent with WithPresetVariables.
+//
+// Exported:
+//   - Preset / PresetBudget / PresetSummarization: Preset definition
+//   - RegisterPreset / LoadPresets / GetPreset / ListPresets: Registry
+//   - NewAgentFromPreset: Create an agent from a registered preset
+//   - Preset.AgentOptions: The options a preset applies
+//   - WithPresetVariables: Fill system prompt template placeholders
+
+package mcpagent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+// Preset is a named agent configuration. Zero-valued fields keep the agent defaults.
+type Preset struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+
+	// Provider and ModelID select the LLM when the preset is used over gRPC;
+	// NewAgentFromPreset uses the LLM it is given
+	Provider string `json:"provider,omitempty"`
+	ModelID  string `json:"model_id,omitempty"`
+
+	// SystemPromptTemplate replaces the default system prompt. {{NAME}}
+	// placeholders are filled from Variables and WithPresetVariables.
+	SystemPromptTemplate string            `json:"system_prompt_template,omitempty"`
+	Variables            map[string]string `json:"variables,omitempty"`
+
+	// Toolset
+	MCPConfigPath   string   `json:"mcp_config_path,omitempty"`
+	SelectedServers []string `json:"selected_servers,omitempty"`
+	SelectedTools   []string `json:"selected_tools,omitempty"` // "server:tool" or "server:*"
+
+	Budget        PresetBudget         `json:"budget,omitempty"`
+	Summarization *PresetSummarization `json:"summarization,omitempty"`
+
+	// OutputSchema is a JSON schema final answers must conform to. It is
+	// added to the system prompt and exposed as Agent.OutputSchema for
+	// AskStructured callers.
+	OutputSchema string `json:"output_schema,omitempty"`
+
+	// Options are applied after the fields above (Go only)
+	Options []AgentOption `json:"-"`
+}
+
+// PresetBudget bounds the work an agent may do
+type PresetBudget struct {
+	MaxTurns    int           `json:"max_turns,omitempty"`
+	ToolTimeout time.Duration `json:"tool_timeout,omitempty"` // nanoseconds in JSON
+	// MaxTokens enables adaptive max output tokens with these caps
+	MaxTokens *AdaptiveMaxTokensConfig `json:"max_tokens,omitempty"`
+	// MaxCostUSD aborts conversations once the agent has spent this much (see WithBudgetLimit)
+	MaxCostUSD float64 `json:"max_cost_usd,omitempty"`
+}
+
+// PresetSummarization enables context summarization with these settings
+type PresetSummarization struct {
+	TokenThresholdPercent float64 `json:"token_threshold_percent,omitempty"` // 0 = summarize on the default triggers only
+	KeepLastMessages      int     `json:"keep_last_messages,omitempty"`
+	CooldownTurns         int     `json:"cooldown_turns,omitempty"`
+}
+
+var (
+	presetsMu sync.RWMutex
+	presets   = map[string]Preset{}
+)
+
+// RegisterPreset adds or replaces a preset in the process-wide registry
+func RegisterPreset(preset Preset) error {
+	if strings.TrimSpace(preset.Name) == "" {
+		return fmt.Errorf("preset name is required")
+	}
+	presetsMu.Lock()
+	defer presetsMu.Unlock()
+	presets[preset.Name] = preset
+	return nil
+}
+
+// LoadPresets registers the presets in a JSON file holding an array of presets
+func LoadPresets(path string) error {
+	data, err := os.ReadFile(path) //nolint:gosec // path is supplied by the operator
+	if err != nil {
+		return fmt.Errorf("failed to read presets file: %w", err)
+	}
+	var loaded []Preset
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		return fmt.Errorf("failed to parse presets file %s: %w", path, err)
+	}
+	for _, preset := range loaded {
+		if err := RegisterPreset(preset); err != nil {
+			return fmt.Errorf("invalid preset in %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// GetPreset returns the registered preset with the given name
+func GetPreset(name string) (Preset, bool) {
+	presetsMu.RLock()
+	defer presetsMu.RUnlock()
+	preset, ok := presets[name]
+	return preset, ok
+}
+
+// ListPresets returns the names of all registered presets, sorted
+func ListPresets() []string {
+	presetsMu.RLock()
+	defer presetsMu.RUnlock()
+	names := make([]string, 0, len(presets))
+	for name := range presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewAgentFromPreset creates an agent configured by the registered preset
+// name. options are applied after the preset's, so they override it.
+//
+// Example:
+//
+//	agent, err := mcpagent.NewAgentFromPreset(ctx, llm, "ipo-research",
+//	    mcpagent.WithPresetVariables(map[string]string{"COMPANY": "Acme"}))
+func NewAgentFromPreset(ctx context.Context, llm llmtypes.Model, name string, options ...AgentOption) (*Agent, error) {
+	preset, ok := GetPreset(name)
+	if !ok {
+		return nil, fmt.Errorf("preset not found: %s", name)
+	}
+	return NewAgent(ctx, llm, preset.MCPConfigPath, append(preset.AgentOptions(), options...)...)
+}
+
+// AgentOptions returns the options that apply the preset to an agent
+func (p Preset) AgentOptions() []AgentOption {
+	preset := p
+	options := []AgentOption{func(a *Agent) {
+		a.PresetName = preset.Name
+		a.OutputSchema = preset.OutputSchema
+		a.presetTemplate = preset.SystemPromptTemplate
+		a.presetVariables = make(map[string]string, len(preset.Variables))
+		for k, v := range preset.Variables {
+			a.presetVariables[k] = v
+		}
+		a.applyPresetSystemPrompt()
+	}}
+
+	if len(p.SelectedServers) > 0 {
+		options = append(options, WithSelectedServers(p.SelectedServers))
+	}
+	if len(p.SelectedTools) > 0 {
+		options = append(options, WithSelectedTools(p.SelectedTools))
+	}
+	if p.Budget.MaxTurns > 0 {
+		options = append(options, WithMaxTurns(p.Budget.MaxTurns))
+	}
+	if p.Budget.ToolTimeout > 0 {
+		options = append(options, WithToolTimeout(p.Budget.ToolTimeout))
+	}
+	if p.Budget.MaxTokens != nil {
+		options = append(options, WithAdaptiveMaxTokens(*p.Budget.MaxTokens))
+	}
+	if p.Budget.MaxCostUSD > 0 {
+		options = append(options, WithBudgetLimit(p.Budget.MaxCostUSD))
+	}
+	if s := p.Summarization; s != nil {
+		options = append(options, WithContextSummarization(true))
+		if s.TokenThresholdPercent > 0 {
+			options = append(options, WithSummarizeOnTokenThreshold(true, s.TokenThresholdPercent))
+		}
+		if s.KeepLastMessages > 0 {
+			options = append(options, WithSummaryKeepLastMessages(s.KeepLastMessages))
+		}
+		if s.CooldownTurns > 0 {
+			options = append(options, WithSummarizationCooldown(s.CooldownTurns))
+		}
+	}
+	return append(options, p.Options...)
+}
+
+// WithPresetVariables fills {{NAME}} placeholders of the preset's system
+// prompt template, overriding the preset's Variables. Has no effect on
+// agents not created from a preset.
+//
+// Default: the preset's Variables
+func WithPresetVariables(vars map[string]string) AgentOption {
+	return func(a *Agent) {
+		if a.presetVariables == nil {
+			a.presetVariables = make(map[string]string, len(vars))
+		}
+		for k, v := range vars {
+			a.presetVariables[k] = v
+		}
+		a.applyPresetSystemPrompt()
+	}
+}
+
+// applyPresetSystemPrompt renders the preset template as the system prompt
+func (a *Agent) applyPresetSystemPrompt() {
+	if a.presetTemplate == "" {
+		return
+	}
+	rendered := a.presetTemplate
+	for name, value := range a.presetVariables {
+		rendered = strings.ReplaceAll(rendered, "{{"+name+"}}", value)
+	}
+	WithSystemPrompt(rendered)(a)
+}
+
+// outputSchemaPromptSection tells the model the shape of its final answer
+func (a *Agent) outputSchemaPromptSection() string {
+	if strings.TrimSpace(a.OutputSchema) == "" {
+		return ""
+	}
+	return "\n## Final Answer Format\n\nWhen you give your final answer, respond with ONLY a valid JSON object that conforms to the following JSON schema, without any other text or markdown formatting.\n\nJSON Schema:\n" + a.OutputSchema + "\n"
+}
@@ -0,0 +1,75 @@
+package mcpagent
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+func TestPresetAgentOptions(t *testing.T) {
+	preset := Preset{
+		Name:                 "ipo-research",
+		SystemPromptTemplate: "You research IPOs for {{COMPANY}} in {{MARKET}}.",
+		Variables:            map[string]string{"COMPANY": "a client", "MARKET": "the US"},
+		SelectedServers:      []string{"sec"},
+		Budget:               PresetBudget{MaxTurns: 12, ToolTimeout: 90 * time.Second},
+		Summarization:        &PresetSummarization{TokenThresholdPercent: 0.7, KeepLastMessages: 6},
+		OutputSchema:         `{"type":"object","properties":{"verdict":{"type":"string"}}}`,
+	}
+
+	a := &Agent{Logger: loggerv2.NewNoop()}
+	for _, option := range append(preset.AgentOptions(), WithPresetVariables(map[string]string{"COMPANY": "Acme"})) {
+		option(a)
+	}
+
+	if a.PresetName != "ipo-research" || a.MaxTurns != 12 || a.ToolTimeout != 90*time.Second {
+		t.Errorf("preset not applied: name=%q max_turns=%d tool_timeout=%s", a.PresetName, a.MaxTurns, a.ToolTimeout)
+	}
+	if !a.EnableContextSummarization || !a.SummarizeOnTokenThreshold || a.TokenThresholdPercent != 0.7 || a.SummaryKeepLastMessages != 6 {
+		t.Error("summarization settings not applied")
+	}
+	if a.systemPrompt != "You research IPOs for Acme in the US." || !a.hasCustomSystemPrompt {
+		t.Errorf("system prompt = %q", a.systemPrompt)
+	}
+
+	messages := ensureSystemPrompt(context.Background(), a, []llmtypes.MessageContent{llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "go")})
+	system := messages[0].Parts[0].(llmtypes.TextContent).Text
+	if !strings.Contains(system, preset.OutputSchema) {
+		t.Errorf("expected the output schema in the system prompt:\n%s", system)
+	}
+}
+
+func TestLoadPresetsAndNewAgentFromPresetUnknown(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "presets.json")
+	body := `[{"name": "test-preset-load", "model_id": "gpt-4.1", "budget": {"max_turns": 5}, "selected_tools": ["sec:*"]}]`
+	if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := LoadPresets(path); err != nil {
+		t.Fatalf("LoadPresets: %v", err)
+	}
+	preset, ok := GetPreset("test-preset-load")
+	if !ok || preset.ModelID != "gpt-4.1" || preset.Budget.MaxTurns != 5 {
+		t.Fatalf("loaded preset = %+v, %v", preset, ok)
+	}
+	found := false
+	for _, name := range ListPresets() {
+		found = found || name == "test-preset-load"
+	}
+	if !found {
+		t.Error("expected the loaded preset to be listed")
+	}
+
+	if err := RegisterPreset(Preset{}); err == nil {
+		t.Error("expected an error for a preset without a name")
+	}
+	if _, err := NewAgentFromPreset(context.Background(), &providerKeyCarrierModel{}, "no-such-preset"); err == nil {
+		t.Error("expected an error for an unknown preset")
+	}
+}
@@ -0,0 +1,90 @@
+// priority.go
+//
+// This file defines conversation priorities for mixed interactive and batch
+// workloads. A priority is set per call with CallWithPriority and is honored
+// by turn pacing (see turn_pacing.go): high-priority calls skip the minimum
+// spacing between LLM calls, low-priority calls are spaced twice as far apart
+// so they leave provider rate limits to interactive traffic. The gRPC server
+// additionally admits queued conversations highest priority first (see
+// grpcserver/admission.go).
+//
+// Exported:
+//   - Priority, PriorityLow / PriorityNormal / PriorityHigh
+//   - ParsePriority: Parse a priority name
+//   - CallWithPriority: Set the priority of one Ask / AskWithHistory call
+
+package mcpagent
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Priority ranks a conversation against others competing for the same
+// resources (provider rate limits, server conversation slots)
+type Priority string
+
+const (
+	// PriorityLow is for batch work that may wait behind interactive users
+	PriorityLow Priority = "low"
+	// PriorityNormal is the default
+	PriorityNormal Priority = "normal"
+	// PriorityHigh is for latency-sensitive work such as chat
+	PriorityHigh Priority = "high"
+)
+
+// lowPriorityPacingFactor widens the turn pacing interval of low-priority calls
+const lowPriorityPacingFactor = 2
+
+// ParsePriority parses "low", "normal" or "high" (case-insensitive); an empty
+// name is PriorityNormal
+func ParsePriority(name string) (Priority, error) {
+	switch p := Priority(strings.ToLower(strings.TrimSpace(name))); p {
+	case "":
+		return PriorityNormal, nil
+	case PriorityLow, PriorityNormal, PriorityHigh:
+		return p, nil
+	default:
+		return "", fmt.Errorf("invalid priority %q (want low, normal or high)", name)
+	}
+}
+
+// Rank orders priorities: low < normal < high. Unknown values rank as normal.
+func (p Priority) Rank() int {
+	switch p {
+	case PriorityLow:
+		return 0
+	case PriorityHigh:
+		return 2
+	default:
+		return 1
+	}
+}
+
+// CallWithPriority sets the priority of this call. See Priority for what it
+// affects.
+func CallWithPriority(priority Priority) CallOption {
+	return func(o *callOptions) {
+		o.priority = priority
+	}
+}
+
+// newCallPriority returns the priority set by opts, PriorityNormal when unset
+func newCallPriority(opts []CallOption) Priority {
+	var o callOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.priority == "" {
+		return PriorityNormal
+	}
+	return o.priority
+}
+
+// currentPriority is the priority of the call
+func (c *callState) currentPriority() Priority {
+	if c.priority == "" {
+		return PriorityNormal
+	}
+	return c.priority
+}
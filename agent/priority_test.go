@@ -0,0 +1,45 @@
+package mcpagent
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestParsePriority(t *testing.T) {
+	for name, want := range map[string]Priority{"": PriorityNormal, "low": PriorityLow, " HIGH ": PriorityHigh, "normal": PriorityNormal} {
+		if got, err := ParsePriority(name); err != nil || got != want {
+			t.Errorf("ParsePriority(%q) = %q, %v; want %q", name, got, err, want)
+		}
+	}
+	if _, err := ParsePriority("urgent"); err == nil {
+		t.Error("expected an error for an unknown priority")
+	}
+	if !(PriorityLow.Rank() < PriorityNormal.Rank() && PriorityNormal.Rank() < PriorityHigh.Rank()) {
+		t.Error("priorities should rank low < normal < high")
+	}
+}
+
+func TestCallPriorityAdjustsPacing(t *testing.T) {
+	a := &Agent{}
+	WithTurnPacing(40 * time.Millisecond)(a)
+	ctx := context.Background()
+
+	callCtx, call := a.beginCall(ctx, []CallOption{CallWithPriority(PriorityHigh)})
+	if currentCall(callCtx).currentPriority() != PriorityHigh {
+		t.Fatalf("priority = %q, want high", currentCall(callCtx).currentPriority())
+	}
+	for i := 0; i < 3; i++ {
+		if delay, _ := a.pacer.wait(ctx, "openai/gpt-4.1", currentCall(callCtx).currentPriority()); delay != 0 {
+			t.Errorf("high-priority call %d waited %v", i, delay)
+		}
+	}
+	a.endCall(call)
+	if currentCall(ctx).currentPriority() != PriorityNormal {
+		t.Errorf("priority outside the call = %q, want normal", currentCall(ctx).currentPriority())
+	}
+
+	if delay, _ := a.pacer.wait(ctx, "openai/gpt-4.1", PriorityLow); delay < 60*time.Millisecond {
+		t.Errorf("low-priority call should wait twice the interval, waited %v", delay)
+	}
+}
@@ -0,0 +1,218 @@
+// profile.go
+//
+// This file loads agent profiles: YAML files that define an agent's model,
+// temperature, tool filters, system prompt, summarization and context
+// offloading settings, so teams can version agent behavior outside of Go code.
+// A profile is a preset (see preset.go) in a file: NewAgentFromProfile builds
+// an agent from one directly, and AgentProfile.Preset converts it for
+// RegisterPreset and the gRPC CreateAgentFromPreset RPC.
+//
+// Relative mcp_config and system_prompt_file paths are resolved against the
+// directory of the profile file. Unknown keys are rejected so typos fail
+// loudly instead of silently keeping a default.
+//
+// Example research.yaml:
+//
+//	name: research
+//	model:
+//	  provider: anthropic
+//	  model_id: claude-sonnet-4-5
+//	  temperature: 0.2
+//	system_prompt_file: prompts/research.md
+//	mcp_config: mcp_servers.json
+//	tools:
+//	  servers: [web, arxiv]
+//	  tools: ["web:search", "arxiv:*"]
+//	budget:
+//	  max_turns: 30
+//	  tool_timeout: 2m
+//	summarization:
+//	  token_threshold_percent: 0.7
+//	  keep_last_messages: 8
+//	offloading:
+//	  enabled: true
+//	  threshold: 20000
+//
+// Exported:
+//   - AgentProfile and its sections: Profile definition
+//   - LoadAgentProfile: Parse a profile file
+//   - NewAgentFromProfile: Create an agent from a profile file
+//   - AgentProfile.Preset / AgentProfile.AgentOptions: Apply a profile
+
+package mcpagent
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+	"gopkg.in/yaml.v3"
+)
+
+// AgentProfile is an agent configuration loaded from YAML. Omitted fields keep
+// the agent defaults.
+type AgentProfile struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description,omitempty"`
+
+	Model ProfileModel `yaml:"model,omitempty"`
+
+	// SystemPrompt (or the contents of SystemPromptFile) replaces the default
+	// system prompt; {{NAME}} placeholders are filled from Variables
+	SystemPrompt     string            `yaml:"system_prompt,omitempty"`
+	SystemPromptFile string            `yaml:"system_prompt_file,omitempty"`
+	Variables        map[string]string `yaml:"variables,omitempty"`
+
+	// MCPConfig is the MCP servers config file
+	MCPConfig string       `yaml:"mcp_config,omitempty"`
+	Tools     ProfileTools `yaml:"tools,omitempty"`
+
+	Budget        ProfileBudget         `yaml:"budget,omitempty"`
+	Summarization *ProfileSummarization `yaml:"summarization,omitempty"`
+	Offloading    *ProfileOffloading    `yaml:"offloading,omitempty"`
+
+	OutputSchema string `yaml:"output_schema,omitempty"`
+}
+
+// ProfileModel selects the model. NewAgentFromProfile uses the LLM it is
+// given; Provider and ModelID select it when the profile is used over gRPC.
+type ProfileModel struct {
+	Provider    string   `yaml:"provider,omitempty"`
+	ModelID     string   `yaml:"model_id,omitempty"`
+	Temperature *float64 `yaml:"temperature,omitempty"`
+}
+
+// ProfileTools filters the tools the agent sees
+type ProfileTools struct {
+	Servers []string `yaml:"servers,omitempty"`
+	Tools   []string `yaml:"tools,omitempty"` // "server:tool" or "server:*"
+}
+
+// ProfileBudget bounds the work an agent may do
+type ProfileBudget struct {
+	MaxTurns    int           `yaml:"max_turns,omitempty"`
+	ToolTimeout time.Duration `yaml:"tool_timeout,omitempty"` // e.g. "90s"
+	MaxCostUSD  float64       `yaml:"max_cost_usd,omitempty"`
+}
+
+// ProfileSummarization enables context summarization with these settings
+type ProfileSummarization struct {
+	TokenThresholdPercent float64 `yaml:"token_threshold_percent,omitempty"`
+	KeepLastMessages      int     `yaml:"keep_last_messages,omitempty"`
+	CooldownTurns         int     `yaml:"cooldown_turns,omitempty"`
+}
+
+// ProfileOffloading configures context offloading of large tool outputs
+type ProfileOffloading struct {
+	Enabled   *bool         `yaml:"enabled,omitempty"`
+	Threshold int           `yaml:"threshold,omitempty"` // Tokens
+	Retention time.Duration `yaml:"retention,omitempty"` // e.g. "24h"
+}
+
+// LoadAgentProfile parses the profile file at path and reads its system
+// prompt file, if any
+func LoadAgentProfile(path string) (*AgentProfile, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // path is supplied by the operator
+	if err != nil {
+		return nil, fmt.Errorf("failed to read profile file: %w", err)
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	var profile AgentProfile
+	if err := decoder.Decode(&profile); err != nil {
+		return nil, fmt.Errorf("failed to parse profile file %s: %w", path, err)
+	}
+	if strings.TrimSpace(profile.Name) == "" {
+		profile.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+
+	dir := filepath.Dir(path)
+	if profile.MCPConfig != "" && !filepath.IsAbs(profile.MCPConfig) {
+		profile.MCPConfig = filepath.Join(dir, profile.MCPConfig)
+	}
+	if profile.SystemPromptFile != "" {
+		if profile.SystemPrompt != "" {
+			return nil, fmt.Errorf("profile %s sets both system_prompt and system_prompt_file", path)
+		}
+		promptPath := profile.SystemPromptFile
+		if !filepath.IsAbs(promptPath) {
+			promptPath = filepath.Join(dir, promptPath)
+		}
+		prompt, err := os.ReadFile(promptPath) //nolint:gosec // path is supplied by the operator
+		if err != nil {
+			return nil, fmt.Errorf("failed to read system prompt file of profile %s: %w", path, err)
+		}
+		profile.SystemPrompt = string(prompt)
+	}
+	return &profile, nil
+}
+
+// NewAgentFromProfile creates an agent configured by the profile file at
+// path. options are applied after the profile's, so they override it.
+//
+// Example:
+//
+//	agent, err := mcpagent.NewAgentFromProfile(ctx, llm, "profiles/research.yaml")
+func NewAgentFromProfile(ctx context.Context, llm llmtypes.Model, path string, options ...AgentOption) (*Agent, error) {
+	profile, err := LoadAgentProfile(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewAgent(ctx, llm, profile.MCPConfig, append(profile.AgentOptions(), options...)...)
+}
+
+// Preset converts the profile to a preset, e.g. to register it for
+// NewAgentFromPreset or gRPC
+func (p *AgentProfile) Preset() Preset {
+	preset := Preset{
+		Name:                 p.Name,
+		Description:          p.Description,
+		Provider:             p.Model.Provider,
+		ModelID:              p.Model.ModelID,
+		SystemPromptTemplate: p.SystemPrompt,
+		Variables:            p.Variables,
+		MCPConfigPath:        p.MCPConfig,
+		SelectedServers:      p.Tools.Servers,
+		SelectedTools:        p.Tools.Tools,
+		Budget: PresetBudget{
+			MaxTurns:    p.Budget.MaxTurns,
+			ToolTimeout: p.Budget.ToolTimeout,
+			MaxCostUSD:  p.Budget.MaxCostUSD,
+		},
+		OutputSchema: p.OutputSchema,
+	}
+	if s := p.Summarization; s != nil {
+		preset.Summarization = &PresetSummarization{
+			TokenThresholdPercent: s.TokenThresholdPercent,
+			KeepLastMessages:      s.KeepLastMessages,
+			CooldownTurns:         s.CooldownTurns,
+		}
+	}
+
+	// Settings presets do not model are applied as options
+	if p.Model.Temperature != nil {
+		preset.Options = append(preset.Options, WithTemperature(*p.Model.Temperature))
+	}
+	if o := p.Offloading; o != nil {
+		if o.Enabled != nil {
+			preset.Options = append(preset.Options, WithContextOffloading(*o.Enabled))
+		}
+		if o.Threshold > 0 {
+			preset.Options = append(preset.Options, WithLargeOutputThreshold(o.Threshold))
+		}
+		if o.Retention > 0 {
+			preset.Options = append(preset.Options, WithToolOutputRetentionPeriod(o.Retention))
+		}
+	}
+	return preset
+}
+
+// AgentOptions returns the options that apply the profile to an agent
+func (p *AgentProfile) AgentOptions() []AgentOption {
+	return p.Preset().AgentOptions()
+}
@@ -0,0 +1,82 @@
+package mcpagent
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+)
+
+func writeProfileTestFile(t *testing.T, path, body string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadAgentProfileAppliesSettings(t *testing.T) {
+	dir := t.TempDir()
+	writeProfileTestFile(t, filepath.Join(dir, "prompts", "research.md"), "You research {{TOPIC}}.")
+	path := filepath.Join(dir, "research.yaml")
+	writeProfileTestFile(t, path, `
+model:
+  provider: anthropic
+  model_id: claude-sonnet-4-5
+  temperature: 0
+system_prompt_file: prompts/research.md
+variables:
+  TOPIC: batteries
+mcp_config: mcp_servers.json
+tools:
+  servers: [web]
+  tools: ["web:search"]
+budget:
+  max_turns: 30
+  tool_timeout: 2m
+summarization:
+  token_threshold_percent: 0.7
+  keep_last_messages: 8
+offloading:
+  enabled: false
+  threshold: 20000
+`)
+
+	profile, err := LoadAgentProfile(path)
+	if err != nil {
+		t.Fatalf("LoadAgentProfile: %v", err)
+	}
+	if profile.Name != "research" || profile.MCPConfig != filepath.Join(dir, "mcp_servers.json") || profile.Preset().ModelID != "claude-sonnet-4-5" {
+		t.Fatalf("profile = %+v", profile)
+	}
+
+	a := &Agent{Logger: loggerv2.NewNoop(), Temperature: 0.7, EnableContextOffloading: true}
+	for _, option := range profile.AgentOptions() {
+		option(a)
+	}
+	if a.PresetName != "research" || a.systemPrompt != "You research batteries." {
+		t.Errorf("preset name %q, system prompt %q", a.PresetName, a.systemPrompt)
+	}
+	if a.Temperature != 0 || a.EnableContextOffloading || a.LargeOutputThreshold != 20000 {
+		t.Errorf("temperature %v, offloading %v, threshold %d", a.Temperature, a.EnableContextOffloading, a.LargeOutputThreshold)
+	}
+	if a.MaxTurns != 30 || a.ToolTimeout != 2*time.Minute || !a.EnableContextSummarization || a.SummaryKeepLastMessages != 8 {
+		t.Errorf("max turns %d, tool timeout %s, summarization %v", a.MaxTurns, a.ToolTimeout, a.EnableContextSummarization)
+	}
+	if len(a.selectedServers) != 1 || a.selectedServers[0] != "web" {
+		t.Errorf("selected servers = %v", a.selectedServers)
+	}
+}
+
+func TestLoadAgentProfileRejectsUnknownKeys(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "typo.yaml")
+	writeProfileTestFile(t, path, "name: typo\nbudget:\n  max_turn: 5\n")
+	if _, err := LoadAgentProfile(path); err == nil || !strings.Contains(err.Error(), "max_turn") {
+		t.Errorf("expected an unknown key error, got %v", err)
+	}
+}
@@ -2,6 +2,7 @@ package prompt
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
@@ -71,7 +72,7 @@ If you need retries, backoff, or structured logging, write a small helper in the
 // toolCategories is optional list of tool categories for tool search mode
 func BuildSystemPromptWithoutTools(prompts map[string][]mcp.Prompt, resources map[string][]mcp.Resource, mode interface{}, discoverResource bool, discoverPrompt bool, useCodeExecutionMode bool, toolStructureJSON string, preDiscoveredToolSpecs string, useToolSearchMode bool, toolCategories []string, logger loggerv2.Logger, enableParallelToolExecution bool) string {
 	// Build prompts section with previews (only if discoverPrompt is true and NOT in code execution mode)
-	// In code execution mode, prompts/resources are not accessible via get_prompt/get_resource
+	// In code execution mode, prompts/resources are not accessible via get_prompt/read_resource
 	var promptsSection string
 	if discoverPrompt && !useCodeExecutionMode {
 		promptsSection = buildPromptsSectionWithPreviews(prompts, logger)
@@ -80,7 +81,7 @@ func BuildSystemPromptWithoutTools(prompts map[string][]mcp.Prompt, resources ma
 	}
 
 	// Build resources section (only if discoverResource is true and NOT in code execution mode)
-	// In code execution mode, resources are not accessible via get_resource
+	// In code execution mode, resources are not accessible via read_resource
 	var resourcesSection string
 	if discoverResource && !useCodeExecutionMode {
 		resourcesSection = buildResourcesSection(resources)
@@ -208,7 +209,7 @@ func BuildSystemPromptWithoutTools(prompts map[string][]mcp.Prompt, resources ma
 		largeOutputHandlingSection = `
 CONTEXT OFFLOADING:
 Large tool outputs (>1000 chars) are automatically offloaded to filesystem (offload context pattern).
-Use 'search_large_output' with operation='read', operation='search', or operation='query' to access them.`
+Use 'search_large_output' with operation='read', operation='search', operation='query', or operation='sql' to access them.`
 	}
 
 	// Always use Simple system prompt template
@@ -354,6 +355,66 @@ func buildResourcesSection(resources map[string][]mcp.Resource) string {
 	return strings.ReplaceAll(ResourcesSectionTemplate, ResourcesListPlaceholder, resourcesText)
 }
 
+// MaxServerInstructionsLength caps the instructions included per server so a
+// verbose server cannot crowd out the rest of the system prompt
+const MaxServerInstructionsLength = 4000
+
+// BuildServerInstructionsSection builds the section with the usage instructions
+// MCP servers sent on initialize. Servers are listed in name order; servers
+// not in servers are skipped unless servers is nil.
+func BuildServerInstructionsSection(instructions map[string]string, servers []string) string {
+	if servers == nil {
+		for serverName := range instructions {
+			servers = append(servers, serverName)
+		}
+	}
+	servers = append([]string(nil), servers...)
+	sort.Strings(servers)
+
+	var blocks []string
+	for _, serverName := range servers {
+		text := strings.TrimSpace(instructions[serverName])
+		if text == "" {
+			continue
+		}
+		if len(text) > MaxServerInstructionsLength {
+			text = strings.TrimSpace(text[:MaxServerInstructionsLength]) + "\n[instructions truncated]"
+		}
+		blocks = append(blocks, fmt.Sprintf("### %s\n%s", serverName, text))
+	}
+	if len(blocks) == 0 {
+		return ""
+	}
+	return strings.ReplaceAll(ServerInstructionsSectionTemplate, ServerInstructionsPlaceholder, strings.Join(blocks, "\n\n"))
+}
+
+// BuildGlossarySection builds the terminology contract for a glossary mapping
+// terms (or their variants and translations) to the required rendering. Terms
+// are listed in order; a term that maps to itself must be kept verbatim.
+func BuildGlossarySection(glossary map[string]string) string {
+	terms := make([]string, 0, len(glossary))
+	for term := range glossary {
+		if strings.TrimSpace(term) != "" {
+			terms = append(terms, term)
+		}
+	}
+	if len(terms) == 0 {
+		return ""
+	}
+	sort.Strings(terms)
+
+	lines := make([]string, 0, len(terms))
+	for _, term := range terms {
+		rendering := glossary[term]
+		if rendering == "" || rendering == term {
+			lines = append(lines, fmt.Sprintf("- %s (keep verbatim)", term))
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("- %s → %s", term, rendering))
+	}
+	return strings.ReplaceAll(GlossarySectionTemplate, GlossaryPlaceholder, strings.Join(lines, "\n"))
+}
+
 // buildVirtualToolsSection builds the virtual tools section
 // Only mentions tools that are actually available (prompts/resources must exist)
 func buildVirtualToolsSection(useCodeExecutionMode bool, useToolSearchMode bool, prompts map[string][]mcp.Prompt, resources map[string][]mcp.Resource) string {
@@ -409,9 +470,15 @@ When you finish a phase of work, use remove_tool to unload tools you no longer n
 	var toolsList []string
 	if hasPrompts {
 		toolsList = append(toolsList, "- **get_prompt**: Fetch full prompt content (server + name) from an mcp server")
+		for _, serverPrompts := range prompts {
+			if hasPromptArguments(serverPrompts) {
+				toolsList = append(toolsList, "- **use_prompt**: Render a prompt template with arguments (server + name + arguments) from an mcp server")
+				break
+			}
+		}
 	}
 	if hasResources {
-		toolsList = append(toolsList, "- **get_resource**: Fetch resource content (server + uri) from an mcp server")
+		toolsList = append(toolsList, "- **read_resource**: Fetch resource content (uri, or template + arguments) from an mcp server")
 	}
 
 	// If no tools are available, return empty string (section will be empty)
@@ -427,3 +494,13 @@ When you finish a phase of work, use remove_tool to unload tools you no longer n
 
 These are internal tools - just specify server and identifier.`
 }
+
+// hasPromptArguments reports whether any of the prompts declares arguments
+func hasPromptArguments(prompts []mcp.Prompt) bool {
+	for _, prompt := range prompts {
+		if len(prompt.Arguments) > 0 {
+			return true
+		}
+	}
+	return false
+}
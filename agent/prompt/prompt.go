@@ -41,15 +41,35 @@ const ResourcesSectionTemplate = `
 
 {{RESOURCES_LIST}}
 
-Use 'get_resource' tool to access content when needed.
+Use 'read_resource' tool to access content when needed.
 </resources_section>`
 
+// ServerInstructionsSectionTemplate is the template for the usage instructions MCP servers send on initialize
+const ServerInstructionsSectionTemplate = `
+<server_instructions>
+## 📘 MCP SERVER INSTRUCTIONS
+
+The servers below describe how their tools should be used. Follow these instructions when calling their tools.
+
+{{SERVER_INSTRUCTIONS_LIST}}
+</server_instructions>`
+
+// GlossarySectionTemplate is the template for the terminology contract of WithGlossary
+const GlossarySectionTemplate = `
+<glossary>
+## 📖 TERMINOLOGY
+
+Whatever language you answer in, render the terms below exactly as shown on the right. Never translate, inflect, abbreviate or re-case them.
+
+{{GLOSSARY_LIST}}
+</glossary>`
+
 // VirtualToolsSectionTemplate is the template for virtual tool instructions
 const VirtualToolsSectionTemplate = `
 🔧 VIRTUAL TOOLS:
 
 - **get_prompt**: Fetch full prompt content (server + name) from an mcp server
-- **get_resource**: Fetch resource content (server + uri) from an mcp server
+- **read_resource**: Fetch resource content (uri, or template + arguments) from an mcp server
 
 These are internal tools - just specify server and identifier.`
 
@@ -67,6 +87,8 @@ const (
 	CorePrinciplesPlaceholder      = "{{CORE_PRINCIPLES}}"
 	ToolUsagePlaceholder           = "{{TOOL_USAGE}}"
 	LargeOutputHandlingPlaceholder = "{{LARGE_OUTPUT_HANDLING}}"
+	ServerInstructionsPlaceholder  = "{{SERVER_INSTRUCTIONS_LIST}}"
+	GlossaryPlaceholder            = "{{GLOSSARY_LIST}}"
 )
 
 // RemoveAIStaffEngineerText removes the "AI Staff Engineer" header and description from a system prompt
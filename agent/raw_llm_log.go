@@ -0,0 +1,387 @@
+// raw_llm_log.go
+//
+// This file provides raw provider logging: the exact HTTP requests and
+// responses exchanged with LLM providers (URL, headers, JSON bodies, status),
+// one JSON line per call, for debugging provider-specific serialization bugs
+// such as malformed tool calls. Provider adapters issue requests through the
+// default HTTP transport; an agent created WithRawLLMLogging installs a
+// wrapper around it once per process, and only requests made on behalf of
+// that agent's LLM calls (tagged through the request context) are recorded.
+// Adapters configured with their own transport, and CLI providers, are not
+// captured.
+//
+// Privacy: authentication headers are never written, default redaction rules
+// mask common credential formats, caller rules mask anything else (e.g. PII),
+// bodies are truncated at MaxBodyBytes, and files rotate at MaxFileBytes with
+// only the newest MaxFiles kept.
+//
+// Exported:
+//   - WithRawLLMLogging: Enable raw logging to a folder
+//   - WithRawLLMLogLimits: Override the size caps
+//   - RedactionRule / DefaultRedactionRules: Masking of logged text
+
+package mcpagent
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultRawLLMLogMaxBodyBytes caps each logged request or response body
+	DefaultRawLLMLogMaxBodyBytes = 1 << 20
+	// DefaultRawLLMLogMaxFileBytes is the size at which a log file is rotated
+	DefaultRawLLMLogMaxFileBytes = 50 << 20
+	// DefaultRawLLMLogMaxFiles is how many rotated files are kept per agent
+	DefaultRawLLMLogMaxFiles = 5
+
+	rawLLMLogRedacted = "[REDACTED]"
+)
+
+// RedactionRule replaces every match of Pattern in logged URLs, headers and
+// bodies with Replacement ("" = "[REDACTED]")
+type RedactionRule struct {
+	Pattern     *regexp.Regexp
+	Replacement string
+}
+
+// DefaultRedactionRules mask common credential formats; they are always
+// applied in addition to the rules passed to WithRawLLMLogging
+var DefaultRedactionRules = []RedactionRule{
+	{Pattern: regexp.MustCompile(`sk-[A-Za-z0-9_\-]{16,}`)}, // OpenAI / Anthropic style keys
+	{Pattern: regexp.MustCompile(`AKIA[0-9A-Z]{16}`)},       // AWS access key IDs
+	{Pattern: regexp.MustCompile(`AIza[0-9A-Za-z_\-]{35}`)}, // Google API keys
+	{Pattern: regexp.MustCompile(`(?i)bearer\s+[A-Za-z0-9._\-]+`), Replacement: "Bearer " + rawLLMLogRedacted},
+	{Pattern: regexp.MustCompile(`([?&](?:key|api_key|token)=)[^&"\s]+`), Replacement: "${1}" + rawLLMLogRedacted},
+}
+
+// rawLLMLogSensitiveHeaders are dropped from logged requests and responses
+var rawLLMLogSensitiveHeaders = map[string]bool{
+	"authorization":       true,
+	"proxy-authorization": true,
+	"x-api-key":           true,
+	"api-key":             true,
+	"x-goog-api-key":      true,
+	"cookie":              true,
+	"set-cookie":          true,
+}
+
+// WithRawLLMLogging records the exact provider HTTP requests and responses of
+// this agent's LLM calls as JSON lines in dir. Authentication headers are
+// dropped; DefaultRedactionRules and redactionRules are applied to everything
+// else. Intended for debugging; payloads can still contain user data.
+//
+// Default: disabled
+func WithRawLLMLogging(dir string, redactionRules []RedactionRule) AgentOption {
+	return func(a *Agent) {
+		if dir == "" {
+			return
+		}
+		if a.rawLLMLog == nil {
+			a.rawLLMLog = &rawLLMLogger{
+				maxBodyBytes: DefaultRawLLMLogMaxBodyBytes,
+				maxFileBytes: DefaultRawLLMLogMaxFileBytes,
+				maxFiles:     DefaultRawLLMLogMaxFiles,
+			}
+		}
+		a.rawLLMLog.dir = dir
+		a.rawLLMLog.rules = append(append([]RedactionRule{}, DefaultRedactionRules...), redactionRules...)
+	}
+}
+
+// WithRawLLMLogLimits overrides the size caps of raw LLM logging; values <= 0
+// keep the defaults. Only effective together with WithRawLLMLogging.
+//
+// Default: 1 MiB per body, 50 MiB per file, 5 files
+func WithRawLLMLogLimits(maxBodyBytes, maxFileBytes int64, maxFiles int) AgentOption {
+	return func(a *Agent) {
+		if a.rawLLMLog == nil {
+			a.rawLLMLog = &rawLLMLogger{
+				maxBodyBytes: DefaultRawLLMLogMaxBodyBytes,
+				maxFileBytes: DefaultRawLLMLogMaxFileBytes,
+				maxFiles:     DefaultRawLLMLogMaxFiles,
+			}
+		}
+		if maxBodyBytes > 0 {
+			a.rawLLMLog.maxBodyBytes = maxBodyBytes
+		}
+		if maxFileBytes > 0 {
+			a.rawLLMLog.maxFileBytes = maxFileBytes
+		}
+		if maxFiles > 0 {
+			a.rawLLMLog.maxFiles = maxFiles
+		}
+	}
+}
+
+// rawLLMLogEntry is one logged provider call
+type rawLLMLogEntry struct {
+	Timestamp       time.Time         `json:"timestamp"`
+	SessionID       string            `json:"session_id,omitempty"`
+	Provider        string            `json:"provider,omitempty"`
+	ModelID         string            `json:"model_id,omitempty"`
+	Method          string            `json:"method"`
+	URL             string            `json:"url"`
+	RequestHeaders  map[string]string `json:"request_headers,omitempty"`
+	RequestBody     json.RawMessage   `json:"request_body,omitempty"`
+	Status          int               `json:"status,omitempty"`
+	ResponseHeaders map[string]string `json:"response_headers,omitempty"`
+	ResponseBody    json.RawMessage   `json:"response_body,omitempty"`
+	DurationMs      int64             `json:"duration_ms"`
+	Error           string            `json:"error,omitempty"`
+	Truncated       bool              `json:"truncated,omitempty"`
+}
+
+// rawLLMLogger writes entries to size-capped, rotating files
+type rawLLMLogger struct {
+	dir          string
+	rules        []RedactionRule
+	maxBodyBytes int64
+	maxFileBytes int64
+	maxFiles     int
+
+	mu       sync.Mutex
+	prefix   string
+	file     *os.File
+	fileSize int64
+}
+
+// rawLLMCall tags a request context with the logger and the call it belongs to
+type rawLLMCall struct {
+	log       *rawLLMLogger
+	sessionID string
+	provider  string
+	modelID   string
+}
+
+type rawLLMCallKey struct{}
+
+var rawLLMTransportOnce sync.Once
+
+// withRawLLMLogging tags ctx so provider requests made with it are logged
+func (a *Agent) withRawLLMLogging(ctx context.Context, model LLMModel) context.Context {
+	if a.rawLLMLog == nil || a.rawLLMLog.dir == "" {
+		return ctx
+	}
+	rawLLMTransportOnce.Do(func() {
+		http.DefaultTransport = &rawLLMTransport{base: http.DefaultTransport}
+	})
+	return context.WithValue(ctx, rawLLMCallKey{}, &rawLLMCall{
+		log:       a.rawLLMLog,
+		sessionID: a.SessionID,
+		provider:  model.Provider,
+		modelID:   model.ModelID,
+	})
+}
+
+// closeRawLLMLog closes the current log file
+func (a *Agent) closeRawLLMLog() {
+	if a.rawLLMLog == nil {
+		return
+	}
+	a.rawLLMLog.mu.Lock()
+	defer a.rawLLMLog.mu.Unlock()
+	if a.rawLLMLog.file != nil {
+		_ = a.rawLLMLog.file.Close()
+		a.rawLLMLog.file = nil
+	}
+}
+
+// rawLLMTransport logs requests whose context carries a rawLLMCall
+type rawLLMTransport struct {
+	base http.RoundTripper
+}
+
+func (t *rawLLMTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	call, ok := req.Context().Value(rawLLMCallKey{}).(*rawLLMCall)
+	if !ok {
+		return t.base.RoundTrip(req)
+	}
+
+	entry := &rawLLMLogEntry{
+		Timestamp:      time.Now(),
+		SessionID:      call.sessionID,
+		Provider:       call.provider,
+		ModelID:        call.modelID,
+		Method:         req.Method,
+		URL:            call.log.redact(req.URL.String()),
+		RequestHeaders: call.log.headers(req.Header),
+	}
+	if req.Body != nil && req.Body != http.NoBody {
+		body, err := io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		entry.RequestBody = call.log.body(body, entry)
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		entry.DurationMs = time.Since(entry.Timestamp).Milliseconds()
+		entry.Error = call.log.redact(err.Error())
+		call.log.write(entry)
+		return nil, err
+	}
+	entry.Status = resp.StatusCode
+	entry.ResponseHeaders = call.log.headers(resp.Header)
+	// The entry is written when the caller closes the body, so streamed
+	// responses are captured in full (up to the cap)
+	resp.Body = &rawLLMResponseBody{ReadCloser: resp.Body, call: call, entry: entry}
+	return resp, nil
+}
+
+// rawLLMResponseBody captures the response body as the caller reads it
+type rawLLMResponseBody struct {
+	io.ReadCloser
+	call  *rawLLMCall
+	entry *rawLLMLogEntry
+	buf   bytes.Buffer
+	once  sync.Once
+}
+
+func (b *rawLLMResponseBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if n > 0 && int64(b.buf.Len()) <= b.call.log.maxBodyBytes {
+		b.buf.Write(p[:n])
+	}
+	if err == io.EOF {
+		b.finish()
+	}
+	return n, err
+}
+
+func (b *rawLLMResponseBody) Close() error {
+	b.finish()
+	return b.ReadCloser.Close()
+}
+
+func (b *rawLLMResponseBody) finish() {
+	b.once.Do(func() {
+		b.entry.DurationMs = time.Since(b.entry.Timestamp).Milliseconds()
+		b.entry.ResponseBody = b.call.log.body(b.buf.Bytes(), b.entry)
+		b.call.log.write(b.entry)
+	})
+}
+
+// redact applies the redaction rules to s
+func (l *rawLLMLogger) redact(s string) string {
+	return applyRedactionRules(l.rules, s)
+}
+
+// applyRedactionRules replaces every match of the rules in s
+func applyRedactionRules(rules []RedactionRule, s string) string {
+	for _, rule := range rules {
+		if rule.Pattern == nil {
+			continue
+		}
+		replacement := rule.Replacement
+		if replacement == "" {
+			replacement = rawLLMLogRedacted
+		}
+		s = rule.Pattern.ReplaceAllString(s, replacement)
+	}
+	return s
+}
+
+// headers returns the redacted headers, without authentication headers
+func (l *rawLLMLogger) headers(h http.Header) map[string]string {
+	if len(h) == 0 {
+		return nil
+	}
+	out := make(map[string]string, len(h))
+	for name, values := range h {
+		if rawLLMLogSensitiveHeaders[strings.ToLower(name)] {
+			continue
+		}
+		out[name] = l.redact(strings.Join(values, ", "))
+	}
+	return out
+}
+
+// body returns a redacted, size-capped body as JSON: the body itself when it
+// is valid JSON, otherwise (streams, truncated bodies) a JSON string
+func (l *rawLLMLogger) body(body []byte, entry *rawLLMLogEntry) json.RawMessage {
+	if len(body) == 0 {
+		return nil
+	}
+	// Redact before truncating so a cut never exposes part of a secret
+	redacted := []byte(l.redact(string(body)))
+	if int64(len(redacted)) > l.maxBodyBytes {
+		redacted = redacted[:l.maxBodyBytes]
+		entry.Truncated = true
+	}
+	if json.Valid(redacted) {
+		return redacted
+	}
+	quoted, _ := json.Marshal(string(redacted))
+	return quoted
+}
+
+// write appends entry to the current file, rotating it when full
+func (l *rawLLMLogger) write(entry *rawLLMLogEntry) {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	line = append(line, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.file != nil && l.fileSize+int64(len(line)) > l.maxFileBytes {
+		_ = l.file.Close()
+		l.file = nil
+	}
+	if l.file == nil {
+		if err := l.rotate(entry.SessionID); err != nil {
+			return
+		}
+	}
+	n, _ := l.file.Write(line)
+	l.fileSize += int64(n)
+}
+
+// rotate opens a new log file and removes the oldest beyond maxFiles
+func (l *rawLLMLogger) rotate(sessionID string) error {
+	if err := os.MkdirAll(l.dir, 0o755); err != nil {
+		return err
+	}
+	if l.prefix == "" {
+		if sessionID == "" {
+			sessionID = "default"
+		}
+		l.prefix = "llm_raw_" + sanitizeRawLLMLogName(sessionID) + "_"
+	}
+	path := filepath.Join(l.dir, fmt.Sprintf("%s%d.jsonl", l.prefix, time.Now().UnixNano()))
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600) //nolint:gosec // dir is supplied by the caller
+	if err != nil {
+		return err
+	}
+	l.file, l.fileSize = file, 0
+
+	existing, _ := filepath.Glob(filepath.Join(l.dir, l.prefix+"*.jsonl"))
+	sort.Strings(existing)
+	for len(existing) > l.maxFiles {
+		_ = os.Remove(existing[0])
+		existing = existing[1:]
+	}
+	return nil
+}
+
+var rawLLMLogNameUnsafe = regexp.MustCompile(`[^A-Za-z0-9_\-]`)
+
+func sanitizeRawLLMLogName(name string) string {
+	return rawLLMLogNameUnsafe.ReplaceAllString(name, "_")
+}
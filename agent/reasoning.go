@@ -0,0 +1,309 @@
+// reasoning.go
+//
+// This file implements the provider-aware output controls: a cap on max
+// output tokens and a reasoning effort level. Providers expose reasoning as
+// different knobs, so the agent translates the level per model on every
+// call:
+//   - OpenAI reasoning models (o-series, gpt-5): reasoning_effort
+//   - Claude: an extended thinking budget of 1024, 4096 or 16384 tokens
+//     for low, medium and high (minimal turns thinking off)
+//   - Gemini 3: thinking_level; Gemini 2.5: a thinking budget like Claude's
+//
+// The model metadata of the LLM decides reasoning effort and thinking level
+// support, with the model family as a fallback when there is no metadata.
+// Thinking budget models (Claude 3.7 and later, Gemini 2.5) are recognized
+// by family, as the model catalogs do not report them. NewAgent rejects a level the primary
+// model does not support, and a Claude thinking budget that does not fit in
+// the max output tokens (Anthropic requires budget < max_tokens and would
+// otherwise drop thinking silently). The level is translated again for each
+// fallback model, which is called without it when it does not support the
+// level or its thinking budget does not fit.
+//
+// The reasoning tokens a call used are reported in the usage metrics of
+// llm_generation_end events (Gemini thoughts tokens in its metadata).
+//
+// Exported:
+//   - WithMaxOutputTokens: Cap the output tokens of every LLM call
+//   - WithReasoningEffort: Set the reasoning effort level
+//   - Reasoning effort levels: ReasoningEffortMinimal, ReasoningEffortLow, ReasoningEffortMedium, ReasoningEffortHigh
+
+package mcpagent
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	llm "github.com/manishiitg/multi-llm-provider-go"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+// Reasoning effort levels accepted by WithReasoningEffort. Models that list
+// other levels in their metadata (e.g. "xhigh") accept those as well.
+const (
+	ReasoningEffortMinimal = "minimal"
+	ReasoningEffortLow     = "low"
+	ReasoningEffortMedium  = "medium"
+	ReasoningEffortHigh    = "high"
+)
+
+// Thinking budgets for the reasoning effort levels of budget-based models,
+// matching the labels of the Anthropic adapter. 1024 is Anthropic's minimum.
+var reasoningThinkingBudgets = map[string]int{
+	ReasoningEffortLow:    1024,
+	ReasoningEffortMedium: 4096,
+	ReasoningEffortHigh:   16384,
+}
+
+// reasoningKnob is how a model takes its reasoning effort
+type reasoningKnob int
+
+const (
+	reasoningUnsupported reasoningKnob = iota
+	reasoningEffortKnob
+	reasoningThinkingLevelKnob
+	reasoningThinkingBudgetKnob
+)
+
+// reasoningControl is the reasoning effort translated for one model
+type reasoningControl struct {
+	knob   reasoningKnob
+	value  string // Effort or thinking level
+	budget int    // Thinking budget in tokens (0 = thinking off)
+}
+
+// WithMaxOutputTokens caps the output tokens of every LLM call.
+//
+// With adaptive max tokens (WithAdaptiveMaxTokens) each call's budget is
+// limited to n; otherwise every call asks for n. For Claude models with a
+// reasoning effort, n includes the thinking budget and must exceed it.
+// Ignored for coding-agent CLI providers.
+//
+// Default: 0 (Provider default, or the adaptive budget)
+func WithMaxOutputTokens(n int) AgentOption {
+	return func(a *Agent) {
+		a.MaxOutputTokens = n
+	}
+}
+
+// WithReasoningEffort sets how much the model reasons before answering.
+//
+// The level is sent as reasoning_effort to OpenAI reasoning models, as a
+// thinking level to Gemini 3 and as a thinking budget to Claude and Gemini
+// 2.5 (see reasoning.go). NewAgent fails when the model does not support the
+// level.
+//
+// Parameters:
+//   - level: ReasoningEffortMinimal, ReasoningEffortLow, ReasoningEffortMedium or ReasoningEffortHigh
+//
+// Default: "" (Provider default)
+func WithReasoningEffort(level string) AgentOption {
+	return func(a *Agent) {
+		a.ReasoningEffort = strings.ToLower(strings.TrimSpace(level))
+	}
+}
+
+// validateOutputControls checks MaxOutputTokens and ReasoningEffort against
+// the agent's model
+func (a *Agent) validateOutputControls() error {
+	if a.MaxOutputTokens < 0 {
+		return fmt.Errorf("max output tokens must be positive, got %d", a.MaxOutputTokens)
+	}
+	if a.ReasoningEffort == "" || isCodingCLIProvider(a.provider, a.ModelID) {
+		return nil
+	}
+	modelID := a.reasoningModelID()
+	control, err := resolveReasoningControl(a.modelMetadata(modelID), modelID, a.ReasoningEffort)
+	if err != nil {
+		return err
+	}
+	if control.knob == reasoningThinkingBudgetKnob && a.MaxOutputTokens > 0 && a.MaxOutputTokens <= control.budget {
+		return fmt.Errorf("max output tokens %d must exceed the %d token thinking budget of reasoning effort %q for model %s",
+			a.MaxOutputTokens, control.budget, a.ReasoningEffort, modelID)
+	}
+	return nil
+}
+
+// reasoningControl translates ReasoningEffort for the current model; ok is
+// false when no reasoning option should be sent
+func (a *Agent) reasoningControl() (reasoningControl, bool) {
+	if a.ReasoningEffort == "" || isCodingCLIProvider(a.provider, a.ModelID) {
+		return reasoningControl{}, false
+	}
+	modelID := a.reasoningModelID()
+	control, err := resolveReasoningControl(a.modelMetadata(modelID), modelID, a.ReasoningEffort)
+	return control, err == nil
+}
+
+// fallbackReasoningOption replaces the reasoning options built for the
+// primary model with those of fallback model, or with none when it does not
+// support the reasoning effort. A thinking budget that no longer fits in
+// MaxOutputTokens turns thinking off.
+func (a *Agent) fallbackReasoningOption(model LLMModel) llmtypes.CallOption {
+	control, ok := reasoningControl{}, false
+	if a.ReasoningEffort != "" && !isCodingCLIProvider(llm.Provider(model.Provider), model.ModelID) {
+		metadata := a.modelMetadata(model.ModelID)
+		if model.Model != nil {
+			metadata, _ = model.Model.GetModelMetadata(model.ModelID)
+		}
+		var err error
+		control, err = resolveReasoningControl(metadata, model.ModelID, a.ReasoningEffort)
+		ok = err == nil
+	}
+	return func(o *llmtypes.CallOptions) {
+		o.ReasoningEffort, o.ThinkingLevel, o.ThinkingBudget = "", "", 0
+		if !ok {
+			return
+		}
+		switch control.knob {
+		case reasoningEffortKnob:
+			o.ReasoningEffort = control.value
+		case reasoningThinkingLevelKnob:
+			o.ThinkingLevel = control.value
+		case reasoningThinkingBudgetKnob:
+			if control.budget == 0 || a.MaxOutputTokens > 0 && a.MaxOutputTokens <= control.budget {
+				return
+			}
+			if o.MaxTokens > 0 && o.MaxTokens <= control.budget {
+				o.MaxTokens = control.budget + DefaultMinAdaptiveMaxTokens
+				if a.MaxOutputTokens > 0 {
+					o.MaxTokens = min(o.MaxTokens, a.MaxOutputTokens)
+				}
+			}
+			o.ThinkingBudget = control.budget
+		}
+	}
+}
+
+// outputControlOptions returns the max tokens and reasoning call options for
+// one LLM call: the adaptive budget for hint capped by MaxOutputTokens, raised
+// when needed so a thinking budget still leaves room for the answer
+func (a *Agent) outputControlOptions(messages []llmtypes.MessageContent, hint MaxTokensTaskHint) []llmtypes.CallOption {
+	var opts []llmtypes.CallOption
+	maxTokens := a.callMaxTokens(messages, hint)
+	if control, ok := a.reasoningControl(); ok {
+		switch control.knob {
+		case reasoningEffortKnob:
+			opts = append(opts, llmtypes.WithReasoningEffort(control.value))
+		case reasoningThinkingLevelKnob:
+			opts = append(opts, llmtypes.WithThinkingLevel(control.value))
+		case reasoningThinkingBudgetKnob:
+			if control.budget > 0 {
+				if maxTokens > 0 && maxTokens <= control.budget {
+					maxTokens = control.budget + DefaultMinAdaptiveMaxTokens
+					if a.MaxOutputTokens > 0 {
+						maxTokens = min(maxTokens, a.MaxOutputTokens)
+					}
+				}
+				opts = append(opts, llmtypes.WithThinkingBudget(control.budget))
+			}
+		}
+	}
+	if maxTokens > 0 {
+		opts = append(opts, llmtypes.WithMaxTokens(maxTokens))
+	}
+	return opts
+}
+
+// callMaxTokens returns the max output tokens for one call, or 0 for the
+// provider default
+func (a *Agent) callMaxTokens(messages []llmtypes.MessageContent, hint MaxTokensTaskHint) int {
+	if isCodingCLIProvider(a.provider, a.ModelID) {
+		return 0
+	}
+	maxTokens := a.adaptiveMaxTokens(messages, hint)
+	if a.MaxOutputTokens > 0 && (maxTokens == 0 || maxTokens > a.MaxOutputTokens) {
+		maxTokens = a.MaxOutputTokens
+	}
+	return maxTokens
+}
+
+func (a *Agent) reasoningModelID() string {
+	if a.ModelID == "" && a.LLM != nil {
+		return a.LLM.GetModelID()
+	}
+	return a.ModelID
+}
+
+func (a *Agent) modelMetadata(modelID string) *llmtypes.ModelMetadata {
+	if a.LLM == nil {
+		return nil
+	}
+	metadata, err := a.LLM.GetModelMetadata(modelID)
+	if err != nil {
+		return nil
+	}
+	return metadata
+}
+
+// resolveReasoningControl translates level for modelID. Reasoning support
+// reported by metadata wins over the model family.
+func resolveReasoningControl(metadata *llmtypes.ModelMetadata, modelID, level string) (reasoningControl, error) {
+	knob, levels := reasoningKnobForModel(metadata, modelID)
+	if knob == reasoningUnsupported {
+		return reasoningControl{}, fmt.Errorf("model %s does not support reasoning effort", modelID)
+	}
+	if !slices.Contains(levels, level) {
+		return reasoningControl{}, fmt.Errorf("model %s does not support reasoning effort %q (supported: %s)",
+			modelID, level, strings.Join(levels, ", "))
+	}
+	control := reasoningControl{knob: knob, value: level}
+	if knob == reasoningThinkingBudgetKnob {
+		control.budget = reasoningThinkingBudgets[level]
+	}
+	return control, nil
+}
+
+// reasoningKnobForModel returns the knob of modelID and the levels it accepts.
+// Model catalogs report reasoning effort and thinking levels but not thinking
+// budgets, so metadata that reports neither rules out the effort and level
+// knobs and only the budget families are recognized by name.
+func reasoningKnobForModel(metadata *llmtypes.ModelMetadata, modelID string) (reasoningKnob, []string) {
+	defaultLevels := []string{ReasoningEffortMinimal, ReasoningEffortLow, ReasoningEffortMedium, ReasoningEffortHigh}
+	if metadata != nil {
+		switch {
+		case metadata.SupportsReasoningEffort:
+			return reasoningEffortKnob, orDefaultLevels(metadata.ReasoningEffortLevels, defaultLevels)
+		case metadata.SupportsThinkingLevel:
+			return reasoningThinkingLevelKnob, orDefaultLevels(metadata.ThinkingLevels, []string{ReasoningEffortLow, ReasoningEffortHigh})
+		case metadata.SupportsThinkingBudget:
+			return reasoningThinkingBudgetKnob, defaultLevels
+		}
+	}
+
+	// Strip routing prefixes: "openai/gpt-5", "us.anthropic.claude-sonnet-4"
+	name := strings.ToLower(modelID)
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	switch {
+	case metadata == nil && (llm.IsO3O4Model(name) || strings.HasPrefix(name, "o1") ||
+		strings.HasPrefix(name, "gpt-5") && !strings.HasPrefix(name, "gpt-5-chat")):
+		return reasoningEffortKnob, defaultLevels
+	case strings.Contains(name, "claude") && !isLegacyClaude(name):
+		return reasoningThinkingBudgetKnob, defaultLevels
+	case metadata == nil && strings.HasPrefix(name, "gemini-3"):
+		return reasoningThinkingLevelKnob, []string{ReasoningEffortLow, ReasoningEffortHigh}
+	case strings.HasPrefix(name, "gemini-2.5"):
+		return reasoningThinkingBudgetKnob, defaultLevels
+	}
+	return reasoningUnsupported, nil
+}
+
+// isLegacyClaude reports whether name is a Claude model from before extended
+// thinking (Claude 3.7)
+func isLegacyClaude(name string) bool {
+	for _, legacy := range []string{"claude-instant", "claude-2", "claude-3-opus", "claude-3-sonnet", "claude-3-haiku", "claude-3-5"} {
+		if strings.Contains(name, legacy) {
+			return true
+		}
+	}
+	return false
+}
+
+func orDefaultLevels(levels, defaults []string) []string {
+	if len(levels) == 0 {
+		return defaults
+	}
+	return levels
+}
@@ -0,0 +1,118 @@
+package mcpagent
+
+import (
+	"testing"
+
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+func TestResolveReasoningControl(t *testing.T) {
+	tests := []struct {
+		name     string
+		metadata *llmtypes.ModelMetadata
+		modelID  string
+		level    string
+		want     reasoningControl
+		wantErr  bool
+	}{
+		{"o-series effort", nil, "o4-mini", "high", reasoningControl{knob: reasoningEffortKnob, value: "high"}, false},
+		{"gpt-5 behind a router", nil, "openai/gpt-5", "minimal", reasoningControl{knob: reasoningEffortKnob, value: "minimal"}, false},
+		{"claude budget", nil, "claude-sonnet-4-5", "medium", reasoningControl{knob: reasoningThinkingBudgetKnob, value: "medium", budget: 4096}, false},
+		{"claude minimal turns thinking off", nil, "us.anthropic.claude-opus-4", "minimal", reasoningControl{knob: reasoningThinkingBudgetKnob, value: "minimal"}, false},
+		{"gemini 3 level", nil, "gemini-3-pro-preview", "low", reasoningControl{knob: reasoningThinkingLevelKnob, value: "low"}, false},
+		{"gemini 3 without medium", nil, "gemini-3-pro-preview", "medium", reasoningControl{}, true},
+		{"gemini 2.5 budget", nil, "gemini-2.5-flash", "high", reasoningControl{knob: reasoningThinkingBudgetKnob, value: "high", budget: 16384}, false},
+		{"unsupported model", nil, "gpt-4o", "high", reasoningControl{}, true},
+		{"unknown level", nil, "o3", "extreme", reasoningControl{}, true},
+		{"gpt-5 chat", nil, "gpt-5-chat-latest", "low", reasoningControl{}, true},
+		{"claude before extended thinking", nil, "claude-3-5-sonnet-20241022", "low", reasoningControl{}, true},
+		{"claude 3.7 budget", nil, "claude-3-7-sonnet-latest", "low", reasoningControl{knob: reasoningThinkingBudgetKnob, value: "low", budget: 1024}, false},
+		{"metadata vetoes effort", &llmtypes.ModelMetadata{ModelID: "gpt-5"}, "gpt-5", "low", reasoningControl{}, true},
+		{"metadata vetoes thinking level", &llmtypes.ModelMetadata{ModelID: "gemini-3-flash-preview"}, "gemini-3-flash-preview", "low", reasoningControl{}, true},
+		{
+			"thinking budget without metadata flag",
+			&llmtypes.ModelMetadata{ModelID: "claude-sonnet-4-5"},
+			"claude-sonnet-4-5", "low",
+			reasoningControl{knob: reasoningThinkingBudgetKnob, value: "low", budget: 1024}, false,
+		},
+		{
+			"metadata levels win",
+			&llmtypes.ModelMetadata{SupportsReasoningEffort: true, ReasoningEffortLevels: []string{"low", "high", "xhigh"}},
+			"custom-model", "xhigh",
+			reasoningControl{knob: reasoningEffortKnob, value: "xhigh"}, false,
+		},
+		{
+			"metadata rejects unlisted level",
+			&llmtypes.ModelMetadata{SupportsReasoningEffort: true, ReasoningEffortLevels: []string{"low", "high"}},
+			"gpt-5", "minimal",
+			reasoningControl{}, true,
+		},
+	}
+	for _, tt := range tests {
+		got, err := resolveReasoningControl(tt.metadata, tt.modelID, tt.level)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: err = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: got %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestValidateOutputControls(t *testing.T) {
+	tests := []struct {
+		name    string
+		agent   *Agent
+		wantErr bool
+	}{
+		{"nothing set", &Agent{ModelID: "gpt-4o"}, false},
+		{"negative max tokens", &Agent{ModelID: "gpt-4o", MaxOutputTokens: -1}, true},
+		{"effort on a reasoning model", &Agent{ModelID: "o3", ReasoningEffort: "low"}, false},
+		{"effort on a non-reasoning model", &Agent{ModelID: "gpt-4o", ReasoningEffort: "low"}, true},
+		{"thinking budget fits", &Agent{ModelID: "claude-sonnet-4-5", ReasoningEffort: "high", MaxOutputTokens: 32000}, false},
+		{"thinking budget exceeds max tokens", &Agent{ModelID: "claude-sonnet-4-5", ReasoningEffort: "high", MaxOutputTokens: 8192}, true},
+	}
+	for _, tt := range tests {
+		if err := tt.agent.validateOutputControls(); (err != nil) != tt.wantErr {
+			t.Errorf("%s: err = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestOutputControlOptions(t *testing.T) {
+	apply := func(a *Agent, hint MaxTokensTaskHint) llmtypes.CallOptions {
+		var opts llmtypes.CallOptions
+		for _, opt := range a.outputControlOptions(nil, hint) {
+			opt(&opts)
+		}
+		return opts
+	}
+
+	openAI := &Agent{ModelID: "gpt-5", ReasoningEffort: "medium", MaxOutputTokens: 2000}
+	if got := apply(openAI, MaxTokensHintFinalSynthesis); got.ReasoningEffort != "medium" || got.MaxTokens != 2000 {
+		t.Errorf("openai options = effort %q, max tokens %d", got.ReasoningEffort, got.MaxTokens)
+	}
+
+	// The orchestration budget is raised above the thinking budget,
+	// but never beyond MaxOutputTokens
+	claude := &Agent{
+		ModelID:           "claude-sonnet-4-5",
+		ReasoningEffort:   "medium",
+		MaxOutputTokens:   4200,
+		AdaptiveMaxTokens: &AdaptiveMaxTokensConfig{ToolOrchestrationTokens: 2048},
+	}
+	if got := apply(claude, MaxTokensHintToolOrchestration); got.ThinkingBudget != 4096 || got.MaxTokens != 4200 {
+		t.Errorf("claude options = budget %d, max tokens %d, want 4096, 4200", got.ThinkingBudget, got.MaxTokens)
+	}
+	claude.MaxOutputTokens = 0
+	if got := apply(claude, MaxTokensHintToolOrchestration); got.MaxTokens != 4096+DefaultMinAdaptiveMaxTokens {
+		t.Errorf("claude max tokens = %d, want %d", got.MaxTokens, 4096+DefaultMinAdaptiveMaxTokens)
+	}
+
+	// A fallback model without reasoning support gets no reasoning option
+	fallback := &Agent{ModelID: "gpt-4o", ReasoningEffort: "high"}
+	if got := apply(fallback, MaxTokensHintFinalSynthesis); got.ReasoningEffort != "" || got.MaxTokens != 0 {
+		t.Errorf("fallback options = effort %q, max tokens %d", got.ReasoningEffort, got.MaxTokens)
+	}
+}
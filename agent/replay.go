@@ -0,0 +1,421 @@
+// replay.go
+//
+// This file provides deterministic replay for debugging conversations. An
+// agent created WithRecording appends every LLM response and tool result of
+// its session to a JSON Lines recording file as they happen, so recording
+// costs the same for every call however long the session runs. An agent created
+// WithReplay loads such a file and re-runs the conversation from it: LLM
+// calls return the recorded responses in order and tool calls return the
+// recorded results, so no provider is called and no tool is executed. The
+// same prompts then walk through the same turns, tool calls and final answer,
+// which makes a failed production conversation reproducible under a debugger.
+//
+// Scope: the conversation's LLM calls (including streaming, which replays the
+// recorded content as one chunk) and tool executions (MCP, custom and virtual
+// tools) are recorded. Failed LLM calls are not, so replay skips the retries
+// of the original run. LLM calls the agent makes through its own model and
+// fallbacks outside the conversation turns (context summarization, MCP
+// sampling) are recorded and replayed in call order as well. Calls made
+// directly on a separate or auxiliary model (output and tool output guard
+// classifiers, grounding checks, long-term memory extraction, tool image
+// descriptions, smart routing, structured output conversion) are neither
+// recorded nor replayed and still reach their models. Tool middleware runs on replay as it did when recording, around the
+// recorded results. The agent still loads its tool list when created, so
+// replayed tool calls resolve the same way.
+//
+// Exported:
+//   - SessionRecording / RecordedLLMCall / RecordedToolCall: Recording file format
+//   - LoadSessionRecording: Read a recording file
+//   - WithRecording: Record a session when creating an agent
+//   - WithReplay: Replay a recording when creating an agent
+//   - ErrReplayExhausted / ErrReplayToolNotRecorded: Replay divergence errors
+
+package mcpagent
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// sessionRecordingVersion is the format version written to recordings.
+// Version 1 recordings were one JSON document holding a SessionRecording;
+// version 2 recordings are JSON Lines of recordingLine.
+const sessionRecordingVersion = 2
+
+// Replay divergence errors: the replayed conversation asked for more than the
+// recording holds, which means it no longer follows the recorded run
+var (
+	ErrReplayExhausted       = errors.New("replay: no recorded LLM response left")
+	ErrReplayToolNotRecorded = errors.New("replay: tool call was not recorded")
+)
+
+// SessionRecording is the content of a recording file, as returned by
+// LoadSessionRecording
+type SessionRecording struct {
+	Version   int                `json:"version"`
+	SessionID string             `json:"session_id,omitempty"`
+	CreatedAt time.Time          `json:"created_at"`
+	LLMCalls  []RecordedLLMCall  `json:"llm_calls"`
+	ToolCalls []RecordedToolCall `json:"tool_calls"`
+}
+
+// RecordedLLMCall is one successful LLM call, in call order
+type RecordedLLMCall struct {
+	Provider string `json:"provider,omitempty"`
+	ModelID  string `json:"model_id,omitempty"`
+	// Messages is the number of messages sent; replay logs a warning when
+	// the replayed call sends a different number
+	Messages int                       `json:"messages"`
+	Response *llmtypes.ContentResponse `json:"response"`
+}
+
+// RecordedToolCall is one tool execution, in completion order
+type RecordedToolCall struct {
+	// ID is the tool call ID assigned by the LLM; replay matches on it
+	ID         string                 `json:"id,omitempty"`
+	Name       string                 `json:"name"`
+	ServerName string                 `json:"server_name,omitempty"`
+	Arguments  map[string]interface{} `json:"arguments,omitempty"`
+	// Result is the mcp.CallToolResult as JSON; empty when the call failed
+	Result json.RawMessage `json:"result,omitempty"`
+	// Error is the error returned by the tool execution, if any
+	Error string `json:"error,omitempty"`
+}
+
+// recordingLine is one line of a recording file: the header line with the
+// version and creation time, then one line per recorded call. LLM call lines
+// carry the session ID.
+type recordingLine struct {
+	Version   int               `json:"version,omitempty"`
+	CreatedAt time.Time         `json:"created_at,omitzero"`
+	SessionID string            `json:"session_id,omitempty"`
+	LLMCall   *RecordedLLMCall  `json:"llm_call,omitempty"`
+	ToolCall  *RecordedToolCall `json:"tool_call,omitempty"`
+}
+
+// LoadSessionRecording reads a recording file written WithRecording. A last
+// line cut short by a crash while recording is ignored.
+func LoadSessionRecording(path string) (*SessionRecording, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // G304: path is provided by the caller
+	if err != nil {
+		return nil, fmt.Errorf("failed to read recording: %w", err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	var header recordingLine
+	if err := dec.Decode(&header); err != nil {
+		return nil, fmt.Errorf("failed to decode recording: %w", err)
+	}
+	if header.Version <= 1 {
+		var rec SessionRecording
+		if err := json.Unmarshal(data, &rec); err != nil {
+			return nil, fmt.Errorf("failed to decode recording: %w", err)
+		}
+		return &rec, nil
+	}
+	if header.Version > sessionRecordingVersion {
+		return nil, fmt.Errorf("unsupported recording version %d", header.Version)
+	}
+
+	rec := &SessionRecording{Version: header.Version, CreatedAt: header.CreatedAt}
+	for lineNumber := 2; dec.More(); lineNumber++ {
+		var line recordingLine
+		if err := dec.Decode(&line); err != nil {
+			if errors.Is(err, io.ErrUnexpectedEOF) {
+				break
+			}
+			return nil, fmt.Errorf("failed to decode recording line %d: %w", lineNumber, err)
+		}
+		if line.SessionID != "" {
+			rec.SessionID = line.SessionID
+		}
+		if line.LLMCall != nil {
+			rec.LLMCalls = append(rec.LLMCalls, *line.LLMCall)
+		}
+		if line.ToolCall != nil {
+			rec.ToolCalls = append(rec.ToolCalls, *line.ToolCall)
+		}
+	}
+	return rec, nil
+}
+
+// sessionRecorder records a session to a file, or replays one from it
+type sessionRecorder struct {
+	path   string
+	replay bool
+
+	mu sync.Mutex
+	// started is set once the header line is written
+	started bool
+	// rec is the replayed recording; while recording it only holds the
+	// creation time
+	rec SessionRecording
+	// loadErr is the error loading the recording in replay mode; every
+	// replayed call fails with it
+	loadErr error
+	// Replay cursors
+	nextLLM   int
+	usedTools []bool
+}
+
+// WithRecording records every LLM response and tool result of the agent's
+// session to path (JSON Lines, one line appended per call), for later
+// debugging WithReplay. Recordings hold full LLM responses and tool outputs;
+// store them like conversation logs.
+//
+// Default: disabled
+func WithRecording(path string) AgentOption {
+	return func(a *Agent) {
+		if path == "" {
+			return
+		}
+		a.recorder = &sessionRecorder{
+			path: path,
+			rec:  SessionRecording{Version: sessionRecordingVersion, CreatedAt: time.Now()},
+		}
+	}
+}
+
+// WithReplay re-runs a conversation recorded WithRecording: LLM calls return
+// the recorded responses in order and tool calls the recorded results, without
+// calling providers or executing tools. Ask the agent the same prompts as the
+// recorded run. If the recording cannot be loaded, every LLM call fails with
+// the load error; a conversation that diverges from the recording fails with
+// ErrReplayExhausted or gets ErrReplayToolNotRecorded tool errors.
+//
+// Example:
+//
+//	agent, _ := mcpagent.NewAgent(ctx, llm, configPath,
+//	    mcpagent.WithReplay("recordings/session-42.json"))
+//	answer, _ := agent.Ask(ctx, originalPrompt)
+//
+// Default: disabled
+func WithReplay(recordingPath string) AgentOption {
+	return func(a *Agent) {
+		r := &sessionRecorder{path: recordingPath, replay: true}
+		if rec, err := LoadSessionRecording(recordingPath); err != nil {
+			r.loadErr = err
+		} else {
+			r.rec = *rec
+			r.usedTools = make([]bool, len(rec.ToolCalls))
+		}
+		a.recorder = r
+	}
+}
+
+// appendLine appends line to the recording file, starting a new file with
+// the header line on the first call. Callers hold r.mu.
+func (r *sessionRecorder) appendLine(line recordingLine) error {
+	var buf bytes.Buffer
+	flags := os.O_WRONLY | os.O_APPEND
+	if !r.started {
+		if err := os.MkdirAll(filepath.Dir(r.path), 0700); err != nil {
+			return fmt.Errorf("failed to create recording directory: %w", err)
+		}
+		if err := json.NewEncoder(&buf).Encode(recordingLine{Version: r.rec.Version, CreatedAt: r.rec.CreatedAt}); err != nil {
+			return fmt.Errorf("failed to encode recording: %w", err)
+		}
+		flags = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	}
+	if err := json.NewEncoder(&buf).Encode(line); err != nil {
+		return fmt.Errorf("failed to encode recording: %w", err)
+	}
+	f, err := os.OpenFile(r.path, flags, 0600) //nolint:gosec // G304: path is provided by the caller
+	if err != nil {
+		return fmt.Errorf("failed to write recording: %w", err)
+	}
+	if _, err := f.Write(buf.Bytes()); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to write recording: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write recording: %w", err)
+	}
+	r.started = true
+	return nil
+}
+
+// recordLLM appends a successful LLM call
+func (r *sessionRecorder) recordLLM(sessionID string, model LLMModel, messages int, resp *llmtypes.ContentResponse) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.appendLine(recordingLine{
+		SessionID: sessionID,
+		LLMCall: &RecordedLLMCall{
+			Provider: model.Provider,
+			ModelID:  model.ModelID,
+			Messages: messages,
+			Response: resp,
+		},
+	})
+}
+
+// recordTool appends a tool execution
+func (r *sessionRecorder) recordTool(call *ToolInvocation, result *mcp.CallToolResult, toolErr error) error {
+	entry := RecordedToolCall{
+		ID:         call.ID,
+		Name:       call.Name,
+		ServerName: call.ServerName,
+		Arguments:  call.Arguments,
+	}
+	if result != nil {
+		// Text built as &mcp.TextContent{Text: ...} has no type, which
+		// mcp.ParseCallToolResult rejects on replay
+		encoded := *result
+		encoded.Content = make([]mcp.Content, len(result.Content))
+		for i, content := range result.Content {
+			if text, ok := content.(*mcp.TextContent); ok && text.Type == "" {
+				content = mcp.NewTextContent(text.Text)
+			}
+			encoded.Content[i] = content
+		}
+		data, err := json.Marshal(&encoded)
+		if err != nil {
+			return fmt.Errorf("failed to encode tool result: %w", err)
+		}
+		entry.Result = data
+	}
+	if toolErr != nil {
+		entry.Error = toolErr.Error()
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.appendLine(recordingLine{ToolCall: &entry})
+}
+
+// nextLLMCall returns the next recorded LLM call
+func (r *sessionRecorder) nextLLMCall() (RecordedLLMCall, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.loadErr != nil {
+		return RecordedLLMCall{}, r.loadErr
+	}
+	if r.nextLLM >= len(r.rec.LLMCalls) {
+		return RecordedLLMCall{}, fmt.Errorf("%w (recording has %d)", ErrReplayExhausted, len(r.rec.LLMCalls))
+	}
+	call := r.rec.LLMCalls[r.nextLLM]
+	r.nextLLM++
+	return call, nil
+}
+
+// takeToolCall returns the recorded execution of call: the unused entry with
+// the same tool call ID, or without IDs the first unused entry for the tool
+func (r *sessionRecorder) takeToolCall(call *ToolInvocation) (RecordedToolCall, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, entry := range r.rec.ToolCalls {
+		if r.usedTools[i] || entry.Name != call.Name || entry.ID != call.ID {
+			continue
+		}
+		r.usedTools[i] = true
+		return entry, true
+	}
+	if call.ID == "" {
+		return RecordedToolCall{}, false
+	}
+	for i, entry := range r.rec.ToolCalls {
+		if r.usedTools[i] || entry.Name != call.Name || entry.ID != "" {
+			continue
+		}
+		r.usedTools[i] = true
+		return entry, true
+	}
+	return RecordedToolCall{}, false
+}
+
+// replayLLM answers an LLM call from the recording. The recorded content is
+// sent as one chunk when the call streams.
+func (a *Agent) replayLLM(ctx context.Context, messages []llmtypes.MessageContent, opts []llmtypes.CallOption) (*llmtypes.ContentResponse, error) {
+	call, err := a.recorder.nextLLMCall()
+	if err != nil {
+		return nil, err
+	}
+	if call.Messages != len(messages) {
+		getLogger(a).Warn("⏪ [REPLAY] LLM call diverges from the recording",
+			loggerv2.Int("recorded_messages", call.Messages),
+			loggerv2.Int("messages", len(messages)))
+	}
+	resp := call.Response
+	if resp == nil {
+		resp = &llmtypes.ContentResponse{}
+	}
+	var callOpts llmtypes.CallOptions
+	for _, opt := range opts {
+		opt(&callOpts)
+	}
+	if callOpts.StreamChan != nil && len(resp.Choices) > 0 && resp.Choices[0].Content != "" {
+		select {
+		case callOpts.StreamChan <- llmtypes.StreamChunk{Type: llmtypes.StreamChunkTypeContent, Content: resp.Choices[0].Content}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	return resp, nil
+}
+
+// replayTool answers a tool call from the recording
+func (a *Agent) replayTool(call *ToolInvocation) (*mcp.CallToolResult, error) {
+	entry, ok := a.recorder.takeToolCall(call)
+	if !ok {
+		getLogger(a).Warn("⏪ [REPLAY] Tool call diverges from the recording",
+			loggerv2.String("tool_name", call.Name),
+			loggerv2.String("tool_call_id", call.ID))
+		return nil, fmt.Errorf("%w: %s (id %q)", ErrReplayToolNotRecorded, call.Name, call.ID)
+	}
+	var result *mcp.CallToolResult
+	if len(entry.Result) > 0 {
+		raw := json.RawMessage(entry.Result)
+		parsed, err := mcp.ParseCallToolResult(&raw)
+		if err != nil {
+			return nil, fmt.Errorf("replay: failed to decode recorded result of %s: %w", call.Name, err)
+		}
+		result = parsed
+	}
+	if entry.Error != "" {
+		return result, errors.New(entry.Error)
+	}
+	return result, nil
+}
+
+// recordingToolExec wraps execute, the innermost step of the tool middleware
+// chain, to record its results or answer from a replayed recording
+func (a *Agent) recordingToolExec(execute ToolExecFunc) ToolExecFunc {
+	if a.recorder == nil {
+		return execute
+	}
+	if a.recorder.replay {
+		return func(_ context.Context, call *ToolInvocation) (*mcp.CallToolResult, error) {
+			return a.replayTool(call)
+		}
+	}
+	return func(ctx context.Context, call *ToolInvocation) (*mcp.CallToolResult, error) {
+		result, err := execute(ctx, call)
+		if recErr := a.recorder.recordTool(call, result, err); recErr != nil {
+			getLogger(a).Warn("⏺️ [RECORDING] Failed to record tool call",
+				loggerv2.String("tool_name", call.Name),
+				loggerv2.Error(recErr))
+		}
+		return result, err
+	}
+}
+
+// recordLLMCall records a successful LLM call when recording
+func (a *Agent) recordLLMCall(model LLMModel, messages []llmtypes.MessageContent, resp *llmtypes.ContentResponse) {
+	if a.recorder == nil || a.recorder.replay {
+		return
+	}
+	if err := a.recorder.recordLLM(a.SessionID, model, len(messages), resp); err != nil {
+		getLogger(a).Warn("⏺️ [RECORDING] Failed to record LLM call", loggerv2.Error(err))
+	}
+}
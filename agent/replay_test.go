@@ -0,0 +1,119 @@
+package mcpagent
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+func TestRecordingReplaysConversationWithoutLLMOrTools(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "recordings", "session.json")
+
+	var received map[string]interface{}
+	recorder := middlewareTestAgent(&received)
+	recorder.SessionID = "session-replay-test"
+	WithRecording(path)(recorder)
+
+	messages := []llmtypes.MessageContent{llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "who is bob?")}
+	resp := &llmtypes.ContentResponse{Choices: []*llmtypes.ContentChoice{{
+		Content:   "Let me look that up.",
+		ToolCalls: []llmtypes.ToolCall{{ID: "call_1", Type: "function", FunctionCall: &llmtypes.FunctionCall{Name: "lookup", Arguments: `{"user":"bob"}`}}},
+	}}}
+	recorder.recordLLMCall(LLMModel{Provider: "openai", ModelID: "gpt-4.1"}, messages, resp)
+	recorded := runMiddlewareTestCall(recorder)
+	if received == nil {
+		t.Fatal("tool should run while recording")
+	}
+
+	rec, err := LoadSessionRecording(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec.SessionID != "session-replay-test" || len(rec.LLMCalls) != 1 || len(rec.ToolCalls) != 1 {
+		t.Fatalf("recording = %+v", rec)
+	}
+
+	received = nil
+	replayer := middlewareTestAgent(&received)
+	WithReplay(path)(replayer)
+
+	stream := make(chan llmtypes.StreamChunk, 1)
+	got, err := replayer.executeLLM(context.Background(), LLMModel{Provider: "openai", ModelID: "gpt-4.1"}, messages,
+		[]llmtypes.CallOption{llmtypes.WithStreamingChan(stream)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Choices[0].Content != "Let me look that up." || got.Choices[0].ToolCalls[0].ID != "call_1" {
+		t.Errorf("replayed response = %+v", got.Choices[0])
+	}
+	if chunk := <-stream; chunk.Content != "Let me look that up." {
+		t.Errorf("streamed chunk = %q", chunk.Content)
+	}
+
+	replayed := runMiddlewareTestCall(replayer)
+	if received != nil {
+		t.Error("tool should not run on replay")
+	}
+	if replayed.resultText != recorded.resultText {
+		t.Errorf("replayed result = %q, want %q", replayed.resultText, recorded.resultText)
+	}
+
+	// The conversation now asks for more than was recorded
+	if _, err := replayer.executeLLM(context.Background(), LLMModel{}, messages, nil); !errors.Is(err, ErrReplayExhausted) {
+		t.Errorf("err = %v, want ErrReplayExhausted", err)
+	}
+	if result := runMiddlewareTestCall(replayer); !errors.Is(result.toolErr, ErrReplayToolNotRecorded) {
+		t.Errorf("toolErr = %v, want ErrReplayToolNotRecorded", result.toolErr)
+	}
+}
+
+func TestReplayFailsLLMCallsWhenRecordingCannotLoad(t *testing.T) {
+	a := &Agent{}
+	WithReplay(filepath.Join(t.TempDir(), "missing.json"))(a)
+	_, err := a.executeLLM(context.Background(), LLMModel{}, nil, nil)
+	if err == nil || !strings.Contains(err.Error(), "failed to read recording") {
+		t.Errorf("err = %v, want the load error", err)
+	}
+}
+
+func TestRecordingAppendsOneLinePerCall(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.json")
+	a := &Agent{SessionID: "session-1"}
+	WithRecording(path)(a)
+	for i := 0; i < 3; i++ {
+		a.recordLLMCall(LLMModel{ModelID: "gpt-4.1"}, nil, &llmtypes.ContentResponse{})
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Count(string(data), "\n"); lines != 4 {
+		t.Errorf("recording has %d lines, want a header and 3 calls:\n%s", lines, data)
+	}
+
+	// A line cut short by a crash is ignored
+	if err := os.WriteFile(path, append(data, `{"llm_call":{"mess`...), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	rec, err := LoadSessionRecording(path)
+	if err != nil || rec.Version != sessionRecordingVersion || rec.SessionID != "session-1" || len(rec.LLMCalls) != 3 {
+		t.Fatalf("recording = %+v, err = %v", rec, err)
+	}
+}
+
+func TestLoadSessionRecordingReadsVersion1Documents(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.json")
+	doc := `{"version": 1, "session_id": "old", "llm_calls": [{"model_id": "gpt-4.1", "messages": 1}], "tool_calls": [{"id": "call_1", "name": "lookup"}]}`
+	if err := os.WriteFile(path, []byte(doc), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	rec, err := LoadSessionRecording(path)
+	if err != nil || rec.SessionID != "old" || len(rec.LLMCalls) != 1 || rec.ToolCalls[0].ID != "call_1" {
+		t.Fatalf("recording = %+v, err = %v", rec, err)
+	}
+}
@@ -0,0 +1,395 @@
+// retention.go
+//
+// This file provides retention policies for offloaded tool outputs and
+// workspace folders, which otherwise accumulate forever. A RetentionPolicy
+// bounds them by file age and by total size (oldest files go first), and can
+// remove a session's tool output folder when the session ends. A
+// StorageJanitor goroutine applies the age and size limits periodically;
+// agents created WithRetentionPolicy run their own, and the gRPC server runs
+// one shared janitor for all its agents.
+//
+// Files registered with RegisterArtifact (results handed to users, files
+// served by the artifact server) are never deleted. In dry-run mode nothing
+// is deleted and the StorageCleanup events report what would have been.
+//
+// Exported:
+//   - RetentionPolicy: Age, size and session-end limits
+//   - WithRetentionPolicy: Apply a policy when creating an agent
+//   - StorageJanitor / NewStorageJanitor: Periodic sweeper for a set of folders
+//   - RegisterArtifact / UnregisterArtifact: Protect files from cleanup
+
+package mcpagent
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/manishiitg/mcpagent/events"
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+)
+
+// maxCleanupEventPaths caps the paths listed in a StorageCleanup event
+const maxCleanupEventPaths = 20
+
+// RetentionPolicy bounds the files kept in tool output and workspace folders
+type RetentionPolicy struct {
+	// MaxAge deletes files not modified for longer than this (0 = no age limit)
+	MaxAge time.Duration
+	// MaxTotalBytes deletes the oldest files of each folder until it is at or
+	// below this size (0 = no size limit)
+	MaxTotalBytes int64
+	// CleanupSessionOnEnd removes the session's tool output folder when the
+	// session ends, keeping registered artifacts
+	CleanupSessionOnEnd bool
+	// Interval between janitor sweeps (0 = DefaultToolOutputCleanupInterval)
+	Interval time.Duration
+	// DryRun reports what would be deleted without deleting anything
+	DryRun bool
+	// Roots are folders swept besides the agent's tool output folder, e.g.
+	// workspaces. The gRPC server sweeps only these (default: the tool output folder).
+	Roots []string
+}
+
+// sweeps reports whether the policy has limits for a janitor to apply
+func (p RetentionPolicy) sweeps() bool {
+	return p.MaxAge > 0 || p.MaxTotalBytes > 0
+}
+
+// WithRetentionPolicy bounds the agent's offloaded tool outputs and the
+// policy's extra roots by age and total size, swept by a janitor goroutine
+// while the agent is open, and optionally removes the session's tool output
+// folder when the session ends (EndAgentSession or Close). It replaces the
+// WithToolOutputRetentionPeriod cleanup.
+//
+// Example:
+//
+//	mcpagent.WithRetentionPolicy(mcpagent.RetentionPolicy{
+//	    MaxAge:              48 * time.Hour,
+//	    MaxTotalBytes:       2 << 30, // 2 GiB per folder
+//	    CleanupSessionOnEnd: true,
+//	    Roots:               []string{"workspace"},
+//	})
+//
+// Default: nil (Files older than ToolOutputRetentionPeriod are removed hourly)
+func WithRetentionPolicy(policy RetentionPolicy) AgentOption {
+	return func(a *Agent) {
+		a.Retention = &policy
+	}
+}
+
+// artifactRegistry holds the absolute paths protected from cleanup
+var artifactRegistry = struct {
+	sync.RWMutex
+	paths map[string]struct{}
+}{paths: make(map[string]struct{})}
+
+// RegisterArtifact protects the file at path from retention cleanup until
+// UnregisterArtifact is called. Register files handed to users as results.
+func RegisterArtifact(path string) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	artifactRegistry.Lock()
+	artifactRegistry.paths[abs] = struct{}{}
+	artifactRegistry.Unlock()
+	return nil
+}
+
+// UnregisterArtifact makes a registered file subject to retention cleanup again
+func UnregisterArtifact(path string) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return
+	}
+	artifactRegistry.Lock()
+	delete(artifactRegistry.paths, abs)
+	artifactRegistry.Unlock()
+}
+
+func isRegisteredArtifact(path string) bool {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	artifactRegistry.RLock()
+	defer artifactRegistry.RUnlock()
+	_, ok := artifactRegistry.paths[abs]
+	return ok
+}
+
+// StorageJanitor applies a RetentionPolicy's age and size limits to a set of
+// folders, once per Sweep or periodically between Start and Stop
+type StorageJanitor struct {
+	policy RetentionPolicy
+	roots  []string
+	logger loggerv2.Logger
+	emit   func(context.Context, *events.StorageCleanupEvent)
+
+	mu   sync.Mutex
+	stop context.CancelFunc
+	done chan struct{}
+}
+
+// NewStorageJanitor creates a janitor sweeping policy.Roots
+func NewStorageJanitor(policy RetentionPolicy, logger loggerv2.Logger) *StorageJanitor {
+	if logger == nil {
+		logger = loggerv2.NewNoop()
+	}
+	return &StorageJanitor{policy: policy, roots: policy.Roots, logger: logger}
+}
+
+// Start sweeps immediately and then every policy interval until Stop or ctx
+// is canceled. Starting a running janitor does nothing.
+func (j *StorageJanitor) Start(ctx context.Context) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.stop != nil || !j.policy.sweeps() {
+		return
+	}
+	interval := j.policy.Interval
+	if interval <= 0 {
+		interval = DefaultToolOutputCleanupInterval
+	}
+	ctx, j.stop = context.WithCancel(ctx)
+	j.done = make(chan struct{})
+	go func(done chan struct{}) {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			j.Sweep(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}(j.done)
+}
+
+// Stop ends periodic sweeping and waits for a running sweep to finish
+func (j *StorageJanitor) Stop() {
+	j.mu.Lock()
+	stop, done := j.stop, j.done
+	j.stop, j.done = nil, nil
+	j.mu.Unlock()
+	if stop != nil {
+		stop()
+		<-done
+	}
+}
+
+// Sweep applies the age and size limits to every root once and returns a
+// report per root and limit that removed (or in dry-run mode, would remove) files
+func (j *StorageJanitor) Sweep(ctx context.Context) []*events.StorageCleanupEvent {
+	var reports []*events.StorageCleanupEvent
+	for _, root := range j.roots {
+		if ctx.Err() != nil {
+			break
+		}
+		reports = append(reports, j.sweepRoot(ctx, root)...)
+	}
+	return reports
+}
+
+// storedFile is a file considered for cleanup
+type storedFile struct {
+	path      string
+	size      int64
+	modTime   time.Time
+	protected bool
+}
+
+func (j *StorageJanitor) sweepRoot(ctx context.Context, root string) []*events.StorageCleanupEvent {
+	var files []storedFile
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			// Continue on errors for individual files/dirs
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		files = append(files, storedFile{path: path, size: info.Size(), modTime: info.ModTime(), protected: isRegisteredArtifact(path)})
+		return nil
+	})
+	if err != nil || len(files) == 0 {
+		return nil
+	}
+
+	kept := 0
+	for _, f := range files {
+		if f.protected {
+			kept++
+		}
+	}
+
+	var reports []*events.StorageCleanupEvent
+	if j.policy.MaxAge > 0 {
+		cutoff := time.Now().Add(-j.policy.MaxAge)
+		var expired, remaining []storedFile
+		for _, f := range files {
+			if !f.protected && f.modTime.Before(cutoff) {
+				expired = append(expired, f)
+			} else {
+				remaining = append(remaining, f)
+			}
+		}
+		if report := j.removeFiles(ctx, root, "max_age", expired, kept); report != nil {
+			reports = append(reports, report)
+		}
+		files = remaining
+	}
+
+	if j.policy.MaxTotalBytes > 0 {
+		var total int64
+		for _, f := range files {
+			total += f.size
+		}
+		sort.Slice(files, func(a, b int) bool { return files[a].modTime.Before(files[b].modTime) })
+		var oldest []storedFile
+		for _, f := range files {
+			if total <= j.policy.MaxTotalBytes {
+				break
+			}
+			if f.protected {
+				continue
+			}
+			oldest = append(oldest, f)
+			total -= f.size
+		}
+		if report := j.removeFiles(ctx, root, "max_total_size", oldest, kept); report != nil {
+			reports = append(reports, report)
+		}
+	}
+	return reports
+}
+
+// removeFiles deletes files (unless dry-run), logs and emits the report, and
+// returns it; nil when there was nothing to remove. kept counts the
+// registered artifacts spared under root.
+func (j *StorageJanitor) removeFiles(ctx context.Context, root, reason string, files []storedFile, kept int) *events.StorageCleanupEvent {
+	if len(files) == 0 {
+		return nil
+	}
+	report := &events.StorageCleanupEvent{
+		BaseEventData: events.BaseEventData{Timestamp: time.Now()},
+		Root:          root,
+		Reason:        reason,
+		DryRun:        j.policy.DryRun,
+		Kept:          kept,
+	}
+	for _, f := range files {
+		if !j.policy.DryRun {
+			if err := os.Remove(f.path); err != nil && !os.IsNotExist(err) {
+				report.Errors++
+				continue
+			}
+		}
+		report.FilesDeleted++
+		report.BytesFreed += f.size
+		if len(report.Paths) < maxCleanupEventPaths {
+			report.Paths = append(report.Paths, f.path)
+		}
+	}
+	j.report(ctx, report)
+	return report
+}
+
+func (j *StorageJanitor) report(ctx context.Context, report *events.StorageCleanupEvent) {
+	message := "🧹 [RETENTION] Removed files"
+	if report.DryRun {
+		message = "🧹 [RETENTION] Dry run: would remove files"
+	}
+	j.logger.Info(message,
+		loggerv2.String("root", report.Root),
+		loggerv2.String("reason", report.Reason),
+		loggerv2.Int("files", report.FilesDeleted),
+		loggerv2.Any("bytes", report.BytesFreed),
+		loggerv2.Int("kept_artifacts", report.Kept),
+		loggerv2.Int("errors", report.Errors))
+	if j.emit != nil {
+		j.emit(ctx, report)
+	}
+}
+
+// startRetentionJanitor starts the agent's janitor over its tool output
+// folder and the policy's roots
+func (a *Agent) startRetentionJanitor() {
+	if !a.Retention.sweeps() {
+		return
+	}
+	policy := *a.Retention
+	roots := append([]string(nil), policy.Roots...)
+	if a.toolOutputHandler != nil && a.toolOutputHandler.GetToolOutputFolder() != "" {
+		roots = append([]string{a.toolOutputHandler.GetToolOutputFolder()}, roots...)
+	}
+	policy.Roots = roots
+	a.janitor = NewStorageJanitor(policy, getLogger(a))
+	a.janitor.emit = func(ctx context.Context, ev *events.StorageCleanupEvent) { a.EmitTypedEvent(ctx, ev) }
+	a.janitor.Start(context.Background())
+}
+
+// cleanupSessionStorage removes the session's tool output folder when the
+// policy asks for it, keeping registered artifacts (and their folders)
+func (a *Agent) cleanupSessionStorage(ctx context.Context) {
+	if a.Retention == nil || !a.Retention.CleanupSessionOnEnd || a.toolOutputHandler == nil {
+		return
+	}
+	sessionID := a.toolOutputHandler.GetSessionID()
+	if sessionID == "" || a.toolOutputHandler.GetToolOutputFolder() == "" {
+		return
+	}
+	folder := filepath.Join(a.toolOutputHandler.GetToolOutputFolder(), sessionID)
+	if _, err := os.Stat(folder); err != nil {
+		return
+	}
+
+	janitor := NewStorageJanitor(*a.Retention, getLogger(a))
+	janitor.emit = func(ctx context.Context, ev *events.StorageCleanupEvent) { a.EmitTypedEvent(ctx, ev) }
+	var files []storedFile
+	kept := 0
+	_ = filepath.WalkDir(folder, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		if isRegisteredArtifact(path) {
+			kept++
+			return nil
+		}
+		var size int64
+		if info, infoErr := d.Info(); infoErr == nil {
+			size = info.Size()
+		}
+		files = append(files, storedFile{path: path, size: size})
+		return nil
+	})
+	if len(files) == 0 {
+		return
+	}
+
+	if report := janitor.removeFiles(ctx, folder, "session_end", files, kept); report != nil && !report.DryRun {
+		removeEmptyDirs(folder)
+	}
+}
+
+// removeEmptyDirs removes empty directories under and including dir, deepest first
+func removeEmptyDirs(dir string) {
+	var dirs []string
+	_ = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err == nil && d.IsDir() {
+			dirs = append(dirs, path)
+		}
+		return nil
+	})
+	for i := len(dirs) - 1; i >= 0; i-- {
+		_ = os.Remove(dirs[i]) // fails (and is skipped) when not empty
+	}
+}
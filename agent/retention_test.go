@@ -0,0 +1,112 @@
+package mcpagent
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/manishiitg/mcpagent/events"
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+)
+
+func writeAgedFile(t *testing.T, path string, size int, age time.Duration) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, make([]byte, size), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	modTime := time.Now().Add(-age)
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestStorageJanitorSweepsByAgeAndSize(t *testing.T) {
+	root := t.TempDir()
+	stale := filepath.Join(root, "s1", "stale.json")
+	artifact := filepath.Join(root, "s1", "report.pdf")
+	older := filepath.Join(root, "s2", "older.json")
+	newer := filepath.Join(root, "s2", "newer.json")
+	writeAgedFile(t, stale, 10, 3*time.Hour)
+	writeAgedFile(t, artifact, 10, 3*time.Hour)
+	writeAgedFile(t, older, 100, 20*time.Minute)
+	writeAgedFile(t, newer, 100, 10*time.Minute)
+	if err := RegisterArtifact(artifact); err != nil {
+		t.Fatal(err)
+	}
+	defer UnregisterArtifact(artifact)
+
+	janitor := NewStorageJanitor(RetentionPolicy{MaxAge: time.Hour, MaxTotalBytes: 150, Roots: []string{root}}, loggerv2.NewNoop())
+	reports := janitor.Sweep(context.Background())
+
+	reasons := map[string]int{}
+	for _, report := range reports {
+		reasons[report.Reason] += report.FilesDeleted
+	}
+	if reasons["max_age"] != 1 || reasons["max_total_size"] != 1 {
+		t.Errorf("unexpected reports: %v", reasons)
+	}
+	for path, want := range map[string]bool{stale: false, artifact: true, older: false, newer: true} {
+		if _, err := os.Stat(path); (err == nil) != want {
+			t.Errorf("%s: exists = %v, want %v", filepath.Base(path), err == nil, want)
+		}
+	}
+}
+
+func TestStorageJanitorDryRunKeepsFiles(t *testing.T) {
+	root := t.TempDir()
+	stale := filepath.Join(root, "stale.json")
+	writeAgedFile(t, stale, 10, 3*time.Hour)
+
+	janitor := NewStorageJanitor(RetentionPolicy{MaxAge: time.Hour, DryRun: true, Roots: []string{root}}, loggerv2.NewNoop())
+	reports := janitor.Sweep(context.Background())
+	if len(reports) != 1 || !reports[0].DryRun || reports[0].FilesDeleted != 1 {
+		t.Fatalf("unexpected reports: %+v", reports)
+	}
+	if _, err := os.Stat(stale); err != nil {
+		t.Error("dry run should not delete files")
+	}
+}
+
+func TestCleanupSessionStorageKeepsArtifacts(t *testing.T) {
+	folder := t.TempDir()
+	handler := NewToolOutputHandler()
+	handler.OutputFolder = folder
+	handler.SetSessionID("session-1")
+	a := &Agent{Logger: loggerv2.NewNoop(), toolOutputHandler: handler}
+	WithRetentionPolicy(RetentionPolicy{CleanupSessionOnEnd: true})(a)
+	listener := &recordingAgentEventListener{}
+	a.AddEventListener(listener)
+
+	output := filepath.Join(folder, "session-1", "tool_output.json")
+	artifact := filepath.Join(folder, "session-1", "exports", "result.csv")
+	other := filepath.Join(folder, "session-2", "tool_output.json")
+	writeAgedFile(t, output, 10, 0)
+	writeAgedFile(t, artifact, 10, 0)
+	writeAgedFile(t, other, 10, 0)
+	if err := RegisterArtifact(artifact); err != nil {
+		t.Fatal(err)
+	}
+	defer UnregisterArtifact(artifact)
+
+	a.cleanupSessionStorage(context.Background())
+
+	for path, want := range map[string]bool{output: false, artifact: true, other: true} {
+		if _, err := os.Stat(path); (err == nil) != want {
+			t.Errorf("%s: exists = %v, want %v", path, err == nil, want)
+		}
+	}
+	var report *events.StorageCleanupEvent
+	for _, event := range listener.events {
+		if ev, ok := event.Data.(*events.StorageCleanupEvent); ok {
+			report = ev
+		}
+	}
+	if report == nil || report.Reason != "session_end" || report.FilesDeleted != 1 || report.Kept != 1 {
+		t.Errorf("unexpected cleanup event: %+v", report)
+	}
+}
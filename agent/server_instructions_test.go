@@ -0,0 +1,43 @@
+package mcpagent
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/manishiitg/mcpagent/agent/prompt"
+)
+
+func TestWithServerInstructions(t *testing.T) {
+	a := &Agent{
+		IncludeServerInstructions: true,
+		serverInstructions: map[string]string{
+			"playwright": "Call browser_snapshot before clicking.",
+			"fs":         "Paths are relative to the workspace.",
+			"jira":       strings.Repeat("x", prompt.MaxServerInstructionsLength+100),
+		},
+	}
+
+	all := a.withServerInstructions("BASE", nil)
+	if !strings.HasPrefix(all, "BASE\n") || !strings.Contains(all, "<server_instructions>") {
+		t.Fatalf("instructions section not appended:\n%s", all)
+	}
+	if strings.Index(all, "### fs") > strings.Index(all, "### playwright") {
+		t.Error("servers should be listed in name order")
+	}
+	if !strings.Contains(all, "[instructions truncated]") {
+		t.Error("long instructions should be truncated")
+	}
+
+	scoped := a.withServerInstructions("BASE", []string{"playwright"})
+	if !strings.Contains(scoped, "browser_snapshot") || strings.Contains(scoped, "### fs") {
+		t.Errorf("only the given servers should be included:\n%s", scoped)
+	}
+	if got := a.withServerInstructions("BASE", []string{"github"}); got != "BASE" {
+		t.Errorf("servers without instructions should add nothing, got %q", got)
+	}
+
+	a.IncludeServerInstructions = false
+	if got := a.withServerInstructions("BASE", nil); got != "BASE" {
+		t.Errorf("disabled instructions should leave the prompt unchanged, got %q", got)
+	}
+}
@@ -0,0 +1,195 @@
+// session_store.go
+//
+// This file provides persistent conversation sessions. When an agent is
+// created WithSessionStore, the conversation history, cumulative token usage
+// and context summaries are saved to a ConversationStore after every
+// AskWithHistory call, so a conversation survives process restarts: a new
+// agent calls ResumeSession with the session ID to get the history back and
+// continue.
+//
+// Sessions and autosave checkpoints (see autosave.go) share the store and the
+// record type: a checkpoint protects one in-flight call and is deleted when
+// it completes, while a session outlives calls and is kept, marked
+// Completed, until the caller deletes it. With both enabled the checkpoint of
+// a running call is saved under the session ID, so a crash leaves the session
+// resumable with RecoverConversations or ResumeSession.
+//
+// Exported:
+//   - SessionTokenUsage: Token usage saved with a session
+//   - WithSessionStore: Persist sessions when creating an agent
+//   - Agent.ResumeSession / Agent.ConversationSessionID
+
+package mcpagent
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+// SessionTokenUsage is the cumulative token usage of a session
+type SessionTokenUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+	CacheTokens      int `json:"cache_tokens,omitempty"`
+	ReasoningTokens  int `json:"reasoning_tokens,omitempty"`
+	LLMCallCount     int `json:"llm_call_count"`
+}
+
+// WithSessionStore persists the conversation session (history, token usage
+// and summaries) to store after every AskWithHistory call, under
+// ConversationSessionID. Use Agent.ResumeSession to continue a stored session
+// after a restart.
+//
+// Example:
+//
+//	store, _ := mcpagent.NewFileConversationStore("data/sessions")
+//	agent, _ := mcpagent.NewAgent(ctx, llm, configPath, mcpagent.WithSessionStore(store))
+//	history, err := agent.ResumeSession(ctx, "support-chat-42")
+//
+// Default: nil (History only lives in the caller's message slice)
+func WithSessionStore(store ConversationStore) AgentOption {
+	return func(a *Agent) {
+		a.SessionStore = store
+	}
+}
+
+// ConversationSessionID returns the ID under which the conversation session
+// is stored: the resumed session's ID, else the agent's SessionID, else its
+// trace ID.
+func (a *Agent) ConversationSessionID() string {
+	a.sessionStateMu.Lock()
+	defer a.sessionStateMu.Unlock()
+	return a.conversationSessionIDLocked()
+}
+
+func (a *Agent) conversationSessionIDLocked() string {
+	if a.storedSessionID == "" {
+		a.storedSessionID = a.SessionID
+		if a.storedSessionID == "" {
+			a.storedSessionID = string(a.TraceID)
+		}
+	}
+	return a.storedSessionID
+}
+
+// ResumeSession loads the stored session sessionID and makes it this agent's
+// session: later calls save under sessionID, token usage continues from the
+// stored totals and summaries are carried over. It returns the stored
+// history; append the next user message to it and call AskWithHistory. A
+// session whose last call was interrupted returns the history up to its last
+// checkpoint. Unknown IDs, and sessions of another user (see WithUserID),
+// fail with ErrCheckpointNotFound.
+func (a *Agent) ResumeSession(ctx context.Context, sessionID string) ([]llmtypes.MessageContent, error) {
+	if a.SessionStore == nil {
+		return nil, errors.New("no session store configured (see WithSessionStore)")
+	}
+	session, err := a.SessionStore.Load(ctx, userConversationID(a.UserID, sessionID))
+	if err != nil {
+		return nil, err
+	}
+	if session.UserID != a.UserID {
+		return nil, ErrCheckpointNotFound
+	}
+	a.adoptConversation(session)
+
+	getLogger(a).Info("Resumed conversation session",
+		loggerv2.String("session_id", session.ID),
+		loggerv2.Int("messages", len(session.Messages)))
+	return session.Messages, nil
+}
+
+// adoptConversation makes the stored conversation record this agent's
+// session and checkpoint: later saves go under its ID, and token usage and
+// summaries continue from it. The record belongs to the agent's user.
+func (a *Agent) adoptConversation(record *ConversationCheckpoint) {
+	a.checkpointMu.Lock()
+	a.autosaveID, a.lastCheckpointID = record.ID, record.ID
+	a.checkpointMu.Unlock()
+	a.sessionStateMu.Lock()
+	a.storedSessionID = strings.TrimPrefix(record.ID, userConversationID(a.UserID, ""))
+	a.sessionCreatedAt = record.CreatedAt
+	a.sessionSummaries = append([]string(nil), record.Summaries...)
+	a.sessionStateMu.Unlock()
+
+	a.tokenTrackingMutex.Lock()
+	a.cumulativePromptTokens = record.TokenUsage.PromptTokens
+	a.cumulativeCompletionTokens = record.TokenUsage.CompletionTokens
+	a.cumulativeTotalTokens = record.TokenUsage.TotalTokens
+	a.cumulativeCacheTokens = record.TokenUsage.CacheTokens
+	a.cumulativeReasoningTokens = record.TokenUsage.ReasoningTokens
+	a.llmCallCount = record.TokenUsage.LLMCallCount
+	a.tokenTrackingMutex.Unlock()
+}
+
+// conversationRecord returns the stored state of the conversation under id:
+// messages and the session's token usage, summaries and experiment
+func (a *Agent) conversationRecord(id string, messages []llmtypes.MessageContent) *ConversationCheckpoint {
+	now := time.Now()
+	a.sessionStateMu.Lock()
+	if a.sessionCreatedAt.IsZero() {
+		a.sessionCreatedAt = now
+	}
+	record := &ConversationCheckpoint{
+		ID:                id,
+		SessionID:         a.SessionID,
+		UserID:            a.UserID,
+		Owner:             a.CheckpointOwner,
+		Provider:          string(a.provider),
+		ModelID:           a.ModelID,
+		Messages:          append([]llmtypes.MessageContent(nil), messages...),
+		Summaries:         append([]string(nil), a.sessionSummaries...),
+		Experiment:        a.experimentName,
+		ExperimentVariant: a.experimentVariant,
+		CreatedAt:         a.sessionCreatedAt,
+		UpdatedAt:         now,
+	}
+	a.sessionStateMu.Unlock()
+
+	promptTokens, completionTokens, totalTokens, cacheTokens, reasoningTokens, llmCallCount, _ := a.GetTokenUsage()
+	record.TokenUsage = SessionTokenUsage{
+		PromptTokens:     promptTokens,
+		CompletionTokens: completionTokens,
+		TotalTokens:      totalTokens,
+		CacheTokens:      cacheTokens,
+		ReasoningTokens:  reasoningTokens,
+		LLMCallCount:     llmCallCount,
+	}
+	return record
+}
+
+// recordSessionSummary keeps a context summary for the stored session
+func (a *Agent) recordSessionSummary(summary string) {
+	if a.SessionStore == nil {
+		return
+	}
+	a.sessionStateMu.Lock()
+	a.sessionSummaries = append(a.sessionSummaries, summary)
+	a.sessionStateMu.Unlock()
+}
+
+// saveSession stores the session after a call, marked Completed unless the
+// call failed. Failures are logged, never returned: persistence must not fail
+// the conversation it records.
+func (a *Agent) saveSession(ctx context.Context, messages []llmtypes.MessageContent, convErr error) {
+	if a.SessionStore == nil || len(messages) == 0 {
+		return
+	}
+	session := a.conversationRecord(userConversationID(a.UserID, a.ConversationSessionID()), messages)
+	session.Question = lastUserText(messages)
+	session.Completed = convErr == nil
+	if convErr != nil {
+		session.LastError = convErr.Error()
+	}
+
+	// Save even if the conversation context was canceled
+	if err := a.SessionStore.Save(context.WithoutCancel(ctx), session); err != nil {
+		getLogger(a).Warn("Failed to save conversation session", loggerv2.String("session_id", session.ID), loggerv2.Error(err))
+	}
+}
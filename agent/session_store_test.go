@@ -0,0 +1,141 @@
+package mcpagent
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+func TestConversationStoresRoundTripSessions(t *testing.T) {
+	fileStore, err := NewFileConversationStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, store := range map[string]ConversationStore{"file": fileStore, "memory": NewMemoryConversationStore()} {
+		ctx := context.Background()
+		if _, err := store.Load(ctx, "missing"); !errors.Is(err, ErrCheckpointNotFound) {
+			t.Errorf("%s: Load(missing) = %v, want ErrCheckpointNotFound", name, err)
+		}
+		session := &ConversationCheckpoint{
+			ID:         "chat-1",
+			Messages:   []llmtypes.MessageContent{llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "hello")},
+			TokenUsage: SessionTokenUsage{TotalTokens: 42, LLMCallCount: 1},
+			Summaries:  []string{"greeted"},
+			Completed:  true,
+		}
+		if err := store.Save(ctx, session); err != nil {
+			t.Fatalf("%s: Save: %v", name, err)
+		}
+		session.Messages[0] = llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "mutated")
+
+		loaded, err := store.Load(ctx, "chat-1")
+		if err != nil {
+			t.Fatalf("%s: Load: %v", name, err)
+		}
+		if got := loaded.Messages[0].Parts[0].(llmtypes.TextContent).Text; got != "hello" || loaded.TokenUsage.TotalTokens != 42 || loaded.Summaries[0] != "greeted" {
+			t.Errorf("%s: unexpected session %+v", name, loaded)
+		}
+		if sessions, _ := store.List(ctx); len(sessions) != 1 {
+			t.Errorf("%s: List returned %d sessions", name, len(sessions))
+		}
+		if recoverable, _ := RecoverConversations(ctx, store); len(recoverable) != 0 {
+			t.Errorf("%s: completed session listed as recoverable", name)
+		}
+		if err := store.Delete(ctx, "chat-1"); err != nil {
+			t.Fatalf("%s: Delete: %v", name, err)
+		}
+		if sessions, _ := store.List(ctx); len(sessions) != 0 {
+			t.Errorf("%s: session not deleted", name)
+		}
+	}
+}
+
+func TestResumeSessionContinuesHistoryAndUsage(t *testing.T) {
+	store := NewMemoryConversationStore()
+	newAgent := func(answer string) *Agent {
+		a := &Agent{Logger: loggerv2.NewNoop(), LLM: &providerKeyCarrierModel{}, ModelID: "test-model", MaxTurns: 5, SessionID: "support-42"}
+		WithSessionStore(store)(a)
+		WithAskPipeline(AskPipeline{Generate: &scriptedGenerateStage{responses: []*llmtypes.ContentResponse{
+			{Choices: []*llmtypes.ContentChoice{{Content: answer}}},
+		}}})(a)
+		return a
+	}
+
+	first := newAgent("Hi, how can I help?")
+	if _, _, err := first.AskWithHistory(context.Background(), []llmtypes.MessageContent{
+		llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "hello"),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	first.tokenTrackingMutex.Lock()
+	first.cumulativeTotalTokens = 100
+	first.tokenTrackingMutex.Unlock()
+	first.recordSessionSummary("user greeted")
+	first.saveSession(context.Background(), []llmtypes.MessageContent{
+		llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "hello"),
+		llmtypes.TextParts(llmtypes.ChatMessageTypeAI, "Hi, how can I help?"),
+	}, nil)
+
+	// A new agent (as after a restart) picks the session up
+	second := newAgent("Your order ships tomorrow.")
+	second.SessionID = ""
+	history, err := second.ResumeSession(context.Background(), "support-42")
+	if err != nil {
+		t.Fatalf("ResumeSession: %v", err)
+	}
+	if len(history) != 2 || second.ConversationSessionID() != "support-42" {
+		t.Fatalf("unexpected resumed history %d / id %q", len(history), second.ConversationSessionID())
+	}
+	if _, _, total, _, _, _, _ := second.GetTokenUsage(); total != 100 {
+		t.Errorf("token usage should continue from the stored totals, got %d", total)
+	}
+
+	history = append(history, llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "where is my order?"))
+	if _, _, err := second.AskWithHistory(context.Background(), history); err != nil {
+		t.Fatal(err)
+	}
+	stored, err := store.Load(context.Background(), "support-42")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stored.Messages) < 4 || stored.TokenUsage.TotalTokens < 100 || len(stored.Summaries) != 1 {
+		t.Errorf("session not updated after the resumed call: %d messages, usage %+v, summaries %v",
+			len(stored.Messages), stored.TokenUsage, stored.Summaries)
+	}
+	if sessions, _ := store.List(context.Background()); len(sessions) != 1 {
+		t.Errorf("expected one session, got %d", len(sessions))
+	}
+}
+
+func TestResumeSessionRequiresStore(t *testing.T) {
+	a := &Agent{Logger: loggerv2.NewNoop()}
+	if _, err := a.ResumeSession(context.Background(), "x"); err == nil {
+		t.Error("expected an error without a session store")
+	}
+}
+
+func TestSessionAndAutosaveShareOneRecord(t *testing.T) {
+	store := NewMemoryConversationStore()
+	a := &Agent{Logger: loggerv2.NewNoop(), LLM: &providerKeyCarrierModel{}, ModelID: "test-model", MaxTurns: 5, SessionID: "support-7", TraceID: "trace-1"}
+	WithSessionStore(store)(a)
+	WithAutosave(store, 1)(a)
+	messages := []llmtypes.MessageContent{llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "hello")}
+	ctx := context.Background()
+
+	a.autosave(ctx, messages, 1, "hello")
+	if recoverable, _ := RecoverConversations(ctx, store); len(recoverable) != 1 || recoverable[0].ID != "support-7" {
+		t.Fatalf("in-flight checkpoint should be saved under the session ID, got %+v", recoverable)
+	}
+	a.finishAutosave(ctx, messages, nil)
+	a.saveSession(ctx, messages, nil)
+	all, _ := store.List(ctx)
+	if len(all) != 1 || !all[0].Completed {
+		t.Fatalf("expected one completed record, got %+v", all)
+	}
+	if recoverable, _ := RecoverConversations(ctx, store); len(recoverable) != 0 {
+		t.Errorf("completed session listed as recoverable: %+v", recoverable)
+	}
+}
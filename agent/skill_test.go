@@ -1,6 +1,7 @@
 package mcpagent
 
 import (
+	"context"
 	"strings"
 	"testing"
 
@@ -93,7 +94,7 @@ func TestEnsureSystemPromptAppendsAttachedSkills(t *testing.T) {
 	a := &Agent{systemPrompt: "BASE PROMPT"}
 	a.AttachSkill(&llmtypes.Skill{Name: "pdf-extract", Description: "Extract PDFs"})
 
-	out := ensureSystemPrompt(a, nil)
+	out := ensureSystemPrompt(context.Background(), a, nil)
 	if len(out) == 0 || out[0].Role != llmtypes.ChatMessageTypeSystem {
 		t.Fatalf("expected leading system message, got %+v", out)
 	}
@@ -124,7 +125,7 @@ func TestEnsureSystemPromptAppendsAttachedSkills(t *testing.T) {
 func TestEnsureSystemPromptWithoutAttachedSkillsLeavesBaseUntouched(t *testing.T) {
 	a := &Agent{systemPrompt: "JUST THE BASE"}
 
-	out := ensureSystemPrompt(a, nil)
+	out := ensureSystemPrompt(context.Background(), a, nil)
 	if len(out) == 0 {
 		t.Fatalf("expected at least one message")
 	}
@@ -158,7 +159,7 @@ func TestEnsureSystemPromptReplacesExistingSystemMessage(t *testing.T) {
 		},
 	}
 
-	out := ensureSystemPrompt(a, stale)
+	out := ensureSystemPrompt(context.Background(), a, stale)
 	if len(out) != 2 {
 		t.Fatalf("expected 2 messages (system replaced + human kept), got %d", len(out))
 	}
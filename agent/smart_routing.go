@@ -0,0 +1,395 @@
+// smart_routing.go
+//
+// This file provides smart routing: choosing which MCP servers' tools are
+// offered to the LLM for a conversation, so agents connected to many servers
+// do not send every tool definition on every call. The route stage embeds
+// the conversation's latest question and a profile of each server (name,
+// description, tool names and descriptions), and keeps the servers most
+// similar to the question. When a router model is configured and several
+// servers pass the embedding pre-filter, the router picks among those
+// candidates only, from a truncated view of the conversation.
+//
+// Decisions are kept per session: asking the same question again in a
+// session reuses the earlier decision without embedding or router calls.
+// Server profile embeddings are computed once and recomputed only when a
+// server's tools change (e.g. after a config reload). Custom and virtual
+// tools are always offered. Every decision is reported in a RoutingDecision
+// event with the similarity scores.
+//
+// Exported:
+//   - SmartRoutingConfig: Embedding function, candidate limits and router model
+//   - WithSmartRouting: Enable smart routing when creating an agent
+
+package mcpagent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/manishiitg/mcpagent/events"
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+	"github.com/manishiitg/mcpagent/mcpclient"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+// Smart routing defaults
+const (
+	DefaultSmartRoutingMaxServers    = 3
+	DefaultSmartRoutingMaxQueryChars = 2000
+
+	// smartRoutingMaxProfileChars caps the server profile that is embedded
+	smartRoutingMaxProfileChars = 4000
+	// smartRoutingMaxDecisions caps the decisions kept across sessions
+	smartRoutingMaxDecisions = 1024
+)
+
+// SmartRoutingConfig configures smart routing
+type SmartRoutingConfig struct {
+	// Embed embeds the question and the server profiles (required)
+	Embed EmbedFunc
+	// MaxServers is the number of servers the embedding pre-filter keeps
+	// (0 = DefaultSmartRoutingMaxServers)
+	MaxServers int
+	// MinScore drops servers whose similarity to the question is lower;
+	// 0 keeps the MaxServers best whatever their score
+	MinScore float64
+	// Router picks the servers among the candidates with an LLM call; a
+	// small model is enough (nil = the embedding candidates are offered)
+	Router llmtypes.Model
+	// MaxQueryChars caps the question embedded and the conversation text
+	// sent to the router (0 = DefaultSmartRoutingMaxQueryChars)
+	MaxQueryChars int
+}
+
+// smartRouter holds the embedded server profiles and the decisions made
+type smartRouter struct {
+	config SmartRoutingConfig
+
+	mu        sync.Mutex
+	profiles  map[string]serverProfile // By server name
+	decisions map[string][]string      // Selected servers by session and question
+	order     []string                 // Decision keys, oldest first
+}
+
+// serverProfile is the embedded description of a server
+type serverProfile struct {
+	text   string
+	vector []float32
+}
+
+// WithSmartRouting offers the LLM only the tools of the MCP servers relevant
+// to each conversation, chosen by embedding similarity and optionally a
+// router model. Has no effect on agents whose tools fit within MaxServers
+// servers, and in tool search and code execution modes.
+//
+// Example:
+//
+//	mcpagent.WithSmartRouting(mcpagent.SmartRoutingConfig{
+//	    Embed:      embedTexts,
+//	    MaxServers: 4,
+//	    Router:     cheapLLM,
+//	})
+//
+// Default: disabled
+func WithSmartRouting(config SmartRoutingConfig) AgentOption {
+	return func(a *Agent) {
+		if config.Embed == nil {
+			return
+		}
+		if config.MaxServers <= 0 {
+			config.MaxServers = DefaultSmartRoutingMaxServers
+		}
+		if config.MaxQueryChars <= 0 {
+			config.MaxQueryChars = DefaultSmartRoutingMaxQueryChars
+		}
+		a.smartRouter = &smartRouter{
+			config:    config,
+			profiles:  make(map[string]serverProfile),
+			decisions: make(map[string][]string),
+		}
+	}
+}
+
+// applySmartRouting drops the MCP tools of servers smart routing did not
+// select for the conversation in messages
+func (a *Agent) applySmartRouting(ctx context.Context, messages []llmtypes.MessageContent, tools []llmtypes.Tool) []llmtypes.Tool {
+	r := a.smartRouter
+	if r == nil {
+		return tools
+	}
+	serverTools := make(map[string][]llmtypes.Tool)
+	for _, tool := range tools {
+		if tool.Function == nil {
+			continue
+		}
+		if server, ok := a.toolToServer[tool.Function.Name]; ok && server != "custom" {
+			serverTools[server] = append(serverTools[server], tool)
+		}
+	}
+	if len(serverTools) <= r.config.MaxServers {
+		return tools
+	}
+	query := truncateUTF8(strings.TrimSpace(lastUserText(messages)), r.config.MaxQueryChars)
+	if query == "" {
+		return tools
+	}
+
+	selected, err := a.routeServers(ctx, messages, query, serverTools)
+	if err != nil {
+		getLogger(a).Warn("🧭 [ROUTING] Smart routing failed; offering all tools", loggerv2.Error(err))
+		return tools
+	}
+	keep := make(map[string]bool, len(selected))
+	for _, server := range selected {
+		keep[server] = true
+	}
+	routed := make([]llmtypes.Tool, 0, len(tools))
+	for _, tool := range tools {
+		if tool.Function != nil {
+			if server, ok := a.toolToServer[tool.Function.Name]; ok && server != "custom" && !keep[server] {
+				continue
+			}
+		}
+		routed = append(routed, tool)
+	}
+	return routed
+}
+
+// routeServers returns the servers to offer for query, from the session's
+// earlier decision when there is one, and emits a RoutingDecision event
+func (a *Agent) routeServers(ctx context.Context, messages []llmtypes.MessageContent, query string, serverTools map[string][]llmtypes.Tool) ([]string, error) {
+	r := a.smartRouter
+	startTime := time.Now()
+	event := &events.RoutingDecisionEvent{
+		BaseEventData: events.BaseEventData{Timestamp: startTime},
+		Query:         query,
+	}
+	key := a.SessionID + "\x00" + strings.ToLower(strings.Join(strings.Fields(query), " "))
+	if selected, ok := r.decision(key); ok {
+		event.Selected, event.Method = selected, "cached"
+		event.Duration = time.Since(startTime)
+		a.EmitTypedEvent(ctx, event)
+		return selected, nil
+	}
+
+	scores, err := r.score(ctx, a, query, serverTools)
+	if err != nil {
+		return nil, err
+	}
+	event.Scores = scores
+	for _, score := range scores {
+		if len(event.Candidates) == r.config.MaxServers {
+			break
+		}
+		if r.config.MinScore > 0 && score.Score < r.config.MinScore {
+			break
+		}
+		event.Candidates = append(event.Candidates, score.Server)
+	}
+	event.Selected, event.Method = event.Candidates, "embedding"
+	if r.config.Router != nil && len(event.Candidates) > 1 {
+		picked, err := r.askRouter(ctx, messages, event.Candidates, serverTools, a.serverConfigs)
+		if err != nil {
+			event.Error = err.Error()
+			getLogger(a).Warn("🧭 [ROUTING] Router call failed; offering the embedding candidates", loggerv2.Error(err))
+		} else {
+			event.Selected, event.Method = picked, "llm"
+		}
+	}
+	if event.Selected == nil {
+		event.Selected = []string{}
+	}
+	r.remember(key, event.Selected)
+
+	event.Duration = time.Since(startTime)
+	getLogger(a).Info("🧭 [ROUTING] Routed conversation",
+		loggerv2.Any("selected", event.Selected),
+		loggerv2.String("method", event.Method),
+		loggerv2.Int("servers", len(serverTools)))
+	a.EmitTypedEvent(ctx, event)
+	return event.Selected, nil
+}
+
+// score returns the similarity of every server to query, best first.
+// Profiles are embedded in the same call as the query when they changed.
+func (r *smartRouter) score(ctx context.Context, a *Agent, query string, serverTools map[string][]llmtypes.Tool) ([]events.RoutingScore, error) {
+	servers := make([]string, 0, len(serverTools))
+	for server := range serverTools {
+		servers = append(servers, server)
+	}
+	sort.Strings(servers)
+
+	texts := []string{query}
+	var stale []string
+	r.mu.Lock()
+	profiles := make(map[string]serverProfile, len(servers))
+	for _, server := range servers {
+		text := serverProfileText(server, a.serverConfigs[server].Description, serverTools[server])
+		if profile, ok := r.profiles[server]; ok && profile.text == text {
+			profiles[server] = profile
+			continue
+		}
+		profiles[server] = serverProfile{text: text}
+		stale = append(stale, server)
+		texts = append(texts, text)
+	}
+	r.mu.Unlock()
+
+	vectors, err := r.config.Embed(ctx, texts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed: %w", err)
+	}
+	if len(vectors) != len(texts) {
+		return nil, fmt.Errorf("embed returned %d vectors for %d texts", len(vectors), len(texts))
+	}
+	r.mu.Lock()
+	for i, server := range stale {
+		profile := profiles[server]
+		profile.vector = vectors[i+1]
+		profiles[server] = profile
+		r.profiles[server] = profile
+	}
+	r.mu.Unlock()
+
+	scores := make([]events.RoutingScore, 0, len(servers))
+	for _, server := range servers {
+		scores = append(scores, events.RoutingScore{Server: server, Score: cosineSimilarity(vectors[0], profiles[server].vector)})
+	}
+	sort.SliceStable(scores, func(i, j int) bool { return scores[i].Score > scores[j].Score })
+	return scores, nil
+}
+
+// serverProfileText describes a server for embedding and for the router
+func serverProfileText(server, description string, tools []llmtypes.Tool) string {
+	var b strings.Builder
+	b.WriteString(server)
+	if description != "" {
+		b.WriteString(": " + description)
+	}
+	names := make([]string, 0, len(tools))
+	descriptions := make(map[string]string, len(tools))
+	for _, tool := range tools {
+		names = append(names, tool.Function.Name)
+		descriptions[tool.Function.Name] = tool.Function.Description
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		b.WriteString("\n- " + name)
+		if d := strings.TrimSpace(descriptions[name]); d != "" {
+			b.WriteString(": " + strings.SplitN(d, "\n", 2)[0])
+		}
+	}
+	return truncateUTF8(b.String(), smartRoutingMaxProfileChars)
+}
+
+// smartRoutingPrompt instructs the router model
+const smartRoutingPrompt = `You route a conversation to the tool servers an AI assistant needs to continue it.
+
+Pick every server whose tools the assistant is likely to need, and no others. If none is needed, pick none.
+
+Respond with ONLY a JSON object, without markdown formatting:
+{"servers": ["<server name>", ...]}`
+
+// askRouter asks the router model to pick among candidates. Names it
+// returns that are not candidates are ignored.
+func (r *smartRouter) askRouter(ctx context.Context, messages []llmtypes.MessageContent, candidates []string, serverTools map[string][]llmtypes.Tool, configs map[string]mcpclient.MCPServerConfig) ([]string, error) {
+	var b strings.Builder
+	b.WriteString("Servers:\n\n")
+	for _, server := range candidates {
+		b.WriteString(serverProfileText(server, configs[server].Description, serverTools[server]) + "\n\n")
+	}
+	b.WriteString("Conversation (most recent last):\n\n" + conversationTail(messages, r.config.MaxQueryChars))
+
+	routerMessages := []llmtypes.MessageContent{
+		llmtypes.TextParts(llmtypes.ChatMessageTypeSystem, smartRoutingPrompt),
+		llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, b.String()),
+	}
+	resp, err := r.config.Router.GenerateContent(ctx, routerMessages, llmtypes.WithTemperature(0), llmtypes.WithJSONMode())
+	if err != nil {
+		return nil, err
+	}
+	if resp == nil || len(resp.Choices) == 0 || resp.Choices[0] == nil {
+		return nil, fmt.Errorf("router returned no response")
+	}
+	content := resp.Choices[0].Content
+	start, end := strings.Index(content, "{"), strings.LastIndex(content, "}")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("router response is not JSON")
+	}
+	var parsed struct {
+		Servers []string `json:"servers"`
+	}
+	if err := json.Unmarshal([]byte(content[start:end+1]), &parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse router response: %w", err)
+	}
+	picked := []string{}
+	for _, server := range candidates {
+		for _, name := range parsed.Servers {
+			if strings.EqualFold(strings.TrimSpace(name), server) {
+				picked = append(picked, server)
+				break
+			}
+		}
+	}
+	return picked, nil
+}
+
+// conversationTail renders the text of the user and assistant messages,
+// keeping the most recent maxChars
+func conversationTail(messages []llmtypes.MessageContent, maxChars int) string {
+	var lines []string
+	for _, msg := range messages {
+		role := ""
+		switch msg.Role {
+		case llmtypes.ChatMessageTypeHuman:
+			role = "User"
+		case llmtypes.ChatMessageTypeAI:
+			role = "Assistant"
+		default:
+			continue
+		}
+		for _, part := range msg.Parts {
+			if text, ok := part.(llmtypes.TextContent); ok && strings.TrimSpace(text.Text) != "" {
+				lines = append(lines, role+": "+strings.TrimSpace(text.Text))
+			}
+		}
+	}
+	tail := strings.Join(lines, "\n")
+	if len(tail) > maxChars {
+		start := len(tail) - maxChars
+		for start < len(tail) && !utf8.RuneStart(tail[start]) {
+			start++
+		}
+		tail = tail[start:]
+	}
+	return tail
+}
+
+// decision returns the servers selected earlier for key
+func (r *smartRouter) decision(key string) ([]string, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	selected, ok := r.decisions[key]
+	return selected, ok
+}
+
+// remember keeps the servers selected for key, dropping the oldest decision
+// past smartRoutingMaxDecisions
+func (r *smartRouter) remember(key string, selected []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.decisions[key]; !ok {
+		r.order = append(r.order, key)
+	}
+	r.decisions[key] = selected
+	if len(r.order) > smartRoutingMaxDecisions {
+		delete(r.decisions, r.order[0])
+		r.order = r.order[1:]
+	}
+}
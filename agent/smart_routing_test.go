@@ -0,0 +1,127 @@
+package mcpagent
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/manishiitg/mcpagent/events"
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+	"github.com/manishiitg/mcpagent/mcpclient"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+// smartRoutingTestAgent has four servers of one tool each plus a custom tool.
+// The test embedding puts each server on its own axis, and a question on the
+// axes of the server names it mentions.
+func smartRoutingTestAgent(config SmartRoutingConfig) (*Agent, *recordingAgentEventListener, *int) {
+	servers := []string{"github", "slack", "postgres", "weather"}
+	embedCalls := 0
+	config.Embed = func(_ context.Context, texts []string) ([][]float32, error) {
+		embedCalls++
+		vectors := make([][]float32, len(texts))
+		for i, text := range texts {
+			vectors[i] = make([]float32, len(servers))
+			for j, server := range servers {
+				if strings.Contains(strings.ToLower(text), server) {
+					vectors[i][j] = 1
+				}
+			}
+		}
+		return vectors, nil
+	}
+
+	listener := &recordingAgentEventListener{}
+	a := &Agent{
+		Logger:        loggerv2.NewNoop(),
+		SessionID:     "session-1",
+		listeners:     []AgentEventListener{listener},
+		toolToServer:  map[string]string{"save_note": "custom"},
+		serverConfigs: map[string]mcpclient.MCPServerConfig{},
+	}
+	for _, server := range servers {
+		name := server + "_query"
+		a.Tools = append(a.Tools, hintTestTool(name))
+		a.toolToServer[name] = server
+		a.serverConfigs[server] = mcpclient.MCPServerConfig{Description: server + " server"}
+	}
+	a.Tools = append(a.Tools, hintTestTool("save_note"))
+	WithSmartRouting(config)(a)
+	return a, listener, &embedCalls
+}
+
+func routingDecisions(listener *recordingAgentEventListener) []*events.RoutingDecisionEvent {
+	var decisions []*events.RoutingDecisionEvent
+	for _, e := range listener.events {
+		if decision, ok := e.Data.(*events.RoutingDecisionEvent); ok {
+			decisions = append(decisions, decision)
+		}
+	}
+	return decisions
+}
+
+func TestSmartRoutingKeepsMostSimilarServersAndReusesDecisions(t *testing.T) {
+	a, listener, embedCalls := smartRoutingTestAgent(SmartRoutingConfig{MaxServers: 2, MinScore: 0.1})
+	messages := []llmtypes.MessageContent{llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "Open a GitHub issue for the failing Postgres migration")}
+
+	tools := DefaultRouteStage{}.Route(context.Background(), a, messages)
+	if got := strings.Join(hintToolNames(tools), ","); got != "github_query,postgres_query,save_note" {
+		t.Fatalf("routed tools = %s", got)
+	}
+	decisions := routingDecisions(listener)
+	if len(decisions) != 1 || decisions[0].Method != "embedding" || len(decisions[0].Scores) != 4 {
+		t.Fatalf("decision = %+v", decisions)
+	}
+	if decisions[0].Scores[0].Score <= decisions[0].Scores[2].Score {
+		t.Errorf("scores not sorted best first: %+v", decisions[0].Scores)
+	}
+
+	// Same question, differently spaced: no new embedding
+	messages[0] = llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "open a github issue for the  failing postgres migration")
+	tools = DefaultRouteStage{}.Route(context.Background(), a, messages)
+	if got := strings.Join(hintToolNames(tools), ","); got != "github_query,postgres_query,save_note" {
+		t.Errorf("cached routed tools = %s", got)
+	}
+	if *embedCalls != 1 {
+		t.Errorf("embed calls = %d, want 1", *embedCalls)
+	}
+	if decisions = routingDecisions(listener); len(decisions) != 2 || decisions[1].Method != "cached" {
+		t.Errorf("second decision = %+v", decisions[len(decisions)-1])
+	}
+
+	// A new question is routed afresh
+	messages[0] = llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "What is the weather in Paris?")
+	tools = DefaultRouteStage{}.Route(context.Background(), a, messages)
+	if got := strings.Join(hintToolNames(tools), ","); got != "weather_query,save_note" {
+		t.Errorf("routed tools = %s", got)
+	}
+}
+
+func TestSmartRoutingRouterPicksAmongCandidates(t *testing.T) {
+	router := &fallbackTestModel{id: "router", answer: `{"servers": ["slack", "weather"]}`}
+	a, listener, _ := smartRoutingTestAgent(SmartRoutingConfig{MaxServers: 2, Router: router})
+	messages := []llmtypes.MessageContent{llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "Post the github release notes to slack")}
+
+	tools := DefaultRouteStage{}.Route(context.Background(), a, messages)
+	// weather was not an embedding candidate, so the router cannot add it
+	if got := strings.Join(hintToolNames(tools), ","); got != "slack_query,save_note" {
+		t.Errorf("routed tools = %s", got)
+	}
+	if decisions := routingDecisions(listener); len(decisions) != 1 || decisions[0].Method != "llm" || len(decisions[0].Candidates) != 2 {
+		t.Errorf("decision = %+v", decisions)
+	}
+	if router.calls != 1 {
+		t.Errorf("router calls = %d", router.calls)
+	}
+}
+
+func TestSmartRoutingSkipsAgentsWithFewServers(t *testing.T) {
+	a, listener, embedCalls := smartRoutingTestAgent(SmartRoutingConfig{MaxServers: 4})
+	messages := []llmtypes.MessageContent{llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "Check slack")}
+	if tools := (DefaultRouteStage{}).Route(context.Background(), a, messages); len(tools) != 5 {
+		t.Errorf("routed %d tools, want all 5", len(tools))
+	}
+	if *embedCalls != 0 || len(routingDecisions(listener)) != 0 {
+		t.Error("routing should not run")
+	}
+}
@@ -2,6 +2,7 @@ package mcpagent
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"sync"
 	"time"
@@ -17,6 +18,66 @@ type StreamingTracer interface {
 	GetEventStream() <-chan *events.AgentEvent
 	// SubscribeToEvents allows external systems to subscribe to events
 	SubscribeToEvents(ctx context.Context) (<-chan *events.AgentEvent, func())
+	// SubscribeWithReplay subscribes like SubscribeToEvents, but the channel first
+	// receives the retained recent events (see SetReplaySize) and then goes live
+	SubscribeWithReplay(ctx context.Context) (<-chan *events.AgentEvent, func())
+	// SetReplaySize sets how many recent events are retained for late subscribers (0 = none)
+	SetReplaySize(size int)
+	// SubscribeWithOptions subscribes with a verbosity, so each subscriber can
+	// receive a stream tailored to it from the same tracer
+	SubscribeWithOptions(ctx context.Context, opts SubscribeOptions) (<-chan *events.AgentEvent, func())
+}
+
+// StreamVerbosity selects which events a subscriber receives
+type StreamVerbosity string
+
+const (
+	// StreamVerbosityFull delivers every event (e.g. monitoring dashboards)
+	StreamVerbosityFull StreamVerbosity = "full"
+	// StreamVerbosityChunks delivers streamed answer text (streaming start,
+	// chunk, structured chunk, end and error events) plus the conversation's
+	// end or error, so the client knows when the answer is complete
+	StreamVerbosityChunks StreamVerbosity = "chunks"
+	// StreamVerbosityMilestones delivers lifecycle events only: conversation,
+	// agent and sub-agent start/end, tool calls, completion and errors (see
+	// events.IsMilestoneEvent), e.g. for mobile clients
+	StreamVerbosityMilestones StreamVerbosity = "milestones"
+)
+
+// ParseStreamVerbosity parses a verbosity name; "" is StreamVerbosityFull
+func ParseStreamVerbosity(s string) (StreamVerbosity, error) {
+	switch v := StreamVerbosity(s); v {
+	case "":
+		return StreamVerbosityFull, nil
+	case StreamVerbosityFull, StreamVerbosityChunks, StreamVerbosityMilestones:
+		return v, nil
+	}
+	return "", fmt.Errorf("unknown stream verbosity %q (want full, chunks or milestones)", s)
+}
+
+// includes reports whether a subscriber with verbosity v receives event
+func (v StreamVerbosity) includes(event *events.AgentEvent) bool {
+	switch v {
+	case StreamVerbosityChunks:
+		return events.IsStreamingTextEvent(event.Type) || event.Type == events.ConversationEnd || event.Type == events.ConversationError
+	case StreamVerbosityMilestones:
+		return events.IsMilestoneEvent(event.Type)
+	}
+	return true
+}
+
+// SubscribeOptions configures a subscription to a StreamingTracer
+type SubscribeOptions struct {
+	// Verbosity selects the events delivered; "" = StreamVerbosityFull
+	Verbosity StreamVerbosity
+	// Replay first delivers the retained recent events that match Verbosity
+	Replay bool
+}
+
+// streamSubscriber is a subscriber channel and the events it wants
+type streamSubscriber struct {
+	ch        chan *events.AgentEvent
+	verbosity StreamVerbosity
 }
 
 // streamingTracerImpl is a custom tracer that provides streaming capabilities
@@ -24,10 +85,19 @@ type streamingTracerImpl struct {
 	baseTracer   observability.Tracer
 	eventStream  chan *events.AgentEvent
 	bufferSize   int
-	subscribers  map[string]chan *events.AgentEvent
+	subscribers  map[string]*streamSubscriber
 	subscriberMu sync.RWMutex
+	subscriberN  uint64
 	closed       bool
-	mu           sync.RWMutex
+
+	// Replay buffer: the last replaySize forwarded events, oldest first.
+	// Appended by forwardEvents under the subscriberMu read lock, so a
+	// subscriber registered under the write lock sees every event exactly
+	// once, either in its replay or live.
+	replay     []*events.AgentEvent
+	replaySize int
+	replayMu   sync.Mutex
+	mu         sync.RWMutex
 }
 
 // NewStreamingTracer creates a new streaming tracer that wraps an existing tracer
@@ -40,7 +110,7 @@ func NewStreamingTracer(baseTracer observability.Tracer, bufferSize int) Streami
 		baseTracer:  baseTracer,
 		eventStream: make(chan *events.AgentEvent, bufferSize),
 		bufferSize:  bufferSize,
-		subscribers: make(map[string]chan *events.AgentEvent),
+		subscribers: make(map[string]*streamSubscriber),
 	}
 
 	// Start event forwarding goroutine
@@ -56,6 +126,37 @@ func (st *streamingTracerImpl) GetEventStream() <-chan *events.AgentEvent {
 
 // SubscribeToEvents allows external systems to subscribe to events
 func (st *streamingTracerImpl) SubscribeToEvents(ctx context.Context) (<-chan *events.AgentEvent, func()) {
+	return st.SubscribeWithOptions(ctx, SubscribeOptions{})
+}
+
+// SubscribeWithReplay subscribes and first delivers the retained recent events,
+// so UIs that join a running conversation catch up before going live
+func (st *streamingTracerImpl) SubscribeWithReplay(ctx context.Context) (<-chan *events.AgentEvent, func()) {
+	return st.SubscribeWithOptions(ctx, SubscribeOptions{Replay: true})
+}
+
+// SetReplaySize sets how many recent events are retained for SubscribeWithReplay
+func (st *streamingTracerImpl) SetReplaySize(size int) {
+	st.replayMu.Lock()
+	defer st.replayMu.Unlock()
+	if size < 0 {
+		size = 0
+	}
+	st.replaySize = size
+	if len(st.replay) > size {
+		st.replay = append([]*events.AgentEvent(nil), st.replay[len(st.replay)-size:]...)
+	}
+}
+
+// SubscribeWithOptions subscribes with a verbosity and optional replay. The
+// tracer filters events per subscriber, so subscribers with different
+// verbosities share one tracer.
+func (st *streamingTracerImpl) SubscribeWithOptions(ctx context.Context, opts SubscribeOptions) (<-chan *events.AgentEvent, func()) {
+	verbosity := opts.Verbosity
+	if verbosity == "" {
+		verbosity = StreamVerbosityFull
+	}
+
 	st.subscriberMu.Lock()
 	defer st.subscriberMu.Unlock()
 
@@ -63,18 +164,33 @@ func (st *streamingTracerImpl) SubscribeToEvents(ctx context.Context) (<-chan *e
 		return nil, func() {}
 	}
 
-	// Create unique subscriber ID
-	subscriberID := fmt.Sprintf("subscriber-%d", time.Now().UnixNano())
-	subscriberChan := make(chan *events.AgentEvent, st.bufferSize)
+	var replay []*events.AgentEvent
+	if opts.Replay {
+		st.replayMu.Lock()
+		for _, event := range st.replay {
+			if verbosity.includes(event) {
+				replay = append(replay, event)
+			}
+		}
+		st.replayMu.Unlock()
+	}
 
-	st.subscribers[subscriberID] = subscriberChan
+	// Create unique subscriber ID; the channel has room for the replay on top of the live buffer
+	st.subscriberN++
+	subscriberID := fmt.Sprintf("subscriber-%d-%d", time.Now().UnixNano(), st.subscriberN)
+	subscriberChan := make(chan *events.AgentEvent, st.bufferSize+len(replay))
+	for _, event := range replay {
+		subscriberChan <- event
+	}
+
+	st.subscribers[subscriberID] = &streamSubscriber{ch: subscriberChan, verbosity: verbosity}
 
 	// Return unsubscribe function
 	unsubscribe := func() {
 		st.subscriberMu.Lock()
 		defer st.subscriberMu.Unlock()
-		if ch, exists := st.subscribers[subscriberID]; exists {
-			close(ch)
+		if sub, exists := st.subscribers[subscriberID]; exists {
+			close(sub.ch)
 			delete(st.subscribers, subscriberID)
 		}
 	}
@@ -92,11 +208,15 @@ func (st *streamingTracerImpl) SubscribeToEvents(ctx context.Context) (<-chan *e
 func (st *streamingTracerImpl) forwardEvents() {
 	for event := range st.eventStream {
 		st.subscriberMu.RLock()
+		st.recordReplay(event)
 		// Send while holding the read lock so unsubscribe/Close cannot close a
 		// subscriber channel between selection and send.
-		for _, ch := range st.subscribers {
+		for _, sub := range st.subscribers {
+			if !sub.verbosity.includes(event) {
+				continue
+			}
 			select {
-			case ch <- event:
+			case sub.ch <- event:
 				// Event sent successfully
 			default:
 				// Channel is full, skip this subscriber
@@ -106,11 +226,51 @@ func (st *streamingTracerImpl) forwardEvents() {
 	}
 }
 
+// recordReplay appends event to the replay buffer, dropping the oldest when full
+func (st *streamingTracerImpl) recordReplay(event *events.AgentEvent) {
+	st.replayMu.Lock()
+	defer st.replayMu.Unlock()
+	if st.replaySize == 0 {
+		return
+	}
+	if len(st.replay) >= st.replaySize {
+		copy(st.replay, st.replay[1:])
+		st.replay = st.replay[:len(st.replay)-1]
+	}
+	st.replay = append(st.replay, event)
+}
+
+// replayBytes estimates the memory held by the replay buffer
+func (st *streamingTracerImpl) replayBytes() int64 {
+	st.replayMu.Lock()
+	defer st.replayMu.Unlock()
+	var total int64
+	for _, event := range st.replay {
+		if data, err := json.Marshal(event); err == nil {
+			total += int64(len(data))
+		}
+	}
+	return total
+}
+
+// clearReplay drops the retained events; new events are still recorded
+func (st *streamingTracerImpl) clearReplay() {
+	st.replayMu.Lock()
+	defer st.replayMu.Unlock()
+	st.replay = nil
+}
+
 // EmitEvent implements observability.Tracer interface
 func (st *streamingTracerImpl) EmitEvent(event observability.AgentEvent) error {
+	return st.EmitEventContext(context.Background(), event)
+}
+
+// EmitEventContext implements observability.ContextTracer, passing ctx on to
+// the base tracer so it can sample by conversation
+func (st *streamingTracerImpl) EmitEventContext(ctx context.Context, event observability.AgentEvent) error {
 	// Forward to base tracer
 	if st.baseTracer != nil {
-		_ = st.baseTracer.EmitEvent(event) // Ignore errors from base tracer
+		_ = observability.EmitEvent(ctx, st.baseTracer, event) // Ignore errors from base tracer
 	}
 
 	// Try to convert to our AgentEvent type for streaming
@@ -133,6 +293,31 @@ func (st *streamingTracerImpl) EmitEvent(event observability.AgentEvent) error {
 	return nil
 }
 
+// beginTraceSampling rolls a new sampling decision on every sampling tracer and
+// returns ctx carrying them, with the decisions for recording on the
+// ConversationStart event.
+func (a *Agent) beginTraceSampling(ctx context.Context) (context.Context, []events.TraceSamplingDecision) {
+	var decisions []events.TraceSamplingDecision
+	for _, tracer := range a.Tracers {
+		if st, ok := tracer.(*streamingTracerImpl); ok {
+			tracer = st.baseTracer
+		}
+		sampler, ok := tracer.(*observability.SamplingTracer)
+		if !ok {
+			continue
+		}
+		var decision observability.SamplingDecision
+		ctx, decision = sampler.BeginConversation(ctx, a.UserID)
+		decisions = append(decisions, events.TraceSamplingDecision{
+			Tracer:  sampler.Name(),
+			Sampled: decision.Sampled,
+			Rate:    decision.Rate,
+			Reason:  decision.Reason,
+		})
+	}
+	return ctx, decisions
+}
+
 // EmitLLMEvent implements observability.Tracer interface
 func (st *streamingTracerImpl) EmitLLMEvent(event observability.LLMEvent) error {
 	// Forward to base tracer
@@ -173,10 +358,10 @@ func (st *streamingTracerImpl) Close() error {
 
 	// Close all subscriber channels
 	st.subscriberMu.Lock()
-	for _, ch := range st.subscribers {
-		close(ch)
+	for _, sub := range st.subscribers {
+		close(sub.ch)
 	}
-	st.subscribers = make(map[string]chan *events.AgentEvent)
+	st.subscribers = make(map[string]*streamSubscriber)
 	st.subscriberMu.Unlock()
 
 	return nil
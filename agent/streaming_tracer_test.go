@@ -97,3 +97,144 @@ func TestStreamingTracerUnsubscribeDuringForwardDoesNotPanic(t *testing.T) {
 		t.Fatalf("streaming tracer panicked during concurrent unsubscribe/emit: %v", recovered)
 	}
 }
+
+func TestStreamingTracerReplaysRetainedEventsToLateSubscribers(t *testing.T) {
+	tracer := NewStreamingTracer(observability.NoopTracer{}, 16)
+	defer func() {
+		_ = tracer.(interface{ Close() error }).Close()
+	}()
+	tracer.SetReplaySize(2)
+
+	// A live subscriber makes sure the first events have been forwarded
+	// (and recorded) before the late subscriber joins
+	live, unsubscribeLive := tracer.SubscribeToEvents(context.Background())
+	defer unsubscribeLive()
+
+	emit := func(content string) {
+		if err := tracer.EmitEvent(&events.AgentEvent{
+			Type:      events.StreamingChunk,
+			Timestamp: time.Now(),
+			Data:      &events.StreamingChunkEvent{Content: content},
+		}); err != nil {
+			t.Fatalf("EmitEvent: %v", err)
+		}
+	}
+	receive := func(ch <-chan *events.AgentEvent) string {
+		select {
+		case event := <-ch:
+			return event.Data.(*events.StreamingChunkEvent).Content
+		case <-time.After(2 * time.Second):
+			t.Fatal("timed out waiting for event")
+			return ""
+		}
+	}
+
+	for _, content := range []string{"a", "b", "c"} {
+		emit(content)
+		if got := receive(live); got != content {
+			t.Fatalf("live subscriber got %q, want %q", got, content)
+		}
+	}
+
+	late, unsubscribeLate := tracer.SubscribeWithReplay(context.Background())
+	defer unsubscribeLate()
+	emit("d")
+
+	// Oldest event is dropped once the replay buffer is full
+	for _, want := range []string{"b", "c", "d"} {
+		if got := receive(late); got != want {
+			t.Fatalf("late subscriber got %q, want %q", got, want)
+		}
+	}
+
+	plain, unsubscribePlain := tracer.SubscribeToEvents(context.Background())
+	defer unsubscribePlain()
+	emit("e")
+	if got := receive(plain); got != "e" {
+		t.Fatalf("subscriber without replay got %q, want live event %q", got, "e")
+	}
+}
+
+func TestStreamingTracerTailorsStreamPerSubscriberVerbosity(t *testing.T) {
+	tracer := NewStreamingTracer(observability.NoopTracer{}, 16)
+	defer func() {
+		_ = tracer.(interface{ Close() error }).Close()
+	}()
+
+	ctx := context.Background()
+	full, unsubscribeFull := tracer.SubscribeWithOptions(ctx, SubscribeOptions{Verbosity: StreamVerbosityFull})
+	defer unsubscribeFull()
+	chunks, unsubscribeChunks := tracer.SubscribeWithOptions(ctx, SubscribeOptions{Verbosity: StreamVerbosityChunks})
+	defer unsubscribeChunks()
+	milestones, unsubscribeMilestones := tracer.SubscribeWithOptions(ctx, SubscribeOptions{Verbosity: StreamVerbosityMilestones})
+	defer unsubscribeMilestones()
+
+	sequence := []events.EventType{
+		events.ConversationStart, events.LLMGenerationStart, events.StreamingChunk,
+		events.ToolCallStart, events.TokenUsage, events.ToolCallEnd, events.StreamingChunk, events.ConversationEnd,
+	}
+	for _, eventType := range sequence {
+		if err := tracer.EmitEvent(&events.AgentEvent{Type: eventType, Timestamp: time.Now()}); err != nil {
+			t.Fatalf("EmitEvent: %v", err)
+		}
+	}
+
+	receive := func(ch <-chan *events.AgentEvent, n int) []events.EventType {
+		var got []events.EventType
+		for len(got) < n {
+			select {
+			case event := <-ch:
+				got = append(got, event.Type)
+			case <-time.After(2 * time.Second):
+				t.Fatalf("timed out after %v", got)
+			}
+		}
+		return got
+	}
+	assertTypes := func(name string, got, want []events.EventType) {
+		t.Helper()
+		if len(got) != len(want) {
+			t.Fatalf("%s subscriber got %v, want %v", name, got, want)
+		}
+		for i := range got {
+			if got[i] != want[i] {
+				t.Fatalf("%s subscriber got %v, want %v", name, got, want)
+			}
+		}
+	}
+
+	assertTypes("full", receive(full, len(sequence)), sequence)
+	// The conversation end is the last event, so receiving it proves nothing else was queued
+	assertTypes("chunks", receive(chunks, 3), []events.EventType{events.StreamingChunk, events.StreamingChunk, events.ConversationEnd})
+	assertTypes("milestones", receive(milestones, 4), []events.EventType{events.ConversationStart, events.ToolCallStart, events.ToolCallEnd, events.ConversationEnd})
+	select {
+	case event := <-milestones:
+		t.Fatalf("milestones subscriber got extra event %s", event.Type)
+	default:
+	}
+
+	// Replay honours the verbosity too
+	tracer.SetReplaySize(16)
+	if err := tracer.EmitEvent(&events.AgentEvent{Type: events.TokenUsage, Timestamp: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+	if err := tracer.EmitEvent(&events.AgentEvent{Type: events.ToolCallError, Timestamp: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+	receive(full, 2) // both forwarded and recorded
+	late, unsubscribeLate := tracer.SubscribeWithOptions(ctx, SubscribeOptions{Verbosity: StreamVerbosityMilestones, Replay: true})
+	defer unsubscribeLate()
+	assertTypes("late milestones", receive(late, 1), []events.EventType{events.ToolCallError})
+}
+
+func TestParseStreamVerbosity(t *testing.T) {
+	if v, err := ParseStreamVerbosity(""); err != nil || v != StreamVerbosityFull {
+		t.Errorf("empty verbosity = %q, %v; want full", v, err)
+	}
+	if v, err := ParseStreamVerbosity("milestones"); err != nil || v != StreamVerbosityMilestones {
+		t.Errorf("milestones = %q, %v", v, err)
+	}
+	if _, err := ParseStreamVerbosity("verbose"); err == nil {
+		t.Error("expected an error for an unknown verbosity")
+	}
+}
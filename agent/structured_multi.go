@@ -0,0 +1,190 @@
+// structured_multi.go
+//
+// This file provides multi-schema structured extraction in a single pass. For
+// tasks that yield heterogeneous outputs (a summary, a table and action items),
+// AskStructuredMulti registers one submission tool per schema and lets the
+// model submit whichever results apply — possibly several — in the same
+// conversation, instead of running one AskStructured call per schema.
+//
+// CLI providers cannot call registered tools, so for them the schemas are
+// injected into the prompt and the model answers with one JSON object keyed
+// by schema name (see cli_structured_output.go).
+//
+// Exported:
+//   - StructuredResults: Submitted JSON per schema name, with Decode
+//   - Agent.AskStructuredMulti: Ask a question and collect the submitted results
+
+package mcpagent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+// StructuredResults holds the JSON the model submitted for each schema name.
+// Schemas the model did not submit are absent.
+type StructuredResults map[string]json.RawMessage
+
+// Decode unmarshals the result submitted for name into v. It reports false
+// (and leaves v untouched) when the model did not submit name.
+func (r StructuredResults) Decode(name string, v interface{}) (bool, error) {
+	raw, ok := r[name]
+	if !ok {
+		return false, nil
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		return true, fmt.Errorf("failed to decode structured result %q: %w", name, err)
+	}
+	return true, nil
+}
+
+// AskStructuredMulti asks a question whose answer may span several structured
+// outputs and collects them in one pass.
+//
+// Each entry of schemas maps a result name (e.g. "summary", "action_items") to
+// a JSON schema string. A submission tool "submit_<name>" is registered per
+// schema; the model calls those that apply, and the arguments of each call
+// become that result. When a schema is submitted more than once, the last
+// submission wins.
+//
+// Example:
+//
+//	results, err := agent.AskStructuredMulti(ctx, "Review this incident report", map[string]string{
+//	    "summary":      summarySchema,
+//	    "action_items": actionItemsSchema,
+//	})
+//	var summary Summary
+//	found, err := results.Decode("summary", &summary)
+//
+// Returns:
+//   - StructuredResults: The submitted results by schema name. Results
+//     submitted before a conversation error are returned with the error.
+//   - error: An error if a schema is invalid, the conversation fails or no
+//     result was submitted.
+func (a *Agent) AskStructuredMulti(ctx context.Context, question string, schemas map[string]string) (StructuredResults, error) {
+	if len(schemas) == 0 {
+		return nil, fmt.Errorf("at least one schema is required")
+	}
+	messages := []llmtypes.MessageContent{llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, question)}
+
+	names := make([]string, 0, len(schemas))
+	for name := range schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if isCLIProvider(a.provider) {
+		return askStructuredMultiCLI(a, ctx, messages, names, schemas)
+	}
+
+	// Register one submission tool per schema
+	toolToName := make(map[string]string, len(names))
+	var instruction strings.Builder
+	instruction.WriteString("\n\nSubmit your results with these tools. Call each tool whose result applies to this request (one call per result), then reply with a brief confirmation:\n")
+	for _, name := range names {
+		toolName := structuredSubmitToolName(name)
+		if other, exists := toolToName[toolName]; exists {
+			return nil, fmt.Errorf("schemas %q and %q map to the same tool name %q", other, name, toolName)
+		}
+		toolParams, err := parseSchemaForToolParameters(schemas[name])
+		if err != nil {
+			return nil, fmt.Errorf("invalid schema %q: %w", name, err)
+		}
+		recorded := fmt.Sprintf("Recorded the %s result.", name)
+		executionFunc := func(context.Context, map[string]interface{}) (string, error) {
+			return recorded, nil
+		}
+		// "structured_output" keeps the tool available even in code execution mode
+		if err := a.RegisterCustomTool(toolName, fmt.Sprintf("Submit the %s result", name), toolParams, executionFunc, "structured_output"); err != nil {
+			return nil, fmt.Errorf("failed to register submission tool for %q: %w", name, err)
+		}
+		toolToName[toolName] = name
+		instruction.WriteString(fmt.Sprintf("- %s: the %s result\n", toolName, name))
+	}
+
+	_, updatedMessages, err := a.AskWithHistory(ctx, injectStructuredOutputIntoLastUserMessage(messages, instruction.String()))
+
+	results := collectStructuredSubmissions(updatedMessages, toolToName)
+	getLogger(a).Debug("Structured multi-extraction finished",
+		loggerv2.Int("schemas", len(names)),
+		loggerv2.Int("submitted", len(results)))
+	if err != nil {
+		return results, fmt.Errorf("failed to get response from conversation: %w", err)
+	}
+	if len(results) == 0 {
+		return results, fmt.Errorf("no structured result was submitted (expected one of %s)", strings.Join(names, ", "))
+	}
+	return results, nil
+}
+
+// structuredSubmitToolNamePattern matches characters not allowed in tool names
+var structuredSubmitToolNamePattern = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+
+// structuredSubmitToolName is the submission tool registered for a schema name
+func structuredSubmitToolName(name string) string {
+	return "submit_" + strings.Trim(structuredSubmitToolNamePattern.ReplaceAllString(name, "_"), "_")
+}
+
+// collectStructuredSubmissions returns the arguments of the submission tool
+// calls in messages, by schema name; later submissions replace earlier ones
+func collectStructuredSubmissions(messages []llmtypes.MessageContent, toolToName map[string]string) StructuredResults {
+	results := make(StructuredResults)
+	for _, msg := range messages {
+		if msg.Role != llmtypes.ChatMessageTypeAI {
+			continue
+		}
+		for _, part := range msg.Parts {
+			toolCall, ok := part.(llmtypes.ToolCall)
+			if !ok || toolCall.FunctionCall == nil {
+				continue
+			}
+			name, ok := toolToName[toolCall.FunctionCall.Name]
+			if !ok || !json.Valid([]byte(toolCall.FunctionCall.Arguments)) {
+				continue
+			}
+			results[name] = json.RawMessage(toolCall.FunctionCall.Arguments)
+		}
+	}
+	return results
+}
+
+// askStructuredMultiCLI asks CLI providers for one JSON object keyed by schema
+// name and splits it into results
+func askStructuredMultiCLI(a *Agent, ctx context.Context, messages []llmtypes.MessageContent, names []string, schemas map[string]string) (StructuredResults, error) {
+	var instruction strings.Builder
+	instruction.WriteString("\n\nIMPORTANT: You MUST respond with ONLY a valid JSON object. Its keys are the result names below; include each result that applies to this request, with a value that conforms to that result's JSON schema. Do NOT include any text, explanations, or markdown formatting — output ONLY the raw JSON object.\n")
+	for _, name := range names {
+		instruction.WriteString(fmt.Sprintf("\nResult %q schema:\n%s\n", name, schemas[name]))
+	}
+
+	textResponse, _, err := a.AskWithHistory(ctx, injectStructuredOutputIntoLastUserMessage(messages, instruction.String()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get text response: %w", err)
+	}
+	jsonBytes, err := extractJSONFromCLIResponse(textResponse)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract JSON from CLI response: %w", err)
+	}
+	var submitted map[string]json.RawMessage
+	if err := json.Unmarshal(jsonBytes, &submitted); err != nil {
+		return nil, fmt.Errorf("CLI response is not a JSON object keyed by result name: %w", err)
+	}
+
+	results := make(StructuredResults)
+	for _, name := range names {
+		if raw, ok := submitted[name]; ok && string(raw) != "null" {
+			results[name] = raw
+		}
+	}
+	if len(results) == 0 {
+		return results, fmt.Errorf("no structured result was submitted (expected one of %s)", strings.Join(names, ", "))
+	}
+	return results, nil
+}
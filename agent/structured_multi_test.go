@@ -0,0 +1,71 @@
+package mcpagent
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+func TestAskStructuredMultiCollectsSubmittedSchemas(t *testing.T) {
+	submit := func(id, name, args string) llmtypes.ToolCall {
+		return llmtypes.ToolCall{ID: id, Type: "function", FunctionCall: &llmtypes.FunctionCall{Name: name, Arguments: args}}
+	}
+	generate := &scriptedGenerateStage{responses: []*llmtypes.ContentResponse{
+		{Choices: []*llmtypes.ContentChoice{{ToolCalls: []llmtypes.ToolCall{
+			submit("call-1", "submit_summary", `{"text":"draft"}`),
+			submit("call-2", "submit_action_items", `{"items":["page on-call","rotate keys"]}`),
+		}}}},
+		{Choices: []*llmtypes.ContentChoice{{ToolCalls: []llmtypes.ToolCall{
+			submit("call-3", "submit_summary", `{"text":"Disk filled up on db-1."}`),
+		}}}},
+		{Choices: []*llmtypes.ContentChoice{{Content: "Submitted."}}},
+	}}
+	dispatcher := &recordingToolDispatcher{}
+	a := &Agent{Logger: loggerv2.NewNoop(), LLM: &providerKeyCarrierModel{}, ModelID: "test-model", MaxTurns: 5}
+	WithAskPipeline(AskPipeline{Generate: generate, DispatchTools: dispatcher})(a)
+
+	results, err := a.AskStructuredMulti(context.Background(), "Review the incident", map[string]string{
+		"summary":      `{"type":"object","properties":{"text":{"type":"string"}},"required":["text"]}`,
+		"action_items": `{"type":"object","properties":{"items":{"type":"array","items":{"type":"string"}}}}`,
+		"table":        `{"type":"object","properties":{"rows":{"type":"array"}}}`,
+	})
+	if err != nil {
+		t.Fatalf("AskStructuredMulti: %v", err)
+	}
+	for _, tool := range []string{"submit_summary", "submit_action_items", "submit_table"} {
+		if _, ok := a.customTools[tool]; !ok {
+			t.Errorf("expected submission tool %s to be registered", tool)
+		}
+	}
+
+	var summary struct{ Text string }
+	if found, err := results.Decode("summary", &summary); !found || err != nil || summary.Text != "Disk filled up on db-1." {
+		t.Errorf("summary = %+v found=%v err=%v, want the last submission", summary, found, err)
+	}
+	var actions struct{ Items []string }
+	if found, err := results.Decode("action_items", &actions); !found || err != nil || len(actions.Items) != 2 {
+		t.Errorf("action_items = %+v found=%v err=%v", actions, found, err)
+	}
+	if found, _ := results.Decode("table", &struct{}{}); found {
+		t.Error("table was not submitted")
+	}
+}
+
+func TestAskStructuredMultiRejectsInvalidSchemas(t *testing.T) {
+	a := &Agent{Logger: loggerv2.NewNoop()}
+	if _, err := a.AskStructuredMulti(context.Background(), "q", nil); err == nil {
+		t.Error("expected an error without schemas")
+	}
+	if _, err := a.AskStructuredMulti(context.Background(), "q", map[string]string{"summary": `{"type":"string"}`}); err == nil || !strings.Contains(err.Error(), `"summary"`) {
+		t.Errorf("expected an invalid schema error naming the schema, got %v", err)
+	}
+	if _, err := a.AskStructuredMulti(context.Background(), "q", map[string]string{
+		"action items": `{"properties":{}}`,
+		"action_items": `{"properties":{}}`,
+	}); err == nil || !strings.Contains(err.Error(), "submit_action_items") {
+		t.Errorf("expected a tool name collision error, got %v", err)
+	}
+}
@@ -0,0 +1,116 @@
+// structured_native.go
+//
+// This file implements structured output through the provider's native JSON
+// schema mode (OpenAI/Azure structured outputs, Gemini responseSchema). The
+// schema is sent with every LLM call of the conversation, so the final answer
+// is constrained to it and is decoded directly — no second conversion call
+// (AskStructured) and no submission tool (AskWithHistoryStructuredViaTool).
+// Providers without native support fall back to AskWithHistoryStructured.
+//
+// Exported:
+//   - AskStructuredNative: Ask a question with a natively constrained answer
+//   - AskWithHistoryStructuredNative: Same, continuing a conversation
+
+package mcpagent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/manishiitg/mcpagent/llm"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+// nativeStructuredOutputSchemaName is the schema name sent to the provider
+const nativeStructuredOutputSchemaName = "structured_output"
+
+// supportsNativeStructuredOutput reports whether provider enforces a JSON
+// schema on responses through the provider library
+func supportsNativeStructuredOutput(provider llm.Provider) bool {
+	return provider == llm.ProviderOpenAI || provider == llm.ProviderAzure || provider == llm.ProviderVertex
+}
+
+// callWithResponseSchema constrains the answer of this call to a JSON schema
+func callWithResponseSchema(schema *llmtypes.JSONSchemaConfig) CallOption {
+	return func(o *callOptions) {
+		o.responseSchema = schema
+	}
+}
+
+// newCallResponseSchema returns the response schema set by opts, nil when unset
+func newCallResponseSchema(opts []CallOption) *llmtypes.JSONSchemaConfig {
+	var o callOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o.responseSchema
+}
+
+// AskStructuredNative processes a single question and constrains the answer
+// to a JSON schema using the provider's native structured output mode.
+//
+// See AskWithHistoryStructuredNative.
+func AskStructuredNative[T any](a *Agent, ctx context.Context, question string, schema T, schemaString string) (T, error) {
+	userMessage := llmtypes.MessageContent{
+		Role:  llmtypes.ChatMessageTypeHuman,
+		Parts: []llmtypes.ContentPart{llmtypes.TextContent{Text: question}},
+	}
+
+	answer, _, err := AskWithHistoryStructuredNative(a, ctx, []llmtypes.MessageContent{userMessage}, schema, schemaString)
+	return answer, err
+}
+
+// AskWithHistoryStructuredNative runs a multi-turn interaction whose final
+// answer is constrained to a JSON schema by the provider.
+//
+// On providers with native JSON schema support (OpenAI, Azure, Vertex) the
+// schema is passed as the response format of every LLM call and the final
+// answer is decoded into T. If the answer does not decode, it is converted
+// with the same second call AskWithHistoryStructured uses. Other providers
+// (including CLI providers) use AskWithHistoryStructured directly.
+//
+// Parameters:
+//   - a: The Agent instance.
+//   - ctx: Context for the request.
+//   - messages: The conversation history.
+//   - schema: An instance of generic type T.
+//   - schemaString: A JSON schema string describing T.
+//
+// Returns:
+//   - T: The result parsed into type T.
+//   - []llmtypes.MessageContent: The updated conversation history.
+//   - error: An error if the schema is invalid, or processing or conversion fails.
+func AskWithHistoryStructuredNative[T any](a *Agent, ctx context.Context, messages []llmtypes.MessageContent, schema T, schemaString string) (T, []llmtypes.MessageContent, error) {
+	var zero T
+	if !supportsNativeStructuredOutput(a.provider) {
+		return AskWithHistoryStructured(a, ctx, messages, schema, schemaString)
+	}
+
+	var schemaMap map[string]interface{}
+	if err := json.Unmarshal([]byte(schemaString), &schemaMap); err != nil {
+		return zero, messages, fmt.Errorf("failed to parse schema JSON: %w", err)
+	}
+
+	textResponse, updatedMessages, err := a.AskWithHistory(ctx, messages, callWithResponseSchema(&llmtypes.JSONSchemaConfig{
+		Name:   nativeStructuredOutputSchemaName,
+		Schema: schemaMap,
+	}))
+	if err != nil {
+		return zero, updatedMessages, fmt.Errorf("failed to get text response: %w", err)
+	}
+
+	var result T
+	if jsonBytes, extractErr := extractJSONFromCLIResponse(textResponse); extractErr == nil {
+		if err := json.Unmarshal(jsonBytes, &result); err == nil {
+			return result, updatedMessages, nil
+		}
+	}
+
+	getLogger(a).Warn("Native structured output did not decode, converting the answer instead")
+	result, err = ConvertToStructuredOutput(a, ctx, textResponse, schema, schemaString)
+	if err != nil {
+		return zero, updatedMessages, fmt.Errorf("failed to convert to structured output: %w", err)
+	}
+	return result, updatedMessages, nil
+}
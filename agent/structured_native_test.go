@@ -0,0 +1,76 @@
+package mcpagent
+
+import (
+	"context"
+	"testing"
+
+	"github.com/manishiitg/mcpagent/llm"
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+	"github.com/manishiitg/mcpagent/observability"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+// schemaRecordingGenerateStage answers with content and records the JSON
+// schema each call was constrained to
+type schemaRecordingGenerateStage struct {
+	content string
+	schemas []*llmtypes.JSONSchemaConfig
+}
+
+func (s *schemaRecordingGenerateStage) Generate(_ context.Context, _ *Agent, _ []llmtypes.MessageContent, opts []llmtypes.CallOption, _ int) (*llmtypes.ContentResponse, observability.UsageMetrics, error) {
+	var callOpts llmtypes.CallOptions
+	for _, opt := range opts {
+		opt(&callOpts)
+	}
+	s.schemas = append(s.schemas, callOpts.JSONSchema)
+	return &llmtypes.ContentResponse{Choices: []*llmtypes.ContentChoice{{Content: s.content}}}, observability.UsageMetrics{}, nil
+}
+
+type nativeTestAnswer struct {
+	City  string `json:"city"`
+	Count int    `json:"count"`
+}
+
+const nativeTestSchema = `{"type":"object","properties":{"city":{"type":"string"},"count":{"type":"integer"}},"required":["city","count"]}`
+
+func TestAskStructuredNativeSendsSchemaAndDecodesAnswer(t *testing.T) {
+	generate := &schemaRecordingGenerateStage{content: `{"city":"Paris","count":3}`}
+	a := &Agent{Logger: loggerv2.NewNoop(), LLM: &providerKeyCarrierModel{}, ModelID: "test-model", MaxTurns: 3}
+	WithProvider(llm.ProviderOpenAI)(a)
+	WithAskPipeline(AskPipeline{Generate: generate})(a)
+
+	answer, err := AskStructuredNative(a, context.Background(), "where?", nativeTestAnswer{}, nativeTestSchema)
+	if err != nil {
+		t.Fatalf("AskStructuredNative: %v", err)
+	}
+	if answer != (nativeTestAnswer{City: "Paris", Count: 3}) {
+		t.Errorf("answer = %+v", answer)
+	}
+	if len(generate.schemas) != 1 || generate.schemas[0] == nil || generate.schemas[0].Name != nativeStructuredOutputSchemaName {
+		t.Fatalf("schemas = %+v, want the native schema on the call", generate.schemas)
+	}
+	if generate.schemas[0].Schema["type"] != "object" {
+		t.Errorf("schema = %v", generate.schemas[0].Schema)
+	}
+	if len(a.activeCalls) != 0 {
+		t.Error("the call should be finished")
+	}
+
+	if _, err := AskStructuredNative(a, context.Background(), "where?", nativeTestAnswer{}, "not json"); err == nil {
+		t.Error("expected an error for an invalid schema")
+	}
+}
+
+func TestSupportsNativeStructuredOutput(t *testing.T) {
+	for provider, want := range map[llm.Provider]bool{
+		llm.ProviderOpenAI:     true,
+		llm.ProviderAzure:      true,
+		llm.ProviderVertex:     true,
+		llm.ProviderAnthropic:  false,
+		llm.ProviderClaudeCode: false,
+	} {
+		if got := supportsNativeStructuredOutput(provider); got != want {
+			t.Errorf("supportsNativeStructuredOutput(%s) = %v, want %v", provider, got, want)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+// structured_stream.go
+//
+// This file provides a streaming-compatible variant of
+// AskWithHistoryStructuredViaTool. The original cancels the conversation
+// context the moment the structured output tool is called, which also aborts
+// any stream consumers. The streaming variant leaves the context alone: the
+// tool call is recorded, the turn loop ends normally after the tool's turn
+// with a StructuredOutputCaptured event and a completion event, and the
+// tool arguments are extracted from the history as before.
+//
+// Exported:
+//   - AskWithHistoryStructuredViaToolStream: Tool-based structured output that keeps streams alive
+
+package mcpagent
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/manishiitg/mcpagent/events"
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+// callWithStructuredCapture ends the conversation after the turn in which
+// toolName was called
+func callWithStructuredCapture(toolName string) CallOption {
+	return func(o *callOptions) {
+		o.structuredCaptureTool = toolName
+	}
+}
+
+// newCallStructuredCapture returns the capture tool set by opts, "" when unset
+func newCallStructuredCapture(opts []CallOption) string {
+	var o callOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o.structuredCaptureTool
+}
+
+// AskWithHistoryStructuredViaToolStream is AskWithHistoryStructuredViaTool for
+// streaming agents (WithStreaming). The context is never cancelled: content
+// chunks keep flowing to subscribers until the conversation ends, which it
+// does right after the turn that called toolName. A StructuredOutputCaptured
+// event carries the tool arguments, followed by the usual completion event.
+//
+// Parameters and result are the same as for AskWithHistoryStructuredViaTool.
+func AskWithHistoryStructuredViaToolStream[T any](
+	a *Agent,
+	ctx context.Context,
+	messages []llmtypes.MessageContent,
+	toolName string,
+	toolDescription string,
+	schema string,
+) (StructuredOutputResult[T], error) {
+	// CLI providers answer with JSON in the text; nothing is cancelled there either
+	if isCLIProvider(a.provider) {
+		return askWithHistoryStructuredViaToolCLI[T](a, ctx, messages, toolName, toolDescription, schema)
+	}
+
+	toolParams, err := parseSchemaForToolParameters(schema)
+	if err != nil {
+		var zero StructuredOutputResult[T]
+		return zero, fmt.Errorf("failed to parse schema for tool parameters: %w", err)
+	}
+
+	executionFunc := func(context.Context, map[string]interface{}) (string, error) {
+		return "Recorded the structured output.", nil
+	}
+	// Register with "structured_output" category so it's always available even in code execution mode
+	if err := a.RegisterCustomTool(toolName, toolDescription, toolParams, executionFunc, "structured_output"); err != nil {
+		var zero StructuredOutputResult[T]
+		return zero, fmt.Errorf("failed to register custom tool: %w", err)
+	}
+
+	textResponse, updatedMessages, err := a.AskWithHistory(ctx, messages, callWithStructuredCapture(toolName))
+
+	structuredResult, found, extractErr := extractStructuredToolCall[T](updatedMessages, toolName)
+	if extractErr != nil {
+		var zero StructuredOutputResult[T]
+		return zero, fmt.Errorf("failed to extract structured tool call: %w", extractErr)
+	}
+	if found {
+		return StructuredOutputResult[T]{
+			HasStructuredOutput: true,
+			StructuredResult:    structuredResult,
+			Messages:            updatedMessages,
+		}, nil
+	}
+	if err != nil {
+		var zero StructuredOutputResult[T]
+		return zero, fmt.Errorf("failed to get response from conversation: %w", err)
+	}
+
+	// Structured tool was not called - return text response (conversational input)
+	return StructuredOutputResult[T]{
+		HasStructuredOutput: false,
+		StructuredResult:    structuredResult, // zero value
+		TextResponse:        textResponse,
+		Messages:            updatedMessages,
+	}, nil
+}
+
+// captureStructuredOutput reports whether toolCalls include the structured
+// output tool of this call. If so it emits the StructuredOutputCaptured and
+// completion events and ends the agent session; the caller then returns.
+func (a *Agent) captureStructuredOutput(ctx context.Context, toolCalls []llmtypes.ToolCall, question string, startTime time.Time, turn int) bool {
+	captureTool := currentCall(ctx).structuredCaptureTool
+	if captureTool == "" {
+		return false
+	}
+	for _, tc := range toolCalls {
+		if tc.FunctionCall == nil || tc.FunctionCall.Name != captureTool {
+			continue
+		}
+		getLogger(a).Info("📦 [STRUCTURED_OUTPUT] Captured structured output tool call, ending conversation",
+			loggerv2.String("tool_name", tc.FunctionCall.Name),
+			loggerv2.Int("turn", turn+1))
+		a.EmitTypedEvent(ctx, &events.StructuredOutputCapturedEvent{
+			BaseEventData: events.BaseEventData{Timestamp: time.Now()},
+			ToolName:      tc.FunctionCall.Name,
+			ToolCallID:    tc.ID,
+			Arguments:     tc.FunctionCall.Arguments,
+			Turn:          turn + 1,
+		})
+		completion := events.NewUnifiedCompletionEvent("simple", string(a.AgentMode), question,
+			tc.FunctionCall.Arguments, "completed", time.Since(startTime), turn+1)
+		a.annotateUnifiedCompletionEvent(completion)
+		a.EmitTypedEvent(ctx, completion)
+		a.EndAgentSession(ctx, time.Since(startTime))
+		return true
+	}
+	return false
+}
@@ -0,0 +1,64 @@
+package mcpagent
+
+import (
+	"context"
+	"testing"
+
+	"github.com/manishiitg/mcpagent/events"
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+func TestStructuredViaToolStreamEndsWithoutCancelling(t *testing.T) {
+	type person struct {
+		Name string `json:"name"`
+		Age  int    `json:"age"`
+	}
+	generate := &scriptedGenerateStage{responses: []*llmtypes.ContentResponse{
+		{Choices: []*llmtypes.ContentChoice{{Content: "Filling in the record.", ToolCalls: []llmtypes.ToolCall{{
+			ID:           "call-1",
+			Type:         "function",
+			FunctionCall: &llmtypes.FunctionCall{Name: "submit_person", Arguments: `{"name":"Ada","age":36}`},
+		}}}}},
+	}}
+	dispatcher := &recordingToolDispatcher{}
+	listener := &recordingAgentEventListener{}
+	a := &Agent{Logger: loggerv2.NewNoop(), LLM: &providerKeyCarrierModel{}, ModelID: "test-model", MaxTurns: 5}
+	a.AddEventListener(listener)
+	WithAskPipeline(AskPipeline{Generate: generate, DispatchTools: dispatcher})(a)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	result, err := AskWithHistoryStructuredViaToolStream[person](a, ctx, []llmtypes.MessageContent{
+		llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "Record Ada, 36"),
+	}, "submit_person", "Submit the person", `{"type":"object","properties":{"name":{"type":"string"},"age":{"type":"integer"}},"required":["name"]}`)
+	if err != nil {
+		t.Fatalf("AskWithHistoryStructuredViaToolStream: %v", err)
+	}
+	if !result.HasStructuredOutput || result.StructuredResult != (person{Name: "Ada", Age: 36}) {
+		t.Fatalf("result = %+v", result)
+	}
+	if generate.calls != 1 || len(dispatcher.dispatched) != 1 {
+		t.Errorf("expected the conversation to end after the tool turn, got %d LLM calls", generate.calls)
+	}
+	if ctx.Err() != nil || len(a.activeCalls) != 0 {
+		t.Error("expected the context untouched and the call finished")
+	}
+
+	var captured *events.StructuredOutputCapturedEvent
+	completed := false
+	for _, event := range listener.events {
+		switch data := event.Data.(type) {
+		case *events.StructuredOutputCapturedEvent:
+			captured = data
+		case *events.UnifiedCompletionEvent:
+			completed = captured != nil && data.Status == "completed"
+		}
+	}
+	if captured == nil || captured.ToolCallID != "call-1" || captured.Arguments != `{"name":"Ada","age":36}` {
+		t.Errorf("structured_output_captured = %+v", captured)
+	}
+	if !completed {
+		t.Error("expected a completed completion event after the capture")
+	}
+}
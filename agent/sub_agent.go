@@ -0,0 +1,263 @@
+// sub_agent.go
+//
+// This file implements delegation to sub-agents. SpawnSubAgent creates a
+// child agent from the parent's MCP configuration, optionally with a
+// different model, system prompt or tool set, runs one task on it and
+// returns the answer. The child:
+//
+//   - reports its events through the parent's tracers and listeners, nested
+//     under the parent's current event (sub_agent_start / sub_agent_end
+//     events on the parent frame them)
+//   - rolls its token usage and cost up into the parent's cumulative totals,
+//     so GetTokenUsage and the parent's token usage events include it
+//   - uses its own connection session (derived from the parent's SessionID),
+//     which is closed when the task ends, so tool restrictions and custom
+//     tool registrations never leak into the parent's session
+//
+// Exported:
+//   - SubAgentOptions: Task and overrides for a sub-agent
+//   - SubAgentResult: Answer, history and usage of a delegated task
+//   - Agent.SpawnSubAgent: Delegate a task to a child agent
+
+package mcpagent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/manishiitg/mcpagent/events"
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+// SubAgentOptions configures a task delegated with SpawnSubAgent. Zero values
+// inherit from the parent agent.
+type SubAgentOptions struct {
+	// Task is the question or instruction the sub-agent works on (required)
+	Task string
+	// LLM runs the sub-agent on a different model; nil = the parent's model.
+	// Pass WithProvider in Options when the provider differs.
+	LLM llmtypes.Model
+	// SystemPrompt replaces the default system prompt
+	SystemPrompt string
+	// Tools restricts the sub-agent to these tool names (see SetToolAllowList)
+	Tools []string
+	// Servers restricts the MCP servers the sub-agent connects to
+	Servers []string
+	// MaxTurns bounds the sub-agent's turns; 0 = the parent's MaxTurns
+	MaxTurns int
+	// Options are applied to the sub-agent after the inherited settings
+	Options []AgentOption
+}
+
+// SubAgentResult is the outcome of a delegated task
+type SubAgentResult struct {
+	ID       string
+	ModelID  string
+	Answer   string
+	Messages []llmtypes.MessageContent
+	Duration time.Duration
+
+	// Usage of the sub-agent, already included in the parent's totals
+	PromptTokens     int
+	CompletionTokens int
+	TotalTokens      int
+	LLMCallCount     int
+	TotalCost        float64
+}
+
+// subAgentLink places a sub-agent's events in its parent's event tree
+type subAgentLink struct {
+	parentEventID  string
+	levelOffset    int
+	eventSessionID string
+}
+
+// subAgentEventForwarder publishes a sub-agent's events through the parent
+type subAgentEventForwarder struct {
+	parent *Agent
+}
+
+func (f subAgentEventForwarder) HandleEvent(ctx context.Context, event *events.AgentEvent) error {
+	f.parent.publishEvent(ctx, event)
+	return nil
+}
+
+func (f subAgentEventForwarder) Name() string {
+	return "sub_agent_forwarder"
+}
+
+// SpawnSubAgent delegates opts.Task to a new child agent and returns its
+// answer. The child is created from the parent's MCP configuration and
+// inherits its logger, trace ID, servers, user ID, runtime overrides and
+// max turns unless opts overrides them, and may spend what is left of the
+// parent's budget limit; it is closed when the task ends.
+//
+// Example:
+//
+//	result, err := agent.SpawnSubAgent(ctx, mcpagent.SubAgentOptions{
+//	    Task:  "Summarize the open issues labelled 'bug'",
+//	    LLM:   cheapLLM,
+//	    Tools: []string{"search_issues", "get_issue"},
+//	})
+func (a *Agent) SpawnSubAgent(ctx context.Context, opts SubAgentOptions) (*SubAgentResult, error) {
+	if strings.TrimSpace(opts.Task) == "" {
+		return nil, fmt.Errorf("sub-agent task is required")
+	}
+	id := "sub_" + events.GenerateEventID()
+	sessionID := a.SessionID
+	if sessionID == "" {
+		sessionID = "global"
+	}
+	sessionID = sessionID + ":" + id
+
+	a.eventMu.Lock()
+	link := &subAgentLink{
+		parentEventID:  a.currentParentEventID,
+		levelOffset:    a.currentHierarchyLevel + 1,
+		eventSessionID: a.SessionID,
+	}
+	a.eventMu.Unlock()
+	if link.eventSessionID == "" {
+		link.eventSessionID = string(a.TraceID)
+	}
+
+	llm := opts.LLM
+	options := []AgentOption{
+		WithLogger(a.Logger),
+		WithTraceID(a.TraceID),
+		WithSessionID(sessionID),
+		WithUserID(a.UserID),
+		WithRuntimeOverrides(a.RuntimeOverrides),
+		WithDisableCache(a.DisableCache),
+		WithServerName(a.serverName),
+		WithMaxTurns(a.MaxTurns),
+	}
+	if llm == nil {
+		llm = a.LLM
+		options = append(options, WithProvider(a.provider))
+		if a.ModelPricingOverride != nil {
+			options = append(options, WithModelPricing(*a.ModelPricingOverride))
+		}
+	}
+	if len(opts.Servers) > 0 {
+		options = append(options, WithServerName(strings.Join(opts.Servers, ",")))
+	}
+	if opts.MaxTurns > 0 {
+		options = append(options, WithMaxTurns(opts.MaxTurns))
+	}
+	// The child may spend what is left of the parent's budget
+	if a.BudgetLimitUSD > 0 {
+		spent := a.GetTotalCost()
+		if spent >= a.BudgetLimitUSD {
+			return nil, &BudgetExceededError{LimitUSD: a.BudgetLimitUSD, SpentUSD: spent}
+		}
+		options = append(options, WithBudgetLimit(a.BudgetLimitUSD-spent))
+	}
+	options = append(options, opts.Options...)
+	options = append(options, func(child *Agent) { child.subAgent = link })
+
+	startTime := time.Now()
+	a.EmitTypedEvent(ctx, events.NewSubAgentStartEvent(id, opts.Task, modelIDOf(llm)))
+	a.Logger.Info("🧩 [SUB_AGENT] Delegating task to sub-agent",
+		loggerv2.String("sub_agent_id", id),
+		loggerv2.String("model_id", modelIDOf(llm)))
+
+	child, err := NewAgent(ctx, llm, a.configPath, options...)
+	if err != nil {
+		err = fmt.Errorf("create sub-agent: %w", err)
+		a.EmitTypedEvent(ctx, &events.SubAgentEndEvent{
+			BaseEventData: events.BaseEventData{Timestamp: time.Now()},
+			SubAgentID:    id,
+			ModelID:       modelIDOf(llm),
+			Error:         err.Error(),
+			Duration:      time.Since(startTime),
+		})
+		return nil, err
+	}
+	defer func() {
+		child.Close()
+		CloseSession(sessionID)
+	}()
+	if opts.SystemPrompt != "" {
+		child.SetSystemPrompt(opts.SystemPrompt)
+	}
+	if len(opts.Tools) > 0 {
+		child.SetToolAllowList(opts.Tools)
+	}
+	child.AddEventListener(subAgentEventForwarder{parent: a})
+
+	answer, messages, askErr := child.AskWithHistory(ctx, []llmtypes.MessageContent{
+		llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, opts.Task),
+	})
+
+	result := &SubAgentResult{
+		ID:       id,
+		ModelID:  child.ModelID,
+		Answer:   answer,
+		Messages: messages,
+		Duration: time.Since(startTime),
+	}
+	result.PromptTokens, result.CompletionTokens, result.TotalTokens, result.LLMCallCount, result.TotalCost = a.addSubAgentUsage(child)
+
+	endEvent := &events.SubAgentEndEvent{
+		BaseEventData:    events.BaseEventData{Timestamp: time.Now()},
+		SubAgentID:       id,
+		ModelID:          child.ModelID,
+		Result:           answer,
+		Duration:         result.Duration,
+		PromptTokens:     result.PromptTokens,
+		CompletionTokens: result.CompletionTokens,
+		TotalTokens:      result.TotalTokens,
+		LLMCallCount:     result.LLMCallCount,
+		TotalCost:        result.TotalCost,
+	}
+	if askErr != nil {
+		endEvent.Error = askErr.Error()
+	}
+	a.EmitTypedEvent(ctx, endEvent)
+	a.Logger.Info("🧩 [SUB_AGENT] Sub-agent finished",
+		loggerv2.String("sub_agent_id", id),
+		loggerv2.Int("total_tokens", result.TotalTokens),
+		loggerv2.String("duration", result.Duration.String()),
+		loggerv2.Any("failed", askErr != nil))
+	return result, askErr
+}
+
+// addSubAgentUsage adds the child's cumulative usage to the parent's totals
+// and returns the child's prompt, completion and total tokens, LLM calls and cost
+func (a *Agent) addSubAgentUsage(child *Agent) (promptTokens, completionTokens, totalTokens, llmCalls int, totalCost float64) {
+	child.tokenTrackingMutex.RLock()
+	prompt, completion, total := child.cumulativePromptTokens, child.cumulativeCompletionTokens, child.cumulativeTotalTokens
+	cache, reasoning, discount := child.cumulativeCacheTokens, child.cumulativeReasoningTokens, child.cumulativeCacheDiscount
+	calls, cacheCalls := child.llmCallCount, child.cacheEnabledCallCount
+	inputCost, outputCost, reasoningCost, cacheCost, cost := child.cumulativeInputCost, child.cumulativeOutputCost, child.cumulativeReasoningCost, child.cumulativeCacheCost, child.cumulativeTotalCost
+	child.tokenTrackingMutex.RUnlock()
+
+	a.tokenTrackingMutex.Lock()
+	a.cumulativePromptTokens += prompt
+	a.cumulativeCompletionTokens += completion
+	a.cumulativeTotalTokens += total
+	a.cumulativeCacheTokens += cache
+	a.cumulativeReasoningTokens += reasoning
+	a.cumulativeCacheDiscount += discount
+	a.llmCallCount += calls
+	a.cacheEnabledCallCount += cacheCalls
+	a.cumulativeInputCost += inputCost
+	a.cumulativeOutputCost += outputCost
+	a.cumulativeReasoningCost += reasoningCost
+	a.cumulativeCacheCost += cacheCost
+	a.cumulativeTotalCost += cost
+	a.tokenTrackingMutex.Unlock()
+	return prompt, completion, total, calls, cost
+}
+
+// modelIDOf returns the model ID of llm, or "" for nil
+func modelIDOf(llm llmtypes.Model) string {
+	if llm == nil {
+		return ""
+	}
+	return llm.GetModelID()
+}
@@ -0,0 +1,94 @@
+package mcpagent
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/manishiitg/mcpagent/events"
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+	"github.com/manishiitg/mcpagent/observability"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+// usageGenerateStage reports the usage of its scripted responses
+type usageGenerateStage struct {
+	scriptedGenerateStage
+}
+
+func (s *usageGenerateStage) Generate(ctx context.Context, a *Agent, messages []llmtypes.MessageContent, opts []llmtypes.CallOption, turn int) (*llmtypes.ContentResponse, observability.UsageMetrics, error) {
+	resp, _, err := s.scriptedGenerateStage.Generate(ctx, a, messages, opts, turn)
+	return resp, observability.UsageMetrics{InputTokens: resp.Usage.InputTokens, OutputTokens: resp.Usage.OutputTokens, TotalTokens: resp.Usage.TotalTokens}, err
+}
+
+func TestSpawnSubAgentNestsEventsAndRollsUpUsage(t *testing.T) {
+	config := filepath.Join(t.TempDir(), "mcp.json")
+	if err := os.WriteFile(config, []byte(`{"mcpServers": {}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	parent, err := NewAgent(ctx, &providerKeyCarrierModel{}, config, WithLogger(loggerv2.NewNoop()), WithSessionID("sub-agent-test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer parent.Close()
+	listener := &recordingAgentEventListener{}
+	parent.AddEventListener(listener)
+	parent.accumulateTokenUsage(ctx, events.UsageMetrics{PromptTokens: 100, CompletionTokens: 10, TotalTokens: 110},
+		&llmtypes.ContentResponse{Usage: &llmtypes.Usage{InputTokens: 100, OutputTokens: 10, TotalTokens: 110}}, 1)
+
+	generate := &usageGenerateStage{scriptedGenerateStage{responses: []*llmtypes.ContentResponse{{
+		Choices: []*llmtypes.ContentChoice{{Content: "3 open bugs"}},
+		Usage:   &llmtypes.Usage{InputTokens: 40, OutputTokens: 5, TotalTokens: 45},
+	}}}}
+	result, err := parent.SpawnSubAgent(ctx, SubAgentOptions{
+		Task:         "count the open bugs",
+		SystemPrompt: "You count bugs.",
+		Tools:        []string{"search_issues"},
+		Options:      []AgentOption{WithAskPipeline(AskPipeline{Generate: generate})},
+	})
+	if err != nil {
+		t.Fatalf("SpawnSubAgent: %v", err)
+	}
+	if system := result.Messages[0].Parts[0].(llmtypes.TextContent).Text; system != "You count bugs." {
+		t.Errorf("sub-agent system prompt = %q", system)
+	}
+	if result.Answer != "3 open bugs" || result.TotalTokens != 45 || result.LLMCallCount != 1 {
+		t.Errorf("result = %+v", result)
+	}
+	if prompt, completion, total, _, _, calls, _ := parent.GetTokenUsage(); prompt != 140 || completion != 15 || total != 155 || calls != 2 {
+		t.Errorf("parent usage = %d/%d/%d over %d calls, want the sub-agent rolled up", prompt, completion, total, calls)
+	}
+
+	var start, end, childEvents int
+	for _, event := range listener.events {
+		switch data := event.Data.(type) {
+		case *events.SubAgentStartEvent:
+			start++
+			if data.Task != "count the open bugs" || data.SubAgentID != result.ID {
+				t.Errorf("start event = %+v", data)
+			}
+		case *events.SubAgentEndEvent:
+			end++
+			if data.Result != "3 open bugs" || data.TotalTokens != 45 || data.Error != "" {
+				t.Errorf("end event = %+v", data)
+			}
+		default:
+			childEvents++
+			if event.SessionID != "sub-agent-test" || event.HierarchyLevel < 1 {
+				t.Errorf("sub-agent event %s has session %q, level %d; want it nested in the parent's session", event.Type, event.SessionID, event.HierarchyLevel)
+			}
+		}
+	}
+	if start != 1 || end != 1 || childEvents == 0 {
+		t.Errorf("got %d start, %d end and %d sub-agent events", start, end, childEvents)
+	}
+}
+
+func TestSpawnSubAgentRequiresTask(t *testing.T) {
+	a := &Agent{Logger: loggerv2.NewNoop()}
+	if _, err := a.SpawnSubAgent(context.Background(), SubAgentOptions{}); err == nil {
+		t.Error("expected an error for an empty task")
+	}
+}
@@ -0,0 +1,288 @@
+// summarization_strategy.go
+//
+// This file defines how context summarization condenses the old part of the
+// conversation. rebuildMessagesWithSummary (see context_summarization.go)
+// still picks the split point, keeps the system prompt and the recent
+// messages and emits the events; the strategy only decides what replaces the
+// old messages:
+//   - FullSummarization: one LLM summary of all old messages (the default)
+//   - RollingSummarization: folds the messages leaving the window into the
+//     running summary of the previous summarization
+//   - MapReduceSummarization: summarizes chunks separately, then merges the
+//     partial summaries, for histories larger than the summarizer's context
+//   - SelectiveSummarization: no LLM call; keeps user messages, tool calls and
+//     tool results and drops the assistant's narration
+//
+// Exported:
+//   - SummarizationStrategy, SummarizationResult: Strategy interface and its result
+//   - FullSummarization, RollingSummarization, MapReduceSummarization, SelectiveSummarization
+//   - WithSummarizationStrategy: Agent option selecting the strategy
+
+package mcpagent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/manishiitg/mcpagent/events"
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+// SummarizationStrategy condenses the old messages of a conversation when
+// context summarization triggers. oldMessages never include the system prompt
+// and never split a tool call from its results.
+type SummarizationStrategy interface {
+	Summarize(ctx context.Context, a *Agent, oldMessages []llmtypes.MessageContent) (SummarizationResult, error)
+}
+
+// SummarizationResult is what a strategy made of the old messages
+type SummarizationResult struct {
+	// Messages replace the old messages, between the system prompt and the recent messages
+	Messages []llmtypes.MessageContent
+	// Summary is the generated summary, "" if the strategy did not write one
+	Summary string
+	// Usage of the strategy's LLM calls, reported in the completed event
+	Usage events.UsageMetrics
+}
+
+// WithSummarizationStrategy selects how context summarization condenses old
+// messages. Requires WithContextSummarization.
+//
+// Example:
+//
+//	mcpagent.WithSummarizationStrategy(mcpagent.MapReduceSummarization{ChunkChars: 20000})
+//
+// Default: nil (FullSummarization)
+func WithSummarizationStrategy(strategy SummarizationStrategy) AgentOption {
+	return func(a *Agent) {
+		a.summarizationStrategy = strategy
+	}
+}
+
+// summaryMarker starts the message a summarization strategy inserts
+const summaryMarker = "=== CONVERSATION SUMMARY"
+
+// newSummaryMessage wraps summary in the user message that replaces count messages
+func newSummaryMessage(count int, summary string) llmtypes.MessageContent {
+	return llmtypes.MessageContent{
+		Role: llmtypes.ChatMessageTypeHuman,
+		Parts: []llmtypes.ContentPart{
+			llmtypes.TextContent{
+				Text: fmt.Sprintf("%s (Previous %d messages) ===\n\n%s\n\n=== END SUMMARY ===", summaryMarker, count, summary),
+			},
+		},
+	}
+}
+
+// parseSummaryMessage returns the summary and message count of a message
+// built by newSummaryMessage
+func parseSummaryMessage(msg llmtypes.MessageContent) (string, int, bool) {
+	if msg.Role != llmtypes.ChatMessageTypeHuman || len(msg.Parts) != 1 {
+		return "", 0, false
+	}
+	text, ok := msg.Parts[0].(llmtypes.TextContent)
+	if !ok || !strings.HasPrefix(text.Text, summaryMarker) {
+		return "", 0, false
+	}
+	var count int
+	if _, err := fmt.Sscanf(text.Text, summaryMarker+" (Previous %d messages)", &count); err != nil {
+		return "", 0, false
+	}
+	_, body, _ := strings.Cut(text.Text, "\n\n")
+	return strings.TrimSuffix(body, "\n\n=== END SUMMARY ==="), count, true
+}
+
+// FullSummarization summarizes all old messages in one LLM call, including a
+// summary left by an earlier summarization
+type FullSummarization struct{}
+
+// Summarize implements SummarizationStrategy
+func (FullSummarization) Summarize(ctx context.Context, a *Agent, oldMessages []llmtypes.MessageContent) (SummarizationResult, error) {
+	summary, usage, err := summarizeConversationHistory(a, ctx, oldMessages)
+	if err != nil {
+		return SummarizationResult{}, err
+	}
+	return SummarizationResult{
+		Messages: []llmtypes.MessageContent{newSummaryMessage(len(oldMessages), summary)},
+		Summary:  summary,
+		Usage:    usage,
+	}, nil
+}
+
+// RollingSummarization keeps one running summary: each summarization only
+// sends the messages that left the window since the last one, together with
+// the previous summary, and asks for an updated summary. Without a previous
+// summary it behaves like FullSummarization.
+type RollingSummarization struct{}
+
+// Summarize implements SummarizationStrategy
+func (RollingSummarization) Summarize(ctx context.Context, a *Agent, oldMessages []llmtypes.MessageContent) (SummarizationResult, error) {
+	if len(oldMessages) == 0 {
+		return FullSummarization{}.Summarize(ctx, a, oldMessages)
+	}
+	previous, previousCount, ok := parseSummaryMessage(oldMessages[0])
+	if !ok {
+		return FullSummarization{}.Summarize(ctx, a, oldMessages)
+	}
+	newMessages := oldMessages[1:]
+	text := fmt.Sprintf("EXISTING SUMMARY:\n\n%s\n\nNEW MESSAGES:\n\n%s", previous, buildConversationTextForSummarization(newMessages))
+	summary, usage, err := GenerateSummary(a, ctx, buildRollingSummarizationPrompt(), text)
+	if err != nil {
+		return SummarizationResult{}, err
+	}
+	return SummarizationResult{
+		Messages: []llmtypes.MessageContent{newSummaryMessage(previousCount+len(newMessages), summary)},
+		Summary:  summary,
+		Usage:    usage,
+	}, nil
+}
+
+// buildRollingSummarizationPrompt creates the prompt for updating a running summary
+func buildRollingSummarizationPrompt() string {
+	return `You maintain the running summary of a long conversation between a user and an AI agent. You receive the EXISTING SUMMARY and the NEW MESSAGES that happened after it.
+
+Return the updated summary in the same format as the existing summary:
+- Keep everything from the existing summary that still matters; do not drop pending tasks, user requests or constraints
+- Add the new requests, findings, decisions, errors and files from the new messages
+- Update the current work and next step to reflect the new messages
+- Preserve ALL file paths, function names, tool names, IDs and specific values exactly
+
+Return only the updated summary.`
+}
+
+// DefaultSummarizationChunkChars is the default chunk size of MapReduceSummarization
+const DefaultSummarizationChunkChars = 40000
+
+// MapReduceSummarization splits the old messages into chunks of about
+// ChunkChars characters, summarizes each chunk (map) and merges the partial
+// summaries into the final summary (reduce). Use it when the old history does
+// not fit the summarizer's context window.
+type MapReduceSummarization struct {
+	// ChunkChars is the size of a chunk in characters of conversation text;
+	// 0 = DefaultSummarizationChunkChars. A message larger than a chunk is its own chunk.
+	ChunkChars int
+}
+
+// Summarize implements SummarizationStrategy
+func (s MapReduceSummarization) Summarize(ctx context.Context, a *Agent, oldMessages []llmtypes.MessageContent) (SummarizationResult, error) {
+	chunkChars := s.ChunkChars
+	if chunkChars <= 0 {
+		chunkChars = DefaultSummarizationChunkChars
+	}
+	chunks := chunkConversationText(conversationTextEntries(oldMessages), chunkChars)
+	if len(chunks) <= 1 {
+		return FullSummarization{}.Summarize(ctx, a, oldMessages)
+	}
+
+	var usage events.UsageMetrics
+	partials := make([]string, 0, len(chunks))
+	for i, chunk := range chunks {
+		partial, partialUsage, err := GenerateSummary(a, ctx, buildChunkSummarizationPrompt(i+1, len(chunks)), chunk)
+		if err != nil {
+			return SummarizationResult{}, fmt.Errorf("chunk %d of %d: %w", i+1, len(chunks), err)
+		}
+		addUsageMetrics(&usage, partialUsage)
+		partials = append(partials, fmt.Sprintf("## Part %d of %d\n\n%s", i+1, len(chunks), partial))
+	}
+	getLogger(a).Info("📊 [CONTEXT_SUMMARIZATION] Merging partial summaries",
+		loggerv2.Int("chunks", len(chunks)))
+
+	summary, reduceUsage, err := GenerateSummary(a, ctx, buildSummarizationPrompt(),
+		"The conversation was summarized in consecutive parts:\n\n"+strings.Join(partials, "\n\n"))
+	if err != nil {
+		return SummarizationResult{}, err
+	}
+	addUsageMetrics(&usage, reduceUsage)
+	return SummarizationResult{
+		Messages: []llmtypes.MessageContent{newSummaryMessage(len(oldMessages), summary)},
+		Summary:  summary,
+		Usage:    usage,
+	}, nil
+}
+
+// chunkConversationText groups consecutive entries into chunks of at most
+// chunkChars characters
+func chunkConversationText(entries []string, chunkChars int) []string {
+	var chunks []string
+	var current []string
+	size := 0
+	for _, entry := range entries {
+		if len(current) > 0 && size+len(entry) > chunkChars {
+			chunks = append(chunks, strings.Join(current, "\n\n"))
+			current, size = nil, 0
+		}
+		current = append(current, entry)
+		size += len(entry) + 2
+	}
+	if len(current) > 0 {
+		chunks = append(chunks, strings.Join(current, "\n\n"))
+	}
+	return chunks
+}
+
+// buildChunkSummarizationPrompt creates the prompt for summarizing one chunk
+func buildChunkSummarizationPrompt(part, total int) string {
+	return fmt.Sprintf(`You summarize part %d of %d of a long conversation between a user and an AI agent. The parts will be merged into one summary later, so describe only what happens in this part.
+
+Include the user's requests, what the agent did and found, decisions, errors and their fixes, and unfinished work. Preserve ALL file paths, function names, tool names, IDs and specific values exactly. Be concise.`, part, total)
+}
+
+// addUsageMetrics adds the token counts of u to total
+func addUsageMetrics(total *events.UsageMetrics, u events.UsageMetrics) {
+	total.PromptTokens += u.PromptTokens
+	total.CompletionTokens += u.CompletionTokens
+	total.TotalTokens += u.TotalTokens
+	total.CacheTokens += u.CacheTokens
+	total.ReasoningTokens += u.ReasoningTokens
+}
+
+// SelectiveSummarization condenses without an LLM call: user messages, tool
+// calls and tool results are kept and the assistant's text ("chatter") is
+// dropped. Useful when tool results are the facts the agent works from.
+type SelectiveSummarization struct {
+	// MaxToolResultChars truncates longer tool results; 0 = keep them whole
+	MaxToolResultChars int
+}
+
+// Summarize implements SummarizationStrategy
+func (s SelectiveSummarization) Summarize(_ context.Context, _ *Agent, oldMessages []llmtypes.MessageContent) (SummarizationResult, error) {
+	kept := make([]llmtypes.MessageContent, 0, len(oldMessages))
+	for _, msg := range oldMessages {
+		switch msg.Role {
+		case llmtypes.ChatMessageTypeAI:
+			// Keep only the tool calls, whose results follow
+			var calls []llmtypes.ContentPart
+			for _, part := range msg.Parts {
+				if _, ok := part.(llmtypes.ToolCall); ok {
+					calls = append(calls, part)
+				}
+			}
+			if len(calls) > 0 {
+				kept = append(kept, llmtypes.MessageContent{Role: msg.Role, Parts: calls})
+			}
+		case llmtypes.ChatMessageTypeTool:
+			kept = append(kept, s.truncateToolResults(msg))
+		default:
+			kept = append(kept, msg)
+		}
+	}
+	return SummarizationResult{Messages: kept}, nil
+}
+
+// truncateToolResults shortens the tool results of msg to MaxToolResultChars
+func (s SelectiveSummarization) truncateToolResults(msg llmtypes.MessageContent) llmtypes.MessageContent {
+	if s.MaxToolResultChars <= 0 {
+		return msg
+	}
+	parts := make([]llmtypes.ContentPart, len(msg.Parts))
+	for i, part := range msg.Parts {
+		if resp, ok := part.(llmtypes.ToolCallResponse); ok && len(resp.Content) > s.MaxToolResultChars {
+			resp.Content = resp.Content[:s.MaxToolResultChars] + fmt.Sprintf("\n... [truncated %d characters]", len(resp.Content)-s.MaxToolResultChars)
+			part = resp
+		}
+		parts[i] = part
+	}
+	return llmtypes.MessageContent{Role: msg.Role, Parts: parts}
+}
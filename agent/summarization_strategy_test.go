@@ -0,0 +1,83 @@
+package mcpagent
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+// summarizationTestHistory returns a system prompt, an exchange with a tool
+// call, and two recent messages
+func summarizationTestHistory() []llmtypes.MessageContent {
+	return []llmtypes.MessageContent{
+		llmtypes.TextParts(llmtypes.ChatMessageTypeSystem, "You are helpful."),
+		llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "Find the open bugs"),
+		{Role: llmtypes.ChatMessageTypeAI, Parts: []llmtypes.ContentPart{
+			llmtypes.TextContent{Text: "Let me look that up for you."},
+			llmtypes.ToolCall{ID: "call-1", Type: "function", FunctionCall: &llmtypes.FunctionCall{Name: "search_issues", Arguments: `{"label":"bug"}`}},
+		}},
+		{Role: llmtypes.ChatMessageTypeTool, Parts: []llmtypes.ContentPart{
+			llmtypes.ToolCallResponse{ToolCallID: "call-1", Name: "search_issues", Content: "#12 crash on start, #15 slow search"},
+		}},
+		llmtypes.TextParts(llmtypes.ChatMessageTypeAI, "There are two open bugs: #12 and #15."),
+		llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "Fix #12"),
+		llmtypes.TextParts(llmtypes.ChatMessageTypeAI, "Working on #12."),
+	}
+}
+
+func TestSelectiveSummarizationKeepsToolResultsAndDropsChatter(t *testing.T) {
+	a := &Agent{Logger: loggerv2.NewNoop(), ModelID: "test-model"}
+	WithSummarizationStrategy(SelectiveSummarization{MaxToolResultChars: 9})(a)
+
+	messages, err := SummarizeConversationHistory(a, context.Background(), summarizationTestHistory(), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// system, user question, tool call (without its text), tool result, 2 recent
+	if len(messages) != 6 {
+		t.Fatalf("got %d messages: %+v", len(messages), messages)
+	}
+	if len(messages[2].Parts) != 1 {
+		t.Errorf("assistant text should be dropped from the tool call message: %+v", messages[2].Parts)
+	}
+	result := messages[3].Parts[0].(llmtypes.ToolCallResponse).Content
+	if !strings.HasPrefix(result, "#12 crash") || !strings.Contains(result, "[truncated") {
+		t.Errorf("tool result = %q", result)
+	}
+	if len(a.sessionSummaries) != 0 {
+		t.Error("selective summarization writes no summary")
+	}
+}
+
+func TestSummaryMessageRoundTrip(t *testing.T) {
+	msg := newSummaryMessage(12, "The user asked for the open bugs.\n\nTwo were found.")
+	summary, count, ok := parseSummaryMessage(msg)
+	if !ok || count != 12 || summary != "The user asked for the open bugs.\n\nTwo were found." {
+		t.Fatalf("parseSummaryMessage = %q, %d, %v", summary, count, ok)
+	}
+	if _, _, ok := parseSummaryMessage(llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "Fix #12")); ok {
+		t.Error("a user message is not a summary")
+	}
+}
+
+func TestChunkConversationText(t *testing.T) {
+	entries := conversationTextEntries(summarizationTestHistory())
+	if len(entries) != 6 || !strings.HasPrefix(entries[0], "[Turn 2] User: Find the open bugs") {
+		t.Fatalf("entries = %q", entries)
+	}
+	chunks := chunkConversationText(entries, 100)
+	if len(chunks) < 2 || strings.Join(chunks, "\n\n") != strings.Join(entries, "\n\n") {
+		t.Fatalf("chunks = %q", chunks)
+	}
+	for _, chunk := range chunks {
+		if len(chunk) > 100 && strings.Contains(chunk, "\n\n") {
+			t.Errorf("chunk over budget holds several entries: %q", chunk)
+		}
+	}
+	if got := chunkConversationText(entries, DefaultSummarizationChunkChars); len(got) != 1 {
+		t.Errorf("a short history is one chunk, got %d", len(got))
+	}
+}
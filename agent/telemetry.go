@@ -0,0 +1,332 @@
+// telemetry.go
+//
+// This file provides opt-in, anonymous feature usage telemetry. An agent
+// created WithTelemetry periodically POSTs an aggregate TelemetryReport to the
+// configured endpoint: which features and modes it runs with, event counts by
+// type and the provider it uses. Reports never contain content: no prompts,
+// answers, tool names or arguments, tool results, model output, server names,
+// session or user IDs. The report schema is documented in docs/telemetry.md.
+//
+// Telemetry is off unless WithTelemetry is used, and MCPAGENT_TELEMETRY_DISABLED
+// or DO_NOT_TRACK turns it off even then.
+//
+// Exported:
+//   - WithTelemetry / TelemetryConfig: Opt in to usage reports
+//   - TelemetryReport: The report schema
+//   - TelemetryDisabledEnv / TelemetrySchemaVersion / DefaultTelemetryInterval
+
+package mcpagent
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/manishiitg/mcpagent/events"
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+)
+
+const (
+	// TelemetryDisabledEnv turns telemetry off for the process when set to
+	// anything but "", "0" or "false" (DO_NOT_TRACK is honored the same way)
+	TelemetryDisabledEnv = "MCPAGENT_TELEMETRY_DISABLED"
+	// TelemetrySchemaVersion is the version of TelemetryReport
+	TelemetrySchemaVersion = 1
+	// DefaultTelemetryInterval is how often reports are sent
+	DefaultTelemetryInterval = time.Hour
+
+	telemetryTimeout = 10 * time.Second
+)
+
+// telemetryInstallationID identifies this process in reports. It is random
+// and never persisted, so reports from different runs cannot be linked.
+var telemetryInstallationID = uuid.NewString()
+
+// TelemetryConfig configures usage reports
+type TelemetryConfig struct {
+	// Endpoint receives the reports as JSON POSTs (required)
+	Endpoint string
+	// Interval between reports; 0 = DefaultTelemetryInterval. A final
+	// report is sent when the agent is closed.
+	Interval time.Duration
+}
+
+// TelemetryReport is the body of a usage report. See docs/telemetry.md.
+type TelemetryReport struct {
+	SchemaVersion  int       `json:"schema_version"`
+	InstallationID string    `json:"installation_id"` // random per process
+	AgentID        string    `json:"agent_id"`        // random per agent
+	PeriodStart    time.Time `json:"period_start"`
+	PeriodEnd      time.Time `json:"period_end"`
+	GoVersion      string    `json:"go_version"`
+	OS             string    `json:"os"`
+	Arch           string    `json:"arch"`
+
+	// Configuration
+	Provider        string   `json:"provider"`
+	AgentMode       string   `json:"agent_mode"`
+	Features        []string `json:"features"` // sorted names of the enabled features
+	MCPServerCount  int      `json:"mcp_server_count"`
+	CustomToolCount int      `json:"custom_tool_count"`
+
+	// Activity during the period
+	Conversations int            `json:"conversations"`
+	LLMCalls      int            `json:"llm_calls"`
+	ToolCalls     int            `json:"tool_calls"`
+	ToolErrors    int            `json:"tool_errors"`
+	EventCounts   map[string]int `json:"event_counts"` // by event type
+}
+
+// WithTelemetry opts in to anonymous, aggregate feature usage reports sent
+// to config.Endpoint (see TelemetryReport for everything a report contains).
+// Ignored when config.Endpoint is empty or telemetry is disabled through
+// TelemetryDisabledEnv or DO_NOT_TRACK.
+//
+// Default: disabled
+func WithTelemetry(config TelemetryConfig) AgentOption {
+	return func(a *Agent) {
+		if strings.TrimSpace(config.Endpoint) == "" || telemetryDisabledByEnv() {
+			return
+		}
+		if config.Interval <= 0 {
+			config.Interval = DefaultTelemetryInterval
+		}
+		a.telemetry = &telemetryReporter{
+			agent:       a,
+			config:      config,
+			agentID:     uuid.NewString(),
+			client:      &http.Client{Timeout: telemetryTimeout},
+			periodStart: time.Now(),
+			eventCounts: make(map[string]int),
+		}
+		a.listeners = append(a.listeners, a.telemetry)
+	}
+}
+
+// telemetryDisabledByEnv reports whether the environment turns telemetry off
+func telemetryDisabledByEnv() bool {
+	for _, name := range []string{TelemetryDisabledEnv, "DO_NOT_TRACK"} {
+		switch strings.ToLower(strings.TrimSpace(os.Getenv(name))) {
+		case "", "0", "false":
+		default:
+			return true
+		}
+	}
+	return false
+}
+
+// telemetryReporter counts events and sends periodic reports
+type telemetryReporter struct {
+	agent   *Agent
+	config  TelemetryConfig
+	agentID string
+	client  *http.Client
+
+	startOnce sync.Once
+	stop      chan struct{}
+	done      chan struct{}
+
+	mu            sync.Mutex
+	closed        bool
+	periodStart   time.Time
+	conversations int
+	llmCalls      int
+	toolCalls     int
+	toolErrors    int
+	eventCounts   map[string]int
+}
+
+// Name implements AgentEventListener
+func (t *telemetryReporter) Name() string {
+	return "telemetry"
+}
+
+// HandleEvent implements AgentEventListener. Only the event type is recorded.
+func (t *telemetryReporter) HandleEvent(_ context.Context, event *events.AgentEvent) error {
+	if event == nil {
+		return nil
+	}
+	t.startOnce.Do(t.start)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.eventCounts[string(event.Type)]++
+	switch event.Type {
+	case events.ConversationStart:
+		t.conversations++
+	case events.LLMGenerationEnd:
+		t.llmCalls++
+	case events.ToolCallStart:
+		t.toolCalls++
+	case events.ToolCallError:
+		t.toolErrors++
+	}
+	return nil
+}
+
+func (t *telemetryReporter) start() {
+	t.stop = make(chan struct{})
+	t.done = make(chan struct{})
+	go func() {
+		defer close(t.done)
+		ticker := time.NewTicker(t.config.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				t.flush()
+			case <-t.stop:
+				return
+			}
+		}
+	}()
+}
+
+// close stops the periodic reports and sends the final one
+func (t *telemetryReporter) close() {
+	t.mu.Lock()
+	if t.closed {
+		t.mu.Unlock()
+		return
+	}
+	t.closed = true
+	t.mu.Unlock()
+
+	t.startOnce.Do(func() {}) // no events: nothing to stop
+	if t.stop != nil {
+		close(t.stop)
+		<-t.done
+	}
+	t.flush()
+}
+
+// flush sends the activity since the last report, if there was any
+func (t *telemetryReporter) flush() {
+	report, ok := t.takeReport()
+	if !ok {
+		return
+	}
+	if err := t.send(report); err != nil {
+		// Telemetry must never disturb the application: report quietly and drop
+		getLogger(t.agent).Debug("Telemetry report not sent", loggerv2.String("endpoint", t.config.Endpoint), loggerv2.Error(err))
+	}
+}
+
+// takeReport builds the report of the current period and starts a new one
+func (t *telemetryReporter) takeReport() (TelemetryReport, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.eventCounts) == 0 {
+		return TelemetryReport{}, false
+	}
+	now := time.Now()
+	a := t.agent
+	report := TelemetryReport{
+		SchemaVersion:   TelemetrySchemaVersion,
+		InstallationID:  telemetryInstallationID,
+		AgentID:         t.agentID,
+		PeriodStart:     t.periodStart.UTC(),
+		PeriodEnd:       now.UTC(),
+		GoVersion:       runtime.Version(),
+		OS:              runtime.GOOS,
+		Arch:            runtime.GOARCH,
+		Provider:        string(a.provider),
+		AgentMode:       string(a.AgentMode),
+		Features:        a.telemetryFeatures(),
+		MCPServerCount:  len(a.Clients),
+		CustomToolCount: len(a.customTools),
+		Conversations:   t.conversations,
+		LLMCalls:        t.llmCalls,
+		ToolCalls:       t.toolCalls,
+		ToolErrors:      t.toolErrors,
+		EventCounts:     t.eventCounts,
+	}
+	t.periodStart = now
+	t.conversations, t.llmCalls, t.toolCalls, t.toolErrors = 0, 0, 0, 0
+	t.eventCounts = make(map[string]int)
+	return report, true
+}
+
+func (t *telemetryReporter) send(report TelemetryReport) error {
+	body, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, t.config.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("telemetry endpoint returned HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// telemetryFeatures returns the sorted names of the features the agent runs with
+func (a *Agent) telemetryFeatures() []string {
+	enabled := map[string]bool{
+		"code_execution":        a.UseCodeExecutionMode,
+		"tool_search":           a.UseToolSearchMode,
+		"streaming":             a.EnableStreaming,
+		"parallel_tools":        a.EnableParallelToolExecution,
+		"context_offloading":    a.EnableContextOffloading,
+		"context_summarization": a.EnableContextSummarization,
+		"context_editing":       a.EnableContextEditing,
+		"grounding_check":       a.Grounding != nil,
+		"glossary":              len(a.Glossary) > 0,
+		"memory":                a.memory != nil,
+		"answer_contract":       a.answerContract != nil,
+		"tool_result_dedup":     a.ToolResultDeduplication != nil,
+		"tool_result_cache":     a.toolResultCache != nil,
+		"tool_middleware":       len(a.toolMiddleware) > 0,
+		"tool_arg_limits":       len(a.ToolArgLimits) > 0,
+		"tool_failure_limit":    a.ToolFailureLimit > 0,
+		"budget_limit":          a.BudgetLimitUSD > 0,
+		"utility_tools":         a.EnableBuiltinUtilityTools,
+		"session_store":         a.SessionStore != nil,
+		"webhooks":              len(a.webhooks) > 0,
+		"raw_llm_log":           a.rawLLMLog != nil,
+		"sub_agent":             a.subAgent != nil,
+		"post_mortem":           a.postMortem != nil,
+		"config_watch":          a.watchConfig,
+		"experiments":           a.experimentName != "",
+		"docker_sandbox":        a.CodeExecutionSandbox == CodeExecutionSandboxDocker,
+		"mcp_sampling":          a.mcpSampling != nil,
+		"tool_output_guard":     a.toolOutputGuard != nil,
+		"fallback_llms":         len(a.fallbackLLMs) > 0,
+		"tool_output_storage":   a.toolOutputStorage != nil,
+		"audit_log":             a.auditLog != nil,
+		"output_guard":          a.outputGuard != nil,
+	}
+	features := make([]string, 0, len(enabled))
+	for name, on := range enabled {
+		if on {
+			features = append(features, name)
+		}
+	}
+	sort.Strings(features)
+	return features
+}
+
+// closeTelemetry sends the final usage report
+func (a *Agent) closeTelemetry() {
+	if a.telemetry != nil {
+		a.telemetry.close()
+	}
+}
@@ -0,0 +1,85 @@
+package mcpagent
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/manishiitg/mcpagent/events"
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+)
+
+func TestTelemetryReportsAggregatesWithoutContent(t *testing.T) {
+	t.Setenv(TelemetryDisabledEnv, "")
+	t.Setenv("DO_NOT_TRACK", "")
+	var mu sync.Mutex
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		bodies = append(bodies, string(body))
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	a := &Agent{Logger: loggerv2.NewNoop(), provider: "openai", AgentMode: SimpleAgent, UseCodeExecutionMode: true, ToolFailureLimit: 3}
+	WithTelemetry(TelemetryConfig{Endpoint: server.URL})(a)
+	if a.telemetry == nil {
+		t.Fatal("WithTelemetry should enable telemetry")
+	}
+	emitWebhookTestEvent(t, a, events.NewConversationStartEvent("what is the secret launch date?", "confidential prompt", 4, "acme-internal"))
+	emitWebhookTestEvent(t, a, events.NewToolCallStartEvent(1, "lookup_customer", events.ToolParams{Arguments: `{"email":"bob@example.com"}`}, "crm", ""))
+	emitWebhookTestEvent(t, a, events.NewToolCallStartEvent(1, "lookup_customer", events.ToolParams{Arguments: `{}`}, "crm", ""))
+	a.closeTelemetry()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(bodies) != 1 {
+		t.Fatalf("got %d reports, want the final report on close", len(bodies))
+	}
+	for _, secret := range []string{"secret launch", "confidential", "acme-internal", "lookup_customer", "bob@example.com", "crm"} {
+		if strings.Contains(bodies[0], secret) {
+			t.Errorf("report leaks %q: %s", secret, bodies[0])
+		}
+	}
+	var report TelemetryReport
+	if err := json.Unmarshal([]byte(bodies[0]), &report); err != nil {
+		t.Fatal(err)
+	}
+	if report.SchemaVersion != TelemetrySchemaVersion || report.Provider != "openai" || report.Conversations != 1 || report.ToolCalls != 2 {
+		t.Errorf("report = %+v", report)
+	}
+	if report.EventCounts[string(events.ToolCallStart)] != 2 {
+		t.Errorf("event counts = %v", report.EventCounts)
+	}
+	if strings.Join(report.Features, ",") != "code_execution,tool_failure_limit" {
+		t.Errorf("features = %v", report.Features)
+	}
+
+	// Nothing new happened: closing again sends nothing
+	a.closeTelemetry()
+	if len(bodies) != 1 {
+		t.Errorf("got %d reports after a second close, want 1", len(bodies))
+	}
+}
+
+func TestTelemetryOffSwitch(t *testing.T) {
+	t.Setenv(TelemetryDisabledEnv, "")
+	t.Setenv("DO_NOT_TRACK", "1")
+	a := &Agent{Logger: loggerv2.NewNoop()}
+	WithTelemetry(TelemetryConfig{Endpoint: "http://127.0.0.1:1/telemetry"})(a)
+	if a.telemetry != nil || len(a.listeners) != 0 {
+		t.Error("DO_NOT_TRACK should disable telemetry")
+	}
+
+	t.Setenv("DO_NOT_TRACK", "")
+	t.Setenv(TelemetryDisabledEnv, "true")
+	WithTelemetry(TelemetryConfig{Endpoint: "http://127.0.0.1:1/telemetry"})(a)
+	if a.telemetry != nil {
+		t.Errorf("%s should disable telemetry", TelemetryDisabledEnv)
+	}
+}
@@ -0,0 +1,215 @@
+// tokenizer.go
+//
+// This file provides the token counting used for context management: the
+// large tool output threshold (WithLargeOutputThreshold), the max tool output
+// limit, the pre-flight context estimate that backs token-threshold
+// summarization and adaptive max tokens, and Agent.EstimateTokens.
+//
+// Only OpenAI publishes its tokenizers as tiktoken encodings, so only OpenAI
+// counts are exact. The other families are approximated with the closest
+// tiktoken encoding, scaled by the family's typical ratio to it; actual
+// counts vary with the text (code, non-English text) by 10% or more:
+//   - OpenAI: o200k_base (GPT-4o, GPT-4.1, GPT-5, o-series) or cl100k_base
+//     (GPT-3.5, GPT-4)
+//   - Anthropic: Claude's tokenizer yields ~15% more tokens than cl100k_base
+//   - Gemini: Gemini's SentencePiece vocabulary tokenizes close to o200k_base
+//   - Llama: Llama 3 and 4 extend cl100k_base; Llama 2 and Code Llama use a
+//     32k SentencePiece vocabulary yielding ~20% more tokens
+//
+// The family comes from the model ID (so Claude on Bedrock or Llama on
+// OpenRouter is counted as Claude or Llama), then the provider. The counts
+// are meant for thresholds with headroom, not for filling the context window
+// to the last token; agents that need exact counts, e.g. from a provider's
+// count-tokens endpoint, can plug in their own Tokenizer with WithTokenizer.
+//
+// Exported:
+//   - Tokenizer / TokenizerFunc: Token counting abstraction
+//   - TokenizerForModel: Built-in tokenizer for a provider and model
+//   - NewOpenAITokenizer / NewAnthropicTokenizer / NewGeminiTokenizer / NewLlamaTokenizer: Built-in tokenizers (exact for OpenAI, approximate otherwise)
+//   - WithTokenizer: Use a custom tokenizer
+//   - Agent.EstimateTokens: Estimated prompt tokens of a message list
+
+package mcpagent
+
+import (
+	"math"
+	"strings"
+	"sync"
+
+	"github.com/pkoukk/tiktoken-go"
+
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+// Tokenizer counts the tokens a model's tokenizer produces for text
+type Tokenizer interface {
+	CountTokens(text string) int
+}
+
+// TokenizerFunc adapts a function to the Tokenizer interface
+type TokenizerFunc func(text string) int
+
+// CountTokens calls f(text)
+func (f TokenizerFunc) CountTokens(text string) int {
+	return f(text)
+}
+
+// Approximate ratios of a family's token count to its base encoding
+const (
+	anthropicTokenScale     = 1.15
+	sentencePieceTokenScale = 1.2
+)
+
+// messageOverheadTokens approximates the role and delimiter tokens every
+// provider adds around a message
+const messageOverheadTokens = 4
+
+// encodingTokenizer counts tokens with a tiktoken encoding, scaled for
+// tokenizers that are not published as tiktoken encodings
+type encodingTokenizer struct {
+	encoding string
+	scale    float64
+}
+
+func (t encodingTokenizer) CountTokens(text string) int {
+	if text == "" {
+		return 0
+	}
+	n := countEncodingTokens(text, t.encoding)
+	if t.scale == 0 || t.scale == 1 {
+		return n
+	}
+	return int(math.Ceil(float64(n) * t.scale))
+}
+
+// NewOpenAITokenizer returns the tokenizer of an OpenAI model
+func NewOpenAITokenizer(modelID string) Tokenizer {
+	m := strings.ToLower(modelID)
+	if i := strings.LastIndex(m, "/"); i >= 0 {
+		m = m[i+1:] // e.g. "openai/gpt-4-turbo" on OpenRouter
+	}
+	if strings.HasPrefix(m, "gpt-3.5") ||
+		(strings.HasPrefix(m, "gpt-4") && !strings.HasPrefix(m, "gpt-4o") && !strings.HasPrefix(m, "gpt-4.1")) {
+		return encodingTokenizer{encoding: "cl100k_base"}
+	}
+	return encodingTokenizer{encoding: "o200k_base"}
+}
+
+// NewAnthropicTokenizer returns an approximate tokenizer for Claude models:
+// Anthropic does not publish its tokenizer, so counts are cl100k_base scaled
+// by the typical ratio
+func NewAnthropicTokenizer() Tokenizer {
+	return encodingTokenizer{encoding: "cl100k_base", scale: anthropicTokenScale}
+}
+
+// NewGeminiTokenizer returns an approximate tokenizer for Gemini and Gemma
+// models, counting with o200k_base
+func NewGeminiTokenizer() Tokenizer {
+	return encodingTokenizer{encoding: "o200k_base"}
+}
+
+// NewLlamaTokenizer returns the tokenizer of a Llama-family model: close to
+// exact for Llama 3 and 4, approximate for Llama 2 and Code Llama
+func NewLlamaTokenizer(modelID string) Tokenizer {
+	m := strings.ToLower(modelID)
+	if strings.Contains(m, "llama-2") || strings.Contains(m, "llama2") || strings.Contains(m, "codellama") {
+		return encodingTokenizer{encoding: "cl100k_base", scale: sentencePieceTokenScale}
+	}
+	return encodingTokenizer{encoding: "cl100k_base"}
+}
+
+// TokenizerForModel returns the built-in tokenizer for a model, chosen by the
+// model family in modelID and otherwise by provider. Only OpenAI models get
+// exact counts (see the file comment).
+func TokenizerForModel(provider, modelID string) Tokenizer {
+	m := strings.ToLower(modelID)
+	switch {
+	case strings.Contains(m, "claude"):
+		return NewAnthropicTokenizer()
+	case strings.Contains(m, "gemini"), strings.Contains(m, "gemma"):
+		return NewGeminiTokenizer()
+	case strings.Contains(m, "llama"):
+		return NewLlamaTokenizer(modelID)
+	}
+	switch strings.ToLower(provider) {
+	case "anthropic", "claude-code":
+		return NewAnthropicTokenizer()
+	case "vertex", "google", "agy-cli":
+		return NewGeminiTokenizer()
+	}
+	return NewOpenAITokenizer(modelID)
+}
+
+// WithTokenizer sets the tokenizer used for context management, e.g. one
+// backed by the provider's count-tokens endpoint.
+//
+// Default: TokenizerForModel for the agent's provider and model
+func WithTokenizer(tokenizer Tokenizer) AgentOption {
+	return func(a *Agent) {
+		a.tokenizer = tokenizer
+	}
+}
+
+// tokenizerFor returns the tokenizer of the agent
+func (a *Agent) tokenizerFor() Tokenizer {
+	if a.tokenizer != nil {
+		return a.tokenizer
+	}
+	if a.toolOutputHandler != nil {
+		return a.toolOutputHandler.tokenizerFor(a.ModelID)
+	}
+	return TokenizerForModel(string(a.provider), a.ModelID)
+}
+
+// EstimateTokens estimates the prompt tokens of messages for the agent's
+// model: text, tool calls and tool results, plus a per-message overhead.
+// Images and documents are not counted. The estimate is approximate unless
+// the model is an OpenAI model or WithTokenizer supplies exact counts.
+func (a *Agent) EstimateTokens(messages []llmtypes.MessageContent) int {
+	return estimateMessageTokens(a.tokenizerFor(), messages)
+}
+
+func estimateMessageTokens(tokenizer Tokenizer, messages []llmtypes.MessageContent) int {
+	total := 0
+	for _, msg := range messages {
+		total += messageOverheadTokens
+		for _, part := range msg.Parts {
+			switch p := part.(type) {
+			case llmtypes.TextContent:
+				total += tokenizer.CountTokens(p.Text)
+			case llmtypes.ToolCall:
+				if p.FunctionCall != nil {
+					total += tokenizer.CountTokens(p.FunctionCall.Name) + tokenizer.CountTokens(p.FunctionCall.Arguments)
+				}
+			case llmtypes.ToolCallResponse:
+				total += tokenizer.CountTokens(p.Name) + tokenizer.CountTokens(p.Content)
+			case string:
+				total += tokenizer.CountTokens(p)
+			}
+		}
+	}
+	return total
+}
+
+// tiktokenEncoding is a lazily loaded encoding; a failed load (e.g. offline,
+// since tiktoken downloads encodings on first use) is not retried
+type tiktokenEncoding struct {
+	once     sync.Once
+	encoding *tiktoken.Tiktoken
+}
+
+var tiktokenEncodings sync.Map // encoding name -> *tiktokenEncoding
+
+// countEncodingTokens counts text with a tiktoken encoding, or approximates
+// 4 characters per token when the encoding is unavailable
+var countEncodingTokens = func(text, encoding string) int {
+	value, _ := tiktokenEncodings.LoadOrStore(encoding, &tiktokenEncoding{})
+	enc := value.(*tiktokenEncoding)
+	enc.once.Do(func() {
+		enc.encoding, _ = tiktoken.GetEncoding(encoding)
+	})
+	if enc.encoding == nil {
+		return (len(text) + 3) / 4
+	}
+	return len(enc.encoding.Encode(text, nil, nil))
+}
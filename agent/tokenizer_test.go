@@ -0,0 +1,78 @@
+package mcpagent
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+// stubEncodings counts one token per word, 100 more for o200k_base so tests
+// can tell the encodings apart without downloading them
+func stubEncodings(t *testing.T) {
+	t.Helper()
+	original := countEncodingTokens
+	countEncodingTokens = func(text, encoding string) int {
+		n := len(strings.Fields(text))
+		if encoding == "o200k_base" {
+			n += 100
+		}
+		return n
+	}
+	t.Cleanup(func() { countEncodingTokens = original })
+}
+
+func TestTokenizerForModelPicksFamily(t *testing.T) {
+	stubEncodings(t)
+	text := strings.Repeat("word ", 20)
+
+	for _, tc := range []struct {
+		provider, model string
+		want            int
+	}{
+		{"openai", "gpt-4o-mini", 120},
+		{"openai", "gpt-4-turbo", 20},
+		{"openrouter", "openai/gpt-4", 20},
+		{"anthropic", "claude-sonnet-4-20250514", 23},
+		{"bedrock", "us.anthropic.claude-3-5-sonnet", 23},
+		{"vertex", "gemini-2.5-pro", 120},
+		{"openrouter", "meta-llama/llama-3.3-70b-instruct", 20},
+		{"bedrock", "meta.llama2-70b-chat", 24},
+		{"vertex", "some-tuned-model", 120},
+	} {
+		if got := TokenizerForModel(tc.provider, tc.model).CountTokens(text); got != tc.want {
+			t.Errorf("TokenizerForModel(%q, %q) counted %d tokens, want %d", tc.provider, tc.model, got, tc.want)
+		}
+	}
+}
+
+func TestEstimateTokensCountsToolCallsAndCustomTokenizer(t *testing.T) {
+	stubEncodings(t)
+	messages := []llmtypes.MessageContent{
+		llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "list the open issues"),
+		{Role: llmtypes.ChatMessageTypeAI, Parts: []llmtypes.ContentPart{llmtypes.ToolCall{
+			ID:           "call-1",
+			FunctionCall: &llmtypes.FunctionCall{Name: "list_issues", Arguments: `{"state": "open"}`},
+		}}},
+		{Role: llmtypes.ChatMessageTypeTool, Parts: []llmtypes.ContentPart{llmtypes.ToolCallResponse{
+			ToolCallID: "call-1", Name: "list_issues", Content: "#1 crash on start",
+		}}},
+	}
+
+	a := &Agent{ModelID: "llama-3.1-8b"}
+	// 3 messages of overhead + 4 words + (1 + 2) for the call + (1 + 4) for the result
+	if got, want := a.EstimateTokens(messages), 3*messageOverheadTokens+4+3+5; got != want {
+		t.Errorf("EstimateTokens() = %d, want %d", got, want)
+	}
+
+	WithTokenizer(TokenizerFunc(func(text string) int { return len(text) }))(a)
+	if got, want := a.EstimateTokens(messages[:1]), messageOverheadTokens+len("list the open issues"); got != want {
+		t.Errorf("EstimateTokens() with a custom tokenizer = %d, want %d", got, want)
+	}
+
+	handler := NewToolOutputHandler()
+	handler.Tokenizer = a.tokenizer
+	if got := handler.CountTokensForModel("twelve chars", "gpt-4o"); got != 12 {
+		t.Errorf("CountTokensForModel() = %d, want the custom tokenizer's 12", got)
+	}
+}
@@ -0,0 +1,48 @@
+// tool_annotations.go
+//
+// This file looks up the annotations (readOnlyHint, destructiveHint, ...) MCP
+// servers declare for their tools. Tool definitions reach the agent as LLM
+// tools, which drop the annotations, so a server's tools are listed again the
+// first time one of its annotations is needed and the annotations are kept
+// for the agent's lifetime.
+
+package mcpagent
+
+import (
+	"context"
+
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+	"github.com/manishiitg/mcpagent/mcpclient"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// mcpToolAnnotations returns the annotations server declares for tool,
+// listing the server's tools through client on first use. ok is false when
+// the server cannot be listed or does not have the tool.
+func (a *Agent) mcpToolAnnotations(ctx context.Context, client mcpclient.ClientInterface, server, tool string) (mcp.ToolAnnotation, bool) {
+	a.toolAnnotationsMu.Lock()
+	defer a.toolAnnotationsMu.Unlock()
+	annotations, listed := a.toolAnnotations[server]
+	if !listed {
+		if client == nil {
+			return mcp.ToolAnnotation{}, false
+		}
+		tools, err := client.ListTools(ctx)
+		if err != nil {
+			getLogger(a).Warn("Failed to list tool annotations",
+				loggerv2.String("server", server),
+				loggerv2.Error(err))
+			return mcp.ToolAnnotation{}, false
+		}
+		annotations = make(map[string]mcp.ToolAnnotation, len(tools))
+		for _, t := range tools {
+			annotations[t.Name] = t.Annotations
+		}
+		if a.toolAnnotations == nil {
+			a.toolAnnotations = make(map[string]map[string]mcp.ToolAnnotation)
+		}
+		a.toolAnnotations[server] = annotations
+	}
+	annotation, ok := annotations[tool]
+	return annotation, ok
+}
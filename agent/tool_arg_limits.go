@@ -0,0 +1,188 @@
+// tool_arg_limits.go
+//
+// This file enforces per-tool argument size limits. A model sometimes passes
+// a huge value to a tool (a whole document as a search query, a 2MB string to
+// search_emails); the limits catch those calls before the tool runs. An
+// oversized call is either rejected with a corrective error the model can act
+// on, or its string arguments are truncated and the tool runs with a note
+// appended to its result. Every intervention emits a tool_argument_limited
+// event. Limits apply to every tool (MCP, custom and virtual) in sequential
+// and parallel dispatch, before tool middleware.
+//
+// Exported:
+//   - ToolArgLimitPolicy: What happens to an oversized call (reject or truncate)
+//   - ToolArgLimit: Size limits for one tool
+//   - WithToolArgLimits: Configure limits when creating an agent
+
+package mcpagent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/manishiitg/mcpagent/events"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// ToolArgLimitPolicy decides what happens to a call whose arguments are too large
+type ToolArgLimitPolicy string
+
+const (
+	// ToolArgLimitReject returns an error result to the model without running the tool
+	ToolArgLimitReject ToolArgLimitPolicy = "reject"
+	// ToolArgLimitTruncate cuts oversized string arguments to the limit and
+	// runs the tool. Oversized non-string arguments are still rejected.
+	ToolArgLimitTruncate ToolArgLimitPolicy = "truncate"
+)
+
+// ToolArgLimitAll is the WithToolArgLimits key whose limit applies to tools without their own entry
+const ToolArgLimitAll = "*"
+
+// ToolArgLimit bounds the size of one tool's arguments. Sizes are in bytes:
+// the length of a string value, or the JSON encoding of any other value.
+type ToolArgLimit struct {
+	// MaxBytes limits each argument value; 0 = unlimited
+	MaxBytes int `json:"max_bytes,omitempty"`
+	// PerArgument overrides MaxBytes for individual arguments, keyed by argument name
+	PerArgument map[string]int `json:"per_argument,omitempty"`
+	// MaxTotalBytes limits the JSON encoding of all arguments together; 0 =
+	// unlimited. Exceeding it rejects the call whatever the policy.
+	MaxTotalBytes int `json:"max_total_bytes,omitempty"`
+	// Policy applies to arguments over their limit. Default: ToolArgLimitReject
+	Policy ToolArgLimitPolicy `json:"policy,omitempty"`
+}
+
+// WithToolArgLimits sets argument size limits keyed by tool name. The
+// ToolArgLimitAll ("*") entry applies to tools without their own entry.
+//
+// Example:
+//
+//	mcpagent.WithToolArgLimits(map[string]mcpagent.ToolArgLimit{
+//	    "*":             {MaxTotalBytes: 256 * 1024},
+//	    "search_emails": {PerArgument: map[string]int{"query": 1024}, Policy: mcpagent.ToolArgLimitTruncate},
+//	})
+//
+// Default: nil (no limits)
+func WithToolArgLimits(limits map[string]ToolArgLimit) AgentOption {
+	return func(a *Agent) {
+		if a.ToolArgLimits == nil {
+			a.ToolArgLimits = make(map[string]ToolArgLimit, len(limits))
+		}
+		for name, limit := range limits {
+			a.ToolArgLimits[name] = limit
+		}
+	}
+}
+
+// toolArgLimit returns the limit that applies to toolName and whether there is one
+func (a *Agent) toolArgLimit(toolName string) (ToolArgLimit, bool) {
+	if limit, ok := a.ToolArgLimits[toolName]; ok {
+		return limit, true
+	}
+	limit, ok := a.ToolArgLimits[ToolArgLimitAll]
+	return limit, ok
+}
+
+// enforceToolArgLimits checks call against its tool's limit. It returns a
+// result to hand the model instead of running the tool when the call is
+// rejected, and otherwise a note to append to the tool's result when string
+// arguments were truncated in place.
+func (a *Agent) enforceToolArgLimits(ctx context.Context, call *ToolInvocation) (rejected *mcp.CallToolResult, note string) {
+	limit, ok := a.toolArgLimit(call.Name)
+	if !ok {
+		return nil, ""
+	}
+
+	var violations []events.ToolArgumentViolation
+	names := make([]string, 0, len(call.Arguments))
+	for name := range call.Arguments {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		max := limit.MaxBytes
+		if perArg, ok := limit.PerArgument[name]; ok {
+			max = perArg
+		}
+		if max <= 0 {
+			continue
+		}
+		if size := argumentSize(call.Arguments[name]); size > max {
+			violations = append(violations, events.ToolArgumentViolation{Argument: name, SizeBytes: size, LimitBytes: max})
+		}
+	}
+	if limit.MaxTotalBytes > 0 {
+		if encoded, err := json.Marshal(call.Arguments); err == nil && len(encoded) > limit.MaxTotalBytes {
+			violations = append(violations, events.ToolArgumentViolation{SizeBytes: len(encoded), LimitBytes: limit.MaxTotalBytes})
+		}
+	}
+	if len(violations) == 0 {
+		return nil, ""
+	}
+
+	if limit.Policy == ToolArgLimitTruncate && truncatable(call.Arguments, violations) {
+		truncated := make(map[string]interface{}, len(call.Arguments))
+		for name, value := range call.Arguments {
+			truncated[name] = value
+		}
+		var notes []string
+		for _, v := range violations {
+			truncated[v.Argument] = truncateUTF8(truncated[v.Argument].(string), v.LimitBytes)
+			notes = append(notes, fmt.Sprintf("%s (%d bytes, cut to %d)", v.Argument, v.SizeBytes, v.LimitBytes))
+		}
+		call.Arguments = truncated
+		a.EmitTypedEvent(ctx, events.NewToolArgumentLimitedEvent(call.Turn, call.Name, call.ServerName, call.ID, string(ToolArgLimitTruncate), violations))
+		return nil, fmt.Sprintf("Note: arguments of %s were truncated to their size limits before the call: %s. The result may be incomplete.", call.Name, strings.Join(notes, ", "))
+	}
+
+	a.EmitTypedEvent(ctx, events.NewToolArgumentLimitedEvent(call.Turn, call.Name, call.ServerName, call.ID, string(ToolArgLimitReject), violations))
+	var problems []string
+	for _, v := range violations {
+		if v.Argument == "" {
+			problems = append(problems, fmt.Sprintf("the arguments total %d bytes (limit %d)", v.SizeBytes, v.LimitBytes))
+		} else {
+			problems = append(problems, fmt.Sprintf("argument %q is %d bytes (limit %d)", v.Argument, v.SizeBytes, v.LimitBytes))
+		}
+	}
+	return mcp.NewToolResultError(fmt.Sprintf("tool %s was not run: %s. Retry with shorter arguments, e.g. a concise query or the input split across several calls.", call.Name, strings.Join(problems, "; "))), ""
+}
+
+// truncatable reports whether every violation is a single string argument
+func truncatable(arguments map[string]interface{}, violations []events.ToolArgumentViolation) bool {
+	for _, v := range violations {
+		if v.Argument == "" {
+			return false
+		}
+		if _, ok := arguments[v.Argument].(string); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// argumentSize returns the size of an argument value in bytes
+func argumentSize(value interface{}) int {
+	if s, ok := value.(string); ok {
+		return len(s)
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return 0
+	}
+	return len(encoded)
+}
+
+// truncateUTF8 cuts s to at most max bytes without splitting a UTF-8 sequence
+func truncateUTF8(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	for max > 0 && !utf8.RuneStart(s[max]) {
+		max--
+	}
+	return s[:max]
+}
@@ -0,0 +1,73 @@
+package mcpagent
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/manishiitg/mcpagent/events"
+)
+
+func TestToolArgLimitsRejectOversizedArguments(t *testing.T) {
+	var received map[string]interface{}
+	a := middlewareTestAgent(&received)
+	listener := &recordingAgentEventListener{}
+	a.AddEventListener(listener)
+	WithToolArgLimits(map[string]ToolArgLimit{ToolArgLimitAll: {MaxBytes: 2}})(a)
+
+	result := runMiddlewareTestCall(a)
+	if received != nil {
+		t.Error("a rejected call should not run the tool")
+	}
+	if result.result == nil || !result.result.IsError || !strings.Contains(result.resultText, `argument "user" is 3 bytes (limit 2)`) {
+		t.Errorf("got %+v, want the corrective error result", result)
+	}
+	var limited *events.ToolArgumentLimitedEvent
+	for _, e := range listener.events {
+		if data, ok := e.Data.(*events.ToolArgumentLimitedEvent); ok {
+			limited = data
+		}
+	}
+	if limited == nil || limited.Action != "reject" || limited.ToolName != "lookup" || len(limited.Violations) != 1 {
+		t.Errorf("event = %+v, want a reject event for lookup", limited)
+	}
+}
+
+func TestToolArgLimitsTruncateStringArguments(t *testing.T) {
+	var received map[string]interface{}
+	a := middlewareTestAgent(&received)
+	WithToolArgLimits(map[string]ToolArgLimit{
+		ToolArgLimitAll: {MaxBytes: 1},
+		"lookup":        {PerArgument: map[string]int{"user": 2}, Policy: ToolArgLimitTruncate},
+	})(a)
+
+	result := runMiddlewareTestCall(a)
+	if received["user"] != "bo" {
+		t.Errorf("tool received %v, want the truncated argument", received)
+	}
+	if !strings.Contains(result.resultText, "user=bo") || !strings.Contains(result.resultText, "user (3 bytes, cut to 2)") {
+		t.Errorf("result = %q, want the tool output and a truncation note", result.resultText)
+	}
+}
+
+func TestToolArgLimitsTotalAndNonStringArguments(t *testing.T) {
+	a := &Agent{}
+	WithToolArgLimits(map[string]ToolArgLimit{"search": {MaxBytes: 5, MaxTotalBytes: 100, Policy: ToolArgLimitTruncate}})(a)
+
+	call := &ToolInvocation{Name: "search", Arguments: map[string]interface{}{"ids": []interface{}{1, 2, 3}}}
+	if rejected, _ := a.enforceToolArgLimits(t.Context(), call); rejected == nil {
+		t.Error("an oversized non-string argument cannot be truncated and should be rejected")
+	}
+	call = &ToolInvocation{Name: "search", Arguments: map[string]interface{}{"a": "x", "b": strings.Repeat("y", 200)}}
+	a.ToolArgLimits["search"] = ToolArgLimit{MaxTotalBytes: 100, Policy: ToolArgLimitTruncate}
+	if rejected, _ := a.enforceToolArgLimits(t.Context(), call); rejected == nil {
+		t.Error("exceeding the total limit should reject the call")
+	}
+	call = &ToolInvocation{Name: "other", Arguments: map[string]interface{}{"q": strings.Repeat("z", 200)}}
+	if rejected, note := a.enforceToolArgLimits(t.Context(), call); rejected != nil || note != "" {
+		t.Error("tools without a limit should be unaffected")
+	}
+
+	if got := truncateUTF8("héllo", 2); got != "h" {
+		t.Errorf("truncateUTF8 = %q, want the cut before the multi-byte rune", got)
+	}
+}
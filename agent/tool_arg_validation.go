@@ -0,0 +1,150 @@
+// tool_arg_validation.go
+//
+// This file checks tool call arguments against the tool's input schema
+// before the tool runs. Arguments generated by the LLM sometimes miss a
+// required field or pass a string where a number is expected; sent as is,
+// the MCP server fails with an error that rarely says which field is wrong.
+// Instead, such a call is not run: the LLM gets an error result naming the
+// bad fields, and a tool_args_validation_failed event is emitted.
+//
+// Validation covers MCP and custom tools (virtual tools check their own
+// arguments) and supports the schema keywords used by tool definitions:
+// type, enum, required, properties, additionalProperties, items, minItems
+// and maxItems (see answer_contract.go). It runs at the end of the tool
+// middleware chain, on the arguments as middleware left them. It is on by
+// default; WithToolArgValidation(false) turns it off.
+//
+// Exported:
+//   - WithToolArgValidation: Turn schema validation of tool arguments on or off
+
+package mcpagent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/manishiitg/mcpagent/events"
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// WithToolArgValidation turns checking MCP and custom tool arguments against
+// the tool's input schema on or off. Calls with invalid arguments are not run;
+// the LLM is told which fields to fix.
+//
+// Default: true
+func WithToolArgValidation(enabled bool) AgentOption {
+	return func(a *Agent) {
+		a.DisableToolArgValidation = !enabled
+	}
+}
+
+// argsValidatedExec wraps execute, the innermost step of the tool middleware
+// chain, so validation sees the arguments as middleware left them
+func (a *Agent) argsValidatedExec(execute ToolExecFunc) ToolExecFunc {
+	return func(ctx context.Context, call *ToolInvocation) (*mcp.CallToolResult, error) {
+		if rejected := a.validateToolArgs(ctx, call); rejected != nil {
+			return rejected, nil
+		}
+		return execute(ctx, call)
+	}
+}
+
+// validateToolArgs returns an error result when call's arguments do not
+// match its tool's input schema, nil otherwise
+func (a *Agent) validateToolArgs(ctx context.Context, call *ToolInvocation) *mcp.CallToolResult {
+	if a.DisableToolArgValidation || (call.Type != "MCP" && call.Type != "custom") {
+		return nil
+	}
+	schema := a.toolInputSchema(call.Name)
+	if schema == nil {
+		return nil
+	}
+	// Round-trip the arguments so values have their decoded JSON types
+	// whatever middleware or transformers stored in the map
+	var args interface{} = map[string]interface{}{}
+	if call.Arguments != nil {
+		encoded, err := json.Marshal(call.Arguments)
+		if err != nil {
+			return nil
+		}
+		if err := json.Unmarshal(encoded, &args); err != nil {
+			return nil
+		}
+	}
+	var violations []string
+	validateContractValue(schema, args, "$", &violations)
+	if len(violations) == 0 {
+		return nil
+	}
+
+	fields := make([]string, 0, len(violations))
+	problems := make([]string, 0, len(violations))
+	for _, violation := range violations {
+		field, problem := toolArgViolation(violation)
+		fields = append(fields, field)
+		problems = append(problems, problem)
+	}
+	a.EmitTypedEvent(ctx, events.NewToolArgsValidationFailedEvent(call.Turn, call.Name, call.ServerName, call.ID, fields, problems))
+	getLogger(a).Info("🧾 [TOOL_ARGS] Tool call arguments do not match the input schema; not running it",
+		loggerv2.String("tool", call.Name),
+		loggerv2.Any("problems", problems))
+	return mcp.NewToolResultError(fmt.Sprintf("Invalid arguments: %s was not run because its arguments do not match its input schema: %s. Fix these fields and call it again.",
+		call.Name, strings.Join(problems, "; ")))
+}
+
+// toolArgViolation turns a validateContractValue violation ("$.filters.limit:
+// expected integer, got string") into the field at fault ("filters.limit")
+// and a message naming it
+func toolArgViolation(violation string) (field, problem string) {
+	path, message, _ := strings.Cut(violation, ": ")
+	field = strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+	// Missing and unexpected properties are reported on their parent object
+	if i := strings.Index(message, "property \""); i >= 0 && strings.HasSuffix(message, "\"") {
+		name := message[i+len("property \"") : len(message)-1]
+		if field == "" {
+			field = name
+		} else {
+			field += "." + name
+		}
+		return field, message[:i] + "argument \"" + field + "\""
+	}
+	if field == "" {
+		return "", "arguments: " + message
+	}
+	return field, fmt.Sprintf("argument %q: %s", field, message)
+}
+
+// toolInputSchema returns the input schema of the tool named name, or nil
+// when the tool or its schema is unknown
+func (a *Agent) toolInputSchema(name string) map[string]interface{} {
+	var parameters *llmtypes.Parameters
+	if tool, ok := a.customTools[name]; ok && tool.Definition.Function != nil {
+		parameters = tool.Definition.Function.Parameters
+	} else {
+	search:
+		for _, group := range [][]llmtypes.Tool{a.Tools, a.allMCPToolDefs, a.allDeferredTools} {
+			for _, tool := range group {
+				if tool.Function != nil && tool.Function.Name == name {
+					parameters = tool.Function.Parameters
+					break search
+				}
+			}
+		}
+	}
+	if parameters == nil {
+		return nil
+	}
+	encoded, err := json.Marshal(parameters)
+	if err != nil {
+		return nil
+	}
+	var schema map[string]interface{}
+	if err := json.Unmarshal(encoded, &schema); err != nil || len(schema) == 0 {
+		return nil
+	}
+	return schema
+}
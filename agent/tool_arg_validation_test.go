@@ -0,0 +1,91 @@
+package mcpagent
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/manishiitg/mcpagent/events"
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+func TestToolArgValidationRejectsCallsNotMatchingTheSchema(t *testing.T) {
+	listener := &recordingAgentEventListener{}
+	a := &Agent{Logger: loggerv2.NewNoop(), listeners: []AgentEventListener{listener}}
+	a.Tools = []llmtypes.Tool{{Type: "function", Function: &llmtypes.FunctionDefinition{
+		Name: "search_issues",
+		Parameters: llmtypes.NewParameters(map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"query": map[string]interface{}{"type": "string"},
+				"state": map[string]interface{}{"type": "string", "enum": []interface{}{"open", "closed"}},
+				"filters": map[string]interface{}{
+					"type":       "object",
+					"properties": map[string]interface{}{"limit": map[string]interface{}{"type": "integer"}},
+				},
+			},
+			"required": []interface{}{"query"},
+		}),
+	}}}
+
+	ran := 0
+	execute := func(context.Context, *ToolInvocation) (*mcp.CallToolResult, error) {
+		ran++
+		return mcp.NewToolResultText("ok"), nil
+	}
+	call := func(args map[string]interface{}) *mcp.CallToolResult {
+		result, err := a.executeWithToolMiddleware(context.Background(),
+			&ToolInvocation{ID: "call_1", Name: "search_issues", ServerName: "github", Type: "MCP", Turn: 1, Arguments: args}, execute)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result
+	}
+
+	if result := call(map[string]interface{}{"query": "crash", "filters": map[string]interface{}{"limit": 10}}); result.IsError {
+		t.Fatalf("valid call rejected: %+v", result.Content)
+	}
+	result := call(map[string]interface{}{"state": "stale", "filters": map[string]interface{}{"limit": "ten"}})
+	text := result.Content[0].(mcp.TextContent).Text
+	if !result.IsError || ran != 1 {
+		t.Fatalf("invalid call ran (runs = %d): %q", ran, text)
+	}
+	for _, want := range []string{`missing required argument "query"`, `argument "state": must be one of ["open","closed"]`, `argument "filters.limit": expected integer, got string`} {
+		if !strings.Contains(text, want) {
+			t.Errorf("result %q does not contain %q", text, want)
+		}
+	}
+
+	var failed []*events.ToolArgsValidationFailedEvent
+	for _, e := range listener.events {
+		if ev, ok := e.Data.(*events.ToolArgsValidationFailedEvent); ok {
+			failed = append(failed, ev)
+		}
+	}
+	if len(failed) != 1 || !reflect.DeepEqual(failed[0].Fields, []string{"query", "filters.limit", "state"}) || failed[0].ToolCallID != "call_1" {
+		t.Errorf("tool_args_validation_failed events = %+v", failed)
+	}
+
+	// Off: the server gets the call as is
+	WithToolArgValidation(false)(a)
+	if result := call(map[string]interface{}{}); result.IsError || ran != 2 {
+		t.Errorf("call with validation off rejected: %+v", result.Content)
+	}
+}
+
+func TestToolArgValidationSkipsToolsWithoutSchema(t *testing.T) {
+	a := &Agent{Logger: loggerv2.NewNoop(), Tools: []llmtypes.Tool{hintTestTool("ping")}}
+	if a.validateToolArgs(context.Background(), &ToolInvocation{Name: "ping", Type: "MCP", Arguments: map[string]interface{}{"x": 1}}) != nil {
+		t.Error("tool without parameters rejected")
+	}
+	if a.validateToolArgs(context.Background(), &ToolInvocation{Name: "unknown", Type: "MCP"}) != nil {
+		t.Error("unknown tool rejected")
+	}
+	if a.validateToolArgs(context.Background(), &ToolInvocation{Name: "get_prompt", Type: "virtual"}) != nil {
+		t.Error("virtual tools are not validated")
+	}
+}
@@ -0,0 +1,149 @@
+// tool_disablement.go
+//
+// This file disables tools that keep failing within a conversation. With
+// WithToolFailureLimit(k), a tool whose calls fail k times in one
+// conversation (error results, execution errors, timeouts) is removed from
+// the toolset for the remaining turns, a note telling the model the tool is
+// unavailable is added to the history, and a ToolDisabledForConversation
+// event is emitted. Calls the model still makes to the tool are rejected
+// without running it. The counts start over with every conversation.
+//
+// Exported:
+//   - WithToolFailureLimit: Disable tools after k failures per conversation
+//   - Agent.DisabledToolsForConversation: The tools disabled so far
+
+package mcpagent
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/manishiitg/mcpagent/events"
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// toolFailureTracker counts failed calls per tool for the current conversation
+type toolFailureTracker struct {
+	mu       sync.Mutex
+	failures map[string]int
+	disabled map[string]bool
+}
+
+// WithToolFailureLimit disables a tool for the rest of a conversation once k
+// of its calls have failed in that conversation, so the model stops retrying
+// a broken connector and works with the remaining tools. k <= 0 disables the
+// limit.
+//
+// Default: 0 (tools are never disabled)
+func WithToolFailureLimit(k int) AgentOption {
+	return func(a *Agent) {
+		if k < 0 {
+			k = 0
+		}
+		a.ToolFailureLimit = k
+	}
+}
+
+// DisabledToolsForConversation returns the tools disabled in the current (or
+// last) conversation because of repeated failures, sorted by name
+func (a *Agent) DisabledToolsForConversation() []string {
+	a.toolFailures.mu.Lock()
+	defer a.toolFailures.mu.Unlock()
+	names := make([]string, 0, len(a.toolFailures.disabled))
+	for name := range a.toolFailures.disabled {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// resetToolFailures starts the failure counts of a new conversation
+func (a *Agent) resetToolFailures() {
+	a.toolFailures.mu.Lock()
+	defer a.toolFailures.mu.Unlock()
+	a.toolFailures.failures = nil
+	a.toolFailures.disabled = nil
+}
+
+// isToolDisabledForConversation reports whether toolName was disabled by repeated failures
+func (a *Agent) isToolDisabledForConversation(toolName string) bool {
+	a.toolFailures.mu.Lock()
+	defer a.toolFailures.mu.Unlock()
+	return a.toolFailures.disabled[toolName]
+}
+
+// disabledToolResult is returned instead of running a disabled tool
+func disabledToolResult(toolName string) *mcp.CallToolResult {
+	return mcp.NewToolResultError(fmt.Sprintf("tool %s is disabled for the rest of this conversation after repeated failures; do not call it again", toolName))
+}
+
+// recordToolFailures counts the failed tool results among the messages a
+// dispatch appended and disables the tools that reached the limit. The
+// disabled tools are removed from the conversation's toolset, and a note
+// about them is appended to the returned history.
+func (a *Agent) recordToolFailures(ctx context.Context, messages []llmtypes.MessageContent, dispatched, turn int) []llmtypes.MessageContent {
+	if a.ToolFailureLimit <= 0 || dispatched > len(messages) {
+		return messages
+	}
+
+	type disabledTool struct {
+		name      string
+		failures  int
+		lastError string
+	}
+	var newlyDisabled []disabledTool
+	a.toolFailures.mu.Lock()
+	for _, msg := range messages[dispatched:] {
+		for _, part := range msg.Parts {
+			resp, ok := part.(llmtypes.ToolCallResponse)
+			if !ok || !resp.IsError || resp.Name == "" || a.toolFailures.disabled[resp.Name] {
+				continue
+			}
+			if a.toolFailures.failures == nil {
+				a.toolFailures.failures = make(map[string]int)
+			}
+			a.toolFailures.failures[resp.Name]++
+			if n := a.toolFailures.failures[resp.Name]; n >= a.ToolFailureLimit {
+				if a.toolFailures.disabled == nil {
+					a.toolFailures.disabled = make(map[string]bool)
+				}
+				a.toolFailures.disabled[resp.Name] = true
+				newlyDisabled = append(newlyDisabled, disabledTool{name: resp.Name, failures: n, lastError: resp.Content})
+			}
+		}
+	}
+	a.toolFailures.mu.Unlock()
+	if len(newlyDisabled) == 0 {
+		return messages
+	}
+
+	filtered := make([]llmtypes.Tool, 0, len(a.filteredTools))
+	for _, t := range a.filteredTools {
+		if t.Function != nil && a.isToolDisabledForConversation(t.Function.Name) {
+			continue
+		}
+		filtered = append(filtered, t)
+	}
+	a.filteredTools = filtered
+
+	names := make([]string, 0, len(newlyDisabled))
+	for _, tool := range newlyDisabled {
+		names = append(names, tool.name)
+		if len(tool.lastError) > MaxPreviewLength {
+			tool.lastError = tool.lastError[:MaxPreviewLength]
+		}
+		getLogger(a).Warn("🚫 [TOOL_DISABLED] Tool disabled for the rest of the conversation after repeated failures",
+			loggerv2.String("tool", tool.name),
+			loggerv2.Int("failures", tool.failures),
+			loggerv2.Int("turn", turn+1))
+		a.EmitTypedEvent(ctx, events.NewToolDisabledForConversationEvent(turn+1, tool.name, a.toolToServer[tool.name], tool.failures, tool.lastError))
+	}
+	note := fmt.Sprintf("[System note] The following tools failed %d times and are unavailable for the rest of this conversation: %s. Do not call them again; continue with the other tools, or answer with the information you have and mention what could not be retrieved.",
+		a.ToolFailureLimit, strings.Join(names, ", "))
+	return append(messages, llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, note))
+}
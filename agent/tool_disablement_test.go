@@ -0,0 +1,93 @@
+package mcpagent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/manishiitg/mcpagent/events"
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+func TestToolDisabledAfterRepeatedFailures(t *testing.T) {
+	calls := 0
+	a := &Agent{
+		Logger:   loggerv2.NewNoop(),
+		LLM:      &providerKeyCarrierModel{},
+		ModelID:  "test-model",
+		MaxTurns: 10,
+		Tools:    []llmtypes.Tool{hintTestTool("send_email"), hintTestTool("search")},
+		customTools: map[string]CustomTool{
+			"send_email": {
+				Definition: hintTestTool("send_email"),
+				Category:   "custom",
+				Timeout:    -1,
+				Execution: func(context.Context, map[string]interface{}) (string, error) {
+					calls++
+					return "", errors.New("gmail connector unavailable")
+				},
+			},
+		},
+	}
+	var responses []*llmtypes.ContentResponse
+	for i := 0; i < 3; i++ {
+		responses = append(responses, &llmtypes.ContentResponse{Choices: []*llmtypes.ContentChoice{{ToolCalls: []llmtypes.ToolCall{{
+			ID:           fmt.Sprintf("call-%d", i),
+			Type:         "function",
+			FunctionCall: &llmtypes.FunctionCall{Name: "send_email", Arguments: `{}`},
+		}}}}})
+	}
+	responses = append(responses, &llmtypes.ContentResponse{Choices: []*llmtypes.ContentChoice{{Content: "The email could not be sent."}}})
+	listener := &recordingAgentEventListener{}
+	a.AddEventListener(listener)
+	WithAskPipeline(AskPipeline{Generate: &scriptedGenerateStage{responses: responses}})(a)
+	WithToolFailureLimit(2)(a)
+
+	answer, history, err := AskWithHistory(a, context.Background(), []llmtypes.MessageContent{
+		llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "email the report"),
+	})
+	if err != nil {
+		t.Fatalf("AskWithHistory: %v", err)
+	}
+	if answer != "The email could not be sent." {
+		t.Errorf("answer = %q", answer)
+	}
+	if calls != 2 {
+		t.Errorf("tool ran %d times, want 2 (the third call is rejected)", calls)
+	}
+	if got := a.DisabledToolsForConversation(); len(got) != 1 || got[0] != "send_email" {
+		t.Errorf("disabled tools = %v", got)
+	}
+	if len(a.filteredTools) != 1 || a.filteredTools[0].Function.Name != "search" {
+		t.Errorf("toolset still offers %v", a.filteredTools)
+	}
+
+	notes := 0
+	for _, msg := range history {
+		if text, ok := msg.Parts[0].(llmtypes.TextContent); ok && strings.Contains(text.Text, "unavailable for the rest of this conversation: send_email") {
+			notes++
+		}
+	}
+	if notes != 1 {
+		t.Errorf("got %d unavailability notes, want 1", notes)
+	}
+
+	var disabled []*events.ToolDisabledForConversationEvent
+	for _, event := range listener.events {
+		if data, ok := event.Data.(*events.ToolDisabledForConversationEvent); ok {
+			disabled = append(disabled, data)
+		}
+	}
+	if len(disabled) != 1 || disabled[0].ToolName != "send_email" || disabled[0].Failures != 2 || disabled[0].Turn != 2 {
+		t.Errorf("unexpected disable events: %+v", disabled)
+	}
+
+	// The next conversation starts with the tool enabled again
+	a.resetToolFailures()
+	if a.isToolDisabledForConversation("send_email") {
+		t.Error("failure counts should reset per conversation")
+	}
+}
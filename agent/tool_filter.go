@@ -72,6 +72,7 @@ func NewToolFilter(
 		"human_tools",          // human_feedback, notify_user, etc.
 		"delegation_tools",     // delegate, send_message_to_agent, etc.
 		"workflow",             // plan modification tools (update_regular_step, add_regular_step, etc.)
+		"utility_tools",        // calculate, date_math, convert_units, etc. (WithBuiltinUtilityTools)
 	}
 	for _, cat := range systemCats {
 		tf.systemCategories[cat] = true
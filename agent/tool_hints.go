@@ -0,0 +1,224 @@
+// tool_hints.go
+//
+// This file implements per-call tool hints. The calling application often
+// knows which tools or servers a question is likely to need (e.g. the user
+// clicked "search Jira"). CallWithToolHints passes that knowledge for a
+// single Ask/AskWithHistory call: hinted tools are offered first and ranked
+// first in search_tools results, and with CallWithToolHintScoping the call only
+// sees the hinted tools. Agent-level filters (selected tools/servers, allow
+// list, discovered tools) are not changed.
+//
+// Per-call options and the state a call builds up (recalled memory facts, a
+// pending compaction request) are kept in a callState carried in the call's
+// context, so concurrent calls on one agent never see each other's.
+//
+// Exported:
+//   - CallOption: Per-call option for Ask / AskWithHistory
+//   - CallWithToolHints: Suggest likely-relevant tools or servers
+//   - CallWithToolHintScoping: Restrict the call's toolset to the hints
+
+package mcpagent
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync/atomic"
+
+	"github.com/manishiitg/mcpagent/events"
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+// CallOption configures a single Ask / AskWithHistory call
+type CallOption func(*callOptions)
+
+type callOptions struct {
+	toolHints    []string
+	scopeToHints bool
+	priority     Priority
+	// responseSchema constrains the answer with the provider's native JSON
+	// schema mode (see structured_native.go)
+	responseSchema *llmtypes.JSONSchemaConfig
+	// structuredCaptureTool ends the conversation after it is called
+	// (see structured_stream.go)
+	structuredCaptureTool string
+	// partialJSON streams the JSON answer as partial objects (see partial_json.go)
+	partialJSON bool
+}
+
+// CallWithToolHints suggests tools or servers likely relevant to this call.
+//
+// Each hint is a tool name ("create_issue"), a server name ("jira"), or a
+// "server:tool" / "server:*" pattern as used by WithSelectedTools. Hinted tools
+// are listed first in the toolset sent to the LLM and in search_tools results.
+func CallWithToolHints(hints []string) CallOption {
+	return func(o *callOptions) {
+		o.toolHints = append(o.toolHints, hints...)
+	}
+}
+
+// CallWithToolHintScoping restricts the toolset of this call to the hinted
+// tools when enabled. Virtual tools (search_tools, add_tool, ...) stay
+// available. Has no effect without CallWithToolHints.
+func CallWithToolHintScoping(enabled bool) CallOption {
+	return func(o *callOptions) {
+		o.scopeToHints = enabled
+	}
+}
+
+// toolHints is the parsed form of the hints of the current call
+type toolHints struct {
+	names   map[string]bool // Bare hints: match a tool name or a server name
+	servers map[string]bool // "server:*"
+	tools   map[string]bool // "server:tool"
+	scope   bool
+}
+
+func newToolHints(opts []CallOption) *toolHints {
+	var o callOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if len(o.toolHints) == 0 {
+		return nil
+	}
+	h := &toolHints{
+		names:   make(map[string]bool),
+		servers: make(map[string]bool),
+		tools:   make(map[string]bool),
+		scope:   o.scopeToHints,
+	}
+	for _, hint := range o.toolHints {
+		hint = strings.TrimSpace(hint)
+		server, tool, qualified := strings.Cut(hint, ":")
+		switch {
+		case hint == "":
+		case !qualified:
+			h.names[hint] = true
+		case tool == "*":
+			h.servers[server] = true
+		default:
+			h.tools[server+":"+tool] = true
+		}
+	}
+	return h
+}
+
+// matches reports whether the tool toolName on server is hinted
+func (h *toolHints) matches(toolName, server string) bool {
+	if h.names[toolName] || h.tools[server+":"+toolName] {
+		return true
+	}
+	return server != "" && (h.names[server] || h.servers[server])
+}
+
+// callState is the state of one Ask/AskWithHistory call
+type callState struct {
+	// Tool hints (nil = no hints)
+	toolHints *toolHints
+	// Priority (see priority.go); "" = normal
+	priority Priority
+	// Native JSON schema (see structured_native.go); nil = free-form answer
+	responseSchema *llmtypes.JSONSchemaConfig
+	// Structured output tool that ends the call (see structured_stream.go); "" = none
+	structuredCaptureTool string
+	// Stream the JSON answer as partial objects (see partial_json.go)
+	partialJSON bool
+	// Long-term memory facts recalled for the call (see long_term_memory.go)
+	memoryFacts []MemoryFact
+	// Summarize before the next LLM call (see compact_context.go)
+	summarizeRequested atomic.Bool
+	// Autosave checkpoint ID (see autosave.go); "" until the first save
+	checkpointID string
+	// Trace sampling decisions, recorded on ConversationStart (see streaming_tracer.go)
+	traceSampling []events.TraceSamplingDecision
+}
+
+type callStateKey struct{}
+
+// beginCall applies the per-call options for the duration of one conversation
+// and returns ctx carrying them
+func (a *Agent) beginCall(ctx context.Context, opts []CallOption) (context.Context, *callState) {
+	call := &callState{
+		toolHints:             newToolHints(opts),
+		priority:              newCallPriority(opts),
+		responseSchema:        newCallResponseSchema(opts),
+		structuredCaptureTool: newCallStructuredCapture(opts),
+	}
+	call.partialJSON = newCallPartialJSON(opts) || call.responseSchema != nil
+	a.activeCallsMu.Lock()
+	if a.activeCalls == nil {
+		a.activeCalls = make(map[*callState]struct{})
+	}
+	a.activeCalls[call] = struct{}{}
+	a.activeCallsMu.Unlock()
+	return context.WithValue(ctx, callStateKey{}, call), call
+}
+
+// endCall marks call as finished
+func (a *Agent) endCall(call *callState) {
+	a.activeCallsMu.Lock()
+	delete(a.activeCalls, call)
+	a.activeCallsMu.Unlock()
+}
+
+// currentCall returns the state of the call ctx belongs to; outside a call
+// it returns an empty state
+func currentCall(ctx context.Context) *callState {
+	if call, ok := ctx.Value(callStateKey{}).(*callState); ok {
+		return call
+	}
+	return &callState{}
+}
+
+// applyToolHints orders hinted tools first and, when the call is scoped,
+// drops every non-virtual tool that is not hinted
+func (a *Agent) applyToolHints(ctx context.Context, tools []llmtypes.Tool) []llmtypes.Tool {
+	h := currentCall(ctx).toolHints
+	if h == nil {
+		return tools
+	}
+	hinted := make([]llmtypes.Tool, 0, len(tools))
+	var others []llmtypes.Tool
+	for _, t := range tools {
+		if t.Function != nil && h.matches(t.Function.Name, a.toolToServer[t.Function.Name]) {
+			hinted = append(hinted, t)
+			continue
+		}
+		if h.scope && (t.Function == nil || !isVirtualTool(t.Function.Name)) {
+			continue
+		}
+		others = append(others, t)
+	}
+	if a.Logger != nil {
+		a.Logger.Debug("🎯 [TOOL_HINTS] Applied",
+			loggerv2.Int("total", len(tools)),
+			loggerv2.Int("hinted", len(hinted)),
+			loggerv2.Any("scoped", h.scope))
+	}
+	return append(hinted, others...)
+}
+
+// rankToolSearchResults moves hinted tools to the front of search results
+// and, when the call is scoped, removes tools that are not hinted
+func (a *Agent) rankToolSearchResults(ctx context.Context, matches []ToolSearchResult) []ToolSearchResult {
+	h := currentCall(ctx).toolHints
+	if h == nil {
+		return matches
+	}
+	if h.scope {
+		scoped := matches[:0:0]
+		for _, m := range matches {
+			if h.matches(m.Name, m.Server) {
+				scoped = append(scoped, m)
+			}
+		}
+		return scoped
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return h.matches(matches[i].Name, matches[i].Server) && !h.matches(matches[j].Name, matches[j].Server)
+	})
+	return matches
+}
@@ -0,0 +1,75 @@
+package mcpagent
+
+import (
+	"context"
+	"testing"
+
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+func hintTestTool(name string) llmtypes.Tool {
+	return llmtypes.Tool{Type: "function", Function: &llmtypes.FunctionDefinition{Name: name}}
+}
+
+func hintToolNames(tools []llmtypes.Tool) []string {
+	names := make([]string, len(tools))
+	for i, t := range tools {
+		names[i] = t.Function.Name
+	}
+	return names
+}
+
+func TestApplyToolHintsOrdersAndScopes(t *testing.T) {
+	a := &Agent{
+		Logger:       loggerv2.NewDefault(),
+		toolToServer: map[string]string{"create_issue": "jira", "search_issues": "jira", "send_message": "slack", "read_file": "fs"},
+	}
+	tools := []llmtypes.Tool{
+		hintTestTool("read_file"), hintTestTool("send_message"), hintTestTool("search_tools"),
+		hintTestTool("search_issues"), hintTestTool("create_issue"),
+	}
+
+	ctx, _ := a.beginCall(context.Background(), []CallOption{CallWithToolHints([]string{"jira:*", "send_message"})})
+	got := hintToolNames(a.applyToolHints(ctx, tools))
+	want := []string{"send_message", "search_issues", "create_issue", "read_file", "search_tools"}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+	}
+
+	scopedCtx, _ := a.beginCall(context.Background(), []CallOption{CallWithToolHints([]string{"jira:create_issue"}), CallWithToolHintScoping(true)})
+	got = hintToolNames(a.applyToolHints(scopedCtx, tools))
+	if len(got) != 2 || got[0] != "create_issue" || got[1] != "search_tools" {
+		t.Errorf("scoped call should keep only hinted and virtual tools, got %v", got)
+	}
+
+	// Concurrent calls keep their own hints
+	if got := hintToolNames(a.applyToolHints(ctx, tools)); len(got) != len(tools) || got[0] != "send_message" {
+		t.Errorf("the first call's hints changed, got %v", got)
+	}
+	if got := a.applyToolHints(context.Background(), tools); len(got) != len(tools) {
+		t.Errorf("hints must not apply outside the call, got %d tools", len(got))
+	}
+}
+
+func TestRankToolSearchResults(t *testing.T) {
+	a := &Agent{}
+	matches := []ToolSearchResult{{Name: "read_file", Server: "fs"}, {Name: "create_issue", Server: "jira"}}
+
+	ctx, _ := a.beginCall(context.Background(), []CallOption{CallWithToolHints([]string{"jira"})})
+	ranked := a.rankToolSearchResults(ctx, append([]ToolSearchResult(nil), matches...))
+	if ranked[0].Name != "create_issue" || len(ranked) != 2 {
+		t.Errorf("hinted server tools should rank first, got %+v", ranked)
+	}
+
+	ctx, _ = a.beginCall(context.Background(), []CallOption{CallWithToolHints([]string{"jira"}), CallWithToolHintScoping(true)})
+	if scoped := a.rankToolSearchResults(ctx, matches); len(scoped) != 1 || scoped[0].Name != "create_issue" {
+		t.Errorf("scoped search should only return hinted tools, got %+v", scoped)
+	}
+}
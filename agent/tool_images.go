@@ -0,0 +1,310 @@
+// tool_images.go
+//
+// This file handles image content returned by MCP tools (e.g. browser
+// screenshots). Without it, images are stringified into the tool result as
+// base64 text the model cannot read. With WithToolImages, images are decoded,
+// downscaled to the configured limits and passed to vision-capable models as
+// image parts. For models that cannot see images, each image is replaced by a
+// text description from a configured vision model.
+//
+// Exported:
+//   - ToolImageConfig: Size limits, mode and vision model for tool images
+//   - ToolImageMode: How tool images reach the model
+
+package mcpagent
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"strings"
+
+	_ "image/gif" // Register GIF decoder for screenshots returned as GIF
+
+	"github.com/manishiitg/mcpagent/llm"
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	// DefaultToolImageMaxImages is the number of images kept per tool result
+	DefaultToolImageMaxImages = 4
+	// DefaultToolImageMaxDimension is the longest image edge in pixels
+	DefaultToolImageMaxDimension = 1568
+	// DefaultToolImageMaxBytes is the largest decoded image size sent to the model
+	DefaultToolImageMaxBytes = 3 * 1024 * 1024
+	// toolImageJPEGQuality is used when an image must be re-encoded to fit MaxBytes
+	toolImageJPEGQuality = 85
+)
+
+// ToolImageMode selects how tool images reach the model
+type ToolImageMode string
+
+const (
+	// ToolImageModeAuto passes images to models known to support vision and
+	// describes them for all other models
+	ToolImageModeAuto ToolImageMode = ""
+	// ToolImageModePassThrough always passes images to the model
+	ToolImageModePassThrough ToolImageMode = "pass_through"
+	// ToolImageModeDescribe always replaces images with a text description
+	ToolImageModeDescribe ToolImageMode = "describe"
+)
+
+// ToolImageConfig configures handling of images returned by tools
+type ToolImageConfig struct {
+	Mode         ToolImageMode
+	MaxImages    int // Images kept per tool result; extra images are dropped
+	MaxDimension int // Longest edge in pixels; larger images are downscaled
+	MaxBytes     int // Decoded size limit; larger images are re-encoded as JPEG, then dropped
+
+	// VisionModel describes images when they are not passed through.
+	// When nil, images are replaced with a short placeholder.
+	VisionModel llmtypes.Model
+}
+
+func (c ToolImageConfig) withDefaults() ToolImageConfig {
+	if c.MaxImages <= 0 {
+		c.MaxImages = DefaultToolImageMaxImages
+	}
+	if c.MaxDimension <= 0 {
+		c.MaxDimension = DefaultToolImageMaxDimension
+	}
+	if c.MaxBytes <= 0 {
+		c.MaxBytes = DefaultToolImageMaxBytes
+	}
+	return c
+}
+
+// toolImageBatch holds the images of one tool result that are delivered in a
+// user message after the turn's tool results
+type toolImageBatch struct {
+	toolName string
+	images   []llmtypes.ImageContent
+}
+
+// visionModelPrefixes lists model ID fragments of models that accept image input
+var visionModelPrefixes = []string{
+	"claude-3", "claude-sonnet-4", "claude-opus-4", "claude-haiku-4",
+	"gpt-4o", "gpt-4.1", "gpt-5", "o3", "o4",
+	"gemini", "pixtral", "llava", "vision", "grok-4",
+}
+
+// modelSupportsVision reports whether modelID is known to accept images.
+// Unknown models are treated as text-only.
+func modelSupportsVision(modelID string) bool {
+	id := strings.ToLower(modelID)
+	for _, prefix := range visionModelPrefixes {
+		if strings.Contains(id, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// toolImagesInToolResult reports whether images can be attached to the tool
+// result itself. Other providers receive them in a follow-up user message.
+func (a *Agent) toolImagesInToolResult() bool {
+	return a.provider == llm.ProviderAnthropic
+}
+
+// processToolImages separates image content from a tool result. It returns a
+// copy of result in which every image is replaced by a text note (or a
+// description), and the images to pass to the model. When tool images are
+// disabled or the result has no images, result is returned unchanged.
+func (a *Agent) processToolImages(ctx context.Context, toolName string, result *mcp.CallToolResult) (*mcp.CallToolResult, []llmtypes.ImageContent) {
+	if a.ToolImages == nil || result == nil {
+		return result, nil
+	}
+	hasImages := false
+	for _, content := range result.Content {
+		if _, ok := toolImageContent(content); ok {
+			hasImages = true
+			break
+		}
+	}
+	if !hasImages {
+		return result, nil
+	}
+
+	cfg := a.ToolImages.withDefaults()
+	passThrough := cfg.Mode == ToolImageModePassThrough ||
+		(cfg.Mode == ToolImageModeAuto && modelSupportsVision(a.ModelID))
+
+	processed := *result
+	processed.Content = make([]mcp.Content, 0, len(result.Content))
+	var images []llmtypes.ImageContent
+	index := 0
+	for _, content := range result.Content {
+		raw, ok := toolImageContent(content)
+		if !ok {
+			processed.Content = append(processed.Content, content)
+			continue
+		}
+		index++
+		if index > cfg.MaxImages {
+			processed.Content = append(processed.Content, &mcp.TextContent{
+				Text: fmt.Sprintf("[Image %d omitted: more than %d images in one tool result]", index, cfg.MaxImages),
+			})
+			continue
+		}
+		img, err := prepareToolImage(raw, cfg)
+		if err != nil {
+			a.Logger.Warn("Dropping tool image",
+				loggerv2.String("tool", toolName),
+				loggerv2.Int("image_index", index),
+				loggerv2.Error(err))
+			processed.Content = append(processed.Content, &mcp.TextContent{
+				Text: fmt.Sprintf("[Image %d omitted: %v]", index, err),
+			})
+			continue
+		}
+
+		if passThrough {
+			images = append(images, img)
+			processed.Content = append(processed.Content, &mcp.TextContent{
+				Text: fmt.Sprintf("[Image %d attached (%s)]", index, img.MediaType),
+			})
+			continue
+		}
+		processed.Content = append(processed.Content, &mcp.TextContent{
+			Text: fmt.Sprintf("[Image %d: %s]", index, a.describeToolImage(ctx, cfg.VisionModel, toolName, img)),
+		})
+	}
+	return &processed, images
+}
+
+// describeToolImage returns a text description of img from the vision model,
+// or a placeholder when no vision model is configured or the call fails.
+func (a *Agent) describeToolImage(ctx context.Context, visionModel llmtypes.Model, toolName string, img llmtypes.ImageContent) string {
+	if visionModel == nil {
+		return "the current model cannot view images and no vision model is configured to describe it"
+	}
+	prompt := fmt.Sprintf("This image was returned by the tool %q. Describe it for an assistant that cannot see it: "+
+		"transcribe all visible text and describe the layout, UI elements, charts and anything notable.", toolName)
+	resp, err := visionModel.GenerateContent(ctx, []llmtypes.MessageContent{{
+		Role:  llmtypes.ChatMessageTypeHuman,
+		Parts: []llmtypes.ContentPart{llmtypes.TextContent{Text: prompt}, img},
+	}})
+	if err == nil && (resp == nil || len(resp.Choices) == 0 || strings.TrimSpace(resp.Choices[0].Content) == "") {
+		err = fmt.Errorf("empty response from vision model")
+	}
+	if err != nil {
+		a.Logger.Warn("Failed to describe tool image", loggerv2.String("tool", toolName), loggerv2.Error(err))
+		return "image description unavailable"
+	}
+	return strings.TrimSpace(resp.Choices[0].Content)
+}
+
+// appendToolImageMessage adds one user message carrying the images of the
+// turn's tool results. Providers other than Anthropic do not accept images in
+// tool results, so images are delivered right after them.
+func appendToolImageMessage(messages []llmtypes.MessageContent, batches []toolImageBatch) []llmtypes.MessageContent {
+	if len(batches) == 0 {
+		return messages
+	}
+	var parts []llmtypes.ContentPart
+	for _, batch := range batches {
+		parts = append(parts, llmtypes.TextContent{Text: fmt.Sprintf("Images returned by tool %s:", batch.toolName)})
+		for _, img := range batch.images {
+			parts = append(parts, img)
+		}
+	}
+	return append(messages, llmtypes.MessageContent{Role: llmtypes.ChatMessageTypeHuman, Parts: parts})
+}
+
+// toolImageContent returns the image in content, which MCP clients may
+// deliver either as a value or a pointer
+func toolImageContent(content mcp.Content) (mcp.ImageContent, bool) {
+	switch c := content.(type) {
+	case mcp.ImageContent:
+		return c, true
+	case *mcp.ImageContent:
+		if c != nil {
+			return *c, true
+		}
+	}
+	return mcp.ImageContent{}, false
+}
+
+// prepareToolImage decodes an MCP image and fits it into the configured
+// dimension and size limits
+func prepareToolImage(raw mcp.ImageContent, cfg ToolImageConfig) (llmtypes.ImageContent, error) {
+	data, err := base64.StdEncoding.DecodeString(raw.Data)
+	if err != nil {
+		return llmtypes.ImageContent{}, fmt.Errorf("invalid base64 image data")
+	}
+	mediaType := raw.MIMEType
+
+	src, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		// Formats without a decoder (e.g. WebP) are passed through when small enough
+		if len(data) > cfg.MaxBytes {
+			return llmtypes.ImageContent{}, fmt.Errorf("%s image of %d bytes exceeds the %d byte limit", mediaType, len(data), cfg.MaxBytes)
+		}
+		return llmtypes.ImageContent{SourceType: "base64", MediaType: mediaType, Data: raw.Data}, nil
+	}
+
+	bounds := src.Bounds()
+	if bounds.Dx() <= cfg.MaxDimension && bounds.Dy() <= cfg.MaxDimension && len(data) <= cfg.MaxBytes {
+		return llmtypes.ImageContent{SourceType: "base64", MediaType: mediaType, Data: raw.Data}, nil
+	}
+
+	resized := downscaleImage(src, cfg.MaxDimension)
+	var buf bytes.Buffer
+	if format == "png" {
+		if err := png.Encode(&buf, resized); err == nil && buf.Len() <= cfg.MaxBytes {
+			return llmtypes.ImageContent{SourceType: "base64", MediaType: "image/png", Data: base64.StdEncoding.EncodeToString(buf.Bytes())}, nil
+		}
+		buf.Reset()
+	}
+	if err := jpeg.Encode(&buf, resized, &jpeg.Options{Quality: toolImageJPEGQuality}); err != nil {
+		return llmtypes.ImageContent{}, fmt.Errorf("failed to re-encode image: %w", err)
+	}
+	if buf.Len() > cfg.MaxBytes {
+		return llmtypes.ImageContent{}, fmt.Errorf("image of %d bytes exceeds the %d byte limit after downscaling", buf.Len(), cfg.MaxBytes)
+	}
+	return llmtypes.ImageContent{SourceType: "base64", MediaType: "image/jpeg", Data: base64.StdEncoding.EncodeToString(buf.Bytes())}, nil
+}
+
+// downscaleImage shrinks src so its longest edge is at most maxDimension,
+// averaging the source pixels covered by each destination pixel
+func downscaleImage(src image.Image, maxDimension int) image.Image {
+	bounds := src.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	if w <= maxDimension && h <= maxDimension {
+		return src
+	}
+	dw, dh := maxDimension, maxDimension
+	if w >= h {
+		dh = max(1, h*maxDimension/w)
+	} else {
+		dw = max(1, w*maxDimension/h)
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		sy0 := bounds.Min.Y + y*h/dh
+		sy1 := max(sy0+1, bounds.Min.Y+(y+1)*h/dh)
+		for x := 0; x < dw; x++ {
+			sx0 := bounds.Min.X + x*w/dw
+			sx1 := max(sx0+1, bounds.Min.X+(x+1)*w/dw)
+			var r, g, b, al, n uint64
+			for sy := sy0; sy < sy1; sy++ {
+				for sx := sx0; sx < sx1; sx++ {
+					cr, cg, cb, ca := src.At(sx, sy).RGBA()
+					r, g, b, al, n = r+uint64(cr), g+uint64(cg), b+uint64(cb), al+uint64(ca), n+1
+				}
+			}
+			dst.Set(x, y, color.RGBA64{R: uint16(r / n), G: uint16(g / n), B: uint16(b / n), A: uint16(al / n)})
+		}
+	}
+	return dst
+}
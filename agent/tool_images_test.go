@@ -0,0 +1,100 @@
+package mcpagent
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"image"
+	"image/png"
+	"strings"
+	"testing"
+
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+type fakeVisionModel struct {
+	prompts []llmtypes.MessageContent
+}
+
+func (m *fakeVisionModel) GenerateContent(_ context.Context, messages []llmtypes.MessageContent, _ ...llmtypes.CallOption) (*llmtypes.ContentResponse, error) {
+	m.prompts = append(m.prompts, messages...)
+	return &llmtypes.ContentResponse{Choices: []*llmtypes.ContentChoice{{Content: "A login form with a Submit button"}}}, nil
+}
+
+func (m *fakeVisionModel) GetModelID() string { return "vision" }
+
+func (m *fakeVisionModel) GetModelMetadata(string) (*llmtypes.ModelMetadata, error) {
+	return nil, nil
+}
+
+func testPNG(t *testing.T, w, h int) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, w, h))); err != nil {
+		t.Fatal(err)
+	}
+	return base64.StdEncoding.EncodeToString(buf.Bytes())
+}
+
+func TestProcessToolImagesPassesDownscaledImagesToVisionModels(t *testing.T) {
+	a := &Agent{Logger: loggerv2.NewDefault(), ModelID: "claude-sonnet-4-20250514", ToolImages: &ToolImageConfig{MaxDimension: 200}}
+	result := &mcp.CallToolResult{Content: []mcp.Content{
+		mcp.NewTextContent("Screenshot taken"),
+		mcp.NewImageContent(testPNG(t, 800, 400), "image/png"),
+	}}
+
+	processed, images := a.processToolImages(context.Background(), "browser_take_screenshot", result)
+	if len(images) != 1 {
+		t.Fatalf("expected 1 image, got %d", len(images))
+	}
+	data, _ := base64.StdEncoding.DecodeString(images[0].Data)
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || cfg.Width != 200 || cfg.Height != 100 {
+		t.Errorf("expected image downscaled to 200x100, got %dx%d (%v)", cfg.Width, cfg.Height, err)
+	}
+	text := processed.Content[1].(*mcp.TextContent).Text
+	if !strings.Contains(text, "attached") {
+		t.Errorf("image should be replaced by a note in the text result, got %q", text)
+	}
+	if _, ok := result.Content[1].(mcp.ImageContent); !ok {
+		t.Error("original tool result must not be modified")
+	}
+}
+
+func TestProcessToolImagesDescribesForTextOnlyModels(t *testing.T) {
+	vision := &fakeVisionModel{}
+	a := &Agent{Logger: loggerv2.NewDefault(), ModelID: "deepseek-chat", ToolImages: &ToolImageConfig{VisionModel: vision, MaxImages: 1}}
+	result := &mcp.CallToolResult{Content: []mcp.Content{
+		mcp.NewImageContent(testPNG(t, 10, 10), "image/png"),
+		mcp.NewImageContent(testPNG(t, 10, 10), "image/png"),
+	}}
+
+	processed, images := a.processToolImages(context.Background(), "screenshot", result)
+	if len(images) != 0 {
+		t.Fatalf("text-only model should not receive images, got %d", len(images))
+	}
+	if got := processed.Content[0].(*mcp.TextContent).Text; !strings.Contains(got, "login form") {
+		t.Errorf("expected vision model description, got %q", got)
+	}
+	if got := processed.Content[1].(*mcp.TextContent).Text; !strings.Contains(got, "omitted") {
+		t.Errorf("expected image over MaxImages to be omitted, got %q", got)
+	}
+	if len(vision.prompts) != 1 {
+		t.Errorf("expected one vision call, got %d", len(vision.prompts))
+	}
+}
+
+func TestAppendToolImageMessage(t *testing.T) {
+	img := llmtypes.ImageContent{SourceType: "base64", MediaType: "image/png", Data: "abc"}
+	messages := appendToolImageMessage(nil, []toolImageBatch{{toolName: "screenshot", images: []llmtypes.ImageContent{img}}})
+	if len(messages) != 1 || messages[0].Role != llmtypes.ChatMessageTypeHuman || len(messages[0].Parts) != 2 {
+		t.Fatalf("unexpected image message: %+v", messages)
+	}
+	if got := appendToolImageMessage(messages, nil); len(got) != 1 {
+		t.Error("no batches should not add a message")
+	}
+}
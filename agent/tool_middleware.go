@@ -0,0 +1,147 @@
+// tool_middleware.go
+//
+// This file implements tool middleware: user hooks that wrap every tool
+// execution — MCP, custom and virtual tools, in sequential and parallel
+// dispatch. Unlike event listeners, which only observe, a middleware runs in
+// the call path and can:
+//
+//   - modify arguments: change call.Arguments before calling next
+//   - block execution: return a result without calling next, e.g.
+//     mcp.NewToolResultError("blocked by policy"), nil
+//   - redact results: call next and edit the result it returns
+//   - inject errors: return an error, which is handled like a failed tool
+//     call (error recovery, then an error result for the LLM)
+//
+// Middlewares run in the order they are added, the first one outermost. They
+// run inside the tool's timeout context, after any SetToolArgTransformer
+// transformer.
+//
+// Tool calls generated code makes through the HTTP API (code execution mode
+// and coding CLI providers) reach the agent through the executor handlers,
+// which run them through the same chain via a session hook
+// (codeexec.SetSessionToolCallHook), so argument limits, recording, the
+// folder guard and argument validation apply to them too. Their ID is empty
+// and their turn 0.
+//
+// Exported:
+//   - ToolInvocation: The tool call passed to middleware
+//   - ToolExecFunc: Executes a tool call (the next step of the chain)
+//   - ToolMiddleware / WithToolMiddleware: Wrap tool executions
+
+package mcpagent
+
+import (
+	"context"
+	"errors"
+
+	"github.com/manishiitg/mcpagent/agent/codeexec"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// ToolInvocation is a tool call about to be executed
+type ToolInvocation struct {
+	// ID is the tool call ID assigned by the LLM
+	ID   string
+	Name string
+	// ServerName is the MCP server, "custom" for custom tools, or the
+	// server a virtual tool refers to
+	ServerName string
+	// Type is "MCP", "custom" or "virtual"
+	Type string
+	// Turn is the conversation turn (1-based)
+	Turn int
+	// Arguments are passed to the tool; middleware may modify or replace them
+	Arguments map[string]interface{}
+}
+
+// ToolExecFunc executes a tool call
+type ToolExecFunc func(ctx context.Context, call *ToolInvocation) (*mcp.CallToolResult, error)
+
+// ToolMiddleware wraps a tool execution. Call next to run the tool (or the
+// next middleware); return without calling it to block the call.
+type ToolMiddleware func(ctx context.Context, call *ToolInvocation, next ToolExecFunc) (*mcp.CallToolResult, error)
+
+// WithToolMiddleware adds a middleware around every tool execution. Can be
+// given several times; middlewares run in the order they are added.
+//
+// Example (block shell commands, redact tokens from results):
+//
+//	mcpagent.WithToolMiddleware(func(ctx context.Context, call *mcpagent.ToolInvocation, next mcpagent.ToolExecFunc) (*mcp.CallToolResult, error) {
+//	    if call.Name == "execute_shell_command" {
+//	        return mcp.NewToolResultError("shell commands are disabled"), nil
+//	    }
+//	    result, err := next(ctx, call)
+//	    if result != nil {
+//	        redactTokens(result)
+//	    }
+//	    return result, err
+//	})
+//
+// Default: none
+func WithToolMiddleware(middleware ToolMiddleware) AgentOption {
+	return func(a *Agent) {
+		if middleware != nil {
+			a.toolMiddleware = append(a.toolMiddleware, middleware)
+		}
+	}
+}
+
+// executeWithToolMiddleware runs execute for call through the agent's
+// middleware chain. Tools disabled by repeated failures are not run, and
+// argument size limits are enforced before the chain; the folder guard and
+// the input schema are checked at its end, and in dry-run mode write tools
+// are stopped right before execute.
+func (a *Agent) executeWithToolMiddleware(ctx context.Context, call *ToolInvocation, execute ToolExecFunc) (*mcp.CallToolResult, error) {
+	if a.isToolDisabledForConversation(call.Name) {
+		return disabledToolResult(call.Name), nil
+	}
+	rejected, note := a.enforceToolArgLimits(ctx, call)
+	if rejected != nil {
+		return rejected, nil
+	}
+	next := a.recordingToolExec(a.folderGuardedExec(a.argsValidatedExec(a.dryRunExec(execute))))
+	for i := len(a.toolMiddleware) - 1; i >= 0; i-- {
+		middleware, inner := a.toolMiddleware[i], next
+		next = func(ctx context.Context, call *ToolInvocation) (*mcp.CallToolResult, error) {
+			return middleware(ctx, call, inner)
+		}
+	}
+	result, err := next(ctx, call)
+	result = a.guardToolOutput(ctx, call, result)
+	if note != "" && result != nil {
+		result.Content = append(result.Content, mcp.NewTextContent(note))
+	}
+	return result, err
+}
+
+// registerCodeExecToolCallHook routes the session's HTTP API tool calls
+// through the middleware chain (see codeExecToolCall)
+func (a *Agent) registerCodeExecToolCallHook() {
+	if a.SessionID == "" || !(a.UseCodeExecutionMode || isCodingCLIProvider(a.provider, a.ModelID)) {
+		return
+	}
+	codeexec.SetSessionToolCallHook(a.SessionID, a, a.codeExecToolCall)
+}
+
+// codeExecToolCall runs a tool call generated code made through the HTTP API
+// through the middleware chain. A result the chain turned into an error
+// (blocked, rejected) is returned as an error, which the API reports to the
+// code.
+func (a *Agent) codeExecToolCall(ctx context.Context, kind, server, tool string, args map[string]interface{}, execute func(ctx context.Context, args map[string]interface{}) (string, error)) (string, error) {
+	call := &ToolInvocation{Name: tool, ServerName: server, Type: kind, Arguments: args}
+	result, err := a.executeWithToolMiddleware(ctx, call, func(ctx context.Context, call *ToolInvocation) (*mcp.CallToolResult, error) {
+		text, err := execute(ctx, call.Arguments)
+		if err != nil {
+			return nil, err
+		}
+		return mcp.NewToolResultText(text), nil
+	})
+	if err != nil {
+		return "", err
+	}
+	text := toolResultText(result)
+	if result != nil && result.IsError {
+		return "", errors.New(text)
+	}
+	return text, nil
+}
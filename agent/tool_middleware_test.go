@@ -0,0 +1,144 @@
+package mcpagent
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/manishiitg/mcpagent/agent/codeexec"
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+	"github.com/manishiitg/mcpagent/mcpclient"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+func middlewareTestAgent(received *map[string]interface{}) *Agent {
+	return &Agent{
+		Logger: loggerv2.NewNoop(),
+		customTools: map[string]CustomTool{
+			"lookup": {
+				Definition: hintTestTool("lookup"),
+				Category:   "custom",
+				Execution: func(_ context.Context, args map[string]interface{}) (string, error) {
+					*received = args
+					return "secret=abc123 user=" + args["user"].(string), nil
+				},
+			},
+		},
+	}
+}
+
+func runMiddlewareTestCall(a *Agent) toolExecutionResult {
+	plan := toolExecutionPlan{
+		toolCall:     llmtypes.ToolCall{ID: "call_1", FunctionCall: &llmtypes.FunctionCall{Name: "lookup", Arguments: `{"user":"bob"}`}},
+		args:         map[string]interface{}{"user": "bob"},
+		serverName:   "custom",
+		isCustomTool: true,
+		hasNoTimeout: true,
+		toolType:     "custom",
+	}
+	return executeToolCall(context.Background(), a, plan, 0, time.Now(), context.Background())
+}
+
+func TestToolMiddlewareModifiesArgumentsAndRedactsResults(t *testing.T) {
+	var received map[string]interface{}
+	a := middlewareTestAgent(&received)
+	var order []string
+	WithToolMiddleware(func(ctx context.Context, call *ToolInvocation, next ToolExecFunc) (*mcp.CallToolResult, error) {
+		order = append(order, "outer:"+call.Type)
+		call.Arguments = map[string]interface{}{"user": "alice"}
+		return next(ctx, call)
+	})(a)
+	WithToolMiddleware(func(ctx context.Context, call *ToolInvocation, next ToolExecFunc) (*mcp.CallToolResult, error) {
+		order = append(order, "inner:"+call.Arguments["user"].(string))
+		result, err := next(ctx, call)
+		if result != nil {
+			text := mcpclient.ToolResultAsString(result)
+			result = mcp.NewToolResultText(strings.ReplaceAll(text, "abc123", "[REDACTED]"))
+		}
+		return result, err
+	})(a)
+
+	result := runMiddlewareTestCall(a)
+	if received["user"] != "alice" {
+		t.Errorf("tool received %v, want the modified arguments", received)
+	}
+	if result.resultText != "secret=[REDACTED] user=alice" {
+		t.Errorf("result = %q, want the redacted result", result.resultText)
+	}
+	if strings.Join(order, ",") != "outer:custom,inner:alice" {
+		t.Errorf("middleware order = %v", order)
+	}
+}
+
+func TestToolMiddlewareBlocksAndInjectsErrors(t *testing.T) {
+	var received map[string]interface{}
+	a := middlewareTestAgent(&received)
+	WithToolMiddleware(func(context.Context, *ToolInvocation, ToolExecFunc) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultError("lookup is blocked by policy"), nil
+	})(a)
+
+	result := runMiddlewareTestCall(a)
+	if received != nil {
+		t.Error("blocked tool should not run")
+	}
+	if result.result == nil || !result.result.IsError || !strings.Contains(result.resultText, "lookup is blocked by policy") {
+		t.Errorf("got %+v, want the blocking error result", result)
+	}
+
+	a = middlewareTestAgent(&received)
+	WithToolMiddleware(func(context.Context, *ToolInvocation, ToolExecFunc) (*mcp.CallToolResult, error) {
+		return nil, errors.New("quota exceeded")
+	})(a)
+	result = runMiddlewareTestCall(a)
+	if result.toolErr == nil || !strings.Contains(result.resultText, "quota exceeded") {
+		t.Errorf("got %+v, want the injected error", result)
+	}
+}
+
+func TestToolMiddlewareRunsCodeExecutionToolCalls(t *testing.T) {
+	config := filepath.Join(t.TempDir(), "mcp.json")
+	if err := os.WriteFile(config, []byte(`{"mcpServers": {}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var seen []string
+	a, err := NewAgent(context.Background(), &providerKeyCarrierModel{}, config,
+		WithLogger(loggerv2.NewNoop()), WithSessionID("code-exec-middleware"), WithCodeExecutionMode(true),
+		WithToolMiddleware(func(ctx context.Context, call *ToolInvocation, next ToolExecFunc) (*mcp.CallToolResult, error) {
+			seen = append(seen, call.Type+" "+call.ServerName+"/"+call.Name)
+			if call.Name == "delete_repo" {
+				return mcp.NewToolResultError("blocked by policy"), nil
+			}
+			call.Arguments["user"] = "alice"
+			return next(ctx, call)
+		}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ran := 0
+	execute := func(_ context.Context, args map[string]interface{}) (string, error) {
+		ran++
+		return "hello " + args["user"].(string), nil
+	}
+	ctx := context.Background()
+	if result, err := codeexec.RunToolCall(ctx, "code-exec-middleware", "MCP", "github", "get_user", map[string]interface{}{"user": "bob"}, execute); err != nil || result != "hello alice" {
+		t.Errorf("get_user = %q, %v; want the middleware's arguments", result, err)
+	}
+	if _, err := codeexec.RunToolCall(ctx, "code-exec-middleware", "MCP", "github", "delete_repo", map[string]interface{}{}, execute); err == nil || !strings.Contains(err.Error(), "blocked by policy") {
+		t.Errorf("delete_repo err = %v, want blocked", err)
+	}
+	if ran != 1 || len(seen) != 2 || seen[0] != "MCP github/get_user" {
+		t.Errorf("ran %d times, middleware saw %v", ran, seen)
+	}
+
+	a.Close()
+	if _, err := codeexec.RunToolCall(ctx, "code-exec-middleware", "MCP", "github", "delete_repo", map[string]interface{}{"user": "bob"}, execute); err != nil || ran != 2 {
+		t.Errorf("after Close: err = %v, ran = %d; the hook should be gone", err, ran)
+	}
+}
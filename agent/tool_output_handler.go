@@ -1,17 +1,18 @@
 package mcpagent
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"time"
 
 	llmproviders "github.com/manishiitg/multi-llm-provider-go"
 	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
-	"github.com/manishiitg/multi-llm-provider-go/pkg/utils"
 )
 
 const (
@@ -49,8 +50,15 @@ type ToolOutputHandler struct {
 	Enabled              bool
 	ServerAvailable      bool                // Whether context offloading virtual tools are available
 	LLM                  llmtypes.Model      // Optional LLM model for provider-aware token counting
-	tokenCounter         *utils.TokenCounter // Cached token counter instance
+	Tokenizer            Tokenizer           // Optional tokenizer overriding TokenizerForModel (see tokenizer.go)
 	MaxToolOutputTokens  int                 // Absolute maximum token limit (applies even when offloading is disabled)
+
+	// Storage holds the offloaded outputs (nil = local disk, see output_storage.go)
+	Storage OutputStorage
+
+	// Index of the files written by WriteToolOutputToFile (see output_references.go)
+	indexMu sync.Mutex
+	index   []OffloadedOutput
 }
 
 // NewToolOutputHandler creates a new tool output handler with default settings
@@ -61,7 +69,6 @@ func NewToolOutputHandler() *ToolOutputHandler {
 		SessionID:           "",
 		Enabled:             true,
 		ServerAvailable:     false, // Will be set by agent
-		tokenCounter:        utils.NewTokenCounter(),
 		MaxToolOutputTokens: DefaultMaxToolOutputTokenLimit,
 	}
 }
@@ -74,7 +81,6 @@ func NewToolOutputHandlerWithConfig(threshold int, outputFolder string, sessionI
 		SessionID:           sessionID,
 		Enabled:             enabled,
 		ServerAvailable:     serverAvailable,
-		tokenCounter:        utils.NewTokenCounter(),
 		MaxToolOutputTokens: DefaultMaxToolOutputTokenLimit,
 	}
 }
@@ -111,46 +117,27 @@ func (h *ToolOutputHandler) SetLLM(llm llmtypes.Model) {
 }
 
 // CountTokensForModel counts tokens for the given content using provider/model-specific encoding
-// It uses the LLM model's metadata to determine the correct encoding, or falls back to provider-based encoding
+// It uses the configured Tokenizer, or TokenizerForModel with the provider from the LLM model's
+// metadata (falling back to a provider inferred from the model ID)
 func (h *ToolOutputHandler) CountTokensForModel(content string, modelID string) int {
-	// Initialize token counter if not already initialized
-	if h.tokenCounter == nil {
-		h.tokenCounter = utils.NewTokenCounter()
-	}
+	return h.tokenizerFor(modelID).CountTokens(content)
+}
 
-	// Try to use LLM model for accurate token counting
+// tokenizerFor returns the tokenizer used to count tokens for modelID
+func (h *ToolOutputHandler) tokenizerFor(modelID string) Tokenizer {
+	if h.Tokenizer != nil {
+		return h.Tokenizer
+	}
+	provider := ""
 	if h.LLM != nil {
-		// Get model metadata from LLM
-		metadata, err := h.LLM.GetModelMetadata(modelID)
-		if err == nil && metadata != nil {
-			// Use provider-aware token counting
-			tokenCount, err := h.tokenCounter.CountTokens(content, metadata)
-			if err == nil {
-				return tokenCount
-			}
-		}
-
-		// Fallback: try to get provider from model ID or use CountTokensForModel with LLM
-		// Extract provider from model metadata if available
-		if metadata != nil && metadata.Provider != "" {
-			tokenCount, err := h.tokenCounter.CountTokensForProvider(content, metadata.Provider, modelID)
-			if err == nil {
-				return tokenCount
-			}
+		if metadata, err := h.LLM.GetModelMetadata(modelID); err == nil && metadata != nil {
+			provider = metadata.Provider
 		}
 	}
-
-	// Fallback: use provider-based encoding detection from model ID
-	// Try to infer provider from model ID
-	provider := inferProviderFromModelID(modelID)
-	tokenCount, err := h.tokenCounter.CountTokensForProvider(content, provider, modelID)
-	if err == nil {
-		return tokenCount
+	if provider == "" {
+		provider = inferProviderFromModelID(modelID)
 	}
-
-	// If tiktoken fails completely, return 0 (character-based estimation removed)
-	// This means large output detection may not work if tiktoken fails
-	return 0
+	return TokenizerForModel(provider, modelID)
 }
 
 // inferProviderFromModelID attempts to infer the provider from the model ID
@@ -199,20 +186,35 @@ func (h *ToolOutputHandler) WriteToolOutputToFile(content, toolName string) (str
 		sessionFolder = h.OutputFolder
 	}
 
-	// Ensure output directory exists
-	if err := os.MkdirAll(sessionFolder, 0755); err != nil { //nolint:gosec // 0755 permissions are intentional for user-accessible directories
-		return "", fmt.Errorf("failed to create output directory: %w", err)
-	}
-
 	// Generate unique filename with appropriate extension
 	filename := h.generateToolOutputFilename(toolName, actualContent)
 	filePath := filepath.Join(sessionFolder, filename)
 
-	// Write actual content to file (without prefix)
-	if err := os.WriteFile(filePath, []byte(actualContent), 0644); err != nil { //nolint:gosec // 0644 permissions are intentional for user-accessible files
-		return "", fmt.Errorf("failed to write tool output to file: %w", err)
+	if h.Storage != nil {
+		if err := h.Storage.Put(context.Background(), outputKey(filePath), []byte(actualContent)); err != nil {
+			return "", fmt.Errorf("failed to write tool output to storage: %w", err)
+		}
+	} else {
+		// Ensure output directory exists
+		if err := os.MkdirAll(sessionFolder, 0755); err != nil { //nolint:gosec // 0755 permissions are intentional for user-accessible directories
+			return "", fmt.Errorf("failed to create output directory: %w", err)
+		}
+
+		// Write actual content to file (without prefix)
+		if err := os.WriteFile(filePath, []byte(actualContent), 0644); err != nil { //nolint:gosec // 0644 permissions are intentional for user-accessible files
+			return "", fmt.Errorf("failed to write tool output to file: %w", err)
+		}
 	}
 
+	h.indexMu.Lock()
+	h.index = append(h.index, OffloadedOutput{
+		Path:      filePath,
+		ToolName:  toolName,
+		Size:      int64(len(actualContent)),
+		CreatedAt: time.Now(),
+	})
+	h.indexMu.Unlock()
+
 	return filePath, nil
 }
 
@@ -297,9 +299,9 @@ FIRST %d CHARACTERS OF OUTPUT (%d%% of threshold):
 Make sure to use the virtual tool next to read contents of this file in an efficient manner:
 
 Available virtual tool for context offloading:
-- search_large_output - unified tool for accessing offloaded files. Use operation='read' to read character ranges, operation='search' for regex pattern matching, or operation='query' for jq JSON queries.
+- search_large_output - unified tool for accessing offloaded files. Use operation='read' to read character ranges, operation='search' for regex pattern matching, operation='query' for jq JSON queries, or operation='sql' to filter and aggregate JSON/CSV rows with SQL (table 'output').
 
-Example: Use search_large_output with operation='read' (start/end params), operation='search' (pattern param), or operation='query' (query param for jq)
+Example: Use search_large_output with operation='read' (start/end params), operation='search' (pattern param), operation='query' (query param for jq), or operation='sql' (sql param)
 
 NOTE: When using the virtual tool, you can provide either:
 - The full path: "%s" (recommended - includes session folder)
@@ -318,6 +320,13 @@ func (h *ToolOutputHandler) ExtractFirstNCharacters(content string, n int) strin
 	return content[:n]
 }
 
+// OffloadedOutputs returns the files written by WriteToolOutputToFile, oldest first
+func (h *ToolOutputHandler) OffloadedOutputs() []OffloadedOutput {
+	h.indexMu.Lock()
+	defer h.indexMu.Unlock()
+	return append([]OffloadedOutput(nil), h.index...)
+}
+
 // GetToolOutputFolder returns the current output folder path
 func (h *ToolOutputHandler) GetToolOutputFolder() string {
 	return h.OutputFolder
@@ -365,6 +374,10 @@ func (h *ToolOutputHandler) CleanupOldFiles(maxAge time.Duration) error {
 		return fmt.Errorf("output folder is not set")
 	}
 
+	if h.Storage != nil {
+		return h.deleteStoredOutputs(context.Background(), h.OutputFolder, time.Now().Add(-maxAge))
+	}
+
 	// Check if output folder exists
 	if _, err := os.Stat(h.OutputFolder); os.IsNotExist(err) {
 		// Folder doesn't exist, nothing to clean
@@ -426,6 +439,10 @@ func (h *ToolOutputHandler) CleanupSessionFolder(sessionID string) error {
 
 	sessionFolder := filepath.Join(h.OutputFolder, sessionID)
 
+	if h.Storage != nil {
+		return h.deleteStoredOutputs(context.Background(), sessionFolder, time.Time{})
+	}
+
 	// Check if session folder exists
 	if _, err := os.Stat(sessionFolder); os.IsNotExist(err) {
 		// Folder doesn't exist, nothing to clean
@@ -555,20 +572,7 @@ func GetMaxContextTokenLimit(modelContextWindow int) int {
 // EstimateMessagesTokenCount estimates total tokens across all messages
 // This is used for pre-flight checks before calling the LLM API
 func (h *ToolOutputHandler) EstimateMessagesTokenCount(messages []llmtypes.MessageContent, modelID string) int {
-	totalTokens := 0
-	for _, msg := range messages {
-		for _, part := range msg.Parts {
-			switch p := part.(type) {
-			case llmtypes.TextContent:
-				totalTokens += h.CountTokensForModel(p.Text, modelID)
-			case llmtypes.ToolCallResponse:
-				totalTokens += h.CountTokensForModel(p.Content, modelID)
-			case string:
-				totalTokens += h.CountTokensForModel(p, modelID)
-			}
-		}
-	}
-	return totalTokens
+	return estimateMessageTokens(h.tokenizerFor(modelID), messages)
 }
 
 // ExceedsContextLimit checks if messages would exceed the safe context limit
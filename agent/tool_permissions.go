@@ -0,0 +1,190 @@
+// tool_permissions.go
+//
+// This file provides fine-grained permissions for the tools the agent itself
+// implements: virtual tools (get_prompt, search_large_output, get_api_spec,
+// tool search tools) and custom tools such as the workspace tools. A tool can
+// be disabled outright, or its path arguments can be scoped to a set of
+// folders. Disabled tools are hidden from the LLM and calls to them (including
+// calls made from code execution) are rejected; out-of-scope paths are
+// rejected before the tool runs.
+//
+// Exported:
+//   - ToolPermission: Enable flag and path scope for one tool
+//   - WithToolPermissions: Configure permissions when creating an agent
+//   - Agent.ToolPermission: Look up the permission of a tool
+
+package mcpagent
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+// ToolPermission restricts a single virtual or custom tool
+type ToolPermission struct {
+	// Disabled hides the tool from the LLM and rejects calls to it
+	Disabled bool `json:"disabled,omitempty"`
+	// Paths limits the tool's path arguments (path, paths, file_path, filename,
+	// folder, ...) to these folders. Relative paths are compared as given,
+	// typically relative to the workspace root; if either side is absolute both
+	// are resolved against the working directory. Empty = unrestricted.
+	Paths []string `json:"paths,omitempty"`
+}
+
+// WithToolPermissions sets per-tool permissions for virtual and custom tools,
+// keyed by tool name (e.g. "search_large_output", "delete_workspace_file").
+// Tools without an entry are unrestricted. Permissions for MCP server tools
+// are not affected; use WithSelectedTools or SetToolAllowList for those.
+//
+// Example:
+//
+//	mcpagent.WithToolPermissions(map[string]mcpagent.ToolPermission{
+//	    "delete_workspace_file": {Disabled: true},
+//	    "git_commit":            {Paths: []string{"src", "docs"}},
+//	})
+//
+// Default: nil (all tools enabled, no path scopes)
+func WithToolPermissions(permissions map[string]ToolPermission) AgentOption {
+	return func(a *Agent) {
+		if a.ToolPermissions == nil {
+			a.ToolPermissions = make(map[string]ToolPermission, len(permissions))
+		}
+		for name, permission := range permissions {
+			a.ToolPermissions[name] = permission
+		}
+	}
+}
+
+// ToolPermission returns the permission configured for toolName and whether one is set
+func (a *Agent) ToolPermission(toolName string) (ToolPermission, bool) {
+	permission, ok := a.ToolPermissions[toolName]
+	return permission, ok
+}
+
+// isToolEnabled reports whether toolName is not disabled by its permission
+func (a *Agent) isToolEnabled(toolName string) bool {
+	permission, ok := a.ToolPermissions[toolName]
+	return !ok || !permission.Disabled
+}
+
+// applyToolPermissions removes disabled tools from a tool slice
+func (a *Agent) applyToolPermissions(tools []llmtypes.Tool) []llmtypes.Tool {
+	if len(a.ToolPermissions) == 0 {
+		return tools
+	}
+	filtered := make([]llmtypes.Tool, 0, len(tools))
+	for _, t := range tools {
+		if t.Function != nil && !a.isToolEnabled(t.Function.Name) {
+			continue
+		}
+		filtered = append(filtered, t)
+	}
+	return filtered
+}
+
+// checkToolPermission returns an error if toolName is disabled or one of its
+// path arguments falls outside the tool's path scope
+func (a *Agent) checkToolPermission(toolName string, args map[string]interface{}) error {
+	permission, ok := a.ToolPermissions[toolName]
+	if !ok {
+		return nil
+	}
+	if permission.Disabled {
+		return fmt.Errorf("tool %s is disabled for this agent", toolName)
+	}
+	if len(permission.Paths) == 0 {
+		return nil
+	}
+	for key, value := range args {
+		if !isPathArgument(key) {
+			continue
+		}
+		for _, path := range pathArgumentValues(value) {
+			if err := a.checkToolPathPermission(toolName, path); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// checkToolPathPermission returns an error if path falls outside the path
+// scope of toolName. Tools that carry their paths inside another argument
+// (apply_patch) check each path with it.
+func (a *Agent) checkToolPathPermission(toolName, path string) error {
+	permission := a.ToolPermissions[toolName]
+	if len(permission.Paths) == 0 || pathInScope(path, permission.Paths) {
+		return nil
+	}
+	return fmt.Errorf("tool %s is not allowed to access %q (allowed: %s)", toolName, path, strings.Join(permission.Paths, ", "))
+}
+
+// withToolPermission wraps a custom tool's execution function with its permission check.
+// The permission is looked up per call, so permissions set after registration apply.
+func (a *Agent) withToolPermission(name string, execution func(ctx context.Context, args map[string]interface{}) (string, error)) func(ctx context.Context, args map[string]interface{}) (string, error) {
+	return func(ctx context.Context, args map[string]interface{}) (string, error) {
+		if err := a.checkToolPermission(name, args); err != nil {
+			return "", err
+		}
+		return execution(ctx, args)
+	}
+}
+
+// isPathArgument reports whether an argument name conventionally holds a file path
+func isPathArgument(key string) bool {
+	key = strings.ToLower(key)
+	switch key {
+	case "filename", "folder", "dir", "directory":
+		return true
+	}
+	return strings.HasSuffix(key, "path") || strings.HasSuffix(key, "paths")
+}
+
+// pathArgumentValues returns the string values of a path argument (a string or a list of strings)
+func pathArgumentValues(value interface{}) []string {
+	switch v := value.(type) {
+	case string:
+		if v == "" {
+			return nil
+		}
+		return []string{v}
+	case []string:
+		return v
+	case []interface{}:
+		paths := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok && s != "" {
+				paths = append(paths, s)
+			}
+		}
+		return paths
+	}
+	return nil
+}
+
+// pathInScope reports whether path is one of scopes or inside one of them
+func pathInScope(path string, scopes []string) bool {
+	for _, scope := range scopes {
+		p, s := filepath.Clean(path), filepath.Clean(scope)
+		if filepath.IsAbs(p) != filepath.IsAbs(s) {
+			var errP, errS error
+			p, errP = filepath.Abs(p)
+			s, errS = filepath.Abs(s)
+			if errP != nil || errS != nil {
+				continue
+			}
+		}
+		rel, err := filepath.Rel(s, p)
+		if err != nil {
+			continue
+		}
+		if rel == "." || (rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))) {
+			return true
+		}
+	}
+	return false
+}
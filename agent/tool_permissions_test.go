@@ -0,0 +1,80 @@
+package mcpagent
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+func TestApplyToolPermissionsHidesDisabledTools(t *testing.T) {
+	a := &Agent{}
+	WithToolPermissions(map[string]ToolPermission{
+		"get_api_spec": {Disabled: true},
+		"git_commit":   {Paths: []string{"src"}},
+	})(a)
+
+	tools := a.applyToolPermissions([]llmtypes.Tool{hintTestTool("get_api_spec"), hintTestTool("git_commit"), hintTestTool("read_file")})
+	if got := strings.Join(hintToolNames(tools), ","); got != "git_commit,read_file" {
+		t.Errorf("tools = %s, want git_commit,read_file", got)
+	}
+}
+
+func TestCheckToolPermissionScopesPathArguments(t *testing.T) {
+	a := &Agent{ToolPermissions: map[string]ToolPermission{
+		"git_commit": {Paths: []string{"src", "docs/"}},
+		"git_init":   {Disabled: true},
+	}}
+
+	tests := []struct {
+		name    string
+		tool    string
+		args    map[string]interface{}
+		allowed bool
+	}{
+		{"unrestricted tool", "git_status", map[string]interface{}{"path": "/etc/passwd"}, true},
+		{"disabled tool", "git_init", nil, false},
+		{"inside scope", "git_commit", map[string]interface{}{"paths": []interface{}{"src/main.go", "docs/readme.md"}}, true},
+		{"scope root", "git_commit", map[string]interface{}{"path": "src"}, true},
+		{"outside scope", "git_commit", map[string]interface{}{"paths": []interface{}{"src/main.go", "secrets.env"}}, false},
+		{"escape via dot-dot", "git_commit", map[string]interface{}{"file_path": "src/../secrets.env"}, false},
+		{"sibling prefix", "git_commit", map[string]interface{}{"path": "srcx/main.go"}, false},
+		{"non-path arguments ignored", "git_commit", map[string]interface{}{"message": "../../etc"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := a.checkToolPermission(tt.tool, tt.args)
+			if (err == nil) != tt.allowed {
+				t.Errorf("checkToolPermission(%s, %v) = %v, want allowed=%v", tt.tool, tt.args, err, tt.allowed)
+			}
+		})
+	}
+}
+
+func TestToolPermissionsEnforcedOnToolExecution(t *testing.T) {
+	a := &Agent{
+		Logger: loggerv2.NewNoop(),
+		ToolPermissions: map[string]ToolPermission{
+			"delete_file":  {Disabled: true},
+			"get_resource": {Disabled: true},
+		},
+	}
+	called := false
+	if err := a.RegisterCustomTool("delete_file", "Delete a file", map[string]interface{}{"type": "object"},
+		func(ctx context.Context, args map[string]interface{}) (string, error) {
+			called = true
+			return "deleted", nil
+		}, "workspace"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := a.customTools["delete_file"].Execution(context.Background(), map[string]interface{}{"path": "a.txt"}); err == nil || called {
+		t.Errorf("expected disabled custom tool to be rejected without running (err=%v, called=%v)", err, called)
+	}
+	if _, err := a.HandleVirtualTool(context.Background(), "get_resource", map[string]interface{}{"server": "s", "uri": "u"}); err == nil ||
+		!strings.Contains(err.Error(), "disabled") {
+		t.Errorf("expected disabled virtual tool to be rejected, got %v", err)
+	}
+}
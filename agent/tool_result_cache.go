@@ -0,0 +1,282 @@
+// tool_result_cache.go
+//
+// This file caches MCP tool results keyed by (user, server, tool, arguments).
+// Research workflows often repeat identical calls (the same context7 lookup,
+// the same file listing); with a cache configured, a repeated call returns
+// the stored result without the MCP round trip and its ToolCallEnd event is
+// flagged from_cache. Entries expire after a TTL and the least recently used
+// ones are evicted beyond the entry and byte limits. Only successful results
+// of tools that opt in are cached: tools named in the config or, without
+// names, tools known to be read-only. Virtual and custom tools always run. A
+// cache is safe for concurrent use and can be shared by several agents; the
+// user ID in the key keeps one user's results from another (see WithUserID).
+//
+// Exported:
+//   - ToolResultCacheConfig: TTL, size limits and tool selection
+//   - ToolResultCache / NewToolResultCache: The cache
+//   - ToolResultCacheStats: Hit, miss and eviction counters
+//   - WithToolResultCache: Use a cache when creating an agent
+
+package mcpagent
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/manishiitg/mcpagent/mcpclient"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Defaults applied by NewToolResultCache to zero config fields
+const (
+	DefaultToolResultCacheTTL        = 10 * time.Minute
+	DefaultToolResultCacheMaxEntries = 1000
+)
+
+// ToolResultCacheConfig configures a ToolResultCache
+type ToolResultCacheConfig struct {
+	// TTL is how long a result stays valid (0 = DefaultToolResultCacheTTL)
+	TTL time.Duration
+	// MaxEntries bounds the number of cached results (0 = DefaultToolResultCacheMaxEntries)
+	MaxEntries int
+	// MaxBytes bounds the JSON size of all cached results together; 0 = unlimited.
+	// A single result larger than MaxBytes is not cached.
+	MaxBytes int
+	// Tools are the tool names whose results are cached. Leave tools with
+	// side effects out of the list. When empty, only read-only MCP tools are
+	// cached: those listed under "read_only_tools" in the MCP config or
+	// annotated readOnlyHint by their server.
+	Tools []string
+}
+
+// ToolResultCacheStats counts cache activity since the cache was created
+type ToolResultCacheStats struct {
+	Hits      int64 `json:"hits"`
+	Misses    int64 `json:"misses"`
+	Evictions int64 `json:"evictions"` // Expired or pushed out by the size limits
+	Entries   int   `json:"entries"`
+	Bytes     int   `json:"bytes"`
+}
+
+// ToolResultCache is an LRU cache of MCP tool results with a TTL
+type ToolResultCache struct {
+	config ToolResultCacheConfig
+	tools  map[string]bool // nil = read-only tools (see cachesToolResult)
+	now    func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	order   *list.List // front = most recently used
+	bytes   int
+	stats   ToolResultCacheStats
+}
+
+type toolResultCacheEntry struct {
+	key     string
+	result  *mcp.CallToolResult
+	size    int
+	expires time.Time
+}
+
+// NewToolResultCache creates an empty cache
+func NewToolResultCache(config ToolResultCacheConfig) *ToolResultCache {
+	if config.TTL <= 0 {
+		config.TTL = DefaultToolResultCacheTTL
+	}
+	if config.MaxEntries <= 0 {
+		config.MaxEntries = DefaultToolResultCacheMaxEntries
+	}
+	c := &ToolResultCache{
+		config:  config,
+		now:     time.Now,
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+	}
+	if len(config.Tools) > 0 {
+		c.tools = make(map[string]bool, len(config.Tools))
+		for _, tool := range config.Tools {
+			c.tools[tool] = true
+		}
+	}
+	return c
+}
+
+// WithToolResultCache serves repeated MCP tool calls with identical arguments
+// from cache. Pass the same cache to several agents to share results between
+// them.
+//
+// Example:
+//
+//	cache := mcpagent.NewToolResultCache(mcpagent.ToolResultCacheConfig{
+//	    TTL:   30 * time.Minute,
+//	    Tools: []string{"resolve-library-id", "get-library-docs"},
+//	})
+//	agent, err := mcpagent.NewAgent(ctx, llm, configPath, mcpagent.WithToolResultCache(cache))
+//
+// Default: nil (no caching)
+func WithToolResultCache(cache *ToolResultCache) AgentOption {
+	return func(a *Agent) {
+		a.toolResultCache = cache
+	}
+}
+
+// Get returns the cached result of the call made for userID ("" = no user), if any
+func (c *ToolResultCache) Get(userID, server, tool string, args map[string]interface{}) (*mcp.CallToolResult, bool) {
+	key, ok := c.key(userID, server, tool, args)
+	if !ok {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, found := c.entries[key]
+	if !found {
+		c.stats.Misses++
+		return nil, false
+	}
+	entry := elem.Value.(*toolResultCacheEntry)
+	if !c.now().Before(entry.expires) {
+		c.remove(elem)
+		c.stats.Misses++
+		return nil, false
+	}
+	c.order.MoveToFront(elem)
+	c.stats.Hits++
+	return copyToolResult(entry.result), true
+}
+
+// Put stores a successful result of the call made for userID. Error
+// results, results of tools outside a non-empty ToolResultCacheConfig.Tools
+// and results over MaxBytes are ignored.
+func (c *ToolResultCache) Put(userID, server, tool string, args map[string]interface{}, result *mcp.CallToolResult) {
+	if result == nil || result.IsError {
+		return
+	}
+	key, ok := c.key(userID, server, tool, args)
+	if !ok {
+		return
+	}
+	encoded, err := json.Marshal(result)
+	if err != nil {
+		return
+	}
+	size := len(encoded)
+	if c.config.MaxBytes > 0 && size > c.config.MaxBytes {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, found := c.entries[key]; found {
+		c.bytes -= elem.Value.(*toolResultCacheEntry).size
+		c.order.Remove(elem)
+		delete(c.entries, key)
+	}
+	entry := &toolResultCacheEntry{key: key, result: copyToolResult(result), size: size, expires: c.now().Add(c.config.TTL)}
+	c.entries[key] = c.order.PushFront(entry)
+	c.bytes += size
+	for c.order.Len() > c.config.MaxEntries || (c.config.MaxBytes > 0 && c.bytes > c.config.MaxBytes) {
+		c.remove(c.order.Back())
+	}
+}
+
+// Clear removes every cached result
+func (c *ToolResultCache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = make(map[string]*list.Element)
+	c.order.Init()
+	c.bytes = 0
+}
+
+// DeleteUserData implements UserDataStore: it removes the results cached for userID
+func (c *ToolResultCache) DeleteUserData(_ context.Context, userID string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	prefix := userID + "\x00"
+	for key, elem := range c.entries {
+		if strings.HasPrefix(key, prefix) {
+			entry := elem.Value.(*toolResultCacheEntry)
+			c.order.Remove(elem)
+			delete(c.entries, key)
+			c.bytes -= entry.size
+		}
+	}
+	return nil
+}
+
+// Stats returns the cache counters
+func (c *ToolResultCache) Stats() ToolResultCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stats := c.stats
+	stats.Entries = c.order.Len()
+	stats.Bytes = c.bytes
+	return stats
+}
+
+// remove evicts elem; the caller holds c.mu
+func (c *ToolResultCache) remove(elem *list.Element) {
+	entry := elem.Value.(*toolResultCacheEntry)
+	c.order.Remove(elem)
+	delete(c.entries, entry.key)
+	c.bytes -= entry.size
+	c.stats.Evictions++
+}
+
+// key identifies a call; ok is false for tools that are not cached or
+// arguments that cannot be encoded. encoding/json sorts map keys, so equal
+// arguments give equal keys.
+func (c *ToolResultCache) key(userID, server, tool string, args map[string]interface{}) (string, bool) {
+	if c.tools != nil && !c.tools[tool] {
+		return "", false
+	}
+	encoded, err := json.Marshal(args)
+	if err != nil {
+		return "", false
+	}
+	return userID + "\x00" + server + "\x00" + tool + "\x00" + string(encoded), true
+}
+
+// cachesToolResult reports whether the agent caches results of tool of
+// server: tools named in ToolResultCacheConfig.Tools or, when it is empty,
+// tools classified read-only by the MCP config or the server's annotations
+func (a *Agent) cachesToolResult(ctx context.Context, client mcpclient.ClientInterface, server, tool string) bool {
+	if a.toolResultCache.tools != nil {
+		return a.toolResultCache.tools[tool]
+	}
+	if config, ok := a.serverConfigs[server]; ok {
+		if readOnly, ok := config.GetToolReadOnly(tool); ok {
+			return readOnly
+		}
+	}
+	annotation, ok := a.mcpToolAnnotations(ctx, client, server, tool)
+	return ok && annotation.ReadOnlyHint != nil && *annotation.ReadOnlyHint
+}
+
+// copyToolResult copies the result and its content list, so callers can
+// modify either without touching the cached entry
+func copyToolResult(result *mcp.CallToolResult) *mcp.CallToolResult {
+	copied := *result
+	copied.Content = append([]mcp.Content(nil), result.Content...)
+	return &copied
+}
+
+type toolResultCacheHitKey struct{}
+
+// withToolResultCacheHit returns a context in which callMCPTool records
+// whether the call was served from the tool result cache
+func withToolResultCacheHit(ctx context.Context, hit *atomic.Bool) context.Context {
+	return context.WithValue(ctx, toolResultCacheHitKey{}, hit)
+}
+
+// markToolResultCacheHit records a cache hit for the call running under ctx
+func markToolResultCacheHit(ctx context.Context) {
+	if hit, ok := ctx.Value(toolResultCacheHitKey{}).(*atomic.Bool); ok {
+		hit.Store(true)
+	}
+}
@@ -0,0 +1,115 @@
+package mcpagent
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+	"github.com/manishiitg/mcpagent/mcpclient"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// countingClient answers every CallTool with the tool name and counts the
+// calls. get-library-docs is annotated read-only.
+type countingClient struct {
+	mcpclient.ClientInterface
+	calls int
+}
+
+func (c *countingClient) ListTools(context.Context) ([]mcp.Tool, error) {
+	return []mcp.Tool{
+		mcp.NewTool("get-library-docs", mcp.WithReadOnlyHintAnnotation(true)),
+		mcp.NewTool("send_email"),
+	}, nil
+}
+
+func (c *countingClient) CallTool(_ context.Context, name string, _ map[string]interface{}) (*mcp.CallToolResult, error) {
+	c.calls++
+	return mcp.NewToolResultText("result of " + name), nil
+}
+
+func TestToolResultCacheServesRepeatedCalls(t *testing.T) {
+	client := &countingClient{}
+	a := &Agent{Logger: loggerv2.NewNoop()}
+	WithToolResultCache(NewToolResultCache(ToolResultCacheConfig{}))(a)
+
+	args := map[string]interface{}{"library": "react", "topic": "hooks"}
+	if _, err := a.callMCPTool(context.Background(), client, "get-library-docs", args, a.Logger, "context7"); err != nil {
+		t.Fatal(err)
+	}
+	var hit atomic.Bool
+	ctx := withToolResultCacheHit(context.Background(), &hit)
+	// Same arguments in another map: the key must not depend on map order
+	result, err := a.callMCPTool(ctx, client, "get-library-docs", map[string]interface{}{"topic": "hooks", "library": "react"}, a.Logger, "context7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.calls != 1 || !hit.Load() {
+		t.Errorf("calls = %d, hit = %v; want the second call served from cache", client.calls, hit.Load())
+	}
+	if text := result.Content[0].(mcp.TextContent).Text; text != "result of get-library-docs" {
+		t.Errorf("cached result = %q", text)
+	}
+
+	// Different arguments or server miss the cache
+	_, _ = a.callMCPTool(context.Background(), client, "get-library-docs", map[string]interface{}{"library": "vue"}, a.Logger, "context7")
+	_, _ = a.callMCPTool(context.Background(), client, "get-library-docs", args, a.Logger, "other")
+	if client.calls != 3 {
+		t.Errorf("calls = %d, want 3", client.calls)
+	}
+	if stats := a.toolResultCache.Stats(); stats.Hits != 1 || stats.Misses != 3 || stats.Entries != 3 {
+		t.Errorf("stats = %+v", stats)
+	}
+
+	// Another user's identical call misses the cache
+	a.UserID = "bob"
+	_, _ = a.callMCPTool(context.Background(), client, "get-library-docs", args, a.Logger, "context7")
+	if client.calls != 4 {
+		t.Errorf("calls = %d; another user must not get a cached result", client.calls)
+	}
+
+	// Tools not known to be read-only are never cached without a Tools list
+	for i := 0; i < 2; i++ {
+		_, _ = a.callMCPTool(context.Background(), client, "send_email", map[string]interface{}{"to": "x"}, a.Logger, "context7")
+	}
+	if client.calls != 6 {
+		t.Errorf("calls = %d; send_email must run every time", client.calls)
+	}
+}
+
+func TestToolResultCacheLimits(t *testing.T) {
+	now := time.Now()
+	cache := NewToolResultCache(ToolResultCacheConfig{TTL: time.Minute, MaxEntries: 2, Tools: []string{"read", "list"}})
+	cache.now = func() time.Time { return now }
+	result := mcp.NewToolResultText("ok")
+
+	cache.Put("", "fs", "write", nil, result)
+	cache.Put("", "fs", "read", nil, mcp.NewToolResultError("denied"))
+	if stats := cache.Stats(); stats.Entries != 0 {
+		t.Fatalf("unlisted tools and error results should not be cached: %+v", stats)
+	}
+
+	cache.Put("", "fs", "read", map[string]interface{}{"path": "a"}, result)
+	cache.Put("", "fs", "read", map[string]interface{}{"path": "b"}, result)
+	if _, ok := cache.Get("", "fs", "read", map[string]interface{}{"path": "a"}); !ok {
+		t.Fatal("expected a hit for a")
+	}
+	// a was used last, so adding a third entry evicts b
+	cache.Put("", "fs", "list", nil, result)
+	if _, ok := cache.Get("", "fs", "read", map[string]interface{}{"path": "b"}); ok {
+		t.Error("b should have been evicted")
+	}
+
+	now = now.Add(time.Minute)
+	if _, ok := cache.Get("", "fs", "read", map[string]interface{}{"path": "a"}); ok {
+		t.Error("a should have expired")
+	}
+
+	small := NewToolResultCache(ToolResultCacheConfig{MaxBytes: 10})
+	small.Put("", "fs", "read", nil, result)
+	if stats := small.Stats(); stats.Entries != 0 {
+		t.Errorf("results over MaxBytes should not be cached: %+v", stats)
+	}
+}
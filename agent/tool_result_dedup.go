@@ -0,0 +1,188 @@
+// tool_result_dedup.go
+//
+// This file deduplicates tool results across the conversation history. Long
+// sessions accumulate identical tool outputs (the same page fetched twice,
+// the same file read again); every copy is resent to the LLM on each turn.
+// Before each LLM call, tool results whose content hash matches an earlier
+// result are replaced with a short reference to the first occurrence. The
+// first occurrence is never modified, so the model can always follow the
+// reference. Savings are reported in a ToolResultsDeduplicated event and
+// accumulated in Agent.GetToolResultDeduplicationStats.
+//
+// Exported:
+//   - ToolResultDeduplicationConfig: Size threshold and per-tool selection
+//   - WithToolResultDeduplication: Enable deduplication when creating an agent
+//   - ToolResultDeduplicationStats / Agent.GetToolResultDeduplicationStats
+
+package mcpagent
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"strings"
+
+	"github.com/manishiitg/mcpagent/events"
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+// DefaultDeduplicationMinTokens is the smallest tool result (in tokens) worth
+// replacing; shorter results cost about as much as the reference itself
+const DefaultDeduplicationMinTokens = 100
+
+// duplicateResultPrefix marks a tool result replaced by a reference
+const duplicateResultPrefix = "[Duplicate tool result]"
+
+// ToolResultDeduplicationConfig selects which tool results are deduplicated
+type ToolResultDeduplicationConfig struct {
+	// MinTokens skips results smaller than this (0 = DefaultDeduplicationMinTokens)
+	MinTokens int
+	// OnlyTools limits deduplication to these tools (empty = all tools)
+	OnlyTools []string
+	// ExcludeTools are never deduplicated, e.g. polling tools whose repeated
+	// identical output is itself meaningful
+	ExcludeTools []string
+}
+
+// ToolResultDeduplicationStats reports the savings of deduplication so far
+type ToolResultDeduplicationStats struct {
+	DeduplicatedResults int
+	TokensSaved         int
+}
+
+// WithToolResultDeduplication replaces tool results identical to an earlier
+// result in the history with a reference to the first occurrence.
+//
+// Example:
+//
+//	mcpagent.WithToolResultDeduplication(mcpagent.ToolResultDeduplicationConfig{
+//	    ExcludeTools: []string{"get_job_status"},
+//	})
+//
+// Default: disabled
+func WithToolResultDeduplication(config ToolResultDeduplicationConfig) AgentOption {
+	return func(a *Agent) {
+		a.ToolResultDeduplication = &config
+	}
+}
+
+// GetToolResultDeduplicationStats returns the cumulative deduplication savings
+func (a *Agent) GetToolResultDeduplicationStats() ToolResultDeduplicationStats {
+	a.tokenTrackingMutex.RLock()
+	defer a.tokenTrackingMutex.RUnlock()
+	return a.dedupStats
+}
+
+// appliesTo reports whether results of toolName may be deduplicated
+func (c *ToolResultDeduplicationConfig) appliesTo(toolName string) bool {
+	for _, name := range c.ExcludeTools {
+		if name == toolName {
+			return false
+		}
+	}
+	if len(c.OnlyTools) == 0 {
+		return true
+	}
+	for _, name := range c.OnlyTools {
+		if name == toolName {
+			return true
+		}
+	}
+	return false
+}
+
+// deduplicateToolResults replaces repeated identical tool results with
+// references to their first occurrence. messages is not modified; a new slice
+// is returned when anything was replaced.
+func deduplicateToolResults(a *Agent, ctx context.Context, messages []llmtypes.MessageContent, currentTurn int) []llmtypes.MessageContent {
+	config := a.ToolResultDeduplication
+	if config == nil {
+		return messages
+	}
+	minTokens := config.MinTokens
+	if minTokens <= 0 {
+		minTokens = DefaultDeduplicationMinTokens
+	}
+
+	type firstResult struct {
+		toolName   string
+		toolCallID string
+	}
+	seen := make(map[[sha256.Size]byte]firstResult)
+	var result []llmtypes.MessageContent
+	var duplicates []events.DeduplicatedToolResult
+	tokensSaved := 0
+
+	for i, msg := range messages {
+		if msg.Role != llmtypes.ChatMessageTypeTool {
+			continue
+		}
+		var parts []llmtypes.ContentPart
+		for j, part := range msg.Parts {
+			tr, ok := part.(llmtypes.ToolCallResponse)
+			if !ok || tr.IsError || strings.HasPrefix(tr.Content, duplicateResultPrefix) || !config.appliesTo(tr.Name) {
+				continue
+			}
+			hash := sha256.Sum256([]byte(tr.Content))
+			first, duplicate := seen[hash]
+			if !duplicate {
+				seen[hash] = firstResult{toolName: tr.Name, toolCallID: tr.ToolCallID}
+				continue
+			}
+			tokens := a.countDedupTokens(tr.Content)
+			if tokens < minTokens {
+				continue
+			}
+
+			if parts == nil {
+				parts = make([]llmtypes.ContentPart, len(msg.Parts))
+				copy(parts, msg.Parts)
+			}
+			tr.Content = fmt.Sprintf("%s Identical to the result of %s (tool call %s) earlier in this conversation; refer to that result.",
+				duplicateResultPrefix, first.toolName, first.toolCallID)
+			parts[j] = tr
+			saved := tokens - a.countDedupTokens(tr.Content)
+			tokensSaved += saved
+			duplicates = append(duplicates, events.DeduplicatedToolResult{
+				ToolName:        tr.Name,
+				ToolCallID:      tr.ToolCallID,
+				FirstToolName:   first.toolName,
+				FirstToolCallID: first.toolCallID,
+				TokensSaved:     saved,
+			})
+		}
+		if parts != nil {
+			if result == nil {
+				result = make([]llmtypes.MessageContent, len(messages))
+				copy(result, messages)
+			}
+			result[i] = llmtypes.MessageContent{Role: msg.Role, Parts: parts}
+		}
+	}
+
+	if result == nil {
+		return messages
+	}
+
+	a.tokenTrackingMutex.Lock()
+	a.dedupStats.DeduplicatedResults += len(duplicates)
+	a.dedupStats.TokensSaved += tokensSaved
+	totalSaved := a.dedupStats.TokensSaved
+	a.tokenTrackingMutex.Unlock()
+
+	a.Logger.Info("♻️ [DEDUP] Replaced duplicate tool results with references",
+		loggerv2.Int("deduplicated_count", len(duplicates)),
+		loggerv2.Int("tokens_saved", tokensSaved),
+		loggerv2.Int("current_turn", currentTurn))
+	a.EmitTypedEvent(ctx, events.NewToolResultsDeduplicatedEvent(currentTurn, tokensSaved, totalSaved, duplicates))
+	return result
+}
+
+// countDedupTokens counts tokens with the model's encoding when available
+func (a *Agent) countDedupTokens(content string) int {
+	if a.toolOutputHandler != nil {
+		return a.toolOutputHandler.CountTokensForModel(content, a.ModelID)
+	}
+	return len(content) / 4
+}
@@ -0,0 +1,77 @@
+package mcpagent
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/manishiitg/mcpagent/events"
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+func toolResultMessage(id, name, content string) llmtypes.MessageContent {
+	return llmtypes.MessageContent{
+		Role:  llmtypes.ChatMessageTypeTool,
+		Parts: []llmtypes.ContentPart{llmtypes.ToolCallResponse{ToolCallID: id, Name: name, Content: content}},
+	}
+}
+
+func toolResultContent(msg llmtypes.MessageContent) string {
+	return msg.Parts[0].(llmtypes.ToolCallResponse).Content
+}
+
+func TestDeduplicateToolResultsReplacesRepeats(t *testing.T) {
+	page := strings.Repeat("<p>quarterly results</p>", 100)
+	listener := &recordingAgentEventListener{}
+	a := &Agent{Logger: loggerv2.NewNoop()}
+	a.AddEventListener(listener)
+	WithToolResultDeduplication(ToolResultDeduplicationConfig{ExcludeTools: []string{"job_status"}})(a)
+
+	messages := []llmtypes.MessageContent{
+		llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "compare"),
+		toolResultMessage("call-1", "fetch_page", page),
+		toolResultMessage("call-2", "fetch_page", page),
+		toolResultMessage("call-3", "job_status", page), // excluded tool
+		toolResultMessage("call-4", "fetch_page", "short"),
+		toolResultMessage("call-5", "fetch_page", "short"), // below MinTokens
+	}
+
+	got := deduplicateToolResults(a, context.Background(), messages, 2)
+
+	if toolResultContent(got[1]) != page {
+		t.Error("first occurrence must be kept")
+	}
+	if ref := toolResultContent(got[2]); !strings.HasPrefix(ref, duplicateResultPrefix) || !strings.Contains(ref, "call-1") {
+		t.Errorf("duplicate not replaced by a reference: %q", ref)
+	}
+	if toolResultContent(got[3]) != page || toolResultContent(got[5]) != "short" {
+		t.Error("excluded and small results must be kept")
+	}
+	if toolResultContent(messages[2]) != page {
+		t.Error("input messages must not be modified")
+	}
+
+	stats := a.GetToolResultDeduplicationStats()
+	if stats.DeduplicatedResults != 1 || stats.TokensSaved <= 0 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+
+	// References are stable: a second pass changes nothing
+	if again := deduplicateToolResults(a, context.Background(), got, 3); toolResultContent(again[2]) != toolResultContent(got[2]) {
+		t.Error("expected the reference to be kept on later turns")
+	}
+	if a.GetToolResultDeduplicationStats() != stats {
+		t.Error("expected no additional savings on an already deduplicated history")
+	}
+
+	var reported *events.ToolResultsDeduplicatedEvent
+	for _, event := range listener.events {
+		if data, ok := event.Data.(*events.ToolResultsDeduplicatedEvent); ok {
+			reported = data
+		}
+	}
+	if reported == nil || reported.DeduplicatedCount != 1 || reported.Duplicates[0].FirstToolCallID != "call-1" {
+		t.Errorf("expected a deduplication event, got %+v", reported)
+	}
+}
@@ -56,7 +56,7 @@ func (a *Agent) handleSearchTools(ctx context.Context, args map[string]interface
 		}
 	}
 
-	return a.formatSearchResults(matches)
+	return a.formatSearchResults(a.rankToolSearchResults(ctx, matches))
 }
 
 // handleAddTool handles the add_tool virtual tool
@@ -372,9 +372,15 @@ func (a *Agent) getToolsForToolSearchMode() []llmtypes.Tool {
 	// Start with search_tools
 	tools := CreateToolSearchTools()
 
-	// Add discovered tools (includes pre-discovered)
-	for _, tool := range a.discoveredTools {
-		tools = append(tools, tool)
+	// Add discovered tools (includes pre-discovered), sorted by name so the
+	// tool list is identical across turns and runs
+	names := make([]string, 0, len(a.discoveredTools))
+	for name := range a.discoveredTools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		tools = append(tools, a.discoveredTools[name])
 	}
 
 	return tools
@@ -0,0 +1,224 @@
+// tool_stats.go
+//
+// This file aggregates tool usage analytics from the agent's event stream:
+// call counts, error rates, latency percentiles and bytes returned, per tool
+// and per MCP server. Every tool_call_end and tool_call_error event the agent
+// publishes is counted, including those of its sub-agents, so UIs can show
+// which servers are slow or flaky. Latency percentiles are computed over the
+// most recent toolStatsLatencySamples calls of each tool.
+//
+// Exported:
+//   - ToolCallStats: Counters and latencies shared by tool and server stats
+//   - ToolStats, ServerToolStats, ToolStatsReport: Aggregated statistics
+//   - Agent.GetToolStats: Snapshot of the statistics since the agent started
+
+package mcpagent
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/manishiitg/mcpagent/events"
+)
+
+// toolStatsLatencySamples bounds the latency samples kept per tool
+const toolStatsLatencySamples = 1024
+
+// ToolCallStats are the usage statistics of a tool or a server
+type ToolCallStats struct {
+	Calls  int
+	Errors int
+	// ErrorRate is Errors / Calls (0 without calls)
+	ErrorRate float64
+	// CacheHits counts calls answered from the tool result cache
+	CacheHits     int
+	P50Latency    time.Duration
+	P95Latency    time.Duration
+	MaxLatency    time.Duration
+	BytesReturned int64
+}
+
+// ToolStats are the usage statistics of one tool
+type ToolStats struct {
+	ToolName string
+	// ServerName is the MCP server, or "" for virtual and custom tools
+	ServerName string
+	ToolCallStats
+}
+
+// ServerToolStats are the usage statistics of all tools of one server
+type ServerToolStats struct {
+	ServerName string
+	ToolCallStats
+}
+
+// ToolStatsReport is a snapshot of the agent's tool usage
+type ToolStatsReport struct {
+	// Tools sorted by server, then tool name
+	Tools []ToolStats
+	// Servers sorted by name; tools without a server are not included
+	Servers []ServerToolStats
+}
+
+// toolStatsKey identifies a tool
+type toolStatsKey struct {
+	server, tool string
+}
+
+// toolStatsEntry accumulates the calls of one tool
+type toolStatsEntry struct {
+	calls, errors, cacheHits int
+	bytes                    int64
+	maxLatency               time.Duration
+	latencies                []time.Duration // ring buffer of the recent calls
+	next                     int
+}
+
+func (e *toolStatsEntry) record(latency time.Duration) {
+	if latency > e.maxLatency {
+		e.maxLatency = latency
+	}
+	if len(e.latencies) < toolStatsLatencySamples {
+		e.latencies = append(e.latencies, latency)
+		return
+	}
+	e.latencies[e.next] = latency
+	e.next = (e.next + 1) % toolStatsLatencySamples
+}
+
+// toolStatsCollector aggregates tool call events
+type toolStatsCollector struct {
+	mu    sync.Mutex
+	tools map[toolStatsKey]*toolStatsEntry
+}
+
+// observe counts a tool call event; other events are ignored
+func (c *toolStatsCollector) observe(event *events.AgentEvent) {
+	if event == nil {
+		return
+	}
+	switch data := event.Data.(type) {
+	case *events.ToolCallEndEvent:
+		entry := c.entry(data.ServerName, data.ToolName)
+		defer c.mu.Unlock()
+		entry.calls++
+		entry.bytes += int64(len(data.Result))
+		if data.FromCache {
+			entry.cacheHits++
+		}
+		entry.record(data.Duration)
+	case *events.ToolCallErrorEvent:
+		entry := c.entry(data.ServerName, data.ToolName)
+		defer c.mu.Unlock()
+		entry.calls++
+		entry.errors++
+		entry.record(data.Duration)
+	}
+}
+
+// entry returns the entry of a tool with c.mu held
+func (c *toolStatsCollector) entry(server, tool string) *toolStatsEntry {
+	c.mu.Lock()
+	if c.tools == nil {
+		c.tools = make(map[toolStatsKey]*toolStatsEntry)
+	}
+	key := toolStatsKey{server: server, tool: tool}
+	entry, ok := c.tools[key]
+	if !ok {
+		entry = &toolStatsEntry{}
+		c.tools[key] = entry
+	}
+	return entry
+}
+
+// report builds a snapshot of the collected statistics
+func (c *toolStatsCollector) report() ToolStatsReport {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var report ToolStatsReport
+	servers := make(map[string]*toolStatsEntry)
+	for key, entry := range c.tools {
+		report.Tools = append(report.Tools, ToolStats{
+			ToolName:      key.tool,
+			ServerName:    key.server,
+			ToolCallStats: entry.stats(entry.latencies),
+		})
+		if key.server == "" {
+			continue
+		}
+		server, ok := servers[key.server]
+		if !ok {
+			server = &toolStatsEntry{}
+			servers[key.server] = server
+		}
+		server.calls += entry.calls
+		server.errors += entry.errors
+		server.cacheHits += entry.cacheHits
+		server.bytes += entry.bytes
+		if entry.maxLatency > server.maxLatency {
+			server.maxLatency = entry.maxLatency
+		}
+		server.latencies = append(server.latencies, entry.latencies...)
+	}
+	for name, server := range servers {
+		report.Servers = append(report.Servers, ServerToolStats{
+			ServerName:    name,
+			ToolCallStats: server.stats(server.latencies),
+		})
+	}
+	sort.Slice(report.Tools, func(i, j int) bool {
+		if report.Tools[i].ServerName != report.Tools[j].ServerName {
+			return report.Tools[i].ServerName < report.Tools[j].ServerName
+		}
+		return report.Tools[i].ToolName < report.Tools[j].ToolName
+	})
+	sort.Slice(report.Servers, func(i, j int) bool {
+		return report.Servers[i].ServerName < report.Servers[j].ServerName
+	})
+	return report
+}
+
+// stats converts the counters, with percentiles over latencies
+func (e *toolStatsEntry) stats(latencies []time.Duration) ToolCallStats {
+	stats := ToolCallStats{
+		Calls:         e.calls,
+		Errors:        e.errors,
+		CacheHits:     e.cacheHits,
+		MaxLatency:    e.maxLatency,
+		BytesReturned: e.bytes,
+	}
+	if e.calls > 0 {
+		stats.ErrorRate = float64(e.errors) / float64(e.calls)
+	}
+	if len(latencies) > 0 {
+		sorted := append([]time.Duration(nil), latencies...)
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+		stats.P50Latency = latencyPercentile(sorted, 50)
+		stats.P95Latency = latencyPercentile(sorted, 95)
+	}
+	return stats
+}
+
+// latencyPercentile returns the nearest-rank percentile p of sorted latencies
+func latencyPercentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// GetToolStats returns per-tool and per-server call counts, error rates,
+// p50/p95 latencies and bytes returned, aggregated from the tool call events
+// the agent (and its sub-agents) emitted since it was created.
+//
+// Example:
+//
+//	for _, s := range agent.GetToolStats().Servers {
+//	    fmt.Printf("%s: %d calls, %.0f%% errors, p95 %s\n", s.ServerName, s.Calls, s.ErrorRate*100, s.P95Latency)
+//	}
+func (a *Agent) GetToolStats() ToolStatsReport {
+	return a.toolStats.report()
+}
@@ -0,0 +1,47 @@
+package mcpagent
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/manishiitg/mcpagent/events"
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+)
+
+func TestGetToolStatsAggregatesToolCallEvents(t *testing.T) {
+	a := &Agent{Logger: loggerv2.NewNoop()}
+	ctx := context.Background()
+	for i := 1; i <= 20; i++ {
+		a.EmitTypedEvent(ctx, &events.ToolCallEndEvent{
+			ToolName: "search", ServerName: "github", Result: "abcd",
+			Duration: time.Duration(i) * time.Millisecond, FromCache: i%10 == 0,
+		})
+	}
+	a.EmitTypedEvent(ctx, &events.ToolCallErrorEvent{ToolName: "search", ServerName: "github", Duration: time.Second})
+	a.EmitTypedEvent(ctx, &events.ToolCallEndEvent{ToolName: "get_issue", ServerName: "github", Result: "ok", Duration: 5 * time.Millisecond})
+	a.EmitTypedEvent(ctx, &events.ToolCallEndEvent{ToolName: "add_note", Result: "done", Duration: time.Millisecond})
+
+	report := a.GetToolStats()
+	if len(report.Tools) != 3 || report.Tools[0].ToolName != "add_note" || report.Tools[2].ToolName != "search" {
+		t.Fatalf("tools = %+v", report.Tools)
+	}
+	search := report.Tools[2]
+	if search.Calls != 21 || search.Errors != 1 || search.CacheHits != 2 || search.BytesReturned != 80 {
+		t.Errorf("search counters = %+v", search.ToolCallStats)
+	}
+	if search.P50Latency != 11*time.Millisecond || search.P95Latency != 20*time.Millisecond || search.MaxLatency != time.Second {
+		t.Errorf("search latencies: p50 %s, p95 %s, max %s", search.P50Latency, search.P95Latency, search.MaxLatency)
+	}
+
+	if len(report.Servers) != 1 {
+		t.Fatalf("servers = %+v, want only github", report.Servers)
+	}
+	github := report.Servers[0]
+	if github.ServerName != "github" || github.Calls != 22 || github.Errors != 1 || github.BytesReturned != 82 {
+		t.Errorf("github = %+v", github)
+	}
+	if want := 1.0 / 22; github.ErrorRate != want {
+		t.Errorf("ErrorRate = %v, want %v", github.ErrorRate, want)
+	}
+}
@@ -0,0 +1,197 @@
+// tool_structure.go
+//
+// This file maintains the code-execution tool structure (the JSON index of
+// servers/categories and their tools shown in the system prompt) incrementally.
+// buildToolIndex seeds the index from the full agent state; registering a
+// custom tool afterwards only moves that tool between categories, re-renders
+// the affected category and patches the JSON block in the system prompt in
+// place, instead of rediscovering every tool and rebuilding the whole prompt.
+// Agents that register dozens of workspace/human tools at startup pay O(1)
+// prompt work per registration rather than O(N).
+
+package mcpagent
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+)
+
+// toolStructureIndex is the tool structure split into sections (MCP servers
+// and custom tool categories), each rendered once and cached until it changes
+type toolStructureIndex struct {
+	servers   map[string]map[string]bool // normalized server name → tools
+	custom    map[string]map[string]bool // custom tool category → tools
+	fragments map[string]string          // section → rendered JSON fragment
+}
+
+// toolStructureSection mirrors the JSON shape of one index entry
+type toolStructureSection struct {
+	Tools []string `json:"tools"`
+}
+
+func newToolStructureIndex(servers, custom map[string]map[string]bool) *toolStructureIndex {
+	return &toolStructureIndex{
+		servers:   servers,
+		custom:    custom,
+		fragments: make(map[string]string),
+	}
+}
+
+// section returns the tools of a section; a custom category shadows a server of the same name
+func (x *toolStructureIndex) section(name string) map[string]bool {
+	if tools, ok := x.custom[name]; ok {
+		return tools
+	}
+	return x.servers[name]
+}
+
+func (x *toolStructureIndex) sectionNames() []string {
+	names := make([]string, 0, len(x.servers)+len(x.custom))
+	for name := range x.servers {
+		names = append(names, name)
+	}
+	for name := range x.custom {
+		if _, ok := x.servers[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (x *toolStructureIndex) sectionCount() int {
+	return len(x.sectionNames())
+}
+
+func (x *toolStructureIndex) toolCount() int {
+	total := 0
+	for _, name := range x.sectionNames() {
+		total += len(x.section(name))
+	}
+	return total
+}
+
+// setCustomTool places a custom tool in category, removing it from any other
+// category. An empty category removes the tool from the index.
+func (x *toolStructureIndex) setCustomTool(toolName, category string) {
+	for name, tools := range x.custom {
+		if name == category || !tools[toolName] {
+			continue
+		}
+		delete(tools, toolName)
+		delete(x.fragments, name)
+		if len(tools) == 0 {
+			delete(x.custom, name)
+		}
+	}
+	if category == "" || x.custom[category][toolName] {
+		return
+	}
+	if x.custom[category] == nil {
+		x.custom[category] = make(map[string]bool)
+	}
+	x.custom[category][toolName] = true
+	delete(x.fragments, category)
+}
+
+// render returns the index as indented JSON, byte-identical to marshaling the
+// whole map, re-rendering only sections changed since the last call
+func (x *toolStructureIndex) render() (string, error) {
+	names := x.sectionNames()
+	if len(names) == 0 {
+		return "{}", nil
+	}
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		fragment, ok := x.fragments[name]
+		if !ok {
+			tools := make([]string, 0, len(x.section(name)))
+			for toolName := range x.section(name) {
+				tools = append(tools, toolName)
+			}
+			sort.Strings(tools)
+			key, err := json.Marshal(name)
+			if err != nil {
+				return "", err
+			}
+			value, err := json.MarshalIndent(toolStructureSection{Tools: tools}, "  ", "  ")
+			if err != nil {
+				return "", err
+			}
+			fragment = "  " + string(key) + ": " + string(value)
+			x.fragments[name] = fragment
+		}
+		parts = append(parts, fragment)
+	}
+	return "{\n" + strings.Join(parts, ",\n") + "\n}", nil
+}
+
+// updateToolStructure re-indexes one custom tool and returns the tool
+// structure before and after the change. Without a seeded index (or for an
+// empty toolName) it falls back to a full buildToolIndex and previous is "".
+func (a *Agent) updateToolStructure(toolName string) (previous, current string, err error) {
+	a.toolStructureMu.Lock()
+	index := a.toolStructure
+	if index == nil || toolName == "" {
+		a.toolStructureMu.Unlock()
+		current, err = a.buildToolIndex()
+		return "", current, err
+	}
+	defer a.toolStructureMu.Unlock()
+
+	if previous, err = index.render(); err != nil {
+		return "", "", fmt.Errorf("failed to marshal tool index: %w", err)
+	}
+	category := ""
+	if ct, ok := a.customTools[toolName]; ok && a.isToolAllowed(toolName) && a.isToolEnabled(toolName) {
+		category = ct.Category
+	}
+	index.setCustomTool(toolName, category)
+	if current, err = index.render(); err != nil {
+		return "", "", fmt.Errorf("failed to marshal tool index: %w", err)
+	}
+	return previous, current, nil
+}
+
+// invalidateToolStructure drops the incremental index after a change that
+// affects many tools (e.g. the allow list); the next update rebuilds it
+func (a *Agent) invalidateToolStructure() {
+	a.toolStructureMu.Lock()
+	a.toolStructure = nil
+	a.toolStructureMu.Unlock()
+}
+
+// patchToolStructureInPrompt replaces the tool structure JSON block in the
+// system prompt. It reports false when the prompt does not contain exactly
+// one copy of previous, in which case the caller must rebuild the prompt.
+func (a *Agent) patchToolStructureInPrompt(previous, current string) bool {
+	if previous == "" {
+		return false
+	}
+	oldBlock := "```json\n" + previous + "\n```"
+	if strings.Count(a.systemPrompt, oldBlock) != 1 {
+		return false
+	}
+	if previous != current {
+		a.systemPrompt = strings.Replace(a.systemPrompt, oldBlock, "```json\n"+current+"\n```", 1)
+	}
+	if a.Logger != nil {
+		a.Logger.Debug("🔧 [CODE_EXECUTION] Patched tool structure in system prompt",
+			loggerv2.Int("tool_structure_bytes", len(current)))
+	}
+	return true
+}
+
+// isPreDiscoveredTool reports whether toolName has its full spec pre-loaded in the prompt
+func (a *Agent) isPreDiscoveredTool(toolName string) bool {
+	for _, name := range a.preDiscoveredTools {
+		if name == toolName {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,77 @@
+package mcpagent
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+)
+
+func toolSet(names ...string) map[string]bool {
+	set := make(map[string]bool, len(names))
+	for _, name := range names {
+		set[name] = true
+	}
+	return set
+}
+
+func TestToolStructureIndexRenderMatchesFullMarshal(t *testing.T) {
+	index := newToolStructureIndex(
+		map[string]map[string]bool{"playwright": toolSet("browser_navigate", "browser_click"), "shadowed": toolSet("old")},
+		map[string]map[string]bool{"workspace": toolSet("read_file"), "shadowed": toolSet("new")},
+	)
+	index.setCustomTool("write_file", "workspace")
+	index.setCustomTool("ask_human", "human_tools")
+	index.setCustomTool("read_file", "human_tools") // moves categories
+	index.setCustomTool("write_file", "")           // removed, empties "workspace"
+
+	got, err := index.render()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := json.MarshalIndent(map[string]toolStructureSection{
+		"playwright":  {Tools: []string{"browser_click", "browser_navigate"}},
+		"shadowed":    {Tools: []string{"new"}},
+		"human_tools": {Tools: []string{"ask_human", "read_file"}},
+	}, "", "  ")
+	if got != string(want) {
+		t.Errorf("render() =\n%s\nwant\n%s", got, want)
+	}
+
+	empty, _ := newToolStructureIndex(map[string]map[string]bool{}, map[string]map[string]bool{}).render()
+	if empty != "{}" {
+		t.Errorf("empty index rendered %q", empty)
+	}
+}
+
+func TestRegisterCustomToolPatchesToolStructureInPrompt(t *testing.T) {
+	a := &Agent{
+		Logger:               loggerv2.NewNoop(),
+		UseCodeExecutionMode: true,
+		toolToServer:         map[string]string{"browser_navigate": "playwright"},
+		toolFilter:           &ToolFilter{},
+		customTools:          map[string]CustomTool{},
+	}
+	initial, err := a.buildToolIndex()
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.systemPrompt = "PROLOGUE\n```json\n" + initial + "\n```\nEPILOGUE"
+
+	a.customTools["read_file"] = CustomTool{Category: "workspace"}
+	if err := a.rebuildSystemPromptWithUpdatedToolStructure("read_file"); err != nil {
+		t.Fatal(err)
+	}
+
+	want, err := a.buildToolIndex()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.systemPrompt != "PROLOGUE\n```json\n"+want+"\n```\nEPILOGUE" {
+		t.Errorf("expected only the tool structure block to change, got:\n%s", a.systemPrompt)
+	}
+	if !strings.Contains(a.systemPrompt, `"read_file"`) {
+		t.Error("expected the new custom tool in the patched prompt")
+	}
+}
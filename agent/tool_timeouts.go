@@ -0,0 +1,103 @@
+// tool_timeouts.go
+//
+// This file resolves the timeout of each tool call and builds the result the
+// model sees when a call hits it. The timeout comes from, in order:
+//   - WithPerToolTimeout, by the tool name the model calls
+//   - "tool_timeouts" of the tool's server in the MCP config, by tool name
+//   - "tool_timeout" of the tool's server in the MCP config
+//   - the custom tool's own Timeout (custom tools only)
+//   - WithToolTimeout, or the TOOL_EXECUTION_TIMEOUT environment variable
+//
+// A call that times out is answered with a structured TOOL TIMEOUT result
+// instead of a generic error: which tool, which limit, the progress the
+// server reported before the deadline (if any) and how to adapt.
+//
+// Exported:
+//   - WithPerToolTimeout: Per-tool timeout overrides when creating an agent
+
+package mcpagent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/manishiitg/mcpagent/mcpclient"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// WithPerToolTimeout overrides the tool timeout of individual tools, by the
+// tool name the model calls. A zero duration disables the timeout of that
+// tool. Takes precedence over the server's "tool_timeout"/"tool_timeouts" in
+// the MCP config and over WithToolTimeout.
+//
+// Example:
+//
+//	mcpagent.WithPerToolTimeout(map[string]time.Duration{
+//	    "crawl_site": 15 * time.Minute,
+//	    "get_weather": 10 * time.Second,
+//	})
+//
+// Default: nil (the server's configured timeout, else the global one)
+func WithPerToolTimeout(timeouts map[string]time.Duration) AgentOption {
+	return func(a *Agent) {
+		a.perToolTimeouts = timeouts
+	}
+}
+
+// resolveToolTimeout returns the timeout of a call to toolName on serverName;
+// <= 0 means the call has no timeout
+func (a *Agent) resolveToolTimeout(toolName, serverName string) time.Duration {
+	if timeout, ok := a.perToolTimeouts[toolName]; ok {
+		return timeout
+	}
+	if !isVirtualTool(toolName) {
+		if config, ok := a.serverConfigs[serverName]; ok {
+			if timeout, ok := config.GetToolTimeout(actualMCPToolName(toolName, serverName)); ok {
+				return timeout
+			}
+		}
+	}
+	if customTool, exists := a.customTools[toolName]; exists && customTool.Timeout != -1 {
+		// Custom tools: 0 = no timeout, > 0 = their own timeout
+		return customTool.Timeout
+	}
+	return getToolExecutionTimeout(a)
+}
+
+// toolTimeoutResult builds the result returned to the model when a call to
+// toolName hit its timeout. partial is what the call returned, if anything,
+// e.g. the progress reported before the deadline (see callToolWithTimeoutWrapper).
+func toolTimeoutResult(toolName, serverName string, timeout time.Duration, partial *mcp.CallToolResult) *mcp.CallToolResult {
+	var b strings.Builder
+	fmt.Fprintf(&b, "TOOL TIMEOUT: tool '%s'", toolName)
+	if serverName != "" && serverName != "custom" && serverName != "virtual-tools" {
+		fmt.Fprintf(&b, " (server '%s')", serverName)
+	}
+	fmt.Fprintf(&b, " did not finish within its %s timeout.\n\n", timeout)
+
+	if text := partialTimeoutOutput(partial); text != "" {
+		b.WriteString(text)
+		return &mcp.CallToolResult{IsError: true, Content: []mcp.Content{&mcp.TextContent{Text: b.String()}}}
+	}
+	b.WriteString("No partial output was reported before the timeout. " +
+		"The operation may still have had side effects. " +
+		"Retry with a smaller scope (fewer pages, items or a narrower query), use a different tool, " +
+		"or continue without this result.")
+	return &mcp.CallToolResult{IsError: true, Content: []mcp.Content{&mcp.TextContent{Text: b.String()}}}
+}
+
+// partialTimeoutOutput returns the output of a timed-out call worth showing
+// the model; an error that only reports the cancellation is not
+func partialTimeoutOutput(partial *mcp.CallToolResult) string {
+	if partial == nil {
+		return ""
+	}
+	// Without IsError, ToolResultAsString does not add its error prefix
+	text := strings.TrimSpace(mcpclient.ToolResultAsString(&mcp.CallToolResult{Content: partial.Content}))
+	if partial.IsError && strings.Contains(text, context.DeadlineExceeded.Error()) {
+		return ""
+	}
+	return text
+}
@@ -0,0 +1,144 @@
+package mcpagent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/manishiitg/mcpagent/events"
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+	"github.com/manishiitg/mcpagent/mcpclient"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+func TestResolveToolTimeoutPrecedence(t *testing.T) {
+	a := &Agent{
+		ToolTimeout: 5 * time.Minute,
+		serverConfigs: map[string]mcpclient.MCPServerConfig{
+			"crawler": {ToolTimeout: "10m", ToolTimeouts: map[string]string{"fetch": "30s"}},
+		},
+		customTools: map[string]CustomTool{
+			"subagent": {Timeout: 0},
+			"notes":    {Timeout: -1},
+		},
+	}
+	WithPerToolTimeout(map[string]time.Duration{"crawler__screenshot": time.Minute})(a)
+
+	for _, tc := range []struct {
+		tool, server string
+		want         time.Duration
+	}{
+		{"crawler__screenshot", "crawler", time.Minute},
+		{"fetch", "crawler", 30 * time.Second},
+		{"crawler__fetch", "crawler", 30 * time.Second},
+		{"crawl", "crawler", 10 * time.Minute},
+		{"search", "web", 5 * time.Minute},
+		{"subagent", "custom", 0},
+		{"notes", "custom", 5 * time.Minute},
+	} {
+		if got := a.resolveToolTimeout(tc.tool, tc.server); got != tc.want {
+			t.Errorf("resolveToolTimeout(%q, %q) = %s, want %s", tc.tool, tc.server, got, tc.want)
+		}
+	}
+}
+
+func TestToolTimeoutResult(t *testing.T) {
+	result := toolTimeoutResult("crawl", "crawler", time.Minute, nil)
+	text := mcpclient.ToolResultAsString(result)
+	if !result.IsError || !strings.Contains(text, "TOOL TIMEOUT: tool 'crawl' (server 'crawler') did not finish within its 1m0s timeout") ||
+		!strings.Contains(text, "No partial output") {
+		t.Errorf("unexpected timeout result: %q", text)
+	}
+
+	partial := mcpclient.PartialToolResult("crawl", time.Minute, []mcpclient.ToolProgress{{Progress: 3, Total: 10, Message: "crawled 3 pages"}})
+	text = mcpclient.ToolResultAsString(toolTimeoutResult("crawl", "crawler", time.Minute, partial))
+	if !strings.Contains(text, "[3/10] crawled 3 pages") || strings.Contains(text, "No partial output") {
+		t.Errorf("partial output missing from timeout result: %q", text)
+	}
+}
+
+func TestToolTimeoutReturnsStructuredResult(t *testing.T) {
+	blockingTool := CustomTool{
+		Definition: hintTestTool("slow"),
+		Category:   "custom",
+		Timeout:    -1,
+		Execution: func(ctx context.Context, _ map[string]interface{}) (string, error) {
+			<-ctx.Done()
+			return "", ctx.Err()
+		},
+	}
+	for _, parallel := range []bool{false, true} {
+		a := &Agent{Logger: loggerv2.NewNoop(), customTools: map[string]CustomTool{"slow": blockingTool}}
+		a.EnableParallelToolExecution = parallel
+		WithPerToolTimeout(map[string]time.Duration{"slow": 20 * time.Millisecond})(a)
+
+		calls := []llmtypes.ToolCall{
+			{ID: "call_0", FunctionCall: &llmtypes.FunctionCall{Name: "slow", Arguments: `{}`}},
+			{ID: "call_1", FunctionCall: &llmtypes.FunctionCall{Name: "slow", Arguments: `{}`}},
+		}
+		messages, err := DefaultToolDispatcher{}.DispatchTools(context.Background(), a, &ToolDispatch{ToolCalls: calls, StartTime: time.Now()})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(messages) != 2 {
+			t.Fatalf("parallel=%v: got %d tool results, want 2", parallel, len(messages))
+		}
+		resp := messages[0].Parts[0].(llmtypes.ToolCallResponse)
+		if !resp.IsError || !strings.Contains(resp.Content, "TOOL TIMEOUT: tool 'slow' did not finish within its 20ms timeout") ||
+			!strings.Contains(resp.Content, "No partial output") {
+			t.Errorf("parallel=%v: result = %+v, want a structured timeout result", parallel, resp)
+		}
+	}
+}
+
+func TestToolProgressNotificationsEmitEvents(t *testing.T) {
+	mcpServer := server.NewMCPServer("crawler", "1.0.0")
+	mcpServer.AddTool(mcp.NewTool("crawl"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		token := request.Params.Meta.ProgressToken
+		for page := 1; page <= 2; page++ {
+			_ = server.ServerFromContext(ctx).SendNotificationToClient(ctx, "notifications/progress", map[string]any{
+				"progressToken": token, "progress": page, "total": 4, "message": fmt.Sprintf("crawled page %d", page),
+			})
+		}
+		return mcp.NewToolResultText("done"), nil
+	})
+	httpServer := server.NewTestStreamableHTTPServer(mcpServer)
+	t.Cleanup(httpServer.Close)
+	client := mcpclient.New(mcpclient.MCPServerConfig{URL: httpServer.URL + "/mcp", Protocol: mcpclient.ProtocolHTTP}, loggerv2.NewNoop())
+	if err := client.Connect(context.Background()); err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	t.Cleanup(func() { _ = client.Close() })
+
+	listener := &syncEventListener{}
+	a := &Agent{Logger: loggerv2.NewNoop(), SessionID: "progress-session", listeners: []AgentEventListener{listener}}
+	ctx := a.withToolProgressEvents(context.Background(), 3, "crawl", "crawler", "call-1")
+	if _, err := a.callMCPTool(ctx, client, "crawl", nil, loggerv2.NewNoop(), "crawler"); err != nil {
+		t.Fatalf("callMCPTool: %v", err)
+	}
+
+	var progress []*events.ToolCallProgressEvent
+	deadline := time.Now().Add(2 * time.Second)
+	for len(progress) < 2 && time.Now().Before(deadline) {
+		progress = progress[:0]
+		listener.mu.Lock()
+		for _, event := range listener.events {
+			if p, ok := event.Data.(*events.ToolCallProgressEvent); ok {
+				progress = append(progress, p)
+			}
+		}
+		listener.mu.Unlock()
+		time.Sleep(10 * time.Millisecond)
+	}
+	if len(progress) != 2 {
+		t.Fatalf("got %d progress events, want 2", len(progress))
+	}
+	if p := progress[1]; p.Progress != 50 || p.Message != "crawled page 2" || p.ToolCallID != "call-1" || p.Turn != 3 || p.ServerName != "crawler" {
+		t.Errorf("progress event = %+v", p)
+	}
+}
@@ -0,0 +1,68 @@
+package mcpagent
+
+import (
+	"testing"
+
+	"github.com/manishiitg/mcpagent/mcpclient"
+
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+func TestServerToolsSortedDeterministically(t *testing.T) {
+	config := &mcpclient.MCPConfig{MCPServers: map[string]mcpclient.MCPServerConfig{"slack": {}, "fs": {}, "jira": {}}}
+	for i := 0; i < 5; i++ {
+		if got := config.ListServers(); got[0] != "fs" || got[1] != "jira" || got[2] != "slack" {
+			t.Fatalf("servers not sorted: %v", got)
+		}
+	}
+
+	result := &serverConnectionResult{
+		tools:     []llmtypes.Tool{hintTestTool("write_file"), hintTestTool("list_dir"), hintTestTool("read_file")},
+		toolNames: []string{"write_file", "list_dir", "read_file"},
+	}
+	sortServerTools(result)
+	for i, want := range []string{"list_dir", "read_file", "write_file"} {
+		if result.toolNames[i] != want || result.tools[i].Function.Name != want {
+			t.Fatalf("tools not sorted: %v", result.toolNames)
+		}
+	}
+}
+
+func TestToolsetHash(t *testing.T) {
+	a := &Agent{filteredTools: []llmtypes.Tool{hintTestTool("a"), hintTestTool("b")}}
+	hash := a.ToolsetHash()
+	if len(hash) != 16 || hash != a.ToolsetHash() {
+		t.Fatalf("hash should be stable, got %q", hash)
+	}
+
+	reordered := &Agent{filteredTools: []llmtypes.Tool{hintTestTool("b"), hintTestTool("a")}}
+	if reordered.ToolsetHash() == hash {
+		t.Error("tool order changes the prompt and must change the hash")
+	}
+
+	changed := hintTestTool("b")
+	changed.Function.Description = "new description"
+	described := &Agent{filteredTools: []llmtypes.Tool{hintTestTool("a"), changed}}
+	if described.ToolsetHash() == hash {
+		t.Error("description changes must change the hash")
+	}
+}
+
+func TestToolSearchModeToolsSorted(t *testing.T) {
+	a := &Agent{discoveredTools: map[string]llmtypes.Tool{
+		"zeta": hintTestTool("zeta"), "alpha": hintTestTool("alpha"), "mid": hintTestTool("mid"),
+	}}
+	first := hintToolNames(a.getToolsForToolSearchMode())
+	for i := 0; i < 5; i++ {
+		got := hintToolNames(a.getToolsForToolSearchMode())
+		for j := range got {
+			if got[j] != first[j] {
+				t.Fatalf("tool order changed between calls: %v vs %v", first, got)
+			}
+		}
+	}
+	n := len(first)
+	if first[n-3] != "alpha" || first[n-2] != "mid" || first[n-1] != "zeta" {
+		t.Errorf("discovered tools should be sorted by name, got %v", first)
+	}
+}
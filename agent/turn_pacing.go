@@ -0,0 +1,192 @@
+// turn_pacing.go
+//
+// This file provides turn pacing: a minimum delay between the starts of
+// consecutive LLM calls, for provider keys with strict requests-per-minute
+// limits that autonomous loops would otherwise hit immediately.
+//
+// Pacing is adaptive. A throttled call (429, overloaded) widens the interval
+// and holds the next call to the same model until the provider's Retry-After
+// has passed (or the widened interval when the provider gives none); each
+// successful call narrows the interval back towards the configured minimum.
+// While pacing is enabled, a short throttle is retried on the same model by
+// GenerateContentWithRetry instead of failing over to the fallback chain —
+// pacing, not failover, handles short bursts. A Retry-After longer than
+// DefaultMaxThrottleWait is left to the regular retry and fallback logic.
+//
+// The call's priority (CallWithPriority) adjusts the spacing: high-priority
+// calls skip it, low-priority calls wait twice the interval.
+//
+// Exported:
+//   - WithTurnPacing: Enable pacing when creating an agent
+
+package mcpagent
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/manishiitg/multi-llm-provider-go/llmerrors"
+)
+
+const (
+	// DefaultMaxPacingInterval caps the adaptive interval between LLM calls
+	DefaultMaxPacingInterval = 30 * time.Second
+	// DefaultMaxThrottleWait is the longest Retry-After that pacing absorbs by
+	// waiting; longer throttles are handed to the retry and fallback logic
+	DefaultMaxThrottleWait = 60 * time.Second
+)
+
+// WithTurnPacing spaces the starts of consecutive LLM calls at least
+// minInterval apart, and paces adaptively on throttling: a 429 widens the
+// interval and the call is retried on the same model once the provider's
+// Retry-After has passed, instead of failing over to a fallback model.
+//
+// Example:
+//
+//	mcpagent.WithTurnPacing(2 * time.Second) // at most 30 LLM calls per minute
+//
+// Default: disabled (calls start immediately; throttles use retry backoff and fallback)
+func WithTurnPacing(minInterval time.Duration) AgentOption {
+	return func(a *Agent) {
+		if minInterval < 0 {
+			minInterval = 0
+		}
+		a.pacer = &turnPacer{
+			minInterval:    minInterval,
+			interval:       minInterval,
+			throttledUntil: make(map[string]time.Time),
+		}
+	}
+}
+
+// turnPacer schedules LLM calls for one agent
+type turnPacer struct {
+	mu             sync.Mutex
+	minInterval    time.Duration
+	interval       time.Duration        // current interval; grows on throttles, decays on success
+	lastStart      time.Time            // start of the previous call
+	throttledUntil map[string]time.Time // earliest next call per "provider/model"
+}
+
+// wait blocks until a call to modelKey may start, and records the start. A
+// nil pacer never waits. High-priority calls skip the spacing (throttle holds
+// still apply); low-priority calls are spaced lowPriorityPacingFactor times
+// the interval apart.
+func (p *turnPacer) wait(ctx context.Context, modelKey string, priority Priority) (time.Duration, error) {
+	if p == nil {
+		return 0, nil
+	}
+	p.mu.Lock()
+	now := time.Now()
+	start := now
+	interval := p.interval
+	switch priority {
+	case PriorityHigh:
+		interval = 0
+	case PriorityLow:
+		interval *= lowPriorityPacingFactor
+	}
+	if !p.lastStart.IsZero() && interval > 0 {
+		if next := p.lastStart.Add(interval); next.After(start) {
+			start = next
+		}
+	}
+	if until := p.throttledUntil[modelKey]; until.After(start) {
+		start = until
+	}
+	// Reserve the slot before sleeping so concurrent callers queue behind it
+	if start.After(p.lastStart) {
+		p.lastStart = start
+	}
+	delete(p.throttledUntil, modelKey)
+	p.mu.Unlock()
+
+	delay := start.Sub(now)
+	if delay <= 0 {
+		return 0, nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	case <-timer.C:
+		return delay, nil
+	}
+}
+
+// recordSuccess narrows the interval back towards the minimum
+func (p *turnPacer) recordSuccess() {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.interval /= 2
+	if p.interval < p.minInterval {
+		p.interval = p.minInterval
+	}
+}
+
+// absorbThrottle widens the interval after a throttled call to modelKey and
+// holds the next call to it until the provider's Retry-After (or the new
+// interval) has passed. It returns the hold and false when the throttle is
+// too long for pacing to absorb.
+func (p *turnPacer) absorbThrottle(modelKey string, err error) (time.Duration, bool) {
+	if p == nil {
+		return 0, false
+	}
+	retryAfter := retryAfterFromError(err)
+	if retryAfter > DefaultMaxThrottleWait {
+		return retryAfter, false
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.interval <= 0 {
+		p.interval = time.Second
+	} else {
+		p.interval *= 2
+	}
+	if p.interval > DefaultMaxPacingInterval {
+		p.interval = DefaultMaxPacingInterval
+	}
+	hold := retryAfter
+	if hold <= 0 {
+		hold = p.interval
+	}
+	p.throttledUntil[modelKey] = time.Now().Add(hold)
+	return hold, true
+}
+
+// retryAfterPattern matches Retry-After hints in provider error text:
+// "Retry-After: 12", "retry after 1.5s", "retryDelay: 30s", "try again in 20s"
+var retryAfterPattern = regexp.MustCompile(`(?i)(?:retry[- ]?after|retry_?delay|try again in)["':\s]+(\d+(?:\.\d+)?)\s*(ms|s|sec|secs|seconds?)?\b`)
+
+// retryAfterFromError returns the provider-suggested wait carried by err, or 0
+func retryAfterFromError(err error) time.Duration {
+	if err == nil {
+		return 0
+	}
+	var llmErr *llmerrors.Error
+	if errors.As(err, &llmErr) && llmErr.RetryAfter > 0 {
+		return llmErr.RetryAfter
+	}
+	m := retryAfterPattern.FindStringSubmatch(err.Error())
+	if m == nil {
+		return 0
+	}
+	value, parseErr := strconv.ParseFloat(m[1], 64)
+	if parseErr != nil || value <= 0 {
+		return 0
+	}
+	if strings.EqualFold(m[2], "ms") {
+		return time.Duration(value * float64(time.Millisecond))
+	}
+	return time.Duration(value * float64(time.Second))
+}
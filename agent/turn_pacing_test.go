@@ -0,0 +1,103 @@
+package mcpagent
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/manishiitg/mcpagent/events"
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+	"github.com/manishiitg/multi-llm-provider-go/llmerrors"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+func TestTurnPacerSpacesCallsAndAdapts(t *testing.T) {
+	a := &Agent{}
+	WithTurnPacing(20 * time.Millisecond)(a)
+	ctx := context.Background()
+
+	if delay, _ := a.pacer.wait(ctx, "openai/gpt-4.1", PriorityNormal); delay != 0 {
+		t.Errorf("first call should not wait, waited %v", delay)
+	}
+	if delay, _ := a.pacer.wait(ctx, "openai/gpt-4.1", PriorityNormal); delay <= 0 {
+		t.Error("second call should wait for the minimum interval")
+	}
+
+	throttled := &llmerrors.Error{Kind: llmerrors.KindRateLimit, RetryAfter: 50 * time.Millisecond, Err: errors.New("429")}
+	hold, ok := a.pacer.absorbThrottle("openai/gpt-4.1", throttled)
+	if !ok || hold != 50*time.Millisecond || a.pacer.interval != 40*time.Millisecond {
+		t.Fatalf("hold = %v ok = %v interval = %v", hold, ok, a.pacer.interval)
+	}
+	start := time.Now()
+	if _, err := a.pacer.wait(ctx, "openai/gpt-4.1", PriorityNormal); err != nil || time.Since(start) < 40*time.Millisecond {
+		t.Errorf("call after a throttle should honor Retry-After, waited %v", time.Since(start))
+	}
+
+	a.pacer.recordSuccess()
+	a.pacer.recordSuccess()
+	if a.pacer.interval != 20*time.Millisecond {
+		t.Errorf("interval should decay to the minimum, got %v", a.pacer.interval)
+	}
+
+	if _, ok := a.pacer.absorbThrottle("openai/gpt-4.1", errors.New("status code: 429, Retry-After: 600")); ok {
+		t.Error("a throttle longer than DefaultMaxThrottleWait should be left to fallback")
+	}
+}
+
+func TestRetryAfterFromError(t *testing.T) {
+	for msg, want := range map[string]time.Duration{
+		"status code: 429 Retry-After: 12":              12 * time.Second,
+		`{"error": {"retryDelay": "1.5s"}}`:             1500 * time.Millisecond,
+		"rate limit reached, please try again in 250ms": 250 * time.Millisecond,
+		"status code: 429 Too Many Requests":            0,
+	} {
+		if got := retryAfterFromError(errors.New(msg)); got != want {
+			t.Errorf("%q: got %v, want %v", msg, got, want)
+		}
+	}
+}
+
+func TestTurnPacingRetriesThrottlesBeforeFailover(t *testing.T) {
+	t.Setenv("LLM_MAX_RETRIES", "3")
+	newAgent := func(opts ...AgentOption) *Agent {
+		a := &Agent{Logger: loggerv2.NewNoop(), LLMConfig: AgentLLMConfiguration{
+			Primary:   LLMModel{Provider: "openrouter", ModelID: "primary"},
+			Fallbacks: []LLMModel{{Provider: "openrouter", ModelID: "fallback"}},
+		}}
+		WithChaos(ChaosConfig{Seed: 1, ProviderThrottleRate: 1})(a)
+		for _, opt := range opts {
+			opt(a)
+		}
+		return a
+	}
+	messages := []llmtypes.MessageContent{llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "hi")}
+
+	// OpenRouter fails over immediately on a throttle without pacing
+	unpaced := newAgent()
+	if _, _, err := GenerateContentWithRetry(unpaced, context.Background(), messages, nil, 0); err == nil {
+		t.Fatal("expected every call to be throttled")
+	}
+	if got := unpaced.ChaosStats().ProviderThrottles; got != 2 {
+		t.Errorf("unpaced: %d calls, want 2 (one per model)", got)
+	}
+
+	listener := &recordingAgentEventListener{}
+	paced := newAgent(WithTurnPacing(time.Millisecond))
+	paced.AddEventListener(listener)
+	if _, _, err := GenerateContentWithRetry(paced, context.Background(), messages, nil, 0); err == nil {
+		t.Fatal("expected every call to be throttled")
+	}
+	if got := paced.ChaosStats().ProviderThrottles; got != 6 {
+		t.Errorf("paced: %d calls, want 6 (three per model)", got)
+	}
+	throttles := 0
+	for _, event := range listener.events {
+		if _, ok := event.Data.(*events.ThrottlingDetectedEvent); ok {
+			throttles++
+		}
+	}
+	if throttles != 4 {
+		t.Errorf("expected 4 paced retries, got %d", throttles)
+	}
+}
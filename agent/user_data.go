@@ -0,0 +1,119 @@
+// user_data.go
+//
+// This file provides per-user data scoping for multi-user deployments.
+// When an agent is created WithUserID, everything it keeps on behalf of that
+// user is keyed by the user ID: OAuth tokens and offloaded tool outputs live
+// under user-specific paths, stored conversations and sessions get
+// user-prefixed IDs and can only be resumed by the same user, and cached tool
+// results are keyed by user. Agents serving different users from one pool
+// never see each other's data.
+//
+// Exported:
+//   - UserDataStore: A store that can erase one user's data
+//   - DeleteUserData: Remove everything persisted for a user (GDPR erasure)
+//   - UserToolOutputFolder: Tool output folder used for a user
+
+package mcpagent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// userTokenDir is the per-user OAuth token directory, relative to the user's
+// home directory. Must match the path built in NewAgentConnectionWithSession.
+const userTokenDir = ".config/mcpagent/tokens"
+
+// validateUserID rejects user IDs that cannot be used safely as a single
+// path segment.
+func validateUserID(userID string) error {
+	if userID == "" {
+		return errors.New("user ID is required")
+	}
+	if userID == "." || userID == ".." || strings.ContainsAny(userID, `/\`) {
+		return fmt.Errorf("user ID %q must not contain path separators or be a relative path", userID)
+	}
+	return nil
+}
+
+// UserToolOutputFolder returns the folder where offloaded tool outputs for
+// userID are stored. Session folders are created beneath it.
+func UserToolOutputFolder(userID string) string {
+	return filepath.Join(DefaultToolOutputFolder, "users", userID)
+}
+
+// UserDataStore is implemented by stores that keep data per user
+// (conversation stores, tool result caches, memories), so DeleteUserData can
+// erase it
+type UserDataStore interface {
+	// DeleteUserData removes everything the store holds for userID
+	DeleteUserData(ctx context.Context, userID string) error
+}
+
+// DeleteUserData removes all data mcpagent has persisted for userID: the
+// user's OAuth tokens and offloaded tool outputs, and the user's data in
+// stores (conversations, sessions, cached tool results, memory facts).
+// Intended for GDPR erasure requests. Agents still running for the user
+// should be closed first, otherwise they may write new data after the
+// deletion.
+//
+// Example usage:
+//
+//	if err := mcpagent.DeleteUserData(ctx, "user-123", sessionStore, toolCache); err != nil {
+//	    log.Printf("erasure failed: %v", err)
+//	}
+func DeleteUserData(ctx context.Context, userID string, stores ...UserDataStore) error {
+	if err := validateUserID(userID); err != nil {
+		return err
+	}
+
+	var errs []error
+	if home, err := os.UserHomeDir(); err == nil {
+		if err := os.RemoveAll(filepath.Join(home, userTokenDir, userID)); err != nil {
+			errs = append(errs, fmt.Errorf("failed to remove OAuth tokens: %w", err))
+		}
+	} else {
+		errs = append(errs, fmt.Errorf("failed to resolve home directory: %w", err))
+	}
+
+	if err := os.RemoveAll(UserToolOutputFolder(userID)); err != nil {
+		errs = append(errs, fmt.Errorf("failed to remove tool outputs: %w", err))
+	}
+
+	for _, store := range stores {
+		if err := store.DeleteUserData(ctx, userID); err != nil {
+			errs = append(errs, fmt.Errorf("failed to remove data from %T: %w", store, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// userConversationID returns the store ID of the conversation id of userID:
+// prefixed with the user ID, so users sharing a store and a session ID (the
+// default "global" included) never load or overwrite each other's records
+func userConversationID(userID, id string) string {
+	if userID == "" {
+		return id
+	}
+	return userID + "." + id
+}
+
+// deleteUserConversations removes the records of userID from store
+func deleteUserConversations(ctx context.Context, store ConversationStore, userID string) error {
+	checkpoints, err := store.List(ctx)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, checkpoint := range checkpoints {
+		if checkpoint.UserID == userID {
+			errs = append(errs, store.Delete(ctx, checkpoint.ID))
+		}
+	}
+	return errors.Join(errs...)
+}
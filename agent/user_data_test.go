@@ -0,0 +1,129 @@
+package mcpagent
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestValidateUserIDRejectsPathSegments(t *testing.T) {
+	for _, id := range []string{"", ".", "..", "a/b", `a\b`, "../etc"} {
+		if err := validateUserID(id); err == nil {
+			t.Errorf("validateUserID(%q) = nil, want error", id)
+		}
+	}
+	if err := validateUserID("user-123@example.com"); err != nil {
+		t.Errorf("validateUserID(email) = %v, want nil", err)
+	}
+}
+
+func TestDeleteUserDataRemovesOnlyThatUser(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Chdir(t.TempDir())
+
+	write := func(path string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("x"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	aliceToken := filepath.Join(home, userTokenDir, "alice", "github.json")
+	bobToken := filepath.Join(home, userTokenDir, "bob", "github.json")
+	aliceOutput := filepath.Join(UserToolOutputFolder("alice"), "session", "tool_1.txt")
+	bobOutput := filepath.Join(UserToolOutputFolder("bob"), "session", "tool_1.txt")
+	for _, p := range []string{aliceToken, bobToken, aliceOutput, bobOutput} {
+		write(p)
+	}
+
+	ctx := context.Background()
+	store := NewMemoryConversationStore()
+	cache := NewToolResultCache(ToolResultCacheConfig{})
+	for _, user := range []string{"alice", "bob"} {
+		if err := store.Save(ctx, &ConversationCheckpoint{ID: userConversationID(user, "global"), UserID: user}); err != nil {
+			t.Fatal(err)
+		}
+		cache.Put(user, "docs", "search", nil, mcp.NewToolResultText("result"))
+	}
+
+	if err := DeleteUserData(ctx, "alice", store, cache); err != nil {
+		t.Fatalf("DeleteUserData: %v", err)
+	}
+
+	if _, err := store.Load(ctx, userConversationID("alice", "global")); !errors.Is(err, ErrCheckpointNotFound) {
+		t.Errorf("alice's conversation was kept: %v", err)
+	}
+	if _, err := store.Load(ctx, userConversationID("bob", "global")); err != nil {
+		t.Errorf("bob's conversation was removed: %v", err)
+	}
+	if _, ok := cache.Get("alice", "docs", "search", nil); ok {
+		t.Error("alice's cached tool result was kept")
+	}
+	if _, ok := cache.Get("bob", "docs", "search", nil); !ok {
+		t.Error("bob's cached tool result was removed")
+	}
+
+	for _, p := range []string{aliceToken, aliceOutput} {
+		if _, err := os.Stat(p); !os.IsNotExist(err) {
+			t.Errorf("%s still exists after deletion", p)
+		}
+	}
+	for _, p := range []string{bobToken, bobOutput} {
+		if _, err := os.Stat(p); err != nil {
+			t.Errorf("%s was removed for another user: %v", p, err)
+		}
+	}
+}
+
+func TestSessionsOfUsersSharingASessionIDStayApart(t *testing.T) {
+	store := NewMemoryConversationStore()
+	newAgent := func(userID, answer string) *Agent {
+		a := &Agent{Logger: loggerv2.NewNoop(), LLM: &providerKeyCarrierModel{}, ModelID: "test-model", MaxTurns: 5, SessionID: "global", UserID: userID}
+		WithSessionStore(store)(a)
+		WithAskPipeline(AskPipeline{Generate: &scriptedGenerateStage{responses: []*llmtypes.ContentResponse{
+			{Choices: []*llmtypes.ContentChoice{{Content: answer}}},
+		}}})(a)
+		return a
+	}
+	for user, question := range map[string]string{"alice": "alice's secret", "bob": "bob's question"} {
+		if _, _, err := newAgent(user, "noted").AskWithHistory(context.Background(), []llmtypes.MessageContent{
+			llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, question),
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	bob := newAgent("bob", "")
+	history, err := bob.ResumeSession(context.Background(), "global")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := lastUserText(history); got != "bob's question" {
+		t.Errorf("bob resumed %q", got)
+	}
+	if bob.ConversationSessionID() != "global" {
+		t.Errorf("session ID = %q, want the unprefixed global", bob.ConversationSessionID())
+	}
+
+	alice, err := store.Load(context.Background(), userConversationID("alice", "global"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := bob.ResumeConversation(context.Background(), alice); err == nil {
+		t.Error("bob resumed alice's conversation")
+	}
+	anonymous := newAgent("", "")
+	if _, err := anonymous.ResumeSession(context.Background(), "alice.global"); !errors.Is(err, ErrCheckpointNotFound) {
+		t.Errorf("agent without a user resumed alice's session: %v", err)
+	}
+}
@@ -0,0 +1,965 @@
+// utility_tools.go
+//
+// Optional in-process utility tools, so simple setups don't depend on the
+// model's arithmetic or on an MCP server for basic computations:
+//
+//   - calculate: arithmetic on exact rationals (0.1 + 0.2 = 0.3), with
+//     powers, parentheses and common math functions
+//   - date_math: current time, date arithmetic (calendar-aware months and
+//     years), differences between dates and date info
+//   - convert_units: length, mass, volume, area, speed, time, data size and
+//     temperature conversions with exact factors
+//   - random_value: integers, floats and UUIDs from a cryptographic source
+//   - base64: encode and decode (standard or URL-safe alphabet)
+//
+// The tools are registered as custom tools in the "utility_tools" category.
+//
+// Exported:
+//   - WithBuiltinUtilityTools: Enable the tools pack
+//   - BuiltinUtilityToolNames: Names of the tools in the pack
+
+package mcpagent
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+)
+
+// utilityToolsCategory is the custom tool category of the utility tools
+const utilityToolsCategory = "utility_tools"
+
+// BuiltinUtilityToolNames are the tools registered by WithBuiltinUtilityTools
+var BuiltinUtilityToolNames = []string{"calculate", "date_math", "convert_units", "random_value", "base64"}
+
+// WithBuiltinUtilityTools registers the in-process utility tools (calculate,
+// date_math, convert_units, random_value, base64) when the agent is created.
+//
+// Default: false
+func WithBuiltinUtilityTools(enabled bool) AgentOption {
+	return func(a *Agent) {
+		a.EnableBuiltinUtilityTools = enabled
+	}
+}
+
+// registerBuiltinUtilityTools registers the utility tools as custom tools
+func (a *Agent) registerBuiltinUtilityTools() error {
+	str := func(description string) map[string]interface{} {
+		return map[string]interface{}{"type": "string", "description": description}
+	}
+	num := func(description string) map[string]interface{} {
+		return map[string]interface{}{"type": "number", "description": description}
+	}
+	enum := func(description string, values ...string) map[string]interface{} {
+		return map[string]interface{}{"type": "string", "enum": values, "description": description}
+	}
+	object := func(properties map[string]interface{}, required ...string) map[string]interface{} {
+		params := map[string]interface{}{"type": "object", "properties": properties}
+		if len(required) > 0 {
+			params["required"] = required
+		}
+		return params
+	}
+
+	tools := []struct {
+		name        string
+		description string
+		params      map[string]interface{}
+		exec        func(ctx context.Context, args map[string]interface{}) (string, error)
+	}{
+		{
+			name: "calculate",
+			description: "Evaluate an arithmetic expression exactly. Use this instead of doing math yourself. " +
+				"Supports + - * / % ^ (or **), parentheses, the constants pi and e, and the functions " +
+				"sqrt, abs, floor, ceil, round(x[, digits]), min, max, ln, log10, log2, exp, sin, cos, tan (radians).",
+			params: object(map[string]interface{}{
+				"expression": str("Expression to evaluate, e.g. \"(1250 * 0.075) / 12\""),
+			}, "expression"),
+			exec: func(_ context.Context, args map[string]interface{}) (string, error) {
+				expression, _ := args["expression"].(string)
+				result, err := evaluateExpression(expression)
+				if err != nil {
+					return "", err
+				}
+				return formatRat(result), nil
+			},
+		},
+		{
+			name: "date_math",
+			description: "Date and time calculations. operation \"now\" returns the current time; \"add\" adds years, months, days, " +
+				"hours and minutes (negative to subtract) to date; \"diff\" returns the time between date and end_date; " +
+				"\"info\" returns the weekday, ISO week and day of year of date. Dates are RFC 3339 or YYYY-MM-DD[ HH:MM[:SS]].",
+			params: object(map[string]interface{}{
+				"operation": enum("Operation to perform", "now", "add", "diff", "info"),
+				"date":      str("Date to start from (default: now)"),
+				"end_date":  str("End date for diff"),
+				"years":     num("Years to add"),
+				"months":    num("Months to add"),
+				"days":      num("Days to add"),
+				"hours":     num("Hours to add"),
+				"minutes":   num("Minutes to add"),
+				"timezone":  str("IANA time zone for dates without an offset and for now, e.g. \"Europe/Berlin\" (default: UTC)"),
+			}, "operation"),
+			exec: func(_ context.Context, args map[string]interface{}) (string, error) {
+				return dateMath(args, time.Now())
+			},
+		},
+		{
+			name: "convert_units",
+			description: "Convert a value between units of length (mm, cm, m, km, in, ft, yd, mi, nmi), mass (mg, g, kg, t, oz, lb, st), " +
+				"volume (ml, l, m3, tsp, tbsp, floz, cup, pt, qt, gal; US customary), area (cm2, m2, km2, ha, acre, in2, ft2, mi2), " +
+				"speed (m/s, km/h, mph, knot, ft/s), time (ms, s, min, h, day, week), data (bit, B, KB, MB, GB, TB, KiB, MiB, GiB, TiB) " +
+				"and temperature (C, F, K).",
+			params: object(map[string]interface{}{
+				"value": num("Value to convert"),
+				"from":  str("Unit of value"),
+				"to":    str("Unit to convert to"),
+			}, "value", "from", "to"),
+			exec: func(_ context.Context, args map[string]interface{}) (string, error) {
+				value, err := ratArgument(args["value"])
+				if err != nil {
+					return "", err
+				}
+				from, _ := args["from"].(string)
+				to, _ := args["to"].(string)
+				result, err := convertUnits(value, from, to)
+				if err != nil {
+					return "", err
+				}
+				return fmt.Sprintf("%s %s = %s %s", formatRat(value), from, formatRat(result), to), nil
+			},
+		},
+		{
+			name:        "random_value",
+			description: "Generate random values from a cryptographic source: integers in [min, max] (default 1-100), floats in [min, max) (default 0-1) or version 4 UUIDs.",
+			params: object(map[string]interface{}{
+				"kind":  enum("Kind of value", "integer", "float", "uuid"),
+				"min":   num("Lower bound"),
+				"max":   num("Upper bound"),
+				"count": num("Number of values (default 1, at most 100)"),
+			}, "kind"),
+			exec: func(_ context.Context, args map[string]interface{}) (string, error) {
+				return randomValues(args)
+			},
+		},
+		{
+			name:        "base64",
+			description: "Encode text to base64 or decode base64 to text.",
+			params: object(map[string]interface{}{
+				"operation": enum("Operation to perform", "encode", "decode"),
+				"text":      str("Text to encode, or base64 to decode"),
+				"url_safe":  map[string]interface{}{"type": "boolean", "description": "Use the URL-safe alphabet"},
+			}, "operation", "text"),
+			exec: func(_ context.Context, args map[string]interface{}) (string, error) {
+				operation, _ := args["operation"].(string)
+				text, _ := args["text"].(string)
+				urlSafe, _ := args["url_safe"].(bool)
+				return base64Codec(operation, text, urlSafe)
+			},
+		},
+	}
+
+	for _, t := range tools {
+		if err := a.RegisterCustomTool(t.name, t.description, t.params, t.exec, utilityToolsCategory); err != nil {
+			return fmt.Errorf("failed to register %s: %w", t.name, err)
+		}
+	}
+	return nil
+}
+
+// ---- calculate ----
+
+// maxExactExponent bounds integer powers computed exactly
+const maxExactExponent = 4096
+
+// maxExactPowerBits bounds the numerator and denominator size, in bits, of
+// an exact power, so nested powers like (9^4096)^4096 are refused before
+// they are computed
+const maxExactPowerBits = 1 << 16
+
+// exprParser is a recursive-descent parser evaluating an expression on rationals:
+//
+//	expr    = term { ("+" | "-") term }
+//	term    = unary { ("*" | "/" | "%") unary }
+//	unary   = ("+" | "-") unary | power
+//	power   = primary [ ("^" | "**") unary ]
+//	primary = number | constant | function "(" expr { "," expr } ")" | "(" expr ")"
+type exprParser struct {
+	input string
+	pos   int
+}
+
+// evaluateExpression evaluates an arithmetic expression exactly where possible
+func evaluateExpression(expression string) (*big.Rat, error) {
+	if strings.TrimSpace(expression) == "" {
+		return nil, fmt.Errorf("expression is required")
+	}
+	p := &exprParser{input: expression}
+	result, err := p.expr()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if p.pos < len(p.input) {
+		return nil, fmt.Errorf("unexpected %q at position %d", p.input[p.pos:], p.pos+1)
+	}
+	return result, nil
+}
+
+func (p *exprParser) skipSpace() {
+	for p.pos < len(p.input) && unicode.IsSpace(rune(p.input[p.pos])) {
+		p.pos++
+	}
+}
+
+// accept consumes token if it comes next
+func (p *exprParser) accept(token string) bool {
+	p.skipSpace()
+	if strings.HasPrefix(p.input[p.pos:], token) {
+		p.pos += len(token)
+		return true
+	}
+	return false
+}
+
+func (p *exprParser) expr() (*big.Rat, error) {
+	left, err := p.term()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		switch {
+		case p.accept("+"):
+			right, err := p.term()
+			if err != nil {
+				return nil, err
+			}
+			left = new(big.Rat).Add(left, right)
+		case p.accept("-"):
+			right, err := p.term()
+			if err != nil {
+				return nil, err
+			}
+			left = new(big.Rat).Sub(left, right)
+		default:
+			return left, nil
+		}
+	}
+}
+
+func (p *exprParser) term() (*big.Rat, error) {
+	left, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		var op string
+		switch {
+		case p.accept("*"):
+			op = "*"
+		case p.accept("/"):
+			op = "/"
+		case p.accept("%"):
+			op = "%"
+		default:
+			return left, nil
+		}
+		right, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		switch op {
+		case "*":
+			left = new(big.Rat).Mul(left, right)
+		case "/":
+			if right.Sign() == 0 {
+				return nil, fmt.Errorf("division by zero")
+			}
+			left = new(big.Rat).Quo(left, right)
+		case "%":
+			if right.Sign() == 0 {
+				return nil, fmt.Errorf("modulo by zero")
+			}
+			// left - right * floor(left / right)
+			quotient := floorRat(new(big.Rat).Quo(left, right))
+			left = new(big.Rat).Sub(left, new(big.Rat).Mul(right, quotient))
+		}
+	}
+}
+
+func (p *exprParser) unary() (*big.Rat, error) {
+	if p.accept("-") {
+		value, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return new(big.Rat).Neg(value), nil
+	}
+	if p.accept("+") {
+		return p.unary()
+	}
+	return p.power()
+}
+
+func (p *exprParser) power() (*big.Rat, error) {
+	base, err := p.primary()
+	if err != nil {
+		return nil, err
+	}
+	if p.accept("**") || p.accept("^") {
+		exponent, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return powRat(base, exponent)
+	}
+	return base, nil
+}
+
+func (p *exprParser) primary() (*big.Rat, error) {
+	p.skipSpace()
+	if p.pos >= len(p.input) {
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+	c := p.input[p.pos]
+	switch {
+	case c == '(':
+		p.pos++
+		value, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.accept(")") {
+			return nil, fmt.Errorf("missing closing parenthesis")
+		}
+		return value, nil
+	case c >= '0' && c <= '9' || c == '.':
+		return p.number()
+	case unicode.IsLetter(rune(c)):
+		start := p.pos
+		for p.pos < len(p.input) && (unicode.IsLetter(rune(p.input[p.pos])) || unicode.IsDigit(rune(p.input[p.pos]))) {
+			p.pos++
+		}
+		name := strings.ToLower(p.input[start:p.pos])
+		if !p.accept("(") {
+			switch name {
+			case "pi":
+				return floatRat(math.Pi)
+			case "e":
+				return floatRat(math.E)
+			}
+			return nil, fmt.Errorf("unknown constant %q", name)
+		}
+		var args []*big.Rat
+		if !p.accept(")") {
+			for {
+				arg, err := p.expr()
+				if err != nil {
+					return nil, err
+				}
+				args = append(args, arg)
+				if p.accept(")") {
+					break
+				}
+				if !p.accept(",") {
+					return nil, fmt.Errorf("expected , or ) in arguments of %s", name)
+				}
+			}
+		}
+		return callFunction(name, args)
+	}
+	return nil, fmt.Errorf("unexpected %q at position %d", string(c), p.pos+1)
+}
+
+// number parses a decimal number with an optional exponent, e.g. 1.5e-3
+func (p *exprParser) number() (*big.Rat, error) {
+	start := p.pos
+	for p.pos < len(p.input) && (p.input[p.pos] >= '0' && p.input[p.pos] <= '9' || p.input[p.pos] == '.' || p.input[p.pos] == '_') {
+		p.pos++
+	}
+	if p.pos < len(p.input) && (p.input[p.pos] == 'e' || p.input[p.pos] == 'E') {
+		end := p.pos + 1
+		if end < len(p.input) && (p.input[end] == '+' || p.input[end] == '-') {
+			end++
+		}
+		if end < len(p.input) && p.input[end] >= '0' && p.input[end] <= '9' {
+			for end < len(p.input) && p.input[end] >= '0' && p.input[end] <= '9' {
+				end++
+			}
+			p.pos = end
+		}
+	}
+	literal := strings.ReplaceAll(p.input[start:p.pos], "_", "")
+	value, ok := new(big.Rat).SetString(literal)
+	if !ok {
+		return nil, fmt.Errorf("invalid number %q", literal)
+	}
+	return value, nil
+}
+
+// callFunction applies a calculator function to its arguments
+func callFunction(name string, args []*big.Rat) (*big.Rat, error) {
+	arity := func(n ...int) error {
+		for _, want := range n {
+			if len(args) == want {
+				return nil
+			}
+		}
+		return fmt.Errorf("%s takes %v argument(s), got %d", name, n, len(args))
+	}
+	float := func(fn func(float64) float64) (*big.Rat, error) {
+		if err := arity(1); err != nil {
+			return nil, err
+		}
+		x, _ := args[0].Float64()
+		return floatRat(fn(x))
+	}
+
+	switch name {
+	case "abs":
+		if err := arity(1); err != nil {
+			return nil, err
+		}
+		return new(big.Rat).Abs(args[0]), nil
+	case "floor":
+		if err := arity(1); err != nil {
+			return nil, err
+		}
+		return floorRat(args[0]), nil
+	case "ceil":
+		if err := arity(1); err != nil {
+			return nil, err
+		}
+		return new(big.Rat).Neg(floorRat(new(big.Rat).Neg(args[0]))), nil
+	case "round":
+		if err := arity(1, 2); err != nil {
+			return nil, err
+		}
+		digits := 0
+		if len(args) == 2 {
+			if !args[1].IsInt() || args[1].Num().BitLen() > 16 {
+				return nil, fmt.Errorf("round digits must be a small integer")
+			}
+			digits = int(args[1].Num().Int64())
+		}
+		return roundRat(args[0], digits), nil
+	case "min", "max":
+		if len(args) == 0 {
+			return nil, fmt.Errorf("%s needs at least one argument", name)
+		}
+		best := args[0]
+		for _, arg := range args[1:] {
+			if (name == "min") == (arg.Cmp(best) < 0) {
+				best = arg
+			}
+		}
+		return best, nil
+	case "sqrt":
+		if err := arity(1); err != nil {
+			return nil, err
+		}
+		if args[0].Sign() < 0 {
+			return nil, fmt.Errorf("sqrt of a negative number")
+		}
+		root := new(big.Float).SetPrec(256).SetRat(args[0])
+		root.Sqrt(root)
+		result, _ := root.Rat(nil)
+		return result, nil
+	case "ln":
+		return float(math.Log)
+	case "log10", "log":
+		return float(math.Log10)
+	case "log2":
+		return float(math.Log2)
+	case "exp":
+		return float(math.Exp)
+	case "sin":
+		return float(math.Sin)
+	case "cos":
+		return float(math.Cos)
+	case "tan":
+		return float(math.Tan)
+	}
+	return nil, fmt.Errorf("unknown function %q", name)
+}
+
+// powRat raises base to exponent, exactly for integer exponents
+func powRat(base, exponent *big.Rat) (*big.Rat, error) {
+	if exponent.IsInt() && exponent.Num().IsInt64() && absInt64(exponent.Num().Int64()) <= maxExactExponent {
+		n := exponent.Num().Int64()
+		if n < 0 && base.Sign() == 0 {
+			return nil, fmt.Errorf("division by zero")
+		}
+		// |num^n| has at most n times the bits of |num|; likewise den
+		if int64(max(base.Num().BitLen(), base.Denom().BitLen()))*absInt64(n) > maxExactPowerBits {
+			return nil, fmt.Errorf("result is too large")
+		}
+		e := big.NewInt(absInt64(n))
+		num := new(big.Int).Exp(base.Num(), e, nil)
+		den := new(big.Int).Exp(base.Denom(), e, nil)
+		if n < 0 {
+			num, den = den, num
+		}
+		return new(big.Rat).SetFrac(num, den), nil
+	}
+	b, _ := base.Float64()
+	e, _ := exponent.Float64()
+	return floatRat(math.Pow(b, e))
+}
+
+func absInt64(n int64) int64 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// floatRat converts the result of a floating-point function, rejecting NaN and infinities
+func floatRat(f float64) (*big.Rat, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return nil, fmt.Errorf("result is not a finite number")
+	}
+	// Round-trip through the shortest decimal so 0.1 stays 0.1
+	result, _ := new(big.Rat).SetString(strconv.FormatFloat(f, 'g', -1, 64))
+	return result, nil
+}
+
+// floorRat returns the largest integer <= r
+func floorRat(r *big.Rat) *big.Rat {
+	quotient := new(big.Int)
+	remainder := new(big.Int)
+	quotient.DivMod(r.Num(), r.Denom(), remainder) // Euclidean: remainder >= 0 for positive denominators
+	return new(big.Rat).SetInt(quotient)
+}
+
+// roundRat rounds r half away from zero to digits decimal places
+func roundRat(r *big.Rat, digits int) *big.Rat {
+	scale := new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(absInt(digits))), nil))
+	scaled := new(big.Rat).Set(r)
+	if digits >= 0 {
+		scaled.Mul(scaled, scale)
+	} else {
+		scaled.Quo(scaled, scale)
+	}
+	half := big.NewRat(1, 2)
+	if scaled.Sign() >= 0 {
+		scaled = floorRat(scaled.Add(scaled, half))
+	} else {
+		scaled = new(big.Rat).Neg(floorRat(new(big.Rat).Add(new(big.Rat).Neg(scaled), half)))
+	}
+	if digits >= 0 {
+		return scaled.Quo(scaled, scale)
+	}
+	return scaled.Mul(scaled, scale)
+}
+
+func absInt(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// formatRat formats r as an integer or a decimal with up to 15 fractional
+// digits, falling back to scientific notation for very small values
+func formatRat(r *big.Rat) string {
+	if r.IsInt() {
+		return r.Num().String()
+	}
+	s := r.FloatString(15)
+	s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	if s == "0" || s == "-0" {
+		f, _ := r.Float64()
+		return strconv.FormatFloat(f, 'g', 15, 64)
+	}
+	return s
+}
+
+// ratArgument reads a numeric tool argument given as a number or a string
+func ratArgument(value interface{}) (*big.Rat, error) {
+	var literal string
+	switch v := value.(type) {
+	case float64:
+		literal = strconv.FormatFloat(v, 'f', -1, 64)
+	case json.Number:
+		literal = v.String()
+	case string:
+		literal = strings.ReplaceAll(strings.TrimSpace(v), ",", "")
+	default:
+		return nil, fmt.Errorf("value must be a number")
+	}
+	result, ok := new(big.Rat).SetString(literal)
+	if !ok {
+		return nil, fmt.Errorf("invalid number %q", literal)
+	}
+	return result, nil
+}
+
+// maxIntegerArgument bounds integer tool arguments: float64 holds every
+// integer up to 2^53
+const maxIntegerArgument = 1 << 53
+
+// intArgument converts a numeric tool argument to an integer. NaN, ±Inf and
+// values outside [lo, hi] are rejected while still a float64, since
+// converting them to an integer overflows.
+func intArgument(name string, v, lo, hi float64) (int64, error) {
+	if !isFinite(v) || v < lo || v > hi {
+		return 0, fmt.Errorf("%s must be a number from %s to %s", name,
+			strconv.FormatFloat(lo, 'f', -1, 64), strconv.FormatFloat(hi, 'f', -1, 64))
+	}
+	return int64(v), nil
+}
+
+func isFinite(v float64) bool {
+	return !math.IsNaN(v) && !math.IsInf(v, 0)
+}
+
+// ---- convert_units ----
+
+// unitDefinition converts a unit to the base unit of its dimension
+type unitDefinition struct {
+	dimension string
+	factor    string // exact decimal or fraction; base = value * factor
+}
+
+var unitDefinitions = map[string]unitDefinition{
+	// length (meter)
+	"mm": {"length", "0.001"}, "cm": {"length", "0.01"}, "m": {"length", "1"}, "km": {"length", "1000"},
+	"in": {"length", "0.0254"}, "ft": {"length", "0.3048"}, "yd": {"length", "0.9144"},
+	"mi": {"length", "1609.344"}, "nmi": {"length", "1852"},
+	// mass (kilogram)
+	"mg": {"mass", "0.000001"}, "g": {"mass", "0.001"}, "kg": {"mass", "1"}, "t": {"mass", "1000"},
+	"oz": {"mass", "0.028349523125"}, "lb": {"mass", "0.45359237"}, "st": {"mass", "6.35029318"},
+	// volume (liter, US customary units)
+	"ml": {"volume", "0.001"}, "l": {"volume", "1"}, "m3": {"volume", "1000"},
+	"tsp": {"volume", "0.00492892159375"}, "tbsp": {"volume", "0.01478676478125"},
+	"floz": {"volume", "0.0295735295625"}, "cup": {"volume", "0.2365882365"},
+	"pt": {"volume", "0.473176473"}, "qt": {"volume", "0.946352946"}, "gal": {"volume", "3.785411784"},
+	// area (square meter)
+	"cm2": {"area", "0.0001"}, "m2": {"area", "1"}, "km2": {"area", "1000000"}, "ha": {"area", "10000"},
+	"acre": {"area", "4046.8564224"}, "in2": {"area", "0.00064516"}, "ft2": {"area", "0.09290304"},
+	"mi2": {"area", "2589988.110336"},
+	// speed (meter per second)
+	"m/s": {"speed", "1"}, "km/h": {"speed", "5/18"}, "mph": {"speed", "0.44704"},
+	"knot": {"speed", "463/900"}, "ft/s": {"speed", "0.3048"},
+	// time (second)
+	"ms": {"time", "0.001"}, "s": {"time", "1"}, "min": {"time", "60"}, "h": {"time", "3600"},
+	"day": {"time", "86400"}, "week": {"time", "604800"},
+	// data (byte)
+	"bit": {"data", "1/8"}, "b": {"data", "1"}, "kb": {"data", "1000"}, "mb": {"data", "1000000"},
+	"gb": {"data", "1000000000"}, "tb": {"data", "1000000000000"}, "kib": {"data", "1024"},
+	"mib": {"data", "1048576"}, "gib": {"data", "1073741824"}, "tib": {"data", "1099511627776"},
+}
+
+// unitAliases maps common spellings to the keys of unitDefinitions
+var unitAliases = map[string]string{
+	"millimeter": "mm", "centimeter": "cm", "meter": "m", "metre": "m", "kilometer": "km", "kilometre": "km",
+	"inch": "in", "inches": "in", "foot": "ft", "feet": "ft", "yard": "yd", "mile": "mi", "nautical mile": "nmi",
+	"milligram": "mg", "gram": "g", "kilogram": "kg", "tonne": "t", "ton": "t", "ounce": "oz", "pound": "lb", "lbs": "lb", "stone": "st",
+	"milliliter": "ml", "millilitre": "ml", "liter": "l", "litre": "l", "teaspoon": "tsp", "tablespoon": "tbsp",
+	"fl oz": "floz", "fluid ounce": "floz", "pint": "pt", "quart": "qt", "gallon": "gal",
+	"hectare": "ha", "sq m": "m2", "sq km": "km2", "sq ft": "ft2", "sq in": "in2", "sq mi": "mi2",
+	"mps": "m/s", "kph": "km/h", "kmh": "km/h", "kmph": "km/h", "kn": "knot", "kt": "knot", "fps": "ft/s",
+	"millisecond": "ms", "second": "s", "sec": "s", "minute": "min", "hour": "h", "hr": "h", "d": "day",
+	"byte": "b", "kilobyte": "kb", "megabyte": "mb", "gigabyte": "gb", "terabyte": "tb",
+	"kibibyte": "kib", "mebibyte": "mib", "gibibyte": "gib", "tebibyte": "tib",
+	"°c": "c", "celsius": "c", "°f": "f", "fahrenheit": "f", "kelvin": "k",
+}
+
+// normalizeUnit returns the unitDefinitions key (or c, f, k) for a unit name
+func normalizeUnit(unit string) string {
+	name := strings.ToLower(strings.TrimSpace(unit))
+	for _, candidate := range []string{name, strings.TrimSuffix(name, "s")} {
+		if _, ok := unitDefinitions[candidate]; ok {
+			return candidate
+		}
+		if alias, ok := unitAliases[candidate]; ok {
+			return alias
+		}
+		if candidate == "c" || candidate == "f" || candidate == "k" {
+			return candidate
+		}
+	}
+	return ""
+}
+
+// convertUnits converts value between two units of the same dimension
+func convertUnits(value *big.Rat, from, to string) (*big.Rat, error) {
+	fromKey, toKey := normalizeUnit(from), normalizeUnit(to)
+	if fromKey == "" {
+		return nil, fmt.Errorf("unknown unit %q", from)
+	}
+	if toKey == "" {
+		return nil, fmt.Errorf("unknown unit %q", to)
+	}
+	if isTemperatureUnit(fromKey) || isTemperatureUnit(toKey) {
+		if !isTemperatureUnit(fromKey) || !isTemperatureUnit(toKey) {
+			return nil, fmt.Errorf("cannot convert %s to %s", from, to)
+		}
+		return convertTemperature(value, fromKey, toKey), nil
+	}
+	fromUnit, toUnit := unitDefinitions[fromKey], unitDefinitions[toKey]
+	if fromUnit.dimension != toUnit.dimension {
+		return nil, fmt.Errorf("cannot convert %s (%s) to %s (%s)", from, fromUnit.dimension, to, toUnit.dimension)
+	}
+	fromFactor, _ := new(big.Rat).SetString(fromUnit.factor)
+	toFactor, _ := new(big.Rat).SetString(toUnit.factor)
+	result := new(big.Rat).Mul(value, fromFactor)
+	return result.Quo(result, toFactor), nil
+}
+
+func isTemperatureUnit(key string) bool {
+	return key == "c" || key == "f" || key == "k"
+}
+
+// convertTemperature converts between Celsius, Fahrenheit and Kelvin through Kelvin
+func convertTemperature(value *big.Rat, from, to string) *big.Rat {
+	offset, _ := new(big.Rat).SetString("273.15")
+	kelvin := new(big.Rat).Set(value)
+	switch from {
+	case "c":
+		kelvin.Add(kelvin, offset)
+	case "f":
+		kelvin.Sub(kelvin, big.NewRat(32, 1)).Mul(kelvin, big.NewRat(5, 9)).Add(kelvin, offset)
+	}
+	switch to {
+	case "c":
+		return kelvin.Sub(kelvin, offset)
+	case "f":
+		return kelvin.Sub(kelvin, offset).Mul(kelvin, big.NewRat(9, 5)).Add(kelvin, big.NewRat(32, 1))
+	}
+	return kelvin
+}
+
+// ---- date_math ----
+
+// dateLayouts are the accepted date formats besides RFC 3339
+var dateLayouts = []string{"2006-01-02T15:04:05", "2006-01-02 15:04:05", "2006-01-02T15:04", "2006-01-02 15:04", "2006-01-02"}
+
+// parseDate parses an RFC 3339 date, or a date without offset in loc
+func parseDate(value string, loc *time.Location) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	for _, layout := range dateLayouts {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q: use RFC 3339 or YYYY-MM-DD[ HH:MM[:SS]]", value)
+}
+
+// maxDateOffset bounds each offset of a date_math add, keeping the hours and
+// minutes within time.Duration
+const maxDateOffset = 1_000_000
+
+// dateMath implements the date_math tool; now is the current time
+func dateMath(args map[string]interface{}, now time.Time) (string, error) {
+	loc := time.UTC
+	if name, _ := args["timezone"].(string); strings.TrimSpace(name) != "" {
+		var err error
+		if loc, err = time.LoadLocation(strings.TrimSpace(name)); err != nil {
+			return "", fmt.Errorf("unknown timezone %q", name)
+		}
+	}
+	date := now.In(loc)
+	if value, _ := args["date"].(string); strings.TrimSpace(value) != "" {
+		var err error
+		if date, err = parseDate(value, loc); err != nil {
+			return "", err
+		}
+	}
+	operation, _ := args["operation"].(string)
+	var result map[string]interface{}
+	switch operation {
+	case "now", "info":
+		result = dateInfo(date)
+	case "add":
+		var offsets [5]int
+		for i, name := range []string{"years", "months", "days", "hours", "minutes"} {
+			v, _ := args[name].(float64)
+			n, err := intArgument(name, v, -maxDateOffset, maxDateOffset)
+			if err != nil {
+				return "", err
+			}
+			offsets[i] = int(n)
+		}
+		date = date.AddDate(offsets[0], offsets[1], offsets[2]).
+			Add(time.Duration(offsets[3])*time.Hour + time.Duration(offsets[4])*time.Minute)
+		result = dateInfo(date)
+	case "diff":
+		value, _ := args["end_date"].(string)
+		if strings.TrimSpace(value) == "" {
+			return "", fmt.Errorf("end_date is required for diff")
+		}
+		end, err := parseDate(value, loc)
+		if err != nil {
+			return "", err
+		}
+		result = dateDiff(date, end)
+	default:
+		return "", fmt.Errorf("unknown operation %q: use now, add, diff or info", operation)
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func dateInfo(t time.Time) map[string]interface{} {
+	year, week := t.ISOWeek()
+	return map[string]interface{}{
+		"date":        t.Format(time.RFC3339),
+		"weekday":     t.Weekday().String(),
+		"iso_week":    fmt.Sprintf("%d-W%02d", year, week),
+		"day_of_year": t.YearDay(),
+		"unix":        t.Unix(),
+	}
+}
+
+// dateDiff returns the time from start to end in total days and hours and
+// as a calendar breakdown (years, months, days)
+func dateDiff(start, end time.Time) map[string]interface{} {
+	duration := end.Sub(start)
+	sign := 1
+	if end.Before(start) {
+		start, end = end, start
+		sign = -1
+	}
+	end = end.In(start.Location())
+	years := end.Year() - start.Year()
+	months := int(end.Month()) - int(start.Month())
+	days := end.Day() - start.Day()
+	clock := func(t time.Time) time.Duration {
+		return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second
+	}
+	if clock(end) < clock(start) {
+		days--
+	}
+	if days < 0 {
+		months--
+		// Days in the month before end's month
+		days += time.Date(end.Year(), end.Month(), 0, 0, 0, 0, 0, time.UTC).Day()
+	}
+	if months < 0 {
+		years--
+		months += 12
+	}
+	return map[string]interface{}{
+		"total_days":    math.Round(duration.Hours()/24*1000) / 1000,
+		"total_hours":   math.Round(duration.Hours()*1000) / 1000,
+		"total_seconds": int64(duration.Seconds()),
+		"calendar": map[string]int{
+			"years":  sign * years,
+			"months": sign * months,
+			"days":   sign * days,
+		},
+	}
+}
+
+// ---- random_value ----
+
+// maxRandomValues bounds the count of a random_value call
+const maxRandomValues = 100
+
+func randomValues(args map[string]interface{}) (string, error) {
+	count := 1
+	if v, ok := args["count"].(float64); ok && v >= 1 {
+		n, err := intArgument("count", v, 1, maxRandomValues)
+		if err != nil {
+			return "", err
+		}
+		count = int(n)
+	}
+	kind, _ := args["kind"].(string)
+	minValue, hasMin := args["min"].(float64)
+	maxValue, hasMax := args["max"].(float64)
+	if (hasMin && !isFinite(minValue)) || (hasMax && !isFinite(maxValue)) {
+		return "", fmt.Errorf("min and max must be finite numbers")
+	}
+
+	values := make([]string, 0, count)
+	for i := 0; i < count; i++ {
+		switch kind {
+		case "uuid":
+			values = append(values, uuid.NewString())
+		case "integer":
+			lo, hi := int64(1), int64(100)
+			var err error
+			if hasMin {
+				if lo, err = intArgument("min", math.Ceil(minValue), -maxIntegerArgument, maxIntegerArgument); err != nil {
+					return "", err
+				}
+			}
+			if hasMax {
+				if hi, err = intArgument("max", math.Floor(maxValue), -maxIntegerArgument, maxIntegerArgument); err != nil {
+					return "", err
+				}
+			}
+			if hi < lo {
+				return "", fmt.Errorf("max must not be less than min")
+			}
+			n, err := rand.Int(rand.Reader, new(big.Int).Add(new(big.Int).Sub(big.NewInt(hi), big.NewInt(lo)), big.NewInt(1)))
+			if err != nil {
+				return "", err
+			}
+			values = append(values, strconv.FormatInt(lo+n.Int64(), 10))
+		case "float":
+			lo, hi := 0.0, 1.0
+			if hasMin {
+				lo = minValue
+			}
+			if hasMax {
+				hi = maxValue
+			}
+			if hi <= lo {
+				return "", fmt.Errorf("max must be greater than min")
+			}
+			n, err := rand.Int(rand.Reader, big.NewInt(1<<53))
+			if err != nil {
+				return "", err
+			}
+			values = append(values, strconv.FormatFloat(lo+(hi-lo)*float64(n.Int64())/(1<<53), 'g', -1, 64))
+		default:
+			return "", fmt.Errorf("unknown kind %q: use integer, float or uuid", kind)
+		}
+	}
+	return strings.Join(values, "\n"), nil
+}
+
+// ---- base64 ----
+
+func base64Codec(operation, text string, urlSafe bool) (string, error) {
+	encoding := base64.StdEncoding
+	if urlSafe {
+		encoding = base64.URLEncoding
+	}
+	switch operation {
+	case "encode":
+		return encoding.EncodeToString([]byte(text)), nil
+	case "decode":
+		trimmed := strings.TrimSpace(text)
+		data, err := encoding.DecodeString(trimmed)
+		if err != nil {
+			// Accept input without padding
+			data, err = encoding.WithPadding(base64.NoPadding).DecodeString(strings.TrimRight(trimmed, "="))
+		}
+		if err != nil {
+			return "", fmt.Errorf("invalid base64: %w", err)
+		}
+		return string(data), nil
+	}
+	return "", fmt.Errorf("unknown operation %q: use encode or decode", operation)
+}
@@ -0,0 +1,200 @@
+package mcpagent
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+)
+
+func TestEvaluateExpression(t *testing.T) {
+	cases := map[string]string{
+		"0.1 + 0.2":                "0.3",
+		"(1250 * 0.075) / 12":      "7.8125",
+		"2 ^ 10":                   "1024",
+		"2 ** 3 ** 2":              "512",
+		"-2 ^ 2":                   "-4",
+		"2 ^ -2":                   "0.25",
+		"1 / 3":                    "0.333333333333333",
+		"7 % 3":                    "1",
+		"-7 % 3":                   "2",
+		"sqrt(16) + abs(-2.5)":     "6.5",
+		"round(2.345, 2)":          "2.35",
+		"round(-2.5)":              "-3",
+		"floor(-1.5) + ceil(1.2)":  "0",
+		"max(1, 7, 3) - min(4, 2)": "5",
+		"1_000_000 * 1e-3":         "1000",
+		"99999999999999999999 + 1": "100000000000000000000",
+		"log10(1000)":              "3",
+	}
+	for expression, want := range cases {
+		result, err := evaluateExpression(expression)
+		if err != nil {
+			t.Errorf("%s: %v", expression, err)
+			continue
+		}
+		if got := formatRat(result); got != want {
+			t.Errorf("%s = %s, want %s", expression, got, want)
+		}
+	}
+
+	for _, expression := range []string{"", "1 / 0", "2 +", "(1 + 2", "foo(1)", "sqrt(-1)", "1 2", "9 ^ 9 ^ 9", "(9 ^ 4096) ^ 4096"} {
+		if _, err := evaluateExpression(expression); err == nil {
+			t.Errorf("%q: expected an error", expression)
+		}
+	}
+}
+
+func TestConvertUnits(t *testing.T) {
+	cases := []struct {
+		value    string
+		from, to string
+		want     string
+	}{
+		{"1", "mi", "km", "1.609344"},
+		{"12", "inches", "ft", "1"},
+		{"100", "C", "F", "212"},
+		{"32", "fahrenheit", "celsius", "0"},
+		{"0", "K", "C", "-273.15"},
+		{"1", "GiB", "MB", "1073.741824"},
+		{"36", "km/h", "m/s", "10"},
+		{"2", "hours", "min", "120"},
+		{"1", "lb", "g", "453.59237"},
+	}
+	for _, tc := range cases {
+		value, _ := new(big.Rat).SetString(tc.value)
+		result, err := convertUnits(value, tc.from, tc.to)
+		if err != nil {
+			t.Errorf("%s %s -> %s: %v", tc.value, tc.from, tc.to, err)
+			continue
+		}
+		if got := formatRat(result); got != tc.want {
+			t.Errorf("%s %s -> %s = %s, want %s", tc.value, tc.from, tc.to, got, tc.want)
+		}
+	}
+
+	if _, err := convertUnits(big.NewRat(1, 1), "kg", "m"); err == nil {
+		t.Error("converting between dimensions should fail")
+	}
+	if _, err := convertUnits(big.NewRat(1, 1), "C", "kg"); err == nil {
+		t.Error("converting a temperature to mass should fail")
+	}
+	if _, err := convertUnits(big.NewRat(1, 1), "parsec", "m"); err == nil {
+		t.Error("unknown units should fail")
+	}
+}
+
+func TestDateMath(t *testing.T) {
+	now := time.Date(2024, 1, 31, 9, 30, 0, 0, time.UTC)
+	run := func(args map[string]interface{}) map[string]interface{} {
+		t.Helper()
+		out, err := dateMath(args, now)
+		if err != nil {
+			t.Fatalf("dateMath(%v): %v", args, err)
+		}
+		var result map[string]interface{}
+		if err := json.Unmarshal([]byte(out), &result); err != nil {
+			t.Fatal(err)
+		}
+		return result
+	}
+
+	if result := run(map[string]interface{}{"operation": "now"}); result["date"] != "2024-01-31T09:30:00Z" || result["weekday"] != "Wednesday" {
+		t.Errorf("now = %v", result)
+	}
+	if result := run(map[string]interface{}{"operation": "add", "date": "2024-01-31", "months": 1.0, "days": -1.0}); result["date"] != "2024-03-01T00:00:00Z" {
+		t.Errorf("add = %v", result)
+	}
+	result := run(map[string]interface{}{"operation": "diff", "date": "2023-11-15", "end_date": "2024-03-01"})
+	calendar := result["calendar"].(map[string]interface{})
+	if result["total_days"] != 107.0 || calendar["months"] != 3.0 || calendar["days"] != 15.0 {
+		t.Errorf("diff = %v", result)
+	}
+	if result := run(map[string]interface{}{"operation": "info", "date": "2024-12-30 08:00", "timezone": "UTC"}); result["iso_week"] != "2025-W01" {
+		t.Errorf("info = %v", result)
+	}
+
+	if _, err := dateMath(map[string]interface{}{"operation": "diff"}, now); err == nil {
+		t.Error("diff without end_date should fail")
+	}
+	if _, err := dateMath(map[string]interface{}{"operation": "add", "date": "31/01/2024"}, now); err == nil {
+		t.Error("unparseable dates should fail")
+	}
+	for _, days := range []float64{1e300, math.Inf(-1), math.NaN()} {
+		if _, err := dateMath(map[string]interface{}{"operation": "add", "days": days}, now); err == nil {
+			t.Errorf("days = %v should fail", days)
+		}
+	}
+}
+
+func TestRandomValuesAndBase64(t *testing.T) {
+	out, err := randomValues(map[string]interface{}{"kind": "integer", "min": 5.0, "max": 6.0, "count": 20.0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	values := strings.Split(out, "\n")
+	if len(values) != 20 {
+		t.Fatalf("got %d values, want 20", len(values))
+	}
+	for _, v := range values {
+		if n, err := strconv.Atoi(v); err != nil || n < 5 || n > 6 {
+			t.Errorf("integer %q outside [5, 6]", v)
+		}
+	}
+	if out, err := randomValues(map[string]interface{}{"kind": "uuid"}); err != nil || len(out) != 36 {
+		t.Errorf("uuid = %q, %v", out, err)
+	}
+	if _, err := randomValues(map[string]interface{}{"kind": "integer", "count": 1000.0}); err == nil {
+		t.Error("count above the limit should fail")
+	}
+	if _, err := randomValues(map[string]interface{}{"kind": "integer", "min": -1e300, "max": math.Inf(1)}); err == nil {
+		t.Error("bounds beyond the integer range should fail")
+	}
+
+	encoded, _ := base64Codec("encode", "hello?>", true)
+	if encoded != "aGVsbG8_Pg==" {
+		t.Errorf("encode = %q", encoded)
+	}
+	if decoded, err := base64Codec("decode", "aGVsbG8_Pg", true); err != nil || decoded != "hello?>" {
+		t.Errorf("decode without padding = %q, %v", decoded, err)
+	}
+	if _, err := base64Codec("decode", "not base64!", false); err == nil {
+		t.Error("invalid base64 should fail")
+	}
+}
+
+func TestWithBuiltinUtilityToolsRegistersTools(t *testing.T) {
+	config := filepath.Join(t.TempDir(), "mcp.json")
+	if err := os.WriteFile(config, []byte(`{"mcpServers": {}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	a, err := NewAgent(context.Background(), &providerKeyCarrierModel{}, config,
+		WithLogger(loggerv2.NewNoop()), WithBuiltinUtilityTools(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer a.Close()
+
+	for _, name := range BuiltinUtilityToolNames {
+		tool, ok := a.customTools[name]
+		if !ok || tool.Category != utilityToolsCategory {
+			t.Errorf("tool %s not registered in the %s category", name, utilityToolsCategory)
+		}
+	}
+	out, err := a.customTools["calculate"].Execution(context.Background(), map[string]interface{}{"expression": "19.99 * 3"})
+	if err != nil || out != "59.97" {
+		t.Errorf("calculate = %q, %v", out, err)
+	}
+	// Huge floats overflow int; the tool must refuse them rather than panic
+	if _, err := a.customTools["random_value"].Execution(context.Background(), map[string]interface{}{"kind": "uuid", "count": 1e300}); err == nil {
+		t.Error("random_value with count=1e300 should fail")
+	}
+}
@@ -162,4 +162,4 @@ func extractAllTokenTypes(resp *llmtypes.ContentResponse) (cacheTokens, thoughts
 }
 
 // estimateInputTokens has been removed - we now use only actual token values from LLM responses.
-// For token counting needs, use CountTokensForModel() (tiktoken-based) which is available in tool_output_handler.go
+// For token counting needs, use Agent.EstimateTokens() or CountTokensForModel() (see tokenizer.go)
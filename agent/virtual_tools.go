@@ -24,7 +24,7 @@ type VirtualTool struct {
 func (a *Agent) CreateVirtualTools() []llmtypes.Tool {
 	var virtualTools []llmtypes.Tool
 
-	// Check if MCP servers exist - get_prompt and get_resource require MCP servers
+	// Check if MCP servers exist - get_prompt and read_resource require MCP servers
 	hasMCPServers := len(a.Clients) > 0
 	// Also check if NO_SERVERS is explicitly selected (overrides client count)
 	if len(a.selectedServers) > 0 {
@@ -83,33 +83,83 @@ func (a *Agent) CreateVirtualTools() []llmtypes.Tool {
 			},
 		}
 		virtualTools = append(virtualTools, getPromptTool)
+
+		// use_prompt renders prompt templates that take arguments
+		if a.hasPromptsWithArguments() {
+			usePromptTool := llmtypes.Tool{
+				Type: "function",
+				Function: &llmtypes.FunctionDefinition{
+					Name:        "use_prompt",
+					Description: a.usePromptToolDescription(),
+					Parameters: llmtypes.NewParameters(map[string]interface{}{
+						"type": "object",
+						"properties": map[string]interface{}{
+							"server": map[string]interface{}{
+								"type":        "string",
+								"description": "Server name",
+							},
+							"name": map[string]interface{}{
+								"type":        "string",
+								"description": "Prompt name",
+							},
+							"arguments": map[string]interface{}{
+								"type":                 "object",
+								"description":          "Values of the prompt's arguments (e.g., {\"language\": \"go\"})",
+								"additionalProperties": map[string]interface{}{"type": "string"},
+							},
+						},
+						"required": []string{"server", "name"},
+					}),
+				},
+			}
+			virtualTools = append(virtualTools, usePromptTool)
+		}
 	}
 
-	// Only add get_resource if resources actually exist
-	if hasResources {
-		// Add get_resource tool
-		getResourceTool := llmtypes.Tool{
+	// Resource templates can exist without listed resources
+	hasResourceTemplates := false
+	if hasMCPServers {
+		for _, serverTemplates := range a.resourceTemplates {
+			if len(serverTemplates) > 0 {
+				hasResourceTemplates = true
+				break
+			}
+		}
+	}
+
+	// Only add read_resource if resources or resource templates actually exist
+	// (get_resource is still handled for conversations that used it)
+	if hasResources || hasResourceTemplates {
+		readResourceTool := llmtypes.Tool{
 			Type: "function",
 			Function: &llmtypes.FunctionDefinition{
-				Name:        "get_resource",
-				Description: "Fetch the content of a specific resource by URI and server. Only use URIs that are listed in the system prompt's 'AVAILABLE RESOURCES' section.",
+				Name:        "read_resource",
+				Description: a.readResourceToolDescription(),
 				Parameters: llmtypes.NewParameters(map[string]interface{}{
 					"type": "object",
 					"properties": map[string]interface{}{
-						"server": map[string]interface{}{
+						"uri": map[string]interface{}{
 							"type":        "string",
-							"description": "Server name",
+							"description": "Resource URI (e.g., file:///docs/guide.md)",
 						},
-						"uri": map[string]interface{}{
+						"template": map[string]interface{}{
 							"type":        "string",
-							"description": "Resource URI",
+							"description": "Resource template (its URI template or name) to expand with arguments, instead of uri",
+						},
+						"arguments": map[string]interface{}{
+							"type":                 "object",
+							"description":          "Values of the template's variables (e.g., {\"table\": \"orders\"}). Used with template",
+							"additionalProperties": map[string]interface{}{"type": "string"},
+						},
+						"server": map[string]interface{}{
+							"type":        "string",
+							"description": "Server name. Optional: by default the server listing the resource or template is used",
 						},
 					},
-					"required": []string{"server", "uri"},
 				}),
 			},
 		}
-		virtualTools = append(virtualTools, getResourceTool)
+		virtualTools = append(virtualTools, readResourceTool)
 	}
 
 	// Add context offloading virtual tools if enabled
@@ -120,6 +170,17 @@ func (a *Agent) CreateVirtualTools() []llmtypes.Tool {
 		virtualTools = append(virtualTools, largeOutputTools...)
 	}
 
+	// compact_context lets the LLM summarize the conversation when it notices
+	// context bloat (see compact_context.go)
+	if a.EnableContextSummarization && !a.UseCodeExecutionMode {
+		virtualTools = append(virtualTools, createCompactContextTool())
+	}
+
+	// update_plan keeps the LLM's task checklist for plan events (see plan.go)
+	if a.EnablePlanTracking && !a.UseCodeExecutionMode {
+		virtualTools = append(virtualTools, createUpdatePlanTool())
+	}
+
 	// Add get_api_spec tool — returns OpenAPI spec for specific tool(s)
 	getAPISpecTool := llmtypes.Tool{
 		Type: "function",
@@ -143,16 +204,24 @@ func (a *Agent) CreateVirtualTools() []llmtypes.Tool {
 	}
 	virtualTools = append(virtualTools, getAPISpecTool)
 
-	return virtualTools
+	return a.applyToolPermissions(virtualTools)
 }
 
 // HandleVirtualTool handles virtual tool execution
 func (a *Agent) HandleVirtualTool(ctx context.Context, toolName string, args map[string]interface{}) (string, error) {
+	if err := a.checkToolPermission(toolName, args); err != nil {
+		return "", err
+	}
+
 	switch toolName {
 	case "get_prompt":
 		return a.handleGetPrompt(ctx, args)
+	case "use_prompt":
+		return a.handleUsePrompt(ctx, args)
 	case "get_resource":
 		return a.handleGetResource(ctx, args)
+	case "read_resource":
+		return a.handleReadResource(ctx, args)
 	case "get_api_spec":
 		return a.handleGetAPISpec(ctx, args)
 	case "search_tools":
@@ -163,12 +232,18 @@ func (a *Agent) HandleVirtualTool(ctx context.Context, toolName string, args map
 		return a.handleRemoveTool(ctx, args)
 	case "show_all_tools":
 		return a.handleShowAllTools(ctx, args)
+	case "compact_context":
+		return a.handleCompactContext(ctx, args)
+	case "update_plan":
+		return a.handleUpdatePlan(ctx, args)
+	case "apply_patch", "insert_at_line", "replace_between_markers":
+		return a.handleWorkspaceEditTool(ctx, toolName, args)
 	default:
 		// Check if it's a context offloading virtual tool
 		if a.EnableContextOffloading {
 			return a.HandleLargeOutputVirtualTool(ctx, toolName, args)
 		}
-		return "", fmt.Errorf("unknown virtual tool: %s", toolName)
+		return "", fmt.Errorf("unknown virtual tool: %s: %w", toolName, ErrToolNotFound)
 	}
 }
 
@@ -325,8 +400,12 @@ func (a *Agent) handleGetResource(ctx context.Context, args map[string]interface
 // formatResourceContents formats resource contents for display (copied from existing code)
 func formatResourceContents(resource mcp.ResourceContents) string {
 	switch r := resource.(type) {
+	case mcp.TextResourceContents:
+		return r.Text
 	case *mcp.TextResourceContents:
 		return r.Text
+	case mcp.BlobResourceContents:
+		return fmt.Sprintf("[Binary data: %s]", r.MIMEType)
 	case *mcp.BlobResourceContents:
 		return fmt.Sprintf("[Binary data: %s]", r.MIMEType)
 	default:
@@ -0,0 +1,304 @@
+// webhook.go
+//
+// This file provides conversation event webhooks. An agent created
+// WithWebhook POSTs the selected events to an external URL so low-code
+// platforms can react to tool errors or finished conversations without
+// consuming the full event stream. Deliveries are signed with HMAC-SHA256,
+// sent from a background worker so they never block the agent, retried with
+// exponential backoff, and appended to a dead-letter file when they keep
+// failing.
+//
+// Exported:
+//   - WithWebhook: Register a webhook for selected event types
+//   - WithWebhookDeadLetterFile: Where failed deliveries are recorded
+//   - WebhookSignatureHeader / WebhookEventHeader: Request headers
+//   - SignWebhookPayload: Compute the signature receivers should verify
+
+package mcpagent
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/manishiitg/mcpagent/events"
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+)
+
+const (
+	// WebhookSignatureHeader carries "sha256=<hex HMAC of the body>" when a secret is set
+	WebhookSignatureHeader = "X-MCPAgent-Signature"
+	// WebhookEventHeader carries the event type of the delivery
+	WebhookEventHeader = "X-MCPAgent-Event"
+
+	// DefaultWebhookDeadLetterFile receives deliveries that failed every retry
+	DefaultWebhookDeadLetterFile = "webhook_dead_letter.jsonl"
+
+	webhookMaxAttempts  = 4
+	webhookTimeout      = 10 * time.Second
+	webhookQueueSize    = 256
+	webhookCloseTimeout = 10 * time.Second
+)
+
+// webhookRetryBase is the delay before the first retry; it doubles per attempt
+var webhookRetryBase = 500 * time.Millisecond
+
+// WithWebhook POSTs events of the given types to url as JSON (the same
+// AgentEvent shape the event stream uses). When secret is non-empty every
+// request carries an HMAC-SHA256 signature of the body in
+// WebhookSignatureHeader. Failed deliveries (network errors, 429 and 5xx) are
+// retried with exponential backoff; deliveries that still fail are appended to
+// the dead-letter file (see WithWebhookDeadLetterFile). Can be used multiple
+// times to register several webhooks.
+//
+// Parameters:
+//   - url: Endpoint that receives the events
+//   - eventTypes: Event types to deliver (e.g. events.ToolCallError, events.ConversationEnd); empty = all events
+//   - secret: HMAC key shared with the receiver; empty = unsigned
+//
+// Default: No webhooks
+func WithWebhook(url string, eventTypes []events.EventType, secret string) AgentOption {
+	return func(a *Agent) {
+		sink := &webhookSink{
+			agent:  a,
+			url:    url,
+			secret: secret,
+			client: &http.Client{Timeout: webhookTimeout},
+		}
+		if len(eventTypes) > 0 {
+			sink.eventTypes = make(map[events.EventType]bool, len(eventTypes))
+			for _, eventType := range eventTypes {
+				sink.eventTypes[eventType] = true
+			}
+		}
+		a.webhooks = append(a.webhooks, sink)
+		a.listeners = append(a.listeners, sink)
+	}
+}
+
+// WithWebhookDeadLetterFile sets the JSON Lines file that receives webhook
+// deliveries which failed every retry, so they can be replayed later.
+//
+// Default: DefaultWebhookDeadLetterFile in the working directory
+func WithWebhookDeadLetterFile(path string) AgentOption {
+	return func(a *Agent) {
+		a.WebhookDeadLetterFile = path
+	}
+}
+
+// SignWebhookPayload returns the WebhookSignatureHeader value for body signed with secret
+func SignWebhookPayload(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// webhookDelivery is one queued request
+type webhookDelivery struct {
+	eventType events.EventType
+	body      []byte
+}
+
+// webhookDeadLetter is one line of the dead-letter file
+type webhookDeadLetter struct {
+	URL       string          `json:"url"`
+	EventType string          `json:"event_type"`
+	Event     json.RawMessage `json:"event"`
+	Attempts  int             `json:"attempts"`
+	Error     string          `json:"error"`
+	FailedAt  time.Time       `json:"failed_at"`
+}
+
+// webhookSink is an AgentEventListener that delivers events to one webhook URL
+type webhookSink struct {
+	agent      *Agent
+	url        string
+	eventTypes map[events.EventType]bool // nil = all events
+	secret     string
+	client     *http.Client
+
+	startOnce sync.Once
+	mu        sync.RWMutex // guards closed and sends on queue
+	closed    bool
+	queue     chan webhookDelivery
+	done      chan struct{}
+
+	deadLetterMu sync.Mutex
+}
+
+// Name implements AgentEventListener
+func (w *webhookSink) Name() string {
+	return "webhook:" + w.url
+}
+
+// HandleEvent implements AgentEventListener. It only queues the event; the
+// request is made by the background worker.
+func (w *webhookSink) HandleEvent(_ context.Context, event *events.AgentEvent) error {
+	if event == nil || (w.eventTypes != nil && !w.eventTypes[event.Type]) {
+		return nil
+	}
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook event: %w", err)
+	}
+	delivery := webhookDelivery{eventType: event.Type, body: body}
+
+	w.startOnce.Do(w.start)
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.closed {
+		w.deadLetter(delivery, 0, fmt.Errorf("agent closed"))
+		return nil
+	}
+	select {
+	case w.queue <- delivery:
+	default:
+		w.deadLetter(delivery, 0, fmt.Errorf("webhook queue full"))
+	}
+	return nil
+}
+
+func (w *webhookSink) start() {
+	w.queue = make(chan webhookDelivery, webhookQueueSize)
+	w.done = make(chan struct{})
+	go func() {
+		defer close(w.done)
+		for delivery := range w.queue {
+			w.deliver(delivery)
+		}
+	}()
+}
+
+// close stops accepting events and waits (bounded) for queued deliveries
+func (w *webhookSink) close() {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return
+	}
+	w.closed = true
+	started := w.queue != nil
+	if started {
+		close(w.queue)
+	}
+	w.mu.Unlock()
+
+	if !started {
+		return
+	}
+	select {
+	case <-w.done:
+	case <-time.After(webhookCloseTimeout):
+		w.logger().Warn("Timed out flushing webhook deliveries", loggerv2.String("url", w.url))
+	}
+}
+
+// deliver sends one event, retrying transient failures, and dead-letters it on final failure
+func (w *webhookSink) deliver(delivery webhookDelivery) {
+	var err error
+	attempt := 0
+	for attempt < webhookMaxAttempts {
+		if attempt > 0 {
+			time.Sleep(webhookRetryBase << (attempt - 1))
+		}
+		attempt++
+		var retryable bool
+		retryable, err = w.post(delivery)
+		if err == nil {
+			return
+		}
+		if !retryable {
+			break
+		}
+	}
+	w.logger().Warn("Webhook delivery failed",
+		loggerv2.String("url", w.url),
+		loggerv2.String("event_type", string(delivery.eventType)),
+		loggerv2.Int("attempts", attempt),
+		loggerv2.Error(err))
+	w.deadLetter(delivery, attempt, err)
+}
+
+// post makes one request; the bool reports whether a failure is worth retrying
+func (w *webhookSink) post(delivery webhookDelivery) (bool, error) {
+	req, err := http.NewRequest(http.MethodPost, w.url, bytes.NewReader(delivery.body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookEventHeader, string(delivery.eventType))
+	if w.secret != "" {
+		req.Header.Set(WebhookSignatureHeader, SignWebhookPayload(w.secret, delivery.body))
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	err = fmt.Errorf("webhook returned HTTP %d", resp.StatusCode)
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500, err
+}
+
+// deadLetter appends a failed delivery to the dead-letter file
+func (w *webhookSink) deadLetter(delivery webhookDelivery, attempts int, cause error) {
+	path := w.agent.WebhookDeadLetterFile
+	if path == "" {
+		path = DefaultWebhookDeadLetterFile
+	}
+	line, err := json.Marshal(webhookDeadLetter{
+		URL:       w.url,
+		EventType: string(delivery.eventType),
+		Event:     delivery.body,
+		Attempts:  attempts,
+		Error:     cause.Error(),
+		FailedAt:  time.Now(),
+	})
+	if err != nil {
+		return
+	}
+
+	w.deadLetterMu.Lock()
+	defer w.deadLetterMu.Unlock()
+	if dir := filepath.Dir(path); dir != "." {
+		_ = os.MkdirAll(dir, 0755) //nolint:gosec // 0755 permissions are intentional for user-accessible directories
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		w.logger().Warn("Failed to open webhook dead-letter file", loggerv2.String("path", path), loggerv2.Error(err))
+		return
+	}
+	defer f.Close()
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		w.logger().Warn("Failed to write webhook dead-letter file", loggerv2.String("path", path), loggerv2.Error(err))
+	}
+}
+
+func (w *webhookSink) logger() loggerv2.Logger {
+	if w.agent.Logger != nil {
+		return w.agent.Logger
+	}
+	return loggerv2.NewNoop()
+}
+
+// closeWebhooks flushes and stops all webhook workers
+func (a *Agent) closeWebhooks() {
+	for _, sink := range a.webhooks {
+		sink.close()
+	}
+}
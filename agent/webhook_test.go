@@ -0,0 +1,134 @@
+package mcpagent
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/manishiitg/mcpagent/events"
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+)
+
+func webhookTestAgent(t *testing.T, options ...AgentOption) *Agent {
+	t.Helper()
+	previous := webhookRetryBase
+	webhookRetryBase = time.Millisecond
+	t.Cleanup(func() { webhookRetryBase = previous })
+
+	a := &Agent{
+		Logger:                loggerv2.NewNoop(),
+		WebhookDeadLetterFile: filepath.Join(t.TempDir(), "dead.jsonl"),
+	}
+	for _, option := range options {
+		option(a)
+	}
+	return a
+}
+
+func emitWebhookTestEvent(t *testing.T, a *Agent, data events.EventData) {
+	t.Helper()
+	event := events.NewAgentEvent(data)
+	for _, listener := range a.listeners {
+		if err := listener.HandleEvent(context.Background(), event); err != nil {
+			t.Fatalf("HandleEvent: %v", err)
+		}
+	}
+}
+
+func TestWebhookDeliversSelectedSignedEvents(t *testing.T) {
+	var mu sync.Mutex
+	var received []*http.Request
+	var bodies [][]byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		received = append(received, r)
+		bodies = append(bodies, body)
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	a := webhookTestAgent(t, WithWebhook(server.URL, []events.EventType{events.ToolCallError}, "s3cret"))
+	emitWebhookTestEvent(t, a, &events.ToolCallStartEvent{ToolName: "read_file"})
+	emitWebhookTestEvent(t, a, &events.ToolCallErrorEvent{ToolName: "read_file", Error: "boom"})
+	a.closeWebhooks()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(received) != 1 {
+		t.Fatalf("expected only the ToolCallError event to be delivered, got %d requests", len(received))
+	}
+	if got := received[0].Header.Get(WebhookEventHeader); got != string(events.ToolCallError) {
+		t.Errorf("%s = %q", WebhookEventHeader, got)
+	}
+	if got, want := received[0].Header.Get(WebhookSignatureHeader), SignWebhookPayload("s3cret", bodies[0]); got != want {
+		t.Errorf("signature = %q, want %q", got, want)
+	}
+	var event map[string]interface{}
+	if err := json.Unmarshal(bodies[0], &event); err != nil || event["type"] != string(events.ToolCallError) {
+		t.Errorf("unexpected body %s (%v)", bodies[0], err)
+	}
+}
+
+func TestWebhookRetriesServerErrors(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	a := webhookTestAgent(t, WithWebhook(server.URL, nil, ""))
+	emitWebhookTestEvent(t, a, &events.ConversationEndEvent{Result: "done"})
+	a.closeWebhooks()
+
+	if got := atomic.LoadInt32(&calls); got != 3 {
+		t.Errorf("expected 3 attempts, got %d", got)
+	}
+	if _, err := os.Stat(a.WebhookDeadLetterFile); !os.IsNotExist(err) {
+		t.Error("expected no dead letter after a successful retry")
+	}
+}
+
+func TestWebhookDeadLettersFailedDeliveries(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	a := webhookTestAgent(t, WithWebhook(server.URL, nil, ""))
+	emitWebhookTestEvent(t, a, &events.ConversationEndEvent{Result: "done"})
+	a.closeWebhooks()
+
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Errorf("expected client errors not to be retried, got %d attempts", got)
+	}
+	data, err := os.ReadFile(a.WebhookDeadLetterFile)
+	if err != nil {
+		t.Fatalf("expected dead-letter file: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("expected one dead letter, got %d", len(lines))
+	}
+	var record webhookDeadLetter
+	if err := json.Unmarshal([]byte(lines[0]), &record); err != nil {
+		t.Fatal(err)
+	}
+	if record.URL != server.URL || record.EventType != string(events.ConversationEnd) ||
+		record.Attempts != 1 || !strings.Contains(record.Error, "400") {
+		t.Errorf("unexpected dead letter: %+v", record)
+	}
+}
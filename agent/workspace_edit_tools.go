@@ -0,0 +1,764 @@
+// workspace_edit_tools.go
+//
+// This file provides virtual tools for editing files in place, so a
+// code-editing agent can change a few lines without rewriting the whole file
+// through the filesystem MCP server's write_file:
+//
+//   - apply_patch: apply a unified diff (one or more files; creating, deleting
+//     and renaming files is supported)
+//   - insert_at_line: insert text before a given line
+//   - replace_between_markers: replace the text between two marker strings
+//
+// The tools only touch files inside the folder guard write paths
+// (SetFolderGuardPaths) and are offered while write paths are set; code
+// execution mode edits files from code instead and does not get them.
+// Relative paths are resolved against the first write path. Every changed
+// file emits a workspace_file_operation event ("patch", "update", "move" or
+// "delete").
+//
+// apply_patch checks every hunk against the current file content before
+// writing anything, so a patch either applies completely or not at all: the
+// new contents are written to temporary files and renamed into place, and a
+// failing write restores the files already changed. A patch may change each
+// file once, and its paths are checked against the ToolPermission path scope
+// of apply_patch like the path arguments of the other tools.
+// Hunks are located by their context and removed lines; the line numbers in
+// "@@" headers are only a hint, so a patch still applies when the file
+// shifted a few lines since the LLM read it.
+
+package mcpagent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/manishiitg/mcpagent/events"
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+// workspaceEditToolNames are the virtual tools defined in this file
+var workspaceEditToolNames = []string{"apply_patch", "insert_at_line", "replace_between_markers"}
+
+// isWorkspaceEditTool reports whether name is one of the workspace edit tools
+func isWorkspaceEditTool(name string) bool {
+	for _, tool := range workspaceEditToolNames {
+		if tool == name {
+			return true
+		}
+	}
+	return false
+}
+
+// createWorkspaceEditTools returns the workspace edit virtual tools for the
+// given write paths
+func createWorkspaceEditTools(writePaths []string) []llmtypes.Tool {
+	folders := strings.Join(writePaths, ", ")
+	pathDescription := fmt.Sprintf("File to edit, inside one of the writable folders (%s). Relative paths are resolved against %s", folders, writePaths[0])
+	return []llmtypes.Tool{
+		{
+			Type: "function",
+			Function: &llmtypes.FunctionDefinition{
+				Name: "apply_patch",
+				Description: fmt.Sprintf("Apply a unified diff to files in the writable folders (%s). Prefer this over rewriting whole files: send only the changed hunks, with a few unchanged context lines around each change. "+
+					"Each file starts with '--- a/<path>' and '+++ b/<path>' lines ('/dev/null' to create or delete a file), followed by '@@ -<line>,<count> +<line>,<count> @@' hunks whose lines start with ' ' (context), '-' (removed) or '+' (added). "+
+					"Either every hunk applies or no file is changed.", folders),
+				Parameters: llmtypes.NewParameters(map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"patch": map[string]interface{}{
+							"type":        "string",
+							"description": fmt.Sprintf("The unified diff. Relative paths are resolved against %s", writePaths[0]),
+						},
+					},
+					"required": []string{"patch"},
+				}),
+			},
+		},
+		{
+			Type: "function",
+			Function: &llmtypes.FunctionDefinition{
+				Name:        "insert_at_line",
+				Description: "Insert text into a file before the given line, leaving the rest of the file unchanged. Use the file's line count + 1 to append.",
+				Parameters: llmtypes.NewParameters(map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"path": map[string]interface{}{"type": "string", "description": pathDescription},
+						"line": map[string]interface{}{
+							"type":        "integer",
+							"description": "1-based line number the text is inserted before",
+						},
+						"content": map[string]interface{}{
+							"type":        "string",
+							"description": "Text to insert; may span several lines",
+						},
+					},
+					"required": []string{"path", "line", "content"},
+				}),
+			},
+		},
+		{
+			Type: "function",
+			Function: &llmtypes.FunctionDefinition{
+				Name:        "replace_between_markers",
+				Description: "Replace the text between two marker strings in a file (e.g. '// BEGIN GENERATED' and '// END GENERATED'), keeping the markers. The start marker must occur exactly once in the file.",
+				Parameters: llmtypes.NewParameters(map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"path":         map[string]interface{}{"type": "string", "description": pathDescription},
+						"start_marker": map[string]interface{}{"type": "string", "description": "Text marking the start of the region"},
+						"end_marker":   map[string]interface{}{"type": "string", "description": "Text marking the end of the region; the first occurrence after the start marker is used"},
+						"content": map[string]interface{}{
+							"type":        "string",
+							"description": "New text for the region between the markers",
+						},
+						"include_markers": map[string]interface{}{
+							"type":        "boolean",
+							"description": "Replace the markers too instead of keeping them (default: false)",
+						},
+					},
+					"required": []string{"path", "start_marker", "end_marker", "content"},
+				}),
+			},
+		},
+	}
+}
+
+// syncWorkspaceEditTools offers the workspace edit tools while folder guard
+// write paths are set, and withdraws them otherwise. Called by
+// SetFolderGuardPaths, since write paths are usually set after NewAgent.
+func (a *Agent) syncWorkspaceEditTools() {
+	a.Tools = withoutWorkspaceEditTools(a.Tools)
+	a.filteredTools = withoutWorkspaceEditTools(a.filteredTools)
+	for _, name := range workspaceEditToolNames {
+		delete(a.discoveredTools, name)
+	}
+
+	var tools []llmtypes.Tool
+	if len(a.FolderGuardWritePaths) > 0 && !a.UseCodeExecutionMode {
+		tools = a.applyToolPermissions(createWorkspaceEditTools(a.FolderGuardWritePaths))
+	}
+	if a.UseToolSearchMode {
+		// Like search_large_output, editing tools are immediately available
+		if a.discoveredTools == nil {
+			a.discoveredTools = make(map[string]llmtypes.Tool)
+		}
+		for _, tool := range tools {
+			a.discoveredTools[tool.Function.Name] = tool
+		}
+		a.filteredTools = a.getToolsForToolSearchMode()
+		return
+	}
+	a.Tools = append(a.Tools, tools...)
+	a.filteredTools = append(a.filteredTools, tools...)
+}
+
+// withoutWorkspaceEditTools returns tools minus the workspace edit tools
+func withoutWorkspaceEditTools(tools []llmtypes.Tool) []llmtypes.Tool {
+	if len(tools) == 0 {
+		return tools
+	}
+	kept := make([]llmtypes.Tool, 0, len(tools))
+	for _, tool := range tools {
+		if tool.Function == nil || !isWorkspaceEditTool(tool.Function.Name) {
+			kept = append(kept, tool)
+		}
+	}
+	return kept
+}
+
+// handleWorkspaceEditTool handles the workspace edit virtual tools
+func (a *Agent) handleWorkspaceEditTool(ctx context.Context, toolName string, args map[string]interface{}) (string, error) {
+	_, writePaths := a.GetFolderGuardPaths()
+	if len(writePaths) == 0 {
+		return "", fmt.Errorf("%s is not available: no folder is writable in this session", toolName)
+	}
+	switch toolName {
+	case "apply_patch":
+		return a.handleApplyPatch(ctx, args)
+	case "insert_at_line":
+		return a.handleInsertAtLine(ctx, args)
+	default:
+		return a.handleReplaceBetweenMarkers(ctx, args)
+	}
+}
+
+// editablePath resolves path (relative paths against the first write path)
+// and returns it with the write path containing it, or an error when it is
+// outside every write path
+func (a *Agent) editablePath(path string) (resolved, folder string, err error) {
+	if strings.TrimSpace(path) == "" {
+		return "", "", errors.New("path is required")
+	}
+	_, writePaths := a.GetFolderGuardPaths()
+	if !filepath.IsAbs(path) && path != "~" && !strings.HasPrefix(path, "~/") {
+		path = filepath.Join(writePaths[0], path)
+	}
+	resolved, err = resolveGuardedPath(path, "")
+	if err != nil {
+		return "", "", fmt.Errorf("invalid path %q: %w", path, err)
+	}
+	for _, writePath := range writePaths {
+		if root, err := resolveGuardedPath(writePath, ""); err == nil && withinFolders(resolved, []string{root}) {
+			return resolved, writePath, nil
+		}
+	}
+	return "", "", fmt.Errorf("Access denied: %q is outside the writable folders: %s. Use a path inside one of them.",
+		path, strings.Join(writePaths, ", "))
+}
+
+// emitWorkspaceEdit emits a workspace_file_operation event for an edited file
+func (a *Agent) emitWorkspaceEdit(ctx context.Context, operation, path, folder string) {
+	turn, _ := ctx.Value(ToolExecutionTurnKey).(int)
+	serverName, _ := ctx.Value(ToolExecutionServerKey).(string)
+	a.EmitTypedEvent(ctx, events.NewWorkspaceFileOperationEvent(operation, path, folder, turn, serverName))
+}
+
+// textFile is a file's content as lines, remembering whether it ended with a newline
+type textFile struct {
+	lines           []string
+	trailingNewline bool
+	mode            os.FileMode
+	created         bool // not on disk yet
+}
+
+func readTextFile(path string) (*textFile, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return nil, fmt.Errorf("%s is a directory", path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return splitTextFile(string(data), info.Mode().Perm()), nil
+}
+
+func splitTextFile(content string, mode os.FileMode) *textFile {
+	file := &textFile{trailingNewline: strings.HasSuffix(content, "\n"), mode: mode}
+	content = strings.TrimSuffix(content, "\n")
+	if content != "" || file.trailingNewline {
+		file.lines = strings.Split(content, "\n")
+	}
+	return file
+}
+
+func (f *textFile) String() string {
+	if len(f.lines) == 0 {
+		return ""
+	}
+	content := strings.Join(f.lines, "\n")
+	if f.trailingNewline {
+		content += "\n"
+	}
+	return content
+}
+
+func (f *textFile) write(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	mode := f.mode
+	if mode == 0 {
+		mode = 0o644
+	}
+	return os.WriteFile(path, []byte(f.String()), mode)
+}
+
+// contentLines splits inserted text into lines, ignoring one trailing newline
+func contentLines(content string) []string {
+	return strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+}
+
+func (a *Agent) handleInsertAtLine(ctx context.Context, args map[string]interface{}) (string, error) {
+	rawPath, _ := args["path"].(string)
+	path, folder, err := a.editablePath(rawPath)
+	if err != nil {
+		return "", err
+	}
+	line, ok := args["line"].(float64)
+	if !ok {
+		if n, isInt := args["line"].(int); isInt {
+			line, ok = float64(n), true
+		}
+	}
+	if !ok || line != float64(int(line)) {
+		return "", errors.New("line is required and must be an integer")
+	}
+	content, ok := args["content"].(string)
+	if !ok {
+		return "", errors.New("content is required")
+	}
+
+	file, err := readTextFile(path)
+	if err != nil {
+		return "", fmt.Errorf("cannot read %s: %w", rawPath, err)
+	}
+	at := int(line)
+	if at < 1 || at > len(file.lines)+1 {
+		return "", fmt.Errorf("line %d is out of range: %s has %d lines, so use 1 to %d", at, rawPath, len(file.lines), len(file.lines)+1)
+	}
+	inserted := contentLines(content)
+	lines := make([]string, 0, len(file.lines)+len(inserted))
+	lines = append(lines, file.lines[:at-1]...)
+	lines = append(lines, inserted...)
+	if len(file.lines) == 0 {
+		file.trailingNewline = true
+	}
+	file.lines = append(lines, file.lines[at-1:]...)
+	if err := file.write(path); err != nil {
+		return "", fmt.Errorf("cannot write %s: %w", rawPath, err)
+	}
+
+	a.emitWorkspaceEdit(ctx, "update", path, folder)
+	getLogger(a).Info("✏️ [WORKSPACE_EDIT] Inserted lines",
+		loggerv2.String("path", path),
+		loggerv2.Int("line", at),
+		loggerv2.Int("lines", len(inserted)))
+	return fmt.Sprintf("Inserted %d line(s) before line %d of %s; the file now has %d lines.", len(inserted), at, rawPath, len(file.lines)), nil
+}
+
+func (a *Agent) handleReplaceBetweenMarkers(ctx context.Context, args map[string]interface{}) (string, error) {
+	rawPath, _ := args["path"].(string)
+	path, folder, err := a.editablePath(rawPath)
+	if err != nil {
+		return "", err
+	}
+	startMarker, _ := args["start_marker"].(string)
+	endMarker, _ := args["end_marker"].(string)
+	if startMarker == "" || endMarker == "" {
+		return "", errors.New("start_marker and end_marker are required")
+	}
+	content, ok := args["content"].(string)
+	if !ok {
+		return "", errors.New("content is required")
+	}
+	includeMarkers, _ := args["include_markers"].(bool)
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("cannot read %s: %w", rawPath, err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("cannot read %s: %w", rawPath, err)
+	}
+	text := string(data)
+	switch count := strings.Count(text, startMarker); count {
+	case 0:
+		return "", fmt.Errorf("start marker %q not found in %s", startMarker, rawPath)
+	case 1:
+	default:
+		return "", fmt.Errorf("start marker %q occurs %d times in %s; use a marker that occurs once", startMarker, count, rawPath)
+	}
+	start := strings.Index(text, startMarker) + len(startMarker)
+	end := strings.Index(text[start:], endMarker)
+	if end < 0 {
+		return "", fmt.Errorf("end marker %q not found after the start marker in %s", endMarker, rawPath)
+	}
+	end += start
+
+	var updated string
+	if includeMarkers {
+		updated = text[:start-len(startMarker)] + content + text[end+len(endMarker):]
+	} else {
+		// Keep markers on their own lines when they were
+		region := text[start:end]
+		if strings.HasPrefix(region, "\n") && !strings.HasPrefix(content, "\n") {
+			content = "\n" + content
+		}
+		if strings.HasSuffix(region, "\n") && !strings.HasSuffix(content, "\n") {
+			content += "\n"
+		}
+		updated = text[:start] + content + text[end:]
+	}
+	if err := os.WriteFile(path, []byte(updated), info.Mode().Perm()); err != nil {
+		return "", fmt.Errorf("cannot write %s: %w", rawPath, err)
+	}
+
+	a.emitWorkspaceEdit(ctx, "update", path, folder)
+	getLogger(a).Info("✏️ [WORKSPACE_EDIT] Replaced text between markers",
+		loggerv2.String("path", path),
+		loggerv2.String("start_marker", startMarker))
+	return fmt.Sprintf("Replaced the text between %q and %q in %s.", startMarker, endMarker, rawPath), nil
+}
+
+// filePatch is the part of a unified diff for one file
+type filePatch struct {
+	oldPath, newPath string // "" for /dev/null
+	hunks            []patchHunk
+}
+
+// patchHunk is one "@@" hunk of a unified diff
+type patchHunk struct {
+	oldStart int
+	old, new []string
+	header   string
+}
+
+var hunkHeaderPattern = regexp.MustCompile(`^@@ -(\d+)(?:,\d+)? \+\d+(?:,\d+)? @@`)
+
+// parseUnifiedDiff parses a unified diff into per-file patches. Hunks run
+// until the next hunk or file header; their line counts are not enforced, as
+// LLM-written diffs often get them wrong.
+func parseUnifiedDiff(patch string) ([]filePatch, error) {
+	lines := strings.Split(strings.ReplaceAll(patch, "\r\n", "\n"), "\n")
+	var files []filePatch
+	var hunk *patchHunk
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		if strings.HasPrefix(line, "--- ") && i+1 < len(lines) && strings.HasPrefix(lines[i+1], "+++ ") {
+			files = append(files, filePatch{oldPath: diffPath(line[4:], "a/"), newPath: diffPath(lines[i+1][4:], "b/")})
+			hunk = nil
+			i++
+			continue
+		}
+		if strings.HasPrefix(line, "@@") {
+			if len(files) == 0 {
+				return nil, errors.New("hunk before the first '--- a/<path>' / '+++ b/<path>' file header")
+			}
+			match := hunkHeaderPattern.FindStringSubmatch(line)
+			if match == nil {
+				return nil, fmt.Errorf("malformed hunk header %q: expected '@@ -<line>,<count> +<line>,<count> @@'", line)
+			}
+			oldStart, _ := strconv.Atoi(match[1])
+			current := &files[len(files)-1]
+			current.hunks = append(current.hunks, patchHunk{oldStart: oldStart, header: match[0]})
+			hunk = &current.hunks[len(current.hunks)-1]
+			continue
+		}
+		if hunk == nil {
+			// "diff --git", "index ..." and other preamble lines
+			continue
+		}
+		switch {
+		case strings.HasPrefix(line, "\\"):
+			// "\ No newline at end of file"
+		case strings.HasPrefix(line, "+"):
+			hunk.new = append(hunk.new, line[1:])
+		case strings.HasPrefix(line, "-"):
+			hunk.old = append(hunk.old, line[1:])
+		case strings.HasPrefix(line, " "):
+			hunk.old = append(hunk.old, line[1:])
+			hunk.new = append(hunk.new, line[1:])
+		case line == "" && i < len(lines)-1:
+			// Editors strip the space of empty context lines
+			hunk.old = append(hunk.old, "")
+			hunk.new = append(hunk.new, "")
+		}
+	}
+	if len(files) == 0 {
+		return nil, errors.New("no file headers found: the patch must be a unified diff starting with '--- a/<path>' and '+++ b/<path>' lines")
+	}
+	for _, file := range files {
+		if file.oldPath == "" && file.newPath == "" {
+			return nil, errors.New("a file header has /dev/null on both sides")
+		}
+		if len(file.hunks) == 0 && file.oldPath == file.newPath {
+			return nil, fmt.Errorf("no hunks for %s", file.newPath)
+		}
+	}
+	return files, nil
+}
+
+// diffPath returns the path in a diff file header line, without timestamp
+// and without git's a/ or b/ prefix; "" for /dev/null
+func diffPath(header, prefix string) string {
+	path := strings.TrimSpace(header)
+	if i := strings.Index(path, "\t"); i >= 0 {
+		path = path[:i]
+	}
+	if path == "/dev/null" {
+		return ""
+	}
+	return strings.TrimPrefix(path, prefix)
+}
+
+// applyHunks applies hunks to lines, returning the new lines or an error
+// naming the first hunk that does not match
+func applyHunks(lines []string, hunks []patchHunk, name string) ([]string, error) {
+	result := append([]string(nil), lines...)
+	offset := 0 // lines added minus lines removed by earlier hunks
+	floor := 0  // earlier hunks' ends; later hunks must not overlap them
+	for i, hunk := range hunks {
+		want := hunk.oldStart - 1 + offset
+		if len(hunk.old) == 0 {
+			// Pure insertion: "@@ -N,0" inserts after line N
+			want = hunk.oldStart + offset
+		}
+		at := findHunk(result, hunk.old, want, floor)
+		if at < 0 {
+			return nil, fmt.Errorf("hunk %d (%s) does not match the current content of %s: its context and removed lines were not found. Read the file again and regenerate the patch",
+				i+1, hunk.header, name)
+		}
+		updated := make([]string, 0, len(result)-len(hunk.old)+len(hunk.new))
+		updated = append(updated, result[:at]...)
+		updated = append(updated, hunk.new...)
+		updated = append(updated, result[at+len(hunk.old):]...)
+		result = updated
+		offset += len(hunk.new) - len(hunk.old)
+		floor = at + len(hunk.new)
+	}
+	return result, nil
+}
+
+// findHunk returns the position at or after floor where old matches lines,
+// closest to want; lines are compared exactly first, then ignoring trailing
+// whitespace. -1 when old is not found.
+func findHunk(lines, old []string, want, floor int) int {
+	if want < floor {
+		want = floor
+	}
+	if want > len(lines) {
+		want = len(lines)
+	}
+	if len(old) == 0 {
+		return want
+	}
+	for _, equal := range []func(a, b string) bool{
+		func(a, b string) bool { return a == b },
+		func(a, b string) bool { return strings.TrimRight(a, " \t") == strings.TrimRight(b, " \t") },
+	} {
+		matches := func(at int) bool {
+			if at < floor || at+len(old) > len(lines) {
+				return false
+			}
+			for j, line := range old {
+				if !equal(lines[at+j], line) {
+					return false
+				}
+			}
+			return true
+		}
+		for distance := 0; distance <= len(lines); distance++ {
+			if matches(want - distance) {
+				return want - distance
+			}
+			if distance > 0 && matches(want+distance) {
+				return want + distance
+			}
+		}
+	}
+	return -1
+}
+
+// patchedFile is a file change computed by apply_patch, written once every
+// file's hunks applied
+type patchedFile struct {
+	operation    string // "patch", "move" or "delete"
+	path, folder string // resolved target
+	rawPath      string
+	removePath   string // old path of a renamed file
+	file         *textFile
+	original     string // content before the patch, restored on rollback
+	tempPath     string // new content staged next to path
+}
+
+func (a *Agent) handleApplyPatch(ctx context.Context, args map[string]interface{}) (string, error) {
+	patch, _ := args["patch"].(string)
+	if strings.TrimSpace(patch) == "" {
+		return "", errors.New("patch is required")
+	}
+	filePatches, err := parseUnifiedDiff(patch)
+	if err != nil {
+		return "", fmt.Errorf("invalid patch: %w", err)
+	}
+
+	// Apply every hunk in memory first so a failing hunk changes nothing
+	changes := make([]*patchedFile, 0, len(filePatches))
+	touched := make(map[string]string)
+	for _, fp := range filePatches {
+		for _, path := range []string{fp.oldPath, fp.newPath} {
+			if path == "" {
+				continue
+			}
+			if err := a.checkToolPathPermission("apply_patch", path); err != nil {
+				return "", err
+			}
+		}
+		change, err := a.preparePatchedFile(fp)
+		if err != nil {
+			return "", err
+		}
+		for _, path := range []string{change.path, change.removePath} {
+			if path == "" {
+				continue
+			}
+			if previous, ok := touched[path]; ok {
+				return "", fmt.Errorf("invalid patch: %s is changed more than once (also as %s); combine its changes into one file diff", change.rawPath, previous)
+			}
+			touched[path] = change.rawPath
+		}
+		changes = append(changes, change)
+	}
+
+	// Stage the new contents next to their targets, then move them into
+	// place; a failing write restores the files already changed
+	defer func() {
+		for _, change := range changes {
+			if change.tempPath != "" {
+				_ = os.Remove(change.tempPath)
+			}
+		}
+	}()
+	for _, change := range changes {
+		if err := change.stage(); err != nil {
+			return "", fmt.Errorf("cannot write %s: %w", change.rawPath, err)
+		}
+	}
+	for i, change := range changes {
+		if err := change.commit(); err != nil {
+			for j := i - 1; j >= 0; j-- {
+				if rollbackErr := changes[j].rollback(); rollbackErr != nil {
+					getLogger(a).Error("Failed to roll back patched file", rollbackErr,
+						loggerv2.String("path", changes[j].path))
+				}
+			}
+			return "", fmt.Errorf("cannot write %s, no file was changed: %w", change.rawPath, err)
+		}
+	}
+
+	summary := make([]string, 0, len(changes))
+	for _, change := range changes {
+		switch {
+		case change.operation == "delete":
+			summary = append(summary, "deleted "+change.rawPath)
+		case change.removePath != "":
+			summary = append(summary, "renamed to "+change.rawPath)
+		case change.file.created:
+			summary = append(summary, "created "+change.rawPath)
+		default:
+			summary = append(summary, "patched "+change.rawPath)
+		}
+		a.emitWorkspaceEdit(ctx, change.operation, change.path, change.folder)
+	}
+
+	getLogger(a).Info("✏️ [WORKSPACE_EDIT] Applied patch",
+		loggerv2.Int("files", len(changes)),
+		loggerv2.Any("changes", summary))
+	return fmt.Sprintf("Patch applied: %s.", strings.Join(summary, ", ")), nil
+}
+
+// stage writes the new content to a temporary file in the target's folder
+func (c *patchedFile) stage() error {
+	if c.operation == "delete" {
+		return nil
+	}
+	dir := filepath.Dir(c.path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(c.path)+".*.patch")
+	if err != nil {
+		return err
+	}
+	c.tempPath = tmp.Name()
+	if _, err := tmp.WriteString(c.file.String()); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Chmod(c.tempPath, c.mode())
+}
+
+// commit moves the staged content into place. A rename whose old file
+// cannot be removed is undone.
+func (c *patchedFile) commit() error {
+	if c.operation == "delete" {
+		return os.Remove(c.path)
+	}
+	if err := os.Rename(c.tempPath, c.path); err != nil {
+		return err
+	}
+	c.tempPath = ""
+	if c.removePath != "" {
+		if err := os.Remove(c.removePath); err != nil {
+			_ = os.Remove(c.path)
+			return err
+		}
+	}
+	return nil
+}
+
+// rollback restores the files a committed change touched
+func (c *patchedFile) rollback() error {
+	switch {
+	case c.operation == "delete":
+		return os.WriteFile(c.path, []byte(c.original), c.mode())
+	case c.removePath != "":
+		if err := os.WriteFile(c.removePath, []byte(c.original), c.mode()); err != nil {
+			return err
+		}
+		return os.Remove(c.path)
+	case c.file.created:
+		return os.Remove(c.path)
+	default:
+		return os.WriteFile(c.path, []byte(c.original), c.mode())
+	}
+}
+
+func (c *patchedFile) mode() os.FileMode {
+	if c.file == nil || c.file.mode == 0 {
+		return 0o644
+	}
+	return c.file.mode
+}
+
+// preparePatchedFile checks fp's paths against the write paths and applies
+// its hunks to the current content, without writing
+func (a *Agent) preparePatchedFile(fp filePatch) (*patchedFile, error) {
+	sourcePath := fp.oldPath
+	if sourcePath == "" {
+		sourcePath = fp.newPath
+	}
+	source, folder, err := a.editablePath(sourcePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var file *textFile
+	if fp.oldPath == "" {
+		if _, err := os.Stat(source); err == nil {
+			return nil, fmt.Errorf("cannot create %s: the file already exists; patch it against its current content instead", fp.newPath)
+		}
+		file = &textFile{trailingNewline: true, created: true}
+	} else if file, err = readTextFile(source); err != nil {
+		return nil, fmt.Errorf("cannot read %s: %w", fp.oldPath, err)
+	}
+	lines, err := applyHunks(file.lines, fp.hunks, sourcePath)
+	if err != nil {
+		return nil, err
+	}
+	original := file.String()
+	file.lines = lines
+
+	switch {
+	case fp.newPath == "":
+		if len(lines) != 0 {
+			return nil, fmt.Errorf("cannot delete %s: the patch leaves %d lines in it", fp.oldPath, len(lines))
+		}
+		return &patchedFile{operation: "delete", path: source, folder: folder, rawPath: fp.oldPath, file: file, original: original}, nil
+	case fp.oldPath != "" && fp.newPath != fp.oldPath:
+		target, targetFolder, err := a.editablePath(fp.newPath)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := os.Stat(target); err == nil {
+			return nil, fmt.Errorf("cannot rename %s to %s: the target already exists", fp.oldPath, fp.newPath)
+		}
+		return &patchedFile{operation: "move", path: target, folder: targetFolder, rawPath: fp.newPath, removePath: source, file: file, original: original}, nil
+	default:
+		return &patchedFile{operation: "patch", path: source, folder: folder, rawPath: fp.newPath, file: file, original: original}, nil
+	}
+}
@@ -0,0 +1,162 @@
+package mcpagent
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/manishiitg/mcpagent/events"
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+func TestWorkspaceEditToolsFollowWritePaths(t *testing.T) {
+	a := &Agent{Logger: loggerv2.NewNoop(), Tools: []llmtypes.Tool{hintTestTool("read_file")}}
+	if names := hintToolNames(a.Tools); len(names) != 1 {
+		t.Fatalf("tools = %v", names)
+	}
+	a.SetFolderGuardPaths([]string{"/data/docs"}, []string{"/data/out"})
+	if names := strings.Join(hintToolNames(a.Tools), ","); names != "read_file,apply_patch,insert_at_line,replace_between_markers" {
+		t.Errorf("tools with write paths = %s", names)
+	}
+	a.SetFolderGuardPaths([]string{"/data/docs"}, nil)
+	if names := hintToolNames(a.Tools); len(names) != 1 {
+		t.Errorf("edit tools offered without write paths: %v", names)
+	}
+}
+
+func TestWorkspaceEditToolsEditFilesInsideWritePaths(t *testing.T) {
+	root := t.TempDir()
+	writeDir := filepath.Join(root, "out")
+	if err := os.Mkdir(writeDir, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	mainGo := filepath.Join(writeDir, "main.go")
+	if err := os.WriteFile(mainGo, []byte("package main\n\nimport \"fmt\"\n\nfunc main() {\n\tfmt.Println(\"hi\")\n}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	listener := &recordingAgentEventListener{}
+	a := &Agent{Logger: loggerv2.NewNoop(), listeners: []AgentEventListener{listener}}
+	a.SetFolderGuardPaths(nil, []string{writeDir})
+	ctx := context.WithValue(context.Background(), ToolExecutionTurnKey, 2)
+	read := func(path string) string {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+
+	// The header line numbers are off by one; the hunk is found by its context
+	patch := "--- a/main.go\n+++ b/main.go\n@@ -6,3 +6,4 @@\n func main() {\n-\tfmt.Println(\"hi\")\n+\tfmt.Println(\"hello\")\n+\tfmt.Println(\"bye\")\n }\n" +
+		"--- /dev/null\n+++ b/notes/todo.md\n@@ -0,0 +1,2 @@\n+# TODO\n+- ship\n"
+	if _, err := a.HandleVirtualTool(ctx, "apply_patch", map[string]interface{}{"patch": patch}); err != nil {
+		t.Fatalf("apply_patch: %v", err)
+	}
+	if got := read(mainGo); got != "package main\n\nimport \"fmt\"\n\nfunc main() {\n\tfmt.Println(\"hello\")\n\tfmt.Println(\"bye\")\n}\n" {
+		t.Errorf("patched main.go = %q", got)
+	}
+	if got := read(filepath.Join(writeDir, "notes", "todo.md")); got != "# TODO\n- ship\n" {
+		t.Errorf("created todo.md = %q", got)
+	}
+
+	// A hunk that does not match changes no file, not even the ones before it
+	bad := "--- a/main.go\n+++ b/main.go\n@@ -1 +1 @@\n-package main\n+package app\n" +
+		"--- a/notes/todo.md\n+++ b/notes/todo.md\n@@ -1 +1 @@\n-# DONE\n+# Done\n"
+	if _, err := a.HandleVirtualTool(ctx, "apply_patch", map[string]interface{}{"patch": bad}); err == nil || !strings.Contains(err.Error(), "hunk 1") {
+		t.Errorf("mismatching patch error = %v", err)
+	}
+	if !strings.HasPrefix(read(mainGo), "package main\n") {
+		t.Error("failed patch changed main.go")
+	}
+
+	if _, err := a.HandleVirtualTool(ctx, "insert_at_line", map[string]interface{}{"path": "main.go", "line": float64(2), "content": "\n// Greets.\n"}); err != nil {
+		t.Fatalf("insert_at_line: %v", err)
+	}
+	if got := read(mainGo); !strings.HasPrefix(got, "package main\n\n// Greets.\n\nimport") {
+		t.Errorf("main.go after insert = %q", got)
+	}
+
+	config := filepath.Join(writeDir, "config.yaml")
+	if err := os.WriteFile(config, []byte("name: x\n# BEGIN servers\nold: 1\n# END servers\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.HandleVirtualTool(ctx, "replace_between_markers", map[string]interface{}{
+		"path": config, "start_marker": "# BEGIN servers", "end_marker": "# END servers", "content": "github: 1\nslack: 2",
+	}); err != nil {
+		t.Fatalf("replace_between_markers: %v", err)
+	}
+	if got := read(config); got != "name: x\n# BEGIN servers\ngithub: 1\nslack: 2\n# END servers\n" {
+		t.Errorf("config.yaml = %q", got)
+	}
+
+	// Nothing outside the write paths is touched
+	outside := filepath.Join(root, "secret.txt")
+	if err := os.WriteFile(outside, []byte("a\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.HandleVirtualTool(ctx, "insert_at_line", map[string]interface{}{"path": outside, "line": float64(1), "content": "x"}); err == nil || !strings.Contains(err.Error(), "Access denied") {
+		t.Errorf("edit outside the write paths: %v", err)
+	}
+	escape := "--- a/../secret.txt\n+++ b/../secret.txt\n@@ -1 +1 @@\n-a\n+b\n"
+	if _, err := a.HandleVirtualTool(ctx, "apply_patch", map[string]interface{}{"patch": escape}); err == nil || read(outside) != "a\n" {
+		t.Errorf("patch escaping the write paths: %v", err)
+	}
+
+	var operations []string
+	for _, e := range listener.events {
+		if ev, ok := e.Data.(*events.WorkspaceFileOperationEvent); ok {
+			if ev.Folder != writeDir || ev.Turn != 2 {
+				t.Errorf("event %+v", ev)
+			}
+			operations = append(operations, ev.Operation+" "+filepath.Base(ev.Filepath))
+		}
+	}
+	if got := strings.Join(operations, ", "); got != "patch main.go, patch todo.md, update main.go, update config.yaml" {
+		t.Errorf("workspace_file_operation events = %s", got)
+	}
+}
+
+func TestApplyPatchRejectsRepeatedAndOutOfScopePaths(t *testing.T) {
+	writeDir := t.TempDir()
+	for name, content := range map[string]string{"a.txt": "a\n", "src/b.txt": "b\n"} {
+		path := filepath.Join(writeDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	a := &Agent{Logger: loggerv2.NewNoop(), ToolPermissions: map[string]ToolPermission{"apply_patch": {Paths: []string{"src"}}}}
+	a.SetFolderGuardPaths(nil, []string{writeDir})
+	apply := func(patch string) error {
+		_, err := a.HandleVirtualTool(context.Background(), "apply_patch", map[string]interface{}{"patch": patch})
+		return err
+	}
+
+	if err := apply("--- a/a.txt\n+++ b/a.txt\n@@ -1 +1 @@\n-a\n+x\n"); err == nil || !strings.Contains(err.Error(), "not allowed to access") {
+		t.Errorf("patch outside the permission scope: %v", err)
+	}
+	twice := "--- a/src/b.txt\n+++ b/src/b.txt\n@@ -1 +1 @@\n-b\n+c\n" +
+		"--- a/src/b.txt\n+++ b/src/b.txt\n@@ -1 +1 @@\n-b\n+d\n"
+	if err := apply(twice); err == nil || !strings.Contains(err.Error(), "more than once") {
+		t.Errorf("patch changing a file twice: %v", err)
+	}
+	if err := apply("--- a/src/b.txt\n+++ b/src/b.txt\n@@ -1 +1 @@\n-b\n+c\n"); err != nil {
+		t.Fatalf("apply_patch: %v", err)
+	}
+
+	entries, err := os.ReadDir(filepath.Join(writeDir, "src"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Name() != "b.txt" {
+		t.Errorf("src holds %v, want only b.txt", entries)
+	}
+	if data, _ := os.ReadFile(filepath.Join(writeDir, "a.txt")); string(data) != "a\n" {
+		t.Errorf("a.txt = %q", data)
+	}
+}
@@ -0,0 +1,417 @@
+// workspace_git_tools.go
+//
+// Optional git tools for code-generation tasks. They run git against a single
+// workspace folder so the agent can produce a reviewable commit history of
+// the files it generates. Every operation emits a workspace_file_operation
+// event carrying GitOperationMetadata.
+//
+// The agent can write the workspace's .git folder with its file tools, so git
+// runs with hooks and fsmonitor disabled, without system and global config,
+// and refuses repositories whose config makes git run commands (see
+// unsafeGitConfigKey). Otherwise a planted hook or config key would run on the
+// host, outside the folder guard.
+//
+// Exported:
+//   - RegisterWorkspaceGitTools: Register git_init, git_status, git_diff,
+//     git_commit and git_branch as "workspace" custom tools
+
+package mcpagent
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/manishiitg/mcpagent/events"
+)
+
+// Default identity used for commits when the workspace has no git user configured
+const (
+	workspaceGitAuthorName  = "mcpagent"
+	workspaceGitAuthorEmail = "mcpagent@localhost"
+)
+
+// branchNamePattern limits branch names to a safe subset so they can never be
+// interpreted as git options.
+var branchNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._/-]*$`)
+
+// unsafeGitConfigKey matches repository config keys (as listed by git config
+// --name-only, lowercased) that make git run commands: hooks, fsmonitor,
+// pagers and editors, diff, merge and filter drivers, credential helpers and
+// signing programs, plus includes, which could pull in any of them, and
+// core.worktree, which moves the work tree out of the workspace.
+var unsafeGitConfigKey = regexp.MustCompile(`^(core\.(fsmonitor|hookspath|sshcommand|pager|editor|askpass|gitproxy|worktree|alternaterefscommand)` +
+	`|credential\..*|diff\.(external|.*\.(textconv|command))|filter\..*|merge\..*\.driver|gpg\..*|(commit|tag)\.gpgsign` +
+	`|include\..*|includeif\..*|sequence\.editor|pager\..*|interactive\.difffilter|remote\..*\.(uploadpack|receivepack)|uploadpack\.packobjectshook)$`)
+
+// workspaceGit runs git confined to a single workspace directory
+type workspaceGit struct {
+	dir string // absolute workspace path
+}
+
+func newWorkspaceGit(workspaceDir string) (*workspaceGit, error) {
+	if workspaceDir == "" {
+		return nil, fmt.Errorf("workspace directory is required")
+	}
+	abs, err := filepath.Abs(workspaceDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve workspace directory: %w", err)
+	}
+	if err := os.MkdirAll(abs, 0755); err != nil { //nolint:gosec // 0755 permissions are intentional for user-accessible directories
+		return nil, fmt.Errorf("failed to create workspace directory: %w", err)
+	}
+	return &workspaceGit{dir: abs}, nil
+}
+
+// run executes git in the workspace after checking the repository config.
+// GIT_CEILING_DIRECTORIES stops git from discovering a repository above the
+// workspace, so operations never touch files outside it.
+func (g *workspaceGit) run(ctx context.Context, args ...string) (string, error) {
+	if err := g.checkConfig(ctx); err != nil {
+		return "", err
+	}
+	return g.exec(ctx, args...)
+}
+
+// checkConfig refuses repositories whose config sets an unsafeGitConfigKey
+func (g *workspaceGit) checkConfig(ctx context.Context) error {
+	if _, err := os.Lstat(filepath.Join(g.dir, ".git")); os.IsNotExist(err) {
+		return nil // not a repository yet
+	}
+	out, err := g.exec(ctx, "config", "--local", "--list", "--name-only")
+	if err != nil {
+		return err
+	}
+	for _, key := range splitLines(out) {
+		if unsafeGitConfigKey.MatchString(strings.ToLower(key)) {
+			return fmt.Errorf("git is disabled for this workspace: its repository config sets %s, which can run commands; remove it to use the git tools", key)
+		}
+	}
+	return nil
+}
+
+// exec runs git with hooks and fsmonitor disabled and without system and
+// global config, whatever the repository config says
+func (g *workspaceGit) exec(ctx context.Context, args ...string) (string, error) {
+	gitArgs := append([]string{"-c", "core.hooksPath=" + os.DevNull, "-c", "core.fsmonitor=false"}, args...)
+	cmd := exec.CommandContext(ctx, "git", gitArgs...) //nolint:gosec // G204: args are built from validated tool parameters
+	cmd.Dir = g.dir
+	cmd.Env = append(os.Environ(),
+		"GIT_CEILING_DIRECTORIES="+filepath.Dir(g.dir),
+		"GIT_TERMINAL_PROMPT=0",
+		"GIT_CONFIG_NOSYSTEM=1",
+		"GIT_CONFIG_GLOBAL="+os.DevNull,
+	)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return "", fmt.Errorf("git %s failed: %s", args[0], msg)
+	}
+	return stdout.String(), nil
+}
+
+// relPath validates that path stays inside the workspace and returns it
+// relative to the workspace root.
+func (g *workspaceGit) relPath(path string) (string, error) {
+	if path == "" {
+		return "", nil
+	}
+	full := path
+	if !filepath.IsAbs(full) {
+		full = filepath.Join(g.dir, path)
+	}
+	rel, err := filepath.Rel(g.dir, filepath.Clean(full))
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("path %q is outside the workspace", path)
+	}
+	return rel, nil
+}
+
+func (g *workspaceGit) currentBranch(ctx context.Context) string {
+	out, err := g.run(ctx, "rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil {
+		// Fresh repository without commits: fall back to the symbolic ref
+		out, err = g.run(ctx, "symbolic-ref", "--short", "HEAD")
+		if err != nil {
+			return ""
+		}
+	}
+	return strings.TrimSpace(out)
+}
+
+func (g *workspaceGit) init(ctx context.Context) (string, *events.GitOperationMetadata, error) {
+	out, err := g.run(ctx, "init")
+	if err != nil {
+		return "", nil, err
+	}
+	return strings.TrimSpace(out), &events.GitOperationMetadata{Command: "init", Branch: g.currentBranch(ctx)}, nil
+}
+
+func (g *workspaceGit) status(ctx context.Context) (string, *events.GitOperationMetadata, error) {
+	out, err := g.run(ctx, "status", "--porcelain=v1", "--untracked-files=all")
+	if err != nil {
+		return "", nil, err
+	}
+	var files []string
+	for _, line := range strings.Split(strings.TrimRight(out, "\n"), "\n") {
+		if len(line) > 3 {
+			files = append(files, line[3:])
+		}
+	}
+	meta := &events.GitOperationMetadata{Command: "status", Branch: g.currentBranch(ctx), ChangedFiles: files}
+	if len(files) == 0 {
+		return fmt.Sprintf("On branch %s, nothing to commit, working tree clean", meta.Branch), meta, nil
+	}
+	return fmt.Sprintf("On branch %s\n%s", meta.Branch, out), meta, nil
+}
+
+func (g *workspaceGit) diff(ctx context.Context, path string, staged bool) (string, *events.GitOperationMetadata, error) {
+	rel, err := g.relPath(path)
+	if err != nil {
+		return "", nil, err
+	}
+	args := []string{"diff"}
+	if staged {
+		args = append(args, "--cached")
+	}
+	nameArgs := append(append([]string{}, args...), "--name-only")
+	args = append(args, "--")
+	nameArgs = append(nameArgs, "--")
+	if rel != "" {
+		args = append(args, rel)
+		nameArgs = append(nameArgs, rel)
+	}
+
+	out, err := g.run(ctx, args...)
+	if err != nil {
+		return "", nil, err
+	}
+	names, _ := g.run(ctx, nameArgs...)
+	meta := &events.GitOperationMetadata{Command: "diff", Branch: g.currentBranch(ctx), ChangedFiles: splitLines(names)}
+	if out == "" {
+		return "No changes", meta, nil
+	}
+	return out, meta, nil
+}
+
+func (g *workspaceGit) commit(ctx context.Context, message string, paths []string) (string, *events.GitOperationMetadata, error) {
+	if strings.TrimSpace(message) == "" {
+		return "", nil, fmt.Errorf("commit message is required")
+	}
+
+	addArgs := []string{"add", "-A", "--"}
+	for _, p := range paths {
+		rel, err := g.relPath(p)
+		if err != nil {
+			return "", nil, err
+		}
+		addArgs = append(addArgs, rel)
+	}
+	if _, err := g.run(ctx, addArgs...); err != nil {
+		return "", nil, err
+	}
+
+	staged, err := g.run(ctx, "diff", "--cached", "--name-only")
+	if err != nil {
+		return "", nil, err
+	}
+	files := splitLines(staged)
+	if len(files) == 0 {
+		return "", nil, fmt.Errorf("nothing to commit")
+	}
+
+	var commitArgs []string
+	if email, _ := g.run(ctx, "config", "user.email"); strings.TrimSpace(email) == "" {
+		commitArgs = append(commitArgs, "-c", "user.name="+workspaceGitAuthorName, "-c", "user.email="+workspaceGitAuthorEmail)
+	}
+	commitArgs = append(commitArgs, "commit", "-m", message)
+	if _, err := g.run(ctx, commitArgs...); err != nil {
+		return "", nil, err
+	}
+
+	hash, err := g.run(ctx, "rev-parse", "--short", "HEAD")
+	if err != nil {
+		return "", nil, err
+	}
+	meta := &events.GitOperationMetadata{
+		Command:      "commit",
+		Branch:       g.currentBranch(ctx),
+		CommitHash:   strings.TrimSpace(hash),
+		Message:      message,
+		ChangedFiles: files,
+	}
+	return fmt.Sprintf("Committed %d file(s) on %s as %s", len(files), meta.Branch, meta.CommitHash), meta, nil
+}
+
+func (g *workspaceGit) branch(ctx context.Context, name string, checkout bool) (string, *events.GitOperationMetadata, error) {
+	if name == "" {
+		out, err := g.run(ctx, "branch", "--list")
+		if err != nil {
+			return "", nil, err
+		}
+		return out, &events.GitOperationMetadata{Command: "branch", Branch: g.currentBranch(ctx)}, nil
+	}
+
+	if !branchNamePattern.MatchString(name) || strings.Contains(name, "..") {
+		return "", nil, fmt.Errorf("invalid branch name %q", name)
+	}
+	args := []string{"branch", name}
+	if checkout {
+		args = []string{"checkout", "-b", name}
+	}
+	if _, err := g.run(ctx, args...); err != nil {
+		return "", nil, err
+	}
+	meta := &events.GitOperationMetadata{Command: "branch", Branch: g.currentBranch(ctx)}
+	if checkout {
+		return fmt.Sprintf("Created and switched to branch %s", name), meta, nil
+	}
+	return fmt.Sprintf("Created branch %s", name), meta, nil
+}
+
+func splitLines(s string) []string {
+	var lines []string
+	for _, line := range strings.Split(strings.TrimSpace(s), "\n") {
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// RegisterWorkspaceGitTools registers git tools confined to workspaceDir as
+// custom tools in the "workspace" category: git_init, git_status, git_diff,
+// git_commit and git_branch. Paths passed to the tools must resolve inside
+// the workspace, and git never discovers repositories above it.
+//
+// Example usage:
+//
+//	if err := agent.RegisterWorkspaceGitTools("/data/workspaces/job-42"); err != nil {
+//	    return err
+//	}
+func (a *Agent) RegisterWorkspaceGitTools(workspaceDir string) error {
+	g, err := newWorkspaceGit(workspaceDir)
+	if err != nil {
+		return err
+	}
+
+	type gitOp func(ctx context.Context, args map[string]interface{}) (string, *events.GitOperationMetadata, error)
+
+	// wrap emits a workspace_file_operation event for every successful git operation
+	wrap := func(op gitOp) func(ctx context.Context, args map[string]interface{}) (string, error) {
+		return func(ctx context.Context, args map[string]interface{}) (string, error) {
+			result, meta, err := op(ctx, args)
+			if err != nil {
+				return "", err
+			}
+			turn, _ := ctx.Value(ToolExecutionTurnKey).(int)
+			serverName, _ := ctx.Value(ToolExecutionServerKey).(string)
+			path, _ := args["path"].(string)
+			event := events.NewWorkspaceFileOperationEvent("git_"+meta.Command, path, g.dir, turn, serverName, meta.Command == "commit")
+			event.Git = meta
+			a.EmitTypedEvent(ctx, event)
+			return result, nil
+		}
+	}
+
+	tools := []struct {
+		name        string
+		description string
+		params      map[string]interface{}
+		op          gitOp
+	}{
+		{
+			name:        "git_init",
+			description: "Initialize a git repository in the workspace folder. Safe to call on an existing repository.",
+			params:      map[string]interface{}{"type": "object", "properties": map[string]interface{}{}},
+			op: func(ctx context.Context, _ map[string]interface{}) (string, *events.GitOperationMetadata, error) {
+				return g.init(ctx)
+			},
+		},
+		{
+			name:        "git_status",
+			description: "Show the current branch and the files changed in the workspace since the last commit.",
+			params:      map[string]interface{}{"type": "object", "properties": map[string]interface{}{}},
+			op: func(ctx context.Context, _ map[string]interface{}) (string, *events.GitOperationMetadata, error) {
+				return g.status(ctx)
+			},
+		},
+		{
+			name:        "git_diff",
+			description: "Show uncommitted changes in the workspace as a unified diff.",
+			params: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"path":   map[string]interface{}{"type": "string", "description": "Optional file or folder (relative to the workspace) to limit the diff to"},
+					"staged": map[string]interface{}{"type": "boolean", "description": "Show staged changes instead of unstaged ones"},
+				},
+			},
+			op: func(ctx context.Context, args map[string]interface{}) (string, *events.GitOperationMetadata, error) {
+				path, _ := args["path"].(string)
+				staged, _ := args["staged"].(bool)
+				return g.diff(ctx, path, staged)
+			},
+		},
+		{
+			name:        "git_commit",
+			description: "Stage changes and create a commit in the workspace repository. Stages all changes unless paths are given.",
+			params: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"message": map[string]interface{}{"type": "string", "description": "Commit message describing the change"},
+					"paths": map[string]interface{}{
+						"type":        "array",
+						"items":       map[string]interface{}{"type": "string"},
+						"description": "Optional files (relative to the workspace) to include in the commit",
+					},
+				},
+				"required": []string{"message"},
+			},
+			op: func(ctx context.Context, args map[string]interface{}) (string, *events.GitOperationMetadata, error) {
+				message, _ := args["message"].(string)
+				var paths []string
+				if raw, ok := args["paths"].([]interface{}); ok {
+					for _, p := range raw {
+						if s, ok := p.(string); ok && s != "" {
+							paths = append(paths, s)
+						}
+					}
+				}
+				return g.commit(ctx, message, paths)
+			},
+		},
+		{
+			name:        "git_branch",
+			description: "List branches, or create a new branch (optionally switching to it) in the workspace repository.",
+			params: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"name":     map[string]interface{}{"type": "string", "description": "Branch to create. Omit to list branches."},
+					"checkout": map[string]interface{}{"type": "boolean", "description": "Switch to the new branch after creating it"},
+				},
+			},
+			op: func(ctx context.Context, args map[string]interface{}) (string, *events.GitOperationMetadata, error) {
+				name, _ := args["name"].(string)
+				checkout, _ := args["checkout"].(bool)
+				return g.branch(ctx, name, checkout)
+			},
+		},
+	}
+
+	for _, t := range tools {
+		if err := a.RegisterCustomTool(t.name, t.description, t.params, wrap(t.op), "workspace"); err != nil {
+			return fmt.Errorf("failed to register %s: %w", t.name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package mcpagent
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWorkspaceGitLifecycle(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	ctx := context.Background()
+	g, err := newWorkspaceGit(filepath.Join(t.TempDir(), "ws"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, meta, err := g.init(ctx); err != nil || meta.Command != "init" {
+		t.Fatalf("init: meta=%+v err=%v", meta, err)
+	}
+	if err := os.WriteFile(filepath.Join(g.dir, "main.go"), []byte("package main\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	_, meta, err := g.status(ctx)
+	if err != nil || len(meta.ChangedFiles) != 1 || meta.ChangedFiles[0] != "main.go" {
+		t.Fatalf("status: meta=%+v err=%v", meta, err)
+	}
+
+	_, meta, err = g.commit(ctx, "Add main.go", nil)
+	if err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+	if meta.CommitHash == "" || meta.Message != "Add main.go" || len(meta.ChangedFiles) != 1 {
+		t.Errorf("unexpected commit metadata: %+v", meta)
+	}
+	if _, _, err := g.commit(ctx, "empty", nil); err == nil {
+		t.Error("commit with no changes should fail")
+	}
+
+	if _, meta, err = g.branch(ctx, "feature/x", true); err != nil || meta.Branch != "feature/x" {
+		t.Fatalf("branch: meta=%+v err=%v", meta, err)
+	}
+	if _, _, err := g.branch(ctx, "-D", false); err == nil {
+		t.Error("branch names starting with '-' should be rejected")
+	}
+}
+
+func TestWorkspaceGitRejectsPathsOutsideWorkspace(t *testing.T) {
+	g := &workspaceGit{dir: filepath.Join(t.TempDir(), "ws")}
+	for _, p := range []string{"../secret", "/etc/passwd", "a/../../b"} {
+		if _, err := g.relPath(p); err == nil {
+			t.Errorf("relPath(%q) = nil error, want rejection", p)
+		}
+	}
+	if rel, err := g.relPath("src/../main.go"); err != nil || rel != "main.go" {
+		t.Errorf("relPath(src/../main.go) = %q, %v", rel, err)
+	}
+}
+
+func TestWorkspaceGitIgnoresPlantedHooksAndConfig(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	ctx := context.Background()
+	g, err := newWorkspaceGit(filepath.Join(t.TempDir(), "ws"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := g.init(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// The agent can write .git with its file tools; a planted hook must not run
+	marker := filepath.Join(t.TempDir(), "hook-ran")
+	hook := "#!/bin/sh\ntouch " + marker + "\n"
+	if err := os.WriteFile(filepath.Join(g.dir, ".git", "hooks", "pre-commit"), []byte(hook), 0o755); err != nil { //nolint:gosec // the hook must be executable
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(g.dir, "main.go"), []byte("package main\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := g.commit(ctx, "Add main.go", nil); err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+	if _, err := os.Stat(marker); !os.IsNotExist(err) {
+		t.Fatal("the pre-commit hook ran")
+	}
+
+	// Config keys that run commands disable the tools
+	config, err := os.OpenFile(filepath.Join(g.dir, ".git", "config"), os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = config.WriteString("[core]\n\tfsmonitor = touch " + marker + "\n")
+	_ = config.Close()
+	if _, _, err := g.status(ctx); err == nil || !strings.Contains(err.Error(), "core.fsmonitor") {
+		t.Errorf("status with core.fsmonitor set = %v, want refusal", err)
+	}
+	if _, err := os.Stat(marker); !os.IsNotExist(err) {
+		t.Fatal("core.fsmonitor ran")
+	}
+}
@@ -3,9 +3,18 @@
 // call to the appropriate per-tool HTTP endpoint with bearer token authentication.
 //
 // This binary is launched by Claude Code via --mcp-config as a subprocess.
+//
+// Endpoints answer with a single JSON result, or stream it: a response with
+// Content-Type application/x-ndjson carries one JSON object per line, either a
+// chunk ({"chunk": "...", "progress": 3, "total": 10}) or the final result
+// ({"success": true, "result": "..."}). Chunks are forwarded to the client as
+// MCP progress notifications when the call has a progress token; the tool
+// result is the final result, or the concatenated chunks if there is none.
 package main
 
 import (
+	"bufio"
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
@@ -29,6 +38,53 @@ type ToolDef struct {
 	InputSchema json.RawMessage `json:"input_schema"`
 	Server      string          `json:"server"` // MCP server name (empty for custom/virtual)
 	Type        string          `json:"type"`   // "mcp", "custom", or "virtual"
+	// TimeoutMs overrides the HTTP timeout of this tool's calls: > 0 is the
+	// timeout in milliseconds, < 0 means no timeout, 0 (omitted) keeps the
+	// default (longer for long-running delegation tools)
+	TimeoutMs int64 `json:"timeout_ms,omitempty"`
+}
+
+// ndjsonContentType is the Content-Type of streamed tool responses
+const ndjsonContentType = "application/x-ndjson"
+
+// maxStreamLineBytes bounds one line of a streamed response
+const maxStreamLineBytes = 16 * 1024 * 1024
+
+// maxStreamResponseBytes bounds a whole streamed response
+const maxStreamResponseBytes = 64 * 1024 * 1024
+
+// bridgeResponse is an endpoint's tool result, and the final line of a
+// streamed response
+type bridgeResponse struct {
+	Success bool   `json:"success"`
+	Result  string `json:"result"`
+	Error   string `json:"error"`
+}
+
+// streamLine is one line of a streamed response: a chunk, or the final
+// result when Success is set
+type streamLine struct {
+	Chunk    *string  `json:"chunk"`
+	Progress *float64 `json:"progress"`
+	Total    *float64 `json:"total"`
+	Success  *bool    `json:"success"`
+	Result   string   `json:"result"`
+	Error    string   `json:"error"`
+}
+
+// httpClientFor returns the HTTP client for def's calls: its own timeout if
+// set, else the long-running or default client
+func httpClientFor(def ToolDef, defaultClient, longRunningClient *http.Client) *http.Client {
+	switch {
+	case def.TimeoutMs > 0:
+		return &http.Client{Timeout: time.Duration(def.TimeoutMs) * time.Millisecond}
+	case def.TimeoutMs < 0:
+		return &http.Client{}
+	case isLongRunningDelegationTool(def.Type, def.Name):
+		return longRunningClient
+	default:
+		return defaultClient
+	}
 }
 
 func isLongRunningDelegationTool(toolType, toolName string) bool {
@@ -63,6 +119,113 @@ func bridgeRequestError(toolType, toolName, sessionID string, timeout time.Durat
 	}
 }
 
+// toolResultText turns an endpoint's result into the text returned to the LLM
+func toolResultText(result bridgeResponse) string {
+	if !result.Success {
+		// Return error as regular text (not IsError) so the LLM always sees the
+		// actual error details. Some CLI providers (for example Claude Code) show
+		// only a generic "MCP tool reported an error" when IsError=true, hiding
+		// the actual error content from the LLM.
+		errorMsg := result.Error
+		if errorMsg == "" {
+			errorMsg = "unknown error (no details in response)"
+		}
+		return fmt.Sprintf("ERROR: %s", truncateBridgeErrorText(errorMsg))
+	}
+	return result.Result
+}
+
+// readStreamedResponse reads a newline-delimited streamed response, calling
+// onChunk for every chunk, and returns the tool result text. Lines that are
+// not chunk or result objects are treated as chunks of text, newline included.
+// A line longer than maxStreamLineBytes or a response longer than
+// maxStreamResponseBytes fails the call without reading the rest.
+func readStreamedResponse(body io.Reader, onChunk func(chunk string, progress float64, total *float64)) (string, error) {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxStreamLineBytes)
+	scanner.Split(scanLinesWithNewline)
+	var chunks strings.Builder
+	var final *bridgeResponse
+	count, read := 0, 0
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if read += len(line); read > maxStreamResponseBytes {
+			return "", fmt.Errorf("streamed response exceeds %d bytes", maxStreamResponseBytes)
+		}
+		if trimmed := bytes.TrimSpace(line); len(trimmed) > 0 {
+			var parsed streamLine
+			if json.Unmarshal(trimmed, &parsed) != nil || (parsed.Chunk == nil && parsed.Success == nil) {
+				text := string(line)
+				parsed = streamLine{Chunk: &text}
+			}
+			if parsed.Chunk != nil {
+				count++
+				progress := float64(count)
+				if parsed.Progress != nil {
+					progress = *parsed.Progress
+				}
+				chunks.WriteString(*parsed.Chunk)
+				if onChunk != nil {
+					onChunk(*parsed.Chunk, progress, parsed.Total)
+				}
+			} else {
+				final = &bridgeResponse{Success: *parsed.Success, Result: parsed.Result, Error: parsed.Error}
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return "", fmt.Errorf("streamed line exceeds %d bytes", maxStreamLineBytes)
+		}
+		return "", err
+	}
+	if final == nil {
+		return chunks.String(), nil
+	}
+	if final.Success && final.Result == "" {
+		final.Result = chunks.String()
+	}
+	return toolResultText(*final), nil
+}
+
+// scanLinesWithNewline is bufio.ScanLines keeping each line's newline, so
+// plain text lines are forwarded as written
+func scanLinesWithNewline(data []byte, atEOF bool) (int, []byte, error) {
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		return i + 1, data[:i+1], nil
+	}
+	if atEOF && len(data) > 0 {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
+
+// progressNotifier returns an onChunk callback forwarding chunks as MCP
+// progress notifications, or nil when the call has no progress token
+func progressNotifier(ctx context.Context, req mcp.CallToolRequest) func(chunk string, progress float64, total *float64) {
+	if req.Params.Meta == nil || req.Params.Meta.ProgressToken == nil {
+		return nil
+	}
+	mcpServer := server.ServerFromContext(ctx)
+	if mcpServer == nil {
+		return nil
+	}
+	token := req.Params.Meta.ProgressToken
+	return func(chunk string, progress float64, total *float64) {
+		params := map[string]any{
+			"progressToken": token,
+			"progress":      progress,
+			"message":       chunk,
+		}
+		if total != nil {
+			params["total"] = *total
+		}
+		if err := mcpServer.SendNotificationToClient(ctx, "notifications/progress", params); err != nil {
+			log.Printf("mcpbridge: failed to send progress notification: %v", err)
+		}
+	}
+}
+
 func main() {
 	// If MCP_BRIDGE_LOG is set, tee all log output to that file in addition to stderr.
 	// This lets the Go server capture mcpbridge startup/crash messages for debugging.
@@ -151,10 +314,9 @@ func main() {
 				httpReq.Header.Set("X-Session-ID", sessionID)
 			}
 
-			httpClient := defaultHTTPClient
-			if isLongRunningDelegationTool(def.Type, def.Name) {
-				httpClient = longRunningHTTPClient
-			}
+			httpReq.Header.Set("Accept", ndjsonContentType+", application/json")
+
+			httpClient := httpClientFor(def, defaultHTTPClient, longRunningHTTPClient)
 
 			started := time.Now()
 			log.Printf("mcpbridge: tool call start type=%s tool=%s url=%s args_bytes=%d diff_bytes=%d filepath=%q session=%s", def.Type, def.Name, url, len(argsJSON), diffBytes, filepathArg, sessionID)
@@ -165,6 +327,19 @@ func main() {
 			}
 			defer resp.Body.Close()
 
+			if resp.StatusCode < 400 && strings.HasPrefix(resp.Header.Get("Content-Type"), ndjsonContentType) {
+				text, err := readStreamedResponse(resp.Body, progressNotifier(ctx, req))
+				if err != nil {
+					log.Printf("mcpbridge: tool call stream error type=%s tool=%s duration=%s error=%v", def.Type, def.Name, time.Since(started), err)
+					if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+						return mcp.NewToolResultText(bridgeRequestError(def.Type, def.Name, sessionID, httpClient.Timeout, err)), nil
+					}
+					return mcp.NewToolResultText(fmt.Sprintf("ERROR: failed to read streamed response: %v", err)), nil
+				}
+				log.Printf("mcpbridge: tool call streamed response type=%s tool=%s duration=%s result_bytes=%d", def.Type, def.Name, time.Since(started), len(text))
+				return mcp.NewToolResultText(text), nil
+			}
+
 			body, err := io.ReadAll(resp.Body)
 			if err != nil {
 				log.Printf("mcpbridge: tool call read error type=%s tool=%s status=%d duration=%s error=%v", def.Type, def.Name, resp.StatusCode, time.Since(started), err)
@@ -176,28 +351,12 @@ func main() {
 				return mcp.NewToolResultText(fmt.Sprintf("ERROR: HTTP %d: %s", resp.StatusCode, truncateBridgeErrorText(string(body)))), nil
 			}
 
-			var result struct {
-				Success bool   `json:"success"`
-				Result  string `json:"result"`
-				Error   string `json:"error"`
-			}
+			var result bridgeResponse
 			if err := json.Unmarshal(body, &result); err != nil {
 				// If response isn't our expected format, return raw body
 				return mcp.NewToolResultText(string(body)), nil
 			}
-
-			if !result.Success {
-				// Return error as regular text (not IsError) so the LLM always sees the
-				// actual error details. Some CLI providers (for example Claude Code) show
-				// only a generic "MCP tool reported an error" when IsError=true, hiding
-				// the actual error content from the LLM.
-				errorMsg := result.Error
-				if errorMsg == "" {
-					errorMsg = "unknown error (no details in response)"
-				}
-				return mcp.NewToolResultText(fmt.Sprintf("ERROR: %s", truncateBridgeErrorText(errorMsg))), nil
-			}
-			return mcp.NewToolResultText(result.Result), nil
+			return mcp.NewToolResultText(toolResultText(result)), nil
 		})
 	}
 
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"net/http"
 	"strings"
 	"testing"
 	"time"
@@ -24,3 +25,67 @@ func TestBridgeRequestErrorIdentifiesCancellation(t *testing.T) {
 		}
 	}
 }
+
+func TestReadStreamedResponseForwardsChunksAndReturnsFinalResult(t *testing.T) {
+	body := `{"chunk": "cloning repo\n", "progress": 1, "total": 3}` + "\n" +
+		`{"chunk": "running tests\n"}` + "\n" +
+		"plain log line\n" +
+		`{"success": true, "result": "42 tests passed"}` + "\n"
+	var chunks []string
+	var progress []float64
+	text, err := readStreamedResponse(strings.NewReader(body), func(chunk string, p float64, total *float64) {
+		chunks = append(chunks, chunk)
+		progress = append(progress, p)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text != "42 tests passed" {
+		t.Errorf("result = %q", text)
+	}
+	if len(chunks) != 3 || chunks[1] != "running tests\n" || chunks[2] != "plain log line\n" {
+		t.Errorf("chunks = %q", chunks)
+	}
+	if len(progress) != 3 || progress[0] != 1 || progress[1] != 2 || progress[2] != 3 {
+		t.Errorf("progress = %v", progress)
+	}
+}
+
+func TestReadStreamedResponseWithoutFinalResultJoinsChunks(t *testing.T) {
+	text, err := readStreamedResponse(strings.NewReader(`{"chunk": "a"}`+"\n"+`{"chunk": "b"}`), nil)
+	if err != nil || text != "ab" {
+		t.Errorf("result = %q, %v", text, err)
+	}
+	text, err = readStreamedResponse(strings.NewReader(`{"chunk": "a"}`+"\n"+`{"success": false, "error": "disk full"}`+"\n"), nil)
+	if err != nil || text != "ERROR: disk full" {
+		t.Errorf("failed result = %q, %v", text, err)
+	}
+}
+
+func TestHTTPClientForUsesToolDefTimeout(t *testing.T) {
+	defaultClient, longRunning := &http.Client{Timeout: 5 * time.Minute}, &http.Client{Timeout: 90 * time.Minute}
+	if c := httpClientFor(ToolDef{Name: "crawl", Type: "mcp", TimeoutMs: 20 * 60 * 1000}, defaultClient, longRunning); c.Timeout != 20*time.Minute {
+		t.Errorf("timeout_ms client timeout = %s", c.Timeout)
+	}
+	if c := httpClientFor(ToolDef{Name: "crawl", Type: "mcp", TimeoutMs: -1}, defaultClient, longRunning); c.Timeout != 0 {
+		t.Errorf("negative timeout_ms client timeout = %s, want none", c.Timeout)
+	}
+	if c := httpClientFor(ToolDef{Name: "execute_shell_command", Type: "custom"}, defaultClient, longRunning); c != longRunning {
+		t.Error("long-running tool without timeout_ms does not use the long-running client")
+	}
+	if c := httpClientFor(ToolDef{Name: "search", Type: "mcp"}, defaultClient, longRunning); c != defaultClient {
+		t.Error("tool without timeout_ms does not use the default client")
+	}
+}
+
+func TestReadStreamedResponseBoundsLinesAndResponse(t *testing.T) {
+	long := strings.Repeat("x", maxStreamLineBytes+1)
+	if _, err := readStreamedResponse(strings.NewReader(long), nil); err == nil || !strings.Contains(err.Error(), "streamed line exceeds") {
+		t.Errorf("long line err = %v", err)
+	}
+	line := strings.Repeat("y", 1024*1024-1) + "\n"
+	body := strings.Repeat(line, maxStreamResponseBytes/len(line)+1)
+	if _, err := readStreamedResponse(strings.NewReader(body), nil); err == nil || !strings.Contains(err.Error(), "streamed response exceeds") {
+		t.Errorf("long response err = %v", err)
+	}
+}
@@ -0,0 +1,357 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/manishiitg/mcpagent/llm"
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+	"github.com/manishiitg/mcpagent/mcpclient"
+
+	"github.com/manishiitg/multi-llm-provider-go/llmerrors"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+type doctorStatus string
+
+const (
+	doctorPass doctorStatus = "PASS"
+	doctorWarn doctorStatus = "WARN"
+	doctorFail doctorStatus = "FAIL"
+	doctorSkip doctorStatus = "SKIP"
+)
+
+// doctorCheck is one line of the doctor report
+type doctorCheck struct {
+	Category string       `json:"category"`
+	Name     string       `json:"name"`
+	Status   doctorStatus `json:"status"`
+	Detail   string       `json:"detail,omitempty"`
+	Hint     string       `json:"hint,omitempty"` // Remediation for WARN and FAIL
+}
+
+// doctorProviders lists the providers checked for credentials. A provider is
+// configured when any of its environment variables is set.
+var doctorProviders = []struct {
+	provider llm.Provider
+	envVars  []string
+}{
+	{llm.ProviderOpenAI, []string{"OPENAI_API_KEY"}},
+	{llm.ProviderAnthropic, []string{"ANTHROPIC_API_KEY"}},
+	{llm.ProviderOpenRouter, []string{"OPEN_ROUTER_API_KEY", "OPENROUTER_API_KEY"}},
+	{llm.ProviderVertex, []string{"VERTEX_API_KEY", "GOOGLE_API_KEY", "GEMINI_API_KEY"}},
+	{llm.ProviderBedrock, []string{"AWS_REGION", "BEDROCK_REGION"}},
+	{llm.ProviderAzure, []string{"AZURE_AI_API_KEY"}},
+	{llm.ProviderZAI, []string{"ZAI_API_KEY"}},
+	{llm.ProviderKimi, []string{"KIMI_API_KEY", "MOONSHOT_API_KEY"}},
+	{llm.ProviderMiniMax, []string{"MINIMAX_API_KEY"}},
+}
+
+// doctorRuntimes are the launchers most MCP server configs use
+var doctorRuntimes = []struct {
+	name string
+	hint string
+}{
+	{"node", "Install Node.js (https://nodejs.org) for JavaScript MCP servers"},
+	{"npx", "npx ships with Node.js; reinstall Node.js or add its bin folder to PATH"},
+	{"uvx", "Install uv (https://docs.astral.sh/uv/) for Python MCP servers"},
+}
+
+// Seams replaced in tests
+var (
+	doctorLookPath = exec.LookPath
+	doctorPing     = pingProvider
+)
+
+// runDoctor implements `server doctor`: it checks the environment end to end
+// and prints a pass/fail report. The exit code is 1 when any check fails.
+func runDoctor(args []string, out io.Writer) int {
+	fs := flag.NewFlagSet("doctor", flag.ContinueOnError)
+	fs.SetOutput(out)
+	configPath := fs.String("config", "mcp_servers.json", "Path to MCP servers configuration file")
+	socketPath := fs.String("socket", "", "gRPC socket path to check (default: a socket in the temp dir)")
+	skipPing := fs.Bool("skip-ping", false, "Only check that API keys are set; do not call the providers")
+	jsonOut := fs.Bool("json", false, "Print the report as JSON")
+	timeout := fs.Duration("timeout", 20*time.Second, "Timeout for each provider ping")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	ctx := context.Background()
+	var checks []doctorCheck
+	checks = append(checks, checkProviderKeys(ctx, !*skipPing, *timeout)...)
+	checks = append(checks, checkMCPServers(*configPath)...)
+	checks = append(checks, checkRuntimes()...)
+	checks = append(checks, checkGoToolchain(ctx))
+	checks = append(checks, checkSocket(*socketPath))
+
+	if *jsonOut {
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(map[string]interface{}{"checks": checks, "ok": !doctorFailed(checks)})
+	} else {
+		printDoctorReport(out, checks)
+	}
+	if doctorFailed(checks) {
+		return 1
+	}
+	return 0
+}
+
+func doctorFailed(checks []doctorCheck) bool {
+	for _, c := range checks {
+		if c.Status == doctorFail {
+			return true
+		}
+	}
+	return false
+}
+
+func printDoctorReport(out io.Writer, checks []doctorCheck) {
+	fmt.Fprintf(out, "\n  MCPAgent Doctor\n  ===============\n")
+	counts := map[doctorStatus]int{}
+	category := ""
+	for _, c := range checks {
+		if c.Category != category {
+			category = c.Category
+			fmt.Fprintf(out, "\n  %s\n", category)
+		}
+		counts[c.Status]++
+		line := fmt.Sprintf("    [%s] %s", c.Status, c.Name)
+		if c.Detail != "" {
+			line += " - " + c.Detail
+		}
+		fmt.Fprintln(out, line)
+		if c.Hint != "" && (c.Status == doctorFail || c.Status == doctorWarn) {
+			fmt.Fprintf(out, "           -> %s\n", c.Hint)
+		}
+	}
+	fmt.Fprintf(out, "\n  %d passed, %d warnings, %d failed, %d skipped\n\n",
+		counts[doctorPass], counts[doctorWarn], counts[doctorFail], counts[doctorSkip])
+}
+
+// checkProviderKeys reports which providers have credentials and, when ping
+// is set, verifies them with a one-token completion
+func checkProviderKeys(ctx context.Context, ping bool, timeout time.Duration) []doctorCheck {
+	var checks []doctorCheck
+	for _, p := range doctorProviders {
+		envVar := ""
+		for _, name := range p.envVars {
+			if strings.TrimSpace(os.Getenv(name)) != "" {
+				envVar = name
+				break
+			}
+		}
+		if envVar == "" {
+			continue
+		}
+
+		check := doctorCheck{Category: "LLM providers", Name: string(p.provider), Status: doctorPass, Detail: envVar + " set"}
+		if !ping {
+			checks = append(checks, check)
+			continue
+		}
+		pingCtx, cancel := context.WithTimeout(ctx, timeout)
+		err := doctorPing(pingCtx, p.provider)
+		cancel()
+		if err == nil {
+			check.Detail += ", ping ok"
+		} else {
+			switch llmerrors.KindOf(llmerrors.Classify(string(p.provider), llm.GetDefaultModel(p.provider), err)) {
+			case llmerrors.KindAuth:
+				check.Status = doctorFail
+				check.Hint = fmt.Sprintf("The provider rejected the credentials; check %s", envVar)
+			case llmerrors.KindQuotaExhausted:
+				check.Status = doctorFail
+				check.Hint = "The account is out of quota or credit; top it up or use another provider"
+			default:
+				check.Status = doctorWarn
+				check.Hint = "The key is set but the ping failed; check network access and the default model"
+			}
+			check.Detail += ", ping failed: " + truncateDoctorDetail(err.Error())
+		}
+		checks = append(checks, check)
+	}
+
+	if len(checks) == 0 {
+		var names []string
+		for _, p := range doctorProviders {
+			names = append(names, p.envVars[0])
+		}
+		checks = append(checks, doctorCheck{
+			Category: "LLM providers", Name: "api keys", Status: doctorFail,
+			Detail: "no provider credentials found in the environment or .env",
+			Hint:   "Set at least one of " + strings.Join(names, ", "),
+		})
+	}
+	return checks
+}
+
+// pingProvider sends a one-token completion to the provider's default model
+func pingProvider(ctx context.Context, provider llm.Provider) error {
+	model, err := llm.InitializeLLM(llm.Config{
+		Provider: provider,
+		ModelID:  llm.GetDefaultModel(provider),
+		Logger:   loggerv2.NewNoop(),
+		Context:  ctx,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = model.GenerateContent(ctx,
+		[]llmtypes.MessageContent{llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "ping")},
+		llmtypes.WithMaxTokens(1))
+	return err
+}
+
+// checkMCPServers verifies that stdio server commands resolve on PATH and
+// remote servers have a usable URL. Servers are not started.
+func checkMCPServers(configPath string) []doctorCheck {
+	const category = "MCP servers"
+	if _, err := os.Stat(configPath); err != nil {
+		return []doctorCheck{{
+			Category: category, Name: configPath, Status: doctorWarn,
+			Detail: "config file not found",
+			Hint:   "Create it or pass --config; agents without MCP servers still work",
+		}}
+	}
+	config, err := mcpclient.LoadConfig(configPath, nil)
+	if err != nil {
+		return []doctorCheck{{
+			Category: category, Name: configPath, Status: doctorFail,
+			Detail: truncateDoctorDetail(err.Error()),
+			Hint:   "Fix the JSON; see mcp_servers.json in the repository for the format",
+		}}
+	}
+
+	checks := []doctorCheck{{Category: category, Name: configPath, Status: doctorPass, Detail: fmt.Sprintf("%d servers", len(config.MCPServers))}}
+	for _, name := range config.ListServers() {
+		server := config.MCPServers[name]
+		check := doctorCheck{Category: category, Name: name, Status: doctorPass}
+		switch protocol := server.GetProtocol(); protocol {
+		case mcpclient.ProtocolStdio:
+			if server.Command == "" {
+				check.Status = doctorFail
+				check.Detail = "stdio server has no command"
+				check.Hint = `Set "command" (and "args") for this server`
+				break
+			}
+			path, err := doctorLookPath(server.Command)
+			if err != nil {
+				check.Status = doctorFail
+				check.Detail = fmt.Sprintf("command %q not found", server.Command)
+				check.Hint = commandHint(server.Command)
+				break
+			}
+			check.Detail = "stdio: " + path
+		default:
+			if server.URL == "" {
+				check.Status = doctorFail
+				check.Detail = fmt.Sprintf("%s server has no url", protocol)
+				check.Hint = `Set "url" for this server`
+				break
+			}
+			check.Detail = fmt.Sprintf("%s: %s", protocol, server.URL)
+		}
+		checks = append(checks, check)
+	}
+	return checks
+}
+
+// commandHint suggests how to install a missing server command
+func commandHint(command string) string {
+	for _, r := range doctorRuntimes {
+		if filepath.Base(command) == r.name {
+			return r.hint
+		}
+	}
+	return "Install the command or use an absolute path in the server config"
+}
+
+func checkRuntimes() []doctorCheck {
+	var checks []doctorCheck
+	for _, r := range doctorRuntimes {
+		check := doctorCheck{Category: "Runtimes", Name: r.name, Status: doctorPass}
+		if path, err := doctorLookPath(r.name); err != nil {
+			check.Status = doctorWarn
+			check.Detail = "not found on PATH"
+			check.Hint = r.hint
+		} else {
+			check.Detail = path
+		}
+		checks = append(checks, check)
+	}
+	return checks
+}
+
+// checkGoToolchain checks for the go toolchain used by code execution mode
+func checkGoToolchain(ctx context.Context) doctorCheck {
+	check := doctorCheck{Category: "Runtimes", Name: "go (code execution)", Status: doctorPass}
+	path, err := doctorLookPath("go")
+	if err != nil {
+		check.Status = doctorWarn
+		check.Detail = "not found on PATH"
+		check.Hint = "Install Go (https://go.dev/dl/) to use code execution mode"
+		return check
+	}
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	output, err := exec.CommandContext(ctx, path, "version").Output() //nolint:gosec // G204: path comes from LookPath
+	if err != nil {
+		check.Status = doctorWarn
+		check.Detail = fmt.Sprintf("%s version failed: %v", path, err)
+		check.Hint = "Reinstall Go; `go version` must succeed for code execution mode"
+		return check
+	}
+	check.Detail = strings.TrimSpace(string(output))
+	return check
+}
+
+// checkSocket verifies that the server can create a Unix socket at
+// socketPath, or in the temp dir when it is empty
+func checkSocket(socketPath string) doctorCheck {
+	check := doctorCheck{Category: "gRPC server", Name: "socket", Status: doctorPass}
+	probe := socketPath
+	if socketPath == "" {
+		probe = filepath.Join(os.TempDir(), fmt.Sprintf("mcpagent-doctor-%d.sock", os.Getpid()))
+	} else if _, err := os.Stat(socketPath); err == nil {
+		if conn, err := net.DialTimeout("unix", socketPath, time.Second); err == nil {
+			_ = conn.Close()
+			check.Status = doctorWarn
+			check.Detail = socketPath + " is in use by a running server"
+			check.Hint = "Stop the other server or pass a different --socket"
+			return check
+		}
+		// A stale socket file is removed by the server on startup; probe next to it
+		probe = filepath.Join(filepath.Dir(socketPath), fmt.Sprintf("mcpagent-doctor-%d.sock", os.Getpid()))
+	}
+
+	listener, err := net.Listen("unix", probe)
+	if err != nil {
+		check.Status = doctorFail
+		check.Detail = truncateDoctorDetail(err.Error())
+		check.Hint = fmt.Sprintf("Make %s writable or pass a --socket in a writable folder", filepath.Dir(probe))
+		return check
+	}
+	_ = listener.Close()
+	_ = os.Remove(probe)
+	check.Detail = "can listen in " + filepath.Dir(probe)
+	return check
+}
+
+func truncateDoctorDetail(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if len(s) > 200 {
+		return s[:200] + "..."
+	}
+	return s
+}
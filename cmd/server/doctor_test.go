@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/manishiitg/mcpagent/llm"
+)
+
+func stubDoctor(t *testing.T, onPath map[string]bool, pingErr error) {
+	t.Helper()
+	prevLookPath, prevPing := doctorLookPath, doctorPing
+	doctorLookPath = func(name string) (string, error) {
+		if onPath[name] {
+			return "/usr/bin/" + name, nil
+		}
+		return "", exec.ErrNotFound
+	}
+	doctorPing = func(context.Context, llm.Provider) error { return pingErr }
+	t.Cleanup(func() { doctorLookPath, doctorPing = prevLookPath, prevPing })
+
+	for _, p := range doctorProviders {
+		for _, name := range p.envVars {
+			t.Setenv(name, "")
+		}
+	}
+}
+
+func findCheck(checks []doctorCheck, name string) *doctorCheck {
+	for i := range checks {
+		if checks[i].Name == name {
+			return &checks[i]
+		}
+	}
+	return nil
+}
+
+func TestCheckProviderKeys(t *testing.T) {
+	stubDoctor(t, nil, nil)
+	checks := checkProviderKeys(context.Background(), true, 0)
+	if len(checks) != 1 || checks[0].Status != doctorFail {
+		t.Fatalf("no keys should fail, got %+v", checks)
+	}
+
+	t.Setenv("OPENAI_API_KEY", "sk-test")
+	if c := findCheck(checkProviderKeys(context.Background(), true, 0), "openai"); c == nil || c.Status != doctorPass {
+		t.Errorf("expected openai to pass, got %+v", c)
+	}
+
+	stubDoctor(t, nil, errors.New("401 Unauthorized: invalid api key"))
+	t.Setenv("OPENAI_API_KEY", "sk-test")
+	if c := findCheck(checkProviderKeys(context.Background(), true, 0), "openai"); c == nil || c.Status != doctorFail || c.Hint == "" {
+		t.Errorf("rejected key should fail with a hint, got %+v", c)
+	}
+	if c := findCheck(checkProviderKeys(context.Background(), false, 0), "openai"); c == nil || c.Status != doctorPass {
+		t.Errorf("skip-ping should only check presence, got %+v", c)
+	}
+}
+
+func TestCheckMCPServers(t *testing.T) {
+	stubDoctor(t, map[string]bool{"npx": true}, nil)
+	path := filepath.Join(t.TempDir(), "mcp_servers.json")
+	config := `{"mcpServers": {
+		"fs": {"command": "npx", "args": ["-y", "@modelcontextprotocol/server-filesystem"]},
+		"fetch": {"command": "uvx", "args": ["mcp-server-fetch"]},
+		"remote": {"url": "https://example.com/mcp"}
+	}}`
+	if err := os.WriteFile(path, []byte(config), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	checks := checkMCPServers(path)
+	if c := findCheck(checks, "fs"); c == nil || c.Status != doctorPass {
+		t.Errorf("fs should pass, got %+v", c)
+	}
+	if c := findCheck(checks, "fetch"); c == nil || c.Status != doctorFail || !strings.Contains(c.Hint, "uv") {
+		t.Errorf("missing uvx should fail with an install hint, got %+v", c)
+	}
+	if c := findCheck(checks, "remote"); c == nil || c.Status != doctorPass {
+		t.Errorf("remote should pass, got %+v", c)
+	}
+
+	if missing := checkMCPServers(filepath.Join(t.TempDir(), "none.json")); missing[0].Status != doctorWarn {
+		t.Errorf("missing config should warn, got %+v", missing)
+	}
+}
+
+func TestRunDoctorJSON(t *testing.T) {
+	stubDoctor(t, map[string]bool{"node": true, "npx": true, "uvx": true}, nil)
+	t.Setenv("ANTHROPIC_API_KEY", "sk-ant-test")
+
+	var out bytes.Buffer
+	code := runDoctor([]string{"--json", "--config", filepath.Join(t.TempDir(), "none.json"),
+		"--socket", filepath.Join(t.TempDir(), "agent.sock")}, &out)
+
+	var report struct {
+		Checks []doctorCheck `json:"checks"`
+		OK     bool          `json:"ok"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &report); err != nil {
+		t.Fatalf("invalid JSON report: %v\n%s", err, out.String())
+	}
+	if code != 0 || !report.OK {
+		t.Errorf("expected a passing report, got code %d: %s", code, out.String())
+	}
+	if c := findCheck(report.Checks, "socket"); c == nil || c.Status != doctorPass {
+		t.Errorf("socket check should pass in a temp dir, got %+v", c)
+	}
+}
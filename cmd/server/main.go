@@ -6,10 +6,12 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/joho/godotenv"
+	mcpagent "github.com/manishiitg/mcpagent/agent"
 	"github.com/manishiitg/mcpagent/grpcserver"
 	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
 )
@@ -21,11 +23,39 @@ func main() {
 		_ = godotenv.Load(".env")
 	}
 
+	// `server doctor [flags]` checks the environment and exits
+	if len(os.Args) > 1 && os.Args[1] == "doctor" {
+		os.Exit(runDoctor(os.Args[2:], os.Stdout))
+	}
+
 	// Parse command line flags
 	socketPath := flag.String("socket", "", "gRPC Unix domain socket path (required)")
 	configPath := flag.String("config", "mcp_servers.json", "Path to MCP servers configuration file")
 	logLevel := flag.String("log-level", "info", "Log level (debug, info, warn, error)")
 	parentPID := flag.Int("parent-pid", 0, "Parent process ID to monitor (exit when parent dies)")
+	artifactAddr := flag.String("artifact-addr", "", "Serve generated files over HTTP on this address (e.g. 127.0.0.1:8089); disabled when empty")
+	artifactBaseURL := flag.String("artifact-base-url", "", "External base URL for artifact links (default http://<artifact-addr>)")
+	autosaveDir := flag.String("autosave-dir", "", "Checkpoint conversations to this folder for crash recovery; disabled when empty")
+	autosaveEvery := flag.Int("autosave-every", 1, "Checkpoint interval in turns when --autosave-dir is set")
+	streamReplay := flag.Int("stream-replay", 0, "Stream agent events to WatchConversation subscribers, replaying the last N to late joiners; disabled when 0")
+	memoryLimitMB := flag.Int("memory-limit-mb", 0, "Evict idle agents' retained session state when all agents together exceed this many MB; disabled when 0")
+	memoryPolicy := flag.String("memory-policy", "spill", "What to do with evicted session state: spill (save to --autosave-dir) or drop")
+	eventBridgeAddr := flag.String("event-bridge-addr", "", "Stream agent events to browsers over SSE and WebSocket on this address (e.g. 127.0.0.1:8091); requires --stream-replay; disabled when empty")
+	eventBridgeOrigins := flag.String("event-bridge-origins", "", "Comma-separated browser origins allowed to connect to the event bridge (\"*\" for any; default same-origin)")
+	healthAddr := flag.String("health-addr", "", "Serve /healthz and /readyz probes over HTTP on this address (e.g. 127.0.0.1:8090); disabled when empty")
+	metricsAddr := flag.String("metrics-addr", "", "Serve Prometheus metrics at /metrics over HTTP on this address (e.g. 127.0.0.1:9090); disabled when empty")
+	presetsPath := flag.String("presets", "", "JSON file of agent presets for CreateAgentFromPreset; reloaded on SIGHUP")
+	warmPoolsPath := flag.String("warm-pools", "", "JSON file of warm agent pools to pre-create at start for CreateAgent requests naming them")
+	artifactRoots := flag.String("artifact-roots", "", "Comma-separated name=folder pairs to serve (default tool_output=tool_output_folder)")
+	maxConcurrent := flag.Int("max-concurrent-conversations", 0, "Run at most this many conversations at once; further requests queue by priority (high, normal, low); unlimited when 0")
+	retentionMaxAge := flag.Duration("retention-max-age", 0, "Delete generated files older than this (e.g. 72h); disabled when 0")
+	retentionMaxSizeMB := flag.Int("retention-max-size-mb", 0, "Delete the oldest generated files once a folder exceeds this many MB; disabled when 0")
+	retentionRoots := flag.String("retention-roots", "", "Comma-separated folders to clean up (default tool_output_folder)")
+	retentionSessionCleanup := flag.Bool("retention-session-cleanup", false, "Delete an agent's session folder when the agent is destroyed")
+	postMortemDir := flag.String("post-mortem-dir", "", "Write a redacted diagnostic bundle to this folder whenever a conversation fails (also available via GetPostMortemBundle); disabled when empty")
+	retentionDryRun := flag.Bool("retention-dry-run", false, "Report what retention cleanup would delete without deleting anything")
+	authConfigPath := flag.String("auth-config", "", "JSON file of client tokens, JWT settings and client scopes; every request must then authenticate (JWT secret defaults to MCPAGENT_AUTH_JWT_SECRET)")
+	drainTimeout := flag.Duration("drain-timeout", 30*time.Second, "On shutdown, refuse new agents and conversations and wait this long for running conversations before cancelling them")
 	flag.Parse()
 
 	if *socketPath == "" {
@@ -33,6 +63,12 @@ func main() {
 		os.Exit(1)
 	}
 
+	policy := mcpagent.MemoryPolicy(*memoryPolicy)
+	if policy != mcpagent.MemoryPolicySpill && policy != mcpagent.MemoryPolicyDrop {
+		fmt.Fprintf(os.Stderr, "Error: invalid --memory-policy %q (want spill or drop)\n", *memoryPolicy)
+		os.Exit(1)
+	}
+
 	// Initialize logger
 	logger, err := loggerv2.New(loggerv2.Config{
 		Level:  *logLevel,
@@ -43,17 +79,159 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Configure the optional artifact server. The token is read from the
+	// environment so it does not show up in process listings.
+	var artifacts *grpcserver.ArtifactServerConfig
+	if *artifactAddr != "" {
+		artifacts = &grpcserver.ArtifactServerConfig{
+			Addr:    *artifactAddr,
+			BaseURL: *artifactBaseURL,
+			Token:   os.Getenv("MCPAGENT_ARTIFACT_TOKEN"),
+		}
+		if *artifactRoots != "" {
+			artifacts.Roots = make(map[string]string)
+			for _, pair := range strings.Split(*artifactRoots, ",") {
+				name, dir, ok := strings.Cut(strings.TrimSpace(pair), "=")
+				if !ok {
+					fmt.Fprintf(os.Stderr, "Error: invalid --artifact-roots entry %q (want name=folder)\n", pair)
+					os.Exit(1)
+				}
+				artifacts.Roots[name] = dir
+			}
+		}
+	}
+
+	// Configure the optional event bridge; its token is read from the
+	// environment like the artifact token
+	var eventBridge *grpcserver.EventBridgeConfig
+	if *eventBridgeAddr != "" {
+		eventBridge = &grpcserver.EventBridgeConfig{
+			Addr:  *eventBridgeAddr,
+			Token: os.Getenv("MCPAGENT_EVENT_BRIDGE_TOKEN"),
+		}
+		for _, origin := range strings.Split(*eventBridgeOrigins, ",") {
+			if origin = strings.TrimSpace(origin); origin != "" {
+				eventBridge.AllowedOrigins = append(eventBridge.AllowedOrigins, origin)
+			}
+		}
+	}
+
+	if *presetsPath != "" {
+		if err := mcpagent.LoadPresets(*presetsPath); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to load presets: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	var warmPools []grpcserver.WarmPoolConfig
+	if *warmPoolsPath != "" {
+		warmPools, err = grpcserver.LoadWarmPools(*warmPoolsPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to load warm pools: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	// The JWT secret may come from the environment so it stays out of the file
+	var auth *grpcserver.AuthConfig
+	if *authConfigPath != "" {
+		auth, err = grpcserver.LoadAuthConfig(*authConfigPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to load auth config: %v\n", err)
+			os.Exit(1)
+		}
+		if auth.JWT != nil && auth.JWT.Secret == "" {
+			auth.JWT.Secret = os.Getenv("MCPAGENT_AUTH_JWT_SECRET")
+		}
+	}
+
+	var retention *mcpagent.RetentionPolicy
+	if *retentionMaxAge > 0 || *retentionMaxSizeMB > 0 || *retentionSessionCleanup {
+		retention = &mcpagent.RetentionPolicy{
+			MaxAge:              *retentionMaxAge,
+			MaxTotalBytes:       int64(*retentionMaxSizeMB) << 20,
+			CleanupSessionOnEnd: *retentionSessionCleanup,
+			DryRun:              *retentionDryRun,
+		}
+		if *retentionRoots != "" {
+			for _, root := range strings.Split(*retentionRoots, ",") {
+				if root = strings.TrimSpace(root); root != "" {
+					retention.Roots = append(retention.Roots, root)
+				}
+			}
+		}
+	}
+
+	var postMortem *mcpagent.PostMortemConfig
+	if *postMortemDir != "" {
+		postMortem = &mcpagent.PostMortemConfig{Dir: *postMortemDir}
+	}
+
+	var conversationStore mcpagent.ConversationStore
+	if *autosaveDir != "" {
+		store, err := mcpagent.NewFileConversationStore(*autosaveDir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to initialize autosave: %v\n", err)
+			os.Exit(1)
+		}
+		conversationStore = store
+	}
+
 	// Create gRPC server
 	server := grpcserver.NewServer(grpcserver.Config{
-		SocketPath:        *socketPath,
-		DefaultConfigPath: *configPath,
-		Logger:            logger,
+		SocketPath:                 *socketPath,
+		DefaultConfigPath:          *configPath,
+		Logger:                     logger,
+		Artifacts:                  artifacts,
+		EventBridge:                eventBridge,
+		ConversationStore:          conversationStore,
+		AutosaveEveryTurns:         *autosaveEvery,
+		StreamReplaySize:           *streamReplay,
+		MemoryLimitBytes:           int64(*memoryLimitMB) << 20,
+		MemoryPolicy:               policy,
+		HealthAddr:                 *healthAddr,
+		MetricsAddr:                *metricsAddr,
+		WarmPools:                  warmPools,
+		Retention:                  retention,
+		MaxConcurrentConversations: *maxConcurrent,
+		PostMortem:                 postMortem,
+		Auth:                       auth,
 	})
 
+	if conversationStore != nil {
+		if checkpoints, err := mcpagent.RecoverConversations(context.Background(), conversationStore); err == nil && len(checkpoints) > 0 {
+			logger.Info("Found recoverable conversations", loggerv2.Int("count", len(checkpoints)))
+		}
+	}
+
 	// Handle graceful shutdown
 	shutdown := make(chan os.Signal, 1)
 	signal.Notify(shutdown, os.Interrupt, syscall.SIGTERM)
 
+	// SIGHUP reloads .env, presets and the MCP config without restarting
+	reload := make(chan os.Signal, 1)
+	signal.Notify(reload, syscall.SIGHUP)
+	go func() {
+		for range reload {
+			logger.Info("Reload signal received", loggerv2.String("config", *configPath))
+			if _, err := os.Stat(".env"); err == nil {
+				if err := godotenv.Overload(".env"); err != nil {
+					logger.Warn("Failed to reload .env", loggerv2.String("error", err.Error()))
+				}
+			}
+			if *presetsPath != "" {
+				if err := mcpagent.LoadPresets(*presetsPath); err != nil {
+					logger.Warn("Failed to reload presets", loggerv2.String("error", err.Error()))
+				}
+			}
+			if err := server.ReloadConfig(); err != nil {
+				logger.Warn("Config reload failed; server is not ready", loggerv2.String("error", err.Error()))
+				continue
+			}
+			logger.Info("Config reloaded")
+		}
+	}()
+
 	// Monitor parent process if specified
 	if *parentPID > 0 {
 		go func() {
@@ -88,16 +266,43 @@ func main() {
 		fmt.Printf("  ===============\n")
 		fmt.Printf("  gRPC Socket: %s\n", *socketPath)
 		fmt.Printf("  Config: %s\n", *configPath)
+		if artifacts != nil {
+			fmt.Printf("  Artifacts: http://%s/artifacts/\n", *artifactAddr)
+		}
+		if eventBridge != nil {
+			fmt.Printf("  Event bridge: http://%s/agents/{id}/events, ws://%s/agents/{id}/ws\n", *eventBridgeAddr, *eventBridgeAddr)
+		}
+		if *healthAddr != "" {
+			fmt.Printf("  Health: http://%s/healthz, http://%s/readyz\n", *healthAddr, *healthAddr)
+		}
+		if *metricsAddr != "" {
+			fmt.Printf("  Metrics: http://%s/metrics\n", *metricsAddr)
+		}
+		for _, pool := range warmPools {
+			fmt.Printf("  Warm pool: %s (%d agents)\n", pool.Name, pool.Size)
+		}
+		if retention != nil {
+			fmt.Printf("  Retention: max age %s, max size %d MB, session cleanup %t, dry run %t\n",
+				retention.MaxAge, *retentionMaxSizeMB, retention.CleanupSessionOnEnd, retention.DryRun)
+		}
+		if postMortem != nil {
+			fmt.Printf("  Post-mortem bundles: %s\n", postMortem.Dir)
+		}
 		fmt.Printf("\n  gRPC Services:\n")
 		fmt.Printf("    AgentService.CreateAgent           - Create agent\n")
+		fmt.Printf("    AgentService.CreateAgentFromPreset - Create agent from preset\n")
 		fmt.Printf("    AgentService.GetAgent              - Get agent info\n")
 		fmt.Printf("    AgentService.ListAgents            - List agents\n")
 		fmt.Printf("    AgentService.DestroyAgent          - Destroy agent\n")
 		fmt.Printf("    AgentService.Ask                   - Ask question (unary)\n")
 		fmt.Printf("    AgentService.AskWithHistory        - Multi-turn (unary)\n")
 		fmt.Printf("    AgentService.Converse              - Bidirectional streaming\n")
+		fmt.Printf("    AgentService.WatchConversation     - Watch agent events (read-only)\n")
+		fmt.Printf("    AgentService.AskStream             - Ask with server-side streaming\n")
 		fmt.Printf("    AgentService.GetTokenUsage         - Token stats\n")
+		fmt.Printf("    AgentService.GetPostMortemBundle   - Diagnostics of the last failed conversation\n")
 		fmt.Printf("    AgentService.HealthCheck           - Health check\n")
+		fmt.Printf("    AgentService.ListRecoverableConversations - Autosaved unfinished conversations\n")
 		fmt.Printf("\n  Ready to accept connections...\n\n")
 
 		if err := server.Start(); err != nil {
@@ -110,8 +315,8 @@ func main() {
 	<-shutdown
 	logger.Info("Shutdown signal received")
 
-	// Graceful shutdown with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	// Drain running conversations, then stop
+	ctx, cancel := context.WithTimeout(context.Background(), *drainTimeout)
 	defer cancel()
 
 	if err := server.Shutdown(ctx); err != nil {
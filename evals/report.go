@@ -0,0 +1,158 @@
+package evals
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Checks a case can fail
+const (
+	CheckError         = "error"
+	CheckToolCalls     = "tool_calls"
+	CheckForbiddenTool = "forbidden_tool"
+	CheckAssertion     = "assertion"
+	CheckCustom        = "check"
+	CheckCost          = "cost"
+)
+
+// Report is the outcome of a suite run
+type Report struct {
+	Suite    string        `json:"suite"`
+	Results  []CaseResult  `json:"results"`
+	Duration time.Duration `json:"duration"`
+}
+
+// CaseResult is the outcome of one case
+type CaseResult struct {
+	Name     string    `json:"name"`
+	Passed   bool      `json:"passed"`
+	Failures []Failure `json:"failures,omitempty"`
+	// Replayed is true when the case ran from its recording
+	Replayed  bool          `json:"replayed,omitempty"`
+	Answer    string        `json:"answer"`
+	ToolCalls []ToolCall    `json:"tool_calls,omitempty"`
+	CostUSD   float64       `json:"cost_usd"`
+	Duration  time.Duration `json:"duration"`
+	// Error is the error of the agent, if it failed to answer
+	Error string `json:"error,omitempty"`
+}
+
+// Failure is a failed check of a case
+type Failure struct {
+	Check   string `json:"check"`
+	Message string `json:"message"`
+	// Diff shows the expected ("-") against the actual ("+") lines, for
+	// tool calls and equals assertions
+	Diff string `json:"diff,omitempty"`
+}
+
+// ToolCall is a tool call the agent made
+type ToolCall struct {
+	Name      string                 `json:"name"`
+	Arguments map[string]interface{} `json:"arguments,omitempty"`
+}
+
+func (c ToolCall) String() string {
+	return formatToolCall(c.Name, c.Arguments)
+}
+
+func formatToolCall(name string, args map[string]interface{}) string {
+	if len(args) == 0 {
+		return name
+	}
+	data, err := json.Marshal(args)
+	if err != nil {
+		return name
+	}
+	return name + " " + string(data)
+}
+
+// Passed returns the number of passed cases
+func (r *Report) Passed() int {
+	passed := 0
+	for _, result := range r.Results {
+		if result.Passed {
+			passed++
+		}
+	}
+	return passed
+}
+
+// OK reports whether every case passed
+func (r *Report) OK() bool {
+	return r.Passed() == len(r.Results)
+}
+
+// String renders the report as text, with the failures and diffs of failed
+// cases:
+//
+//	eval suite weather: 1/2 passed (2.1s)
+//	PASS forecast (1.2s, $0.0031)
+//	FAIL alerts (0.9s, $0.0024, replayed)
+//	  tool_calls: missing tool calls: get_alerts {"region":"EU"}
+//	    - get_alerts {"region":"EU"}
+//	    + get_forecast {"city":"Paris"}
+func (r *Report) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "eval suite %s: %d/%d passed (%s)\n", r.Suite, r.Passed(), len(r.Results), r.Duration.Round(time.Millisecond))
+	for _, result := range r.Results {
+		status := "PASS"
+		if !result.Passed {
+			status = "FAIL"
+		}
+		details := fmt.Sprintf("%s, $%.4f", result.Duration.Round(time.Millisecond), result.CostUSD)
+		if result.Replayed {
+			details += ", replayed"
+		}
+		fmt.Fprintf(&b, "%s %s (%s)\n", status, result.Name, details)
+		for _, failure := range result.Failures {
+			fmt.Fprintf(&b, "  %s: %s\n", failure.Check, failure.Message)
+			if failure.Diff != "" {
+				for _, line := range strings.Split(strings.TrimSuffix(failure.Diff, "\n"), "\n") {
+					fmt.Fprintf(&b, "    %s\n", line)
+				}
+			}
+		}
+	}
+	return b.String()
+}
+
+// lineDiff renders the difference between want and got: common lines are
+// prefixed with "  ", lines only in want with "- " and lines only in got
+// with "+ " (longest common subsequence)
+func lineDiff(want, got []string) string {
+	// lcs[i][j] is the LCS length of want[i:] and got[j:]
+	lcs := make([][]int, len(want)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(got)+1)
+	}
+	for i := len(want) - 1; i >= 0; i-- {
+		for j := len(got) - 1; j >= 0; j-- {
+			if want[i] == got[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var b strings.Builder
+	i, j := 0, 0
+	for i < len(want) || j < len(got) {
+		switch {
+		case i < len(want) && j < len(got) && want[i] == got[j]:
+			b.WriteString("  " + want[i] + "\n")
+			i++
+			j++
+		case i < len(want) && (j == len(got) || lcs[i+1][j] >= lcs[i][j+1]):
+			b.WriteString("- " + want[i] + "\n")
+			i++
+		default:
+			b.WriteString("+ " + got[j] + "\n")
+			j++
+		}
+	}
+	return b.String()
+}
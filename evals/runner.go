@@ -0,0 +1,289 @@
+package evals
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	mcpagent "github.com/manishiitg/mcpagent/agent"
+	"github.com/manishiitg/mcpagent/llm"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Runner runs eval suites, each case on a fresh agent
+type Runner struct {
+	// LLM is the model of live cases. Recorded cases do not call it, but
+	// their agents are still created with it.
+	LLM llmtypes.Model
+	// Options are applied to every agent, after the suite's profile
+	Options []mcpagent.AgentOption
+	// RecordDir, when set, records the sessions of live cases to
+	// RecordDir/<case>.json, ready to become their recording
+	RecordDir string
+	// Setup is called with each new agent before its case runs, e.g. to
+	// register custom tools
+	Setup func(agent *mcpagent.Agent) error
+}
+
+// Run runs the cases of suite one after the other. The error reports an
+// invalid suite or runner; failing cases are reported in the Report.
+func (r *Runner) Run(ctx context.Context, suite *Suite) (*Report, error) {
+	if r.LLM == nil {
+		return nil, fmt.Errorf("eval runner needs an LLM")
+	}
+	if err := suite.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid eval suite %s: %w", suite.Name, err)
+	}
+	start := time.Now()
+	report := &Report{Suite: suite.Name}
+	for _, c := range suite.Cases {
+		report.Results = append(report.Results, r.runCase(ctx, suite, c))
+	}
+	report.Duration = time.Since(start)
+	return report, nil
+}
+
+// runCase asks the case's prompt on a new agent and checks the outcome
+func (r *Runner) runCase(ctx context.Context, suite *Suite, c Case) CaseResult {
+	start := time.Now()
+	result := CaseResult{Name: c.Name, Replayed: c.Recording != ""}
+
+	// Recorded first so calls blocked by other middleware still count. The
+	// middleware also sees the calls code execution makes through the
+	// executor API.
+	var mu sync.Mutex
+	var calls []ToolCall
+	options := []mcpagent.AgentOption{
+		mcpagent.WithToolMiddleware(func(ctx context.Context, call *mcpagent.ToolInvocation, next mcpagent.ToolExecFunc) (*mcp.CallToolResult, error) {
+			args := make(map[string]interface{}, len(call.Arguments))
+			for k, v := range call.Arguments {
+				args[k] = v
+			}
+			mu.Lock()
+			calls = append(calls, ToolCall{Name: call.Name, Arguments: args})
+			mu.Unlock()
+			return next(ctx, call)
+		}),
+	}
+	options = append(options, r.Options...)
+	switch {
+	case c.Recording != "":
+		options = append(options, mcpagent.WithReplay(c.Recording))
+	case r.RecordDir != "":
+		options = append(options, mcpagent.WithRecording(filepath.Join(r.RecordDir, recordingFileName(c.Name))))
+	}
+	if c.MaxTurns > 0 {
+		options = append(options, mcpagent.WithMaxTurns(c.MaxTurns))
+	}
+	options = append(options, c.Options...)
+
+	agent, err := r.newAgent(ctx, suite, options)
+	if err != nil {
+		result.Error = err.Error()
+		result.Failures = []Failure{{Check: CheckError, Message: "failed to create agent: " + err.Error()}}
+		result.Duration = time.Since(start)
+		return result
+	}
+	defer agent.Close()
+	if r.Setup != nil {
+		if err := r.Setup(agent); err != nil {
+			result.Error = err.Error()
+			result.Failures = []Failure{{Check: CheckError, Message: "agent setup failed: " + err.Error()}}
+			result.Duration = time.Since(start)
+			return result
+		}
+	}
+
+	// Coding-agent CLIs run their own tools, which bypass the middleware, so
+	// the case's tool call checks would pass or fail for the wrong reason
+	if llm.IsCodingAgentProvider(agent.GetProvider(), agent.ModelID) && (len(c.ToolCalls) > 0 || len(c.ForbiddenTools) > 0) {
+		result.Failures = []Failure{{
+			Check: CheckToolCalls,
+			Message: fmt.Sprintf("tool calls of coding-agent CLI provider %s cannot be observed; "+
+				"drop tool_calls and forbidden_tools from the case or run it on an API provider", agent.GetProvider()),
+		}}
+		result.Duration = time.Since(start)
+		return result
+	}
+
+	answer, err := agent.Ask(ctx, c.Prompt)
+	result.Answer = answer
+	result.CostUSD = agent.GetTotalCost()
+	mu.Lock()
+	result.ToolCalls = calls
+	mu.Unlock()
+	if err != nil {
+		result.Error = err.Error()
+		result.Failures = append(result.Failures, Failure{Check: CheckError, Message: err.Error()})
+	} else {
+		result.Failures = append(result.Failures, checkCase(c, answer, result.ToolCalls, result.CostUSD)...)
+	}
+	result.Passed = len(result.Failures) == 0
+	result.Duration = time.Since(start)
+	return result
+}
+
+func (r *Runner) newAgent(ctx context.Context, suite *Suite, options []mcpagent.AgentOption) (*mcpagent.Agent, error) {
+	if suite.Profile != "" {
+		return mcpagent.NewAgentFromProfile(ctx, r.LLM, suite.Profile, options...)
+	}
+	return mcpagent.NewAgent(ctx, r.LLM, suite.MCPConfig, options...)
+}
+
+// recordingFileName turns a case name into a file name
+func recordingFileName(caseName string) string {
+	name := strings.Map(func(r rune) rune {
+		if r == '-' || r == '_' || r == '.' || ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z') || ('0' <= r && r <= '9') {
+			return r
+		}
+		return '_'
+	}, caseName)
+	return name + ".json"
+}
+
+// checkCase returns the failed checks of a completed case
+func checkCase(c Case, answer string, calls []ToolCall, costUSD float64) []Failure {
+	var failures []Failure
+	if failure, ok := checkToolCalls(c.ToolCalls, c.OrderedToolCalls, calls); !ok {
+		failures = append(failures, failure)
+	}
+	for _, forbidden := range c.ForbiddenTools {
+		for _, call := range calls {
+			if call.Name == forbidden {
+				failures = append(failures, Failure{Check: CheckForbiddenTool, Message: "called forbidden tool " + call.String()})
+				break
+			}
+		}
+	}
+	for _, assertion := range c.Assertions {
+		if failure, ok := checkAssertion(assertion, answer); !ok {
+			failures = append(failures, failure)
+		}
+	}
+	if c.Check != nil {
+		if err := c.Check(answer); err != nil {
+			failures = append(failures, Failure{Check: CheckCustom, Message: err.Error()})
+		}
+	}
+	if c.MaxCostUSD > 0 && costUSD > c.MaxCostUSD {
+		failures = append(failures, Failure{Check: CheckCost, Message: fmt.Sprintf("cost $%.4f exceeds $%.4f", costUSD, c.MaxCostUSD)})
+	}
+	return failures
+}
+
+// checkToolCalls matches expected against the calls made: each expected call
+// takes the first unused matching call (after the previous match when
+// ordered)
+func checkToolCalls(expected []ExpectedToolCall, ordered bool, calls []ToolCall) (Failure, bool) {
+	// matchedBy[i] is the index of the expected call that call i matched
+	matchedBy := make([]int, len(calls))
+	for i := range matchedBy {
+		matchedBy[i] = -1
+	}
+	next := 0
+	var missing []string
+	for j, want := range expected {
+		found := false
+		for i := next; i < len(calls); i++ {
+			if matchedBy[i] >= 0 || !want.matches(calls[i]) {
+				continue
+			}
+			matchedBy[i] = j
+			if ordered {
+				next = i + 1
+			}
+			found = true
+			break
+		}
+		if !found {
+			missing = append(missing, want.String())
+		}
+	}
+	if len(missing) == 0 {
+		return Failure{}, true
+	}
+
+	wantLines := make([]string, len(expected))
+	for i, want := range expected {
+		wantLines[i] = want.String()
+	}
+	// Matched calls show as the expected line, so only mismatches differ
+	gotLines := make([]string, len(calls))
+	for i, call := range calls {
+		gotLines[i] = call.String()
+		if matchedBy[i] >= 0 {
+			gotLines[i] = wantLines[matchedBy[i]]
+		}
+	}
+	message := "missing tool calls: " + strings.Join(missing, ", ")
+	if ordered {
+		message = "missing or out-of-order tool calls: " + strings.Join(missing, ", ")
+	}
+	return Failure{Check: CheckToolCalls, Message: message, Diff: lineDiff(wantLines, gotLines)}, false
+}
+
+// matches reports whether call has the expected name and arguments
+func (e ExpectedToolCall) matches(call ToolCall) bool {
+	if e.Name != call.Name {
+		return false
+	}
+	for key, want := range e.Arguments {
+		got, ok := call.Arguments[key]
+		if !ok || !reflect.DeepEqual(normalizeJSON(want), normalizeJSON(got)) {
+			return false
+		}
+	}
+	return true
+}
+
+// normalizeJSON converts v to its JSON decoding, so YAML ints compare equal
+// to the float64 numbers of tool arguments
+func normalizeJSON(v interface{}) interface{} {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	var normalized interface{}
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return v
+	}
+	return normalized
+}
+
+func (e ExpectedToolCall) String() string {
+	return formatToolCall(e.Name, e.Arguments)
+}
+
+func checkAssertion(a Assertion, answer string) (Failure, bool) {
+	switch {
+	case a.Contains != "":
+		if !strings.Contains(answer, a.Contains) {
+			return Failure{Check: CheckAssertion, Message: fmt.Sprintf("answer does not contain %q", a.Contains)}, false
+		}
+	case a.NotContains != "":
+		if strings.Contains(answer, a.NotContains) {
+			return Failure{Check: CheckAssertion, Message: fmt.Sprintf("answer contains %q", a.NotContains)}, false
+		}
+	case a.Equals != "":
+		if want, got := strings.TrimSpace(a.Equals), strings.TrimSpace(answer); want != got {
+			return Failure{
+				Check:   CheckAssertion,
+				Message: "answer differs from the expected answer",
+				Diff:    lineDiff(strings.Split(want, "\n"), strings.Split(got, "\n")),
+			}, false
+		}
+	case a.Matches != "":
+		// Validated when the suite was loaded
+		if !regexp.MustCompile(a.Matches).MatchString(answer) {
+			return Failure{Check: CheckAssertion, Message: fmt.Sprintf("answer does not match %q", a.Matches)}, false
+		}
+	}
+	return Failure{}, true
+}
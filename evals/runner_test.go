@@ -0,0 +1,200 @@
+package evals
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	mcpagent "github.com/manishiitg/mcpagent/agent"
+	"github.com/manishiitg/mcpagent/llm"
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+// forecastModel looks up the forecast, then answers from the tool result
+type forecastModel struct {
+	calls atomic.Int32
+}
+
+func (m *forecastModel) GenerateContent(_ context.Context, messages []llmtypes.MessageContent, _ ...llmtypes.CallOption) (*llmtypes.ContentResponse, error) {
+	m.calls.Add(1)
+	for _, msg := range messages {
+		for _, part := range msg.Parts {
+			if result, ok := part.(llmtypes.ToolCallResponse); ok {
+				return &llmtypes.ContentResponse{Choices: []*llmtypes.ContentChoice{{Content: "Paris: " + result.Content}}}, nil
+			}
+		}
+	}
+	return &llmtypes.ContentResponse{Choices: []*llmtypes.ContentChoice{{
+		ToolCalls: []llmtypes.ToolCall{{
+			ID:           "call_1",
+			Type:         "function",
+			FunctionCall: &llmtypes.FunctionCall{Name: "get_forecast", Arguments: `{"city":"Paris","days":1}`},
+		}},
+	}}}, nil
+}
+
+func (m *forecastModel) GetModelID() string {
+	return "forecast-model"
+}
+
+func (m *forecastModel) GetModelMetadata(string) (*llmtypes.ModelMetadata, error) {
+	return nil, errors.New("no metadata")
+}
+
+func newTestRunner(t *testing.T, model llmtypes.Model) (*Runner, *atomic.Int32) {
+	t.Helper()
+	var toolRuns atomic.Int32
+	return &Runner{
+		LLM: model,
+		Options: []mcpagent.AgentOption{
+			mcpagent.WithLogger(loggerv2.NewNoop()),
+			mcpagent.WithLLMConfig(mcpagent.AgentLLMConfiguration{
+				Primary: mcpagent.LLMModel{Provider: "openai", ModelID: model.GetModelID(), Model: model},
+			}),
+		},
+		Setup: func(agent *mcpagent.Agent) error {
+			return agent.RegisterCustomTool("get_forecast", "Get the weather forecast", map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"city": map[string]interface{}{"type": "string"},
+					"days": map[string]interface{}{"type": "integer"},
+				},
+			}, func(context.Context, map[string]interface{}) (string, error) {
+				toolRuns.Add(1)
+				return "sunny, 21 °C", nil
+			}, "weather")
+		},
+	}, &toolRuns
+}
+
+func writeMCPConfig(t *testing.T, dir string) string {
+	t.Helper()
+	config := filepath.Join(dir, "mcp.json")
+	if err := os.WriteFile(config, []byte(`{"mcpServers": {}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return config
+}
+
+func TestRunnerReportsPassAndFailuresWithDiffs(t *testing.T) {
+	runner, _ := newTestRunner(t, &forecastModel{})
+	suite := &Suite{
+		Name:      "weather",
+		MCPConfig: writeMCPConfig(t, t.TempDir()),
+		Cases: []Case{
+			{
+				Name:           "forecast",
+				Prompt:         "Weather in Paris?",
+				ToolCalls:      []ExpectedToolCall{{Name: "get_forecast", Arguments: map[string]interface{}{"city": "Paris", "days": 1}}},
+				ForbiddenTools: []string{"send_message"},
+				Assertions:     []Assertion{{Contains: "Paris"}, {Matches: `\d+ °C`}},
+			},
+			{
+				Name:           "regressed",
+				Prompt:         "Weather in Paris?",
+				ToolCalls:      []ExpectedToolCall{{Name: "get_alerts"}, {Name: "get_forecast"}},
+				ForbiddenTools: []string{"get_forecast"},
+				Assertions:     []Assertion{{Equals: "Paris: rainy"}, {NotContains: "sunny"}},
+				Check: func(string) error {
+					return errors.New("custom check failed")
+				},
+			},
+		},
+	}
+
+	report, err := runner.Run(context.Background(), suite)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Passed() != 1 || report.OK() {
+		t.Fatalf("report:\n%s", report)
+	}
+	if pass := report.Results[0]; !pass.Passed || pass.Answer != "Paris: sunny, 21 °C" || len(pass.ToolCalls) != 1 {
+		t.Errorf("passing case = %+v", pass)
+	}
+
+	checks := map[string]Failure{}
+	for _, failure := range report.Results[1].Failures {
+		checks[failure.Check] = failure
+	}
+	for _, check := range []string{CheckToolCalls, CheckForbiddenTool, CheckAssertion, CheckCustom} {
+		if _, ok := checks[check]; !ok {
+			t.Errorf("missing %s failure in %+v", check, report.Results[1].Failures)
+		}
+	}
+	if diff := checks[CheckToolCalls].Diff; diff != "- get_alerts\n  get_forecast\n" {
+		t.Errorf("tool call diff = %q", diff)
+	}
+	text := report.String()
+	for _, want := range []string{"eval suite weather: 1/2 passed", "PASS forecast", "FAIL regressed", "    - Paris: rainy", "    + Paris: sunny, 21 °C"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("report missing %q:\n%s", want, text)
+		}
+	}
+}
+
+func TestRunnerRecordsAndReplaysCases(t *testing.T) {
+	dir := t.TempDir()
+	model := &forecastModel{}
+	runner, toolRuns := newTestRunner(t, model)
+	runner.RecordDir = filepath.Join(dir, "recordings")
+	suite := &Suite{
+		MCPConfig: writeMCPConfig(t, dir),
+		Cases: []Case{{
+			Name:       "paris forecast",
+			Prompt:     "Weather in Paris?",
+			ToolCalls:  []ExpectedToolCall{{Name: "get_forecast"}},
+			Assertions: []Assertion{{Contains: "21 °C"}},
+		}},
+	}
+	report, err := runner.Run(context.Background(), suite)
+	if err != nil || !report.OK() {
+		t.Fatalf("live run: %v\n%s", err, report)
+	}
+	llmCalls, tools := model.calls.Load(), toolRuns.Load()
+
+	runner.RecordDir = ""
+	suite.Cases[0].Recording = filepath.Join(dir, "recordings", "paris_forecast.json")
+	report, err = runner.Run(context.Background(), suite)
+	if err != nil || !report.OK() || !report.Results[0].Replayed {
+		t.Fatalf("replayed run: %v\n%s", err, report)
+	}
+	if model.calls.Load() != llmCalls || toolRuns.Load() != tools {
+		t.Error("replayed case called the LLM or ran the tool")
+	}
+}
+
+func TestRunnerRejectsInvalidSuite(t *testing.T) {
+	runner, _ := newTestRunner(t, &forecastModel{})
+	if _, err := runner.Run(context.Background(), &Suite{MCPConfig: "mcp.json"}); err == nil {
+		t.Error("expected an error for a suite without cases")
+	}
+}
+
+func TestRunnerFailsToolCallChecksOfCodingAgentCLIs(t *testing.T) {
+	runner, _ := newTestRunner(t, &forecastModel{})
+	runner.Options = append(runner.Options, mcpagent.WithLLMConfig(mcpagent.AgentLLMConfiguration{
+		Primary: mcpagent.LLMModel{Provider: string(llm.ProviderClaudeCode), ModelID: "claude-sonnet-4-5", Model: &forecastModel{}},
+	}))
+	suite := &Suite{
+		MCPConfig: writeMCPConfig(t, t.TempDir()),
+		Cases: []Case{{
+			Name:           "forecast",
+			Prompt:         "Weather in Paris?",
+			ForbiddenTools: []string{"send_message"},
+		}},
+	}
+	report, err := runner.Run(context.Background(), suite)
+	if err != nil {
+		t.Fatal(err)
+	}
+	failures := report.Results[0].Failures
+	if len(failures) != 1 || failures[0].Check != CheckToolCalls || !strings.Contains(failures[0].Message, "cannot be observed") {
+		t.Errorf("failures = %+v", failures)
+	}
+}
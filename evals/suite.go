@@ -0,0 +1,185 @@
+// Package evals runs declarative regression tests against an agent
+// configuration. A suite lists cases: a prompt, the tool calls the agent is
+// expected to make, assertions on its answer and a cost limit. Runner runs
+// each case on a fresh agent, live or from a session recording (see
+// mcpagent.WithRecording), and returns a pass/fail Report with diffs, so
+// changes to prompts, tool filters or models can be checked before they
+// ship.
+//
+// Example weather.yaml:
+//
+//	name: weather
+//	profile: ../profiles/assistant.yaml
+//	cases:
+//	  - name: forecast
+//	    prompt: What is the weather in Paris tomorrow?
+//	    recording: recordings/forecast.json
+//	    tool_calls:
+//	      - name: get_forecast
+//	        arguments: {city: Paris}
+//	    forbidden_tools: [send_message]
+//	    assertions:
+//	      - contains: Paris
+//	      - matches: '\d+\s?°C'
+//	    max_cost_usd: 0.05
+package evals
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	mcpagent "github.com/manishiitg/mcpagent/agent"
+	"gopkg.in/yaml.v3"
+)
+
+// Suite is a set of eval cases run against one agent configuration
+type Suite struct {
+	Name string `yaml:"name"`
+	// Profile is an agent profile file (see mcpagent.LoadAgentProfile) the
+	// agents are created from; it takes precedence over MCPConfig
+	Profile string `yaml:"profile,omitempty"`
+	// MCPConfig is the MCP servers config file of the agents
+	MCPConfig string `yaml:"mcp_config,omitempty"`
+	Cases     []Case `yaml:"cases"`
+}
+
+// Case is one eval: a prompt and what the agent must do to answer it
+type Case struct {
+	Name   string `yaml:"name"`
+	Prompt string `yaml:"prompt"`
+	// Recording replays a session recorded with mcpagent.WithRecording
+	// instead of calling the LLM and the tools; "" runs the case live
+	Recording string `yaml:"recording,omitempty"`
+
+	// ToolCalls must all be made. With OrderedToolCalls they must be made in
+	// this order; other calls may come in between. Calls are observed
+	// through the tool middleware, which coding-agent CLI providers bypass:
+	// their cases fail without running when they set ToolCalls or
+	// ForbiddenTools.
+	ToolCalls        []ExpectedToolCall `yaml:"tool_calls,omitempty"`
+	OrderedToolCalls bool               `yaml:"ordered_tool_calls,omitempty"`
+	// ForbiddenTools must not be called
+	ForbiddenTools []string `yaml:"forbidden_tools,omitempty"`
+
+	Assertions []Assertion `yaml:"assertions,omitempty"`
+	// MaxCostUSD fails the case when the agent's cost exceeds it; 0 = no limit
+	MaxCostUSD float64 `yaml:"max_cost_usd,omitempty"`
+	// MaxTurns bounds the agent's turns; 0 keeps the configured limit
+	MaxTurns int `yaml:"max_turns,omitempty"`
+
+	// Options are applied to the case's agent after the runner's (Go only)
+	Options []mcpagent.AgentOption `yaml:"-"`
+	// Check is an extra assertion on the answer (Go only)
+	Check func(answer string) error `yaml:"-"`
+}
+
+// ExpectedToolCall is a tool call a case expects
+type ExpectedToolCall struct {
+	Name string `yaml:"name"`
+	// Arguments the call must have; other arguments are not checked
+	Arguments map[string]interface{} `yaml:"arguments,omitempty"`
+}
+
+// Assertion is a check on the final answer. Exactly one field is set.
+type Assertion struct {
+	Contains    string `yaml:"contains,omitempty"`
+	NotContains string `yaml:"not_contains,omitempty"`
+	// Equals compares the answer with surrounding whitespace trimmed
+	Equals string `yaml:"equals,omitempty"`
+	// Matches is a regular expression (Go syntax) the answer must match
+	Matches string `yaml:"matches,omitempty"`
+}
+
+// LoadSuite parses the suite file at path. Relative profile, mcp_config and
+// recording paths are resolved against its directory; unknown keys are
+// rejected.
+func LoadSuite(path string) (*Suite, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // path is supplied by the operator
+	if err != nil {
+		return nil, fmt.Errorf("failed to read eval suite: %w", err)
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	var suite Suite
+	if err := decoder.Decode(&suite); err != nil {
+		return nil, fmt.Errorf("failed to parse eval suite %s: %w", path, err)
+	}
+	if strings.TrimSpace(suite.Name) == "" {
+		suite.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+
+	dir := filepath.Dir(path)
+	suite.Profile = resolvePath(dir, suite.Profile)
+	suite.MCPConfig = resolvePath(dir, suite.MCPConfig)
+	for i := range suite.Cases {
+		suite.Cases[i].Recording = resolvePath(dir, suite.Cases[i].Recording)
+	}
+	if err := suite.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid eval suite %s: %w", path, err)
+	}
+	return &suite, nil
+}
+
+func resolvePath(dir, path string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
+
+// Validate checks that cases are named uniquely, have prompts and well-formed
+// assertions
+func (s *Suite) Validate() error {
+	if s.Profile == "" && s.MCPConfig == "" {
+		return fmt.Errorf("suite needs a profile or an mcp_config")
+	}
+	if len(s.Cases) == 0 {
+		return fmt.Errorf("suite has no cases")
+	}
+	seen := make(map[string]bool, len(s.Cases))
+	for _, c := range s.Cases {
+		if strings.TrimSpace(c.Name) == "" {
+			return fmt.Errorf("case without a name")
+		}
+		if seen[c.Name] {
+			return fmt.Errorf("duplicate case %q", c.Name)
+		}
+		seen[c.Name] = true
+		if strings.TrimSpace(c.Prompt) == "" {
+			return fmt.Errorf("case %q has no prompt", c.Name)
+		}
+		for _, call := range c.ToolCalls {
+			if call.Name == "" {
+				return fmt.Errorf("case %q expects a tool call without a name", c.Name)
+			}
+		}
+		for _, assertion := range c.Assertions {
+			if err := assertion.validate(); err != nil {
+				return fmt.Errorf("case %q: %w", c.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (a Assertion) validate() error {
+	set := 0
+	for _, value := range []string{a.Contains, a.NotContains, a.Equals, a.Matches} {
+		if value != "" {
+			set++
+		}
+	}
+	if set != 1 {
+		return fmt.Errorf("assertion must set exactly one of contains, not_contains, equals, matches")
+	}
+	if a.Matches != "" {
+		if _, err := regexp.Compile(a.Matches); err != nil {
+			return fmt.Errorf("invalid matches pattern: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package evals
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeSuite(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "weather.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadSuiteResolvesPaths(t *testing.T) {
+	path := writeSuite(t, `
+mcp_config: mcp.json
+cases:
+  - name: forecast
+    prompt: Weather in Paris?
+    recording: recordings/forecast.json
+    tool_calls:
+      - name: get_forecast
+        arguments: {city: Paris, days: 1}
+    ordered_tool_calls: true
+    assertions:
+      - contains: Paris
+      - matches: '\d+'
+    max_cost_usd: 0.05
+`)
+	suite, err := LoadSuite(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := filepath.Dir(path)
+	if suite.Name != "weather" || suite.MCPConfig != filepath.Join(dir, "mcp.json") {
+		t.Errorf("suite = %+v", suite)
+	}
+	c := suite.Cases[0]
+	if c.Recording != filepath.Join(dir, "recordings", "forecast.json") || !c.OrderedToolCalls || c.MaxCostUSD != 0.05 {
+		t.Errorf("case = %+v", c)
+	}
+	if !c.ToolCalls[0].matches(ToolCall{Name: "get_forecast", Arguments: map[string]interface{}{"city": "Paris", "days": float64(1), "units": "metric"}}) {
+		t.Error("YAML arguments should match the JSON-decoded tool arguments")
+	}
+}
+
+func TestLoadSuiteRejectsInvalidSuites(t *testing.T) {
+	for name, content := range map[string]string{
+		"unknown key":         "mcp_config: m.json\ncases:\n  - name: a\n    prompt: p\n    asserts: []\n",
+		"no agent config":     "cases:\n  - name: a\n    prompt: p\n",
+		"no prompt":           "mcp_config: m.json\ncases:\n  - name: a\n",
+		"duplicate case":      "mcp_config: m.json\ncases:\n  - name: a\n    prompt: p\n  - name: a\n    prompt: p\n",
+		"empty assertion":     "mcp_config: m.json\ncases:\n  - name: a\n    prompt: p\n    assertions:\n      - {}\n",
+		"two assertion kinds": "mcp_config: m.json\ncases:\n  - name: a\n    prompt: p\n    assertions:\n      - {contains: x, equals: y}\n",
+		"invalid pattern":     "mcp_config: m.json\ncases:\n  - name: a\n    prompt: p\n    assertions:\n      - matches: '('\n",
+	} {
+		if _, err := LoadSuite(writeSuite(t, content)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestLineDiff(t *testing.T) {
+	got := lineDiff([]string{"a", "b", "c"}, []string{"a", "x", "c", "d"})
+	if want := "  a\n- b\n+ x\n  c\n+ d\n"; got != want {
+		t.Errorf("diff = %q, want %q", got, want)
+	}
+	if got := lineDiff(nil, []string{"a"}); !strings.HasPrefix(got, "+ a") {
+		t.Errorf("diff = %q", got)
+	}
+}
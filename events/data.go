@@ -2,6 +2,7 @@ package events
 
 import (
 	"crypto/rand"
+	"encoding/base64"
 	"encoding/hex"
 	"fmt"
 
@@ -32,6 +33,9 @@ type AgentEvent struct {
 	HierarchyLevel int    `json:"hierarchy_level"`      // 0=root, 1=child, 2=grandchild
 	SessionID      string `json:"session_id,omitempty"` // Group related events
 	Component      string `json:"component,omitempty"`  // orchestrator, agent, llm, tool
+
+	// ForkID identifies the forked agent (Agent.Fork) that emitted the event
+	ForkID string `json:"fork_id,omitempty"`
 }
 
 // Getter methods to implement observability.AgentEvent interface
@@ -246,12 +250,27 @@ type ConversationStartEvent struct {
 	SystemPrompt string `json:"system_prompt"`
 	ToolsCount   int    `json:"tools_count"`
 	Servers      string `json:"servers"`
+	// ToolsetHash identifies the exact tool definitions sent to the LLM
+	// (see Agent.ToolsetHash); a change between conversations explains
+	// prompt-cache misses.
+	ToolsetHash string `json:"toolset_hash,omitempty"`
+	// TraceSampling records each sampling tracer's decision for this
+	// conversation so downstream analysis can reweight sampled traces.
+	TraceSampling []TraceSamplingDecision `json:"trace_sampling,omitempty"`
 }
 
 func (e *ConversationStartEvent) GetEventType() EventType {
 	return ConversationStart
 }
 
+// TraceSamplingDecision records whether a tracer is recording a conversation.
+type TraceSamplingDecision struct {
+	Tracer  string  `json:"tracer"`
+	Sampled bool    `json:"sampled"`
+	Rate    float64 `json:"rate"`
+	Reason  string  `json:"reason"`
+}
+
 // SerializedMessage represents a message that can be properly serialized to JSON
 type SerializedMessage struct {
 	Role  string        `json:"role"`
@@ -335,6 +354,22 @@ func serializeMessage(msg llmtypes.MessageContent) SerializedMessage {
 					imageMeta["data_preview"] = "base64_encoded_image_data"
 				}
 				messagePart.Content = imageMeta
+			case llmtypes.DocumentContent:
+				messagePart.Type = "document"
+				// Metadata only, like images
+				docMeta := map[string]interface{}{
+					"source_type": p.SourceType,
+					"media_type":  p.MediaType,
+				}
+				if p.Title != "" {
+					docMeta["title"] = p.Title
+				}
+				if p.SourceType == "url" {
+					docMeta["url"] = p.Data
+				} else {
+					docMeta["data_length"] = len(p.Data)
+				}
+				messagePart.Content = docMeta
 			case llmtypes.ToolCall:
 				messagePart.Type = "tool_call"
 				messagePart.Content = map[string]interface{}{
@@ -426,6 +461,8 @@ type ToolCallEndEvent struct {
 	Duration   time.Duration `json:"duration"`
 	ServerName string        `json:"server_name"`
 	ToolCallID string        `json:"tool_call_id,omitempty"` // Unique ID from the LLM response, used to correlate start/end/error events
+	IsParallel bool          `json:"is_parallel,omitempty"`  // Executed concurrently with other calls of the same turn
+	FromCache  bool          `json:"from_cache,omitempty"`   // Served from the agent's tool result cache, without calling the MCP server
 	// Token usage information (optional)
 	ContextUsagePercent float64 `json:"context_usage_percent,omitempty"`
 	ModelContextWindow  int     `json:"model_context_window,omitempty"`
@@ -447,12 +484,23 @@ type WorkspaceFileOperationEvent struct {
 	Turn            int    `json:"turn"`
 	ServerName      string `json:"server_name"`
 	ShouldHighlight bool   `json:"should_highlight,omitempty"` // Whether to highlight this file in the UI (default: true)
+	// Git is set for git operations ("git_init", "git_status", "git_diff", "git_commit", "git_branch")
+	Git *GitOperationMetadata `json:"git,omitempty"`
 }
 
 func (e *WorkspaceFileOperationEvent) GetEventType() EventType {
 	return WorkspaceFileOperation
 }
 
+// GitOperationMetadata describes a git operation performed in the workspace
+type GitOperationMetadata struct {
+	Command      string   `json:"command"`                 // "init", "status", "diff", "commit", "branch"
+	Branch       string   `json:"branch,omitempty"`        // Current (or newly created) branch
+	CommitHash   string   `json:"commit_hash,omitempty"`   // Hash of the created commit
+	Message      string   `json:"message,omitempty"`       // Commit message
+	ChangedFiles []string `json:"changed_files,omitempty"` // Files reported by status/diff or included in the commit
+}
+
 // NewWorkspaceFileOperationEvent creates a new WorkspaceFileOperationEvent
 // shouldHighlight defaults to true if not specified (for backward compatibility)
 func NewWorkspaceFileOperationEvent(operation, filepath, folder string, turn int, serverName string, shouldHighlight ...bool) *WorkspaceFileOperationEvent {
@@ -511,6 +559,94 @@ func (e *MCPServerDiscoveryEvent) GetEventType() EventType {
 	return MCPServerDiscovery
 }
 
+// MCPServerConnectionStateEvent reports a change of the connection to an MCP
+// server mid-session: disconnected, reconnecting, reconnected, restarted or
+// reconnect_failed
+type MCPServerConnectionStateEvent struct {
+	BaseEventData
+	ServerName     string `json:"server_name"`
+	State          string `json:"state"`
+	Attempt        int    `json:"attempt,omitempty"`
+	SessionResumed bool   `json:"session_resumed,omitempty"`
+	Error          string `json:"error,omitempty"`
+}
+
+func (e *MCPServerConnectionStateEvent) GetEventType() EventType {
+	return MCPServerConnectionState
+}
+
+// NewMCPServerConnectionStateEvent creates a new MCPServerConnectionStateEvent
+func NewMCPServerConnectionStateEvent(serverName, state string, attempt int, sessionResumed bool, err string) *MCPServerConnectionStateEvent {
+	return &MCPServerConnectionStateEvent{
+		BaseEventData: BaseEventData{
+			Timestamp: time.Now(),
+		},
+		ServerName:     serverName,
+		State:          state,
+		Attempt:        attempt,
+		SessionResumed: sessionResumed,
+		Error:          err,
+	}
+}
+
+// MCPServerRestartedEvent reports that a stdio MCP server whose process
+// exited mid-session was restarted
+type MCPServerRestartedEvent struct {
+	BaseEventData
+	ServerName string        `json:"server_name"`
+	Attempt    int           `json:"attempt"`
+	Downtime   time.Duration `json:"downtime"`
+	Reason     string        `json:"reason,omitempty"`
+}
+
+func (e *MCPServerRestartedEvent) GetEventType() EventType {
+	return MCPServerRestarted
+}
+
+// NewMCPServerRestartedEvent creates a new MCPServerRestartedEvent
+func NewMCPServerRestartedEvent(serverName string, attempt int, downtime time.Duration, reason string) *MCPServerRestartedEvent {
+	return &MCPServerRestartedEvent{
+		BaseEventData: BaseEventData{
+			Timestamp: time.Now(),
+		},
+		ServerName: serverName,
+		Attempt:    attempt,
+		Downtime:   downtime,
+		Reason:     reason,
+	}
+}
+
+// MCPConfigReloadedEvent reports a reload of the MCP server configuration.
+// Error is set when the reload failed and the previous servers stay in use.
+type MCPConfigReloadedEvent struct {
+	BaseEventData
+	ConfigPath string   `json:"config_path,omitempty"`
+	Added      []string `json:"added,omitempty"`
+	Removed    []string `json:"removed,omitempty"`
+	Modified   []string `json:"modified,omitempty"`
+	ToolCount  int      `json:"tool_count"`
+	Error      string   `json:"error,omitempty"`
+}
+
+func (e *MCPConfigReloadedEvent) GetEventType() EventType {
+	return MCPConfigReloaded
+}
+
+// NewMCPConfigReloadedEvent creates a new MCPConfigReloadedEvent
+func NewMCPConfigReloadedEvent(configPath string, added, removed, modified []string, toolCount int, err string) *MCPConfigReloadedEvent {
+	return &MCPConfigReloadedEvent{
+		BaseEventData: BaseEventData{
+			Timestamp: time.Now(),
+		},
+		ConfigPath: configPath,
+		Added:      added,
+		Removed:    removed,
+		Modified:   modified,
+		ToolCount:  toolCount,
+		Error:      err,
+	}
+}
+
 // MCPServerSelectionEvent represents MCP server selection for a query
 type MCPServerSelectionEvent struct {
 	BaseEventData
@@ -525,6 +661,29 @@ func (e *MCPServerSelectionEvent) GetEventType() EventType {
 	return MCPServerSelection
 }
 
+// RoutingScore is the similarity of an MCP server to a routed query
+type RoutingScore struct {
+	Server string  `json:"server"`
+	Score  float64 `json:"score"`
+}
+
+// RoutingDecisionEvent reports the MCP servers smart routing offered for a
+// conversation and how they were chosen
+type RoutingDecisionEvent struct {
+	BaseEventData
+	Query      string         `json:"query"`
+	Scores     []RoutingScore `json:"scores,omitempty"`     // Embedding similarity of every server, best first
+	Candidates []string       `json:"candidates,omitempty"` // Servers kept by the embedding pre-filter
+	Selected   []string       `json:"selected"`
+	Method     string         `json:"method"` // "embedding", "llm" or "cached"
+	Duration   time.Duration  `json:"duration"`
+	Error      string         `json:"error,omitempty"`
+}
+
+func (e *RoutingDecisionEvent) GetEventType() EventType {
+	return RoutingDecision
+}
+
 // ConversationEndEvent represents the end of a conversation
 type ConversationEndEvent struct {
 	BaseEventData
@@ -534,6 +693,11 @@ type ConversationEndEvent struct {
 	Turns    int           `json:"turns"`
 	Status   string        `json:"status"`
 	Error    string        `json:"error,omitempty"`
+
+	// ModelID and Provider of the model that answered, a fallback model when
+	// the primary failed
+	ModelID  string `json:"model_id,omitempty"`
+	Provider string `json:"provider,omitempty"`
 }
 
 func (e *ConversationEndEvent) GetEventType() EventType {
@@ -548,6 +712,9 @@ type ConversationErrorEvent struct {
 	Turn     int           `json:"turn"`
 	Context  string        `json:"context"`
 	Duration time.Duration `json:"duration"`
+
+	// ErrorCode classifies the error, e.g. "llm_rate_limited" (see mcpagent.ErrorCode)
+	ErrorCode string `json:"error_code,omitempty"`
 }
 
 func (e *ConversationErrorEvent) GetEventType() EventType {
@@ -576,12 +743,380 @@ type ToolCallErrorEvent struct {
 	ServerName string        `json:"server_name"`
 	Duration   time.Duration `json:"duration"`
 	ToolCallID string        `json:"tool_call_id,omitempty"` // Unique ID from the LLM response, used to correlate start/end/error events
+	IsParallel bool          `json:"is_parallel,omitempty"`  // Executed concurrently with other calls of the same turn
 }
 
 func (e *ToolCallErrorEvent) GetEventType() EventType {
 	return ToolCallError
 }
 
+// SubAgentStartEvent reports a task delegated to a sub-agent. The sub-agent's
+// own events follow it, nested one level below in the hierarchy.
+type SubAgentStartEvent struct {
+	BaseEventData
+	SubAgentID string `json:"sub_agent_id"`
+	Task       string `json:"task"`
+	ModelID    string `json:"model_id,omitempty"`
+}
+
+func (e *SubAgentStartEvent) GetEventType() EventType {
+	return SubAgentStart
+}
+
+// NewSubAgentStartEvent creates a new SubAgentStartEvent
+func NewSubAgentStartEvent(subAgentID, task, modelID string) *SubAgentStartEvent {
+	return &SubAgentStartEvent{
+		BaseEventData: BaseEventData{
+			Timestamp: time.Now(),
+		},
+		SubAgentID: subAgentID,
+		Task:       task,
+		ModelID:    modelID,
+	}
+}
+
+// SubAgentEndEvent reports the outcome of a delegated task and the usage
+// rolled up into the parent's totals
+type SubAgentEndEvent struct {
+	BaseEventData
+	SubAgentID       string        `json:"sub_agent_id"`
+	ModelID          string        `json:"model_id,omitempty"`
+	Result           string        `json:"result,omitempty"`
+	Error            string        `json:"error,omitempty"`
+	Duration         time.Duration `json:"duration"`
+	PromptTokens     int           `json:"prompt_tokens"`
+	CompletionTokens int           `json:"completion_tokens"`
+	TotalTokens      int           `json:"total_tokens"`
+	LLMCallCount     int           `json:"llm_call_count"`
+	TotalCost        float64       `json:"total_cost,omitempty"`
+}
+
+func (e *SubAgentEndEvent) GetEventType() EventType {
+	return SubAgentEnd
+}
+
+// OrchestratorStartEvent reports the start of a task graph run. The tasks'
+// step and sub-agent events are nested below it.
+type OrchestratorStartEvent struct {
+	BaseEventData
+	OrchestrationID string   `json:"orchestration_id"`
+	Tasks           []string `json:"tasks"`
+}
+
+func (e *OrchestratorStartEvent) GetEventType() EventType {
+	return OrchestratorStart
+}
+
+// OrchestratorEndEvent reports the outcome of a task graph run
+type OrchestratorEndEvent struct {
+	BaseEventData
+	OrchestrationID string        `json:"orchestration_id"`
+	Duration        time.Duration `json:"duration"`
+	Completed       []string      `json:"completed,omitempty"`
+	Failed          []string      `json:"failed,omitempty"`
+	Skipped         []string      `json:"skipped,omitempty"`
+	TotalTokens     int           `json:"total_tokens"`
+	TotalCost       float64       `json:"total_cost,omitempty"`
+}
+
+func (e *OrchestratorEndEvent) GetEventType() EventType {
+	return OrchestratorEnd
+}
+
+// StepExecutionStartEvent reports a task of a task graph starting once its
+// dependencies completed
+type StepExecutionStartEvent struct {
+	BaseEventData
+	OrchestrationID string   `json:"orchestration_id"`
+	StepID          string   `json:"step_id"`
+	DependsOn       []string `json:"depends_on,omitempty"`
+	ModelID         string   `json:"model_id,omitempty"`
+}
+
+func (e *StepExecutionStartEvent) GetEventType() EventType {
+	return StepExecutionStart
+}
+
+// StepExecutionEndEvent reports a completed task of a task graph and the
+// sub-agent that ran it
+type StepExecutionEndEvent struct {
+	BaseEventData
+	OrchestrationID string        `json:"orchestration_id"`
+	StepID          string        `json:"step_id"`
+	SubAgentID      string        `json:"sub_agent_id,omitempty"`
+	Duration        time.Duration `json:"duration"`
+	TotalTokens     int           `json:"total_tokens"`
+	TotalCost       float64       `json:"total_cost,omitempty"`
+}
+
+func (e *StepExecutionEndEvent) GetEventType() EventType {
+	return StepExecutionEnd
+}
+
+// StepExecutionFailedEvent reports a failed task of a task graph, or one
+// skipped because a dependency failed
+type StepExecutionFailedEvent struct {
+	BaseEventData
+	OrchestrationID string        `json:"orchestration_id"`
+	StepID          string        `json:"step_id"`
+	SubAgentID      string        `json:"sub_agent_id,omitempty"`
+	Error           string        `json:"error"`
+	Skipped         bool          `json:"skipped,omitempty"`
+	Duration        time.Duration `json:"duration"`
+}
+
+func (e *StepExecutionFailedEvent) GetEventType() EventType {
+	return StepExecutionFailed
+}
+
+// ToolDisabledForConversationEvent reports a tool removed from the toolset for
+// the rest of a conversation because it failed too often
+type ToolDisabledForConversationEvent struct {
+	BaseEventData
+	Turn       int    `json:"turn"`
+	ToolName   string `json:"tool_name"`
+	ServerName string `json:"server_name,omitempty"`
+	Failures   int    `json:"failures"`
+	LastError  string `json:"last_error,omitempty"`
+}
+
+func (e *ToolDisabledForConversationEvent) GetEventType() EventType {
+	return ToolDisabledForConversation
+}
+
+// NewToolDisabledForConversationEvent creates a new ToolDisabledForConversationEvent
+func NewToolDisabledForConversationEvent(turn int, toolName, serverName string, failures int, lastError string) *ToolDisabledForConversationEvent {
+	return &ToolDisabledForConversationEvent{
+		BaseEventData: BaseEventData{
+			Timestamp: time.Now(),
+		},
+		Turn:       turn,
+		ToolName:   toolName,
+		ServerName: serverName,
+		Failures:   failures,
+		LastError:  lastError,
+	}
+}
+
+// ToolArgumentViolation is one argument over its size limit. An empty
+// Argument means the arguments together exceeded the total limit.
+type ToolArgumentViolation struct {
+	Argument   string `json:"argument,omitempty"`
+	SizeBytes  int    `json:"size_bytes"`
+	LimitBytes int    `json:"limit_bytes"`
+}
+
+// ToolArgumentLimitedEvent reports a tool call whose arguments exceeded their
+// size limits. Action is "reject" (the tool was not run) or "truncate".
+type ToolArgumentLimitedEvent struct {
+	BaseEventData
+	Turn       int                     `json:"turn"`
+	ToolName   string                  `json:"tool_name"`
+	ServerName string                  `json:"server_name,omitempty"`
+	ToolCallID string                  `json:"tool_call_id,omitempty"`
+	Action     string                  `json:"action"`
+	Violations []ToolArgumentViolation `json:"violations"`
+}
+
+func (e *ToolArgumentLimitedEvent) GetEventType() EventType {
+	return ToolArgumentLimited
+}
+
+// NewToolArgumentLimitedEvent creates a new ToolArgumentLimitedEvent
+func NewToolArgumentLimitedEvent(turn int, toolName, serverName, toolCallID, action string, violations []ToolArgumentViolation) *ToolArgumentLimitedEvent {
+	return &ToolArgumentLimitedEvent{
+		BaseEventData: BaseEventData{
+			Timestamp: time.Now(),
+		},
+		Turn:       turn,
+		ToolName:   toolName,
+		ServerName: serverName,
+		ToolCallID: toolCallID,
+		Action:     action,
+		Violations: violations,
+	}
+}
+
+// FolderGuardBlockedEvent reports a filesystem tool call rejected because a
+// path it accesses is outside the folder guard paths
+type FolderGuardBlockedEvent struct {
+	BaseEventData
+	Turn       int    `json:"turn"`
+	ToolName   string `json:"tool_name"`
+	ServerName string `json:"server_name,omitempty"`
+	ToolCallID string `json:"tool_call_id,omitempty"`
+	Path       string `json:"path"`
+	Access     string `json:"access"` // "read" or "write"
+}
+
+func (e *FolderGuardBlockedEvent) GetEventType() EventType {
+	return FolderGuardBlocked
+}
+
+// NewFolderGuardBlockedEvent creates a new FolderGuardBlockedEvent
+func NewFolderGuardBlockedEvent(turn int, toolName, serverName, toolCallID, path, access string) *FolderGuardBlockedEvent {
+	return &FolderGuardBlockedEvent{
+		BaseEventData: BaseEventData{
+			Timestamp: time.Now(),
+		},
+		Turn:       turn,
+		ToolName:   toolName,
+		ServerName: serverName,
+		ToolCallID: toolCallID,
+		Path:       path,
+		Access:     access,
+	}
+}
+
+// ToolArgsValidationFailedEvent reports a tool call not run because its
+// arguments did not match the tool's input schema
+type ToolArgsValidationFailedEvent struct {
+	BaseEventData
+	Turn       int      `json:"turn"`
+	ToolName   string   `json:"tool_name"`
+	ServerName string   `json:"server_name,omitempty"`
+	ToolCallID string   `json:"tool_call_id,omitempty"`
+	Fields     []string `json:"fields"`   // Arguments at fault, e.g. "query" or "filters.limit"
+	Problems   []string `json:"problems"` // One message per violation, as sent to the LLM
+}
+
+func (e *ToolArgsValidationFailedEvent) GetEventType() EventType {
+	return ToolArgsValidationFailed
+}
+
+// NewToolArgsValidationFailedEvent creates a new ToolArgsValidationFailedEvent
+func NewToolArgsValidationFailedEvent(turn int, toolName, serverName, toolCallID string, fields, problems []string) *ToolArgsValidationFailedEvent {
+	return &ToolArgsValidationFailedEvent{
+		BaseEventData: BaseEventData{
+			Timestamp: time.Now(),
+		},
+		Turn:       turn,
+		ToolName:   toolName,
+		ServerName: serverName,
+		ToolCallID: toolCallID,
+		Fields:     fields,
+		Problems:   problems,
+	}
+}
+
+// DryRunToolBlockedEvent reports a write tool call that was not run because
+// the agent is in dry-run mode; the LLM got a simulated result instead
+type DryRunToolBlockedEvent struct {
+	BaseEventData
+	Turn           int    `json:"turn"`
+	ToolName       string `json:"tool_name"`
+	ServerName     string `json:"server_name,omitempty"`
+	ToolCallID     string `json:"tool_call_id,omitempty"`
+	Arguments      string `json:"arguments,omitempty"`
+	Classification string `json:"classification"` // Why the tool counts as a write: "config", "name", "category" or "unknown"
+}
+
+func (e *DryRunToolBlockedEvent) GetEventType() EventType {
+	return DryRunToolBlocked
+}
+
+// NewDryRunToolBlockedEvent creates a new DryRunToolBlockedEvent
+func NewDryRunToolBlockedEvent(turn int, toolName, serverName, toolCallID, arguments, classification string) *DryRunToolBlockedEvent {
+	return &DryRunToolBlockedEvent{
+		BaseEventData: BaseEventData{
+			Timestamp: time.Now(),
+		},
+		Turn:           turn,
+		ToolName:       toolName,
+		ServerName:     serverName,
+		ToolCallID:     toolCallID,
+		Arguments:      arguments,
+		Classification: classification,
+	}
+}
+
+// PlanStep is one step of the agent's task checklist
+type PlanStep struct {
+	Title  string `json:"title"`
+	Status string `json:"status"` // "pending", "in_progress" or "completed"
+}
+
+// PlanCreatedEvent reports the plan the LLM set with update_plan, when it is
+// first created or its steps change
+type PlanCreatedEvent struct {
+	BaseEventData
+	Turn        int        `json:"turn"`
+	Steps       []PlanStep `json:"steps"`
+	Explanation string     `json:"explanation,omitempty"`
+	Revision    int        `json:"revision"` // 1 for the first plan, incremented on every change of steps
+}
+
+func (e *PlanCreatedEvent) GetEventType() EventType {
+	return PlanCreated
+}
+
+// NewPlanCreatedEvent creates a new PlanCreatedEvent
+func NewPlanCreatedEvent(turn int, steps []PlanStep, explanation string, revision int) *PlanCreatedEvent {
+	return &PlanCreatedEvent{
+		BaseEventData: BaseEventData{
+			Timestamp: time.Now(),
+		},
+		Turn:        turn,
+		Steps:       steps,
+		Explanation: explanation,
+		Revision:    revision,
+	}
+}
+
+// PlanStepStartedEvent reports that the LLM started a step of its plan
+type PlanStepStartedEvent struct {
+	BaseEventData
+	Turn       int    `json:"turn"`
+	StepIndex  int    `json:"step_index"` // 0-based position in the plan
+	Title      string `json:"title"`
+	TotalSteps int    `json:"total_steps"`
+}
+
+func (e *PlanStepStartedEvent) GetEventType() EventType {
+	return PlanStepStarted
+}
+
+// NewPlanStepStartedEvent creates a new PlanStepStartedEvent
+func NewPlanStepStartedEvent(turn, stepIndex int, title string, totalSteps int) *PlanStepStartedEvent {
+	return &PlanStepStartedEvent{
+		BaseEventData: BaseEventData{
+			Timestamp: time.Now(),
+		},
+		Turn:       turn,
+		StepIndex:  stepIndex,
+		Title:      title,
+		TotalSteps: totalSteps,
+	}
+}
+
+// PlanStepCompletedEvent reports that the LLM completed a step of its plan
+type PlanStepCompletedEvent struct {
+	BaseEventData
+	Turn           int    `json:"turn"`
+	StepIndex      int    `json:"step_index"` // 0-based position in the plan
+	Title          string `json:"title"`
+	TotalSteps     int    `json:"total_steps"`
+	CompletedSteps int    `json:"completed_steps"` // Steps completed so far, this one included
+}
+
+func (e *PlanStepCompletedEvent) GetEventType() EventType {
+	return PlanStepCompleted
+}
+
+// NewPlanStepCompletedEvent creates a new PlanStepCompletedEvent
+func NewPlanStepCompletedEvent(turn, stepIndex int, title string, totalSteps, completedSteps int) *PlanStepCompletedEvent {
+	return &PlanStepCompletedEvent{
+		BaseEventData: BaseEventData{
+			Timestamp: time.Now(),
+		},
+		Turn:           turn,
+		StepIndex:      stepIndex,
+		Title:          title,
+		TotalSteps:     totalSteps,
+		CompletedSteps: completedSteps,
+	}
+}
+
 // TokenUsageEvent represents detailed token usage information
 type TokenUsageEvent struct {
 	BaseEventData
@@ -695,12 +1230,50 @@ type UserMessageEvent struct {
 	Turn    int    `json:"turn"`
 	Content string `json:"content"`
 	Role    string `json:"role"`
+	// Attachments describes the images and documents sent with the message (metadata only)
+	Attachments []AttachmentInfo `json:"attachments,omitempty"`
 }
 
 func (e *UserMessageEvent) GetEventType() EventType {
 	return UserMessage
 }
 
+// AttachmentInfo describes an image or document part of a message without its data
+type AttachmentInfo struct {
+	Type       string `json:"type"` // "image" or "document"
+	MediaType  string `json:"media_type,omitempty"`
+	SourceType string `json:"source_type"`          // "base64" or "url"
+	Name       string `json:"name,omitempty"`       // Document title
+	URL        string `json:"url,omitempty"`        // For SourceType "url"
+	Size       int    `json:"size_bytes,omitempty"` // Approximate decoded size for SourceType "base64"
+}
+
+// AttachmentInfoFromParts returns the metadata of the image and document parts
+func AttachmentInfoFromParts(parts []llmtypes.ContentPart) []AttachmentInfo {
+	var infos []AttachmentInfo
+	for _, part := range parts {
+		var info AttachmentInfo
+		var data string
+		switch p := part.(type) {
+		case llmtypes.ImageContent:
+			info = AttachmentInfo{Type: "image", MediaType: p.MediaType, SourceType: p.SourceType}
+			data = p.Data
+		case llmtypes.DocumentContent:
+			info = AttachmentInfo{Type: "document", MediaType: p.MediaType, SourceType: p.SourceType, Name: p.Title}
+			data = p.Data
+		default:
+			continue
+		}
+		if info.SourceType == "url" {
+			info.URL = data
+		} else {
+			info.Size = base64.StdEncoding.DecodedLen(len(data))
+		}
+		infos = append(infos, info)
+	}
+	return infos
+}
+
 // NewUserMessageEvent creates a new UserMessageEvent
 func NewUserMessageEvent(turn int, content, role string) *UserMessageEvent {
 	return &UserMessageEvent{
@@ -939,6 +1512,31 @@ func NewToolCallStartEventWithCorrelation(turn int, toolName string, toolParams
 	}
 }
 
+// NewToolCallProgressEvent creates a new ToolCallProgressEvent for a running
+// tool that reported current of total (0 = unknown) with an optional message
+func NewToolCallProgressEvent(turn int, toolName, serverName string, current, total float64, message string, elapsed time.Duration) *ToolCallProgressEvent {
+	progress := 0
+	if total > 0 {
+		progress = int(current / total * 100)
+		progress = max(0, min(progress, 100))
+	}
+	return &ToolCallProgressEvent{
+		BaseEventData: BaseEventData{
+			Timestamp: time.Now(),
+			EventID:   GenerateEventID(),
+		},
+		Turn:        turn,
+		ToolName:    toolName,
+		ServerName:  serverName,
+		Progress:    progress,
+		Current:     current,
+		Total:       total,
+		Status:      "running",
+		Message:     message,
+		ElapsedTime: elapsed.Round(time.Millisecond).String(),
+	}
+}
+
 // NewToolCallEndEvent creates a new ToolCallEndEvent
 func NewToolCallEndEvent(turn int, toolName, result, serverName string, duration time.Duration, spanID string) *ToolCallEndEvent {
 	return &ToolCallEndEvent{
@@ -1432,6 +2030,182 @@ func NewContextEditingErrorEvent(err string, totalMessages, tokenThreshold, turn
 	}
 }
 
+// Tool result deduplication events
+
+// DeduplicatedToolResult describes one tool result replaced by a reference
+type DeduplicatedToolResult struct {
+	ToolName        string `json:"tool_name"`
+	ToolCallID      string `json:"tool_call_id"`
+	FirstToolName   string `json:"first_tool_name"`    // Tool that produced the first occurrence
+	FirstToolCallID string `json:"first_tool_call_id"` // Call ID of the first occurrence
+	TokensSaved     int    `json:"tokens_saved"`
+}
+
+// ToolResultsDeduplicatedEvent reports tool results replaced by references to
+// an identical earlier result
+type ToolResultsDeduplicatedEvent struct {
+	BaseEventData
+	CurrentTurn       int                      `json:"current_turn"`
+	DeduplicatedCount int                      `json:"deduplicated_count"`
+	TokensSaved       int                      `json:"tokens_saved"`
+	TotalTokensSaved  int                      `json:"total_tokens_saved"` // Cumulative for the agent
+	Duplicates        []DeduplicatedToolResult `json:"duplicates,omitempty"`
+}
+
+func (e *ToolResultsDeduplicatedEvent) GetEventType() EventType {
+	return ToolResultsDeduplicated
+}
+
+func NewToolResultsDeduplicatedEvent(currentTurn, tokensSaved, totalTokensSaved int, duplicates []DeduplicatedToolResult) *ToolResultsDeduplicatedEvent {
+	return &ToolResultsDeduplicatedEvent{
+		BaseEventData: BaseEventData{
+			Timestamp: time.Now(),
+		},
+		CurrentTurn:       currentTurn,
+		DeduplicatedCount: len(duplicates),
+		TokensSaved:       tokensSaved,
+		TotalTokensSaved:  totalTokensSaved,
+		Duplicates:        duplicates,
+	}
+}
+
+// Final-answer grounding events
+
+// GroundingClaim is one factual claim of a final answer and its verdict
+type GroundingClaim struct {
+	Claim     string `json:"claim"`
+	Supported bool   `json:"supported"`
+	Evidence  string `json:"evidence,omitempty"` // Supporting tool output, or why the claim is unsupported
+}
+
+// GroundingReport is the result of checking a final answer against the tool
+// outputs collected in the conversation
+type GroundingReport struct {
+	Model             string           `json:"model"` // Model that ran the check
+	Claims            []GroundingClaim `json:"claims,omitempty"`
+	UnsupportedClaims int              `json:"unsupported_claims"`
+	EvidenceCount     int              `json:"evidence_count"`    // Tool results given to the checker
+	Reasked           bool             `json:"reasked,omitempty"` // The answer was revised after an earlier check
+	Error             string           `json:"error,omitempty"`   // Set when the check could not be completed
+}
+
+// GroundingCheckEvent reports a grounding check of a final answer
+type GroundingCheckEvent struct {
+	BaseEventData
+	CurrentTurn int             `json:"current_turn"`
+	Report      GroundingReport `json:"report"`
+}
+
+func (e *GroundingCheckEvent) GetEventType() EventType {
+	return GroundingCheck
+}
+
+func NewGroundingCheckEvent(currentTurn int, report GroundingReport) *GroundingCheckEvent {
+	return &GroundingCheckEvent{
+		BaseEventData: BaseEventData{
+			Timestamp: time.Now(),
+		},
+		CurrentTurn: currentTurn,
+		Report:      report,
+	}
+}
+
+// AnswerContractCheckEvent reports a check of a final answer against the
+// agent's answer contract
+type AnswerContractCheckEvent struct {
+	BaseEventData
+	CurrentTurn int      `json:"current_turn"`
+	Mode        string   `json:"mode"` // "strict" or "lenient"
+	Satisfied   bool     `json:"satisfied"`
+	Violations  []string `json:"violations,omitempty"`
+	Repairs     int      `json:"repairs"`                     // Repair re-asks before this check
+	Exhausted   bool     `json:"repairs_exhausted,omitempty"` // No repairs left; the conversation fails
+}
+
+func (e *AnswerContractCheckEvent) GetEventType() EventType {
+	return AnswerContractCheck
+}
+
+func NewAnswerContractCheckEvent(currentTurn int, mode string, violations []string, repairs int, exhausted bool) *AnswerContractCheckEvent {
+	return &AnswerContractCheckEvent{
+		BaseEventData: BaseEventData{
+			Timestamp: time.Now(),
+		},
+		CurrentTurn: currentTurn,
+		Mode:        mode,
+		Satisfied:   len(violations) == 0,
+		Violations:  violations,
+		Repairs:     repairs,
+		Exhausted:   exhausted,
+	}
+}
+
+// OutputValidationFailedEvent reports a final answer rejected by the agent's
+// output validator
+type OutputValidationFailedEvent struct {
+	BaseEventData
+	CurrentTurn int    `json:"current_turn"`
+	Error       string `json:"error"`
+	Repairs     int    `json:"repairs"`                     // Repair re-asks before this check
+	Exhausted   bool   `json:"repairs_exhausted,omitempty"` // No repairs left; the conversation fails
+}
+
+func (e *OutputValidationFailedEvent) GetEventType() EventType {
+	return OutputValidationFailed
+}
+
+func NewOutputValidationFailedEvent(currentTurn int, err string, repairs int, exhausted bool) *OutputValidationFailedEvent {
+	return &OutputValidationFailedEvent{
+		BaseEventData: BaseEventData{
+			Timestamp: time.Now(),
+		},
+		CurrentTurn: currentTurn,
+		Error:       err,
+		Repairs:     repairs,
+		Exhausted:   exhausted,
+	}
+}
+
+// OutputValidationRepairedEvent reports a final answer that passed the output
+// validator after being sent back for repair
+type OutputValidationRepairedEvent struct {
+	BaseEventData
+	CurrentTurn int `json:"current_turn"`
+	Repairs     int `json:"repairs"`
+}
+
+func (e *OutputValidationRepairedEvent) GetEventType() EventType {
+	return OutputValidationRepaired
+}
+
+func NewOutputValidationRepairedEvent(currentTurn, repairs int) *OutputValidationRepairedEvent {
+	return &OutputValidationRepairedEvent{
+		BaseEventData: BaseEventData{
+			Timestamp: time.Now(),
+		},
+		CurrentTurn: currentTurn,
+		Repairs:     repairs,
+	}
+}
+
+// StorageCleanupEvent reports files removed (or, in dry-run mode, that would
+// be removed) from a tool output or workspace folder by a retention policy
+type StorageCleanupEvent struct {
+	BaseEventData
+	Root         string   `json:"root"`
+	Reason       string   `json:"reason"` // "max_age", "max_total_size" or "session_end"
+	DryRun       bool     `json:"dry_run,omitempty"`
+	FilesDeleted int      `json:"files_deleted"`
+	BytesFreed   int64    `json:"bytes_freed"`
+	Paths        []string `json:"paths,omitempty"` // First deleted paths, capped
+	Kept         int      `json:"kept,omitempty"`  // Registered artifacts that were spared
+	Errors       int      `json:"errors,omitempty"`
+}
+
+func (e *StorageCleanupEvent) GetEventType() EventType {
+	return StorageCleanup
+}
+
 func NewLargeToolOutputServerUnavailableEvent(toolName string, outputSize int, serverName, reason string) *LargeToolOutputServerUnavailableEvent {
 	return &LargeToolOutputServerUnavailableEvent{
 		BaseEventData: BaseEventData{
@@ -1492,6 +2266,22 @@ func (e *ThrottlingDetectedEvent) GetEventType() EventType {
 	return ThrottlingDetected
 }
 
+// RateLimitWaitEvent is emitted when an LLM call waits for room under the
+// provider's process-wide rate limit (llm.Config.RateLimits)
+type RateLimitWaitEvent struct {
+	BaseEventData
+	Turn     int    `json:"turn"`
+	ModelID  string `json:"model_id"`
+	Provider string `json:"provider"`
+	Reason   string `json:"reason"` // "requests_per_minute" or "tokens_per_minute"
+	Wait     string `json:"wait"`   // Time the call waits (e.g., "12.5s")
+	Queued   int    `json:"queued"` // Calls waiting for the provider, including this one
+}
+
+func (e *RateLimitWaitEvent) GetEventType() EventType {
+	return RateLimitWait
+}
+
 // TokenLimitExceededEvent represents when token limits are exceeded
 type TokenLimitExceededEvent struct {
 	BaseEventData
@@ -1508,6 +2298,51 @@ func (e *TokenLimitExceededEvent) GetEventType() EventType {
 	return TokenLimitExceeded
 }
 
+// MemoryPressureEvent represents eviction of an agent's retained session state
+// because its agent pool exceeded the configured memory ceiling
+type MemoryPressureEvent struct {
+	BaseEventData
+	Policy       string `json:"policy"`                  // "spill" or "drop"
+	UsageBytes   int64  `json:"usage_bytes"`             // Agent's retained state before eviction
+	LimitBytes   int64  `json:"limit_bytes"`             // Pool memory ceiling that was exceeded
+	FreedBytes   int64  `json:"freed_bytes"`             // Retained state released (0 if eviction failed)
+	CheckpointID string `json:"checkpoint_id,omitempty"` // Checkpoint the history was spilled to
+	Error        string `json:"error,omitempty"`         // Why spilling failed
+}
+
+func (e *MemoryPressureEvent) GetEventType() EventType {
+	return MemoryPressure
+}
+
+// MemoryReadEvent reports the long-term memory facts recalled for a
+// conversation and injected into the system prompt
+type MemoryReadEvent struct {
+	BaseEventData
+	Query    string        `json:"query"`
+	TopK     int           `json:"top_k"`
+	FactIDs  []string      `json:"fact_ids,omitempty"`
+	Facts    []string      `json:"facts,omitempty"`
+	Duration time.Duration `json:"duration"`
+	Error    string        `json:"error,omitempty"`
+}
+
+func (e *MemoryReadEvent) GetEventType() EventType {
+	return MemoryRead
+}
+
+// MemoryWriteEvent reports the durable facts extracted from a conversation
+// and saved to long-term memory
+type MemoryWriteEvent struct {
+	BaseEventData
+	Facts    []string      `json:"facts,omitempty"`
+	Duration time.Duration `json:"duration"`
+	Error    string        `json:"error,omitempty"`
+}
+
+func (e *MemoryWriteEvent) GetEventType() EventType {
+	return MemoryWrite
+}
+
 // NewModelChangeEvent creates a new ModelChangeEvent
 func NewModelChangeEvent(turn int, oldModelID, newModelID, reason, provider string, duration time.Duration) *ModelChangeEvent {
 	return &ModelChangeEvent{
@@ -1642,6 +2477,31 @@ func NewMaxTurnsReachedEvent(turn, maxTurns int, question, finalMessage, agentMo
 	}
 }
 
+// BudgetExceededEvent represents when a conversation is aborted because the
+// agent's cumulative cost crossed its budget limit
+type BudgetExceededEvent struct {
+	BaseEventData
+	Turn     int     `json:"turn"`
+	LimitUSD float64 `json:"limit_usd"`
+	SpentUSD float64 `json:"spent_usd"`
+}
+
+func (e *BudgetExceededEvent) GetEventType() EventType {
+	return BudgetExceeded
+}
+
+// NewBudgetExceededEvent creates a new BudgetExceededEvent
+func NewBudgetExceededEvent(turn int, limitUSD, spentUSD float64) *BudgetExceededEvent {
+	return &BudgetExceededEvent{
+		BaseEventData: BaseEventData{
+			Timestamp: time.Now(),
+		},
+		Turn:     turn,
+		LimitUSD: limitUSD,
+		SpentUSD: spentUSD,
+	}
+}
+
 // ContextCancelledEvent represents when a conversation is cancelled due to context cancellation
 type ContextCancelledEvent struct {
 	BaseEventData
@@ -1842,6 +2702,8 @@ type UnifiedCompletionEvent struct {
 	Turns       int                    `json:"turns"`              // Number of conversation turns
 	Error       string                 `json:"error,omitempty"`    // Error message if status is error
 	Metadata    map[string]interface{} `json:"metadata,omitempty"` // Additional context
+	// Grounding is the check of the final answer against tool evidence, when enabled
+	Grounding *GroundingReport `json:"grounding,omitempty"`
 }
 
 func (e *UnifiedCompletionEvent) GetEventType() EventType {
@@ -1923,6 +2785,21 @@ func (e *StructuredOutputErrorEvent) GetEventType() EventType {
 	return StructuredOutputError
 }
 
+// StructuredOutputCapturedEvent is emitted when the structured output tool of
+// a streaming AskWithHistoryStructuredViaTool call was called. The
+// conversation ends normally after it, so stream consumers see a clean end.
+type StructuredOutputCapturedEvent struct {
+	BaseEventData
+	ToolName   string `json:"tool_name"`
+	ToolCallID string `json:"tool_call_id,omitempty"`
+	Arguments  string `json:"arguments"` // JSON arguments of the tool call
+	Turn       int    `json:"turn"`
+}
+
+func (e *StructuredOutputCapturedEvent) GetEventType() EventType {
+	return StructuredOutputCaptured
+}
+
 // =============================================================================
 // STREAMING EVENTS
 // =============================================================================
@@ -1951,6 +2828,26 @@ func (e *StreamingChunkEvent) GetEventType() EventType {
 	return StreamingChunk
 }
 
+// StreamingStructuredChunkEvent carries the partial object of a structured
+// output answer that is streamed as JSON, parsed from the content chunks
+// received so far
+type StreamingStructuredChunkEvent struct {
+	BaseEventData
+	// Objects, arrays and strings as far as they have been written; numbers,
+	// booleans and nulls once complete
+	Partial interface{} `json:"partial"`
+	// Paths of the values completed by this chunk, e.g. "title" or "items.0.name"
+	CompletedFields []string `json:"completed_fields,omitempty"`
+	// Path of the string value still being written, if any
+	PendingField string `json:"pending_field,omitempty"`
+	ChunkIndex   int    `json:"chunk_index"`
+	Done         bool   `json:"done"` // The root value is complete
+}
+
+func (e *StreamingStructuredChunkEvent) GetEventType() EventType {
+	return StreamingStructuredChunk
+}
+
 // StreamingEndEvent represents the end of a streaming response
 type StreamingEndEvent struct {
 	BaseEventData
@@ -2156,6 +3053,55 @@ func (e *MCPServerConnectionErrorEvent) GetEventType() EventType {
 	return MCPServerConnectionError
 }
 
+// MCPSamplingRequestEvent reports a sampling/createMessage request of an MCP
+// server, emitted before the approval hook runs
+type MCPSamplingRequestEvent struct {
+	BaseEventData
+	ServerName   string   `json:"server_name"`
+	MessageCount int      `json:"message_count"`
+	SystemPrompt string   `json:"system_prompt,omitempty"`
+	MaxTokens    int      `json:"max_tokens,omitempty"`
+	ModelHints   []string `json:"model_hints,omitempty"`
+}
+
+func (e *MCPSamplingRequestEvent) GetEventType() EventType {
+	return MCPSamplingRequest
+}
+
+// MCPSamplingResponseEvent reports the outcome of a sampling request
+type MCPSamplingResponseEvent struct {
+	BaseEventData
+	ServerName string        `json:"server_name"`
+	Approved   bool          `json:"approved"`
+	Model      string        `json:"model,omitempty"`
+	Response   string        `json:"response,omitempty"` // Truncated completion text
+	Usage      UsageMetrics  `json:"usage"`
+	Duration   time.Duration `json:"duration"`
+	Error      string        `json:"error,omitempty"`
+}
+
+func (e *MCPSamplingResponseEvent) GetEventType() EventType {
+	return MCPSamplingResponse
+}
+
+// ToolOutputFlaggedEvent reports a tool result flagged as prompt injection,
+// by rule or by the classifier model, and the policy applied to it
+type ToolOutputFlaggedEvent struct {
+	BaseEventData
+	ToolName   string   `json:"tool_name"`
+	ServerName string   `json:"server_name,omitempty"`
+	Turn       int      `json:"turn"`
+	Policy     string   `json:"policy"`               // "block", "annotate" or "allow"
+	Rules      []string `json:"rules,omitempty"`      // Names of the matching rules
+	Excerpt    string   `json:"excerpt,omitempty"`    // Text of the first rule match
+	Classifier string   `json:"classifier,omitempty"` // Model that flagged the output
+	Reason     string   `json:"reason,omitempty"`     // Classifier's explanation
+}
+
+func (e *ToolOutputFlaggedEvent) GetEventType() EventType {
+	return ToolOutputFlagged
+}
+
 // =============================================================================
 // JSON VALIDATION EVENTS
 // =============================================================================
@@ -2218,15 +3164,20 @@ func (e *LLMMessagesEvent) GetEventType() EventType {
 	return LLMMessages
 }
 
-// ToolCallProgressEvent represents progress during a tool call
+// ToolCallProgressEvent represents progress during a tool call, such as an
+// MCP progress notification from a long-running tool
 type ToolCallProgressEvent struct {
 	BaseEventData
-	ToolName    string `json:"tool_name"`
-	ToolCallID  string `json:"tool_call_id,omitempty"`
-	Progress    int    `json:"progress"` // 0-100 percentage
-	Status      string `json:"status"`   // "running", "waiting", "processing"
-	Message     string `json:"message,omitempty"`
-	ElapsedTime string `json:"elapsed_time,omitempty"`
+	Turn        int     `json:"turn,omitempty"`
+	ToolName    string  `json:"tool_name"`
+	ServerName  string  `json:"server_name,omitempty"`
+	ToolCallID  string  `json:"tool_call_id,omitempty"`
+	Progress    int     `json:"progress"` // 0-100 percentage, 0 when the total is unknown
+	Current     float64 `json:"current"`  // Progress value reported by the tool
+	Total       float64 `json:"total,omitempty"`
+	Status      string  `json:"status"`            // "running", "waiting", "processing"
+	Message     string  `json:"message,omitempty"` // Partial output or status text reported by the tool
+	ElapsedTime string  `json:"elapsed_time,omitempty"`
 }
 
 func (e *ToolCallProgressEvent) GetEventType() EventType {
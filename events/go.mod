@@ -0,0 +1,5 @@
+module github.com/manishiitg/mcpagent/events
+
+go 1.25.12
+
+require github.com/manishiitg/multi-llm-provider-go v0.7.4-0.20260716090415-37555ec848b5
@@ -29,11 +29,36 @@ const (
 	ToolCallProgress       EventType = "tool_call_progress"
 	WorkspaceFileOperation EventType = "workspace_file_operation"
 
+	// ToolDisabledForConversation: a tool was removed for the rest of a conversation after repeated failures
+	ToolDisabledForConversation EventType = "tool_disabled_for_conversation"
+
+	// ToolArgumentLimited: a tool call's arguments exceeded a size limit and were rejected or truncated
+	ToolArgumentLimited EventType = "tool_argument_limited"
+
+	// FolderGuardBlocked: a filesystem tool call outside the folder guard paths was rejected
+	FolderGuardBlocked EventType = "folder_guard_blocked"
+
+	// ToolArgsValidationFailed: a tool call's arguments did not match the tool's input schema and it was not run
+	ToolArgsValidationFailed EventType = "tool_args_validation_failed"
+
+	// DryRunToolBlocked: a write tool call was not run because the agent is in dry-run mode
+	DryRunToolBlocked EventType = "dry_run_tool_blocked"
+
+	// Plan events, driven by the update_plan virtual tool: the LLM created or
+	// revised its task checklist, started or completed a step
+	PlanCreated       EventType = "plan_created"
+	PlanStepStarted   EventType = "plan_step_started"
+	PlanStepCompleted EventType = "plan_step_completed"
+
 	// Agent events
 	AgentStart EventType = "agent_start"
 	AgentEnd   EventType = "agent_end"
 	AgentError EventType = "agent_error"
 
+	// Sub-agent delegation events (emitted by the parent agent)
+	SubAgentStart EventType = "sub_agent_start"
+	SubAgentEnd   EventType = "sub_agent_end"
+
 	// System events
 	SystemPrompt EventType = "system_prompt"
 	UserMessage  EventType = "user_message"
@@ -43,13 +68,14 @@ const (
 	ToolResponse EventType = "tool_response"
 
 	// Streaming events
-	StreamingStart          EventType = "streaming_start"
-	StreamingChunk          EventType = "streaming_chunk"
-	StreamingEnd            EventType = "streaming_end"
-	StreamingError          EventType = "streaming_error"
-	StreamingProgress       EventType = "streaming_progress"
-	StreamingConnectionLost EventType = "streaming_connection_lost"
-	StreamingStatusLine     EventType = "status_line"
+	StreamingStart           EventType = "streaming_start"
+	StreamingChunk           EventType = "streaming_chunk"
+	StreamingEnd             EventType = "streaming_end"
+	StreamingError           EventType = "streaming_error"
+	StreamingProgress        EventType = "streaming_progress"
+	StreamingConnectionLost  EventType = "streaming_connection_lost"
+	StreamingStatusLine      EventType = "status_line"
+	StreamingStructuredChunk EventType = "streaming_structured_chunk" // Partial object of a streamed structured output answer
 
 	// Debug events
 	Debug         EventType = "debug"
@@ -71,22 +97,63 @@ const (
 	ContextEditingCompleted EventType = "context_editing_completed"
 	ContextEditingError     EventType = "context_editing_error"
 
+	// Tool result deduplication events
+	ToolResultsDeduplicated EventType = "tool_results_deduplicated"
+
+	// Tool output guard: a tool result was flagged as prompt injection
+	ToolOutputFlagged EventType = "tool_output_flagged"
+
+	// Final-answer grounding events
+	GroundingCheck EventType = "grounding_check"
+
+	// Final-answer contract events
+	AnswerContractCheck EventType = "answer_contract_check"
+
+	// Output guard events: a final answer was rejected by the output validator, or passed after repairs
+	OutputValidationFailed   EventType = "output_validation_failed"
+	OutputValidationRepaired EventType = "output_validation_repaired"
+
+	// Retention cleanup of tool outputs and workspaces
+	StorageCleanup EventType = "storage_cleanup"
+
 	// Fallback events
 	FallbackModelUsed  EventType = "fallback_model_used"
 	ThrottlingDetected EventType = "throttling_detected"
+	RateLimitWait      EventType = "rate_limit_wait" // Call queued by the provider's process-wide rate limit
 	//nolint:gosec // G101: This is an event type constant, not a credential
 	TokenLimitExceeded EventType = "token_limit_exceeded"
 	MaxTurnsReached    EventType = "max_turns_reached"
 	ContextCancelled   EventType = "context_cancelled"
+	BudgetExceeded     EventType = "budget_exceeded"
+
+	// Memory events
+	MemoryPressure EventType = "memory_pressure"
+	MemoryRead     EventType = "memory_read"  // Long-term memory facts recalled before a conversation
+	MemoryWrite    EventType = "memory_write" // Durable facts saved after a conversation
 
 	// MCP server events
 	MCPServerConnection      EventType = "mcp_server_connection"
 	MCPServerDiscovery       EventType = "mcp_server_discovery"
 	MCPServerSelection       EventType = "mcp_server_selection"
+	RoutingDecision          EventType = "routing_decision" // Smart routing chose the MCP servers offered for a conversation
 	MCPServerConnectionStart EventType = "mcp_server_connection_start"
 	MCPServerConnectionEnd   EventType = "mcp_server_connection_end"
 	MCPServerConnectionError EventType = "mcp_server_connection_error"
 
+	// MCPServerConnectionState: a connected MCP server dropped, is being
+	// reconnected or came back (see mcpclient.ConnectionState)
+	MCPServerConnectionState EventType = "mcp_server_connection_state"
+
+	// MCPServerRestarted: a crashed stdio MCP server was respawned and initialized
+	MCPServerRestarted EventType = "mcp_server_restarted"
+
+	// MCPConfigReloaded: the MCP server configuration was reloaded (see Agent.ReloadConfig)
+	MCPConfigReloaded EventType = "mcp_config_reloaded"
+
+	// MCP sampling: a server asked the agent's LLM for a completion during a tool call
+	MCPSamplingRequest  EventType = "mcp_sampling_request"
+	MCPSamplingResponse EventType = "mcp_sampling_response"
+
 	// Cache events
 	CacheHit            EventType = "cache_hit"
 	CacheMiss           EventType = "cache_miss"
@@ -99,11 +166,12 @@ const (
 	GenericCache        EventType = "cache_event"
 
 	// Structured output events
-	StructuredOutputStart EventType = "structured_output_start"
-	StructuredOutputEnd   EventType = "structured_output_end"
-	StructuredOutputError EventType = "structured_output_error"
-	JSONValidationStart   EventType = "json_validation_start"
-	JSONValidationEnd     EventType = "json_validation_end"
+	StructuredOutputStart    EventType = "structured_output_start"
+	StructuredOutputEnd      EventType = "structured_output_end"
+	StructuredOutputError    EventType = "structured_output_error"
+	StructuredOutputCaptured EventType = "structured_output_captured" // Streaming structured output tool was called
+	JSONValidationStart      EventType = "json_validation_start"
+	JSONValidationEnd        EventType = "json_validation_end"
 
 	// Tool execution events
 	ToolExecution          EventType = "tool_execution"
@@ -225,7 +293,7 @@ func (b *BaseEventData) GetBaseEventData() *BaseEventData {
 // Helper function to get component from event type
 func GetComponentFromEventType(eventType EventType) string {
 	switch eventType {
-	case StructuredOutputStart, StructuredOutputEnd, StructuredOutputError,
+	case StructuredOutputStart, StructuredOutputEnd, StructuredOutputError, StructuredOutputCaptured,
 		JSONValidationStart, JSONValidationEnd,
 		IndependentStepsSelected, TodoStepsExtracted, VariablesExtracted,
 		StepTokenUsage, StepProgressUpdated,
@@ -233,13 +301,14 @@ func GetComponentFromEventType(eventType EventType) string {
 		HumanVerificationResponse, RequestHumanFeedback, BlockingHumanFeedback,
 		LearningSkipped,
 		DecisionEvaluated, PreValidationCompleted,
+		OrchestratorStart, OrchestratorEnd, OrchestratorError,
 		StepExecutionStart, StepExecutionEnd, StepExecutionFailed:
 		return "orchestrator"
 	case AgentStart, AgentEnd, AgentError:
 		return "agent"
 	case LLMGenerationStart, LLMGenerationEnd, LLMGenerationError:
 		return "llm"
-	case ToolCallStart, ToolCallEnd, ToolCallError, WorkspaceFileOperation:
+	case ToolCallStart, ToolCallEnd, ToolCallError, ToolCallProgress, WorkspaceFileOperation:
 		return "tool"
 	case ConversationStart, ConversationEnd, ConversationError, ConversationTurn, ConversationThinking:
 		return "conversation"
@@ -260,7 +329,8 @@ func IsStartEvent(eventType EventType) bool {
 		eventType == ConversationTurn ||
 		eventType == LLMGenerationStart ||
 		eventType == ToolCallStart ||
-		eventType == AgentStart
+		eventType == AgentStart ||
+		eventType == OrchestratorStart
 }
 
 // Helper function to check if event is an end event
@@ -268,5 +338,34 @@ func IsEndEvent(eventType EventType) bool {
 	return eventType == ConversationEnd ||
 		eventType == LLMGenerationEnd ||
 		eventType == ToolCallEnd ||
-		eventType == AgentEnd
+		eventType == AgentEnd ||
+		eventType == OrchestratorEnd
+}
+
+// IsMilestoneEvent reports whether an event marks a step of a conversation a
+// lightweight client shows: the conversation, agent and sub-agent lifecycle,
+// tool calls, the final completion and conditions that stop or redirect work
+func IsMilestoneEvent(eventType EventType) bool {
+	switch eventType {
+	case ConversationStart, ConversationEnd, ConversationError,
+		AgentStart, AgentEnd, AgentError,
+		SubAgentStart, SubAgentEnd,
+		ToolCallStart, ToolCallEnd, ToolCallError, ToolDisabledForConversation,
+		EventTypeUnifiedCompletion, MaxTurnsReached, ContextCancelled, BudgetExceeded, FallbackModelUsed,
+		RequestHumanFeedback, BlockingHumanFeedback,
+		OrchestratorStart, OrchestratorEnd, OrchestratorError,
+		StepExecutionStart, StepExecutionEnd, StepExecutionFailed,
+		PlanCreated, PlanStepStarted, PlanStepCompleted:
+		return true
+	}
+	return false
+}
+
+// IsStreamingTextEvent reports whether an event carries or frames streamed answer text
+func IsStreamingTextEvent(eventType EventType) bool {
+	switch eventType {
+	case StreamingStart, StreamingChunk, StreamingStructuredChunk, StreamingEnd, StreamingError:
+		return true
+	}
+	return false
 }
@@ -0,0 +1,40 @@
+package events
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+// wasmForbiddenDeps must never become dependencies of this module, which Go
+// frontends compile with GOOS=js GOARCH=wasm
+var wasmForbiddenDeps = []string{
+	"os/exec",
+	"github.com/mark3labs/mcp-go",
+	"github.com/manishiitg/mcpagent/agent",
+	"github.com/manishiitg/mcpagent/mcpclient",
+}
+
+func TestWASMPackagesStayDependencyLight(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs go list")
+	}
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go toolchain not available")
+	}
+	cmd := exec.Command(goBin, "list", "-deps", "./...")
+	cmd.Env = append(os.Environ(), "GOOS=js", "GOARCH=wasm")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("go list under js/wasm failed: %v\n%s", err, out)
+	}
+	for _, dep := range strings.Fields(string(out)) {
+		for _, forbidden := range wasmForbiddenDeps {
+			if dep == forbidden || strings.HasPrefix(dep, forbidden+"/") {
+				t.Errorf("%s is a dependency of the js/wasm packages", dep)
+			}
+		}
+	}
+}
@@ -4,6 +4,10 @@ go 1.24.4
 
 replace github.com/manishiitg/mcpagent => ../..
 
+replace github.com/manishiitg/mcpagent/events => ../../events
+
+replace github.com/manishiitg/mcpagent/grpcserver/pb => ../../grpcserver/pb
+
 replace github.com/manishiitg/multi-llm-provider-go => ../../../multi-llm-provider-go
 
 require (
@@ -75,4 +75,8 @@ require (
 // Adjust or remove these for your own setup if your directory layout differs.
 replace github.com/manishiitg/mcpagent => ../../
 
+replace github.com/manishiitg/mcpagent/events => ../..//events
+
+replace github.com/manishiitg/mcpagent/grpcserver/pb => ../..//grpcserver/pb
+
 replace github.com/manishiitg/multi-llm-provider-go => ../../../multi-llm-provider-go
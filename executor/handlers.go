@@ -380,62 +380,123 @@ func (h *ExecutorHandlers) HandleMCPExecute(w http.ResponseWriter, r *http.Reque
 		loggerv2.String("server", req.Server))
 	mcpToolStartTime := time.Now()
 	h.logger.Info(fmt.Sprintf("⏱️  TOOL EXECUTION START - Time: %s, Tool: %s, Server: %s", mcpToolStartTime.Format(time.RFC3339), req.Tool, req.Server))
-	result, err := client.CallTool(ctx, req.Tool, req.Args)
-
-	// 🔧 BROKEN PIPE DETECTION AND RETRY
-	// When a workflow is stopped, MCP connections are closed which causes "transport closed"
-	// errors. We must NOT retry in that case — otherwise sub-agents continue as zombies.
-	if err != nil && mcpclient.IsBrokenPipeError(err) {
-		// Guard: skip retry if context is canceled (intentional stop, not transient error)
-		if ctx.Err() != nil {
-			h.logger.Info("🔧 [BROKEN PIPE] Skipping retry — context canceled (intentional stop)",
-				loggerv2.String("tool", req.Tool),
-				loggerv2.String("server", req.Server),
-				loggerv2.String("ctx_err", ctx.Err().Error()))
-		} else if req.SessionID != "" && mcpclient.GetSessionRegistry().IsSessionStopped(req.SessionID) {
-			// Guard: skip retry if the session was stopped via CloseHTTPSession
-			h.logger.Info("🔧 [BROKEN PIPE] Skipping retry — session stopped (zombie prevention)",
-				loggerv2.String("tool", req.Tool),
-				loggerv2.String("server", req.Server),
-				loggerv2.String("session_id", req.SessionID))
-		} else {
-			h.logger.Info("🔧 [BROKEN PIPE] Detected, closing old connection and getting fresh one...",
-				loggerv2.String("tool", req.Tool),
-				loggerv2.String("server", req.Server))
-
-			// Close the old broken connection first to kill the subprocess.
-			if client != nil {
-				h.logger.Info("🔧 [BROKEN PIPE] Closing old broken connection",
+	// Calls run through the session's tool call hook, e.g. the agent's tool
+	// middleware, which may change the arguments or block the call
+	resultStr, err := codeexec.RunToolCall(ctx, req.SessionID, "MCP", req.Server, req.Tool, req.Args, func(ctx context.Context, args map[string]interface{}) (string, error) {
+		result, err := client.CallTool(ctx, req.Tool, args)
+
+		// 🔧 BROKEN PIPE DETECTION AND RETRY
+		// When a workflow is stopped, MCP connections are closed which causes "transport closed"
+		// errors. We must NOT retry in that case — otherwise sub-agents continue as zombies.
+		if err != nil && mcpclient.IsBrokenPipeError(err) {
+			// Guard: skip retry if context is canceled (intentional stop, not transient error)
+			if ctx.Err() != nil {
+				h.logger.Info("🔧 [BROKEN PIPE] Skipping retry — context canceled (intentional stop)",
+					loggerv2.String("tool", req.Tool),
+					loggerv2.String("server", req.Server),
+					loggerv2.String("ctx_err", ctx.Err().Error()))
+			} else if req.SessionID != "" && mcpclient.GetSessionRegistry().IsSessionStopped(req.SessionID) {
+				// Guard: skip retry if the session was stopped via CloseHTTPSession
+				h.logger.Info("🔧 [BROKEN PIPE] Skipping retry — session stopped (zombie prevention)",
+					loggerv2.String("tool", req.Tool),
+					loggerv2.String("server", req.Server),
+					loggerv2.String("session_id", req.SessionID))
+			} else {
+				h.logger.Info("🔧 [BROKEN PIPE] Detected, closing old connection and getting fresh one...",
+					loggerv2.String("tool", req.Tool),
 					loggerv2.String("server", req.Server))
-				_ = client.Close()
-			}
 
-			// Also remove the stale connection from the session registry.
-			if req.SessionID != "" {
-				registry := mcpclient.GetSessionRegistry()
-				connSessionID := registry.ResolveConnectionSessionID(req.SessionID, req.Server)
-				registry.CloseSessionServer(connSessionID, req.Server)
-			}
+				// Close the old broken connection first to kill the subprocess.
+				if client != nil {
+					h.logger.Info("🔧 [BROKEN PIPE] Closing old broken connection",
+						loggerv2.String("server", req.Server))
+					_ = client.Close()
+				}
 
-			freshClient, freshErr := mcpcache.GetFreshConnection(ctx, req.Server, h.configPath, h.logger)
-			if freshErr == nil {
-				h.logger.Info("🔧 [BROKEN PIPE] Retrying with fresh connection...",
-					loggerv2.String("tool", req.Tool))
-				result, err = freshClient.CallTool(ctx, req.Tool, req.Args)
-				if err == nil {
-					h.logger.Info("🔧 [BROKEN PIPE] Retry successful",
+				// Also remove the stale connection from the session registry.
+				if req.SessionID != "" {
+					registry := mcpclient.GetSessionRegistry()
+					connSessionID := registry.ResolveConnectionSessionID(req.SessionID, req.Server)
+					registry.CloseSessionServer(connSessionID, req.Server)
+				}
+
+				freshClient, freshErr := mcpcache.GetFreshConnection(ctx, req.Server, h.configPath, h.logger)
+				if freshErr == nil {
+					h.logger.Info("🔧 [BROKEN PIPE] Retrying with fresh connection...",
 						loggerv2.String("tool", req.Tool))
+					result, err = freshClient.CallTool(ctx, req.Tool, args)
+					if err == nil {
+						h.logger.Info("🔧 [BROKEN PIPE] Retry successful",
+							loggerv2.String("tool", req.Tool))
+					} else {
+						h.logger.Error("🔧 [BROKEN PIPE] Retry failed", err,
+							loggerv2.String("tool", req.Tool))
+					}
 				} else {
-					h.logger.Error("🔧 [BROKEN PIPE] Retry failed", err,
-						loggerv2.String("tool", req.Tool))
+					h.logger.Error("🔧 [BROKEN PIPE] Failed to get fresh connection", freshErr,
+						loggerv2.String("server", req.Server))
 				}
-			} else {
-				h.logger.Error("🔧 [BROKEN PIPE] Failed to get fresh connection", freshErr,
+			}
+		}
+
+		if err != nil {
+			return "", err
+		}
+
+		// Convert result to string
+		resultStr := ConvertMCPResultToString(result)
+
+		// 🔧 BROKEN PIPE DETECTION IN RESULT CONTENT
+		// MCP servers sometimes return broken pipe errors as text content (success=true, err=nil).
+		// The err != nil check above won't catch these — check the result text too.
+		if err == nil && mcpclient.IsBrokenPipeInContent(resultStr) {
+			// Same guards as above: skip retry if context canceled or session stopped
+			if ctx.Err() != nil {
+				h.logger.Info("🔧 [BROKEN PIPE IN CONTENT] Skipping retry — context canceled",
+					loggerv2.String("tool", req.Tool),
+					loggerv2.String("server", req.Server))
+			} else if req.SessionID != "" && mcpclient.GetSessionRegistry().IsSessionStopped(req.SessionID) {
+				h.logger.Info("🔧 [BROKEN PIPE IN CONTENT] Skipping retry — session stopped",
+					loggerv2.String("tool", req.Tool),
 					loggerv2.String("server", req.Server))
+			} else {
+				h.logger.Info("🔧 [BROKEN PIPE IN CONTENT] Detected broken pipe in result text, closing and retrying...",
+					loggerv2.String("tool", req.Tool),
+					loggerv2.String("server", req.Server),
+					loggerv2.String("result_snippet", resultStr[:min(len(resultStr), 200)]))
+
+				if client != nil {
+					_ = client.Close()
+				}
+				if req.SessionID != "" {
+					registry := mcpclient.GetSessionRegistry()
+					connSessionID := registry.ResolveConnectionSessionID(req.SessionID, req.Server)
+					registry.CloseSessionServer(connSessionID, req.Server)
+				}
+
+				freshClient, freshErr := mcpcache.GetFreshConnection(ctx, req.Server, h.configPath, h.logger)
+				if freshErr == nil {
+					defer freshClient.Close() //nolint:errcheck
+					h.logger.Info("🔧 [BROKEN PIPE IN CONTENT] Retrying with fresh connection...",
+						loggerv2.String("tool", req.Tool))
+					retryResult, retryErr := freshClient.CallTool(ctx, req.Tool, args)
+					if retryErr == nil {
+						resultStr = ConvertMCPResultToString(retryResult)
+						h.logger.Info("🔧 [BROKEN PIPE IN CONTENT] Retry successful",
+							loggerv2.String("tool", req.Tool))
+					} else {
+						h.logger.Error("🔧 [BROKEN PIPE IN CONTENT] Retry failed", retryErr,
+							loggerv2.String("tool", req.Tool))
+					}
+				} else {
+					h.logger.Error("🔧 [BROKEN PIPE IN CONTENT] Failed to get fresh connection", freshErr,
+						loggerv2.String("server", req.Server))
+				}
 			}
 		}
-	}
 
+		return resultStr, nil
+	})
 	h.logger.Info(fmt.Sprintf("⏱️  TOOL EXECUTION END - Time: %s, Tool: %s, Server: %s, Duration: %v", time.Now().Format(time.RFC3339), req.Tool, req.Server, time.Since(mcpToolStartTime)))
 	if err != nil {
 		h.logger.Error("Tool execution failed", err,
@@ -451,58 +512,6 @@ func (h *ExecutorHandlers) HandleMCPExecute(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	// Convert result to string
-	resultStr := ConvertMCPResultToString(result)
-
-	// 🔧 BROKEN PIPE DETECTION IN RESULT CONTENT
-	// MCP servers sometimes return broken pipe errors as text content (success=true, err=nil).
-	// The err != nil check above won't catch these — check the result text too.
-	if err == nil && mcpclient.IsBrokenPipeInContent(resultStr) {
-		// Same guards as above: skip retry if context canceled or session stopped
-		if ctx.Err() != nil {
-			h.logger.Info("🔧 [BROKEN PIPE IN CONTENT] Skipping retry — context canceled",
-				loggerv2.String("tool", req.Tool),
-				loggerv2.String("server", req.Server))
-		} else if req.SessionID != "" && mcpclient.GetSessionRegistry().IsSessionStopped(req.SessionID) {
-			h.logger.Info("🔧 [BROKEN PIPE IN CONTENT] Skipping retry — session stopped",
-				loggerv2.String("tool", req.Tool),
-				loggerv2.String("server", req.Server))
-		} else {
-			h.logger.Info("🔧 [BROKEN PIPE IN CONTENT] Detected broken pipe in result text, closing and retrying...",
-				loggerv2.String("tool", req.Tool),
-				loggerv2.String("server", req.Server),
-				loggerv2.String("result_snippet", resultStr[:min(len(resultStr), 200)]))
-
-			if client != nil {
-				_ = client.Close()
-			}
-			if req.SessionID != "" {
-				registry := mcpclient.GetSessionRegistry()
-				connSessionID := registry.ResolveConnectionSessionID(req.SessionID, req.Server)
-				registry.CloseSessionServer(connSessionID, req.Server)
-			}
-
-			freshClient, freshErr := mcpcache.GetFreshConnection(ctx, req.Server, h.configPath, h.logger)
-			if freshErr == nil {
-				defer freshClient.Close() //nolint:errcheck
-				h.logger.Info("🔧 [BROKEN PIPE IN CONTENT] Retrying with fresh connection...",
-					loggerv2.String("tool", req.Tool))
-				retryResult, retryErr := freshClient.CallTool(ctx, req.Tool, req.Args)
-				if retryErr == nil {
-					resultStr = ConvertMCPResultToString(retryResult)
-					h.logger.Info("🔧 [BROKEN PIPE IN CONTENT] Retry successful",
-						loggerv2.String("tool", req.Tool))
-				} else {
-					h.logger.Error("🔧 [BROKEN PIPE IN CONTENT] Retry failed", retryErr,
-						loggerv2.String("tool", req.Tool))
-				}
-			} else {
-				h.logger.Error("🔧 [BROKEN PIPE IN CONTENT] Failed to get fresh connection", freshErr,
-					loggerv2.String("server", req.Server))
-			}
-		}
-	}
-
 	h.logger.Info("✅ Tool executed successfully",
 		loggerv2.String("tool", req.Tool),
 		loggerv2.Int("result_length", len(resultStr)))
@@ -580,7 +589,9 @@ func (h *ExecutorHandlers) HandleCustomExecute(w http.ResponseWriter, r *http.Re
 		loggerv2.String("session_id", req.SessionID))
 	toolStartTime := time.Now()
 	h.logger.Info(fmt.Sprintf("⏱️  TOOL EXECUTION START - Time: %s, Tool: %s", toolStartTime.Format(time.RFC3339), req.Tool))
-	result, err := codeexec.CallCustomToolWithSession(ctx, req.SessionID, req.Tool, req.Args)
+	result, err := codeexec.RunToolCall(ctx, req.SessionID, "custom", "custom", req.Tool, req.Args, func(ctx context.Context, args map[string]interface{}) (string, error) {
+		return codeexec.CallCustomToolWithSession(ctx, req.SessionID, req.Tool, args)
+	})
 	toolDuration := time.Since(toolStartTime)
 	h.logger.Info(fmt.Sprintf("⏱️  TOOL EXECUTION END - Time: %s, Tool: %s, Duration: %v", time.Now().Format(time.RFC3339), req.Tool, toolDuration))
 	if err != nil {
@@ -663,7 +674,9 @@ func (h *ExecutorHandlers) HandleVirtualExecute(w http.ResponseWriter, r *http.R
 	h.logger.Info("🚀 Executing virtual tool",
 		loggerv2.String("tool", req.Tool),
 		loggerv2.String("session_id", req.SessionID))
-	result, err := codeexec.CallVirtualToolWithSession(ctx, req.SessionID, req.Tool, req.Args)
+	result, err := codeexec.RunToolCall(ctx, req.SessionID, "virtual", "", req.Tool, req.Args, func(ctx context.Context, args map[string]interface{}) (string, error) {
+		return codeexec.CallVirtualToolWithSession(ctx, req.SessionID, req.Tool, args)
+	})
 	if err != nil {
 		h.logger.Error("Virtual tool execution failed", err, loggerv2.String("tool", req.Tool))
 		errorText := toolExecutionError("virtual_tool_handler", req.Tool, req.SessionID, toolTimeout, err)
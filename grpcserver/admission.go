@@ -0,0 +1,151 @@
+package grpcserver
+
+import (
+	"context"
+	"sync"
+
+	mcpagent "github.com/manishiitg/mcpagent/agent"
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+)
+
+// admissionQueue bounds the conversations running at once across all agents.
+// When every slot is taken, conversations wait and are admitted highest
+// priority first, in arrival order within a priority, so interactive (high)
+// requests overtake queued batch (low) work. Low-priority conversations can
+// wait indefinitely under sustained higher-priority load; bound them with a
+// client deadline.
+type admissionQueue struct {
+	mu      sync.Mutex
+	limit   int
+	active  int
+	waiting [3][]chan struct{} // by Priority.Rank()
+}
+
+// newAdmissionQueue creates a queue admitting limit conversations at once
+func newAdmissionQueue(limit int) *admissionQueue {
+	return &admissionQueue{limit: limit}
+}
+
+// acquire waits for a conversation slot. The returned func releases it; it
+// must be called exactly once. A nil queue admits immediately.
+func (q *admissionQueue) acquire(ctx context.Context, priority mcpagent.Priority) (func(), error) {
+	if q == nil {
+		return func() {}, nil
+	}
+	q.mu.Lock()
+	if q.active < q.limit && q.queued() == 0 {
+		q.active++
+		q.mu.Unlock()
+		return q.release, nil
+	}
+	rank := priority.Rank()
+	ticket := make(chan struct{})
+	q.waiting[rank] = append(q.waiting[rank], ticket)
+	q.mu.Unlock()
+
+	select {
+	case <-ticket:
+		return q.release, nil
+	case <-ctx.Done():
+		q.mu.Lock()
+		defer q.mu.Unlock()
+		select {
+		case <-ticket:
+			// Admitted while giving up: hand the slot on
+			q.active--
+			q.admitNext()
+		default:
+			q.remove(rank, ticket)
+		}
+		return nil, ctx.Err()
+	}
+}
+
+// release frees a slot and admits the next waiting conversation
+func (q *admissionQueue) release() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.active--
+	q.admitNext()
+}
+
+// admitNext admits waiting conversations while slots are free; q.mu is held
+func (q *admissionQueue) admitNext() {
+	for q.active < q.limit {
+		rank := len(q.waiting) - 1
+		for rank >= 0 && len(q.waiting[rank]) == 0 {
+			rank--
+		}
+		if rank < 0 {
+			return
+		}
+		ticket := q.waiting[rank][0]
+		q.waiting[rank] = q.waiting[rank][1:]
+		q.active++
+		close(ticket)
+	}
+}
+
+// remove drops a ticket that gave up waiting; q.mu is held
+func (q *admissionQueue) remove(rank int, ticket chan struct{}) {
+	for i, t := range q.waiting[rank] {
+		if t == ticket {
+			q.waiting[rank] = append(q.waiting[rank][:i], q.waiting[rank][i+1:]...)
+			return
+		}
+	}
+}
+
+// queued returns the number of waiting conversations; q.mu is held
+func (q *admissionQueue) queued() int {
+	n := 0
+	for _, tickets := range q.waiting {
+		n += len(tickets)
+	}
+	return n
+}
+
+// SetMaxConcurrentConversations bounds the conversations running at once
+// across all agents; further conversations queue by priority. 0 = unlimited.
+func (m *AgentManager) SetMaxConcurrentConversations(limit int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if limit <= 0 {
+		m.admission = nil
+		return
+	}
+	m.admission = newAdmissionQueue(limit)
+}
+
+// admitConversation waits for a conversation slot for priority. The returned
+// func releases the slot. Conversations are refused while the server drains.
+func (m *AgentManager) admitConversation(ctx context.Context, agentID string, priority mcpagent.Priority) (func(), error) {
+	leave, err := m.enterConversation()
+	if err != nil {
+		return nil, err
+	}
+	m.mu.RLock()
+	queue := m.admission
+	m.mu.RUnlock()
+	release, err := queue.acquire(ctx, priority)
+	if err != nil {
+		leave()
+		m.logger.Debug("Conversation left the admission queue",
+			loggerv2.String("agent_id", agentID),
+			loggerv2.String("priority", string(priority)))
+		return nil, agentError(err, "waiting for a conversation slot", map[string]string{"agent_id": agentID})
+	}
+	return func() {
+		release()
+		leave()
+	}, nil
+}
+
+// requestPriority parses the priority of a request
+func requestPriority(name string) (mcpagent.Priority, error) {
+	priority, err := mcpagent.ParsePriority(name)
+	if err != nil {
+		return "", invalidArgumentError(err.Error())
+	}
+	return priority, nil
+}
@@ -0,0 +1,90 @@
+package grpcserver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	mcpagent "github.com/manishiitg/mcpagent/agent"
+	"github.com/manishiitg/mcpagent/grpcserver/pb"
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+)
+
+func TestAdmissionQueueAdmitsByPriority(t *testing.T) {
+	q := newAdmissionQueue(1)
+	ctx := context.Background()
+	releaseFirst, err := q.acquire(ctx, mcpagent.PriorityLow)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	admitted := make(chan mcpagent.Priority, 3)
+	enqueue := func(priority mcpagent.Priority) {
+		go func() {
+			release, err := q.acquire(ctx, priority)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			admitted <- priority
+			release()
+		}()
+		// Wait until the request is queued so arrival order is deterministic
+		for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+			q.mu.Lock()
+			queued := len(q.waiting[priority.Rank()])
+			q.mu.Unlock()
+			if queued > 0 {
+				return
+			}
+		}
+		t.Fatalf("%s request was not queued", priority)
+	}
+	enqueue(mcpagent.PriorityLow)
+	enqueue(mcpagent.PriorityNormal)
+	enqueue(mcpagent.PriorityHigh)
+
+	releaseFirst()
+	var order []mcpagent.Priority
+	for i := 0; i < 3; i++ {
+		order = append(order, <-admitted)
+	}
+	want := []mcpagent.Priority{mcpagent.PriorityHigh, mcpagent.PriorityNormal, mcpagent.PriorityLow}
+	for i := range want {
+		if order[i] != want[i] {
+			t.Fatalf("admission order = %v, want %v", order, want)
+		}
+	}
+}
+
+func TestAdmissionQueueCancelledWaiterLeaves(t *testing.T) {
+	q := newAdmissionQueue(1)
+	release, _ := q.acquire(context.Background(), mcpagent.PriorityNormal)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := q.acquire(ctx, mcpagent.PriorityHigh); err == nil {
+		t.Fatal("expected the wait to end with the context")
+	}
+	if q.queued() != 0 {
+		t.Errorf("cancelled request is still queued")
+	}
+
+	release()
+	next, err := q.acquire(context.Background(), mcpagent.PriorityLow)
+	if err != nil {
+		t.Fatalf("slot should be free after release: %v", err)
+	}
+	next()
+}
+
+func TestAskRejectsUnknownPriority(t *testing.T) {
+	service := NewAgentService(NewAgentManager(loggerv2.NewNoop(), ""), loggerv2.NewNoop())
+	_, err := service.Ask(context.Background(), &pb.AskRequest{AgentId: "a", Question: "hi", Priority: "urgent"})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("got %v, want InvalidArgument", err)
+	}
+}
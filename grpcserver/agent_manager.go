@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
@@ -13,6 +14,8 @@ import (
 	mcpagent "github.com/manishiitg/mcpagent/agent"
 	"github.com/manishiitg/mcpagent/llm"
 	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+	"github.com/manishiitg/mcpagent/metrics"
+	"github.com/manishiitg/mcpagent/observability"
 )
 
 // ManagedAgent wraps an agent with metadata for lifecycle management
@@ -27,6 +30,11 @@ type ManagedAgent struct {
 	capabilities Capabilities
 	// CustomTools stores definitions for tools that execute via gRPC stream
 	CustomTools []CustomToolDefinition
+
+	// Activity tracking for memory-pressure eviction (see memory.go)
+	activityMu          sync.Mutex
+	activeConversations int
+	lastActive          time.Time
 }
 
 // AgentManager manages the lifecycle of agent instances
@@ -35,6 +43,46 @@ type AgentManager struct {
 	mu            sync.RWMutex
 	logger        loggerv2.Logger
 	defaultConfig string // Default MCP config path
+
+	// Conversation autosave for agents created by this manager (nil = disabled)
+	autosaveStore      mcpagent.ConversationStore
+	autosaveEveryTurns int
+
+	// Events retained per agent for WatchConversation replay (0 = watching disabled)
+	streamReplaySize int
+
+	// Ceiling on session state retained by all agents (see memory.go); 0 = unlimited
+	memoryLimitBytes int64
+	memoryPolicy     mcpagent.MemoryPolicy
+
+	// Bounds concurrent conversations, admitting queued ones by priority (see admission.go); nil = unlimited
+	admission *admissionQueue
+
+	// Session-end cleanup applied to agents (the server runs the shared janitor); nil = disabled
+	retention *mcpagent.RetentionPolicy
+
+	// Diagnostic bundles of failed conversations (see mcpagent.WithPostMortemBundles); nil = disabled
+	postMortem *mcpagent.PostMortemConfig
+
+	// Prometheus metrics collector added to every agent (see metrics.go); nil = disabled
+	metricsCollector atomic.Pointer[metrics.Collector]
+
+	// Cancel functions of running requests started with a request ID (see cancellation.go)
+	requestsMu sync.Mutex
+	requests   map[string]trackedRequest
+
+	// Pre-created agents handed out by CreateAgent (see warm_pool.go)
+	poolsMu   sync.RWMutex
+	warmPools map[string]*warmPool
+	stopPools context.CancelFunc
+
+	// Graceful shutdown: in-flight conversations and open Converse streams (see drain.go)
+	drainMu       sync.Mutex
+	draining      bool
+	drainDeadline time.Time
+	inflight      int
+	drained       chan struct{} // Closed when inflight drops to 0; nil when nobody waits
+	streams       map[*StreamHandler]context.CancelFunc
 }
 
 // NewAgentManager creates a new agent manager
@@ -46,37 +94,146 @@ func NewAgentManager(logger loggerv2.Logger, defaultConfigPath string) *AgentMan
 	}
 }
 
-// CreateAgent creates a new agent instance with the given configuration
+// SetAutosave enables conversation autosave for agents created after this call
+func (m *AgentManager) SetAutosave(store mcpagent.ConversationStore, everyTurns int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.autosaveStore = store
+	m.autosaveEveryTurns = everyTurns
+}
+
+// SetStreamReplay enables event streaming for agents created after this call,
+// retaining the last size events for watchers that join late
+func (m *AgentManager) SetStreamReplay(size int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.streamReplaySize = size
+}
+
+// SetRetention applies the session-end cleanup of policy to agents created
+// after this call. Age and size limits are left to a shared StorageJanitor
+// (see Server), so agents do not each sweep the same folders.
+func (m *AgentManager) SetRetention(policy mcpagent.RetentionPolicy) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.retention = &mcpagent.RetentionPolicy{
+		CleanupSessionOnEnd: policy.CleanupSessionOnEnd,
+		DryRun:              policy.DryRun,
+	}
+}
+
+// SetPostMortem builds a diagnostic bundle whenever a conversation of an
+// agent created after this call fails, for GetPostMortemBundle
+func (m *AgentManager) SetPostMortem(config mcpagent.PostMortemConfig) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.postMortem = &config
+}
+
+// AutosaveStore returns the conversation store used for autosave, or nil
+func (m *AgentManager) AutosaveStore() mcpagent.ConversationStore {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.autosaveStore
+}
+
+// CreateAgent creates a new agent instance with the given configuration.
+// When req.WarmPool names a warm pool that can serve the request, a
+// pre-created agent is handed out instead (see warm_pool.go). The
+// authenticated client in parentCtx, if any, owns the agent's autosaved
+// conversations.
 func (m *AgentManager) CreateAgent(parentCtx context.Context, req CreateAgentRequest) (*ManagedAgent, error) {
+	client, _ := ClientFromContext(parentCtx)
+	if req.WarmPool != "" {
+		managed, resolved, err := m.takeWarmAgent(req)
+		if err != nil {
+			return nil, err
+		}
+		if managed != nil {
+			mcpagent.WithCheckpointOwner(client.ID)(managed.Agent)
+			m.mu.Lock()
+			m.agents[managed.ID] = managed
+			m.mu.Unlock()
+			m.logger.Info("Agent taken from warm pool",
+				loggerv2.String("agent_id", managed.ID),
+				loggerv2.String("session_id", managed.SessionID),
+				loggerv2.String("warm_pool", req.WarmPool))
+			return managed, nil
+		}
+		req = resolved
+	}
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
 	// Generate IDs
-	agentID := newManagedAgentID()
 	sessionID := req.SessionID
 	if sessionID == "" {
 		sessionID = newManagedSessionID()
 	}
 
+	// Resolve the preset, if any; explicitly set config fields win
+	req, presetOptions, err := resolvePreset(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Build agent options
+	options := append(presetOptions, m.buildAgentOptions(req.Config, sessionID)...)
+	options = append(options, mcpagent.WithCheckpointOwner(client.ID))
+
+	managed, err := m.startAgent(parentCtx, req.Config, sessionID, options)
+	if err != nil {
+		return nil, err
+	}
+
+	m.agents[managed.ID] = managed
+	m.logger.Info("Agent created", loggerv2.String("agent_id", managed.ID), loggerv2.String("session_id", sessionID))
+
+	return managed, nil
+}
+
+// resolvePreset fills unset connection fields of req from its preset and
+// returns the preset's options
+func resolvePreset(req CreateAgentRequest) (CreateAgentRequest, []mcpagent.AgentOption, error) {
+	if req.Preset == "" {
+		return req, nil, nil
+	}
+	preset, ok := mcpagent.GetPreset(req.Preset)
+	if !ok {
+		return req, nil, fmt.Errorf("preset not found: %s", req.Preset)
+	}
+	if req.Config.Provider == "" {
+		req.Config.Provider = preset.Provider
+	}
+	if req.Config.ModelID == "" {
+		req.Config.ModelID = preset.ModelID
+	}
+	if req.Config.MCPConfigPath == "" {
+		req.Config.MCPConfigPath = preset.MCPConfigPath
+	}
+	return req, append(preset.AgentOptions(), mcpagent.WithPresetVariables(req.PresetVariables)), nil
+}
+
+// startAgent initializes the LLM, connects the agent and wraps it for
+// management. The agent is not registered with the manager.
+func (m *AgentManager) startAgent(parentCtx context.Context, config AgentConfig, sessionID string, options []mcpagent.AgentOption) (*ManagedAgent, error) {
 	// Create context with cancellation
 	ctx, cancel := context.WithCancel(parentCtx)
 
 	// Determine config path
-	configPath := req.Config.MCPConfigPath
+	configPath := config.MCPConfigPath
 	if configPath == "" {
 		configPath = m.defaultConfig
 	}
 
 	// Initialize LLM
-	llmModel, err := m.initializeLLM(ctx, req.Config)
+	llmModel, err := m.initializeLLM(ctx, config)
 	if err != nil {
 		cancel()
 		return nil, fmt.Errorf("failed to initialize LLM: %w", err)
 	}
 
-	// Build agent options
-	options := m.buildAgentOptions(req.Config, sessionID)
-
 	// Create the agent
 	agent, err := mcpagent.NewAgent(ctx, llmModel, configPath, options...)
 	if err != nil {
@@ -84,6 +241,10 @@ func (m *AgentManager) CreateAgent(parentCtx context.Context, req CreateAgentReq
 		return nil, fmt.Errorf("failed to create agent: %w", err)
 	}
 
+	if collector := m.metricsCollector.Load(); collector != nil {
+		agent.AddEventListener(collector)
+	}
+
 	// Get capabilities
 	toolToServer := agent.GetToolToServer()
 	tools := make([]string, 0, len(toolToServer))
@@ -97,25 +258,21 @@ func (m *AgentManager) CreateAgent(parentCtx context.Context, req CreateAgentReq
 		servers = append(servers, server)
 	}
 
-	managed := &ManagedAgent{
-		ID:          agentID,
+	return &ManagedAgent{
+		ID:          newManagedAgentID(),
 		SessionID:   sessionID,
 		Agent:       agent,
-		Config:      req.Config,
+		Config:      config,
 		CreatedAt:   time.Now(),
+		lastActive:  time.Now(),
 		ctx:         ctx,
 		cancel:      cancel,
-		CustomTools: req.Config.CustomTools,
+		CustomTools: config.CustomTools,
 		capabilities: Capabilities{
 			Tools:   tools,
 			Servers: servers,
 		},
-	}
-
-	m.agents[agentID] = managed
-	m.logger.Info("Agent created", loggerv2.String("agent_id", agentID), loggerv2.String("session_id", sessionID))
-
-	return managed, nil
+	}, nil
 }
 
 func newManagedAgentID() string {
@@ -278,5 +435,33 @@ func (m *AgentManager) buildAgentOptions(config AgentConfig, sessionID string) [
 		options = append(options, mcpagent.WithStreaming(true))
 	}
 
+	if len(config.ToolPermissions) > 0 {
+		options = append(options, mcpagent.WithToolPermissions(config.ToolPermissions))
+	}
+
+	if m.autosaveStore != nil {
+		options = append(options, mcpagent.WithAutosave(m.autosaveStore, m.autosaveEveryTurns))
+	}
+
+	// The last history is only kept to persist it on drain or to account
+	// it against the memory limit
+	if m.autosaveStore != nil || m.memoryLimitBytes > 0 {
+		options = append(options, mcpagent.WithHistoryRetention(true))
+	}
+
+	if m.retention != nil {
+		options = append(options, mcpagent.WithRetentionPolicy(*m.retention))
+	}
+
+	if m.postMortem != nil {
+		options = append(options, mcpagent.WithPostMortemBundles(*m.postMortem))
+	}
+
+	if m.streamReplaySize > 0 {
+		options = append(options,
+			mcpagent.WithTracer(observability.NoopTracer{}),
+			mcpagent.WithStreamReplay(m.streamReplaySize))
+	}
+
 	return options
 }
@@ -0,0 +1,364 @@
+package grpcserver
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	mcpagent "github.com/manishiitg/mcpagent/agent"
+	"github.com/manishiitg/mcpagent/events"
+	"github.com/manishiitg/mcpagent/grpcserver/pb"
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+)
+
+// artifactPathPrefix is the URL prefix under which artifacts are served:
+// {BaseURL}/artifacts/{root}/{path relative to root}
+const artifactPathPrefix = "/artifacts/"
+
+// ArtifactServerConfig configures the optional HTTP server that lets gRPC
+// clients download files produced by agents (workspace files, offloaded tool
+// outputs). Only files inside the configured roots are served, and every
+// request must present Token as "Authorization: Bearer <token>". When the
+// gRPC server runs with Config.Auth, clients present their own client token
+// or JWT instead and may only download files from the tool output folders of
+// the agents they own: the agent's session folder, and the user's folder for
+// agents created WithUserID. Other files, including those of agents that no
+// longer exist, are served to admin clients only (see AuthConfig).
+type ArtifactServerConfig struct {
+	// Addr is the TCP address to listen on (e.g. "127.0.0.1:8089")
+	Addr string
+	// BaseURL is the externally visible URL prefix used in artifact references.
+	// Defaults to "http://" + Addr.
+	BaseURL string
+	// Token is the shared secret clients must send. Required, unless the
+	// server runs with Config.Auth, where it must be empty.
+	Token string
+	// Roots maps a URL-safe root name to a local folder. Defaults to
+	// {"tool_output": mcpagent.DefaultToolOutputFolder}.
+	Roots map[string]string
+}
+
+// artifactRoot is a served folder with its path resolved
+type artifactRoot struct {
+	name string
+	dir  string // absolute, symlinks resolved
+}
+
+// ArtifactServer serves files from a fixed set of folders over token-protected HTTP
+type ArtifactServer struct {
+	httpServer *http.Server
+	addr       string
+	baseURL    string
+	token      []byte
+	roots      []artifactRoot
+	auth       *authenticator // Set when clients authenticate with Config.Auth credentials
+	logger     loggerv2.Logger
+}
+
+// NewArtifactServer validates cfg and creates an ArtifactServer. Root folders
+// that do not exist yet are created so paths can be resolved.
+func NewArtifactServer(cfg ArtifactServerConfig, logger loggerv2.Logger) (*ArtifactServer, error) {
+	return newArtifactServer(cfg, nil, logger)
+}
+
+// newArtifactServer creates an ArtifactServer; with auth, clients
+// authenticate with their Config.Auth credentials instead of cfg.Token
+func newArtifactServer(cfg ArtifactServerConfig, auth *authenticator, logger loggerv2.Logger) (*ArtifactServer, error) {
+	if logger == nil {
+		logger = loggerv2.NewDefault()
+	}
+	if cfg.Addr == "" {
+		return nil, errors.New("artifact server address is required")
+	}
+	if auth == nil && cfg.Token == "" {
+		return nil, errors.New("artifact server token is required")
+	}
+	if auth != nil && cfg.Token != "" {
+		return nil, errors.New("artifact server token must be empty when auth is configured; clients use their own credentials")
+	}
+
+	rootDirs := cfg.Roots
+	if len(rootDirs) == 0 {
+		rootDirs = map[string]string{"tool_output": mcpagent.DefaultToolOutputFolder}
+	}
+
+	roots := make([]artifactRoot, 0, len(rootDirs))
+	for name, dir := range rootDirs {
+		if name == "" || strings.ContainsAny(name, `/\?#%`) || name == "." || name == ".." {
+			return nil, fmt.Errorf("invalid artifact root name %q", name)
+		}
+		if err := os.MkdirAll(dir, 0755); err != nil { //nolint:gosec // 0755 permissions are intentional for user-accessible directories
+			return nil, fmt.Errorf("failed to create artifact root %q: %w", name, err)
+		}
+		resolved, err := resolveDir(dir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve artifact root %q: %w", name, err)
+		}
+		roots = append(roots, artifactRoot{name: name, dir: resolved})
+	}
+	// Longest folder first so nested roots win when building URLs
+	sort.Slice(roots, func(i, j int) bool {
+		if len(roots[i].dir) != len(roots[j].dir) {
+			return len(roots[i].dir) > len(roots[j].dir)
+		}
+		return roots[i].name < roots[j].name
+	})
+
+	baseURL := strings.TrimRight(cfg.BaseURL, "/")
+	if baseURL == "" {
+		baseURL = "http://" + cfg.Addr
+	}
+
+	s := &ArtifactServer{
+		addr:    cfg.Addr,
+		baseURL: baseURL,
+		token:   []byte(cfg.Token),
+		roots:   roots,
+		auth:    auth,
+		logger:  logger,
+	}
+	s.httpServer = &http.Server{
+		Addr:              cfg.Addr,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	return s, nil
+}
+
+// Handler returns the HTTP handler serving artifacts
+func (s *ArtifactServer) Handler() http.Handler {
+	return http.HandlerFunc(s.serveArtifact)
+}
+
+// Start listens on the configured address and serves until Shutdown is called
+func (s *ArtifactServer) Start() error {
+	listener, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return err
+	}
+	s.logger.Info("Starting artifact server", loggerv2.String("addr", s.addr))
+	if err := s.httpServer.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// Shutdown gracefully stops the HTTP server
+func (s *ArtifactServer) Shutdown(ctx context.Context) error {
+	return s.httpServer.Shutdown(ctx)
+}
+
+// URLFor returns the download URL for a local file path, or false when the
+// path is not inside any artifact root.
+func (s *ArtifactServer) URLFor(path string) (string, bool) {
+	if path == "" {
+		return "", false
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", false
+	}
+	// Resolve symlinks when the file exists so it matches the resolved roots
+	if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+		abs = resolved
+	}
+	for _, root := range s.roots {
+		rel, ok := relInside(root.dir, abs)
+		if !ok || rel == "." {
+			continue
+		}
+		segments := strings.Split(filepath.ToSlash(rel), "/")
+		for i, seg := range segments {
+			segments[i] = url.PathEscape(seg)
+		}
+		return s.baseURL + artifactPathPrefix + url.PathEscape(root.name) + "/" + strings.Join(segments, "/"), true
+	}
+	return "", false
+}
+
+// artifactsForEvent returns download references for files mentioned by an event
+func (s *ArtifactServer) artifactsForEvent(data events.EventData) []*pb.Artifact {
+	var path string
+	switch ev := data.(type) {
+	case *events.WorkspaceFileOperationEvent:
+		path = ev.Filepath
+		if path != "" && ev.Folder != "" && !filepath.IsAbs(path) {
+			path = filepath.Join(ev.Folder, path)
+		}
+	case *events.LargeToolOutputFileWrittenEvent:
+		path = ev.FilePath
+	}
+	if path == "" {
+		return nil
+	}
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		return nil
+	}
+	u, ok := s.URLFor(path)
+	if !ok {
+		return nil
+	}
+	return []*pb.Artifact{{Path: path, Url: u}}
+}
+
+func (s *ArtifactServer) serveArtifact(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	client, ok := s.authenticate(r)
+	if !ok {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="mcpagent-artifacts"`)
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	path, ok := s.resolveRequestPath(r.URL.Path)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	// Files of other clients are reported as missing so their names cannot be probed
+	if s.auth != nil && !s.clientMayRead(client, path) {
+		s.auth.logRefusal(r.URL.Path, client.ID, &authError{authFileNotOwned, errors.New("file belongs to another client")})
+		http.NotFound(w, r)
+		return
+	}
+
+	f, err := os.Open(path) //nolint:gosec // G304: path is confined to an artifact root by resolveRequestPath
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	defer func() { _ = f.Close() }()
+
+	info, err := f.Stat()
+	if err != nil || !info.Mode().IsRegular() {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", info.Name()))
+	http.ServeContent(w, r, info.Name(), info.ModTime(), f)
+}
+
+// authenticate checks the request's token: the shared Token, or with auth a
+// client credential. The client is empty without auth.
+func (s *ArtifactServer) authenticate(r *http.Request) (ClientIdentity, bool) {
+	token, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !found {
+		return ClientIdentity{}, false
+	}
+	if s.auth == nil {
+		return ClientIdentity{}, subtle.ConstantTimeCompare([]byte(token), s.token) == 1
+	}
+	client, denied := s.auth.authenticateToken(strings.TrimSpace(token))
+	if denied != nil {
+		s.auth.logRefusal(r.URL.Path, "", denied)
+		return ClientIdentity{}, false
+	}
+	return client, true
+}
+
+// clientMayRead reports whether client may download the resolved path:
+// admins any file, other clients files in the output folders of their agents
+func (s *ArtifactServer) clientMayRead(client ClientIdentity, path string) bool {
+	if client.Scope.Admin {
+		return true
+	}
+	for _, summary := range s.auth.manager.ListAgents() {
+		agent, ok := s.auth.manager.GetAgent(summary.AgentID)
+		if !ok || !client.owns(s.auth.owner(summary.AgentID)) {
+			continue
+		}
+		for _, dir := range agentOutputFolders(agent) {
+			if resolved, err := resolveDir(dir); err == nil {
+				if _, inside := relInside(resolved, path); inside {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// agentOutputFolders returns the folders holding an agent's files: its
+// session's tool output folder and, for agents created WithUserID, the
+// user's tool output folder
+func agentOutputFolders(agent *ManagedAgent) []string {
+	var folders []string
+	if handler := agent.Agent.GetToolOutputHandler(); handler != nil && handler.GetSessionID() != "" {
+		folders = append(folders, filepath.Join(handler.GetToolOutputFolder(), handler.GetSessionID()))
+	}
+	if agent.Agent.UserID != "" {
+		folders = append(folders, mcpagent.UserToolOutputFolder(agent.Agent.UserID))
+	}
+	return folders
+}
+
+// resolveRequestPath maps a request path to a file inside an artifact root.
+// Symlinks are resolved before the containment check so links cannot be used
+// to escape the root.
+func (s *ArtifactServer) resolveRequestPath(urlPath string) (string, bool) {
+	rest, found := strings.CutPrefix(urlPath, artifactPathPrefix)
+	if !found {
+		return "", false
+	}
+	name, rel, found := strings.Cut(rest, "/")
+	if !found || rel == "" {
+		return "", false
+	}
+
+	var root *artifactRoot
+	for i := range s.roots {
+		if s.roots[i].name == name {
+			root = &s.roots[i]
+			break
+		}
+	}
+	if root == nil {
+		return "", false
+	}
+
+	target := filepath.Join(root.dir, filepath.FromSlash(rel))
+	if _, ok := relInside(root.dir, target); !ok {
+		return "", false
+	}
+	resolved, err := filepath.EvalSymlinks(target)
+	if err != nil {
+		return "", false
+	}
+	if _, ok := relInside(root.dir, resolved); !ok {
+		return "", false
+	}
+	return resolved, true
+}
+
+// resolveDir returns the absolute, symlink-resolved form of dir
+func resolveDir(dir string) (string, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	return filepath.EvalSymlinks(abs)
+}
+
+// relInside returns path relative to dir when path is dir or lies beneath it
+func relInside(dir, path string) (string, bool) {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) || filepath.IsAbs(rel) {
+		return "", false
+	}
+	return rel, true
+}
@@ -0,0 +1,191 @@
+package grpcserver
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	mcpagent "github.com/manishiitg/mcpagent/agent"
+	"github.com/manishiitg/mcpagent/events"
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+)
+
+func newTestArtifactServer(t *testing.T) (*ArtifactServer, string, string) {
+	t.Helper()
+	base := t.TempDir()
+	root := filepath.Join(base, "workspace")
+	if err := os.MkdirAll(filepath.Join(root, "reports"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "reports", "q3 summary.md"), []byte("# Q3"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(base, "secret.txt"), []byte("secret"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := NewArtifactServer(ArtifactServerConfig{
+		Addr:    "127.0.0.1:0",
+		BaseURL: "http://files.local/",
+		Token:   "s3cret",
+		Roots:   map[string]string{"workspace": root},
+	}, nil)
+	if err != nil {
+		t.Fatalf("NewArtifactServer: %v", err)
+	}
+	return s, root, base
+}
+
+func TestArtifactServerRequiresToken(t *testing.T) {
+	if _, err := NewArtifactServer(ArtifactServerConfig{Addr: "127.0.0.1:0"}, nil); err == nil {
+		t.Fatal("expected error when token is empty")
+	}
+
+	s, _, _ := newTestArtifactServer(t)
+	for _, auth := range []string{"", "Bearer wrong", "s3cret"} {
+		req := httptest.NewRequest(http.MethodGet, "/artifacts/workspace/reports/q3%20summary.md", nil)
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+		rec := httptest.NewRecorder()
+		s.Handler().ServeHTTP(rec, req)
+		if rec.Code != http.StatusUnauthorized {
+			t.Errorf("Authorization %q: status = %d, want 401", auth, rec.Code)
+		}
+	}
+}
+
+func TestArtifactServerServesFilesInsideRoots(t *testing.T) {
+	s, root, base := newTestArtifactServer(t)
+
+	u, ok := s.URLFor(filepath.Join(root, "reports", "q3 summary.md"))
+	if !ok || u != "http://files.local/artifacts/workspace/reports/q3%20summary.md" {
+		t.Fatalf("URLFor = %q, %v", u, ok)
+	}
+	if _, ok := s.URLFor(filepath.Join(base, "secret.txt")); ok {
+		t.Error("URLFor returned a URL for a file outside the roots")
+	}
+
+	req := httptest.NewRequest(http.MethodGet, strings.TrimPrefix(u, "http://files.local"), nil)
+	req.Header.Set("Authorization", "Bearer s3cret")
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, req)
+	body, _ := io.ReadAll(rec.Body)
+	if rec.Code != http.StatusOK || string(body) != "# Q3" {
+		t.Fatalf("status = %d, body = %q", rec.Code, body)
+	}
+}
+
+func TestArtifactServerRejectsEscapes(t *testing.T) {
+	s, root, base := newTestArtifactServer(t)
+	if err := os.Symlink(filepath.Join(base, "secret.txt"), filepath.Join(root, "link.txt")); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range []string{
+		"/artifacts/workspace/../secret.txt",
+		"/artifacts/workspace/link.txt",
+		"/artifacts/workspace/reports",
+		"/artifacts/unknown/secret.txt",
+		"/secret.txt",
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.URL.Path = path
+		req.Header.Set("Authorization", "Bearer s3cret")
+		rec := httptest.NewRecorder()
+		s.Handler().ServeHTTP(rec, req)
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("%s: status = %d, want 404", path, rec.Code)
+		}
+	}
+}
+
+func TestArtifactsForEvent(t *testing.T) {
+	s, root, _ := newTestArtifactServer(t)
+
+	ev := events.NewWorkspaceFileOperationEvent("write", "reports/q3 summary.md", root, 1, "custom")
+	artifacts := s.artifactsForEvent(ev)
+	if len(artifacts) != 1 || !strings.HasSuffix(artifacts[0].Url, "/artifacts/workspace/reports/q3%20summary.md") {
+		t.Fatalf("artifacts = %+v", artifacts)
+	}
+
+	missing := events.NewWorkspaceFileOperationEvent("delete", "gone.md", root, 1, "custom")
+	if got := s.artifactsForEvent(missing); len(got) != 0 {
+		t.Errorf("expected no artifacts for a missing file, got %+v", got)
+	}
+}
+
+func TestArtifactServerServesOnlyOwnFilesWithAuth(t *testing.T) {
+	t.Chdir(t.TempDir())
+	files := map[string]string{
+		"support": filepath.Join(mcpagent.DefaultToolOutputFolder, "session-support", "tool_a.json"),
+		"ops":     filepath.Join(mcpagent.DefaultToolOutputFolder, "session-ops", "tool_b.json"),
+		"alice":   filepath.Join(mcpagent.UserToolOutputFolder("alice"), "session-earlier", "tool_c.json"),
+	}
+	for _, path := range files {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("{}"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	manager := NewAgentManager(loggerv2.NewNoop(), "")
+	auth, err := newAuthenticator(AuthConfig{Clients: []ClientCredential{
+		{ID: "support", Token: "a"},
+		{ID: "ops", Token: "b"},
+		{ID: "root", Token: "c", Scope: ClientScope{Admin: true}},
+	}}, manager, loggerv2.NewNoop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for owner, user := range map[string]string{"support": "alice", "ops": ""} {
+		agent := &mcpagent.Agent{Logger: loggerv2.NewNoop(), UserID: user}
+		agent.SetToolOutputHandler(mcpagent.NewToolOutputHandlerWithConfig(0, mcpagent.DefaultToolOutputFolder, "session-"+owner, true, true))
+		manager.agents["agent-"+owner] = &ManagedAgent{ID: "agent-" + owner, Agent: agent}
+		auth.owners["agent-"+owner] = owner
+	}
+
+	if _, err := newArtifactServer(ArtifactServerConfig{Addr: "127.0.0.1:0", Token: "s3cret"}, auth, nil); err == nil {
+		t.Error("a shared token should be refused when auth is configured")
+	}
+	s, err := newArtifactServer(ArtifactServerConfig{Addr: "127.0.0.1:0"}, auth, loggerv2.NewNoop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	get := func(token, file string) int {
+		u, ok := s.URLFor(files[file])
+		if !ok {
+			t.Fatalf("no URL for %s", files[file])
+		}
+		req := httptest.NewRequest(http.MethodGet, u, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		s.Handler().ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	cases := []struct {
+		token, file string
+		want        int
+	}{
+		{"a", "support", http.StatusOK},
+		{"a", "alice", http.StatusOK}, // the user's folder of an agent created WithUserID
+		{"a", "ops", http.StatusNotFound},
+		{"b", "ops", http.StatusOK},
+		{"b", "support", http.StatusNotFound},
+		{"b", "alice", http.StatusNotFound},
+		{"c", "alice", http.StatusOK},
+		{"s3cret", "support", http.StatusUnauthorized},
+	}
+	for _, tc := range cases {
+		if got := get(tc.token, tc.file); got != tc.want {
+			t.Errorf("token %q, %s file: status = %d, want %d", tc.token, tc.file, got, tc.want)
+		}
+	}
+}
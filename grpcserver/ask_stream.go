@@ -0,0 +1,247 @@
+package grpcserver
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/status"
+
+	mcpagent "github.com/manishiitg/mcpagent/agent"
+	"github.com/manishiitg/mcpagent/events"
+	"github.com/manishiitg/mcpagent/grpcserver/pb"
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+// AskStream answers one question over a server stream. Text chunks, tool
+// call starts and ends, and the final response are sent as typed messages, so
+// clients can render progress without driving a bidirectional Converse
+// stream. Events are taken from the agent's listeners, so they stream whether
+// or not the server runs with stream replay. Custom tools executed by the
+// client need Converse and are not available here.
+func (s *AgentService) AskStream(req *pb.AskStreamRequest, stream pb.AgentService_AskStreamServer) error {
+	if req.AgentId == "" {
+		return invalidArgumentError("agent_id is required")
+	}
+	if req.Question == "" {
+		return invalidArgumentError("question is required")
+	}
+
+	priority, err := requestPriority(req.Priority)
+	if err != nil {
+		return err
+	}
+
+	agent, ok := s.manager.GetAgent(req.AgentId)
+	if !ok {
+		return agentNotFoundError(req.AgentId)
+	}
+
+	ctx, done, err := s.manager.trackRequest(stream.Context(), req.RequestId)
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	release, err := s.manager.admitConversation(ctx, req.AgentId, priority)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	startTime := time.Now()
+	defer s.manager.beginConversation(agent)()
+
+	sender := &askStreamSender{
+		stream:        stream,
+		logger:        s.logger,
+		artifacts:     s.artifacts,
+		includeEvents: req.IncludeEvents,
+	}
+	agent.Agent.AddEventListener(sender)
+	defer agent.Agent.RemoveEventListener(sender)
+
+	var response string
+	var updatedMessages []llmtypes.MessageContent
+	if len(req.History) > 0 {
+		messages := append(messagesToLLM(req.History), llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, req.Question))
+		response, updatedMessages, err = agent.Agent.AskWithHistory(ctx, messages, mcpagent.CallWithPriority(priority))
+	} else {
+		response, err = agent.Agent.Ask(ctx, req.Question, mcpagent.CallWithPriority(priority))
+	}
+	if err != nil {
+		s.logger.Error("AskStream failed", err, loggerv2.String("agent_id", req.AgentId))
+		err = agentError(err, "ask failed", map[string]string{"agent_id": req.AgentId})
+		sender.sendError(err)
+		return err
+	}
+
+	promptTokens, completionTokens, totalTokens, cacheTokens, reasoningTokens, llmCallCount, _ := agent.Agent.GetTokenUsage()
+
+	return sender.send(&pb.AskStreamResponse{
+		Payload: &pb.AskStreamResponse_FinalResponse{
+			FinalResponse: &pb.FinalResponse{
+				Response:        response,
+				UpdatedMessages: messagesToProto(updatedMessages),
+				TokenUsage: &pb.TokenUsage{
+					PromptTokens:     safeIntToInt32(promptTokens),
+					CompletionTokens: safeIntToInt32(completionTokens),
+					TotalTokens:      safeIntToInt32(totalTokens),
+					CacheTokens:      safeIntToInt32(cacheTokens),
+					ReasoningTokens:  safeIntToInt32(reasoningTokens),
+					LlmCallCount:     safeIntToInt32(llmCallCount),
+				},
+				DurationMs: time.Since(startTime).Milliseconds(),
+				Artifacts:  sender.conversationArtifacts(),
+			},
+		},
+	})
+}
+
+// askStreamSender is an agent event listener that multiplexes the events of
+// one AskStream call onto its stream. Events can arrive from several
+// goroutines (parallel tool calls), so sends are serialized.
+type askStreamSender struct {
+	stream        pb.AgentService_AskStreamServer
+	logger        loggerv2.Logger
+	artifacts     *ArtifactServer
+	includeEvents bool
+
+	mu       sync.Mutex
+	produced []*pb.Artifact
+}
+
+// Name implements mcpagent.AgentEventListener
+func (s *askStreamSender) Name() string {
+	return "grpc_ask_stream"
+}
+
+// HandleEvent implements mcpagent.AgentEventListener. Send failures (the
+// client went away) are logged and do not fail the conversation, which ends
+// through the cancelled stream context.
+func (s *askStreamSender) HandleEvent(_ context.Context, event *events.AgentEvent) error {
+	if resp := s.eventResponse(event); resp != nil {
+		if err := s.send(resp); err != nil {
+			s.logger.Debug("Failed to send AskStream event", loggerv2.String("error", err.Error()))
+		}
+	}
+	return nil
+}
+
+// eventResponse converts an agent event to its stream message; events other
+// than chunks and tool calls are only sent with include_events
+func (s *askStreamSender) eventResponse(event *events.AgentEvent) *pb.AskStreamResponse {
+	if event == nil || event.Data == nil {
+		return nil
+	}
+	var artifacts []*pb.Artifact
+	if s.artifacts != nil {
+		artifacts = s.artifacts.artifactsForEvent(event.Data)
+		s.recordArtifacts(artifacts)
+	}
+
+	switch ev := event.Data.(type) {
+	case *events.StreamingChunkEvent:
+		return &pb.AskStreamResponse{Payload: &pb.AskStreamResponse_TextChunk{
+			TextChunk: &pb.TextChunkEvent{Text: ev.Content},
+		}}
+	case *events.ToolCallStartEvent:
+		return &pb.AskStreamResponse{Payload: &pb.AskStreamResponse_ToolCallStart{
+			ToolCallStart: &pb.ToolCallStart{
+				CallId:     ev.ToolCallID,
+				ToolName:   ev.ToolName,
+				ServerName: ev.ServerName,
+				Arguments:  ev.ToolParams.Arguments,
+				Turn:       safeIntToInt32(ev.Turn),
+			},
+		}}
+	case *events.ToolCallEndEvent:
+		return &pb.AskStreamResponse{Payload: &pb.AskStreamResponse_ToolCallEnd{
+			ToolCallEnd: &pb.ToolCallEnd{
+				CallId:     ev.ToolCallID,
+				ToolName:   ev.ToolName,
+				ServerName: ev.ServerName,
+				Success:    true,
+				Result:     ev.Result,
+				DurationMs: ev.Duration.Milliseconds(),
+				Turn:       safeIntToInt32(ev.Turn),
+				FromCache:  ev.FromCache,
+			},
+		}}
+	case *events.ToolCallErrorEvent:
+		return &pb.AskStreamResponse{Payload: &pb.AskStreamResponse_ToolCallEnd{
+			ToolCallEnd: &pb.ToolCallEnd{
+				CallId:     ev.ToolCallID,
+				ToolName:   ev.ToolName,
+				ServerName: ev.ServerName,
+				Error:      ev.Error,
+				DurationMs: ev.Duration.Milliseconds(),
+				Turn:       safeIntToInt32(ev.Turn),
+			},
+		}}
+	}
+
+	if !s.includeEvents {
+		return nil
+	}
+	pbEvent := agentEventToProto(*event, nil)
+	pbEvent.Artifacts = artifacts
+	return &pb.AskStreamResponse{Payload: &pb.AskStreamResponse_AgentEvent{AgentEvent: pbEvent}}
+}
+
+// send writes one message to the stream
+func (s *askStreamSender) send(resp *pb.AskStreamResponse) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stream.Send(resp)
+}
+
+// sendError sends a fatal error event before the stream ends with err
+func (s *askStreamSender) sendError(err error) {
+	code, details := errorInfo(err)
+	message := err.Error()
+	if st, ok := status.FromError(err); ok {
+		message = st.Message()
+	}
+	resp := &pb.AskStreamResponse{Payload: &pb.AskStreamResponse_Error{
+		Error: &pb.ErrorEvent{
+			Code:    code,
+			Message: message,
+			Details: detailsStruct(details),
+			Fatal:   true,
+		},
+	}}
+	if sendErr := s.send(resp); sendErr != nil {
+		s.logger.Debug("Failed to send error", loggerv2.String("error", sendErr.Error()))
+	}
+}
+
+// recordArtifacts remembers artifacts referenced by events for the final response
+func (s *askStreamSender) recordArtifacts(artifacts []*pb.Artifact) {
+	if len(artifacts) == 0 {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, artifact := range artifacts {
+		known := false
+		for _, existing := range s.produced {
+			if existing.Path == artifact.Path {
+				known = true
+				break
+			}
+		}
+		if !known {
+			s.produced = append(s.produced, artifact)
+		}
+	}
+}
+
+// conversationArtifacts returns the artifacts recorded during the call
+func (s *askStreamSender) conversationArtifacts() []*pb.Artifact {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*pb.Artifact(nil), s.produced...)
+}
@@ -0,0 +1,91 @@
+package grpcserver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/manishiitg/mcpagent/events"
+	"github.com/manishiitg/mcpagent/grpcserver/pb"
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+)
+
+type recordingAskStream struct {
+	grpc.ServerStream
+	sent []*pb.AskStreamResponse
+}
+
+func (s *recordingAskStream) Send(resp *pb.AskStreamResponse) error {
+	s.sent = append(s.sent, resp)
+	return nil
+}
+
+func (s *recordingAskStream) Context() context.Context {
+	return context.Background()
+}
+
+func TestAskStreamSenderMultiplexesEvents(t *testing.T) {
+	stream := &recordingAskStream{}
+	sender := &askStreamSender{stream: stream, logger: loggerv2.NewNoop()}
+	emit := func(data events.EventData) {
+		_ = sender.HandleEvent(context.Background(), &events.AgentEvent{Timestamp: time.Now(), Data: data})
+	}
+
+	emit(&events.StreamingChunkEvent{Content: "Looking"})
+	emit(&events.ToolCallStartEvent{Turn: 1, ToolName: "read_file", ServerName: "fs", ToolCallID: "call-1", ToolParams: events.ToolParams{Arguments: `{"path":"a.txt"}`}})
+	emit(&events.ToolCallEndEvent{Turn: 1, ToolName: "read_file", ServerName: "fs", ToolCallID: "call-1", Result: "hello", Duration: 1500 * time.Millisecond, FromCache: true})
+	emit(&events.ToolCallErrorEvent{Turn: 1, ToolName: "write_file", ToolCallID: "call-2", Error: "denied"})
+	emit(&events.TokenUsageEvent{TotalTokens: 10})
+
+	if len(stream.sent) != 4 {
+		t.Fatalf("sent %d messages, want 4 (token usage is only sent with include_events)", len(stream.sent))
+	}
+	if chunk := stream.sent[0].GetTextChunk(); chunk == nil || chunk.Text != "Looking" {
+		t.Errorf("unexpected chunk: %v", stream.sent[0])
+	}
+	if start := stream.sent[1].GetToolCallStart(); start == nil || start.CallId != "call-1" || start.Arguments != `{"path":"a.txt"}` || start.Turn != 1 {
+		t.Errorf("unexpected tool start: %v", stream.sent[1])
+	}
+	if end := stream.sent[2].GetToolCallEnd(); end == nil || !end.Success || end.Result != "hello" || end.DurationMs != 1500 || !end.FromCache {
+		t.Errorf("unexpected tool end: %v", stream.sent[2])
+	}
+	if failed := stream.sent[3].GetToolCallEnd(); failed == nil || failed.Success || failed.Error != "denied" {
+		t.Errorf("unexpected tool failure: %v", stream.sent[3])
+	}
+
+	sender.includeEvents = true
+	emit(&events.TokenUsageEvent{TotalTokens: 10})
+	if event := stream.sent[len(stream.sent)-1].GetAgentEvent(); event == nil || event.Type != string(events.TokenUsage) {
+		t.Errorf("expected the token usage event with include_events, got %v", stream.sent[len(stream.sent)-1])
+	}
+	// Tool progress carries its payload as the event data
+	emit(events.NewToolCallProgressEvent(1, "crawl", "web", 3, 4, "crawled page 3", time.Second))
+	event := stream.sent[len(stream.sent)-1].GetAgentEvent()
+	if event == nil || event.Type != string(events.ToolCallProgress) {
+		t.Fatalf("expected the tool progress event, got %v", stream.sent[len(stream.sent)-1])
+	}
+	if fields := event.Data.GetFields(); fields["progress"].GetNumberValue() != 75 || fields["message"].GetStringValue() != "crawled page 3" {
+		t.Errorf("unexpected tool progress data: %v", event.Data)
+	}
+}
+
+func TestAskStreamValidatesRequest(t *testing.T) {
+	service := NewAgentService(NewAgentManager(loggerv2.NewNoop(), ""), loggerv2.NewNoop())
+	stream := &recordingAskStream{}
+
+	err := service.AskStream(&pb.AskStreamRequest{AgentId: "missing"}, stream)
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("missing question: got %v", err)
+	}
+	err = service.AskStream(&pb.AskStreamRequest{AgentId: "missing", Question: "hi"}, stream)
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("unknown agent: got %v", err)
+	}
+	if len(stream.sent) != 0 {
+		t.Errorf("nothing should be streamed for an invalid request, got %d messages", len(stream.sent))
+	}
+}
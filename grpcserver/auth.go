@@ -0,0 +1,551 @@
+package grpcserver
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/manishiitg/mcpagent/grpcserver/pb"
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+)
+
+// AuthConfig turns on authentication of gRPC requests. Every request must
+// carry "authorization: Bearer <token>" metadata with one of the static
+// client tokens or, when JWT is set, a signed JWT. The authenticated client's
+// scope limits the RPCs it may call and the servers and tools of the agents
+// it may create. Agents, requests and autosaved conversations belong to the
+// client that started them and can only be used, cancelled or listed by it;
+// those without an owner (e.g. from before a restart) only by admin clients.
+// The standard grpc.health.v1 service stays unauthenticated for probes.
+//
+// The same rules apply to the event bridge and the artifact server when they
+// run next to an authenticated gRPC server: their shared Token is replaced by
+// the client credentials, the event bridge only streams the client's own
+// agents (and needs the WatchConversation method in its scope), and the
+// artifact server only serves files from the tool output folders of the
+// client's agents. Admin clients may stream and download everything.
+type AuthConfig struct {
+	Clients []ClientCredential `json:"clients,omitempty"`
+	JWT     *JWTConfig         `json:"jwt,omitempty"`
+}
+
+// ClientCredential is a static bearer token identifying a client
+type ClientCredential struct {
+	ID    string      `json:"id"`
+	Token string      `json:"token"`
+	Scope ClientScope `json:"scope"`
+}
+
+// JWTConfig accepts HMAC-signed (HS256, HS384, HS512) JWTs. The "sub" claim
+// is the client ID, "exp" is required, and the "mcpagent_scope" claim holds
+// the client's scope in the ClientScope JSON format (no claim = unrestricted).
+type JWTConfig struct {
+	// Secret is the HMAC key. Required.
+	Secret string `json:"secret"`
+	// Issuer, when set, must match the "iss" claim
+	Issuer string `json:"issuer,omitempty"`
+	// Audience, when set, must be in the "aud" claim
+	Audience string `json:"audience,omitempty"`
+}
+
+// ClientScope limits what an authenticated client may do. Empty lists do not
+// restrict. A client limited to servers or tools must select them explicitly
+// when creating agents: agents whose servers or tools are not all inside the
+// scope are refused.
+type ClientScope struct {
+	// Methods are the AgentService RPCs the client may call (e.g. "Ask", "CreateAgent")
+	Methods []string `json:"methods,omitempty"`
+	// Servers are the MCP servers the client's agents may use
+	Servers []string `json:"servers,omitempty"`
+	// Tools are the tools the client's agents may use, as "server:tool",
+	// "server:*" or a bare tool name on any allowed server
+	Tools []string `json:"tools,omitempty"`
+	// MCPConfigPaths are the MCP config files a client limited to servers or
+	// tools may name in mcp_config_path; others always use the server default
+	MCPConfigPaths []string `json:"mcp_config_paths,omitempty"`
+	// Admin clients may use, cancel and list the agents, requests and
+	// conversations of every client, including unowned ones
+	Admin bool `json:"admin,omitempty"`
+}
+
+// ClientIdentity is the client a request was authenticated as
+type ClientIdentity struct {
+	ID    string
+	Scope ClientScope
+}
+
+type clientIdentityKey struct{}
+
+// ClientFromContext returns the client a request was authenticated as. ok is
+// false when authentication is off.
+func ClientFromContext(ctx context.Context) (ClientIdentity, bool) {
+	client, ok := ctx.Value(clientIdentityKey{}).(ClientIdentity)
+	return client, ok
+}
+
+// LoadAuthConfig reads an AuthConfig from a JSON file
+func LoadAuthConfig(path string) (*AuthConfig, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // path is supplied by the operator
+	if err != nil {
+		return nil, fmt.Errorf("failed to read auth config file: %w", err)
+	}
+	var cfg AuthConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse auth config file %s: %w", path, err)
+	}
+	return &cfg, nil
+}
+
+// Auth failure reasons, logged with every refused request
+const (
+	authMissingToken     = "missing_token"
+	authInvalidToken     = "invalid_token"
+	authMethodNotAllowed = "method_not_allowed"
+	authServerNotAllowed = "server_not_allowed"
+	authToolNotAllowed   = "tool_not_allowed"
+	authConfigNotAllowed = "mcp_config_path_not_allowed"
+	authAgentNotOwned    = "agent_not_owned"
+	authRequestNotOwned  = "request_not_owned"
+	authFileNotOwned     = "file_not_owned"
+)
+
+// jwtClockSkewTolerance is the leeway for the exp and nbf claims
+const jwtClockSkewTolerance = time.Minute
+
+// authError is a refused request: the client-facing error and the reason logged
+type authError struct {
+	reason string
+	err    error
+}
+
+// authenticator authenticates requests and enforces client scopes in the
+// gRPC interceptors
+type authenticator struct {
+	clients []ClientCredential
+	jwt     *JWTConfig
+	manager *AgentManager
+	logger  loggerv2.Logger
+	now     func() time.Time
+
+	mu     sync.Mutex
+	owners map[string]string // agent ID -> ID of the client that created it
+}
+
+func newAuthenticator(cfg AuthConfig, manager *AgentManager, logger loggerv2.Logger) (*authenticator, error) {
+	if len(cfg.Clients) == 0 && cfg.JWT == nil {
+		return nil, errors.New("at least one client or a JWT config is required")
+	}
+	seen := make(map[string]bool, len(cfg.Clients))
+	for _, client := range cfg.Clients {
+		if client.ID == "" || client.Token == "" {
+			return nil, errors.New("every client needs an id and a token")
+		}
+		if seen[client.ID] {
+			return nil, fmt.Errorf("duplicate client id: %s", client.ID)
+		}
+		seen[client.ID] = true
+	}
+	if cfg.JWT != nil && cfg.JWT.Secret == "" {
+		return nil, errors.New("jwt secret is required")
+	}
+	return &authenticator{
+		clients: cfg.Clients,
+		jwt:     cfg.JWT,
+		manager: manager,
+		logger:  logger,
+		now:     time.Now,
+		owners:  make(map[string]string),
+	}, nil
+}
+
+func (a *authenticator) unaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if isUnauthenticatedMethod(info.FullMethod) {
+		return handler(ctx, req)
+	}
+	client, denied := a.authenticate(ctx)
+	if denied != nil {
+		return nil, a.refuse(info.FullMethod, "", denied)
+	}
+	if denied := client.Scope.checkMethod(client.ID, info.FullMethod); denied != nil {
+		return nil, a.refuse(info.FullMethod, client.ID, denied)
+	}
+	if denied := a.authorizeRequest(client, req); denied != nil {
+		return nil, a.refuse(info.FullMethod, client.ID, denied)
+	}
+	resp, err := handler(context.WithValue(ctx, clientIdentityKey{}, client), req)
+	if err != nil {
+		return resp, err
+	}
+	if denied := a.authorizeResponse(client, resp); denied != nil {
+		return nil, a.refuse(info.FullMethod, client.ID, denied)
+	}
+	return resp, nil
+}
+
+func (a *authenticator) streamInterceptor(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if isUnauthenticatedMethod(info.FullMethod) {
+		return handler(srv, ss)
+	}
+	client, denied := a.authenticate(ss.Context())
+	if denied != nil {
+		return a.refuse(info.FullMethod, "", denied)
+	}
+	if denied := client.Scope.checkMethod(client.ID, info.FullMethod); denied != nil {
+		return a.refuse(info.FullMethod, client.ID, denied)
+	}
+	return handler(srv, &authorizedStream{
+		ServerStream: ss,
+		ctx:          context.WithValue(ss.Context(), clientIdentityKey{}, client),
+		authorize: func(msg any) error {
+			if denied := a.authorizeRequest(client, msg); denied != nil {
+				return a.refuse(info.FullMethod, client.ID, denied)
+			}
+			return nil
+		},
+	})
+}
+
+// authorizedStream checks every message a client sends on a stream
+type authorizedStream struct {
+	grpc.ServerStream
+	ctx       context.Context
+	authorize func(msg any) error
+}
+
+func (s *authorizedStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *authorizedStream) RecvMsg(m any) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	return s.authorize(m)
+}
+
+// isUnauthenticatedMethod reports whether method is served without a token
+func isUnauthenticatedMethod(fullMethod string) bool {
+	return strings.HasPrefix(fullMethod, "/grpc.health.v1.Health/")
+}
+
+// refuse logs a refused request and returns its status error
+func (a *authenticator) refuse(fullMethod, clientID string, denied *authError) error {
+	a.logRefusal(fullMethod, clientID, denied)
+	if clientID == "" {
+		return newStatusError(ReasonUnauthenticated, denied.err.Error(), map[string]string{"reason": denied.reason}, 0)
+	}
+	return newStatusError(ReasonPermissionDenied, denied.err.Error(), map[string]string{"reason": denied.reason, "client_id": clientID}, 0)
+}
+
+// logRefusal logs a refused gRPC or HTTP request; method is the gRPC method
+// or the HTTP path
+func (a *authenticator) logRefusal(method, clientID string, denied *authError) {
+	a.logger.Warn("Request refused",
+		loggerv2.String("event", "grpc_auth_failure"),
+		loggerv2.String("method", method),
+		loggerv2.String("client_id", clientID),
+		loggerv2.String("reason", denied.reason),
+		loggerv2.String("error", denied.err.Error()))
+}
+
+// authenticate identifies the client from the request's bearer token
+func (a *authenticator) authenticate(ctx context.Context) (ClientIdentity, *authError) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	var token string
+	for _, value := range md.Get("authorization") {
+		if t, ok := strings.CutPrefix(value, "Bearer "); ok {
+			token = strings.TrimSpace(t)
+			break
+		}
+	}
+	return a.authenticateToken(token)
+}
+
+// authenticateToken identifies the client from a bearer token, a static
+// client token or a JWT
+func (a *authenticator) authenticateToken(token string) (ClientIdentity, *authError) {
+	if token == "" {
+		return ClientIdentity{}, &authError{authMissingToken, errors.New("missing bearer token in authorization metadata")}
+	}
+
+	client, ok := a.staticClient(token)
+	if !ok {
+		if a.jwt == nil || strings.Count(token, ".") != 2 {
+			return ClientIdentity{}, &authError{authInvalidToken, errors.New("invalid token")}
+		}
+		var err error
+		if client, err = a.verifyJWT(token); err != nil {
+			return ClientIdentity{}, &authError{authInvalidToken, fmt.Errorf("invalid token: %w", err)}
+		}
+	}
+	return client, nil
+}
+
+// staticClient finds the static client with token, comparing in constant time
+func (a *authenticator) staticClient(token string) (ClientIdentity, bool) {
+	var found ClientIdentity
+	ok := false
+	for _, client := range a.clients {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(client.Token)) == 1 {
+			found, ok = ClientIdentity{ID: client.ID, Scope: client.Scope}, true
+		}
+	}
+	return found, ok
+}
+
+// jwtClaims are the JWT claims the server reads
+type jwtClaims struct {
+	Subject   string          `json:"sub"`
+	Issuer    string          `json:"iss"`
+	Audience  json.RawMessage `json:"aud"`
+	ExpiresAt *float64        `json:"exp"`
+	NotBefore *float64        `json:"nbf"`
+	Scope     *ClientScope    `json:"mcpagent_scope"`
+}
+
+// verifyJWT checks the signature and claims of an HMAC-signed JWT
+func (a *authenticator) verifyJWT(token string) (ClientIdentity, error) {
+	parts := strings.Split(token, ".")
+	headerJSON, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return ClientIdentity{}, errors.New("malformed header")
+	}
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if err := json.Unmarshal(headerJSON, &header); err != nil {
+		return ClientIdentity{}, errors.New("malformed header")
+	}
+	var newHash func() hash.Hash
+	switch header.Alg {
+	case "HS256":
+		newHash = sha256.New
+	case "HS384":
+		newHash = sha512.New384
+	case "HS512":
+		newHash = sha512.New
+	default:
+		return ClientIdentity{}, fmt.Errorf("unsupported algorithm %q", header.Alg)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return ClientIdentity{}, errors.New("malformed signature")
+	}
+	mac := hmac.New(newHash, []byte(a.jwt.Secret))
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+	if !hmac.Equal(signature, mac.Sum(nil)) {
+		return ClientIdentity{}, errors.New("bad signature")
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return ClientIdentity{}, errors.New("malformed claims")
+	}
+	var claims jwtClaims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return ClientIdentity{}, errors.New("malformed claims")
+	}
+	now := a.now()
+	switch {
+	case claims.Subject == "":
+		return ClientIdentity{}, errors.New("missing sub claim")
+	case claims.ExpiresAt == nil:
+		return ClientIdentity{}, errors.New("missing exp claim")
+	case now.After(time.Unix(int64(*claims.ExpiresAt), 0).Add(jwtClockSkewTolerance)):
+		return ClientIdentity{}, errors.New("token expired")
+	case claims.NotBefore != nil && now.Add(jwtClockSkewTolerance).Before(time.Unix(int64(*claims.NotBefore), 0)):
+		return ClientIdentity{}, errors.New("token not valid yet")
+	case a.jwt.Issuer != "" && claims.Issuer != a.jwt.Issuer:
+		return ClientIdentity{}, errors.New("wrong issuer")
+	case a.jwt.Audience != "" && !jwtAudienceContains(claims.Audience, a.jwt.Audience):
+		return ClientIdentity{}, errors.New("wrong audience")
+	}
+	client := ClientIdentity{ID: claims.Subject}
+	if claims.Scope != nil {
+		client.Scope = *claims.Scope
+	}
+	return client, nil
+}
+
+// jwtAudienceContains reports whether an "aud" claim, a string or an array
+// of strings, contains audience
+func jwtAudienceContains(raw json.RawMessage, audience string) bool {
+	var single string
+	if json.Unmarshal(raw, &single) == nil {
+		return single == audience
+	}
+	var list []string
+	if json.Unmarshal(raw, &list) == nil {
+		return slices.Contains(list, audience)
+	}
+	return false
+}
+
+// authorizeRequest checks a request against the client's scope: agents it
+// creates must stay inside the scope, and agents it addresses must be its own
+func (a *authenticator) authorizeRequest(client ClientIdentity, req any) *authError {
+	switch r := req.(type) {
+	case *pb.CreateAgentRequest:
+		return client.Scope.checkAgentConfig(client.ID, r.GetConfig())
+	case *pb.CreateAgentFromPresetRequest:
+		return client.Scope.checkAgentConfig(client.ID, r.GetConfig())
+	}
+	if r, ok := req.(*pb.CancelRequestRequest); ok {
+		if owner, running := a.manager.requestOwner(r.GetRequestId()); running && !client.owns(owner) {
+			return &authError{authRequestNotOwned, fmt.Errorf("request %s belongs to another client", r.GetRequestId())}
+		}
+	}
+	if r, ok := req.(interface{ GetAgentId() string }); ok && r.GetAgentId() != "" {
+		// Unknown agents are left to the handler's not-found error
+		if _, exists := a.manager.GetAgent(r.GetAgentId()); exists && !client.owns(a.owner(r.GetAgentId())) {
+			return &authError{authAgentNotOwned, fmt.Errorf("agent %s belongs to another client", r.GetAgentId())}
+		}
+	}
+	return nil
+}
+
+// authorizeResponse records the owner of created agents, refusing (and
+// destroying) agents whose resolved servers and tools leave the client's
+// scope, e.g. from a preset or warm pool, and hides other clients' agents
+// from ListAgents and HealthCheckDetailed
+func (a *authenticator) authorizeResponse(client ClientIdentity, resp any) *authError {
+	switch r := resp.(type) {
+	case *pb.CreateAgentResponse:
+		if denied := client.Scope.checkCapabilities(client.ID, r.GetCapabilities()); denied != nil {
+			if err := a.manager.DestroyAgent(r.GetAgentId()); err != nil {
+				a.logger.Warn("Failed to destroy agent refused by client scope",
+					loggerv2.String("agent_id", r.GetAgentId()),
+					loggerv2.String("error", err.Error()))
+			}
+			return denied
+		}
+		a.mu.Lock()
+		a.owners[r.GetAgentId()] = client.ID
+		a.mu.Unlock()
+	case *pb.DestroyAgentResponse:
+		a.mu.Lock()
+		delete(a.owners, r.GetAgentId())
+		a.mu.Unlock()
+	case *pb.ListAgentsResponse:
+		r.Agents = slices.DeleteFunc(r.Agents, func(agent *pb.AgentSummary) bool {
+			return !client.owns(a.owner(agent.GetAgentId()))
+		})
+	case *pb.HealthCheckDetailedResponse:
+		r.Agents = slices.DeleteFunc(r.Agents, func(agent *pb.AgentHealth) bool {
+			return !client.owns(a.owner(agent.GetAgentId()))
+		})
+	}
+	return nil
+}
+
+// owns reports whether the client may use what owner started: its own
+// agents, requests and conversations, or anything for admins. Unowned ones
+// ("") are admin-only.
+func (c ClientIdentity) owns(owner string) bool {
+	return c.Scope.Admin || (owner != "" && owner == c.ID)
+}
+
+// owner returns the ID of the client that created agentID, or "" for agents
+// created without a client (in process) or before a restart. Entries of
+// agents that no longer exist are dropped.
+func (a *authenticator) owner(agentID string) string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	owner, ok := a.owners[agentID]
+	if ok {
+		if _, exists := a.manager.GetAgent(agentID); !exists {
+			delete(a.owners, agentID)
+			return ""
+		}
+	}
+	return owner
+}
+
+// restrictsAgents reports whether the scope limits servers or tools
+func (s ClientScope) restrictsAgents() bool {
+	return len(s.Servers) > 0 || len(s.Tools) > 0
+}
+
+// checkMethod checks that the scope allows calling fullMethod
+func (s ClientScope) checkMethod(clientID, fullMethod string) *authError {
+	method := path.Base(fullMethod)
+	if len(s.Methods) > 0 && !slices.Contains(s.Methods, method) {
+		return &authError{authMethodNotAllowed, fmt.Errorf("client %s may not call %s", clientID, method)}
+	}
+	return nil
+}
+
+// allowsServer reports whether agents may use server
+func (s ClientScope) allowsServer(server string) bool {
+	return len(s.Servers) == 0 || slices.Contains(s.Servers, server)
+}
+
+// allowsTool reports whether agents may use tool of server
+func (s ClientScope) allowsTool(server, tool string) bool {
+	if !s.allowsServer(server) {
+		return false
+	}
+	if len(s.Tools) == 0 {
+		return true
+	}
+	return slices.Contains(s.Tools, server+":"+tool) || slices.Contains(s.Tools, server+":*") ||
+		(tool != "*" && slices.Contains(s.Tools, tool))
+}
+
+// checkAgentConfig checks the servers, tools and MCP config an agent is
+// requested with
+func (s ClientScope) checkAgentConfig(clientID string, config *pb.AgentConfig) *authError {
+	if !s.restrictsAgents() {
+		return nil
+	}
+	if p := config.GetMcpConfigPath(); p != "" && !slices.Contains(s.MCPConfigPaths, p) {
+		return &authError{authConfigNotAllowed, fmt.Errorf("client %s may not use MCP config %s", clientID, p)}
+	}
+	for _, server := range config.GetSelectedServers() {
+		if server != "NO_SERVERS" && !s.allowsServer(server) {
+			return &authError{authServerNotAllowed, fmt.Errorf("client %s may not use server %s", clientID, server)}
+		}
+	}
+	for _, selected := range config.GetSelectedTools() {
+		server, tool, _ := strings.Cut(selected, ":")
+		if !s.allowsTool(server, tool) {
+			return &authError{authToolNotAllowed, fmt.Errorf("client %s may not use tool %s", clientID, selected)}
+		}
+	}
+	return nil
+}
+
+// checkCapabilities checks the servers and tools a created agent ended up with
+func (s ClientScope) checkCapabilities(clientID string, caps *pb.Capabilities) *authError {
+	if !s.restrictsAgents() {
+		return nil
+	}
+	for _, server := range caps.GetServers() {
+		if !s.allowsServer(server) {
+			return &authError{authServerNotAllowed, fmt.Errorf("client %s may not use server %s; select the allowed servers explicitly", clientID, server)}
+		}
+	}
+	for _, qualified := range caps.GetTools() {
+		server, tool, _ := strings.Cut(qualified, ":")
+		if !s.allowsTool(server, tool) {
+			return &authError{authToolNotAllowed, fmt.Errorf("client %s may not use tool %s; select the allowed tools explicitly", clientID, qualified)}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,261 @@
+package grpcserver
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	mcpagent "github.com/manishiitg/mcpagent/agent"
+	"github.com/manishiitg/mcpagent/grpcserver/pb"
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+func signTestJWT(t *testing.T, secret string, claims map[string]any) string {
+	t.Helper()
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	unsigned := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`)) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(unsigned))
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func withBearer(token string) context.Context {
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+token))
+}
+
+func TestAuthInterceptorAuthenticatesClients(t *testing.T) {
+	auth, err := newAuthenticator(AuthConfig{
+		Clients: []ClientCredential{{ID: "reports", Token: "s3cret", Scope: ClientScope{Methods: []string{"Ask", "ListAgents"}}}},
+		JWT:     &JWTConfig{Secret: "jwt-key", Issuer: "idp"},
+	}, NewAgentManager(loggerv2.NewNoop(), ""), loggerv2.NewNoop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ask := &grpc.UnaryServerInfo{FullMethod: "/mcpagent.v1.AgentService/Ask"}
+	var seen string
+	handler := func(ctx context.Context, _ any) (any, error) {
+		client, _ := ClientFromContext(ctx)
+		seen = client.ID
+		return &pb.AskResponse{}, nil
+	}
+	call := func(ctx context.Context, info *grpc.UnaryServerInfo) codes.Code {
+		seen = ""
+		_, err := auth.unaryInterceptor(ctx, &pb.AskRequest{}, info, handler)
+		return status.Code(err)
+	}
+
+	if code := call(withBearer("s3cret"), ask); code != codes.OK || seen != "reports" {
+		t.Errorf("static token: code %v, client %q", code, seen)
+	}
+	if code := call(context.Background(), ask); code != codes.Unauthenticated {
+		t.Errorf("no token: code %v", code)
+	}
+	if code := call(withBearer("wrong"), ask); code != codes.Unauthenticated {
+		t.Errorf("wrong token: code %v", code)
+	}
+	if code := call(withBearer("s3cret"), &grpc.UnaryServerInfo{FullMethod: "/mcpagent.v1.AgentService/CreateAgent"}); code != codes.PermissionDenied {
+		t.Errorf("method outside scope: code %v", code)
+	}
+	if code := call(context.Background(), &grpc.UnaryServerInfo{FullMethod: "/grpc.health.v1.Health/Check"}); code != codes.OK {
+		t.Errorf("health check: code %v", code)
+	}
+
+	exp := float64(time.Now().Add(time.Hour).Unix())
+	if code := call(withBearer(signTestJWT(t, "jwt-key", map[string]any{"sub": "ci", "iss": "idp", "exp": exp})), ask); code != codes.OK || seen != "ci" {
+		t.Errorf("JWT: code %v, client %q", code, seen)
+	}
+	for name, token := range map[string]string{
+		"bad signature": signTestJWT(t, "other-key", map[string]any{"sub": "ci", "iss": "idp", "exp": exp}),
+		"expired":       signTestJWT(t, "jwt-key", map[string]any{"sub": "ci", "iss": "idp", "exp": float64(time.Now().Add(-time.Hour).Unix())}),
+		"no exp":        signTestJWT(t, "jwt-key", map[string]any{"sub": "ci", "iss": "idp"}),
+		"wrong issuer":  signTestJWT(t, "jwt-key", map[string]any{"sub": "ci", "iss": "evil", "exp": exp}),
+	} {
+		if code := call(withBearer(token), ask); code != codes.Unauthenticated {
+			t.Errorf("%s JWT: code %v", name, code)
+		}
+	}
+}
+
+func TestAuthInterceptorEnforcesClientScopes(t *testing.T) {
+	manager := NewAgentManager(loggerv2.NewNoop(), "")
+	auth, err := newAuthenticator(AuthConfig{Clients: []ClientCredential{
+		{ID: "support", Token: "a", Scope: ClientScope{Servers: []string{"zendesk"}, Tools: []string{"zendesk:get_ticket", "search_kb"}}},
+		{ID: "ops", Token: "b"},
+	}}, manager, loggerv2.NewNoop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	create := &grpc.UnaryServerInfo{FullMethod: "/mcpagent.v1.AgentService/CreateAgent"}
+	created := func(id string, tools ...string) grpc.UnaryHandler {
+		return func(context.Context, any) (any, error) {
+			_, cancel := context.WithCancel(context.Background())
+			manager.agents[id] = &ManagedAgent{ID: id, Agent: &mcpagent.Agent{Logger: loggerv2.NewNoop()}, cancel: cancel}
+			servers := map[string]bool{}
+			for _, tool := range tools {
+				servers[strings.Split(tool, ":")[0]] = true
+			}
+			caps := &pb.Capabilities{Tools: tools}
+			for server := range servers {
+				caps.Servers = append(caps.Servers, server)
+			}
+			return &pb.CreateAgentResponse{AgentId: id, Capabilities: caps}, nil
+		}
+	}
+
+	for name, config := range map[string]*pb.AgentConfig{
+		"server":     {SelectedServers: []string{"github"}},
+		"tool":       {SelectedTools: []string{"zendesk:delete_ticket"}},
+		"mcp config": {SelectedServers: []string{"zendesk"}, McpConfigPath: "/tmp/evil.json"},
+	} {
+		_, err := auth.unaryInterceptor(withBearer("a"), &pb.CreateAgentRequest{Config: config}, create, func(context.Context, any) (any, error) {
+			t.Errorf("%s outside the scope reached the handler", name)
+			return nil, nil
+		})
+		if status.Code(err) != codes.PermissionDenied {
+			t.Errorf("%s outside the scope: %v", name, err)
+		}
+	}
+
+	// Nothing selected: the agent gets every tool of the server and is refused
+	req := &pb.CreateAgentRequest{Config: &pb.AgentConfig{SelectedServers: []string{"zendesk"}}}
+	if _, err := auth.unaryInterceptor(withBearer("a"), req, create, created("agent-wide", "zendesk:get_ticket", "zendesk:delete_ticket")); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("agent with tools outside the scope: %v", err)
+	}
+	if _, ok := manager.GetAgent("agent-wide"); ok {
+		t.Error("refused agent was not destroyed")
+	}
+
+	req = &pb.CreateAgentRequest{Config: &pb.AgentConfig{SelectedTools: []string{"zendesk:get_ticket", "zendesk:search_kb"}}}
+	if _, err := auth.unaryInterceptor(withBearer("a"), req, create, created("agent-support", "zendesk:get_ticket", "zendesk:search_kb")); err != nil {
+		t.Fatalf("agent inside the scope: %v", err)
+	}
+	if _, err := auth.unaryInterceptor(withBearer("b"), &pb.CreateAgentRequest{}, create, created("agent-ops", "github:get_issue")); err != nil {
+		t.Fatalf("unrestricted client: %v", err)
+	}
+
+	// Agents can only be used and listed by the client that created them
+	ask := &grpc.UnaryServerInfo{FullMethod: "/mcpagent.v1.AgentService/Ask"}
+	answer := func(context.Context, any) (any, error) { return &pb.AskResponse{}, nil }
+	if _, err := auth.unaryInterceptor(withBearer("b"), &pb.AskRequest{AgentId: "agent-support"}, ask, answer); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("ask another client's agent: %v", err)
+	}
+	if _, err := auth.unaryInterceptor(withBearer("a"), &pb.AskRequest{AgentId: "agent-support"}, ask, answer); err != nil {
+		t.Errorf("ask own agent: %v", err)
+	}
+	list := &grpc.UnaryServerInfo{FullMethod: "/mcpagent.v1.AgentService/ListAgents"}
+	resp, err := auth.unaryInterceptor(withBearer("a"), &pb.ListAgentsRequest{}, list, func(context.Context, any) (any, error) {
+		return &pb.ListAgentsResponse{Agents: []*pb.AgentSummary{{AgentId: "agent-support"}, {AgentId: "agent-ops"}}}, nil
+	})
+	if err != nil || len(resp.(*pb.ListAgentsResponse).Agents) != 1 || resp.(*pb.ListAgentsResponse).Agents[0].AgentId != "agent-support" {
+		t.Errorf("ListAgents for support = %v, %v", resp, err)
+	}
+}
+
+func TestAuthInterceptorScopesRequestsConversationsAndUnownedAgents(t *testing.T) {
+	manager := NewAgentManager(loggerv2.NewNoop(), "")
+	auth, err := newAuthenticator(AuthConfig{Clients: []ClientCredential{
+		{ID: "support", Token: "a"},
+		{ID: "ops", Token: "b"},
+		{ID: "root", Token: "c", Scope: ClientScope{Admin: true}},
+	}}, manager, loggerv2.NewNoop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	service := NewAgentService(manager, loggerv2.NewNoop())
+	unary := func(token, method string, req any, handler grpc.UnaryHandler) (any, error) {
+		return auth.unaryInterceptor(withBearer(token), req, &grpc.UnaryServerInfo{FullMethod: "/mcpagent.v1.AgentService/" + method}, handler)
+	}
+
+	// Requests can only be cancelled by the client that started them
+	var requestCtx context.Context
+	var done func()
+	if _, err := unary("a", "Ask", &pb.AskRequest{}, func(ctx context.Context, _ any) (any, error) {
+		requestCtx, done, err = manager.trackRequest(ctx, "req-1")
+		return &pb.AskResponse{}, err
+	}); err != nil {
+		t.Fatal(err)
+	}
+	defer done()
+	cancel := func(token string) error {
+		_, err := unary(token, "CancelRequest", &pb.CancelRequestRequest{RequestId: "req-1"}, func(ctx context.Context, req any) (any, error) {
+			return service.CancelRequest(ctx, req.(*pb.CancelRequestRequest))
+		})
+		return err
+	}
+	if err := cancel("b"); status.Code(err) != codes.PermissionDenied || requestCtx.Err() != nil {
+		t.Errorf("cancel another client's request: %v", err)
+	}
+	if err := cancel("a"); err != nil || requestCtx.Err() == nil {
+		t.Errorf("cancel own request: %v", err)
+	}
+
+	// Agents without an owner, e.g. from before a restart, are admin-only
+	_, stop := context.WithCancel(context.Background())
+	manager.agents["agent-orphan"] = &ManagedAgent{ID: "agent-orphan", Agent: &mcpagent.Agent{Logger: loggerv2.NewNoop()}, cancel: stop}
+	answer := func(context.Context, any) (any, error) { return &pb.AskResponse{}, nil }
+	if _, err := unary("a", "Ask", &pb.AskRequest{AgentId: "agent-orphan"}, answer); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("ask an unowned agent: %v", err)
+	}
+	if _, err := unary("c", "Ask", &pb.AskRequest{AgentId: "agent-orphan"}, answer); err != nil {
+		t.Errorf("admin asks an unowned agent: %v", err)
+	}
+	health := func(token string) []*pb.AgentHealth {
+		resp, err := unary(token, "HealthCheckDetailed", &pb.HealthCheckDetailedRequest{}, func(context.Context, any) (any, error) {
+			return &pb.HealthCheckDetailedResponse{Agents: []*pb.AgentHealth{{AgentId: "agent-orphan"}}}, nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp.(*pb.HealthCheckDetailedResponse).Agents
+	}
+	if len(health("a")) != 0 || len(health("c")) != 1 {
+		t.Error("HealthCheckDetailed should only show unowned agents to admins")
+	}
+
+	// Recoverable conversations are listed to their owner only
+	store, err := mcpagent.NewFileConversationStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for id, owner := range map[string]string{"conv-support": "support", "conv-ops": "ops", "conv-orphan": ""} {
+		checkpoint := &mcpagent.ConversationCheckpoint{ID: id, Owner: owner, LastError: "crashed", Messages: []llmtypes.MessageContent{llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "hi")}}
+		if err := store.Save(context.Background(), checkpoint); err != nil {
+			t.Fatal(err)
+		}
+	}
+	manager.SetAutosave(store, 1)
+	recoverable := func(token string) []string {
+		resp, err := unary(token, "ListRecoverableConversations", &pb.ListRecoverableConversationsRequest{}, func(ctx context.Context, req any) (any, error) {
+			return service.ListRecoverableConversations(ctx, req.(*pb.ListRecoverableConversationsRequest))
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var ids []string
+		for _, conversation := range resp.(*pb.ListRecoverableConversationsResponse).Conversations {
+			ids = append(ids, conversation.Id)
+		}
+		return ids
+	}
+	if ids := recoverable("a"); len(ids) != 1 || ids[0] != "conv-support" {
+		t.Errorf("support sees conversations %v", ids)
+	}
+	if ids := recoverable("c"); len(ids) != 3 {
+		t.Errorf("admin sees conversations %v", ids)
+	}
+}
@@ -0,0 +1,88 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/manishiitg/mcpagent/grpcserver/pb"
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+)
+
+// ErrRequestCancelled is the cancellation cause of requests cancelled with CancelRequest
+var ErrRequestCancelled = errors.New("request cancelled by client")
+
+// trackedRequest is a running request started with a request ID
+type trackedRequest struct {
+	cancel context.CancelCauseFunc
+	owner  string // ID of the authenticated client that started it; "" without authentication
+}
+
+// trackRequest makes a request started with requestID cancellable through
+// CancelRequest. The returned context is cancelled by CancelRequest; done
+// must be called when the request finishes. Requests without an ID are not
+// tracked.
+func (m *AgentManager) trackRequest(ctx context.Context, requestID string) (context.Context, func(), error) {
+	if requestID == "" {
+		return ctx, func() {}, nil
+	}
+	ctx, cancel := context.WithCancelCause(ctx)
+
+	m.requestsMu.Lock()
+	defer m.requestsMu.Unlock()
+	if _, running := m.requests[requestID]; running {
+		cancel(nil)
+		return nil, nil, duplicateRequestError(requestID)
+	}
+	if m.requests == nil {
+		m.requests = make(map[string]trackedRequest)
+	}
+	client, _ := ClientFromContext(ctx)
+	m.requests[requestID] = trackedRequest{cancel: cancel, owner: client.ID}
+
+	return ctx, func() {
+		m.requestsMu.Lock()
+		delete(m.requests, requestID)
+		m.requestsMu.Unlock()
+		cancel(nil)
+	}, nil
+}
+
+// CancelRequest cancels the running request with requestID, reporting
+// whether one was running
+func (m *AgentManager) CancelRequest(requestID, reason string) bool {
+	m.requestsMu.Lock()
+	request, running := m.requests[requestID]
+	m.requestsMu.Unlock()
+	if !running {
+		return false
+	}
+	cause := ErrRequestCancelled
+	if reason != "" {
+		cause = fmt.Errorf("%w: %s", ErrRequestCancelled, reason)
+	}
+	request.cancel(cause)
+	return true
+}
+
+// requestOwner returns the client that started the running request with
+// requestID, reporting whether one is running
+func (m *AgentManager) requestOwner(requestID string) (string, bool) {
+	m.requestsMu.Lock()
+	defer m.requestsMu.Unlock()
+	request, running := m.requests[requestID]
+	return request.owner, running
+}
+
+// CancelRequest cancels a running Ask, AskWithHistory or AskStream by request ID
+func (s *AgentService) CancelRequest(ctx context.Context, req *pb.CancelRequestRequest) (*pb.CancelRequestResponse, error) {
+	if req.RequestId == "" {
+		return nil, invalidArgumentError("request_id is required")
+	}
+	cancelled := s.manager.CancelRequest(req.RequestId, req.Reason)
+	s.logger.Info("Cancel request",
+		loggerv2.String("request_id", req.RequestId),
+		loggerv2.String("reason", req.Reason),
+		loggerv2.Any("cancelled", cancelled))
+	return &pb.CancelRequestResponse{Cancelled: cancelled}, nil
+}
@@ -0,0 +1,55 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/manishiitg/mcpagent/grpcserver/pb"
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+)
+
+func TestCancelRequestCancelsTrackedRequest(t *testing.T) {
+	m := NewAgentManager(loggerv2.NewNoop(), "")
+	ctx, done, err := m.trackRequest(context.Background(), "req-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := m.trackRequest(context.Background(), "req-1"); status.Code(err) != codes.AlreadyExists {
+		t.Errorf("duplicate request ID: got %v, want AlreadyExists", err)
+	}
+
+	if !m.CancelRequest("req-1", "user pressed stop") {
+		t.Fatal("CancelRequest() = false for a running request")
+	}
+	<-ctx.Done()
+	if cause := context.Cause(ctx); !errors.Is(cause, ErrRequestCancelled) || !strings.Contains(cause.Error(), "user pressed stop") {
+		t.Errorf("cancellation cause = %v", cause)
+	}
+
+	done()
+	if m.CancelRequest("req-1", "") {
+		t.Error("CancelRequest() = true for a finished request")
+	}
+	if _, done, err := m.trackRequest(context.Background(), "req-1"); err != nil {
+		t.Errorf("request ID should be reusable once finished: %v", err)
+	} else {
+		done()
+	}
+}
+
+func TestCancelRequestRPC(t *testing.T) {
+	service := NewAgentService(NewAgentManager(loggerv2.NewNoop(), ""), loggerv2.NewNoop())
+	if _, err := service.CancelRequest(context.Background(), &pb.CancelRequestRequest{}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("empty request_id: got %v, want InvalidArgument", err)
+	}
+	resp, err := service.CancelRequest(context.Background(), &pb.CancelRequestRequest{RequestId: "unknown"})
+	if err != nil || resp.Cancelled {
+		t.Errorf("unknown request: got %+v, %v", resp, err)
+	}
+}
@@ -0,0 +1,179 @@
+package grpcserver
+
+import (
+	"context"
+	"time"
+
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+)
+
+// enterConversation counts a conversation as in flight until the returned
+// func is called. Fails with a SERVER_DRAINING error once Drain has started.
+func (m *AgentManager) enterConversation() (func(), error) {
+	m.drainMu.Lock()
+	defer m.drainMu.Unlock()
+	if m.draining {
+		return nil, serverDrainingError(m.drainDeadline)
+	}
+	m.inflight++
+	return func() {
+		m.drainMu.Lock()
+		defer m.drainMu.Unlock()
+		m.inflight--
+		if m.inflight == 0 && m.drained != nil {
+			close(m.drained)
+			m.drained = nil
+		}
+	}, nil
+}
+
+// trackStream registers an open Converse stream so Drain can notify and
+// close it. The returned func unregisters it.
+func (m *AgentManager) trackStream(h *StreamHandler, cancel context.CancelFunc) (func(), error) {
+	m.drainMu.Lock()
+	defer m.drainMu.Unlock()
+	if m.draining {
+		return nil, serverDrainingError(m.drainDeadline)
+	}
+	if m.streams == nil {
+		m.streams = make(map[*StreamHandler]context.CancelFunc)
+	}
+	m.streams[h] = cancel
+	return func() {
+		m.drainMu.Lock()
+		defer m.drainMu.Unlock()
+		delete(m.streams, h)
+	}, nil
+}
+
+// Draining reports whether Drain has started
+func (m *AgentManager) Draining() bool {
+	m.drainMu.Lock()
+	defer m.drainMu.Unlock()
+	return m.draining
+}
+
+// ActiveConversations returns the number of conversations currently running
+func (m *AgentManager) ActiveConversations() int {
+	m.drainMu.Lock()
+	defer m.drainMu.Unlock()
+	return m.inflight
+}
+
+// drainingError returns the SERVER_DRAINING error once Drain has started
+func (m *AgentManager) drainingError() error {
+	m.drainMu.Lock()
+	defer m.drainMu.Unlock()
+	if !m.draining {
+		return nil
+	}
+	return serverDrainingError(m.drainDeadline)
+}
+
+// Drain stops the manager from accepting new agents and conversations, sends
+// a SERVER_DRAINING notice (a non-fatal ErrorEvent carrying the deadline) on
+// open Converse streams, and waits for the running conversations to finish
+// until ctx is done. Open Converse streams are then closed, cancelling any
+// conversation still running on them. Returns ctx's error if conversations
+// were still running at the deadline.
+func (m *AgentManager) Drain(ctx context.Context) error {
+	m.drainMu.Lock()
+	m.draining = true
+	m.drainDeadline, _ = ctx.Deadline()
+	inflight := m.inflight
+	streams := make([]*StreamHandler, 0, len(m.streams))
+	for h := range m.streams {
+		streams = append(streams, h)
+	}
+	m.drainMu.Unlock()
+
+	m.logger.Info("Draining agent manager",
+		loggerv2.Int("conversations", inflight),
+		loggerv2.Int("streams", len(streams)))
+	notice := serverDrainingError(m.drainDeadline)
+	for _, h := range streams {
+		h.sendError(notice, false)
+	}
+
+	err := m.waitIdle(ctx)
+	if err != nil {
+		m.drainMu.Lock()
+		inflight = m.inflight
+		m.drainMu.Unlock()
+		m.logger.Warn("Drain deadline reached, cancelling running conversations", loggerv2.Int("conversations", inflight))
+	}
+
+	m.drainMu.Lock()
+	for _, cancel := range m.streams {
+		cancel()
+	}
+	m.drainMu.Unlock()
+	return err
+}
+
+// waitIdle waits until no conversation is in flight or ctx is done
+func (m *AgentManager) waitIdle(ctx context.Context) error {
+	m.drainMu.Lock()
+	if m.inflight == 0 {
+		m.drainMu.Unlock()
+		return nil
+	}
+	if m.drained == nil {
+		m.drained = make(chan struct{})
+	}
+	drained := m.drained
+	m.drainMu.Unlock()
+
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// PersistSessions checkpoints the retained history of every agent so the
+// conversations can be resumed after a restart (see
+// ListRecoverableConversations): to the agent's session store when it has
+// one, else to the autosave store. Conversations cut off by the drain
+// deadline are checkpointed by autosave itself. Returns the number of agents
+// saved; agents without either store are skipped.
+func (m *AgentManager) PersistSessions(ctx context.Context) int {
+	m.mu.RLock()
+	autosaveStore := m.autosaveStore
+	agents := make([]*ManagedAgent, 0, len(m.agents))
+	for _, agent := range m.agents {
+		agents = append(agents, agent)
+	}
+	m.mu.RUnlock()
+
+	saved := 0
+	for _, agent := range agents {
+		store := agent.Agent.SessionStore
+		if store == nil {
+			store = autosaveStore
+		}
+		if store == nil {
+			continue
+		}
+		checkpointID, err := agent.Agent.CheckpointRetainedHistory(ctx, store)
+		if err != nil {
+			m.logger.Warn("Failed to persist agent session state",
+				loggerv2.String("agent_id", agent.ID), loggerv2.Error(err))
+			continue
+		}
+		if checkpointID != "" {
+			saved++
+		}
+	}
+	return saved
+}
+
+// serverDrainingError reports a request refused because the server is shutting down
+func serverDrainingError(deadline time.Time) error {
+	metadata := map[string]string{}
+	if !deadline.IsZero() {
+		metadata["deadline"] = deadline.UTC().Format(time.RFC3339)
+	}
+	return newStatusError(ReasonServerDraining, "server is shutting down; retry on another instance or after restart", metadata, 0)
+}
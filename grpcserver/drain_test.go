@@ -0,0 +1,77 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	mcpagent "github.com/manishiitg/mcpagent/agent"
+	"github.com/manishiitg/mcpagent/grpcserver/pb"
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+)
+
+func TestDrainWaitsForRunningConversations(t *testing.T) {
+	m := NewAgentManager(loggerv2.NewNoop(), "")
+	release, err := m.admitConversation(context.Background(), "agent_1", mcpagent.PriorityNormal)
+	if err != nil {
+		t.Fatalf("admitConversation: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- m.Drain(ctx) }()
+
+	for !m.Draining() {
+		time.Sleep(time.Millisecond)
+	}
+	_, err = m.admitConversation(context.Background(), "agent_1", mcpagent.PriorityNormal)
+	if reason, _ := errorInfo(err); reason != ReasonServerDraining {
+		t.Fatalf("admit while draining: reason = %q (%v), want %s", reason, err, ReasonServerDraining)
+	}
+	if st, _ := status.FromError(err); st.Code() != codes.Unavailable {
+		t.Fatalf("code = %v, want Unavailable", st.Code())
+	}
+
+	select {
+	case err := <-done:
+		t.Fatalf("Drain returned before the conversation finished: %v", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+	release()
+	if err := <-done; err != nil {
+		t.Fatalf("Drain: %v", err)
+	}
+}
+
+func TestDrainTimesOutOnRunningConversation(t *testing.T) {
+	m := NewAgentManager(loggerv2.NewNoop(), "")
+	release, err := m.admitConversation(context.Background(), "agent_1", mcpagent.PriorityNormal)
+	if err != nil {
+		t.Fatalf("admitConversation: %v", err)
+	}
+	defer release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := m.Drain(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Drain = %v, want DeadlineExceeded", err)
+	}
+}
+
+func TestCreateAgentRefusedWhileDraining(t *testing.T) {
+	m := NewAgentManager(loggerv2.NewNoop(), "")
+	if err := m.Drain(context.Background()); err != nil {
+		t.Fatalf("Drain: %v", err)
+	}
+
+	service := NewAgentService(m, loggerv2.NewNoop())
+	_, err := service.CreateAgent(context.Background(), &pb.CreateAgentRequest{})
+	if reason, _ := errorInfo(err); reason != ReasonServerDraining {
+		t.Fatalf("CreateAgent reason = %q (%v), want %s", reason, err, ReasonServerDraining)
+	}
+}
@@ -0,0 +1,327 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	mcpagent "github.com/manishiitg/mcpagent/agent"
+	"github.com/manishiitg/multi-llm-provider-go/llmerrors"
+)
+
+// ErrorDomain is the google.rpc.ErrorInfo domain attached to every error
+// returned by the agent service.
+const ErrorDomain = "mcpagent"
+
+// Error reasons carried in google.rpc.ErrorInfo.Reason (unary RPCs) and in
+// ErrorEvent.code (Converse stream). Clients should branch on these rather
+// than on the gRPC code or message text.
+const (
+	ReasonContextOverflow   = "CONTEXT_OVERFLOW"
+	ReasonProviderThrottled = "PROVIDER_THROTTLED"
+	ReasonQuotaExhausted    = "QUOTA_EXHAUSTED"
+	ReasonProviderAuth      = "PROVIDER_AUTH"
+	ReasonProviderError     = "PROVIDER_ERROR"
+	ReasonModelNotFound     = "MODEL_NOT_FOUND"
+	ReasonToolTimeout       = "TOOL_TIMEOUT"
+	ReasonBudgetExceeded    = "BUDGET_EXCEEDED"
+	ReasonGuardBlocked      = "GUARD_BLOCKED"
+	ReasonCancelled         = "CANCELLED"
+	ReasonTimeout           = "TIMEOUT"
+	ReasonAgentNotFound     = "AGENT_NOT_FOUND"
+	ReasonPresetNotFound    = "PRESET_NOT_FOUND"
+	ReasonWarmPoolNotFound  = "WARM_POOL_NOT_FOUND"
+	ReasonNoPostMortem      = "POST_MORTEM_NOT_FOUND"
+	ReasonToolNotFound      = "TOOL_NOT_FOUND"
+	ReasonMCPConnection     = "MCP_CONNECTION_FAILED"
+	ReasonServerDraining    = "SERVER_DRAINING"
+	ReasonDuplicateRequest  = "DUPLICATE_REQUEST"
+	ReasonInvalidArgument   = "INVALID_ARGUMENT"
+	ReasonUnauthenticated   = "UNAUTHENTICATED"
+	ReasonPermissionDenied  = "PERMISSION_DENIED"
+	ReasonInternal          = "INTERNAL"
+)
+
+// reasonCodes maps each reason to its canonical gRPC status code.
+var reasonCodes = map[string]codes.Code{
+	ReasonContextOverflow:   codes.OutOfRange,
+	ReasonProviderThrottled: codes.ResourceExhausted,
+	ReasonQuotaExhausted:    codes.ResourceExhausted,
+	ReasonProviderAuth:      codes.FailedPrecondition,
+	ReasonProviderError:     codes.Unavailable,
+	ReasonModelNotFound:     codes.FailedPrecondition,
+	ReasonToolTimeout:       codes.DeadlineExceeded,
+	ReasonBudgetExceeded:    codes.ResourceExhausted,
+	ReasonGuardBlocked:      codes.PermissionDenied,
+	ReasonCancelled:         codes.Canceled,
+	ReasonTimeout:           codes.DeadlineExceeded,
+	ReasonAgentNotFound:     codes.NotFound,
+	ReasonPresetNotFound:    codes.NotFound,
+	ReasonWarmPoolNotFound:  codes.NotFound,
+	ReasonNoPostMortem:      codes.NotFound,
+	ReasonToolNotFound:      codes.NotFound,
+	ReasonMCPConnection:     codes.Unavailable,
+	ReasonServerDraining:    codes.Unavailable,
+	ReasonDuplicateRequest:  codes.AlreadyExists,
+	ReasonInvalidArgument:   codes.InvalidArgument,
+	ReasonUnauthenticated:   codes.Unauthenticated,
+	ReasonPermissionDenied:  codes.PermissionDenied,
+	ReasonInternal:          codes.Internal,
+}
+
+// ReasonedError is implemented by errors that know their own failure reason
+// (for example budget or guard rejections). It takes precedence over the
+// heuristic classification in classifyError.
+type ReasonedError interface {
+	error
+	ErrorReason() string
+}
+
+// classifyError maps an agent error onto one of the Reason* constants.
+func classifyError(err error) string {
+	if err == nil {
+		return ReasonInternal
+	}
+
+	var reasoned ReasonedError
+	if errors.As(err, &reasoned) {
+		if _, ok := reasonCodes[reasoned.ErrorReason()]; ok {
+			return reasoned.ErrorReason()
+		}
+	}
+
+	if errors.Is(err, context.Canceled) {
+		return ReasonCancelled
+	}
+
+	// The agent's sentinel errors (see mcpagent.ErrorCode)
+	switch {
+	case errors.Is(err, mcpagent.ErrBudgetExceeded):
+		return ReasonBudgetExceeded
+	case errors.Is(err, mcpagent.ErrContextWindowExceeded):
+		return ReasonContextOverflow
+	case errors.Is(err, mcpagent.ErrLLMRateLimited):
+		return ReasonProviderThrottled
+	case errors.Is(err, mcpagent.ErrToolNotFound):
+		return ReasonToolNotFound
+	case errors.Is(err, mcpagent.ErrMCPConnectionFailed):
+		return ReasonMCPConnection
+	}
+
+	// Tool timeouts are reported by the agent as plain text errors, so check
+	// before falling back to the generic deadline handling below.
+	msg := strings.ToLower(err.Error())
+	if strings.Contains(msg, "tool execution timed out") {
+		return ReasonToolTimeout
+	}
+
+	switch llmerrors.KindOf(err) {
+	case llmerrors.KindContextTooLong:
+		return ReasonContextOverflow
+	case llmerrors.KindRateLimit:
+		return ReasonProviderThrottled
+	case llmerrors.KindQuotaExhausted:
+		return ReasonQuotaExhausted
+	case llmerrors.KindAuth:
+		return ReasonProviderAuth
+	case llmerrors.KindModelNotFound:
+		return ReasonModelNotFound
+	case llmerrors.KindServerError, llmerrors.KindNetwork:
+		return ReasonProviderError
+	case llmerrors.KindCanceled:
+		return ReasonCancelled
+	case llmerrors.KindTimeout:
+		return ReasonTimeout
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return ReasonTimeout
+	}
+
+	// Fallback text matching for errors that reach us without an
+	// llmerrors.Error in the chain (e.g. after fmt.Errorf without %w).
+	switch {
+	case strings.Contains(msg, "context canceled"):
+		return ReasonCancelled
+	case strings.Contains(msg, "budget exceeded"):
+		return ReasonBudgetExceeded
+	case strings.Contains(msg, "guard blocked"), strings.Contains(msg, "blocked by guard"):
+		return ReasonGuardBlocked
+	case strings.Contains(msg, "context length"), strings.Contains(msg, "context window"),
+		strings.Contains(msg, "context_length_exceeded"):
+		return ReasonContextOverflow
+	case strings.Contains(msg, "throttl"), strings.Contains(msg, "rate limit"),
+		strings.Contains(msg, "too many requests"):
+		return ReasonProviderThrottled
+	}
+
+	return ReasonInternal
+}
+
+// newStatusError builds a gRPC status error for reason carrying a
+// google.rpc.ErrorInfo detail, plus RetryInfo when retryAfter is known.
+func newStatusError(reason, message string, metadata map[string]string, retryAfter time.Duration) error {
+	code, ok := reasonCodes[reason]
+	if !ok {
+		code = codes.Internal
+	}
+
+	details := []protoadapt.MessageV1{&errdetails.ErrorInfo{
+		Reason:   reason,
+		Domain:   ErrorDomain,
+		Metadata: metadata,
+	}}
+	if retryAfter > 0 {
+		details = append(details, &errdetails.RetryInfo{RetryDelay: durationpb.New(retryAfter)})
+	}
+
+	st := status.New(code, message)
+	if detailed, err := st.WithDetails(details...); err == nil {
+		st = detailed
+	}
+	return st.Err()
+}
+
+// invalidArgumentError reports a malformed request.
+func invalidArgumentError(message string) error {
+	return newStatusError(ReasonInvalidArgument, message, nil, 0)
+}
+
+// agentNotFoundError reports an unknown agent ID.
+func agentNotFoundError(agentID string) error {
+	return newStatusError(ReasonAgentNotFound, "agent not found: "+agentID, map[string]string{"agent_id": agentID}, 0)
+}
+
+// postMortemNotFoundError reports an agent without a post-mortem bundle.
+func postMortemNotFoundError(agentID string) error {
+	return newStatusError(ReasonNoPostMortem, "no post-mortem bundle for agent "+agentID+"; bundles are built when a conversation fails and post-mortem bundles are enabled on the server",
+		map[string]string{"agent_id": agentID}, 0)
+}
+
+// presetNotFoundError reports an unknown preset name.
+func presetNotFoundError(name string) error {
+	return newStatusError(ReasonPresetNotFound, "preset not found: "+name, map[string]string{"preset": name}, 0)
+}
+
+// warmPoolNotFoundError reports an unknown warm pool name.
+func warmPoolNotFoundError(name string) error {
+	return newStatusError(ReasonWarmPoolNotFound, "warm pool not found: "+name, map[string]string{"warm_pool": name}, 0)
+}
+
+// toolNotFoundError reports a tool the agent does not have.
+func toolNotFoundError(agentID, tool string) error {
+	return newStatusError(ReasonToolNotFound, "tool not found: "+tool, map[string]string{"agent_id": agentID, "tool": tool}, 0)
+}
+
+// duplicateRequestError reports a request ID that is already running.
+func duplicateRequestError(requestID string) error {
+	return newStatusError(ReasonDuplicateRequest, "request already running: "+requestID, map[string]string{"request_id": requestID}, 0)
+}
+
+// agentError converts a failure returned by the agent into a gRPC status
+// error with structured details. prefix is prepended to the message, e.g.
+// "ask failed".
+func agentError(err error, prefix string, metadata map[string]string) error {
+	if err == nil {
+		return nil
+	}
+
+	md := make(map[string]string, len(metadata)+3)
+	for k, v := range metadata {
+		md[k] = v
+	}
+
+	var retryAfter time.Duration
+	var llmErr *llmerrors.Error
+	if errors.As(err, &llmErr) {
+		if llmErr.Provider != "" {
+			md["provider"] = llmErr.Provider
+		}
+		if llmErr.Model != "" {
+			md["model"] = llmErr.Model
+		}
+		md["provider_error_kind"] = string(llmErr.Kind)
+		retryAfter = llmErr.RetryAfter
+	}
+
+	message := err.Error()
+	if prefix != "" {
+		message = prefix + ": " + message
+	}
+	return newStatusError(classifyError(err), message, md, retryAfter)
+}
+
+// errorInfo extracts the reason and structured payload from a status error.
+// Errors without an ErrorInfo detail are classified on the fly.
+func errorInfo(err error) (reason string, details map[string]interface{}) {
+	details = make(map[string]interface{})
+
+	st, ok := status.FromError(err)
+	if ok {
+		details["grpc_code"] = st.Code().String()
+		for _, d := range st.Details() {
+			switch info := d.(type) {
+			case *errdetails.ErrorInfo:
+				reason = info.Reason
+				for k, v := range info.Metadata {
+					details[k] = v
+				}
+			case *errdetails.RetryInfo:
+				details["retry_after_ms"] = float64(info.GetRetryDelay().AsDuration() / time.Millisecond)
+			}
+		}
+	}
+
+	if reason == "" {
+		// Plain status errors (e.g. validation failures created with
+		// status.Error) keep their code; anything else is classified.
+		if ok && st.Code() != codes.Unknown {
+			reason = reasonForCode(st.Code())
+		} else {
+			reason = classifyError(err)
+			details["grpc_code"] = reasonCodes[reason].String()
+		}
+	}
+
+	return reason, details
+}
+
+// reasonForCode picks a reason for a status error that carries no ErrorInfo.
+func reasonForCode(code codes.Code) string {
+	switch code {
+	case codes.NotFound:
+		return ReasonAgentNotFound
+	case codes.InvalidArgument:
+		return ReasonInvalidArgument
+	case codes.DeadlineExceeded:
+		return ReasonTimeout
+	case codes.Canceled:
+		return ReasonCancelled
+	case codes.ResourceExhausted:
+		return ReasonProviderThrottled
+	case codes.PermissionDenied:
+		return ReasonGuardBlocked
+	default:
+		return ReasonInternal
+	}
+}
+
+// detailsStruct converts error details into a protobuf Struct for ErrorEvent.
+func detailsStruct(details map[string]interface{}) *structpb.Struct {
+	if len(details) == 0 {
+		return nil
+	}
+	s, err := structpb.NewStruct(details)
+	if err != nil {
+		return nil
+	}
+	return s
+}
@@ -0,0 +1,127 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	mcpagent "github.com/manishiitg/mcpagent/agent"
+	"github.com/manishiitg/mcpagent/grpcserver/pb"
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+	"github.com/manishiitg/multi-llm-provider-go/llmerrors"
+)
+
+type budgetErr struct{}
+
+func (budgetErr) Error() string       { return "spend cap reached" }
+func (budgetErr) ErrorReason() string { return ReasonBudgetExceeded }
+
+func TestClassifyErrorMapsFailureTaxonomy(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"context overflow", &llmerrors.Error{Kind: llmerrors.KindContextTooLong, Err: errors.New("too long")}, ReasonContextOverflow},
+		{"throttled", fmt.Errorf("generate: %w", &llmerrors.Error{Kind: llmerrors.KindRateLimit, Err: errors.New("429")}), ReasonProviderThrottled},
+		{"quota", &llmerrors.Error{Kind: llmerrors.KindQuotaExhausted, Err: errors.New("quota")}, ReasonQuotaExhausted},
+		{"tool timeout", errors.New("tool execution timed out after 30s: search"), ReasonToolTimeout},
+		{"reasoned error", fmt.Errorf("ask: %w", budgetErr{}), ReasonBudgetExceeded},
+		{"guard text", errors.New("request guard blocked the prompt"), ReasonGuardBlocked},
+		{"cancelled", context.Canceled, ReasonCancelled},
+		{"budget sentinel", fmt.Errorf("ask: %w", &mcpagent.BudgetExceededError{LimitUSD: 1, SpentUSD: 2}), ReasonBudgetExceeded},
+		{"context window sentinel", fmt.Errorf("compact: %w", mcpagent.ErrContextWindowExceeded), ReasonContextOverflow},
+		{"rate limited sentinel", mcpagent.ErrLLMRateLimited, ReasonProviderThrottled},
+		{"tool not found sentinel", fmt.Errorf("unknown virtual tool: x: %w", mcpagent.ErrToolNotFound), ReasonToolNotFound},
+		{"mcp connection sentinel", fmt.Errorf("on-demand: %w", mcpagent.ErrMCPConnectionFailed), ReasonMCPConnection},
+		{"unknown", errors.New("boom"), ReasonInternal},
+	}
+	for _, tt := range tests {
+		if got := classifyError(tt.err); got != tt.want {
+			t.Errorf("%s: classifyError() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestAgentErrorCarriesStatusDetails(t *testing.T) {
+	cause := &llmerrors.Error{
+		Kind:       llmerrors.KindRateLimit,
+		Provider:   "openai",
+		Model:      "gpt-4.1",
+		RetryAfter: 2 * time.Second,
+		Err:        errors.New("429 too many requests"),
+	}
+
+	err := agentError(cause, "ask failed", map[string]string{"agent_id": "agent_1"})
+	st, ok := status.FromError(err)
+	if !ok {
+		t.Fatalf("agentError returned non-status error: %v", err)
+	}
+	if st.Code() != codes.ResourceExhausted {
+		t.Fatalf("code = %v, want ResourceExhausted", st.Code())
+	}
+
+	var info *errdetails.ErrorInfo
+	var retry *errdetails.RetryInfo
+	for _, d := range st.Details() {
+		switch v := d.(type) {
+		case *errdetails.ErrorInfo:
+			info = v
+		case *errdetails.RetryInfo:
+			retry = v
+		}
+	}
+	if info == nil {
+		t.Fatal("missing ErrorInfo detail")
+	}
+	if info.Reason != ReasonProviderThrottled || info.Domain != ErrorDomain {
+		t.Fatalf("ErrorInfo = %s/%s, want %s/%s", info.Domain, info.Reason, ErrorDomain, ReasonProviderThrottled)
+	}
+	if info.Metadata["agent_id"] != "agent_1" || info.Metadata["provider"] != "openai" || info.Metadata["model"] != "gpt-4.1" {
+		t.Fatalf("unexpected metadata: %v", info.Metadata)
+	}
+	if retry == nil || retry.RetryDelay.AsDuration() != 2*time.Second {
+		t.Fatalf("RetryInfo = %v, want 2s delay", retry)
+	}
+
+	reason, details := errorInfo(err)
+	if reason != ReasonProviderThrottled {
+		t.Fatalf("errorInfo reason = %q", reason)
+	}
+	if details["grpc_code"] != codes.ResourceExhausted.String() || details["retry_after_ms"] != float64(2000) {
+		t.Fatalf("unexpected details: %v", details)
+	}
+}
+
+func TestErrorInfoFallsBackForPlainErrors(t *testing.T) {
+	if reason, _ := errorInfo(status.Error(codes.NotFound, "gone")); reason != ReasonAgentNotFound {
+		t.Fatalf("plain NotFound status reason = %q, want %q", reason, ReasonAgentNotFound)
+	}
+	reason, details := errorInfo(errors.New("tool execution timed out after 1s: x"))
+	if reason != ReasonToolTimeout || details["grpc_code"] != codes.DeadlineExceeded.String() {
+		t.Fatalf("plain error = %q %v, want %q with DeadlineExceeded", reason, details, ReasonToolTimeout)
+	}
+}
+
+func TestCreateAgentFromPresetReportsUnknownPreset(t *testing.T) {
+	service := NewAgentService(NewAgentManager(loggerv2.NewNoop(), ""), loggerv2.NewNoop())
+
+	_, err := service.CreateAgentFromPreset(context.Background(), &pb.CreateAgentFromPresetRequest{Preset: "no-such-preset"})
+	if reason, details := errorInfo(err); reason != ReasonPresetNotFound || details["preset"] != "no-such-preset" {
+		t.Fatalf("reason = %q %v, want %q", reason, details, ReasonPresetNotFound)
+	}
+	if status.Code(err) != codes.NotFound {
+		t.Fatalf("code = %s, want NotFound", status.Code(err))
+	}
+
+	_, err = service.CreateAgentFromPreset(context.Background(), &pb.CreateAgentFromPresetRequest{})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("missing preset code = %s, want InvalidArgument", status.Code(err))
+	}
+}
@@ -0,0 +1,500 @@
+package grpcserver
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	mcpagent "github.com/manishiitg/mcpagent/agent"
+	"github.com/manishiitg/mcpagent/events"
+	"github.com/manishiitg/mcpagent/grpcserver/pb"
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+)
+
+const (
+	// DefaultEventBridgeBufferSize is the number of events queued per client
+	// before backpressure kicks in
+	DefaultEventBridgeBufferSize = 256
+
+	// EventBridgeDroppedEvent is the type of the notice sent to a client
+	// before the next event after events were dropped for it
+	EventBridgeDroppedEvent = "stream_dropped"
+	// EventBridgeOverflowEvent is the type of the notice sent before a client
+	// is disconnected because it fell too far behind
+	EventBridgeOverflowEvent = "stream_overflow"
+
+	eventBridgeHeartbeat    = 15 * time.Second
+	eventBridgeWriteTimeout = 10 * time.Second
+)
+
+// EventBridgeConfig configures the optional HTTP server that exposes the
+// per-agent event stream of WatchConversation to browsers, which cannot speak
+// gRPC, over Server-Sent Events and WebSocket:
+//
+//	GET /agents/{agent_id}/events  (text/event-stream)
+//	GET /agents/{agent_id}/ws      (WebSocket)
+//
+// Both accept the query parameters verbosity ("full", "chunks" or
+// "milestones") and replay=true, like WatchConversation. Every request must
+// present Token as "Authorization: Bearer <token>" or, because EventSource
+// and WebSocket cannot set headers in browsers, as ?token=<token>. When the
+// gRPC server runs with Config.Auth, clients send their own client token or
+// JWT the same way instead, and may only stream the agents they own (see
+// AuthConfig). Requires Config.StreamReplaySize > 0.
+type EventBridgeConfig struct {
+	// Addr is the TCP address to listen on (e.g. "127.0.0.1:8091")
+	Addr string
+	// Token is the shared secret clients must send. Required, unless the
+	// server runs with Config.Auth, where it must be empty.
+	Token string
+	// AllowedOrigins lists the browser origins (e.g. "https://app.example.com")
+	// allowed to connect; "*" allows any. Empty allows same-origin requests
+	// and clients that send no Origin header.
+	AllowedOrigins []string
+	// BufferSize is the number of events queued per client; 0 =
+	// DefaultEventBridgeBufferSize. When a client's queue is full, events
+	// that are not milestones are dropped for it (and counted in a
+	// stream_dropped notice); a milestone that does not fit disconnects the
+	// client with a stream_overflow notice so it can reconnect with replay.
+	BufferSize int
+}
+
+// EventBridgeServer streams agent events to browsers over SSE and WebSocket
+type EventBridgeServer struct {
+	httpServer *http.Server
+	addr       string
+	token      []byte
+	origins    []string
+	bufferSize int
+	manager    *AgentManager
+	artifacts  *ArtifactServer
+	auth       *authenticator // Set when clients authenticate with Config.Auth credentials
+	upgrader   websocket.Upgrader
+	logger     loggerv2.Logger
+}
+
+// NewEventBridgeServer validates cfg and creates an EventBridgeServer serving
+// the agents of manager. artifacts is optional; when set, events carry
+// download URLs as in WatchConversation.
+func NewEventBridgeServer(cfg EventBridgeConfig, manager *AgentManager, artifacts *ArtifactServer, logger loggerv2.Logger) (*EventBridgeServer, error) {
+	return newEventBridgeServer(cfg, manager, artifacts, nil, logger)
+}
+
+// newEventBridgeServer creates an EventBridgeServer; with auth, clients
+// authenticate with their Config.Auth credentials instead of cfg.Token
+func newEventBridgeServer(cfg EventBridgeConfig, manager *AgentManager, artifacts *ArtifactServer, auth *authenticator, logger loggerv2.Logger) (*EventBridgeServer, error) {
+	if logger == nil {
+		logger = loggerv2.NewDefault()
+	}
+	if cfg.Addr == "" {
+		return nil, errors.New("event bridge address is required")
+	}
+	if auth == nil && cfg.Token == "" {
+		return nil, errors.New("event bridge token is required")
+	}
+	if auth != nil && cfg.Token != "" {
+		return nil, errors.New("event bridge token must be empty when auth is configured; clients use their own credentials")
+	}
+	if manager == nil {
+		return nil, errors.New("event bridge agent manager is required")
+	}
+	if cfg.BufferSize < 0 {
+		return nil, fmt.Errorf("invalid event bridge buffer size %d", cfg.BufferSize)
+	}
+	bufferSize := cfg.BufferSize
+	if bufferSize == 0 {
+		bufferSize = DefaultEventBridgeBufferSize
+	}
+
+	s := &EventBridgeServer{
+		addr:       cfg.Addr,
+		token:      []byte(cfg.Token),
+		origins:    cfg.AllowedOrigins,
+		bufferSize: bufferSize,
+		manager:    manager,
+		artifacts:  artifacts,
+		auth:       auth,
+		logger:     logger,
+	}
+	s.upgrader = websocket.Upgrader{CheckOrigin: s.originAllowed}
+	s.httpServer = &http.Server{
+		Addr:              cfg.Addr,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	return s, nil
+}
+
+// Handler returns the HTTP handler serving the event streams
+func (s *EventBridgeServer) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /agents/{agent_id}/events", s.serveSSE)
+	mux.HandleFunc("GET /agents/{agent_id}/ws", s.serveWebSocket)
+	mux.HandleFunc("OPTIONS /agents/{agent_id}/events", s.servePreflight)
+	return mux
+}
+
+// Start listens on the configured address and serves until Shutdown is called
+func (s *EventBridgeServer) Start() error {
+	listener, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return err
+	}
+	s.logger.Info("Starting event bridge", loggerv2.String("addr", s.addr))
+	if err := s.httpServer.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// Shutdown gracefully stops the HTTP server. Open streams end when their
+// agents are destroyed or ctx expires.
+func (s *EventBridgeServer) Shutdown(ctx context.Context) error {
+	if err := s.httpServer.Shutdown(ctx); err != nil {
+		return s.httpServer.Close()
+	}
+	return nil
+}
+
+// bridgeEvent is the JSON form of an event sent to browsers
+type bridgeEvent struct {
+	Type           string           `json:"type"`
+	Timestamp      time.Time        `json:"timestamp"`
+	EventIndex     int              `json:"event_index"`
+	TraceID        string           `json:"trace_id,omitempty"`
+	SpanID         string           `json:"span_id,omitempty"`
+	ParentID       string           `json:"parent_id,omitempty"`
+	CorrelationID  string           `json:"correlation_id,omitempty"`
+	HierarchyLevel int              `json:"hierarchy_level"`
+	SessionID      string           `json:"session_id,omitempty"`
+	Component      string           `json:"component,omitempty"`
+	Data           events.EventData `json:"data,omitempty"`
+	Artifacts      []*pb.Artifact   `json:"artifacts,omitempty"`
+}
+
+// bridgeNotice reports dropped events or an overflow to a client
+type bridgeNotice struct {
+	Type    string `json:"type"`
+	Dropped int64  `json:"dropped"`
+}
+
+// bridgeMessage is an encoded event waiting in a client's queue
+type bridgeMessage struct {
+	eventType string
+	payload   []byte
+}
+
+// bridgeSubscription is one client's subscription with its bounded queue
+type bridgeSubscription struct {
+	queue    chan bridgeMessage
+	overflow chan struct{}
+	dropped  atomic.Int64
+}
+
+// subscribe subscribes to the agent named in the request and starts filling
+// the client's queue. It writes the HTTP error and returns false on failure.
+func (s *EventBridgeServer) subscribe(w http.ResponseWriter, r *http.Request) (*bridgeSubscription, bool) {
+	client, denied := s.authenticate(r)
+	if denied != nil && denied.reason == authMethodNotAllowed {
+		http.Error(w, denied.err.Error(), http.StatusForbidden)
+		return nil, false
+	}
+	if denied != nil {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="mcpagent-events"`)
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return nil, false
+	}
+	if !s.originAllowed(r) {
+		http.Error(w, "origin not allowed", http.StatusForbidden)
+		return nil, false
+	}
+
+	agentID := r.PathValue("agent_id")
+	agent, ok := s.manager.GetAgent(agentID)
+	if !ok {
+		http.Error(w, fmt.Sprintf("agent not found: %s", agentID), http.StatusNotFound)
+		return nil, false
+	}
+	if s.auth != nil && !client.owns(s.auth.owner(agentID)) {
+		s.auth.logRefusal(r.URL.Path, client.ID, &authError{authAgentNotOwned, fmt.Errorf("agent %s belongs to another client", agentID)})
+		http.Error(w, fmt.Sprintf("agent %s belongs to another client", agentID), http.StatusForbidden)
+		return nil, false
+	}
+	query := r.URL.Query()
+	verbosity, err := mcpagent.ParseStreamVerbosity(query.Get("verbosity"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+	replay := query.Get("replay") == "true" || query.Get("replay") == "1"
+
+	eventChan, unsubscribe, ok := agent.Agent.SubscribeWithOptions(r.Context(), mcpagent.SubscribeOptions{Verbosity: verbosity, Replay: replay})
+	if !ok || eventChan == nil {
+		http.Error(w, "event streaming is not enabled for this agent; start the server with stream replay enabled", http.StatusServiceUnavailable)
+		return nil, false
+	}
+
+	sub := &bridgeSubscription{
+		queue:    make(chan bridgeMessage, s.bufferSize),
+		overflow: make(chan struct{}),
+	}
+	go func() {
+		defer unsubscribe()
+		s.pump(r.Context(), eventChan, sub)
+	}()
+	return sub, true
+}
+
+// pump moves events from the agent subscription into the client's queue.
+// A client that cannot keep up loses events that are not milestones; a
+// milestone that does not fit ends the subscription.
+func (s *EventBridgeServer) pump(ctx context.Context, eventChan <-chan *events.AgentEvent, sub *bridgeSubscription) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-eventChan:
+			if !ok {
+				close(sub.queue)
+				return
+			}
+			msg, ok := s.encodeEvent(event)
+			if !ok {
+				continue
+			}
+			select {
+			case sub.queue <- msg:
+			default:
+				if !events.IsMilestoneEvent(events.EventType(msg.eventType)) {
+					sub.dropped.Add(1)
+					continue
+				}
+				close(sub.overflow)
+				return
+			}
+		}
+	}
+}
+
+// encodeEvent converts an agent event to its JSON form
+func (s *EventBridgeServer) encodeEvent(event *events.AgentEvent) (bridgeMessage, bool) {
+	if event == nil || event.Data == nil {
+		return bridgeMessage{}, false
+	}
+	out := bridgeEvent{
+		Type:           string(event.Data.GetEventType()),
+		Timestamp:      event.Timestamp,
+		EventIndex:     event.EventIndex,
+		TraceID:        event.TraceID,
+		SpanID:         event.SpanID,
+		ParentID:       event.ParentID,
+		CorrelationID:  event.CorrelationID,
+		HierarchyLevel: event.HierarchyLevel,
+		SessionID:      event.SessionID,
+		Component:      event.Component,
+		Data:           event.Data,
+	}
+	if s.artifacts != nil {
+		out.Artifacts = s.artifacts.artifactsForEvent(event.Data)
+	}
+	payload, err := json.Marshal(out)
+	if err != nil {
+		s.logger.Warn("Failed to encode event for event bridge",
+			loggerv2.String("event_type", out.Type), loggerv2.String("error", err.Error()))
+		return bridgeMessage{}, false
+	}
+	return bridgeMessage{eventType: out.Type, payload: payload}, true
+}
+
+// newBridgeNotice encodes a stream_dropped or stream_overflow notice
+func newBridgeNotice(eventType string, dropped int64) bridgeMessage {
+	payload, _ := json.Marshal(bridgeNotice{Type: eventType, Dropped: dropped})
+	return bridgeMessage{eventType: eventType, payload: payload}
+}
+
+// next waits for the client's next message: a pending stream_dropped notice
+// first, then the queued event. done is true when the stream has ended, in
+// which case msg is a stream_overflow notice if the client fell behind.
+func (sub *bridgeSubscription) next(ctx context.Context, heartbeat <-chan time.Time) (msg bridgeMessage, beat, done bool) {
+	if n := sub.dropped.Swap(0); n > 0 {
+		return newBridgeNotice(EventBridgeDroppedEvent, n), false, false
+	}
+	select {
+	case <-ctx.Done():
+		return bridgeMessage{}, false, true
+	case <-sub.overflow:
+		return newBridgeNotice(EventBridgeOverflowEvent, sub.dropped.Swap(0)), false, true
+	case <-heartbeat:
+		return bridgeMessage{}, true, false
+	case msg, ok := <-sub.queue:
+		if !ok {
+			return bridgeMessage{}, false, true
+		}
+		return msg, false, false
+	}
+}
+
+func (s *EventBridgeServer) serveSSE(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	s.setCORSHeaders(w, r)
+	sub, ok := s.subscribe(w, r)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no") // disable proxy buffering (nginx)
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(eventBridgeHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		msg, beat, done := sub.next(r.Context(), heartbeat.C)
+		var err error
+		switch {
+		case beat:
+			_, err = fmt.Fprint(w, ": heartbeat\n\n")
+		case msg.payload != nil:
+			_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", msg.eventType, msg.payload)
+		}
+		if err != nil {
+			return
+		}
+		flusher.Flush()
+		if done {
+			return
+		}
+	}
+}
+
+func (s *EventBridgeServer) serveWebSocket(w http.ResponseWriter, r *http.Request) {
+	if !websocket.IsWebSocketUpgrade(r) {
+		http.Error(w, "websocket upgrade required", http.StatusBadRequest)
+		return
+	}
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	sub, ok := s.subscribe(w, r.WithContext(ctx))
+	if !ok {
+		return
+	}
+	conn, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return // the upgrader has written the HTTP error
+	}
+	defer func() { _ = conn.Close() }()
+
+	// The stream is one-way; reading processes pings and closes from the client
+	go func() {
+		defer cancel()
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	heartbeat := time.NewTicker(eventBridgeHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		msg, beat, done := sub.next(ctx, heartbeat.C)
+		_ = conn.SetWriteDeadline(time.Now().Add(eventBridgeWriteTimeout))
+		switch {
+		case beat:
+			err = conn.WriteMessage(websocket.PingMessage, nil)
+		case msg.payload != nil:
+			err = conn.WriteMessage(websocket.TextMessage, msg.payload)
+		}
+		if err != nil {
+			return
+		}
+		if done {
+			_ = conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+			return
+		}
+	}
+}
+
+// servePreflight answers CORS preflight requests for the SSE endpoint
+func (s *EventBridgeServer) servePreflight(w http.ResponseWriter, r *http.Request) {
+	if !s.originAllowed(r) {
+		http.Error(w, "origin not allowed", http.StatusForbidden)
+		return
+	}
+	s.setCORSHeaders(w, r)
+	w.Header().Set("Access-Control-Allow-Methods", "GET, OPTIONS")
+	w.Header().Set("Access-Control-Allow-Headers", "Authorization")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// setCORSHeaders allows the request's origin to read the response when it is
+// an allowed cross-origin request
+func (s *EventBridgeServer) setCORSHeaders(w http.ResponseWriter, r *http.Request) {
+	origin := r.Header.Get("Origin")
+	if origin == "" || !s.originAllowed(r) {
+		return
+	}
+	w.Header().Set("Access-Control-Allow-Origin", origin)
+	w.Header().Add("Vary", "Origin")
+}
+
+// originAllowed reports whether the request's Origin may connect: a
+// configured origin, "*", the server's own host, or no Origin at all
+// (non-browser clients)
+func (s *EventBridgeServer) originAllowed(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	for _, allowed := range s.origins {
+		if allowed == "*" || strings.EqualFold(strings.TrimRight(allowed, "/"), origin) {
+			return true
+		}
+	}
+	host, found := strings.CutPrefix(origin, "http://")
+	if !found {
+		host, found = strings.CutPrefix(origin, "https://")
+	}
+	return found && strings.EqualFold(host, r.Host)
+}
+
+// authenticate checks the request's token: the shared Token, or with auth a
+// client credential whose scope allows WatchConversation. The client is
+// empty without auth.
+func (s *EventBridgeServer) authenticate(r *http.Request) (ClientIdentity, *authError) {
+	token, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !found {
+		token = r.URL.Query().Get("token")
+	}
+	if s.auth == nil {
+		if token == "" || subtle.ConstantTimeCompare([]byte(token), s.token) != 1 {
+			return ClientIdentity{}, &authError{authInvalidToken, errors.New("invalid token")}
+		}
+		return ClientIdentity{}, nil
+	}
+	client, denied := s.auth.authenticateToken(strings.TrimSpace(token))
+	if denied == nil {
+		denied = client.Scope.checkMethod(client.ID, pb.AgentService_WatchConversation_FullMethodName)
+	}
+	if denied != nil {
+		s.auth.logRefusal(r.URL.Path, client.ID, denied)
+		return ClientIdentity{}, denied
+	}
+	return client, nil
+}
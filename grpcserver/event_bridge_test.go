@@ -0,0 +1,242 @@
+package grpcserver
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	mcpagent "github.com/manishiitg/mcpagent/agent"
+	"github.com/manishiitg/mcpagent/events"
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+	"github.com/manishiitg/mcpagent/observability"
+)
+
+func newTestEventBridge(t *testing.T, cfg EventBridgeConfig) (*EventBridgeServer, *mcpagent.Agent) {
+	t.Helper()
+	m := NewAgentManager(loggerv2.NewNoop(), "")
+	agent := &mcpagent.Agent{
+		Logger:  loggerv2.NewNoop(),
+		Tracers: []observability.Tracer{mcpagent.NewStreamingTracer(observability.NoopTracer{}, 100)},
+	}
+	m.agents["agent-1"] = &ManagedAgent{ID: "agent-1", Agent: agent}
+	m.agents["no-stream"] = &ManagedAgent{ID: "no-stream", Agent: &mcpagent.Agent{Logger: loggerv2.NewNoop()}}
+
+	cfg.Addr = "127.0.0.1:0"
+	cfg.Token = "s3cret"
+	s, err := NewEventBridgeServer(cfg, m, nil, loggerv2.NewNoop())
+	if err != nil {
+		t.Fatalf("NewEventBridgeServer: %v", err)
+	}
+	return s, agent
+}
+
+func TestEventBridgeRejectsBadRequests(t *testing.T) {
+	if _, err := NewEventBridgeServer(EventBridgeConfig{Addr: "127.0.0.1:0"}, NewAgentManager(loggerv2.NewNoop(), ""), nil, nil); err == nil {
+		t.Fatal("expected error when token is empty")
+	}
+
+	s, _ := newTestEventBridge(t, EventBridgeConfig{AllowedOrigins: []string{"https://app.example.com"}})
+	cases := []struct {
+		path   string
+		origin string
+		want   int
+	}{
+		{"/agents/agent-1/events", "", http.StatusUnauthorized},
+		{"/agents/agent-1/events?token=wrong", "", http.StatusUnauthorized},
+		{"/agents/agent-1/events?token=s3cret", "https://evil.example.com", http.StatusForbidden},
+		{"/agents/missing/events?token=s3cret", "", http.StatusNotFound},
+		{"/agents/agent-1/events?token=s3cret&verbosity=loud", "", http.StatusBadRequest},
+		{"/agents/no-stream/events?token=s3cret", "", http.StatusServiceUnavailable},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+		if tc.origin != "" {
+			req.Header.Set("Origin", tc.origin)
+		}
+		rec := httptest.NewRecorder()
+		s.Handler().ServeHTTP(rec, req)
+		if rec.Code != tc.want {
+			t.Errorf("%s (origin %q): status = %d, want %d", tc.path, tc.origin, rec.Code, tc.want)
+		}
+	}
+}
+
+func TestEventBridgeStreamsServerSentEvents(t *testing.T) {
+	s, agent := newTestEventBridge(t, EventBridgeConfig{AllowedOrigins: []string{"https://app.example.com"}})
+	server := httptest.NewServer(s.Handler())
+	defer server.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/agents/agent-1/events?verbosity=milestones", nil)
+	req.Header.Set("Authorization", "Bearer s3cret")
+	req.Header.Set("Origin", "https://app.example.com")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("status = %d, content type = %q", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	if got := resp.Header.Get("Access-Control-Allow-Origin"); got != "https://app.example.com" {
+		t.Errorf("Access-Control-Allow-Origin = %q", got)
+	}
+
+	// The milestones verbosity filters the chunk out
+	agent.EmitTypedEvent(context.Background(), &events.StreamingChunkEvent{Content: "partial"})
+	agent.EmitTypedEvent(context.Background(), events.NewToolCallStartEvent(1, "search", events.ToolParams{Arguments: "{}"}, "web", ""))
+
+	reader := bufio.NewReader(resp.Body)
+	var eventLine, dataLine string
+	for eventLine == "" || dataLine == "" {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v, ok := strings.CutPrefix(line, "event: "); ok {
+			eventLine = strings.TrimSpace(v)
+		}
+		if v, ok := strings.CutPrefix(line, "data: "); ok {
+			dataLine = strings.TrimSpace(v)
+		}
+	}
+	if eventLine != string(events.ToolCallStart) {
+		t.Fatalf("event = %q, want %s", eventLine, events.ToolCallStart)
+	}
+	var payload struct {
+		Type string `json:"type"`
+		Data struct {
+			ToolName string `json:"tool_name"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal([]byte(dataLine), &payload); err != nil {
+		t.Fatalf("data is not JSON: %v (%s)", err, dataLine)
+	}
+	if payload.Type != string(events.ToolCallStart) || payload.Data.ToolName != "search" {
+		t.Errorf("payload = %s", dataLine)
+	}
+}
+
+func TestEventBridgeStreamsWebSocketMessages(t *testing.T) {
+	s, agent := newTestEventBridge(t, EventBridgeConfig{})
+	server := httptest.NewServer(s.Handler())
+	defer server.Close()
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/agents/agent-1/ws?token=s3cret"
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	agent.EmitTypedEvent(context.Background(), events.NewToolCallStartEvent(1, "search", events.ToolParams{Arguments: "{}"}, "web", ""))
+
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var payload struct {
+		Type string `json:"type"`
+	}
+	if err := conn.ReadJSON(&payload); err != nil {
+		t.Fatal(err)
+	}
+	if payload.Type != string(events.ToolCallStart) {
+		t.Errorf("type = %q, want %s", payload.Type, events.ToolCallStart)
+	}
+
+	if _, _, err := websocket.DefaultDialer.Dial(strings.Replace(url, "s3cret", "wrong", 1), nil); err == nil {
+		t.Error("expected the handshake to fail with a wrong token")
+	}
+}
+
+func TestEventBridgeBackpressure(t *testing.T) {
+	s, _ := newTestEventBridge(t, EventBridgeConfig{BufferSize: 1})
+	chunk := &events.AgentEvent{Type: events.StreamingChunk, Data: &events.StreamingChunkEvent{Content: "x"}}
+	milestone := &events.AgentEvent{Type: events.ToolCallStart, Data: events.NewToolCallStartEvent(1, "search", events.ToolParams{}, "web", "")}
+
+	// A slow client loses chunks but is told how many
+	eventChan := make(chan *events.AgentEvent, 10)
+	eventChan <- milestone
+	eventChan <- chunk
+	eventChan <- chunk
+	close(eventChan)
+	sub := &bridgeSubscription{queue: make(chan bridgeMessage, s.bufferSize), overflow: make(chan struct{})}
+	s.pump(context.Background(), eventChan, sub)
+
+	ctx := context.Background()
+	var got []string
+	for {
+		msg, _, done := sub.next(ctx, nil)
+		if done {
+			break
+		}
+		got = append(got, msg.eventType)
+	}
+	if strings.Join(got, ",") != "stream_dropped,tool_call_start" {
+		t.Errorf("messages = %v, want the dropped notice, then the milestone", got)
+	}
+
+	// A milestone that does not fit disconnects the client
+	eventChan = make(chan *events.AgentEvent, 10)
+	eventChan <- milestone
+	eventChan <- milestone
+	sub = &bridgeSubscription{queue: make(chan bridgeMessage, s.bufferSize), overflow: make(chan struct{})}
+	s.pump(context.Background(), eventChan, sub)
+	<-sub.queue
+	msg, _, done := sub.next(ctx, nil)
+	if !done || msg.eventType != EventBridgeOverflowEvent {
+		t.Errorf("next = %q (done %v), want %s", msg.eventType, done, EventBridgeOverflowEvent)
+	}
+}
+
+func TestEventBridgeStreamsOnlyOwnAgentsWithAuth(t *testing.T) {
+	m := NewAgentManager(loggerv2.NewNoop(), "")
+	auth, err := newAuthenticator(AuthConfig{Clients: []ClientCredential{
+		{ID: "support", Token: "a"},
+		{ID: "ops", Token: "b"},
+		{ID: "asker", Token: "c", Scope: ClientScope{Methods: []string{"Ask"}}},
+		{ID: "root", Token: "d", Scope: ClientScope{Admin: true}},
+	}}, m, loggerv2.NewNoop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.agents["agent-1"] = &ManagedAgent{ID: "agent-1", Agent: &mcpagent.Agent{
+		Logger:  loggerv2.NewNoop(),
+		Tracers: []observability.Tracer{mcpagent.NewStreamingTracer(observability.NoopTracer{}, 100)},
+	}}
+	auth.owners["agent-1"] = "support"
+
+	if _, err := newEventBridgeServer(EventBridgeConfig{Addr: "127.0.0.1:0", Token: "s3cret"}, m, nil, auth, nil); err == nil {
+		t.Error("a shared token should be refused when auth is configured")
+	}
+	s, err := newEventBridgeServer(EventBridgeConfig{Addr: "127.0.0.1:0"}, m, nil, auth, loggerv2.NewNoop())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		token string
+		want  int
+	}{
+		{"s3cret", http.StatusUnauthorized},
+		{"b", http.StatusForbidden}, // another client's agent
+		{"c", http.StatusForbidden}, // scope without WatchConversation
+		{"a", http.StatusOK},
+		{"d", http.StatusOK},
+	}
+	for _, tc := range cases {
+		// Accepted streams run until the request context ends
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		req := httptest.NewRequest(http.MethodGet, "/agents/agent-1/events?token="+tc.token, nil).WithContext(ctx)
+		rec := httptest.NewRecorder()
+		s.Handler().ServeHTTP(rec, req)
+		cancel()
+		if rec.Code != tc.want {
+			t.Errorf("token %q: status = %d, want %d", tc.token, rec.Code, tc.want)
+		}
+	}
+}
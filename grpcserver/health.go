@@ -0,0 +1,313 @@
+package grpcserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"sort"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/manishiitg/mcpagent/grpcserver/pb"
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+	"github.com/manishiitg/mcpagent/mcpcache"
+	"github.com/manishiitg/mcpagent/mcpclient"
+)
+
+// Readiness check names reported by /readyz
+const (
+	ReadinessCheckGRPC         = "grpc"
+	ReadinessCheckConfig       = "config"
+	ReadinessCheckMCPPreflight = "mcp_preflight"
+)
+
+// Statuses reported by HealthCheckDetailed
+const (
+	HealthStatusOK       = "ok"
+	HealthStatusDegraded = "degraded"
+	HealthStatusNotReady = "not_ready"
+)
+
+// defaultConnectionCheckTimeout bounds the MCP connection checks of all agents
+// in HealthCheckDetailed
+const defaultConnectionCheckTimeout = 5 * time.Second
+
+// healthLookPath resolves stdio server commands; replaced in tests
+var healthLookPath = exec.LookPath
+
+// ReadinessStatus is the JSON body served by /readyz
+type ReadinessStatus struct {
+	Ready  bool              `json:"ready"`
+	Checks map[string]string `json:"checks"` // check name → "ok" or the failure reason
+}
+
+// readiness tracks the state /readyz reports
+type readiness struct {
+	mu           sync.RWMutex
+	serving      bool
+	configErr    error
+	preflightErr error
+
+	// Mirrors the readiness over grpc.health.v1 for the server ("") and
+	// AgentService; nil when not registered
+	grpcHealth *health.Server
+}
+
+func (r *readiness) setServing(serving bool) {
+	r.mu.Lock()
+	r.serving = serving
+	r.mu.Unlock()
+	r.publish()
+}
+
+func (r *readiness) setConfig(configErr, preflightErr error) {
+	r.mu.Lock()
+	r.configErr, r.preflightErr = configErr, preflightErr
+	r.mu.Unlock()
+	r.publish()
+}
+
+// publish updates the grpc.health.v1 serving status from the readiness
+func (r *readiness) publish() {
+	if r.grpcHealth == nil {
+		return
+	}
+	servingStatus := healthpb.HealthCheckResponse_NOT_SERVING
+	if r.status().Ready {
+		servingStatus = healthpb.HealthCheckResponse_SERVING
+	}
+	for _, service := range []string{"", pb.AgentService_ServiceDesc.ServiceName} {
+		r.grpcHealth.SetServingStatus(service, servingStatus)
+	}
+}
+
+func (r *readiness) status() ReadinessStatus {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	status := ReadinessStatus{Ready: true, Checks: make(map[string]string, 3)}
+	report := func(name string, err error) {
+		if err != nil {
+			status.Ready = false
+			status.Checks[name] = err.Error()
+			return
+		}
+		status.Checks[name] = "ok"
+	}
+	var grpcErr error
+	if !r.serving {
+		grpcErr = errors.New("not serving")
+	}
+	report(ReadinessCheckGRPC, grpcErr)
+	report(ReadinessCheckConfig, r.configErr)
+	report(ReadinessCheckMCPPreflight, r.preflightErr)
+	return status
+}
+
+// checkMCPConfig loads the MCP config at configPath and verifies every server
+// can be reached in principle: stdio commands resolve on PATH and remote
+// servers have a URL. No server is started. A missing file is not an error,
+// since agents without MCP servers still work.
+func checkMCPConfig(configPath string) (configErr, preflightErr error) {
+	if configPath == "" {
+		return nil, nil
+	}
+	if _, err := os.Stat(configPath); errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	config, err := mcpclient.LoadConfig(configPath, nil)
+	if err != nil {
+		return err, errors.New("config not loaded")
+	}
+	for _, name := range config.ListServers() {
+		server := config.MCPServers[name]
+		switch protocol := server.GetProtocol(); protocol {
+		case mcpclient.ProtocolStdio:
+			if server.Command == "" {
+				return nil, fmt.Errorf("server %s: stdio server has no command", name)
+			}
+			if _, err := healthLookPath(server.Command); err != nil {
+				return nil, fmt.Errorf("server %s: command %q not found", name, server.Command)
+			}
+		default:
+			if server.URL == "" {
+				return nil, fmt.Errorf("server %s: %s server has no url", name, protocol)
+			}
+		}
+	}
+	return nil, nil
+}
+
+// HealthServer serves liveness (/healthz) and readiness (/readyz) probes for
+// orchestrators such as Kubernetes or PM2
+type HealthServer struct {
+	httpServer *http.Server
+	addr       string
+	readiness  *readiness
+	logger     loggerv2.Logger
+}
+
+func newHealthServer(addr string, r *readiness, logger loggerv2.Logger) *HealthServer {
+	s := &HealthServer{addr: addr, readiness: r, logger: logger}
+	s.httpServer = &http.Server{
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	return s
+}
+
+// Handler returns the HTTP handler serving the probes
+func (s *HealthServer) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, _ *http.Request) {
+		writeHealthJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	})
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, _ *http.Request) {
+		status := s.readiness.status()
+		code := http.StatusOK
+		if !status.Ready {
+			code = http.StatusServiceUnavailable
+		}
+		writeHealthJSON(w, code, status)
+	})
+	return mux
+}
+
+// Start listens on the configured address and serves until Shutdown is called
+func (s *HealthServer) Start() error {
+	listener, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return err
+	}
+	s.logger.Info("Starting health server", loggerv2.String("addr", s.addr))
+	if err := s.httpServer.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// Shutdown gracefully stops the HTTP server
+func (s *HealthServer) Shutdown(ctx context.Context) error {
+	return s.httpServer.Shutdown(ctx)
+}
+
+func writeHealthJSON(w http.ResponseWriter, code int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// HealthCheckDetailed reports readiness, the MCP connection health of the
+// managed agents (see Agent.CheckConnectionHealth), the MCP cache status and
+// how many agents and conversations are active. Agents are checked in
+// parallel under one deadline, so unhealthy agents do not add up.
+func (s *AgentService) HealthCheckDetailed(ctx context.Context, req *pb.HealthCheckDetailedRequest) (*pb.HealthCheckDetailedResponse, error) {
+	var agents []*ManagedAgent
+	if req.AgentId != "" {
+		agent, ok := s.manager.GetAgent(req.AgentId)
+		if !ok {
+			return nil, agentNotFoundError(req.AgentId)
+		}
+		agents = []*ManagedAgent{agent}
+	} else {
+		s.manager.mu.RLock()
+		for _, agent := range s.manager.agents {
+			agents = append(agents, agent)
+		}
+		s.manager.mu.RUnlock()
+		sort.Slice(agents, func(i, j int) bool { return agents[i].ID < agents[j].ID })
+	}
+
+	resp := &pb.HealthCheckDetailedResponse{
+		Ready:               true,
+		Draining:            s.manager.Draining(),
+		ActiveAgents:        safeIntToInt32(len(s.manager.ListAgents())),
+		ActiveConversations: safeIntToInt32(s.manager.ActiveConversations()),
+		Cache:               cacheStatusToProto(mcpcache.GetCacheManager(s.logger).GetStats()),
+	}
+	if s.readiness != nil {
+		status := s.readiness.status()
+		resp.Ready, resp.Checks = status.Ready, status.Checks
+	}
+	if resp.Draining {
+		resp.Ready = false
+	}
+
+	degraded := false
+	if !req.SkipConnections {
+		timeout := defaultConnectionCheckTimeout
+		if req.TimeoutMs > 0 {
+			timeout = time.Duration(req.TimeoutMs) * time.Millisecond
+		}
+		checkCtx, cancel := context.WithTimeout(ctx, timeout)
+		resp.Agents = make([]*pb.AgentHealth, len(agents))
+		var wg sync.WaitGroup
+		for i, agent := range agents {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				resp.Agents[i] = checkAgentHealth(checkCtx, agent)
+			}()
+		}
+		wg.Wait()
+		cancel()
+		for _, agentHealth := range resp.Agents {
+			for _, server := range agentHealth.Servers {
+				degraded = degraded || !server.Healthy
+			}
+		}
+	}
+
+	switch {
+	case !resp.Ready:
+		resp.Status = HealthStatusNotReady
+	case degraded:
+		resp.Status = HealthStatusDegraded
+	default:
+		resp.Status = HealthStatusOK
+	}
+	return resp, nil
+}
+
+// checkAgentHealth checks every MCP connection of agent
+func checkAgentHealth(ctx context.Context, agent *ManagedAgent) *pb.AgentHealth {
+	agentHealth := &pb.AgentHealth{AgentId: agent.ID, SessionId: agent.SessionID}
+	if agent.Agent == nil {
+		return agentHealth
+	}
+	failures := agent.Agent.CheckConnectionHealth(ctx)
+	for name := range agent.Agent.Clients {
+		server := &pb.ServerHealth{Name: name, Healthy: true}
+		if err := failures[name]; err != nil {
+			server.Healthy, server.Error = false, err.Error()
+		}
+		agentHealth.Servers = append(agentHealth.Servers, server)
+	}
+	sort.Slice(agentHealth.Servers, func(i, j int) bool { return agentHealth.Servers[i].Name < agentHealth.Servers[j].Name })
+	return agentHealth
+}
+
+// cacheStatusToProto converts mcpcache.CacheManager.GetStats output
+func cacheStatusToProto(stats map[string]interface{}) *pb.CacheStatus {
+	count := func(key string) int32 {
+		n, _ := stats[key].(int)
+		return safeIntToInt32(n)
+	}
+	directory, _ := stats["cache_directory"].(string)
+	return &pb.CacheStatus{
+		TotalEntries:   count("total_entries"),
+		ValidEntries:   count("valid_entries"),
+		ExpiredEntries: count("expired_entries"),
+		TtlMinutes:     count("ttl_minutes"),
+		Directory:      directory,
+	}
+}
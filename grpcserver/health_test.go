@@ -0,0 +1,176 @@
+package grpcserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	mcpagent "github.com/manishiitg/mcpagent/agent"
+	"github.com/manishiitg/mcpagent/grpcserver/pb"
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+	"github.com/manishiitg/mcpagent/mcpclient"
+)
+
+func getReadiness(t *testing.T, h http.Handler) (int, ReadinessStatus) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	var status ReadinessStatus
+	if err := json.NewDecoder(rec.Body).Decode(&status); err != nil {
+		t.Fatalf("decode /readyz: %v", err)
+	}
+	return rec.Code, status
+}
+
+func TestHealthServerProbes(t *testing.T) {
+	origLookPath := healthLookPath
+	defer func() { healthLookPath = origLookPath }()
+	healthLookPath = func(file string) (string, error) {
+		if file == "npx" {
+			return "/usr/bin/npx", nil
+		}
+		return "", errors.New("not found")
+	}
+
+	configPath := filepath.Join(t.TempDir(), "mcp_servers.json")
+	writeConfig := func(body string) {
+		if err := os.WriteFile(configPath, []byte(body), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeConfig(`{"mcpServers": {"fs": {"command": "npx"}, "docs": {"url": "https://docs.example.com/mcp"}}}`)
+
+	s := NewServer(Config{DefaultConfigPath: configPath, Logger: loggerv2.NewNoop(), HealthAddr: "127.0.0.1:0"})
+	h := s.health.Handler()
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("/healthz = %d, want 200", rec.Code)
+	}
+
+	if err := s.ReloadConfig(); err != nil {
+		t.Fatalf("ReloadConfig: %v", err)
+	}
+	if code, status := getReadiness(t, h); code != http.StatusServiceUnavailable || status.Checks[ReadinessCheckGRPC] == "ok" {
+		t.Errorf("expected not ready before serving, got %d %+v", code, status)
+	}
+
+	s.readiness.setServing(true)
+	if code, status := getReadiness(t, h); code != http.StatusOK || !status.Ready {
+		t.Errorf("expected ready, got %d %+v", code, status)
+	}
+
+	writeConfig(`{"mcpServers": {"fs": {"command": "missing-binary"}}}`)
+	if err := s.ReloadConfig(); err == nil {
+		t.Error("expected the reload to fail the preflight")
+	}
+	code, status := getReadiness(t, h)
+	if code != http.StatusServiceUnavailable || !strings.Contains(status.Checks[ReadinessCheckMCPPreflight], "missing-binary") {
+		t.Errorf("expected preflight failure, got %d %+v", code, status)
+	}
+
+	writeConfig(`{not json`)
+	if err := s.ReloadConfig(); err == nil {
+		t.Error("expected the reload to fail loading the config")
+	}
+	if _, status := getReadiness(t, h); status.Checks[ReadinessCheckConfig] == "ok" {
+		t.Errorf("expected config failure, got %+v", status)
+	}
+}
+
+func TestGRPCHealthMirrorsReadiness(t *testing.T) {
+	s := NewServer(Config{Logger: loggerv2.NewNoop()})
+	check := func() healthpb.HealthCheckResponse_ServingStatus {
+		t.Helper()
+		resp, err := s.readiness.grpcHealth.Check(context.Background(), &healthpb.HealthCheckRequest{Service: pb.AgentService_ServiceDesc.ServiceName})
+		if err != nil {
+			t.Fatalf("Check: %v", err)
+		}
+		return resp.Status
+	}
+
+	if got := check(); got != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Errorf("before serving = %s, want NOT_SERVING", got)
+	}
+	s.readiness.setServing(true)
+	if got := check(); got != healthpb.HealthCheckResponse_SERVING {
+		t.Errorf("serving = %s, want SERVING", got)
+	}
+	s.readiness.setConfig(errors.New("bad config"), nil)
+	if got := check(); got != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Errorf("with a config error = %s, want NOT_SERVING", got)
+	}
+}
+
+// hangingClient is an MCP connection whose health check hangs until cancelled
+type hangingClient struct {
+	mcpclient.ClientInterface
+}
+
+func (hangingClient) ListTools(ctx context.Context) ([]mcp.Tool, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestHealthCheckDetailedChecksAgentsUnderOneDeadline(t *testing.T) {
+	s := NewServer(Config{Logger: loggerv2.NewNoop()})
+	for _, id := range []string{"agent-1", "agent-2", "agent-3"} {
+		agent := &mcpagent.Agent{Logger: loggerv2.NewNoop(), Clients: map[string]mcpclient.ClientInterface{"hung": hangingClient{}}}
+		s.manager.agents[id] = &ManagedAgent{ID: id, Agent: agent}
+	}
+
+	start := time.Now()
+	resp, err := s.service.HealthCheckDetailed(context.Background(), &pb.HealthCheckDetailedRequest{TimeoutMs: 200})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("checking 3 hung agents took %v, want about one 200ms deadline", elapsed)
+	}
+	if len(resp.Agents) != 3 || resp.Agents[2].AgentId != "agent-3" || resp.Agents[2].Servers[0].Healthy || resp.Status != HealthStatusNotReady {
+		t.Errorf("response = %+v", resp)
+	}
+}
+
+func TestHealthCheckDetailed(t *testing.T) {
+	s := NewServer(Config{Logger: loggerv2.NewNoop()})
+	s.readiness.setServing(true)
+	agent := &mcpagent.Agent{Logger: loggerv2.NewNoop(), Clients: map[string]mcpclient.ClientInterface{"broken": nil}}
+	s.manager.agents["agent-1"] = &ManagedAgent{ID: "agent-1", SessionID: "session-1", Agent: agent}
+
+	resp, err := s.service.HealthCheckDetailed(context.Background(), &pb.HealthCheckDetailedRequest{})
+	if err != nil {
+		t.Fatalf("HealthCheckDetailed: %v", err)
+	}
+	if resp.Status != HealthStatusDegraded || !resp.Ready || resp.ActiveAgents != 1 || resp.Cache == nil {
+		t.Errorf("response = %+v", resp)
+	}
+	if len(resp.Agents) != 1 || len(resp.Agents[0].Servers) != 1 || resp.Agents[0].Servers[0].Healthy || resp.Agents[0].Servers[0].Error == "" {
+		t.Errorf("agents = %+v", resp.Agents)
+	}
+
+	resp, err = s.service.HealthCheckDetailed(context.Background(), &pb.HealthCheckDetailedRequest{SkipConnections: true})
+	if err != nil || resp.Status != HealthStatusOK || len(resp.Agents) != 0 {
+		t.Errorf("skipping connections: %+v, %v", resp, err)
+	}
+
+	if _, err := s.service.HealthCheckDetailed(context.Background(), &pb.HealthCheckDetailedRequest{AgentId: "missing"}); err == nil {
+		t.Error("expected an error for an unknown agent")
+	}
+
+	s.readiness.setServing(false)
+	if resp, _ := s.service.HealthCheckDetailed(context.Background(), &pb.HealthCheckDetailedRequest{SkipConnections: true}); resp.Status != HealthStatusNotReady {
+		t.Errorf("status when not serving = %q", resp.Status)
+	}
+}
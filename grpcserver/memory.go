@@ -0,0 +1,113 @@
+package grpcserver
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	mcpagent "github.com/manishiitg/mcpagent/agent"
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+)
+
+// SetMemoryLimit caps the session state retained by all managed agents.
+// Agents created afterwards retain their last conversation history (see
+// mcpagent.WithHistoryRetention) so it can be accounted. After every
+// conversation the manager sums each agent's MemoryUsage and,
+// while the total exceeds limitBytes, evicts the state of the least recently
+// active idle agent using policy. Spilling requires an autosave store (see
+// SetAutosave); without one spilled state is dropped. limitBytes <= 0 disables
+// the ceiling.
+func (m *AgentManager) SetMemoryLimit(limitBytes int64, policy mcpagent.MemoryPolicy) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if policy == "" {
+		policy = mcpagent.MemoryPolicySpill
+	}
+	m.memoryLimitBytes = limitBytes
+	m.memoryPolicy = policy
+}
+
+// beginConversation marks agent busy so it is never evicted mid-conversation.
+// The returned func marks it idle again and enforces the memory ceiling.
+func (m *AgentManager) beginConversation(agent *ManagedAgent) func() {
+	agent.activityMu.Lock()
+	agent.activeConversations++
+	agent.lastActive = time.Now()
+	agent.activityMu.Unlock()
+
+	return func() {
+		agent.activityMu.Lock()
+		agent.activeConversations--
+		agent.lastActive = time.Now()
+		agent.activityMu.Unlock()
+		m.EnforceMemoryLimit(context.Background())
+	}
+}
+
+// EnforceMemoryLimit evicts idle agents' retained state, oldest activity
+// first, until the pool is back under its memory ceiling. Agents in a
+// conversation are never evicted, so the total can stay above the ceiling
+// while they run. Returns the number of agents evicted.
+func (m *AgentManager) EnforceMemoryLimit(ctx context.Context) int {
+	m.mu.RLock()
+	limit, policy, store := m.memoryLimitBytes, m.memoryPolicy, m.autosaveStore
+	agents := make([]*ManagedAgent, 0, len(m.agents))
+	for _, agent := range m.agents {
+		agents = append(agents, agent)
+	}
+	m.mu.RUnlock()
+
+	if limit <= 0 {
+		return 0
+	}
+
+	type candidate struct {
+		agent      *ManagedAgent
+		lastActive time.Time
+		usage      int64
+	}
+	var total int64
+	candidates := make([]candidate, 0, len(agents))
+	for _, agent := range agents {
+		usage := agent.Agent.MemoryUsage().Total()
+		total += usage
+		agent.activityMu.Lock()
+		idle, lastActive := agent.activeConversations == 0, agent.lastActive
+		agent.activityMu.Unlock()
+		if idle && usage > 0 {
+			candidates = append(candidates, candidate{agent: agent, lastActive: lastActive, usage: usage})
+		}
+	}
+	if total <= limit {
+		return 0
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].lastActive.Before(candidates[j].lastActive)
+	})
+
+	evicted := 0
+	for _, c := range candidates {
+		if total <= limit {
+			break
+		}
+		effective := policy
+		if effective == mcpagent.MemoryPolicySpill && store == nil {
+			effective = mcpagent.MemoryPolicyDrop
+		}
+		freed, err := c.agent.Agent.EvictSessionState(ctx, effective, store, limit)
+		if err != nil {
+			m.logger.Warn("Failed to spill agent session state",
+				loggerv2.String("agent_id", c.agent.ID), loggerv2.Error(err))
+			continue
+		}
+		total -= freed.Total()
+		evicted++
+	}
+
+	if total > limit {
+		m.logger.Warn("Agent pool still above memory limit after eviction",
+			loggerv2.Any("usage_bytes", total), loggerv2.Any("limit_bytes", limit))
+	}
+	return evicted
+}
@@ -0,0 +1,68 @@
+package grpcserver
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	mcpagent "github.com/manishiitg/mcpagent/agent"
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+)
+
+func memoryTestManagedAgent(id string, lastActive time.Time, logBytes int) *ManagedAgent {
+	return &ManagedAgent{
+		ID: id,
+		Agent: &mcpagent.Agent{
+			Logger:      loggerv2.NewNoop(),
+			ToolCallLog: []string{strings.Repeat("x", logBytes)},
+		},
+		lastActive: lastActive,
+	}
+}
+
+func TestEnforceMemoryLimitEvictsOldestIdleAgentsFirst(t *testing.T) {
+	m := NewAgentManager(loggerv2.NewNoop(), "")
+	now := time.Now()
+	oldest := memoryTestManagedAgent("oldest", now.Add(-3*time.Minute), 400)
+	busy := memoryTestManagedAgent("busy", now.Add(-4*time.Minute), 400)
+	busy.activeConversations = 1
+	middle := memoryTestManagedAgent("middle", now.Add(-2*time.Minute), 400)
+	newest := memoryTestManagedAgent("newest", now.Add(-time.Minute), 400)
+	for _, agent := range []*ManagedAgent{oldest, busy, middle, newest} {
+		m.agents[agent.ID] = agent
+	}
+
+	if evicted := m.EnforceMemoryLimit(context.Background()); evicted != 0 {
+		t.Fatalf("evicted %d agents without a memory limit", evicted)
+	}
+
+	m.SetMemoryLimit(900, mcpagent.MemoryPolicyDrop)
+	if evicted := m.EnforceMemoryLimit(context.Background()); evicted != 2 {
+		t.Fatalf("evicted %d agents, want 2", evicted)
+	}
+	for _, tc := range []struct {
+		agent *ManagedAgent
+		kept  bool
+	}{{oldest, false}, {busy, true}, {middle, false}, {newest, true}} {
+		if kept := tc.agent.Agent.MemoryUsage().Total() > 0; kept != tc.kept {
+			t.Errorf("agent %s kept state = %v, want %v", tc.agent.ID, kept, tc.kept)
+		}
+	}
+}
+
+func TestBeginConversationProtectsAgentUntilDone(t *testing.T) {
+	m := NewAgentManager(loggerv2.NewNoop(), "")
+	m.SetMemoryLimit(100, mcpagent.MemoryPolicySpill) // no autosave store: spill falls back to drop
+	agent := memoryTestManagedAgent("agent", time.Now(), 400)
+	m.agents[agent.ID] = agent
+
+	done := m.beginConversation(agent)
+	if evicted := m.EnforceMemoryLimit(context.Background()); evicted != 0 {
+		t.Fatalf("evicted an agent in a conversation")
+	}
+	done()
+	if total := agent.Agent.MemoryUsage().Total(); total != 0 {
+		t.Errorf("expected state to be evicted once the conversation ended, still %d bytes", total)
+	}
+}
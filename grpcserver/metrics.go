@@ -0,0 +1,97 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+	"github.com/manishiitg/mcpagent/metrics"
+)
+
+// SetMetrics adds collector as an event listener to agents created after this
+// call and reports the number of managed agents as mcpagent_active_agents
+func (m *AgentManager) SetMetrics(collector *metrics.Collector) {
+	m.metricsCollector.Store(collector)
+	collector.SetActiveAgents(func() int {
+		m.mu.RLock()
+		defer m.mu.RUnlock()
+		return len(m.agents)
+	})
+}
+
+// grpcMetrics counts gRPC requests by method and status code
+type grpcMetrics struct {
+	requests *metrics.CounterVec
+	duration *metrics.HistogramVec
+}
+
+func newGRPCMetrics(registry *metrics.Registry) *grpcMetrics {
+	return &grpcMetrics{
+		requests: registry.NewCounter("mcpagent_grpc_requests_total", "gRPC requests by method and status code.", "method", "code"),
+		duration: registry.NewHistogram("mcpagent_grpc_request_duration_seconds", "Duration of gRPC requests by method; streams are measured until they end.",
+			metrics.DefaultDurationBuckets, "method"),
+	}
+}
+
+func (g *grpcMetrics) observe(method string, start time.Time, err error) {
+	g.requests.Inc(method, status.Code(err).String())
+	g.duration.Observe(time.Since(start).Seconds(), method)
+}
+
+func (g *grpcMetrics) unaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	start := time.Now()
+	resp, err := handler(ctx, req)
+	g.observe(info.FullMethod, start, err)
+	return resp, err
+}
+
+func (g *grpcMetrics) streamInterceptor(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	start := time.Now()
+	err := handler(srv, ss)
+	g.observe(info.FullMethod, start, err)
+	return err
+}
+
+// MetricsServer serves the Prometheus metrics of the server and its agents at /metrics
+type MetricsServer struct {
+	httpServer *http.Server
+	addr       string
+	logger     loggerv2.Logger
+}
+
+func newMetricsServer(addr string, collector *metrics.Collector, logger loggerv2.Logger) *MetricsServer {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", collector.Handler())
+	return &MetricsServer{
+		addr:   addr,
+		logger: logger,
+		httpServer: &http.Server{
+			Handler:           mux,
+			ReadHeaderTimeout: 10 * time.Second,
+		},
+	}
+}
+
+// Start listens on the configured address and serves until Shutdown is called
+func (s *MetricsServer) Start() error {
+	listener, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return err
+	}
+	s.logger.Info("Starting metrics server", loggerv2.String("addr", s.addr))
+	if err := s.httpServer.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// Shutdown gracefully stops the HTTP server
+func (s *MetricsServer) Shutdown(ctx context.Context) error {
+	return s.httpServer.Shutdown(ctx)
+}
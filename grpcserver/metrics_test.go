@@ -0,0 +1,45 @@
+package grpcserver
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+
+	mcpagent "github.com/manishiitg/mcpagent/agent"
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+	"github.com/manishiitg/mcpagent/metrics"
+)
+
+func TestServerMetrics(t *testing.T) {
+	server := NewServer(Config{SocketPath: t.TempDir() + "/agent.sock", Logger: loggerv2.NewNoop(), MetricsAddr: "127.0.0.1:0"})
+	collector := server.Metrics()
+	if collector == nil {
+		t.Fatal("expected a collector when MetricsAddr is set")
+	}
+	server.manager.agents["agent-1"] = &ManagedAgent{ID: "agent-1", Agent: &mcpagent.Agent{Logger: loggerv2.NewNoop()}}
+
+	gm := newGRPCMetrics(metrics.NewRegistry())
+	info := &grpc.UnaryServerInfo{FullMethod: "/mcpagent.v1.AgentService/GetAgent"}
+	_, _ = gm.unaryInterceptor(context.Background(), nil, info, func(context.Context, any) (any, error) {
+		return nil, agentNotFoundError("missing")
+	})
+	if got := gm.requests.Value(info.FullMethod, "NotFound"); got != 1 {
+		t.Errorf("requests{code=NotFound} = %v, want 1", got)
+	}
+
+	rec := httptest.NewRecorder()
+	server.metrics.httpServer.Handler.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body := rec.Body.String()
+	for _, want := range []string{"mcpagent_active_agents 1", "# TYPE mcpagent_grpc_requests_total counter"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("missing %q in:\n%s", want, body)
+		}
+	}
+
+	if NewServer(Config{SocketPath: t.TempDir() + "/agent.sock", Logger: loggerv2.NewNoop()}).Metrics() != nil {
+		t.Error("metrics should be disabled without MetricsAddr")
+	}
+}
@@ -28,7 +28,11 @@ type CreateAgentRequest struct {
 	// Optional session ID (auto-generated if empty)
 	SessionId string `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
 	// Agent configuration
-	Config        *AgentConfig `protobuf:"bytes,2,opt,name=config,proto3" json:"config,omitempty"`
+	Config *AgentConfig `protobuf:"bytes,2,opt,name=config,proto3" json:"config,omitempty"`
+	// Optional warm pool to take a pre-created agent from. Config fields that
+	// are set overlay the pool's configuration; the pool is bypassed when they
+	// change the provider, model, MCP servers or tools, or session_id is set.
+	WarmPool      string `protobuf:"bytes,3,opt,name=warm_pool,json=warmPool,proto3" json:"warm_pool,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -77,6 +81,13 @@ func (x *CreateAgentRequest) GetConfig() *AgentConfig {
 	return nil
 }
 
+func (x *CreateAgentRequest) GetWarmPool() string {
+	if x != nil {
+		return x.WarmPool
+	}
+	return ""
+}
+
 type AgentConfig struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
 	// LLM provider: bedrock, openai, anthropic, openrouter, vertex
@@ -102,9 +113,11 @@ type AgentConfig struct {
 	// Enable streaming responses
 	EnableStreaming bool `protobuf:"varint,11,opt,name=enable_streaming,json=enableStreaming,proto3" json:"enable_streaming,omitempty"`
 	// Custom tools with handlers on client side
-	CustomTools   []*CustomToolDefinition `protobuf:"bytes,12,rep,name=custom_tools,json=customTools,proto3" json:"custom_tools,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	CustomTools []*CustomToolDefinition `protobuf:"bytes,12,rep,name=custom_tools,json=customTools,proto3" json:"custom_tools,omitempty"`
+	// Permissions for virtual and custom tools (e.g. disable delete, scope paths)
+	ToolPermissions []*ToolPermission `protobuf:"bytes,13,rep,name=tool_permissions,json=toolPermissions,proto3" json:"tool_permissions,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
 }
 
 func (x *AgentConfig) Reset() {
@@ -221,6 +234,77 @@ func (x *AgentConfig) GetCustomTools() []*CustomToolDefinition {
 	return nil
 }
 
+func (x *AgentConfig) GetToolPermissions() []*ToolPermission {
+	if x != nil {
+		return x.ToolPermissions
+	}
+	return nil
+}
+
+// ToolPermission restricts one virtual or custom tool
+type ToolPermission struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Tool name (e.g., search_large_output, git_commit)
+	Tool string `protobuf:"bytes,1,opt,name=tool,proto3" json:"tool,omitempty"`
+	// Hide the tool from the LLM and reject calls to it
+	Disabled bool `protobuf:"varint,2,opt,name=disabled,proto3" json:"disabled,omitempty"`
+	// Folders the tool's path arguments must stay within (empty = unrestricted)
+	Paths         []string `protobuf:"bytes,3,rep,name=paths,proto3" json:"paths,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ToolPermission) Reset() {
+	*x = ToolPermission{}
+	mi := &file_agent_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ToolPermission) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ToolPermission) ProtoMessage() {}
+
+func (x *ToolPermission) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ToolPermission.ProtoReflect.Descriptor instead.
+func (*ToolPermission) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ToolPermission) GetTool() string {
+	if x != nil {
+		return x.Tool
+	}
+	return ""
+}
+
+func (x *ToolPermission) GetDisabled() bool {
+	if x != nil {
+		return x.Disabled
+	}
+	return false
+}
+
+func (x *ToolPermission) GetPaths() []string {
+	if x != nil {
+		return x.Paths
+	}
+	return nil
+}
+
 type CustomToolDefinition struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
 	// Unique tool name
@@ -239,7 +323,7 @@ type CustomToolDefinition struct {
 
 func (x *CustomToolDefinition) Reset() {
 	*x = CustomToolDefinition{}
-	mi := &file_agent_proto_msgTypes[2]
+	mi := &file_agent_proto_msgTypes[3]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -251,7 +335,7 @@ func (x *CustomToolDefinition) String() string {
 func (*CustomToolDefinition) ProtoMessage() {}
 
 func (x *CustomToolDefinition) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_proto_msgTypes[2]
+	mi := &file_agent_proto_msgTypes[3]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -264,7 +348,7 @@ func (x *CustomToolDefinition) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CustomToolDefinition.ProtoReflect.Descriptor instead.
 func (*CustomToolDefinition) Descriptor() ([]byte, []int) {
-	return file_agent_proto_rawDescGZIP(), []int{2}
+	return file_agent_proto_rawDescGZIP(), []int{3}
 }
 
 func (x *CustomToolDefinition) GetName() string {
@@ -302,6 +386,78 @@ func (x *CustomToolDefinition) GetCategory() string {
 	return ""
 }
 
+type CreateAgentFromPresetRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Optional session ID (auto-generated if empty)
+	SessionId string `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	// Preset name
+	Preset string `protobuf:"bytes,2,opt,name=preset,proto3" json:"preset,omitempty"`
+	// Values for {{NAME}} placeholders in the preset's system prompt template
+	Variables map[string]string `protobuf:"bytes,3,rep,name=variables,proto3" json:"variables,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// Optional overrides; set fields take precedence over the preset
+	Config        *AgentConfig `protobuf:"bytes,4,opt,name=config,proto3" json:"config,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateAgentFromPresetRequest) Reset() {
+	*x = CreateAgentFromPresetRequest{}
+	mi := &file_agent_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateAgentFromPresetRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateAgentFromPresetRequest) ProtoMessage() {}
+
+func (x *CreateAgentFromPresetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateAgentFromPresetRequest.ProtoReflect.Descriptor instead.
+func (*CreateAgentFromPresetRequest) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *CreateAgentFromPresetRequest) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *CreateAgentFromPresetRequest) GetPreset() string {
+	if x != nil {
+		return x.Preset
+	}
+	return ""
+}
+
+func (x *CreateAgentFromPresetRequest) GetVariables() map[string]string {
+	if x != nil {
+		return x.Variables
+	}
+	return nil
+}
+
+func (x *CreateAgentFromPresetRequest) GetConfig() *AgentConfig {
+	if x != nil {
+		return x.Config
+	}
+	return nil
+}
+
 type CreateAgentResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
@@ -315,7 +471,7 @@ type CreateAgentResponse struct {
 
 func (x *CreateAgentResponse) Reset() {
 	*x = CreateAgentResponse{}
-	mi := &file_agent_proto_msgTypes[3]
+	mi := &file_agent_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -327,7 +483,7 @@ func (x *CreateAgentResponse) String() string {
 func (*CreateAgentResponse) ProtoMessage() {}
 
 func (x *CreateAgentResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_proto_msgTypes[3]
+	mi := &file_agent_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -340,7 +496,7 @@ func (x *CreateAgentResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CreateAgentResponse.ProtoReflect.Descriptor instead.
 func (*CreateAgentResponse) Descriptor() ([]byte, []int) {
-	return file_agent_proto_rawDescGZIP(), []int{3}
+	return file_agent_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *CreateAgentResponse) GetAgentId() string {
@@ -390,7 +546,7 @@ type Capabilities struct {
 
 func (x *Capabilities) Reset() {
 	*x = Capabilities{}
-	mi := &file_agent_proto_msgTypes[4]
+	mi := &file_agent_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -402,7 +558,7 @@ func (x *Capabilities) String() string {
 func (*Capabilities) ProtoMessage() {}
 
 func (x *Capabilities) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_proto_msgTypes[4]
+	mi := &file_agent_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -415,7 +571,7 @@ func (x *Capabilities) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Capabilities.ProtoReflect.Descriptor instead.
 func (*Capabilities) Descriptor() ([]byte, []int) {
-	return file_agent_proto_rawDescGZIP(), []int{4}
+	return file_agent_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *Capabilities) GetTools() []string {
@@ -441,7 +597,7 @@ type GetAgentRequest struct {
 
 func (x *GetAgentRequest) Reset() {
 	*x = GetAgentRequest{}
-	mi := &file_agent_proto_msgTypes[5]
+	mi := &file_agent_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -453,7 +609,7 @@ func (x *GetAgentRequest) String() string {
 func (*GetAgentRequest) ProtoMessage() {}
 
 func (x *GetAgentRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_proto_msgTypes[5]
+	mi := &file_agent_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -466,7 +622,7 @@ func (x *GetAgentRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetAgentRequest.ProtoReflect.Descriptor instead.
 func (*GetAgentRequest) Descriptor() ([]byte, []int) {
-	return file_agent_proto_rawDescGZIP(), []int{5}
+	return file_agent_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *GetAgentRequest) GetAgentId() string {
@@ -477,20 +633,26 @@ func (x *GetAgentRequest) GetAgentId() string {
 }
 
 type GetAgentResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
-	SessionId     string                 `protobuf:"bytes,2,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
-	Status        string                 `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"`
-	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
-	Capabilities  *Capabilities          `protobuf:"bytes,5,opt,name=capabilities,proto3" json:"capabilities,omitempty"`
-	TokenUsage    *TokenUsage            `protobuf:"bytes,6,opt,name=token_usage,json=tokenUsage,proto3" json:"token_usage,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	AgentId      string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	SessionId    string                 `protobuf:"bytes,2,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	Status       string                 `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"`
+	CreatedAt    *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	Capabilities *Capabilities          `protobuf:"bytes,5,opt,name=capabilities,proto3" json:"capabilities,omitempty"`
+	TokenUsage   *TokenUsage            `protobuf:"bytes,6,opt,name=token_usage,json=tokenUsage,proto3" json:"token_usage,omitempty"`
+	// Tools currently available to the agent (same as ListTools)
+	Tools []*ToolInfo `protobuf:"bytes,7,rep,name=tools,proto3" json:"tools,omitempty"`
+	// Tool filters the agent was created with
+	Filters *AgentFilters `protobuf:"bytes,8,opt,name=filters,proto3" json:"filters,omitempty"`
+	// Whether the agent runs in code execution mode
+	CodeExecutionMode bool `protobuf:"varint,9,opt,name=code_execution_mode,json=codeExecutionMode,proto3" json:"code_execution_mode,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
 }
 
 func (x *GetAgentResponse) Reset() {
 	*x = GetAgentResponse{}
-	mi := &file_agent_proto_msgTypes[6]
+	mi := &file_agent_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -502,7 +664,7 @@ func (x *GetAgentResponse) String() string {
 func (*GetAgentResponse) ProtoMessage() {}
 
 func (x *GetAgentResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_proto_msgTypes[6]
+	mi := &file_agent_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -515,7 +677,7 @@ func (x *GetAgentResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetAgentResponse.ProtoReflect.Descriptor instead.
 func (*GetAgentResponse) Descriptor() ([]byte, []int) {
-	return file_agent_proto_rawDescGZIP(), []int{6}
+	return file_agent_proto_rawDescGZIP(), []int{8}
 }
 
 func (x *GetAgentResponse) GetAgentId() string {
@@ -560,6 +722,82 @@ func (x *GetAgentResponse) GetTokenUsage() *TokenUsage {
 	return nil
 }
 
+func (x *GetAgentResponse) GetTools() []*ToolInfo {
+	if x != nil {
+		return x.Tools
+	}
+	return nil
+}
+
+func (x *GetAgentResponse) GetFilters() *AgentFilters {
+	if x != nil {
+		return x.Filters
+	}
+	return nil
+}
+
+func (x *GetAgentResponse) GetCodeExecutionMode() bool {
+	if x != nil {
+		return x.CodeExecutionMode
+	}
+	return false
+}
+
+// AgentFilters are the tool filters applied to an agent
+type AgentFilters struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Servers the agent's tools are limited to (empty = all)
+	SelectedServers []string `protobuf:"bytes,1,rep,name=selected_servers,json=selectedServers,proto3" json:"selected_servers,omitempty"`
+	// Tools the agent is limited to (format: "server:tool", empty = all)
+	SelectedTools []string `protobuf:"bytes,2,rep,name=selected_tools,json=selectedTools,proto3" json:"selected_tools,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AgentFilters) Reset() {
+	*x = AgentFilters{}
+	mi := &file_agent_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AgentFilters) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AgentFilters) ProtoMessage() {}
+
+func (x *AgentFilters) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AgentFilters.ProtoReflect.Descriptor instead.
+func (*AgentFilters) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *AgentFilters) GetSelectedServers() []string {
+	if x != nil {
+		return x.SelectedServers
+	}
+	return nil
+}
+
+func (x *AgentFilters) GetSelectedTools() []string {
+	if x != nil {
+		return x.SelectedTools
+	}
+	return nil
+}
+
 type ListAgentsRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	unknownFields protoimpl.UnknownFields
@@ -568,7 +806,7 @@ type ListAgentsRequest struct {
 
 func (x *ListAgentsRequest) Reset() {
 	*x = ListAgentsRequest{}
-	mi := &file_agent_proto_msgTypes[7]
+	mi := &file_agent_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -580,7 +818,7 @@ func (x *ListAgentsRequest) String() string {
 func (*ListAgentsRequest) ProtoMessage() {}
 
 func (x *ListAgentsRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_proto_msgTypes[7]
+	mi := &file_agent_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -593,7 +831,7 @@ func (x *ListAgentsRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ListAgentsRequest.ProtoReflect.Descriptor instead.
 func (*ListAgentsRequest) Descriptor() ([]byte, []int) {
-	return file_agent_proto_rawDescGZIP(), []int{7}
+	return file_agent_proto_rawDescGZIP(), []int{10}
 }
 
 type ListAgentsResponse struct {
@@ -605,7 +843,7 @@ type ListAgentsResponse struct {
 
 func (x *ListAgentsResponse) Reset() {
 	*x = ListAgentsResponse{}
-	mi := &file_agent_proto_msgTypes[8]
+	mi := &file_agent_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -617,7 +855,7 @@ func (x *ListAgentsResponse) String() string {
 func (*ListAgentsResponse) ProtoMessage() {}
 
 func (x *ListAgentsResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_proto_msgTypes[8]
+	mi := &file_agent_proto_msgTypes[11]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -630,7 +868,7 @@ func (x *ListAgentsResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ListAgentsResponse.ProtoReflect.Descriptor instead.
 func (*ListAgentsResponse) Descriptor() ([]byte, []int) {
-	return file_agent_proto_rawDescGZIP(), []int{8}
+	return file_agent_proto_rawDescGZIP(), []int{11}
 }
 
 func (x *ListAgentsResponse) GetAgents() []*AgentSummary {
@@ -641,18 +879,22 @@ func (x *ListAgentsResponse) GetAgents() []*AgentSummary {
 }
 
 type AgentSummary struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
-	SessionId     string                 `protobuf:"bytes,2,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
-	Status        string                 `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"`
-	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	AgentId           string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	SessionId         string                 `protobuf:"bytes,2,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	Status            string                 `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"`
+	CreatedAt         *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	TokenUsage        *TokenUsage            `protobuf:"bytes,5,opt,name=token_usage,json=tokenUsage,proto3" json:"token_usage,omitempty"`
+	Tools             []*ToolInfo            `protobuf:"bytes,6,rep,name=tools,proto3" json:"tools,omitempty"`
+	Filters           *AgentFilters          `protobuf:"bytes,7,opt,name=filters,proto3" json:"filters,omitempty"`
+	CodeExecutionMode bool                   `protobuf:"varint,8,opt,name=code_execution_mode,json=codeExecutionMode,proto3" json:"code_execution_mode,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
 }
 
 func (x *AgentSummary) Reset() {
 	*x = AgentSummary{}
-	mi := &file_agent_proto_msgTypes[9]
+	mi := &file_agent_proto_msgTypes[12]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -664,7 +906,7 @@ func (x *AgentSummary) String() string {
 func (*AgentSummary) ProtoMessage() {}
 
 func (x *AgentSummary) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_proto_msgTypes[9]
+	mi := &file_agent_proto_msgTypes[12]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -677,7 +919,7 @@ func (x *AgentSummary) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use AgentSummary.ProtoReflect.Descriptor instead.
 func (*AgentSummary) Descriptor() ([]byte, []int) {
-	return file_agent_proto_rawDescGZIP(), []int{9}
+	return file_agent_proto_rawDescGZIP(), []int{12}
 }
 
 func (x *AgentSummary) GetAgentId() string {
@@ -708,6 +950,34 @@ func (x *AgentSummary) GetCreatedAt() *timestamppb.Timestamp {
 	return nil
 }
 
+func (x *AgentSummary) GetTokenUsage() *TokenUsage {
+	if x != nil {
+		return x.TokenUsage
+	}
+	return nil
+}
+
+func (x *AgentSummary) GetTools() []*ToolInfo {
+	if x != nil {
+		return x.Tools
+	}
+	return nil
+}
+
+func (x *AgentSummary) GetFilters() *AgentFilters {
+	if x != nil {
+		return x.Filters
+	}
+	return nil
+}
+
+func (x *AgentSummary) GetCodeExecutionMode() bool {
+	if x != nil {
+		return x.CodeExecutionMode
+	}
+	return false
+}
+
 type DestroyAgentRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
@@ -717,7 +987,7 @@ type DestroyAgentRequest struct {
 
 func (x *DestroyAgentRequest) Reset() {
 	*x = DestroyAgentRequest{}
-	mi := &file_agent_proto_msgTypes[10]
+	mi := &file_agent_proto_msgTypes[13]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -729,7 +999,7 @@ func (x *DestroyAgentRequest) String() string {
 func (*DestroyAgentRequest) ProtoMessage() {}
 
 func (x *DestroyAgentRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_proto_msgTypes[10]
+	mi := &file_agent_proto_msgTypes[13]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -742,7 +1012,7 @@ func (x *DestroyAgentRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DestroyAgentRequest.ProtoReflect.Descriptor instead.
 func (*DestroyAgentRequest) Descriptor() ([]byte, []int) {
-	return file_agent_proto_rawDescGZIP(), []int{10}
+	return file_agent_proto_rawDescGZIP(), []int{13}
 }
 
 func (x *DestroyAgentRequest) GetAgentId() string {
@@ -762,7 +1032,7 @@ type DestroyAgentResponse struct {
 
 func (x *DestroyAgentResponse) Reset() {
 	*x = DestroyAgentResponse{}
-	mi := &file_agent_proto_msgTypes[11]
+	mi := &file_agent_proto_msgTypes[14]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -774,7 +1044,7 @@ func (x *DestroyAgentResponse) String() string {
 func (*DestroyAgentResponse) ProtoMessage() {}
 
 func (x *DestroyAgentResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_proto_msgTypes[11]
+	mi := &file_agent_proto_msgTypes[14]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -787,7 +1057,7 @@ func (x *DestroyAgentResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DestroyAgentResponse.ProtoReflect.Descriptor instead.
 func (*DestroyAgentResponse) Descriptor() ([]byte, []int) {
-	return file_agent_proto_rawDescGZIP(), []int{11}
+	return file_agent_proto_rawDescGZIP(), []int{14}
 }
 
 func (x *DestroyAgentResponse) GetAgentId() string {
@@ -813,7 +1083,7 @@ type GetTokenUsageRequest struct {
 
 func (x *GetTokenUsageRequest) Reset() {
 	*x = GetTokenUsageRequest{}
-	mi := &file_agent_proto_msgTypes[12]
+	mi := &file_agent_proto_msgTypes[15]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -825,7 +1095,7 @@ func (x *GetTokenUsageRequest) String() string {
 func (*GetTokenUsageRequest) ProtoMessage() {}
 
 func (x *GetTokenUsageRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_proto_msgTypes[12]
+	mi := &file_agent_proto_msgTypes[15]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -838,7 +1108,7 @@ func (x *GetTokenUsageRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetTokenUsageRequest.ProtoReflect.Descriptor instead.
 func (*GetTokenUsageRequest) Descriptor() ([]byte, []int) {
-	return file_agent_proto_rawDescGZIP(), []int{12}
+	return file_agent_proto_rawDescGZIP(), []int{15}
 }
 
 func (x *GetTokenUsageRequest) GetAgentId() string {
@@ -862,7 +1132,7 @@ type TokenUsage struct {
 
 func (x *TokenUsage) Reset() {
 	*x = TokenUsage{}
-	mi := &file_agent_proto_msgTypes[13]
+	mi := &file_agent_proto_msgTypes[16]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -874,7 +1144,7 @@ func (x *TokenUsage) String() string {
 func (*TokenUsage) ProtoMessage() {}
 
 func (x *TokenUsage) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_proto_msgTypes[13]
+	mi := &file_agent_proto_msgTypes[16]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -887,7 +1157,7 @@ func (x *TokenUsage) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use TokenUsage.ProtoReflect.Descriptor instead.
 func (*TokenUsage) Descriptor() ([]byte, []int) {
-	return file_agent_proto_rawDescGZIP(), []int{13}
+	return file_agent_proto_rawDescGZIP(), []int{16}
 }
 
 func (x *TokenUsage) GetPromptTokens() int32 {
@@ -945,7 +1215,7 @@ type Costs struct {
 
 func (x *Costs) Reset() {
 	*x = Costs{}
-	mi := &file_agent_proto_msgTypes[14]
+	mi := &file_agent_proto_msgTypes[17]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -957,7 +1227,7 @@ func (x *Costs) String() string {
 func (*Costs) ProtoMessage() {}
 
 func (x *Costs) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_proto_msgTypes[14]
+	mi := &file_agent_proto_msgTypes[17]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -970,7 +1240,7 @@ func (x *Costs) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Costs.ProtoReflect.Descriptor instead.
 func (*Costs) Descriptor() ([]byte, []int) {
-	return file_agent_proto_rawDescGZIP(), []int{14}
+	return file_agent_proto_rawDescGZIP(), []int{17}
 }
 
 func (x *Costs) GetInputCost() float64 {
@@ -1018,7 +1288,7 @@ type TokenUsageResponse struct {
 
 func (x *TokenUsageResponse) Reset() {
 	*x = TokenUsageResponse{}
-	mi := &file_agent_proto_msgTypes[15]
+	mi := &file_agent_proto_msgTypes[18]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1030,7 +1300,7 @@ func (x *TokenUsageResponse) String() string {
 func (*TokenUsageResponse) ProtoMessage() {}
 
 func (x *TokenUsageResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_proto_msgTypes[15]
+	mi := &file_agent_proto_msgTypes[18]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1043,7 +1313,7 @@ func (x *TokenUsageResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use TokenUsageResponse.ProtoReflect.Descriptor instead.
 func (*TokenUsageResponse) Descriptor() ([]byte, []int) {
-	return file_agent_proto_rawDescGZIP(), []int{15}
+	return file_agent_proto_rawDescGZIP(), []int{18}
 }
 
 func (x *TokenUsageResponse) GetTokenUsage() *TokenUsage {
@@ -1060,35 +1330,28 @@ func (x *TokenUsageResponse) GetCosts() *Costs {
 	return nil
 }
 
-type ConversationRequest struct {
-	state protoimpl.MessageState `protogen:"open.v1"`
-	// Agent ID for the conversation
-	AgentId string `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
-	// Types that are valid to be assigned to Payload:
-	//
-	//	*ConversationRequest_Question
-	//	*ConversationRequest_ToolResult
-	//	*ConversationRequest_Cancel
-	Payload       isConversationRequest_Payload `protobuf_oneof:"payload"`
+type GetPostMortemBundleRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ConversationRequest) Reset() {
-	*x = ConversationRequest{}
-	mi := &file_agent_proto_msgTypes[16]
+func (x *GetPostMortemBundleRequest) Reset() {
+	*x = GetPostMortemBundleRequest{}
+	mi := &file_agent_proto_msgTypes[19]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ConversationRequest) String() string {
+func (x *GetPostMortemBundleRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ConversationRequest) ProtoMessage() {}
+func (*GetPostMortemBundleRequest) ProtoMessage() {}
 
-func (x *ConversationRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_proto_msgTypes[16]
+func (x *GetPostMortemBundleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[19]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1099,102 +1362,112 @@ func (x *ConversationRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ConversationRequest.ProtoReflect.Descriptor instead.
-func (*ConversationRequest) Descriptor() ([]byte, []int) {
-	return file_agent_proto_rawDescGZIP(), []int{16}
+// Deprecated: Use GetPostMortemBundleRequest.ProtoReflect.Descriptor instead.
+func (*GetPostMortemBundleRequest) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{19}
 }
 
-func (x *ConversationRequest) GetAgentId() string {
+func (x *GetPostMortemBundleRequest) GetAgentId() string {
 	if x != nil {
 		return x.AgentId
 	}
 	return ""
 }
 
-func (x *ConversationRequest) GetPayload() isConversationRequest_Payload {
-	if x != nil {
-		return x.Payload
-	}
-	return nil
+type GetPostMortemBundleResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Zip archive with error.json, events.jsonl, messages.json, config.json
+	// and environment.json; secrets are redacted
+	Bundle []byte `protobuf:"bytes,1,opt,name=bundle,proto3" json:"bundle,omitempty"`
+	// Path of the bundle on the server ("" when not written to disk)
+	Path string `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
+	// The (redacted) error the conversation ended with
+	Error         string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ConversationRequest) GetQuestion() *QuestionMessage {
-	if x != nil {
-		if x, ok := x.Payload.(*ConversationRequest_Question); ok {
-			return x.Question
-		}
-	}
-	return nil
+func (x *GetPostMortemBundleResponse) Reset() {
+	*x = GetPostMortemBundleResponse{}
+	mi := &file_agent_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-func (x *ConversationRequest) GetToolResult() *ToolResultMessage {
-	if x != nil {
-		if x, ok := x.Payload.(*ConversationRequest_ToolResult); ok {
-			return x.ToolResult
-		}
-	}
-	return nil
+func (x *GetPostMortemBundleResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
 
-func (x *ConversationRequest) GetCancel() *CancelMessage {
+func (*GetPostMortemBundleResponse) ProtoMessage() {}
+
+func (x *GetPostMortemBundleResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[20]
 	if x != nil {
-		if x, ok := x.Payload.(*ConversationRequest_Cancel); ok {
-			return x.Cancel
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
 		}
+		return ms
 	}
-	return nil
+	return mi.MessageOf(x)
 }
 
-type isConversationRequest_Payload interface {
-	isConversationRequest_Payload()
+// Deprecated: Use GetPostMortemBundleResponse.ProtoReflect.Descriptor instead.
+func (*GetPostMortemBundleResponse) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{20}
 }
 
-type ConversationRequest_Question struct {
-	// Client asks a question (optionally with history)
-	Question *QuestionMessage `protobuf:"bytes,2,opt,name=question,proto3,oneof"`
+func (x *GetPostMortemBundleResponse) GetBundle() []byte {
+	if x != nil {
+		return x.Bundle
+	}
+	return nil
 }
 
-type ConversationRequest_ToolResult struct {
-	// Client returns result of a tool call
-	ToolResult *ToolResultMessage `protobuf:"bytes,3,opt,name=tool_result,json=toolResult,proto3,oneof"`
+func (x *GetPostMortemBundleResponse) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
 }
 
-type ConversationRequest_Cancel struct {
-	// Client requests cancellation
-	Cancel *CancelMessage `protobuf:"bytes,4,opt,name=cancel,proto3,oneof"`
+func (x *GetPostMortemBundleResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
 }
 
-func (*ConversationRequest_Question) isConversationRequest_Payload() {}
-
-func (*ConversationRequest_ToolResult) isConversationRequest_Payload() {}
-
-func (*ConversationRequest_Cancel) isConversationRequest_Payload() {}
+func (x *GetPostMortemBundleResponse) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
 
-type QuestionMessage struct {
-	state protoimpl.MessageState `protogen:"open.v1"`
-	// The question/prompt text
-	Text string `protobuf:"bytes,1,opt,name=text,proto3" json:"text,omitempty"`
-	// Optional conversation history for multi-turn
-	History       []*Message `protobuf:"bytes,2,rep,name=history,proto3" json:"history,omitempty"`
+type GetToolStatsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *QuestionMessage) Reset() {
-	*x = QuestionMessage{}
-	mi := &file_agent_proto_msgTypes[17]
+func (x *GetToolStatsRequest) Reset() {
+	*x = GetToolStatsRequest{}
+	mi := &file_agent_proto_msgTypes[21]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *QuestionMessage) String() string {
+func (x *GetToolStatsRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*QuestionMessage) ProtoMessage() {}
+func (*GetToolStatsRequest) ProtoMessage() {}
 
-func (x *QuestionMessage) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_proto_msgTypes[17]
+func (x *GetToolStatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[21]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1205,56 +1478,50 @@ func (x *QuestionMessage) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use QuestionMessage.ProtoReflect.Descriptor instead.
-func (*QuestionMessage) Descriptor() ([]byte, []int) {
-	return file_agent_proto_rawDescGZIP(), []int{17}
+// Deprecated: Use GetToolStatsRequest.ProtoReflect.Descriptor instead.
+func (*GetToolStatsRequest) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{21}
 }
 
-func (x *QuestionMessage) GetText() string {
+func (x *GetToolStatsRequest) GetAgentId() string {
 	if x != nil {
-		return x.Text
+		return x.AgentId
 	}
 	return ""
 }
 
-func (x *QuestionMessage) GetHistory() []*Message {
-	if x != nil {
-		return x.History
-	}
-	return nil
-}
-
-type ToolResultMessage struct {
-	state protoimpl.MessageState `protogen:"open.v1"`
-	// Call ID from ToolCallEvent
-	CallId string `protobuf:"bytes,1,opt,name=call_id,json=callId,proto3" json:"call_id,omitempty"`
-	// Whether tool execution succeeded
-	Success bool `protobuf:"varint,2,opt,name=success,proto3" json:"success,omitempty"`
-	// Tool output (if success)
-	Result string `protobuf:"bytes,3,opt,name=result,proto3" json:"result,omitempty"`
-	// Error details (if not success)
-	Error *ToolError `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
-	// Execution duration in milliseconds
-	DurationMs    int64 `protobuf:"varint,5,opt,name=duration_ms,json=durationMs,proto3" json:"duration_ms,omitempty"`
+// ToolCallStats are the usage statistics of a tool or a server
+type ToolCallStats struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Calls  int32                  `protobuf:"varint,1,opt,name=calls,proto3" json:"calls,omitempty"`
+	Errors int32                  `protobuf:"varint,2,opt,name=errors,proto3" json:"errors,omitempty"`
+	// errors / calls
+	ErrorRate float64 `protobuf:"fixed64,3,opt,name=error_rate,json=errorRate,proto3" json:"error_rate,omitempty"`
+	// Calls answered from the tool result cache
+	CacheHits     int32 `protobuf:"varint,4,opt,name=cache_hits,json=cacheHits,proto3" json:"cache_hits,omitempty"`
+	P50LatencyMs  int64 `protobuf:"varint,5,opt,name=p50_latency_ms,json=p50LatencyMs,proto3" json:"p50_latency_ms,omitempty"`
+	P95LatencyMs  int64 `protobuf:"varint,6,opt,name=p95_latency_ms,json=p95LatencyMs,proto3" json:"p95_latency_ms,omitempty"`
+	MaxLatencyMs  int64 `protobuf:"varint,7,opt,name=max_latency_ms,json=maxLatencyMs,proto3" json:"max_latency_ms,omitempty"`
+	BytesReturned int64 `protobuf:"varint,8,opt,name=bytes_returned,json=bytesReturned,proto3" json:"bytes_returned,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ToolResultMessage) Reset() {
-	*x = ToolResultMessage{}
-	mi := &file_agent_proto_msgTypes[18]
+func (x *ToolCallStats) Reset() {
+	*x = ToolCallStats{}
+	mi := &file_agent_proto_msgTypes[22]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ToolResultMessage) String() string {
+func (x *ToolCallStats) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ToolResultMessage) ProtoMessage() {}
+func (*ToolCallStats) ProtoMessage() {}
 
-func (x *ToolResultMessage) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_proto_msgTypes[18]
+func (x *ToolCallStats) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[22]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1265,70 +1532,92 @@ func (x *ToolResultMessage) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ToolResultMessage.ProtoReflect.Descriptor instead.
-func (*ToolResultMessage) Descriptor() ([]byte, []int) {
-	return file_agent_proto_rawDescGZIP(), []int{18}
+// Deprecated: Use ToolCallStats.ProtoReflect.Descriptor instead.
+func (*ToolCallStats) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{22}
 }
 
-func (x *ToolResultMessage) GetCallId() string {
+func (x *ToolCallStats) GetCalls() int32 {
 	if x != nil {
-		return x.CallId
+		return x.Calls
 	}
-	return ""
+	return 0
 }
 
-func (x *ToolResultMessage) GetSuccess() bool {
+func (x *ToolCallStats) GetErrors() int32 {
 	if x != nil {
-		return x.Success
+		return x.Errors
 	}
-	return false
+	return 0
 }
 
-func (x *ToolResultMessage) GetResult() string {
+func (x *ToolCallStats) GetErrorRate() float64 {
 	if x != nil {
-		return x.Result
+		return x.ErrorRate
 	}
-	return ""
+	return 0
 }
 
-func (x *ToolResultMessage) GetError() *ToolError {
+func (x *ToolCallStats) GetCacheHits() int32 {
 	if x != nil {
-		return x.Error
+		return x.CacheHits
 	}
-	return nil
+	return 0
 }
 
-func (x *ToolResultMessage) GetDurationMs() int64 {
+func (x *ToolCallStats) GetP50LatencyMs() int64 {
 	if x != nil {
-		return x.DurationMs
+		return x.P50LatencyMs
 	}
 	return 0
 }
 
-type ToolError struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Code          string                 `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
-	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
-	Details       *structpb.Struct       `protobuf:"bytes,3,opt,name=details,proto3" json:"details,omitempty"`
+func (x *ToolCallStats) GetP95LatencyMs() int64 {
+	if x != nil {
+		return x.P95LatencyMs
+	}
+	return 0
+}
+
+func (x *ToolCallStats) GetMaxLatencyMs() int64 {
+	if x != nil {
+		return x.MaxLatencyMs
+	}
+	return 0
+}
+
+func (x *ToolCallStats) GetBytesReturned() int64 {
+	if x != nil {
+		return x.BytesReturned
+	}
+	return 0
+}
+
+type ToolStats struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	ToolName string                 `protobuf:"bytes,1,opt,name=tool_name,json=toolName,proto3" json:"tool_name,omitempty"`
+	// MCP server; empty for virtual and custom tools
+	ServerName    string         `protobuf:"bytes,2,opt,name=server_name,json=serverName,proto3" json:"server_name,omitempty"`
+	Stats         *ToolCallStats `protobuf:"bytes,3,opt,name=stats,proto3" json:"stats,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ToolError) Reset() {
-	*x = ToolError{}
-	mi := &file_agent_proto_msgTypes[19]
+func (x *ToolStats) Reset() {
+	*x = ToolStats{}
+	mi := &file_agent_proto_msgTypes[23]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ToolError) String() string {
+func (x *ToolStats) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ToolError) ProtoMessage() {}
+func (*ToolStats) ProtoMessage() {}
 
-func (x *ToolError) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_proto_msgTypes[19]
+func (x *ToolStats) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[23]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1339,54 +1628,55 @@ func (x *ToolError) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ToolError.ProtoReflect.Descriptor instead.
-func (*ToolError) Descriptor() ([]byte, []int) {
-	return file_agent_proto_rawDescGZIP(), []int{19}
+// Deprecated: Use ToolStats.ProtoReflect.Descriptor instead.
+func (*ToolStats) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{23}
 }
 
-func (x *ToolError) GetCode() string {
+func (x *ToolStats) GetToolName() string {
 	if x != nil {
-		return x.Code
+		return x.ToolName
 	}
 	return ""
 }
 
-func (x *ToolError) GetMessage() string {
+func (x *ToolStats) GetServerName() string {
 	if x != nil {
-		return x.Message
+		return x.ServerName
 	}
 	return ""
 }
 
-func (x *ToolError) GetDetails() *structpb.Struct {
+func (x *ToolStats) GetStats() *ToolCallStats {
 	if x != nil {
-		return x.Details
+		return x.Stats
 	}
 	return nil
 }
 
-type CancelMessage struct {
+type ServerToolStats struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Reason        string                 `protobuf:"bytes,1,opt,name=reason,proto3" json:"reason,omitempty"`
+	ServerName    string                 `protobuf:"bytes,1,opt,name=server_name,json=serverName,proto3" json:"server_name,omitempty"`
+	Stats         *ToolCallStats         `protobuf:"bytes,2,opt,name=stats,proto3" json:"stats,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *CancelMessage) Reset() {
-	*x = CancelMessage{}
-	mi := &file_agent_proto_msgTypes[20]
+func (x *ServerToolStats) Reset() {
+	*x = ServerToolStats{}
+	mi := &file_agent_proto_msgTypes[24]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *CancelMessage) String() string {
+func (x *ServerToolStats) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*CancelMessage) ProtoMessage() {}
+func (*ServerToolStats) ProtoMessage() {}
 
-func (x *CancelMessage) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_proto_msgTypes[20]
+func (x *ServerToolStats) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[24]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1397,47 +1687,50 @@ func (x *CancelMessage) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use CancelMessage.ProtoReflect.Descriptor instead.
-func (*CancelMessage) Descriptor() ([]byte, []int) {
-	return file_agent_proto_rawDescGZIP(), []int{20}
+// Deprecated: Use ServerToolStats.ProtoReflect.Descriptor instead.
+func (*ServerToolStats) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{24}
 }
 
-func (x *CancelMessage) GetReason() string {
+func (x *ServerToolStats) GetServerName() string {
 	if x != nil {
-		return x.Reason
+		return x.ServerName
 	}
 	return ""
 }
 
-type ConversationResponse struct {
+func (x *ServerToolStats) GetStats() *ToolCallStats {
+	if x != nil {
+		return x.Stats
+	}
+	return nil
+}
+
+type GetToolStatsResponse struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
-	// Types that are valid to be assigned to Payload:
-	//
-	//	*ConversationResponse_TextChunk
-	//	*ConversationResponse_ToolCall
-	//	*ConversationResponse_AgentEvent
-	//	*ConversationResponse_FinalResponse
-	//	*ConversationResponse_Error
-	Payload       isConversationResponse_Payload `protobuf_oneof:"payload"`
+	// Sorted by server, then tool name
+	Tools []*ToolStats `protobuf:"bytes,1,rep,name=tools,proto3" json:"tools,omitempty"`
+	// Sorted by name
+	Servers       []*ServerToolStats `protobuf:"bytes,2,rep,name=servers,proto3" json:"servers,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ConversationResponse) Reset() {
-	*x = ConversationResponse{}
-	mi := &file_agent_proto_msgTypes[21]
+func (x *GetToolStatsResponse) Reset() {
+	*x = GetToolStatsResponse{}
+	mi := &file_agent_proto_msgTypes[25]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ConversationResponse) String() string {
+func (x *GetToolStatsResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ConversationResponse) ProtoMessage() {}
+func (*GetToolStatsResponse) ProtoMessage() {}
 
-func (x *ConversationResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_proto_msgTypes[21]
+func (x *GetToolStatsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[25]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1448,127 +1741,2517 @@ func (x *ConversationResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ConversationResponse.ProtoReflect.Descriptor instead.
-func (*ConversationResponse) Descriptor() ([]byte, []int) {
-	return file_agent_proto_rawDescGZIP(), []int{21}
+// Deprecated: Use GetToolStatsResponse.ProtoReflect.Descriptor instead.
+func (*GetToolStatsResponse) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{25}
 }
 
-func (x *ConversationResponse) GetPayload() isConversationResponse_Payload {
+func (x *GetToolStatsResponse) GetTools() []*ToolStats {
 	if x != nil {
-		return x.Payload
+		return x.Tools
 	}
 	return nil
 }
 
-func (x *ConversationResponse) GetTextChunk() *TextChunkEvent {
+func (x *GetToolStatsResponse) GetServers() []*ServerToolStats {
 	if x != nil {
-		if x, ok := x.Payload.(*ConversationResponse_TextChunk); ok {
-			return x.TextChunk
-		}
+		return x.Servers
 	}
 	return nil
 }
 
-func (x *ConversationResponse) GetToolCall() *ToolCallEvent {
+type ListToolsRequest struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	AgentId string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	// Only tools of this server (or custom tool category); empty = all tools
+	Server        string `protobuf:"bytes,2,opt,name=server,proto3" json:"server,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListToolsRequest) Reset() {
+	*x = ListToolsRequest{}
+	mi := &file_agent_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListToolsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListToolsRequest) ProtoMessage() {}
+
+func (x *ListToolsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[26]
 	if x != nil {
-		if x, ok := x.Payload.(*ConversationResponse_ToolCall); ok {
-			return x.ToolCall
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
 		}
+		return ms
 	}
-	return nil
+	return mi.MessageOf(x)
 }
 
-func (x *ConversationResponse) GetAgentEvent() *AgentEvent {
+// Deprecated: Use ListToolsRequest.ProtoReflect.Descriptor instead.
+func (*ListToolsRequest) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *ListToolsRequest) GetAgentId() string {
 	if x != nil {
-		if x, ok := x.Payload.(*ConversationResponse_AgentEvent); ok {
-			return x.AgentEvent
-		}
+		return x.AgentId
 	}
-	return nil
+	return ""
 }
 
-func (x *ConversationResponse) GetFinalResponse() *FinalResponse {
+func (x *ListToolsRequest) GetServer() string {
 	if x != nil {
-		if x, ok := x.Payload.(*ConversationResponse_FinalResponse); ok {
-			return x.FinalResponse
+		return x.Server
+	}
+	return ""
+}
+
+type ListToolsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Sorted by name
+	Tools         []*ToolInfo `protobuf:"bytes,1,rep,name=tools,proto3" json:"tools,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListToolsResponse) Reset() {
+	*x = ListToolsResponse{}
+	mi := &file_agent_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListToolsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListToolsResponse) ProtoMessage() {}
+
+func (x *ListToolsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
 		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListToolsResponse.ProtoReflect.Descriptor instead.
+func (*ListToolsResponse) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *ListToolsResponse) GetTools() []*ToolInfo {
+	if x != nil {
+		return x.Tools
 	}
 	return nil
 }
 
-func (x *ConversationResponse) GetError() *ErrorEvent {
+// ToolInfo describes a tool available to an agent
+type ToolInfo struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Name        string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Description string                 `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
+	// MCP server of an MCP tool, category of a custom tool, empty for virtual tools
+	Server string `protobuf:"bytes,3,opt,name=server,proto3" json:"server,omitempty"`
+	// "mcp", "custom" or "virtual"
+	Type          string `protobuf:"bytes,4,opt,name=type,proto3" json:"type,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ToolInfo) Reset() {
+	*x = ToolInfo{}
+	mi := &file_agent_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ToolInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ToolInfo) ProtoMessage() {}
+
+func (x *ToolInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[28]
 	if x != nil {
-		if x, ok := x.Payload.(*ConversationResponse_Error); ok {
-			return x.Error
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
 		}
+		return ms
 	}
-	return nil
+	return mi.MessageOf(x)
 }
 
-type isConversationResponse_Payload interface {
-	isConversationResponse_Payload()
+// Deprecated: Use ToolInfo.ProtoReflect.Descriptor instead.
+func (*ToolInfo) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{28}
 }
 
-type ConversationResponse_TextChunk struct {
-	// Streaming text chunk from LLM
-	TextChunk *TextChunkEvent `protobuf:"bytes,1,opt,name=text_chunk,json=textChunk,proto3,oneof"`
+func (x *ToolInfo) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
 }
 
-type ConversationResponse_ToolCall struct {
-	// Server requests tool execution from client
-	ToolCall *ToolCallEvent `protobuf:"bytes,2,opt,name=tool_call,json=toolCall,proto3,oneof"`
+func (x *ToolInfo) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
 }
 
-type ConversationResponse_AgentEvent struct {
-	// Agent event for observability
-	AgentEvent *AgentEvent `protobuf:"bytes,3,opt,name=agent_event,json=agentEvent,proto3,oneof"`
+func (x *ToolInfo) GetServer() string {
+	if x != nil {
+		return x.Server
+	}
+	return ""
 }
 
-type ConversationResponse_FinalResponse struct {
-	// Final response (conversation complete)
-	FinalResponse *FinalResponse `protobuf:"bytes,4,opt,name=final_response,json=finalResponse,proto3,oneof"`
+func (x *ToolInfo) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
 }
 
-type ConversationResponse_Error struct {
-	// Error event
-	Error *ErrorEvent `protobuf:"bytes,5,opt,name=error,proto3,oneof"`
+type GetToolSchemaRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	ToolName      string                 `protobuf:"bytes,2,opt,name=tool_name,json=toolName,proto3" json:"tool_name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (*ConversationResponse_TextChunk) isConversationResponse_Payload() {}
+func (x *GetToolSchemaRequest) Reset() {
+	*x = GetToolSchemaRequest{}
+	mi := &file_agent_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
 
-func (*ConversationResponse_ToolCall) isConversationResponse_Payload() {}
+func (x *GetToolSchemaRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
 
-func (*ConversationResponse_AgentEvent) isConversationResponse_Payload() {}
+func (*GetToolSchemaRequest) ProtoMessage() {}
 
-func (*ConversationResponse_FinalResponse) isConversationResponse_Payload() {}
+func (x *GetToolSchemaRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
 
-func (*ConversationResponse_Error) isConversationResponse_Payload() {}
+// Deprecated: Use GetToolSchemaRequest.ProtoReflect.Descriptor instead.
+func (*GetToolSchemaRequest) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{29}
+}
 
-type TextChunkEvent struct {
+func (x *GetToolSchemaRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *GetToolSchemaRequest) GetToolName() string {
+	if x != nil {
+		return x.ToolName
+	}
+	return ""
+}
+
+type GetToolSchemaResponse struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
-	// Text content chunk
-	Text string `protobuf:"bytes,1,opt,name=text,proto3" json:"text,omitempty"`
-	// Whether this is thinking/reasoning (for reasoning models)
-	IsThinking    bool `protobuf:"varint,2,opt,name=is_thinking,json=isThinking,proto3" json:"is_thinking,omitempty"`
+	Tool  *ToolInfo              `protobuf:"bytes,1,opt,name=tool,proto3" json:"tool,omitempty"`
+	// JSON schema of the tool's arguments
+	InputSchema   *structpb.Struct `protobuf:"bytes,2,opt,name=input_schema,json=inputSchema,proto3" json:"input_schema,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetToolSchemaResponse) Reset() {
+	*x = GetToolSchemaResponse{}
+	mi := &file_agent_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetToolSchemaResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetToolSchemaResponse) ProtoMessage() {}
+
+func (x *GetToolSchemaResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetToolSchemaResponse.ProtoReflect.Descriptor instead.
+func (*GetToolSchemaResponse) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *GetToolSchemaResponse) GetTool() *ToolInfo {
+	if x != nil {
+		return x.Tool
+	}
+	return nil
+}
+
+func (x *GetToolSchemaResponse) GetInputSchema() *structpb.Struct {
+	if x != nil {
+		return x.InputSchema
+	}
+	return nil
+}
+
+type ListServersRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListServersRequest) Reset() {
+	*x = ListServersRequest{}
+	mi := &file_agent_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListServersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListServersRequest) ProtoMessage() {}
+
+func (x *ListServersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListServersRequest.ProtoReflect.Descriptor instead.
+func (*ListServersRequest) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *ListServersRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+type ListServersResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Sorted by name
+	Servers       []*ServerInfo `protobuf:"bytes,1,rep,name=servers,proto3" json:"servers,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListServersResponse) Reset() {
+	*x = ListServersResponse{}
+	mi := &file_agent_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListServersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListServersResponse) ProtoMessage() {}
+
+func (x *ListServersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListServersResponse.ProtoReflect.Descriptor instead.
+func (*ListServersResponse) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *ListServersResponse) GetServers() []*ServerInfo {
+	if x != nil {
+		return x.Servers
+	}
+	return nil
+}
+
+// ServerInfo describes an MCP server an agent uses
+type ServerInfo struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Name        string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Description string                 `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
+	// False for servers connected lazily on their first tool call
+	Connected     bool  `protobuf:"varint,3,opt,name=connected,proto3" json:"connected,omitempty"`
+	ToolCount     int32 `protobuf:"varint,4,opt,name=tool_count,json=toolCount,proto3" json:"tool_count,omitempty"`
+	PromptCount   int32 `protobuf:"varint,5,opt,name=prompt_count,json=promptCount,proto3" json:"prompt_count,omitempty"`
+	ResourceCount int32 `protobuf:"varint,6,opt,name=resource_count,json=resourceCount,proto3" json:"resource_count,omitempty"`
+	// Usage instructions the server sent on initialize
+	Instructions  string `protobuf:"bytes,7,opt,name=instructions,proto3" json:"instructions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ServerInfo) Reset() {
+	*x = ServerInfo{}
+	mi := &file_agent_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ServerInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ServerInfo) ProtoMessage() {}
+
+func (x *ServerInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ServerInfo.ProtoReflect.Descriptor instead.
+func (*ServerInfo) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *ServerInfo) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ServerInfo) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *ServerInfo) GetConnected() bool {
+	if x != nil {
+		return x.Connected
+	}
+	return false
+}
+
+func (x *ServerInfo) GetToolCount() int32 {
+	if x != nil {
+		return x.ToolCount
+	}
+	return 0
+}
+
+func (x *ServerInfo) GetPromptCount() int32 {
+	if x != nil {
+		return x.PromptCount
+	}
+	return 0
+}
+
+func (x *ServerInfo) GetResourceCount() int32 {
+	if x != nil {
+		return x.ResourceCount
+	}
+	return 0
+}
+
+func (x *ServerInfo) GetInstructions() string {
+	if x != nil {
+		return x.Instructions
+	}
+	return ""
+}
+
+type ListPromptsRequest struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	AgentId string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	// Only prompts of this server; empty = all servers
+	Server        string `protobuf:"bytes,2,opt,name=server,proto3" json:"server,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListPromptsRequest) Reset() {
+	*x = ListPromptsRequest{}
+	mi := &file_agent_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListPromptsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPromptsRequest) ProtoMessage() {}
+
+func (x *ListPromptsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPromptsRequest.ProtoReflect.Descriptor instead.
+func (*ListPromptsRequest) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *ListPromptsRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *ListPromptsRequest) GetServer() string {
+	if x != nil {
+		return x.Server
+	}
+	return ""
+}
+
+type ListPromptsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Sorted by server, then name
+	Prompts       []*PromptInfo `protobuf:"bytes,1,rep,name=prompts,proto3" json:"prompts,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListPromptsResponse) Reset() {
+	*x = ListPromptsResponse{}
+	mi := &file_agent_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListPromptsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPromptsResponse) ProtoMessage() {}
+
+func (x *ListPromptsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPromptsResponse.ProtoReflect.Descriptor instead.
+func (*ListPromptsResponse) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *ListPromptsResponse) GetPrompts() []*PromptInfo {
+	if x != nil {
+		return x.Prompts
+	}
+	return nil
+}
+
+// PromptInfo describes a prompt an MCP server offers
+type PromptInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Description   string                 `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
+	Server        string                 `protobuf:"bytes,3,opt,name=server,proto3" json:"server,omitempty"`
+	Arguments     []*PromptArgument      `protobuf:"bytes,4,rep,name=arguments,proto3" json:"arguments,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PromptInfo) Reset() {
+	*x = PromptInfo{}
+	mi := &file_agent_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PromptInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PromptInfo) ProtoMessage() {}
+
+func (x *PromptInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PromptInfo.ProtoReflect.Descriptor instead.
+func (*PromptInfo) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *PromptInfo) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *PromptInfo) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *PromptInfo) GetServer() string {
+	if x != nil {
+		return x.Server
+	}
+	return ""
+}
+
+func (x *PromptInfo) GetArguments() []*PromptArgument {
+	if x != nil {
+		return x.Arguments
+	}
+	return nil
+}
+
+type PromptArgument struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Description   string                 `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
+	Required      bool                   `protobuf:"varint,3,opt,name=required,proto3" json:"required,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PromptArgument) Reset() {
+	*x = PromptArgument{}
+	mi := &file_agent_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PromptArgument) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PromptArgument) ProtoMessage() {}
+
+func (x *PromptArgument) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PromptArgument.ProtoReflect.Descriptor instead.
+func (*PromptArgument) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *PromptArgument) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *PromptArgument) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *PromptArgument) GetRequired() bool {
+	if x != nil {
+		return x.Required
+	}
+	return false
+}
+
+type ConversationRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Agent ID for the conversation
+	AgentId string `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	// Types that are valid to be assigned to Payload:
+	//
+	//	*ConversationRequest_Question
+	//	*ConversationRequest_ToolResult
+	//	*ConversationRequest_Cancel
+	Payload       isConversationRequest_Payload `protobuf_oneof:"payload"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConversationRequest) Reset() {
+	*x = ConversationRequest{}
+	mi := &file_agent_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConversationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConversationRequest) ProtoMessage() {}
+
+func (x *ConversationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConversationRequest.ProtoReflect.Descriptor instead.
+func (*ConversationRequest) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *ConversationRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *ConversationRequest) GetPayload() isConversationRequest_Payload {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *ConversationRequest) GetQuestion() *QuestionMessage {
+	if x != nil {
+		if x, ok := x.Payload.(*ConversationRequest_Question); ok {
+			return x.Question
+		}
+	}
+	return nil
+}
+
+func (x *ConversationRequest) GetToolResult() *ToolResultMessage {
+	if x != nil {
+		if x, ok := x.Payload.(*ConversationRequest_ToolResult); ok {
+			return x.ToolResult
+		}
+	}
+	return nil
+}
+
+func (x *ConversationRequest) GetCancel() *CancelMessage {
+	if x != nil {
+		if x, ok := x.Payload.(*ConversationRequest_Cancel); ok {
+			return x.Cancel
+		}
+	}
+	return nil
+}
+
+type isConversationRequest_Payload interface {
+	isConversationRequest_Payload()
+}
+
+type ConversationRequest_Question struct {
+	// Client asks a question (optionally with history)
+	Question *QuestionMessage `protobuf:"bytes,2,opt,name=question,proto3,oneof"`
+}
+
+type ConversationRequest_ToolResult struct {
+	// Client returns result of a tool call
+	ToolResult *ToolResultMessage `protobuf:"bytes,3,opt,name=tool_result,json=toolResult,proto3,oneof"`
+}
+
+type ConversationRequest_Cancel struct {
+	// Client requests cancellation
+	Cancel *CancelMessage `protobuf:"bytes,4,opt,name=cancel,proto3,oneof"`
+}
+
+func (*ConversationRequest_Question) isConversationRequest_Payload() {}
+
+func (*ConversationRequest_ToolResult) isConversationRequest_Payload() {}
+
+func (*ConversationRequest_Cancel) isConversationRequest_Payload() {}
+
+type QuestionMessage struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The question/prompt text
+	Text string `protobuf:"bytes,1,opt,name=text,proto3" json:"text,omitempty"`
+	// Optional conversation history for multi-turn
+	History []*Message `protobuf:"bytes,2,rep,name=history,proto3" json:"history,omitempty"`
+	// Conversation priority: "low", "normal" (default) or "high"
+	Priority      string `protobuf:"bytes,3,opt,name=priority,proto3" json:"priority,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QuestionMessage) Reset() {
+	*x = QuestionMessage{}
+	mi := &file_agent_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QuestionMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QuestionMessage) ProtoMessage() {}
+
+func (x *QuestionMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QuestionMessage.ProtoReflect.Descriptor instead.
+func (*QuestionMessage) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *QuestionMessage) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *QuestionMessage) GetHistory() []*Message {
+	if x != nil {
+		return x.History
+	}
+	return nil
+}
+
+func (x *QuestionMessage) GetPriority() string {
+	if x != nil {
+		return x.Priority
+	}
+	return ""
+}
+
+type ToolResultMessage struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Call ID from ToolCallEvent
+	CallId string `protobuf:"bytes,1,opt,name=call_id,json=callId,proto3" json:"call_id,omitempty"`
+	// Whether tool execution succeeded
+	Success bool `protobuf:"varint,2,opt,name=success,proto3" json:"success,omitempty"`
+	// Tool output (if success)
+	Result string `protobuf:"bytes,3,opt,name=result,proto3" json:"result,omitempty"`
+	// Error details (if not success)
+	Error *ToolError `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
+	// Execution duration in milliseconds
+	DurationMs    int64 `protobuf:"varint,5,opt,name=duration_ms,json=durationMs,proto3" json:"duration_ms,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ToolResultMessage) Reset() {
+	*x = ToolResultMessage{}
+	mi := &file_agent_proto_msgTypes[40]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ToolResultMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ToolResultMessage) ProtoMessage() {}
+
+func (x *ToolResultMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[40]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ToolResultMessage.ProtoReflect.Descriptor instead.
+func (*ToolResultMessage) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{40}
+}
+
+func (x *ToolResultMessage) GetCallId() string {
+	if x != nil {
+		return x.CallId
+	}
+	return ""
+}
+
+func (x *ToolResultMessage) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *ToolResultMessage) GetResult() string {
+	if x != nil {
+		return x.Result
+	}
+	return ""
+}
+
+func (x *ToolResultMessage) GetError() *ToolError {
+	if x != nil {
+		return x.Error
+	}
+	return nil
+}
+
+func (x *ToolResultMessage) GetDurationMs() int64 {
+	if x != nil {
+		return x.DurationMs
+	}
+	return 0
+}
+
+type ToolError struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          string                 `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Details       *structpb.Struct       `protobuf:"bytes,3,opt,name=details,proto3" json:"details,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ToolError) Reset() {
+	*x = ToolError{}
+	mi := &file_agent_proto_msgTypes[41]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ToolError) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ToolError) ProtoMessage() {}
+
+func (x *ToolError) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[41]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ToolError.ProtoReflect.Descriptor instead.
+func (*ToolError) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{41}
+}
+
+func (x *ToolError) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *ToolError) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *ToolError) GetDetails() *structpb.Struct {
+	if x != nil {
+		return x.Details
+	}
+	return nil
+}
+
+type CancelMessage struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Reason        string                 `protobuf:"bytes,1,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CancelMessage) Reset() {
+	*x = CancelMessage{}
+	mi := &file_agent_proto_msgTypes[42]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelMessage) ProtoMessage() {}
+
+func (x *CancelMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[42]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelMessage.ProtoReflect.Descriptor instead.
+func (*CancelMessage) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{42}
+}
+
+func (x *CancelMessage) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type ConversationResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Payload:
+	//
+	//	*ConversationResponse_TextChunk
+	//	*ConversationResponse_ToolCall
+	//	*ConversationResponse_AgentEvent
+	//	*ConversationResponse_FinalResponse
+	//	*ConversationResponse_Error
+	Payload       isConversationResponse_Payload `protobuf_oneof:"payload"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConversationResponse) Reset() {
+	*x = ConversationResponse{}
+	mi := &file_agent_proto_msgTypes[43]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConversationResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConversationResponse) ProtoMessage() {}
+
+func (x *ConversationResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[43]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConversationResponse.ProtoReflect.Descriptor instead.
+func (*ConversationResponse) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{43}
+}
+
+func (x *ConversationResponse) GetPayload() isConversationResponse_Payload {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *ConversationResponse) GetTextChunk() *TextChunkEvent {
+	if x != nil {
+		if x, ok := x.Payload.(*ConversationResponse_TextChunk); ok {
+			return x.TextChunk
+		}
+	}
+	return nil
+}
+
+func (x *ConversationResponse) GetToolCall() *ToolCallEvent {
+	if x != nil {
+		if x, ok := x.Payload.(*ConversationResponse_ToolCall); ok {
+			return x.ToolCall
+		}
+	}
+	return nil
+}
+
+func (x *ConversationResponse) GetAgentEvent() *AgentEvent {
+	if x != nil {
+		if x, ok := x.Payload.(*ConversationResponse_AgentEvent); ok {
+			return x.AgentEvent
+		}
+	}
+	return nil
+}
+
+func (x *ConversationResponse) GetFinalResponse() *FinalResponse {
+	if x != nil {
+		if x, ok := x.Payload.(*ConversationResponse_FinalResponse); ok {
+			return x.FinalResponse
+		}
+	}
+	return nil
+}
+
+func (x *ConversationResponse) GetError() *ErrorEvent {
+	if x != nil {
+		if x, ok := x.Payload.(*ConversationResponse_Error); ok {
+			return x.Error
+		}
+	}
+	return nil
+}
+
+type isConversationResponse_Payload interface {
+	isConversationResponse_Payload()
+}
+
+type ConversationResponse_TextChunk struct {
+	// Streaming text chunk from LLM
+	TextChunk *TextChunkEvent `protobuf:"bytes,1,opt,name=text_chunk,json=textChunk,proto3,oneof"`
+}
+
+type ConversationResponse_ToolCall struct {
+	// Server requests tool execution from client
+	ToolCall *ToolCallEvent `protobuf:"bytes,2,opt,name=tool_call,json=toolCall,proto3,oneof"`
+}
+
+type ConversationResponse_AgentEvent struct {
+	// Agent event for observability
+	AgentEvent *AgentEvent `protobuf:"bytes,3,opt,name=agent_event,json=agentEvent,proto3,oneof"`
+}
+
+type ConversationResponse_FinalResponse struct {
+	// Final response (conversation complete)
+	FinalResponse *FinalResponse `protobuf:"bytes,4,opt,name=final_response,json=finalResponse,proto3,oneof"`
+}
+
+type ConversationResponse_Error struct {
+	// Error event
+	Error *ErrorEvent `protobuf:"bytes,5,opt,name=error,proto3,oneof"`
+}
+
+func (*ConversationResponse_TextChunk) isConversationResponse_Payload() {}
+
+func (*ConversationResponse_ToolCall) isConversationResponse_Payload() {}
+
+func (*ConversationResponse_AgentEvent) isConversationResponse_Payload() {}
+
+func (*ConversationResponse_FinalResponse) isConversationResponse_Payload() {}
+
+func (*ConversationResponse_Error) isConversationResponse_Payload() {}
+
+type TextChunkEvent struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Text content chunk
+	Text string `protobuf:"bytes,1,opt,name=text,proto3" json:"text,omitempty"`
+	// Whether this is thinking/reasoning (for reasoning models)
+	IsThinking    bool `protobuf:"varint,2,opt,name=is_thinking,json=isThinking,proto3" json:"is_thinking,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TextChunkEvent) Reset() {
+	*x = TextChunkEvent{}
+	mi := &file_agent_proto_msgTypes[44]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TextChunkEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TextChunkEvent) ProtoMessage() {}
+
+func (x *TextChunkEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[44]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TextChunkEvent.ProtoReflect.Descriptor instead.
+func (*TextChunkEvent) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{44}
+}
+
+func (x *TextChunkEvent) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *TextChunkEvent) GetIsThinking() bool {
+	if x != nil {
+		return x.IsThinking
+	}
+	return false
+}
+
+type ToolCallEvent struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Unique call ID for matching result
+	CallId string `protobuf:"bytes,1,opt,name=call_id,json=callId,proto3" json:"call_id,omitempty"`
+	// Tool name to execute
+	ToolName string `protobuf:"bytes,2,opt,name=tool_name,json=toolName,proto3" json:"tool_name,omitempty"`
+	// Tool arguments as JSON object
+	Arguments *structpb.Struct `protobuf:"bytes,3,opt,name=arguments,proto3" json:"arguments,omitempty"`
+	// Timeout for this tool call in milliseconds
+	TimeoutMs     int32 `protobuf:"varint,4,opt,name=timeout_ms,json=timeoutMs,proto3" json:"timeout_ms,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ToolCallEvent) Reset() {
+	*x = ToolCallEvent{}
+	mi := &file_agent_proto_msgTypes[45]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ToolCallEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ToolCallEvent) ProtoMessage() {}
+
+func (x *ToolCallEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[45]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ToolCallEvent.ProtoReflect.Descriptor instead.
+func (*ToolCallEvent) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{45}
+}
+
+func (x *ToolCallEvent) GetCallId() string {
+	if x != nil {
+		return x.CallId
+	}
+	return ""
+}
+
+func (x *ToolCallEvent) GetToolName() string {
+	if x != nil {
+		return x.ToolName
+	}
+	return ""
+}
+
+func (x *ToolCallEvent) GetArguments() *structpb.Struct {
+	if x != nil {
+		return x.Arguments
+	}
+	return nil
+}
+
+func (x *ToolCallEvent) GetTimeoutMs() int32 {
+	if x != nil {
+		return x.TimeoutMs
+	}
+	return 0
+}
+
+type FinalResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Final response text
+	Response string `protobuf:"bytes,1,opt,name=response,proto3" json:"response,omitempty"`
+	// Updated conversation messages
+	UpdatedMessages []*Message `protobuf:"bytes,2,rep,name=updated_messages,json=updatedMessages,proto3" json:"updated_messages,omitempty"`
+	// Token usage for this conversation
+	TokenUsage *TokenUsage `protobuf:"bytes,3,opt,name=token_usage,json=tokenUsage,proto3" json:"token_usage,omitempty"`
+	// Total duration in milliseconds
+	DurationMs int64 `protobuf:"varint,4,opt,name=duration_ms,json=durationMs,proto3" json:"duration_ms,omitempty"`
+	// Files produced during the conversation that can be downloaded from the artifact server
+	Artifacts     []*Artifact `protobuf:"bytes,5,rep,name=artifacts,proto3" json:"artifacts,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FinalResponse) Reset() {
+	*x = FinalResponse{}
+	mi := &file_agent_proto_msgTypes[46]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FinalResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FinalResponse) ProtoMessage() {}
+
+func (x *FinalResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[46]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FinalResponse.ProtoReflect.Descriptor instead.
+func (*FinalResponse) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{46}
+}
+
+func (x *FinalResponse) GetResponse() string {
+	if x != nil {
+		return x.Response
+	}
+	return ""
+}
+
+func (x *FinalResponse) GetUpdatedMessages() []*Message {
+	if x != nil {
+		return x.UpdatedMessages
+	}
+	return nil
+}
+
+func (x *FinalResponse) GetTokenUsage() *TokenUsage {
+	if x != nil {
+		return x.TokenUsage
+	}
+	return nil
+}
+
+func (x *FinalResponse) GetDurationMs() int64 {
+	if x != nil {
+		return x.DurationMs
+	}
+	return 0
+}
+
+func (x *FinalResponse) GetArtifacts() []*Artifact {
+	if x != nil {
+		return x.Artifacts
+	}
+	return nil
+}
+
+type ErrorEvent struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Error reason (e.g., AGENT_NOT_FOUND, PROVIDER_THROTTLED, CONTEXT_OVERFLOW)
+	Code string `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	// Human-readable error message
+	Message string `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	// Structured error details (grpc_code, provider, model, retry_after_ms, ...)
+	Details *structpb.Struct `protobuf:"bytes,3,opt,name=details,proto3" json:"details,omitempty"`
+	// If true, the stream is terminated
+	Fatal         bool `protobuf:"varint,4,opt,name=fatal,proto3" json:"fatal,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ErrorEvent) Reset() {
+	*x = ErrorEvent{}
+	mi := &file_agent_proto_msgTypes[47]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ErrorEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ErrorEvent) ProtoMessage() {}
+
+func (x *ErrorEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[47]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ErrorEvent.ProtoReflect.Descriptor instead.
+func (*ErrorEvent) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{47}
+}
+
+func (x *ErrorEvent) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *ErrorEvent) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *ErrorEvent) GetDetails() *structpb.Struct {
+	if x != nil {
+		return x.Details
+	}
+	return nil
+}
+
+func (x *ErrorEvent) GetFatal() bool {
+	if x != nil {
+		return x.Fatal
+	}
+	return false
+}
+
+type AgentEvent struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Event type (e.g., "agent_start", "tool_call", "llm_request")
+	Type string `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	// Event timestamp
+	Timestamp *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	// Trace ID for distributed tracing
+	TraceId string `protobuf:"bytes,3,opt,name=trace_id,json=traceId,proto3" json:"trace_id,omitempty"`
+	// Span ID within the trace
+	SpanId string `protobuf:"bytes,4,opt,name=span_id,json=spanId,proto3" json:"span_id,omitempty"`
+	// Parent span ID
+	ParentId string `protobuf:"bytes,5,opt,name=parent_id,json=parentId,proto3" json:"parent_id,omitempty"`
+	// Correlation ID for related events
+	CorrelationId string `protobuf:"bytes,6,opt,name=correlation_id,json=correlationId,proto3" json:"correlation_id,omitempty"`
+	// Hierarchy level in the call stack
+	HierarchyLevel int32 `protobuf:"varint,7,opt,name=hierarchy_level,json=hierarchyLevel,proto3" json:"hierarchy_level,omitempty"`
+	// Session ID
+	SessionId string `protobuf:"bytes,8,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	// Component that emitted the event
+	Component string `protobuf:"bytes,9,opt,name=component,proto3" json:"component,omitempty"`
+	// Event-specific data as JSON object
+	Data *structpb.Struct `protobuf:"bytes,10,opt,name=data,proto3" json:"data,omitempty"`
+	// Files referenced by this event that can be downloaded from the artifact server
+	Artifacts     []*Artifact `protobuf:"bytes,11,rep,name=artifacts,proto3" json:"artifacts,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AgentEvent) Reset() {
+	*x = AgentEvent{}
+	mi := &file_agent_proto_msgTypes[48]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AgentEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AgentEvent) ProtoMessage() {}
+
+func (x *AgentEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[48]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AgentEvent.ProtoReflect.Descriptor instead.
+func (*AgentEvent) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{48}
+}
+
+func (x *AgentEvent) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *AgentEvent) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+func (x *AgentEvent) GetTraceId() string {
+	if x != nil {
+		return x.TraceId
+	}
+	return ""
+}
+
+func (x *AgentEvent) GetSpanId() string {
+	if x != nil {
+		return x.SpanId
+	}
+	return ""
+}
+
+func (x *AgentEvent) GetParentId() string {
+	if x != nil {
+		return x.ParentId
+	}
+	return ""
+}
+
+func (x *AgentEvent) GetCorrelationId() string {
+	if x != nil {
+		return x.CorrelationId
+	}
+	return ""
+}
+
+func (x *AgentEvent) GetHierarchyLevel() int32 {
+	if x != nil {
+		return x.HierarchyLevel
+	}
+	return 0
+}
+
+func (x *AgentEvent) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *AgentEvent) GetComponent() string {
+	if x != nil {
+		return x.Component
+	}
+	return ""
+}
+
+func (x *AgentEvent) GetData() *structpb.Struct {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *AgentEvent) GetArtifacts() []*Artifact {
+	if x != nil {
+		return x.Artifacts
+	}
+	return nil
+}
+
+type Artifact struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Local file path as reported by the agent
+	Path string `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	// Download URL on the artifact server (requires the artifact server token)
+	Url           string `protobuf:"bytes,2,opt,name=url,proto3" json:"url,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Artifact) Reset() {
+	*x = Artifact{}
+	mi := &file_agent_proto_msgTypes[49]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Artifact) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Artifact) ProtoMessage() {}
+
+func (x *Artifact) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[49]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Artifact.ProtoReflect.Descriptor instead.
+func (*Artifact) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{49}
+}
+
+func (x *Artifact) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *Artifact) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+type WatchConversationRequest struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	AgentId string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	// Deliver the agent's retained recent events first, then go live
+	// (requires stream replay on the server)
+	Replay bool `protobuf:"varint,2,opt,name=replay,proto3" json:"replay,omitempty"`
+	// Events to deliver: "full" (default, every event), "chunks" (streamed
+	// text plus the conversation end) or "milestones" (lifecycle and tool calls)
+	Verbosity     string `protobuf:"bytes,3,opt,name=verbosity,proto3" json:"verbosity,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchConversationRequest) Reset() {
+	*x = WatchConversationRequest{}
+	mi := &file_agent_proto_msgTypes[50]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchConversationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchConversationRequest) ProtoMessage() {}
+
+func (x *WatchConversationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[50]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchConversationRequest.ProtoReflect.Descriptor instead.
+func (*WatchConversationRequest) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{50}
+}
+
+func (x *WatchConversationRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *WatchConversationRequest) GetReplay() bool {
+	if x != nil {
+		return x.Replay
+	}
+	return false
+}
+
+func (x *WatchConversationRequest) GetVerbosity() string {
+	if x != nil {
+		return x.Verbosity
+	}
+	return ""
+}
+
+type AskStreamRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	AgentId  string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	Question string                 `protobuf:"bytes,2,opt,name=question,proto3" json:"question,omitempty"`
+	// Optional conversation history for multi-turn
+	History []*Message `protobuf:"bytes,3,rep,name=history,proto3" json:"history,omitempty"`
+	// Also stream every other agent event (LLM calls, token usage, ...) as AgentEvent
+	IncludeEvents bool `protobuf:"varint,4,opt,name=include_events,json=includeEvents,proto3" json:"include_events,omitempty"`
+	// Conversation priority: "low", "normal" (default) or "high"
+	Priority string `protobuf:"bytes,5,opt,name=priority,proto3" json:"priority,omitempty"`
+	// Optional client-chosen ID making the request cancellable with CancelRequest
+	RequestId     string `protobuf:"bytes,6,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AskStreamRequest) Reset() {
+	*x = AskStreamRequest{}
+	mi := &file_agent_proto_msgTypes[51]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AskStreamRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AskStreamRequest) ProtoMessage() {}
+
+func (x *AskStreamRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[51]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AskStreamRequest.ProtoReflect.Descriptor instead.
+func (*AskStreamRequest) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{51}
+}
+
+func (x *AskStreamRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *AskStreamRequest) GetQuestion() string {
+	if x != nil {
+		return x.Question
+	}
+	return ""
+}
+
+func (x *AskStreamRequest) GetHistory() []*Message {
+	if x != nil {
+		return x.History
+	}
+	return nil
+}
+
+func (x *AskStreamRequest) GetIncludeEvents() bool {
+	if x != nil {
+		return x.IncludeEvents
+	}
+	return false
+}
+
+func (x *AskStreamRequest) GetPriority() string {
+	if x != nil {
+		return x.Priority
+	}
+	return ""
+}
+
+func (x *AskStreamRequest) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+type AskStreamResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Payload:
+	//
+	//	*AskStreamResponse_TextChunk
+	//	*AskStreamResponse_ToolCallStart
+	//	*AskStreamResponse_ToolCallEnd
+	//	*AskStreamResponse_FinalResponse
+	//	*AskStreamResponse_Error
+	//	*AskStreamResponse_AgentEvent
+	Payload       isAskStreamResponse_Payload `protobuf_oneof:"payload"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AskStreamResponse) Reset() {
+	*x = AskStreamResponse{}
+	mi := &file_agent_proto_msgTypes[52]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AskStreamResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AskStreamResponse) ProtoMessage() {}
+
+func (x *AskStreamResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[52]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AskStreamResponse.ProtoReflect.Descriptor instead.
+func (*AskStreamResponse) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{52}
+}
+
+func (x *AskStreamResponse) GetPayload() isAskStreamResponse_Payload {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *AskStreamResponse) GetTextChunk() *TextChunkEvent {
+	if x != nil {
+		if x, ok := x.Payload.(*AskStreamResponse_TextChunk); ok {
+			return x.TextChunk
+		}
+	}
+	return nil
+}
+
+func (x *AskStreamResponse) GetToolCallStart() *ToolCallStart {
+	if x != nil {
+		if x, ok := x.Payload.(*AskStreamResponse_ToolCallStart); ok {
+			return x.ToolCallStart
+		}
+	}
+	return nil
+}
+
+func (x *AskStreamResponse) GetToolCallEnd() *ToolCallEnd {
+	if x != nil {
+		if x, ok := x.Payload.(*AskStreamResponse_ToolCallEnd); ok {
+			return x.ToolCallEnd
+		}
+	}
+	return nil
+}
+
+func (x *AskStreamResponse) GetFinalResponse() *FinalResponse {
+	if x != nil {
+		if x, ok := x.Payload.(*AskStreamResponse_FinalResponse); ok {
+			return x.FinalResponse
+		}
+	}
+	return nil
+}
+
+func (x *AskStreamResponse) GetError() *ErrorEvent {
+	if x != nil {
+		if x, ok := x.Payload.(*AskStreamResponse_Error); ok {
+			return x.Error
+		}
+	}
+	return nil
+}
+
+func (x *AskStreamResponse) GetAgentEvent() *AgentEvent {
+	if x != nil {
+		if x, ok := x.Payload.(*AskStreamResponse_AgentEvent); ok {
+			return x.AgentEvent
+		}
+	}
+	return nil
+}
+
+type isAskStreamResponse_Payload interface {
+	isAskStreamResponse_Payload()
+}
+
+type AskStreamResponse_TextChunk struct {
+	// Streaming text chunk from LLM
+	TextChunk *TextChunkEvent `protobuf:"bytes,1,opt,name=text_chunk,json=textChunk,proto3,oneof"`
+}
+
+type AskStreamResponse_ToolCallStart struct {
+	// A tool call started
+	ToolCallStart *ToolCallStart `protobuf:"bytes,2,opt,name=tool_call_start,json=toolCallStart,proto3,oneof"`
+}
+
+type AskStreamResponse_ToolCallEnd struct {
+	// A tool call finished (successfully or not)
+	ToolCallEnd *ToolCallEnd `protobuf:"bytes,3,opt,name=tool_call_end,json=toolCallEnd,proto3,oneof"`
+}
+
+type AskStreamResponse_FinalResponse struct {
+	// Final response (the stream ends after it)
+	FinalResponse *FinalResponse `protobuf:"bytes,4,opt,name=final_response,json=finalResponse,proto3,oneof"`
+}
+
+type AskStreamResponse_Error struct {
+	// Error event (the stream ends after a fatal one)
+	Error *ErrorEvent `protobuf:"bytes,5,opt,name=error,proto3,oneof"`
+}
+
+type AskStreamResponse_AgentEvent struct {
+	// Other agent events, only with include_events
+	AgentEvent *AgentEvent `protobuf:"bytes,6,opt,name=agent_event,json=agentEvent,proto3,oneof"`
+}
+
+func (*AskStreamResponse_TextChunk) isAskStreamResponse_Payload() {}
+
+func (*AskStreamResponse_ToolCallStart) isAskStreamResponse_Payload() {}
+
+func (*AskStreamResponse_ToolCallEnd) isAskStreamResponse_Payload() {}
+
+func (*AskStreamResponse_FinalResponse) isAskStreamResponse_Payload() {}
+
+func (*AskStreamResponse_Error) isAskStreamResponse_Payload() {}
+
+func (*AskStreamResponse_AgentEvent) isAskStreamResponse_Payload() {}
+
+type ToolCallStart struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Tool call ID from the LLM response, matches ToolCallEnd.call_id
+	CallId   string `protobuf:"bytes,1,opt,name=call_id,json=callId,proto3" json:"call_id,omitempty"`
+	ToolName string `protobuf:"bytes,2,opt,name=tool_name,json=toolName,proto3" json:"tool_name,omitempty"`
+	// MCP server providing the tool
+	ServerName string `protobuf:"bytes,3,opt,name=server_name,json=serverName,proto3" json:"server_name,omitempty"`
+	// Tool arguments as a JSON string
+	Arguments string `protobuf:"bytes,4,opt,name=arguments,proto3" json:"arguments,omitempty"`
+	// Conversation turn the call belongs to
+	Turn          int32 `protobuf:"varint,5,opt,name=turn,proto3" json:"turn,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ToolCallStart) Reset() {
+	*x = ToolCallStart{}
+	mi := &file_agent_proto_msgTypes[53]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ToolCallStart) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ToolCallStart) ProtoMessage() {}
+
+func (x *ToolCallStart) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[53]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ToolCallStart.ProtoReflect.Descriptor instead.
+func (*ToolCallStart) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{53}
+}
+
+func (x *ToolCallStart) GetCallId() string {
+	if x != nil {
+		return x.CallId
+	}
+	return ""
+}
+
+func (x *ToolCallStart) GetToolName() string {
+	if x != nil {
+		return x.ToolName
+	}
+	return ""
+}
+
+func (x *ToolCallStart) GetServerName() string {
+	if x != nil {
+		return x.ServerName
+	}
+	return ""
+}
+
+func (x *ToolCallStart) GetArguments() string {
+	if x != nil {
+		return x.Arguments
+	}
+	return ""
+}
+
+func (x *ToolCallStart) GetTurn() int32 {
+	if x != nil {
+		return x.Turn
+	}
+	return 0
+}
+
+type ToolCallEnd struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Tool call ID from the LLM response, matches ToolCallStart.call_id
+	CallId   string `protobuf:"bytes,1,opt,name=call_id,json=callId,proto3" json:"call_id,omitempty"`
+	ToolName string `protobuf:"bytes,2,opt,name=tool_name,json=toolName,proto3" json:"tool_name,omitempty"`
+	// MCP server providing the tool
+	ServerName string `protobuf:"bytes,3,opt,name=server_name,json=serverName,proto3" json:"server_name,omitempty"`
+	// Whether the tool call succeeded
+	Success bool `protobuf:"varint,4,opt,name=success,proto3" json:"success,omitempty"`
+	// Tool output (if success)
+	Result string `protobuf:"bytes,5,opt,name=result,proto3" json:"result,omitempty"`
+	// Error message (if not success)
+	Error string `protobuf:"bytes,6,opt,name=error,proto3" json:"error,omitempty"`
+	// Execution duration in milliseconds
+	DurationMs int64 `protobuf:"varint,7,opt,name=duration_ms,json=durationMs,proto3" json:"duration_ms,omitempty"`
+	// Conversation turn the call belongs to
+	Turn int32 `protobuf:"varint,8,opt,name=turn,proto3" json:"turn,omitempty"`
+	// Whether the result was served from the agent's tool result cache
+	FromCache     bool `protobuf:"varint,9,opt,name=from_cache,json=fromCache,proto3" json:"from_cache,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ToolCallEnd) Reset() {
+	*x = ToolCallEnd{}
+	mi := &file_agent_proto_msgTypes[54]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ToolCallEnd) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ToolCallEnd) ProtoMessage() {}
+
+func (x *ToolCallEnd) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[54]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ToolCallEnd.ProtoReflect.Descriptor instead.
+func (*ToolCallEnd) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{54}
+}
+
+func (x *ToolCallEnd) GetCallId() string {
+	if x != nil {
+		return x.CallId
+	}
+	return ""
+}
+
+func (x *ToolCallEnd) GetToolName() string {
+	if x != nil {
+		return x.ToolName
+	}
+	return ""
+}
+
+func (x *ToolCallEnd) GetServerName() string {
+	if x != nil {
+		return x.ServerName
+	}
+	return ""
+}
+
+func (x *ToolCallEnd) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *ToolCallEnd) GetResult() string {
+	if x != nil {
+		return x.Result
+	}
+	return ""
+}
+
+func (x *ToolCallEnd) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *ToolCallEnd) GetDurationMs() int64 {
+	if x != nil {
+		return x.DurationMs
+	}
+	return 0
+}
+
+func (x *ToolCallEnd) GetTurn() int32 {
+	if x != nil {
+		return x.Turn
+	}
+	return 0
+}
+
+func (x *ToolCallEnd) GetFromCache() bool {
+	if x != nil {
+		return x.FromCache
+	}
+	return false
+}
+
+type Message struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Role: "user", "assistant", "system"
+	Role string `protobuf:"bytes,1,opt,name=role,proto3" json:"role,omitempty"`
+	// Message content
+	Content       string `protobuf:"bytes,2,opt,name=content,proto3" json:"content,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Message) Reset() {
+	*x = Message{}
+	mi := &file_agent_proto_msgTypes[55]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Message) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Message) ProtoMessage() {}
+
+func (x *Message) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[55]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Message.ProtoReflect.Descriptor instead.
+func (*Message) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{55}
+}
+
+func (x *Message) GetRole() string {
+	if x != nil {
+		return x.Role
+	}
+	return ""
+}
+
+func (x *Message) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+type AskRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	AgentId  string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	Question string                 `protobuf:"bytes,2,opt,name=question,proto3" json:"question,omitempty"`
+	// Conversation priority: "low", "normal" (default) or "high"
+	Priority string `protobuf:"bytes,3,opt,name=priority,proto3" json:"priority,omitempty"`
+	// Optional client-chosen ID making the request cancellable with CancelRequest
+	RequestId     string `protobuf:"bytes,4,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AskRequest) Reset() {
+	*x = AskRequest{}
+	mi := &file_agent_proto_msgTypes[56]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AskRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AskRequest) ProtoMessage() {}
+
+func (x *AskRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[56]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AskRequest.ProtoReflect.Descriptor instead.
+func (*AskRequest) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{56}
+}
+
+func (x *AskRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *AskRequest) GetQuestion() string {
+	if x != nil {
+		return x.Question
+	}
+	return ""
+}
+
+func (x *AskRequest) GetPriority() string {
+	if x != nil {
+		return x.Priority
+	}
+	return ""
+}
+
+func (x *AskRequest) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+type AskResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Response      string                 `protobuf:"bytes,1,opt,name=response,proto3" json:"response,omitempty"`
+	TokenUsage    *TokenUsage            `protobuf:"bytes,2,opt,name=token_usage,json=tokenUsage,proto3" json:"token_usage,omitempty"`
+	DurationMs    int64                  `protobuf:"varint,3,opt,name=duration_ms,json=durationMs,proto3" json:"duration_ms,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AskResponse) Reset() {
+	*x = AskResponse{}
+	mi := &file_agent_proto_msgTypes[57]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AskResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AskResponse) ProtoMessage() {}
+
+func (x *AskResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[57]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AskResponse.ProtoReflect.Descriptor instead.
+func (*AskResponse) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{57}
+}
+
+func (x *AskResponse) GetResponse() string {
+	if x != nil {
+		return x.Response
+	}
+	return ""
+}
+
+func (x *AskResponse) GetTokenUsage() *TokenUsage {
+	if x != nil {
+		return x.TokenUsage
+	}
+	return nil
+}
+
+func (x *AskResponse) GetDurationMs() int64 {
+	if x != nil {
+		return x.DurationMs
+	}
+	return 0
+}
+
+type AskWithHistoryRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	AgentId  string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	Messages []*Message             `protobuf:"bytes,2,rep,name=messages,proto3" json:"messages,omitempty"`
+	// Conversation priority: "low", "normal" (default) or "high"
+	Priority string `protobuf:"bytes,3,opt,name=priority,proto3" json:"priority,omitempty"`
+	// Optional client-chosen ID making the request cancellable with CancelRequest
+	RequestId     string `protobuf:"bytes,4,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AskWithHistoryRequest) Reset() {
+	*x = AskWithHistoryRequest{}
+	mi := &file_agent_proto_msgTypes[58]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AskWithHistoryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AskWithHistoryRequest) ProtoMessage() {}
+
+func (x *AskWithHistoryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[58]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AskWithHistoryRequest.ProtoReflect.Descriptor instead.
+func (*AskWithHistoryRequest) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{58}
+}
+
+func (x *AskWithHistoryRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *AskWithHistoryRequest) GetMessages() []*Message {
+	if x != nil {
+		return x.Messages
+	}
+	return nil
+}
+
+func (x *AskWithHistoryRequest) GetPriority() string {
+	if x != nil {
+		return x.Priority
+	}
+	return ""
+}
+
+func (x *AskWithHistoryRequest) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+type AskWithHistoryResponse struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Response        string                 `protobuf:"bytes,1,opt,name=response,proto3" json:"response,omitempty"`
+	UpdatedMessages []*Message             `protobuf:"bytes,2,rep,name=updated_messages,json=updatedMessages,proto3" json:"updated_messages,omitempty"`
+	TokenUsage      *TokenUsage            `protobuf:"bytes,3,opt,name=token_usage,json=tokenUsage,proto3" json:"token_usage,omitempty"`
+	DurationMs      int64                  `protobuf:"varint,4,opt,name=duration_ms,json=durationMs,proto3" json:"duration_ms,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *AskWithHistoryResponse) Reset() {
+	*x = AskWithHistoryResponse{}
+	mi := &file_agent_proto_msgTypes[59]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AskWithHistoryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AskWithHistoryResponse) ProtoMessage() {}
+
+func (x *AskWithHistoryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[59]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AskWithHistoryResponse.ProtoReflect.Descriptor instead.
+func (*AskWithHistoryResponse) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{59}
+}
+
+func (x *AskWithHistoryResponse) GetResponse() string {
+	if x != nil {
+		return x.Response
+	}
+	return ""
+}
+
+func (x *AskWithHistoryResponse) GetUpdatedMessages() []*Message {
+	if x != nil {
+		return x.UpdatedMessages
+	}
+	return nil
+}
+
+func (x *AskWithHistoryResponse) GetTokenUsage() *TokenUsage {
+	if x != nil {
+		return x.TokenUsage
+	}
+	return nil
+}
+
+func (x *AskWithHistoryResponse) GetDurationMs() int64 {
+	if x != nil {
+		return x.DurationMs
+	}
+	return 0
+}
+
+type CancelRequestRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	RequestId string                 `protobuf:"bytes,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	// Optional reason, reported as the error of the conversation_end event
+	Reason        string `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *TextChunkEvent) Reset() {
-	*x = TextChunkEvent{}
-	mi := &file_agent_proto_msgTypes[22]
+func (x *CancelRequestRequest) Reset() {
+	*x = CancelRequestRequest{}
+	mi := &file_agent_proto_msgTypes[60]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *TextChunkEvent) String() string {
+func (x *CancelRequestRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*TextChunkEvent) ProtoMessage() {}
+func (*CancelRequestRequest) ProtoMessage() {}
 
-func (x *TextChunkEvent) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_proto_msgTypes[22]
+func (x *CancelRequestRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[60]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1579,54 +4262,48 @@ func (x *TextChunkEvent) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use TextChunkEvent.ProtoReflect.Descriptor instead.
-func (*TextChunkEvent) Descriptor() ([]byte, []int) {
-	return file_agent_proto_rawDescGZIP(), []int{22}
+// Deprecated: Use CancelRequestRequest.ProtoReflect.Descriptor instead.
+func (*CancelRequestRequest) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{60}
 }
 
-func (x *TextChunkEvent) GetText() string {
+func (x *CancelRequestRequest) GetRequestId() string {
 	if x != nil {
-		return x.Text
+		return x.RequestId
 	}
 	return ""
 }
 
-func (x *TextChunkEvent) GetIsThinking() bool {
+func (x *CancelRequestRequest) GetReason() string {
 	if x != nil {
-		return x.IsThinking
+		return x.Reason
 	}
-	return false
+	return ""
 }
 
-type ToolCallEvent struct {
+type CancelRequestResponse struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
-	// Unique call ID for matching result
-	CallId string `protobuf:"bytes,1,opt,name=call_id,json=callId,proto3" json:"call_id,omitempty"`
-	// Tool name to execute
-	ToolName string `protobuf:"bytes,2,opt,name=tool_name,json=toolName,proto3" json:"tool_name,omitempty"`
-	// Tool arguments as JSON object
-	Arguments *structpb.Struct `protobuf:"bytes,3,opt,name=arguments,proto3" json:"arguments,omitempty"`
-	// Timeout for this tool call in milliseconds
-	TimeoutMs     int32 `protobuf:"varint,4,opt,name=timeout_ms,json=timeoutMs,proto3" json:"timeout_ms,omitempty"`
+	// False when no request with this ID is running (unknown or already finished)
+	Cancelled     bool `protobuf:"varint,1,opt,name=cancelled,proto3" json:"cancelled,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ToolCallEvent) Reset() {
-	*x = ToolCallEvent{}
-	mi := &file_agent_proto_msgTypes[23]
+func (x *CancelRequestResponse) Reset() {
+	*x = CancelRequestResponse{}
+	mi := &file_agent_proto_msgTypes[61]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ToolCallEvent) String() string {
+func (x *CancelRequestResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ToolCallEvent) ProtoMessage() {}
+func (*CancelRequestResponse) ProtoMessage() {}
 
-func (x *ToolCallEvent) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_proto_msgTypes[23]
+func (x *CancelRequestResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[61]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1637,68 +4314,76 @@ func (x *ToolCallEvent) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ToolCallEvent.ProtoReflect.Descriptor instead.
-func (*ToolCallEvent) Descriptor() ([]byte, []int) {
-	return file_agent_proto_rawDescGZIP(), []int{23}
+// Deprecated: Use CancelRequestResponse.ProtoReflect.Descriptor instead.
+func (*CancelRequestResponse) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{61}
 }
 
-func (x *ToolCallEvent) GetCallId() string {
+func (x *CancelRequestResponse) GetCancelled() bool {
 	if x != nil {
-		return x.CallId
+		return x.Cancelled
 	}
-	return ""
+	return false
 }
 
-func (x *ToolCallEvent) GetToolName() string {
-	if x != nil {
-		return x.ToolName
-	}
-	return ""
+type HealthCheckRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ToolCallEvent) GetArguments() *structpb.Struct {
-	if x != nil {
-		return x.Arguments
-	}
-	return nil
+func (x *HealthCheckRequest) Reset() {
+	*x = HealthCheckRequest{}
+	mi := &file_agent_proto_msgTypes[62]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-func (x *ToolCallEvent) GetTimeoutMs() int32 {
+func (x *HealthCheckRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HealthCheckRequest) ProtoMessage() {}
+
+func (x *HealthCheckRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[62]
 	if x != nil {
-		return x.TimeoutMs
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return 0
+	return mi.MessageOf(x)
 }
 
-type FinalResponse struct {
-	state protoimpl.MessageState `protogen:"open.v1"`
-	// Final response text
-	Response string `protobuf:"bytes,1,opt,name=response,proto3" json:"response,omitempty"`
-	// Updated conversation messages
-	UpdatedMessages []*Message `protobuf:"bytes,2,rep,name=updated_messages,json=updatedMessages,proto3" json:"updated_messages,omitempty"`
-	// Token usage for this conversation
-	TokenUsage *TokenUsage `protobuf:"bytes,3,opt,name=token_usage,json=tokenUsage,proto3" json:"token_usage,omitempty"`
-	// Total duration in milliseconds
-	DurationMs    int64 `protobuf:"varint,4,opt,name=duration_ms,json=durationMs,proto3" json:"duration_ms,omitempty"`
+// Deprecated: Use HealthCheckRequest.ProtoReflect.Descriptor instead.
+func (*HealthCheckRequest) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{62}
+}
+
+type HealthCheckResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *FinalResponse) Reset() {
-	*x = FinalResponse{}
-	mi := &file_agent_proto_msgTypes[24]
+func (x *HealthCheckResponse) Reset() {
+	*x = HealthCheckResponse{}
+	mi := &file_agent_proto_msgTypes[63]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *FinalResponse) String() string {
+func (x *HealthCheckResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*FinalResponse) ProtoMessage() {}
+func (*HealthCheckResponse) ProtoMessage() {}
 
-func (x *FinalResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_proto_msgTypes[24]
+func (x *HealthCheckResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[63]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1709,68 +4394,45 @@ func (x *FinalResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use FinalResponse.ProtoReflect.Descriptor instead.
-func (*FinalResponse) Descriptor() ([]byte, []int) {
-	return file_agent_proto_rawDescGZIP(), []int{24}
+// Deprecated: Use HealthCheckResponse.ProtoReflect.Descriptor instead.
+func (*HealthCheckResponse) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{63}
 }
 
-func (x *FinalResponse) GetResponse() string {
+func (x *HealthCheckResponse) GetStatus() string {
 	if x != nil {
-		return x.Response
+		return x.Status
 	}
 	return ""
 }
 
-func (x *FinalResponse) GetUpdatedMessages() []*Message {
-	if x != nil {
-		return x.UpdatedMessages
-	}
-	return nil
-}
-
-func (x *FinalResponse) GetTokenUsage() *TokenUsage {
-	if x != nil {
-		return x.TokenUsage
-	}
-	return nil
-}
-
-func (x *FinalResponse) GetDurationMs() int64 {
-	if x != nil {
-		return x.DurationMs
-	}
-	return 0
-}
-
-type ErrorEvent struct {
+type HealthCheckDetailedRequest struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
-	// Error code (e.g., AGENT_NOT_FOUND, ASK_FAILED)
-	Code string `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
-	// Human-readable error message
-	Message string `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
-	// Additional error details
-	Details *structpb.Struct `protobuf:"bytes,3,opt,name=details,proto3" json:"details,omitempty"`
-	// If true, the stream is terminated
-	Fatal         bool `protobuf:"varint,4,opt,name=fatal,proto3" json:"fatal,omitempty"`
+	// Optional: check only this agent's MCP servers (default all agents)
+	AgentId string `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	// Skip the MCP connection checks, which list every server's tools
+	SkipConnections bool `protobuf:"varint,2,opt,name=skip_connections,json=skipConnections,proto3" json:"skip_connections,omitempty"`
+	// Deadline of the connection checks of all agents (default 5000)
+	TimeoutMs     int64 `protobuf:"varint,3,opt,name=timeout_ms,json=timeoutMs,proto3" json:"timeout_ms,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ErrorEvent) Reset() {
-	*x = ErrorEvent{}
-	mi := &file_agent_proto_msgTypes[25]
+func (x *HealthCheckDetailedRequest) Reset() {
+	*x = HealthCheckDetailedRequest{}
+	mi := &file_agent_proto_msgTypes[64]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ErrorEvent) String() string {
+func (x *HealthCheckDetailedRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ErrorEvent) ProtoMessage() {}
+func (*HealthCheckDetailedRequest) ProtoMessage() {}
 
-func (x *ErrorEvent) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_proto_msgTypes[25]
+func (x *HealthCheckDetailedRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[64]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1781,80 +4443,63 @@ func (x *ErrorEvent) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ErrorEvent.ProtoReflect.Descriptor instead.
-func (*ErrorEvent) Descriptor() ([]byte, []int) {
-	return file_agent_proto_rawDescGZIP(), []int{25}
-}
-
-func (x *ErrorEvent) GetCode() string {
-	if x != nil {
-		return x.Code
-	}
-	return ""
+// Deprecated: Use HealthCheckDetailedRequest.ProtoReflect.Descriptor instead.
+func (*HealthCheckDetailedRequest) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{64}
 }
 
-func (x *ErrorEvent) GetMessage() string {
+func (x *HealthCheckDetailedRequest) GetAgentId() string {
 	if x != nil {
-		return x.Message
+		return x.AgentId
 	}
 	return ""
 }
 
-func (x *ErrorEvent) GetDetails() *structpb.Struct {
+func (x *HealthCheckDetailedRequest) GetSkipConnections() bool {
 	if x != nil {
-		return x.Details
+		return x.SkipConnections
 	}
-	return nil
+	return false
 }
 
-func (x *ErrorEvent) GetFatal() bool {
+func (x *HealthCheckDetailedRequest) GetTimeoutMs() int64 {
 	if x != nil {
-		return x.Fatal
+		return x.TimeoutMs
 	}
-	return false
+	return 0
 }
 
-type AgentEvent struct {
+type HealthCheckDetailedResponse struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
-	// Event type (e.g., "agent_start", "tool_call", "llm_request")
-	Type string `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
-	// Event timestamp
-	Timestamp *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
-	// Trace ID for distributed tracing
-	TraceId string `protobuf:"bytes,3,opt,name=trace_id,json=traceId,proto3" json:"trace_id,omitempty"`
-	// Span ID within the trace
-	SpanId string `protobuf:"bytes,4,opt,name=span_id,json=spanId,proto3" json:"span_id,omitempty"`
-	// Parent span ID
-	ParentId string `protobuf:"bytes,5,opt,name=parent_id,json=parentId,proto3" json:"parent_id,omitempty"`
-	// Correlation ID for related events
-	CorrelationId string `protobuf:"bytes,6,opt,name=correlation_id,json=correlationId,proto3" json:"correlation_id,omitempty"`
-	// Hierarchy level in the call stack
-	HierarchyLevel int32 `protobuf:"varint,7,opt,name=hierarchy_level,json=hierarchyLevel,proto3" json:"hierarchy_level,omitempty"`
-	// Session ID
-	SessionId string `protobuf:"bytes,8,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
-	// Component that emitted the event
-	Component string `protobuf:"bytes,9,opt,name=component,proto3" json:"component,omitempty"`
-	// Event-specific data as JSON object
-	Data          *structpb.Struct `protobuf:"bytes,10,opt,name=data,proto3" json:"data,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
-}
-
-func (x *AgentEvent) Reset() {
-	*x = AgentEvent{}
-	mi := &file_agent_proto_msgTypes[26]
+	// "ok", "degraded" (some MCP server unhealthy) or "not_ready"
+	Status string `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	Ready  bool   `protobuf:"varint,2,opt,name=ready,proto3" json:"ready,omitempty"`
+	// Readiness check name → "ok" or the failure reason, as served by /readyz
+	Checks              map[string]string `protobuf:"bytes,3,rep,name=checks,proto3" json:"checks,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Draining            bool              `protobuf:"varint,4,opt,name=draining,proto3" json:"draining,omitempty"`
+	ActiveAgents        int32             `protobuf:"varint,5,opt,name=active_agents,json=activeAgents,proto3" json:"active_agents,omitempty"`
+	ActiveConversations int32             `protobuf:"varint,6,opt,name=active_conversations,json=activeConversations,proto3" json:"active_conversations,omitempty"`
+	Agents              []*AgentHealth    `protobuf:"bytes,7,rep,name=agents,proto3" json:"agents,omitempty"`
+	Cache               *CacheStatus      `protobuf:"bytes,8,opt,name=cache,proto3" json:"cache,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *HealthCheckDetailedResponse) Reset() {
+	*x = HealthCheckDetailedResponse{}
+	mi := &file_agent_proto_msgTypes[65]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *AgentEvent) String() string {
+func (x *HealthCheckDetailedResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*AgentEvent) ProtoMessage() {}
+func (*HealthCheckDetailedResponse) ProtoMessage() {}
 
-func (x *AgentEvent) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_proto_msgTypes[26]
+func (x *HealthCheckDetailedResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[65]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1865,106 +4510,93 @@ func (x *AgentEvent) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use AgentEvent.ProtoReflect.Descriptor instead.
-func (*AgentEvent) Descriptor() ([]byte, []int) {
-	return file_agent_proto_rawDescGZIP(), []int{26}
-}
-
-func (x *AgentEvent) GetType() string {
-	if x != nil {
-		return x.Type
-	}
-	return ""
-}
-
-func (x *AgentEvent) GetTimestamp() *timestamppb.Timestamp {
-	if x != nil {
-		return x.Timestamp
-	}
-	return nil
+// Deprecated: Use HealthCheckDetailedResponse.ProtoReflect.Descriptor instead.
+func (*HealthCheckDetailedResponse) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{65}
 }
 
-func (x *AgentEvent) GetTraceId() string {
+func (x *HealthCheckDetailedResponse) GetStatus() string {
 	if x != nil {
-		return x.TraceId
+		return x.Status
 	}
 	return ""
 }
 
-func (x *AgentEvent) GetSpanId() string {
+func (x *HealthCheckDetailedResponse) GetReady() bool {
 	if x != nil {
-		return x.SpanId
+		return x.Ready
 	}
-	return ""
+	return false
 }
 
-func (x *AgentEvent) GetParentId() string {
+func (x *HealthCheckDetailedResponse) GetChecks() map[string]string {
 	if x != nil {
-		return x.ParentId
+		return x.Checks
 	}
-	return ""
+	return nil
 }
 
-func (x *AgentEvent) GetCorrelationId() string {
+func (x *HealthCheckDetailedResponse) GetDraining() bool {
 	if x != nil {
-		return x.CorrelationId
+		return x.Draining
 	}
-	return ""
+	return false
 }
 
-func (x *AgentEvent) GetHierarchyLevel() int32 {
+func (x *HealthCheckDetailedResponse) GetActiveAgents() int32 {
 	if x != nil {
-		return x.HierarchyLevel
+		return x.ActiveAgents
 	}
 	return 0
 }
 
-func (x *AgentEvent) GetSessionId() string {
+func (x *HealthCheckDetailedResponse) GetActiveConversations() int32 {
 	if x != nil {
-		return x.SessionId
+		return x.ActiveConversations
 	}
-	return ""
+	return 0
 }
 
-func (x *AgentEvent) GetComponent() string {
+func (x *HealthCheckDetailedResponse) GetAgents() []*AgentHealth {
 	if x != nil {
-		return x.Component
+		return x.Agents
 	}
-	return ""
+	return nil
 }
 
-func (x *AgentEvent) GetData() *structpb.Struct {
+func (x *HealthCheckDetailedResponse) GetCache() *CacheStatus {
 	if x != nil {
-		return x.Data
+		return x.Cache
 	}
 	return nil
 }
 
-type Message struct {
-	state protoimpl.MessageState `protogen:"open.v1"`
-	// Role: "user", "assistant", "system"
-	Role string `protobuf:"bytes,1,opt,name=role,proto3" json:"role,omitempty"`
-	// Message content
-	Content       string `protobuf:"bytes,2,opt,name=content,proto3" json:"content,omitempty"`
+// AgentHealth is the MCP connection health of one managed agent
+type AgentHealth struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	AgentId   string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	SessionId string                 `protobuf:"bytes,2,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	// Sorted by name
+	Servers       []*ServerHealth `protobuf:"bytes,3,rep,name=servers,proto3" json:"servers,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
-}
-
-func (x *Message) Reset() {
-	*x = Message{}
-	mi := &file_agent_proto_msgTypes[27]
+}
+
+func (x *AgentHealth) Reset() {
+	*x = AgentHealth{}
+	mi := &file_agent_proto_msgTypes[66]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *Message) String() string {
+func (x *AgentHealth) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Message) ProtoMessage() {}
+func (*AgentHealth) ProtoMessage() {}
 
-func (x *Message) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_proto_msgTypes[27]
+func (x *AgentHealth) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[66]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1975,48 +4607,56 @@ func (x *Message) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Message.ProtoReflect.Descriptor instead.
-func (*Message) Descriptor() ([]byte, []int) {
-	return file_agent_proto_rawDescGZIP(), []int{27}
+// Deprecated: Use AgentHealth.ProtoReflect.Descriptor instead.
+func (*AgentHealth) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{66}
 }
 
-func (x *Message) GetRole() string {
+func (x *AgentHealth) GetAgentId() string {
 	if x != nil {
-		return x.Role
+		return x.AgentId
 	}
 	return ""
 }
 
-func (x *Message) GetContent() string {
+func (x *AgentHealth) GetSessionId() string {
 	if x != nil {
-		return x.Content
+		return x.SessionId
 	}
 	return ""
 }
 
-type AskRequest struct {
+func (x *AgentHealth) GetServers() []*ServerHealth {
+	if x != nil {
+		return x.Servers
+	}
+	return nil
+}
+
+type ServerHealth struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
-	Question      string                 `protobuf:"bytes,2,opt,name=question,proto3" json:"question,omitempty"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Healthy       bool                   `protobuf:"varint,2,opt,name=healthy,proto3" json:"healthy,omitempty"`
+	Error         string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *AskRequest) Reset() {
-	*x = AskRequest{}
-	mi := &file_agent_proto_msgTypes[28]
+func (x *ServerHealth) Reset() {
+	*x = ServerHealth{}
+	mi := &file_agent_proto_msgTypes[67]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *AskRequest) String() string {
+func (x *ServerHealth) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*AskRequest) ProtoMessage() {}
+func (*ServerHealth) ProtoMessage() {}
 
-func (x *AskRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_proto_msgTypes[28]
+func (x *ServerHealth) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[67]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2027,49 +4667,59 @@ func (x *AskRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use AskRequest.ProtoReflect.Descriptor instead.
-func (*AskRequest) Descriptor() ([]byte, []int) {
-	return file_agent_proto_rawDescGZIP(), []int{28}
+// Deprecated: Use ServerHealth.ProtoReflect.Descriptor instead.
+func (*ServerHealth) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{67}
 }
 
-func (x *AskRequest) GetAgentId() string {
+func (x *ServerHealth) GetName() string {
 	if x != nil {
-		return x.AgentId
+		return x.Name
 	}
 	return ""
 }
 
-func (x *AskRequest) GetQuestion() string {
+func (x *ServerHealth) GetHealthy() bool {
 	if x != nil {
-		return x.Question
+		return x.Healthy
+	}
+	return false
+}
+
+func (x *ServerHealth) GetError() string {
+	if x != nil {
+		return x.Error
 	}
 	return ""
 }
 
-type AskResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Response      string                 `protobuf:"bytes,1,opt,name=response,proto3" json:"response,omitempty"`
-	TokenUsage    *TokenUsage            `protobuf:"bytes,2,opt,name=token_usage,json=tokenUsage,proto3" json:"token_usage,omitempty"`
-	DurationMs    int64                  `protobuf:"varint,3,opt,name=duration_ms,json=durationMs,proto3" json:"duration_ms,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+// CacheStatus summarizes the MCP server metadata cache
+type CacheStatus struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	TotalEntries   int32                  `protobuf:"varint,1,opt,name=total_entries,json=totalEntries,proto3" json:"total_entries,omitempty"`
+	ValidEntries   int32                  `protobuf:"varint,2,opt,name=valid_entries,json=validEntries,proto3" json:"valid_entries,omitempty"`
+	ExpiredEntries int32                  `protobuf:"varint,3,opt,name=expired_entries,json=expiredEntries,proto3" json:"expired_entries,omitempty"`
+	TtlMinutes     int32                  `protobuf:"varint,4,opt,name=ttl_minutes,json=ttlMinutes,proto3" json:"ttl_minutes,omitempty"`
+	Directory      string                 `protobuf:"bytes,5,opt,name=directory,proto3" json:"directory,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
 }
 
-func (x *AskResponse) Reset() {
-	*x = AskResponse{}
-	mi := &file_agent_proto_msgTypes[29]
+func (x *CacheStatus) Reset() {
+	*x = CacheStatus{}
+	mi := &file_agent_proto_msgTypes[68]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *AskResponse) String() string {
+func (x *CacheStatus) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*AskResponse) ProtoMessage() {}
+func (*CacheStatus) ProtoMessage() {}
 
-func (x *AskResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_proto_msgTypes[29]
+func (x *CacheStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[68]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2080,55 +4730,67 @@ func (x *AskResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use AskResponse.ProtoReflect.Descriptor instead.
-func (*AskResponse) Descriptor() ([]byte, []int) {
-	return file_agent_proto_rawDescGZIP(), []int{29}
+// Deprecated: Use CacheStatus.ProtoReflect.Descriptor instead.
+func (*CacheStatus) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{68}
 }
 
-func (x *AskResponse) GetResponse() string {
+func (x *CacheStatus) GetTotalEntries() int32 {
 	if x != nil {
-		return x.Response
+		return x.TotalEntries
 	}
-	return ""
+	return 0
 }
 
-func (x *AskResponse) GetTokenUsage() *TokenUsage {
+func (x *CacheStatus) GetValidEntries() int32 {
 	if x != nil {
-		return x.TokenUsage
+		return x.ValidEntries
 	}
-	return nil
+	return 0
 }
 
-func (x *AskResponse) GetDurationMs() int64 {
+func (x *CacheStatus) GetExpiredEntries() int32 {
 	if x != nil {
-		return x.DurationMs
+		return x.ExpiredEntries
 	}
 	return 0
 }
 
-type AskWithHistoryRequest struct {
+func (x *CacheStatus) GetTtlMinutes() int32 {
+	if x != nil {
+		return x.TtlMinutes
+	}
+	return 0
+}
+
+func (x *CacheStatus) GetDirectory() string {
+	if x != nil {
+		return x.Directory
+	}
+	return ""
+}
+
+type ListRecoverableConversationsRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
-	Messages      []*Message             `protobuf:"bytes,2,rep,name=messages,proto3" json:"messages,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *AskWithHistoryRequest) Reset() {
-	*x = AskWithHistoryRequest{}
-	mi := &file_agent_proto_msgTypes[30]
+func (x *ListRecoverableConversationsRequest) Reset() {
+	*x = ListRecoverableConversationsRequest{}
+	mi := &file_agent_proto_msgTypes[69]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *AskWithHistoryRequest) String() string {
+func (x *ListRecoverableConversationsRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*AskWithHistoryRequest) ProtoMessage() {}
+func (*ListRecoverableConversationsRequest) ProtoMessage() {}
 
-func (x *AskWithHistoryRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_proto_msgTypes[30]
+func (x *ListRecoverableConversationsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[69]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2139,50 +4801,33 @@ func (x *AskWithHistoryRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use AskWithHistoryRequest.ProtoReflect.Descriptor instead.
-func (*AskWithHistoryRequest) Descriptor() ([]byte, []int) {
-	return file_agent_proto_rawDescGZIP(), []int{30}
-}
-
-func (x *AskWithHistoryRequest) GetAgentId() string {
-	if x != nil {
-		return x.AgentId
-	}
-	return ""
-}
-
-func (x *AskWithHistoryRequest) GetMessages() []*Message {
-	if x != nil {
-		return x.Messages
-	}
-	return nil
+// Deprecated: Use ListRecoverableConversationsRequest.ProtoReflect.Descriptor instead.
+func (*ListRecoverableConversationsRequest) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{69}
 }
 
-type AskWithHistoryResponse struct {
-	state           protoimpl.MessageState `protogen:"open.v1"`
-	Response        string                 `protobuf:"bytes,1,opt,name=response,proto3" json:"response,omitempty"`
-	UpdatedMessages []*Message             `protobuf:"bytes,2,rep,name=updated_messages,json=updatedMessages,proto3" json:"updated_messages,omitempty"`
-	TokenUsage      *TokenUsage            `protobuf:"bytes,3,opt,name=token_usage,json=tokenUsage,proto3" json:"token_usage,omitempty"`
-	DurationMs      int64                  `protobuf:"varint,4,opt,name=duration_ms,json=durationMs,proto3" json:"duration_ms,omitempty"`
-	unknownFields   protoimpl.UnknownFields
-	sizeCache       protoimpl.SizeCache
+type ListRecoverableConversationsResponse struct {
+	state         protoimpl.MessageState     `protogen:"open.v1"`
+	Conversations []*RecoverableConversation `protobuf:"bytes,1,rep,name=conversations,proto3" json:"conversations,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *AskWithHistoryResponse) Reset() {
-	*x = AskWithHistoryResponse{}
-	mi := &file_agent_proto_msgTypes[31]
+func (x *ListRecoverableConversationsResponse) Reset() {
+	*x = ListRecoverableConversationsResponse{}
+	mi := &file_agent_proto_msgTypes[70]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *AskWithHistoryResponse) String() string {
+func (x *ListRecoverableConversationsResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*AskWithHistoryResponse) ProtoMessage() {}
+func (*ListRecoverableConversationsResponse) ProtoMessage() {}
 
-func (x *AskWithHistoryResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_proto_msgTypes[31]
+func (x *ListRecoverableConversationsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[70]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2193,60 +4838,54 @@ func (x *AskWithHistoryResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use AskWithHistoryResponse.ProtoReflect.Descriptor instead.
-func (*AskWithHistoryResponse) Descriptor() ([]byte, []int) {
-	return file_agent_proto_rawDescGZIP(), []int{31}
-}
-
-func (x *AskWithHistoryResponse) GetResponse() string {
-	if x != nil {
-		return x.Response
-	}
-	return ""
-}
-
-func (x *AskWithHistoryResponse) GetUpdatedMessages() []*Message {
-	if x != nil {
-		return x.UpdatedMessages
-	}
-	return nil
+// Deprecated: Use ListRecoverableConversationsResponse.ProtoReflect.Descriptor instead.
+func (*ListRecoverableConversationsResponse) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{70}
 }
 
-func (x *AskWithHistoryResponse) GetTokenUsage() *TokenUsage {
+func (x *ListRecoverableConversationsResponse) GetConversations() []*RecoverableConversation {
 	if x != nil {
-		return x.TokenUsage
+		return x.Conversations
 	}
 	return nil
 }
 
-func (x *AskWithHistoryResponse) GetDurationMs() int64 {
-	if x != nil {
-		return x.DurationMs
-	}
-	return 0
-}
-
-type HealthCheckRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
+type RecoverableConversation struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Checkpoint ID
+	Id        string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	SessionId string `protobuf:"bytes,2,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	UserId    string `protobuf:"bytes,3,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Provider  string `protobuf:"bytes,4,opt,name=provider,proto3" json:"provider,omitempty"`
+	ModelId   string `protobuf:"bytes,5,opt,name=model_id,json=modelId,proto3" json:"model_id,omitempty"`
+	// Latest user message
+	Question string `protobuf:"bytes,6,opt,name=question,proto3" json:"question,omitempty"`
+	// Turns completed when the checkpoint was taken
+	Turn int32 `protobuf:"varint,7,opt,name=turn,proto3" json:"turn,omitempty"`
+	// Error that ended the conversation, empty if the process stopped mid-run
+	LastError string                 `protobuf:"bytes,8,opt,name=last_error,json=lastError,proto3" json:"last_error,omitempty"`
+	UpdatedAt *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	// Conversation history at the checkpoint
+	Messages      []*Message `protobuf:"bytes,10,rep,name=messages,proto3" json:"messages,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *HealthCheckRequest) Reset() {
-	*x = HealthCheckRequest{}
-	mi := &file_agent_proto_msgTypes[32]
+func (x *RecoverableConversation) Reset() {
+	*x = RecoverableConversation{}
+	mi := &file_agent_proto_msgTypes[71]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *HealthCheckRequest) String() string {
+func (x *RecoverableConversation) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*HealthCheckRequest) ProtoMessage() {}
+func (*RecoverableConversation) ProtoMessage() {}
 
-func (x *HealthCheckRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_proto_msgTypes[32]
+func (x *RecoverableConversation) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[71]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2257,64 +4896,91 @@ func (x *HealthCheckRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use HealthCheckRequest.ProtoReflect.Descriptor instead.
-func (*HealthCheckRequest) Descriptor() ([]byte, []int) {
-	return file_agent_proto_rawDescGZIP(), []int{32}
+// Deprecated: Use RecoverableConversation.ProtoReflect.Descriptor instead.
+func (*RecoverableConversation) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{71}
 }
 
-type HealthCheckResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+func (x *RecoverableConversation) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
 }
 
-func (x *HealthCheckResponse) Reset() {
-	*x = HealthCheckResponse{}
-	mi := &file_agent_proto_msgTypes[33]
-	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-	ms.StoreMessageInfo(mi)
+func (x *RecoverableConversation) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
 }
 
-func (x *HealthCheckResponse) String() string {
-	return protoimpl.X.MessageStringOf(x)
+func (x *RecoverableConversation) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
 }
 
-func (*HealthCheckResponse) ProtoMessage() {}
+func (x *RecoverableConversation) GetProvider() string {
+	if x != nil {
+		return x.Provider
+	}
+	return ""
+}
 
-func (x *HealthCheckResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_proto_msgTypes[33]
+func (x *RecoverableConversation) GetModelId() string {
 	if x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
-		}
-		return ms
+		return x.ModelId
 	}
-	return mi.MessageOf(x)
+	return ""
 }
 
-// Deprecated: Use HealthCheckResponse.ProtoReflect.Descriptor instead.
-func (*HealthCheckResponse) Descriptor() ([]byte, []int) {
-	return file_agent_proto_rawDescGZIP(), []int{33}
+func (x *RecoverableConversation) GetQuestion() string {
+	if x != nil {
+		return x.Question
+	}
+	return ""
 }
 
-func (x *HealthCheckResponse) GetStatus() string {
+func (x *RecoverableConversation) GetTurn() int32 {
 	if x != nil {
-		return x.Status
+		return x.Turn
+	}
+	return 0
+}
+
+func (x *RecoverableConversation) GetLastError() string {
+	if x != nil {
+		return x.LastError
 	}
 	return ""
 }
 
+func (x *RecoverableConversation) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+func (x *RecoverableConversation) GetMessages() []*Message {
+	if x != nil {
+		return x.Messages
+	}
+	return nil
+}
+
 var File_agent_proto protoreflect.FileDescriptor
 
 const file_agent_proto_rawDesc = "" +
 	"\n" +
-	"\vagent.proto\x12\vmcpagent.v1\x1a\x1fgoogle/protobuf/timestamp.proto\x1a\x1cgoogle/protobuf/struct.proto\"e\n" +
+	"\vagent.proto\x12\vmcpagent.v1\x1a\x1fgoogle/protobuf/timestamp.proto\x1a\x1cgoogle/protobuf/struct.proto\"\x82\x01\n" +
 	"\x12CreateAgentRequest\x12\x1d\n" +
 	"\n" +
 	"session_id\x18\x01 \x01(\tR\tsessionId\x120\n" +
-	"\x06config\x18\x02 \x01(\v2\x18.mcpagent.v1.AgentConfigR\x06config\"\x91\x04\n" +
+	"\x06config\x18\x02 \x01(\v2\x18.mcpagent.v1.AgentConfigR\x06config\x12\x1b\n" +
+	"\twarm_pool\x18\x03 \x01(\tR\bwarmPool\"\xd9\x04\n" +
 	"\vAgentConfig\x12\x1a\n" +
 	"\bprovider\x18\x01 \x01(\tR\bprovider\x12\x19\n" +
 	"\bmodel_id\x18\x02 \x01(\tR\amodelId\x12 \n" +
@@ -2328,7 +4994,12 @@ const file_agent_proto_rawDesc = "" +
 	"\x19enable_context_offloading\x18\n" +
 	" \x01(\bR\x17enableContextOffloading\x12)\n" +
 	"\x10enable_streaming\x18\v \x01(\bR\x0fenableStreaming\x12D\n" +
-	"\fcustom_tools\x18\f \x03(\v2!.mcpagent.v1.CustomToolDefinitionR\vcustomTools\"\xc0\x01\n" +
+	"\fcustom_tools\x18\f \x03(\v2!.mcpagent.v1.CustomToolDefinitionR\vcustomTools\x12F\n" +
+	"\x10tool_permissions\x18\r \x03(\v2\x1b.mcpagent.v1.ToolPermissionR\x0ftoolPermissions\"V\n" +
+	"\x0eToolPermission\x12\x12\n" +
+	"\x04tool\x18\x01 \x01(\tR\x04tool\x12\x1a\n" +
+	"\bdisabled\x18\x02 \x01(\bR\bdisabled\x12\x14\n" +
+	"\x05paths\x18\x03 \x03(\tR\x05paths\"\xc0\x01\n" +
 	"\x14CustomToolDefinition\x12\x12\n" +
 	"\x04name\x18\x01 \x01(\tR\x04name\x12 \n" +
 	"\vdescription\x18\x02 \x01(\tR\vdescription\x127\n" +
@@ -2337,7 +5008,16 @@ const file_agent_proto_rawDesc = "" +
 	"parameters\x12\x1d\n" +
 	"\n" +
 	"timeout_ms\x18\x04 \x01(\x05R\ttimeoutMs\x12\x1a\n" +
-	"\bcategory\x18\x05 \x01(\tR\bcategory\"\xe1\x01\n" +
+	"\bcategory\x18\x05 \x01(\tR\bcategory\"\x9d\x02\n" +
+	"\x1cCreateAgentFromPresetRequest\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\x12\x16\n" +
+	"\x06preset\x18\x02 \x01(\tR\x06preset\x12V\n" +
+	"\tvariables\x18\x03 \x03(\v28.mcpagent.v1.CreateAgentFromPresetRequest.VariablesEntryR\tvariables\x120\n" +
+	"\x06config\x18\x04 \x01(\v2\x18.mcpagent.v1.AgentConfigR\x06config\x1a<\n" +
+	"\x0eVariablesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xe1\x01\n" +
 	"\x13CreateAgentResponse\x12\x19\n" +
 	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x1d\n" +
 	"\n" +
@@ -2350,7 +5030,7 @@ const file_agent_proto_rawDesc = "" +
 	"\x05tools\x18\x01 \x03(\tR\x05tools\x12\x18\n" +
 	"\aservers\x18\x02 \x03(\tR\aservers\",\n" +
 	"\x0fGetAgentRequest\x12\x19\n" +
-	"\bagent_id\x18\x01 \x01(\tR\aagentId\"\x98\x02\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\"\xaa\x03\n" +
 	"\x10GetAgentResponse\x12\x19\n" +
 	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x1d\n" +
 	"\n" +
@@ -2360,17 +5040,28 @@ const file_agent_proto_rawDesc = "" +
 	"created_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x12=\n" +
 	"\fcapabilities\x18\x05 \x01(\v2\x19.mcpagent.v1.CapabilitiesR\fcapabilities\x128\n" +
 	"\vtoken_usage\x18\x06 \x01(\v2\x17.mcpagent.v1.TokenUsageR\n" +
-	"tokenUsage\"\x13\n" +
+	"tokenUsage\x12+\n" +
+	"\x05tools\x18\a \x03(\v2\x15.mcpagent.v1.ToolInfoR\x05tools\x123\n" +
+	"\afilters\x18\b \x01(\v2\x19.mcpagent.v1.AgentFiltersR\afilters\x12.\n" +
+	"\x13code_execution_mode\x18\t \x01(\bR\x11codeExecutionMode\"`\n" +
+	"\fAgentFilters\x12)\n" +
+	"\x10selected_servers\x18\x01 \x03(\tR\x0fselectedServers\x12%\n" +
+	"\x0eselected_tools\x18\x02 \x03(\tR\rselectedTools\"\x13\n" +
 	"\x11ListAgentsRequest\"G\n" +
 	"\x12ListAgentsResponse\x121\n" +
-	"\x06agents\x18\x01 \x03(\v2\x19.mcpagent.v1.AgentSummaryR\x06agents\"\x9b\x01\n" +
+	"\x06agents\x18\x01 \x03(\v2\x19.mcpagent.v1.AgentSummaryR\x06agents\"\xe7\x02\n" +
 	"\fAgentSummary\x12\x19\n" +
 	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x1d\n" +
 	"\n" +
 	"session_id\x18\x02 \x01(\tR\tsessionId\x12\x16\n" +
 	"\x06status\x18\x03 \x01(\tR\x06status\x129\n" +
 	"\n" +
-	"created_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"0\n" +
+	"created_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x128\n" +
+	"\vtoken_usage\x18\x05 \x01(\v2\x17.mcpagent.v1.TokenUsageR\n" +
+	"tokenUsage\x12+\n" +
+	"\x05tools\x18\x06 \x03(\v2\x15.mcpagent.v1.ToolInfoR\x05tools\x123\n" +
+	"\afilters\x18\a \x01(\v2\x19.mcpagent.v1.AgentFiltersR\afilters\x12.\n" +
+	"\x13code_execution_mode\x18\b \x01(\bR\x11codeExecutionMode\"0\n" +
 	"\x13DestroyAgentRequest\x12\x19\n" +
 	"\bagent_id\x18\x01 \x01(\tR\aagentId\"O\n" +
 	"\x14DestroyAgentResponse\x12\x19\n" +
@@ -2399,17 +5090,96 @@ const file_agent_proto_rawDesc = "" +
 	"\x12TokenUsageResponse\x128\n" +
 	"\vtoken_usage\x18\x01 \x01(\v2\x17.mcpagent.v1.TokenUsageR\n" +
 	"tokenUsage\x12(\n" +
-	"\x05costs\x18\x02 \x01(\v2\x12.mcpagent.v1.CostsR\x05costs\"\xf0\x01\n" +
+	"\x05costs\x18\x02 \x01(\v2\x12.mcpagent.v1.CostsR\x05costs\"7\n" +
+	"\x1aGetPostMortemBundleRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\"\x9a\x01\n" +
+	"\x1bGetPostMortemBundleResponse\x12\x16\n" +
+	"\x06bundle\x18\x01 \x01(\fR\x06bundle\x12\x12\n" +
+	"\x04path\x18\x02 \x01(\tR\x04path\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\x129\n" +
+	"\n" +
+	"created_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"0\n" +
+	"\x13GetToolStatsRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\"\x94\x02\n" +
+	"\rToolCallStats\x12\x14\n" +
+	"\x05calls\x18\x01 \x01(\x05R\x05calls\x12\x16\n" +
+	"\x06errors\x18\x02 \x01(\x05R\x06errors\x12\x1d\n" +
+	"\n" +
+	"error_rate\x18\x03 \x01(\x01R\terrorRate\x12\x1d\n" +
+	"\n" +
+	"cache_hits\x18\x04 \x01(\x05R\tcacheHits\x12$\n" +
+	"\x0ep50_latency_ms\x18\x05 \x01(\x03R\fp50LatencyMs\x12$\n" +
+	"\x0ep95_latency_ms\x18\x06 \x01(\x03R\fp95LatencyMs\x12$\n" +
+	"\x0emax_latency_ms\x18\a \x01(\x03R\fmaxLatencyMs\x12%\n" +
+	"\x0ebytes_returned\x18\b \x01(\x03R\rbytesReturned\"{\n" +
+	"\tToolStats\x12\x1b\n" +
+	"\ttool_name\x18\x01 \x01(\tR\btoolName\x12\x1f\n" +
+	"\vserver_name\x18\x02 \x01(\tR\n" +
+	"serverName\x120\n" +
+	"\x05stats\x18\x03 \x01(\v2\x1a.mcpagent.v1.ToolCallStatsR\x05stats\"d\n" +
+	"\x0fServerToolStats\x12\x1f\n" +
+	"\vserver_name\x18\x01 \x01(\tR\n" +
+	"serverName\x120\n" +
+	"\x05stats\x18\x02 \x01(\v2\x1a.mcpagent.v1.ToolCallStatsR\x05stats\"|\n" +
+	"\x14GetToolStatsResponse\x12,\n" +
+	"\x05tools\x18\x01 \x03(\v2\x16.mcpagent.v1.ToolStatsR\x05tools\x126\n" +
+	"\aservers\x18\x02 \x03(\v2\x1c.mcpagent.v1.ServerToolStatsR\aservers\"E\n" +
+	"\x10ListToolsRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x16\n" +
+	"\x06server\x18\x02 \x01(\tR\x06server\"@\n" +
+	"\x11ListToolsResponse\x12+\n" +
+	"\x05tools\x18\x01 \x03(\v2\x15.mcpagent.v1.ToolInfoR\x05tools\"l\n" +
+	"\bToolInfo\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12 \n" +
+	"\vdescription\x18\x02 \x01(\tR\vdescription\x12\x16\n" +
+	"\x06server\x18\x03 \x01(\tR\x06server\x12\x12\n" +
+	"\x04type\x18\x04 \x01(\tR\x04type\"N\n" +
+	"\x14GetToolSchemaRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x1b\n" +
+	"\ttool_name\x18\x02 \x01(\tR\btoolName\"~\n" +
+	"\x15GetToolSchemaResponse\x12)\n" +
+	"\x04tool\x18\x01 \x01(\v2\x15.mcpagent.v1.ToolInfoR\x04tool\x12:\n" +
+	"\finput_schema\x18\x02 \x01(\v2\x17.google.protobuf.StructR\vinputSchema\"/\n" +
+	"\x12ListServersRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\"H\n" +
+	"\x13ListServersResponse\x121\n" +
+	"\aservers\x18\x01 \x03(\v2\x17.mcpagent.v1.ServerInfoR\aservers\"\xed\x01\n" +
+	"\n" +
+	"ServerInfo\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12 \n" +
+	"\vdescription\x18\x02 \x01(\tR\vdescription\x12\x1c\n" +
+	"\tconnected\x18\x03 \x01(\bR\tconnected\x12\x1d\n" +
+	"\n" +
+	"tool_count\x18\x04 \x01(\x05R\ttoolCount\x12!\n" +
+	"\fprompt_count\x18\x05 \x01(\x05R\vpromptCount\x12%\n" +
+	"\x0eresource_count\x18\x06 \x01(\x05R\rresourceCount\x12\"\n" +
+	"\finstructions\x18\a \x01(\tR\finstructions\"G\n" +
+	"\x12ListPromptsRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x16\n" +
+	"\x06server\x18\x02 \x01(\tR\x06server\"H\n" +
+	"\x13ListPromptsResponse\x121\n" +
+	"\aprompts\x18\x01 \x03(\v2\x17.mcpagent.v1.PromptInfoR\aprompts\"\x95\x01\n" +
+	"\n" +
+	"PromptInfo\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12 \n" +
+	"\vdescription\x18\x02 \x01(\tR\vdescription\x12\x16\n" +
+	"\x06server\x18\x03 \x01(\tR\x06server\x129\n" +
+	"\targuments\x18\x04 \x03(\v2\x1b.mcpagent.v1.PromptArgumentR\targuments\"b\n" +
+	"\x0ePromptArgument\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12 \n" +
+	"\vdescription\x18\x02 \x01(\tR\vdescription\x12\x1a\n" +
+	"\brequired\x18\x03 \x01(\bR\brequired\"\xf0\x01\n" +
 	"\x13ConversationRequest\x12\x19\n" +
 	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12:\n" +
 	"\bquestion\x18\x02 \x01(\v2\x1c.mcpagent.v1.QuestionMessageH\x00R\bquestion\x12A\n" +
 	"\vtool_result\x18\x03 \x01(\v2\x1e.mcpagent.v1.ToolResultMessageH\x00R\n" +
 	"toolResult\x124\n" +
 	"\x06cancel\x18\x04 \x01(\v2\x1a.mcpagent.v1.CancelMessageH\x00R\x06cancelB\t\n" +
-	"\apayload\"U\n" +
+	"\apayload\"q\n" +
 	"\x0fQuestionMessage\x12\x12\n" +
 	"\x04text\x18\x01 \x01(\tR\x04text\x12.\n" +
-	"\ahistory\x18\x02 \x03(\v2\x14.mcpagent.v1.MessageR\ahistory\"\xad\x01\n" +
+	"\ahistory\x18\x02 \x03(\v2\x14.mcpagent.v1.MessageR\ahistory\x12\x1a\n" +
+	"\bpriority\x18\x03 \x01(\tR\bpriority\"\xad\x01\n" +
 	"\x11ToolResultMessage\x12\x17\n" +
 	"\acall_id\x18\x01 \x01(\tR\x06callId\x12\x18\n" +
 	"\asuccess\x18\x02 \x01(\bR\asuccess\x12\x16\n" +
@@ -2441,20 +5211,21 @@ const file_agent_proto_rawDesc = "" +
 	"\ttool_name\x18\x02 \x01(\tR\btoolName\x125\n" +
 	"\targuments\x18\x03 \x01(\v2\x17.google.protobuf.StructR\targuments\x12\x1d\n" +
 	"\n" +
-	"timeout_ms\x18\x04 \x01(\x05R\ttimeoutMs\"\xc7\x01\n" +
+	"timeout_ms\x18\x04 \x01(\x05R\ttimeoutMs\"\xfc\x01\n" +
 	"\rFinalResponse\x12\x1a\n" +
 	"\bresponse\x18\x01 \x01(\tR\bresponse\x12?\n" +
 	"\x10updated_messages\x18\x02 \x03(\v2\x14.mcpagent.v1.MessageR\x0fupdatedMessages\x128\n" +
 	"\vtoken_usage\x18\x03 \x01(\v2\x17.mcpagent.v1.TokenUsageR\n" +
 	"tokenUsage\x12\x1f\n" +
 	"\vduration_ms\x18\x04 \x01(\x03R\n" +
-	"durationMs\"\x83\x01\n" +
+	"durationMs\x123\n" +
+	"\tartifacts\x18\x05 \x03(\v2\x15.mcpagent.v1.ArtifactR\tartifacts\"\x83\x01\n" +
 	"\n" +
 	"ErrorEvent\x12\x12\n" +
 	"\x04code\x18\x01 \x01(\tR\x04code\x12\x18\n" +
 	"\amessage\x18\x02 \x01(\tR\amessage\x121\n" +
 	"\adetails\x18\x03 \x01(\v2\x17.google.protobuf.StructR\adetails\x12\x14\n" +
-	"\x05fatal\x18\x04 \x01(\bR\x05fatal\"\xe5\x02\n" +
+	"\x05fatal\x18\x04 \x01(\bR\x05fatal\"\x9a\x03\n" +
 	"\n" +
 	"AgentEvent\x12\x12\n" +
 	"\x04type\x18\x01 \x01(\tR\x04type\x128\n" +
@@ -2468,44 +5239,165 @@ const file_agent_proto_rawDesc = "" +
 	"session_id\x18\b \x01(\tR\tsessionId\x12\x1c\n" +
 	"\tcomponent\x18\t \x01(\tR\tcomponent\x12+\n" +
 	"\x04data\x18\n" +
-	" \x01(\v2\x17.google.protobuf.StructR\x04data\"7\n" +
+	" \x01(\v2\x17.google.protobuf.StructR\x04data\x123\n" +
+	"\tartifacts\x18\v \x03(\v2\x15.mcpagent.v1.ArtifactR\tartifacts\"0\n" +
+	"\bArtifact\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12\x10\n" +
+	"\x03url\x18\x02 \x01(\tR\x03url\"k\n" +
+	"\x18WatchConversationRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x16\n" +
+	"\x06replay\x18\x02 \x01(\bR\x06replay\x12\x1c\n" +
+	"\tverbosity\x18\x03 \x01(\tR\tverbosity\"\xdb\x01\n" +
+	"\x10AskStreamRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x1a\n" +
+	"\bquestion\x18\x02 \x01(\tR\bquestion\x12.\n" +
+	"\ahistory\x18\x03 \x03(\v2\x14.mcpagent.v1.MessageR\ahistory\x12%\n" +
+	"\x0einclude_events\x18\x04 \x01(\bR\rincludeEvents\x12\x1a\n" +
+	"\bpriority\x18\x05 \x01(\tR\bpriority\x12\x1d\n" +
+	"\n" +
+	"request_id\x18\x06 \x01(\tR\trequestId\"\x94\x03\n" +
+	"\x11AskStreamResponse\x12<\n" +
+	"\n" +
+	"text_chunk\x18\x01 \x01(\v2\x1b.mcpagent.v1.TextChunkEventH\x00R\ttextChunk\x12D\n" +
+	"\x0ftool_call_start\x18\x02 \x01(\v2\x1a.mcpagent.v1.ToolCallStartH\x00R\rtoolCallStart\x12>\n" +
+	"\rtool_call_end\x18\x03 \x01(\v2\x18.mcpagent.v1.ToolCallEndH\x00R\vtoolCallEnd\x12C\n" +
+	"\x0efinal_response\x18\x04 \x01(\v2\x1a.mcpagent.v1.FinalResponseH\x00R\rfinalResponse\x12/\n" +
+	"\x05error\x18\x05 \x01(\v2\x17.mcpagent.v1.ErrorEventH\x00R\x05error\x12:\n" +
+	"\vagent_event\x18\x06 \x01(\v2\x17.mcpagent.v1.AgentEventH\x00R\n" +
+	"agentEventB\t\n" +
+	"\apayload\"\x98\x01\n" +
+	"\rToolCallStart\x12\x17\n" +
+	"\acall_id\x18\x01 \x01(\tR\x06callId\x12\x1b\n" +
+	"\ttool_name\x18\x02 \x01(\tR\btoolName\x12\x1f\n" +
+	"\vserver_name\x18\x03 \x01(\tR\n" +
+	"serverName\x12\x1c\n" +
+	"\targuments\x18\x04 \x01(\tR\targuments\x12\x12\n" +
+	"\x04turn\x18\x05 \x01(\x05R\x04turn\"\x80\x02\n" +
+	"\vToolCallEnd\x12\x17\n" +
+	"\acall_id\x18\x01 \x01(\tR\x06callId\x12\x1b\n" +
+	"\ttool_name\x18\x02 \x01(\tR\btoolName\x12\x1f\n" +
+	"\vserver_name\x18\x03 \x01(\tR\n" +
+	"serverName\x12\x18\n" +
+	"\asuccess\x18\x04 \x01(\bR\asuccess\x12\x16\n" +
+	"\x06result\x18\x05 \x01(\tR\x06result\x12\x14\n" +
+	"\x05error\x18\x06 \x01(\tR\x05error\x12\x1f\n" +
+	"\vduration_ms\x18\a \x01(\x03R\n" +
+	"durationMs\x12\x12\n" +
+	"\x04turn\x18\b \x01(\x05R\x04turn\x12\x1d\n" +
+	"\n" +
+	"from_cache\x18\t \x01(\bR\tfromCache\"7\n" +
 	"\aMessage\x12\x12\n" +
 	"\x04role\x18\x01 \x01(\tR\x04role\x12\x18\n" +
-	"\acontent\x18\x02 \x01(\tR\acontent\"C\n" +
+	"\acontent\x18\x02 \x01(\tR\acontent\"~\n" +
 	"\n" +
 	"AskRequest\x12\x19\n" +
 	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x1a\n" +
-	"\bquestion\x18\x02 \x01(\tR\bquestion\"\x84\x01\n" +
+	"\bquestion\x18\x02 \x01(\tR\bquestion\x12\x1a\n" +
+	"\bpriority\x18\x03 \x01(\tR\bpriority\x12\x1d\n" +
+	"\n" +
+	"request_id\x18\x04 \x01(\tR\trequestId\"\x84\x01\n" +
 	"\vAskResponse\x12\x1a\n" +
 	"\bresponse\x18\x01 \x01(\tR\bresponse\x128\n" +
 	"\vtoken_usage\x18\x02 \x01(\v2\x17.mcpagent.v1.TokenUsageR\n" +
 	"tokenUsage\x12\x1f\n" +
 	"\vduration_ms\x18\x03 \x01(\x03R\n" +
-	"durationMs\"d\n" +
+	"durationMs\"\x9f\x01\n" +
 	"\x15AskWithHistoryRequest\x12\x19\n" +
 	"\bagent_id\x18\x01 \x01(\tR\aagentId\x120\n" +
-	"\bmessages\x18\x02 \x03(\v2\x14.mcpagent.v1.MessageR\bmessages\"\xd0\x01\n" +
+	"\bmessages\x18\x02 \x03(\v2\x14.mcpagent.v1.MessageR\bmessages\x12\x1a\n" +
+	"\bpriority\x18\x03 \x01(\tR\bpriority\x12\x1d\n" +
+	"\n" +
+	"request_id\x18\x04 \x01(\tR\trequestId\"\xd0\x01\n" +
 	"\x16AskWithHistoryResponse\x12\x1a\n" +
 	"\bresponse\x18\x01 \x01(\tR\bresponse\x12?\n" +
 	"\x10updated_messages\x18\x02 \x03(\v2\x14.mcpagent.v1.MessageR\x0fupdatedMessages\x128\n" +
 	"\vtoken_usage\x18\x03 \x01(\v2\x17.mcpagent.v1.TokenUsageR\n" +
 	"tokenUsage\x12\x1f\n" +
 	"\vduration_ms\x18\x04 \x01(\x03R\n" +
-	"durationMs\"\x14\n" +
+	"durationMs\"M\n" +
+	"\x14CancelRequestRequest\x12\x1d\n" +
+	"\n" +
+	"request_id\x18\x01 \x01(\tR\trequestId\x12\x16\n" +
+	"\x06reason\x18\x02 \x01(\tR\x06reason\"5\n" +
+	"\x15CancelRequestResponse\x12\x1c\n" +
+	"\tcancelled\x18\x01 \x01(\bR\tcancelled\"\x14\n" +
 	"\x12HealthCheckRequest\"-\n" +
 	"\x13HealthCheckResponse\x12\x16\n" +
-	"\x06status\x18\x01 \x01(\tR\x06status2\xde\x05\n" +
+	"\x06status\x18\x01 \x01(\tR\x06status\"\x81\x01\n" +
+	"\x1aHealthCheckDetailedRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12)\n" +
+	"\x10skip_connections\x18\x02 \x01(\bR\x0fskipConnections\x12\x1d\n" +
+	"\n" +
+	"timeout_ms\x18\x03 \x01(\x03R\ttimeoutMs\"\xaa\x03\n" +
+	"\x1bHealthCheckDetailedResponse\x12\x16\n" +
+	"\x06status\x18\x01 \x01(\tR\x06status\x12\x14\n" +
+	"\x05ready\x18\x02 \x01(\bR\x05ready\x12L\n" +
+	"\x06checks\x18\x03 \x03(\v24.mcpagent.v1.HealthCheckDetailedResponse.ChecksEntryR\x06checks\x12\x1a\n" +
+	"\bdraining\x18\x04 \x01(\bR\bdraining\x12#\n" +
+	"\ractive_agents\x18\x05 \x01(\x05R\factiveAgents\x121\n" +
+	"\x14active_conversations\x18\x06 \x01(\x05R\x13activeConversations\x120\n" +
+	"\x06agents\x18\a \x03(\v2\x18.mcpagent.v1.AgentHealthR\x06agents\x12.\n" +
+	"\x05cache\x18\b \x01(\v2\x18.mcpagent.v1.CacheStatusR\x05cache\x1a9\n" +
+	"\vChecksEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"|\n" +
+	"\vAgentHealth\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x02 \x01(\tR\tsessionId\x123\n" +
+	"\aservers\x18\x03 \x03(\v2\x19.mcpagent.v1.ServerHealthR\aservers\"R\n" +
+	"\fServerHealth\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x18\n" +
+	"\ahealthy\x18\x02 \x01(\bR\ahealthy\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\"\xbf\x01\n" +
+	"\vCacheStatus\x12#\n" +
+	"\rtotal_entries\x18\x01 \x01(\x05R\ftotalEntries\x12#\n" +
+	"\rvalid_entries\x18\x02 \x01(\x05R\fvalidEntries\x12'\n" +
+	"\x0fexpired_entries\x18\x03 \x01(\x05R\x0eexpiredEntries\x12\x1f\n" +
+	"\vttl_minutes\x18\x04 \x01(\x05R\n" +
+	"ttlMinutes\x12\x1c\n" +
+	"\tdirectory\x18\x05 \x01(\tR\tdirectory\"%\n" +
+	"#ListRecoverableConversationsRequest\"r\n" +
+	"$ListRecoverableConversationsResponse\x12J\n" +
+	"\rconversations\x18\x01 \x03(\v2$.mcpagent.v1.RecoverableConversationR\rconversations\"\xd4\x02\n" +
+	"\x17RecoverableConversation\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x02 \x01(\tR\tsessionId\x12\x17\n" +
+	"\auser_id\x18\x03 \x01(\tR\x06userId\x12\x1a\n" +
+	"\bprovider\x18\x04 \x01(\tR\bprovider\x12\x19\n" +
+	"\bmodel_id\x18\x05 \x01(\tR\amodelId\x12\x1a\n" +
+	"\bquestion\x18\x06 \x01(\tR\bquestion\x12\x12\n" +
+	"\x04turn\x18\a \x01(\x05R\x04turn\x12\x1d\n" +
+	"\n" +
+	"last_error\x18\b \x01(\tR\tlastError\x129\n" +
+	"\n" +
+	"updated_at\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x120\n" +
+	"\bmessages\x18\n" +
+	" \x03(\v2\x14.mcpagent.v1.MessageR\bmessages2\xc2\x0e\n" +
 	"\fAgentService\x12P\n" +
-	"\vCreateAgent\x12\x1f.mcpagent.v1.CreateAgentRequest\x1a .mcpagent.v1.CreateAgentResponse\x12G\n" +
+	"\vCreateAgent\x12\x1f.mcpagent.v1.CreateAgentRequest\x1a .mcpagent.v1.CreateAgentResponse\x12d\n" +
+	"\x15CreateAgentFromPreset\x12).mcpagent.v1.CreateAgentFromPresetRequest\x1a .mcpagent.v1.CreateAgentResponse\x12G\n" +
 	"\bGetAgent\x12\x1c.mcpagent.v1.GetAgentRequest\x1a\x1d.mcpagent.v1.GetAgentResponse\x12M\n" +
 	"\n" +
 	"ListAgents\x12\x1e.mcpagent.v1.ListAgentsRequest\x1a\x1f.mcpagent.v1.ListAgentsResponse\x12S\n" +
 	"\fDestroyAgent\x12 .mcpagent.v1.DestroyAgentRequest\x1a!.mcpagent.v1.DestroyAgentResponse\x12S\n" +
-	"\rGetTokenUsage\x12!.mcpagent.v1.GetTokenUsageRequest\x1a\x1f.mcpagent.v1.TokenUsageResponse\x12S\n" +
-	"\bConverse\x12 .mcpagent.v1.ConversationRequest\x1a!.mcpagent.v1.ConversationResponse(\x010\x01\x128\n" +
+	"\rGetTokenUsage\x12!.mcpagent.v1.GetTokenUsageRequest\x1a\x1f.mcpagent.v1.TokenUsageResponse\x12h\n" +
+	"\x13GetPostMortemBundle\x12'.mcpagent.v1.GetPostMortemBundleRequest\x1a(.mcpagent.v1.GetPostMortemBundleResponse\x12S\n" +
+	"\fGetToolStats\x12 .mcpagent.v1.GetToolStatsRequest\x1a!.mcpagent.v1.GetToolStatsResponse\x12J\n" +
+	"\tListTools\x12\x1d.mcpagent.v1.ListToolsRequest\x1a\x1e.mcpagent.v1.ListToolsResponse\x12V\n" +
+	"\rGetToolSchema\x12!.mcpagent.v1.GetToolSchemaRequest\x1a\".mcpagent.v1.GetToolSchemaResponse\x12P\n" +
+	"\vListServers\x12\x1f.mcpagent.v1.ListServersRequest\x1a .mcpagent.v1.ListServersResponse\x12P\n" +
+	"\vListPrompts\x12\x1f.mcpagent.v1.ListPromptsRequest\x1a .mcpagent.v1.ListPromptsResponse\x12S\n" +
+	"\bConverse\x12 .mcpagent.v1.ConversationRequest\x1a!.mcpagent.v1.ConversationResponse(\x010\x01\x12_\n" +
+	"\x11WatchConversation\x12%.mcpagent.v1.WatchConversationRequest\x1a!.mcpagent.v1.ConversationResponse0\x01\x12L\n" +
+	"\tAskStream\x12\x1d.mcpagent.v1.AskStreamRequest\x1a\x1e.mcpagent.v1.AskStreamResponse0\x01\x128\n" +
 	"\x03Ask\x12\x17.mcpagent.v1.AskRequest\x1a\x18.mcpagent.v1.AskResponse\x12Y\n" +
-	"\x0eAskWithHistory\x12\".mcpagent.v1.AskWithHistoryRequest\x1a#.mcpagent.v1.AskWithHistoryResponse\x12P\n" +
-	"\vHealthCheck\x12\x1f.mcpagent.v1.HealthCheckRequest\x1a .mcpagent.v1.HealthCheckResponseB,Z*github.com/mcpagent/mcpagent/grpcserver/pbb\x06proto3"
+	"\x0eAskWithHistory\x12\".mcpagent.v1.AskWithHistoryRequest\x1a#.mcpagent.v1.AskWithHistoryResponse\x12V\n" +
+	"\rCancelRequest\x12!.mcpagent.v1.CancelRequestRequest\x1a\".mcpagent.v1.CancelRequestResponse\x12P\n" +
+	"\vHealthCheck\x12\x1f.mcpagent.v1.HealthCheckRequest\x1a .mcpagent.v1.HealthCheckResponse\x12h\n" +
+	"\x13HealthCheckDetailed\x12'.mcpagent.v1.HealthCheckDetailedRequest\x1a(.mcpagent.v1.HealthCheckDetailedResponse\x12\x83\x01\n" +
+	"\x1cListRecoverableConversations\x120.mcpagent.v1.ListRecoverableConversationsRequest\x1a1.mcpagent.v1.ListRecoverableConversationsResponseB,Z*github.com/mcpagent/mcpagent/grpcserver/pbb\x06proto3"
 
 var (
 	file_agent_proto_rawDescOnce sync.Once
@@ -2519,102 +5411,201 @@ func file_agent_proto_rawDescGZIP() []byte {
 	return file_agent_proto_rawDescData
 }
 
-var file_agent_proto_msgTypes = make([]protoimpl.MessageInfo, 34)
+var file_agent_proto_msgTypes = make([]protoimpl.MessageInfo, 74)
 var file_agent_proto_goTypes = []any{
-	(*CreateAgentRequest)(nil),     // 0: mcpagent.v1.CreateAgentRequest
-	(*AgentConfig)(nil),            // 1: mcpagent.v1.AgentConfig
-	(*CustomToolDefinition)(nil),   // 2: mcpagent.v1.CustomToolDefinition
-	(*CreateAgentResponse)(nil),    // 3: mcpagent.v1.CreateAgentResponse
-	(*Capabilities)(nil),           // 4: mcpagent.v1.Capabilities
-	(*GetAgentRequest)(nil),        // 5: mcpagent.v1.GetAgentRequest
-	(*GetAgentResponse)(nil),       // 6: mcpagent.v1.GetAgentResponse
-	(*ListAgentsRequest)(nil),      // 7: mcpagent.v1.ListAgentsRequest
-	(*ListAgentsResponse)(nil),     // 8: mcpagent.v1.ListAgentsResponse
-	(*AgentSummary)(nil),           // 9: mcpagent.v1.AgentSummary
-	(*DestroyAgentRequest)(nil),    // 10: mcpagent.v1.DestroyAgentRequest
-	(*DestroyAgentResponse)(nil),   // 11: mcpagent.v1.DestroyAgentResponse
-	(*GetTokenUsageRequest)(nil),   // 12: mcpagent.v1.GetTokenUsageRequest
-	(*TokenUsage)(nil),             // 13: mcpagent.v1.TokenUsage
-	(*Costs)(nil),                  // 14: mcpagent.v1.Costs
-	(*TokenUsageResponse)(nil),     // 15: mcpagent.v1.TokenUsageResponse
-	(*ConversationRequest)(nil),    // 16: mcpagent.v1.ConversationRequest
-	(*QuestionMessage)(nil),        // 17: mcpagent.v1.QuestionMessage
-	(*ToolResultMessage)(nil),      // 18: mcpagent.v1.ToolResultMessage
-	(*ToolError)(nil),              // 19: mcpagent.v1.ToolError
-	(*CancelMessage)(nil),          // 20: mcpagent.v1.CancelMessage
-	(*ConversationResponse)(nil),   // 21: mcpagent.v1.ConversationResponse
-	(*TextChunkEvent)(nil),         // 22: mcpagent.v1.TextChunkEvent
-	(*ToolCallEvent)(nil),          // 23: mcpagent.v1.ToolCallEvent
-	(*FinalResponse)(nil),          // 24: mcpagent.v1.FinalResponse
-	(*ErrorEvent)(nil),             // 25: mcpagent.v1.ErrorEvent
-	(*AgentEvent)(nil),             // 26: mcpagent.v1.AgentEvent
-	(*Message)(nil),                // 27: mcpagent.v1.Message
-	(*AskRequest)(nil),             // 28: mcpagent.v1.AskRequest
-	(*AskResponse)(nil),            // 29: mcpagent.v1.AskResponse
-	(*AskWithHistoryRequest)(nil),  // 30: mcpagent.v1.AskWithHistoryRequest
-	(*AskWithHistoryResponse)(nil), // 31: mcpagent.v1.AskWithHistoryResponse
-	(*HealthCheckRequest)(nil),     // 32: mcpagent.v1.HealthCheckRequest
-	(*HealthCheckResponse)(nil),    // 33: mcpagent.v1.HealthCheckResponse
-	(*structpb.Struct)(nil),        // 34: google.protobuf.Struct
-	(*timestamppb.Timestamp)(nil),  // 35: google.protobuf.Timestamp
+	(*CreateAgentRequest)(nil),                   // 0: mcpagent.v1.CreateAgentRequest
+	(*AgentConfig)(nil),                          // 1: mcpagent.v1.AgentConfig
+	(*ToolPermission)(nil),                       // 2: mcpagent.v1.ToolPermission
+	(*CustomToolDefinition)(nil),                 // 3: mcpagent.v1.CustomToolDefinition
+	(*CreateAgentFromPresetRequest)(nil),         // 4: mcpagent.v1.CreateAgentFromPresetRequest
+	(*CreateAgentResponse)(nil),                  // 5: mcpagent.v1.CreateAgentResponse
+	(*Capabilities)(nil),                         // 6: mcpagent.v1.Capabilities
+	(*GetAgentRequest)(nil),                      // 7: mcpagent.v1.GetAgentRequest
+	(*GetAgentResponse)(nil),                     // 8: mcpagent.v1.GetAgentResponse
+	(*AgentFilters)(nil),                         // 9: mcpagent.v1.AgentFilters
+	(*ListAgentsRequest)(nil),                    // 10: mcpagent.v1.ListAgentsRequest
+	(*ListAgentsResponse)(nil),                   // 11: mcpagent.v1.ListAgentsResponse
+	(*AgentSummary)(nil),                         // 12: mcpagent.v1.AgentSummary
+	(*DestroyAgentRequest)(nil),                  // 13: mcpagent.v1.DestroyAgentRequest
+	(*DestroyAgentResponse)(nil),                 // 14: mcpagent.v1.DestroyAgentResponse
+	(*GetTokenUsageRequest)(nil),                 // 15: mcpagent.v1.GetTokenUsageRequest
+	(*TokenUsage)(nil),                           // 16: mcpagent.v1.TokenUsage
+	(*Costs)(nil),                                // 17: mcpagent.v1.Costs
+	(*TokenUsageResponse)(nil),                   // 18: mcpagent.v1.TokenUsageResponse
+	(*GetPostMortemBundleRequest)(nil),           // 19: mcpagent.v1.GetPostMortemBundleRequest
+	(*GetPostMortemBundleResponse)(nil),          // 20: mcpagent.v1.GetPostMortemBundleResponse
+	(*GetToolStatsRequest)(nil),                  // 21: mcpagent.v1.GetToolStatsRequest
+	(*ToolCallStats)(nil),                        // 22: mcpagent.v1.ToolCallStats
+	(*ToolStats)(nil),                            // 23: mcpagent.v1.ToolStats
+	(*ServerToolStats)(nil),                      // 24: mcpagent.v1.ServerToolStats
+	(*GetToolStatsResponse)(nil),                 // 25: mcpagent.v1.GetToolStatsResponse
+	(*ListToolsRequest)(nil),                     // 26: mcpagent.v1.ListToolsRequest
+	(*ListToolsResponse)(nil),                    // 27: mcpagent.v1.ListToolsResponse
+	(*ToolInfo)(nil),                             // 28: mcpagent.v1.ToolInfo
+	(*GetToolSchemaRequest)(nil),                 // 29: mcpagent.v1.GetToolSchemaRequest
+	(*GetToolSchemaResponse)(nil),                // 30: mcpagent.v1.GetToolSchemaResponse
+	(*ListServersRequest)(nil),                   // 31: mcpagent.v1.ListServersRequest
+	(*ListServersResponse)(nil),                  // 32: mcpagent.v1.ListServersResponse
+	(*ServerInfo)(nil),                           // 33: mcpagent.v1.ServerInfo
+	(*ListPromptsRequest)(nil),                   // 34: mcpagent.v1.ListPromptsRequest
+	(*ListPromptsResponse)(nil),                  // 35: mcpagent.v1.ListPromptsResponse
+	(*PromptInfo)(nil),                           // 36: mcpagent.v1.PromptInfo
+	(*PromptArgument)(nil),                       // 37: mcpagent.v1.PromptArgument
+	(*ConversationRequest)(nil),                  // 38: mcpagent.v1.ConversationRequest
+	(*QuestionMessage)(nil),                      // 39: mcpagent.v1.QuestionMessage
+	(*ToolResultMessage)(nil),                    // 40: mcpagent.v1.ToolResultMessage
+	(*ToolError)(nil),                            // 41: mcpagent.v1.ToolError
+	(*CancelMessage)(nil),                        // 42: mcpagent.v1.CancelMessage
+	(*ConversationResponse)(nil),                 // 43: mcpagent.v1.ConversationResponse
+	(*TextChunkEvent)(nil),                       // 44: mcpagent.v1.TextChunkEvent
+	(*ToolCallEvent)(nil),                        // 45: mcpagent.v1.ToolCallEvent
+	(*FinalResponse)(nil),                        // 46: mcpagent.v1.FinalResponse
+	(*ErrorEvent)(nil),                           // 47: mcpagent.v1.ErrorEvent
+	(*AgentEvent)(nil),                           // 48: mcpagent.v1.AgentEvent
+	(*Artifact)(nil),                             // 49: mcpagent.v1.Artifact
+	(*WatchConversationRequest)(nil),             // 50: mcpagent.v1.WatchConversationRequest
+	(*AskStreamRequest)(nil),                     // 51: mcpagent.v1.AskStreamRequest
+	(*AskStreamResponse)(nil),                    // 52: mcpagent.v1.AskStreamResponse
+	(*ToolCallStart)(nil),                        // 53: mcpagent.v1.ToolCallStart
+	(*ToolCallEnd)(nil),                          // 54: mcpagent.v1.ToolCallEnd
+	(*Message)(nil),                              // 55: mcpagent.v1.Message
+	(*AskRequest)(nil),                           // 56: mcpagent.v1.AskRequest
+	(*AskResponse)(nil),                          // 57: mcpagent.v1.AskResponse
+	(*AskWithHistoryRequest)(nil),                // 58: mcpagent.v1.AskWithHistoryRequest
+	(*AskWithHistoryResponse)(nil),               // 59: mcpagent.v1.AskWithHistoryResponse
+	(*CancelRequestRequest)(nil),                 // 60: mcpagent.v1.CancelRequestRequest
+	(*CancelRequestResponse)(nil),                // 61: mcpagent.v1.CancelRequestResponse
+	(*HealthCheckRequest)(nil),                   // 62: mcpagent.v1.HealthCheckRequest
+	(*HealthCheckResponse)(nil),                  // 63: mcpagent.v1.HealthCheckResponse
+	(*HealthCheckDetailedRequest)(nil),           // 64: mcpagent.v1.HealthCheckDetailedRequest
+	(*HealthCheckDetailedResponse)(nil),          // 65: mcpagent.v1.HealthCheckDetailedResponse
+	(*AgentHealth)(nil),                          // 66: mcpagent.v1.AgentHealth
+	(*ServerHealth)(nil),                         // 67: mcpagent.v1.ServerHealth
+	(*CacheStatus)(nil),                          // 68: mcpagent.v1.CacheStatus
+	(*ListRecoverableConversationsRequest)(nil),  // 69: mcpagent.v1.ListRecoverableConversationsRequest
+	(*ListRecoverableConversationsResponse)(nil), // 70: mcpagent.v1.ListRecoverableConversationsResponse
+	(*RecoverableConversation)(nil),              // 71: mcpagent.v1.RecoverableConversation
+	nil,                                          // 72: mcpagent.v1.CreateAgentFromPresetRequest.VariablesEntry
+	nil,                                          // 73: mcpagent.v1.HealthCheckDetailedResponse.ChecksEntry
+	(*structpb.Struct)(nil),                      // 74: google.protobuf.Struct
+	(*timestamppb.Timestamp)(nil),                // 75: google.protobuf.Timestamp
 }
 var file_agent_proto_depIdxs = []int32{
 	1,  // 0: mcpagent.v1.CreateAgentRequest.config:type_name -> mcpagent.v1.AgentConfig
-	2,  // 1: mcpagent.v1.AgentConfig.custom_tools:type_name -> mcpagent.v1.CustomToolDefinition
-	34, // 2: mcpagent.v1.CustomToolDefinition.parameters:type_name -> google.protobuf.Struct
-	35, // 3: mcpagent.v1.CreateAgentResponse.created_at:type_name -> google.protobuf.Timestamp
-	4,  // 4: mcpagent.v1.CreateAgentResponse.capabilities:type_name -> mcpagent.v1.Capabilities
-	35, // 5: mcpagent.v1.GetAgentResponse.created_at:type_name -> google.protobuf.Timestamp
-	4,  // 6: mcpagent.v1.GetAgentResponse.capabilities:type_name -> mcpagent.v1.Capabilities
-	13, // 7: mcpagent.v1.GetAgentResponse.token_usage:type_name -> mcpagent.v1.TokenUsage
-	9,  // 8: mcpagent.v1.ListAgentsResponse.agents:type_name -> mcpagent.v1.AgentSummary
-	35, // 9: mcpagent.v1.AgentSummary.created_at:type_name -> google.protobuf.Timestamp
-	13, // 10: mcpagent.v1.TokenUsageResponse.token_usage:type_name -> mcpagent.v1.TokenUsage
-	14, // 11: mcpagent.v1.TokenUsageResponse.costs:type_name -> mcpagent.v1.Costs
-	17, // 12: mcpagent.v1.ConversationRequest.question:type_name -> mcpagent.v1.QuestionMessage
-	18, // 13: mcpagent.v1.ConversationRequest.tool_result:type_name -> mcpagent.v1.ToolResultMessage
-	20, // 14: mcpagent.v1.ConversationRequest.cancel:type_name -> mcpagent.v1.CancelMessage
-	27, // 15: mcpagent.v1.QuestionMessage.history:type_name -> mcpagent.v1.Message
-	19, // 16: mcpagent.v1.ToolResultMessage.error:type_name -> mcpagent.v1.ToolError
-	34, // 17: mcpagent.v1.ToolError.details:type_name -> google.protobuf.Struct
-	22, // 18: mcpagent.v1.ConversationResponse.text_chunk:type_name -> mcpagent.v1.TextChunkEvent
-	23, // 19: mcpagent.v1.ConversationResponse.tool_call:type_name -> mcpagent.v1.ToolCallEvent
-	26, // 20: mcpagent.v1.ConversationResponse.agent_event:type_name -> mcpagent.v1.AgentEvent
-	24, // 21: mcpagent.v1.ConversationResponse.final_response:type_name -> mcpagent.v1.FinalResponse
-	25, // 22: mcpagent.v1.ConversationResponse.error:type_name -> mcpagent.v1.ErrorEvent
-	34, // 23: mcpagent.v1.ToolCallEvent.arguments:type_name -> google.protobuf.Struct
-	27, // 24: mcpagent.v1.FinalResponse.updated_messages:type_name -> mcpagent.v1.Message
-	13, // 25: mcpagent.v1.FinalResponse.token_usage:type_name -> mcpagent.v1.TokenUsage
-	34, // 26: mcpagent.v1.ErrorEvent.details:type_name -> google.protobuf.Struct
-	35, // 27: mcpagent.v1.AgentEvent.timestamp:type_name -> google.protobuf.Timestamp
-	34, // 28: mcpagent.v1.AgentEvent.data:type_name -> google.protobuf.Struct
-	13, // 29: mcpagent.v1.AskResponse.token_usage:type_name -> mcpagent.v1.TokenUsage
-	27, // 30: mcpagent.v1.AskWithHistoryRequest.messages:type_name -> mcpagent.v1.Message
-	27, // 31: mcpagent.v1.AskWithHistoryResponse.updated_messages:type_name -> mcpagent.v1.Message
-	13, // 32: mcpagent.v1.AskWithHistoryResponse.token_usage:type_name -> mcpagent.v1.TokenUsage
-	0,  // 33: mcpagent.v1.AgentService.CreateAgent:input_type -> mcpagent.v1.CreateAgentRequest
-	5,  // 34: mcpagent.v1.AgentService.GetAgent:input_type -> mcpagent.v1.GetAgentRequest
-	7,  // 35: mcpagent.v1.AgentService.ListAgents:input_type -> mcpagent.v1.ListAgentsRequest
-	10, // 36: mcpagent.v1.AgentService.DestroyAgent:input_type -> mcpagent.v1.DestroyAgentRequest
-	12, // 37: mcpagent.v1.AgentService.GetTokenUsage:input_type -> mcpagent.v1.GetTokenUsageRequest
-	16, // 38: mcpagent.v1.AgentService.Converse:input_type -> mcpagent.v1.ConversationRequest
-	28, // 39: mcpagent.v1.AgentService.Ask:input_type -> mcpagent.v1.AskRequest
-	30, // 40: mcpagent.v1.AgentService.AskWithHistory:input_type -> mcpagent.v1.AskWithHistoryRequest
-	32, // 41: mcpagent.v1.AgentService.HealthCheck:input_type -> mcpagent.v1.HealthCheckRequest
-	3,  // 42: mcpagent.v1.AgentService.CreateAgent:output_type -> mcpagent.v1.CreateAgentResponse
-	6,  // 43: mcpagent.v1.AgentService.GetAgent:output_type -> mcpagent.v1.GetAgentResponse
-	8,  // 44: mcpagent.v1.AgentService.ListAgents:output_type -> mcpagent.v1.ListAgentsResponse
-	11, // 45: mcpagent.v1.AgentService.DestroyAgent:output_type -> mcpagent.v1.DestroyAgentResponse
-	15, // 46: mcpagent.v1.AgentService.GetTokenUsage:output_type -> mcpagent.v1.TokenUsageResponse
-	21, // 47: mcpagent.v1.AgentService.Converse:output_type -> mcpagent.v1.ConversationResponse
-	29, // 48: mcpagent.v1.AgentService.Ask:output_type -> mcpagent.v1.AskResponse
-	31, // 49: mcpagent.v1.AgentService.AskWithHistory:output_type -> mcpagent.v1.AskWithHistoryResponse
-	33, // 50: mcpagent.v1.AgentService.HealthCheck:output_type -> mcpagent.v1.HealthCheckResponse
-	42, // [42:51] is the sub-list for method output_type
-	33, // [33:42] is the sub-list for method input_type
-	33, // [33:33] is the sub-list for extension type_name
-	33, // [33:33] is the sub-list for extension extendee
-	0,  // [0:33] is the sub-list for field type_name
+	3,  // 1: mcpagent.v1.AgentConfig.custom_tools:type_name -> mcpagent.v1.CustomToolDefinition
+	2,  // 2: mcpagent.v1.AgentConfig.tool_permissions:type_name -> mcpagent.v1.ToolPermission
+	74, // 3: mcpagent.v1.CustomToolDefinition.parameters:type_name -> google.protobuf.Struct
+	72, // 4: mcpagent.v1.CreateAgentFromPresetRequest.variables:type_name -> mcpagent.v1.CreateAgentFromPresetRequest.VariablesEntry
+	1,  // 5: mcpagent.v1.CreateAgentFromPresetRequest.config:type_name -> mcpagent.v1.AgentConfig
+	75, // 6: mcpagent.v1.CreateAgentResponse.created_at:type_name -> google.protobuf.Timestamp
+	6,  // 7: mcpagent.v1.CreateAgentResponse.capabilities:type_name -> mcpagent.v1.Capabilities
+	75, // 8: mcpagent.v1.GetAgentResponse.created_at:type_name -> google.protobuf.Timestamp
+	6,  // 9: mcpagent.v1.GetAgentResponse.capabilities:type_name -> mcpagent.v1.Capabilities
+	16, // 10: mcpagent.v1.GetAgentResponse.token_usage:type_name -> mcpagent.v1.TokenUsage
+	28, // 11: mcpagent.v1.GetAgentResponse.tools:type_name -> mcpagent.v1.ToolInfo
+	9,  // 12: mcpagent.v1.GetAgentResponse.filters:type_name -> mcpagent.v1.AgentFilters
+	12, // 13: mcpagent.v1.ListAgentsResponse.agents:type_name -> mcpagent.v1.AgentSummary
+	75, // 14: mcpagent.v1.AgentSummary.created_at:type_name -> google.protobuf.Timestamp
+	16, // 15: mcpagent.v1.AgentSummary.token_usage:type_name -> mcpagent.v1.TokenUsage
+	28, // 16: mcpagent.v1.AgentSummary.tools:type_name -> mcpagent.v1.ToolInfo
+	9,  // 17: mcpagent.v1.AgentSummary.filters:type_name -> mcpagent.v1.AgentFilters
+	16, // 18: mcpagent.v1.TokenUsageResponse.token_usage:type_name -> mcpagent.v1.TokenUsage
+	17, // 19: mcpagent.v1.TokenUsageResponse.costs:type_name -> mcpagent.v1.Costs
+	75, // 20: mcpagent.v1.GetPostMortemBundleResponse.created_at:type_name -> google.protobuf.Timestamp
+	22, // 21: mcpagent.v1.ToolStats.stats:type_name -> mcpagent.v1.ToolCallStats
+	22, // 22: mcpagent.v1.ServerToolStats.stats:type_name -> mcpagent.v1.ToolCallStats
+	23, // 23: mcpagent.v1.GetToolStatsResponse.tools:type_name -> mcpagent.v1.ToolStats
+	24, // 24: mcpagent.v1.GetToolStatsResponse.servers:type_name -> mcpagent.v1.ServerToolStats
+	28, // 25: mcpagent.v1.ListToolsResponse.tools:type_name -> mcpagent.v1.ToolInfo
+	28, // 26: mcpagent.v1.GetToolSchemaResponse.tool:type_name -> mcpagent.v1.ToolInfo
+	74, // 27: mcpagent.v1.GetToolSchemaResponse.input_schema:type_name -> google.protobuf.Struct
+	33, // 28: mcpagent.v1.ListServersResponse.servers:type_name -> mcpagent.v1.ServerInfo
+	36, // 29: mcpagent.v1.ListPromptsResponse.prompts:type_name -> mcpagent.v1.PromptInfo
+	37, // 30: mcpagent.v1.PromptInfo.arguments:type_name -> mcpagent.v1.PromptArgument
+	39, // 31: mcpagent.v1.ConversationRequest.question:type_name -> mcpagent.v1.QuestionMessage
+	40, // 32: mcpagent.v1.ConversationRequest.tool_result:type_name -> mcpagent.v1.ToolResultMessage
+	42, // 33: mcpagent.v1.ConversationRequest.cancel:type_name -> mcpagent.v1.CancelMessage
+	55, // 34: mcpagent.v1.QuestionMessage.history:type_name -> mcpagent.v1.Message
+	41, // 35: mcpagent.v1.ToolResultMessage.error:type_name -> mcpagent.v1.ToolError
+	74, // 36: mcpagent.v1.ToolError.details:type_name -> google.protobuf.Struct
+	44, // 37: mcpagent.v1.ConversationResponse.text_chunk:type_name -> mcpagent.v1.TextChunkEvent
+	45, // 38: mcpagent.v1.ConversationResponse.tool_call:type_name -> mcpagent.v1.ToolCallEvent
+	48, // 39: mcpagent.v1.ConversationResponse.agent_event:type_name -> mcpagent.v1.AgentEvent
+	46, // 40: mcpagent.v1.ConversationResponse.final_response:type_name -> mcpagent.v1.FinalResponse
+	47, // 41: mcpagent.v1.ConversationResponse.error:type_name -> mcpagent.v1.ErrorEvent
+	74, // 42: mcpagent.v1.ToolCallEvent.arguments:type_name -> google.protobuf.Struct
+	55, // 43: mcpagent.v1.FinalResponse.updated_messages:type_name -> mcpagent.v1.Message
+	16, // 44: mcpagent.v1.FinalResponse.token_usage:type_name -> mcpagent.v1.TokenUsage
+	49, // 45: mcpagent.v1.FinalResponse.artifacts:type_name -> mcpagent.v1.Artifact
+	74, // 46: mcpagent.v1.ErrorEvent.details:type_name -> google.protobuf.Struct
+	75, // 47: mcpagent.v1.AgentEvent.timestamp:type_name -> google.protobuf.Timestamp
+	74, // 48: mcpagent.v1.AgentEvent.data:type_name -> google.protobuf.Struct
+	49, // 49: mcpagent.v1.AgentEvent.artifacts:type_name -> mcpagent.v1.Artifact
+	55, // 50: mcpagent.v1.AskStreamRequest.history:type_name -> mcpagent.v1.Message
+	44, // 51: mcpagent.v1.AskStreamResponse.text_chunk:type_name -> mcpagent.v1.TextChunkEvent
+	53, // 52: mcpagent.v1.AskStreamResponse.tool_call_start:type_name -> mcpagent.v1.ToolCallStart
+	54, // 53: mcpagent.v1.AskStreamResponse.tool_call_end:type_name -> mcpagent.v1.ToolCallEnd
+	46, // 54: mcpagent.v1.AskStreamResponse.final_response:type_name -> mcpagent.v1.FinalResponse
+	47, // 55: mcpagent.v1.AskStreamResponse.error:type_name -> mcpagent.v1.ErrorEvent
+	48, // 56: mcpagent.v1.AskStreamResponse.agent_event:type_name -> mcpagent.v1.AgentEvent
+	16, // 57: mcpagent.v1.AskResponse.token_usage:type_name -> mcpagent.v1.TokenUsage
+	55, // 58: mcpagent.v1.AskWithHistoryRequest.messages:type_name -> mcpagent.v1.Message
+	55, // 59: mcpagent.v1.AskWithHistoryResponse.updated_messages:type_name -> mcpagent.v1.Message
+	16, // 60: mcpagent.v1.AskWithHistoryResponse.token_usage:type_name -> mcpagent.v1.TokenUsage
+	73, // 61: mcpagent.v1.HealthCheckDetailedResponse.checks:type_name -> mcpagent.v1.HealthCheckDetailedResponse.ChecksEntry
+	66, // 62: mcpagent.v1.HealthCheckDetailedResponse.agents:type_name -> mcpagent.v1.AgentHealth
+	68, // 63: mcpagent.v1.HealthCheckDetailedResponse.cache:type_name -> mcpagent.v1.CacheStatus
+	67, // 64: mcpagent.v1.AgentHealth.servers:type_name -> mcpagent.v1.ServerHealth
+	71, // 65: mcpagent.v1.ListRecoverableConversationsResponse.conversations:type_name -> mcpagent.v1.RecoverableConversation
+	75, // 66: mcpagent.v1.RecoverableConversation.updated_at:type_name -> google.protobuf.Timestamp
+	55, // 67: mcpagent.v1.RecoverableConversation.messages:type_name -> mcpagent.v1.Message
+	0,  // 68: mcpagent.v1.AgentService.CreateAgent:input_type -> mcpagent.v1.CreateAgentRequest
+	4,  // 69: mcpagent.v1.AgentService.CreateAgentFromPreset:input_type -> mcpagent.v1.CreateAgentFromPresetRequest
+	7,  // 70: mcpagent.v1.AgentService.GetAgent:input_type -> mcpagent.v1.GetAgentRequest
+	10, // 71: mcpagent.v1.AgentService.ListAgents:input_type -> mcpagent.v1.ListAgentsRequest
+	13, // 72: mcpagent.v1.AgentService.DestroyAgent:input_type -> mcpagent.v1.DestroyAgentRequest
+	15, // 73: mcpagent.v1.AgentService.GetTokenUsage:input_type -> mcpagent.v1.GetTokenUsageRequest
+	19, // 74: mcpagent.v1.AgentService.GetPostMortemBundle:input_type -> mcpagent.v1.GetPostMortemBundleRequest
+	21, // 75: mcpagent.v1.AgentService.GetToolStats:input_type -> mcpagent.v1.GetToolStatsRequest
+	26, // 76: mcpagent.v1.AgentService.ListTools:input_type -> mcpagent.v1.ListToolsRequest
+	29, // 77: mcpagent.v1.AgentService.GetToolSchema:input_type -> mcpagent.v1.GetToolSchemaRequest
+	31, // 78: mcpagent.v1.AgentService.ListServers:input_type -> mcpagent.v1.ListServersRequest
+	34, // 79: mcpagent.v1.AgentService.ListPrompts:input_type -> mcpagent.v1.ListPromptsRequest
+	38, // 80: mcpagent.v1.AgentService.Converse:input_type -> mcpagent.v1.ConversationRequest
+	50, // 81: mcpagent.v1.AgentService.WatchConversation:input_type -> mcpagent.v1.WatchConversationRequest
+	51, // 82: mcpagent.v1.AgentService.AskStream:input_type -> mcpagent.v1.AskStreamRequest
+	56, // 83: mcpagent.v1.AgentService.Ask:input_type -> mcpagent.v1.AskRequest
+	58, // 84: mcpagent.v1.AgentService.AskWithHistory:input_type -> mcpagent.v1.AskWithHistoryRequest
+	60, // 85: mcpagent.v1.AgentService.CancelRequest:input_type -> mcpagent.v1.CancelRequestRequest
+	62, // 86: mcpagent.v1.AgentService.HealthCheck:input_type -> mcpagent.v1.HealthCheckRequest
+	64, // 87: mcpagent.v1.AgentService.HealthCheckDetailed:input_type -> mcpagent.v1.HealthCheckDetailedRequest
+	69, // 88: mcpagent.v1.AgentService.ListRecoverableConversations:input_type -> mcpagent.v1.ListRecoverableConversationsRequest
+	5,  // 89: mcpagent.v1.AgentService.CreateAgent:output_type -> mcpagent.v1.CreateAgentResponse
+	5,  // 90: mcpagent.v1.AgentService.CreateAgentFromPreset:output_type -> mcpagent.v1.CreateAgentResponse
+	8,  // 91: mcpagent.v1.AgentService.GetAgent:output_type -> mcpagent.v1.GetAgentResponse
+	11, // 92: mcpagent.v1.AgentService.ListAgents:output_type -> mcpagent.v1.ListAgentsResponse
+	14, // 93: mcpagent.v1.AgentService.DestroyAgent:output_type -> mcpagent.v1.DestroyAgentResponse
+	18, // 94: mcpagent.v1.AgentService.GetTokenUsage:output_type -> mcpagent.v1.TokenUsageResponse
+	20, // 95: mcpagent.v1.AgentService.GetPostMortemBundle:output_type -> mcpagent.v1.GetPostMortemBundleResponse
+	25, // 96: mcpagent.v1.AgentService.GetToolStats:output_type -> mcpagent.v1.GetToolStatsResponse
+	27, // 97: mcpagent.v1.AgentService.ListTools:output_type -> mcpagent.v1.ListToolsResponse
+	30, // 98: mcpagent.v1.AgentService.GetToolSchema:output_type -> mcpagent.v1.GetToolSchemaResponse
+	32, // 99: mcpagent.v1.AgentService.ListServers:output_type -> mcpagent.v1.ListServersResponse
+	35, // 100: mcpagent.v1.AgentService.ListPrompts:output_type -> mcpagent.v1.ListPromptsResponse
+	43, // 101: mcpagent.v1.AgentService.Converse:output_type -> mcpagent.v1.ConversationResponse
+	43, // 102: mcpagent.v1.AgentService.WatchConversation:output_type -> mcpagent.v1.ConversationResponse
+	52, // 103: mcpagent.v1.AgentService.AskStream:output_type -> mcpagent.v1.AskStreamResponse
+	57, // 104: mcpagent.v1.AgentService.Ask:output_type -> mcpagent.v1.AskResponse
+	59, // 105: mcpagent.v1.AgentService.AskWithHistory:output_type -> mcpagent.v1.AskWithHistoryResponse
+	61, // 106: mcpagent.v1.AgentService.CancelRequest:output_type -> mcpagent.v1.CancelRequestResponse
+	63, // 107: mcpagent.v1.AgentService.HealthCheck:output_type -> mcpagent.v1.HealthCheckResponse
+	65, // 108: mcpagent.v1.AgentService.HealthCheckDetailed:output_type -> mcpagent.v1.HealthCheckDetailedResponse
+	70, // 109: mcpagent.v1.AgentService.ListRecoverableConversations:output_type -> mcpagent.v1.ListRecoverableConversationsResponse
+	89, // [89:110] is the sub-list for method output_type
+	68, // [68:89] is the sub-list for method input_type
+	68, // [68:68] is the sub-list for extension type_name
+	68, // [68:68] is the sub-list for extension extendee
+	0,  // [0:68] is the sub-list for field type_name
 }
 
 func init() { file_agent_proto_init() }
@@ -2622,25 +5613,33 @@ func file_agent_proto_init() {
 	if File_agent_proto != nil {
 		return
 	}
-	file_agent_proto_msgTypes[16].OneofWrappers = []any{
+	file_agent_proto_msgTypes[38].OneofWrappers = []any{
 		(*ConversationRequest_Question)(nil),
 		(*ConversationRequest_ToolResult)(nil),
 		(*ConversationRequest_Cancel)(nil),
 	}
-	file_agent_proto_msgTypes[21].OneofWrappers = []any{
+	file_agent_proto_msgTypes[43].OneofWrappers = []any{
 		(*ConversationResponse_TextChunk)(nil),
 		(*ConversationResponse_ToolCall)(nil),
 		(*ConversationResponse_AgentEvent)(nil),
 		(*ConversationResponse_FinalResponse)(nil),
 		(*ConversationResponse_Error)(nil),
 	}
+	file_agent_proto_msgTypes[52].OneofWrappers = []any{
+		(*AskStreamResponse_TextChunk)(nil),
+		(*AskStreamResponse_ToolCallStart)(nil),
+		(*AskStreamResponse_ToolCallEnd)(nil),
+		(*AskStreamResponse_FinalResponse)(nil),
+		(*AskStreamResponse_Error)(nil),
+		(*AskStreamResponse_AgentEvent)(nil),
+	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_agent_proto_rawDesc), len(file_agent_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   34,
+			NumMessages:   74,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -19,15 +19,27 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	AgentService_CreateAgent_FullMethodName    = "/mcpagent.v1.AgentService/CreateAgent"
-	AgentService_GetAgent_FullMethodName       = "/mcpagent.v1.AgentService/GetAgent"
-	AgentService_ListAgents_FullMethodName     = "/mcpagent.v1.AgentService/ListAgents"
-	AgentService_DestroyAgent_FullMethodName   = "/mcpagent.v1.AgentService/DestroyAgent"
-	AgentService_GetTokenUsage_FullMethodName  = "/mcpagent.v1.AgentService/GetTokenUsage"
-	AgentService_Converse_FullMethodName       = "/mcpagent.v1.AgentService/Converse"
-	AgentService_Ask_FullMethodName            = "/mcpagent.v1.AgentService/Ask"
-	AgentService_AskWithHistory_FullMethodName = "/mcpagent.v1.AgentService/AskWithHistory"
-	AgentService_HealthCheck_FullMethodName    = "/mcpagent.v1.AgentService/HealthCheck"
+	AgentService_CreateAgent_FullMethodName                  = "/mcpagent.v1.AgentService/CreateAgent"
+	AgentService_CreateAgentFromPreset_FullMethodName        = "/mcpagent.v1.AgentService/CreateAgentFromPreset"
+	AgentService_GetAgent_FullMethodName                     = "/mcpagent.v1.AgentService/GetAgent"
+	AgentService_ListAgents_FullMethodName                   = "/mcpagent.v1.AgentService/ListAgents"
+	AgentService_DestroyAgent_FullMethodName                 = "/mcpagent.v1.AgentService/DestroyAgent"
+	AgentService_GetTokenUsage_FullMethodName                = "/mcpagent.v1.AgentService/GetTokenUsage"
+	AgentService_GetPostMortemBundle_FullMethodName          = "/mcpagent.v1.AgentService/GetPostMortemBundle"
+	AgentService_GetToolStats_FullMethodName                 = "/mcpagent.v1.AgentService/GetToolStats"
+	AgentService_ListTools_FullMethodName                    = "/mcpagent.v1.AgentService/ListTools"
+	AgentService_GetToolSchema_FullMethodName                = "/mcpagent.v1.AgentService/GetToolSchema"
+	AgentService_ListServers_FullMethodName                  = "/mcpagent.v1.AgentService/ListServers"
+	AgentService_ListPrompts_FullMethodName                  = "/mcpagent.v1.AgentService/ListPrompts"
+	AgentService_Converse_FullMethodName                     = "/mcpagent.v1.AgentService/Converse"
+	AgentService_WatchConversation_FullMethodName            = "/mcpagent.v1.AgentService/WatchConversation"
+	AgentService_AskStream_FullMethodName                    = "/mcpagent.v1.AgentService/AskStream"
+	AgentService_Ask_FullMethodName                          = "/mcpagent.v1.AgentService/Ask"
+	AgentService_AskWithHistory_FullMethodName               = "/mcpagent.v1.AgentService/AskWithHistory"
+	AgentService_CancelRequest_FullMethodName                = "/mcpagent.v1.AgentService/CancelRequest"
+	AgentService_HealthCheck_FullMethodName                  = "/mcpagent.v1.AgentService/HealthCheck"
+	AgentService_HealthCheckDetailed_FullMethodName          = "/mcpagent.v1.AgentService/HealthCheckDetailed"
+	AgentService_ListRecoverableConversations_FullMethodName = "/mcpagent.v1.AgentService/ListRecoverableConversations"
 )
 
 // AgentServiceClient is the client API for AgentService service.
@@ -36,20 +48,53 @@ const (
 type AgentServiceClient interface {
 	// Agent Lifecycle
 	CreateAgent(ctx context.Context, in *CreateAgentRequest, opts ...grpc.CallOption) (*CreateAgentResponse, error)
+	// Create an agent from a preset registered on the server (--presets)
+	CreateAgentFromPreset(ctx context.Context, in *CreateAgentFromPresetRequest, opts ...grpc.CallOption) (*CreateAgentResponse, error)
 	GetAgent(ctx context.Context, in *GetAgentRequest, opts ...grpc.CallOption) (*GetAgentResponse, error)
 	ListAgents(ctx context.Context, in *ListAgentsRequest, opts ...grpc.CallOption) (*ListAgentsResponse, error)
 	DestroyAgent(ctx context.Context, in *DestroyAgentRequest, opts ...grpc.CallOption) (*DestroyAgentResponse, error)
 	// Token Usage
 	GetTokenUsage(ctx context.Context, in *GetTokenUsageRequest, opts ...grpc.CallOption) (*TokenUsageResponse, error)
+	// Diagnostics
+	// Returns the post-mortem bundle of the agent's last failed conversation
+	// (requires post-mortem bundles on the server)
+	GetPostMortemBundle(ctx context.Context, in *GetPostMortemBundleRequest, opts ...grpc.CallOption) (*GetPostMortemBundleResponse, error)
+	// Per-tool and per-server call counts, error rates, latencies and bytes
+	// returned since the agent was created
+	GetToolStats(ctx context.Context, in *GetToolStatsRequest, opts ...grpc.CallOption) (*GetToolStatsResponse, error)
+	// Introspection (read-only views of the live agent, e.g. for tool pickers)
+	ListTools(ctx context.Context, in *ListToolsRequest, opts ...grpc.CallOption) (*ListToolsResponse, error)
+	GetToolSchema(ctx context.Context, in *GetToolSchemaRequest, opts ...grpc.CallOption) (*GetToolSchemaResponse, error)
+	ListServers(ctx context.Context, in *ListServersRequest, opts ...grpc.CallOption) (*ListServersResponse, error)
+	ListPrompts(ctx context.Context, in *ListPromptsRequest, opts ...grpc.CallOption) (*ListPromptsResponse, error)
 	// Bidirectional Streaming Conversation
 	// Client sends: questions, tool results, cancel
 	// Server sends: text chunks, tool calls, events, final response
 	Converse(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[ConversationRequest, ConversationResponse], error)
+	// Read-only event stream of an agent's conversations, for UIs watching a
+	// session another client drives. Any number of watchers can subscribe.
+	// Server sends: text chunks and events (tool calls arrive as events)
+	WatchConversation(ctx context.Context, in *WatchConversationRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ConversationResponse], error)
+	// Server-streaming Ask for clients that only render progress: one question
+	// in, typed progress messages out over a single stream, no bidi handling.
+	// Server sends: text chunks, tool call start/end, final response (or error)
+	AskStream(ctx context.Context, in *AskStreamRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[AskStreamResponse], error)
 	// Unary RPCs (backward compatibility, non-streaming)
 	Ask(ctx context.Context, in *AskRequest, opts ...grpc.CallOption) (*AskResponse, error)
 	AskWithHistory(ctx context.Context, in *AskWithHistoryRequest, opts ...grpc.CallOption) (*AskWithHistoryResponse, error)
+	// Cancels a running Ask, AskWithHistory or AskStream by the request_id it
+	// was started with; the call fails with CANCELLED and the agent emits a
+	// conversation_end event with status "cancelled"
+	CancelRequest(ctx context.Context, in *CancelRequestRequest, opts ...grpc.CallOption) (*CancelRequestResponse, error)
 	// Health Check
 	HealthCheck(ctx context.Context, in *HealthCheckRequest, opts ...grpc.CallOption) (*HealthCheckResponse, error)
+	// Readiness, per-MCP-server connection health of the managed agents, MCP
+	// cache status and activity counts. Liveness and readiness probes should
+	// use the standard grpc.health.v1.Health service instead.
+	HealthCheckDetailed(ctx context.Context, in *HealthCheckDetailedRequest, opts ...grpc.CallOption) (*HealthCheckDetailedResponse, error)
+	// Crash Recovery
+	// Lists autosaved conversations that did not finish (requires autosave on the server)
+	ListRecoverableConversations(ctx context.Context, in *ListRecoverableConversationsRequest, opts ...grpc.CallOption) (*ListRecoverableConversationsResponse, error)
 }
 
 type agentServiceClient struct {
@@ -70,6 +115,16 @@ func (c *agentServiceClient) CreateAgent(ctx context.Context, in *CreateAgentReq
 	return out, nil
 }
 
+func (c *agentServiceClient) CreateAgentFromPreset(ctx context.Context, in *CreateAgentFromPresetRequest, opts ...grpc.CallOption) (*CreateAgentResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreateAgentResponse)
+	err := c.cc.Invoke(ctx, AgentService_CreateAgentFromPreset_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *agentServiceClient) GetAgent(ctx context.Context, in *GetAgentRequest, opts ...grpc.CallOption) (*GetAgentResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(GetAgentResponse)
@@ -110,6 +165,66 @@ func (c *agentServiceClient) GetTokenUsage(ctx context.Context, in *GetTokenUsag
 	return out, nil
 }
 
+func (c *agentServiceClient) GetPostMortemBundle(ctx context.Context, in *GetPostMortemBundleRequest, opts ...grpc.CallOption) (*GetPostMortemBundleResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetPostMortemBundleResponse)
+	err := c.cc.Invoke(ctx, AgentService_GetPostMortemBundle_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentServiceClient) GetToolStats(ctx context.Context, in *GetToolStatsRequest, opts ...grpc.CallOption) (*GetToolStatsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetToolStatsResponse)
+	err := c.cc.Invoke(ctx, AgentService_GetToolStats_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentServiceClient) ListTools(ctx context.Context, in *ListToolsRequest, opts ...grpc.CallOption) (*ListToolsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListToolsResponse)
+	err := c.cc.Invoke(ctx, AgentService_ListTools_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentServiceClient) GetToolSchema(ctx context.Context, in *GetToolSchemaRequest, opts ...grpc.CallOption) (*GetToolSchemaResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetToolSchemaResponse)
+	err := c.cc.Invoke(ctx, AgentService_GetToolSchema_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentServiceClient) ListServers(ctx context.Context, in *ListServersRequest, opts ...grpc.CallOption) (*ListServersResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListServersResponse)
+	err := c.cc.Invoke(ctx, AgentService_ListServers_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentServiceClient) ListPrompts(ctx context.Context, in *ListPromptsRequest, opts ...grpc.CallOption) (*ListPromptsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListPromptsResponse)
+	err := c.cc.Invoke(ctx, AgentService_ListPrompts_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *agentServiceClient) Converse(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[ConversationRequest, ConversationResponse], error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	stream, err := c.cc.NewStream(ctx, &AgentService_ServiceDesc.Streams[0], AgentService_Converse_FullMethodName, cOpts...)
@@ -123,6 +238,44 @@ func (c *agentServiceClient) Converse(ctx context.Context, opts ...grpc.CallOpti
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
 type AgentService_ConverseClient = grpc.BidiStreamingClient[ConversationRequest, ConversationResponse]
 
+func (c *agentServiceClient) WatchConversation(ctx context.Context, in *WatchConversationRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ConversationResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &AgentService_ServiceDesc.Streams[1], AgentService_WatchConversation_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchConversationRequest, ConversationResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type AgentService_WatchConversationClient = grpc.ServerStreamingClient[ConversationResponse]
+
+func (c *agentServiceClient) AskStream(ctx context.Context, in *AskStreamRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[AskStreamResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &AgentService_ServiceDesc.Streams[2], AgentService_AskStream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[AskStreamRequest, AskStreamResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type AgentService_AskStreamClient = grpc.ServerStreamingClient[AskStreamResponse]
+
 func (c *agentServiceClient) Ask(ctx context.Context, in *AskRequest, opts ...grpc.CallOption) (*AskResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(AskResponse)
@@ -143,6 +296,16 @@ func (c *agentServiceClient) AskWithHistory(ctx context.Context, in *AskWithHist
 	return out, nil
 }
 
+func (c *agentServiceClient) CancelRequest(ctx context.Context, in *CancelRequestRequest, opts ...grpc.CallOption) (*CancelRequestResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CancelRequestResponse)
+	err := c.cc.Invoke(ctx, AgentService_CancelRequest_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *agentServiceClient) HealthCheck(ctx context.Context, in *HealthCheckRequest, opts ...grpc.CallOption) (*HealthCheckResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(HealthCheckResponse)
@@ -153,26 +316,79 @@ func (c *agentServiceClient) HealthCheck(ctx context.Context, in *HealthCheckReq
 	return out, nil
 }
 
+func (c *agentServiceClient) HealthCheckDetailed(ctx context.Context, in *HealthCheckDetailedRequest, opts ...grpc.CallOption) (*HealthCheckDetailedResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(HealthCheckDetailedResponse)
+	err := c.cc.Invoke(ctx, AgentService_HealthCheckDetailed_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentServiceClient) ListRecoverableConversations(ctx context.Context, in *ListRecoverableConversationsRequest, opts ...grpc.CallOption) (*ListRecoverableConversationsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListRecoverableConversationsResponse)
+	err := c.cc.Invoke(ctx, AgentService_ListRecoverableConversations_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // AgentServiceServer is the server API for AgentService service.
 // All implementations must embed UnimplementedAgentServiceServer
 // for forward compatibility.
 type AgentServiceServer interface {
 	// Agent Lifecycle
 	CreateAgent(context.Context, *CreateAgentRequest) (*CreateAgentResponse, error)
+	// Create an agent from a preset registered on the server (--presets)
+	CreateAgentFromPreset(context.Context, *CreateAgentFromPresetRequest) (*CreateAgentResponse, error)
 	GetAgent(context.Context, *GetAgentRequest) (*GetAgentResponse, error)
 	ListAgents(context.Context, *ListAgentsRequest) (*ListAgentsResponse, error)
 	DestroyAgent(context.Context, *DestroyAgentRequest) (*DestroyAgentResponse, error)
 	// Token Usage
 	GetTokenUsage(context.Context, *GetTokenUsageRequest) (*TokenUsageResponse, error)
+	// Diagnostics
+	// Returns the post-mortem bundle of the agent's last failed conversation
+	// (requires post-mortem bundles on the server)
+	GetPostMortemBundle(context.Context, *GetPostMortemBundleRequest) (*GetPostMortemBundleResponse, error)
+	// Per-tool and per-server call counts, error rates, latencies and bytes
+	// returned since the agent was created
+	GetToolStats(context.Context, *GetToolStatsRequest) (*GetToolStatsResponse, error)
+	// Introspection (read-only views of the live agent, e.g. for tool pickers)
+	ListTools(context.Context, *ListToolsRequest) (*ListToolsResponse, error)
+	GetToolSchema(context.Context, *GetToolSchemaRequest) (*GetToolSchemaResponse, error)
+	ListServers(context.Context, *ListServersRequest) (*ListServersResponse, error)
+	ListPrompts(context.Context, *ListPromptsRequest) (*ListPromptsResponse, error)
 	// Bidirectional Streaming Conversation
 	// Client sends: questions, tool results, cancel
 	// Server sends: text chunks, tool calls, events, final response
 	Converse(grpc.BidiStreamingServer[ConversationRequest, ConversationResponse]) error
+	// Read-only event stream of an agent's conversations, for UIs watching a
+	// session another client drives. Any number of watchers can subscribe.
+	// Server sends: text chunks and events (tool calls arrive as events)
+	WatchConversation(*WatchConversationRequest, grpc.ServerStreamingServer[ConversationResponse]) error
+	// Server-streaming Ask for clients that only render progress: one question
+	// in, typed progress messages out over a single stream, no bidi handling.
+	// Server sends: text chunks, tool call start/end, final response (or error)
+	AskStream(*AskStreamRequest, grpc.ServerStreamingServer[AskStreamResponse]) error
 	// Unary RPCs (backward compatibility, non-streaming)
 	Ask(context.Context, *AskRequest) (*AskResponse, error)
 	AskWithHistory(context.Context, *AskWithHistoryRequest) (*AskWithHistoryResponse, error)
+	// Cancels a running Ask, AskWithHistory or AskStream by the request_id it
+	// was started with; the call fails with CANCELLED and the agent emits a
+	// conversation_end event with status "cancelled"
+	CancelRequest(context.Context, *CancelRequestRequest) (*CancelRequestResponse, error)
 	// Health Check
 	HealthCheck(context.Context, *HealthCheckRequest) (*HealthCheckResponse, error)
+	// Readiness, per-MCP-server connection health of the managed agents, MCP
+	// cache status and activity counts. Liveness and readiness probes should
+	// use the standard grpc.health.v1.Health service instead.
+	HealthCheckDetailed(context.Context, *HealthCheckDetailedRequest) (*HealthCheckDetailedResponse, error)
+	// Crash Recovery
+	// Lists autosaved conversations that did not finish (requires autosave on the server)
+	ListRecoverableConversations(context.Context, *ListRecoverableConversationsRequest) (*ListRecoverableConversationsResponse, error)
 	mustEmbedUnimplementedAgentServiceServer()
 }
 
@@ -186,6 +402,9 @@ type UnimplementedAgentServiceServer struct{}
 func (UnimplementedAgentServiceServer) CreateAgent(context.Context, *CreateAgentRequest) (*CreateAgentResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method CreateAgent not implemented")
 }
+func (UnimplementedAgentServiceServer) CreateAgentFromPreset(context.Context, *CreateAgentFromPresetRequest) (*CreateAgentResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method CreateAgentFromPreset not implemented")
+}
 func (UnimplementedAgentServiceServer) GetAgent(context.Context, *GetAgentRequest) (*GetAgentResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method GetAgent not implemented")
 }
@@ -198,18 +417,51 @@ func (UnimplementedAgentServiceServer) DestroyAgent(context.Context, *DestroyAge
 func (UnimplementedAgentServiceServer) GetTokenUsage(context.Context, *GetTokenUsageRequest) (*TokenUsageResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method GetTokenUsage not implemented")
 }
+func (UnimplementedAgentServiceServer) GetPostMortemBundle(context.Context, *GetPostMortemBundleRequest) (*GetPostMortemBundleResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetPostMortemBundle not implemented")
+}
+func (UnimplementedAgentServiceServer) GetToolStats(context.Context, *GetToolStatsRequest) (*GetToolStatsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetToolStats not implemented")
+}
+func (UnimplementedAgentServiceServer) ListTools(context.Context, *ListToolsRequest) (*ListToolsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListTools not implemented")
+}
+func (UnimplementedAgentServiceServer) GetToolSchema(context.Context, *GetToolSchemaRequest) (*GetToolSchemaResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetToolSchema not implemented")
+}
+func (UnimplementedAgentServiceServer) ListServers(context.Context, *ListServersRequest) (*ListServersResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListServers not implemented")
+}
+func (UnimplementedAgentServiceServer) ListPrompts(context.Context, *ListPromptsRequest) (*ListPromptsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListPrompts not implemented")
+}
 func (UnimplementedAgentServiceServer) Converse(grpc.BidiStreamingServer[ConversationRequest, ConversationResponse]) error {
 	return status.Error(codes.Unimplemented, "method Converse not implemented")
 }
+func (UnimplementedAgentServiceServer) WatchConversation(*WatchConversationRequest, grpc.ServerStreamingServer[ConversationResponse]) error {
+	return status.Error(codes.Unimplemented, "method WatchConversation not implemented")
+}
+func (UnimplementedAgentServiceServer) AskStream(*AskStreamRequest, grpc.ServerStreamingServer[AskStreamResponse]) error {
+	return status.Error(codes.Unimplemented, "method AskStream not implemented")
+}
 func (UnimplementedAgentServiceServer) Ask(context.Context, *AskRequest) (*AskResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method Ask not implemented")
 }
 func (UnimplementedAgentServiceServer) AskWithHistory(context.Context, *AskWithHistoryRequest) (*AskWithHistoryResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method AskWithHistory not implemented")
 }
+func (UnimplementedAgentServiceServer) CancelRequest(context.Context, *CancelRequestRequest) (*CancelRequestResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method CancelRequest not implemented")
+}
 func (UnimplementedAgentServiceServer) HealthCheck(context.Context, *HealthCheckRequest) (*HealthCheckResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method HealthCheck not implemented")
 }
+func (UnimplementedAgentServiceServer) HealthCheckDetailed(context.Context, *HealthCheckDetailedRequest) (*HealthCheckDetailedResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method HealthCheckDetailed not implemented")
+}
+func (UnimplementedAgentServiceServer) ListRecoverableConversations(context.Context, *ListRecoverableConversationsRequest) (*ListRecoverableConversationsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListRecoverableConversations not implemented")
+}
 func (UnimplementedAgentServiceServer) mustEmbedUnimplementedAgentServiceServer() {}
 func (UnimplementedAgentServiceServer) testEmbeddedByValue()                      {}
 
@@ -249,6 +501,24 @@ func _AgentService_CreateAgent_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _AgentService_CreateAgentFromPreset_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateAgentFromPresetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).CreateAgentFromPreset(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_CreateAgentFromPreset_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).CreateAgentFromPreset(ctx, req.(*CreateAgentFromPresetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _AgentService_GetAgent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(GetAgentRequest)
 	if err := dec(in); err != nil {
@@ -321,6 +591,114 @@ func _AgentService_GetTokenUsage_Handler(srv interface{}, ctx context.Context, d
 	return interceptor(ctx, in, info, handler)
 }
 
+func _AgentService_GetPostMortemBundle_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPostMortemBundleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).GetPostMortemBundle(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_GetPostMortemBundle_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).GetPostMortemBundle(ctx, req.(*GetPostMortemBundleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentService_GetToolStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetToolStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).GetToolStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_GetToolStats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).GetToolStats(ctx, req.(*GetToolStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentService_ListTools_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListToolsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).ListTools(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_ListTools_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).ListTools(ctx, req.(*ListToolsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentService_GetToolSchema_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetToolSchemaRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).GetToolSchema(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_GetToolSchema_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).GetToolSchema(ctx, req.(*GetToolSchemaRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentService_ListServers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListServersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).ListServers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_ListServers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).ListServers(ctx, req.(*ListServersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentService_ListPrompts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListPromptsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).ListPrompts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_ListPrompts_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).ListPrompts(ctx, req.(*ListPromptsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _AgentService_Converse_Handler(srv interface{}, stream grpc.ServerStream) error {
 	return srv.(AgentServiceServer).Converse(&grpc.GenericServerStream[ConversationRequest, ConversationResponse]{ServerStream: stream})
 }
@@ -328,6 +706,28 @@ func _AgentService_Converse_Handler(srv interface{}, stream grpc.ServerStream) e
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
 type AgentService_ConverseServer = grpc.BidiStreamingServer[ConversationRequest, ConversationResponse]
 
+func _AgentService_WatchConversation_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchConversationRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(AgentServiceServer).WatchConversation(m, &grpc.GenericServerStream[WatchConversationRequest, ConversationResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type AgentService_WatchConversationServer = grpc.ServerStreamingServer[ConversationResponse]
+
+func _AgentService_AskStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(AskStreamRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(AgentServiceServer).AskStream(m, &grpc.GenericServerStream[AskStreamRequest, AskStreamResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type AgentService_AskStreamServer = grpc.ServerStreamingServer[AskStreamResponse]
+
 func _AgentService_Ask_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(AskRequest)
 	if err := dec(in); err != nil {
@@ -364,6 +764,24 @@ func _AgentService_AskWithHistory_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _AgentService_CancelRequest_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancelRequestRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).CancelRequest(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_CancelRequest_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).CancelRequest(ctx, req.(*CancelRequestRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _AgentService_HealthCheck_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(HealthCheckRequest)
 	if err := dec(in); err != nil {
@@ -382,6 +800,42 @@ func _AgentService_HealthCheck_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _AgentService_HealthCheckDetailed_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HealthCheckDetailedRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).HealthCheckDetailed(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_HealthCheckDetailed_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).HealthCheckDetailed(ctx, req.(*HealthCheckDetailedRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentService_ListRecoverableConversations_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRecoverableConversationsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).ListRecoverableConversations(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_ListRecoverableConversations_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).ListRecoverableConversations(ctx, req.(*ListRecoverableConversationsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // AgentService_ServiceDesc is the grpc.ServiceDesc for AgentService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -393,6 +847,10 @@ var AgentService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "CreateAgent",
 			Handler:    _AgentService_CreateAgent_Handler,
 		},
+		{
+			MethodName: "CreateAgentFromPreset",
+			Handler:    _AgentService_CreateAgentFromPreset_Handler,
+		},
 		{
 			MethodName: "GetAgent",
 			Handler:    _AgentService_GetAgent_Handler,
@@ -409,6 +867,30 @@ var AgentService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetTokenUsage",
 			Handler:    _AgentService_GetTokenUsage_Handler,
 		},
+		{
+			MethodName: "GetPostMortemBundle",
+			Handler:    _AgentService_GetPostMortemBundle_Handler,
+		},
+		{
+			MethodName: "GetToolStats",
+			Handler:    _AgentService_GetToolStats_Handler,
+		},
+		{
+			MethodName: "ListTools",
+			Handler:    _AgentService_ListTools_Handler,
+		},
+		{
+			MethodName: "GetToolSchema",
+			Handler:    _AgentService_GetToolSchema_Handler,
+		},
+		{
+			MethodName: "ListServers",
+			Handler:    _AgentService_ListServers_Handler,
+		},
+		{
+			MethodName: "ListPrompts",
+			Handler:    _AgentService_ListPrompts_Handler,
+		},
 		{
 			MethodName: "Ask",
 			Handler:    _AgentService_Ask_Handler,
@@ -417,10 +899,22 @@ var AgentService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "AskWithHistory",
 			Handler:    _AgentService_AskWithHistory_Handler,
 		},
+		{
+			MethodName: "CancelRequest",
+			Handler:    _AgentService_CancelRequest_Handler,
+		},
 		{
 			MethodName: "HealthCheck",
 			Handler:    _AgentService_HealthCheck_Handler,
 		},
+		{
+			MethodName: "HealthCheckDetailed",
+			Handler:    _AgentService_HealthCheckDetailed_Handler,
+		},
+		{
+			MethodName: "ListRecoverableConversations",
+			Handler:    _AgentService_ListRecoverableConversations_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -429,6 +923,16 @@ var AgentService_ServiceDesc = grpc.ServiceDesc{
 			ServerStreams: true,
 			ClientStreams: true,
 		},
+		{
+			StreamName:    "WatchConversation",
+			Handler:       _AgentService_WatchConversation_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "AskStream",
+			Handler:       _AgentService_AskStream_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: "agent.proto",
 }
@@ -2,15 +2,21 @@ package grpcserver
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"net"
 	"os"
 	"time"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/keepalive"
 
+	mcpagent "github.com/manishiitg/mcpagent/agent"
 	"github.com/manishiitg/mcpagent/grpcserver/pb"
 	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+	"github.com/manishiitg/mcpagent/metrics"
 )
 
 // Server represents the gRPC server for MCPAgent
@@ -18,9 +24,26 @@ type Server struct {
 	grpcServer *grpc.Server
 	listener   net.Listener
 	socketPath string
+	socketFile os.FileInfo // The socket this server created; a successor may have replaced it
 	manager    *AgentManager
 	service    *AgentService
 	logger     loggerv2.Logger
+
+	artifacts    *ArtifactServer
+	artifactsErr error // Set when the artifact server config is invalid; returned by Start
+
+	eventBridge    *EventBridgeServer
+	eventBridgeErr error // Set when the event bridge config is invalid; returned by Start
+
+	authErr error // Set when the auth config is invalid; returned by Start
+
+	configPath string
+	readiness  *readiness
+	health     *HealthServer
+	metrics    *MetricsServer
+	collector  *metrics.Collector
+	warmPools  []WarmPoolConfig
+	janitor    *mcpagent.StorageJanitor
 }
 
 // Config holds gRPC server configuration
@@ -30,6 +53,58 @@ type Config struct {
 	Logger            loggerv2.Logger
 	// Optional: share an existing AgentManager
 	Manager *AgentManager
+	// Optional: checkpoint conversations of managed agents every
+	// AutosaveEveryTurns turns so they can be listed via
+	// ListRecoverableConversations after a crash
+	ConversationStore  mcpagent.ConversationStore
+	AutosaveEveryTurns int
+	// Optional: stream events of managed agents and retain the last
+	// StreamReplaySize of them, so WatchConversation subscribers that join a
+	// running conversation catch up first. 0 disables WatchConversation.
+	StreamReplaySize int
+	// Optional: ceiling on the session state (histories, replay buffers,
+	// caches) retained by all agents. When exceeded, idle agents' state is
+	// spilled to ConversationStore or dropped per MemoryPolicy, oldest first,
+	// and a MemoryPressure event is emitted. 0 disables the ceiling.
+	MemoryLimitBytes int64
+	MemoryPolicy     mcpagent.MemoryPolicy // "spill" (default) or "drop"
+	// Optional: serve workspace and tool output files over authenticated HTTP.
+	// When set, events and final responses include download URLs for files
+	// inside the configured roots.
+	Artifacts *ArtifactServerConfig
+	// Optional: expose WatchConversation's per-agent event stream to
+	// browsers over Server-Sent Events and WebSocket. Requires
+	// StreamReplaySize > 0.
+	EventBridge *EventBridgeConfig
+	// Optional: TCP address (e.g. "127.0.0.1:8090") serving /healthz
+	// (process up) and /readyz (gRPC serving, config loaded, MCP preflight
+	// passed) for orchestrators.
+	HealthAddr string
+	// Optional: TCP address (e.g. "127.0.0.1:9090") serving Prometheus
+	// metrics at /metrics: gRPC requests plus LLM calls, tokens, tool call
+	// durations and errors of all managed agents
+	MetricsAddr string
+	// Optional: pools of agents created at Start and handed out by
+	// CreateAgent requests that name them, refilled in the background
+	WarmPools []WarmPoolConfig
+	// Optional: bound offloaded tool outputs and workspaces by age and total
+	// size with one janitor for all agents (Retention.Roots, default the tool
+	// output folder), and clean each session's tool outputs when its agent is
+	// destroyed if CleanupSessionOnEnd is set
+	Retention *mcpagent.RetentionPolicy
+	// Optional: run at most this many conversations at once across all
+	// agents; further conversations queue and are admitted by request
+	// priority (high, normal, low). 0 = unlimited.
+	MaxConcurrentConversations int
+	// Optional: build a diagnostic bundle (redacted event log, messages,
+	// config and environment) whenever a conversation fails, written to
+	// PostMortem.Dir when set and returned by GetPostMortemBundle
+	PostMortem *mcpagent.PostMortemConfig
+	// Optional: require a static client token or JWT on every request and
+	// limit each client to the RPCs, servers and tools of its scope and to
+	// the agents it created (see AuthConfig). The artifact server and event
+	// bridge then authenticate the same clients instead of their shared Token.
+	Auth *AuthConfig
 }
 
 // NewServer creates a new gRPC server
@@ -45,6 +120,48 @@ func NewServer(cfg Config) *Server {
 		manager = NewAgentManager(logger, cfg.DefaultConfigPath)
 	}
 
+	if cfg.ConversationStore != nil {
+		manager.SetAutosave(cfg.ConversationStore, cfg.AutosaveEveryTurns)
+	}
+
+	if cfg.StreamReplaySize > 0 {
+		manager.SetStreamReplay(cfg.StreamReplaySize)
+	}
+
+	if cfg.MemoryLimitBytes > 0 {
+		manager.SetMemoryLimit(cfg.MemoryLimitBytes, cfg.MemoryPolicy)
+	}
+
+	if cfg.MaxConcurrentConversations > 0 {
+		manager.SetMaxConcurrentConversations(cfg.MaxConcurrentConversations)
+	}
+
+	if cfg.PostMortem != nil {
+		manager.SetPostMortem(*cfg.PostMortem)
+	}
+
+	var collector *metrics.Collector
+	var unaryInterceptors []grpc.UnaryServerInterceptor
+	var streamInterceptors []grpc.StreamServerInterceptor
+	if cfg.MetricsAddr != "" {
+		collector = metrics.NewCollector()
+		manager.SetMetrics(collector)
+		gm := newGRPCMetrics(collector.Registry())
+		unaryInterceptors = append(unaryInterceptors, gm.unaryInterceptor)
+		streamInterceptors = append(streamInterceptors, gm.streamInterceptor)
+	}
+
+	// Authenticate after the metrics interceptor so refused requests are counted
+	var auth *authenticator
+	var authErr error
+	if cfg.Auth != nil {
+		auth, authErr = newAuthenticator(*cfg.Auth, manager, logger)
+		if authErr == nil {
+			unaryInterceptors = append(unaryInterceptors, auth.unaryInterceptor)
+			streamInterceptors = append(streamInterceptors, auth.streamInterceptor)
+		}
+	}
+
 	// Create gRPC server with keepalive settings
 	grpcServer := grpc.NewServer(
 		grpc.KeepaliveParams(keepalive.ServerParameters{
@@ -61,23 +178,78 @@ func NewServer(cfg Config) *Server {
 		// Allow large messages for tool outputs
 		grpc.MaxRecvMsgSize(100*1024*1024), // 100MB
 		grpc.MaxSendMsgSize(100*1024*1024), // 100MB
+		grpc.ChainUnaryInterceptor(unaryInterceptors...),
+		grpc.ChainStreamInterceptor(streamInterceptors...),
 	)
 
 	// Create and register the service
 	service := NewAgentService(manager, logger)
 	pb.RegisterAgentServiceServer(grpcServer, service)
 
-	return &Server{
+	// Standard grpc.health.v1 service for probes, mirroring /readyz
+	grpcHealth := health.NewServer()
+	healthpb.RegisterHealthServer(grpcServer, grpcHealth)
+
+	server := &Server{
 		grpcServer: grpcServer,
 		socketPath: cfg.SocketPath,
 		manager:    manager,
 		service:    service,
 		logger:     logger,
+		configPath: cfg.DefaultConfigPath,
+		readiness:  &readiness{grpcHealth: grpcHealth},
+		collector:  collector,
+		warmPools:  cfg.WarmPools,
+		authErr:    authErr,
 	}
+	server.readiness.publish()
+	service.readiness = server.readiness
+
+	if cfg.Retention != nil {
+		manager.SetRetention(*cfg.Retention)
+		policy := *cfg.Retention
+		if len(policy.Roots) == 0 {
+			policy.Roots = []string{mcpagent.DefaultToolOutputFolder}
+		}
+		server.janitor = mcpagent.NewStorageJanitor(policy, logger)
+	}
+
+	if cfg.HealthAddr != "" {
+		server.health = newHealthServer(cfg.HealthAddr, server.readiness, logger)
+	}
+
+	if collector != nil {
+		server.metrics = newMetricsServer(cfg.MetricsAddr, collector, logger)
+	}
+
+	if cfg.Artifacts != nil {
+		server.artifacts, server.artifactsErr = newArtifactServer(*cfg.Artifacts, auth, logger)
+		service.artifacts = server.artifacts
+	}
+
+	if cfg.EventBridge != nil {
+		if cfg.StreamReplaySize <= 0 {
+			server.eventBridgeErr = errors.New("StreamReplaySize must be > 0")
+		} else {
+			server.eventBridge, server.eventBridgeErr = newEventBridgeServer(*cfg.EventBridge, manager, server.artifacts, auth, logger)
+		}
+	}
+
+	return server
 }
 
 // Start starts the gRPC server on a Unix domain socket
 func (s *Server) Start() error {
+	if s.authErr != nil {
+		return fmt.Errorf("invalid auth config: %w", s.authErr)
+	}
+	if s.artifactsErr != nil {
+		return fmt.Errorf("invalid artifact server config: %w", s.artifactsErr)
+	}
+	if s.eventBridgeErr != nil {
+		return fmt.Errorf("invalid event bridge config: %w", s.eventBridgeErr)
+	}
+
 	// Remove existing socket file if it exists
 	if err := os.Remove(s.socketPath); err != nil && !os.IsNotExist(err) {
 		return err
@@ -94,14 +266,100 @@ func (s *Server) Start() error {
 		return err
 	}
 	s.listener = listener
+	s.socketFile, _ = os.Stat(s.socketPath)
+
+	if err := s.ReloadConfig(); err != nil {
+		s.logger.Warn("MCP config preflight failed; /readyz reports not ready", loggerv2.String("error", err.Error()))
+	}
+
+	if len(s.warmPools) > 0 {
+		if err := s.manager.StartWarmPools(context.Background(), s.warmPools); err != nil {
+			_ = listener.Close()
+			_ = os.Remove(s.socketPath)
+			return fmt.Errorf("invalid warm pool config: %w", err)
+		}
+	}
+
+	if s.janitor != nil {
+		s.janitor.Start(context.Background())
+	}
+
+	if s.health != nil {
+		go func() {
+			if err := s.health.Start(); err != nil {
+				s.logger.Error("Health server error", err)
+			}
+		}()
+	}
+
+	if s.metrics != nil {
+		go func() {
+			if err := s.metrics.Start(); err != nil {
+				s.logger.Error("Metrics server error", err)
+			}
+		}()
+	}
+
+	if s.artifacts != nil {
+		go func() {
+			if err := s.artifacts.Start(); err != nil {
+				s.logger.Error("Artifact server error", err)
+			}
+		}()
+	}
+
+	if s.eventBridge != nil {
+		go func() {
+			if err := s.eventBridge.Start(); err != nil {
+				s.logger.Error("Event bridge error", err)
+			}
+		}()
+	}
 
 	s.logger.Info("Starting gRPC server on Unix socket", loggerv2.String("socket", s.socketPath))
+	s.readiness.setServing(true)
+	defer s.readiness.setServing(false)
 	return s.grpcServer.Serve(listener)
 }
 
-// Shutdown gracefully shuts down the server
+// ReloadConfig re-reads the default MCP config and re-runs the preflight
+// (stdio commands resolve, remote servers have URLs), updating /readyz.
+// Agents created afterwards use the reloaded file. cmd/server calls it on SIGHUP.
+func (s *Server) ReloadConfig() error {
+	configErr, preflightErr := checkMCPConfig(s.configPath)
+	s.readiness.setConfig(configErr, preflightErr)
+	if configErr != nil {
+		return fmt.Errorf("failed to load MCP config: %w", configErr)
+	}
+	if preflightErr != nil {
+		return fmt.Errorf("MCP preflight failed: %w", preflightErr)
+	}
+	return nil
+}
+
+// Readiness returns the state reported by /readyz
+func (s *Server) Readiness() ReadinessStatus {
+	return s.readiness.status()
+}
+
+// drainGracePeriod is how long Shutdown waits for conversations cancelled at
+// the drain deadline to return, so their autosave checkpoints are written
+const drainGracePeriod = 5 * time.Second
+
+// Shutdown drains and stops the server. /readyz turns unready, new agents and
+// conversations are refused with SERVER_DRAINING, open Converse streams get a
+// shutdown notice, and running conversations may finish until ctx is done;
+// the ones still running then are cancelled. Finally the agents' session
+// state is saved to the autosave store, if any, for ListRecoverableConversations.
+//
+// For a zero-downtime restart, start the new server on the same socket path
+// before shutting down the old one: new clients connect to the new server
+// while the old one drains, and the old one leaves the new socket in place.
 func (s *Server) Shutdown(ctx context.Context) error {
 	s.logger.Info("Shutting down gRPC server")
+	s.readiness.setServing(false)
+
+	drainErr := s.manager.Drain(ctx)
 
 	// Graceful stop with timeout
 	done := make(chan struct{})
@@ -118,14 +376,62 @@ func (s *Server) Shutdown(ctx context.Context) error {
 		s.grpcServer.Stop()
 	}
 
-	// Clean up socket file
+	if drainErr != nil {
+		graceCtx, cancel := context.WithTimeout(context.Background(), drainGracePeriod)
+		if err := s.manager.waitIdle(graceCtx); err != nil {
+			s.logger.Warn("Cancelled conversations did not finish in time")
+		}
+		cancel()
+	}
+	if saved := s.manager.PersistSessions(context.Background()); saved > 0 {
+		s.logger.Info("Persisted agent session state", loggerv2.Int("agents", saved))
+	}
+
+	s.manager.StopWarmPools()
+
+	if s.janitor != nil {
+		s.janitor.Stop()
+	}
+
+	if s.health != nil {
+		if err := s.health.Shutdown(ctx); err != nil {
+			s.logger.Warn("Health server shutdown failed", loggerv2.String("error", err.Error()))
+		}
+	}
+
+	if s.metrics != nil {
+		if err := s.metrics.Shutdown(ctx); err != nil {
+			s.logger.Warn("Metrics server shutdown failed", loggerv2.String("error", err.Error()))
+		}
+	}
+
+	if s.artifacts != nil {
+		if err := s.artifacts.Shutdown(ctx); err != nil {
+			s.logger.Warn("Artifact server shutdown failed", loggerv2.String("error", err.Error()))
+		}
+	}
+
+	if s.eventBridge != nil {
+		if err := s.eventBridge.Shutdown(ctx); err != nil {
+			s.logger.Warn("Event bridge shutdown failed", loggerv2.String("error", err.Error()))
+		}
+	}
+
+	// Clean up the socket file unless a new server already replaced it
 	if s.socketPath != "" {
-		_ = os.Remove(s.socketPath)
+		if current, err := os.Stat(s.socketPath); err == nil && (s.socketFile == nil || os.SameFile(current, s.socketFile)) {
+			_ = os.Remove(s.socketPath)
+		}
 	}
 
 	return nil
 }
 
+// Metrics returns the metrics collector, or nil when MetricsAddr is not set
+func (s *Server) Metrics() *metrics.Collector {
+	return s.collector
+}
+
 // GetManager returns the agent manager
 func (s *Server) GetManager() *AgentManager {
 	return s.manager
@@ -2,12 +2,14 @@ package grpcserver
 
 import (
 	"context"
+	"fmt"
+	"sort"
 	"time"
 
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
 	"google.golang.org/protobuf/types/known/timestamppb"
 
+	mcpagent "github.com/manishiitg/mcpagent/agent"
 	"github.com/manishiitg/mcpagent/grpcserver/pb"
 	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
 
@@ -17,8 +19,10 @@ import (
 // AgentService implements the gRPC AgentService
 type AgentService struct {
 	pb.UnimplementedAgentServiceServer
-	manager *AgentManager
-	logger  loggerv2.Logger
+	manager   *AgentManager
+	logger    loggerv2.Logger
+	artifacts *ArtifactServer // nil when the artifact server is disabled
+	readiness *readiness      // nil when not run by a Server
 }
 
 // NewAgentService creates a new AgentService
@@ -36,24 +40,66 @@ func (s *AgentService) HealthCheck(ctx context.Context, req *pb.HealthCheckReque
 	}, nil
 }
 
+// ListRecoverableConversations lists autosaved conversations that did not
+// finish. With authentication on, clients only see their own conversations.
+func (s *AgentService) ListRecoverableConversations(ctx context.Context, req *pb.ListRecoverableConversationsRequest) (*pb.ListRecoverableConversationsResponse, error) {
+	store := s.manager.AutosaveStore()
+	if store == nil {
+		return &pb.ListRecoverableConversationsResponse{}, nil
+	}
+
+	checkpoints, err := mcpagent.RecoverConversations(ctx, store)
+	if err != nil {
+		return nil, newStatusError(ReasonInternal, fmt.Sprintf("failed to list checkpoints: %v", err), nil, 0)
+	}
+
+	client, authenticated := ClientFromContext(ctx)
+	conversations := make([]*pb.RecoverableConversation, 0, len(checkpoints))
+	for _, cp := range checkpoints {
+		if authenticated && !client.owns(cp.Owner) {
+			continue
+		}
+		conversations = append(conversations, &pb.RecoverableConversation{
+			Id:        cp.ID,
+			SessionId: cp.SessionID,
+			UserId:    cp.UserID,
+			Provider:  cp.Provider,
+			ModelId:   cp.ModelID,
+			Question:  cp.Question,
+			Turn:      safeIntToInt32(cp.Turn),
+			LastError: cp.LastError,
+			UpdatedAt: timestamppb.New(cp.UpdatedAt),
+			Messages:  messagesToProto(cp.Messages),
+		})
+	}
+	return &pb.ListRecoverableConversationsResponse{Conversations: conversations}, nil
+}
+
 // CreateAgent creates a new agent instance
 func (s *AgentService) CreateAgent(ctx context.Context, req *pb.CreateAgentRequest) (*pb.CreateAgentResponse, error) {
 	// Convert protobuf config to AgentConfig
+	if err := s.manager.drainingError(); err != nil {
+		return nil, err
+	}
 	config, err := s.convertAgentConfig(req.Config)
 	if err != nil {
-		return nil, status.Errorf(codes.InvalidArgument, "invalid config: %v", err)
+		return nil, invalidArgumentError("invalid config: " + err.Error())
+	}
+	if req.WarmPool != "" && !s.manager.HasWarmPool(req.WarmPool) {
+		return nil, warmPoolNotFoundError(req.WarmPool)
 	}
 
 	// Create the agent using the manager
 	createReq := CreateAgentRequest{
 		SessionID: req.SessionId,
 		Config:    config,
+		WarmPool:  req.WarmPool,
 	}
 
 	agent, err := s.manager.CreateAgent(ctx, createReq)
 	if err != nil {
 		s.logger.Error("Failed to create agent", err)
-		return nil, status.Errorf(codes.Internal, "failed to create agent: %v", err)
+		return nil, agentError(err, "failed to create agent", nil)
 	}
 
 	// Get capabilities
@@ -71,20 +117,59 @@ func (s *AgentService) CreateAgent(ctx context.Context, req *pb.CreateAgentReque
 	}, nil
 }
 
+// CreateAgentFromPreset creates an agent from a preset registered on the server
+func (s *AgentService) CreateAgentFromPreset(ctx context.Context, req *pb.CreateAgentFromPresetRequest) (*pb.CreateAgentResponse, error) {
+	if req.Preset == "" {
+		return nil, invalidArgumentError("preset is required")
+	}
+	if err := s.manager.drainingError(); err != nil {
+		return nil, err
+	}
+	if _, ok := mcpagent.GetPreset(req.Preset); !ok {
+		return nil, presetNotFoundError(req.Preset)
+	}
+
+	config, err := s.convertAgentConfig(req.Config)
+	if err != nil {
+		return nil, invalidArgumentError("invalid config: " + err.Error())
+	}
+
+	agent, err := s.manager.CreateAgent(ctx, CreateAgentRequest{
+		SessionID:       req.SessionId,
+		Config:          config,
+		Preset:          req.Preset,
+		PresetVariables: req.Variables,
+	})
+	if err != nil {
+		s.logger.Error("Failed to create agent from preset", err)
+		return nil, agentError(err, "failed to create agent", map[string]string{"preset": req.Preset})
+	}
+
+	caps, _ := s.manager.GetCapabilities(agent.ID)
+
+	return &pb.CreateAgentResponse{
+		AgentId:   agent.ID,
+		SessionId: agent.SessionID,
+		Status:    "ready",
+		CreatedAt: timestamppb.New(agent.CreatedAt),
+		Capabilities: &pb.Capabilities{
+			Tools:   caps.Tools,
+			Servers: caps.Servers,
+		},
+	}, nil
+}
+
 // GetAgent retrieves information about an agent
 func (s *AgentService) GetAgent(ctx context.Context, req *pb.GetAgentRequest) (*pb.GetAgentResponse, error) {
 	if req.AgentId == "" {
-		return nil, status.Error(codes.InvalidArgument, "agent_id is required")
+		return nil, invalidArgumentError("agent_id is required")
 	}
 
 	agent, ok := s.manager.GetAgent(req.AgentId)
 	if !ok {
-		return nil, status.Errorf(codes.NotFound, "agent not found: %s", req.AgentId)
+		return nil, agentNotFoundError(req.AgentId)
 	}
 
-	// Get token usage
-	promptTokens, completionTokens, totalTokens, cacheTokens, reasoningTokens, llmCallCount, _ := agent.Agent.GetTokenUsage()
-
 	caps, _ := s.manager.GetCapabilities(agent.ID)
 
 	return &pb.GetAgentResponse{
@@ -96,14 +181,10 @@ func (s *AgentService) GetAgent(ctx context.Context, req *pb.GetAgentRequest) (*
 			Tools:   caps.Tools,
 			Servers: caps.Servers,
 		},
-		TokenUsage: &pb.TokenUsage{
-			PromptTokens:     safeIntToInt32(promptTokens),
-			CompletionTokens: safeIntToInt32(completionTokens),
-			TotalTokens:      safeIntToInt32(totalTokens),
-			CacheTokens:      safeIntToInt32(cacheTokens),
-			ReasoningTokens:  safeIntToInt32(reasoningTokens),
-			LlmCallCount:     safeIntToInt32(llmCallCount),
-		},
+		TokenUsage:        agentTokenUsageToProto(agent.Agent),
+		Tools:             agentToolsToProto(agent.Agent),
+		Filters:           agentFiltersToProto(agent.Agent),
+		CodeExecutionMode: agent.Agent.UseCodeExecutionMode,
 	}, nil
 }
 
@@ -119,6 +200,13 @@ func (s *AgentService) ListAgents(ctx context.Context, req *pb.ListAgentsRequest
 			Status:    agent.Status,
 			CreatedAt: timestamppb.New(agent.CreatedAt),
 		}
+		// The agent may have been destroyed since the list was taken
+		if managed, ok := s.manager.GetAgent(agent.AgentID); ok {
+			pbAgents[i].TokenUsage = agentTokenUsageToProto(managed.Agent)
+			pbAgents[i].Tools = agentToolsToProto(managed.Agent)
+			pbAgents[i].Filters = agentFiltersToProto(managed.Agent)
+			pbAgents[i].CodeExecutionMode = managed.Agent.UseCodeExecutionMode
+		}
 	}
 
 	return &pb.ListAgentsResponse{
@@ -129,11 +217,11 @@ func (s *AgentService) ListAgents(ctx context.Context, req *pb.ListAgentsRequest
 // DestroyAgent destroys an agent
 func (s *AgentService) DestroyAgent(ctx context.Context, req *pb.DestroyAgentRequest) (*pb.DestroyAgentResponse, error) {
 	if req.AgentId == "" {
-		return nil, status.Error(codes.InvalidArgument, "agent_id is required")
+		return nil, invalidArgumentError("agent_id is required")
 	}
 
 	if err := s.manager.DestroyAgent(req.AgentId); err != nil {
-		return nil, status.Errorf(codes.NotFound, "failed to destroy agent: %v", err)
+		return nil, newStatusError(ReasonAgentNotFound, "failed to destroy agent: "+err.Error(), map[string]string{"agent_id": req.AgentId}, 0)
 	}
 
 	return &pb.DestroyAgentResponse{
@@ -145,12 +233,12 @@ func (s *AgentService) DestroyAgent(ctx context.Context, req *pb.DestroyAgentReq
 // GetTokenUsage retrieves token usage and costs for an agent
 func (s *AgentService) GetTokenUsage(ctx context.Context, req *pb.GetTokenUsageRequest) (*pb.TokenUsageResponse, error) {
 	if req.AgentId == "" {
-		return nil, status.Error(codes.InvalidArgument, "agent_id is required")
+		return nil, invalidArgumentError("agent_id is required")
 	}
 
 	agent, ok := s.manager.GetAgent(req.AgentId)
 	if !ok {
-		return nil, status.Errorf(codes.NotFound, "agent not found: %s", req.AgentId)
+		return nil, agentNotFoundError(req.AgentId)
 	}
 
 	// Get token usage with pricing
@@ -175,27 +263,265 @@ func (s *AgentService) GetTokenUsage(ctx context.Context, req *pb.GetTokenUsageR
 	}, nil
 }
 
+// GetPostMortemBundle returns the diagnostic bundle of the agent's last failed conversation
+func (s *AgentService) GetPostMortemBundle(ctx context.Context, req *pb.GetPostMortemBundleRequest) (*pb.GetPostMortemBundleResponse, error) {
+	if req.AgentId == "" {
+		return nil, invalidArgumentError("agent_id is required")
+	}
+
+	agent, ok := s.manager.GetAgent(req.AgentId)
+	if !ok {
+		return nil, agentNotFoundError(req.AgentId)
+	}
+
+	bundle, ok := agent.Agent.LastPostMortemBundle()
+	if !ok {
+		return nil, postMortemNotFoundError(req.AgentId)
+	}
+	return &pb.GetPostMortemBundleResponse{
+		Bundle:    bundle.Data,
+		Path:      bundle.Path,
+		Error:     bundle.Error,
+		CreatedAt: timestamppb.New(bundle.CreatedAt),
+	}, nil
+}
+
+// GetToolStats returns the agent's per-tool and per-server usage statistics
+func (s *AgentService) GetToolStats(ctx context.Context, req *pb.GetToolStatsRequest) (*pb.GetToolStatsResponse, error) {
+	if req.AgentId == "" {
+		return nil, invalidArgumentError("agent_id is required")
+	}
+
+	agent, ok := s.manager.GetAgent(req.AgentId)
+	if !ok {
+		return nil, agentNotFoundError(req.AgentId)
+	}
+
+	report := agent.Agent.GetToolStats()
+	resp := &pb.GetToolStatsResponse{}
+	for _, tool := range report.Tools {
+		resp.Tools = append(resp.Tools, &pb.ToolStats{
+			ToolName:   tool.ToolName,
+			ServerName: tool.ServerName,
+			Stats:      toolCallStatsToProto(tool.ToolCallStats),
+		})
+	}
+	for _, server := range report.Servers {
+		resp.Servers = append(resp.Servers, &pb.ServerToolStats{
+			ServerName: server.ServerName,
+			Stats:      toolCallStatsToProto(server.ToolCallStats),
+		})
+	}
+	return resp, nil
+}
+
+func toolCallStatsToProto(stats mcpagent.ToolCallStats) *pb.ToolCallStats {
+	return &pb.ToolCallStats{
+		Calls:         safeIntToInt32(stats.Calls),
+		Errors:        safeIntToInt32(stats.Errors),
+		ErrorRate:     stats.ErrorRate,
+		CacheHits:     safeIntToInt32(stats.CacheHits),
+		P50LatencyMs:  stats.P50Latency.Milliseconds(),
+		P95LatencyMs:  stats.P95Latency.Milliseconds(),
+		MaxLatencyMs:  stats.MaxLatency.Milliseconds(),
+		BytesReturned: stats.BytesReturned,
+	}
+}
+
+// ListTools returns the tools available to the agent
+func (s *AgentService) ListTools(ctx context.Context, req *pb.ListToolsRequest) (*pb.ListToolsResponse, error) {
+	if req.AgentId == "" {
+		return nil, invalidArgumentError("agent_id is required")
+	}
+
+	agent, ok := s.manager.GetAgent(req.AgentId)
+	if !ok {
+		return nil, agentNotFoundError(req.AgentId)
+	}
+
+	resp := &pb.ListToolsResponse{}
+	for _, tool := range agent.Agent.ListTools() {
+		if req.Server != "" && tool.Server != req.Server {
+			continue
+		}
+		resp.Tools = append(resp.Tools, toolInfoToProto(tool))
+	}
+	return resp, nil
+}
+
+// GetToolSchema returns a tool with the JSON schema of its arguments
+func (s *AgentService) GetToolSchema(ctx context.Context, req *pb.GetToolSchemaRequest) (*pb.GetToolSchemaResponse, error) {
+	if req.AgentId == "" {
+		return nil, invalidArgumentError("agent_id is required")
+	}
+	if req.ToolName == "" {
+		return nil, invalidArgumentError("tool_name is required")
+	}
+
+	agent, ok := s.manager.GetAgent(req.AgentId)
+	if !ok {
+		return nil, agentNotFoundError(req.AgentId)
+	}
+
+	tool, ok := agent.Agent.ToolSchema(req.ToolName)
+	if !ok {
+		return nil, toolNotFoundError(req.AgentId, req.ToolName)
+	}
+	resp := &pb.GetToolSchemaResponse{Tool: toolInfoToProto(tool)}
+	if tool.Parameters != nil {
+		schema, err := structpb.NewStruct(tool.Parameters)
+		if err != nil {
+			return nil, newStatusError(ReasonInternal, "failed to encode tool schema: "+err.Error(),
+				map[string]string{"agent_id": req.AgentId, "tool": req.ToolName}, 0)
+		}
+		resp.InputSchema = schema
+	}
+	return resp, nil
+}
+
+// ListServers returns the MCP servers the agent uses
+func (s *AgentService) ListServers(ctx context.Context, req *pb.ListServersRequest) (*pb.ListServersResponse, error) {
+	if req.AgentId == "" {
+		return nil, invalidArgumentError("agent_id is required")
+	}
+
+	agent, ok := s.manager.GetAgent(req.AgentId)
+	if !ok {
+		return nil, agentNotFoundError(req.AgentId)
+	}
+
+	resp := &pb.ListServersResponse{}
+	for _, server := range agent.Agent.ListServers() {
+		resp.Servers = append(resp.Servers, &pb.ServerInfo{
+			Name:          server.Name,
+			Description:   server.Description,
+			Connected:     server.Connected,
+			ToolCount:     int32(server.ToolCount),
+			PromptCount:   int32(server.PromptCount),
+			ResourceCount: int32(server.ResourceCount),
+			Instructions:  server.Instructions,
+		})
+	}
+	return resp, nil
+}
+
+// ListPrompts returns the prompts offered by the agent's MCP servers
+func (s *AgentService) ListPrompts(ctx context.Context, req *pb.ListPromptsRequest) (*pb.ListPromptsResponse, error) {
+	if req.AgentId == "" {
+		return nil, invalidArgumentError("agent_id is required")
+	}
+
+	agent, ok := s.manager.GetAgent(req.AgentId)
+	if !ok {
+		return nil, agentNotFoundError(req.AgentId)
+	}
+
+	resp := &pb.ListPromptsResponse{}
+	for server, prompts := range agent.Agent.GetPrompts() {
+		if req.Server != "" && server != req.Server {
+			continue
+		}
+		for _, prompt := range prompts {
+			info := &pb.PromptInfo{Name: prompt.Name, Description: prompt.Description, Server: server}
+			for _, arg := range prompt.Arguments {
+				info.Arguments = append(info.Arguments, &pb.PromptArgument{
+					Name:        arg.Name,
+					Description: arg.Description,
+					Required:    arg.Required,
+				})
+			}
+			resp.Prompts = append(resp.Prompts, info)
+		}
+	}
+	sort.Slice(resp.Prompts, func(i, j int) bool {
+		if resp.Prompts[i].Server != resp.Prompts[j].Server {
+			return resp.Prompts[i].Server < resp.Prompts[j].Server
+		}
+		return resp.Prompts[i].Name < resp.Prompts[j].Name
+	})
+	return resp, nil
+}
+
+// agentTokenUsageToProto returns the cumulative token usage of agent
+func agentTokenUsageToProto(agent *mcpagent.Agent) *pb.TokenUsage {
+	promptTokens, completionTokens, totalTokens, cacheTokens, reasoningTokens, llmCallCount, _ := agent.GetTokenUsage()
+	return &pb.TokenUsage{
+		PromptTokens:     safeIntToInt32(promptTokens),
+		CompletionTokens: safeIntToInt32(completionTokens),
+		TotalTokens:      safeIntToInt32(totalTokens),
+		CacheTokens:      safeIntToInt32(cacheTokens),
+		ReasoningTokens:  safeIntToInt32(reasoningTokens),
+		LlmCallCount:     safeIntToInt32(llmCallCount),
+	}
+}
+
+// agentToolsToProto returns the tools currently available to agent
+func agentToolsToProto(agent *mcpagent.Agent) []*pb.ToolInfo {
+	tools := agent.ListTools()
+	infos := make([]*pb.ToolInfo, len(tools))
+	for i, tool := range tools {
+		infos[i] = toolInfoToProto(tool)
+	}
+	return infos
+}
+
+// agentFiltersToProto returns the tool filters agent was created with
+func agentFiltersToProto(agent *mcpagent.Agent) *pb.AgentFilters {
+	return &pb.AgentFilters{
+		SelectedServers: agent.GetSelectedServers(),
+		SelectedTools:   agent.GetSelectedTools(),
+	}
+}
+
+// toolInfoToProto converts a tool description for the wire
+func toolInfoToProto(tool mcpagent.ToolInfo) *pb.ToolInfo {
+	return &pb.ToolInfo{
+		Name:        tool.Name,
+		Description: tool.Description,
+		Server:      tool.Server,
+		Type:        tool.Type,
+	}
+}
+
 // Ask handles a single question (unary RPC for backward compatibility)
 func (s *AgentService) Ask(ctx context.Context, req *pb.AskRequest) (*pb.AskResponse, error) {
 	if req.AgentId == "" {
-		return nil, status.Error(codes.InvalidArgument, "agent_id is required")
+		return nil, invalidArgumentError("agent_id is required")
 	}
 	if req.Question == "" {
-		return nil, status.Error(codes.InvalidArgument, "question is required")
+		return nil, invalidArgumentError("question is required")
+	}
+
+	priority, err := requestPriority(req.Priority)
+	if err != nil {
+		return nil, err
 	}
 
 	agent, ok := s.manager.GetAgent(req.AgentId)
 	if !ok {
-		return nil, status.Errorf(codes.NotFound, "agent not found: %s", req.AgentId)
+		return nil, agentNotFoundError(req.AgentId)
 	}
 
+	ctx, done, err := s.manager.trackRequest(ctx, req.RequestId)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	release, err := s.manager.admitConversation(ctx, req.AgentId, priority)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
 	startTime := time.Now()
+	defer s.manager.beginConversation(agent)()
 
 	// Call the agent
-	response, err := agent.Agent.Ask(ctx, req.Question)
+	response, err := agent.Agent.Ask(ctx, req.Question, mcpagent.CallWithPriority(priority))
 	if err != nil {
 		s.logger.Error("Ask failed", err, loggerv2.String("agent_id", req.AgentId))
-		return nil, status.Errorf(codes.Internal, "ask failed: %v", err)
+		return nil, agentError(err, "ask failed", map[string]string{"agent_id": req.AgentId})
 	}
 
 	duration := time.Since(startTime)
@@ -220,18 +546,36 @@ func (s *AgentService) Ask(ctx context.Context, req *pb.AskRequest) (*pb.AskResp
 // AskWithHistory handles a multi-turn conversation (unary RPC for backward compatibility)
 func (s *AgentService) AskWithHistory(ctx context.Context, req *pb.AskWithHistoryRequest) (*pb.AskWithHistoryResponse, error) {
 	if req.AgentId == "" {
-		return nil, status.Error(codes.InvalidArgument, "agent_id is required")
+		return nil, invalidArgumentError("agent_id is required")
 	}
 	if len(req.Messages) == 0 {
-		return nil, status.Error(codes.InvalidArgument, "messages array is required")
+		return nil, invalidArgumentError("messages array is required")
+	}
+
+	priority, err := requestPriority(req.Priority)
+	if err != nil {
+		return nil, err
 	}
 
 	agent, ok := s.manager.GetAgent(req.AgentId)
 	if !ok {
-		return nil, status.Errorf(codes.NotFound, "agent not found: %s", req.AgentId)
+		return nil, agentNotFoundError(req.AgentId)
+	}
+
+	ctx, done, err := s.manager.trackRequest(ctx, req.RequestId)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	release, err := s.manager.admitConversation(ctx, req.AgentId, priority)
+	if err != nil {
+		return nil, err
 	}
+	defer release()
 
 	startTime := time.Now()
+	defer s.manager.beginConversation(agent)()
 
 	// Convert messages to LLM format
 	messages := make([]llmtypes.MessageContent, len(req.Messages))
@@ -255,10 +599,10 @@ func (s *AgentService) AskWithHistory(ctx context.Context, req *pb.AskWithHistor
 	}
 
 	// Call the agent
-	response, updatedMessages, err := agent.Agent.AskWithHistory(ctx, messages)
+	response, updatedMessages, err := agent.Agent.AskWithHistory(ctx, messages, mcpagent.CallWithPriority(priority))
 	if err != nil {
 		s.logger.Error("AskWithHistory failed", err, loggerv2.String("agent_id", req.AgentId))
-		return nil, status.Errorf(codes.Internal, "ask with history failed: %v", err)
+		return nil, agentError(err, "ask with history failed", map[string]string{"agent_id": req.AgentId})
 	}
 
 	duration := time.Since(startTime)
@@ -312,6 +656,7 @@ func (s *AgentService) AskWithHistory(ctx context.Context, req *pb.AskWithHistor
 func (s *AgentService) Converse(stream pb.AgentService_ConverseServer) error {
 	// Create a stream handler for this conversation
 	handler := NewStreamHandler(s.manager, s.logger, stream)
+	handler.artifacts = s.artifacts
 	return handler.Handle()
 }
 
@@ -344,6 +689,20 @@ func (s *AgentService) convertAgentConfig(pbConfig *pb.AgentConfig) (AgentConfig
 		})
 	}
 
+	var toolPermissions map[string]mcpagent.ToolPermission
+	for _, permission := range pbConfig.ToolPermissions {
+		if permission.Tool == "" {
+			return AgentConfig{}, fmt.Errorf("tool permission requires a tool name")
+		}
+		if toolPermissions == nil {
+			toolPermissions = make(map[string]mcpagent.ToolPermission)
+		}
+		toolPermissions[permission.Tool] = mcpagent.ToolPermission{
+			Disabled: permission.Disabled,
+			Paths:    permission.Paths,
+		}
+	}
+
 	return AgentConfig{
 		Provider:                   pbConfig.Provider,
 		ModelID:                    pbConfig.ModelId,
@@ -357,6 +716,7 @@ func (s *AgentService) convertAgentConfig(pbConfig *pb.AgentConfig) (AgentConfig
 		EnableContextOffloading:    pbConfig.EnableContextOffloading,
 		EnableStreaming:            pbConfig.EnableStreaming,
 		CustomTools:                customTools,
+		ToolPermissions:            toolPermissions,
 	}, nil
 }
 
@@ -0,0 +1,155 @@
+package grpcserver
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	mcpagent "github.com/manishiitg/mcpagent/agent"
+	"github.com/manishiitg/mcpagent/events"
+	"github.com/manishiitg/mcpagent/grpcserver/pb"
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+	"github.com/manishiitg/mcpagent/observability"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+type failingGenerateStage struct{}
+
+func (failingGenerateStage) Generate(context.Context, *mcpagent.Agent, []llmtypes.MessageContent, []llmtypes.CallOption, int) (*llmtypes.ContentResponse, observability.UsageMetrics, error) {
+	return nil, observability.UsageMetrics{}, errors.New("provider unavailable")
+}
+
+func TestGetPostMortemBundle(t *testing.T) {
+	m := NewAgentManager(loggerv2.NewNoop(), "")
+	agent := &mcpagent.Agent{Logger: loggerv2.NewNoop(), MaxTurns: 2}
+	mcpagent.WithPostMortemBundles(mcpagent.PostMortemConfig{})(agent)
+	mcpagent.WithAskPipeline(mcpagent.AskPipeline{Generate: failingGenerateStage{}})(agent)
+	m.agents["agent-1"] = &ManagedAgent{ID: "agent-1", Agent: agent}
+	service := NewAgentService(m, loggerv2.NewNoop())
+
+	_, err := service.GetPostMortemBundle(context.Background(), &pb.GetPostMortemBundleRequest{AgentId: "agent-1"})
+	if reason, _ := errorInfo(err); reason != ReasonNoPostMortem || status.Code(err) != codes.NotFound {
+		t.Fatalf("before a failure: reason = %q, code = %s", reason, status.Code(err))
+	}
+
+	if _, err := agent.Ask(context.Background(), "hello"); err == nil {
+		t.Fatal("expected the conversation to fail")
+	}
+	resp, err := service.GetPostMortemBundle(context.Background(), &pb.GetPostMortemBundleRequest{AgentId: "agent-1"})
+	if err != nil {
+		t.Fatalf("GetPostMortemBundle: %v", err)
+	}
+	if resp.Error == "" || resp.CreatedAt == nil {
+		t.Errorf("response = %+v", resp)
+	}
+	if _, err := zip.NewReader(bytes.NewReader(resp.Bundle), int64(len(resp.Bundle))); err != nil {
+		t.Errorf("bundle is not a zip archive: %v", err)
+	}
+}
+
+func TestGetToolStats(t *testing.T) {
+	m := NewAgentManager(loggerv2.NewNoop(), "")
+	agent := &mcpagent.Agent{Logger: loggerv2.NewNoop()}
+	m.agents["agent-1"] = &ManagedAgent{ID: "agent-1", Agent: agent}
+	service := NewAgentService(m, loggerv2.NewNoop())
+	ctx := context.Background()
+
+	agent.EmitTypedEvent(ctx, &events.ToolCallEndEvent{ToolName: "search", ServerName: "github", Result: "abc", Duration: 40 * time.Millisecond})
+	agent.EmitTypedEvent(ctx, &events.ToolCallErrorEvent{ToolName: "search", ServerName: "github", Duration: 120 * time.Millisecond})
+
+	resp, err := service.GetToolStats(ctx, &pb.GetToolStatsRequest{AgentId: "agent-1"})
+	if err != nil {
+		t.Fatalf("GetToolStats: %v", err)
+	}
+	if len(resp.Tools) != 1 || len(resp.Servers) != 1 {
+		t.Fatalf("response = %v", resp)
+	}
+	stats := resp.Servers[0].Stats
+	if stats.Calls != 2 || stats.Errors != 1 || stats.ErrorRate != 0.5 || stats.MaxLatencyMs != 120 || stats.BytesReturned != 3 {
+		t.Errorf("github stats = %v", stats)
+	}
+	if _, err := service.GetToolStats(ctx, &pb.GetToolStatsRequest{AgentId: "other"}); status.Code(err) != codes.NotFound {
+		t.Errorf("unknown agent: %v", err)
+	}
+}
+
+func TestToolIntrospection(t *testing.T) {
+	m := NewAgentManager(loggerv2.NewNoop(), "")
+	agent := &mcpagent.Agent{Logger: loggerv2.NewNoop()}
+	params := map[string]interface{}{"type": "object", "required": []interface{}{"text"}}
+	run := func(context.Context, map[string]interface{}) (string, error) { return "", nil }
+	if err := agent.RegisterCustomTool("add_note", "Adds a note", params, run, "notes"); err != nil {
+		t.Fatal(err)
+	}
+	m.agents["agent-1"] = &ManagedAgent{ID: "agent-1", Agent: agent}
+	service := NewAgentService(m, loggerv2.NewNoop())
+	ctx := context.Background()
+
+	tools, err := service.ListTools(ctx, &pb.ListToolsRequest{AgentId: "agent-1"})
+	if err != nil || len(tools.Tools) != 1 || tools.Tools[0].Server != "notes" || tools.Tools[0].Type != mcpagent.ToolTypeCustom {
+		t.Fatalf("ListTools = %v, %v", tools, err)
+	}
+	if filtered, _ := service.ListTools(ctx, &pb.ListToolsRequest{AgentId: "agent-1", Server: "crm"}); len(filtered.Tools) != 0 {
+		t.Errorf("server filter: %v", filtered.Tools)
+	}
+
+	schema, err := service.GetToolSchema(ctx, &pb.GetToolSchemaRequest{AgentId: "agent-1", ToolName: "add_note"})
+	if err != nil || schema.InputSchema.AsMap()["type"] != "object" {
+		t.Fatalf("GetToolSchema = %v, %v", schema, err)
+	}
+	_, err = service.GetToolSchema(ctx, &pb.GetToolSchemaRequest{AgentId: "agent-1", ToolName: "missing"})
+	if reason, _ := errorInfo(err); reason != ReasonToolNotFound || status.Code(err) != codes.NotFound {
+		t.Errorf("unknown tool: reason = %q, code = %s", reason, status.Code(err))
+	}
+
+	if _, err := service.ListServers(ctx, &pb.ListServersRequest{}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("missing agent_id: %v", err)
+	}
+	if _, err := service.ListPrompts(ctx, &pb.ListPromptsRequest{AgentId: "other"}); status.Code(err) != codes.NotFound {
+		t.Errorf("unknown agent: %v", err)
+	}
+}
+
+func TestGetAgentAndListAgentsIncludeInventory(t *testing.T) {
+	m := NewAgentManager(loggerv2.NewNoop(), "")
+	agent := &mcpagent.Agent{Logger: loggerv2.NewNoop()}
+	mcpagent.WithSelectedServers([]string{"notes"})(agent)
+	mcpagent.WithSelectedTools([]string{"notes:add_note"})(agent)
+	run := func(context.Context, map[string]interface{}) (string, error) { return "", nil }
+	if err := agent.RegisterCustomTool("add_note", "Adds a note", map[string]interface{}{"type": "object"}, run, "notes"); err != nil {
+		t.Fatal(err)
+	}
+	agent.UseCodeExecutionMode = true
+	m.agents["agent-1"] = &ManagedAgent{ID: "agent-1", Agent: agent}
+	service := NewAgentService(m, loggerv2.NewNoop())
+	ctx := context.Background()
+
+	resp, err := service.GetAgent(ctx, &pb.GetAgentRequest{AgentId: "agent-1"})
+	if err != nil {
+		t.Fatalf("GetAgent: %v", err)
+	}
+	if len(resp.Tools) != 1 || resp.Tools[0].Name != "add_note" || resp.Tools[0].Description != "Adds a note" || resp.Tools[0].Server != "notes" {
+		t.Errorf("tools = %v", resp.Tools)
+	}
+	if !resp.CodeExecutionMode || resp.TokenUsage == nil {
+		t.Errorf("response = %v", resp)
+	}
+	if f := resp.Filters; len(f.SelectedServers) != 1 || f.SelectedServers[0] != "notes" || len(f.SelectedTools) != 1 || f.SelectedTools[0] != "notes:add_note" {
+		t.Errorf("filters = %v", f)
+	}
+
+	list, err := service.ListAgents(ctx, &pb.ListAgentsRequest{})
+	if err != nil || len(list.Agents) != 1 {
+		t.Fatalf("ListAgents = %v, %v", list, err)
+	}
+	summary := list.Agents[0]
+	if len(summary.Tools) != 1 || !summary.CodeExecutionMode || summary.TokenUsage == nil || len(summary.Filters.SelectedTools) != 1 {
+		t.Errorf("summary = %v", summary)
+	}
+}
@@ -2,18 +2,17 @@ package grpcserver
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"sync"
 	"time"
 
-	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/structpb"
 	"google.golang.org/protobuf/types/known/timestamppb"
 
+	mcpagent "github.com/manishiitg/mcpagent/agent"
 	"github.com/manishiitg/mcpagent/events"
 	"github.com/manishiitg/mcpagent/grpcserver/pb"
 	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
@@ -38,6 +37,11 @@ type StreamHandler struct {
 	questionChan chan *questionRequest
 	errChan      chan error
 
+	// Optional artifact server; when set, events and the final response carry
+	// download URLs for files produced during the conversation
+	artifacts         *ArtifactServer
+	producedArtifacts []*pb.Artifact
+
 	mu sync.Mutex
 }
 
@@ -68,6 +72,12 @@ func (h *StreamHandler) Handle() error {
 	ctx, cancel := context.WithCancel(h.stream.Context())
 	defer cancel()
 
+	untrack, err := h.manager.trackStream(h, cancel)
+	if err != nil {
+		return err
+	}
+	defer untrack()
+
 	var wg sync.WaitGroup
 
 	// Start receive loop in a goroutine so it can continue receiving
@@ -167,17 +177,24 @@ func (h *StreamHandler) handleQuestion(ctx context.Context, agentID string, ques
 	// Validate agent
 	if agentID == "" {
 		h.mu.Unlock()
-		return status.Error(codes.InvalidArgument, "agent_id is required")
+		return invalidArgumentError("agent_id is required")
+	}
+
+	priority, err := requestPriority(question.GetPriority())
+	if err != nil {
+		h.mu.Unlock()
+		return err
 	}
 
 	agent, ok := h.manager.GetAgent(agentID)
 	if !ok {
 		h.mu.Unlock()
-		return status.Errorf(codes.NotFound, "agent not found: %s", agentID)
+		return agentNotFoundError(agentID)
 	}
 
 	h.agentID = agentID
 	h.agent = agent
+	h.producedArtifacts = nil
 
 	// Create cancellable context
 	convCtx, cancel := context.WithCancel(ctx)
@@ -186,7 +203,14 @@ func (h *StreamHandler) handleQuestion(ctx context.Context, agentID string, ques
 
 	defer cancel()
 
+	release, err := h.manager.admitConversation(convCtx, agentID, priority)
+	if err != nil {
+		return err
+	}
+	defer release()
+
 	startTime := time.Now()
+	defer h.manager.beginConversation(agent)()
 
 	// Register custom tools with stream-based execution
 	if len(agent.CustomTools) > 0 {
@@ -205,7 +229,6 @@ func (h *StreamHandler) handleQuestion(ctx context.Context, agentID string, ques
 	// Prepare messages for conversation
 	var response string
 	var updatedMessages []llmtypes.MessageContent
-	var err error
 
 	if len(question.History) > 0 {
 		// Multi-turn conversation
@@ -217,15 +240,15 @@ func (h *StreamHandler) handleQuestion(ctx context.Context, agentID string, ques
 			Parts: []llmtypes.ContentPart{llmtypes.TextContent{Text: question.Text}},
 		})
 
-		response, updatedMessages, err = agent.Agent.AskWithHistory(convCtx, messages)
+		response, updatedMessages, err = agent.Agent.AskWithHistory(convCtx, messages, mcpagent.CallWithPriority(priority))
 	} else {
 		// Single turn
-		response, err = agent.Agent.Ask(convCtx, question.Text)
+		response, err = agent.Agent.Ask(convCtx, question.Text, mcpagent.CallWithPriority(priority))
 	}
 
 	if err != nil {
 		h.logger.Error("Conversation failed", err, loggerv2.String("agent_id", agentID))
-		return status.Errorf(codes.Internal, "conversation failed: %v", err)
+		return agentError(err, "conversation failed", map[string]string{"agent_id": agentID})
 	}
 
 	duration := time.Since(startTime)
@@ -248,6 +271,7 @@ func (h *StreamHandler) handleQuestion(ctx context.Context, agentID string, ques
 					LlmCallCount:     safeIntToInt32(llmCallCount),
 				},
 				DurationMs: duration.Milliseconds(),
+				Artifacts:  h.conversationArtifacts(),
 			},
 		},
 	}
@@ -300,17 +324,28 @@ func (h *StreamHandler) sendAgentEvent(event events.AgentEvent) {
 		}
 	}
 
-	// Check for tool call events - these need special handling for bidirectional flow
-	if eventData.GetEventType() == events.ToolCallStart {
-		if toolEvent, ok := eventData.(*events.ToolCallStartEvent); ok {
-			h.sendToolCallStart(toolEvent)
-			return
-		}
+	// Other events, including tool call starts, are sent as AgentEvent. Custom
+	// tools that run on the client are requested by their execution function
+	// (see registerCustomTools), not from events.
+	pbEvent := agentEventToProto(event, h.artifacts)
+	h.recordArtifacts(pbEvent.Artifacts)
+
+	resp := &pb.ConversationResponse{
+		Payload: &pb.ConversationResponse_AgentEvent{
+			AgentEvent: pbEvent,
+		},
 	}
 
-	// For other events, send as AgentEvent
+	if err := h.stream.Send(resp); err != nil {
+		h.logger.Debug("Failed to send agent event", loggerv2.String("error", err.Error()))
+	}
+}
+
+// agentEventToProto converts an agent event to its protobuf form, attaching
+// download URLs for referenced files when artifacts is set
+func agentEventToProto(event events.AgentEvent, artifacts *ArtifactServer) *pb.AgentEvent {
 	pbEvent := &pb.AgentEvent{
-		Type:           string(eventData.GetEventType()),
+		Type:           string(event.Data.GetEventType()),
 		Timestamp:      timestamppb.New(event.Timestamp),
 		TraceId:        event.TraceID,
 		SpanId:         event.SpanID,
@@ -320,18 +355,67 @@ func (h *StreamHandler) sendAgentEvent(event events.AgentEvent) {
 		SessionId:      event.SessionID,
 		Component:      event.Component,
 	}
+	if artifacts != nil {
+		pbEvent.Artifacts = artifacts.artifactsForEvent(event.Data)
+	}
+	// Tool progress is only useful with its payload (percentage and partial
+	// output); other events are sent as metadata only
+	if progress, ok := event.Data.(*events.ToolCallProgressEvent); ok {
+		pbEvent.Data = toolProgressStruct(progress)
+	}
+	return pbEvent
+}
 
-	resp := &pb.ConversationResponse{
-		Payload: &pb.ConversationResponse_AgentEvent{
-			AgentEvent: pbEvent,
-		},
+// toolProgressStruct converts a tool progress event to the AgentEvent data
+func toolProgressStruct(progress *events.ToolCallProgressEvent) *structpb.Struct {
+	data := map[string]interface{}{
+		"turn":         progress.Turn,
+		"tool_name":    progress.ToolName,
+		"server_name":  progress.ServerName,
+		"tool_call_id": progress.ToolCallID,
+		"progress":     progress.Progress,
+		"current":      progress.Current,
+		"total":        progress.Total,
+		"status":       progress.Status,
+		"message":      progress.Message,
+		"elapsed_time": progress.ElapsedTime,
+	}
+	s, err := structpb.NewStruct(data)
+	if err != nil {
+		return nil
 	}
+	return s
+}
 
-	if err := h.stream.Send(resp); err != nil {
-		h.logger.Debug("Failed to send agent event", loggerv2.String("error", err.Error()))
+// recordArtifacts remembers artifacts referenced by events so they can be
+// listed in the final response
+func (h *StreamHandler) recordArtifacts(artifacts []*pb.Artifact) {
+	if len(artifacts) == 0 {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, artifact := range artifacts {
+		known := false
+		for _, existing := range h.producedArtifacts {
+			if existing.Path == artifact.Path {
+				known = true
+				break
+			}
+		}
+		if !known {
+			h.producedArtifacts = append(h.producedArtifacts, artifact)
+		}
 	}
 }
 
+// conversationArtifacts returns the artifacts recorded for the current conversation
+func (h *StreamHandler) conversationArtifacts() []*pb.Artifact {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]*pb.Artifact(nil), h.producedArtifacts...)
+}
+
 // sendTextChunk sends a streaming text chunk
 func (h *StreamHandler) sendTextChunk(text string, isThinking bool) {
 	resp := &pb.ConversationResponse{
@@ -348,61 +432,13 @@ func (h *StreamHandler) sendTextChunk(text string, isThinking bool) {
 	}
 }
 
-// sendToolCallStart sends a tool call request to the client
-func (h *StreamHandler) sendToolCallStart(toolEvent *events.ToolCallStartEvent) {
-	callID := uuid.New().String()[:8]
-
-	// Parse arguments JSON string to map
-	var argsMap map[string]interface{}
-	if toolEvent.ToolParams.Arguments != "" {
-		if err := json.Unmarshal([]byte(toolEvent.ToolParams.Arguments), &argsMap); err != nil {
-			h.logger.Debug("Failed to parse tool arguments JSON", loggerv2.String("error", err.Error()))
-			argsMap = make(map[string]interface{})
-		}
-	} else {
-		argsMap = make(map[string]interface{})
-	}
-
-	// Convert to protobuf Struct
-	argsStruct, err := structpb.NewStruct(argsMap)
-	if err != nil {
-		h.logger.Error("Failed to convert tool arguments", err)
-		argsStruct = &structpb.Struct{}
-	}
-
-	resp := &pb.ConversationResponse{
-		Payload: &pb.ConversationResponse_ToolCall{
-			ToolCall: &pb.ToolCallEvent{
-				CallId:    callID,
-				ToolName:  toolEvent.ToolName,
-				Arguments: argsStruct,
-				TimeoutMs: 30000, // Default 30s timeout
-			},
-		},
-	}
-
-	if err := h.stream.Send(resp); err != nil {
-		h.logger.Error("Failed to send tool call", err)
-	}
-}
-
-// sendError sends an error event via the stream
+// sendError sends an error event via the stream. The event code is the
+// error reason (see Reason* constants) and details carry the structured
+// ErrorInfo metadata so clients can branch on failure type.
 func (h *StreamHandler) sendError(err error, fatal bool) {
-	code := "INTERNAL_ERROR"
+	code, details := errorInfo(err)
 	message := err.Error()
-
-	// Extract code from gRPC status if available
 	if st, ok := status.FromError(err); ok {
-		switch st.Code() {
-		case codes.NotFound:
-			code = "NOT_FOUND"
-		case codes.InvalidArgument:
-			code = "INVALID_ARGUMENT"
-		case codes.DeadlineExceeded:
-			code = "TIMEOUT"
-		case codes.Canceled:
-			code = "CANCELLED"
-		}
 		message = st.Message()
 	}
 
@@ -411,6 +447,7 @@ func (h *StreamHandler) sendError(err error, fatal bool) {
 			Error: &pb.ErrorEvent{
 				Code:    code,
 				Message: message,
+				Details: detailsStruct(details),
 				Fatal:   fatal,
 			},
 		},
@@ -423,6 +460,11 @@ func (h *StreamHandler) sendError(err error, fatal bool) {
 
 // convertMessagesToLLM converts protobuf messages to LLM format
 func (h *StreamHandler) convertMessagesToLLM(messages []*pb.Message) []llmtypes.MessageContent {
+	return messagesToLLM(messages)
+}
+
+// messagesToLLM converts protobuf messages to LLM text messages
+func messagesToLLM(messages []*pb.Message) []llmtypes.MessageContent {
 	result := make([]llmtypes.MessageContent, len(messages))
 	for i, msg := range messages {
 		var role llmtypes.ChatMessageType
@@ -447,6 +489,11 @@ func (h *StreamHandler) convertMessagesToLLM(messages []*pb.Message) []llmtypes.
 
 // convertMessagesToProto converts LLM messages to protobuf format
 func (h *StreamHandler) convertMessagesToProto(messages []llmtypes.MessageContent) []*pb.Message {
+	return messagesToProto(messages)
+}
+
+// messagesToProto converts LLM messages to protobuf messages, keeping only text parts
+func messagesToProto(messages []llmtypes.MessageContent) []*pb.Message {
 	if messages == nil {
 		return nil
 	}
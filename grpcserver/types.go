@@ -3,6 +3,7 @@ package grpcserver
 import (
 	"time"
 
+	mcpagent "github.com/manishiitg/mcpagent/agent"
 	"github.com/manishiitg/mcpagent/llm"
 )
 
@@ -10,6 +11,11 @@ import (
 type CreateAgentRequest struct {
 	SessionID string      `json:"session_id,omitempty"`
 	Config    AgentConfig `json:"config"`
+	// Preset names a registered mcpagent preset; Config fields that are set override it
+	Preset          string            `json:"preset,omitempty"`
+	PresetVariables map[string]string `json:"preset_variables,omitempty"`
+	// WarmPool names a warm pool to take a pre-created agent from (see warm_pool.go)
+	WarmPool string `json:"warm_pool,omitempty"`
 }
 
 // AgentConfig holds the configuration for creating an agent
@@ -27,6 +33,8 @@ type AgentConfig struct {
 	EnableStreaming            bool                   `json:"enable_streaming,omitempty"`
 	CustomTools                []CustomToolDefinition `json:"custom_tools,omitempty"`
 	APIKeys                    *ProviderAPIKeys       `json:"api_keys,omitempty"`
+	// ToolPermissions restricts virtual and custom tools, keyed by tool name
+	ToolPermissions map[string]mcpagent.ToolPermission `json:"tool_permissions,omitempty"`
 }
 
 // ProviderAPIKeys holds API keys for different providers
@@ -0,0 +1,394 @@
+package grpcserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	mcpagent "github.com/manishiitg/mcpagent/agent"
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+)
+
+// warmPoolRetryDelay is how long a pool waits before retrying a failed agent creation
+const warmPoolRetryDelay = 30 * time.Second
+
+// WarmPoolConfig describes a named pool of agents created ahead of demand.
+// CreateAgent requests naming the pool take an idle agent instead of paying
+// the LLM and MCP connection latency; the pool refills in the background.
+type WarmPoolConfig struct {
+	Name string `json:"name"`
+	// Size is the number of idle agents kept ready
+	Size   int         `json:"size"`
+	Config AgentConfig `json:"config"`
+	// Preset optionally names a registered mcpagent preset the agents are created from
+	Preset          string            `json:"preset,omitempty"`
+	PresetVariables map[string]string `json:"preset_variables,omitempty"`
+}
+
+// WarmPoolStatus reports the state of a warm pool
+type WarmPoolStatus struct {
+	Name string `json:"name"`
+	Size int    `json:"size"`
+	Idle int    `json:"idle"`
+	// Hits counts requests served with a warm agent; Misses counts requests
+	// that had to create one (pool empty or overlay not applicable)
+	Hits   int `json:"hits"`
+	Misses int `json:"misses"`
+}
+
+// warmPool holds the idle agents of one WarmPoolConfig
+type warmPool struct {
+	config WarmPoolConfig
+	create func(ctx context.Context) (*ManagedAgent, error)
+	refill chan struct{}
+
+	mu     sync.Mutex
+	idle   []*ManagedAgent
+	hits   int
+	misses int
+	closed bool
+}
+
+// LoadWarmPools reads a JSON file holding an array of warm pool configurations
+func LoadWarmPools(path string) ([]WarmPoolConfig, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // path is supplied by the operator
+	if err != nil {
+		return nil, fmt.Errorf("failed to read warm pools file: %w", err)
+	}
+	var pools []WarmPoolConfig
+	if err := json.Unmarshal(data, &pools); err != nil {
+		return nil, fmt.Errorf("failed to parse warm pools file %s: %w", path, err)
+	}
+	return pools, nil
+}
+
+// StartWarmPools replaces the manager's warm pools with pools and starts
+// filling them in the background. Agents stay warm until taken or until
+// StopWarmPools is called; ctx bounds the refill loops only.
+func (m *AgentManager) StartWarmPools(ctx context.Context, pools []WarmPoolConfig) error {
+	seen := make(map[string]bool, len(pools))
+	for _, config := range pools {
+		if config.Name == "" {
+			return fmt.Errorf("warm pool name is required")
+		}
+		if seen[config.Name] {
+			return fmt.Errorf("duplicate warm pool: %s", config.Name)
+		}
+		seen[config.Name] = true
+		if config.Size <= 0 {
+			return fmt.Errorf("warm pool %s: size must be positive", config.Name)
+		}
+		if config.Preset != "" {
+			if _, ok := mcpagent.GetPreset(config.Preset); !ok {
+				return fmt.Errorf("warm pool %s: preset not found: %s", config.Name, config.Preset)
+			}
+		}
+	}
+
+	m.StopWarmPools()
+	poolCtx, cancel := context.WithCancel(ctx)
+	started := make(map[string]*warmPool, len(pools))
+	for _, config := range pools {
+		pool := m.newWarmPool(config)
+		started[config.Name] = pool
+		go m.fillWarmPool(poolCtx, pool)
+	}
+
+	m.poolsMu.Lock()
+	m.warmPools = started
+	m.stopPools = cancel
+	m.poolsMu.Unlock()
+	return nil
+}
+
+// StopWarmPools stops refilling and closes the idle agents of all pools.
+// Agents already handed out are not affected.
+func (m *AgentManager) StopWarmPools() {
+	m.poolsMu.Lock()
+	pools, cancel := m.warmPools, m.stopPools
+	m.warmPools, m.stopPools = nil, nil
+	m.poolsMu.Unlock()
+
+	if cancel != nil {
+		cancel()
+	}
+	for _, pool := range pools {
+		pool.mu.Lock()
+		idle := pool.idle
+		pool.idle, pool.closed = nil, true
+		pool.mu.Unlock()
+		for _, managed := range idle {
+			managed.cancel()
+			managed.Agent.Close()
+		}
+	}
+}
+
+// HasWarmPool reports whether a warm pool with the given name is running
+func (m *AgentManager) HasWarmPool(name string) bool {
+	return m.warmPool(name) != nil
+}
+
+// WarmPools returns the status of all warm pools, sorted by name
+func (m *AgentManager) WarmPools() []WarmPoolStatus {
+	m.poolsMu.RLock()
+	defer m.poolsMu.RUnlock()
+	statuses := make([]WarmPoolStatus, 0, len(m.warmPools))
+	for _, pool := range m.warmPools {
+		pool.mu.Lock()
+		statuses = append(statuses, WarmPoolStatus{
+			Name:   pool.config.Name,
+			Size:   pool.config.Size,
+			Idle:   len(pool.idle),
+			Hits:   pool.hits,
+			Misses: pool.misses,
+		})
+		pool.mu.Unlock()
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	return statuses
+}
+
+func (m *AgentManager) warmPool(name string) *warmPool {
+	m.poolsMu.RLock()
+	defer m.poolsMu.RUnlock()
+	return m.warmPools[name]
+}
+
+// newWarmPool creates a pool whose agents are built like CreateAgent builds them
+func (m *AgentManager) newWarmPool(config WarmPoolConfig) *warmPool {
+	pool := &warmPool{config: config, refill: make(chan struct{}, 1)}
+	pool.create = func(ctx context.Context) (*ManagedAgent, error) {
+		req, presetOptions, err := resolvePreset(CreateAgentRequest{
+			Config:          config.Config,
+			Preset:          config.Preset,
+			PresetVariables: config.PresetVariables,
+		})
+		if err != nil {
+			return nil, err
+		}
+		sessionID := newManagedSessionID()
+		m.mu.RLock()
+		options := append(presetOptions, m.buildAgentOptions(req.Config, sessionID)...)
+		m.mu.RUnlock()
+		// Warm agents outlive the refill loop that created them
+		return m.startAgent(context.WithoutCancel(ctx), req.Config, sessionID, options)
+	}
+	return pool
+}
+
+// fillWarmPool keeps pool at its configured size until ctx is cancelled
+func (m *AgentManager) fillWarmPool(ctx context.Context, pool *warmPool) {
+	for {
+		for pool.needsAgent() {
+			managed, err := pool.create(ctx)
+			if err != nil {
+				m.logger.Warn("Failed to create warm agent",
+					loggerv2.String("warm_pool", pool.config.Name),
+					loggerv2.String("error", err.Error()))
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(warmPoolRetryDelay):
+				}
+				continue
+			}
+			if !pool.add(managed) {
+				managed.cancel()
+				managed.Agent.Close()
+				return
+			}
+			m.logger.Debug("Warm agent ready",
+				loggerv2.String("warm_pool", pool.config.Name),
+				loggerv2.String("agent_id", managed.ID))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-pool.refill:
+		}
+	}
+}
+
+func (p *warmPool) needsAgent() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return !p.closed && len(p.idle) < p.config.Size
+}
+
+// add stores an idle agent; false when the pool was stopped meanwhile
+func (p *warmPool) add(managed *ManagedAgent) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return false
+	}
+	p.idle = append(p.idle, managed)
+	return true
+}
+
+// take removes the oldest idle agent. It records a miss and returns nil when
+// the request cannot use a warm agent or the pool is empty.
+func (p *warmPool) take(usable bool) *ManagedAgent {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !usable || len(p.idle) == 0 {
+		p.misses++
+		return nil
+	}
+	p.hits++
+	managed := p.idle[0]
+	p.idle = p.idle[1:]
+	select {
+	case p.refill <- struct{}{}:
+	default:
+	}
+	return managed
+}
+
+// takeWarmAgent hands out an idle agent of req.WarmPool with req's config
+// overlaid. When the pool cannot serve req, it returns a nil agent and the
+// request to create cold: the pool's configuration overlaid with req's.
+func (m *AgentManager) takeWarmAgent(req CreateAgentRequest) (*ManagedAgent, CreateAgentRequest, error) {
+	pool := m.warmPool(req.WarmPool)
+	if pool == nil {
+		return nil, req, fmt.Errorf("warm pool not found: %s", req.WarmPool)
+	}
+	resolved := pool.resolve(req)
+	managed := pool.take(pool.canServe(req, resolved))
+	if managed == nil {
+		return nil, resolved, nil
+	}
+	applyWarmOverlay(managed, req.Config)
+	managed.Config = resolved.Config
+	return managed, resolved, nil
+}
+
+// resolve overlays the fields req sets on the pool's configuration
+func (p *warmPool) resolve(req CreateAgentRequest) CreateAgentRequest {
+	base, overlay := p.config.Config, req.Config
+	if overlay.Provider != "" {
+		base.Provider = overlay.Provider
+	}
+	if overlay.ModelID != "" {
+		base.ModelID = overlay.ModelID
+	}
+	if overlay.Temperature != nil {
+		base.Temperature = overlay.Temperature
+	}
+	if overlay.MaxTurns > 0 {
+		base.MaxTurns = overlay.MaxTurns
+	}
+	if overlay.MCPConfigPath != "" {
+		base.MCPConfigPath = overlay.MCPConfigPath
+	}
+	if len(overlay.SelectedServers) > 0 {
+		base.SelectedServers = overlay.SelectedServers
+	}
+	if len(overlay.SelectedTools) > 0 {
+		base.SelectedTools = overlay.SelectedTools
+	}
+	if overlay.SystemPrompt != "" {
+		base.SystemPrompt = overlay.SystemPrompt
+	}
+	base.EnableContextSummarization = base.EnableContextSummarization || overlay.EnableContextSummarization
+	base.EnableContextOffloading = base.EnableContextOffloading || overlay.EnableContextOffloading
+	base.EnableStreaming = base.EnableStreaming || overlay.EnableStreaming
+	if len(overlay.CustomTools) > 0 {
+		base.CustomTools = overlay.CustomTools
+	}
+	if overlay.APIKeys != nil {
+		base.APIKeys = overlay.APIKeys
+	}
+	if len(overlay.ToolPermissions) > 0 {
+		permissions := make(map[string]mcpagent.ToolPermission, len(base.ToolPermissions)+len(overlay.ToolPermissions))
+		for name, permission := range base.ToolPermissions {
+			permissions[name] = permission
+		}
+		for name, permission := range overlay.ToolPermissions {
+			permissions[name] = permission
+		}
+		base.ToolPermissions = permissions
+	}
+
+	resolved := CreateAgentRequest{
+		SessionID:       req.SessionID,
+		Config:          base,
+		Preset:          p.config.Preset,
+		PresetVariables: p.config.PresetVariables,
+	}
+	if req.Preset != "" && req.Preset != p.config.Preset {
+		resolved.Preset, resolved.PresetVariables = req.Preset, nil
+	}
+	if len(req.PresetVariables) > 0 {
+		variables := make(map[string]string, len(resolved.PresetVariables)+len(req.PresetVariables))
+		for name, value := range resolved.PresetVariables {
+			variables[name] = value
+		}
+		for name, value := range req.PresetVariables {
+			variables[name] = value
+		}
+		resolved.PresetVariables = variables
+	}
+	return resolved
+}
+
+// canServe reports whether a warm agent can serve req. Everything fixed when
+// the agent connected — session, LLM, MCP servers and tools, preset prompt —
+// must match the pool; the remaining fields are applied as an overlay.
+func (p *warmPool) canServe(req, resolved CreateAgentRequest) bool {
+	pooled := p.config.Config
+	return req.SessionID == "" &&
+		req.Config.APIKeys == nil &&
+		len(req.PresetVariables) == 0 &&
+		resolved.Preset == p.config.Preset &&
+		resolved.Config.Provider == pooled.Provider &&
+		resolved.Config.ModelID == pooled.ModelID &&
+		resolved.Config.MCPConfigPath == pooled.MCPConfigPath &&
+		slices.Equal(resolved.Config.SelectedServers, pooled.SelectedServers) &&
+		slices.Equal(resolved.Config.SelectedTools, pooled.SelectedTools)
+}
+
+// applyWarmOverlay applies the per-request fields of config to a warm agent
+func applyWarmOverlay(managed *ManagedAgent, config AgentConfig) {
+	agent := managed.Agent
+	if config.SystemPrompt != "" {
+		agent.SetSystemPrompt(config.SystemPrompt)
+	}
+
+	var options []mcpagent.AgentOption
+	if config.MaxTurns > 0 {
+		options = append(options, mcpagent.WithMaxTurns(config.MaxTurns))
+	}
+	if config.Temperature != nil {
+		options = append(options, mcpagent.WithTemperature(*config.Temperature))
+	}
+	if config.EnableContextSummarization {
+		options = append(options, mcpagent.WithContextSummarization(true))
+	}
+	if config.EnableContextOffloading {
+		options = append(options, mcpagent.WithContextOffloading(true))
+	}
+	if config.EnableStreaming {
+		options = append(options, mcpagent.WithStreaming(true))
+	}
+	if len(config.ToolPermissions) > 0 {
+		options = append(options, mcpagent.WithToolPermissions(config.ToolPermissions))
+	}
+	for _, option := range options {
+		option(agent)
+	}
+
+	if len(config.CustomTools) > 0 {
+		managed.CustomTools = config.CustomTools
+	}
+	managed.CreatedAt = time.Now()
+	managed.activityMu.Lock()
+	managed.lastActive = managed.CreatedAt
+	managed.activityMu.Unlock()
+}
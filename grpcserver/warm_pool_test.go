@@ -0,0 +1,147 @@
+package grpcserver
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	mcpagent "github.com/manishiitg/mcpagent/agent"
+	"github.com/manishiitg/mcpagent/grpcserver/pb"
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+)
+
+// startTestWarmPool runs a pool whose agents are created without LLM or MCP connections
+func startTestWarmPool(t *testing.T, m *AgentManager, config WarmPoolConfig) *int32 {
+	t.Helper()
+	var created int32
+	pool := m.newWarmPool(config)
+	pool.create = func(ctx context.Context) (*ManagedAgent, error) {
+		atomic.AddInt32(&created, 1)
+		agentCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		return &ManagedAgent{
+			ID:        newManagedAgentID(),
+			SessionID: newManagedSessionID(),
+			Agent:     &mcpagent.Agent{Logger: loggerv2.NewNoop(), MaxTurns: 10},
+			Config:    config.Config,
+			ctx:       agentCtx,
+			cancel:    cancel,
+		}, nil
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	m.poolsMu.Lock()
+	m.warmPools = map[string]*warmPool{config.Name: pool}
+	m.stopPools = cancel
+	m.poolsMu.Unlock()
+	go m.fillWarmPool(ctx, pool)
+	t.Cleanup(m.StopWarmPools)
+	return &created
+}
+
+func waitForIdle(t *testing.T, m *AgentManager, want int) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if pools := m.WarmPools(); len(pools) == 1 && pools[0].Idle == want {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("pool did not reach %d idle agents: %+v", want, m.WarmPools())
+}
+
+func TestCreateAgentTakesWarmAgentWithOverlay(t *testing.T) {
+	m := NewAgentManager(loggerv2.NewNoop(), "")
+	created := startTestWarmPool(t, m, WarmPoolConfig{
+		Name:   "research",
+		Size:   2,
+		Config: AgentConfig{Provider: "openai", ModelID: "gpt-4.1", SelectedServers: []string{"fs"}},
+	})
+	waitForIdle(t, m, 2)
+
+	temperature := 0.4
+	managed, err := m.CreateAgent(context.Background(), CreateAgentRequest{
+		WarmPool: "research",
+		Config: AgentConfig{
+			MaxTurns:    3,
+			Temperature: &temperature,
+			CustomTools: []CustomToolDefinition{{Name: "lookup"}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := m.GetAgent(managed.ID); !ok {
+		t.Fatal("warm agent was not registered with the manager")
+	}
+	if managed.Agent.MaxTurns != 3 || managed.Agent.Temperature != 0.4 || len(managed.CustomTools) != 1 {
+		t.Errorf("overlay not applied: max_turns=%d temperature=%v custom_tools=%d",
+			managed.Agent.MaxTurns, managed.Agent.Temperature, len(managed.CustomTools))
+	}
+	if managed.Config.ModelID != "gpt-4.1" || managed.Config.MaxTurns != 3 {
+		t.Errorf("unexpected resolved config %+v", managed.Config)
+	}
+
+	waitForIdle(t, m, 2)
+	if got := atomic.LoadInt32(created); got != 3 {
+		t.Errorf("created %d agents, want 3 (2 initial + 1 refill)", got)
+	}
+	if pools := m.WarmPools(); pools[0].Hits != 1 || pools[0].Misses != 0 {
+		t.Errorf("unexpected pool status %+v", pools[0])
+	}
+}
+
+func TestWarmPoolBypassedForConnectionChanges(t *testing.T) {
+	pool := &warmPool{config: WarmPoolConfig{
+		Name:            "research",
+		Config:          AgentConfig{Provider: "openai", ModelID: "gpt-4.1", SystemPrompt: "base", MaxTurns: 5},
+		Preset:          "ipo-research",
+		PresetVariables: map[string]string{"COMPANY": "Acme"},
+	}}
+
+	for _, tc := range []struct {
+		name string
+		req  CreateAgentRequest
+		want bool
+	}{
+		{"overlay only", CreateAgentRequest{Config: AgentConfig{SystemPrompt: "custom", EnableStreaming: true}}, true},
+		{"other model", CreateAgentRequest{Config: AgentConfig{ModelID: "gpt-4.1-mini"}}, false},
+		{"session id", CreateAgentRequest{SessionID: "s1"}, false},
+		{"selected tools", CreateAgentRequest{Config: AgentConfig{SelectedTools: []string{"fs:read"}}}, false},
+		{"preset variables", CreateAgentRequest{PresetVariables: map[string]string{"COMPANY": "Beta"}}, false},
+	} {
+		resolved := pool.resolve(tc.req)
+		if got := pool.canServe(tc.req, resolved); got != tc.want {
+			t.Errorf("%s: canServe = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+
+	resolved := pool.resolve(CreateAgentRequest{
+		Config:          AgentConfig{ModelID: "gpt-4.1-mini"},
+		PresetVariables: map[string]string{"YEAR": "2026"},
+	})
+	if resolved.Config.ModelID != "gpt-4.1-mini" || resolved.Config.Provider != "openai" || resolved.Config.SystemPrompt != "base" || resolved.Config.MaxTurns != 5 {
+		t.Errorf("cold request should overlay the pool config, got %+v", resolved.Config)
+	}
+	if resolved.Preset != "ipo-research" || resolved.PresetVariables["COMPANY"] != "Acme" || resolved.PresetVariables["YEAR"] != "2026" {
+		t.Errorf("unexpected preset resolution %q %v", resolved.Preset, resolved.PresetVariables)
+	}
+	if len(pool.config.PresetVariables) != 1 {
+		t.Error("resolving a request must not modify the pool config")
+	}
+}
+
+func TestCreateAgentReportsUnknownWarmPool(t *testing.T) {
+	service := NewAgentService(NewAgentManager(loggerv2.NewNoop(), ""), loggerv2.NewNoop())
+
+	_, err := service.CreateAgent(context.Background(), &pb.CreateAgentRequest{WarmPool: "no-such-pool"})
+	if reason, details := errorInfo(err); reason != ReasonWarmPoolNotFound || details["warm_pool"] != "no-such-pool" {
+		t.Fatalf("reason = %q %v, want %q", reason, details, ReasonWarmPoolNotFound)
+	}
+	if status.Code(err) != codes.NotFound {
+		t.Fatalf("code = %s, want NotFound", status.Code(err))
+	}
+}
@@ -0,0 +1,72 @@
+package grpcserver
+
+import (
+	mcpagent "github.com/manishiitg/mcpagent/agent"
+	"github.com/manishiitg/mcpagent/events"
+	"github.com/manishiitg/mcpagent/grpcserver/pb"
+)
+
+// WatchConversation streams an agent's conversation events to a read-only
+// watcher. Any number of watchers (and Converse streams) can subscribe to the
+// same agent; each gets every event. With replay set, the watcher first
+// receives the events the agent retained (see Config.StreamReplaySize) so a
+// UI that joins mid-conversation catches up before going live. Verbosity
+// tailors the stream per watcher: "full", "chunks" or "milestones".
+func (s *AgentService) WatchConversation(req *pb.WatchConversationRequest, stream pb.AgentService_WatchConversationServer) error {
+	if req.AgentId == "" {
+		return invalidArgumentError("agent_id is required")
+	}
+	agent, ok := s.manager.GetAgent(req.AgentId)
+	if !ok {
+		return agentNotFoundError(req.AgentId)
+	}
+
+	verbosity, err := mcpagent.ParseStreamVerbosity(req.Verbosity)
+	if err != nil {
+		return invalidArgumentError(err.Error())
+	}
+
+	ctx := stream.Context()
+	eventChan, unsubscribe, ok := agent.Agent.SubscribeWithOptions(ctx, mcpagent.SubscribeOptions{Verbosity: verbosity, Replay: req.Replay})
+	if !ok || eventChan == nil {
+		return newStatusError(ReasonInternal, "event streaming is not enabled for this agent; start the server with stream replay enabled",
+			map[string]string{"agent_id": req.AgentId}, 0)
+	}
+	defer unsubscribe()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-eventChan:
+			if !ok {
+				return nil
+			}
+			if resp := s.watchResponse(event); resp != nil {
+				if err := stream.Send(resp); err != nil {
+					return err
+				}
+			}
+		}
+	}
+}
+
+// watchResponse converts an agent event for a watcher: streaming chunks become
+// text chunks, everything else an AgentEvent
+func (s *AgentService) watchResponse(event *events.AgentEvent) *pb.ConversationResponse {
+	if event == nil || event.Data == nil {
+		return nil
+	}
+	if chunk, ok := event.Data.(*events.StreamingChunkEvent); ok {
+		return &pb.ConversationResponse{
+			Payload: &pb.ConversationResponse_TextChunk{
+				TextChunk: &pb.TextChunkEvent{Text: chunk.Content},
+			},
+		}
+	}
+	return &pb.ConversationResponse{
+		Payload: &pb.ConversationResponse_AgentEvent{
+			AgentEvent: agentEventToProto(*event, s.artifacts),
+		},
+	}
+}
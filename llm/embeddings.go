@@ -0,0 +1,360 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+
+	"github.com/manishiitg/multi-llm-provider-go/llmerrors"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+
+	llmproviders "github.com/manishiitg/multi-llm-provider-go"
+)
+
+// ProviderOllama selects the local Ollama embedder in EmbedTexts. It needs no
+// API key and is meant for offline development.
+const ProviderOllama Provider = "ollama"
+
+const (
+	// DefaultEmbeddingBatchSize is the number of texts sent per embedding request
+	DefaultEmbeddingBatchSize = 96
+	// DefaultEmbeddingMaxRetries is the number of retries per batch on retryable errors
+	DefaultEmbeddingMaxRetries = 3
+	// DefaultEmbeddingRetryBackoff is the delay before the first retry; it doubles per retry
+	DefaultEmbeddingRetryBackoff = time.Second
+	// DefaultOllamaURL is used when OLLAMA_HOST is not set
+	DefaultOllamaURL = "http://localhost:11434"
+)
+
+// embeddingInitializer creates provider embedding models; replaced in tests
+var embeddingInitializer = llmproviders.InitializeEmbeddingModel
+
+// EmbeddingResult holds the vectors of one EmbedTexts call
+type EmbeddingResult struct {
+	Vectors      [][]float32             // One vector per input text, in input order
+	Provider     Provider                // Provider that produced the vectors
+	Model        string                  // Model that produced the vectors
+	Usage        llmtypes.EmbeddingUsage // Token usage summed over all batches
+	Requests     int                     // Embedding requests made, including retries
+	UsedFallback bool                    // True when the local fallback embedder produced the vectors
+}
+
+// EmbedOption configures EmbedTexts
+type EmbedOption func(*embedOptions)
+
+type embedOptions struct {
+	batchSize         int
+	requestsPerSecond float64
+	maxRetries        int
+	retryBackoff      time.Duration
+	dimensions        int
+	apiKeys           *ProviderAPIKeys
+	logger            loggerv2.Logger
+	fallbackModel     string
+}
+
+// EmbedWithBatchSize sets the number of texts per request. Default: DefaultEmbeddingBatchSize
+func EmbedWithBatchSize(size int) EmbedOption {
+	return func(o *embedOptions) { o.batchSize = size }
+}
+
+// EmbedWithRateLimit limits requests per second across batches. Default: unlimited
+func EmbedWithRateLimit(requestsPerSecond float64) EmbedOption {
+	return func(o *embedOptions) { o.requestsPerSecond = requestsPerSecond }
+}
+
+// EmbedWithRetries sets retries per batch and the initial backoff for
+// rate-limit, server, network and timeout errors.
+// Default: DefaultEmbeddingMaxRetries, DefaultEmbeddingRetryBackoff
+func EmbedWithRetries(maxRetries int, backoff time.Duration) EmbedOption {
+	return func(o *embedOptions) {
+		o.maxRetries = maxRetries
+		o.retryBackoff = backoff
+	}
+}
+
+// EmbedWithDimensions requests vectors of the given size (text-embedding-3 models)
+func EmbedWithDimensions(dimensions int) EmbedOption {
+	return func(o *embedOptions) { o.dimensions = dimensions }
+}
+
+// EmbedWithAPIKeys sets provider API keys. Default: environment variables
+func EmbedWithAPIKeys(keys *ProviderAPIKeys) EmbedOption {
+	return func(o *embedOptions) { o.apiKeys = keys }
+}
+
+// EmbedWithLogger sets the logger for retry and fallback warnings
+func EmbedWithLogger(logger loggerv2.Logger) EmbedOption {
+	return func(o *embedOptions) { o.logger = logger }
+}
+
+// EmbedWithLocalFallback embeds with the given Ollama model when the provider
+// cannot be initialized (e.g. no credentials offline) or a batch still fails
+// after retries. All texts are then re-embedded locally, so the vectors of one
+// result always come from a single model.
+func EmbedWithLocalFallback(ollamaModel string) EmbedOption {
+	return func(o *embedOptions) { o.fallbackModel = ollamaModel }
+}
+
+// EmbedTexts returns one embedding vector per text. Texts are sent in
+// batches, optionally rate limited, and each batch is retried with
+// exponential backoff on retryable errors.
+//
+// Example usage:
+//
+//	result, err := llm.EmbedTexts(ctx, llm.ProviderOpenAI, "text-embedding-3-small", docs,
+//	    llm.EmbedWithRateLimit(5), llm.EmbedWithLocalFallback("nomic-embed-text"))
+func EmbedTexts(ctx context.Context, provider Provider, model string, texts []string, opts ...EmbedOption) (*EmbeddingResult, error) {
+	o := embedOptions{
+		batchSize:    DefaultEmbeddingBatchSize,
+		maxRetries:   DefaultEmbeddingMaxRetries,
+		retryBackoff: DefaultEmbeddingRetryBackoff,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.batchSize <= 0 {
+		o.batchSize = DefaultEmbeddingBatchSize
+	}
+
+	result, err := embedWithProvider(ctx, provider, model, texts, o)
+	if err == nil || o.fallbackModel == "" || provider == ProviderOllama || ctx.Err() != nil {
+		return result, err
+	}
+	if o.logger != nil {
+		o.logger.Warn("Embedding provider failed, using local fallback embedder",
+			loggerv2.String("provider", string(provider)),
+			loggerv2.String("model", model),
+			loggerv2.String("fallback_model", o.fallbackModel),
+			loggerv2.Error(err))
+	}
+	fallback, fallbackErr := embedWithProvider(ctx, ProviderOllama, o.fallbackModel, texts, o)
+	if fallbackErr != nil {
+		return nil, fmt.Errorf("embedding failed: %w (local fallback also failed: %w)", err, fallbackErr)
+	}
+	if result != nil {
+		fallback.Requests += result.Requests
+	}
+	fallback.UsedFallback = true
+	return fallback, nil
+}
+
+func embedWithProvider(ctx context.Context, provider Provider, model string, texts []string, o embedOptions) (*EmbeddingResult, error) {
+	result := &EmbeddingResult{Provider: provider, Model: model, Vectors: make([][]float32, 0, len(texts))}
+	if len(texts) == 0 {
+		return result, nil
+	}
+
+	var embedder llmtypes.EmbeddingModel
+	if provider == ProviderOllama {
+		embedder = NewOllamaEmbedder("", model)
+	} else {
+		var err error
+		embedder, err = embeddingInitializer(convertConfig(Config{Provider: provider, ModelID: model, APIKeys: o.apiKeys, Logger: o.logger, Context: ctx}))
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize embedding model: %w", err)
+		}
+	}
+
+	callOpts := []llmtypes.EmbeddingOption{llmtypes.WithEmbeddingModel(model)}
+	if o.dimensions > 0 {
+		callOpts = append(callOpts, llmtypes.WithDimensions(o.dimensions))
+	}
+	limiter := newRequestLimiter(o.requestsPerSecond)
+
+	for start := 0; start < len(texts); start += o.batchSize {
+		batch := texts[start:min(start+o.batchSize, len(texts))]
+		resp, err := embedBatch(ctx, embedder, batch, callOpts, limiter, o, result)
+		if err != nil {
+			return result, fmt.Errorf("embedding batch starting at text %d failed: %w", start, llmerrors.Classify(string(provider), model, err))
+		}
+		vectors, err := orderedVectors(resp, len(batch))
+		if err != nil {
+			return result, err
+		}
+		result.Vectors = append(result.Vectors, vectors...)
+		if resp.Usage != nil {
+			result.Usage.PromptTokens += resp.Usage.PromptTokens
+			result.Usage.TotalTokens += resp.Usage.TotalTokens
+		}
+	}
+	return result, nil
+}
+
+// embedBatch sends one batch, retrying retryable errors with exponential backoff
+func embedBatch(ctx context.Context, embedder llmtypes.EmbeddingModel, batch []string, callOpts []llmtypes.EmbeddingOption, limiter *requestLimiter, o embedOptions, result *EmbeddingResult) (*llmtypes.EmbeddingResponse, error) {
+	backoff := o.retryBackoff
+	for attempt := 0; ; attempt++ {
+		if err := limiter.wait(ctx); err != nil {
+			return nil, err
+		}
+		result.Requests++
+		resp, err := embedder.GenerateEmbeddings(ctx, batch, callOpts...)
+		if err == nil {
+			return resp, nil
+		}
+		classified := llmerrors.Classify(string(result.Provider), result.Model, err)
+		if attempt >= o.maxRetries || !llmerrors.IsRetryable(classified) {
+			return nil, err
+		}
+		if o.logger != nil {
+			o.logger.Warn("Retrying embedding batch",
+				loggerv2.String("provider", string(result.Provider)),
+				loggerv2.Int("attempt", attempt+1),
+				loggerv2.String("backoff", backoff.String()),
+				loggerv2.Error(err))
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// orderedVectors returns the vectors of resp in input order
+func orderedVectors(resp *llmtypes.EmbeddingResponse, n int) ([][]float32, error) {
+	if resp == nil || len(resp.Embeddings) != n {
+		got := 0
+		if resp != nil {
+			got = len(resp.Embeddings)
+		}
+		return nil, fmt.Errorf("embedding response has %d vectors for %d texts", got, n)
+	}
+	vectors := make([][]float32, n)
+	for i, emb := range resp.Embeddings {
+		idx := emb.Index
+		if idx < 0 || idx >= n || vectors[idx] != nil {
+			idx = i // Providers that omit the index return vectors in input order
+		}
+		vectors[idx] = emb.Embedding
+	}
+	return vectors, nil
+}
+
+// requestLimiter spaces requests at least 1/rps apart
+type requestLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+func newRequestLimiter(requestsPerSecond float64) *requestLimiter {
+	if requestsPerSecond <= 0 {
+		return &requestLimiter{}
+	}
+	return &requestLimiter{interval: time.Duration(float64(time.Second) / requestsPerSecond)}
+}
+
+func (l *requestLimiter) wait(ctx context.Context) error {
+	if l.interval == 0 {
+		return ctx.Err()
+	}
+	l.mu.Lock()
+	now := time.Now()
+	slot := l.next
+	if slot.Before(now) {
+		slot = now
+	}
+	l.next = slot.Add(l.interval)
+	l.mu.Unlock()
+
+	if delay := time.Until(slot); delay > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+	return nil
+}
+
+// OllamaEmbedder generates embeddings with a local Ollama server
+// (https://ollama.com) through its /api/embed endpoint
+type OllamaEmbedder struct {
+	BaseURL string
+	Model   string
+	Client  *http.Client
+}
+
+// NewOllamaEmbedder creates an OllamaEmbedder. An empty baseURL uses
+// OLLAMA_HOST, or DefaultOllamaURL when that is unset.
+func NewOllamaEmbedder(baseURL, model string) *OllamaEmbedder {
+	if baseURL == "" {
+		baseURL = os.Getenv("OLLAMA_HOST")
+	}
+	if baseURL == "" {
+		baseURL = DefaultOllamaURL
+	}
+	if !strings.Contains(baseURL, "://") {
+		baseURL = "http://" + baseURL
+	}
+	return &OllamaEmbedder{BaseURL: strings.TrimRight(baseURL, "/"), Model: model, Client: &http.Client{Timeout: 2 * time.Minute}}
+}
+
+// GenerateEmbeddings implements llmtypes.EmbeddingModel. Input is a string or []string.
+func (e *OllamaEmbedder) GenerateEmbeddings(ctx context.Context, input interface{}, options ...llmtypes.EmbeddingOption) (*llmtypes.EmbeddingResponse, error) {
+	var texts []string
+	switch v := input.(type) {
+	case string:
+		texts = []string{v}
+	case []string:
+		texts = v
+	default:
+		return nil, fmt.Errorf("unsupported embedding input type %T", input)
+	}
+	opts := llmtypes.EmbeddingOptions{Model: e.Model}
+	for _, opt := range options {
+		opt(&opts)
+	}
+
+	body, err := json.Marshal(map[string]interface{}{"model": opts.Model, "input": texts})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.BaseURL+"/api/embed", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := e.Client.Do(req)
+	if err != nil {
+		return nil, &llmerrors.Error{Kind: llmerrors.KindNetwork, Provider: string(ProviderOllama), Model: opts.Model, Err: err}
+	}
+	defer func() { _ = resp.Body.Close() }()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 256<<20))
+	if err != nil {
+		return nil, &llmerrors.Error{Kind: llmerrors.KindNetwork, Provider: string(ProviderOllama), Model: opts.Model, Err: err}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, llmerrors.Classify(string(ProviderOllama), opts.Model,
+			fmt.Errorf("ollama embed failed: status %d: %s", resp.StatusCode, strings.TrimSpace(string(data))))
+	}
+
+	var parsed struct {
+		Model           string      `json:"model"`
+		Embeddings      [][]float32 `json:"embeddings"`
+		PromptEvalCount int         `json:"prompt_eval_count"`
+	}
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		return nil, fmt.Errorf("failed to decode ollama embed response: %w", err)
+	}
+	out := &llmtypes.EmbeddingResponse{
+		Model: opts.Model,
+		Usage: &llmtypes.EmbeddingUsage{PromptTokens: parsed.PromptEvalCount, TotalTokens: parsed.PromptEvalCount},
+	}
+	for i, vec := range parsed.Embeddings {
+		out.Embeddings = append(out.Embeddings, llmtypes.Embedding{Index: i, Embedding: vec, Object: "embedding"})
+	}
+	return out, nil
+}
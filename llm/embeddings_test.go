@@ -0,0 +1,120 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+
+	llmproviders "github.com/manishiitg/multi-llm-provider-go"
+)
+
+type fakeEmbeddingModel struct {
+	failures []error // returned by the first calls, in order
+	batches  [][]string
+}
+
+func (m *fakeEmbeddingModel) GenerateEmbeddings(_ context.Context, input interface{}, _ ...llmtypes.EmbeddingOption) (*llmtypes.EmbeddingResponse, error) {
+	if len(m.failures) > 0 {
+		err := m.failures[0]
+		m.failures = m.failures[1:]
+		return nil, err
+	}
+	texts := input.([]string)
+	m.batches = append(m.batches, texts)
+	resp := &llmtypes.EmbeddingResponse{Usage: &llmtypes.EmbeddingUsage{PromptTokens: len(texts), TotalTokens: len(texts)}}
+	// Return vectors out of order to check that Index is honoured
+	for i := len(texts) - 1; i >= 0; i-- {
+		resp.Embeddings = append(resp.Embeddings, llmtypes.Embedding{Index: i, Embedding: []float32{float32(len(texts[i]))}})
+	}
+	return resp, nil
+}
+
+func useEmbeddingModel(t *testing.T, model llmtypes.EmbeddingModel, err error) {
+	t.Helper()
+	prev := embeddingInitializer
+	embeddingInitializer = func(llmproviders.Config) (llmtypes.EmbeddingModel, error) { return model, err }
+	t.Cleanup(func() { embeddingInitializer = prev })
+}
+
+func TestEmbedTextsBatchesInOrder(t *testing.T) {
+	model := &fakeEmbeddingModel{}
+	useEmbeddingModel(t, model, nil)
+
+	texts := []string{"a", "bb", "ccc", "dddd", "eeeee"}
+	result, err := EmbedTexts(context.Background(), ProviderOpenAI, "text-embedding-3-small", texts, EmbedWithBatchSize(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(model.batches) != 3 || result.Requests != 3 {
+		t.Fatalf("expected 3 batches, got %d (requests %d)", len(model.batches), result.Requests)
+	}
+	for i, text := range texts {
+		if result.Vectors[i][0] != float32(len(text)) {
+			t.Fatalf("vector %d out of order: %v", i, result.Vectors)
+		}
+	}
+	if result.Usage.TotalTokens != 5 || result.UsedFallback {
+		t.Errorf("unexpected usage or fallback: %+v", result)
+	}
+}
+
+func TestEmbedTextsRetriesRetryableErrors(t *testing.T) {
+	model := &fakeEmbeddingModel{failures: []error{errors.New("429 too many requests")}}
+	useEmbeddingModel(t, model, nil)
+
+	result, err := EmbedTexts(context.Background(), ProviderOpenAI, "m", []string{"x"}, EmbedWithRetries(2, time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Requests != 2 {
+		t.Errorf("expected one retry, got %d requests", result.Requests)
+	}
+
+	model = &fakeEmbeddingModel{failures: []error{errors.New("401 invalid api key")}}
+	useEmbeddingModel(t, model, nil)
+	if _, err := EmbedTexts(context.Background(), ProviderOpenAI, "m", []string{"x"}, EmbedWithRetries(2, time.Millisecond)); err == nil {
+		t.Error("auth errors must not be retried into success")
+	}
+	if len(model.failures) != 0 || len(model.batches) != 0 {
+		t.Errorf("auth error should fail after one request")
+	}
+}
+
+func TestEmbedTextsLocalFallback(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Model string   `json:"model"`
+			Input []string `json:"input"`
+		}
+		if r.URL.Path != "/api/embed" || json.NewDecoder(r.Body).Decode(&req) != nil || req.Model != "nomic-embed-text" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		vectors := make([][]float32, len(req.Input))
+		for i := range req.Input {
+			vectors[i] = []float32{1, 2}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"embeddings": vectors, "prompt_eval_count": 7})
+	}))
+	defer server.Close()
+	t.Setenv("OLLAMA_HOST", server.URL)
+	useEmbeddingModel(t, nil, errors.New("OPENAI_API_KEY is not set"))
+
+	result, err := EmbedTexts(context.Background(), ProviderOpenAI, "text-embedding-3-small", []string{"a", "b"},
+		EmbedWithLocalFallback("nomic-embed-text"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.UsedFallback || result.Provider != ProviderOllama || result.Model != "nomic-embed-text" {
+		t.Errorf("expected local fallback result, got %+v", result)
+	}
+	if len(result.Vectors) != 2 || len(result.Vectors[1]) != 2 || result.Usage.PromptTokens != 7 {
+		t.Errorf("unexpected vectors or usage: %+v", result)
+	}
+}
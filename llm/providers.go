@@ -409,6 +409,14 @@ type Config struct {
 	// ClaudeCodeTransport optionally overrides CLAUDE_CODE_TRANSPORT for this
 	// initialized Claude Code model.
 	ClaudeCodeTransport string
+	// RegionRouting optionally routes calls across multiple regions, keyed by
+	// provider (Bedrock and Vertex). Applied when Provider has an entry with
+	// at least two regions. See RegionRoutingConfig.
+	RegionRouting map[Provider]RegionRoutingConfig
+	// RateLimits sets process-wide request and token limits, keyed by
+	// provider. They are shared by every model and agent in the process;
+	// calls over the limit queue instead of hitting 429s. See RateLimit.
+	RateLimits map[Provider]RateLimit
 }
 
 // ProviderAPIKeys is the canonical API key holder — aliased from multi-llm-provider-go.
@@ -500,15 +508,31 @@ func convertConfig(config Config) llmproviders.Config {
 	}
 }
 
+// llmInitializer initializes provider models; replaced in tests
+var llmInitializer = llmproviders.InitializeLLM
+
 // InitializeLLM creates and initializes an LLM based on the provider configuration
 // This function maintains backward compatibility by accepting agent_go Config
 // and converting it to llm-providers Config internally
 func InitializeLLM(config Config) (llmtypes.Model, error) {
+	if limit, ok := config.RateLimits[config.Provider]; ok {
+		SetProviderRateLimit(config.Provider, limit)
+	}
+
+	// Route across regions when configured for this provider
+	if routing, ok := config.RegionRouting[config.Provider]; ok && len(routing.Regions) > 1 {
+		router, err := initializeRegionRouter(config, routing)
+		if err != nil {
+			return nil, err
+		}
+		return wrapProviderAwareLLM(router, config.Provider, config.ModelID, config.Logger, config.APIKeys), nil
+	}
+
 	// Convert agent_go Config to llm-providers Config
 	externalConfig := convertConfig(config)
 
 	// Call llm-providers InitializeLLM (already returns llmtypes.Model)
-	llm, err := llmproviders.InitializeLLM(externalConfig)
+	llm, err := llmInitializer(externalConfig)
 	if err != nil {
 		return nil, err
 	}
@@ -553,7 +577,8 @@ func (p *ProviderAwareLLM) GetAPIKeys() *ProviderAPIKeys {
 	return p.apiKeys
 }
 
-// GenerateContent wraps the underlying model and adds OpenRouter usage metadata.
+// GenerateContent wraps the underlying model, adds OpenRouter usage metadata
+// and applies the provider's rate limit (see SetProviderRateLimit).
 func (p *ProviderAwareLLM) GenerateContent(ctx context.Context, messages []llmtypes.MessageContent, options ...llmtypes.CallOption) (*llmtypes.ContentResponse, error) {
 	// Automatically add usage parameter for OpenRouter requests to get cache token information
 	if p.provider == ProviderOpenRouter {
@@ -563,8 +588,19 @@ func (p *ProviderAwareLLM) GenerateContent(ctx context.Context, messages []llmty
 		options = append(options, WithOpenRouterUsage())
 	}
 
-	// Call the underlying LLM (which is already a ProviderAwareLLM from llm-providers)
-	return p.Model.GenerateContent(ctx, messages, options...)
+	// Queue behind the provider's process-wide rate limit, if any
+	limiter := ProviderRateLimiter(p.provider)
+	if limiter == nil {
+		// Call the underlying LLM (which is already a ProviderAwareLLM from llm-providers)
+		return p.Model.GenerateContent(ctx, messages, options...)
+	}
+	entry, err := limiter.acquire(ctx, p.modelID, estimateInputTokens(messages))
+	if err != nil {
+		return nil, err
+	}
+	resp, err := p.Model.GenerateContent(ctx, messages, options...)
+	limiter.settle(entry, resp)
+	return resp, err
 }
 
 // SearchWeb calls a model's native web search capability when available.
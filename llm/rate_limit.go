@@ -0,0 +1,269 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+// rateLimitWindow is the sliding window the limits apply to
+const rateLimitWindow = time.Minute
+
+// RateLimit bounds the calls to one provider across every model and agent in
+// the process. Calls over the limit are queued in arrival order until the
+// sliding one-minute window has room, instead of being sent and rejected
+// with 429s.
+type RateLimit struct {
+	// RequestsPerMinute bounds the calls started per minute; 0 = unlimited
+	RequestsPerMinute int
+	// TokensPerMinute bounds the tokens (input + output) per minute; 0 =
+	// unlimited. A call reserves an estimate of its input tokens and is
+	// charged its reported usage once it returns.
+	TokensPerMinute int
+	// MaxWait fails a call that would wait longer with ErrRateLimitWait;
+	// 0 = wait until the context is done
+	MaxWait time.Duration
+}
+
+// RateLimitWait describes a call held back by a rate limit
+type RateLimitWait struct {
+	Provider Provider
+	ModelID  string
+	// Reason is "requests_per_minute" or "tokens_per_minute"
+	Reason string
+	Wait   time.Duration
+	// Queued is the number of calls waiting for the provider, including this one
+	Queued int
+}
+
+// ErrRateLimitWait is returned when a call would wait longer than RateLimit.MaxWait
+type ErrRateLimitWait struct {
+	Provider Provider
+	Wait     time.Duration
+	MaxWait  time.Duration
+}
+
+func (e *ErrRateLimitWait) Error() string {
+	return fmt.Sprintf("rate limit for %s: call would wait %s (max %s)", e.Provider, e.Wait.Round(time.Millisecond), e.MaxWait)
+}
+
+type rateLimitWaitHandlerKey struct{}
+
+// WithRateLimitWaitHandler returns a context whose LLM calls report rate
+// limit waits to handler before they wait. The agent uses it to emit
+// rate_limit_wait events.
+func WithRateLimitWaitHandler(ctx context.Context, handler func(RateLimitWait)) context.Context {
+	return context.WithValue(ctx, rateLimitWaitHandlerKey{}, handler)
+}
+
+var (
+	rateLimitersMu sync.Mutex
+	rateLimiters   = make(map[Provider]*RateLimiter)
+)
+
+// SetProviderRateLimit sets the process-wide rate limit of provider. It is
+// called by InitializeLLM for Config.RateLimits; a zero RateLimit removes the
+// limit. Calls already waiting keep their place in the queue.
+func SetProviderRateLimit(provider Provider, limit RateLimit) {
+	rateLimitersMu.Lock()
+	defer rateLimitersMu.Unlock()
+	if limit.RequestsPerMinute <= 0 && limit.TokensPerMinute <= 0 {
+		delete(rateLimiters, provider)
+		return
+	}
+	if limiter, ok := rateLimiters[provider]; ok {
+		limiter.setLimit(limit)
+		return
+	}
+	rateLimiters[provider] = newRateLimiter(provider, limit)
+}
+
+// ProviderRateLimiter returns the process-wide rate limiter of provider, or
+// nil if it is not limited
+func ProviderRateLimiter(provider Provider) *RateLimiter {
+	rateLimitersMu.Lock()
+	defer rateLimitersMu.Unlock()
+	return rateLimiters[provider]
+}
+
+// rateLimitEntry is one call in the window
+type rateLimitEntry struct {
+	at     time.Time
+	tokens int
+}
+
+// RateLimiter enforces a RateLimit with a sliding one-minute window
+type RateLimiter struct {
+	provider Provider
+
+	// queue admits one waiting call at a time, in arrival order
+	queue  chan struct{}
+	queued int
+
+	mu      sync.Mutex
+	limit   RateLimit
+	entries []*rateLimitEntry
+
+	now  func() time.Time
+	wait func(ctx context.Context, d time.Duration) error
+}
+
+func newRateLimiter(provider Provider, limit RateLimit) *RateLimiter {
+	return &RateLimiter{
+		provider: provider,
+		queue:    make(chan struct{}, 1),
+		limit:    limit,
+		now:      time.Now,
+		wait:     sleepContext,
+	}
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func (l *RateLimiter) setLimit(limit RateLimit) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.limit = limit
+}
+
+// Usage returns the requests and tokens counted in the current window
+func (l *RateLimiter) Usage() (requests, tokens int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.prune(l.now())
+	for _, e := range l.entries {
+		tokens += e.tokens
+	}
+	return len(l.entries), tokens
+}
+
+// acquire waits until a call estimated at tokens fits the limit and records
+// it. The returned entry is updated with the call's actual usage.
+func (l *RateLimiter) acquire(ctx context.Context, modelID string, tokens int) (*rateLimitEntry, error) {
+	l.mu.Lock()
+	l.queued++
+	l.mu.Unlock()
+	defer func() {
+		l.mu.Lock()
+		l.queued--
+		l.mu.Unlock()
+	}()
+
+	select {
+	case l.queue <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	defer func() { <-l.queue }()
+
+	var waited time.Duration
+	for {
+		l.mu.Lock()
+		now := l.now()
+		delay, reason := l.delay(now, tokens)
+		if delay <= 0 {
+			entry := &rateLimitEntry{at: now, tokens: tokens}
+			l.entries = append(l.entries, entry)
+			l.mu.Unlock()
+			return entry, nil
+		}
+		maxWait, queued := l.limit.MaxWait, l.queued
+		l.mu.Unlock()
+
+		if maxWait > 0 && waited+delay > maxWait {
+			return nil, &ErrRateLimitWait{Provider: l.provider, Wait: waited + delay, MaxWait: maxWait}
+		}
+		if handler, ok := ctx.Value(rateLimitWaitHandlerKey{}).(func(RateLimitWait)); ok && handler != nil {
+			handler(RateLimitWait{Provider: l.provider, ModelID: modelID, Reason: reason, Wait: delay, Queued: queued})
+		}
+		if err := l.wait(ctx, delay); err != nil {
+			return nil, err
+		}
+		waited += delay
+	}
+}
+
+// delay returns how long a call of tokens must wait at now, and which limit
+// holds it. The caller holds l.mu.
+func (l *RateLimiter) delay(now time.Time, tokens int) (time.Duration, string) {
+	l.prune(now)
+	if rpm := l.limit.RequestsPerMinute; rpm > 0 && len(l.entries) >= rpm {
+		return l.entries[len(l.entries)-rpm].at.Add(rateLimitWindow).Sub(now), "requests_per_minute"
+	}
+	tpm := l.limit.TokensPerMinute
+	if tpm <= 0 || len(l.entries) == 0 {
+		return 0, ""
+	}
+	used := 0
+	for _, e := range l.entries {
+		used += e.tokens
+	}
+	// A call larger than the whole budget runs alone in an empty window
+	if used+tokens <= tpm {
+		return 0, ""
+	}
+	// Wait for the oldest entries to leave the window until the call fits
+	for _, e := range l.entries {
+		used -= e.tokens
+		if used+tokens <= tpm || used == 0 {
+			return e.at.Add(rateLimitWindow).Sub(now), "tokens_per_minute"
+		}
+	}
+	return 0, ""
+}
+
+// prune drops the entries that left the window. The caller holds l.mu.
+func (l *RateLimiter) prune(now time.Time) {
+	i := 0
+	for i < len(l.entries) && !l.entries[i].at.Add(rateLimitWindow).After(now) {
+		i++
+	}
+	l.entries = l.entries[i:]
+}
+
+// settle charges the call its reported usage, keeping the estimate when the
+// provider reported none
+func (l *RateLimiter) settle(entry *rateLimitEntry, resp *llmtypes.ContentResponse) {
+	if resp == nil || len(resp.Choices) == 0 || resp.Choices[0] == nil {
+		return
+	}
+	usage := extractTokenUsageFromGenerationInfo(resp.Choices[0].GenerationInfo)
+	if usage.TotalTokens <= 0 {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	entry.tokens = usage.TotalTokens
+}
+
+// estimateInputTokens approximates the input tokens of messages (4 characters per token)
+func estimateInputTokens(messages []llmtypes.MessageContent) int {
+	chars := 0
+	for _, msg := range messages {
+		for _, part := range msg.Parts {
+			switch p := part.(type) {
+			case llmtypes.TextContent:
+				chars += len(p.Text)
+			case llmtypes.ToolCall:
+				if p.FunctionCall != nil {
+					chars += len(p.FunctionCall.Name) + len(p.FunctionCall.Arguments)
+				}
+			case llmtypes.ToolCallResponse:
+				chars += len(p.Content)
+			}
+		}
+	}
+	return (chars + 3) / 4
+}
@@ -0,0 +1,86 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+// newTestLimiter returns a limiter on a fake clock that advances while waiting
+func newTestLimiter(limit RateLimit) (*RateLimiter, *time.Time) {
+	limiter := newRateLimiter("test", limit)
+	now := time.Unix(0, 0)
+	limiter.now = func() time.Time { return now }
+	limiter.wait = func(_ context.Context, d time.Duration) error {
+		now = now.Add(d)
+		return nil
+	}
+	return limiter, &now
+}
+
+func TestRateLimiterQueuesOverRequestsPerMinute(t *testing.T) {
+	limiter, now := newTestLimiter(RateLimit{RequestsPerMinute: 2})
+	var waits []RateLimitWait
+	ctx := WithRateLimitWaitHandler(context.Background(), func(w RateLimitWait) { waits = append(waits, w) })
+
+	for i := 0; i < 3; i++ {
+		if i > 0 {
+			*now = now.Add(10 * time.Second)
+		}
+		if _, err := limiter.acquire(ctx, "model", 10); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The third call waited until the first left the window
+	if len(waits) != 1 || waits[0].Reason != "requests_per_minute" || waits[0].Wait != 40*time.Second {
+		t.Fatalf("waits = %+v", waits)
+	}
+	if requests, tokens := limiter.Usage(); requests != 2 || tokens != 20 {
+		t.Errorf("Usage() = %d requests, %d tokens, want 2, 20", requests, tokens)
+	}
+}
+
+func TestRateLimiterTokensPerMinuteAndMaxWait(t *testing.T) {
+	limiter, _ := newTestLimiter(RateLimit{TokensPerMinute: 1000, MaxWait: 30 * time.Second})
+	first, err := limiter.acquire(context.Background(), "model", 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The reported usage replaces the estimate
+	tokens := 900
+	limiter.settle(first, &llmtypes.ContentResponse{Choices: []*llmtypes.ContentChoice{{
+		GenerationInfo: &llmtypes.GenerationInfo{TotalTokens: &tokens},
+	}}})
+
+	_, err = limiter.acquire(context.Background(), "model", 200)
+	var waitErr *ErrRateLimitWait
+	if !errors.As(err, &waitErr) || waitErr.Wait != time.Minute {
+		t.Fatalf("acquire() error = %v, want a one-minute ErrRateLimitWait", err)
+	}
+
+	limiter.setLimit(RateLimit{TokensPerMinute: 1000})
+	if _, err := limiter.acquire(context.Background(), "model", 5000); err != nil {
+		t.Fatalf("a call over the whole budget should run alone once the window is empty: %v", err)
+	}
+}
+
+func TestProviderAwareLLMUsesSharedRateLimit(t *testing.T) {
+	SetProviderRateLimit(ProviderKimi, RateLimit{RequestsPerMinute: 1, MaxWait: time.Millisecond})
+	t.Cleanup(func() { SetProviderRateLimit(ProviderKimi, RateLimit{}) })
+
+	first := wrapProviderAwareLLM(&fakeRegionModel{region: "a"}, ProviderKimi, "model-a", nil, nil)
+	second := wrapProviderAwareLLM(&fakeRegionModel{region: "b"}, ProviderKimi, "model-b", nil, nil)
+	if _, err := first.GenerateContent(context.Background(), nil); err != nil {
+		t.Fatal(err)
+	}
+	var waitErr *ErrRateLimitWait
+	if _, err := second.GenerateContent(context.Background(), nil); !errors.As(err, &waitErr) {
+		t.Fatalf("second model of the same provider: error = %v, want ErrRateLimitWait", err)
+	}
+	if ProviderRateLimiter(ProviderOpenAI) != nil {
+		t.Error("other providers must stay unlimited")
+	}
+}
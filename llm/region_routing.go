@@ -0,0 +1,312 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+
+	"github.com/manishiitg/multi-llm-provider-go/llmerrors"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+	vertexadapter "github.com/manishiitg/multi-llm-provider-go/pkg/adapters/vertex"
+)
+
+// Defaults for RegionRoutingConfig fields left at zero
+const (
+	DefaultRegionUnhealthyCooldown = 60 * time.Second
+	DefaultRegionLatencySmoothing  = 0.3
+)
+
+// RegionRoutingConfig routes calls for one provider across several regions.
+// Each call goes to the healthy region with the lowest observed latency; a
+// region that fails with a region-level error (outage, throttling, timeout)
+// is skipped for UnhealthyCooldown and the call is retried in the next region.
+// Only when every region has failed is the error returned, so the agent's
+// cross-provider fallback runs after regional failover is exhausted.
+//
+// Supported providers:
+//   - Bedrock: Regions are AWS regions (e.g. "us-east-1", "us-west-2")
+//   - Vertex: Regions are Vertex locations (e.g. "us-east5", "europe-west1").
+//     Only Anthropic (claude-*) models are regional; VERTEX_PROJECT_ID must be set.
+type RegionRoutingConfig struct {
+	// Regions in order of preference. Before latency has been measured,
+	// regions are tried in this order.
+	Regions []string
+	// UnhealthyCooldown is how long a failed region is skipped before it is tried again
+	UnhealthyCooldown time.Duration
+	// LatencySmoothing is the weight (0-1] of the newest sample in the
+	// per-region moving average latency
+	LatencySmoothing float64
+}
+
+func (c RegionRoutingConfig) withDefaults() RegionRoutingConfig {
+	if c.UnhealthyCooldown <= 0 {
+		c.UnhealthyCooldown = DefaultRegionUnhealthyCooldown
+	}
+	if c.LatencySmoothing <= 0 || c.LatencySmoothing > 1 {
+		c.LatencySmoothing = DefaultRegionLatencySmoothing
+	}
+	return c
+}
+
+// RegionHealth is a snapshot of a region's routing state
+type RegionHealth struct {
+	Region         string
+	Healthy        bool
+	Latency        time.Duration // Moving average; zero until the first successful call
+	LastError      string
+	UnhealthyUntil time.Time
+}
+
+// regionEndpoint is one regional model instance and its routing state
+type regionEndpoint struct {
+	region         string
+	model          llmtypes.Model
+	latency        time.Duration
+	lastError      string
+	unhealthyUntil time.Time
+}
+
+// RegionRouter is an llmtypes.Model that spreads calls across regional
+// instances of the same model. It is created by InitializeLLM when
+// Config.RegionRouting has an entry for the provider.
+type RegionRouter struct {
+	modelID   string
+	endpoints []*regionEndpoint
+	config    RegionRoutingConfig
+	logger    loggerv2.Logger
+	now       func() time.Time
+
+	mu sync.Mutex
+}
+
+// newRegionRouter creates a router over already-initialized regional models
+func newRegionRouter(modelID string, regions []string, models []llmtypes.Model, config RegionRoutingConfig, logger loggerv2.Logger) *RegionRouter {
+	endpoints := make([]*regionEndpoint, len(regions))
+	for i, region := range regions {
+		endpoints[i] = &regionEndpoint{region: region, model: models[i]}
+	}
+	return &RegionRouter{
+		modelID:   modelID,
+		endpoints: endpoints,
+		config:    config.withDefaults(),
+		logger:    logger,
+		now:       time.Now,
+	}
+}
+
+// GetModelID returns the model ID served by every region
+func (r *RegionRouter) GetModelID() string {
+	return r.modelID
+}
+
+// GetModelMetadata returns metadata from the first region; all regions serve the same model
+func (r *RegionRouter) GetModelMetadata(modelID string) (*llmtypes.ModelMetadata, error) {
+	return r.endpoints[0].model.GetModelMetadata(modelID)
+}
+
+// GenerateContent sends the call to the best region, failing over to the
+// remaining regions on region-level errors.
+func (r *RegionRouter) GenerateContent(ctx context.Context, messages []llmtypes.MessageContent, options ...llmtypes.CallOption) (*llmtypes.ContentResponse, error) {
+	var lastErr error
+	for _, ep := range r.candidates() {
+		start := r.now()
+		resp, err := ep.model.GenerateContent(ctx, messages, options...)
+		if err == nil {
+			r.recordSuccess(ep, r.now().Sub(start))
+			return resp, nil
+		}
+		if !isRegionalFailure(ctx, err) {
+			return nil, err
+		}
+		r.recordFailure(ep, err)
+		lastErr = err
+		if r.logger != nil {
+			r.logger.Warn("LLM region failed, trying next region",
+				loggerv2.String("model_id", r.modelID),
+				loggerv2.String("region", ep.region),
+				loggerv2.String("error", err.Error()))
+		}
+	}
+	return nil, fmt.Errorf("all regions failed for %s: %w", r.modelID, lastErr)
+}
+
+// CheckHealth sends a minimal request to every region and updates health and
+// latency from the results. Use it to warm up latency measurements or to
+// recover regions before their cooldown ends.
+func (r *RegionRouter) CheckHealth(ctx context.Context) []RegionHealth {
+	probe := []llmtypes.MessageContent{llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "ping")}
+
+	var wg sync.WaitGroup
+	for _, ep := range r.endpoints {
+		wg.Add(1)
+		go func(ep *regionEndpoint) {
+			defer wg.Done()
+			start := r.now()
+			_, err := ep.model.GenerateContent(ctx, probe, llmtypes.WithMaxTokens(1))
+			if err != nil {
+				r.recordFailure(ep, err)
+				return
+			}
+			r.recordSuccess(ep, r.now().Sub(start))
+		}(ep)
+	}
+	wg.Wait()
+	return r.Health()
+}
+
+// StartHealthChecks runs CheckHealth every interval until ctx is canceled
+func (r *RegionRouter) StartHealthChecks(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				r.CheckHealth(ctx)
+			}
+		}
+	}()
+}
+
+// Health returns the current routing state of every region in configured order
+func (r *RegionRouter) Health() []RegionHealth {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := r.now()
+	health := make([]RegionHealth, len(r.endpoints))
+	for i, ep := range r.endpoints {
+		health[i] = RegionHealth{
+			Region:         ep.region,
+			Healthy:        !now.Before(ep.unhealthyUntil),
+			Latency:        ep.latency,
+			LastError:      ep.lastError,
+			UnhealthyUntil: ep.unhealthyUntil,
+		}
+	}
+	return health
+}
+
+// candidates orders regions for a call: healthy regions by latency (regions
+// without measurements keep their configured order and are tried first so
+// they get measured), then unhealthy regions by soonest recovery as a last resort.
+func (r *RegionRouter) candidates() []*regionEndpoint {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := r.now()
+
+	var healthy, unhealthy []*regionEndpoint
+	for _, ep := range r.endpoints {
+		if now.Before(ep.unhealthyUntil) {
+			unhealthy = append(unhealthy, ep)
+		} else {
+			healthy = append(healthy, ep)
+		}
+	}
+	sort.SliceStable(healthy, func(i, j int) bool {
+		return healthy[i].latency < healthy[j].latency
+	})
+	sort.SliceStable(unhealthy, func(i, j int) bool {
+		return unhealthy[i].unhealthyUntil.Before(unhealthy[j].unhealthyUntil)
+	})
+	return append(healthy, unhealthy...)
+}
+
+func (r *RegionRouter) recordSuccess(ep *regionEndpoint, latency time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if ep.latency == 0 {
+		ep.latency = latency
+	} else {
+		alpha := r.config.LatencySmoothing
+		ep.latency = time.Duration(alpha*float64(latency) + (1-alpha)*float64(ep.latency))
+	}
+	ep.lastError = ""
+	ep.unhealthyUntil = time.Time{}
+}
+
+func (r *RegionRouter) recordFailure(ep *regionEndpoint, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ep.lastError = err.Error()
+	ep.unhealthyUntil = r.now().Add(r.config.UnhealthyCooldown)
+}
+
+// isRegionalFailure reports whether err may succeed in another region.
+// Request problems (bad input, auth, context length) fail the same way
+// everywhere, and canceled calls must not be retried.
+func isRegionalFailure(ctx context.Context, err error) bool {
+	if ctx.Err() != nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	switch llmerrors.KindOf(err) {
+	case llmerrors.KindAuth, llmerrors.KindInvalidRequest, llmerrors.KindContextTooLong, llmerrors.KindCanceled:
+		return false
+	}
+	return true
+}
+
+// initializeRegionRouter initializes one model per configured region and
+// wraps them in a RegionRouter. A region that fails to initialize is skipped
+// with a warning; an error is returned only if no region could be initialized.
+func initializeRegionRouter(config Config, routing RegionRoutingConfig) (llmtypes.Model, error) {
+	var regions []string
+	var models []llmtypes.Model
+	var errs []error
+	for _, region := range routing.Regions {
+		model, err := initializeRegionalModel(config, region)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("region %s: %w", region, err))
+			if config.Logger != nil {
+				config.Logger.Warn("Failed to initialize LLM region",
+					loggerv2.String("provider", string(config.Provider)),
+					loggerv2.String("region", region),
+					loggerv2.String("error", err.Error()))
+			}
+			continue
+		}
+		regions = append(regions, region)
+		models = append(models, model)
+	}
+	if len(models) == 0 {
+		return nil, fmt.Errorf("failed to initialize any region for %s: %w", config.Provider, errors.Join(errs...))
+	}
+	return newRegionRouter(config.ModelID, regions, models, routing, config.Logger), nil
+}
+
+// initializeRegionalModel initializes the configured model pinned to region
+func initializeRegionalModel(config Config, region string) (llmtypes.Model, error) {
+	switch config.Provider {
+	case ProviderBedrock:
+		regional := config
+		regional.APIKeys = config.APIKeys.Clone()
+		if regional.APIKeys == nil {
+			regional.APIKeys = &ProviderAPIKeys{}
+		}
+		regional.APIKeys.Bedrock = &BedrockConfig{Region: region}
+		return llmInitializer(convertConfig(regional))
+	case ProviderVertex:
+		if !isVertexAnthropicModel(config.ModelID) {
+			return nil, fmt.Errorf("region routing for Vertex requires an Anthropic (claude-*) model, got %q", config.ModelID)
+		}
+		projectID := os.Getenv("VERTEX_PROJECT_ID")
+		if projectID == "" {
+			return nil, fmt.Errorf("VERTEX_PROJECT_ID environment variable is required for Vertex region routing")
+		}
+		return vertexadapter.NewVertexAnthropicAdapter(projectID, region, config.ModelID, NewLoggerAdapter(config.Logger)), nil
+	default:
+		return nil, fmt.Errorf("region routing is not supported for provider %s", config.Provider)
+	}
+}
+
+func isVertexAnthropicModel(modelID string) bool {
+	return strings.HasPrefix(modelID, "claude-")
+}
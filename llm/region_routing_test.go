@@ -0,0 +1,109 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/manishiitg/multi-llm-provider-go/llmerrors"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+type fakeRegionModel struct {
+	region string
+	err    error
+	calls  int
+}
+
+func (m *fakeRegionModel) GenerateContent(context.Context, []llmtypes.MessageContent, ...llmtypes.CallOption) (*llmtypes.ContentResponse, error) {
+	m.calls++
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &llmtypes.ContentResponse{Choices: []*llmtypes.ContentChoice{{Content: m.region}}}, nil
+}
+
+func (m *fakeRegionModel) GetModelID() string { return "model" }
+
+func (m *fakeRegionModel) GetModelMetadata(string) (*llmtypes.ModelMetadata, error) {
+	return nil, errors.New("not available")
+}
+
+func newTestRouter(models ...*fakeRegionModel) (*RegionRouter, *time.Time) {
+	regions := make([]string, len(models))
+	llms := make([]llmtypes.Model, len(models))
+	for i, m := range models {
+		regions[i] = m.region
+		llms[i] = m
+	}
+	now := time.Unix(0, 0)
+	router := newRegionRouter("model", regions, llms, RegionRoutingConfig{UnhealthyCooldown: time.Minute}, nil)
+	router.now = func() time.Time { return now }
+	return router, &now
+}
+
+func TestRegionRouterFailsOverAndCoolsDown(t *testing.T) {
+	east := &fakeRegionModel{region: "us-east-1", err: &llmerrors.Error{Kind: llmerrors.KindServerError, Err: errors.New("503")}}
+	west := &fakeRegionModel{region: "us-west-2"}
+	router, now := newTestRouter(east, west)
+
+	resp, err := router.GenerateContent(context.Background(), nil)
+	if err != nil || resp.Choices[0].Content != "us-west-2" {
+		t.Fatalf("expected failover to us-west-2, got %v, %v", resp, err)
+	}
+
+	// us-east-1 is skipped during its cooldown
+	if _, err := router.GenerateContent(context.Background(), nil); err != nil {
+		t.Fatal(err)
+	}
+	if east.calls != 1 {
+		t.Errorf("unhealthy region called %d times during cooldown, want 1", east.calls)
+	}
+
+	// After the cooldown it is tried again
+	east.err = nil
+	*now = now.Add(2 * time.Minute)
+	router.endpoints[1].latency = time.Second
+	resp, _ = router.GenerateContent(context.Background(), nil)
+	if resp.Choices[0].Content != "us-east-1" || !router.Health()[0].Healthy {
+		t.Errorf("expected recovered us-east-1 to serve the call, got %s", resp.Choices[0].Content)
+	}
+}
+
+func TestRegionRouterPrefersLowestLatency(t *testing.T) {
+	east := &fakeRegionModel{region: "us-east-1"}
+	west := &fakeRegionModel{region: "us-west-2"}
+	router, _ := newTestRouter(east, west)
+	router.endpoints[0].latency = 900 * time.Millisecond
+	router.endpoints[1].latency = 200 * time.Millisecond
+
+	resp, err := router.GenerateContent(context.Background(), nil)
+	if err != nil || resp.Choices[0].Content != "us-west-2" {
+		t.Fatalf("expected lowest-latency region, got %v, %v", resp, err)
+	}
+}
+
+func TestRegionRouterDoesNotFailOverRequestErrors(t *testing.T) {
+	east := &fakeRegionModel{region: "us-east-1", err: &llmerrors.Error{Kind: llmerrors.KindContextTooLong, Err: errors.New("too long")}}
+	west := &fakeRegionModel{region: "us-west-2"}
+	router, _ := newTestRouter(east, west)
+
+	if _, err := router.GenerateContent(context.Background(), nil); llmerrors.KindOf(err) != llmerrors.KindContextTooLong {
+		t.Fatalf("expected context-too-long error, got %v", err)
+	}
+	if west.calls != 0 || !router.Health()[0].Healthy {
+		t.Error("request errors must not fail over or mark the region unhealthy")
+	}
+}
+
+func TestRegionRouterReturnsErrorWhenAllRegionsFail(t *testing.T) {
+	throttled := &llmerrors.Error{Kind: llmerrors.KindRateLimit, Err: errors.New("429")}
+	router, _ := newTestRouter(
+		&fakeRegionModel{region: "us-east-1", err: throttled},
+		&fakeRegionModel{region: "us-west-2", err: throttled},
+	)
+	if _, err := router.GenerateContent(context.Background(), nil); llmerrors.KindOf(err) != llmerrors.KindRateLimit {
+		t.Fatalf("expected rate limit error for cross-provider fallback, got %v", err)
+	}
+}
@@ -1308,6 +1308,9 @@ func cacheFreshConnectionData(
 				IsValid:       true,
 				ToolOwnership: toolOwnership, // Add ownership tracking
 			}
+			if client := result.Clients[srvName]; client != nil {
+				entry.Instructions = mcpclient.Instructions(client)
+			}
 
 			// Store in cache using configuration-aware cache key
 			logger.Debug("Calling cacheManager.Put", loggerv2.String("server", srvName), loggerv2.Int("tools_count", len(serverTools)))
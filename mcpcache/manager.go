@@ -32,6 +32,7 @@ type CacheEntry struct {
 	Prompts      []mcp.Prompt    `json:"prompts"`
 	Resources    []mcp.Resource  `json:"resources"`
 	SystemPrompt string          `json:"system_prompt"`
+	Instructions string          `json:"instructions,omitempty"` // Server usage instructions from MCP initialize
 
 	// Metadata
 	CreatedAt  time.Time              `json:"created_at"`
@@ -169,23 +169,35 @@ func buildBaseSpec(title, description, baseURL string) map[string]interface{} {
 // Format is a simple text listing of endpoints with inlined parameter types,
 // roughly 70-80% fewer tokens than OpenAPI YAML.
 func GenerateCompactSpec(serverName string, tools []llmtypes.Tool, baseURL string) string {
-	sanitized := SanitizePathSegment(serverName)
 	var sb strings.Builder
 
-	sb.WriteString(fmt.Sprintf("base: %s\n", baseURL))
-	sb.WriteString("auth: Bearer $MCP_API_TOKEN\n\n")
+	sb.WriteString(CompactSpecHeader(baseURL))
 
 	for _, tool := range tools {
-		if tool.Function == nil {
-			continue
-		}
-		path := fmt.Sprintf("/tools/mcp/%s/%s", sanitized, SanitizePathSegment(tool.Function.Name))
-		writeCompactEntry(&sb, "POST", path, tool.Function.Description, tool.Function.Parameters)
+		sb.WriteString(CompactToolEntry(serverName, tool))
 	}
 
 	return sb.String()
 }
 
+// CompactSpecHeader returns the base URL and auth lines that start a compact spec.
+func CompactSpecHeader(baseURL string) string {
+	return fmt.Sprintf("base: %s\nauth: Bearer $MCP_API_TOKEN\n\n", baseURL)
+}
+
+// CompactToolEntry returns the compact spec entry of one MCP server tool, as
+// written by GenerateCompactSpec. Entries do not depend on the base URL, so
+// they can be rendered once and reused across sessions.
+func CompactToolEntry(serverName string, tool llmtypes.Tool) string {
+	if tool.Function == nil {
+		return ""
+	}
+	var sb strings.Builder
+	path := fmt.Sprintf("/tools/mcp/%s/%s", SanitizePathSegment(serverName), SanitizePathSegment(tool.Function.Name))
+	writeCompactEntry(&sb, "POST", path, tool.Function.Description, tool.Function.Parameters)
+	return sb.String()
+}
+
 // GenerateCustomToolsCompactSpec generates a minimal spec for custom tools.
 func GenerateCustomToolsCompactSpec(category string, tools map[string]CustomToolForOpenAPI, baseURL string) string {
 	var sb strings.Builder
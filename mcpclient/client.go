@@ -44,6 +44,7 @@ type Client struct {
 	config        MCPServerConfig
 	mcpClient     *client.Client
 	serverInfo    *mcp.Implementation
+	instructions  string // Usage instructions from the server's initialize result
 	retryConfig   RetryConfig
 	logger        loggerv2.Logger
 	contextCancel context.CancelFunc // Store context cancel function for SSE connections
@@ -53,6 +54,33 @@ type Client struct {
 	reconnectMu   sync.Mutex         // Serializes mid-session reconnects (resilience.go)
 	connGen       atomic.Int64       // Connection generation; bumped on each successful connect
 	leakGuard     *runtime.Cleanup   // GC guard that reaps unclosed connections (resilience.go)
+
+	// Progress notifications of in-flight tool calls (tool_progress.go)
+	progressMu         sync.Mutex
+	progressCollectors map[string]*ToolProgressCollector // Keyed by progress token
+	progressSeq        atomic.Uint64
+
+	// Sampling handlers of in-flight tool calls (sampling.go)
+	sampling samplingRegistry
+
+	// Remote connection upkeep (remote_connection.go)
+	headers           *remoteHeaders        // Headers of SSE/HTTP requests, including the bearer token
+	httpSessionID     string                // Streamable HTTP session to resume on reconnect
+	httpSessionKeeper *keepSessionTransport // Transport of the current streamable HTTP connection
+	sessionResumed    bool                  // The last connect resumed httpSessionID
+	keepAliveStop     chan struct{}         // Stops the keep-alive goroutine; guarded by mu
+	recovering        atomic.Bool           // A background recovery is running
+	closed            atomic.Bool           // Close was called
+	reconnectFailures int                   // Failed reconnects since the connection was lost; guarded by reconnectMu
+	lostAt            time.Time             // When the connection was found dead; guarded by reconnectMu
+	lossCause         error                 // Why; guarded by reconnectMu
+	stateMu           sync.Mutex
+	stateListeners    map[uint64]func(ConnectionStateChange)
+	stateListenerSeq  uint64
+
+	// Restarts of the server by the supervisor (supervisor.go)
+	restartMu sync.Mutex
+	restarts  []time.Time
 }
 
 // New creates a new MCP client for the given server configuration
@@ -75,6 +103,12 @@ func NewWithRetryConfig(config MCPServerConfig, retryConfig RetryConfig, logger
 
 // Connect establishes a connection to the MCP server with retry logic
 func (c *Client) Connect(ctx context.Context) error {
+	c.closed.Store(false)
+	return c.connect(ctx)
+}
+
+// connect is Connect without reopening a closed client (see reconnectIfStale)
+func (c *Client) connect(ctx context.Context) error {
 	maxRetries := 3
 	baseDelay := time.Second
 
@@ -133,8 +167,12 @@ func (c *Client) connectOnce(ctx context.Context) error {
 	// Close existing client before reconnect to prevent subprocess leaks
 	if c.mcpClient != nil {
 		c.logger.Debug("Closing existing mcpClient before reconnect")
-		_ = c.mcpClient.Close()
-		c.mcpClient = nil
+		previous := c.mcpClient
+		c.mcpClient = nil // Before Close, so its dropped SSE stream is not taken for a lost connection
+		if c.httpSessionKeeper != nil && c.httpSessionID != "" {
+			c.httpSessionKeeper.keep.Store(true) // Keep the session to resume it
+		}
+		_ = previous.Close()
 	}
 
 	// Prepare environment variables
@@ -162,6 +200,11 @@ func (c *Client) connectOnce(ctx context.Context) error {
 		env = append(env, fmt.Sprintf("%s=%s", key, value))
 	}
 
+	// Bearer token from the environment, re-read on every (re)connect
+	if err := c.loadBearerToken(); err != nil {
+		return err
+	}
+
 	// Handle OAuth authentication if configured
 	if c.config.OAuth != nil {
 		if err := c.setupOAuthAuth(ctx); err != nil {
@@ -171,22 +214,25 @@ func (c *Client) connectOnce(ctx context.Context) error {
 
 	var mcpClient *client.Client
 	var err error
+	resumed := false
 
 	// Create MCP client based on protocol type (use smart detection)
 	protocol := c.config.GetProtocol()
 	switch protocol {
 	case ProtocolSSE:
 		// Use SSE transport
-		sseManager := NewSSEManager(c.config.URL, c.config.Headers, c.logger)
-		mcpClient, err = sseManager.Connect(ctx)
+		if c.config.Sampling {
+			c.logger.Warn("Sampling is not supported over SSE, the server will not be offered it",
+				loggerv2.String("server", c.getServerName()))
+		}
+		mcpClient, err = c.connectSSE(ctx)
 		if err != nil {
 			return fmt.Errorf("failed to create SSE MCP client: %w", err)
 		}
 
 	case ProtocolHTTP:
 		// Use HTTP transport
-		httpManager := NewHTTPManager(c.config.URL, c.config.Headers, c.logger)
-		mcpClient, err = httpManager.Connect(ctx)
+		mcpClient, resumed, err = c.connectHTTP(ctx)
 		if err != nil {
 			return fmt.Errorf("failed to create HTTP MCP client: %w", err)
 		}
@@ -196,17 +242,27 @@ func (c *Client) connectOnce(ctx context.Context) error {
 	default:
 		// Default to stdio for backward compatibility
 		stdioManager := NewStdioManager(c.config.Command, c.config.Args, env, c.config.WorkingDir, c.logger)
+		stdioManager.clientOptions = c.clientOptions()
+		var supervise func(*client.Client)
+		stdioManager.onExit, supervise = c.connectionLostHandler("MCP server process exited")
 		mcpClient, err = stdioManager.Connect(ctx)
 		if err != nil {
 			return fmt.Errorf("failed to create MCP client: %w", err)
 		}
+		supervise(mcpClient)
+		c.instructions = stdioManager.Instructions()
 	}
 
 	c.mcpClient = mcpClient
+	c.mcpClient.OnNotification(c.handleNotification)
 
 	// For stdio clients, initialization is handled by the transport manager
-	// For other protocols, we need to initialize here
-	if protocol != ProtocolStdio {
+	// For other protocols, we need to initialize here (unless a streamable
+	// HTTP session was resumed, which keeps the server info it had)
+	if resumed {
+		c.logger.Info("Resumed MCP session",
+			loggerv2.String("server", c.getServerName()))
+	} else if protocol != ProtocolStdio {
 		// Initialize connection
 		initResult, err := c.mcpClient.Initialize(ctx, mcp.InitializeRequest{
 			Params: mcp.InitializeParams{
@@ -224,6 +280,7 @@ func (c *Client) connectOnce(ctx context.Context) error {
 		}
 
 		c.serverInfo = &initResult.ServerInfo
+		c.instructions = initResult.Instructions
 	} else {
 		// For stdio, we need to get server info separately since initialization was already done
 		// We'll get this from the first tool listing or other operation
@@ -233,10 +290,18 @@ func (c *Client) connectOnce(ctx context.Context) error {
 		}
 	}
 
-	c.connGen.Add(1)
+	if protocol == ProtocolHTTP {
+		c.httpSessionID = httpSessionIDOf(c.mcpClient)
+	}
+	c.sessionResumed = resumed
+
+	if c.connGen.Add(1) == 1 {
+		c.notifyState(ConnectionStateChange{State: ConnectionStateConnected})
+	}
 	c.mu.Lock()
 	c.armLeakGuardLocked()
 	c.mu.Unlock()
+	c.startKeepAlive()
 
 	return nil
 }
@@ -331,10 +396,12 @@ func (c *Client) Close() error {
 	}
 
 	// Clear the stored context and cancel function; explicit Close means no leak.
+	c.closed.Store(true)
 	c.mu.Lock()
 	c.context = nil
 	c.contextCancel = nil
 	c.disarmLeakGuardLocked()
+	c.stopKeepAliveLocked()
 	c.mu.Unlock()
 
 	if c.mcpClient != nil {
@@ -356,6 +423,12 @@ func (c *Client) GetServerInfo() *mcp.Implementation {
 	return c.serverInfo
 }
 
+// GetInstructions returns the usage instructions the server sent in its
+// initialize result, or "" if it sent none
+func (c *Client) GetInstructions() string {
+	return c.instructions
+}
+
 // GetMCPClient returns the underlying MCP client (for pooled client usage)
 func (c *Client) GetMCPClient() *client.Client {
 	return c.mcpClient
@@ -418,6 +491,8 @@ func (c *Client) CallTool(ctx context.Context, name string, arguments map[string
 			Arguments: arguments,
 		},
 	}
+	defer c.registerProgress(ctx, &request)()
+	defer c.registerSampling(ctx)()
 
 	observedGen := c.connGeneration()
 	result, err := c.mcpClient.CallTool(ctx, request)
@@ -425,10 +500,10 @@ func (c *Client) CallTool(ctx context.Context, name string, arguments map[string
 		return result, nil
 	}
 
-	if !shouldReconnectAfterError(ctx, err) {
+	if !c.shouldReconnect(ctx, err) {
 		return nil, fmt.Errorf("failed to call tool %s: %w", name, err)
 	}
-	if reconnectErr := c.reconnectIfStale(ctx, observedGen); reconnectErr != nil {
+	if reconnectErr := c.reconnectIfStale(ctx, observedGen, err); reconnectErr != nil {
 		return nil, fmt.Errorf("failed to call tool %s: %w (reconnect also failed: %w)", name, err, reconnectErr)
 	}
 
@@ -484,6 +559,20 @@ func (c *Client) ListResources(ctx context.Context) ([]mcp.Resource, error) {
 	return result.Resources, nil
 }
 
+// ListResourceTemplates lists the resource templates of the server
+func (c *Client) ListResourceTemplates(ctx context.Context) ([]mcp.ResourceTemplate, error) {
+	if c.mcpClient == nil {
+		return nil, fmt.Errorf("client not connected")
+	}
+
+	result, err := c.mcpClient.ListResourceTemplates(ctx, mcp.ListResourceTemplatesRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list resource templates: %w", err)
+	}
+
+	return result.ResourceTemplates, nil
+}
+
 // GetResource gets a specific resource by URI
 func (c *Client) GetResource(ctx context.Context, uri string) (*mcp.ReadResourceResult, error) {
 	if c.mcpClient == nil {
@@ -518,6 +607,11 @@ func (c *Client) ListPrompts(ctx context.Context) ([]mcp.Prompt, error) {
 
 // GetPrompt gets a specific prompt by name
 func (c *Client) GetPrompt(ctx context.Context, name string) (*mcp.GetPromptResult, error) {
+	return c.GetPromptWithArguments(ctx, name, nil)
+}
+
+// GetPromptWithArguments renders a prompt template with the given argument values
+func (c *Client) GetPromptWithArguments(ctx context.Context, name string, arguments map[string]string) (*mcp.GetPromptResult, error) {
 	if c.mcpClient == nil {
 		return nil, fmt.Errorf("client not connected")
 	}
@@ -525,7 +619,8 @@ func (c *Client) GetPrompt(ctx context.Context, name string) (*mcp.GetPromptResu
 	// Create the MCP request
 	request := mcp.GetPromptRequest{
 		Params: mcp.GetPromptParams{
-			Name: name,
+			Name:      name,
+			Arguments: arguments,
 		},
 	}
 
@@ -935,11 +1030,9 @@ func (c *Client) setupOAuthAuth(ctx context.Context) error {
 		return fmt.Errorf("%w\nRun authentication flow to obtain token", err)
 	}
 
-	// Inject Bearer token into headers
-	if c.config.Headers == nil {
-		c.config.Headers = make(map[string]string)
-	}
-	c.config.Headers["Authorization"] = fmt.Sprintf("Bearer %s", token)
+	// Inject Bearer token into request headers; the config's Headers map may
+	// be shared with other clients, so it is not modified
+	c.setBearerToken(token)
 
 	c.logger.Debug("OAuth token injected into request headers")
 	return nil
@@ -57,3 +57,37 @@ type ClientInterface interface {
 	// GetContext retrieves the stored context
 	GetContext() context.Context
 }
+
+// The interfaces below are optional capabilities of a ClientInterface. They
+// are kept out of ClientInterface so existing implementations keep compiling;
+// callers check for them with a type assertion. Client implements all of them.
+
+// InstructionsProvider is implemented by clients that keep the usage
+// instructions of the server's initialize result
+type InstructionsProvider interface {
+	// GetInstructions returns the server's usage instructions from initialize
+	GetInstructions() string
+}
+
+// ResourceTemplateLister is implemented by clients that can list the
+// server's resource templates
+type ResourceTemplateLister interface {
+	// ListResourceTemplates lists all available resource templates
+	ListResourceTemplates(ctx context.Context) ([]mcp.ResourceTemplate, error)
+}
+
+// PromptRenderer is implemented by clients that can render a prompt
+// template with argument values
+type PromptRenderer interface {
+	// GetPromptWithArguments renders a prompt template with the given argument values
+	GetPromptWithArguments(ctx context.Context, name string, arguments map[string]string) (*mcp.GetPromptResult, error)
+}
+
+// Instructions returns the usage instructions of client's server, or "" when
+// the client does not implement InstructionsProvider
+func Instructions(client ClientInterface) string {
+	if provider, ok := client.(InstructionsProvider); ok {
+		return provider.GetInstructions()
+	}
+	return ""
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"sort"
 	"strings"
 	"time"
 
@@ -68,6 +69,13 @@ type ServerConfig struct {
 	// SSE/HTTP specific fields
 	URL     string            `json:"url,omitempty"`
 	Headers map[string]string `json:"headers,omitempty"`
+	// BearerTokenEnv names an environment variable holding a bearer token for
+	// the Authorization header; re-read on every reconnect so rotated tokens
+	// are picked up (OAuth takes precedence)
+	BearerTokenEnv string `json:"bearer_token_env,omitempty"`
+	// KeepAlive is the interval of the pings that detect a dropped SSE/HTTP
+	// connection, as a Go duration (default 30s); "0" disables them
+	KeepAlive string `json:"keep_alive,omitempty"`
 }
 
 // NewServerConfig creates a new server configuration with defaults
@@ -92,8 +100,32 @@ type MCPServerConfig struct {
 	// SSE/HTTP specific fields
 	URL     string            `json:"url,omitempty"`
 	Headers map[string]string `json:"headers,omitempty"`
+	// BearerTokenEnv names an environment variable holding a bearer token for
+	// the Authorization header; re-read on every reconnect so rotated tokens
+	// are picked up (OAuth takes precedence)
+	BearerTokenEnv string `json:"bearer_token_env,omitempty"`
+	// KeepAlive is the interval of the pings that detect a dropped SSE/HTTP
+	// connection, as a Go duration (default 30s); "0" disables them
+	KeepAlive string `json:"keep_alive,omitempty"`
 	// OAuth configuration
 	OAuth *oauth.OAuthConfig `json:"oauth,omitempty"`
+	// Sampling advertises the sampling capability, so the server can request
+	// LLM completions while an agent's tool call runs (stdio and HTTP only)
+	Sampling bool `json:"sampling,omitempty"`
+	// ToolTimeout overrides the agent's tool timeout for this server's tools,
+	// as a Go duration such as "90s" or "10m"; "0" disables the timeout
+	ToolTimeout string `json:"tool_timeout,omitempty"`
+	// ToolTimeouts overrides ToolTimeout for individual tools, by tool name
+	ToolTimeouts map[string]string `json:"tool_timeouts,omitempty"`
+	// ReadOnlyTools and WriteTools classify this server's tools as reading or
+	// changing state, overriding the agent's name-based guess (dry-run mode
+	// only runs read tools)
+	ReadOnlyTools []string `json:"read_only_tools,omitempty"`
+	WriteTools    []string `json:"write_tools,omitempty"`
+	// Filesystem marks a filesystem server whose tools the agent's folder
+	// guard checks; servers run from @modelcontextprotocol/server-filesystem
+	// are recognized without it
+	Filesystem bool `json:"filesystem,omitempty"`
 }
 
 // RuntimeConfigOverride allows runtime modification of MCP server configuration
@@ -175,6 +207,96 @@ func (c *MCPServerConfig) GetPoolConfig() PoolConfig {
 	return DefaultPoolConfig()
 }
 
+// GetToolTimeout returns the timeout configured for toolName, from
+// ToolTimeouts or else ToolTimeout, and whether one is configured
+func (c *MCPServerConfig) GetToolTimeout(toolName string) (time.Duration, bool) {
+	value, ok := c.ToolTimeouts[toolName]
+	if !ok {
+		value = c.ToolTimeout
+	}
+	if value == "" {
+		return 0, false
+	}
+	timeout, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, false
+	}
+	return timeout, true
+}
+
+// GetToolReadOnly reports whether toolName is listed in ReadOnlyTools (true)
+// or WriteTools (false), and whether it is listed at all
+func (c *MCPServerConfig) GetToolReadOnly(toolName string) (readOnly, ok bool) {
+	for _, name := range c.WriteTools {
+		if name == toolName {
+			return false, true
+		}
+	}
+	for _, name := range c.ReadOnlyTools {
+		if name == toolName {
+			return true, true
+		}
+	}
+	return false, false
+}
+
+// IsFilesystemServer reports whether the server is a filesystem server: set
+// Filesystem, or run from the server-filesystem package
+func (c *MCPServerConfig) IsFilesystemServer() bool {
+	if c.Filesystem || strings.Contains(c.Command, "server-filesystem") {
+		return true
+	}
+	for _, arg := range c.Args {
+		if strings.Contains(arg, "server-filesystem") {
+			return true
+		}
+	}
+	return false
+}
+
+// validateToolTimeouts checks that the configured tool timeouts are durations
+func (c *MCPServerConfig) validateToolTimeouts() error {
+	if c.ToolTimeout != "" {
+		if _, err := time.ParseDuration(c.ToolTimeout); err != nil {
+			return fmt.Errorf("tool_timeout: %w", err)
+		}
+	}
+	for tool, value := range c.ToolTimeouts {
+		if _, err := time.ParseDuration(value); err != nil {
+			return fmt.Errorf("tool_timeouts[%s]: %w", tool, err)
+		}
+	}
+	return nil
+}
+
+// validateKeepAlive checks that keep_alive is a non-negative duration
+func (c *MCPServerConfig) validateKeepAlive() error {
+	if c.KeepAlive == "" {
+		return nil
+	}
+	interval, err := time.ParseDuration(c.KeepAlive)
+	if err != nil {
+		return fmt.Errorf("keep_alive: %w", err)
+	}
+	if interval < 0 {
+		return fmt.Errorf("keep_alive: must not be negative")
+	}
+	return nil
+}
+
+// GetKeepAliveInterval returns the keep-alive ping interval of a remote
+// server: KeepAlive, DefaultKeepAliveInterval when unset, 0 when disabled
+func (c *MCPServerConfig) GetKeepAliveInterval() time.Duration {
+	if c.KeepAlive == "" {
+		return DefaultKeepAliveInterval
+	}
+	interval, err := time.ParseDuration(c.KeepAlive)
+	if err != nil || interval < 0 {
+		return DefaultKeepAliveInterval
+	}
+	return interval
+}
+
 // GetProtocol returns the protocol type with smart detection
 func (c *MCPServerConfig) GetProtocol() ProtocolType {
 	// If protocol is explicitly set, use it
@@ -268,6 +390,15 @@ func LoadConfig(configPath string, logger loggerv2.Logger) (*MCPConfig, error) {
 			loggerv2.Any("duration", unmarshalDuration))
 	}
 
+	for name, server := range config.MCPServers {
+		if err := server.validateToolTimeouts(); err != nil {
+			return nil, fmt.Errorf("invalid config file %s: server %s: %w", configPath, name, err)
+		}
+		if err := server.validateKeepAlive(); err != nil {
+			return nil, fmt.Errorf("invalid config file %s: server %s: %w", configPath, name, err)
+		}
+	}
+
 	return &config, nil
 }
 
@@ -397,6 +528,8 @@ func (c *MCPConfig) ListServers() []string {
 	for name := range c.MCPServers {
 		names = append(names, name)
 	}
+	// Sorted so tool lists built from all servers are ordered deterministically
+	sort.Strings(names)
 	return names
 }
 
@@ -0,0 +1,45 @@
+package mcpclient
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGetToolTimeout(t *testing.T) {
+	config := MCPServerConfig{ToolTimeout: "2m", ToolTimeouts: map[string]string{"crawl_site": "15m", "ping": "0"}}
+	for tool, want := range map[string]time.Duration{"crawl_site": 15 * time.Minute, "ping": 0, "search": 2 * time.Minute} {
+		if got, ok := config.GetToolTimeout(tool); !ok || got != want {
+			t.Errorf("GetToolTimeout(%q) = %s, %v, want %s", tool, got, ok, want)
+		}
+	}
+	if _, ok := (&MCPServerConfig{}).GetToolTimeout("search"); ok {
+		t.Error("a server without tool timeouts must not report one")
+	}
+}
+
+func TestLoadConfigRejectsInvalidToolTimeout(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mcp_servers.json")
+	data := `{"mcpServers": {"crawler": {"command": "crawler-mcp", "tool_timeouts": {"crawl_site": "15 minutes"}}}}`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	_, err := LoadConfig(path, nil)
+	if err == nil || !strings.Contains(err.Error(), "tool_timeouts[crawl_site]") {
+		t.Fatalf("LoadConfig() error = %v, want an invalid tool_timeouts error", err)
+	}
+}
+
+func TestGetKeepAliveInterval(t *testing.T) {
+	for value, want := range map[string]time.Duration{"": DefaultKeepAliveInterval, "10s": 10 * time.Second, "0": 0} {
+		config := MCPServerConfig{KeepAlive: value}
+		if got := config.GetKeepAliveInterval(); got != want {
+			t.Errorf("GetKeepAliveInterval() with keep_alive %q = %s, want %s", value, got, want)
+		}
+	}
+	if err := (&MCPServerConfig{KeepAlive: "-5s"}).validateKeepAlive(); err == nil {
+		t.Error("a negative keep_alive must be rejected")
+	}
+}
@@ -3,6 +3,7 @@ package mcpclient
 import (
 	"context"
 	"fmt"
+	"net/http"
 
 	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
 
@@ -15,6 +16,11 @@ type HTTPManager struct {
 	url     string
 	headers map[string]string
 	logger  loggerv2.Logger
+
+	clientOptions []client.ClientOption    // e.g. the sampling handler (sampling.go)
+	headerFunc    transport.HTTPHeaderFunc // Headers computed per request, e.g. a refreshed bearer token
+	sessionID     string                   // Session to resume instead of initializing a new one (remote_connection.go)
+	httpClient    *http.Client             // HTTP client of the transport; the transport's default when nil
 }
 
 // NewHTTPManager creates a new HTTP manager
@@ -35,6 +41,19 @@ func (h *HTTPManager) CreateClient() (*client.Client, error) {
 	if len(h.headers) > 0 {
 		options = append(options, transport.WithHTTPHeaders(h.headers))
 	}
+	if h.headerFunc != nil {
+		options = append(options, transport.WithHTTPHeaderFunc(h.headerFunc))
+	}
+	if h.httpClient != nil {
+		options = append(options, transport.WithHTTPBasicClient(h.httpClient))
+	}
+
+	clientOptions := h.clientOptions
+	if h.sessionID != "" {
+		// The server already initialized this session; skip Initialize
+		options = append(options, transport.WithSession(h.sessionID))
+		clientOptions = append(append([]client.ClientOption(nil), clientOptions...), client.WithSession())
+	}
 
 	// Create StreamableHTTP transport
 	httpTransport, err := transport.NewStreamableHTTP(h.url, options...)
@@ -43,7 +62,7 @@ func (h *HTTPManager) CreateClient() (*client.Client, error) {
 	}
 
 	// Create client with transport
-	return client.NewClient(httpTransport), nil
+	return client.NewClient(httpTransport, clientOptions...), nil
 }
 
 // Connect creates and starts an HTTP client
@@ -0,0 +1,27 @@
+package mcpclient
+
+import (
+	"context"
+	"testing"
+
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func TestConnectCapturesServerInstructions(t *testing.T) {
+	mcpServer := server.NewMCPServer("browser", "1.0.0",
+		server.WithInstructions("Take a snapshot before clicking elements."))
+	httpServer := server.NewTestStreamableHTTPServer(mcpServer)
+	defer httpServer.Close()
+
+	c := New(MCPServerConfig{URL: httpServer.URL + "/mcp", Protocol: ProtocolHTTP}, loggerv2.NewNoop())
+	if err := c.Connect(context.Background()); err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	defer func() { _ = c.Close() }()
+
+	if got := c.GetInstructions(); got != "Take a snapshot before clicking elements." {
+		t.Errorf("unexpected instructions %q", got)
+	}
+}
@@ -0,0 +1,342 @@
+package mcpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sync/atomic"
+	"time"
+	"weak"
+
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/client/transport"
+)
+
+// Upkeep of remote (SSE and streamable HTTP) connections.
+//
+// A long-lived agent outlives restarts of remote MCP servers. While a remote
+// client is open, a keep-alive goroutine pings the server (every keep_alive,
+// default 30s) and the SSE transport reports a dropped stream; either starts
+// a background recovery that reconnects with exponential backoff until it
+// succeeds or the client is closed. A streamable HTTP reconnect first tries
+// to resume the previous session (Mcp-Session-Id) and starts a new one when
+// the server no longer knows it. The Authorization header is computed per
+// request, so a token refreshed on reconnect (OAuth, bearer_token_env) is
+// picked up without rebuilding the transport.
+//
+// Transport callbacks and the keep-alive goroutine hold the Client through a
+// weak pointer so the leak guard (resilience.go) still reaps unclosed clients.
+
+// DefaultKeepAliveInterval is the ping interval of remote connections when
+// keep_alive is not configured
+const DefaultKeepAliveInterval = 30 * time.Second
+
+// ConnectionState is the state of a client's connection to its MCP server
+type ConnectionState string
+
+const (
+	ConnectionStateConnected       ConnectionState = "connected"        // First connection established
+	ConnectionStateDisconnected    ConnectionState = "disconnected"     // Connection found dead mid-session
+	ConnectionStateReconnecting    ConnectionState = "reconnecting"     // Reconnect attempt started
+	ConnectionStateReconnected     ConnectionState = "reconnected"      // Connection to a remote server restored
+	ConnectionStateRestarted       ConnectionState = "restarted"        // Stdio server process respawned and initialized (supervisor.go)
+	ConnectionStateReconnectFailed ConnectionState = "reconnect_failed" // Reconnect attempt failed
+)
+
+// ConnectionStateChange reports a change of a client's connection state
+type ConnectionStateChange struct {
+	Server         string // Server name (see MCPServerConfig.Description)
+	State          ConnectionState
+	Attempt        int           // Reconnect attempt since the connection was lost, from 1
+	SessionResumed bool          // Reconnected to the previous streamable HTTP session
+	Err            error         // Why the connection was lost (also on reconnected/restarted) or the attempt failed
+	Downtime       time.Duration // Since the connection was lost; on reconnected/restarted
+	Time           time.Time
+}
+
+// AddConnectionStateListener calls listener on every connection state change
+// of c until the returned func is called. Listeners run on the goroutine that
+// changed the state and must not block.
+func (c *Client) AddConnectionStateListener(listener func(ConnectionStateChange)) (remove func()) {
+	c.stateMu.Lock()
+	defer c.stateMu.Unlock()
+	if c.stateListeners == nil {
+		c.stateListeners = make(map[uint64]func(ConnectionStateChange))
+	}
+	c.stateListenerSeq++
+	id := c.stateListenerSeq
+	c.stateListeners[id] = listener
+	return func() {
+		c.stateMu.Lock()
+		defer c.stateMu.Unlock()
+		delete(c.stateListeners, id)
+	}
+}
+
+// notifyState reports a connection state change to the listeners
+func (c *Client) notifyState(change ConnectionStateChange) {
+	change.Server = c.getServerName()
+	change.Time = time.Now()
+	c.stateMu.Lock()
+	listeners := make([]func(ConnectionStateChange), 0, len(c.stateListeners))
+	for _, listener := range c.stateListeners {
+		listeners = append(listeners, listener)
+	}
+	c.stateMu.Unlock()
+	for _, listener := range listeners {
+		listener(change)
+	}
+}
+
+// remoteHeaders holds the headers of every request to a remote server: the
+// configured headers plus the current bearer token. It is shared with the
+// transport's header func instead of the Client (see the note above).
+type remoteHeaders struct {
+	static map[string]string
+	token  atomic.Value // string
+}
+
+func newRemoteHeaders(static map[string]string) *remoteHeaders {
+	h := &remoteHeaders{static: make(map[string]string, len(static))}
+	for key, value := range static {
+		h.static[key] = value
+	}
+	return h
+}
+
+// get implements transport.HTTPHeaderFunc
+func (h *remoteHeaders) get(context.Context) map[string]string {
+	headers := make(map[string]string, len(h.static)+1)
+	for key, value := range h.static {
+		headers[key] = value
+	}
+	if token, _ := h.token.Load().(string); token != "" {
+		headers["Authorization"] = "Bearer " + token
+	}
+	return headers
+}
+
+// requestHeaders returns the headers of remote requests, creating them from
+// the configured headers on first use
+func (c *Client) requestHeaders() *remoteHeaders {
+	if c.headers == nil {
+		c.headers = newRemoteHeaders(c.config.Headers)
+	}
+	return c.headers
+}
+
+// setBearerToken sets the token of the Authorization header of remote requests
+func (c *Client) setBearerToken(token string) {
+	c.requestHeaders().token.Store(token)
+}
+
+// loadBearerToken reads bearer_token_env before each connect, so a token
+// rotated in the environment is used on the next reconnect. An OAuth token
+// (setupOAuthAuth) takes precedence.
+func (c *Client) loadBearerToken() error {
+	if c.config.BearerTokenEnv == "" || c.config.OAuth != nil {
+		return nil
+	}
+	token := os.Getenv(c.config.BearerTokenEnv)
+	if token == "" {
+		return fmt.Errorf("bearer_token_env %s is not set", c.config.BearerTokenEnv)
+	}
+	c.setBearerToken(token)
+	return nil
+}
+
+// isAuthExpiredError reports whether err is a 401 that a reconnect can fix by
+// loading a fresh token
+func (c *Client) isAuthExpiredError(err error) bool {
+	if c.config.OAuth == nil && c.config.BearerTokenEnv == "" {
+		return false
+	}
+	var oauthErr *transport.OAuthAuthorizationRequiredError
+	return errors.Is(err, transport.ErrUnauthorized) || errors.As(err, &oauthErr)
+}
+
+// shouldReconnect decides whether a failed call warrants a reconnect+retry:
+// a dead transport, or an expired token that a reconnect refreshes
+func (c *Client) shouldReconnect(ctx context.Context, err error) bool {
+	return shouldReconnectAfterError(ctx, err) || (ctx.Err() == nil && c.isAuthExpiredError(err))
+}
+
+// connectSSE connects over SSE. A dropped stream starts a background recovery.
+func (c *Client) connectSSE(ctx context.Context) (*client.Client, error) {
+	manager := NewSSEManager(c.config.URL, nil, c.logger)
+	manager.headerFunc = c.requestHeaders().get
+	var supervise func(*client.Client)
+	manager.onConnectionLost, supervise = c.connectionLostHandler("MCP SSE stream lost")
+
+	mcpClient, err := manager.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	supervise(mcpClient)
+	return mcpClient, nil
+}
+
+// connectHTTP connects over streamable HTTP, resuming the previous session
+// when the server still knows it
+func (c *Client) connectHTTP(ctx context.Context) (mcpClient *client.Client, resumed bool, err error) {
+	if c.httpSessionID != "" {
+		mcpClient, err = c.newHTTPManager(c.httpSessionID).Connect(ctx)
+		if err == nil {
+			pingCtx, cancel := context.WithTimeout(ctx, healthPingTimeout)
+			err = mcpClient.Ping(pingCtx)
+			cancel()
+			if err == nil {
+				return mcpClient, true, nil
+			}
+			_ = mcpClient.Close()
+		}
+		c.logger.Info("Previous MCP session cannot be resumed, starting a new one",
+			loggerv2.String("server", c.getServerName()),
+			loggerv2.Error(err))
+		c.httpSessionID = ""
+	}
+	mcpClient, err = c.newHTTPManager("").Connect(ctx)
+	return mcpClient, false, err
+}
+
+// newHTTPManager returns the manager of a streamable HTTP connection, resuming
+// sessionID unless empty
+func (c *Client) newHTTPManager(sessionID string) *HTTPManager {
+	manager := NewHTTPManager(c.config.URL, nil, c.logger)
+	manager.headerFunc = c.requestHeaders().get
+	manager.clientOptions = c.clientOptions()
+	manager.sessionID = sessionID
+	c.httpSessionKeeper = &keepSessionTransport{base: http.DefaultTransport}
+	manager.httpClient = &http.Client{Transport: c.httpSessionKeeper}
+	return manager
+}
+
+// keepSessionTransport lets a reconnect close the previous streamable HTTP
+// connection without ending its session: closing the transport sends a
+// DELETE for the session, which is dropped once keep is set
+type keepSessionTransport struct {
+	base http.RoundTripper
+	keep atomic.Bool
+}
+
+func (t *keepSessionTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method == http.MethodDelete && t.keep.Load() {
+		return &http.Response{StatusCode: http.StatusNoContent, Body: http.NoBody, Request: req}, nil
+	}
+	return t.base.RoundTrip(req)
+}
+
+// httpSessionIDOf returns the session ID the server assigned to a streamable
+// HTTP connection, or "" when it did not assign one
+func httpSessionIDOf(mcpClient *client.Client) string {
+	if httpTransport, ok := mcpClient.GetTransport().(*transport.StreamableHTTP); ok {
+		return httpTransport.GetSessionId()
+	}
+	return ""
+}
+
+// startKeepAlive starts pinging a remote server every keep-alive interval
+// until c is closed, starting a recovery when a ping fails. No-op when the
+// keep-alive already runs, is disabled or c is a stdio client.
+func (c *Client) startKeepAlive() {
+	interval := c.config.GetKeepAliveInterval()
+	protocol := c.config.GetProtocol()
+	if interval <= 0 || (protocol != ProtocolSSE && protocol != ProtocolHTTP) {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.keepAliveStop != nil {
+		return
+	}
+	stop := make(chan struct{})
+	c.keepAliveStop = stop
+
+	self := weak.Make(c)
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+			}
+			c := self.Value()
+			if c == nil {
+				return
+			}
+			c.keepAlivePing()
+		}
+	}()
+}
+
+// keepAlivePing pings the server once, starting a recovery when it fails
+func (c *Client) keepAlivePing() {
+	if c.recovering.Load() || c.closed.Load() {
+		return
+	}
+	gen := c.connGeneration()
+	ctx, cancel := context.WithTimeout(context.Background(), healthPingTimeout)
+	err := c.Ping(ctx)
+	cancel()
+	if err == nil || c.closed.Load() {
+		return
+	}
+	c.logger.Warn("MCP keep-alive ping failed",
+		loggerv2.String("server", c.getServerName()),
+		loggerv2.Error(err))
+	c.startRecovery(gen, err)
+}
+
+// stopKeepAliveLocked stops the keep-alive goroutine, if any. Caller must hold c.mu.
+func (c *Client) stopKeepAliveLocked() {
+	if c.keepAliveStop != nil {
+		close(c.keepAliveStop)
+		c.keepAliveStop = nil
+	}
+}
+
+// startRecovery reconnects in the background after the connection of
+// generation gen was lost, retrying with exponential backoff (RetryConfig)
+// until it succeeds, another caller reconnects or c is closed; a stdio server
+// is given RetryConfig.MaxRetries attempts. At most one recovery runs at a time.
+func (c *Client) startRecovery(gen int64, cause error) {
+	if !c.recovering.CompareAndSwap(false, true) {
+		return
+	}
+	c.mu.RLock()
+	stop := c.keepAliveStop // closed by Close; nil without keep-alive
+	c.mu.RUnlock()
+	go func() {
+		defer c.recovering.Store(false)
+		delay := c.retryConfig.InitialDelay
+		for attempt := 1; !c.closed.Load() && c.connGeneration() == gen; attempt++ {
+			if err := c.reconnectIfStale(context.Background(), gen, cause); err == nil {
+				return
+			}
+			// A remote server is waited for; a stdio server that cannot be
+			// respawned is left to the next tool call
+			if c.config.GetProtocol() == ProtocolStdio && attempt > c.retryConfig.MaxRetries {
+				c.logger.Error("Giving up restarting MCP server", fmt.Errorf("%d restart attempts failed", attempt),
+					loggerv2.String("server", c.getServerName()))
+				return
+			}
+			timer := time.NewTimer(delay)
+			select {
+			case <-timer.C:
+			case <-stop:
+				timer.Stop()
+				return
+			}
+			delay = time.Duration(float64(delay) * c.retryConfig.BackoffFactor)
+			if delay > c.retryConfig.MaxDelay {
+				delay = c.retryConfig.MaxDelay
+			}
+		}
+	}()
+}
@@ -0,0 +1,208 @@
+package mcpclient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// restartableMCPServer serves MCP over streamable HTTP; restart replaces the
+// server with a fresh one that knows none of the previous sessions
+type restartableMCPServer struct {
+	*httptest.Server
+	handler atomic.Pointer[http.Handler]
+
+	mu   sync.Mutex
+	auth []string // Authorization headers received
+}
+
+func newRestartableMCPServer(t *testing.T) *restartableMCPServer {
+	s := &restartableMCPServer{}
+	s.restart()
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.auth = append(s.auth, r.Header.Get("Authorization"))
+		s.mu.Unlock()
+		(*s.handler.Load()).ServeHTTP(w, r)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *restartableMCPServer) restart() {
+	mcpServer := server.NewMCPServer("remote", "1.0.0")
+	mcpServer.AddTool(mcp.NewTool("echo"), func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("ok"), nil
+	})
+	var handler http.Handler = server.NewStreamableHTTPServer(mcpServer,
+		server.WithSessionIdManager(&server.InsecureStatefulSessionIdManager{}))
+	s.handler.Store(&handler)
+}
+
+func (s *restartableMCPServer) lastAuth() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.auth[len(s.auth)-1]
+}
+
+func recordStates(c *Client) func() []ConnectionStateChange {
+	var mu sync.Mutex
+	var changes []ConnectionStateChange
+	c.AddConnectionStateListener(func(change ConnectionStateChange) {
+		mu.Lock()
+		defer mu.Unlock()
+		changes = append(changes, change)
+	})
+	return func() []ConnectionStateChange {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]ConnectionStateChange(nil), changes...)
+	}
+}
+
+func TestHTTPReconnectResumesSessionAndReloadsBearerToken(t *testing.T) {
+	remote := newRestartableMCPServer(t)
+	t.Setenv("REMOTE_MCP_TOKEN", "token-1")
+	headers := map[string]string{"X-Team": "research"}
+	c := New(MCPServerConfig{
+		Description:    "remote",
+		URL:            remote.URL + "/mcp",
+		Protocol:       ProtocolHTTP,
+		Headers:        headers,
+		BearerTokenEnv: "REMOTE_MCP_TOKEN",
+		KeepAlive:      "0",
+	}, loggerv2.NewNoop())
+	if err := c.Connect(context.Background()); err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	defer func() { _ = c.Close() }()
+	if got := remote.lastAuth(); got != "Bearer token-1" {
+		t.Errorf("Authorization = %q, want the token of bearer_token_env", got)
+	}
+	if len(headers) != 1 {
+		t.Errorf("configured headers were modified: %v", headers)
+	}
+	states := recordStates(c)
+
+	// Same server: the session is resumed with the rotated token
+	t.Setenv("REMOTE_MCP_TOKEN", "token-2")
+	sessionID := c.httpSessionID
+	if err := c.reconnectIfStale(context.Background(), c.connGeneration(), io.EOF); err != nil {
+		t.Fatalf("reconnect: %v", err)
+	}
+	if c.httpSessionID != sessionID || remote.lastAuth() != "Bearer token-2" {
+		t.Errorf("session %q (was %q), Authorization %q", c.httpSessionID, sessionID, remote.lastAuth())
+	}
+	got := states()
+	want := []ConnectionState{ConnectionStateDisconnected, ConnectionStateReconnecting, ConnectionStateReconnected}
+	if len(got) != len(want) {
+		t.Fatalf("states = %+v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i].State != want[i] || got[i].Server != "remote" {
+			t.Errorf("state %d = %+v, want %s", i, got[i], want[i])
+		}
+	}
+	if !got[2].SessionResumed || got[2].Attempt != 1 {
+		t.Errorf("reconnected = %+v, want a resumed session on attempt 1", got[2])
+	}
+
+	// Restarted server: the old session is gone, so a new one is started
+	remote.restart()
+	if err := c.reconnectIfStale(context.Background(), c.connGeneration(), io.EOF); err != nil {
+		t.Fatalf("reconnect after restart: %v", err)
+	}
+	if last := states()[len(states())-1]; last.State != ConnectionStateReconnected || last.SessionResumed {
+		t.Errorf("after restart = %+v, want reconnected to a new session", last)
+	}
+	if c.httpSessionID == "" || c.httpSessionID == sessionID {
+		t.Errorf("session %q after restart, want a new one", c.httpSessionID)
+	}
+	if _, err := c.ListTools(context.Background()); err != nil {
+		t.Errorf("list tools after restart: %v", err)
+	}
+}
+
+func TestKeepAliveRecoversFromServerRestart(t *testing.T) {
+	remote := newRestartableMCPServer(t)
+	retry := DefaultRetryConfig()
+	retry.InitialDelay = 10 * time.Millisecond
+	retry.MaxDelay = 50 * time.Millisecond
+	c := NewWithRetryConfig(MCPServerConfig{URL: remote.URL + "/mcp", Protocol: ProtocolHTTP, KeepAlive: "20ms"}, retry, loggerv2.NewNoop())
+	if err := c.Connect(context.Background()); err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	defer func() { _ = c.Close() }()
+	states := recordStates(c)
+	gen := c.connGeneration()
+
+	remote.restart()
+	deadline := time.Now().Add(5 * time.Second)
+	for c.connGeneration() == gen && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if c.connGeneration() == gen {
+		t.Fatalf("keep-alive did not reconnect after the server restarted; states %+v", states())
+	}
+	if got := states(); len(got) == 0 || got[0].State != ConnectionStateDisconnected || got[0].Err == nil {
+		t.Errorf("states = %+v, want a disconnected state with its cause first", got)
+	}
+	if _, err := c.ListTools(context.Background()); err != nil {
+		t.Errorf("list tools after recovery: %v", err)
+	}
+
+	// Closed clients stop pinging and do not reconnect
+	_ = c.Close()
+	gen = c.connGeneration()
+	remote.restart()
+	time.Sleep(100 * time.Millisecond)
+	if c.connGeneration() != gen {
+		t.Error("closed client reconnected")
+	}
+}
+
+func TestSSEStreamLossStartsRecovery(t *testing.T) {
+	var handler atomic.Pointer[http.Handler]
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		(*handler.Load()).ServeHTTP(w, r)
+	}))
+	defer httpServer.Close()
+	restart := func() {
+		var h http.Handler = server.NewSSEServer(server.NewMCPServer("events", "1.0.0"), server.WithBaseURL(httpServer.URL))
+		handler.Store(&h)
+	}
+	restart()
+
+	retry := DefaultRetryConfig()
+	retry.InitialDelay = 10 * time.Millisecond
+	c := NewWithRetryConfig(MCPServerConfig{URL: httpServer.URL + "/sse", Protocol: ProtocolSSE, KeepAlive: "0"}, retry, loggerv2.NewNoop())
+	if err := c.Connect(context.Background()); err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	defer func() { _ = c.Close() }()
+	states := recordStates(c)
+	gen := c.connGeneration()
+
+	restart()
+	httpServer.CloseClientConnections()
+	deadline := time.Now().Add(5 * time.Second)
+	for c.connGeneration() == gen && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if c.connGeneration() == gen {
+		t.Fatalf("dropped SSE stream was not recovered; states %+v", states())
+	}
+	if err := c.Ping(context.Background()); err != nil {
+		t.Errorf("ping after recovery: %v", err)
+	}
+}
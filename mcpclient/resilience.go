@@ -14,6 +14,7 @@ import (
 	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
 
 	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/client/transport"
 )
 
 // Mid-session connection resilience.
@@ -42,7 +43,9 @@ func isTransportDeadError(err error) bool {
 		return false
 	}
 	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrClosedPipe) ||
-		errors.Is(err, syscall.EPIPE) || errors.Is(err, syscall.ECONNRESET) {
+		errors.Is(err, syscall.EPIPE) || errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, transport.ErrSessionTerminated) ||
+		errors.Is(err, transport.ErrTransportClosed) {
 		return true
 	}
 	msg := err.Error()
@@ -52,8 +55,11 @@ func isTransportDeadError(err error) bool {
 		"use of closed network connection",
 		"file already closed",
 		"transport is closed",
+		"transport closed", // Stdio server process exited
 		"connection closed",
 		"process already finished",
+		"connection refused", // Remote server restarting
+		"session terminated", // Streamable HTTP server forgot the session (404)
 		"EOF",
 	} {
 		if strings.Contains(msg, pattern) {
@@ -83,8 +89,10 @@ func (c *Client) connGeneration() int64 {
 
 // reconnectIfStale reconnects unless another goroutine already did (the
 // generation moved past observedGen). Serialized so N concurrent failed calls
-// produce one reconnect, not N.
-func (c *Client) reconnectIfStale(ctx context.Context, observedGen int64) error {
+// produce one reconnect, not N. cause is why the connection was found dead;
+// the attempt is reported to the connection state listeners
+// (remote_connection.go).
+func (c *Client) reconnectIfStale(ctx context.Context, observedGen int64, cause error) error {
 	c.reconnectMu.Lock()
 	defer c.reconnectMu.Unlock()
 
@@ -93,17 +101,37 @@ func (c *Client) reconnectIfStale(ctx context.Context, observedGen int64) error
 			loggerv2.String("server", c.getServerName()))
 		return nil
 	}
+	if c.closed.Load() {
+		return fmt.Errorf("client for server '%s' is closed", c.getServerName())
+	}
+
+	if c.reconnectFailures == 0 {
+		c.lostAt, c.lossCause = time.Now(), cause
+		c.notifyState(ConnectionStateChange{State: ConnectionStateDisconnected, Err: cause})
+	}
+	attempt := c.reconnectFailures + 1
+	c.notifyState(ConnectionStateChange{State: ConnectionStateReconnecting, Attempt: attempt})
 
 	c.logger.Warn("MCP transport dead mid-session, reconnecting",
-		loggerv2.String("server", c.getServerName()))
+		loggerv2.String("server", c.getServerName()),
+		loggerv2.Int("attempt", attempt))
 
 	reconnectCtx, cancel := context.WithTimeout(ctx, reconnectTimeout)
 	defer cancel()
-	if err := c.Connect(reconnectCtx); err != nil {
+	if err := c.connect(reconnectCtx); err != nil {
+		c.reconnectFailures++
+		c.notifyState(ConnectionStateChange{State: ConnectionStateReconnectFailed, Attempt: attempt, Err: err})
 		return err
 	}
+	c.reconnectFailures = 0
+	state := ConnectionStateReconnected
+	if c.config.GetProtocol() == ProtocolStdio {
+		state = ConnectionStateRestarted
+	}
+	c.notifyState(ConnectionStateChange{State: state, Attempt: attempt, SessionResumed: c.sessionResumed, Err: c.lossCause, Downtime: time.Since(c.lostAt)})
 	c.logger.Info("MCP mid-session reconnect succeeded",
-		loggerv2.String("server", c.getServerName()))
+		loggerv2.String("server", c.getServerName()),
+		loggerv2.Any("session_resumed", c.sessionResumed))
 	return nil
 }
 
@@ -127,7 +155,7 @@ func (c *Client) EnsureConnected(ctx context.Context) error {
 	c.logger.Warn("MCP health ping failed, attempting reconnect",
 		loggerv2.String("server", c.getServerName()),
 		loggerv2.Error(err))
-	return c.reconnectIfStale(ctx, gen)
+	return c.reconnectIfStale(ctx, gen, err)
 }
 
 // === Leak detection ===
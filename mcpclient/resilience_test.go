@@ -70,7 +70,7 @@ func TestReconnectIfStaleSkipsWhenGenerationMoved(t *testing.T) {
 	// (generation now 1). reconnectIfStale must be a no-op (no Connect attempt
 	// — Connect on this config would fail, so a nil error proves the skip).
 	c.connGen.Add(1)
-	if err := c.reconnectIfStale(context.Background(), 0); err != nil {
+	if err := c.reconnectIfStale(context.Background(), 0, io.EOF); err != nil {
 		t.Errorf("expected skip (nil error) when generation moved, got: %v", err)
 	}
 }
@@ -0,0 +1,110 @@
+package mcpclient
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// ErrNoSamplingHandler is returned to a server that requests sampling while
+// no tool call with a SamplingHandler is running on its connection
+var ErrNoSamplingHandler = errors.New("sampling is only available while a tool call of a sampling-enabled agent is running")
+
+// ErrAmbiguousSamplingHandler is returned to a server that requests sampling
+// while tool calls of several owners run on its shared connection: the
+// request does not say which call it belongs to, so no owner's LLM is used
+var ErrAmbiguousSamplingHandler = errors.New("sampling request is ambiguous: tool calls of several agents are running on the connection")
+
+// SamplingHandler answers a sampling/createMessage request, in which an MCP
+// server asks the client for an LLM completion
+type SamplingHandler func(ctx context.Context, request mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error)
+
+type samplingHandlerKey struct{}
+
+// samplingEntry is the sampling handler of one tool call and its owner
+type samplingEntry struct {
+	owner   any
+	handler SamplingHandler
+}
+
+// WithSamplingHandler returns a context whose CallTool answers the sampling
+// requests the server sends while the tool runs with handler. owner
+// identifies who the handler answers for (an agent) and must be comparable;
+// while tool calls of different owners share the connection, requests are
+// refused with ErrAmbiguousSamplingHandler. The server must be configured
+// with "sampling": true so the capability is advertised.
+func WithSamplingHandler(ctx context.Context, owner any, handler SamplingHandler) context.Context {
+	return context.WithValue(ctx, samplingHandlerKey{}, &samplingEntry{owner: owner, handler: handler})
+}
+
+func samplingHandlerFrom(ctx context.Context) *samplingEntry {
+	entry, _ := ctx.Value(samplingHandlerKey{}).(*samplingEntry)
+	return entry
+}
+
+// samplingRegistry tracks the sampling handlers of in-flight tool calls
+type samplingRegistry struct {
+	mu       sync.Mutex
+	handlers []*samplingEntry
+}
+
+// registerSampling makes the sampling handler in ctx answer the server's
+// sampling requests until the returned func is called. The MCP request does
+// not say which tool call it belongs to, so concurrent tool calls of one
+// owner share its most recently registered handler.
+func (c *Client) registerSampling(ctx context.Context) func() {
+	found := samplingHandlerFrom(ctx)
+	if found == nil || found.handler == nil {
+		return func() {}
+	}
+	// Each call gets its own entry, also when ctx is reused
+	entry := &samplingEntry{owner: found.owner, handler: found.handler}
+	c.sampling.mu.Lock()
+	c.sampling.handlers = append(c.sampling.handlers, entry)
+	c.sampling.mu.Unlock()
+
+	return func() {
+		c.sampling.mu.Lock()
+		defer c.sampling.mu.Unlock()
+		for i, h := range c.sampling.handlers {
+			if h == entry {
+				c.sampling.handlers = append(c.sampling.handlers[:i], c.sampling.handlers[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+// CreateMessage implements client.SamplingHandler by routing the request to
+// the handler of the latest in-flight tool call, provided all in-flight calls
+// have the same owner
+func (r *samplingRegistry) CreateMessage(ctx context.Context, request mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
+	r.mu.Lock()
+	var handler SamplingHandler
+	if n := len(r.handlers); n > 0 {
+		handler = r.handlers[n-1].handler
+		for _, entry := range r.handlers[:n-1] {
+			if entry.owner != r.handlers[n-1].owner {
+				r.mu.Unlock()
+				return nil, ErrAmbiguousSamplingHandler
+			}
+		}
+	}
+	r.mu.Unlock()
+	if handler == nil {
+		return nil, ErrNoSamplingHandler
+	}
+	return handler(ctx, request)
+}
+
+// clientOptions returns the mcp-go options of a new connection: the sampling
+// capability for servers configured with "sampling": true
+func (c *Client) clientOptions() []client.ClientOption {
+	if !c.config.Sampling {
+		return nil
+	}
+	return []client.ClientOption{client.WithSamplingHandler(&c.sampling)}
+}
@@ -0,0 +1,72 @@
+package mcpclient
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func samplingHandlerReplying(text string) SamplingHandler {
+	return func(context.Context, mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
+		return &mcp.CreateMessageResult{
+			SamplingMessage: mcp.SamplingMessage{Role: mcp.RoleAssistant, Content: mcp.NewTextContent(text)},
+		}, nil
+	}
+}
+
+func samplingReply(t *testing.T, c *Client) string {
+	t.Helper()
+	result, err := c.sampling.CreateMessage(context.Background(), mcp.CreateMessageRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return result.Content.(mcp.TextContent).Text
+}
+
+func TestSamplingRoutedToLatestToolCall(t *testing.T) {
+	c := &Client{}
+	if _, err := c.sampling.CreateMessage(context.Background(), mcp.CreateMessageRequest{}); !errors.Is(err, ErrNoSamplingHandler) {
+		t.Fatalf("without a tool call: error = %v, want ErrNoSamplingHandler", err)
+	}
+
+	unregisterFirst := c.registerSampling(WithSamplingHandler(context.Background(), "agent", samplingHandlerReplying("first")))
+	unregisterSecond := c.registerSampling(WithSamplingHandler(context.Background(), "agent", samplingHandlerReplying("second")))
+	if got := samplingReply(t, c); got != "second" {
+		t.Errorf("reply = %q, want the latest call's handler", got)
+	}
+	unregisterSecond()
+	if got := samplingReply(t, c); got != "first" {
+		t.Errorf("reply = %q after the second call ended, want first", got)
+	}
+	unregisterFirst()
+
+	c.registerSampling(context.Background())()
+	if len(c.sampling.handlers) != 0 {
+		t.Errorf("handlers left after all calls ended: %d", len(c.sampling.handlers))
+	}
+}
+
+func TestSamplingRefusedWhenOwnersShareConnection(t *testing.T) {
+	c := &Client{}
+	unregisterFirst := c.registerSampling(WithSamplingHandler(context.Background(), "agent-1", samplingHandlerReplying("first")))
+	unregisterSecond := c.registerSampling(WithSamplingHandler(context.Background(), "agent-2", samplingHandlerReplying("second")))
+	if _, err := c.sampling.CreateMessage(context.Background(), mcp.CreateMessageRequest{}); !errors.Is(err, ErrAmbiguousSamplingHandler) {
+		t.Errorf("two owners: error = %v, want ErrAmbiguousSamplingHandler", err)
+	}
+	unregisterFirst()
+	if got := samplingReply(t, c); got != "second" {
+		t.Errorf("reply = %q after the first owner's call ended, want second", got)
+	}
+	unregisterSecond()
+}
+
+func TestSamplingClientOptions(t *testing.T) {
+	if opts := (&Client{config: MCPServerConfig{}}).clientOptions(); len(opts) != 0 {
+		t.Errorf("servers without sampling must not get client options, got %d", len(opts))
+	}
+	if opts := (&Client{config: MCPServerConfig{Sampling: true}}).clientOptions(); len(opts) != 1 {
+		t.Errorf("sampling server: %d client options, want 1", len(opts))
+	}
+}
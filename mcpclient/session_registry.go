@@ -398,6 +398,17 @@ func (r *SessionConnectionRegistry) CloseSessionServer(sessionID, serverName str
 	}
 }
 
+// DetachSessionServer removes a server connection from a session without
+// closing it, e.g. to connect the server anew while the old connection is
+// still in use. StoreConnection puts it back.
+func (r *SessionConnectionRegistry) DetachSessionServer(sessionID, serverName string) {
+	sessionConnsRaw, ok := r.sessions.Load(sessionID)
+	if !ok {
+		return
+	}
+	sessionConnsRaw.(*sessionConnections).clients.Delete(serverName)
+}
+
 // ListSessions returns all active session IDs (for debugging)
 func (r *SessionConnectionRegistry) ListSessions() []string {
 	var sessions []string
@@ -15,6 +15,9 @@ type SSEManager struct {
 	url     string
 	headers map[string]string
 	logger  loggerv2.Logger
+
+	headerFunc       transport.HTTPHeaderFunc // Headers computed per request, e.g. a refreshed bearer token
+	onConnectionLost func(error)              // Called when the SSE stream drops (remote_connection.go)
 }
 
 // NewSSEManager creates a new SSE manager
@@ -35,6 +38,9 @@ func (s *SSEManager) CreateClient() (*client.Client, error) {
 	if len(s.headers) > 0 {
 		options = append(options, transport.WithHeaders(s.headers))
 	}
+	if s.headerFunc != nil {
+		options = append(options, transport.WithHeaderFunc(s.headerFunc))
+	}
 
 	// Add custom logger for better debugging
 	// Adapt v2.Logger to util.Logger for transport
@@ -46,6 +52,9 @@ func (s *SSEManager) CreateClient() (*client.Client, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to create SSE transport: %w", err)
 	}
+	if s.onConnectionLost != nil {
+		sseTransport.SetConnectionLostHandler(s.onConnectionLost)
+	}
 
 	// Create client with transport
 	return client.NewClient(sseTransport), nil
@@ -28,6 +28,11 @@ type StdioManager struct {
 	workingDir string
 	logger     loggerv2.Logger
 	serverKey  string
+
+	instructions string // From the initialize result of the last Connect
+
+	clientOptions []client.ClientOption // e.g. the sampling handler (sampling.go)
+	onExit        func(error)           // Called when the subprocess exits (supervisor.go)
 }
 
 // NewStdioManager creates a new stdio manager.
@@ -76,6 +81,9 @@ func (s *StdioManager) CreateClient() (*client.Client, error) {
 		s.logger.Error("Failed to create stdio client", err)
 		return nil, fmt.Errorf("failed to create stdio client: %w", err)
 	}
+	if mcpClient, err = s.applyClientOptions(mcpClient); err != nil {
+		return nil, fmt.Errorf("failed to create stdio client: %w", err)
+	}
 	s.logger.Debug("Stdio client created successfully")
 
 	return mcpClient, nil
@@ -137,6 +145,10 @@ func (s *StdioManager) Connect(ctx context.Context) (*client.Client, error) {
 		mcpClient, err = client.NewStdioMCPClient(s.command, s.env, s.args...)
 	}
 
+	if err == nil {
+		mcpClient, err = s.applyClientOptions(mcpClient)
+	}
+
 	if err != nil {
 		clientDuration := time.Since(clientStartTime)
 		s.logger.Error(fmt.Sprintf("❌ [MCP INIT] Failed to create stdio client - server=%s, duration=%v", s.serverKey, clientDuration), err,
@@ -298,11 +310,28 @@ func (s *StdioManager) Connect(ctx context.Context) (*client.Client, error) {
 		loggerv2.String("init_time", initDuration.Round(time.Millisecond).String()),
 		loggerv2.String("total_time", totalDuration.Round(time.Millisecond).String()))
 	s.logger.Debug("Server info", loggerv2.Any("server_info", initResult.ServerInfo))
+	s.instructions = initResult.Instructions
 
 	s.logger.Debug("Stdio connection obtained successfully")
 	return mcpClient, nil
 }
 
+// applyClientOptions rebuilds mcpClient on its running transport with the
+// manager's client options. mcp-go starts stdio transports without Client.Start,
+// which is what installs the handler for server-initiated requests.
+func (s *StdioManager) applyClientOptions(mcpClient *client.Client) (*client.Client, error) {
+	if len(s.clientOptions) == 0 {
+		return mcpClient, nil
+	}
+	withOptions := client.NewClient(mcpClient.GetTransport(), s.clientOptions...)
+	// The transport is already running; Start only installs the handlers
+	if err := withOptions.Start(context.Background()); err != nil {
+		_ = mcpClient.Close()
+		return nil, err
+	}
+	return withOptions, nil
+}
+
 // captureStderr reads from the stderr reader, logs each line, and detects fatal errors
 func (s *StdioManager) captureStderr(stderrReader io.Reader, fatalErrorChan chan<- error) {
 	scanner := bufio.NewScanner(stderrReader)
@@ -310,10 +339,12 @@ func (s *StdioManager) captureStderr(stderrReader io.Reader, fatalErrorChan chan
 	scanner.Buffer(buffer, 1024*1024)  // Allow up to 1MB lines
 
 	fatalErrorSent := false
+	lastLine := ""
 
 	for scanner.Scan() {
 		line := scanner.Text()
 		if strings.TrimSpace(line) != "" {
+			lastLine = line
 			s.logger.Info(fmt.Sprintf("📋 [MCP STDERR] %s: %s", s.serverKey, line),
 				loggerv2.String("server", s.serverKey),
 				loggerv2.String("stderr_line", line))
@@ -346,6 +377,15 @@ func (s *StdioManager) captureStderr(stderrReader io.Reader, fatalErrorChan chan
 		s.logger.Debug(fmt.Sprintf("📋 [MCP STDERR] Stderr stream closed - server=%s", s.serverKey),
 			loggerv2.String("server", s.serverKey))
 	}
+
+	// Stderr closes when the subprocess exits (or the transport is closed)
+	if s.onExit != nil {
+		exitErr := errors.New("MCP server process exited")
+		if lastLine != "" {
+			exitErr = fmt.Errorf("MCP server process exited (last stderr line: %s)", lastLine)
+		}
+		s.onExit(exitErr)
+	}
 }
 
 // detectFatalError checks if a stderr line indicates a fatal error that should cause early failure
@@ -386,6 +426,11 @@ func (s *StdioManager) GetServerKey() string {
 	return s.serverKey
 }
 
+// Instructions returns the usage instructions the server sent on Connect
+func (s *StdioManager) Instructions() string {
+	return s.instructions
+}
+
 // hashEnvVars creates a deterministic hash of environment variables
 // This ensures that connections with different env vars get different server keys
 func hashEnvVars(env []string) string {
@@ -0,0 +1,79 @@
+package mcpclient
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+	"weak"
+
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+
+	"github.com/mark3labs/mcp-go/client"
+)
+
+// Supervision of MCP server processes.
+//
+// A stdio server that crashes mid-conversation used to fail the tool call
+// that found it dead. Its stderr stream closes when the subprocess exits, so
+// the client notices the exit right away and restarts the server in the
+// background (startRecovery: respawn and initialize handshake, with
+// exponential backoff). A call that fails on the dead process waits for the
+// restart and is retried once (CallTool). Each restart is reported to the
+// connection state listeners as ConnectionStateRestarted.
+//
+// A server that keeps crashing is not restarted forever: after
+// maxSupervisedRestarts restarts within restartWindow, the supervisor gives
+// up and only the next tool call tries to reconnect.
+
+const (
+	maxSupervisedRestarts = 5
+	restartWindow         = 5 * time.Minute
+)
+
+// connectionLostHandler returns the handler a transport calls when its
+// connection ends (stream dropped, subprocess exited), and the func that arms
+// it with the connection's client once connected. Closing a replaced
+// connection (connectOnce) or the client also ends it, so only the live
+// connection of an open client starts a recovery.
+func (c *Client) connectionLostHandler(reason string) (handler func(error), arm func(*client.Client)) {
+	var owner atomic.Pointer[client.Client]
+	self := weak.Make(c)
+	handler = func(err error) {
+		c := self.Value()
+		if c == nil || c.closed.Load() || owner.Load() == nil || owner.Load() != c.mcpClient {
+			return
+		}
+		c.logger.Warn(reason,
+			loggerv2.String("server", c.getServerName()),
+			loggerv2.Error(err))
+		if !c.allowSupervisedRestart() {
+			c.logger.Error("MCP server keeps failing, not restarting it",
+				fmt.Errorf("%d restarts within %s", maxSupervisedRestarts, restartWindow),
+				loggerv2.String("server", c.getServerName()))
+			return
+		}
+		c.startRecovery(c.connGeneration(), err)
+	}
+	return handler, func(mcpClient *client.Client) { owner.Store(mcpClient) }
+}
+
+// allowSupervisedRestart records a supervised restart, reporting false when
+// the server already restarted maxSupervisedRestarts times within
+// restartWindow
+func (c *Client) allowSupervisedRestart() bool {
+	c.restartMu.Lock()
+	defer c.restartMu.Unlock()
+	cutoff := time.Now().Add(-restartWindow)
+	recent := c.restarts[:0]
+	for _, at := range c.restarts {
+		if at.After(cutoff) {
+			recent = append(recent, at)
+		}
+	}
+	c.restarts = recent
+	if len(c.restarts) >= maxSupervisedRestarts {
+		return false
+	}
+	c.restarts = append(c.restarts, time.Now())
+	return true
+}
@@ -0,0 +1,128 @@
+package mcpclient
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"testing"
+	"time"
+
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// TestHelperStdioServer is not a test: run by the tests below as a stdio MCP
+// server subprocess. Its crash_once tool exits the process unless the marker
+// file exists, which it creates first, so the restarted server answers.
+func TestHelperStdioServer(t *testing.T) {
+	if os.Getenv("MCPCLIENT_TEST_STDIO_SERVER") != "1" {
+		t.Skip("helper process")
+	}
+	mcpServer := server.NewMCPServer("supervised", "1.0.0")
+	mcpServer.AddTool(mcp.NewTool("crash_once"), func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		marker := os.Getenv("MCPCLIENT_TEST_CRASH_MARKER")
+		if _, err := os.Stat(marker); err != nil {
+			_ = os.WriteFile(marker, nil, 0o600)
+			fmt.Fprintln(os.Stderr, "panic: simulated crash")
+			os.Exit(2)
+		}
+		return mcp.NewToolResultText("recovered"), nil
+	})
+	mcpServer.AddTool(mcp.NewTool("pid"), func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText(strconv.Itoa(os.Getpid())), nil
+	})
+	_ = server.ServeStdio(mcpServer)
+	os.Exit(0)
+}
+
+func newSupervisedTestClient(t *testing.T) (*Client, func() []ConnectionStateChange) {
+	t.Helper()
+	retry := DefaultRetryConfig()
+	retry.InitialDelay = 10 * time.Millisecond
+	c := NewWithRetryConfig(MCPServerConfig{
+		Description: "supervised",
+		Command:     os.Args[0],
+		Args:        []string{"-test.run=^TestHelperStdioServer$"},
+		Env: map[string]string{
+			"MCPCLIENT_TEST_STDIO_SERVER": "1",
+			"MCPCLIENT_TEST_CRASH_MARKER": filepath.Join(t.TempDir(), "crashed"),
+		},
+	}, retry, loggerv2.NewNoop())
+	if err := c.Connect(context.Background()); err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	t.Cleanup(func() { _ = c.Close() })
+	return c, recordStates(c)
+}
+
+func TestCallToolRetriesAfterServerCrash(t *testing.T) {
+	c, states := newSupervisedTestClient(t)
+
+	result, err := c.CallTool(context.Background(), "crash_once", nil)
+	if err != nil {
+		t.Fatalf("call on a crashing server: %v", err)
+	}
+	if text := result.Content[0].(mcp.TextContent).Text; text != "recovered" {
+		t.Errorf("result %q, want the answer of the restarted server", text)
+	}
+
+	var restarted *ConnectionStateChange
+	for _, change := range states() {
+		if change.State == ConnectionStateRestarted {
+			restarted = &change
+		}
+	}
+	if restarted == nil || restarted.Err == nil {
+		t.Fatalf("states = %+v, want a restarted state with the crash cause", states())
+	}
+}
+
+func TestSupervisorRestartsExitedServer(t *testing.T) {
+	c, states := newSupervisedTestClient(t)
+	result, err := c.CallTool(context.Background(), "pid", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pid, _ := strconv.Atoi(result.Content[0].(mcp.TextContent).Text)
+	gen := c.connGeneration()
+
+	// Killed while idle: restarted without waiting for a tool call
+	if err := syscall.Kill(pid, syscall.SIGKILL); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(10 * time.Second)
+	for c.connGeneration() == gen && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if c.connGeneration() == gen {
+		t.Fatalf("exited server was not restarted; states %+v", states())
+	}
+	if got := states(); got[len(got)-1].State != ConnectionStateRestarted {
+		t.Errorf("states = %+v, want restarted last", got)
+	}
+	result, err = c.CallTool(context.Background(), "pid", nil)
+	if err != nil || result.Content[0].(mcp.TextContent).Text == strconv.Itoa(pid) {
+		t.Errorf("call after restart = %v, %v, want a new process", result, err)
+	}
+}
+
+func TestAllowSupervisedRestartStopsCrashLoops(t *testing.T) {
+	c := New(MCPServerConfig{Command: "crashy"}, loggerv2.NewNoop())
+	for i := 0; i < maxSupervisedRestarts; i++ {
+		if !c.allowSupervisedRestart() {
+			t.Fatalf("restart %d refused", i+1)
+		}
+	}
+	if c.allowSupervisedRestart() {
+		t.Error("restart beyond the limit allowed")
+	}
+	c.restarts[0] = time.Now().Add(-2 * restartWindow)
+	if !c.allowSupervisedRestart() {
+		t.Error("restarts outside the window must not count")
+	}
+}
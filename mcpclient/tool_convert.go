@@ -319,6 +319,16 @@ func ToolResultAsString(result *mcp.CallToolResult) string {
 	// Join all content parts
 	var parts []string
 	for _, content := range result.Content {
+		// mcp.NewToolResultText and friends (e.g. in tool middleware) store
+		// content by value; handle it like the pointers MCP clients return
+		switch c := content.(type) {
+		case mcp.TextContent:
+			content = &c
+		case mcp.ImageContent:
+			content = &c
+		case mcp.EmbeddedResource:
+			content = &c
+		}
 		switch c := content.(type) {
 		case *mcp.TextContent:
 			// Try to parse JSON format {"type":"text","text":"..."}
@@ -0,0 +1,158 @@
+package mcpclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// progressNotificationMethod is the MCP method of progress notifications
+const progressNotificationMethod = "notifications/progress"
+
+// ToolProgress is one progress notification received while a tool was running
+type ToolProgress struct {
+	Progress float64
+	Total    float64 // 0 when the server did not report a total
+	Message  string
+	Time     time.Time
+}
+
+// ToolProgressCollector records the progress notifications of tool calls made
+// with a context returned by WithToolProgressCollector. If a call times out,
+// the collected updates are the partial output the server produced.
+type ToolProgressCollector struct {
+	mu       sync.Mutex
+	updates  []ToolProgress
+	onUpdate func(ToolProgress)
+}
+
+// NewToolProgressCollector creates an empty collector
+func NewToolProgressCollector() *ToolProgressCollector {
+	return &ToolProgressCollector{}
+}
+
+// NewToolProgressCollectorWithCallback creates an empty collector that also
+// calls onUpdate with each notification as it arrives, so callers can report
+// progress while the tool is still running
+func NewToolProgressCollectorWithCallback(onUpdate func(ToolProgress)) *ToolProgressCollector {
+	return &ToolProgressCollector{onUpdate: onUpdate}
+}
+
+// Updates returns the progress notifications received so far, oldest first
+func (c *ToolProgressCollector) Updates() []ToolProgress {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]ToolProgress(nil), c.updates...)
+}
+
+func (c *ToolProgressCollector) add(p ToolProgress) {
+	c.mu.Lock()
+	c.updates = append(c.updates, p)
+	c.mu.Unlock()
+	if c.onUpdate != nil {
+		c.onUpdate(p)
+	}
+}
+
+type toolProgressKey struct{}
+
+// WithToolProgressCollector returns a context that makes CallTool request
+// progress notifications from the server and record them in collector
+func WithToolProgressCollector(ctx context.Context, collector *ToolProgressCollector) context.Context {
+	return context.WithValue(ctx, toolProgressKey{}, collector)
+}
+
+// ToolProgressCollectorFrom returns the collector set by
+// WithToolProgressCollector, or nil
+func ToolProgressCollectorFrom(ctx context.Context) *ToolProgressCollector {
+	collector, _ := ctx.Value(toolProgressKey{}).(*ToolProgressCollector)
+	return collector
+}
+
+// PartialToolResult builds the result returned to the model when a tool call
+// timed out after reporting progress. It returns nil when there is nothing to
+// report, so the caller can fall back to the plain timeout error.
+func PartialToolResult(toolName string, elapsed time.Duration, updates []ToolProgress) *mcp.CallToolResult {
+	var lines []string
+	for _, u := range updates {
+		line := strings.TrimSpace(u.Message)
+		switch {
+		case u.Total > 0:
+			line = strings.TrimSpace(fmt.Sprintf("[%g/%g] %s", u.Progress, u.Total, line))
+		case line == "":
+			line = fmt.Sprintf("[progress %g]", u.Progress)
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+
+	text := fmt.Sprintf("PARTIAL RESULT: tool '%s' timed out after %s before finishing. "+
+		"The output below is the progress it reported before the timeout and may be incomplete. "+
+		"Use what is useful, and if needed retry with a smaller scope (fewer pages, items or a narrower query).\n\n%s",
+		toolName, elapsed.Round(time.Second), strings.Join(lines, "\n"))
+	return &mcp.CallToolResult{
+		IsError: true,
+		Content: []mcp.Content{&mcp.TextContent{Text: text}},
+	}
+}
+
+// registerProgress assigns a progress token to a tool call made with a
+// collector in ctx. The returned func unregisters it.
+func (c *Client) registerProgress(ctx context.Context, request *mcp.CallToolRequest) func() {
+	collector := ToolProgressCollectorFrom(ctx)
+	if collector == nil {
+		return func() {}
+	}
+	token := fmt.Sprintf("mcpagent-%d", c.progressSeq.Add(1))
+	request.Params.Meta = &mcp.Meta{ProgressToken: token}
+
+	c.progressMu.Lock()
+	if c.progressCollectors == nil {
+		c.progressCollectors = make(map[string]*ToolProgressCollector)
+	}
+	c.progressCollectors[token] = collector
+	c.progressMu.Unlock()
+
+	return func() {
+		c.progressMu.Lock()
+		delete(c.progressCollectors, token)
+		c.progressMu.Unlock()
+	}
+}
+
+// handleNotification routes progress notifications to the collector of the
+// tool call that requested them
+func (c *Client) handleNotification(notification mcp.JSONRPCNotification) {
+	if notification.Method != progressNotificationMethod {
+		return
+	}
+	raw, err := json.Marshal(notification.Params)
+	if err != nil {
+		return
+	}
+	var params mcp.ProgressNotificationParams
+	if err := json.Unmarshal(raw, &params); err != nil {
+		return
+	}
+	token := fmt.Sprint(params.ProgressToken)
+
+	c.progressMu.Lock()
+	collector := c.progressCollectors[token]
+	c.progressMu.Unlock()
+	if collector == nil {
+		return
+	}
+	collector.add(ToolProgress{
+		Progress: params.Progress,
+		Total:    params.Total,
+		Message:  params.Message,
+		Time:     time.Now(),
+	})
+}
@@ -0,0 +1,73 @@
+package mcpclient
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func progressNotification(token any, progress, total float64, message string) mcp.JSONRPCNotification {
+	return mcp.JSONRPCNotification{
+		JSONRPC: mcp.JSONRPC_VERSION,
+		Notification: mcp.Notification{
+			Method: progressNotificationMethod,
+			Params: mcp.NotificationParams{AdditionalFields: map[string]any{
+				"progressToken": token,
+				"progress":      progress,
+				"total":         total,
+				"message":       message,
+			}},
+		},
+	}
+}
+
+func TestProgressNotificationsRoutedToCallCollector(t *testing.T) {
+	c := &Client{}
+	collector := NewToolProgressCollector()
+	ctx := WithToolProgressCollector(context.Background(), collector)
+
+	request := mcp.CallToolRequest{}
+	unregister := c.registerProgress(ctx, &request)
+	if request.Params.Meta == nil || request.Params.Meta.ProgressToken == nil {
+		t.Fatal("expected progress token on the request")
+	}
+	token := request.Params.Meta.ProgressToken
+
+	c.handleNotification(progressNotification(token, 3, 10, "scraped page 3"))
+	c.handleNotification(progressNotification("other-call", 1, 0, "not ours"))
+	updates := collector.Updates()
+	if len(updates) != 1 || updates[0].Message != "scraped page 3" || updates[0].Total != 10 {
+		t.Fatalf("unexpected updates: %+v", updates)
+	}
+
+	unregister()
+	c.handleNotification(progressNotification(token, 4, 10, "after the call"))
+	if got := len(collector.Updates()); got != 1 {
+		t.Errorf("notifications after the call must be ignored, got %d updates", got)
+	}
+
+	plain := mcp.CallToolRequest{}
+	c.registerProgress(context.Background(), &plain)()
+	if plain.Params.Meta != nil {
+		t.Error("calls without a collector must not request progress")
+	}
+}
+
+func TestPartialToolResult(t *testing.T) {
+	if PartialToolResult("scrape", time.Minute, nil) != nil {
+		t.Error("no progress should produce no partial result")
+	}
+	result := PartialToolResult("scrape", 90*time.Second, []ToolProgress{
+		{Progress: 1, Total: 3, Message: "page 1: 20 products"},
+		{Progress: 2},
+	})
+	text := result.Content[0].(*mcp.TextContent).Text
+	for _, want := range []string{"PARTIAL RESULT", "1m30s", "[1/3] page 1: 20 products", "[progress 2]"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("partial result missing %q:\n%s", want, text)
+		}
+	}
+}
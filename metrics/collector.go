@@ -0,0 +1,139 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/manishiitg/mcpagent/events"
+)
+
+// Error sources reported in the source label of mcpagent_errors_total
+const (
+	ErrorSourceLLM          = "llm"
+	ErrorSourceTool         = "tool"
+	ErrorSourceConversation = "conversation"
+	ErrorSourceConnection   = "mcp_connection"
+)
+
+// Collector derives Prometheus metrics from agent events. It implements the
+// agent event listener interface, so one Collector can be added to every agent
+// of a process:
+//
+//	collector := metrics.NewCollector()
+//	agent.AddEventListener(collector)
+//	http.Handle("/metrics", collector.Handler())
+type Collector struct {
+	registry *Registry
+
+	conversations *CounterVec
+	llmCalls      *CounterVec
+	llmDuration   *HistogramVec
+	llmTokens     *CounterVec
+	llmCost       *CounterVec
+	toolCalls     *CounterVec
+	toolDuration  *HistogramVec
+	errors        *CounterVec
+	cacheLookups  *CounterVec
+
+	activeAgents atomic.Pointer[func() int]
+}
+
+// NewCollector creates a collector with its metrics registered
+func NewCollector() *Collector {
+	r := NewRegistry()
+	c := &Collector{
+		registry:      r,
+		conversations: r.NewCounter("mcpagent_conversations_total", "Conversations started."),
+		llmCalls:      r.NewCounter("mcpagent_llm_calls_total", "LLM calls by status (success or error).", "status"),
+		llmDuration:   r.NewHistogram("mcpagent_llm_call_duration_seconds", "Duration of LLM calls.", DefaultDurationBuckets),
+		llmTokens:     r.NewCounter("mcpagent_llm_tokens_total", "Tokens used by LLM calls by type (prompt, completion, cache, reasoning).", "type"),
+		llmCost:       r.NewCounter("mcpagent_llm_cost_usd_total", "Cost of LLM calls in USD, for models with known pricing."),
+		toolCalls:     r.NewCounter("mcpagent_tool_calls_total", "Tool calls by MCP server and status (success or error).", "server", "status"),
+		toolDuration:  r.NewHistogram("mcpagent_tool_call_duration_seconds", "Duration of tool calls by MCP server.", DefaultDurationBuckets, "server"),
+		errors:        r.NewCounter("mcpagent_errors_total", "Errors by source (llm, tool, conversation, mcp_connection) and MCP server.", "source", "server"),
+		cacheLookups:  r.NewCounter("mcpagent_mcp_cache_lookups_total", "MCP server cache lookups by result (hit or miss).", "result"),
+	}
+	r.NewGaugeFunc("mcpagent_llm_cache_hit_ratio", "Share of prompt tokens read from the provider's prompt cache.", c.cacheHitRatio)
+	r.NewGaugeFunc("mcpagent_active_agents", "Agents currently alive (see SetActiveAgents).", func() float64 {
+		if fn := c.activeAgents.Load(); fn != nil {
+			return float64((*fn)())
+		}
+		return 0
+	})
+	return c
+}
+
+// SetActiveAgents sets the function mcpagent_active_agents is read from at
+// scrape time, e.g. the number of agents a server manages
+func (c *Collector) SetActiveAgents(fn func() int) {
+	c.activeAgents.Store(&fn)
+}
+
+// Registry returns the registry the metrics are rendered from, for
+// registering additional metrics next to them
+func (c *Collector) Registry() *Registry {
+	return c.registry
+}
+
+// Handler serves the metrics for Prometheus to scrape
+func (c *Collector) Handler() http.Handler {
+	return c.registry.Handler()
+}
+
+// Name implements the agent event listener interface
+func (c *Collector) Name() string {
+	return "metrics-collector"
+}
+
+// HandleEvent implements the agent event listener interface
+func (c *Collector) HandleEvent(_ context.Context, event *events.AgentEvent) error {
+	if event == nil {
+		return nil
+	}
+	switch data := event.Data.(type) {
+	case *events.ConversationStartEvent:
+		c.conversations.Inc()
+	case *events.ConversationErrorEvent:
+		c.errors.Inc(ErrorSourceConversation, "")
+	case *events.LLMGenerationEndEvent:
+		c.llmCalls.Inc("success")
+		c.llmDuration.Observe(data.Duration.Seconds())
+		c.llmTokens.Add(float64(data.UsageMetrics.PromptTokens), "prompt")
+		c.llmTokens.Add(float64(data.UsageMetrics.CompletionTokens), "completion")
+		c.llmTokens.Add(float64(data.UsageMetrics.CacheTokens), "cache")
+		c.llmTokens.Add(float64(data.UsageMetrics.ReasoningTokens), "reasoning")
+		if cost, ok := data.Metadata["call_cost_usd"].(float64); ok {
+			c.llmCost.Add(cost)
+		}
+	case *events.LLMGenerationErrorEvent:
+		c.llmCalls.Inc("error")
+		c.llmDuration.Observe(data.Duration.Seconds())
+		c.errors.Inc(ErrorSourceLLM, "")
+	case *events.ToolCallEndEvent:
+		c.toolCalls.Inc(data.ServerName, "success")
+		c.toolDuration.Observe(data.Duration.Seconds(), data.ServerName)
+	case *events.ToolCallErrorEvent:
+		c.toolCalls.Inc(data.ServerName, "error")
+		c.toolDuration.Observe(data.Duration.Seconds(), data.ServerName)
+		c.errors.Inc(ErrorSourceTool, data.ServerName)
+	case *events.MCPServerConnectionEvent:
+		if data.Error != "" {
+			c.errors.Inc(ErrorSourceConnection, data.ServerName)
+		}
+	case *events.CacheEvent:
+		if data.Operation == "hit" || data.Operation == "miss" {
+			c.cacheLookups.Inc(data.Operation)
+		}
+	}
+	return nil
+}
+
+// cacheHitRatio is the share of prompt tokens served from the prompt cache
+func (c *Collector) cacheHitRatio() float64 {
+	prompt := c.llmTokens.Value("prompt")
+	if prompt == 0 {
+		return 0
+	}
+	return min(c.llmTokens.Value("cache")/prompt, 1)
+}
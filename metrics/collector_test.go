@@ -0,0 +1,81 @@
+package metrics
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/manishiitg/mcpagent/events"
+)
+
+func emit(t *testing.T, c *Collector, data events.EventData) {
+	t.Helper()
+	if err := c.HandleEvent(context.Background(), events.NewAgentEvent(data)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func scrape(t *testing.T, c *Collector) string {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	c.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	return rec.Body.String()
+}
+
+func TestCollectorDerivesMetricsFromEvents(t *testing.T) {
+	c := NewCollector()
+	c.SetActiveAgents(func() int { return 3 })
+
+	emit(t, c, events.NewConversationStartEvent("hi", "", 0, ""))
+	end := events.NewLLMGenerationEndEvent(1, "ok", 1, 2*time.Second, events.UsageMetrics{PromptTokens: 100, CompletionTokens: 20, CacheTokens: 40})
+	end.Metadata = map[string]interface{}{"call_cost_usd": 0.5}
+	emit(t, c, end)
+	emit(t, c, events.NewLLMGenerationErrorEvent(2, "gpt-4.1", "throttled", time.Second))
+	emit(t, c, events.NewToolCallEndEvent(1, "search", "done", "github", 300*time.Millisecond, ""))
+	emit(t, c, events.NewToolCallErrorEvent(1, "search", "boom", "github", time.Second))
+	emit(t, c, events.NewCacheHitEvent("github", "key", "config.json", 3, time.Minute))
+
+	out := scrape(t, c)
+	for _, want := range []string{
+		"# TYPE mcpagent_llm_calls_total counter",
+		`mcpagent_llm_calls_total{status="error"} 1`,
+		`mcpagent_llm_calls_total{status="success"} 1`,
+		`mcpagent_llm_tokens_total{type="prompt"} 100`,
+		`mcpagent_llm_tokens_total{type="cache"} 40`,
+		"mcpagent_llm_cost_usd_total 0.5",
+		"mcpagent_llm_call_duration_seconds_bucket{le=\"2.5\"} 2",
+		"mcpagent_llm_call_duration_seconds_count 2",
+		`mcpagent_tool_calls_total{server="github",status="error"} 1`,
+		`mcpagent_tool_call_duration_seconds_bucket{server="github",le="0.5"} 1`,
+		`mcpagent_tool_call_duration_seconds_bucket{server="github",le="+Inf"} 2`,
+		`mcpagent_errors_total{source="llm",server=""} 1`,
+		`mcpagent_errors_total{source="tool",server="github"} 1`,
+		`mcpagent_mcp_cache_lookups_total{result="hit"} 1`,
+		"mcpagent_llm_cache_hit_ratio 0.4",
+		"mcpagent_active_agents 3",
+		"mcpagent_conversations_total 1",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in:\n%s", want, out)
+		}
+	}
+}
+
+func TestRegistryEscapesLabelValues(t *testing.T) {
+	r := NewRegistry()
+	c := r.NewCounter("test_total", "Test counter.", "name")
+	c.Inc("a\"b\\c\nd")
+
+	var sb strings.Builder
+	if _, err := r.WriteTo(&sb); err != nil {
+		t.Fatal(err)
+	}
+	if want := `test_total{name="a\"b\\c\nd"} 1`; !strings.Contains(sb.String(), want) {
+		t.Errorf("got:\n%s\nwant line %s", sb.String(), want)
+	}
+}
@@ -208,7 +208,7 @@ type LangfuseUsage struct {
 // langfuseEvent represents an event for the ingestion API
 type langfuseEvent struct {
 	ID        string                 `json:"id"`
-	Type      string                 `json:"type"` // "trace-create", "span-create", "span-update", "generation-create", "generation-update"
+	Type      string                 `json:"type"` // "trace-create", "span-create", "span-update", "generation-create", "generation-update", "score-create"
 	Timestamp time.Time              `json:"timestamp"`
 	Body      interface{}            `json:"body"`
 	Metadata  map[string]interface{} `json:"metadata,omitempty"`
@@ -544,6 +544,54 @@ func (l *LangfuseTracer) cleanupTrace(traceID TraceID) {
 	// based on their timestamps.
 }
 
+// langfuseScore represents a score in Langfuse v2 API format
+type langfuseScore struct {
+	ID       string  `json:"id"`
+	TraceID  string  `json:"traceId"`
+	Name     string  `json:"name"`
+	Value    float64 `json:"value"`
+	DataType string  `json:"dataType"`
+	Comment  string  `json:"comment,omitempty"`
+}
+
+// Score attaches a numeric score to a trace, e.g. user feedback (1 for
+// thumbs-up, 0 for thumbs-down) or an evaluation result. The trace may have
+// ended already; the score is sent with the next batch.
+func (l *LangfuseTracer) Score(traceID TraceID, name string, value float64, comment string) error {
+	if traceID == "" {
+		return errors.New("langfuse score: trace ID is required")
+	}
+	if name == "" {
+		return errors.New("langfuse score: name is required")
+	}
+
+	event := &langfuseEvent{
+		ID:        generateID(),
+		Type:      "score-create",
+		Timestamp: time.Now(),
+		Body: &langfuseScore{
+			ID:       generateID(),
+			TraceID:  string(traceID),
+			Name:     name,
+			Value:    value,
+			DataType: "NUMERIC",
+			Comment:  comment,
+		},
+	}
+
+	select {
+	case l.eventQueue <- event:
+	default:
+		return errors.New("langfuse score: event queue full, score dropped")
+	}
+
+	l.getV2Logger().Info("Langfuse: Queued score",
+		loggerv2.String("trace_id", string(traceID)),
+		loggerv2.String("name", name),
+		loggerv2.Any("value", value))
+	return nil
+}
+
 // CreateGenerationSpan creates a generation span for LLM calls
 func (l *LangfuseTracer) CreateGenerationSpan(traceID TraceID, parentID SpanID, name, model string, input interface{}) SpanID {
 	id := generateID()
@@ -0,0 +1,31 @@
+//go:build !langfuse_disabled
+
+package observability
+
+import (
+	"testing"
+
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+)
+
+func TestLangfuseScoreQueuesScoreEvent(t *testing.T) {
+	l := &LangfuseTracer{eventQueue: make(chan *langfuseEvent, 1), logger: loggerv2.NewNoop()}
+	if err := l.Score("trace-1", "user_feedback", 1, "spot on"); err != nil {
+		t.Fatal(err)
+	}
+	event := <-l.eventQueue
+	score, ok := event.Body.(*langfuseScore)
+	if event.Type != "score-create" || !ok {
+		t.Fatalf("queued %s event with body %T", event.Type, event.Body)
+	}
+	if score.TraceID != "trace-1" || score.Name != "user_feedback" || score.Value != 1 || score.DataType != "NUMERIC" || score.Comment != "spot on" {
+		t.Errorf("score = %+v", score)
+	}
+
+	if err := l.Score("", "user_feedback", 1, ""); err == nil {
+		t.Error("score without trace ID accepted")
+	}
+	if err := l.Score("trace-1", "", 1, ""); err == nil {
+		t.Error("score without name accepted")
+	}
+}
@@ -0,0 +1,199 @@
+package observability
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+)
+
+// maxDeferredEvents bounds how many events an unsampled conversation keeps
+// in memory while waiting to see whether an error forces it to be traced.
+const maxDeferredEvents = 1000
+
+// Sampling decision reasons recorded on ConversationStart events.
+const (
+	SamplingReasonRate           = "rate"
+	SamplingReasonTenantOverride = "tenant_override"
+	SamplingReasonError          = "error"
+)
+
+// errorEventTypes are the event types that promote an unsampled conversation
+// to sampled when SamplingConfig.AlwaysSampleOnError is set.
+var errorEventTypes = map[string]bool{
+	"conversation_error":   true,
+	"llm_generation_error": true,
+	"tool_call_error":      true,
+	"agent_error":          true,
+}
+
+// SamplingConfig controls what fraction of conversations a tracer records.
+type SamplingConfig struct {
+	// Rate is the probability (0.0-1.0) that a conversation is traced.
+	Rate float64
+	// AlwaysSampleOnError traces an unsampled conversation anyway once it
+	// emits an error event. Events emitted before the error are replayed.
+	AlwaysSampleOnError bool
+	// TenantRates overrides Rate for specific tenants (keyed by user ID).
+	TenantRates map[string]float64
+}
+
+// SamplingDecision is the outcome of sampling a single conversation.
+type SamplingDecision struct {
+	Sampled bool    `json:"sampled"`
+	Rate    float64 `json:"rate"`
+	Reason  string  `json:"reason"`
+}
+
+// rateFor returns the effective sampling rate for tenantID and the reason
+// it applies, clamped to [0, 1].
+func (c SamplingConfig) rateFor(tenantID string) (float64, string) {
+	rate, reason := c.Rate, SamplingReasonRate
+	if tenantID != "" {
+		if r, ok := c.TenantRates[tenantID]; ok {
+			rate, reason = r, SamplingReasonTenantOverride
+		}
+	}
+	if rate < 0 {
+		rate = 0
+	}
+	if rate > 1 {
+		rate = 1
+	}
+	return rate, reason
+}
+
+// SamplingTracer wraps a Tracer and forwards only sampled conversations.
+// The agent calls BeginConversation at the start of every conversation to
+// roll a new decision, and emits the conversation's events with the context
+// it returns, so concurrent conversations are sampled independently. Events
+// emitted outside a conversation are forwarded.
+type SamplingTracer struct {
+	base   Tracer
+	config SamplingConfig
+	random func() float64
+}
+
+// conversationSampling is the sampling state of one conversation
+type conversationSampling struct {
+	mu       sync.Mutex
+	decision SamplingDecision
+	deferred []func()
+}
+
+// samplingKey keys a tracer's conversationSampling in a context
+type samplingKey struct{ tracer *SamplingTracer }
+
+// NewSamplingTracer wraps base with per-conversation sampling.
+func NewSamplingTracer(base Tracer, config SamplingConfig) *SamplingTracer {
+	return &SamplingTracer{
+		base:   base,
+		config: config,
+		random: rand.Float64,
+	}
+}
+
+// BeginConversation rolls the sampling decision for a new conversation and
+// returns ctx carrying it; events emitted with that context follow it.
+func (s *SamplingTracer) BeginConversation(ctx context.Context, tenantID string) (context.Context, SamplingDecision) {
+	rate, reason := s.config.rateFor(tenantID)
+	conversation := &conversationSampling{decision: SamplingDecision{
+		Sampled: rate > 0 && s.random() < rate,
+		Rate:    rate,
+		Reason:  reason,
+	}}
+	return context.WithValue(ctx, samplingKey{s}, conversation), conversation.decision
+}
+
+// conversation returns the sampling state of the conversation of ctx, or
+// nil outside one
+func (s *SamplingTracer) conversation(ctx context.Context) *conversationSampling {
+	conversation, _ := ctx.Value(samplingKey{s}).(*conversationSampling)
+	return conversation
+}
+
+// Name returns the type name of the wrapped tracer.
+func (s *SamplingTracer) Name() string {
+	return fmt.Sprintf("%T", s.base)
+}
+
+// Decision returns the sampling decision for the conversation of ctx;
+// outside a conversation everything is sampled.
+func (s *SamplingTracer) Decision(ctx context.Context) SamplingDecision {
+	conversation := s.conversation(ctx)
+	if conversation == nil {
+		return SamplingDecision{Sampled: true, Rate: 1, Reason: SamplingReasonRate}
+	}
+	conversation.mu.Lock()
+	defer conversation.mu.Unlock()
+	return conversation.decision
+}
+
+// EmitEvent implements Tracer. Without a context the event belongs to no
+// conversation and is forwarded; use EmitEventContext inside one.
+func (s *SamplingTracer) EmitEvent(event AgentEvent) error {
+	return s.base.EmitEvent(event)
+}
+
+// EmitEventContext implements ContextTracer, dropping or deferring events of
+// unsampled conversations.
+func (s *SamplingTracer) EmitEventContext(ctx context.Context, event AgentEvent) error {
+	conversation := s.conversation(ctx)
+	if conversation == nil {
+		return EmitEvent(ctx, s.base, event)
+	}
+	conversation.mu.Lock()
+	if !conversation.decision.Sampled {
+		if !s.config.AlwaysSampleOnError {
+			conversation.mu.Unlock()
+			return nil
+		}
+		if !errorEventTypes[event.GetType()] {
+			if len(conversation.deferred) < maxDeferredEvents {
+				conversation.deferred = append(conversation.deferred, func() { _ = EmitEvent(ctx, s.base, event) })
+			}
+			conversation.mu.Unlock()
+			return nil
+		}
+		// Error in an unsampled conversation: promote it and replay
+		// everything seen so far so the trace is complete.
+		conversation.decision.Sampled = true
+		conversation.decision.Reason = SamplingReasonError
+		deferred := conversation.deferred
+		conversation.deferred = nil
+		conversation.mu.Unlock()
+		for _, emit := range deferred {
+			emit()
+		}
+		return EmitEvent(ctx, s.base, event)
+	}
+	conversation.mu.Unlock()
+	return EmitEvent(ctx, s.base, event)
+}
+
+// EmitLLMEvent implements Tracer. LLM events carry no conversation, so they
+// are forwarded.
+func (s *SamplingTracer) EmitLLMEvent(event LLMEvent) error {
+	return s.base.EmitLLMEvent(event)
+}
+
+// StartTrace implements Tracer.
+func (s *SamplingTracer) StartTrace(name string, input interface{}) TraceID {
+	return s.base.StartTrace(name, input)
+}
+
+// EndTrace implements Tracer.
+func (s *SamplingTracer) EndTrace(traceID TraceID, output interface{}) {
+	s.base.EndTrace(traceID, output)
+}
+
+// Score implements Scorer when the wrapped tracer does. Scores are forwarded
+// whatever the sampling decision: feedback often arrives after the
+// conversation ended.
+func (s *SamplingTracer) Score(traceID TraceID, name string, value float64, comment string) error {
+	scorer, ok := s.base.(Scorer)
+	if !ok {
+		return fmt.Errorf("%s: %w", s.Name(), ErrScoresNotSupported)
+	}
+	return scorer.Score(traceID, name, value, comment)
+}
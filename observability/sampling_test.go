@@ -0,0 +1,166 @@
+package observability
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+type testEvent struct{ typ string }
+
+func (e testEvent) GetType() string          { return e.typ }
+func (e testEvent) GetCorrelationID() string { return "" }
+func (e testEvent) GetTimestamp() time.Time  { return time.Time{} }
+func (e testEvent) GetData() interface{}     { return nil }
+func (e testEvent) GetTraceID() string       { return "" }
+func (e testEvent) GetParentID() string      { return "" }
+
+type recordingTracer struct {
+	NoopTracer
+	mu     sync.Mutex
+	events []string
+}
+
+func (r *recordingTracer) EmitEvent(event AgentEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event.GetType())
+	return nil
+}
+
+// count returns how many forwarded events have type typ
+func (r *recordingTracer) count(typ string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n := 0
+	for _, got := range r.events {
+		if got == typ {
+			n++
+		}
+	}
+	return n
+}
+
+func TestSamplingTracerRateAndTenantOverride(t *testing.T) {
+	base := &recordingTracer{}
+	s := NewSamplingTracer(base, SamplingConfig{
+		Rate:        0.1,
+		TenantRates: map[string]float64{"vip": 1},
+	})
+	s.random = func() float64 { return 0.5 }
+
+	ctx, d := s.BeginConversation(context.Background(), "someone")
+	if d.Sampled || d.Rate != 0.1 || d.Reason != SamplingReasonRate {
+		t.Fatalf("default tenant decision = %+v, want unsampled at rate 0.1", d)
+	}
+	_ = s.EmitEventContext(ctx, testEvent{typ: "conversation_start"})
+	if len(base.events) != 0 {
+		t.Fatalf("unsampled conversation forwarded events: %v", base.events)
+	}
+
+	ctx, d = s.BeginConversation(context.Background(), "vip")
+	if !d.Sampled || d.Reason != SamplingReasonTenantOverride {
+		t.Fatalf("vip decision = %+v, want sampled via tenant override", d)
+	}
+	_ = s.EmitEventContext(ctx, testEvent{typ: "conversation_start"})
+	if len(base.events) != 1 {
+		t.Fatalf("sampled conversation forwarded %d events, want 1", len(base.events))
+	}
+
+	_ = s.EmitEvent(testEvent{typ: "outside"})
+	if base.count("outside") != 1 {
+		t.Fatalf("event outside a conversation was not forwarded: %v", base.events)
+	}
+}
+
+func TestSamplingTracerPromotesOnError(t *testing.T) {
+	base := &recordingTracer{}
+	s := NewSamplingTracer(base, SamplingConfig{Rate: 0, AlwaysSampleOnError: true})
+	s.random = func() float64 { return 0 }
+
+	ctx, d := s.BeginConversation(context.Background(), "")
+	if d.Sampled {
+		t.Fatalf("rate 0 decision = %+v, want unsampled", d)
+	}
+	_ = s.EmitEventContext(ctx, testEvent{typ: "conversation_start"})
+	_ = s.EmitEventContext(ctx, testEvent{typ: "llm_generation_start"})
+	if len(base.events) != 0 {
+		t.Fatalf("events forwarded before error: %v", base.events)
+	}
+
+	_ = s.EmitEventContext(ctx, testEvent{typ: "conversation_error"})
+	want := []string{"conversation_start", "llm_generation_start", "conversation_error"}
+	if len(base.events) != len(want) {
+		t.Fatalf("forwarded %v, want %v", base.events, want)
+	}
+	for i := range want {
+		if base.events[i] != want[i] {
+			t.Fatalf("forwarded %v, want %v", base.events, want)
+		}
+	}
+	if d := s.Decision(ctx); !d.Sampled || d.Reason != SamplingReasonError {
+		t.Fatalf("decision after error = %+v, want sampled with reason error", d)
+	}
+}
+
+func TestSamplingTracerConcurrentConversations(t *testing.T) {
+	base := &recordingTracer{}
+	s := NewSamplingTracer(base, SamplingConfig{Rate: 0.5, AlwaysSampleOnError: true})
+	rolls := []float64{0.9, 0.1} // first conversation unsampled, second sampled
+	s.random = func() float64 {
+		roll := rolls[0]
+		rolls = rolls[1:]
+		return roll
+	}
+
+	// The first conversation defers events before the second one begins
+	unsampledCtx, d := s.BeginConversation(context.Background(), "")
+	if d.Sampled {
+		t.Fatalf("first decision = %+v, want unsampled", d)
+	}
+	_ = s.EmitEventContext(unsampledCtx, testEvent{typ: "unsampled_step"})
+	sampledCtx, d := s.BeginConversation(context.Background(), "")
+	if !d.Sampled {
+		t.Fatalf("second decision = %+v, want sampled", d)
+	}
+
+	const steps = 50
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 1; i < steps; i++ {
+			_ = s.EmitEventContext(unsampledCtx, testEvent{typ: "unsampled_step"})
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < steps; i++ {
+			_ = s.EmitEventContext(sampledCtx, testEvent{typ: "sampled_step"})
+		}
+	}()
+	wg.Wait()
+
+	if n := base.count("unsampled_step"); n != 0 {
+		t.Fatalf("unsampled conversation forwarded %d events before its error", n)
+	}
+	if n := base.count("sampled_step"); n != steps {
+		t.Fatalf("sampled conversation forwarded %d events, want %d", n, steps)
+	}
+	if d := s.Decision(unsampledCtx); d.Sampled {
+		t.Fatalf("first decision changed to %+v by the second conversation", d)
+	}
+
+	// The error promotes only the first conversation and replays all its events
+	_ = s.EmitEventContext(unsampledCtx, testEvent{typ: "conversation_error"})
+	if n := base.count("unsampled_step"); n != steps {
+		t.Fatalf("replayed %d deferred events, want %d", n, steps)
+	}
+	if d := s.Decision(unsampledCtx); !d.Sampled || d.Reason != SamplingReasonError {
+		t.Fatalf("first decision after error = %+v, want sampled with reason error", d)
+	}
+	if d := s.Decision(sampledCtx); d.Reason != SamplingReasonRate {
+		t.Fatalf("second decision after first's error = %+v, want reason rate", d)
+	}
+}
@@ -1,6 +1,8 @@
 package observability
 
 import (
+	"context"
+	"errors"
 	"time"
 )
 
@@ -51,6 +53,32 @@ type Tracer interface {
 	EndTrace(traceID TraceID, output interface{})
 }
 
+// ContextTracer is implemented by tracers that handle an event according to
+// the conversation it was emitted in, which ctx carries (see SamplingTracer)
+type ContextTracer interface {
+	EmitEventContext(ctx context.Context, event AgentEvent) error
+}
+
+// EmitEvent sends event to tracer, with ctx when tracer is a ContextTracer
+func EmitEvent(ctx context.Context, tracer Tracer, event AgentEvent) error {
+	if ct, ok := tracer.(ContextTracer); ok {
+		return ct.EmitEventContext(ctx, event)
+	}
+	return tracer.EmitEvent(event)
+}
+
+// ErrScoresNotSupported is returned by Score when the underlying tracer cannot
+// attach scores to traces
+var ErrScoresNotSupported = errors.New("tracer does not support scores")
+
+// Scorer is implemented by tracers that can attach scores to completed
+// traces, such as user feedback (thumbs up/down) or evaluation results
+type Scorer interface {
+	// Score attaches a numeric score called name to the trace, with an
+	// optional comment
+	Score(traceID TraceID, name string, value float64, comment string) error
+}
+
 // NoopTracer is a tracer that does nothing
 type NoopTracer struct{}
 
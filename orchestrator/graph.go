@@ -0,0 +1,145 @@
+package orchestrator
+
+import (
+	"fmt"
+	"strings"
+
+	mcpagent "github.com/manishiitg/mcpagent/agent"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+// Task is a node of a Graph. Each task runs on its own sub-agent of the
+// coordinator (see mcpagent.Agent.SpawnSubAgent), so it can use a different
+// model and a restricted tool set; zero values inherit from the coordinator.
+type Task struct {
+	// ID names the task in DependsOn, results and events (required, unique)
+	ID string
+	// DependsOn lists the tasks that must complete before this one starts.
+	// If one of them fails, this task is skipped.
+	DependsOn []string
+
+	// Prompt is the instruction for the agent. Unless BuildPrompt is set, the
+	// answers of the direct dependencies are appended to it.
+	Prompt string
+	// BuildPrompt builds the instruction from the shared context instead,
+	// e.g. from the values of a Channel
+	BuildPrompt func(s *Shared) (string, error)
+	// Publish is called with the answer after the task completed, e.g. to
+	// send typed values to a Channel. An error fails the task.
+	Publish func(s *Shared, answer string) error
+
+	// LLM runs the task on a different model (pass mcpagent.WithProvider in
+	// Options when the provider differs)
+	LLM llmtypes.Model
+	// Tools restricts the agent to these tool names
+	Tools []string
+	// Servers restricts the MCP servers the agent connects to
+	Servers []string
+	// SystemPrompt replaces the default system prompt
+	SystemPrompt string
+	// MaxTurns bounds the agent's turns
+	MaxTurns int
+	// Options are applied to the agent after the inherited settings
+	Options []mcpagent.AgentOption
+}
+
+// Graph is a validated DAG of tasks, e.g. research tasks fanning out from a
+// plan and joined by a synthesis task:
+//
+//	graph, err := orchestrator.NewGraph(
+//	    orchestrator.Task{ID: "docs", Prompt: "Research the API docs", Tools: []string{"fetch"}},
+//	    orchestrator.Task{ID: "issues", Prompt: "Research open issues", Servers: []string{"github"}},
+//	    orchestrator.Task{ID: "report", Prompt: "Write the migration guide", DependsOn: []string{"docs", "issues"}, LLM: strongLLM},
+//	)
+//	run, err := graph.Run(ctx, coordinator)
+//	fmt.Println(run.Answer("report"))
+type Graph struct {
+	// MaxParallel bounds the tasks running at once; 0 = no limit
+	MaxParallel int
+
+	tasks []Task
+	index map[string]int
+}
+
+// NewGraph validates tasks and returns their graph. It fails for missing or
+// duplicate IDs, unknown dependencies and cycles.
+func NewGraph(tasks ...Task) (*Graph, error) {
+	if len(tasks) == 0 {
+		return nil, fmt.Errorf("task graph has no tasks")
+	}
+	g := &Graph{tasks: tasks, index: make(map[string]int, len(tasks))}
+	for i, task := range tasks {
+		if strings.TrimSpace(task.ID) == "" {
+			return nil, fmt.Errorf("task %d has no ID", i)
+		}
+		if _, dup := g.index[task.ID]; dup {
+			return nil, fmt.Errorf("duplicate task ID %q", task.ID)
+		}
+		if task.Prompt == "" && task.BuildPrompt == nil {
+			return nil, fmt.Errorf("task %q has no prompt", task.ID)
+		}
+		g.index[task.ID] = i
+	}
+	for _, task := range tasks {
+		for _, dep := range task.DependsOn {
+			if _, ok := g.index[dep]; !ok {
+				return nil, fmt.Errorf("task %q depends on unknown task %q", task.ID, dep)
+			}
+		}
+	}
+	if cycle := g.findCycle(); cycle != nil {
+		return nil, fmt.Errorf("task graph has a cycle: %s", strings.Join(cycle, " -> "))
+	}
+	return g, nil
+}
+
+// Tasks returns the IDs of the tasks in the order they were given
+func (g *Graph) Tasks() []string {
+	ids := make([]string, len(g.tasks))
+	for i, task := range g.tasks {
+		ids[i] = task.ID
+	}
+	return ids
+}
+
+// findCycle returns the task IDs of a dependency cycle, or nil
+func (g *Graph) findCycle() []string {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make([]int, len(g.tasks))
+	var path []string
+	var visit func(i int) []string
+	visit = func(i int) []string {
+		state[i] = visiting
+		path = append(path, g.tasks[i].ID)
+		for _, dep := range g.tasks[i].DependsOn {
+			j := g.index[dep]
+			switch state[j] {
+			case visiting:
+				for k, id := range path {
+					if id == dep {
+						return append(append([]string{}, path[k:]...), dep)
+					}
+				}
+			case unvisited:
+				if cycle := visit(j); cycle != nil {
+					return cycle
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		state[i] = visited
+		return nil
+	}
+	for i := range g.tasks {
+		if state[i] == unvisited {
+			if cycle := visit(i); cycle != nil {
+				return cycle
+			}
+		}
+	}
+	return nil
+}
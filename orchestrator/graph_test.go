@@ -0,0 +1,205 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	mcpagent "github.com/manishiitg/mcpagent/agent"
+	"github.com/manishiitg/mcpagent/events"
+	"github.com/manishiitg/mcpagent/observability"
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+// echoModel answers with its ID and the first line of the last user message
+type echoModel struct {
+	id  string
+	err error
+}
+
+func (m *echoModel) GenerateContent(_ context.Context, messages []llmtypes.MessageContent, _ ...llmtypes.CallOption) (*llmtypes.ContentResponse, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	question := ""
+	for _, msg := range messages {
+		if msg.Role != llmtypes.ChatMessageTypeHuman {
+			continue
+		}
+		for _, part := range msg.Parts {
+			if text, ok := part.(llmtypes.TextContent); ok {
+				question = text.Text
+			}
+		}
+	}
+	return &llmtypes.ContentResponse{Choices: []*llmtypes.ContentChoice{{Content: m.id + ": " + question}}}, nil
+}
+
+func (m *echoModel) GetModelID() string {
+	return m.id
+}
+
+func (m *echoModel) GetModelMetadata(string) (*llmtypes.ModelMetadata, error) {
+	return nil, errors.New("no metadata")
+}
+
+// directGenerate calls the agent's model without provider initialization
+type directGenerate struct{}
+
+func (directGenerate) Generate(ctx context.Context, a *mcpagent.Agent, messages []llmtypes.MessageContent, _ []llmtypes.CallOption, _ int) (*llmtypes.ContentResponse, observability.UsageMetrics, error) {
+	resp, err := a.LLM.GenerateContent(ctx, messages)
+	return resp, observability.UsageMetrics{}, err
+}
+
+// direct makes a task run on directGenerate
+func direct(task Task) Task {
+	task.Options = append(task.Options, mcpagent.WithAskPipeline(mcpagent.AskPipeline{Generate: directGenerate{}}))
+	return task
+}
+
+type recordingListener struct {
+	mu     sync.Mutex
+	events []*events.AgentEvent
+}
+
+func (l *recordingListener) HandleEvent(_ context.Context, event *events.AgentEvent) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.events = append(l.events, event)
+	return nil
+}
+
+func (l *recordingListener) Name() string {
+	return "recording-listener"
+}
+
+func newCoordinator(t *testing.T) *mcpagent.Agent {
+	t.Helper()
+	config := filepath.Join(t.TempDir(), "mcp.json")
+	if err := os.WriteFile(config, []byte(`{"mcpServers": {}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	coordinator, err := mcpagent.NewAgent(context.Background(), &echoModel{id: "coordinator"}, config,
+		mcpagent.WithLogger(loggerv2.NewNoop()), mcpagent.WithSessionID("orchestrator-test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(coordinator.Close)
+	return coordinator
+}
+
+func TestNewGraphValidates(t *testing.T) {
+	for name, tasks := range map[string][]Task{
+		"no tasks":         nil,
+		"missing ID":       {{Prompt: "p"}},
+		"duplicate ID":     {{ID: "a", Prompt: "p"}, {ID: "a", Prompt: "p"}},
+		"no prompt":        {{ID: "a"}},
+		"unknown dep":      {{ID: "a", Prompt: "p", DependsOn: []string{"b"}}},
+		"dependency cycle": {{ID: "a", Prompt: "p", DependsOn: []string{"c"}}, {ID: "b", Prompt: "p", DependsOn: []string{"a"}}, {ID: "c", Prompt: "p", DependsOn: []string{"b"}}},
+	} {
+		if _, err := NewGraph(tasks...); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+	_, err := NewGraph(Task{ID: "a", Prompt: "p", DependsOn: []string{"b"}}, Task{ID: "b", Prompt: "p", DependsOn: []string{"a"}})
+	if err == nil || !strings.Contains(err.Error(), "a -> b -> a") && !strings.Contains(err.Error(), "b -> a -> b") {
+		t.Errorf("cycle error = %v", err)
+	}
+}
+
+func TestGraphRunFansOutAndJoins(t *testing.T) {
+	coordinator := newCoordinator(t)
+	listener := &recordingListener{}
+	coordinator.AddEventListener(listener)
+
+	lengths := NewChannel[int]("lengths")
+	graph, err := NewGraph(
+		direct(Task{ID: "docs", Prompt: "read the docs", LLM: &echoModel{id: "fast"}}),
+		direct(Task{ID: "issues", Prompt: "read the issues", Publish: func(s *Shared, answer string) error {
+			lengths.Send(s, len(answer))
+			return nil
+		}}),
+		direct(Task{ID: "report", Prompt: "write the report", DependsOn: []string{"docs", "issues"}}),
+		direct(Task{ID: "count", DependsOn: []string{"issues"}, BuildPrompt: func(s *Shared) (string, error) {
+			n, ok := lengths.Last(s)
+			if !ok {
+				return "", errors.New("no length published")
+			}
+			return strings.Repeat("x", n), nil
+		}}),
+		direct(Task{ID: "broken", Prompt: "fail", LLM: &echoModel{id: "down", err: errors.New("provider down")}}),
+		direct(Task{ID: "after-broken", Prompt: "never runs", DependsOn: []string{"broken"}}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	graph.MaxParallel = 2
+
+	run, err := graph.Run(context.Background(), coordinator)
+	if err == nil || !strings.Contains(err.Error(), "task broken") {
+		t.Fatalf("Run error = %v, want the broken task's error", err)
+	}
+
+	if got := run.Answer("docs"); got != "fast: read the docs" {
+		t.Errorf("docs answer = %q, want it from the task's own model", got)
+	}
+	report := run.Answer("report")
+	if !strings.HasPrefix(report, "coordinator: write the report") {
+		t.Errorf("report answer = %q", report)
+	}
+	if got := run.Answer("count"); got != "coordinator: "+strings.Repeat("x", len(run.Answer("issues"))) {
+		t.Errorf("count answer = %q, want the length sent on the channel", got)
+	}
+	if r := run.Results["broken"]; r.Status != TaskFailed {
+		t.Errorf("broken = %+v", r)
+	}
+	if r := run.Results["after-broken"]; r.Status != TaskSkipped || !errors.Is(r.Err, ErrDependencyFailed) {
+		t.Errorf("after-broken = %+v", r)
+	}
+
+	listener.mu.Lock()
+	defer listener.mu.Unlock()
+	first, last := listener.events[0], listener.events[len(listener.events)-1]
+	if first.Type != events.OrchestratorStart || last.Type != events.OrchestratorEnd {
+		t.Fatalf("run framed by %s ... %s, want orchestrator_start ... orchestrator_end", first.Type, last.Type)
+	}
+	ended := last.Data.(*events.OrchestratorEndEvent)
+	if len(ended.Completed) != 4 || len(ended.Failed) != 1 || len(ended.Skipped) != 1 {
+		t.Errorf("orchestrator_end = %+v", ended)
+	}
+	steps := 0
+	for _, event := range listener.events[1 : len(listener.events)-1] {
+		if event.HierarchyLevel <= first.HierarchyLevel {
+			t.Errorf("%s at level %d is not nested below orchestrator_start (level %d)", event.Type, event.HierarchyLevel, first.HierarchyLevel)
+		}
+		if event.Type == events.StepExecutionStart {
+			steps++
+		}
+	}
+	if steps != 5 {
+		t.Errorf("got %d step_execution_start events, want 5 (one per task that ran)", steps)
+	}
+}
+
+func TestGraphRunStopsWhenCancelled(t *testing.T) {
+	coordinator := newCoordinator(t)
+	graph, err := NewGraph(direct(Task{ID: "a", Prompt: "p"}), direct(Task{ID: "b", Prompt: "p", DependsOn: []string{"a"}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	run, err := graph.Run(ctx, coordinator)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Run error = %v, want context.Canceled", err)
+	}
+	if r := run.Results["b"]; r.Status == TaskCompleted {
+		t.Errorf("b = %+v, want it not to run", r)
+	}
+}
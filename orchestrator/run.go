@@ -0,0 +1,270 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	mcpagent "github.com/manishiitg/mcpagent/agent"
+	"github.com/manishiitg/mcpagent/events"
+	loggerv2 "github.com/manishiitg/mcpagent/logger/v2"
+)
+
+// TaskStatus is the outcome of a task
+type TaskStatus string
+
+const (
+	TaskCompleted TaskStatus = "completed"
+	TaskFailed    TaskStatus = "failed"
+	// TaskSkipped means a dependency failed or the run was cancelled first
+	TaskSkipped TaskStatus = "skipped"
+)
+
+// ErrDependencyFailed is the error of tasks skipped because a dependency failed
+var ErrDependencyFailed = errors.New("dependency failed")
+
+// TaskResult is the outcome of one task
+type TaskResult struct {
+	TaskID string
+	Status TaskStatus
+	Answer string
+	Err    error
+	// SubAgent is the sub-agent run of the task; nil for skipped tasks
+	SubAgent *mcpagent.SubAgentResult
+	Duration time.Duration
+}
+
+// Run is the outcome of a task graph run
+type Run struct {
+	ID       string
+	Results  map[string]*TaskResult
+	Shared   *Shared
+	Duration time.Duration
+}
+
+// Answer returns the answer of a task, or "" if it did not complete
+func (r *Run) Answer(taskID string) string {
+	if result, ok := r.Results[taskID]; ok {
+		return result.Answer
+	}
+	return ""
+}
+
+// Run executes the graph on sub-agents of coordinator. A task starts as soon
+// as its dependencies completed, so independent tasks run in parallel (up to
+// MaxParallel). Tasks whose dependencies failed are skipped; the others still
+// run.
+//
+// The run is traced as one event tree on the coordinator: orchestrator_start,
+// then per task step_execution_start, the sub-agent's events and
+// step_execution_end (or step_execution_failed), then orchestrator_end. Token
+// usage and cost roll up into the coordinator's totals.
+//
+// The returned error joins the task errors; the Run is returned either way.
+func (g *Graph) Run(ctx context.Context, coordinator *mcpagent.Agent) (*Run, error) {
+	run := &Run{
+		ID:      "orch_" + events.GenerateEventID(),
+		Results: make(map[string]*TaskResult, len(g.tasks)),
+		Shared:  newShared(),
+	}
+	startTime := time.Now()
+	coordinator.EmitTypedEvent(ctx, &events.OrchestratorStartEvent{
+		BaseEventData:   events.BaseEventData{Timestamp: startTime},
+		OrchestrationID: run.ID,
+		Tasks:           g.Tasks(),
+	})
+	coordinator.Logger.Info("🕸️ [ORCHESTRATOR] Running task graph",
+		loggerv2.String("orchestration_id", run.ID),
+		loggerv2.Int("tasks", len(g.tasks)),
+		loggerv2.Int("max_parallel", g.MaxParallel))
+
+	var slots chan struct{}
+	if g.MaxParallel > 0 {
+		slots = make(chan struct{}, g.MaxParallel)
+	}
+	done := make([]chan struct{}, len(g.tasks))
+	for i := range done {
+		done[i] = make(chan struct{})
+	}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for i := range g.tasks {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer close(done[i])
+			task := g.tasks[i]
+			result := g.runTask(ctx, coordinator, run, task, done, slots, &mu)
+			mu.Lock()
+			run.Results[task.ID] = result
+			mu.Unlock()
+		}(i)
+	}
+	wg.Wait()
+	run.Duration = time.Since(startTime)
+
+	end := &events.OrchestratorEndEvent{
+		BaseEventData:   events.BaseEventData{Timestamp: time.Now()},
+		OrchestrationID: run.ID,
+		Duration:        run.Duration,
+	}
+	var errs []error
+	for _, task := range g.tasks {
+		result := run.Results[task.ID]
+		switch result.Status {
+		case TaskCompleted:
+			end.Completed = append(end.Completed, task.ID)
+		case TaskFailed:
+			end.Failed = append(end.Failed, task.ID)
+			errs = append(errs, fmt.Errorf("task %s: %w", task.ID, result.Err))
+		case TaskSkipped:
+			end.Skipped = append(end.Skipped, task.ID)
+		}
+		if result.SubAgent != nil {
+			end.TotalTokens += result.SubAgent.TotalTokens
+			end.TotalCost += result.SubAgent.TotalCost
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		errs = append(errs, err)
+	}
+	// Listeners still need the end of a cancelled run
+	coordinator.EmitTypedEvent(context.WithoutCancel(ctx), end)
+	coordinator.Logger.Info("🕸️ [ORCHESTRATOR] Task graph finished",
+		loggerv2.String("orchestration_id", run.ID),
+		loggerv2.Int("completed", len(end.Completed)),
+		loggerv2.Int("failed", len(end.Failed)),
+		loggerv2.Int("skipped", len(end.Skipped)),
+		loggerv2.String("duration", run.Duration.String()))
+	return run, errors.Join(errs...)
+}
+
+// runTask waits for the task's dependencies and runs it
+func (g *Graph) runTask(ctx context.Context, coordinator *mcpagent.Agent, run *Run, task Task, done []chan struct{}, slots chan struct{}, mu *sync.Mutex) *TaskResult {
+	for _, dep := range task.DependsOn {
+		select {
+		case <-done[g.index[dep]]:
+		case <-ctx.Done():
+			return g.skip(ctx, coordinator, run, task, ctx.Err())
+		}
+	}
+	mu.Lock()
+	for _, dep := range task.DependsOn {
+		if run.Results[dep].Status != TaskCompleted {
+			mu.Unlock()
+			return g.skip(ctx, coordinator, run, task, fmt.Errorf("%w: %s", ErrDependencyFailed, dep))
+		}
+	}
+	mu.Unlock()
+
+	if slots != nil {
+		select {
+		case slots <- struct{}{}:
+			defer func() { <-slots }()
+		case <-ctx.Done():
+			return g.skip(ctx, coordinator, run, task, ctx.Err())
+		}
+	}
+
+	startTime := time.Now()
+	modelID := coordinator.ModelID
+	if task.LLM != nil {
+		modelID = task.LLM.GetModelID()
+	}
+	coordinator.EmitTypedEvent(ctx, &events.StepExecutionStartEvent{
+		BaseEventData:   events.BaseEventData{Timestamp: startTime},
+		OrchestrationID: run.ID,
+		StepID:          task.ID,
+		DependsOn:       task.DependsOn,
+		ModelID:         modelID,
+	})
+
+	result := &TaskResult{TaskID: task.ID, Status: TaskFailed}
+	fail := func(err error) *TaskResult {
+		result.Err = err
+		result.Duration = time.Since(startTime)
+		failed := &events.StepExecutionFailedEvent{
+			BaseEventData:   events.BaseEventData{Timestamp: time.Now()},
+			OrchestrationID: run.ID,
+			StepID:          task.ID,
+			Error:           err.Error(),
+			Duration:        result.Duration,
+		}
+		if result.SubAgent != nil {
+			failed.SubAgentID = result.SubAgent.ID
+		}
+		coordinator.EmitTypedEvent(ctx, failed)
+		return result
+	}
+
+	prompt, err := g.prompt(task, run.Shared)
+	if err != nil {
+		return fail(fmt.Errorf("build prompt: %w", err))
+	}
+	subAgent, err := coordinator.SpawnSubAgent(ctx, mcpagent.SubAgentOptions{
+		Task:         prompt,
+		LLM:          task.LLM,
+		SystemPrompt: task.SystemPrompt,
+		Tools:        task.Tools,
+		Servers:      task.Servers,
+		MaxTurns:     task.MaxTurns,
+		Options:      task.Options,
+	})
+	result.SubAgent = subAgent
+	if err != nil {
+		return fail(err)
+	}
+	if task.Publish != nil {
+		if err := task.Publish(run.Shared, subAgent.Answer); err != nil {
+			return fail(fmt.Errorf("publish: %w", err))
+		}
+	}
+	run.Shared.setAnswer(task.ID, subAgent.Answer)
+
+	result.Status = TaskCompleted
+	result.Answer = subAgent.Answer
+	result.Duration = time.Since(startTime)
+	coordinator.EmitTypedEvent(ctx, &events.StepExecutionEndEvent{
+		BaseEventData:   events.BaseEventData{Timestamp: time.Now()},
+		OrchestrationID: run.ID,
+		StepID:          task.ID,
+		SubAgentID:      subAgent.ID,
+		Duration:        result.Duration,
+		TotalTokens:     subAgent.TotalTokens,
+		TotalCost:       subAgent.TotalCost,
+	})
+	return result
+}
+
+// skip records a task that did not run
+func (g *Graph) skip(ctx context.Context, coordinator *mcpagent.Agent, run *Run, task Task, reason error) *TaskResult {
+	coordinator.EmitTypedEvent(ctx, &events.StepExecutionFailedEvent{
+		BaseEventData:   events.BaseEventData{Timestamp: time.Now()},
+		OrchestrationID: run.ID,
+		StepID:          task.ID,
+		Error:           reason.Error(),
+		Skipped:         true,
+	})
+	return &TaskResult{TaskID: task.ID, Status: TaskSkipped, Err: reason}
+}
+
+// prompt builds the instruction of a task
+func (g *Graph) prompt(task Task, shared *Shared) (string, error) {
+	if task.BuildPrompt != nil {
+		return task.BuildPrompt(shared)
+	}
+	if len(task.DependsOn) == 0 {
+		return task.Prompt, nil
+	}
+	var b strings.Builder
+	b.WriteString(task.Prompt)
+	b.WriteString("\n\n## Results of earlier tasks\n")
+	for _, dep := range task.DependsOn {
+		answer, _ := shared.Answer(dep)
+		fmt.Fprintf(&b, "\n### %s\n%s\n", dep, answer)
+	}
+	return b.String(), nil
+}
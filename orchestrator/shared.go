@@ -0,0 +1,95 @@
+package orchestrator
+
+import "sync"
+
+// Shared is the context the tasks of one run share: the answers of completed
+// tasks and the values sent to channels. A task is guaranteed to see what the
+// tasks it depends on, directly or transitively, published; values from other
+// tasks may or may not have arrived yet.
+type Shared struct {
+	mu       sync.RWMutex
+	answers  map[string]string
+	channels map[string][]any
+}
+
+func newShared() *Shared {
+	return &Shared{
+		answers:  make(map[string]string),
+		channels: make(map[string][]any),
+	}
+}
+
+// Answer returns the answer of a completed task
+func (s *Shared) Answer(taskID string) (string, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	answer, ok := s.answers[taskID]
+	return answer, ok
+}
+
+func (s *Shared) setAnswer(taskID, answer string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.answers[taskID] = answer
+}
+
+// Channel passes values of type T between the tasks of a run. Fan-out tasks
+// typically Send their findings from Task.Publish and the joining task reads
+// them all with Values in Task.BuildPrompt:
+//
+//	findings := orchestrator.NewChannel[Finding]("findings")
+//	...
+//	Publish: func(s *orchestrator.Shared, answer string) error {
+//	    var f Finding
+//	    if err := json.Unmarshal([]byte(answer), &f); err != nil {
+//	        return err
+//	    }
+//	    findings.Send(s, f)
+//	    return nil
+//	},
+//
+// Channels are identified by name; use one type per name.
+type Channel[T any] struct {
+	name string
+}
+
+// NewChannel returns the channel with the given name
+func NewChannel[T any](name string) Channel[T] {
+	return Channel[T]{name: name}
+}
+
+// Name returns the channel name
+func (c Channel[T]) Name() string {
+	return c.name
+}
+
+// Send adds a value to the channel
+func (c Channel[T]) Send(s *Shared, value T) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.channels[c.name] = append(s.channels[c.name], value)
+}
+
+// Values returns the values sent to the channel so far, in send order.
+// Values sent with a different type under the same name are skipped.
+func (c Channel[T]) Values(s *Shared) []T {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	values := make([]T, 0, len(s.channels[c.name]))
+	for _, v := range s.channels[c.name] {
+		if typed, ok := v.(T); ok {
+			values = append(values, typed)
+		}
+	}
+	return values
+}
+
+// Last returns the most recent value sent to the channel
+func (c Channel[T]) Last(s *Shared) (T, bool) {
+	values := c.Values(s)
+	if len(values) == 0 {
+		var zero T
+		return zero, false
+	}
+	return values[len(values)-1], true
+}
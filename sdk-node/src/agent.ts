@@ -5,9 +5,17 @@ import type {
   AgentConfig,
   AgentAPIKeys,
   Message,
+  ConversationPriority,
+  StreamVerbosity,
   AskResponse,
   AskWithHistoryResponse,
   TokenUsageWithPricing,
+  PostMortemBundle,
+  ToolStatsReport,
+  ToolInfo,
+  ToolSchema,
+  ServerInfo,
+  PromptInfo,
   Capabilities,
   CreateAgentResponse,
 } from './types';
@@ -36,6 +44,11 @@ export type { AnyConversationEvent };
 export interface MCPAgentOptions {
   /** Options for the Go server (auto-started if not already running) */
   serverOptions?: ServerManagerOptions;
+  /**
+   * Client token or JWT for servers started with --auth-config
+   * (defaults to the MCPAGENT_AUTH_TOKEN environment variable)
+   */
+  authToken?: string;
 }
 
 /**
@@ -63,6 +76,7 @@ export class MCPAgent {
   private grpcClient: GrpcClient | null = null;
   private streamHandler: StreamHandler | null = null;
   private serverManager: ServerManager;
+  private authToken?: string;
   private agentId: string | null = null;
   private sessionId: string | null = null;
   private capabilities: Capabilities | null = null;
@@ -87,6 +101,7 @@ export class MCPAgent {
    */
   constructor(options: MCPAgentOptions = {}) {
     this.serverManager = new ServerManager(options.serverOptions);
+    this.authToken = options.authToken ?? process.env.MCPAGENT_AUTH_TOKEN;
   }
 
   /**
@@ -175,7 +190,7 @@ export class MCPAgent {
     this.serverStartedByUs = !wasRunning;
 
     // Create gRPC client
-    this.grpcClient = new GrpcClient(socketPath);
+    this.grpcClient = new GrpcClient(socketPath, this.authToken);
     this.streamHandler = new StreamHandler(this.grpcClient, this.toolHandlers);
 
     // Build custom tools for gRPC (no callback socket needed - handled via stream)
@@ -188,11 +203,9 @@ export class MCPAgent {
       category: tool.category,
     }));
 
-    const response = await this.grpcClient.createAgent(
-      this.sessionId || '',
-      config,
-      customTools
-    );
+    const response = config.preset
+      ? await this.grpcClient.createAgentFromPreset(this.sessionId || '', config.preset, config, customTools)
+      : await this.grpcClient.createAgent(this.sessionId || '', config, customTools);
 
     this.agentId = response.agentId;
     this.sessionId = response.sessionId;
@@ -281,6 +294,69 @@ export class MCPAgent {
     yield* this.streamHandler!.converse(this.agentId!, question);
   }
 
+  /**
+   * Ask a question over a server stream.
+   * Lighter than askStream(): no bidirectional stream, and tool calls arrive as
+   * typed tool_start / tool_end events. Custom tools registered with
+   * registerTool() run on the client and need askStream() instead.
+   *
+   * @param question - The question to ask
+   * @param options.history - Earlier conversation messages
+   * @param options.includeEvents - Also yield every other agent event (LLM calls, token usage, ...)
+   * @param options.priority - 'high' for interactive use, 'low' for batch work (default 'normal')
+   * @param options.requestId - Client-chosen ID to stop the request with cancelRequest()
+   * @yields Chunks, tool starts/ends and the final response (or a fatal error)
+   * @throws MCPAgentError if the agent is not initialized or the stream fails
+   *
+   * @example
+   * ```typescript
+   * for await (const event of agent.streamAsk('Summarize the open issues')) {
+   *   if (event.type === 'chunk') {
+   *     process.stdout.write(event.text);
+   *   } else if (event.type === 'tool_start') {
+   *     console.log(`\n[${event.toolName}]`);
+   *   }
+   * }
+   * ```
+   */
+  async *streamAsk(
+    question: string,
+    options: { history?: Message[]; includeEvents?: boolean; priority?: ConversationPriority; requestId?: string } = {}
+  ): AsyncGenerator<AnyConversationEvent> {
+    this.ensureInitialized();
+    yield* this.streamHandler!.askServerStream(
+      this.agentId!,
+      question,
+      options.history,
+      options.includeEvents ?? false,
+      options.priority ?? 'normal',
+      options.requestId ?? ''
+    );
+  }
+
+  /**
+   * Cancel a request started with streamAsk({ requestId }). The stream ends
+   * with a CANCELLED error and the server stops the conversation, including
+   * running tool calls.
+   *
+   * @param requestId - The ID the request was started with
+   * @param reason - Optional reason, reported in the conversation_end event
+   * @returns false if no request with this ID is running
+   *
+   * @example
+   * ```typescript
+   * const requestId = randomUUID();
+   * setTimeout(() => agent.cancelRequest(requestId, 'user pressed stop'), 5000);
+   * for await (const event of agent.streamAsk('Crawl the docs site', { requestId })) {
+   *   // ...
+   * }
+   * ```
+   */
+  async cancelRequest(requestId: string, reason: string = ''): Promise<boolean> {
+    this.ensureInitialized();
+    return this.grpcClient!.cancelRequest(requestId, reason);
+  }
+
   /**
    * Continue a multi-turn conversation with the agent.
    * Pass the full conversation history to maintain context.
@@ -331,6 +407,20 @@ export class MCPAgent {
     yield* this.streamHandler!.converse(this.agentId!, lastUserMessage.content, history);
   }
 
+  /**
+   * Watch this agent's conversations without driving them, e.g. from a second UI.
+   * Requires the server to be started with --stream-replay.
+   *
+   * @param options.replay - First deliver the events the server retained, then go live
+   * @param options.verbosity - 'full' (default), 'chunks' or 'milestones'
+   * @yields Text chunks and agent events; tool calls arrive as agent events
+   * @throws MCPAgentError if the agent is not initialized or the stream fails
+   */
+  async *watch(options: { replay?: boolean; verbosity?: StreamVerbosity } = {}): AsyncGenerator<AnyConversationEvent> {
+    this.ensureInitialized();
+    yield* this.streamHandler!.watch(this.agentId!, options.replay ?? false, options.verbosity ?? 'full');
+  }
+
   /**
    * Get the current token usage statistics with pricing.
    *
@@ -349,6 +439,92 @@ export class MCPAgent {
     return this.grpcClient!.getTokenUsage(this.agentId!);
   }
 
+  /**
+   * Get the diagnostic bundle of the last failed conversation, a zip archive
+   * with the redacted event log, recent messages, config and environment to
+   * attach to bug reports. Requires `--post-mortem-dir` on the server.
+   *
+   * @returns The bundle and where the server wrote it
+   * @throws MCPAgentError with code POST_MORTEM_NOT_FOUND if no conversation has failed
+   *
+   * @example
+   * ```typescript
+   * try {
+   *   await agent.ask('Summarize the report');
+   * } catch (err) {
+   *   const { bundle } = await agent.getPostMortemBundle();
+   *   fs.writeFileSync('postmortem.zip', bundle);
+   * }
+   * ```
+   */
+  async getPostMortemBundle(): Promise<PostMortemBundle> {
+    this.ensureInitialized();
+    return this.grpcClient!.getPostMortemBundle(this.agentId!);
+  }
+
+  /**
+   * Get per-tool and per-server call counts, error rates, p50/p95 latencies
+   * and bytes returned since the agent was created, e.g. to show which MCP
+   * servers are slow or flaky
+   *
+   * @example
+   * ```typescript
+   * const { servers } = await agent.getToolStats();
+   * for (const s of servers) {
+   *   console.log(`${s.serverName}: ${s.calls} calls, p95 ${s.p95LatencyMs}ms`);
+   * }
+   * ```
+   */
+  async getToolStats(): Promise<ToolStatsReport> {
+    this.ensureInitialized();
+    return this.grpcClient!.getToolStats(this.agentId!);
+  }
+
+  /**
+   * List the tools the agent can use right now, including custom tools and
+   * servers added by a config reload. Use this to render tool pickers.
+   *
+   * @param server - Only tools of this MCP server (or custom tool category)
+   *
+   * @example
+   * ```typescript
+   * const tools = await agent.listTools();
+   * const { inputSchema } = await agent.getToolSchema(tools[0].name);
+   * ```
+   */
+  async listTools(server?: string): Promise<ToolInfo[]> {
+    this.ensureInitialized();
+    return this.grpcClient!.listTools(this.agentId!, server);
+  }
+
+  /**
+   * Get a tool with the JSON schema of its arguments
+   *
+   * @throws MCPAgentError with code TOOL_NOT_FOUND if the agent has no such tool
+   */
+  async getToolSchema(toolName: string): Promise<ToolSchema> {
+    this.ensureInitialized();
+    return this.grpcClient!.getToolSchema(this.agentId!, toolName);
+  }
+
+  /**
+   * List the MCP servers the agent uses with their tool, prompt and resource counts
+   */
+  async listServers(): Promise<ServerInfo[]> {
+    this.ensureInitialized();
+    return this.grpcClient!.listServers(this.agentId!);
+  }
+
+  /**
+   * List the prompts offered by the agent's MCP servers
+   *
+   * @param server - Only prompts of this MCP server
+   */
+  async listPrompts(server?: string): Promise<PromptInfo[]> {
+    this.ensureInitialized();
+    return this.grpcClient!.listPrompts(this.agentId!, server);
+  }
+
   /**
    * Destroy the agent and clean up resources.
    * Always call this when done with the agent.
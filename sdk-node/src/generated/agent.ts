@@ -10,9 +10,11 @@ import {
   ChannelCredentials,
   Client,
   ClientDuplexStream,
+  type ClientReadableStream,
   type ClientOptions,
   type ClientUnaryCall,
   handleBidiStreamingCall,
+  type handleServerStreamingCall,
   type handleUnaryCall,
   makeGenericClientConstructor,
   Metadata,
@@ -29,6 +31,12 @@ export interface CreateAgentRequest {
   sessionId: string;
   /** Agent configuration */
   config?: AgentConfig | undefined;
+  /**
+   * Optional warm pool to take a pre-created agent from. Config fields that
+   * are set overlay the pool's configuration; the pool is bypassed when they
+   * change the provider, model, MCP servers or tools, or session_id is set.
+   */
+  warmPool: string;
 }
 
 export interface AgentConfig {
@@ -56,6 +64,8 @@ export interface AgentConfig {
   enableStreaming: boolean;
   /** Custom tools with handlers on client side */
   customTools: CustomToolDefinition[];
+  /** Permissions for virtual and custom tools (e.g. disable delete, scope paths) */
+  toolPermissions: ToolPermission[];
 }
 
 export interface CustomToolDefinition {
@@ -73,6 +83,32 @@ export interface CustomToolDefinition {
   category: string;
 }
 
+/** ToolPermission restricts one virtual or custom tool */
+export interface ToolPermission {
+  /** Tool name (e.g., search_large_output, git_commit) */
+  tool: string;
+  /** Hide the tool from the LLM and reject calls to it */
+  disabled: boolean;
+  /** Folders the tool's path arguments must stay within (empty = unrestricted) */
+  paths: string[];
+}
+
+export interface CreateAgentFromPresetRequest {
+  /** Optional session ID (auto-generated if empty) */
+  sessionId: string;
+  /** Preset name */
+  preset: string;
+  /** Values for {{NAME}} placeholders in the preset's system prompt template */
+  variables: { [key: string]: string };
+  /** Optional overrides; set fields take precedence over the preset */
+  config?: AgentConfig | undefined;
+}
+
+export interface CreateAgentFromPresetRequest_VariablesEntry {
+  key: string;
+  value: string;
+}
+
 export interface CreateAgentResponse {
   agentId: string;
   sessionId: string;
@@ -99,6 +135,20 @@ export interface GetAgentResponse {
   createdAt?: Date | undefined;
   capabilities?: Capabilities | undefined;
   tokenUsage?: TokenUsage | undefined;
+  /** Tools currently available to the agent (same as ListTools) */
+  tools: ToolInfo[];
+  /** Tool filters the agent was created with */
+  filters?: AgentFilters | undefined;
+  /** Whether the agent runs in code execution mode */
+  codeExecutionMode: boolean;
+}
+
+/** AgentFilters are the tool filters applied to an agent */
+export interface AgentFilters {
+  /** Servers the agent's tools are limited to (empty = all) */
+  selectedServers: string[];
+  /** Tools the agent is limited to (format: "server:tool", empty = all) */
+  selectedTools: string[];
 }
 
 export interface ListAgentsRequest {
@@ -113,6 +163,10 @@ export interface AgentSummary {
   sessionId: string;
   status: string;
   createdAt?: Date | undefined;
+  tokenUsage?: TokenUsage | undefined;
+  tools: ToolInfo[];
+  filters?: AgentFilters | undefined;
+  codeExecutionMode: boolean;
 }
 
 export interface DestroyAgentRequest {
@@ -150,6 +204,141 @@ export interface TokenUsageResponse {
   costs?: Costs | undefined;
 }
 
+export interface GetPostMortemBundleRequest {
+  agentId: string;
+}
+
+export interface GetPostMortemBundleResponse {
+  /**
+   * Zip archive with error.json, events.jsonl, messages.json, config.json
+   * and environment.json; secrets are redacted
+   */
+  bundle: Uint8Array;
+  /** Path of the bundle on the server ("" when not written to disk) */
+  path: string;
+  /** The (redacted) error the conversation ended with */
+  error: string;
+  createdAt?: Date | undefined;
+}
+
+export interface GetToolStatsRequest {
+  agentId: string;
+}
+
+/** ToolCallStats are the usage statistics of a tool or a server */
+export interface ToolCallStats {
+  calls: number;
+  errors: number;
+  /** errors / calls */
+  errorRate: number;
+  /** Calls answered from the tool result cache */
+  cacheHits: number;
+  p50LatencyMs: number;
+  p95LatencyMs: number;
+  maxLatencyMs: number;
+  bytesReturned: number;
+}
+
+export interface ToolStats {
+  toolName: string;
+  /** MCP server; empty for virtual and custom tools */
+  serverName: string;
+  stats?: ToolCallStats | undefined;
+}
+
+export interface ServerToolStats {
+  serverName: string;
+  stats?: ToolCallStats | undefined;
+}
+
+export interface GetToolStatsResponse {
+  /** Sorted by server, then tool name */
+  tools: ToolStats[];
+  /** Sorted by name */
+  servers: ServerToolStats[];
+}
+
+export interface ListToolsRequest {
+  agentId: string;
+  /** Only tools of this server (or custom tool category); empty = all tools */
+  server: string;
+}
+
+export interface ListToolsResponse {
+  /** Sorted by name */
+  tools: ToolInfo[];
+}
+
+/** ToolInfo describes a tool available to an agent */
+export interface ToolInfo {
+  name: string;
+  description: string;
+  /** MCP server of an MCP tool, category of a custom tool, empty for virtual tools */
+  server: string;
+  /** "mcp", "custom" or "virtual" */
+  type: string;
+}
+
+export interface GetToolSchemaRequest {
+  agentId: string;
+  toolName: string;
+}
+
+export interface GetToolSchemaResponse {
+  tool?: ToolInfo | undefined;
+  /** JSON schema of the tool's arguments */
+  inputSchema?:
+    | { [key: string]: any }
+    | undefined;
+}
+
+export interface ListServersRequest {
+  agentId: string;
+}
+
+export interface ListServersResponse {
+  /** Sorted by name */
+  servers: ServerInfo[];
+}
+
+/** ServerInfo describes an MCP server an agent uses */
+export interface ServerInfo {
+  name: string;
+  description: string;
+  /** False for servers connected lazily on their first tool call */
+  connected: boolean;
+  toolCount: number;
+  promptCount: number;
+  resourceCount: number;
+  /** Usage instructions the server sent on initialize */
+  instructions: string;
+}
+
+export interface ListPromptsRequest {
+  agentId: string;
+  /** Only prompts of this server; empty = all servers */
+  server: string;
+}
+
+export interface ListPromptsResponse {
+  /** Sorted by server, then name */
+  prompts: PromptInfo[];
+}
+
+/** PromptInfo describes a prompt an MCP server offers */
+export interface PromptInfo {
+  name: string;
+  description: string;
+  server: string;
+  arguments: PromptArgument[];
+}
+
+export interface PromptArgument {
+  name: string;
+  description: string;
+  required: boolean;
+}
+
 export interface ConversationRequest {
   /** Agent ID for the conversation */
   agentId: string;
@@ -170,6 +359,8 @@ export interface QuestionMessage {
   text: string;
   /** Optional conversation history for multi-turn */
   history: Message[];
+  /** Conversation priority: "low", "normal" (default) or "high" */
+  priority: string;
 }
 
 export interface ToolResultMessage {
@@ -249,14 +440,16 @@ export interface FinalResponse {
     | undefined;
   /** Total duration in milliseconds */
   durationMs: number;
+  /** Files produced during the conversation that can be downloaded from the artifact server */
+  artifacts: Artifact[];
 }
 
 export interface ErrorEvent {
-  /** Error code (e.g., AGENT_NOT_FOUND, ASK_FAILED) */
+  /** Error reason (e.g., AGENT_NOT_FOUND, PROVIDER_THROTTLED, CONTEXT_OVERFLOW) */
   code: string;
   /** Human-readable error message */
   message: string;
-  /** Additional error details */
+  /** Structured error details (grpc_code, provider, model, retry_after_ms, ...) */
   details?:
     | { [key: string]: any }
     | undefined;
@@ -286,7 +479,18 @@ export interface AgentEvent {
   /** Component that emitted the event */
   component: string;
   /** Event-specific data as JSON object */
-  data?: { [key: string]: any } | undefined;
+  data?:
+    | { [key: string]: any }
+    | undefined;
+  /** Files referenced by this event that can be downloaded from the artifact server */
+  artifacts: Artifact[];
+}
+
+export interface Artifact {
+  /** Local file path as reported by the agent */
+  path: string;
+  /** Download URL on the artifact server (requires the artifact server token) */
+  url: string;
 }
 
 export interface Message {
@@ -296,9 +500,96 @@ export interface Message {
   content: string;
 }
 
+export interface WatchConversationRequest {
+  agentId: string;
+  /**
+   * Deliver the agent's retained recent events first, then go live
+   * (requires stream replay on the server)
+   */
+  replay: boolean;
+  /**
+   * Events to deliver: "full" (default, every event), "chunks" (streamed
+   * text plus the conversation end) or "milestones" (lifecycle and tool calls)
+   */
+  verbosity: string;
+}
+export interface AskStreamRequest {
+  agentId: string;
+  question: string;
+  /** Optional conversation history for multi-turn */
+  history: Message[];
+  /** Also stream every other agent event (LLM calls, token usage, ...) as AgentEvent */
+  includeEvents: boolean;
+  /** Conversation priority: "low", "normal" (default) or "high" */
+  priority: string;
+  /** Optional client-chosen ID making the request cancellable with CancelRequest */
+  requestId: string;
+}
+
+export interface AskStreamResponse {
+  /** Streaming text chunk from LLM */
+  textChunk?:
+    | TextChunkEvent
+    | undefined;
+  /** A tool call started */
+  toolCallStart?:
+    | ToolCallStart
+    | undefined;
+  /** A tool call finished (successfully or not) */
+  toolCallEnd?:
+    | ToolCallEnd
+    | undefined;
+  /** Final response (the stream ends after it) */
+  finalResponse?:
+    | FinalResponse
+    | undefined;
+  /** Error event (the stream ends after a fatal one) */
+  error?:
+    | ErrorEvent
+    | undefined;
+  /** Other agent events, only with include_events */
+  agentEvent?: AgentEvent | undefined;
+}
+
+export interface ToolCallStart {
+  /** Tool call ID from the LLM response, matches ToolCallEnd.call_id */
+  callId: string;
+  toolName: string;
+  /** MCP server providing the tool */
+  serverName: string;
+  /** Tool arguments as a JSON string */
+  arguments: string;
+  /** Conversation turn the call belongs to */
+  turn: number;
+}
+
+export interface ToolCallEnd {
+  /** Tool call ID from the LLM response, matches ToolCallStart.call_id */
+  callId: string;
+  toolName: string;
+  /** MCP server providing the tool */
+  serverName: string;
+  /** Whether the tool call succeeded */
+  success: boolean;
+  /** Tool output (if success) */
+  result: string;
+  /** Error message (if not success) */
+  error: string;
+  /** Execution duration in milliseconds */
+  durationMs: number;
+  /** Conversation turn the call belongs to */
+  turn: number;
+  /** Whether the result was served from the agent's tool result cache */
+  fromCache: boolean;
+}
+
 export interface AskRequest {
   agentId: string;
   question: string;
+  /** Conversation priority: "low", "normal" (default) or "high" */
+  priority: string;
+  /** Optional client-chosen ID making the request cancellable with CancelRequest */
+  requestId: string;
 }
 
 export interface AskResponse {
@@ -310,6 +601,10 @@ export interface AskResponse {
 export interface AskWithHistoryRequest {
   agentId: string;
   messages: Message[];
+  /** Conversation priority: "low", "normal" (default) or "high" */
+  priority: string;
+  /** Optional client-chosen ID making the request cancellable with CancelRequest */
+  requestId: string;
 }
 
 export interface AskWithHistoryResponse {
@@ -319,6 +614,17 @@ export interface AskWithHistoryResponse {
   durationMs: number;
 }
 
+export interface CancelRequestRequest {
+  requestId: string;
+  /** Optional reason, reported as the error of the conversation_end event */
+  reason: string;
+}
+
+export interface CancelRequestResponse {
+  /** False when no request with this ID is running (unknown or already finished) */
+  cancelled: boolean;
+}
+
 export interface HealthCheckRequest {
 }
 
@@ -326,8 +632,85 @@ export interface HealthCheckResponse {
   status: string;
 }
 
+export interface HealthCheckDetailedRequest {
+  /** Optional: check only this agent's MCP servers (default all agents) */
+  agentId: string;
+  /** Skip the MCP connection checks, which list every server's tools */
+  skipConnections: boolean;
+  /** Per-agent connection check timeout (default 5000) */
+  timeoutMs: number;
+}
+
+export interface HealthCheckDetailedResponse {
+  /** "ok", "degraded" (some MCP server unhealthy) or "not_ready" */
+  status: string;
+  ready: boolean;
+  /** Readiness check name → "ok" or the failure reason, as served by /readyz */
+  checks: { [key: string]: string };
+  draining: boolean;
+  activeAgents: number;
+  activeConversations: number;
+  agents: AgentHealth[];
+  cache?: CacheStatus | undefined;
+}
+
+export interface HealthCheckDetailedResponse_ChecksEntry {
+  key: string;
+  value: string;
+}
+
+/** AgentHealth is the MCP connection health of one managed agent */
+export interface AgentHealth {
+  agentId: string;
+  sessionId: string;
+  /** Sorted by name */
+  servers: ServerHealth[];
+}
+
+export interface ServerHealth {
+  name: string;
+  healthy: boolean;
+  error: string;
+}
+
+/** CacheStatus summarizes the MCP server metadata cache */
+export interface CacheStatus {
+  totalEntries: number;
+  validEntries: number;
+  expiredEntries: number;
+  ttlMinutes: number;
+  directory: string;
+}
+
+export interface ListRecoverableConversationsRequest {
+}
+
+export interface ListRecoverableConversationsResponse {
+  conversations: RecoverableConversation[];
+}
+
+export interface RecoverableConversation {
+  /** Checkpoint ID */
+  id: string;
+  sessionId: string;
+  userId: string;
+  provider: string;
+  modelId: string;
+  /** Latest user message */
+  question: string;
+  /** Turns completed when the checkpoint was taken */
+  turn: number;
+  /** Error that ended the conversation, empty if the process stopped mid-run */
+  lastError: string;
+  updatedAt?:
+    | Date
+    | undefined;
+  /** Conversation history at the checkpoint */
+  messages: Message[];
+}
+
 function createBaseCreateAgentRequest(): CreateAgentRequest {
-  return { sessionId: "", config: undefined };
+  return { sessionId: "", config: undefined, warmPool: "" };
 }
 
 export const CreateAgentRequest = {
@@ -338,6 +721,9 @@ export const CreateAgentRequest = {
     if (message.config !== undefined) {
       AgentConfig.encode(message.config, writer.uint32(18).fork()).ldelim();
     }
+    if (message.warmPool !== "") {
+      writer.uint32(26).string(message.warmPool);
+    }
     return writer;
   },
 
@@ -362,6 +748,13 @@ export const CreateAgentRequest = {
 
           message.config = AgentConfig.decode(reader, reader.uint32());
           continue;
+        case 3:
+          if (tag !== 26) {
+            break;
+          }
+
+          message.warmPool = reader.string();
+          continue;
       }
       if ((tag & 7) === 4 || tag === 0) {
         break;
@@ -375,6 +768,7 @@ export const CreateAgentRequest = {
     return {
       sessionId: isSet(object.sessionId) ? globalThis.String(object.sessionId) : "",
       config: isSet(object.config) ? AgentConfig.fromJSON(object.config) : undefined,
+      warmPool: isSet(object.warmPool) ? globalThis.String(object.warmPool) : "",
     };
   },
 
@@ -386,6 +780,9 @@ export const CreateAgentRequest = {
     if (message.config !== undefined) {
       obj.config = AgentConfig.toJSON(message.config);
     }
+    if (message.warmPool !== "") {
+      obj.warmPool = message.warmPool;
+    }
     return obj;
   },
 
@@ -398,6 +795,7 @@ export const CreateAgentRequest = {
     message.config = (object.config !== undefined && object.config !== null)
       ? AgentConfig.fromPartial(object.config)
       : undefined;
+    message.warmPool = object.warmPool ?? "";
     return message;
   },
 };
@@ -416,6 +814,7 @@ function createBaseAgentConfig(): AgentConfig {
     enableContextOffloading: false,
     enableStreaming: false,
     customTools: [],
+    toolPermissions: [],
   };
 }
 
@@ -457,6 +856,9 @@ export const AgentConfig = {
     for (const v of message.customTools) {
       CustomToolDefinition.encode(v!, writer.uint32(98).fork()).ldelim();
     }
+    for (const v of message.toolPermissions) {
+      ToolPermission.encode(v!, writer.uint32(106).fork()).ldelim();
+    }
     return writer;
   },
 
@@ -551,6 +953,13 @@ export const AgentConfig = {
 
           message.customTools.push(CustomToolDefinition.decode(reader, reader.uint32()));
           continue;
+        case 13:
+          if (tag !== 106) {
+            break;
+          }
+
+          message.toolPermissions.push(ToolPermission.decode(reader, reader.uint32()));
+          continue;
       }
       if ((tag & 7) === 4 || tag === 0) {
         break;
@@ -584,6 +993,9 @@ export const AgentConfig = {
       customTools: globalThis.Array.isArray(object?.customTools)
         ? object.customTools.map((e: any) => CustomToolDefinition.fromJSON(e))
         : [],
+      toolPermissions: globalThis.Array.isArray(object?.toolPermissions)
+        ? object.toolPermissions.map((e: any) => ToolPermission.fromJSON(e))
+        : [],
     };
   },
 
@@ -625,6 +1037,9 @@ export const AgentConfig = {
     if (message.customTools?.length) {
       obj.customTools = message.customTools.map((e) => CustomToolDefinition.toJSON(e));
     }
+    if (message.toolPermissions?.length) {
+      obj.toolPermissions = message.toolPermissions.map((e) => ToolPermission.toJSON(e));
+    }
     return obj;
   },
 
@@ -645,6 +1060,7 @@ export const AgentConfig = {
     message.enableContextOffloading = object.enableContextOffloading ?? false;
     message.enableStreaming = object.enableStreaming ?? false;
     message.customTools = object.customTools?.map((e) => CustomToolDefinition.fromPartial(e)) || [];
+    message.toolPermissions = object.toolPermissions?.map((e) => ToolPermission.fromPartial(e)) || [];
     return message;
   },
 };
@@ -768,34 +1184,28 @@ export const CustomToolDefinition = {
   },
 };
 
-function createBaseCreateAgentResponse(): CreateAgentResponse {
-  return { agentId: "", sessionId: "", status: "", createdAt: undefined, capabilities: undefined };
+function createBaseToolPermission(): ToolPermission {
+  return { tool: "", disabled: false, paths: [] };
 }
 
-export const CreateAgentResponse = {
-  encode(message: CreateAgentResponse, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
-    if (message.agentId !== "") {
-      writer.uint32(10).string(message.agentId);
-    }
-    if (message.sessionId !== "") {
-      writer.uint32(18).string(message.sessionId);
-    }
-    if (message.status !== "") {
-      writer.uint32(26).string(message.status);
+export const ToolPermission = {
+  encode(message: ToolPermission, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
+    if (message.tool !== "") {
+      writer.uint32(10).string(message.tool);
     }
-    if (message.createdAt !== undefined) {
-      Timestamp.encode(toTimestamp(message.createdAt), writer.uint32(34).fork()).ldelim();
+    if (message.disabled !== false) {
+      writer.uint32(16).bool(message.disabled);
     }
-    if (message.capabilities !== undefined) {
-      Capabilities.encode(message.capabilities, writer.uint32(42).fork()).ldelim();
+    for (const v of message.paths) {
+      writer.uint32(26).string(v!);
     }
     return writer;
   },
 
-  decode(input: _m0.Reader | Uint8Array, length?: number): CreateAgentResponse {
+  decode(input: _m0.Reader | Uint8Array, length?: number): ToolPermission {
     const reader = input instanceof _m0.Reader ? input : _m0.Reader.create(input);
     let end = length === undefined ? reader.len : reader.pos + length;
-    const message = createBaseCreateAgentResponse();
+    const message = createBaseToolPermission();
     while (reader.pos < end) {
       const tag = reader.uint32();
       switch (tag >>> 3) {
@@ -804,35 +1214,21 @@ export const CreateAgentResponse = {
             break;
           }
 
-          message.agentId = reader.string();
+          message.tool = reader.string();
           continue;
         case 2:
-          if (tag !== 18) {
+          if (tag !== 16) {
             break;
           }
 
-          message.sessionId = reader.string();
+          message.disabled = reader.bool();
           continue;
         case 3:
           if (tag !== 26) {
             break;
           }
 
-          message.status = reader.string();
-          continue;
-        case 4:
-          if (tag !== 34) {
-            break;
-          }
-
-          message.createdAt = fromTimestamp(Timestamp.decode(reader, reader.uint32()));
-          continue;
-        case 5:
-          if (tag !== 42) {
-            break;
-          }
-
-          message.capabilities = Capabilities.decode(reader, reader.uint32());
+          message.paths.push(reader.string());
           continue;
       }
       if ((tag & 7) === 4 || tag === 0) {
@@ -843,71 +1239,65 @@ export const CreateAgentResponse = {
     return message;
   },
 
-  fromJSON(object: any): CreateAgentResponse {
+  fromJSON(object: any): ToolPermission {
     return {
-      agentId: isSet(object.agentId) ? globalThis.String(object.agentId) : "",
-      sessionId: isSet(object.sessionId) ? globalThis.String(object.sessionId) : "",
-      status: isSet(object.status) ? globalThis.String(object.status) : "",
-      createdAt: isSet(object.createdAt) ? fromJsonTimestamp(object.createdAt) : undefined,
-      capabilities: isSet(object.capabilities) ? Capabilities.fromJSON(object.capabilities) : undefined,
+      tool: isSet(object.tool) ? globalThis.String(object.tool) : "",
+      disabled: isSet(object.disabled) ? globalThis.Boolean(object.disabled) : false,
+      paths: globalThis.Array.isArray(object?.paths) ? object.paths.map((e: any) => globalThis.String(e)) : [],
     };
   },
 
-  toJSON(message: CreateAgentResponse): unknown {
+  toJSON(message: ToolPermission): unknown {
     const obj: any = {};
-    if (message.agentId !== "") {
-      obj.agentId = message.agentId;
-    }
-    if (message.sessionId !== "") {
-      obj.sessionId = message.sessionId;
-    }
-    if (message.status !== "") {
-      obj.status = message.status;
+    if (message.tool !== "") {
+      obj.tool = message.tool;
     }
-    if (message.createdAt !== undefined) {
-      obj.createdAt = message.createdAt.toISOString();
+    if (message.disabled !== false) {
+      obj.disabled = message.disabled;
     }
-    if (message.capabilities !== undefined) {
-      obj.capabilities = Capabilities.toJSON(message.capabilities);
+    if (message.paths?.length) {
+      obj.paths = message.paths;
     }
     return obj;
   },
 
-  create<I extends Exact<DeepPartial<CreateAgentResponse>, I>>(base?: I): CreateAgentResponse {
-    return CreateAgentResponse.fromPartial(base ?? ({} as any));
+  create<I extends Exact<DeepPartial<ToolPermission>, I>>(base?: I): ToolPermission {
+    return ToolPermission.fromPartial(base ?? ({} as any));
   },
-  fromPartial<I extends Exact<DeepPartial<CreateAgentResponse>, I>>(object: I): CreateAgentResponse {
-    const message = createBaseCreateAgentResponse();
-    message.agentId = object.agentId ?? "";
-    message.sessionId = object.sessionId ?? "";
-    message.status = object.status ?? "";
-    message.createdAt = object.createdAt ?? undefined;
-    message.capabilities = (object.capabilities !== undefined && object.capabilities !== null)
-      ? Capabilities.fromPartial(object.capabilities)
-      : undefined;
+  fromPartial<I extends Exact<DeepPartial<ToolPermission>, I>>(object: I): ToolPermission {
+    const message = createBaseToolPermission();
+    message.tool = object.tool ?? "";
+    message.disabled = object.disabled ?? false;
+    message.paths = object.paths?.map((e) => e) || [];
     return message;
   },
 };
 
-function createBaseCapabilities(): Capabilities {
-  return { tools: [], servers: [] };
+function createBaseCreateAgentFromPresetRequest(): CreateAgentFromPresetRequest {
+  return { sessionId: "", preset: "", variables: {}, config: undefined };
 }
 
-export const Capabilities = {
-  encode(message: Capabilities, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
-    for (const v of message.tools) {
-      writer.uint32(10).string(v!);
+export const CreateAgentFromPresetRequest = {
+  encode(message: CreateAgentFromPresetRequest, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
+    if (message.sessionId !== "") {
+      writer.uint32(10).string(message.sessionId);
     }
-    for (const v of message.servers) {
-      writer.uint32(18).string(v!);
+    if (message.preset !== "") {
+      writer.uint32(18).string(message.preset);
+    }
+    Object.entries(message.variables).forEach(([key, value]) => {
+      CreateAgentFromPresetRequest_VariablesEntry.encode({ key: key as any, value }, writer.uint32(26).fork()).ldelim();
+    });
+    if (message.config !== undefined) {
+      AgentConfig.encode(message.config, writer.uint32(34).fork()).ldelim();
     }
     return writer;
   },
 
-  decode(input: _m0.Reader | Uint8Array, length?: number): Capabilities {
+  decode(input: _m0.Reader | Uint8Array, length?: number): CreateAgentFromPresetRequest {
     const reader = input instanceof _m0.Reader ? input : _m0.Reader.create(input);
     let end = length === undefined ? reader.len : reader.pos + length;
-    const message = createBaseCapabilities();
+    const message = createBaseCreateAgentFromPresetRequest();
     while (reader.pos < end) {
       const tag = reader.uint32();
       switch (tag >>> 3) {
@@ -916,69 +1306,120 @@ export const Capabilities = {
             break;
           }
 
-          message.tools.push(reader.string());
+          message.sessionId = reader.string();
           continue;
         case 2:
           if (tag !== 18) {
             break;
           }
 
-          message.servers.push(reader.string());
+          message.preset = reader.string();
           continue;
-      }
-      if ((tag & 7) === 4 || tag === 0) {
-        break;
+        case 3:
+          if (tag !== 26) {
+            break;
+          }
+
+          const entry3 = CreateAgentFromPresetRequest_VariablesEntry.decode(reader, reader.uint32());
+          if (entry3.value !== undefined) {
+            message.variables[entry3.key] = entry3.value;
+          }
+          continue;
+        case 4:
+          if (tag !== 34) {
+            break;
+          }
+
+          message.config = AgentConfig.decode(reader, reader.uint32());
+          continue;
+      }
+      if ((tag & 7) === 4 || tag === 0) {
+        break;
       }
       reader.skipType(tag & 7);
     }
     return message;
   },
 
-  fromJSON(object: any): Capabilities {
+  fromJSON(object: any): CreateAgentFromPresetRequest {
     return {
-      tools: globalThis.Array.isArray(object?.tools) ? object.tools.map((e: any) => globalThis.String(e)) : [],
-      servers: globalThis.Array.isArray(object?.servers) ? object.servers.map((e: any) => globalThis.String(e)) : [],
+      sessionId: isSet(object.sessionId) ? globalThis.String(object.sessionId) : "",
+      preset: isSet(object.preset) ? globalThis.String(object.preset) : "",
+      variables: isObject(object.variables)
+        ? Object.entries(object.variables).reduce<{ [key: string]: string }>((acc, [key, value]) => {
+          acc[key] = String(value);
+          return acc;
+        }, {})
+        : {},
+      config: isSet(object.config) ? AgentConfig.fromJSON(object.config) : undefined,
     };
   },
 
-  toJSON(message: Capabilities): unknown {
+  toJSON(message: CreateAgentFromPresetRequest): unknown {
     const obj: any = {};
-    if (message.tools?.length) {
-      obj.tools = message.tools;
+    if (message.sessionId !== "") {
+      obj.sessionId = message.sessionId;
     }
-    if (message.servers?.length) {
-      obj.servers = message.servers;
+    if (message.preset !== "") {
+      obj.preset = message.preset;
+    }
+    if (message.variables) {
+      const entries = Object.entries(message.variables);
+      if (entries.length > 0) {
+        obj.variables = {};
+        entries.forEach(([k, v]) => {
+          obj.variables[k] = v;
+        });
+      }
+    }
+    if (message.config !== undefined) {
+      obj.config = AgentConfig.toJSON(message.config);
     }
     return obj;
   },
 
-  create<I extends Exact<DeepPartial<Capabilities>, I>>(base?: I): Capabilities {
-    return Capabilities.fromPartial(base ?? ({} as any));
+  create<I extends Exact<DeepPartial<CreateAgentFromPresetRequest>, I>>(base?: I): CreateAgentFromPresetRequest {
+    return CreateAgentFromPresetRequest.fromPartial(base ?? ({} as any));
   },
-  fromPartial<I extends Exact<DeepPartial<Capabilities>, I>>(object: I): Capabilities {
-    const message = createBaseCapabilities();
-    message.tools = object.tools?.map((e) => e) || [];
-    message.servers = object.servers?.map((e) => e) || [];
+  fromPartial<I extends Exact<DeepPartial<CreateAgentFromPresetRequest>, I>>(object: I): CreateAgentFromPresetRequest {
+    const message = createBaseCreateAgentFromPresetRequest();
+    message.sessionId = object.sessionId ?? "";
+    message.preset = object.preset ?? "";
+    message.variables = Object.entries(object.variables ?? {}).reduce<{ [key: string]: string }>(
+      (acc, [key, value]) => {
+        if (value !== undefined) {
+          acc[key] = globalThis.String(value);
+        }
+        return acc;
+      },
+      {},
+    );
+    message.config = (object.config !== undefined && object.config !== null)
+      ? AgentConfig.fromPartial(object.config)
+      : undefined;
     return message;
   },
 };
 
-function createBaseGetAgentRequest(): GetAgentRequest {
-  return { agentId: "" };
+function createBaseCreateAgentFromPresetRequest_VariablesEntry(): CreateAgentFromPresetRequest_VariablesEntry {
+  return { key: "", value: "" };
 }
 
-export const GetAgentRequest = {
-  encode(message: GetAgentRequest, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
-    if (message.agentId !== "") {
-      writer.uint32(10).string(message.agentId);
+export const CreateAgentFromPresetRequest_VariablesEntry = {
+  encode(message: CreateAgentFromPresetRequest_VariablesEntry, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
+    if (message.key !== "") {
+      writer.uint32(10).string(message.key);
+    }
+    if (message.value !== "") {
+      writer.uint32(18).string(message.value);
     }
     return writer;
   },
 
-  decode(input: _m0.Reader | Uint8Array, length?: number): GetAgentRequest {
+  decode(input: _m0.Reader | Uint8Array, length?: number): CreateAgentFromPresetRequest_VariablesEntry {
     const reader = input instanceof _m0.Reader ? input : _m0.Reader.create(input);
     let end = length === undefined ? reader.len : reader.pos + length;
-    const message = createBaseGetAgentRequest();
+    const message = createBaseCreateAgentFromPresetRequest_VariablesEntry();
     while (reader.pos < end) {
       const tag = reader.uint32();
       switch (tag >>> 3) {
@@ -987,7 +1428,14 @@ export const GetAgentRequest = {
             break;
           }
 
-          message.agentId = reader.string();
+          message.key = reader.string();
+          continue;
+        case 2:
+          if (tag !== 18) {
+            break;
+          }
+
+          message.value = reader.string();
           continue;
       }
       if ((tag & 7) === 4 || tag === 0) {
@@ -998,41 +1446,41 @@ export const GetAgentRequest = {
     return message;
   },
 
-  fromJSON(object: any): GetAgentRequest {
-    return { agentId: isSet(object.agentId) ? globalThis.String(object.agentId) : "" };
+  fromJSON(object: any): CreateAgentFromPresetRequest_VariablesEntry {
+    return {
+      key: isSet(object.key) ? globalThis.String(object.key) : "",
+      value: isSet(object.value) ? globalThis.String(object.value) : "",
+    };
   },
 
-  toJSON(message: GetAgentRequest): unknown {
+  toJSON(message: CreateAgentFromPresetRequest_VariablesEntry): unknown {
     const obj: any = {};
-    if (message.agentId !== "") {
-      obj.agentId = message.agentId;
+    if (message.key !== "") {
+      obj.key = message.key;
+    }
+    if (message.value !== "") {
+      obj.value = message.value;
     }
     return obj;
   },
 
-  create<I extends Exact<DeepPartial<GetAgentRequest>, I>>(base?: I): GetAgentRequest {
-    return GetAgentRequest.fromPartial(base ?? ({} as any));
+  create<I extends Exact<DeepPartial<CreateAgentFromPresetRequest_VariablesEntry>, I>>(base?: I): CreateAgentFromPresetRequest_VariablesEntry {
+    return CreateAgentFromPresetRequest_VariablesEntry.fromPartial(base ?? ({} as any));
   },
-  fromPartial<I extends Exact<DeepPartial<GetAgentRequest>, I>>(object: I): GetAgentRequest {
-    const message = createBaseGetAgentRequest();
-    message.agentId = object.agentId ?? "";
+  fromPartial<I extends Exact<DeepPartial<CreateAgentFromPresetRequest_VariablesEntry>, I>>(object: I): CreateAgentFromPresetRequest_VariablesEntry {
+    const message = createBaseCreateAgentFromPresetRequest_VariablesEntry();
+    message.key = object.key ?? "";
+    message.value = object.value ?? "";
     return message;
   },
 };
 
-function createBaseGetAgentResponse(): GetAgentResponse {
-  return {
-    agentId: "",
-    sessionId: "",
-    status: "",
-    createdAt: undefined,
-    capabilities: undefined,
-    tokenUsage: undefined,
-  };
+function createBaseCreateAgentResponse(): CreateAgentResponse {
+  return { agentId: "", sessionId: "", status: "", createdAt: undefined, capabilities: undefined };
 }
 
-export const GetAgentResponse = {
-  encode(message: GetAgentResponse, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
+export const CreateAgentResponse = {
+  encode(message: CreateAgentResponse, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
     if (message.agentId !== "") {
       writer.uint32(10).string(message.agentId);
     }
@@ -1048,16 +1496,13 @@ export const GetAgentResponse = {
     if (message.capabilities !== undefined) {
       Capabilities.encode(message.capabilities, writer.uint32(42).fork()).ldelim();
     }
-    if (message.tokenUsage !== undefined) {
-      TokenUsage.encode(message.tokenUsage, writer.uint32(50).fork()).ldelim();
-    }
     return writer;
   },
 
-  decode(input: _m0.Reader | Uint8Array, length?: number): GetAgentResponse {
+  decode(input: _m0.Reader | Uint8Array, length?: number): CreateAgentResponse {
     const reader = input instanceof _m0.Reader ? input : _m0.Reader.create(input);
     let end = length === undefined ? reader.len : reader.pos + length;
-    const message = createBaseGetAgentResponse();
+    const message = createBaseCreateAgentResponse();
     while (reader.pos < end) {
       const tag = reader.uint32();
       switch (tag >>> 3) {
@@ -1096,13 +1541,6 @@ export const GetAgentResponse = {
 
           message.capabilities = Capabilities.decode(reader, reader.uint32());
           continue;
-        case 6:
-          if (tag !== 50) {
-            break;
-          }
-
-          message.tokenUsage = TokenUsage.decode(reader, reader.uint32());
-          continue;
       }
       if ((tag & 7) === 4 || tag === 0) {
         break;
@@ -1112,18 +1550,17 @@ export const GetAgentResponse = {
     return message;
   },
 
-  fromJSON(object: any): GetAgentResponse {
+  fromJSON(object: any): CreateAgentResponse {
     return {
       agentId: isSet(object.agentId) ? globalThis.String(object.agentId) : "",
       sessionId: isSet(object.sessionId) ? globalThis.String(object.sessionId) : "",
       status: isSet(object.status) ? globalThis.String(object.status) : "",
       createdAt: isSet(object.createdAt) ? fromJsonTimestamp(object.createdAt) : undefined,
       capabilities: isSet(object.capabilities) ? Capabilities.fromJSON(object.capabilities) : undefined,
-      tokenUsage: isSet(object.tokenUsage) ? TokenUsage.fromJSON(object.tokenUsage) : undefined,
     };
   },
 
-  toJSON(message: GetAgentResponse): unknown {
+  toJSON(message: CreateAgentResponse): unknown {
     const obj: any = {};
     if (message.agentId !== "") {
       obj.agentId = message.agentId;
@@ -1140,17 +1577,14 @@ export const GetAgentResponse = {
     if (message.capabilities !== undefined) {
       obj.capabilities = Capabilities.toJSON(message.capabilities);
     }
-    if (message.tokenUsage !== undefined) {
-      obj.tokenUsage = TokenUsage.toJSON(message.tokenUsage);
-    }
     return obj;
   },
 
-  create<I extends Exact<DeepPartial<GetAgentResponse>, I>>(base?: I): GetAgentResponse {
-    return GetAgentResponse.fromPartial(base ?? ({} as any));
+  create<I extends Exact<DeepPartial<CreateAgentResponse>, I>>(base?: I): CreateAgentResponse {
+    return CreateAgentResponse.fromPartial(base ?? ({} as any));
   },
-  fromPartial<I extends Exact<DeepPartial<GetAgentResponse>, I>>(object: I): GetAgentResponse {
-    const message = createBaseGetAgentResponse();
+  fromPartial<I extends Exact<DeepPartial<CreateAgentResponse>, I>>(object: I): CreateAgentResponse {
+    const message = createBaseCreateAgentResponse();
     message.agentId = object.agentId ?? "";
     message.sessionId = object.sessionId ?? "";
     message.status = object.status ?? "";
@@ -1158,29 +1592,46 @@ export const GetAgentResponse = {
     message.capabilities = (object.capabilities !== undefined && object.capabilities !== null)
       ? Capabilities.fromPartial(object.capabilities)
       : undefined;
-    message.tokenUsage = (object.tokenUsage !== undefined && object.tokenUsage !== null)
-      ? TokenUsage.fromPartial(object.tokenUsage)
-      : undefined;
     return message;
   },
 };
 
-function createBaseListAgentsRequest(): ListAgentsRequest {
-  return {};
+function createBaseCapabilities(): Capabilities {
+  return { tools: [], servers: [] };
 }
 
-export const ListAgentsRequest = {
-  encode(_: ListAgentsRequest, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
+export const Capabilities = {
+  encode(message: Capabilities, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
+    for (const v of message.tools) {
+      writer.uint32(10).string(v!);
+    }
+    for (const v of message.servers) {
+      writer.uint32(18).string(v!);
+    }
     return writer;
   },
 
-  decode(input: _m0.Reader | Uint8Array, length?: number): ListAgentsRequest {
+  decode(input: _m0.Reader | Uint8Array, length?: number): Capabilities {
     const reader = input instanceof _m0.Reader ? input : _m0.Reader.create(input);
     let end = length === undefined ? reader.len : reader.pos + length;
-    const message = createBaseListAgentsRequest();
+    const message = createBaseCapabilities();
     while (reader.pos < end) {
       const tag = reader.uint32();
       switch (tag >>> 3) {
+        case 1:
+          if (tag !== 10) {
+            break;
+          }
+
+          message.tools.push(reader.string());
+          continue;
+        case 2:
+          if (tag !== 18) {
+            break;
+          }
+
+          message.servers.push(reader.string());
+          continue;
       }
       if ((tag & 7) === 4 || tag === 0) {
         break;
@@ -1190,40 +1641,51 @@ export const ListAgentsRequest = {
     return message;
   },
 
-  fromJSON(_: any): ListAgentsRequest {
-    return {};
+  fromJSON(object: any): Capabilities {
+    return {
+      tools: globalThis.Array.isArray(object?.tools) ? object.tools.map((e: any) => globalThis.String(e)) : [],
+      servers: globalThis.Array.isArray(object?.servers) ? object.servers.map((e: any) => globalThis.String(e)) : [],
+    };
   },
 
-  toJSON(_: ListAgentsRequest): unknown {
+  toJSON(message: Capabilities): unknown {
     const obj: any = {};
+    if (message.tools?.length) {
+      obj.tools = message.tools;
+    }
+    if (message.servers?.length) {
+      obj.servers = message.servers;
+    }
     return obj;
   },
 
-  create<I extends Exact<DeepPartial<ListAgentsRequest>, I>>(base?: I): ListAgentsRequest {
-    return ListAgentsRequest.fromPartial(base ?? ({} as any));
+  create<I extends Exact<DeepPartial<Capabilities>, I>>(base?: I): Capabilities {
+    return Capabilities.fromPartial(base ?? ({} as any));
   },
-  fromPartial<I extends Exact<DeepPartial<ListAgentsRequest>, I>>(_: I): ListAgentsRequest {
-    const message = createBaseListAgentsRequest();
+  fromPartial<I extends Exact<DeepPartial<Capabilities>, I>>(object: I): Capabilities {
+    const message = createBaseCapabilities();
+    message.tools = object.tools?.map((e) => e) || [];
+    message.servers = object.servers?.map((e) => e) || [];
     return message;
   },
 };
 
-function createBaseListAgentsResponse(): ListAgentsResponse {
-  return { agents: [] };
+function createBaseGetAgentRequest(): GetAgentRequest {
+  return { agentId: "" };
 }
 
-export const ListAgentsResponse = {
-  encode(message: ListAgentsResponse, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
-    for (const v of message.agents) {
-      AgentSummary.encode(v!, writer.uint32(10).fork()).ldelim();
+export const GetAgentRequest = {
+  encode(message: GetAgentRequest, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
+    if (message.agentId !== "") {
+      writer.uint32(10).string(message.agentId);
     }
     return writer;
   },
 
-  decode(input: _m0.Reader | Uint8Array, length?: number): ListAgentsResponse {
+  decode(input: _m0.Reader | Uint8Array, length?: number): GetAgentRequest {
     const reader = input instanceof _m0.Reader ? input : _m0.Reader.create(input);
     let end = length === undefined ? reader.len : reader.pos + length;
-    const message = createBaseListAgentsResponse();
+    const message = createBaseGetAgentRequest();
     while (reader.pos < end) {
       const tag = reader.uint32();
       switch (tag >>> 3) {
@@ -1232,7 +1694,7 @@ export const ListAgentsResponse = {
             break;
           }
 
-          message.agents.push(AgentSummary.decode(reader, reader.uint32()));
+          message.agentId = reader.string();
           continue;
       }
       if ((tag & 7) === 4 || tag === 0) {
@@ -1243,36 +1705,44 @@ export const ListAgentsResponse = {
     return message;
   },
 
-  fromJSON(object: any): ListAgentsResponse {
-    return {
-      agents: globalThis.Array.isArray(object?.agents) ? object.agents.map((e: any) => AgentSummary.fromJSON(e)) : [],
-    };
+  fromJSON(object: any): GetAgentRequest {
+    return { agentId: isSet(object.agentId) ? globalThis.String(object.agentId) : "" };
   },
 
-  toJSON(message: ListAgentsResponse): unknown {
+  toJSON(message: GetAgentRequest): unknown {
     const obj: any = {};
-    if (message.agents?.length) {
-      obj.agents = message.agents.map((e) => AgentSummary.toJSON(e));
+    if (message.agentId !== "") {
+      obj.agentId = message.agentId;
     }
     return obj;
   },
 
-  create<I extends Exact<DeepPartial<ListAgentsResponse>, I>>(base?: I): ListAgentsResponse {
-    return ListAgentsResponse.fromPartial(base ?? ({} as any));
+  create<I extends Exact<DeepPartial<GetAgentRequest>, I>>(base?: I): GetAgentRequest {
+    return GetAgentRequest.fromPartial(base ?? ({} as any));
   },
-  fromPartial<I extends Exact<DeepPartial<ListAgentsResponse>, I>>(object: I): ListAgentsResponse {
-    const message = createBaseListAgentsResponse();
-    message.agents = object.agents?.map((e) => AgentSummary.fromPartial(e)) || [];
+  fromPartial<I extends Exact<DeepPartial<GetAgentRequest>, I>>(object: I): GetAgentRequest {
+    const message = createBaseGetAgentRequest();
+    message.agentId = object.agentId ?? "";
     return message;
   },
 };
 
-function createBaseAgentSummary(): AgentSummary {
-  return { agentId: "", sessionId: "", status: "", createdAt: undefined };
+function createBaseGetAgentResponse(): GetAgentResponse {
+  return {
+    agentId: "",
+    sessionId: "",
+    status: "",
+    createdAt: undefined,
+    capabilities: undefined,
+    tokenUsage: undefined,
+    tools: [],
+    filters: undefined,
+    codeExecutionMode: false,
+  };
 }
 
-export const AgentSummary = {
-  encode(message: AgentSummary, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
+export const GetAgentResponse = {
+  encode(message: GetAgentResponse, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
     if (message.agentId !== "") {
       writer.uint32(10).string(message.agentId);
     }
@@ -1285,13 +1755,28 @@ export const AgentSummary = {
     if (message.createdAt !== undefined) {
       Timestamp.encode(toTimestamp(message.createdAt), writer.uint32(34).fork()).ldelim();
     }
+    if (message.capabilities !== undefined) {
+      Capabilities.encode(message.capabilities, writer.uint32(42).fork()).ldelim();
+    }
+    if (message.tokenUsage !== undefined) {
+      TokenUsage.encode(message.tokenUsage, writer.uint32(50).fork()).ldelim();
+    }
+    for (const v of message.tools) {
+      ToolInfo.encode(v!, writer.uint32(58).fork()).ldelim();
+    }
+    if (message.filters !== undefined) {
+      AgentFilters.encode(message.filters, writer.uint32(66).fork()).ldelim();
+    }
+    if (message.codeExecutionMode !== false) {
+      writer.uint32(72).bool(message.codeExecutionMode);
+    }
     return writer;
   },
 
-  decode(input: _m0.Reader | Uint8Array, length?: number): AgentSummary {
+  decode(input: _m0.Reader | Uint8Array, length?: number): GetAgentResponse {
     const reader = input instanceof _m0.Reader ? input : _m0.Reader.create(input);
     let end = length === undefined ? reader.len : reader.pos + length;
-    const message = createBaseAgentSummary();
+    const message = createBaseGetAgentResponse();
     while (reader.pos < end) {
       const tag = reader.uint32();
       switch (tag >>> 3) {
@@ -1323,25 +1808,65 @@ export const AgentSummary = {
 
           message.createdAt = fromTimestamp(Timestamp.decode(reader, reader.uint32()));
           continue;
-      }
-      if ((tag & 7) === 4 || tag === 0) {
-        break;
-      }
-      reader.skipType(tag & 7);
-    }
-    return message;
-  },
-
-  fromJSON(object: any): AgentSummary {
+        case 5:
+          if (tag !== 42) {
+            break;
+          }
+
+          message.capabilities = Capabilities.decode(reader, reader.uint32());
+          continue;
+        case 6:
+          if (tag !== 50) {
+            break;
+          }
+
+          message.tokenUsage = TokenUsage.decode(reader, reader.uint32());
+          continue;
+        case 7:
+          if (tag !== 58) {
+            break;
+          }
+
+          message.tools.push(ToolInfo.decode(reader, reader.uint32()));
+          continue;
+        case 8:
+          if (tag !== 66) {
+            break;
+          }
+
+          message.filters = AgentFilters.decode(reader, reader.uint32());
+          continue;
+        case 9:
+          if (tag !== 72) {
+            break;
+          }
+
+          message.codeExecutionMode = reader.bool();
+          continue;
+      }
+      if ((tag & 7) === 4 || tag === 0) {
+        break;
+      }
+      reader.skipType(tag & 7);
+    }
+    return message;
+  },
+
+  fromJSON(object: any): GetAgentResponse {
     return {
       agentId: isSet(object.agentId) ? globalThis.String(object.agentId) : "",
       sessionId: isSet(object.sessionId) ? globalThis.String(object.sessionId) : "",
       status: isSet(object.status) ? globalThis.String(object.status) : "",
       createdAt: isSet(object.createdAt) ? fromJsonTimestamp(object.createdAt) : undefined,
+      capabilities: isSet(object.capabilities) ? Capabilities.fromJSON(object.capabilities) : undefined,
+      tokenUsage: isSet(object.tokenUsage) ? TokenUsage.fromJSON(object.tokenUsage) : undefined,
+      tools: globalThis.Array.isArray(object?.tools) ? object.tools.map((e: any) => ToolInfo.fromJSON(e)) : [],
+      filters: isSet(object.filters) ? AgentFilters.fromJSON(object.filters) : undefined,
+      codeExecutionMode: isSet(object.codeExecutionMode) ? globalThis.Boolean(object.codeExecutionMode) : false,
     };
   },
 
-  toJSON(message: AgentSummary): unknown {
+  toJSON(message: GetAgentResponse): unknown {
     const obj: any = {};
     if (message.agentId !== "") {
       obj.agentId = message.agentId;
@@ -1355,38 +1880,67 @@ export const AgentSummary = {
     if (message.createdAt !== undefined) {
       obj.createdAt = message.createdAt.toISOString();
     }
+    if (message.capabilities !== undefined) {
+      obj.capabilities = Capabilities.toJSON(message.capabilities);
+    }
+    if (message.tokenUsage !== undefined) {
+      obj.tokenUsage = TokenUsage.toJSON(message.tokenUsage);
+    }
+    if (message.tools?.length) {
+      obj.tools = message.tools.map((e) => ToolInfo.toJSON(e));
+    }
+    if (message.filters !== undefined) {
+      obj.filters = AgentFilters.toJSON(message.filters);
+    }
+    if (message.codeExecutionMode !== false) {
+      obj.codeExecutionMode = message.codeExecutionMode;
+    }
     return obj;
   },
 
-  create<I extends Exact<DeepPartial<AgentSummary>, I>>(base?: I): AgentSummary {
-    return AgentSummary.fromPartial(base ?? ({} as any));
+  create<I extends Exact<DeepPartial<GetAgentResponse>, I>>(base?: I): GetAgentResponse {
+    return GetAgentResponse.fromPartial(base ?? ({} as any));
   },
-  fromPartial<I extends Exact<DeepPartial<AgentSummary>, I>>(object: I): AgentSummary {
-    const message = createBaseAgentSummary();
+  fromPartial<I extends Exact<DeepPartial<GetAgentResponse>, I>>(object: I): GetAgentResponse {
+    const message = createBaseGetAgentResponse();
     message.agentId = object.agentId ?? "";
     message.sessionId = object.sessionId ?? "";
     message.status = object.status ?? "";
     message.createdAt = object.createdAt ?? undefined;
+    message.capabilities = (object.capabilities !== undefined && object.capabilities !== null)
+      ? Capabilities.fromPartial(object.capabilities)
+      : undefined;
+    message.tokenUsage = (object.tokenUsage !== undefined && object.tokenUsage !== null)
+      ? TokenUsage.fromPartial(object.tokenUsage)
+      : undefined;
+    message.tools = object.tools?.map((e) => ToolInfo.fromPartial(e)) || [];
+    message.filters = (object.filters !== undefined && object.filters !== null)
+      ? AgentFilters.fromPartial(object.filters)
+      : undefined;
+    message.codeExecutionMode = object.codeExecutionMode ?? false;
     return message;
   },
 };
 
-function createBaseDestroyAgentRequest(): DestroyAgentRequest {
-  return { agentId: "" };
+function createBaseAgentFilters(): AgentFilters {
+  return { selectedServers: [], selectedTools: [] };
 }
 
-export const DestroyAgentRequest = {
-  encode(message: DestroyAgentRequest, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
-    if (message.agentId !== "") {
-      writer.uint32(10).string(message.agentId);
+export const AgentFilters = {
+  encode(message: AgentFilters, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
+    for (const v of message.selectedServers) {
+      writer.uint32(10).string(v!);
+    }
+    for (const v of message.selectedTools) {
+      writer.uint32(18).string(v!);
     }
     return writer;
   },
 
-  decode(input: _m0.Reader | Uint8Array, length?: number): DestroyAgentRequest {
+  decode(input: _m0.Reader | Uint8Array, length?: number): AgentFilters {
     const reader = input instanceof _m0.Reader ? input : _m0.Reader.create(input);
     let end = length === undefined ? reader.len : reader.pos + length;
-    const message = createBaseDestroyAgentRequest();
+    const message = createBaseAgentFilters();
     while (reader.pos < end) {
       const tag = reader.uint32();
       switch (tag >>> 3) {
@@ -1395,7 +1949,14 @@ export const DestroyAgentRequest = {
             break;
           }
 
-          message.agentId = reader.string();
+          message.selectedServers.push(reader.string());
+          continue;
+        case 2:
+          if (tag !== 18) {
+            break;
+          }
+
+          message.selectedTools.push(reader.string());
           continue;
       }
       if ((tag & 7) === 4 || tag === 0) {
@@ -1406,64 +1967,51 @@ export const DestroyAgentRequest = {
     return message;
   },
 
-  fromJSON(object: any): DestroyAgentRequest {
-    return { agentId: isSet(object.agentId) ? globalThis.String(object.agentId) : "" };
+  fromJSON(object: any): AgentFilters {
+    return {
+      selectedServers: globalThis.Array.isArray(object?.selectedServers) ? object.selectedServers.map((e: any) => globalThis.String(e)) : [],
+      selectedTools: globalThis.Array.isArray(object?.selectedTools) ? object.selectedTools.map((e: any) => globalThis.String(e)) : [],
+    };
   },
 
-  toJSON(message: DestroyAgentRequest): unknown {
+  toJSON(message: AgentFilters): unknown {
     const obj: any = {};
-    if (message.agentId !== "") {
-      obj.agentId = message.agentId;
+    if (message.selectedServers?.length) {
+      obj.selectedServers = message.selectedServers;
+    }
+    if (message.selectedTools?.length) {
+      obj.selectedTools = message.selectedTools;
     }
     return obj;
   },
 
-  create<I extends Exact<DeepPartial<DestroyAgentRequest>, I>>(base?: I): DestroyAgentRequest {
-    return DestroyAgentRequest.fromPartial(base ?? ({} as any));
+  create<I extends Exact<DeepPartial<AgentFilters>, I>>(base?: I): AgentFilters {
+    return AgentFilters.fromPartial(base ?? ({} as any));
   },
-  fromPartial<I extends Exact<DeepPartial<DestroyAgentRequest>, I>>(object: I): DestroyAgentRequest {
-    const message = createBaseDestroyAgentRequest();
-    message.agentId = object.agentId ?? "";
+  fromPartial<I extends Exact<DeepPartial<AgentFilters>, I>>(object: I): AgentFilters {
+    const message = createBaseAgentFilters();
+    message.selectedServers = object.selectedServers?.map((e) => e) || [];
+    message.selectedTools = object.selectedTools?.map((e) => e) || [];
     return message;
   },
 };
 
-function createBaseDestroyAgentResponse(): DestroyAgentResponse {
-  return { agentId: "", destroyed: false };
+function createBaseListAgentsRequest(): ListAgentsRequest {
+  return {};
 }
 
-export const DestroyAgentResponse = {
-  encode(message: DestroyAgentResponse, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
-    if (message.agentId !== "") {
-      writer.uint32(10).string(message.agentId);
-    }
-    if (message.destroyed !== false) {
-      writer.uint32(16).bool(message.destroyed);
-    }
+export const ListAgentsRequest = {
+  encode(_: ListAgentsRequest, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
     return writer;
   },
 
-  decode(input: _m0.Reader | Uint8Array, length?: number): DestroyAgentResponse {
+  decode(input: _m0.Reader | Uint8Array, length?: number): ListAgentsRequest {
     const reader = input instanceof _m0.Reader ? input : _m0.Reader.create(input);
     let end = length === undefined ? reader.len : reader.pos + length;
-    const message = createBaseDestroyAgentResponse();
+    const message = createBaseListAgentsRequest();
     while (reader.pos < end) {
       const tag = reader.uint32();
       switch (tag >>> 3) {
-        case 1:
-          if (tag !== 10) {
-            break;
-          }
-
-          message.agentId = reader.string();
-          continue;
-        case 2:
-          if (tag !== 16) {
-            break;
-          }
-
-          message.destroyed = reader.bool();
-          continue;
       }
       if ((tag & 7) === 4 || tag === 0) {
         break;
@@ -1473,51 +2021,40 @@ export const DestroyAgentResponse = {
     return message;
   },
 
-  fromJSON(object: any): DestroyAgentResponse {
-    return {
-      agentId: isSet(object.agentId) ? globalThis.String(object.agentId) : "",
-      destroyed: isSet(object.destroyed) ? globalThis.Boolean(object.destroyed) : false,
-    };
+  fromJSON(_: any): ListAgentsRequest {
+    return {};
   },
 
-  toJSON(message: DestroyAgentResponse): unknown {
+  toJSON(_: ListAgentsRequest): unknown {
     const obj: any = {};
-    if (message.agentId !== "") {
-      obj.agentId = message.agentId;
-    }
-    if (message.destroyed !== false) {
-      obj.destroyed = message.destroyed;
-    }
     return obj;
   },
 
-  create<I extends Exact<DeepPartial<DestroyAgentResponse>, I>>(base?: I): DestroyAgentResponse {
-    return DestroyAgentResponse.fromPartial(base ?? ({} as any));
+  create<I extends Exact<DeepPartial<ListAgentsRequest>, I>>(base?: I): ListAgentsRequest {
+    return ListAgentsRequest.fromPartial(base ?? ({} as any));
   },
-  fromPartial<I extends Exact<DeepPartial<DestroyAgentResponse>, I>>(object: I): DestroyAgentResponse {
-    const message = createBaseDestroyAgentResponse();
-    message.agentId = object.agentId ?? "";
-    message.destroyed = object.destroyed ?? false;
+  fromPartial<I extends Exact<DeepPartial<ListAgentsRequest>, I>>(_: I): ListAgentsRequest {
+    const message = createBaseListAgentsRequest();
     return message;
   },
 };
 
-function createBaseGetTokenUsageRequest(): GetTokenUsageRequest {
-  return { agentId: "" };
+function createBaseListAgentsResponse(): ListAgentsResponse {
+  return { agents: [] };
 }
 
-export const GetTokenUsageRequest = {
-  encode(message: GetTokenUsageRequest, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
-    if (message.agentId !== "") {
-      writer.uint32(10).string(message.agentId);
+export const ListAgentsResponse = {
+  encode(message: ListAgentsResponse, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
+    for (const v of message.agents) {
+      AgentSummary.encode(v!, writer.uint32(10).fork()).ldelim();
     }
     return writer;
   },
 
-  decode(input: _m0.Reader | Uint8Array, length?: number): GetTokenUsageRequest {
+  decode(input: _m0.Reader | Uint8Array, length?: number): ListAgentsResponse {
     const reader = input instanceof _m0.Reader ? input : _m0.Reader.create(input);
     let end = length === undefined ? reader.len : reader.pos + length;
-    const message = createBaseGetTokenUsageRequest();
+    const message = createBaseListAgentsResponse();
     while (reader.pos < end) {
       const tag = reader.uint32();
       switch (tag >>> 3) {
@@ -1526,7 +2063,7 @@ export const GetTokenUsageRequest = {
             break;
           }
 
-          message.agentId = reader.string();
+          message.agents.push(AgentSummary.decode(reader, reader.uint32()));
           continue;
       }
       if ((tag & 7) === 4 || tag === 0) {
@@ -1537,103 +2074,3866 @@ export const GetTokenUsageRequest = {
     return message;
   },
 
-  fromJSON(object: any): GetTokenUsageRequest {
-    return { agentId: isSet(object.agentId) ? globalThis.String(object.agentId) : "" };
+  fromJSON(object: any): ListAgentsResponse {
+    return {
+      agents: globalThis.Array.isArray(object?.agents) ? object.agents.map((e: any) => AgentSummary.fromJSON(e)) : [],
+    };
   },
 
-  toJSON(message: GetTokenUsageRequest): unknown {
+  toJSON(message: ListAgentsResponse): unknown {
     const obj: any = {};
-    if (message.agentId !== "") {
-      obj.agentId = message.agentId;
+    if (message.agents?.length) {
+      obj.agents = message.agents.map((e) => AgentSummary.toJSON(e));
     }
     return obj;
   },
 
-  create<I extends Exact<DeepPartial<GetTokenUsageRequest>, I>>(base?: I): GetTokenUsageRequest {
-    return GetTokenUsageRequest.fromPartial(base ?? ({} as any));
+  create<I extends Exact<DeepPartial<ListAgentsResponse>, I>>(base?: I): ListAgentsResponse {
+    return ListAgentsResponse.fromPartial(base ?? ({} as any));
   },
-  fromPartial<I extends Exact<DeepPartial<GetTokenUsageRequest>, I>>(object: I): GetTokenUsageRequest {
-    const message = createBaseGetTokenUsageRequest();
-    message.agentId = object.agentId ?? "";
+  fromPartial<I extends Exact<DeepPartial<ListAgentsResponse>, I>>(object: I): ListAgentsResponse {
+    const message = createBaseListAgentsResponse();
+    message.agents = object.agents?.map((e) => AgentSummary.fromPartial(e)) || [];
     return message;
   },
 };
 
-function createBaseTokenUsage(): TokenUsage {
-  return { promptTokens: 0, completionTokens: 0, totalTokens: 0, cacheTokens: 0, reasoningTokens: 0, llmCallCount: 0 };
+function createBaseAgentSummary(): AgentSummary {
+  return {
+    agentId: "",
+    sessionId: "",
+    status: "",
+    createdAt: undefined,
+    tokenUsage: undefined,
+    tools: [],
+    filters: undefined,
+    codeExecutionMode: false,
+  };
 }
 
-export const TokenUsage = {
-  encode(message: TokenUsage, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
-    if (message.promptTokens !== 0) {
-      writer.uint32(8).int32(message.promptTokens);
+export const AgentSummary = {
+  encode(message: AgentSummary, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
+    if (message.agentId !== "") {
+      writer.uint32(10).string(message.agentId);
     }
-    if (message.completionTokens !== 0) {
-      writer.uint32(16).int32(message.completionTokens);
+    if (message.sessionId !== "") {
+      writer.uint32(18).string(message.sessionId);
     }
-    if (message.totalTokens !== 0) {
-      writer.uint32(24).int32(message.totalTokens);
+    if (message.status !== "") {
+      writer.uint32(26).string(message.status);
     }
-    if (message.cacheTokens !== 0) {
-      writer.uint32(32).int32(message.cacheTokens);
+    if (message.createdAt !== undefined) {
+      Timestamp.encode(toTimestamp(message.createdAt), writer.uint32(34).fork()).ldelim();
     }
-    if (message.reasoningTokens !== 0) {
-      writer.uint32(40).int32(message.reasoningTokens);
+    if (message.tokenUsage !== undefined) {
+      TokenUsage.encode(message.tokenUsage, writer.uint32(42).fork()).ldelim();
     }
-    if (message.llmCallCount !== 0) {
-      writer.uint32(48).int32(message.llmCallCount);
+    for (const v of message.tools) {
+      ToolInfo.encode(v!, writer.uint32(50).fork()).ldelim();
+    }
+    if (message.filters !== undefined) {
+      AgentFilters.encode(message.filters, writer.uint32(58).fork()).ldelim();
+    }
+    if (message.codeExecutionMode !== false) {
+      writer.uint32(64).bool(message.codeExecutionMode);
     }
     return writer;
   },
 
-  decode(input: _m0.Reader | Uint8Array, length?: number): TokenUsage {
+  decode(input: _m0.Reader | Uint8Array, length?: number): AgentSummary {
     const reader = input instanceof _m0.Reader ? input : _m0.Reader.create(input);
     let end = length === undefined ? reader.len : reader.pos + length;
-    const message = createBaseTokenUsage();
+    const message = createBaseAgentSummary();
     while (reader.pos < end) {
       const tag = reader.uint32();
       switch (tag >>> 3) {
         case 1:
-          if (tag !== 8) {
+          if (tag !== 10) {
             break;
           }
 
-          message.promptTokens = reader.int32();
+          message.agentId = reader.string();
           continue;
         case 2:
-          if (tag !== 16) {
+          if (tag !== 18) {
+            break;
+          }
+
+          message.sessionId = reader.string();
+          continue;
+        case 3:
+          if (tag !== 26) {
+            break;
+          }
+
+          message.status = reader.string();
+          continue;
+        case 4:
+          if (tag !== 34) {
+            break;
+          }
+
+          message.createdAt = fromTimestamp(Timestamp.decode(reader, reader.uint32()));
+          continue;
+        case 5:
+          if (tag !== 42) {
+            break;
+          }
+
+          message.tokenUsage = TokenUsage.decode(reader, reader.uint32());
+          continue;
+        case 6:
+          if (tag !== 50) {
+            break;
+          }
+
+          message.tools.push(ToolInfo.decode(reader, reader.uint32()));
+          continue;
+        case 7:
+          if (tag !== 58) {
+            break;
+          }
+
+          message.filters = AgentFilters.decode(reader, reader.uint32());
+          continue;
+        case 8:
+          if (tag !== 64) {
+            break;
+          }
+
+          message.codeExecutionMode = reader.bool();
+          continue;
+      }
+      if ((tag & 7) === 4 || tag === 0) {
+        break;
+      }
+      reader.skipType(tag & 7);
+    }
+    return message;
+  },
+
+  fromJSON(object: any): AgentSummary {
+    return {
+      agentId: isSet(object.agentId) ? globalThis.String(object.agentId) : "",
+      sessionId: isSet(object.sessionId) ? globalThis.String(object.sessionId) : "",
+      status: isSet(object.status) ? globalThis.String(object.status) : "",
+      createdAt: isSet(object.createdAt) ? fromJsonTimestamp(object.createdAt) : undefined,
+      tokenUsage: isSet(object.tokenUsage) ? TokenUsage.fromJSON(object.tokenUsage) : undefined,
+      tools: globalThis.Array.isArray(object?.tools) ? object.tools.map((e: any) => ToolInfo.fromJSON(e)) : [],
+      filters: isSet(object.filters) ? AgentFilters.fromJSON(object.filters) : undefined,
+      codeExecutionMode: isSet(object.codeExecutionMode) ? globalThis.Boolean(object.codeExecutionMode) : false,
+    };
+  },
+
+  toJSON(message: AgentSummary): unknown {
+    const obj: any = {};
+    if (message.agentId !== "") {
+      obj.agentId = message.agentId;
+    }
+    if (message.sessionId !== "") {
+      obj.sessionId = message.sessionId;
+    }
+    if (message.status !== "") {
+      obj.status = message.status;
+    }
+    if (message.createdAt !== undefined) {
+      obj.createdAt = message.createdAt.toISOString();
+    }
+    if (message.tokenUsage !== undefined) {
+      obj.tokenUsage = TokenUsage.toJSON(message.tokenUsage);
+    }
+    if (message.tools?.length) {
+      obj.tools = message.tools.map((e) => ToolInfo.toJSON(e));
+    }
+    if (message.filters !== undefined) {
+      obj.filters = AgentFilters.toJSON(message.filters);
+    }
+    if (message.codeExecutionMode !== false) {
+      obj.codeExecutionMode = message.codeExecutionMode;
+    }
+    return obj;
+  },
+
+  create<I extends Exact<DeepPartial<AgentSummary>, I>>(base?: I): AgentSummary {
+    return AgentSummary.fromPartial(base ?? ({} as any));
+  },
+  fromPartial<I extends Exact<DeepPartial<AgentSummary>, I>>(object: I): AgentSummary {
+    const message = createBaseAgentSummary();
+    message.agentId = object.agentId ?? "";
+    message.sessionId = object.sessionId ?? "";
+    message.status = object.status ?? "";
+    message.createdAt = object.createdAt ?? undefined;
+    message.tokenUsage = (object.tokenUsage !== undefined && object.tokenUsage !== null)
+      ? TokenUsage.fromPartial(object.tokenUsage)
+      : undefined;
+    message.tools = object.tools?.map((e) => ToolInfo.fromPartial(e)) || [];
+    message.filters = (object.filters !== undefined && object.filters !== null)
+      ? AgentFilters.fromPartial(object.filters)
+      : undefined;
+    message.codeExecutionMode = object.codeExecutionMode ?? false;
+    return message;
+  },
+};
+
+function createBaseDestroyAgentRequest(): DestroyAgentRequest {
+  return { agentId: "" };
+}
+
+export const DestroyAgentRequest = {
+  encode(message: DestroyAgentRequest, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
+    if (message.agentId !== "") {
+      writer.uint32(10).string(message.agentId);
+    }
+    return writer;
+  },
+
+  decode(input: _m0.Reader | Uint8Array, length?: number): DestroyAgentRequest {
+    const reader = input instanceof _m0.Reader ? input : _m0.Reader.create(input);
+    let end = length === undefined ? reader.len : reader.pos + length;
+    const message = createBaseDestroyAgentRequest();
+    while (reader.pos < end) {
+      const tag = reader.uint32();
+      switch (tag >>> 3) {
+        case 1:
+          if (tag !== 10) {
+            break;
+          }
+
+          message.agentId = reader.string();
+          continue;
+      }
+      if ((tag & 7) === 4 || tag === 0) {
+        break;
+      }
+      reader.skipType(tag & 7);
+    }
+    return message;
+  },
+
+  fromJSON(object: any): DestroyAgentRequest {
+    return { agentId: isSet(object.agentId) ? globalThis.String(object.agentId) : "" };
+  },
+
+  toJSON(message: DestroyAgentRequest): unknown {
+    const obj: any = {};
+    if (message.agentId !== "") {
+      obj.agentId = message.agentId;
+    }
+    return obj;
+  },
+
+  create<I extends Exact<DeepPartial<DestroyAgentRequest>, I>>(base?: I): DestroyAgentRequest {
+    return DestroyAgentRequest.fromPartial(base ?? ({} as any));
+  },
+  fromPartial<I extends Exact<DeepPartial<DestroyAgentRequest>, I>>(object: I): DestroyAgentRequest {
+    const message = createBaseDestroyAgentRequest();
+    message.agentId = object.agentId ?? "";
+    return message;
+  },
+};
+
+function createBaseDestroyAgentResponse(): DestroyAgentResponse {
+  return { agentId: "", destroyed: false };
+}
+
+export const DestroyAgentResponse = {
+  encode(message: DestroyAgentResponse, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
+    if (message.agentId !== "") {
+      writer.uint32(10).string(message.agentId);
+    }
+    if (message.destroyed !== false) {
+      writer.uint32(16).bool(message.destroyed);
+    }
+    return writer;
+  },
+
+  decode(input: _m0.Reader | Uint8Array, length?: number): DestroyAgentResponse {
+    const reader = input instanceof _m0.Reader ? input : _m0.Reader.create(input);
+    let end = length === undefined ? reader.len : reader.pos + length;
+    const message = createBaseDestroyAgentResponse();
+    while (reader.pos < end) {
+      const tag = reader.uint32();
+      switch (tag >>> 3) {
+        case 1:
+          if (tag !== 10) {
+            break;
+          }
+
+          message.agentId = reader.string();
+          continue;
+        case 2:
+          if (tag !== 16) {
+            break;
+          }
+
+          message.destroyed = reader.bool();
+          continue;
+      }
+      if ((tag & 7) === 4 || tag === 0) {
+        break;
+      }
+      reader.skipType(tag & 7);
+    }
+    return message;
+  },
+
+  fromJSON(object: any): DestroyAgentResponse {
+    return {
+      agentId: isSet(object.agentId) ? globalThis.String(object.agentId) : "",
+      destroyed: isSet(object.destroyed) ? globalThis.Boolean(object.destroyed) : false,
+    };
+  },
+
+  toJSON(message: DestroyAgentResponse): unknown {
+    const obj: any = {};
+    if (message.agentId !== "") {
+      obj.agentId = message.agentId;
+    }
+    if (message.destroyed !== false) {
+      obj.destroyed = message.destroyed;
+    }
+    return obj;
+  },
+
+  create<I extends Exact<DeepPartial<DestroyAgentResponse>, I>>(base?: I): DestroyAgentResponse {
+    return DestroyAgentResponse.fromPartial(base ?? ({} as any));
+  },
+  fromPartial<I extends Exact<DeepPartial<DestroyAgentResponse>, I>>(object: I): DestroyAgentResponse {
+    const message = createBaseDestroyAgentResponse();
+    message.agentId = object.agentId ?? "";
+    message.destroyed = object.destroyed ?? false;
+    return message;
+  },
+};
+
+function createBaseGetTokenUsageRequest(): GetTokenUsageRequest {
+  return { agentId: "" };
+}
+
+export const GetTokenUsageRequest = {
+  encode(message: GetTokenUsageRequest, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
+    if (message.agentId !== "") {
+      writer.uint32(10).string(message.agentId);
+    }
+    return writer;
+  },
+
+  decode(input: _m0.Reader | Uint8Array, length?: number): GetTokenUsageRequest {
+    const reader = input instanceof _m0.Reader ? input : _m0.Reader.create(input);
+    let end = length === undefined ? reader.len : reader.pos + length;
+    const message = createBaseGetTokenUsageRequest();
+    while (reader.pos < end) {
+      const tag = reader.uint32();
+      switch (tag >>> 3) {
+        case 1:
+          if (tag !== 10) {
+            break;
+          }
+
+          message.agentId = reader.string();
+          continue;
+      }
+      if ((tag & 7) === 4 || tag === 0) {
+        break;
+      }
+      reader.skipType(tag & 7);
+    }
+    return message;
+  },
+
+  fromJSON(object: any): GetTokenUsageRequest {
+    return { agentId: isSet(object.agentId) ? globalThis.String(object.agentId) : "" };
+  },
+
+  toJSON(message: GetTokenUsageRequest): unknown {
+    const obj: any = {};
+    if (message.agentId !== "") {
+      obj.agentId = message.agentId;
+    }
+    return obj;
+  },
+
+  create<I extends Exact<DeepPartial<GetTokenUsageRequest>, I>>(base?: I): GetTokenUsageRequest {
+    return GetTokenUsageRequest.fromPartial(base ?? ({} as any));
+  },
+  fromPartial<I extends Exact<DeepPartial<GetTokenUsageRequest>, I>>(object: I): GetTokenUsageRequest {
+    const message = createBaseGetTokenUsageRequest();
+    message.agentId = object.agentId ?? "";
+    return message;
+  },
+};
+
+function createBaseTokenUsage(): TokenUsage {
+  return { promptTokens: 0, completionTokens: 0, totalTokens: 0, cacheTokens: 0, reasoningTokens: 0, llmCallCount: 0 };
+}
+
+export const TokenUsage = {
+  encode(message: TokenUsage, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
+    if (message.promptTokens !== 0) {
+      writer.uint32(8).int32(message.promptTokens);
+    }
+    if (message.completionTokens !== 0) {
+      writer.uint32(16).int32(message.completionTokens);
+    }
+    if (message.totalTokens !== 0) {
+      writer.uint32(24).int32(message.totalTokens);
+    }
+    if (message.cacheTokens !== 0) {
+      writer.uint32(32).int32(message.cacheTokens);
+    }
+    if (message.reasoningTokens !== 0) {
+      writer.uint32(40).int32(message.reasoningTokens);
+    }
+    if (message.llmCallCount !== 0) {
+      writer.uint32(48).int32(message.llmCallCount);
+    }
+    return writer;
+  },
+
+  decode(input: _m0.Reader | Uint8Array, length?: number): TokenUsage {
+    const reader = input instanceof _m0.Reader ? input : _m0.Reader.create(input);
+    let end = length === undefined ? reader.len : reader.pos + length;
+    const message = createBaseTokenUsage();
+    while (reader.pos < end) {
+      const tag = reader.uint32();
+      switch (tag >>> 3) {
+        case 1:
+          if (tag !== 8) {
+            break;
+          }
+
+          message.promptTokens = reader.int32();
+          continue;
+        case 2:
+          if (tag !== 16) {
+            break;
+          }
+
+          message.completionTokens = reader.int32();
+          continue;
+        case 3:
+          if (tag !== 24) {
+            break;
+          }
+
+          message.totalTokens = reader.int32();
+          continue;
+        case 4:
+          if (tag !== 32) {
+            break;
+          }
+
+          message.cacheTokens = reader.int32();
+          continue;
+        case 5:
+          if (tag !== 40) {
+            break;
+          }
+
+          message.reasoningTokens = reader.int32();
+          continue;
+        case 6:
+          if (tag !== 48) {
+            break;
+          }
+
+          message.llmCallCount = reader.int32();
+          continue;
+      }
+      if ((tag & 7) === 4 || tag === 0) {
+        break;
+      }
+      reader.skipType(tag & 7);
+    }
+    return message;
+  },
+
+  fromJSON(object: any): TokenUsage {
+    return {
+      promptTokens: isSet(object.promptTokens) ? globalThis.Number(object.promptTokens) : 0,
+      completionTokens: isSet(object.completionTokens) ? globalThis.Number(object.completionTokens) : 0,
+      totalTokens: isSet(object.totalTokens) ? globalThis.Number(object.totalTokens) : 0,
+      cacheTokens: isSet(object.cacheTokens) ? globalThis.Number(object.cacheTokens) : 0,
+      reasoningTokens: isSet(object.reasoningTokens) ? globalThis.Number(object.reasoningTokens) : 0,
+      llmCallCount: isSet(object.llmCallCount) ? globalThis.Number(object.llmCallCount) : 0,
+    };
+  },
+
+  toJSON(message: TokenUsage): unknown {
+    const obj: any = {};
+    if (message.promptTokens !== 0) {
+      obj.promptTokens = Math.round(message.promptTokens);
+    }
+    if (message.completionTokens !== 0) {
+      obj.completionTokens = Math.round(message.completionTokens);
+    }
+    if (message.totalTokens !== 0) {
+      obj.totalTokens = Math.round(message.totalTokens);
+    }
+    if (message.cacheTokens !== 0) {
+      obj.cacheTokens = Math.round(message.cacheTokens);
+    }
+    if (message.reasoningTokens !== 0) {
+      obj.reasoningTokens = Math.round(message.reasoningTokens);
+    }
+    if (message.llmCallCount !== 0) {
+      obj.llmCallCount = Math.round(message.llmCallCount);
+    }
+    return obj;
+  },
+
+  create<I extends Exact<DeepPartial<TokenUsage>, I>>(base?: I): TokenUsage {
+    return TokenUsage.fromPartial(base ?? ({} as any));
+  },
+  fromPartial<I extends Exact<DeepPartial<TokenUsage>, I>>(object: I): TokenUsage {
+    const message = createBaseTokenUsage();
+    message.promptTokens = object.promptTokens ?? 0;
+    message.completionTokens = object.completionTokens ?? 0;
+    message.totalTokens = object.totalTokens ?? 0;
+    message.cacheTokens = object.cacheTokens ?? 0;
+    message.reasoningTokens = object.reasoningTokens ?? 0;
+    message.llmCallCount = object.llmCallCount ?? 0;
+    return message;
+  },
+};
+
+function createBaseCosts(): Costs {
+  return { inputCost: 0, outputCost: 0, reasoningCost: 0, cacheCost: 0, totalCost: 0 };
+}
+
+export const Costs = {
+  encode(message: Costs, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
+    if (message.inputCost !== 0) {
+      writer.uint32(9).double(message.inputCost);
+    }
+    if (message.outputCost !== 0) {
+      writer.uint32(17).double(message.outputCost);
+    }
+    if (message.reasoningCost !== 0) {
+      writer.uint32(25).double(message.reasoningCost);
+    }
+    if (message.cacheCost !== 0) {
+      writer.uint32(33).double(message.cacheCost);
+    }
+    if (message.totalCost !== 0) {
+      writer.uint32(41).double(message.totalCost);
+    }
+    return writer;
+  },
+
+  decode(input: _m0.Reader | Uint8Array, length?: number): Costs {
+    const reader = input instanceof _m0.Reader ? input : _m0.Reader.create(input);
+    let end = length === undefined ? reader.len : reader.pos + length;
+    const message = createBaseCosts();
+    while (reader.pos < end) {
+      const tag = reader.uint32();
+      switch (tag >>> 3) {
+        case 1:
+          if (tag !== 9) {
+            break;
+          }
+
+          message.inputCost = reader.double();
+          continue;
+        case 2:
+          if (tag !== 17) {
+            break;
+          }
+
+          message.outputCost = reader.double();
+          continue;
+        case 3:
+          if (tag !== 25) {
+            break;
+          }
+
+          message.reasoningCost = reader.double();
+          continue;
+        case 4:
+          if (tag !== 33) {
+            break;
+          }
+
+          message.cacheCost = reader.double();
+          continue;
+        case 5:
+          if (tag !== 41) {
+            break;
+          }
+
+          message.totalCost = reader.double();
+          continue;
+      }
+      if ((tag & 7) === 4 || tag === 0) {
+        break;
+      }
+      reader.skipType(tag & 7);
+    }
+    return message;
+  },
+
+  fromJSON(object: any): Costs {
+    return {
+      inputCost: isSet(object.inputCost) ? globalThis.Number(object.inputCost) : 0,
+      outputCost: isSet(object.outputCost) ? globalThis.Number(object.outputCost) : 0,
+      reasoningCost: isSet(object.reasoningCost) ? globalThis.Number(object.reasoningCost) : 0,
+      cacheCost: isSet(object.cacheCost) ? globalThis.Number(object.cacheCost) : 0,
+      totalCost: isSet(object.totalCost) ? globalThis.Number(object.totalCost) : 0,
+    };
+  },
+
+  toJSON(message: Costs): unknown {
+    const obj: any = {};
+    if (message.inputCost !== 0) {
+      obj.inputCost = message.inputCost;
+    }
+    if (message.outputCost !== 0) {
+      obj.outputCost = message.outputCost;
+    }
+    if (message.reasoningCost !== 0) {
+      obj.reasoningCost = message.reasoningCost;
+    }
+    if (message.cacheCost !== 0) {
+      obj.cacheCost = message.cacheCost;
+    }
+    if (message.totalCost !== 0) {
+      obj.totalCost = message.totalCost;
+    }
+    return obj;
+  },
+
+  create<I extends Exact<DeepPartial<Costs>, I>>(base?: I): Costs {
+    return Costs.fromPartial(base ?? ({} as any));
+  },
+  fromPartial<I extends Exact<DeepPartial<Costs>, I>>(object: I): Costs {
+    const message = createBaseCosts();
+    message.inputCost = object.inputCost ?? 0;
+    message.outputCost = object.outputCost ?? 0;
+    message.reasoningCost = object.reasoningCost ?? 0;
+    message.cacheCost = object.cacheCost ?? 0;
+    message.totalCost = object.totalCost ?? 0;
+    return message;
+  },
+};
+
+function createBaseTokenUsageResponse(): TokenUsageResponse {
+  return { tokenUsage: undefined, costs: undefined };
+}
+
+export const TokenUsageResponse = {
+  encode(message: TokenUsageResponse, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
+    if (message.tokenUsage !== undefined) {
+      TokenUsage.encode(message.tokenUsage, writer.uint32(10).fork()).ldelim();
+    }
+    if (message.costs !== undefined) {
+      Costs.encode(message.costs, writer.uint32(18).fork()).ldelim();
+    }
+    return writer;
+  },
+
+  decode(input: _m0.Reader | Uint8Array, length?: number): TokenUsageResponse {
+    const reader = input instanceof _m0.Reader ? input : _m0.Reader.create(input);
+    let end = length === undefined ? reader.len : reader.pos + length;
+    const message = createBaseTokenUsageResponse();
+    while (reader.pos < end) {
+      const tag = reader.uint32();
+      switch (tag >>> 3) {
+        case 1:
+          if (tag !== 10) {
+            break;
+          }
+
+          message.tokenUsage = TokenUsage.decode(reader, reader.uint32());
+          continue;
+        case 2:
+          if (tag !== 18) {
+            break;
+          }
+
+          message.costs = Costs.decode(reader, reader.uint32());
+          continue;
+      }
+      if ((tag & 7) === 4 || tag === 0) {
+        break;
+      }
+      reader.skipType(tag & 7);
+    }
+    return message;
+  },
+
+  fromJSON(object: any): TokenUsageResponse {
+    return {
+      tokenUsage: isSet(object.tokenUsage) ? TokenUsage.fromJSON(object.tokenUsage) : undefined,
+      costs: isSet(object.costs) ? Costs.fromJSON(object.costs) : undefined,
+    };
+  },
+
+  toJSON(message: TokenUsageResponse): unknown {
+    const obj: any = {};
+    if (message.tokenUsage !== undefined) {
+      obj.tokenUsage = TokenUsage.toJSON(message.tokenUsage);
+    }
+    if (message.costs !== undefined) {
+      obj.costs = Costs.toJSON(message.costs);
+    }
+    return obj;
+  },
+
+  create<I extends Exact<DeepPartial<TokenUsageResponse>, I>>(base?: I): TokenUsageResponse {
+    return TokenUsageResponse.fromPartial(base ?? ({} as any));
+  },
+  fromPartial<I extends Exact<DeepPartial<TokenUsageResponse>, I>>(object: I): TokenUsageResponse {
+    const message = createBaseTokenUsageResponse();
+    message.tokenUsage = (object.tokenUsage !== undefined && object.tokenUsage !== null)
+      ? TokenUsage.fromPartial(object.tokenUsage)
+      : undefined;
+    message.costs = (object.costs !== undefined && object.costs !== null) ? Costs.fromPartial(object.costs) : undefined;
+    return message;
+  },
+};
+
+function createBaseGetPostMortemBundleRequest(): GetPostMortemBundleRequest {
+  return { agentId: "" };
+}
+
+export const GetPostMortemBundleRequest = {
+  encode(message: GetPostMortemBundleRequest, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
+    if (message.agentId !== "") {
+      writer.uint32(10).string(message.agentId);
+    }
+    return writer;
+  },
+
+  decode(input: _m0.Reader | Uint8Array, length?: number): GetPostMortemBundleRequest {
+    const reader = input instanceof _m0.Reader ? input : _m0.Reader.create(input);
+    let end = length === undefined ? reader.len : reader.pos + length;
+    const message = createBaseGetPostMortemBundleRequest();
+    while (reader.pos < end) {
+      const tag = reader.uint32();
+      switch (tag >>> 3) {
+        case 1:
+          if (tag !== 10) {
+            break;
+          }
+
+          message.agentId = reader.string();
+          continue;
+      }
+      if ((tag & 7) === 4 || tag === 0) {
+        break;
+      }
+      reader.skipType(tag & 7);
+    }
+    return message;
+  },
+
+  fromJSON(object: any): GetPostMortemBundleRequest {
+    return { agentId: isSet(object.agentId) ? globalThis.String(object.agentId) : "" };
+  },
+
+  toJSON(message: GetPostMortemBundleRequest): unknown {
+    const obj: any = {};
+    if (message.agentId !== "") {
+      obj.agentId = message.agentId;
+    }
+    return obj;
+  },
+
+  create<I extends Exact<DeepPartial<GetPostMortemBundleRequest>, I>>(base?: I): GetPostMortemBundleRequest {
+    return GetPostMortemBundleRequest.fromPartial(base ?? ({} as any));
+  },
+  fromPartial<I extends Exact<DeepPartial<GetPostMortemBundleRequest>, I>>(object: I): GetPostMortemBundleRequest {
+    const message = createBaseGetPostMortemBundleRequest();
+    message.agentId = object.agentId ?? "";
+    return message;
+  },
+};
+
+function createBaseGetPostMortemBundleResponse(): GetPostMortemBundleResponse {
+  return { bundle: new Uint8Array(0), path: "", error: "", createdAt: undefined };
+}
+
+export const GetPostMortemBundleResponse = {
+  encode(message: GetPostMortemBundleResponse, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
+    if (message.bundle.length !== 0) {
+      writer.uint32(10).bytes(message.bundle);
+    }
+    if (message.path !== "") {
+      writer.uint32(18).string(message.path);
+    }
+    if (message.error !== "") {
+      writer.uint32(26).string(message.error);
+    }
+    if (message.createdAt !== undefined) {
+      Timestamp.encode(toTimestamp(message.createdAt), writer.uint32(34).fork()).ldelim();
+    }
+    return writer;
+  },
+
+  decode(input: _m0.Reader | Uint8Array, length?: number): GetPostMortemBundleResponse {
+    const reader = input instanceof _m0.Reader ? input : _m0.Reader.create(input);
+    let end = length === undefined ? reader.len : reader.pos + length;
+    const message = createBaseGetPostMortemBundleResponse();
+    while (reader.pos < end) {
+      const tag = reader.uint32();
+      switch (tag >>> 3) {
+        case 1:
+          if (tag !== 10) {
+            break;
+          }
+
+          message.bundle = reader.bytes();
+          continue;
+        case 2:
+          if (tag !== 18) {
+            break;
+          }
+
+          message.path = reader.string();
+          continue;
+        case 3:
+          if (tag !== 26) {
+            break;
+          }
+
+          message.error = reader.string();
+          continue;
+        case 4:
+          if (tag !== 34) {
+            break;
+          }
+
+          message.createdAt = fromTimestamp(Timestamp.decode(reader, reader.uint32()));
+          continue;
+      }
+      if ((tag & 7) === 4 || tag === 0) {
+        break;
+      }
+      reader.skipType(tag & 7);
+    }
+    return message;
+  },
+
+  fromJSON(object: any): GetPostMortemBundleResponse {
+    return {
+      bundle: isSet(object.bundle) ? bytesFromBase64(object.bundle) : new Uint8Array(0),
+      path: isSet(object.path) ? globalThis.String(object.path) : "",
+      error: isSet(object.error) ? globalThis.String(object.error) : "",
+      createdAt: isSet(object.createdAt) ? fromJsonTimestamp(object.createdAt) : undefined,
+    };
+  },
+
+  toJSON(message: GetPostMortemBundleResponse): unknown {
+    const obj: any = {};
+    if (message.bundle.length !== 0) {
+      obj.bundle = base64FromBytes(message.bundle);
+    }
+    if (message.path !== "") {
+      obj.path = message.path;
+    }
+    if (message.error !== "") {
+      obj.error = message.error;
+    }
+    if (message.createdAt !== undefined) {
+      obj.createdAt = message.createdAt.toISOString();
+    }
+    return obj;
+  },
+
+  create<I extends Exact<DeepPartial<GetPostMortemBundleResponse>, I>>(base?: I): GetPostMortemBundleResponse {
+    return GetPostMortemBundleResponse.fromPartial(base ?? ({} as any));
+  },
+  fromPartial<I extends Exact<DeepPartial<GetPostMortemBundleResponse>, I>>(object: I): GetPostMortemBundleResponse {
+    const message = createBaseGetPostMortemBundleResponse();
+    message.bundle = object.bundle ?? new Uint8Array(0);
+    message.path = object.path ?? "";
+    message.error = object.error ?? "";
+    message.createdAt = object.createdAt ?? undefined;
+    return message;
+  },
+};
+
+function createBaseGetToolStatsRequest(): GetToolStatsRequest {
+  return { agentId: "" };
+}
+
+export const GetToolStatsRequest = {
+  encode(message: GetToolStatsRequest, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
+    if (message.agentId !== "") {
+      writer.uint32(10).string(message.agentId);
+    }
+    return writer;
+  },
+
+  decode(input: _m0.Reader | Uint8Array, length?: number): GetToolStatsRequest {
+    const reader = input instanceof _m0.Reader ? input : _m0.Reader.create(input);
+    let end = length === undefined ? reader.len : reader.pos + length;
+    const message = createBaseGetToolStatsRequest();
+    while (reader.pos < end) {
+      const tag = reader.uint32();
+      switch (tag >>> 3) {
+        case 1:
+          if (tag !== 10) {
+            break;
+          }
+
+          message.agentId = reader.string();
+          continue;
+      }
+      if ((tag & 7) === 4 || tag === 0) {
+        break;
+      }
+      reader.skipType(tag & 7);
+    }
+    return message;
+  },
+
+  fromJSON(object: any): GetToolStatsRequest {
+    return { agentId: isSet(object.agentId) ? globalThis.String(object.agentId) : "" };
+  },
+
+  toJSON(message: GetToolStatsRequest): unknown {
+    const obj: any = {};
+    if (message.agentId !== "") {
+      obj.agentId = message.agentId;
+    }
+    return obj;
+  },
+
+  create<I extends Exact<DeepPartial<GetToolStatsRequest>, I>>(base?: I): GetToolStatsRequest {
+    return GetToolStatsRequest.fromPartial(base ?? ({} as any));
+  },
+  fromPartial<I extends Exact<DeepPartial<GetToolStatsRequest>, I>>(object: I): GetToolStatsRequest {
+    const message = createBaseGetToolStatsRequest();
+    message.agentId = object.agentId ?? "";
+    return message;
+  },
+};
+
+function createBaseToolCallStats(): ToolCallStats {
+  return { calls: 0, errors: 0, errorRate: 0, cacheHits: 0, p50LatencyMs: 0, p95LatencyMs: 0, maxLatencyMs: 0, bytesReturned: 0 };
+}
+
+export const ToolCallStats = {
+  encode(message: ToolCallStats, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
+    if (message.calls !== 0) {
+      writer.uint32(8).int32(message.calls);
+    }
+    if (message.errors !== 0) {
+      writer.uint32(16).int32(message.errors);
+    }
+    if (message.errorRate !== 0) {
+      writer.uint32(25).double(message.errorRate);
+    }
+    if (message.cacheHits !== 0) {
+      writer.uint32(32).int32(message.cacheHits);
+    }
+    if (message.p50LatencyMs !== 0) {
+      writer.uint32(40).int64(message.p50LatencyMs);
+    }
+    if (message.p95LatencyMs !== 0) {
+      writer.uint32(48).int64(message.p95LatencyMs);
+    }
+    if (message.maxLatencyMs !== 0) {
+      writer.uint32(56).int64(message.maxLatencyMs);
+    }
+    if (message.bytesReturned !== 0) {
+      writer.uint32(64).int64(message.bytesReturned);
+    }
+    return writer;
+  },
+
+  decode(input: _m0.Reader | Uint8Array, length?: number): ToolCallStats {
+    const reader = input instanceof _m0.Reader ? input : _m0.Reader.create(input);
+    let end = length === undefined ? reader.len : reader.pos + length;
+    const message = createBaseToolCallStats();
+    while (reader.pos < end) {
+      const tag = reader.uint32();
+      switch (tag >>> 3) {
+        case 1:
+          if (tag !== 8) {
+            break;
+          }
+
+          message.calls = reader.int32();
+          continue;
+        case 2:
+          if (tag !== 16) {
+            break;
+          }
+
+          message.errors = reader.int32();
+          continue;
+        case 3:
+          if (tag !== 25) {
+            break;
+          }
+
+          message.errorRate = reader.double();
+          continue;
+        case 4:
+          if (tag !== 32) {
+            break;
+          }
+
+          message.cacheHits = reader.int32();
+          continue;
+        case 5:
+          if (tag !== 40) {
+            break;
+          }
+
+          message.p50LatencyMs = longToNumber(reader.int64() as Long);
+          continue;
+        case 6:
+          if (tag !== 48) {
+            break;
+          }
+
+          message.p95LatencyMs = longToNumber(reader.int64() as Long);
+          continue;
+        case 7:
+          if (tag !== 56) {
+            break;
+          }
+
+          message.maxLatencyMs = longToNumber(reader.int64() as Long);
+          continue;
+        case 8:
+          if (tag !== 64) {
+            break;
+          }
+
+          message.bytesReturned = longToNumber(reader.int64() as Long);
+          continue;
+      }
+      if ((tag & 7) === 4 || tag === 0) {
+        break;
+      }
+      reader.skipType(tag & 7);
+    }
+    return message;
+  },
+
+  fromJSON(object: any): ToolCallStats {
+    return {
+      calls: isSet(object.calls) ? globalThis.Number(object.calls) : 0,
+      errors: isSet(object.errors) ? globalThis.Number(object.errors) : 0,
+      errorRate: isSet(object.errorRate) ? globalThis.Number(object.errorRate) : 0,
+      cacheHits: isSet(object.cacheHits) ? globalThis.Number(object.cacheHits) : 0,
+      p50LatencyMs: isSet(object.p50LatencyMs) ? globalThis.Number(object.p50LatencyMs) : 0,
+      p95LatencyMs: isSet(object.p95LatencyMs) ? globalThis.Number(object.p95LatencyMs) : 0,
+      maxLatencyMs: isSet(object.maxLatencyMs) ? globalThis.Number(object.maxLatencyMs) : 0,
+      bytesReturned: isSet(object.bytesReturned) ? globalThis.Number(object.bytesReturned) : 0,
+    };
+  },
+
+  toJSON(message: ToolCallStats): unknown {
+    const obj: any = {};
+    if (message.calls !== 0) {
+      obj.calls = Math.round(message.calls);
+    }
+    if (message.errors !== 0) {
+      obj.errors = Math.round(message.errors);
+    }
+    if (message.errorRate !== 0) {
+      obj.errorRate = message.errorRate;
+    }
+    if (message.cacheHits !== 0) {
+      obj.cacheHits = Math.round(message.cacheHits);
+    }
+    if (message.p50LatencyMs !== 0) {
+      obj.p50LatencyMs = Math.round(message.p50LatencyMs);
+    }
+    if (message.p95LatencyMs !== 0) {
+      obj.p95LatencyMs = Math.round(message.p95LatencyMs);
+    }
+    if (message.maxLatencyMs !== 0) {
+      obj.maxLatencyMs = Math.round(message.maxLatencyMs);
+    }
+    if (message.bytesReturned !== 0) {
+      obj.bytesReturned = Math.round(message.bytesReturned);
+    }
+    return obj;
+  },
+
+  create<I extends Exact<DeepPartial<ToolCallStats>, I>>(base?: I): ToolCallStats {
+    return ToolCallStats.fromPartial(base ?? ({} as any));
+  },
+  fromPartial<I extends Exact<DeepPartial<ToolCallStats>, I>>(object: I): ToolCallStats {
+    const message = createBaseToolCallStats();
+    message.calls = object.calls ?? 0;
+    message.errors = object.errors ?? 0;
+    message.errorRate = object.errorRate ?? 0;
+    message.cacheHits = object.cacheHits ?? 0;
+    message.p50LatencyMs = object.p50LatencyMs ?? 0;
+    message.p95LatencyMs = object.p95LatencyMs ?? 0;
+    message.maxLatencyMs = object.maxLatencyMs ?? 0;
+    message.bytesReturned = object.bytesReturned ?? 0;
+    return message;
+  },
+};
+
+function createBaseToolStats(): ToolStats {
+  return { toolName: "", serverName: "", stats: undefined };
+}
+
+export const ToolStats = {
+  encode(message: ToolStats, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
+    if (message.toolName !== "") {
+      writer.uint32(10).string(message.toolName);
+    }
+    if (message.serverName !== "") {
+      writer.uint32(18).string(message.serverName);
+    }
+    if (message.stats !== undefined) {
+      ToolCallStats.encode(message.stats, writer.uint32(26).fork()).ldelim();
+    }
+    return writer;
+  },
+
+  decode(input: _m0.Reader | Uint8Array, length?: number): ToolStats {
+    const reader = input instanceof _m0.Reader ? input : _m0.Reader.create(input);
+    let end = length === undefined ? reader.len : reader.pos + length;
+    const message = createBaseToolStats();
+    while (reader.pos < end) {
+      const tag = reader.uint32();
+      switch (tag >>> 3) {
+        case 1:
+          if (tag !== 10) {
+            break;
+          }
+
+          message.toolName = reader.string();
+          continue;
+        case 2:
+          if (tag !== 18) {
+            break;
+          }
+
+          message.serverName = reader.string();
+          continue;
+        case 3:
+          if (tag !== 26) {
+            break;
+          }
+
+          message.stats = ToolCallStats.decode(reader, reader.uint32());
+          continue;
+      }
+      if ((tag & 7) === 4 || tag === 0) {
+        break;
+      }
+      reader.skipType(tag & 7);
+    }
+    return message;
+  },
+
+  fromJSON(object: any): ToolStats {
+    return {
+      toolName: isSet(object.toolName) ? globalThis.String(object.toolName) : "",
+      serverName: isSet(object.serverName) ? globalThis.String(object.serverName) : "",
+      stats: isSet(object.stats) ? ToolCallStats.fromJSON(object.stats) : undefined,
+    };
+  },
+
+  toJSON(message: ToolStats): unknown {
+    const obj: any = {};
+    if (message.toolName !== "") {
+      obj.toolName = message.toolName;
+    }
+    if (message.serverName !== "") {
+      obj.serverName = message.serverName;
+    }
+    if (message.stats !== undefined) {
+      obj.stats = ToolCallStats.toJSON(message.stats);
+    }
+    return obj;
+  },
+
+  create<I extends Exact<DeepPartial<ToolStats>, I>>(base?: I): ToolStats {
+    return ToolStats.fromPartial(base ?? ({} as any));
+  },
+  fromPartial<I extends Exact<DeepPartial<ToolStats>, I>>(object: I): ToolStats {
+    const message = createBaseToolStats();
+    message.toolName = object.toolName ?? "";
+    message.serverName = object.serverName ?? "";
+    message.stats = (object.stats !== undefined && object.stats !== null)
+      ? ToolCallStats.fromPartial(object.stats)
+      : undefined;
+    return message;
+  },
+};
+
+function createBaseServerToolStats(): ServerToolStats {
+  return { serverName: "", stats: undefined };
+}
+
+export const ServerToolStats = {
+  encode(message: ServerToolStats, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
+    if (message.serverName !== "") {
+      writer.uint32(10).string(message.serverName);
+    }
+    if (message.stats !== undefined) {
+      ToolCallStats.encode(message.stats, writer.uint32(18).fork()).ldelim();
+    }
+    return writer;
+  },
+
+  decode(input: _m0.Reader | Uint8Array, length?: number): ServerToolStats {
+    const reader = input instanceof _m0.Reader ? input : _m0.Reader.create(input);
+    let end = length === undefined ? reader.len : reader.pos + length;
+    const message = createBaseServerToolStats();
+    while (reader.pos < end) {
+      const tag = reader.uint32();
+      switch (tag >>> 3) {
+        case 1:
+          if (tag !== 10) {
+            break;
+          }
+
+          message.serverName = reader.string();
+          continue;
+        case 2:
+          if (tag !== 18) {
+            break;
+          }
+
+          message.stats = ToolCallStats.decode(reader, reader.uint32());
+          continue;
+      }
+      if ((tag & 7) === 4 || tag === 0) {
+        break;
+      }
+      reader.skipType(tag & 7);
+    }
+    return message;
+  },
+
+  fromJSON(object: any): ServerToolStats {
+    return {
+      serverName: isSet(object.serverName) ? globalThis.String(object.serverName) : "",
+      stats: isSet(object.stats) ? ToolCallStats.fromJSON(object.stats) : undefined,
+    };
+  },
+
+  toJSON(message: ServerToolStats): unknown {
+    const obj: any = {};
+    if (message.serverName !== "") {
+      obj.serverName = message.serverName;
+    }
+    if (message.stats !== undefined) {
+      obj.stats = ToolCallStats.toJSON(message.stats);
+    }
+    return obj;
+  },
+
+  create<I extends Exact<DeepPartial<ServerToolStats>, I>>(base?: I): ServerToolStats {
+    return ServerToolStats.fromPartial(base ?? ({} as any));
+  },
+  fromPartial<I extends Exact<DeepPartial<ServerToolStats>, I>>(object: I): ServerToolStats {
+    const message = createBaseServerToolStats();
+    message.serverName = object.serverName ?? "";
+    message.stats = (object.stats !== undefined && object.stats !== null)
+      ? ToolCallStats.fromPartial(object.stats)
+      : undefined;
+    return message;
+  },
+};
+
+function createBaseGetToolStatsResponse(): GetToolStatsResponse {
+  return { tools: [], servers: [] };
+}
+
+export const GetToolStatsResponse = {
+  encode(message: GetToolStatsResponse, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
+    for (const v of message.tools) {
+      ToolStats.encode(v!, writer.uint32(10).fork()).ldelim();
+    }
+    for (const v of message.servers) {
+      ServerToolStats.encode(v!, writer.uint32(18).fork()).ldelim();
+    }
+    return writer;
+  },
+
+  decode(input: _m0.Reader | Uint8Array, length?: number): GetToolStatsResponse {
+    const reader = input instanceof _m0.Reader ? input : _m0.Reader.create(input);
+    let end = length === undefined ? reader.len : reader.pos + length;
+    const message = createBaseGetToolStatsResponse();
+    while (reader.pos < end) {
+      const tag = reader.uint32();
+      switch (tag >>> 3) {
+        case 1:
+          if (tag !== 10) {
+            break;
+          }
+
+          message.tools.push(ToolStats.decode(reader, reader.uint32()));
+          continue;
+        case 2:
+          if (tag !== 18) {
+            break;
+          }
+
+          message.servers.push(ServerToolStats.decode(reader, reader.uint32()));
+          continue;
+      }
+      if ((tag & 7) === 4 || tag === 0) {
+        break;
+      }
+      reader.skipType(tag & 7);
+    }
+    return message;
+  },
+
+  fromJSON(object: any): GetToolStatsResponse {
+    return {
+      tools: globalThis.Array.isArray(object?.tools) ? object.tools.map((e: any) => ToolStats.fromJSON(e)) : [],
+      servers: globalThis.Array.isArray(object?.servers) ? object.servers.map((e: any) => ServerToolStats.fromJSON(e)) : [],
+    };
+  },
+
+  toJSON(message: GetToolStatsResponse): unknown {
+    const obj: any = {};
+    if (message.tools?.length) {
+      obj.tools = message.tools.map((e) => ToolStats.toJSON(e));
+    }
+    if (message.servers?.length) {
+      obj.servers = message.servers.map((e) => ServerToolStats.toJSON(e));
+    }
+    return obj;
+  },
+
+  create<I extends Exact<DeepPartial<GetToolStatsResponse>, I>>(base?: I): GetToolStatsResponse {
+    return GetToolStatsResponse.fromPartial(base ?? ({} as any));
+  },
+  fromPartial<I extends Exact<DeepPartial<GetToolStatsResponse>, I>>(object: I): GetToolStatsResponse {
+    const message = createBaseGetToolStatsResponse();
+    message.tools = object.tools?.map((e) => ToolStats.fromPartial(e)) || [];
+    message.servers = object.servers?.map((e) => ServerToolStats.fromPartial(e)) || [];
+    return message;
+  },
+};
+
+function createBaseListToolsRequest(): ListToolsRequest {
+  return { agentId: "", server: "" };
+}
+
+export const ListToolsRequest = {
+  encode(message: ListToolsRequest, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
+    if (message.agentId !== "") {
+      writer.uint32(10).string(message.agentId);
+    }
+    if (message.server !== "") {
+      writer.uint32(18).string(message.server);
+    }
+    return writer;
+  },
+
+  decode(input: _m0.Reader | Uint8Array, length?: number): ListToolsRequest {
+    const reader = input instanceof _m0.Reader ? input : _m0.Reader.create(input);
+    let end = length === undefined ? reader.len : reader.pos + length;
+    const message = createBaseListToolsRequest();
+    while (reader.pos < end) {
+      const tag = reader.uint32();
+      switch (tag >>> 3) {
+        case 1:
+          if (tag !== 10) {
+            break;
+          }
+
+          message.agentId = reader.string();
+          continue;
+        case 2:
+          if (tag !== 18) {
+            break;
+          }
+
+          message.server = reader.string();
+          continue;
+      }
+      if ((tag & 7) === 4 || tag === 0) {
+        break;
+      }
+      reader.skipType(tag & 7);
+    }
+    return message;
+  },
+
+  fromJSON(object: any): ListToolsRequest {
+    return {
+      agentId: isSet(object.agentId) ? globalThis.String(object.agentId) : "",
+      server: isSet(object.server) ? globalThis.String(object.server) : "",
+    };
+  },
+
+  toJSON(message: ListToolsRequest): unknown {
+    const obj: any = {};
+    if (message.agentId !== "") {
+      obj.agentId = message.agentId;
+    }
+    if (message.server !== "") {
+      obj.server = message.server;
+    }
+    return obj;
+  },
+
+  create<I extends Exact<DeepPartial<ListToolsRequest>, I>>(base?: I): ListToolsRequest {
+    return ListToolsRequest.fromPartial(base ?? ({} as any));
+  },
+  fromPartial<I extends Exact<DeepPartial<ListToolsRequest>, I>>(object: I): ListToolsRequest {
+    const message = createBaseListToolsRequest();
+    message.agentId = object.agentId ?? "";
+    message.server = object.server ?? "";
+    return message;
+  },
+};
+
+function createBaseListToolsResponse(): ListToolsResponse {
+  return { tools: [] };
+}
+
+export const ListToolsResponse = {
+  encode(message: ListToolsResponse, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
+    for (const v of message.tools) {
+      ToolInfo.encode(v!, writer.uint32(10).fork()).ldelim();
+    }
+    return writer;
+  },
+
+  decode(input: _m0.Reader | Uint8Array, length?: number): ListToolsResponse {
+    const reader = input instanceof _m0.Reader ? input : _m0.Reader.create(input);
+    let end = length === undefined ? reader.len : reader.pos + length;
+    const message = createBaseListToolsResponse();
+    while (reader.pos < end) {
+      const tag = reader.uint32();
+      switch (tag >>> 3) {
+        case 1:
+          if (tag !== 10) {
+            break;
+          }
+
+          message.tools.push(ToolInfo.decode(reader, reader.uint32()));
+          continue;
+      }
+      if ((tag & 7) === 4 || tag === 0) {
+        break;
+      }
+      reader.skipType(tag & 7);
+    }
+    return message;
+  },
+
+  fromJSON(object: any): ListToolsResponse {
+    return { tools: globalThis.Array.isArray(object?.tools) ? object.tools.map((e: any) => ToolInfo.fromJSON(e)) : [] };
+  },
+
+  toJSON(message: ListToolsResponse): unknown {
+    const obj: any = {};
+    if (message.tools?.length) {
+      obj.tools = message.tools.map((e) => ToolInfo.toJSON(e));
+    }
+    return obj;
+  },
+
+  create<I extends Exact<DeepPartial<ListToolsResponse>, I>>(base?: I): ListToolsResponse {
+    return ListToolsResponse.fromPartial(base ?? ({} as any));
+  },
+  fromPartial<I extends Exact<DeepPartial<ListToolsResponse>, I>>(object: I): ListToolsResponse {
+    const message = createBaseListToolsResponse();
+    message.tools = object.tools?.map((e) => ToolInfo.fromPartial(e)) || [];
+    return message;
+  },
+};
+
+function createBaseToolInfo(): ToolInfo {
+  return { name: "", description: "", server: "", type: "" };
+}
+
+export const ToolInfo = {
+  encode(message: ToolInfo, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
+    if (message.name !== "") {
+      writer.uint32(10).string(message.name);
+    }
+    if (message.description !== "") {
+      writer.uint32(18).string(message.description);
+    }
+    if (message.server !== "") {
+      writer.uint32(26).string(message.server);
+    }
+    if (message.type !== "") {
+      writer.uint32(34).string(message.type);
+    }
+    return writer;
+  },
+
+  decode(input: _m0.Reader | Uint8Array, length?: number): ToolInfo {
+    const reader = input instanceof _m0.Reader ? input : _m0.Reader.create(input);
+    let end = length === undefined ? reader.len : reader.pos + length;
+    const message = createBaseToolInfo();
+    while (reader.pos < end) {
+      const tag = reader.uint32();
+      switch (tag >>> 3) {
+        case 1:
+          if (tag !== 10) {
+            break;
+          }
+
+          message.name = reader.string();
+          continue;
+        case 2:
+          if (tag !== 18) {
+            break;
+          }
+
+          message.description = reader.string();
+          continue;
+        case 3:
+          if (tag !== 26) {
+            break;
+          }
+
+          message.server = reader.string();
+          continue;
+        case 4:
+          if (tag !== 34) {
+            break;
+          }
+
+          message.type = reader.string();
+          continue;
+      }
+      if ((tag & 7) === 4 || tag === 0) {
+        break;
+      }
+      reader.skipType(tag & 7);
+    }
+    return message;
+  },
+
+  fromJSON(object: any): ToolInfo {
+    return {
+      name: isSet(object.name) ? globalThis.String(object.name) : "",
+      description: isSet(object.description) ? globalThis.String(object.description) : "",
+      server: isSet(object.server) ? globalThis.String(object.server) : "",
+      type: isSet(object.type) ? globalThis.String(object.type) : "",
+    };
+  },
+
+  toJSON(message: ToolInfo): unknown {
+    const obj: any = {};
+    if (message.name !== "") {
+      obj.name = message.name;
+    }
+    if (message.description !== "") {
+      obj.description = message.description;
+    }
+    if (message.server !== "") {
+      obj.server = message.server;
+    }
+    if (message.type !== "") {
+      obj.type = message.type;
+    }
+    return obj;
+  },
+
+  create<I extends Exact<DeepPartial<ToolInfo>, I>>(base?: I): ToolInfo {
+    return ToolInfo.fromPartial(base ?? ({} as any));
+  },
+  fromPartial<I extends Exact<DeepPartial<ToolInfo>, I>>(object: I): ToolInfo {
+    const message = createBaseToolInfo();
+    message.name = object.name ?? "";
+    message.description = object.description ?? "";
+    message.server = object.server ?? "";
+    message.type = object.type ?? "";
+    return message;
+  },
+};
+
+function createBaseGetToolSchemaRequest(): GetToolSchemaRequest {
+  return { agentId: "", toolName: "" };
+}
+
+export const GetToolSchemaRequest = {
+  encode(message: GetToolSchemaRequest, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
+    if (message.agentId !== "") {
+      writer.uint32(10).string(message.agentId);
+    }
+    if (message.toolName !== "") {
+      writer.uint32(18).string(message.toolName);
+    }
+    return writer;
+  },
+
+  decode(input: _m0.Reader | Uint8Array, length?: number): GetToolSchemaRequest {
+    const reader = input instanceof _m0.Reader ? input : _m0.Reader.create(input);
+    let end = length === undefined ? reader.len : reader.pos + length;
+    const message = createBaseGetToolSchemaRequest();
+    while (reader.pos < end) {
+      const tag = reader.uint32();
+      switch (tag >>> 3) {
+        case 1:
+          if (tag !== 10) {
+            break;
+          }
+
+          message.agentId = reader.string();
+          continue;
+        case 2:
+          if (tag !== 18) {
+            break;
+          }
+
+          message.toolName = reader.string();
+          continue;
+      }
+      if ((tag & 7) === 4 || tag === 0) {
+        break;
+      }
+      reader.skipType(tag & 7);
+    }
+    return message;
+  },
+
+  fromJSON(object: any): GetToolSchemaRequest {
+    return {
+      agentId: isSet(object.agentId) ? globalThis.String(object.agentId) : "",
+      toolName: isSet(object.toolName) ? globalThis.String(object.toolName) : "",
+    };
+  },
+
+  toJSON(message: GetToolSchemaRequest): unknown {
+    const obj: any = {};
+    if (message.agentId !== "") {
+      obj.agentId = message.agentId;
+    }
+    if (message.toolName !== "") {
+      obj.toolName = message.toolName;
+    }
+    return obj;
+  },
+
+  create<I extends Exact<DeepPartial<GetToolSchemaRequest>, I>>(base?: I): GetToolSchemaRequest {
+    return GetToolSchemaRequest.fromPartial(base ?? ({} as any));
+  },
+  fromPartial<I extends Exact<DeepPartial<GetToolSchemaRequest>, I>>(object: I): GetToolSchemaRequest {
+    const message = createBaseGetToolSchemaRequest();
+    message.agentId = object.agentId ?? "";
+    message.toolName = object.toolName ?? "";
+    return message;
+  },
+};
+
+function createBaseGetToolSchemaResponse(): GetToolSchemaResponse {
+  return { tool: undefined, inputSchema: undefined };
+}
+
+export const GetToolSchemaResponse = {
+  encode(message: GetToolSchemaResponse, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
+    if (message.tool !== undefined) {
+      ToolInfo.encode(message.tool, writer.uint32(10).fork()).ldelim();
+    }
+    if (message.inputSchema !== undefined) {
+      Struct.encode(Struct.wrap(message.inputSchema), writer.uint32(18).fork()).ldelim();
+    }
+    return writer;
+  },
+
+  decode(input: _m0.Reader | Uint8Array, length?: number): GetToolSchemaResponse {
+    const reader = input instanceof _m0.Reader ? input : _m0.Reader.create(input);
+    let end = length === undefined ? reader.len : reader.pos + length;
+    const message = createBaseGetToolSchemaResponse();
+    while (reader.pos < end) {
+      const tag = reader.uint32();
+      switch (tag >>> 3) {
+        case 1:
+          if (tag !== 10) {
+            break;
+          }
+
+          message.tool = ToolInfo.decode(reader, reader.uint32());
+          continue;
+        case 2:
+          if (tag !== 18) {
+            break;
+          }
+
+          message.inputSchema = Struct.unwrap(Struct.decode(reader, reader.uint32()));
+          continue;
+      }
+      if ((tag & 7) === 4 || tag === 0) {
+        break;
+      }
+      reader.skipType(tag & 7);
+    }
+    return message;
+  },
+
+  fromJSON(object: any): GetToolSchemaResponse {
+    return {
+      tool: isSet(object.tool) ? ToolInfo.fromJSON(object.tool) : undefined,
+      inputSchema: isObject(object.inputSchema) ? object.inputSchema : undefined,
+    };
+  },
+
+  toJSON(message: GetToolSchemaResponse): unknown {
+    const obj: any = {};
+    if (message.tool !== undefined) {
+      obj.tool = ToolInfo.toJSON(message.tool);
+    }
+    if (message.inputSchema !== undefined) {
+      obj.inputSchema = message.inputSchema;
+    }
+    return obj;
+  },
+
+  create<I extends Exact<DeepPartial<GetToolSchemaResponse>, I>>(base?: I): GetToolSchemaResponse {
+    return GetToolSchemaResponse.fromPartial(base ?? ({} as any));
+  },
+  fromPartial<I extends Exact<DeepPartial<GetToolSchemaResponse>, I>>(object: I): GetToolSchemaResponse {
+    const message = createBaseGetToolSchemaResponse();
+    message.tool = (object.tool !== undefined && object.tool !== null)
+      ? ToolInfo.fromPartial(object.tool)
+      : undefined;
+    message.inputSchema = object.inputSchema ?? undefined;
+    return message;
+  },
+};
+
+function createBaseListServersRequest(): ListServersRequest {
+  return { agentId: "" };
+}
+
+export const ListServersRequest = {
+  encode(message: ListServersRequest, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
+    if (message.agentId !== "") {
+      writer.uint32(10).string(message.agentId);
+    }
+    return writer;
+  },
+
+  decode(input: _m0.Reader | Uint8Array, length?: number): ListServersRequest {
+    const reader = input instanceof _m0.Reader ? input : _m0.Reader.create(input);
+    let end = length === undefined ? reader.len : reader.pos + length;
+    const message = createBaseListServersRequest();
+    while (reader.pos < end) {
+      const tag = reader.uint32();
+      switch (tag >>> 3) {
+        case 1:
+          if (tag !== 10) {
+            break;
+          }
+
+          message.agentId = reader.string();
+          continue;
+      }
+      if ((tag & 7) === 4 || tag === 0) {
+        break;
+      }
+      reader.skipType(tag & 7);
+    }
+    return message;
+  },
+
+  fromJSON(object: any): ListServersRequest {
+    return { agentId: isSet(object.agentId) ? globalThis.String(object.agentId) : "" };
+  },
+
+  toJSON(message: ListServersRequest): unknown {
+    const obj: any = {};
+    if (message.agentId !== "") {
+      obj.agentId = message.agentId;
+    }
+    return obj;
+  },
+
+  create<I extends Exact<DeepPartial<ListServersRequest>, I>>(base?: I): ListServersRequest {
+    return ListServersRequest.fromPartial(base ?? ({} as any));
+  },
+  fromPartial<I extends Exact<DeepPartial<ListServersRequest>, I>>(object: I): ListServersRequest {
+    const message = createBaseListServersRequest();
+    message.agentId = object.agentId ?? "";
+    return message;
+  },
+};
+
+function createBaseListServersResponse(): ListServersResponse {
+  return { servers: [] };
+}
+
+export const ListServersResponse = {
+  encode(message: ListServersResponse, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
+    for (const v of message.servers) {
+      ServerInfo.encode(v!, writer.uint32(10).fork()).ldelim();
+    }
+    return writer;
+  },
+
+  decode(input: _m0.Reader | Uint8Array, length?: number): ListServersResponse {
+    const reader = input instanceof _m0.Reader ? input : _m0.Reader.create(input);
+    let end = length === undefined ? reader.len : reader.pos + length;
+    const message = createBaseListServersResponse();
+    while (reader.pos < end) {
+      const tag = reader.uint32();
+      switch (tag >>> 3) {
+        case 1:
+          if (tag !== 10) {
+            break;
+          }
+
+          message.servers.push(ServerInfo.decode(reader, reader.uint32()));
+          continue;
+      }
+      if ((tag & 7) === 4 || tag === 0) {
+        break;
+      }
+      reader.skipType(tag & 7);
+    }
+    return message;
+  },
+
+  fromJSON(object: any): ListServersResponse {
+    return {
+      servers: globalThis.Array.isArray(object?.servers) ? object.servers.map((e: any) => ServerInfo.fromJSON(e)) : [],
+    };
+  },
+
+  toJSON(message: ListServersResponse): unknown {
+    const obj: any = {};
+    if (message.servers?.length) {
+      obj.servers = message.servers.map((e) => ServerInfo.toJSON(e));
+    }
+    return obj;
+  },
+
+  create<I extends Exact<DeepPartial<ListServersResponse>, I>>(base?: I): ListServersResponse {
+    return ListServersResponse.fromPartial(base ?? ({} as any));
+  },
+  fromPartial<I extends Exact<DeepPartial<ListServersResponse>, I>>(object: I): ListServersResponse {
+    const message = createBaseListServersResponse();
+    message.servers = object.servers?.map((e) => ServerInfo.fromPartial(e)) || [];
+    return message;
+  },
+};
+
+function createBaseServerInfo(): ServerInfo {
+  return { name: "", description: "", connected: false, toolCount: 0, promptCount: 0, resourceCount: 0, instructions: "" };
+}
+
+export const ServerInfo = {
+  encode(message: ServerInfo, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
+    if (message.name !== "") {
+      writer.uint32(10).string(message.name);
+    }
+    if (message.description !== "") {
+      writer.uint32(18).string(message.description);
+    }
+    if (message.connected !== false) {
+      writer.uint32(24).bool(message.connected);
+    }
+    if (message.toolCount !== 0) {
+      writer.uint32(32).int32(message.toolCount);
+    }
+    if (message.promptCount !== 0) {
+      writer.uint32(40).int32(message.promptCount);
+    }
+    if (message.resourceCount !== 0) {
+      writer.uint32(48).int32(message.resourceCount);
+    }
+    if (message.instructions !== "") {
+      writer.uint32(58).string(message.instructions);
+    }
+    return writer;
+  },
+
+  decode(input: _m0.Reader | Uint8Array, length?: number): ServerInfo {
+    const reader = input instanceof _m0.Reader ? input : _m0.Reader.create(input);
+    let end = length === undefined ? reader.len : reader.pos + length;
+    const message = createBaseServerInfo();
+    while (reader.pos < end) {
+      const tag = reader.uint32();
+      switch (tag >>> 3) {
+        case 1:
+          if (tag !== 10) {
+            break;
+          }
+
+          message.name = reader.string();
+          continue;
+        case 2:
+          if (tag !== 18) {
+            break;
+          }
+
+          message.description = reader.string();
+          continue;
+        case 3:
+          if (tag !== 24) {
+            break;
+          }
+
+          message.connected = reader.bool();
+          continue;
+        case 4:
+          if (tag !== 32) {
+            break;
+          }
+
+          message.toolCount = reader.int32();
+          continue;
+        case 5:
+          if (tag !== 40) {
+            break;
+          }
+
+          message.promptCount = reader.int32();
+          continue;
+        case 6:
+          if (tag !== 48) {
+            break;
+          }
+
+          message.resourceCount = reader.int32();
+          continue;
+        case 7:
+          if (tag !== 58) {
+            break;
+          }
+
+          message.instructions = reader.string();
+          continue;
+      }
+      if ((tag & 7) === 4 || tag === 0) {
+        break;
+      }
+      reader.skipType(tag & 7);
+    }
+    return message;
+  },
+
+  fromJSON(object: any): ServerInfo {
+    return {
+      name: isSet(object.name) ? globalThis.String(object.name) : "",
+      description: isSet(object.description) ? globalThis.String(object.description) : "",
+      connected: isSet(object.connected) ? globalThis.Boolean(object.connected) : false,
+      toolCount: isSet(object.toolCount) ? globalThis.Number(object.toolCount) : 0,
+      promptCount: isSet(object.promptCount) ? globalThis.Number(object.promptCount) : 0,
+      resourceCount: isSet(object.resourceCount) ? globalThis.Number(object.resourceCount) : 0,
+      instructions: isSet(object.instructions) ? globalThis.String(object.instructions) : "",
+    };
+  },
+
+  toJSON(message: ServerInfo): unknown {
+    const obj: any = {};
+    if (message.name !== "") {
+      obj.name = message.name;
+    }
+    if (message.description !== "") {
+      obj.description = message.description;
+    }
+    if (message.connected !== false) {
+      obj.connected = message.connected;
+    }
+    if (message.toolCount !== 0) {
+      obj.toolCount = Math.round(message.toolCount);
+    }
+    if (message.promptCount !== 0) {
+      obj.promptCount = Math.round(message.promptCount);
+    }
+    if (message.resourceCount !== 0) {
+      obj.resourceCount = Math.round(message.resourceCount);
+    }
+    if (message.instructions !== "") {
+      obj.instructions = message.instructions;
+    }
+    return obj;
+  },
+
+  create<I extends Exact<DeepPartial<ServerInfo>, I>>(base?: I): ServerInfo {
+    return ServerInfo.fromPartial(base ?? ({} as any));
+  },
+  fromPartial<I extends Exact<DeepPartial<ServerInfo>, I>>(object: I): ServerInfo {
+    const message = createBaseServerInfo();
+    message.name = object.name ?? "";
+    message.description = object.description ?? "";
+    message.connected = object.connected ?? false;
+    message.toolCount = object.toolCount ?? 0;
+    message.promptCount = object.promptCount ?? 0;
+    message.resourceCount = object.resourceCount ?? 0;
+    message.instructions = object.instructions ?? "";
+    return message;
+  },
+};
+
+function createBaseListPromptsRequest(): ListPromptsRequest {
+  return { agentId: "", server: "" };
+}
+
+export const ListPromptsRequest = {
+  encode(message: ListPromptsRequest, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
+    if (message.agentId !== "") {
+      writer.uint32(10).string(message.agentId);
+    }
+    if (message.server !== "") {
+      writer.uint32(18).string(message.server);
+    }
+    return writer;
+  },
+
+  decode(input: _m0.Reader | Uint8Array, length?: number): ListPromptsRequest {
+    const reader = input instanceof _m0.Reader ? input : _m0.Reader.create(input);
+    let end = length === undefined ? reader.len : reader.pos + length;
+    const message = createBaseListPromptsRequest();
+    while (reader.pos < end) {
+      const tag = reader.uint32();
+      switch (tag >>> 3) {
+        case 1:
+          if (tag !== 10) {
+            break;
+          }
+
+          message.agentId = reader.string();
+          continue;
+        case 2:
+          if (tag !== 18) {
+            break;
+          }
+
+          message.server = reader.string();
+          continue;
+      }
+      if ((tag & 7) === 4 || tag === 0) {
+        break;
+      }
+      reader.skipType(tag & 7);
+    }
+    return message;
+  },
+
+  fromJSON(object: any): ListPromptsRequest {
+    return {
+      agentId: isSet(object.agentId) ? globalThis.String(object.agentId) : "",
+      server: isSet(object.server) ? globalThis.String(object.server) : "",
+    };
+  },
+
+  toJSON(message: ListPromptsRequest): unknown {
+    const obj: any = {};
+    if (message.agentId !== "") {
+      obj.agentId = message.agentId;
+    }
+    if (message.server !== "") {
+      obj.server = message.server;
+    }
+    return obj;
+  },
+
+  create<I extends Exact<DeepPartial<ListPromptsRequest>, I>>(base?: I): ListPromptsRequest {
+    return ListPromptsRequest.fromPartial(base ?? ({} as any));
+  },
+  fromPartial<I extends Exact<DeepPartial<ListPromptsRequest>, I>>(object: I): ListPromptsRequest {
+    const message = createBaseListPromptsRequest();
+    message.agentId = object.agentId ?? "";
+    message.server = object.server ?? "";
+    return message;
+  },
+};
+
+function createBaseListPromptsResponse(): ListPromptsResponse {
+  return { prompts: [] };
+}
+
+export const ListPromptsResponse = {
+  encode(message: ListPromptsResponse, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
+    for (const v of message.prompts) {
+      PromptInfo.encode(v!, writer.uint32(10).fork()).ldelim();
+    }
+    return writer;
+  },
+
+  decode(input: _m0.Reader | Uint8Array, length?: number): ListPromptsResponse {
+    const reader = input instanceof _m0.Reader ? input : _m0.Reader.create(input);
+    let end = length === undefined ? reader.len : reader.pos + length;
+    const message = createBaseListPromptsResponse();
+    while (reader.pos < end) {
+      const tag = reader.uint32();
+      switch (tag >>> 3) {
+        case 1:
+          if (tag !== 10) {
+            break;
+          }
+
+          message.prompts.push(PromptInfo.decode(reader, reader.uint32()));
+          continue;
+      }
+      if ((tag & 7) === 4 || tag === 0) {
+        break;
+      }
+      reader.skipType(tag & 7);
+    }
+    return message;
+  },
+
+  fromJSON(object: any): ListPromptsResponse {
+    return {
+      prompts: globalThis.Array.isArray(object?.prompts) ? object.prompts.map((e: any) => PromptInfo.fromJSON(e)) : [],
+    };
+  },
+
+  toJSON(message: ListPromptsResponse): unknown {
+    const obj: any = {};
+    if (message.prompts?.length) {
+      obj.prompts = message.prompts.map((e) => PromptInfo.toJSON(e));
+    }
+    return obj;
+  },
+
+  create<I extends Exact<DeepPartial<ListPromptsResponse>, I>>(base?: I): ListPromptsResponse {
+    return ListPromptsResponse.fromPartial(base ?? ({} as any));
+  },
+  fromPartial<I extends Exact<DeepPartial<ListPromptsResponse>, I>>(object: I): ListPromptsResponse {
+    const message = createBaseListPromptsResponse();
+    message.prompts = object.prompts?.map((e) => PromptInfo.fromPartial(e)) || [];
+    return message;
+  },
+};
+
+function createBasePromptInfo(): PromptInfo {
+  return { name: "", description: "", server: "", arguments: [] };
+}
+
+export const PromptInfo = {
+  encode(message: PromptInfo, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
+    if (message.name !== "") {
+      writer.uint32(10).string(message.name);
+    }
+    if (message.description !== "") {
+      writer.uint32(18).string(message.description);
+    }
+    if (message.server !== "") {
+      writer.uint32(26).string(message.server);
+    }
+    for (const v of message.arguments) {
+      PromptArgument.encode(v!, writer.uint32(34).fork()).ldelim();
+    }
+    return writer;
+  },
+
+  decode(input: _m0.Reader | Uint8Array, length?: number): PromptInfo {
+    const reader = input instanceof _m0.Reader ? input : _m0.Reader.create(input);
+    let end = length === undefined ? reader.len : reader.pos + length;
+    const message = createBasePromptInfo();
+    while (reader.pos < end) {
+      const tag = reader.uint32();
+      switch (tag >>> 3) {
+        case 1:
+          if (tag !== 10) {
+            break;
+          }
+
+          message.name = reader.string();
+          continue;
+        case 2:
+          if (tag !== 18) {
+            break;
+          }
+
+          message.description = reader.string();
+          continue;
+        case 3:
+          if (tag !== 26) {
+            break;
+          }
+
+          message.server = reader.string();
+          continue;
+        case 4:
+          if (tag !== 34) {
+            break;
+          }
+
+          message.arguments.push(PromptArgument.decode(reader, reader.uint32()));
+          continue;
+      }
+      if ((tag & 7) === 4 || tag === 0) {
+        break;
+      }
+      reader.skipType(tag & 7);
+    }
+    return message;
+  },
+
+  fromJSON(object: any): PromptInfo {
+    return {
+      name: isSet(object.name) ? globalThis.String(object.name) : "",
+      description: isSet(object.description) ? globalThis.String(object.description) : "",
+      server: isSet(object.server) ? globalThis.String(object.server) : "",
+      arguments: globalThis.Array.isArray(object?.arguments) ? object.arguments.map((e: any) => PromptArgument.fromJSON(e)) : [],
+    };
+  },
+
+  toJSON(message: PromptInfo): unknown {
+    const obj: any = {};
+    if (message.name !== "") {
+      obj.name = message.name;
+    }
+    if (message.description !== "") {
+      obj.description = message.description;
+    }
+    if (message.server !== "") {
+      obj.server = message.server;
+    }
+    if (message.arguments?.length) {
+      obj.arguments = message.arguments.map((e) => PromptArgument.toJSON(e));
+    }
+    return obj;
+  },
+
+  create<I extends Exact<DeepPartial<PromptInfo>, I>>(base?: I): PromptInfo {
+    return PromptInfo.fromPartial(base ?? ({} as any));
+  },
+  fromPartial<I extends Exact<DeepPartial<PromptInfo>, I>>(object: I): PromptInfo {
+    const message = createBasePromptInfo();
+    message.name = object.name ?? "";
+    message.description = object.description ?? "";
+    message.server = object.server ?? "";
+    message.arguments = object.arguments?.map((e) => PromptArgument.fromPartial(e)) || [];
+    return message;
+  },
+};
+
+function createBasePromptArgument(): PromptArgument {
+  return { name: "", description: "", required: false };
+}
+
+export const PromptArgument = {
+  encode(message: PromptArgument, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
+    if (message.name !== "") {
+      writer.uint32(10).string(message.name);
+    }
+    if (message.description !== "") {
+      writer.uint32(18).string(message.description);
+    }
+    if (message.required !== false) {
+      writer.uint32(24).bool(message.required);
+    }
+    return writer;
+  },
+
+  decode(input: _m0.Reader | Uint8Array, length?: number): PromptArgument {
+    const reader = input instanceof _m0.Reader ? input : _m0.Reader.create(input);
+    let end = length === undefined ? reader.len : reader.pos + length;
+    const message = createBasePromptArgument();
+    while (reader.pos < end) {
+      const tag = reader.uint32();
+      switch (tag >>> 3) {
+        case 1:
+          if (tag !== 10) {
+            break;
+          }
+
+          message.name = reader.string();
+          continue;
+        case 2:
+          if (tag !== 18) {
+            break;
+          }
+
+          message.description = reader.string();
+          continue;
+        case 3:
+          if (tag !== 24) {
+            break;
+          }
+
+          message.required = reader.bool();
+          continue;
+      }
+      if ((tag & 7) === 4 || tag === 0) {
+        break;
+      }
+      reader.skipType(tag & 7);
+    }
+    return message;
+  },
+
+  fromJSON(object: any): PromptArgument {
+    return {
+      name: isSet(object.name) ? globalThis.String(object.name) : "",
+      description: isSet(object.description) ? globalThis.String(object.description) : "",
+      required: isSet(object.required) ? globalThis.Boolean(object.required) : false,
+    };
+  },
+
+  toJSON(message: PromptArgument): unknown {
+    const obj: any = {};
+    if (message.name !== "") {
+      obj.name = message.name;
+    }
+    if (message.description !== "") {
+      obj.description = message.description;
+    }
+    if (message.required !== false) {
+      obj.required = message.required;
+    }
+    return obj;
+  },
+
+  create<I extends Exact<DeepPartial<PromptArgument>, I>>(base?: I): PromptArgument {
+    return PromptArgument.fromPartial(base ?? ({} as any));
+  },
+  fromPartial<I extends Exact<DeepPartial<PromptArgument>, I>>(object: I): PromptArgument {
+    const message = createBasePromptArgument();
+    message.name = object.name ?? "";
+    message.description = object.description ?? "";
+    message.required = object.required ?? false;
+    return message;
+  },
+};
+
+function createBaseConversationRequest(): ConversationRequest {
+  return { agentId: "", question: undefined, toolResult: undefined, cancel: undefined };
+}
+
+export const ConversationRequest = {
+  encode(message: ConversationRequest, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
+    if (message.agentId !== "") {
+      writer.uint32(10).string(message.agentId);
+    }
+    if (message.question !== undefined) {
+      QuestionMessage.encode(message.question, writer.uint32(18).fork()).ldelim();
+    }
+    if (message.toolResult !== undefined) {
+      ToolResultMessage.encode(message.toolResult, writer.uint32(26).fork()).ldelim();
+    }
+    if (message.cancel !== undefined) {
+      CancelMessage.encode(message.cancel, writer.uint32(34).fork()).ldelim();
+    }
+    return writer;
+  },
+
+  decode(input: _m0.Reader | Uint8Array, length?: number): ConversationRequest {
+    const reader = input instanceof _m0.Reader ? input : _m0.Reader.create(input);
+    let end = length === undefined ? reader.len : reader.pos + length;
+    const message = createBaseConversationRequest();
+    while (reader.pos < end) {
+      const tag = reader.uint32();
+      switch (tag >>> 3) {
+        case 1:
+          if (tag !== 10) {
+            break;
+          }
+
+          message.agentId = reader.string();
+          continue;
+        case 2:
+          if (tag !== 18) {
+            break;
+          }
+
+          message.question = QuestionMessage.decode(reader, reader.uint32());
+          continue;
+        case 3:
+          if (tag !== 26) {
+            break;
+          }
+
+          message.toolResult = ToolResultMessage.decode(reader, reader.uint32());
+          continue;
+        case 4:
+          if (tag !== 34) {
+            break;
+          }
+
+          message.cancel = CancelMessage.decode(reader, reader.uint32());
+          continue;
+      }
+      if ((tag & 7) === 4 || tag === 0) {
+        break;
+      }
+      reader.skipType(tag & 7);
+    }
+    return message;
+  },
+
+  fromJSON(object: any): ConversationRequest {
+    return {
+      agentId: isSet(object.agentId) ? globalThis.String(object.agentId) : "",
+      question: isSet(object.question) ? QuestionMessage.fromJSON(object.question) : undefined,
+      toolResult: isSet(object.toolResult) ? ToolResultMessage.fromJSON(object.toolResult) : undefined,
+      cancel: isSet(object.cancel) ? CancelMessage.fromJSON(object.cancel) : undefined,
+    };
+  },
+
+  toJSON(message: ConversationRequest): unknown {
+    const obj: any = {};
+    if (message.agentId !== "") {
+      obj.agentId = message.agentId;
+    }
+    if (message.question !== undefined) {
+      obj.question = QuestionMessage.toJSON(message.question);
+    }
+    if (message.toolResult !== undefined) {
+      obj.toolResult = ToolResultMessage.toJSON(message.toolResult);
+    }
+    if (message.cancel !== undefined) {
+      obj.cancel = CancelMessage.toJSON(message.cancel);
+    }
+    return obj;
+  },
+
+  create<I extends Exact<DeepPartial<ConversationRequest>, I>>(base?: I): ConversationRequest {
+    return ConversationRequest.fromPartial(base ?? ({} as any));
+  },
+  fromPartial<I extends Exact<DeepPartial<ConversationRequest>, I>>(object: I): ConversationRequest {
+    const message = createBaseConversationRequest();
+    message.agentId = object.agentId ?? "";
+    message.question = (object.question !== undefined && object.question !== null)
+      ? QuestionMessage.fromPartial(object.question)
+      : undefined;
+    message.toolResult = (object.toolResult !== undefined && object.toolResult !== null)
+      ? ToolResultMessage.fromPartial(object.toolResult)
+      : undefined;
+    message.cancel = (object.cancel !== undefined && object.cancel !== null)
+      ? CancelMessage.fromPartial(object.cancel)
+      : undefined;
+    return message;
+  },
+};
+
+function createBaseQuestionMessage(): QuestionMessage {
+  return { text: "", history: [], priority: "" };
+}
+
+export const QuestionMessage = {
+  encode(message: QuestionMessage, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
+    if (message.text !== "") {
+      writer.uint32(10).string(message.text);
+    }
+    for (const v of message.history) {
+      Message.encode(v!, writer.uint32(18).fork()).ldelim();
+    }
+    if (message.priority !== "") {
+      writer.uint32(26).string(message.priority);
+    }
+    return writer;
+  },
+
+  decode(input: _m0.Reader | Uint8Array, length?: number): QuestionMessage {
+    const reader = input instanceof _m0.Reader ? input : _m0.Reader.create(input);
+    let end = length === undefined ? reader.len : reader.pos + length;
+    const message = createBaseQuestionMessage();
+    while (reader.pos < end) {
+      const tag = reader.uint32();
+      switch (tag >>> 3) {
+        case 1:
+          if (tag !== 10) {
+            break;
+          }
+
+          message.text = reader.string();
+          continue;
+        case 2:
+          if (tag !== 18) {
+            break;
+          }
+
+          message.history.push(Message.decode(reader, reader.uint32()));
+          continue;
+        case 3:
+          if (tag !== 26) {
+            break;
+          }
+
+          message.priority = reader.string();
+          continue;
+      }
+      if ((tag & 7) === 4 || tag === 0) {
+        break;
+      }
+      reader.skipType(tag & 7);
+    }
+    return message;
+  },
+
+  fromJSON(object: any): QuestionMessage {
+    return {
+      text: isSet(object.text) ? globalThis.String(object.text) : "",
+      history: globalThis.Array.isArray(object?.history) ? object.history.map((e: any) => Message.fromJSON(e)) : [],
+      priority: isSet(object.priority) ? globalThis.String(object.priority) : "",
+    };
+  },
+
+  toJSON(message: QuestionMessage): unknown {
+    const obj: any = {};
+    if (message.text !== "") {
+      obj.text = message.text;
+    }
+    if (message.history?.length) {
+      obj.history = message.history.map((e) => Message.toJSON(e));
+    }
+    if (message.priority !== "") {
+      obj.priority = message.priority;
+    }
+    return obj;
+  },
+
+  create<I extends Exact<DeepPartial<QuestionMessage>, I>>(base?: I): QuestionMessage {
+    return QuestionMessage.fromPartial(base ?? ({} as any));
+  },
+  fromPartial<I extends Exact<DeepPartial<QuestionMessage>, I>>(object: I): QuestionMessage {
+    const message = createBaseQuestionMessage();
+    message.text = object.text ?? "";
+    message.history = object.history?.map((e) => Message.fromPartial(e)) || [];
+    message.priority = object.priority ?? "";
+    return message;
+  },
+};
+
+function createBaseToolResultMessage(): ToolResultMessage {
+  return { callId: "", success: false, result: "", error: undefined, durationMs: 0 };
+}
+
+export const ToolResultMessage = {
+  encode(message: ToolResultMessage, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
+    if (message.callId !== "") {
+      writer.uint32(10).string(message.callId);
+    }
+    if (message.success !== false) {
+      writer.uint32(16).bool(message.success);
+    }
+    if (message.result !== "") {
+      writer.uint32(26).string(message.result);
+    }
+    if (message.error !== undefined) {
+      ToolError.encode(message.error, writer.uint32(34).fork()).ldelim();
+    }
+    if (message.durationMs !== 0) {
+      writer.uint32(40).int64(message.durationMs);
+    }
+    return writer;
+  },
+
+  decode(input: _m0.Reader | Uint8Array, length?: number): ToolResultMessage {
+    const reader = input instanceof _m0.Reader ? input : _m0.Reader.create(input);
+    let end = length === undefined ? reader.len : reader.pos + length;
+    const message = createBaseToolResultMessage();
+    while (reader.pos < end) {
+      const tag = reader.uint32();
+      switch (tag >>> 3) {
+        case 1:
+          if (tag !== 10) {
+            break;
+          }
+
+          message.callId = reader.string();
+          continue;
+        case 2:
+          if (tag !== 16) {
+            break;
+          }
+
+          message.success = reader.bool();
+          continue;
+        case 3:
+          if (tag !== 26) {
+            break;
+          }
+
+          message.result = reader.string();
+          continue;
+        case 4:
+          if (tag !== 34) {
+            break;
+          }
+
+          message.error = ToolError.decode(reader, reader.uint32());
+          continue;
+        case 5:
+          if (tag !== 40) {
+            break;
+          }
+
+          message.durationMs = longToNumber(reader.int64() as Long);
+          continue;
+      }
+      if ((tag & 7) === 4 || tag === 0) {
+        break;
+      }
+      reader.skipType(tag & 7);
+    }
+    return message;
+  },
+
+  fromJSON(object: any): ToolResultMessage {
+    return {
+      callId: isSet(object.callId) ? globalThis.String(object.callId) : "",
+      success: isSet(object.success) ? globalThis.Boolean(object.success) : false,
+      result: isSet(object.result) ? globalThis.String(object.result) : "",
+      error: isSet(object.error) ? ToolError.fromJSON(object.error) : undefined,
+      durationMs: isSet(object.durationMs) ? globalThis.Number(object.durationMs) : 0,
+    };
+  },
+
+  toJSON(message: ToolResultMessage): unknown {
+    const obj: any = {};
+    if (message.callId !== "") {
+      obj.callId = message.callId;
+    }
+    if (message.success !== false) {
+      obj.success = message.success;
+    }
+    if (message.result !== "") {
+      obj.result = message.result;
+    }
+    if (message.error !== undefined) {
+      obj.error = ToolError.toJSON(message.error);
+    }
+    if (message.durationMs !== 0) {
+      obj.durationMs = Math.round(message.durationMs);
+    }
+    return obj;
+  },
+
+  create<I extends Exact<DeepPartial<ToolResultMessage>, I>>(base?: I): ToolResultMessage {
+    return ToolResultMessage.fromPartial(base ?? ({} as any));
+  },
+  fromPartial<I extends Exact<DeepPartial<ToolResultMessage>, I>>(object: I): ToolResultMessage {
+    const message = createBaseToolResultMessage();
+    message.callId = object.callId ?? "";
+    message.success = object.success ?? false;
+    message.result = object.result ?? "";
+    message.error = (object.error !== undefined && object.error !== null)
+      ? ToolError.fromPartial(object.error)
+      : undefined;
+    message.durationMs = object.durationMs ?? 0;
+    return message;
+  },
+};
+
+function createBaseToolError(): ToolError {
+  return { code: "", message: "", details: undefined };
+}
+
+export const ToolError = {
+  encode(message: ToolError, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
+    if (message.code !== "") {
+      writer.uint32(10).string(message.code);
+    }
+    if (message.message !== "") {
+      writer.uint32(18).string(message.message);
+    }
+    if (message.details !== undefined) {
+      Struct.encode(Struct.wrap(message.details), writer.uint32(26).fork()).ldelim();
+    }
+    return writer;
+  },
+
+  decode(input: _m0.Reader | Uint8Array, length?: number): ToolError {
+    const reader = input instanceof _m0.Reader ? input : _m0.Reader.create(input);
+    let end = length === undefined ? reader.len : reader.pos + length;
+    const message = createBaseToolError();
+    while (reader.pos < end) {
+      const tag = reader.uint32();
+      switch (tag >>> 3) {
+        case 1:
+          if (tag !== 10) {
+            break;
+          }
+
+          message.code = reader.string();
+          continue;
+        case 2:
+          if (tag !== 18) {
+            break;
+          }
+
+          message.message = reader.string();
+          continue;
+        case 3:
+          if (tag !== 26) {
+            break;
+          }
+
+          message.details = Struct.unwrap(Struct.decode(reader, reader.uint32()));
+          continue;
+      }
+      if ((tag & 7) === 4 || tag === 0) {
+        break;
+      }
+      reader.skipType(tag & 7);
+    }
+    return message;
+  },
+
+  fromJSON(object: any): ToolError {
+    return {
+      code: isSet(object.code) ? globalThis.String(object.code) : "",
+      message: isSet(object.message) ? globalThis.String(object.message) : "",
+      details: isObject(object.details) ? object.details : undefined,
+    };
+  },
+
+  toJSON(message: ToolError): unknown {
+    const obj: any = {};
+    if (message.code !== "") {
+      obj.code = message.code;
+    }
+    if (message.message !== "") {
+      obj.message = message.message;
+    }
+    if (message.details !== undefined) {
+      obj.details = message.details;
+    }
+    return obj;
+  },
+
+  create<I extends Exact<DeepPartial<ToolError>, I>>(base?: I): ToolError {
+    return ToolError.fromPartial(base ?? ({} as any));
+  },
+  fromPartial<I extends Exact<DeepPartial<ToolError>, I>>(object: I): ToolError {
+    const message = createBaseToolError();
+    message.code = object.code ?? "";
+    message.message = object.message ?? "";
+    message.details = object.details ?? undefined;
+    return message;
+  },
+};
+
+function createBaseCancelMessage(): CancelMessage {
+  return { reason: "" };
+}
+
+export const CancelMessage = {
+  encode(message: CancelMessage, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
+    if (message.reason !== "") {
+      writer.uint32(10).string(message.reason);
+    }
+    return writer;
+  },
+
+  decode(input: _m0.Reader | Uint8Array, length?: number): CancelMessage {
+    const reader = input instanceof _m0.Reader ? input : _m0.Reader.create(input);
+    let end = length === undefined ? reader.len : reader.pos + length;
+    const message = createBaseCancelMessage();
+    while (reader.pos < end) {
+      const tag = reader.uint32();
+      switch (tag >>> 3) {
+        case 1:
+          if (tag !== 10) {
+            break;
+          }
+
+          message.reason = reader.string();
+          continue;
+      }
+      if ((tag & 7) === 4 || tag === 0) {
+        break;
+      }
+      reader.skipType(tag & 7);
+    }
+    return message;
+  },
+
+  fromJSON(object: any): CancelMessage {
+    return { reason: isSet(object.reason) ? globalThis.String(object.reason) : "" };
+  },
+
+  toJSON(message: CancelMessage): unknown {
+    const obj: any = {};
+    if (message.reason !== "") {
+      obj.reason = message.reason;
+    }
+    return obj;
+  },
+
+  create<I extends Exact<DeepPartial<CancelMessage>, I>>(base?: I): CancelMessage {
+    return CancelMessage.fromPartial(base ?? ({} as any));
+  },
+  fromPartial<I extends Exact<DeepPartial<CancelMessage>, I>>(object: I): CancelMessage {
+    const message = createBaseCancelMessage();
+    message.reason = object.reason ?? "";
+    return message;
+  },
+};
+
+function createBaseConversationResponse(): ConversationResponse {
+  return {
+    textChunk: undefined,
+    toolCall: undefined,
+    agentEvent: undefined,
+    finalResponse: undefined,
+    error: undefined,
+  };
+}
+
+export const ConversationResponse = {
+  encode(message: ConversationResponse, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
+    if (message.textChunk !== undefined) {
+      TextChunkEvent.encode(message.textChunk, writer.uint32(10).fork()).ldelim();
+    }
+    if (message.toolCall !== undefined) {
+      ToolCallEvent.encode(message.toolCall, writer.uint32(18).fork()).ldelim();
+    }
+    if (message.agentEvent !== undefined) {
+      AgentEvent.encode(message.agentEvent, writer.uint32(26).fork()).ldelim();
+    }
+    if (message.finalResponse !== undefined) {
+      FinalResponse.encode(message.finalResponse, writer.uint32(34).fork()).ldelim();
+    }
+    if (message.error !== undefined) {
+      ErrorEvent.encode(message.error, writer.uint32(42).fork()).ldelim();
+    }
+    return writer;
+  },
+
+  decode(input: _m0.Reader | Uint8Array, length?: number): ConversationResponse {
+    const reader = input instanceof _m0.Reader ? input : _m0.Reader.create(input);
+    let end = length === undefined ? reader.len : reader.pos + length;
+    const message = createBaseConversationResponse();
+    while (reader.pos < end) {
+      const tag = reader.uint32();
+      switch (tag >>> 3) {
+        case 1:
+          if (tag !== 10) {
+            break;
+          }
+
+          message.textChunk = TextChunkEvent.decode(reader, reader.uint32());
+          continue;
+        case 2:
+          if (tag !== 18) {
+            break;
+          }
+
+          message.toolCall = ToolCallEvent.decode(reader, reader.uint32());
+          continue;
+        case 3:
+          if (tag !== 26) {
+            break;
+          }
+
+          message.agentEvent = AgentEvent.decode(reader, reader.uint32());
+          continue;
+        case 4:
+          if (tag !== 34) {
+            break;
+          }
+
+          message.finalResponse = FinalResponse.decode(reader, reader.uint32());
+          continue;
+        case 5:
+          if (tag !== 42) {
+            break;
+          }
+
+          message.error = ErrorEvent.decode(reader, reader.uint32());
+          continue;
+      }
+      if ((tag & 7) === 4 || tag === 0) {
+        break;
+      }
+      reader.skipType(tag & 7);
+    }
+    return message;
+  },
+
+  fromJSON(object: any): ConversationResponse {
+    return {
+      textChunk: isSet(object.textChunk) ? TextChunkEvent.fromJSON(object.textChunk) : undefined,
+      toolCall: isSet(object.toolCall) ? ToolCallEvent.fromJSON(object.toolCall) : undefined,
+      agentEvent: isSet(object.agentEvent) ? AgentEvent.fromJSON(object.agentEvent) : undefined,
+      finalResponse: isSet(object.finalResponse) ? FinalResponse.fromJSON(object.finalResponse) : undefined,
+      error: isSet(object.error) ? ErrorEvent.fromJSON(object.error) : undefined,
+    };
+  },
+
+  toJSON(message: ConversationResponse): unknown {
+    const obj: any = {};
+    if (message.textChunk !== undefined) {
+      obj.textChunk = TextChunkEvent.toJSON(message.textChunk);
+    }
+    if (message.toolCall !== undefined) {
+      obj.toolCall = ToolCallEvent.toJSON(message.toolCall);
+    }
+    if (message.agentEvent !== undefined) {
+      obj.agentEvent = AgentEvent.toJSON(message.agentEvent);
+    }
+    if (message.finalResponse !== undefined) {
+      obj.finalResponse = FinalResponse.toJSON(message.finalResponse);
+    }
+    if (message.error !== undefined) {
+      obj.error = ErrorEvent.toJSON(message.error);
+    }
+    return obj;
+  },
+
+  create<I extends Exact<DeepPartial<ConversationResponse>, I>>(base?: I): ConversationResponse {
+    return ConversationResponse.fromPartial(base ?? ({} as any));
+  },
+  fromPartial<I extends Exact<DeepPartial<ConversationResponse>, I>>(object: I): ConversationResponse {
+    const message = createBaseConversationResponse();
+    message.textChunk = (object.textChunk !== undefined && object.textChunk !== null)
+      ? TextChunkEvent.fromPartial(object.textChunk)
+      : undefined;
+    message.toolCall = (object.toolCall !== undefined && object.toolCall !== null)
+      ? ToolCallEvent.fromPartial(object.toolCall)
+      : undefined;
+    message.agentEvent = (object.agentEvent !== undefined && object.agentEvent !== null)
+      ? AgentEvent.fromPartial(object.agentEvent)
+      : undefined;
+    message.finalResponse = (object.finalResponse !== undefined && object.finalResponse !== null)
+      ? FinalResponse.fromPartial(object.finalResponse)
+      : undefined;
+    message.error = (object.error !== undefined && object.error !== null)
+      ? ErrorEvent.fromPartial(object.error)
+      : undefined;
+    return message;
+  },
+};
+
+function createBaseTextChunkEvent(): TextChunkEvent {
+  return { text: "", isThinking: false };
+}
+
+export const TextChunkEvent = {
+  encode(message: TextChunkEvent, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
+    if (message.text !== "") {
+      writer.uint32(10).string(message.text);
+    }
+    if (message.isThinking !== false) {
+      writer.uint32(16).bool(message.isThinking);
+    }
+    return writer;
+  },
+
+  decode(input: _m0.Reader | Uint8Array, length?: number): TextChunkEvent {
+    const reader = input instanceof _m0.Reader ? input : _m0.Reader.create(input);
+    let end = length === undefined ? reader.len : reader.pos + length;
+    const message = createBaseTextChunkEvent();
+    while (reader.pos < end) {
+      const tag = reader.uint32();
+      switch (tag >>> 3) {
+        case 1:
+          if (tag !== 10) {
+            break;
+          }
+
+          message.text = reader.string();
+          continue;
+        case 2:
+          if (tag !== 16) {
+            break;
+          }
+
+          message.isThinking = reader.bool();
+          continue;
+      }
+      if ((tag & 7) === 4 || tag === 0) {
+        break;
+      }
+      reader.skipType(tag & 7);
+    }
+    return message;
+  },
+
+  fromJSON(object: any): TextChunkEvent {
+    return {
+      text: isSet(object.text) ? globalThis.String(object.text) : "",
+      isThinking: isSet(object.isThinking) ? globalThis.Boolean(object.isThinking) : false,
+    };
+  },
+
+  toJSON(message: TextChunkEvent): unknown {
+    const obj: any = {};
+    if (message.text !== "") {
+      obj.text = message.text;
+    }
+    if (message.isThinking !== false) {
+      obj.isThinking = message.isThinking;
+    }
+    return obj;
+  },
+
+  create<I extends Exact<DeepPartial<TextChunkEvent>, I>>(base?: I): TextChunkEvent {
+    return TextChunkEvent.fromPartial(base ?? ({} as any));
+  },
+  fromPartial<I extends Exact<DeepPartial<TextChunkEvent>, I>>(object: I): TextChunkEvent {
+    const message = createBaseTextChunkEvent();
+    message.text = object.text ?? "";
+    message.isThinking = object.isThinking ?? false;
+    return message;
+  },
+};
+
+function createBaseToolCallEvent(): ToolCallEvent {
+  return { callId: "", toolName: "", arguments: undefined, timeoutMs: 0 };
+}
+
+export const ToolCallEvent = {
+  encode(message: ToolCallEvent, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
+    if (message.callId !== "") {
+      writer.uint32(10).string(message.callId);
+    }
+    if (message.toolName !== "") {
+      writer.uint32(18).string(message.toolName);
+    }
+    if (message.arguments !== undefined) {
+      Struct.encode(Struct.wrap(message.arguments), writer.uint32(26).fork()).ldelim();
+    }
+    if (message.timeoutMs !== 0) {
+      writer.uint32(32).int32(message.timeoutMs);
+    }
+    return writer;
+  },
+
+  decode(input: _m0.Reader | Uint8Array, length?: number): ToolCallEvent {
+    const reader = input instanceof _m0.Reader ? input : _m0.Reader.create(input);
+    let end = length === undefined ? reader.len : reader.pos + length;
+    const message = createBaseToolCallEvent();
+    while (reader.pos < end) {
+      const tag = reader.uint32();
+      switch (tag >>> 3) {
+        case 1:
+          if (tag !== 10) {
+            break;
+          }
+
+          message.callId = reader.string();
+          continue;
+        case 2:
+          if (tag !== 18) {
+            break;
+          }
+
+          message.toolName = reader.string();
+          continue;
+        case 3:
+          if (tag !== 26) {
+            break;
+          }
+
+          message.arguments = Struct.unwrap(Struct.decode(reader, reader.uint32()));
+          continue;
+        case 4:
+          if (tag !== 32) {
+            break;
+          }
+
+          message.timeoutMs = reader.int32();
+          continue;
+      }
+      if ((tag & 7) === 4 || tag === 0) {
+        break;
+      }
+      reader.skipType(tag & 7);
+    }
+    return message;
+  },
+
+  fromJSON(object: any): ToolCallEvent {
+    return {
+      callId: isSet(object.callId) ? globalThis.String(object.callId) : "",
+      toolName: isSet(object.toolName) ? globalThis.String(object.toolName) : "",
+      arguments: isObject(object.arguments) ? object.arguments : undefined,
+      timeoutMs: isSet(object.timeoutMs) ? globalThis.Number(object.timeoutMs) : 0,
+    };
+  },
+
+  toJSON(message: ToolCallEvent): unknown {
+    const obj: any = {};
+    if (message.callId !== "") {
+      obj.callId = message.callId;
+    }
+    if (message.toolName !== "") {
+      obj.toolName = message.toolName;
+    }
+    if (message.arguments !== undefined) {
+      obj.arguments = message.arguments;
+    }
+    if (message.timeoutMs !== 0) {
+      obj.timeoutMs = Math.round(message.timeoutMs);
+    }
+    return obj;
+  },
+
+  create<I extends Exact<DeepPartial<ToolCallEvent>, I>>(base?: I): ToolCallEvent {
+    return ToolCallEvent.fromPartial(base ?? ({} as any));
+  },
+  fromPartial<I extends Exact<DeepPartial<ToolCallEvent>, I>>(object: I): ToolCallEvent {
+    const message = createBaseToolCallEvent();
+    message.callId = object.callId ?? "";
+    message.toolName = object.toolName ?? "";
+    message.arguments = object.arguments ?? undefined;
+    message.timeoutMs = object.timeoutMs ?? 0;
+    return message;
+  },
+};
+
+function createBaseFinalResponse(): FinalResponse {
+  return { response: "", updatedMessages: [], tokenUsage: undefined, durationMs: 0, artifacts: [] };
+}
+
+export const FinalResponse = {
+  encode(message: FinalResponse, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
+    if (message.response !== "") {
+      writer.uint32(10).string(message.response);
+    }
+    for (const v of message.updatedMessages) {
+      Message.encode(v!, writer.uint32(18).fork()).ldelim();
+    }
+    if (message.tokenUsage !== undefined) {
+      TokenUsage.encode(message.tokenUsage, writer.uint32(26).fork()).ldelim();
+    }
+    if (message.durationMs !== 0) {
+      writer.uint32(32).int64(message.durationMs);
+    }
+    for (const v of message.artifacts) {
+      Artifact.encode(v!, writer.uint32(42).fork()).ldelim();
+    }
+    return writer;
+  },
+
+  decode(input: _m0.Reader | Uint8Array, length?: number): FinalResponse {
+    const reader = input instanceof _m0.Reader ? input : _m0.Reader.create(input);
+    let end = length === undefined ? reader.len : reader.pos + length;
+    const message = createBaseFinalResponse();
+    while (reader.pos < end) {
+      const tag = reader.uint32();
+      switch (tag >>> 3) {
+        case 1:
+          if (tag !== 10) {
+            break;
+          }
+
+          message.response = reader.string();
+          continue;
+        case 2:
+          if (tag !== 18) {
+            break;
+          }
+
+          message.updatedMessages.push(Message.decode(reader, reader.uint32()));
+          continue;
+        case 3:
+          if (tag !== 26) {
+            break;
+          }
+
+          message.tokenUsage = TokenUsage.decode(reader, reader.uint32());
+          continue;
+        case 4:
+          if (tag !== 32) {
+            break;
+          }
+
+          message.durationMs = longToNumber(reader.int64() as Long);
+          continue;
+        case 5:
+          if (tag !== 42) {
+            break;
+          }
+
+          message.artifacts.push(Artifact.decode(reader, reader.uint32()));
+          continue;
+      }
+      if ((tag & 7) === 4 || tag === 0) {
+        break;
+      }
+      reader.skipType(tag & 7);
+    }
+    return message;
+  },
+
+  fromJSON(object: any): FinalResponse {
+    return {
+      response: isSet(object.response) ? globalThis.String(object.response) : "",
+      updatedMessages: globalThis.Array.isArray(object?.updatedMessages)
+        ? object.updatedMessages.map((e: any) => Message.fromJSON(e))
+        : [],
+      tokenUsage: isSet(object.tokenUsage) ? TokenUsage.fromJSON(object.tokenUsage) : undefined,
+      durationMs: isSet(object.durationMs) ? globalThis.Number(object.durationMs) : 0,
+      artifacts: globalThis.Array.isArray(object?.artifacts)
+        ? object.artifacts.map((e: any) => Artifact.fromJSON(e))
+        : [],
+    };
+  },
+
+  toJSON(message: FinalResponse): unknown {
+    const obj: any = {};
+    if (message.response !== "") {
+      obj.response = message.response;
+    }
+    if (message.updatedMessages?.length) {
+      obj.updatedMessages = message.updatedMessages.map((e) => Message.toJSON(e));
+    }
+    if (message.tokenUsage !== undefined) {
+      obj.tokenUsage = TokenUsage.toJSON(message.tokenUsage);
+    }
+    if (message.durationMs !== 0) {
+      obj.durationMs = Math.round(message.durationMs);
+    }
+    if (message.artifacts?.length) {
+      obj.artifacts = message.artifacts.map((e) => Artifact.toJSON(e));
+    }
+    return obj;
+  },
+
+  create<I extends Exact<DeepPartial<FinalResponse>, I>>(base?: I): FinalResponse {
+    return FinalResponse.fromPartial(base ?? ({} as any));
+  },
+  fromPartial<I extends Exact<DeepPartial<FinalResponse>, I>>(object: I): FinalResponse {
+    const message = createBaseFinalResponse();
+    message.response = object.response ?? "";
+    message.updatedMessages = object.updatedMessages?.map((e) => Message.fromPartial(e)) || [];
+    message.tokenUsage = (object.tokenUsage !== undefined && object.tokenUsage !== null)
+      ? TokenUsage.fromPartial(object.tokenUsage)
+      : undefined;
+    message.durationMs = object.durationMs ?? 0;
+    message.artifacts = object.artifacts?.map((e) => Artifact.fromPartial(e)) || [];
+    return message;
+  },
+};
+
+function createBaseErrorEvent(): ErrorEvent {
+  return { code: "", message: "", details: undefined, fatal: false };
+}
+
+export const ErrorEvent = {
+  encode(message: ErrorEvent, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
+    if (message.code !== "") {
+      writer.uint32(10).string(message.code);
+    }
+    if (message.message !== "") {
+      writer.uint32(18).string(message.message);
+    }
+    if (message.details !== undefined) {
+      Struct.encode(Struct.wrap(message.details), writer.uint32(26).fork()).ldelim();
+    }
+    if (message.fatal !== false) {
+      writer.uint32(32).bool(message.fatal);
+    }
+    return writer;
+  },
+
+  decode(input: _m0.Reader | Uint8Array, length?: number): ErrorEvent {
+    const reader = input instanceof _m0.Reader ? input : _m0.Reader.create(input);
+    let end = length === undefined ? reader.len : reader.pos + length;
+    const message = createBaseErrorEvent();
+    while (reader.pos < end) {
+      const tag = reader.uint32();
+      switch (tag >>> 3) {
+        case 1:
+          if (tag !== 10) {
+            break;
+          }
+
+          message.code = reader.string();
+          continue;
+        case 2:
+          if (tag !== 18) {
+            break;
+          }
+
+          message.message = reader.string();
+          continue;
+        case 3:
+          if (tag !== 26) {
+            break;
+          }
+
+          message.details = Struct.unwrap(Struct.decode(reader, reader.uint32()));
+          continue;
+        case 4:
+          if (tag !== 32) {
+            break;
+          }
+
+          message.fatal = reader.bool();
+          continue;
+      }
+      if ((tag & 7) === 4 || tag === 0) {
+        break;
+      }
+      reader.skipType(tag & 7);
+    }
+    return message;
+  },
+
+  fromJSON(object: any): ErrorEvent {
+    return {
+      code: isSet(object.code) ? globalThis.String(object.code) : "",
+      message: isSet(object.message) ? globalThis.String(object.message) : "",
+      details: isObject(object.details) ? object.details : undefined,
+      fatal: isSet(object.fatal) ? globalThis.Boolean(object.fatal) : false,
+    };
+  },
+
+  toJSON(message: ErrorEvent): unknown {
+    const obj: any = {};
+    if (message.code !== "") {
+      obj.code = message.code;
+    }
+    if (message.message !== "") {
+      obj.message = message.message;
+    }
+    if (message.details !== undefined) {
+      obj.details = message.details;
+    }
+    if (message.fatal !== false) {
+      obj.fatal = message.fatal;
+    }
+    return obj;
+  },
+
+  create<I extends Exact<DeepPartial<ErrorEvent>, I>>(base?: I): ErrorEvent {
+    return ErrorEvent.fromPartial(base ?? ({} as any));
+  },
+  fromPartial<I extends Exact<DeepPartial<ErrorEvent>, I>>(object: I): ErrorEvent {
+    const message = createBaseErrorEvent();
+    message.code = object.code ?? "";
+    message.message = object.message ?? "";
+    message.details = object.details ?? undefined;
+    message.fatal = object.fatal ?? false;
+    return message;
+  },
+};
+
+function createBaseAgentEvent(): AgentEvent {
+  return {
+    type: "",
+    timestamp: undefined,
+    traceId: "",
+    spanId: "",
+    parentId: "",
+    correlationId: "",
+    hierarchyLevel: 0,
+    sessionId: "",
+    component: "",
+    data: undefined,
+    artifacts: [],
+  };
+}
+
+export const AgentEvent = {
+  encode(message: AgentEvent, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
+    if (message.type !== "") {
+      writer.uint32(10).string(message.type);
+    }
+    if (message.timestamp !== undefined) {
+      Timestamp.encode(toTimestamp(message.timestamp), writer.uint32(18).fork()).ldelim();
+    }
+    if (message.traceId !== "") {
+      writer.uint32(26).string(message.traceId);
+    }
+    if (message.spanId !== "") {
+      writer.uint32(34).string(message.spanId);
+    }
+    if (message.parentId !== "") {
+      writer.uint32(42).string(message.parentId);
+    }
+    if (message.correlationId !== "") {
+      writer.uint32(50).string(message.correlationId);
+    }
+    if (message.hierarchyLevel !== 0) {
+      writer.uint32(56).int32(message.hierarchyLevel);
+    }
+    if (message.sessionId !== "") {
+      writer.uint32(66).string(message.sessionId);
+    }
+    if (message.component !== "") {
+      writer.uint32(74).string(message.component);
+    }
+    if (message.data !== undefined) {
+      Struct.encode(Struct.wrap(message.data), writer.uint32(82).fork()).ldelim();
+    }
+    for (const v of message.artifacts) {
+      Artifact.encode(v!, writer.uint32(90).fork()).ldelim();
+    }
+    return writer;
+  },
+
+  decode(input: _m0.Reader | Uint8Array, length?: number): AgentEvent {
+    const reader = input instanceof _m0.Reader ? input : _m0.Reader.create(input);
+    let end = length === undefined ? reader.len : reader.pos + length;
+    const message = createBaseAgentEvent();
+    while (reader.pos < end) {
+      const tag = reader.uint32();
+      switch (tag >>> 3) {
+        case 1:
+          if (tag !== 10) {
+            break;
+          }
+
+          message.type = reader.string();
+          continue;
+        case 2:
+          if (tag !== 18) {
+            break;
+          }
+
+          message.timestamp = fromTimestamp(Timestamp.decode(reader, reader.uint32()));
+          continue;
+        case 3:
+          if (tag !== 26) {
+            break;
+          }
+
+          message.traceId = reader.string();
+          continue;
+        case 4:
+          if (tag !== 34) {
+            break;
+          }
+
+          message.spanId = reader.string();
+          continue;
+        case 5:
+          if (tag !== 42) {
+            break;
+          }
+
+          message.parentId = reader.string();
+          continue;
+        case 6:
+          if (tag !== 50) {
+            break;
+          }
+
+          message.correlationId = reader.string();
+          continue;
+        case 7:
+          if (tag !== 56) {
+            break;
+          }
+
+          message.hierarchyLevel = reader.int32();
+          continue;
+        case 8:
+          if (tag !== 66) {
+            break;
+          }
+
+          message.sessionId = reader.string();
+          continue;
+        case 9:
+          if (tag !== 74) {
+            break;
+          }
+
+          message.component = reader.string();
+          continue;
+        case 10:
+          if (tag !== 82) {
+            break;
+          }
+
+          message.data = Struct.unwrap(Struct.decode(reader, reader.uint32()));
+          continue;
+        case 11:
+          if (tag !== 90) {
+            break;
+          }
+
+          message.artifacts.push(Artifact.decode(reader, reader.uint32()));
+          continue;
+      }
+      if ((tag & 7) === 4 || tag === 0) {
+        break;
+      }
+      reader.skipType(tag & 7);
+    }
+    return message;
+  },
+
+  fromJSON(object: any): AgentEvent {
+    return {
+      type: isSet(object.type) ? globalThis.String(object.type) : "",
+      timestamp: isSet(object.timestamp) ? fromJsonTimestamp(object.timestamp) : undefined,
+      traceId: isSet(object.traceId) ? globalThis.String(object.traceId) : "",
+      spanId: isSet(object.spanId) ? globalThis.String(object.spanId) : "",
+      parentId: isSet(object.parentId) ? globalThis.String(object.parentId) : "",
+      correlationId: isSet(object.correlationId) ? globalThis.String(object.correlationId) : "",
+      hierarchyLevel: isSet(object.hierarchyLevel) ? globalThis.Number(object.hierarchyLevel) : 0,
+      sessionId: isSet(object.sessionId) ? globalThis.String(object.sessionId) : "",
+      component: isSet(object.component) ? globalThis.String(object.component) : "",
+      data: isObject(object.data) ? object.data : undefined,
+      artifacts: globalThis.Array.isArray(object?.artifacts)
+        ? object.artifacts.map((e: any) => Artifact.fromJSON(e))
+        : [],
+    };
+  },
+
+  toJSON(message: AgentEvent): unknown {
+    const obj: any = {};
+    if (message.type !== "") {
+      obj.type = message.type;
+    }
+    if (message.timestamp !== undefined) {
+      obj.timestamp = message.timestamp.toISOString();
+    }
+    if (message.traceId !== "") {
+      obj.traceId = message.traceId;
+    }
+    if (message.spanId !== "") {
+      obj.spanId = message.spanId;
+    }
+    if (message.parentId !== "") {
+      obj.parentId = message.parentId;
+    }
+    if (message.correlationId !== "") {
+      obj.correlationId = message.correlationId;
+    }
+    if (message.hierarchyLevel !== 0) {
+      obj.hierarchyLevel = Math.round(message.hierarchyLevel);
+    }
+    if (message.sessionId !== "") {
+      obj.sessionId = message.sessionId;
+    }
+    if (message.component !== "") {
+      obj.component = message.component;
+    }
+    if (message.data !== undefined) {
+      obj.data = message.data;
+    }
+    if (message.artifacts?.length) {
+      obj.artifacts = message.artifacts.map((e) => Artifact.toJSON(e));
+    }
+    return obj;
+  },
+
+  create<I extends Exact<DeepPartial<AgentEvent>, I>>(base?: I): AgentEvent {
+    return AgentEvent.fromPartial(base ?? ({} as any));
+  },
+  fromPartial<I extends Exact<DeepPartial<AgentEvent>, I>>(object: I): AgentEvent {
+    const message = createBaseAgentEvent();
+    message.type = object.type ?? "";
+    message.timestamp = object.timestamp ?? undefined;
+    message.traceId = object.traceId ?? "";
+    message.spanId = object.spanId ?? "";
+    message.parentId = object.parentId ?? "";
+    message.correlationId = object.correlationId ?? "";
+    message.hierarchyLevel = object.hierarchyLevel ?? 0;
+    message.sessionId = object.sessionId ?? "";
+    message.component = object.component ?? "";
+    message.data = object.data ?? undefined;
+    message.artifacts = object.artifacts?.map((e) => Artifact.fromPartial(e)) || [];
+    return message;
+  },
+};
+
+function createBaseArtifact(): Artifact {
+  return { path: "", url: "" };
+}
+
+export const Artifact = {
+  encode(message: Artifact, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
+    if (message.path !== "") {
+      writer.uint32(10).string(message.path);
+    }
+    if (message.url !== "") {
+      writer.uint32(18).string(message.url);
+    }
+    return writer;
+  },
+
+  decode(input: _m0.Reader | Uint8Array, length?: number): Artifact {
+    const reader = input instanceof _m0.Reader ? input : _m0.Reader.create(input);
+    let end = length === undefined ? reader.len : reader.pos + length;
+    const message = createBaseArtifact();
+    while (reader.pos < end) {
+      const tag = reader.uint32();
+      switch (tag >>> 3) {
+        case 1:
+          if (tag !== 10) {
+            break;
+          }
+
+          message.path = reader.string();
+          continue;
+        case 2:
+          if (tag !== 18) {
+            break;
+          }
+
+          message.url = reader.string();
+          continue;
+      }
+      if ((tag & 7) === 4 || tag === 0) {
+        break;
+      }
+      reader.skipType(tag & 7);
+    }
+    return message;
+  },
+
+  fromJSON(object: any): Artifact {
+    return {
+      path: isSet(object.path) ? globalThis.String(object.path) : "",
+      url: isSet(object.url) ? globalThis.String(object.url) : "",
+    };
+  },
+
+  toJSON(message: Artifact): unknown {
+    const obj: any = {};
+    if (message.path !== "") {
+      obj.path = message.path;
+    }
+    if (message.url !== "") {
+      obj.url = message.url;
+    }
+    return obj;
+  },
+
+  create<I extends Exact<DeepPartial<Artifact>, I>>(base?: I): Artifact {
+    return Artifact.fromPartial(base ?? ({} as any));
+  },
+  fromPartial<I extends Exact<DeepPartial<Artifact>, I>>(object: I): Artifact {
+    const message = createBaseArtifact();
+    message.path = object.path ?? "";
+    message.url = object.url ?? "";
+    return message;
+  },
+};
+
+function createBaseMessage(): Message {
+  return { role: "", content: "" };
+}
+
+export const Message = {
+  encode(message: Message, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
+    if (message.role !== "") {
+      writer.uint32(10).string(message.role);
+    }
+    if (message.content !== "") {
+      writer.uint32(18).string(message.content);
+    }
+    return writer;
+  },
+
+  decode(input: _m0.Reader | Uint8Array, length?: number): Message {
+    const reader = input instanceof _m0.Reader ? input : _m0.Reader.create(input);
+    let end = length === undefined ? reader.len : reader.pos + length;
+    const message = createBaseMessage();
+    while (reader.pos < end) {
+      const tag = reader.uint32();
+      switch (tag >>> 3) {
+        case 1:
+          if (tag !== 10) {
+            break;
+          }
+
+          message.role = reader.string();
+          continue;
+        case 2:
+          if (tag !== 18) {
+            break;
+          }
+
+          message.content = reader.string();
+          continue;
+      }
+      if ((tag & 7) === 4 || tag === 0) {
+        break;
+      }
+      reader.skipType(tag & 7);
+    }
+    return message;
+  },
+
+  fromJSON(object: any): Message {
+    return {
+      role: isSet(object.role) ? globalThis.String(object.role) : "",
+      content: isSet(object.content) ? globalThis.String(object.content) : "",
+    };
+  },
+
+  toJSON(message: Message): unknown {
+    const obj: any = {};
+    if (message.role !== "") {
+      obj.role = message.role;
+    }
+    if (message.content !== "") {
+      obj.content = message.content;
+    }
+    return obj;
+  },
+
+  create<I extends Exact<DeepPartial<Message>, I>>(base?: I): Message {
+    return Message.fromPartial(base ?? ({} as any));
+  },
+  fromPartial<I extends Exact<DeepPartial<Message>, I>>(object: I): Message {
+    const message = createBaseMessage();
+    message.role = object.role ?? "";
+    message.content = object.content ?? "";
+    return message;
+  },
+};
+
+function createBaseWatchConversationRequest(): WatchConversationRequest {
+  return { agentId: "", replay: false, verbosity: "" };
+}
+
+export const WatchConversationRequest = {
+  encode(message: WatchConversationRequest, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
+    if (message.agentId !== "") {
+      writer.uint32(10).string(message.agentId);
+    }
+    if (message.replay !== false) {
+      writer.uint32(16).bool(message.replay);
+    }
+    if (message.verbosity !== "") {
+      writer.uint32(26).string(message.verbosity);
+    }
+    return writer;
+  },
+
+  decode(input: _m0.Reader | Uint8Array, length?: number): WatchConversationRequest {
+    const reader = input instanceof _m0.Reader ? input : _m0.Reader.create(input);
+    let end = length === undefined ? reader.len : reader.pos + length;
+    const message = createBaseWatchConversationRequest();
+    while (reader.pos < end) {
+      const tag = reader.uint32();
+      switch (tag >>> 3) {
+        case 1:
+          if (tag !== 10) {
+            break;
+          }
+
+          message.agentId = reader.string();
+          continue;
+        case 2:
+          if (tag !== 16) {
+            break;
+          }
+
+          message.replay = reader.bool();
+          continue;
+        case 3:
+          if (tag !== 26) {
+            break;
+          }
+
+          message.verbosity = reader.string();
+          continue;
+      }
+      if ((tag & 7) === 4 || tag === 0) {
+        break;
+      }
+      reader.skipType(tag & 7);
+    }
+    return message;
+  },
+
+  fromJSON(object: any): WatchConversationRequest {
+    return {
+      agentId: isSet(object.agentId) ? globalThis.String(object.agentId) : "",
+      replay: isSet(object.replay) ? globalThis.Boolean(object.replay) : false,
+      verbosity: isSet(object.verbosity) ? globalThis.String(object.verbosity) : "",
+    };
+  },
+
+  toJSON(message: WatchConversationRequest): unknown {
+    const obj: any = {};
+    if (message.agentId !== "") {
+      obj.agentId = message.agentId;
+    }
+    if (message.replay !== false) {
+      obj.replay = message.replay;
+    }
+    if (message.verbosity !== "") {
+      obj.verbosity = message.verbosity;
+    }
+    return obj;
+  },
+
+  create<I extends Exact<DeepPartial<WatchConversationRequest>, I>>(base?: I): WatchConversationRequest {
+    return WatchConversationRequest.fromPartial(base ?? ({} as any));
+  },
+  fromPartial<I extends Exact<DeepPartial<WatchConversationRequest>, I>>(object: I): WatchConversationRequest {
+    const message = createBaseWatchConversationRequest();
+    message.agentId = object.agentId ?? "";
+    message.replay = object.replay ?? false;
+    message.verbosity = object.verbosity ?? "";
+    return message;
+  },
+};
+
+function createBaseAskStreamRequest(): AskStreamRequest {
+  return { agentId: "", question: "", history: [], includeEvents: false, priority: "", requestId: "" };
+}
+
+export const AskStreamRequest = {
+  encode(message: AskStreamRequest, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
+    if (message.agentId !== "") {
+      writer.uint32(10).string(message.agentId);
+    }
+    if (message.question !== "") {
+      writer.uint32(18).string(message.question);
+    }
+    for (const v of message.history) {
+      Message.encode(v!, writer.uint32(26).fork()).ldelim();
+    }
+    if (message.includeEvents !== false) {
+      writer.uint32(32).bool(message.includeEvents);
+    }
+    if (message.priority !== "") {
+      writer.uint32(42).string(message.priority);
+    }
+    if (message.requestId !== "") {
+      writer.uint32(50).string(message.requestId);
+    }
+    return writer;
+  },
+
+  decode(input: _m0.Reader | Uint8Array, length?: number): AskStreamRequest {
+    const reader = input instanceof _m0.Reader ? input : _m0.Reader.create(input);
+    let end = length === undefined ? reader.len : reader.pos + length;
+    const message = createBaseAskStreamRequest();
+    while (reader.pos < end) {
+      const tag = reader.uint32();
+      switch (tag >>> 3) {
+        case 1:
+          if (tag !== 10) {
+            break;
+          }
+
+          message.agentId = reader.string();
+          continue;
+        case 2:
+          if (tag !== 18) {
             break;
           }
 
-          message.completionTokens = reader.int32();
+          message.question = reader.string();
           continue;
         case 3:
-          if (tag !== 24) {
+          if (tag !== 26) {
             break;
           }
 
-          message.totalTokens = reader.int32();
+          message.history.push(Message.decode(reader, reader.uint32()));
           continue;
         case 4:
           if (tag !== 32) {
             break;
           }
 
-          message.cacheTokens = reader.int32();
+          message.includeEvents = reader.bool();
           continue;
         case 5:
-          if (tag !== 40) {
+          if (tag !== 42) {
             break;
           }
 
-          message.reasoningTokens = reader.int32();
+          message.priority = reader.string();
           continue;
         case 6:
-          if (tag !== 48) {
+          if (tag !== 50) {
             break;
           }
 
-          message.llmCallCount = reader.int32();
+          message.requestId = reader.string();
           continue;
       }
       if ((tag & 7) === 4 || tag === 0) {
@@ -1644,120 +5944,137 @@ export const TokenUsage = {
     return message;
   },
 
-  fromJSON(object: any): TokenUsage {
+  fromJSON(object: any): AskStreamRequest {
     return {
-      promptTokens: isSet(object.promptTokens) ? globalThis.Number(object.promptTokens) : 0,
-      completionTokens: isSet(object.completionTokens) ? globalThis.Number(object.completionTokens) : 0,
-      totalTokens: isSet(object.totalTokens) ? globalThis.Number(object.totalTokens) : 0,
-      cacheTokens: isSet(object.cacheTokens) ? globalThis.Number(object.cacheTokens) : 0,
-      reasoningTokens: isSet(object.reasoningTokens) ? globalThis.Number(object.reasoningTokens) : 0,
-      llmCallCount: isSet(object.llmCallCount) ? globalThis.Number(object.llmCallCount) : 0,
+      agentId: isSet(object.agentId) ? globalThis.String(object.agentId) : "",
+      question: isSet(object.question) ? globalThis.String(object.question) : "",
+      history: globalThis.Array.isArray(object?.history) ? object.history.map((e: any) => Message.fromJSON(e)) : [],
+      includeEvents: isSet(object.includeEvents) ? globalThis.Boolean(object.includeEvents) : false,
+      priority: isSet(object.priority) ? globalThis.String(object.priority) : "",
+      requestId: isSet(object.requestId) ? globalThis.String(object.requestId) : "",
     };
   },
 
-  toJSON(message: TokenUsage): unknown {
+  toJSON(message: AskStreamRequest): unknown {
     const obj: any = {};
-    if (message.promptTokens !== 0) {
-      obj.promptTokens = Math.round(message.promptTokens);
+    if (message.agentId !== "") {
+      obj.agentId = message.agentId;
     }
-    if (message.completionTokens !== 0) {
-      obj.completionTokens = Math.round(message.completionTokens);
+    if (message.question !== "") {
+      obj.question = message.question;
     }
-    if (message.totalTokens !== 0) {
-      obj.totalTokens = Math.round(message.totalTokens);
+    if (message.history?.length) {
+      obj.history = message.history.map((e) => Message.toJSON(e));
     }
-    if (message.cacheTokens !== 0) {
-      obj.cacheTokens = Math.round(message.cacheTokens);
+    if (message.includeEvents !== false) {
+      obj.includeEvents = message.includeEvents;
     }
-    if (message.reasoningTokens !== 0) {
-      obj.reasoningTokens = Math.round(message.reasoningTokens);
+    if (message.priority !== "") {
+      obj.priority = message.priority;
     }
-    if (message.llmCallCount !== 0) {
-      obj.llmCallCount = Math.round(message.llmCallCount);
+    if (message.requestId !== "") {
+      obj.requestId = message.requestId;
     }
     return obj;
   },
 
-  create<I extends Exact<DeepPartial<TokenUsage>, I>>(base?: I): TokenUsage {
-    return TokenUsage.fromPartial(base ?? ({} as any));
+  create<I extends Exact<DeepPartial<AskStreamRequest>, I>>(base?: I): AskStreamRequest {
+    return AskStreamRequest.fromPartial(base ?? ({} as any));
   },
-  fromPartial<I extends Exact<DeepPartial<TokenUsage>, I>>(object: I): TokenUsage {
-    const message = createBaseTokenUsage();
-    message.promptTokens = object.promptTokens ?? 0;
-    message.completionTokens = object.completionTokens ?? 0;
-    message.totalTokens = object.totalTokens ?? 0;
-    message.cacheTokens = object.cacheTokens ?? 0;
-    message.reasoningTokens = object.reasoningTokens ?? 0;
-    message.llmCallCount = object.llmCallCount ?? 0;
+  fromPartial<I extends Exact<DeepPartial<AskStreamRequest>, I>>(object: I): AskStreamRequest {
+    const message = createBaseAskStreamRequest();
+    message.agentId = object.agentId ?? "";
+    message.question = object.question ?? "";
+    message.history = object.history?.map((e) => Message.fromPartial(e)) || [];
+    message.includeEvents = object.includeEvents ?? false;
+    message.priority = object.priority ?? "";
+    message.requestId = object.requestId ?? "";
     return message;
   },
 };
 
-function createBaseCosts(): Costs {
-  return { inputCost: 0, outputCost: 0, reasoningCost: 0, cacheCost: 0, totalCost: 0 };
+function createBaseAskStreamResponse(): AskStreamResponse {
+  return {
+    textChunk: undefined,
+    toolCallStart: undefined,
+    toolCallEnd: undefined,
+    finalResponse: undefined,
+    error: undefined,
+    agentEvent: undefined,
+  };
 }
 
-export const Costs = {
-  encode(message: Costs, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
-    if (message.inputCost !== 0) {
-      writer.uint32(9).double(message.inputCost);
+export const AskStreamResponse = {
+  encode(message: AskStreamResponse, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
+    if (message.textChunk !== undefined) {
+      TextChunkEvent.encode(message.textChunk, writer.uint32(10).fork()).ldelim();
     }
-    if (message.outputCost !== 0) {
-      writer.uint32(17).double(message.outputCost);
+    if (message.toolCallStart !== undefined) {
+      ToolCallStart.encode(message.toolCallStart, writer.uint32(18).fork()).ldelim();
     }
-    if (message.reasoningCost !== 0) {
-      writer.uint32(25).double(message.reasoningCost);
+    if (message.toolCallEnd !== undefined) {
+      ToolCallEnd.encode(message.toolCallEnd, writer.uint32(26).fork()).ldelim();
     }
-    if (message.cacheCost !== 0) {
-      writer.uint32(33).double(message.cacheCost);
+    if (message.finalResponse !== undefined) {
+      FinalResponse.encode(message.finalResponse, writer.uint32(34).fork()).ldelim();
     }
-    if (message.totalCost !== 0) {
-      writer.uint32(41).double(message.totalCost);
+    if (message.error !== undefined) {
+      ErrorEvent.encode(message.error, writer.uint32(42).fork()).ldelim();
+    }
+    if (message.agentEvent !== undefined) {
+      AgentEvent.encode(message.agentEvent, writer.uint32(50).fork()).ldelim();
     }
     return writer;
   },
 
-  decode(input: _m0.Reader | Uint8Array, length?: number): Costs {
+  decode(input: _m0.Reader | Uint8Array, length?: number): AskStreamResponse {
     const reader = input instanceof _m0.Reader ? input : _m0.Reader.create(input);
     let end = length === undefined ? reader.len : reader.pos + length;
-    const message = createBaseCosts();
+    const message = createBaseAskStreamResponse();
     while (reader.pos < end) {
       const tag = reader.uint32();
       switch (tag >>> 3) {
         case 1:
-          if (tag !== 9) {
+          if (tag !== 10) {
             break;
           }
 
-          message.inputCost = reader.double();
+          message.textChunk = TextChunkEvent.decode(reader, reader.uint32());
           continue;
         case 2:
-          if (tag !== 17) {
+          if (tag !== 18) {
             break;
           }
 
-          message.outputCost = reader.double();
+          message.toolCallStart = ToolCallStart.decode(reader, reader.uint32());
           continue;
         case 3:
-          if (tag !== 25) {
+          if (tag !== 26) {
             break;
           }
 
-          message.reasoningCost = reader.double();
+          message.toolCallEnd = ToolCallEnd.decode(reader, reader.uint32());
           continue;
         case 4:
-          if (tag !== 33) {
+          if (tag !== 34) {
             break;
           }
 
-          message.cacheCost = reader.double();
+          message.finalResponse = FinalResponse.decode(reader, reader.uint32());
           continue;
         case 5:
-          if (tag !== 41) {
+          if (tag !== 42) {
             break;
           }
 
-          message.totalCost = reader.double();
+          message.error = ErrorEvent.decode(reader, reader.uint32());
+          continue;
+        case 6:
+          if (tag !== 50) {
+            break;
+          }
+
+          message.agentEvent = AgentEvent.decode(reader, reader.uint32());
           continue;
       }
       if ((tag & 7) === 4 || tag === 0) {
@@ -1768,69 +6085,95 @@ export const Costs = {
     return message;
   },
 
-  fromJSON(object: any): Costs {
+  fromJSON(object: any): AskStreamResponse {
     return {
-      inputCost: isSet(object.inputCost) ? globalThis.Number(object.inputCost) : 0,
-      outputCost: isSet(object.outputCost) ? globalThis.Number(object.outputCost) : 0,
-      reasoningCost: isSet(object.reasoningCost) ? globalThis.Number(object.reasoningCost) : 0,
-      cacheCost: isSet(object.cacheCost) ? globalThis.Number(object.cacheCost) : 0,
-      totalCost: isSet(object.totalCost) ? globalThis.Number(object.totalCost) : 0,
+      textChunk: isSet(object.textChunk) ? TextChunkEvent.fromJSON(object.textChunk) : undefined,
+      toolCallStart: isSet(object.toolCallStart) ? ToolCallStart.fromJSON(object.toolCallStart) : undefined,
+      toolCallEnd: isSet(object.toolCallEnd) ? ToolCallEnd.fromJSON(object.toolCallEnd) : undefined,
+      finalResponse: isSet(object.finalResponse) ? FinalResponse.fromJSON(object.finalResponse) : undefined,
+      error: isSet(object.error) ? ErrorEvent.fromJSON(object.error) : undefined,
+      agentEvent: isSet(object.agentEvent) ? AgentEvent.fromJSON(object.agentEvent) : undefined,
     };
   },
 
-  toJSON(message: Costs): unknown {
+  toJSON(message: AskStreamResponse): unknown {
     const obj: any = {};
-    if (message.inputCost !== 0) {
-      obj.inputCost = message.inputCost;
+    if (message.textChunk !== undefined) {
+      obj.textChunk = TextChunkEvent.toJSON(message.textChunk);
     }
-    if (message.outputCost !== 0) {
-      obj.outputCost = message.outputCost;
+    if (message.toolCallStart !== undefined) {
+      obj.toolCallStart = ToolCallStart.toJSON(message.toolCallStart);
     }
-    if (message.reasoningCost !== 0) {
-      obj.reasoningCost = message.reasoningCost;
+    if (message.toolCallEnd !== undefined) {
+      obj.toolCallEnd = ToolCallEnd.toJSON(message.toolCallEnd);
     }
-    if (message.cacheCost !== 0) {
-      obj.cacheCost = message.cacheCost;
+    if (message.finalResponse !== undefined) {
+      obj.finalResponse = FinalResponse.toJSON(message.finalResponse);
     }
-    if (message.totalCost !== 0) {
-      obj.totalCost = message.totalCost;
+    if (message.error !== undefined) {
+      obj.error = ErrorEvent.toJSON(message.error);
+    }
+    if (message.agentEvent !== undefined) {
+      obj.agentEvent = AgentEvent.toJSON(message.agentEvent);
     }
     return obj;
   },
 
-  create<I extends Exact<DeepPartial<Costs>, I>>(base?: I): Costs {
-    return Costs.fromPartial(base ?? ({} as any));
+  create<I extends Exact<DeepPartial<AskStreamResponse>, I>>(base?: I): AskStreamResponse {
+    return AskStreamResponse.fromPartial(base ?? ({} as any));
   },
-  fromPartial<I extends Exact<DeepPartial<Costs>, I>>(object: I): Costs {
-    const message = createBaseCosts();
-    message.inputCost = object.inputCost ?? 0;
-    message.outputCost = object.outputCost ?? 0;
-    message.reasoningCost = object.reasoningCost ?? 0;
-    message.cacheCost = object.cacheCost ?? 0;
-    message.totalCost = object.totalCost ?? 0;
+  fromPartial<I extends Exact<DeepPartial<AskStreamResponse>, I>>(object: I): AskStreamResponse {
+    const message = createBaseAskStreamResponse();
+    message.textChunk = (object.textChunk !== undefined && object.textChunk !== null)
+      ? TextChunkEvent.fromPartial(object.textChunk)
+      : undefined;
+    message.toolCallStart = (object.toolCallStart !== undefined && object.toolCallStart !== null)
+      ? ToolCallStart.fromPartial(object.toolCallStart)
+      : undefined;
+    message.toolCallEnd = (object.toolCallEnd !== undefined && object.toolCallEnd !== null)
+      ? ToolCallEnd.fromPartial(object.toolCallEnd)
+      : undefined;
+    message.finalResponse = (object.finalResponse !== undefined && object.finalResponse !== null)
+      ? FinalResponse.fromPartial(object.finalResponse)
+      : undefined;
+    message.error = (object.error !== undefined && object.error !== null)
+      ? ErrorEvent.fromPartial(object.error)
+      : undefined;
+    message.agentEvent = (object.agentEvent !== undefined && object.agentEvent !== null)
+      ? AgentEvent.fromPartial(object.agentEvent)
+      : undefined;
     return message;
   },
 };
 
-function createBaseTokenUsageResponse(): TokenUsageResponse {
-  return { tokenUsage: undefined, costs: undefined };
+function createBaseToolCallStart(): ToolCallStart {
+  return { callId: "", toolName: "", serverName: "", arguments: "", turn: 0 };
 }
 
-export const TokenUsageResponse = {
-  encode(message: TokenUsageResponse, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
-    if (message.tokenUsage !== undefined) {
-      TokenUsage.encode(message.tokenUsage, writer.uint32(10).fork()).ldelim();
+export const ToolCallStart = {
+  encode(message: ToolCallStart, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
+    if (message.callId !== "") {
+      writer.uint32(10).string(message.callId);
     }
-    if (message.costs !== undefined) {
-      Costs.encode(message.costs, writer.uint32(18).fork()).ldelim();
+    if (message.toolName !== "") {
+      writer.uint32(18).string(message.toolName);
+    }
+    if (message.serverName !== "") {
+      writer.uint32(26).string(message.serverName);
+    }
+    if (message.arguments !== "") {
+      writer.uint32(34).string(message.arguments);
+    }
+    if (message.turn !== 0) {
+      writer.uint32(40).int32(message.turn);
     }
     return writer;
   },
 
-  decode(input: _m0.Reader | Uint8Array, length?: number): TokenUsageResponse {
+  decode(input: _m0.Reader | Uint8Array, length?: number): ToolCallStart {
     const reader = input instanceof _m0.Reader ? input : _m0.Reader.create(input);
     let end = length === undefined ? reader.len : reader.pos + length;
-    const message = createBaseTokenUsageResponse();
+    const message = createBaseToolCallStart();
     while (reader.pos < end) {
       const tag = reader.uint32();
       switch (tag >>> 3) {
@@ -1839,14 +6182,35 @@ export const TokenUsageResponse = {
             break;
           }
 
-          message.tokenUsage = TokenUsage.decode(reader, reader.uint32());
+          message.callId = reader.string();
           continue;
         case 2:
           if (tag !== 18) {
             break;
           }
 
-          message.costs = Costs.decode(reader, reader.uint32());
+          message.toolName = reader.string();
+          continue;
+        case 3:
+          if (tag !== 26) {
+            break;
+          }
+
+          message.serverName = reader.string();
+          continue;
+        case 4:
+          if (tag !== 34) {
+            break;
+          }
+
+          message.arguments = reader.string();
+          continue;
+        case 5:
+          if (tag !== 40) {
+            break;
+          }
+
+          message.turn = reader.int32();
           continue;
       }
       if ((tag & 7) === 4 || tag === 0) {
@@ -1857,62 +6221,100 @@ export const TokenUsageResponse = {
     return message;
   },
 
-  fromJSON(object: any): TokenUsageResponse {
+  fromJSON(object: any): ToolCallStart {
     return {
-      tokenUsage: isSet(object.tokenUsage) ? TokenUsage.fromJSON(object.tokenUsage) : undefined,
-      costs: isSet(object.costs) ? Costs.fromJSON(object.costs) : undefined,
+      callId: isSet(object.callId) ? globalThis.String(object.callId) : "",
+      toolName: isSet(object.toolName) ? globalThis.String(object.toolName) : "",
+      serverName: isSet(object.serverName) ? globalThis.String(object.serverName) : "",
+      arguments: isSet(object.arguments) ? globalThis.String(object.arguments) : "",
+      turn: isSet(object.turn) ? globalThis.Number(object.turn) : 0,
     };
   },
 
-  toJSON(message: TokenUsageResponse): unknown {
+  toJSON(message: ToolCallStart): unknown {
     const obj: any = {};
-    if (message.tokenUsage !== undefined) {
-      obj.tokenUsage = TokenUsage.toJSON(message.tokenUsage);
+    if (message.callId !== "") {
+      obj.callId = message.callId;
     }
-    if (message.costs !== undefined) {
-      obj.costs = Costs.toJSON(message.costs);
+    if (message.toolName !== "") {
+      obj.toolName = message.toolName;
+    }
+    if (message.serverName !== "") {
+      obj.serverName = message.serverName;
+    }
+    if (message.arguments !== "") {
+      obj.arguments = message.arguments;
+    }
+    if (message.turn !== 0) {
+      obj.turn = Math.round(message.turn);
     }
     return obj;
   },
 
-  create<I extends Exact<DeepPartial<TokenUsageResponse>, I>>(base?: I): TokenUsageResponse {
-    return TokenUsageResponse.fromPartial(base ?? ({} as any));
+  create<I extends Exact<DeepPartial<ToolCallStart>, I>>(base?: I): ToolCallStart {
+    return ToolCallStart.fromPartial(base ?? ({} as any));
   },
-  fromPartial<I extends Exact<DeepPartial<TokenUsageResponse>, I>>(object: I): TokenUsageResponse {
-    const message = createBaseTokenUsageResponse();
-    message.tokenUsage = (object.tokenUsage !== undefined && object.tokenUsage !== null)
-      ? TokenUsage.fromPartial(object.tokenUsage)
-      : undefined;
-    message.costs = (object.costs !== undefined && object.costs !== null) ? Costs.fromPartial(object.costs) : undefined;
+  fromPartial<I extends Exact<DeepPartial<ToolCallStart>, I>>(object: I): ToolCallStart {
+    const message = createBaseToolCallStart();
+    message.callId = object.callId ?? "";
+    message.toolName = object.toolName ?? "";
+    message.serverName = object.serverName ?? "";
+    message.arguments = object.arguments ?? "";
+    message.turn = object.turn ?? 0;
     return message;
   },
 };
 
-function createBaseConversationRequest(): ConversationRequest {
-  return { agentId: "", question: undefined, toolResult: undefined, cancel: undefined };
+function createBaseToolCallEnd(): ToolCallEnd {
+  return {
+    callId: "",
+    toolName: "",
+    serverName: "",
+    success: false,
+    result: "",
+    error: "",
+    durationMs: 0,
+    turn: 0,
+    fromCache: false,
+  };
 }
 
-export const ConversationRequest = {
-  encode(message: ConversationRequest, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
-    if (message.agentId !== "") {
-      writer.uint32(10).string(message.agentId);
+export const ToolCallEnd = {
+  encode(message: ToolCallEnd, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
+    if (message.callId !== "") {
+      writer.uint32(10).string(message.callId);
     }
-    if (message.question !== undefined) {
-      QuestionMessage.encode(message.question, writer.uint32(18).fork()).ldelim();
+    if (message.toolName !== "") {
+      writer.uint32(18).string(message.toolName);
     }
-    if (message.toolResult !== undefined) {
-      ToolResultMessage.encode(message.toolResult, writer.uint32(26).fork()).ldelim();
+    if (message.serverName !== "") {
+      writer.uint32(26).string(message.serverName);
+    }
+    if (message.success !== false) {
+      writer.uint32(32).bool(message.success);
+    }
+    if (message.result !== "") {
+      writer.uint32(42).string(message.result);
+    }
+    if (message.error !== "") {
+      writer.uint32(50).string(message.error);
+    }
+    if (message.durationMs !== 0) {
+      writer.uint32(56).int64(message.durationMs);
+    }
+    if (message.turn !== 0) {
+      writer.uint32(64).int32(message.turn);
     }
-    if (message.cancel !== undefined) {
-      CancelMessage.encode(message.cancel, writer.uint32(34).fork()).ldelim();
+    if (message.fromCache !== false) {
+      writer.uint32(72).bool(message.fromCache);
     }
     return writer;
   },
 
-  decode(input: _m0.Reader | Uint8Array, length?: number): ConversationRequest {
+  decode(input: _m0.Reader | Uint8Array, length?: number): ToolCallEnd {
     const reader = input instanceof _m0.Reader ? input : _m0.Reader.create(input);
     let end = length === undefined ? reader.len : reader.pos + length;
-    const message = createBaseConversationRequest();
+    const message = createBaseToolCallEnd();
     while (reader.pos < end) {
       const tag = reader.uint32();
       switch (tag >>> 3) {
@@ -1921,28 +6323,63 @@ export const ConversationRequest = {
             break;
           }
 
-          message.agentId = reader.string();
+          message.callId = reader.string();
           continue;
         case 2:
           if (tag !== 18) {
             break;
           }
 
-          message.question = QuestionMessage.decode(reader, reader.uint32());
+          message.toolName = reader.string();
           continue;
         case 3:
           if (tag !== 26) {
             break;
           }
 
-          message.toolResult = ToolResultMessage.decode(reader, reader.uint32());
+          message.serverName = reader.string();
           continue;
         case 4:
-          if (tag !== 34) {
+          if (tag !== 32) {
             break;
           }
 
-          message.cancel = CancelMessage.decode(reader, reader.uint32());
+          message.success = reader.bool();
+          continue;
+        case 5:
+          if (tag !== 42) {
+            break;
+          }
+
+          message.result = reader.string();
+          continue;
+        case 6:
+          if (tag !== 50) {
+            break;
+          }
+
+          message.error = reader.string();
+          continue;
+        case 7:
+          if (tag !== 56) {
+            break;
+          }
+
+          message.durationMs = longToNumber(reader.int64() as Long);
+          continue;
+        case 8:
+          if (tag !== 64) {
+            break;
+          }
+
+          message.turn = reader.int32();
+          continue;
+        case 9:
+          if (tag !== 72) {
+            break;
+          }
+
+          message.fromCache = reader.bool();
           continue;
       }
       if ((tag & 7) === 4 || tag === 0) {
@@ -1953,70 +6390,95 @@ export const ConversationRequest = {
     return message;
   },
 
-  fromJSON(object: any): ConversationRequest {
+  fromJSON(object: any): ToolCallEnd {
     return {
-      agentId: isSet(object.agentId) ? globalThis.String(object.agentId) : "",
-      question: isSet(object.question) ? QuestionMessage.fromJSON(object.question) : undefined,
-      toolResult: isSet(object.toolResult) ? ToolResultMessage.fromJSON(object.toolResult) : undefined,
-      cancel: isSet(object.cancel) ? CancelMessage.fromJSON(object.cancel) : undefined,
+      callId: isSet(object.callId) ? globalThis.String(object.callId) : "",
+      toolName: isSet(object.toolName) ? globalThis.String(object.toolName) : "",
+      serverName: isSet(object.serverName) ? globalThis.String(object.serverName) : "",
+      success: isSet(object.success) ? globalThis.Boolean(object.success) : false,
+      result: isSet(object.result) ? globalThis.String(object.result) : "",
+      error: isSet(object.error) ? globalThis.String(object.error) : "",
+      durationMs: isSet(object.durationMs) ? globalThis.Number(object.durationMs) : 0,
+      turn: isSet(object.turn) ? globalThis.Number(object.turn) : 0,
+      fromCache: isSet(object.fromCache) ? globalThis.Boolean(object.fromCache) : false,
     };
   },
 
-  toJSON(message: ConversationRequest): unknown {
+  toJSON(message: ToolCallEnd): unknown {
     const obj: any = {};
-    if (message.agentId !== "") {
-      obj.agentId = message.agentId;
+    if (message.callId !== "") {
+      obj.callId = message.callId;
     }
-    if (message.question !== undefined) {
-      obj.question = QuestionMessage.toJSON(message.question);
+    if (message.toolName !== "") {
+      obj.toolName = message.toolName;
     }
-    if (message.toolResult !== undefined) {
-      obj.toolResult = ToolResultMessage.toJSON(message.toolResult);
+    if (message.serverName !== "") {
+      obj.serverName = message.serverName;
     }
-    if (message.cancel !== undefined) {
-      obj.cancel = CancelMessage.toJSON(message.cancel);
+    if (message.success !== false) {
+      obj.success = message.success;
+    }
+    if (message.result !== "") {
+      obj.result = message.result;
+    }
+    if (message.error !== "") {
+      obj.error = message.error;
+    }
+    if (message.durationMs !== 0) {
+      obj.durationMs = Math.round(message.durationMs);
+    }
+    if (message.turn !== 0) {
+      obj.turn = Math.round(message.turn);
+    }
+    if (message.fromCache !== false) {
+      obj.fromCache = message.fromCache;
     }
     return obj;
   },
 
-  create<I extends Exact<DeepPartial<ConversationRequest>, I>>(base?: I): ConversationRequest {
-    return ConversationRequest.fromPartial(base ?? ({} as any));
+  create<I extends Exact<DeepPartial<ToolCallEnd>, I>>(base?: I): ToolCallEnd {
+    return ToolCallEnd.fromPartial(base ?? ({} as any));
   },
-  fromPartial<I extends Exact<DeepPartial<ConversationRequest>, I>>(object: I): ConversationRequest {
-    const message = createBaseConversationRequest();
-    message.agentId = object.agentId ?? "";
-    message.question = (object.question !== undefined && object.question !== null)
-      ? QuestionMessage.fromPartial(object.question)
-      : undefined;
-    message.toolResult = (object.toolResult !== undefined && object.toolResult !== null)
-      ? ToolResultMessage.fromPartial(object.toolResult)
-      : undefined;
-    message.cancel = (object.cancel !== undefined && object.cancel !== null)
-      ? CancelMessage.fromPartial(object.cancel)
-      : undefined;
+  fromPartial<I extends Exact<DeepPartial<ToolCallEnd>, I>>(object: I): ToolCallEnd {
+    const message = createBaseToolCallEnd();
+    message.callId = object.callId ?? "";
+    message.toolName = object.toolName ?? "";
+    message.serverName = object.serverName ?? "";
+    message.success = object.success ?? false;
+    message.result = object.result ?? "";
+    message.error = object.error ?? "";
+    message.durationMs = object.durationMs ?? 0;
+    message.turn = object.turn ?? 0;
+    message.fromCache = object.fromCache ?? false;
     return message;
   },
 };
 
-function createBaseQuestionMessage(): QuestionMessage {
-  return { text: "", history: [] };
+function createBaseAskRequest(): AskRequest {
+  return { agentId: "", question: "", priority: "", requestId: "" };
 }
 
-export const QuestionMessage = {
-  encode(message: QuestionMessage, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
-    if (message.text !== "") {
-      writer.uint32(10).string(message.text);
+export const AskRequest = {
+  encode(message: AskRequest, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
+    if (message.agentId !== "") {
+      writer.uint32(10).string(message.agentId);
     }
-    for (const v of message.history) {
-      Message.encode(v!, writer.uint32(18).fork()).ldelim();
+    if (message.question !== "") {
+      writer.uint32(18).string(message.question);
+    }
+    if (message.priority !== "") {
+      writer.uint32(26).string(message.priority);
+    }
+    if (message.requestId !== "") {
+      writer.uint32(34).string(message.requestId);
     }
     return writer;
   },
 
-  decode(input: _m0.Reader | Uint8Array, length?: number): QuestionMessage {
+  decode(input: _m0.Reader | Uint8Array, length?: number): AskRequest {
     const reader = input instanceof _m0.Reader ? input : _m0.Reader.create(input);
     let end = length === undefined ? reader.len : reader.pos + length;
-    const message = createBaseQuestionMessage();
+    const message = createBaseAskRequest();
     while (reader.pos < end) {
       const tag = reader.uint32();
       switch (tag >>> 3) {
@@ -2025,14 +6487,28 @@ export const QuestionMessage = {
             break;
           }
 
-          message.text = reader.string();
+          message.agentId = reader.string();
           continue;
         case 2:
           if (tag !== 18) {
             break;
           }
 
-          message.history.push(Message.decode(reader, reader.uint32()));
+          message.question = reader.string();
+          continue;
+        case 3:
+          if (tag !== 26) {
+            break;
+          }
+
+          message.priority = reader.string();
+          continue;
+        case 4:
+          if (tag !== 34) {
+            break;
+          }
+
+          message.requestId = reader.string();
           continue;
       }
       if ((tag & 7) === 4 || tag === 0) {
@@ -2043,63 +6519,67 @@ export const QuestionMessage = {
     return message;
   },
 
-  fromJSON(object: any): QuestionMessage {
+  fromJSON(object: any): AskRequest {
     return {
-      text: isSet(object.text) ? globalThis.String(object.text) : "",
-      history: globalThis.Array.isArray(object?.history) ? object.history.map((e: any) => Message.fromJSON(e)) : [],
+      agentId: isSet(object.agentId) ? globalThis.String(object.agentId) : "",
+      question: isSet(object.question) ? globalThis.String(object.question) : "",
+      priority: isSet(object.priority) ? globalThis.String(object.priority) : "",
+      requestId: isSet(object.requestId) ? globalThis.String(object.requestId) : "",
     };
   },
 
-  toJSON(message: QuestionMessage): unknown {
+  toJSON(message: AskRequest): unknown {
     const obj: any = {};
-    if (message.text !== "") {
-      obj.text = message.text;
+    if (message.agentId !== "") {
+      obj.agentId = message.agentId;
     }
-    if (message.history?.length) {
-      obj.history = message.history.map((e) => Message.toJSON(e));
+    if (message.question !== "") {
+      obj.question = message.question;
+    }
+    if (message.priority !== "") {
+      obj.priority = message.priority;
+    }
+    if (message.requestId !== "") {
+      obj.requestId = message.requestId;
     }
     return obj;
   },
 
-  create<I extends Exact<DeepPartial<QuestionMessage>, I>>(base?: I): QuestionMessage {
-    return QuestionMessage.fromPartial(base ?? ({} as any));
+  create<I extends Exact<DeepPartial<AskRequest>, I>>(base?: I): AskRequest {
+    return AskRequest.fromPartial(base ?? ({} as any));
   },
-  fromPartial<I extends Exact<DeepPartial<QuestionMessage>, I>>(object: I): QuestionMessage {
-    const message = createBaseQuestionMessage();
-    message.text = object.text ?? "";
-    message.history = object.history?.map((e) => Message.fromPartial(e)) || [];
+  fromPartial<I extends Exact<DeepPartial<AskRequest>, I>>(object: I): AskRequest {
+    const message = createBaseAskRequest();
+    message.agentId = object.agentId ?? "";
+    message.question = object.question ?? "";
+    message.priority = object.priority ?? "";
+    message.requestId = object.requestId ?? "";
     return message;
   },
 };
 
-function createBaseToolResultMessage(): ToolResultMessage {
-  return { callId: "", success: false, result: "", error: undefined, durationMs: 0 };
+function createBaseAskResponse(): AskResponse {
+  return { response: "", tokenUsage: undefined, durationMs: 0 };
 }
 
-export const ToolResultMessage = {
-  encode(message: ToolResultMessage, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
-    if (message.callId !== "") {
-      writer.uint32(10).string(message.callId);
-    }
-    if (message.success !== false) {
-      writer.uint32(16).bool(message.success);
-    }
-    if (message.result !== "") {
-      writer.uint32(26).string(message.result);
+export const AskResponse = {
+  encode(message: AskResponse, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
+    if (message.response !== "") {
+      writer.uint32(10).string(message.response);
     }
-    if (message.error !== undefined) {
-      ToolError.encode(message.error, writer.uint32(34).fork()).ldelim();
+    if (message.tokenUsage !== undefined) {
+      TokenUsage.encode(message.tokenUsage, writer.uint32(18).fork()).ldelim();
     }
     if (message.durationMs !== 0) {
-      writer.uint32(40).int64(message.durationMs);
+      writer.uint32(24).int64(message.durationMs);
     }
     return writer;
   },
 
-  decode(input: _m0.Reader | Uint8Array, length?: number): ToolResultMessage {
+  decode(input: _m0.Reader | Uint8Array, length?: number): AskResponse {
     const reader = input instanceof _m0.Reader ? input : _m0.Reader.create(input);
     let end = length === undefined ? reader.len : reader.pos + length;
-    const message = createBaseToolResultMessage();
+    const message = createBaseAskResponse();
     while (reader.pos < end) {
       const tag = reader.uint32();
       switch (tag >>> 3) {
@@ -2108,31 +6588,17 @@ export const ToolResultMessage = {
             break;
           }
 
-          message.callId = reader.string();
+          message.response = reader.string();
           continue;
         case 2:
-          if (tag !== 16) {
+          if (tag !== 18) {
             break;
           }
 
-          message.success = reader.bool();
+          message.tokenUsage = TokenUsage.decode(reader, reader.uint32());
           continue;
         case 3:
-          if (tag !== 26) {
-            break;
-          }
-
-          message.result = reader.string();
-          continue;
-        case 4:
-          if (tag !== 34) {
-            break;
-          }
-
-          message.error = ToolError.decode(reader, reader.uint32());
-          continue;
-        case 5:
-          if (tag !== 40) {
+          if (tag !== 24) {
             break;
           }
 
@@ -2147,29 +6613,21 @@ export const ToolResultMessage = {
     return message;
   },
 
-  fromJSON(object: any): ToolResultMessage {
+  fromJSON(object: any): AskResponse {
     return {
-      callId: isSet(object.callId) ? globalThis.String(object.callId) : "",
-      success: isSet(object.success) ? globalThis.Boolean(object.success) : false,
-      result: isSet(object.result) ? globalThis.String(object.result) : "",
-      error: isSet(object.error) ? ToolError.fromJSON(object.error) : undefined,
+      response: isSet(object.response) ? globalThis.String(object.response) : "",
+      tokenUsage: isSet(object.tokenUsage) ? TokenUsage.fromJSON(object.tokenUsage) : undefined,
       durationMs: isSet(object.durationMs) ? globalThis.Number(object.durationMs) : 0,
     };
   },
 
-  toJSON(message: ToolResultMessage): unknown {
+  toJSON(message: AskResponse): unknown {
     const obj: any = {};
-    if (message.callId !== "") {
-      obj.callId = message.callId;
-    }
-    if (message.success !== false) {
-      obj.success = message.success;
-    }
-    if (message.result !== "") {
-      obj.result = message.result;
+    if (message.response !== "") {
+      obj.response = message.response;
     }
-    if (message.error !== undefined) {
-      obj.error = ToolError.toJSON(message.error);
+    if (message.tokenUsage !== undefined) {
+      obj.tokenUsage = TokenUsage.toJSON(message.tokenUsage);
     }
     if (message.durationMs !== 0) {
       obj.durationMs = Math.round(message.durationMs);
@@ -2177,44 +6635,45 @@ export const ToolResultMessage = {
     return obj;
   },
 
-  create<I extends Exact<DeepPartial<ToolResultMessage>, I>>(base?: I): ToolResultMessage {
-    return ToolResultMessage.fromPartial(base ?? ({} as any));
+  create<I extends Exact<DeepPartial<AskResponse>, I>>(base?: I): AskResponse {
+    return AskResponse.fromPartial(base ?? ({} as any));
   },
-  fromPartial<I extends Exact<DeepPartial<ToolResultMessage>, I>>(object: I): ToolResultMessage {
-    const message = createBaseToolResultMessage();
-    message.callId = object.callId ?? "";
-    message.success = object.success ?? false;
-    message.result = object.result ?? "";
-    message.error = (object.error !== undefined && object.error !== null)
-      ? ToolError.fromPartial(object.error)
+  fromPartial<I extends Exact<DeepPartial<AskResponse>, I>>(object: I): AskResponse {
+    const message = createBaseAskResponse();
+    message.response = object.response ?? "";
+    message.tokenUsage = (object.tokenUsage !== undefined && object.tokenUsage !== null)
+      ? TokenUsage.fromPartial(object.tokenUsage)
       : undefined;
     message.durationMs = object.durationMs ?? 0;
     return message;
   },
 };
-
-function createBaseToolError(): ToolError {
-  return { code: "", message: "", details: undefined };
+
+function createBaseAskWithHistoryRequest(): AskWithHistoryRequest {
+  return { agentId: "", messages: [], priority: "", requestId: "" };
 }
 
-export const ToolError = {
-  encode(message: ToolError, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
-    if (message.code !== "") {
-      writer.uint32(10).string(message.code);
+export const AskWithHistoryRequest = {
+  encode(message: AskWithHistoryRequest, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
+    if (message.agentId !== "") {
+      writer.uint32(10).string(message.agentId);
     }
-    if (message.message !== "") {
-      writer.uint32(18).string(message.message);
+    for (const v of message.messages) {
+      Message.encode(v!, writer.uint32(18).fork()).ldelim();
     }
-    if (message.details !== undefined) {
-      Struct.encode(Struct.wrap(message.details), writer.uint32(26).fork()).ldelim();
+    if (message.priority !== "") {
+      writer.uint32(26).string(message.priority);
+    }
+    if (message.requestId !== "") {
+      writer.uint32(34).string(message.requestId);
     }
     return writer;
   },
 
-  decode(input: _m0.Reader | Uint8Array, length?: number): ToolError {
+  decode(input: _m0.Reader | Uint8Array, length?: number): AskWithHistoryRequest {
     const reader = input instanceof _m0.Reader ? input : _m0.Reader.create(input);
     let end = length === undefined ? reader.len : reader.pos + length;
-    const message = createBaseToolError();
+    const message = createBaseAskWithHistoryRequest();
     while (reader.pos < end) {
       const tag = reader.uint32();
       switch (tag >>> 3) {
@@ -2223,21 +6682,28 @@ export const ToolError = {
             break;
           }
 
-          message.code = reader.string();
+          message.agentId = reader.string();
           continue;
         case 2:
           if (tag !== 18) {
             break;
           }
 
-          message.message = reader.string();
+          message.messages.push(Message.decode(reader, reader.uint32()));
           continue;
         case 3:
           if (tag !== 26) {
             break;
           }
 
-          message.details = Struct.unwrap(Struct.decode(reader, reader.uint32()));
+          message.priority = reader.string();
+          continue;
+        case 4:
+          if (tag !== 34) {
+            break;
+          }
+
+          message.requestId = reader.string();
           continue;
       }
       if ((tag & 7) === 4 || tag === 0) {
@@ -2248,56 +6714,70 @@ export const ToolError = {
     return message;
   },
 
-  fromJSON(object: any): ToolError {
+  fromJSON(object: any): AskWithHistoryRequest {
     return {
-      code: isSet(object.code) ? globalThis.String(object.code) : "",
-      message: isSet(object.message) ? globalThis.String(object.message) : "",
-      details: isObject(object.details) ? object.details : undefined,
+      agentId: isSet(object.agentId) ? globalThis.String(object.agentId) : "",
+      messages: globalThis.Array.isArray(object?.messages) ? object.messages.map((e: any) => Message.fromJSON(e)) : [],
+      priority: isSet(object.priority) ? globalThis.String(object.priority) : "",
+      requestId: isSet(object.requestId) ? globalThis.String(object.requestId) : "",
     };
   },
 
-  toJSON(message: ToolError): unknown {
+  toJSON(message: AskWithHistoryRequest): unknown {
     const obj: any = {};
-    if (message.code !== "") {
-      obj.code = message.code;
+    if (message.agentId !== "") {
+      obj.agentId = message.agentId;
     }
-    if (message.message !== "") {
-      obj.message = message.message;
+    if (message.messages?.length) {
+      obj.messages = message.messages.map((e) => Message.toJSON(e));
     }
-    if (message.details !== undefined) {
-      obj.details = message.details;
+    if (message.priority !== "") {
+      obj.priority = message.priority;
+    }
+    if (message.requestId !== "") {
+      obj.requestId = message.requestId;
     }
     return obj;
   },
 
-  create<I extends Exact<DeepPartial<ToolError>, I>>(base?: I): ToolError {
-    return ToolError.fromPartial(base ?? ({} as any));
+  create<I extends Exact<DeepPartial<AskWithHistoryRequest>, I>>(base?: I): AskWithHistoryRequest {
+    return AskWithHistoryRequest.fromPartial(base ?? ({} as any));
   },
-  fromPartial<I extends Exact<DeepPartial<ToolError>, I>>(object: I): ToolError {
-    const message = createBaseToolError();
-    message.code = object.code ?? "";
-    message.message = object.message ?? "";
-    message.details = object.details ?? undefined;
+  fromPartial<I extends Exact<DeepPartial<AskWithHistoryRequest>, I>>(object: I): AskWithHistoryRequest {
+    const message = createBaseAskWithHistoryRequest();
+    message.agentId = object.agentId ?? "";
+    message.messages = object.messages?.map((e) => Message.fromPartial(e)) || [];
+    message.priority = object.priority ?? "";
+    message.requestId = object.requestId ?? "";
     return message;
   },
 };
 
-function createBaseCancelMessage(): CancelMessage {
-  return { reason: "" };
+function createBaseAskWithHistoryResponse(): AskWithHistoryResponse {
+  return { response: "", updatedMessages: [], tokenUsage: undefined, durationMs: 0 };
 }
 
-export const CancelMessage = {
-  encode(message: CancelMessage, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
-    if (message.reason !== "") {
-      writer.uint32(10).string(message.reason);
+export const AskWithHistoryResponse = {
+  encode(message: AskWithHistoryResponse, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
+    if (message.response !== "") {
+      writer.uint32(10).string(message.response);
+    }
+    for (const v of message.updatedMessages) {
+      Message.encode(v!, writer.uint32(18).fork()).ldelim();
+    }
+    if (message.tokenUsage !== undefined) {
+      TokenUsage.encode(message.tokenUsage, writer.uint32(26).fork()).ldelim();
+    }
+    if (message.durationMs !== 0) {
+      writer.uint32(32).int64(message.durationMs);
     }
     return writer;
   },
 
-  decode(input: _m0.Reader | Uint8Array, length?: number): CancelMessage {
+  decode(input: _m0.Reader | Uint8Array, length?: number): AskWithHistoryResponse {
     const reader = input instanceof _m0.Reader ? input : _m0.Reader.create(input);
     let end = length === undefined ? reader.len : reader.pos + length;
-    const message = createBaseCancelMessage();
+    const message = createBaseAskWithHistoryResponse();
     while (reader.pos < end) {
       const tag = reader.uint32();
       switch (tag >>> 3) {
@@ -2306,7 +6786,28 @@ export const CancelMessage = {
             break;
           }
 
-          message.reason = reader.string();
+          message.response = reader.string();
+          continue;
+        case 2:
+          if (tag !== 18) {
+            break;
+          }
+
+          message.updatedMessages.push(Message.decode(reader, reader.uint32()));
+          continue;
+        case 3:
+          if (tag !== 26) {
+            break;
+          }
+
+          message.tokenUsage = TokenUsage.decode(reader, reader.uint32());
+          continue;
+        case 4:
+          if (tag !== 32) {
+            break;
+          }
+
+          message.durationMs = longToNumber(reader.int64() as Long);
           continue;
       }
       if ((tag & 7) === 4 || tag === 0) {
@@ -2317,62 +6818,68 @@ export const CancelMessage = {
     return message;
   },
 
-  fromJSON(object: any): CancelMessage {
-    return { reason: isSet(object.reason) ? globalThis.String(object.reason) : "" };
+  fromJSON(object: any): AskWithHistoryResponse {
+    return {
+      response: isSet(object.response) ? globalThis.String(object.response) : "",
+      updatedMessages: globalThis.Array.isArray(object?.updatedMessages)
+        ? object.updatedMessages.map((e: any) => Message.fromJSON(e))
+        : [],
+      tokenUsage: isSet(object.tokenUsage) ? TokenUsage.fromJSON(object.tokenUsage) : undefined,
+      durationMs: isSet(object.durationMs) ? globalThis.Number(object.durationMs) : 0,
+    };
   },
 
-  toJSON(message: CancelMessage): unknown {
+  toJSON(message: AskWithHistoryResponse): unknown {
     const obj: any = {};
-    if (message.reason !== "") {
-      obj.reason = message.reason;
+    if (message.response !== "") {
+      obj.response = message.response;
+    }
+    if (message.updatedMessages?.length) {
+      obj.updatedMessages = message.updatedMessages.map((e) => Message.toJSON(e));
+    }
+    if (message.tokenUsage !== undefined) {
+      obj.tokenUsage = TokenUsage.toJSON(message.tokenUsage);
+    }
+    if (message.durationMs !== 0) {
+      obj.durationMs = Math.round(message.durationMs);
     }
     return obj;
   },
 
-  create<I extends Exact<DeepPartial<CancelMessage>, I>>(base?: I): CancelMessage {
-    return CancelMessage.fromPartial(base ?? ({} as any));
+  create<I extends Exact<DeepPartial<AskWithHistoryResponse>, I>>(base?: I): AskWithHistoryResponse {
+    return AskWithHistoryResponse.fromPartial(base ?? ({} as any));
   },
-  fromPartial<I extends Exact<DeepPartial<CancelMessage>, I>>(object: I): CancelMessage {
-    const message = createBaseCancelMessage();
-    message.reason = object.reason ?? "";
+  fromPartial<I extends Exact<DeepPartial<AskWithHistoryResponse>, I>>(object: I): AskWithHistoryResponse {
+    const message = createBaseAskWithHistoryResponse();
+    message.response = object.response ?? "";
+    message.updatedMessages = object.updatedMessages?.map((e) => Message.fromPartial(e)) || [];
+    message.tokenUsage = (object.tokenUsage !== undefined && object.tokenUsage !== null)
+      ? TokenUsage.fromPartial(object.tokenUsage)
+      : undefined;
+    message.durationMs = object.durationMs ?? 0;
     return message;
   },
 };
 
-function createBaseConversationResponse(): ConversationResponse {
-  return {
-    textChunk: undefined,
-    toolCall: undefined,
-    agentEvent: undefined,
-    finalResponse: undefined,
-    error: undefined,
-  };
+function createBaseCancelRequestRequest(): CancelRequestRequest {
+  return { requestId: "", reason: "" };
 }
 
-export const ConversationResponse = {
-  encode(message: ConversationResponse, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
-    if (message.textChunk !== undefined) {
-      TextChunkEvent.encode(message.textChunk, writer.uint32(10).fork()).ldelim();
-    }
-    if (message.toolCall !== undefined) {
-      ToolCallEvent.encode(message.toolCall, writer.uint32(18).fork()).ldelim();
-    }
-    if (message.agentEvent !== undefined) {
-      AgentEvent.encode(message.agentEvent, writer.uint32(26).fork()).ldelim();
+export const CancelRequestRequest = {
+  encode(message: CancelRequestRequest, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
+    if (message.requestId !== "") {
+      writer.uint32(10).string(message.requestId);
     }
-    if (message.finalResponse !== undefined) {
-      FinalResponse.encode(message.finalResponse, writer.uint32(34).fork()).ldelim();
-    }
-    if (message.error !== undefined) {
-      ErrorEvent.encode(message.error, writer.uint32(42).fork()).ldelim();
+    if (message.reason !== "") {
+      writer.uint32(18).string(message.reason);
     }
     return writer;
   },
 
-  decode(input: _m0.Reader | Uint8Array, length?: number): ConversationResponse {
+  decode(input: _m0.Reader | Uint8Array, length?: number): CancelRequestRequest {
     const reader = input instanceof _m0.Reader ? input : _m0.Reader.create(input);
     let end = length === undefined ? reader.len : reader.pos + length;
-    const message = createBaseConversationResponse();
+    const message = createBaseCancelRequestRequest();
     while (reader.pos < end) {
       const tag = reader.uint32();
       switch (tag >>> 3) {
@@ -2381,35 +6888,14 @@ export const ConversationResponse = {
             break;
           }
 
-          message.textChunk = TextChunkEvent.decode(reader, reader.uint32());
+          message.requestId = reader.string();
           continue;
         case 2:
           if (tag !== 18) {
             break;
           }
 
-          message.toolCall = ToolCallEvent.decode(reader, reader.uint32());
-          continue;
-        case 3:
-          if (tag !== 26) {
-            break;
-          }
-
-          message.agentEvent = AgentEvent.decode(reader, reader.uint32());
-          continue;
-        case 4:
-          if (tag !== 34) {
-            break;
-          }
-
-          message.finalResponse = FinalResponse.decode(reader, reader.uint32());
-          continue;
-        case 5:
-          if (tag !== 42) {
-            break;
-          }
-
-          message.error = ErrorEvent.decode(reader, reader.uint32());
+          message.reason = reader.string();
           continue;
       }
       if ((tag & 7) === 4 || tag === 0) {
@@ -2420,95 +6906,60 @@ export const ConversationResponse = {
     return message;
   },
 
-  fromJSON(object: any): ConversationResponse {
+  fromJSON(object: any): CancelRequestRequest {
     return {
-      textChunk: isSet(object.textChunk) ? TextChunkEvent.fromJSON(object.textChunk) : undefined,
-      toolCall: isSet(object.toolCall) ? ToolCallEvent.fromJSON(object.toolCall) : undefined,
-      agentEvent: isSet(object.agentEvent) ? AgentEvent.fromJSON(object.agentEvent) : undefined,
-      finalResponse: isSet(object.finalResponse) ? FinalResponse.fromJSON(object.finalResponse) : undefined,
-      error: isSet(object.error) ? ErrorEvent.fromJSON(object.error) : undefined,
+      requestId: isSet(object.requestId) ? globalThis.String(object.requestId) : "",
+      reason: isSet(object.reason) ? globalThis.String(object.reason) : "",
     };
   },
 
-  toJSON(message: ConversationResponse): unknown {
+  toJSON(message: CancelRequestRequest): unknown {
     const obj: any = {};
-    if (message.textChunk !== undefined) {
-      obj.textChunk = TextChunkEvent.toJSON(message.textChunk);
-    }
-    if (message.toolCall !== undefined) {
-      obj.toolCall = ToolCallEvent.toJSON(message.toolCall);
+    if (message.requestId !== "") {
+      obj.requestId = message.requestId;
     }
-    if (message.agentEvent !== undefined) {
-      obj.agentEvent = AgentEvent.toJSON(message.agentEvent);
-    }
-    if (message.finalResponse !== undefined) {
-      obj.finalResponse = FinalResponse.toJSON(message.finalResponse);
-    }
-    if (message.error !== undefined) {
-      obj.error = ErrorEvent.toJSON(message.error);
+    if (message.reason !== "") {
+      obj.reason = message.reason;
     }
     return obj;
   },
 
-  create<I extends Exact<DeepPartial<ConversationResponse>, I>>(base?: I): ConversationResponse {
-    return ConversationResponse.fromPartial(base ?? ({} as any));
+  create<I extends Exact<DeepPartial<CancelRequestRequest>, I>>(base?: I): CancelRequestRequest {
+    return CancelRequestRequest.fromPartial(base ?? ({} as any));
   },
-  fromPartial<I extends Exact<DeepPartial<ConversationResponse>, I>>(object: I): ConversationResponse {
-    const message = createBaseConversationResponse();
-    message.textChunk = (object.textChunk !== undefined && object.textChunk !== null)
-      ? TextChunkEvent.fromPartial(object.textChunk)
-      : undefined;
-    message.toolCall = (object.toolCall !== undefined && object.toolCall !== null)
-      ? ToolCallEvent.fromPartial(object.toolCall)
-      : undefined;
-    message.agentEvent = (object.agentEvent !== undefined && object.agentEvent !== null)
-      ? AgentEvent.fromPartial(object.agentEvent)
-      : undefined;
-    message.finalResponse = (object.finalResponse !== undefined && object.finalResponse !== null)
-      ? FinalResponse.fromPartial(object.finalResponse)
-      : undefined;
-    message.error = (object.error !== undefined && object.error !== null)
-      ? ErrorEvent.fromPartial(object.error)
-      : undefined;
+  fromPartial<I extends Exact<DeepPartial<CancelRequestRequest>, I>>(object: I): CancelRequestRequest {
+    const message = createBaseCancelRequestRequest();
+    message.requestId = object.requestId ?? "";
+    message.reason = object.reason ?? "";
     return message;
   },
 };
 
-function createBaseTextChunkEvent(): TextChunkEvent {
-  return { text: "", isThinking: false };
+function createBaseCancelRequestResponse(): CancelRequestResponse {
+  return { cancelled: false };
 }
-
-export const TextChunkEvent = {
-  encode(message: TextChunkEvent, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
-    if (message.text !== "") {
-      writer.uint32(10).string(message.text);
-    }
-    if (message.isThinking !== false) {
-      writer.uint32(16).bool(message.isThinking);
+
+export const CancelRequestResponse = {
+  encode(message: CancelRequestResponse, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
+    if (message.cancelled !== false) {
+      writer.uint32(8).bool(message.cancelled);
     }
     return writer;
   },
 
-  decode(input: _m0.Reader | Uint8Array, length?: number): TextChunkEvent {
+  decode(input: _m0.Reader | Uint8Array, length?: number): CancelRequestResponse {
     const reader = input instanceof _m0.Reader ? input : _m0.Reader.create(input);
     let end = length === undefined ? reader.len : reader.pos + length;
-    const message = createBaseTextChunkEvent();
+    const message = createBaseCancelRequestResponse();
     while (reader.pos < end) {
       const tag = reader.uint32();
       switch (tag >>> 3) {
         case 1:
-          if (tag !== 10) {
-            break;
-          }
-
-          message.text = reader.string();
-          continue;
-        case 2:
-          if (tag !== 16) {
+          if (tag !== 8) {
             break;
           }
 
-          message.isThinking = reader.bool();
+          message.cancelled = reader.bool();
           continue;
       }
       if ((tag & 7) === 4 || tag === 0) {
@@ -2519,91 +6970,44 @@ export const TextChunkEvent = {
     return message;
   },
 
-  fromJSON(object: any): TextChunkEvent {
-    return {
-      text: isSet(object.text) ? globalThis.String(object.text) : "",
-      isThinking: isSet(object.isThinking) ? globalThis.Boolean(object.isThinking) : false,
-    };
+  fromJSON(object: any): CancelRequestResponse {
+    return { cancelled: isSet(object.cancelled) ? globalThis.Boolean(object.cancelled) : false };
   },
 
-  toJSON(message: TextChunkEvent): unknown {
+  toJSON(message: CancelRequestResponse): unknown {
     const obj: any = {};
-    if (message.text !== "") {
-      obj.text = message.text;
-    }
-    if (message.isThinking !== false) {
-      obj.isThinking = message.isThinking;
+    if (message.cancelled !== false) {
+      obj.cancelled = message.cancelled;
     }
     return obj;
   },
 
-  create<I extends Exact<DeepPartial<TextChunkEvent>, I>>(base?: I): TextChunkEvent {
-    return TextChunkEvent.fromPartial(base ?? ({} as any));
+  create<I extends Exact<DeepPartial<CancelRequestResponse>, I>>(base?: I): CancelRequestResponse {
+    return CancelRequestResponse.fromPartial(base ?? ({} as any));
   },
-  fromPartial<I extends Exact<DeepPartial<TextChunkEvent>, I>>(object: I): TextChunkEvent {
-    const message = createBaseTextChunkEvent();
-    message.text = object.text ?? "";
-    message.isThinking = object.isThinking ?? false;
+  fromPartial<I extends Exact<DeepPartial<CancelRequestResponse>, I>>(object: I): CancelRequestResponse {
+    const message = createBaseCancelRequestResponse();
+    message.cancelled = object.cancelled ?? false;
     return message;
   },
 };
 
-function createBaseToolCallEvent(): ToolCallEvent {
-  return { callId: "", toolName: "", arguments: undefined, timeoutMs: 0 };
+function createBaseHealthCheckRequest(): HealthCheckRequest {
+  return {};
 }
 
-export const ToolCallEvent = {
-  encode(message: ToolCallEvent, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
-    if (message.callId !== "") {
-      writer.uint32(10).string(message.callId);
-    }
-    if (message.toolName !== "") {
-      writer.uint32(18).string(message.toolName);
-    }
-    if (message.arguments !== undefined) {
-      Struct.encode(Struct.wrap(message.arguments), writer.uint32(26).fork()).ldelim();
-    }
-    if (message.timeoutMs !== 0) {
-      writer.uint32(32).int32(message.timeoutMs);
-    }
+export const HealthCheckRequest = {
+  encode(_: HealthCheckRequest, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
     return writer;
   },
 
-  decode(input: _m0.Reader | Uint8Array, length?: number): ToolCallEvent {
+  decode(input: _m0.Reader | Uint8Array, length?: number): HealthCheckRequest {
     const reader = input instanceof _m0.Reader ? input : _m0.Reader.create(input);
     let end = length === undefined ? reader.len : reader.pos + length;
-    const message = createBaseToolCallEvent();
+    const message = createBaseHealthCheckRequest();
     while (reader.pos < end) {
       const tag = reader.uint32();
       switch (tag >>> 3) {
-        case 1:
-          if (tag !== 10) {
-            break;
-          }
-
-          message.callId = reader.string();
-          continue;
-        case 2:
-          if (tag !== 18) {
-            break;
-          }
-
-          message.toolName = reader.string();
-          continue;
-        case 3:
-          if (tag !== 26) {
-            break;
-          }
-
-          message.arguments = Struct.unwrap(Struct.decode(reader, reader.uint32()));
-          continue;
-        case 4:
-          if (tag !== 32) {
-            break;
-          }
-
-          message.timeoutMs = reader.int32();
-          continue;
       }
       if ((tag & 7) === 4 || tag === 0) {
         break;
@@ -2613,70 +7017,40 @@ export const ToolCallEvent = {
     return message;
   },
 
-  fromJSON(object: any): ToolCallEvent {
-    return {
-      callId: isSet(object.callId) ? globalThis.String(object.callId) : "",
-      toolName: isSet(object.toolName) ? globalThis.String(object.toolName) : "",
-      arguments: isObject(object.arguments) ? object.arguments : undefined,
-      timeoutMs: isSet(object.timeoutMs) ? globalThis.Number(object.timeoutMs) : 0,
-    };
+  fromJSON(_: any): HealthCheckRequest {
+    return {};
   },
 
-  toJSON(message: ToolCallEvent): unknown {
+  toJSON(_: HealthCheckRequest): unknown {
     const obj: any = {};
-    if (message.callId !== "") {
-      obj.callId = message.callId;
-    }
-    if (message.toolName !== "") {
-      obj.toolName = message.toolName;
-    }
-    if (message.arguments !== undefined) {
-      obj.arguments = message.arguments;
-    }
-    if (message.timeoutMs !== 0) {
-      obj.timeoutMs = Math.round(message.timeoutMs);
-    }
     return obj;
   },
 
-  create<I extends Exact<DeepPartial<ToolCallEvent>, I>>(base?: I): ToolCallEvent {
-    return ToolCallEvent.fromPartial(base ?? ({} as any));
+  create<I extends Exact<DeepPartial<HealthCheckRequest>, I>>(base?: I): HealthCheckRequest {
+    return HealthCheckRequest.fromPartial(base ?? ({} as any));
   },
-  fromPartial<I extends Exact<DeepPartial<ToolCallEvent>, I>>(object: I): ToolCallEvent {
-    const message = createBaseToolCallEvent();
-    message.callId = object.callId ?? "";
-    message.toolName = object.toolName ?? "";
-    message.arguments = object.arguments ?? undefined;
-    message.timeoutMs = object.timeoutMs ?? 0;
+  fromPartial<I extends Exact<DeepPartial<HealthCheckRequest>, I>>(_: I): HealthCheckRequest {
+    const message = createBaseHealthCheckRequest();
     return message;
   },
 };
 
-function createBaseFinalResponse(): FinalResponse {
-  return { response: "", updatedMessages: [], tokenUsage: undefined, durationMs: 0 };
+function createBaseHealthCheckResponse(): HealthCheckResponse {
+  return { status: "" };
 }
 
-export const FinalResponse = {
-  encode(message: FinalResponse, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
-    if (message.response !== "") {
-      writer.uint32(10).string(message.response);
-    }
-    for (const v of message.updatedMessages) {
-      Message.encode(v!, writer.uint32(18).fork()).ldelim();
-    }
-    if (message.tokenUsage !== undefined) {
-      TokenUsage.encode(message.tokenUsage, writer.uint32(26).fork()).ldelim();
-    }
-    if (message.durationMs !== 0) {
-      writer.uint32(32).int64(message.durationMs);
+export const HealthCheckResponse = {
+  encode(message: HealthCheckResponse, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
+    if (message.status !== "") {
+      writer.uint32(10).string(message.status);
     }
     return writer;
   },
 
-  decode(input: _m0.Reader | Uint8Array, length?: number): FinalResponse {
+  decode(input: _m0.Reader | Uint8Array, length?: number): HealthCheckResponse {
     const reader = input instanceof _m0.Reader ? input : _m0.Reader.create(input);
     let end = length === undefined ? reader.len : reader.pos + length;
-    const message = createBaseFinalResponse();
+    const message = createBaseHealthCheckResponse();
     while (reader.pos < end) {
       const tag = reader.uint32();
       switch (tag >>> 3) {
@@ -2685,28 +7059,7 @@ export const FinalResponse = {
             break;
           }
 
-          message.response = reader.string();
-          continue;
-        case 2:
-          if (tag !== 18) {
-            break;
-          }
-
-          message.updatedMessages.push(Message.decode(reader, reader.uint32()));
-          continue;
-        case 3:
-          if (tag !== 26) {
-            break;
-          }
-
-          message.tokenUsage = TokenUsage.decode(reader, reader.uint32());
-          continue;
-        case 4:
-          if (tag !== 32) {
-            break;
-          }
-
-          message.durationMs = longToNumber(reader.int64() as Long);
+          message.status = reader.string();
           continue;
       }
       if ((tag & 7) === 4 || tag === 0) {
@@ -2717,74 +7070,50 @@ export const FinalResponse = {
     return message;
   },
 
-  fromJSON(object: any): FinalResponse {
-    return {
-      response: isSet(object.response) ? globalThis.String(object.response) : "",
-      updatedMessages: globalThis.Array.isArray(object?.updatedMessages)
-        ? object.updatedMessages.map((e: any) => Message.fromJSON(e))
-        : [],
-      tokenUsage: isSet(object.tokenUsage) ? TokenUsage.fromJSON(object.tokenUsage) : undefined,
-      durationMs: isSet(object.durationMs) ? globalThis.Number(object.durationMs) : 0,
-    };
+  fromJSON(object: any): HealthCheckResponse {
+    return { status: isSet(object.status) ? globalThis.String(object.status) : "" };
   },
 
-  toJSON(message: FinalResponse): unknown {
+  toJSON(message: HealthCheckResponse): unknown {
     const obj: any = {};
-    if (message.response !== "") {
-      obj.response = message.response;
-    }
-    if (message.updatedMessages?.length) {
-      obj.updatedMessages = message.updatedMessages.map((e) => Message.toJSON(e));
-    }
-    if (message.tokenUsage !== undefined) {
-      obj.tokenUsage = TokenUsage.toJSON(message.tokenUsage);
-    }
-    if (message.durationMs !== 0) {
-      obj.durationMs = Math.round(message.durationMs);
+    if (message.status !== "") {
+      obj.status = message.status;
     }
     return obj;
   },
 
-  create<I extends Exact<DeepPartial<FinalResponse>, I>>(base?: I): FinalResponse {
-    return FinalResponse.fromPartial(base ?? ({} as any));
+  create<I extends Exact<DeepPartial<HealthCheckResponse>, I>>(base?: I): HealthCheckResponse {
+    return HealthCheckResponse.fromPartial(base ?? ({} as any));
   },
-  fromPartial<I extends Exact<DeepPartial<FinalResponse>, I>>(object: I): FinalResponse {
-    const message = createBaseFinalResponse();
-    message.response = object.response ?? "";
-    message.updatedMessages = object.updatedMessages?.map((e) => Message.fromPartial(e)) || [];
-    message.tokenUsage = (object.tokenUsage !== undefined && object.tokenUsage !== null)
-      ? TokenUsage.fromPartial(object.tokenUsage)
-      : undefined;
-    message.durationMs = object.durationMs ?? 0;
+  fromPartial<I extends Exact<DeepPartial<HealthCheckResponse>, I>>(object: I): HealthCheckResponse {
+    const message = createBaseHealthCheckResponse();
+    message.status = object.status ?? "";
     return message;
   },
 };
 
-function createBaseErrorEvent(): ErrorEvent {
-  return { code: "", message: "", details: undefined, fatal: false };
+function createBaseHealthCheckDetailedRequest(): HealthCheckDetailedRequest {
+  return { agentId: "", skipConnections: false, timeoutMs: 0 };
 }
 
-export const ErrorEvent = {
-  encode(message: ErrorEvent, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
-    if (message.code !== "") {
-      writer.uint32(10).string(message.code);
-    }
-    if (message.message !== "") {
-      writer.uint32(18).string(message.message);
+export const HealthCheckDetailedRequest = {
+  encode(message: HealthCheckDetailedRequest, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
+    if (message.agentId !== "") {
+      writer.uint32(10).string(message.agentId);
     }
-    if (message.details !== undefined) {
-      Struct.encode(Struct.wrap(message.details), writer.uint32(26).fork()).ldelim();
+    if (message.skipConnections !== false) {
+      writer.uint32(16).bool(message.skipConnections);
     }
-    if (message.fatal !== false) {
-      writer.uint32(32).bool(message.fatal);
+    if (message.timeoutMs !== 0) {
+      writer.uint32(24).int64(message.timeoutMs);
     }
     return writer;
   },
 
-  decode(input: _m0.Reader | Uint8Array, length?: number): ErrorEvent {
+  decode(input: _m0.Reader | Uint8Array, length?: number): HealthCheckDetailedRequest {
     const reader = input instanceof _m0.Reader ? input : _m0.Reader.create(input);
     let end = length === undefined ? reader.len : reader.pos + length;
-    const message = createBaseErrorEvent();
+    const message = createBaseHealthCheckDetailedRequest();
     while (reader.pos < end) {
       const tag = reader.uint32();
       switch (tag >>> 3) {
@@ -2793,28 +7122,21 @@ export const ErrorEvent = {
             break;
           }
 
-          message.code = reader.string();
+          message.agentId = reader.string();
           continue;
         case 2:
-          if (tag !== 18) {
+          if (tag !== 16) {
             break;
           }
 
-          message.message = reader.string();
+          message.skipConnections = reader.bool();
           continue;
         case 3:
-          if (tag !== 26) {
-            break;
-          }
-
-          message.details = Struct.unwrap(Struct.decode(reader, reader.uint32()));
-          continue;
-        case 4:
-          if (tag !== 32) {
+          if (tag !== 24) {
             break;
           }
 
-          message.fatal = reader.bool();
+          message.timeoutMs = longToNumber(reader.int64() as Long);
           continue;
       }
       if ((tag & 7) === 4 || tag === 0) {
@@ -2825,99 +7147,77 @@ export const ErrorEvent = {
     return message;
   },
 
-  fromJSON(object: any): ErrorEvent {
+  fromJSON(object: any): HealthCheckDetailedRequest {
     return {
-      code: isSet(object.code) ? globalThis.String(object.code) : "",
-      message: isSet(object.message) ? globalThis.String(object.message) : "",
-      details: isObject(object.details) ? object.details : undefined,
-      fatal: isSet(object.fatal) ? globalThis.Boolean(object.fatal) : false,
+      agentId: isSet(object.agentId) ? globalThis.String(object.agentId) : "",
+      skipConnections: isSet(object.skipConnections) ? globalThis.Boolean(object.skipConnections) : false,
+      timeoutMs: isSet(object.timeoutMs) ? globalThis.Number(object.timeoutMs) : 0,
     };
   },
 
-  toJSON(message: ErrorEvent): unknown {
+  toJSON(message: HealthCheckDetailedRequest): unknown {
     const obj: any = {};
-    if (message.code !== "") {
-      obj.code = message.code;
-    }
-    if (message.message !== "") {
-      obj.message = message.message;
+    if (message.agentId !== "") {
+      obj.agentId = message.agentId;
     }
-    if (message.details !== undefined) {
-      obj.details = message.details;
+    if (message.skipConnections !== false) {
+      obj.skipConnections = message.skipConnections;
     }
-    if (message.fatal !== false) {
-      obj.fatal = message.fatal;
+    if (message.timeoutMs !== 0) {
+      obj.timeoutMs = Math.round(message.timeoutMs);
     }
     return obj;
   },
 
-  create<I extends Exact<DeepPartial<ErrorEvent>, I>>(base?: I): ErrorEvent {
-    return ErrorEvent.fromPartial(base ?? ({} as any));
+  create<I extends Exact<DeepPartial<HealthCheckDetailedRequest>, I>>(base?: I): HealthCheckDetailedRequest {
+    return HealthCheckDetailedRequest.fromPartial(base ?? ({} as any));
   },
-  fromPartial<I extends Exact<DeepPartial<ErrorEvent>, I>>(object: I): ErrorEvent {
-    const message = createBaseErrorEvent();
-    message.code = object.code ?? "";
-    message.message = object.message ?? "";
-    message.details = object.details ?? undefined;
-    message.fatal = object.fatal ?? false;
+  fromPartial<I extends Exact<DeepPartial<HealthCheckDetailedRequest>, I>>(object: I): HealthCheckDetailedRequest {
+    const message = createBaseHealthCheckDetailedRequest();
+    message.agentId = object.agentId ?? "";
+    message.skipConnections = object.skipConnections ?? false;
+    message.timeoutMs = object.timeoutMs ?? 0;
     return message;
   },
 };
 
-function createBaseAgentEvent(): AgentEvent {
-  return {
-    type: "",
-    timestamp: undefined,
-    traceId: "",
-    spanId: "",
-    parentId: "",
-    correlationId: "",
-    hierarchyLevel: 0,
-    sessionId: "",
-    component: "",
-    data: undefined,
-  };
+function createBaseHealthCheckDetailedResponse(): HealthCheckDetailedResponse {
+  return { status: "", ready: false, checks: {}, draining: false, activeAgents: 0, activeConversations: 0, agents: [], cache: undefined };
 }
 
-export const AgentEvent = {
-  encode(message: AgentEvent, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
-    if (message.type !== "") {
-      writer.uint32(10).string(message.type);
-    }
-    if (message.timestamp !== undefined) {
-      Timestamp.encode(toTimestamp(message.timestamp), writer.uint32(18).fork()).ldelim();
-    }
-    if (message.traceId !== "") {
-      writer.uint32(26).string(message.traceId);
-    }
-    if (message.spanId !== "") {
-      writer.uint32(34).string(message.spanId);
+export const HealthCheckDetailedResponse = {
+  encode(message: HealthCheckDetailedResponse, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
+    if (message.status !== "") {
+      writer.uint32(10).string(message.status);
     }
-    if (message.parentId !== "") {
-      writer.uint32(42).string(message.parentId);
+    if (message.ready !== false) {
+      writer.uint32(16).bool(message.ready);
     }
-    if (message.correlationId !== "") {
-      writer.uint32(50).string(message.correlationId);
+    Object.entries(message.checks).forEach(([key, value]) => {
+      HealthCheckDetailedResponse_ChecksEntry.encode({ key: key as any, value }, writer.uint32(26).fork()).ldelim();
+    });
+    if (message.draining !== false) {
+      writer.uint32(32).bool(message.draining);
     }
-    if (message.hierarchyLevel !== 0) {
-      writer.uint32(56).int32(message.hierarchyLevel);
+    if (message.activeAgents !== 0) {
+      writer.uint32(40).int32(message.activeAgents);
     }
-    if (message.sessionId !== "") {
-      writer.uint32(66).string(message.sessionId);
+    if (message.activeConversations !== 0) {
+      writer.uint32(48).int32(message.activeConversations);
     }
-    if (message.component !== "") {
-      writer.uint32(74).string(message.component);
+    for (const v of message.agents) {
+      AgentHealth.encode(v!, writer.uint32(58).fork()).ldelim();
     }
-    if (message.data !== undefined) {
-      Struct.encode(Struct.wrap(message.data), writer.uint32(82).fork()).ldelim();
+    if (message.cache !== undefined) {
+      CacheStatus.encode(message.cache, writer.uint32(66).fork()).ldelim();
     }
     return writer;
   },
 
-  decode(input: _m0.Reader | Uint8Array, length?: number): AgentEvent {
+  decode(input: _m0.Reader | Uint8Array, length?: number): HealthCheckDetailedResponse {
     const reader = input instanceof _m0.Reader ? input : _m0.Reader.create(input);
     let end = length === undefined ? reader.len : reader.pos + length;
-    const message = createBaseAgentEvent();
+    const message = createBaseHealthCheckDetailedResponse();
     while (reader.pos < end) {
       const tag = reader.uint32();
       switch (tag >>> 3) {
@@ -2926,70 +7226,59 @@ export const AgentEvent = {
             break;
           }
 
-          message.type = reader.string();
+          message.status = reader.string();
           continue;
         case 2:
-          if (tag !== 18) {
+          if (tag !== 16) {
             break;
           }
 
-          message.timestamp = fromTimestamp(Timestamp.decode(reader, reader.uint32()));
+          message.ready = reader.bool();
           continue;
         case 3:
           if (tag !== 26) {
             break;
           }
 
-          message.traceId = reader.string();
+          const entry3 = HealthCheckDetailedResponse_ChecksEntry.decode(reader, reader.uint32());
+          if (entry3.value !== undefined) {
+            message.checks[entry3.key] = entry3.value;
+          }
           continue;
         case 4:
-          if (tag !== 34) {
+          if (tag !== 32) {
             break;
           }
 
-          message.spanId = reader.string();
+          message.draining = reader.bool();
           continue;
         case 5:
-          if (tag !== 42) {
+          if (tag !== 40) {
             break;
           }
 
-          message.parentId = reader.string();
+          message.activeAgents = reader.int32();
           continue;
         case 6:
-          if (tag !== 50) {
+          if (tag !== 48) {
             break;
           }
 
-          message.correlationId = reader.string();
+          message.activeConversations = reader.int32();
           continue;
         case 7:
-          if (tag !== 56) {
+          if (tag !== 58) {
             break;
           }
 
-          message.hierarchyLevel = reader.int32();
+          message.agents.push(AgentHealth.decode(reader, reader.uint32()));
           continue;
         case 8:
           if (tag !== 66) {
             break;
           }
 
-          message.sessionId = reader.string();
-          continue;
-        case 9:
-          if (tag !== 74) {
-            break;
-          }
-
-          message.component = reader.string();
-          continue;
-        case 10:
-          if (tag !== 82) {
-            break;
-          }
-
-          message.data = Struct.unwrap(Struct.decode(reader, reader.uint32()));
+          message.cache = CacheStatus.decode(reader, reader.uint32());
           continue;
       }
       if ((tag & 7) === 4 || tag === 0) {
@@ -3000,94 +7289,105 @@ export const AgentEvent = {
     return message;
   },
 
-  fromJSON(object: any): AgentEvent {
+  fromJSON(object: any): HealthCheckDetailedResponse {
     return {
-      type: isSet(object.type) ? globalThis.String(object.type) : "",
-      timestamp: isSet(object.timestamp) ? fromJsonTimestamp(object.timestamp) : undefined,
-      traceId: isSet(object.traceId) ? globalThis.String(object.traceId) : "",
-      spanId: isSet(object.spanId) ? globalThis.String(object.spanId) : "",
-      parentId: isSet(object.parentId) ? globalThis.String(object.parentId) : "",
-      correlationId: isSet(object.correlationId) ? globalThis.String(object.correlationId) : "",
-      hierarchyLevel: isSet(object.hierarchyLevel) ? globalThis.Number(object.hierarchyLevel) : 0,
-      sessionId: isSet(object.sessionId) ? globalThis.String(object.sessionId) : "",
-      component: isSet(object.component) ? globalThis.String(object.component) : "",
-      data: isObject(object.data) ? object.data : undefined,
+      status: isSet(object.status) ? globalThis.String(object.status) : "",
+      ready: isSet(object.ready) ? globalThis.Boolean(object.ready) : false,
+      checks: isObject(object.checks)
+        ? Object.entries(object.checks).reduce<{ [key: string]: string }>((acc, [key, value]) => {
+          acc[key] = String(value);
+          return acc;
+        }, {})
+        : {},
+      draining: isSet(object.draining) ? globalThis.Boolean(object.draining) : false,
+      activeAgents: isSet(object.activeAgents) ? globalThis.Number(object.activeAgents) : 0,
+      activeConversations: isSet(object.activeConversations) ? globalThis.Number(object.activeConversations) : 0,
+      agents: globalThis.Array.isArray(object?.agents) ? object.agents.map((e: any) => AgentHealth.fromJSON(e)) : [],
+      cache: isSet(object.cache) ? CacheStatus.fromJSON(object.cache) : undefined,
     };
   },
 
-  toJSON(message: AgentEvent): unknown {
+  toJSON(message: HealthCheckDetailedResponse): unknown {
     const obj: any = {};
-    if (message.type !== "") {
-      obj.type = message.type;
-    }
-    if (message.timestamp !== undefined) {
-      obj.timestamp = message.timestamp.toISOString();
-    }
-    if (message.traceId !== "") {
-      obj.traceId = message.traceId;
+    if (message.status !== "") {
+      obj.status = message.status;
     }
-    if (message.spanId !== "") {
-      obj.spanId = message.spanId;
+    if (message.ready !== false) {
+      obj.ready = message.ready;
     }
-    if (message.parentId !== "") {
-      obj.parentId = message.parentId;
+    if (message.checks) {
+      const entries = Object.entries(message.checks);
+      if (entries.length > 0) {
+        obj.checks = {};
+        entries.forEach(([k, v]) => {
+          obj.checks[k] = v;
+        });
+      }
     }
-    if (message.correlationId !== "") {
-      obj.correlationId = message.correlationId;
+    if (message.draining !== false) {
+      obj.draining = message.draining;
     }
-    if (message.hierarchyLevel !== 0) {
-      obj.hierarchyLevel = Math.round(message.hierarchyLevel);
+    if (message.activeAgents !== 0) {
+      obj.activeAgents = Math.round(message.activeAgents);
     }
-    if (message.sessionId !== "") {
-      obj.sessionId = message.sessionId;
+    if (message.activeConversations !== 0) {
+      obj.activeConversations = Math.round(message.activeConversations);
     }
-    if (message.component !== "") {
-      obj.component = message.component;
+    if (message.agents?.length) {
+      obj.agents = message.agents.map((e) => AgentHealth.toJSON(e));
     }
-    if (message.data !== undefined) {
-      obj.data = message.data;
+    if (message.cache !== undefined) {
+      obj.cache = CacheStatus.toJSON(message.cache);
     }
     return obj;
   },
 
-  create<I extends Exact<DeepPartial<AgentEvent>, I>>(base?: I): AgentEvent {
-    return AgentEvent.fromPartial(base ?? ({} as any));
+  create<I extends Exact<DeepPartial<HealthCheckDetailedResponse>, I>>(base?: I): HealthCheckDetailedResponse {
+    return HealthCheckDetailedResponse.fromPartial(base ?? ({} as any));
   },
-  fromPartial<I extends Exact<DeepPartial<AgentEvent>, I>>(object: I): AgentEvent {
-    const message = createBaseAgentEvent();
-    message.type = object.type ?? "";
-    message.timestamp = object.timestamp ?? undefined;
-    message.traceId = object.traceId ?? "";
-    message.spanId = object.spanId ?? "";
-    message.parentId = object.parentId ?? "";
-    message.correlationId = object.correlationId ?? "";
-    message.hierarchyLevel = object.hierarchyLevel ?? 0;
-    message.sessionId = object.sessionId ?? "";
-    message.component = object.component ?? "";
-    message.data = object.data ?? undefined;
+  fromPartial<I extends Exact<DeepPartial<HealthCheckDetailedResponse>, I>>(object: I): HealthCheckDetailedResponse {
+    const message = createBaseHealthCheckDetailedResponse();
+    message.status = object.status ?? "";
+    message.ready = object.ready ?? false;
+    message.checks = Object.entries(object.checks ?? {}).reduce<{ [key: string]: string }>(
+      (acc, [key, value]) => {
+        if (value !== undefined) {
+          acc[key] = globalThis.String(value);
+        }
+        return acc;
+      },
+      {},
+    );
+    message.draining = object.draining ?? false;
+    message.activeAgents = object.activeAgents ?? 0;
+    message.activeConversations = object.activeConversations ?? 0;
+    message.agents = object.agents?.map((e) => AgentHealth.fromPartial(e)) || [];
+    message.cache = (object.cache !== undefined && object.cache !== null)
+      ? CacheStatus.fromPartial(object.cache)
+      : undefined;
     return message;
   },
 };
 
-function createBaseMessage(): Message {
-  return { role: "", content: "" };
+function createBaseHealthCheckDetailedResponse_ChecksEntry(): HealthCheckDetailedResponse_ChecksEntry {
+  return { key: "", value: "" };
 }
 
-export const Message = {
-  encode(message: Message, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
-    if (message.role !== "") {
-      writer.uint32(10).string(message.role);
+export const HealthCheckDetailedResponse_ChecksEntry = {
+  encode(message: HealthCheckDetailedResponse_ChecksEntry, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
+    if (message.key !== "") {
+      writer.uint32(10).string(message.key);
     }
-    if (message.content !== "") {
-      writer.uint32(18).string(message.content);
+    if (message.value !== "") {
+      writer.uint32(18).string(message.value);
     }
     return writer;
   },
 
-  decode(input: _m0.Reader | Uint8Array, length?: number): Message {
+  decode(input: _m0.Reader | Uint8Array, length?: number): HealthCheckDetailedResponse_ChecksEntry {
     const reader = input instanceof _m0.Reader ? input : _m0.Reader.create(input);
     let end = length === undefined ? reader.len : reader.pos + length;
-    const message = createBaseMessage();
+    const message = createBaseHealthCheckDetailedResponse_ChecksEntry();
     while (reader.pos < end) {
       const tag = reader.uint32();
       switch (tag >>> 3) {
@@ -3096,14 +7396,14 @@ export const Message = {
             break;
           }
 
-          message.role = reader.string();
+          message.key = reader.string();
           continue;
         case 2:
           if (tag !== 18) {
             break;
           }
 
-          message.content = reader.string();
+          message.value = reader.string();
           continue;
       }
       if ((tag & 7) === 4 || tag === 0) {
@@ -3114,131 +7414,57 @@ export const Message = {
     return message;
   },
 
-  fromJSON(object: any): Message {
+  fromJSON(object: any): HealthCheckDetailedResponse_ChecksEntry {
     return {
-      role: isSet(object.role) ? globalThis.String(object.role) : "",
-      content: isSet(object.content) ? globalThis.String(object.content) : "",
+      key: isSet(object.key) ? globalThis.String(object.key) : "",
+      value: isSet(object.value) ? globalThis.String(object.value) : "",
     };
   },
 
-  toJSON(message: Message): unknown {
+  toJSON(message: HealthCheckDetailedResponse_ChecksEntry): unknown {
     const obj: any = {};
-    if (message.role !== "") {
-      obj.role = message.role;
+    if (message.key !== "") {
+      obj.key = message.key;
     }
-    if (message.content !== "") {
-      obj.content = message.content;
+    if (message.value !== "") {
+      obj.value = message.value;
     }
     return obj;
   },
 
-  create<I extends Exact<DeepPartial<Message>, I>>(base?: I): Message {
-    return Message.fromPartial(base ?? ({} as any));
+  create<I extends Exact<DeepPartial<HealthCheckDetailedResponse_ChecksEntry>, I>>(base?: I): HealthCheckDetailedResponse_ChecksEntry {
+    return HealthCheckDetailedResponse_ChecksEntry.fromPartial(base ?? ({} as any));
   },
-  fromPartial<I extends Exact<DeepPartial<Message>, I>>(object: I): Message {
-    const message = createBaseMessage();
-    message.role = object.role ?? "";
-    message.content = object.content ?? "";
+  fromPartial<I extends Exact<DeepPartial<HealthCheckDetailedResponse_ChecksEntry>, I>>(object: I): HealthCheckDetailedResponse_ChecksEntry {
+    const message = createBaseHealthCheckDetailedResponse_ChecksEntry();
+    message.key = object.key ?? "";
+    message.value = object.value ?? "";
     return message;
   },
 };
 
-function createBaseAskRequest(): AskRequest {
-  return { agentId: "", question: "" };
+function createBaseAgentHealth(): AgentHealth {
+  return { agentId: "", sessionId: "", servers: [] };
 }
 
-export const AskRequest = {
-  encode(message: AskRequest, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
+export const AgentHealth = {
+  encode(message: AgentHealth, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
     if (message.agentId !== "") {
       writer.uint32(10).string(message.agentId);
     }
-    if (message.question !== "") {
-      writer.uint32(18).string(message.question);
-    }
-    return writer;
-  },
-
-  decode(input: _m0.Reader | Uint8Array, length?: number): AskRequest {
-    const reader = input instanceof _m0.Reader ? input : _m0.Reader.create(input);
-    let end = length === undefined ? reader.len : reader.pos + length;
-    const message = createBaseAskRequest();
-    while (reader.pos < end) {
-      const tag = reader.uint32();
-      switch (tag >>> 3) {
-        case 1:
-          if (tag !== 10) {
-            break;
-          }
-
-          message.agentId = reader.string();
-          continue;
-        case 2:
-          if (tag !== 18) {
-            break;
-          }
-
-          message.question = reader.string();
-          continue;
-      }
-      if ((tag & 7) === 4 || tag === 0) {
-        break;
-      }
-      reader.skipType(tag & 7);
-    }
-    return message;
-  },
-
-  fromJSON(object: any): AskRequest {
-    return {
-      agentId: isSet(object.agentId) ? globalThis.String(object.agentId) : "",
-      question: isSet(object.question) ? globalThis.String(object.question) : "",
-    };
-  },
-
-  toJSON(message: AskRequest): unknown {
-    const obj: any = {};
-    if (message.agentId !== "") {
-      obj.agentId = message.agentId;
-    }
-    if (message.question !== "") {
-      obj.question = message.question;
-    }
-    return obj;
-  },
-
-  create<I extends Exact<DeepPartial<AskRequest>, I>>(base?: I): AskRequest {
-    return AskRequest.fromPartial(base ?? ({} as any));
-  },
-  fromPartial<I extends Exact<DeepPartial<AskRequest>, I>>(object: I): AskRequest {
-    const message = createBaseAskRequest();
-    message.agentId = object.agentId ?? "";
-    message.question = object.question ?? "";
-    return message;
-  },
-};
-
-function createBaseAskResponse(): AskResponse {
-  return { response: "", tokenUsage: undefined, durationMs: 0 };
-}
-
-export const AskResponse = {
-  encode(message: AskResponse, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
-    if (message.response !== "") {
-      writer.uint32(10).string(message.response);
-    }
-    if (message.tokenUsage !== undefined) {
-      TokenUsage.encode(message.tokenUsage, writer.uint32(18).fork()).ldelim();
+    if (message.sessionId !== "") {
+      writer.uint32(18).string(message.sessionId);
     }
-    if (message.durationMs !== 0) {
-      writer.uint32(24).int64(message.durationMs);
+    for (const v of message.servers) {
+      ServerHealth.encode(v!, writer.uint32(26).fork()).ldelim();
     }
     return writer;
   },
 
-  decode(input: _m0.Reader | Uint8Array, length?: number): AskResponse {
+  decode(input: _m0.Reader | Uint8Array, length?: number): AgentHealth {
     const reader = input instanceof _m0.Reader ? input : _m0.Reader.create(input);
     let end = length === undefined ? reader.len : reader.pos + length;
-    const message = createBaseAskResponse();
+    const message = createBaseAgentHealth();
     while (reader.pos < end) {
       const tag = reader.uint32();
       switch (tag >>> 3) {
@@ -3247,21 +7473,21 @@ export const AskResponse = {
             break;
           }
 
-          message.response = reader.string();
+          message.agentId = reader.string();
           continue;
         case 2:
           if (tag !== 18) {
             break;
           }
 
-          message.tokenUsage = TokenUsage.decode(reader, reader.uint32());
+          message.sessionId = reader.string();
           continue;
         case 3:
-          if (tag !== 24) {
+          if (tag !== 26) {
             break;
           }
 
-          message.durationMs = longToNumber(reader.int64() as Long);
+          message.servers.push(ServerHealth.decode(reader, reader.uint32()));
           continue;
       }
       if ((tag & 7) === 4 || tag === 0) {
@@ -3272,61 +7498,62 @@ export const AskResponse = {
     return message;
   },
 
-  fromJSON(object: any): AskResponse {
+  fromJSON(object: any): AgentHealth {
     return {
-      response: isSet(object.response) ? globalThis.String(object.response) : "",
-      tokenUsage: isSet(object.tokenUsage) ? TokenUsage.fromJSON(object.tokenUsage) : undefined,
-      durationMs: isSet(object.durationMs) ? globalThis.Number(object.durationMs) : 0,
+      agentId: isSet(object.agentId) ? globalThis.String(object.agentId) : "",
+      sessionId: isSet(object.sessionId) ? globalThis.String(object.sessionId) : "",
+      servers: globalThis.Array.isArray(object?.servers) ? object.servers.map((e: any) => ServerHealth.fromJSON(e)) : [],
     };
   },
 
-  toJSON(message: AskResponse): unknown {
+  toJSON(message: AgentHealth): unknown {
     const obj: any = {};
-    if (message.response !== "") {
-      obj.response = message.response;
+    if (message.agentId !== "") {
+      obj.agentId = message.agentId;
     }
-    if (message.tokenUsage !== undefined) {
-      obj.tokenUsage = TokenUsage.toJSON(message.tokenUsage);
+    if (message.sessionId !== "") {
+      obj.sessionId = message.sessionId;
     }
-    if (message.durationMs !== 0) {
-      obj.durationMs = Math.round(message.durationMs);
+    if (message.servers?.length) {
+      obj.servers = message.servers.map((e) => ServerHealth.toJSON(e));
     }
     return obj;
   },
 
-  create<I extends Exact<DeepPartial<AskResponse>, I>>(base?: I): AskResponse {
-    return AskResponse.fromPartial(base ?? ({} as any));
+  create<I extends Exact<DeepPartial<AgentHealth>, I>>(base?: I): AgentHealth {
+    return AgentHealth.fromPartial(base ?? ({} as any));
   },
-  fromPartial<I extends Exact<DeepPartial<AskResponse>, I>>(object: I): AskResponse {
-    const message = createBaseAskResponse();
-    message.response = object.response ?? "";
-    message.tokenUsage = (object.tokenUsage !== undefined && object.tokenUsage !== null)
-      ? TokenUsage.fromPartial(object.tokenUsage)
-      : undefined;
-    message.durationMs = object.durationMs ?? 0;
+  fromPartial<I extends Exact<DeepPartial<AgentHealth>, I>>(object: I): AgentHealth {
+    const message = createBaseAgentHealth();
+    message.agentId = object.agentId ?? "";
+    message.sessionId = object.sessionId ?? "";
+    message.servers = object.servers?.map((e) => ServerHealth.fromPartial(e)) || [];
     return message;
   },
 };
 
-function createBaseAskWithHistoryRequest(): AskWithHistoryRequest {
-  return { agentId: "", messages: [] };
+function createBaseServerHealth(): ServerHealth {
+  return { name: "", healthy: false, error: "" };
 }
 
-export const AskWithHistoryRequest = {
-  encode(message: AskWithHistoryRequest, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
-    if (message.agentId !== "") {
-      writer.uint32(10).string(message.agentId);
+export const ServerHealth = {
+  encode(message: ServerHealth, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
+    if (message.name !== "") {
+      writer.uint32(10).string(message.name);
     }
-    for (const v of message.messages) {
-      Message.encode(v!, writer.uint32(18).fork()).ldelim();
+    if (message.healthy !== false) {
+      writer.uint32(16).bool(message.healthy);
+    }
+    if (message.error !== "") {
+      writer.uint32(26).string(message.error);
     }
     return writer;
   },
 
-  decode(input: _m0.Reader | Uint8Array, length?: number): AskWithHistoryRequest {
+  decode(input: _m0.Reader | Uint8Array, length?: number): ServerHealth {
     const reader = input instanceof _m0.Reader ? input : _m0.Reader.create(input);
     let end = length === undefined ? reader.len : reader.pos + length;
-    const message = createBaseAskWithHistoryRequest();
+    const message = createBaseServerHealth();
     while (reader.pos < end) {
       const tag = reader.uint32();
       switch (tag >>> 3) {
@@ -3335,14 +7562,21 @@ export const AskWithHistoryRequest = {
             break;
           }
 
-          message.agentId = reader.string();
+          message.name = reader.string();
           continue;
         case 2:
-          if (tag !== 18) {
+          if (tag !== 16) {
             break;
           }
 
-          message.messages.push(Message.decode(reader, reader.uint32()));
+          message.healthy = reader.bool();
+          continue;
+        case 3:
+          if (tag !== 26) {
+            break;
+          }
+
+          message.error = reader.string();
           continue;
       }
       if ((tag & 7) === 4 || tag === 0) {
@@ -3353,90 +7587,105 @@ export const AskWithHistoryRequest = {
     return message;
   },
 
-  fromJSON(object: any): AskWithHistoryRequest {
+  fromJSON(object: any): ServerHealth {
     return {
-      agentId: isSet(object.agentId) ? globalThis.String(object.agentId) : "",
-      messages: globalThis.Array.isArray(object?.messages) ? object.messages.map((e: any) => Message.fromJSON(e)) : [],
+      name: isSet(object.name) ? globalThis.String(object.name) : "",
+      healthy: isSet(object.healthy) ? globalThis.Boolean(object.healthy) : false,
+      error: isSet(object.error) ? globalThis.String(object.error) : "",
     };
   },
 
-  toJSON(message: AskWithHistoryRequest): unknown {
+  toJSON(message: ServerHealth): unknown {
     const obj: any = {};
-    if (message.agentId !== "") {
-      obj.agentId = message.agentId;
+    if (message.name !== "") {
+      obj.name = message.name;
     }
-    if (message.messages?.length) {
-      obj.messages = message.messages.map((e) => Message.toJSON(e));
+    if (message.healthy !== false) {
+      obj.healthy = message.healthy;
+    }
+    if (message.error !== "") {
+      obj.error = message.error;
     }
     return obj;
   },
 
-  create<I extends Exact<DeepPartial<AskWithHistoryRequest>, I>>(base?: I): AskWithHistoryRequest {
-    return AskWithHistoryRequest.fromPartial(base ?? ({} as any));
+  create<I extends Exact<DeepPartial<ServerHealth>, I>>(base?: I): ServerHealth {
+    return ServerHealth.fromPartial(base ?? ({} as any));
   },
-  fromPartial<I extends Exact<DeepPartial<AskWithHistoryRequest>, I>>(object: I): AskWithHistoryRequest {
-    const message = createBaseAskWithHistoryRequest();
-    message.agentId = object.agentId ?? "";
-    message.messages = object.messages?.map((e) => Message.fromPartial(e)) || [];
+  fromPartial<I extends Exact<DeepPartial<ServerHealth>, I>>(object: I): ServerHealth {
+    const message = createBaseServerHealth();
+    message.name = object.name ?? "";
+    message.healthy = object.healthy ?? false;
+    message.error = object.error ?? "";
     return message;
   },
 };
 
-function createBaseAskWithHistoryResponse(): AskWithHistoryResponse {
-  return { response: "", updatedMessages: [], tokenUsage: undefined, durationMs: 0 };
+function createBaseCacheStatus(): CacheStatus {
+  return { totalEntries: 0, validEntries: 0, expiredEntries: 0, ttlMinutes: 0, directory: "" };
 }
 
-export const AskWithHistoryResponse = {
-  encode(message: AskWithHistoryResponse, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
-    if (message.response !== "") {
-      writer.uint32(10).string(message.response);
+export const CacheStatus = {
+  encode(message: CacheStatus, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
+    if (message.totalEntries !== 0) {
+      writer.uint32(8).int32(message.totalEntries);
     }
-    for (const v of message.updatedMessages) {
-      Message.encode(v!, writer.uint32(18).fork()).ldelim();
+    if (message.validEntries !== 0) {
+      writer.uint32(16).int32(message.validEntries);
     }
-    if (message.tokenUsage !== undefined) {
-      TokenUsage.encode(message.tokenUsage, writer.uint32(26).fork()).ldelim();
+    if (message.expiredEntries !== 0) {
+      writer.uint32(24).int32(message.expiredEntries);
     }
-    if (message.durationMs !== 0) {
-      writer.uint32(32).int64(message.durationMs);
+    if (message.ttlMinutes !== 0) {
+      writer.uint32(32).int32(message.ttlMinutes);
+    }
+    if (message.directory !== "") {
+      writer.uint32(42).string(message.directory);
     }
     return writer;
   },
 
-  decode(input: _m0.Reader | Uint8Array, length?: number): AskWithHistoryResponse {
+  decode(input: _m0.Reader | Uint8Array, length?: number): CacheStatus {
     const reader = input instanceof _m0.Reader ? input : _m0.Reader.create(input);
     let end = length === undefined ? reader.len : reader.pos + length;
-    const message = createBaseAskWithHistoryResponse();
+    const message = createBaseCacheStatus();
     while (reader.pos < end) {
       const tag = reader.uint32();
       switch (tag >>> 3) {
         case 1:
-          if (tag !== 10) {
+          if (tag !== 8) {
             break;
           }
 
-          message.response = reader.string();
+          message.totalEntries = reader.int32();
           continue;
         case 2:
-          if (tag !== 18) {
+          if (tag !== 16) {
             break;
           }
 
-          message.updatedMessages.push(Message.decode(reader, reader.uint32()));
+          message.validEntries = reader.int32();
           continue;
         case 3:
-          if (tag !== 26) {
+          if (tag !== 24) {
             break;
           }
 
-          message.tokenUsage = TokenUsage.decode(reader, reader.uint32());
+          message.expiredEntries = reader.int32();
           continue;
         case 4:
           if (tag !== 32) {
             break;
           }
 
-          message.durationMs = longToNumber(reader.int64() as Long);
+          message.ttlMinutes = reader.int32();
+          continue;
+        case 5:
+          if (tag !== 42) {
+            break;
+          }
+
+          message.directory = reader.string();
           continue;
       }
       if ((tag & 7) === 4 || tag === 0) {
@@ -3447,62 +7696,63 @@ export const AskWithHistoryResponse = {
     return message;
   },
 
-  fromJSON(object: any): AskWithHistoryResponse {
+  fromJSON(object: any): CacheStatus {
     return {
-      response: isSet(object.response) ? globalThis.String(object.response) : "",
-      updatedMessages: globalThis.Array.isArray(object?.updatedMessages)
-        ? object.updatedMessages.map((e: any) => Message.fromJSON(e))
-        : [],
-      tokenUsage: isSet(object.tokenUsage) ? TokenUsage.fromJSON(object.tokenUsage) : undefined,
-      durationMs: isSet(object.durationMs) ? globalThis.Number(object.durationMs) : 0,
+      totalEntries: isSet(object.totalEntries) ? globalThis.Number(object.totalEntries) : 0,
+      validEntries: isSet(object.validEntries) ? globalThis.Number(object.validEntries) : 0,
+      expiredEntries: isSet(object.expiredEntries) ? globalThis.Number(object.expiredEntries) : 0,
+      ttlMinutes: isSet(object.ttlMinutes) ? globalThis.Number(object.ttlMinutes) : 0,
+      directory: isSet(object.directory) ? globalThis.String(object.directory) : "",
     };
   },
 
-  toJSON(message: AskWithHistoryResponse): unknown {
+  toJSON(message: CacheStatus): unknown {
     const obj: any = {};
-    if (message.response !== "") {
-      obj.response = message.response;
+    if (message.totalEntries !== 0) {
+      obj.totalEntries = Math.round(message.totalEntries);
     }
-    if (message.updatedMessages?.length) {
-      obj.updatedMessages = message.updatedMessages.map((e) => Message.toJSON(e));
+    if (message.validEntries !== 0) {
+      obj.validEntries = Math.round(message.validEntries);
     }
-    if (message.tokenUsage !== undefined) {
-      obj.tokenUsage = TokenUsage.toJSON(message.tokenUsage);
+    if (message.expiredEntries !== 0) {
+      obj.expiredEntries = Math.round(message.expiredEntries);
     }
-    if (message.durationMs !== 0) {
-      obj.durationMs = Math.round(message.durationMs);
+    if (message.ttlMinutes !== 0) {
+      obj.ttlMinutes = Math.round(message.ttlMinutes);
+    }
+    if (message.directory !== "") {
+      obj.directory = message.directory;
     }
     return obj;
   },
 
-  create<I extends Exact<DeepPartial<AskWithHistoryResponse>, I>>(base?: I): AskWithHistoryResponse {
-    return AskWithHistoryResponse.fromPartial(base ?? ({} as any));
+  create<I extends Exact<DeepPartial<CacheStatus>, I>>(base?: I): CacheStatus {
+    return CacheStatus.fromPartial(base ?? ({} as any));
   },
-  fromPartial<I extends Exact<DeepPartial<AskWithHistoryResponse>, I>>(object: I): AskWithHistoryResponse {
-    const message = createBaseAskWithHistoryResponse();
-    message.response = object.response ?? "";
-    message.updatedMessages = object.updatedMessages?.map((e) => Message.fromPartial(e)) || [];
-    message.tokenUsage = (object.tokenUsage !== undefined && object.tokenUsage !== null)
-      ? TokenUsage.fromPartial(object.tokenUsage)
-      : undefined;
-    message.durationMs = object.durationMs ?? 0;
+  fromPartial<I extends Exact<DeepPartial<CacheStatus>, I>>(object: I): CacheStatus {
+    const message = createBaseCacheStatus();
+    message.totalEntries = object.totalEntries ?? 0;
+    message.validEntries = object.validEntries ?? 0;
+    message.expiredEntries = object.expiredEntries ?? 0;
+    message.ttlMinutes = object.ttlMinutes ?? 0;
+    message.directory = object.directory ?? "";
     return message;
   },
 };
 
-function createBaseHealthCheckRequest(): HealthCheckRequest {
+function createBaseListRecoverableConversationsRequest(): ListRecoverableConversationsRequest {
   return {};
 }
 
-export const HealthCheckRequest = {
-  encode(_: HealthCheckRequest, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
+export const ListRecoverableConversationsRequest = {
+  encode(_: ListRecoverableConversationsRequest, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
     return writer;
   },
 
-  decode(input: _m0.Reader | Uint8Array, length?: number): HealthCheckRequest {
+  decode(input: _m0.Reader | Uint8Array, length?: number): ListRecoverableConversationsRequest {
     const reader = input instanceof _m0.Reader ? input : _m0.Reader.create(input);
     let end = length === undefined ? reader.len : reader.pos + length;
-    const message = createBaseHealthCheckRequest();
+    const message = createBaseListRecoverableConversationsRequest();
     while (reader.pos < end) {
       const tag = reader.uint32();
       switch (tag >>> 3) {
@@ -3515,49 +7765,211 @@ export const HealthCheckRequest = {
     return message;
   },
 
-  fromJSON(_: any): HealthCheckRequest {
+  fromJSON(_: any): ListRecoverableConversationsRequest {
     return {};
   },
 
-  toJSON(_: HealthCheckRequest): unknown {
+  toJSON(_: ListRecoverableConversationsRequest): unknown {
+    const obj: any = {};
+    return obj;
+  },
+
+  create<I extends Exact<DeepPartial<ListRecoverableConversationsRequest>, I>>(base?: I): ListRecoverableConversationsRequest {
+    return ListRecoverableConversationsRequest.fromPartial(base ?? ({} as any));
+  },
+  fromPartial<I extends Exact<DeepPartial<ListRecoverableConversationsRequest>, I>>(_: I): ListRecoverableConversationsRequest {
+    const message = createBaseListRecoverableConversationsRequest();
+    return message;
+  },
+};
+
+function createBaseListRecoverableConversationsResponse(): ListRecoverableConversationsResponse {
+  return { conversations: [] };
+}
+
+export const ListRecoverableConversationsResponse = {
+  encode(message: ListRecoverableConversationsResponse, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
+    for (const v of message.conversations) {
+      RecoverableConversation.encode(v!, writer.uint32(10).fork()).ldelim();
+    }
+    return writer;
+  },
+
+  decode(input: _m0.Reader | Uint8Array, length?: number): ListRecoverableConversationsResponse {
+    const reader = input instanceof _m0.Reader ? input : _m0.Reader.create(input);
+    let end = length === undefined ? reader.len : reader.pos + length;
+    const message = createBaseListRecoverableConversationsResponse();
+    while (reader.pos < end) {
+      const tag = reader.uint32();
+      switch (tag >>> 3) {
+        case 1:
+          if (tag !== 10) {
+            break;
+          }
+
+          message.conversations.push(RecoverableConversation.decode(reader, reader.uint32()));
+          continue;
+      }
+      if ((tag & 7) === 4 || tag === 0) {
+        break;
+      }
+      reader.skipType(tag & 7);
+    }
+    return message;
+  },
+
+  fromJSON(object: any): ListRecoverableConversationsResponse {
+    return {
+      conversations: globalThis.Array.isArray(object?.conversations)
+        ? object.conversations.map((e: any) => RecoverableConversation.fromJSON(e))
+        : [],
+    };
+  },
+
+  toJSON(message: ListRecoverableConversationsResponse): unknown {
     const obj: any = {};
+    if (message.conversations?.length) {
+      obj.conversations = message.conversations.map((e) => RecoverableConversation.toJSON(e));
+    }
     return obj;
   },
 
-  create<I extends Exact<DeepPartial<HealthCheckRequest>, I>>(base?: I): HealthCheckRequest {
-    return HealthCheckRequest.fromPartial(base ?? ({} as any));
-  },
-  fromPartial<I extends Exact<DeepPartial<HealthCheckRequest>, I>>(_: I): HealthCheckRequest {
-    const message = createBaseHealthCheckRequest();
-    return message;
-  },
-};
+  create<I extends Exact<DeepPartial<ListRecoverableConversationsResponse>, I>>(base?: I): ListRecoverableConversationsResponse {
+    return ListRecoverableConversationsResponse.fromPartial(base ?? ({} as any));
+  },
+  fromPartial<I extends Exact<DeepPartial<ListRecoverableConversationsResponse>, I>>(object: I): ListRecoverableConversationsResponse {
+    const message = createBaseListRecoverableConversationsResponse();
+    message.conversations = object.conversations?.map((e) => RecoverableConversation.fromPartial(e)) || [];
+    return message;
+  },
+};
+
+function createBaseRecoverableConversation(): RecoverableConversation {
+  return {
+    id: "",
+    sessionId: "",
+    userId: "",
+    provider: "",
+    modelId: "",
+    question: "",
+    turn: 0,
+    lastError: "",
+    updatedAt: undefined,
+    messages: [],
+  };
+}
+
+export const RecoverableConversation = {
+  encode(message: RecoverableConversation, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
+    if (message.id !== "") {
+      writer.uint32(10).string(message.id);
+    }
+    if (message.sessionId !== "") {
+      writer.uint32(18).string(message.sessionId);
+    }
+    if (message.userId !== "") {
+      writer.uint32(26).string(message.userId);
+    }
+    if (message.provider !== "") {
+      writer.uint32(34).string(message.provider);
+    }
+    if (message.modelId !== "") {
+      writer.uint32(42).string(message.modelId);
+    }
+    if (message.question !== "") {
+      writer.uint32(50).string(message.question);
+    }
+    if (message.turn !== 0) {
+      writer.uint32(56).int32(message.turn);
+    }
+    if (message.lastError !== "") {
+      writer.uint32(66).string(message.lastError);
+    }
+    if (message.updatedAt !== undefined) {
+      Timestamp.encode(toTimestamp(message.updatedAt), writer.uint32(74).fork()).ldelim();
+    }
+    for (const v of message.messages) {
+      Message.encode(v!, writer.uint32(82).fork()).ldelim();
+    }
+    return writer;
+  },
+
+  decode(input: _m0.Reader | Uint8Array, length?: number): RecoverableConversation {
+    const reader = input instanceof _m0.Reader ? input : _m0.Reader.create(input);
+    let end = length === undefined ? reader.len : reader.pos + length;
+    const message = createBaseRecoverableConversation();
+    while (reader.pos < end) {
+      const tag = reader.uint32();
+      switch (tag >>> 3) {
+        case 1:
+          if (tag !== 10) {
+            break;
+          }
+
+          message.id = reader.string();
+          continue;
+        case 2:
+          if (tag !== 18) {
+            break;
+          }
+
+          message.sessionId = reader.string();
+          continue;
+        case 3:
+          if (tag !== 26) {
+            break;
+          }
+
+          message.userId = reader.string();
+          continue;
+        case 4:
+          if (tag !== 34) {
+            break;
+          }
+
+          message.provider = reader.string();
+          continue;
+        case 5:
+          if (tag !== 42) {
+            break;
+          }
+
+          message.modelId = reader.string();
+          continue;
+        case 6:
+          if (tag !== 50) {
+            break;
+          }
+
+          message.question = reader.string();
+          continue;
+        case 7:
+          if (tag !== 56) {
+            break;
+          }
 
-function createBaseHealthCheckResponse(): HealthCheckResponse {
-  return { status: "" };
-}
+          message.turn = reader.int32();
+          continue;
+        case 8:
+          if (tag !== 66) {
+            break;
+          }
 
-export const HealthCheckResponse = {
-  encode(message: HealthCheckResponse, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
-    if (message.status !== "") {
-      writer.uint32(10).string(message.status);
-    }
-    return writer;
-  },
+          message.lastError = reader.string();
+          continue;
+        case 9:
+          if (tag !== 74) {
+            break;
+          }
 
-  decode(input: _m0.Reader | Uint8Array, length?: number): HealthCheckResponse {
-    const reader = input instanceof _m0.Reader ? input : _m0.Reader.create(input);
-    let end = length === undefined ? reader.len : reader.pos + length;
-    const message = createBaseHealthCheckResponse();
-    while (reader.pos < end) {
-      const tag = reader.uint32();
-      switch (tag >>> 3) {
-        case 1:
-          if (tag !== 10) {
+          message.updatedAt = fromTimestamp(Timestamp.decode(reader, reader.uint32()));
+          continue;
+        case 10:
+          if (tag !== 82) {
             break;
           }
 
-          message.status = reader.string();
+          message.messages.push(Message.decode(reader, reader.uint32()));
           continue;
       }
       if ((tag & 7) === 4 || tag === 0) {
@@ -3568,24 +7980,73 @@ export const HealthCheckResponse = {
     return message;
   },
 
-  fromJSON(object: any): HealthCheckResponse {
-    return { status: isSet(object.status) ? globalThis.String(object.status) : "" };
+  fromJSON(object: any): RecoverableConversation {
+    return {
+      id: isSet(object.id) ? globalThis.String(object.id) : "",
+      sessionId: isSet(object.sessionId) ? globalThis.String(object.sessionId) : "",
+      userId: isSet(object.userId) ? globalThis.String(object.userId) : "",
+      provider: isSet(object.provider) ? globalThis.String(object.provider) : "",
+      modelId: isSet(object.modelId) ? globalThis.String(object.modelId) : "",
+      question: isSet(object.question) ? globalThis.String(object.question) : "",
+      turn: isSet(object.turn) ? globalThis.Number(object.turn) : 0,
+      lastError: isSet(object.lastError) ? globalThis.String(object.lastError) : "",
+      updatedAt: isSet(object.updatedAt) ? fromJsonTimestamp(object.updatedAt) : undefined,
+      messages: globalThis.Array.isArray(object?.messages)
+        ? object.messages.map((e: any) => Message.fromJSON(e))
+        : [],
+    };
   },
 
-  toJSON(message: HealthCheckResponse): unknown {
+  toJSON(message: RecoverableConversation): unknown {
     const obj: any = {};
-    if (message.status !== "") {
-      obj.status = message.status;
+    if (message.id !== "") {
+      obj.id = message.id;
+    }
+    if (message.sessionId !== "") {
+      obj.sessionId = message.sessionId;
+    }
+    if (message.userId !== "") {
+      obj.userId = message.userId;
+    }
+    if (message.provider !== "") {
+      obj.provider = message.provider;
+    }
+    if (message.modelId !== "") {
+      obj.modelId = message.modelId;
+    }
+    if (message.question !== "") {
+      obj.question = message.question;
+    }
+    if (message.turn !== 0) {
+      obj.turn = Math.round(message.turn);
+    }
+    if (message.lastError !== "") {
+      obj.lastError = message.lastError;
+    }
+    if (message.updatedAt !== undefined) {
+      obj.updatedAt = message.updatedAt.toISOString();
+    }
+    if (message.messages?.length) {
+      obj.messages = message.messages.map((e) => Message.toJSON(e));
     }
     return obj;
   },
 
-  create<I extends Exact<DeepPartial<HealthCheckResponse>, I>>(base?: I): HealthCheckResponse {
-    return HealthCheckResponse.fromPartial(base ?? ({} as any));
+  create<I extends Exact<DeepPartial<RecoverableConversation>, I>>(base?: I): RecoverableConversation {
+    return RecoverableConversation.fromPartial(base ?? ({} as any));
   },
-  fromPartial<I extends Exact<DeepPartial<HealthCheckResponse>, I>>(object: I): HealthCheckResponse {
-    const message = createBaseHealthCheckResponse();
-    message.status = object.status ?? "";
+  fromPartial<I extends Exact<DeepPartial<RecoverableConversation>, I>>(object: I): RecoverableConversation {
+    const message = createBaseRecoverableConversation();
+    message.id = object.id ?? "";
+    message.sessionId = object.sessionId ?? "";
+    message.userId = object.userId ?? "";
+    message.provider = object.provider ?? "";
+    message.modelId = object.modelId ?? "";
+    message.question = object.question ?? "";
+    message.turn = object.turn ?? 0;
+    message.lastError = object.lastError ?? "";
+    message.updatedAt = object.updatedAt ?? undefined;
+    message.messages = object.messages?.map((e) => Message.fromPartial(e)) || [];
     return message;
   },
 };
@@ -3602,6 +8063,16 @@ export const AgentServiceService = {
     responseSerialize: (value: CreateAgentResponse) => Buffer.from(CreateAgentResponse.encode(value).finish()),
     responseDeserialize: (value: Buffer) => CreateAgentResponse.decode(value),
   },
+  /** Create an agent from a preset registered on the server (--presets) */
+  createAgentFromPreset: {
+    path: "/mcpagent.v1.AgentService/CreateAgentFromPreset",
+    requestStream: false,
+    responseStream: false,
+    requestSerialize: (value: CreateAgentFromPresetRequest) => Buffer.from(CreateAgentFromPresetRequest.encode(value).finish()),
+    requestDeserialize: (value: Buffer) => CreateAgentFromPresetRequest.decode(value),
+    responseSerialize: (value: CreateAgentResponse) => Buffer.from(CreateAgentResponse.encode(value).finish()),
+    responseDeserialize: (value: Buffer) => CreateAgentResponse.decode(value),
+  },
   getAgent: {
     path: "/mcpagent.v1.AgentService/GetAgent",
     requestStream: false,
@@ -3639,6 +8110,73 @@ export const AgentServiceService = {
     responseSerialize: (value: TokenUsageResponse) => Buffer.from(TokenUsageResponse.encode(value).finish()),
     responseDeserialize: (value: Buffer) => TokenUsageResponse.decode(value),
   },
+  /**
+   * Diagnostics
+   * Returns the post-mortem bundle of the agent's last failed conversation
+   * (requires post-mortem bundles on the server)
+   */
+  getPostMortemBundle: {
+    path: "/mcpagent.v1.AgentService/GetPostMortemBundle",
+    requestStream: false,
+    responseStream: false,
+    requestSerialize: (value: GetPostMortemBundleRequest) => Buffer.from(GetPostMortemBundleRequest.encode(value).finish()),
+    requestDeserialize: (value: Buffer) => GetPostMortemBundleRequest.decode(value),
+    responseSerialize: (value: GetPostMortemBundleResponse) =>
+      Buffer.from(GetPostMortemBundleResponse.encode(value).finish()),
+    responseDeserialize: (value: Buffer) => GetPostMortemBundleResponse.decode(value),
+  },
+  /**
+   * Per-tool and per-server call counts, error rates, latencies and bytes
+   * returned since the agent was created
+   */
+  getToolStats: {
+    path: "/mcpagent.v1.AgentService/GetToolStats",
+    requestStream: false,
+    responseStream: false,
+    requestSerialize: (value: GetToolStatsRequest) => Buffer.from(GetToolStatsRequest.encode(value).finish()),
+    requestDeserialize: (value: Buffer) => GetToolStatsRequest.decode(value),
+    responseSerialize: (value: GetToolStatsResponse) => Buffer.from(GetToolStatsResponse.encode(value).finish()),
+    responseDeserialize: (value: Buffer) => GetToolStatsResponse.decode(value),
+  },
+  /**
+   * Introspection (read-only views of the live agent, e.g. for tool pickers)
+   */
+  listTools: {
+    path: "/mcpagent.v1.AgentService/ListTools",
+    requestStream: false,
+    responseStream: false,
+    requestSerialize: (value: ListToolsRequest) => Buffer.from(ListToolsRequest.encode(value).finish()),
+    requestDeserialize: (value: Buffer) => ListToolsRequest.decode(value),
+    responseSerialize: (value: ListToolsResponse) => Buffer.from(ListToolsResponse.encode(value).finish()),
+    responseDeserialize: (value: Buffer) => ListToolsResponse.decode(value),
+  },
+  getToolSchema: {
+    path: "/mcpagent.v1.AgentService/GetToolSchema",
+    requestStream: false,
+    responseStream: false,
+    requestSerialize: (value: GetToolSchemaRequest) => Buffer.from(GetToolSchemaRequest.encode(value).finish()),
+    requestDeserialize: (value: Buffer) => GetToolSchemaRequest.decode(value),
+    responseSerialize: (value: GetToolSchemaResponse) => Buffer.from(GetToolSchemaResponse.encode(value).finish()),
+    responseDeserialize: (value: Buffer) => GetToolSchemaResponse.decode(value),
+  },
+  listServers: {
+    path: "/mcpagent.v1.AgentService/ListServers",
+    requestStream: false,
+    responseStream: false,
+    requestSerialize: (value: ListServersRequest) => Buffer.from(ListServersRequest.encode(value).finish()),
+    requestDeserialize: (value: Buffer) => ListServersRequest.decode(value),
+    responseSerialize: (value: ListServersResponse) => Buffer.from(ListServersResponse.encode(value).finish()),
+    responseDeserialize: (value: Buffer) => ListServersResponse.decode(value),
+  },
+  listPrompts: {
+    path: "/mcpagent.v1.AgentService/ListPrompts",
+    requestStream: false,
+    responseStream: false,
+    requestSerialize: (value: ListPromptsRequest) => Buffer.from(ListPromptsRequest.encode(value).finish()),
+    requestDeserialize: (value: Buffer) => ListPromptsRequest.decode(value),
+    responseSerialize: (value: ListPromptsResponse) => Buffer.from(ListPromptsResponse.encode(value).finish()),
+    responseDeserialize: (value: Buffer) => ListPromptsResponse.decode(value),
+  },
   /**
    * Bidirectional Streaming Conversation
    * Client sends: questions, tool results, cancel
@@ -3653,6 +8191,34 @@ export const AgentServiceService = {
     responseSerialize: (value: ConversationResponse) => Buffer.from(ConversationResponse.encode(value).finish()),
     responseDeserialize: (value: Buffer) => ConversationResponse.decode(value),
   },
+  /**
+   * Read-only event stream of an agent's conversations, for UIs watching a
+   * session another client drives. Any number of watchers can subscribe.
+   * Server sends: text chunks and events (tool calls arrive as events)
+   */
+  watchConversation: {
+    path: "/mcpagent.v1.AgentService/WatchConversation",
+    requestStream: false,
+    responseStream: true,
+    requestSerialize: (value: WatchConversationRequest) => Buffer.from(WatchConversationRequest.encode(value).finish()),
+    requestDeserialize: (value: Buffer) => WatchConversationRequest.decode(value),
+    responseSerialize: (value: ConversationResponse) => Buffer.from(ConversationResponse.encode(value).finish()),
+    responseDeserialize: (value: Buffer) => ConversationResponse.decode(value),
+  },
+  /**
+   * Server-streaming Ask for clients that only render progress: one question
+   * in, typed progress messages out over a single stream, no bidi handling.
+   * Server sends: text chunks, tool call start/end, final response (or error)
+   */
+  askStream: {
+    path: "/mcpagent.v1.AgentService/AskStream",
+    requestStream: false,
+    responseStream: true,
+    requestSerialize: (value: AskStreamRequest) => Buffer.from(AskStreamRequest.encode(value).finish()),
+    requestDeserialize: (value: Buffer) => AskStreamRequest.decode(value),
+    responseSerialize: (value: AskStreamResponse) => Buffer.from(AskStreamResponse.encode(value).finish()),
+    responseDeserialize: (value: Buffer) => AskStreamResponse.decode(value),
+  },
   /** Unary RPCs (backward compatibility, non-streaming) */
   ask: {
     path: "/mcpagent.v1.AgentService/Ask",
@@ -3672,6 +8238,20 @@ export const AgentServiceService = {
     responseSerialize: (value: AskWithHistoryResponse) => Buffer.from(AskWithHistoryResponse.encode(value).finish()),
     responseDeserialize: (value: Buffer) => AskWithHistoryResponse.decode(value),
   },
+  /**
+   * Cancels a running Ask, AskWithHistory or AskStream by the request_id it
+   * was started with; the call fails with CANCELLED and the agent emits a
+   * conversation_end event with status "cancelled"
+   */
+  cancelRequest: {
+    path: "/mcpagent.v1.AgentService/CancelRequest",
+    requestStream: false,
+    responseStream: false,
+    requestSerialize: (value: CancelRequestRequest) => Buffer.from(CancelRequestRequest.encode(value).finish()),
+    requestDeserialize: (value: Buffer) => CancelRequestRequest.decode(value),
+    responseSerialize: (value: CancelRequestResponse) => Buffer.from(CancelRequestResponse.encode(value).finish()),
+    responseDeserialize: (value: Buffer) => CancelRequestResponse.decode(value),
+  },
   /** Health Check */
   healthCheck: {
     path: "/mcpagent.v1.AgentService/HealthCheck",
@@ -3682,27 +8262,103 @@ export const AgentServiceService = {
     responseSerialize: (value: HealthCheckResponse) => Buffer.from(HealthCheckResponse.encode(value).finish()),
     responseDeserialize: (value: Buffer) => HealthCheckResponse.decode(value),
   },
+  /**
+   * Readiness, per-MCP-server connection health of the managed agents, MCP
+   * cache status and activity counts. Liveness and readiness probes should
+   * use the standard grpc.health.v1.Health service instead.
+   */
+  healthCheckDetailed: {
+    path: "/mcpagent.v1.AgentService/HealthCheckDetailed",
+    requestStream: false,
+    responseStream: false,
+    requestSerialize: (value: HealthCheckDetailedRequest) => Buffer.from(HealthCheckDetailedRequest.encode(value).finish()),
+    requestDeserialize: (value: Buffer) => HealthCheckDetailedRequest.decode(value),
+    responseSerialize: (value: HealthCheckDetailedResponse) => Buffer.from(HealthCheckDetailedResponse.encode(value).finish()),
+    responseDeserialize: (value: Buffer) => HealthCheckDetailedResponse.decode(value),
+  },
+  /**
+   * Crash Recovery
+   * Lists autosaved conversations that did not finish (requires autosave on the server)
+   */
+  listRecoverableConversations: {
+    path: "/mcpagent.v1.AgentService/ListRecoverableConversations",
+    requestStream: false,
+    responseStream: false,
+    requestSerialize: (value: ListRecoverableConversationsRequest) => Buffer.from(ListRecoverableConversationsRequest.encode(value).finish()),
+    requestDeserialize: (value: Buffer) => ListRecoverableConversationsRequest.decode(value),
+    responseSerialize: (value: ListRecoverableConversationsResponse) => Buffer.from(ListRecoverableConversationsResponse.encode(value).finish()),
+    responseDeserialize: (value: Buffer) => ListRecoverableConversationsResponse.decode(value),
+  },
 } as const;
 
 export interface AgentServiceServer extends UntypedServiceImplementation {
   /** Agent Lifecycle */
   createAgent: handleUnaryCall<CreateAgentRequest, CreateAgentResponse>;
+  /** Create an agent from a preset registered on the server (--presets) */
+  createAgentFromPreset: handleUnaryCall<CreateAgentFromPresetRequest, CreateAgentResponse>;
   getAgent: handleUnaryCall<GetAgentRequest, GetAgentResponse>;
   listAgents: handleUnaryCall<ListAgentsRequest, ListAgentsResponse>;
   destroyAgent: handleUnaryCall<DestroyAgentRequest, DestroyAgentResponse>;
   /** Token Usage */
   getTokenUsage: handleUnaryCall<GetTokenUsageRequest, TokenUsageResponse>;
+  /**
+   * Diagnostics
+   * Returns the post-mortem bundle of the agent's last failed conversation
+   * (requires post-mortem bundles on the server)
+   */
+  getPostMortemBundle: handleUnaryCall<GetPostMortemBundleRequest, GetPostMortemBundleResponse>;
+  /**
+   * Per-tool and per-server call counts, error rates, latencies and bytes
+   * returned since the agent was created
+   */
+  getToolStats: handleUnaryCall<GetToolStatsRequest, GetToolStatsResponse>;
+  /**
+   * Introspection (read-only views of the live agent, e.g. for tool pickers)
+   */
+  listTools: handleUnaryCall<ListToolsRequest, ListToolsResponse>;
+  getToolSchema: handleUnaryCall<GetToolSchemaRequest, GetToolSchemaResponse>;
+  listServers: handleUnaryCall<ListServersRequest, ListServersResponse>;
+  listPrompts: handleUnaryCall<ListPromptsRequest, ListPromptsResponse>;
   /**
    * Bidirectional Streaming Conversation
    * Client sends: questions, tool results, cancel
    * Server sends: text chunks, tool calls, events, final response
    */
   converse: handleBidiStreamingCall<ConversationRequest, ConversationResponse>;
+  /**
+   * Read-only event stream of an agent's conversations, for UIs watching a
+   * session another client drives. Any number of watchers can subscribe.
+   * Server sends: text chunks and events (tool calls arrive as events)
+   */
+  watchConversation: handleServerStreamingCall<WatchConversationRequest, ConversationResponse>;
+  /**
+   * Server-streaming Ask for clients that only render progress: one question
+   * in, typed progress messages out over a single stream, no bidi handling.
+   * Server sends: text chunks, tool call start/end, final response (or error)
+   */
+  askStream: handleServerStreamingCall<AskStreamRequest, AskStreamResponse>;
   /** Unary RPCs (backward compatibility, non-streaming) */
   ask: handleUnaryCall<AskRequest, AskResponse>;
   askWithHistory: handleUnaryCall<AskWithHistoryRequest, AskWithHistoryResponse>;
+  /**
+   * Cancels a running Ask, AskWithHistory or AskStream by the request_id it
+   * was started with; the call fails with CANCELLED and the agent emits a
+   * conversation_end event with status "cancelled"
+   */
+  cancelRequest: handleUnaryCall<CancelRequestRequest, CancelRequestResponse>;
   /** Health Check */
   healthCheck: handleUnaryCall<HealthCheckRequest, HealthCheckResponse>;
+  /**
+   * Readiness, per-MCP-server connection health of the managed agents, MCP
+   * cache status and activity counts. Liveness and readiness probes should
+   * use the standard grpc.health.v1.Health service instead.
+   */
+  healthCheckDetailed: handleUnaryCall<HealthCheckDetailedRequest, HealthCheckDetailedResponse>;
+  /**
+   * Crash Recovery
+   * Lists autosaved conversations that did not finish (requires autosave on the server)
+   */
+  listRecoverableConversations: handleUnaryCall<ListRecoverableConversationsRequest, ListRecoverableConversationsResponse>;
 }
 
 export interface AgentServiceClient extends Client {
@@ -3722,6 +8378,22 @@ export interface AgentServiceClient extends Client {
     options: Partial<CallOptions>,
     callback: (error: ServiceError | null, response: CreateAgentResponse) => void,
   ): ClientUnaryCall;
+  /** Create an agent from a preset registered on the server (--presets) */
+  createAgentFromPreset(
+    request: CreateAgentFromPresetRequest,
+    callback: (error: ServiceError | null, response: CreateAgentResponse) => void,
+  ): ClientUnaryCall;
+  createAgentFromPreset(
+    request: CreateAgentFromPresetRequest,
+    metadata: Metadata,
+    callback: (error: ServiceError | null, response: CreateAgentResponse) => void,
+  ): ClientUnaryCall;
+  createAgentFromPreset(
+    request: CreateAgentFromPresetRequest,
+    metadata: Metadata,
+    options: Partial<CallOptions>,
+    callback: (error: ServiceError | null, response: CreateAgentResponse) => void,
+  ): ClientUnaryCall;
   getAgent(
     request: GetAgentRequest,
     callback: (error: ServiceError | null, response: GetAgentResponse) => void,
@@ -3783,6 +8455,108 @@ export interface AgentServiceClient extends Client {
     options: Partial<CallOptions>,
     callback: (error: ServiceError | null, response: TokenUsageResponse) => void,
   ): ClientUnaryCall;
+  /**
+   * Diagnostics
+   * Returns the post-mortem bundle of the agent's last failed conversation
+   * (requires post-mortem bundles on the server)
+   */
+  getPostMortemBundle(
+    request: GetPostMortemBundleRequest,
+    callback: (error: ServiceError | null, response: GetPostMortemBundleResponse) => void,
+  ): ClientUnaryCall;
+  getPostMortemBundle(
+    request: GetPostMortemBundleRequest,
+    metadata: Metadata,
+    callback: (error: ServiceError | null, response: GetPostMortemBundleResponse) => void,
+  ): ClientUnaryCall;
+  getPostMortemBundle(
+    request: GetPostMortemBundleRequest,
+    metadata: Metadata,
+    options: Partial<CallOptions>,
+    callback: (error: ServiceError | null, response: GetPostMortemBundleResponse) => void,
+  ): ClientUnaryCall;
+  /**
+   * Per-tool and per-server call counts, error rates, latencies and bytes
+   * returned since the agent was created
+   */
+  getToolStats(
+    request: GetToolStatsRequest,
+    callback: (error: ServiceError | null, response: GetToolStatsResponse) => void,
+  ): ClientUnaryCall;
+  getToolStats(
+    request: GetToolStatsRequest,
+    metadata: Metadata,
+    callback: (error: ServiceError | null, response: GetToolStatsResponse) => void,
+  ): ClientUnaryCall;
+  getToolStats(
+    request: GetToolStatsRequest,
+    metadata: Metadata,
+    options: Partial<CallOptions>,
+    callback: (error: ServiceError | null, response: GetToolStatsResponse) => void,
+  ): ClientUnaryCall;
+  /**
+   * Introspection (read-only views of the live agent, e.g. for tool pickers)
+   */
+  listTools(
+    request: ListToolsRequest,
+    callback: (error: ServiceError | null, response: ListToolsResponse) => void,
+  ): ClientUnaryCall;
+  listTools(
+    request: ListToolsRequest,
+    metadata: Metadata,
+    callback: (error: ServiceError | null, response: ListToolsResponse) => void,
+  ): ClientUnaryCall;
+  listTools(
+    request: ListToolsRequest,
+    metadata: Metadata,
+    options: Partial<CallOptions>,
+    callback: (error: ServiceError | null, response: ListToolsResponse) => void,
+  ): ClientUnaryCall;
+  getToolSchema(
+    request: GetToolSchemaRequest,
+    callback: (error: ServiceError | null, response: GetToolSchemaResponse) => void,
+  ): ClientUnaryCall;
+  getToolSchema(
+    request: GetToolSchemaRequest,
+    metadata: Metadata,
+    callback: (error: ServiceError | null, response: GetToolSchemaResponse) => void,
+  ): ClientUnaryCall;
+  getToolSchema(
+    request: GetToolSchemaRequest,
+    metadata: Metadata,
+    options: Partial<CallOptions>,
+    callback: (error: ServiceError | null, response: GetToolSchemaResponse) => void,
+  ): ClientUnaryCall;
+  listServers(
+    request: ListServersRequest,
+    callback: (error: ServiceError | null, response: ListServersResponse) => void,
+  ): ClientUnaryCall;
+  listServers(
+    request: ListServersRequest,
+    metadata: Metadata,
+    callback: (error: ServiceError | null, response: ListServersResponse) => void,
+  ): ClientUnaryCall;
+  listServers(
+    request: ListServersRequest,
+    metadata: Metadata,
+    options: Partial<CallOptions>,
+    callback: (error: ServiceError | null, response: ListServersResponse) => void,
+  ): ClientUnaryCall;
+  listPrompts(
+    request: ListPromptsRequest,
+    callback: (error: ServiceError | null, response: ListPromptsResponse) => void,
+  ): ClientUnaryCall;
+  listPrompts(
+    request: ListPromptsRequest,
+    metadata: Metadata,
+    callback: (error: ServiceError | null, response: ListPromptsResponse) => void,
+  ): ClientUnaryCall;
+  listPrompts(
+    request: ListPromptsRequest,
+    metadata: Metadata,
+    options: Partial<CallOptions>,
+    callback: (error: ServiceError | null, response: ListPromptsResponse) => void,
+  ): ClientUnaryCall;
   /**
    * Bidirectional Streaming Conversation
    * Client sends: questions, tool results, cancel
@@ -3794,6 +8568,31 @@ export interface AgentServiceClient extends Client {
     metadata: Metadata,
     options?: Partial<CallOptions>,
   ): ClientDuplexStream<ConversationRequest, ConversationResponse>;
+  /**
+   * Read-only event stream of an agent's conversations, for UIs watching a
+   * session another client drives. Any number of watchers can subscribe.
+   * Server sends: text chunks and events (tool calls arrive as events)
+   */
+  watchConversation(
+    request: WatchConversationRequest,
+    options?: Partial<CallOptions>,
+  ): ClientReadableStream<ConversationResponse>;
+  watchConversation(
+    request: WatchConversationRequest,
+    metadata?: Metadata,
+    options?: Partial<CallOptions>,
+  ): ClientReadableStream<ConversationResponse>;
+  /**
+   * Server-streaming Ask for clients that only render progress: one question
+   * in, typed progress messages out over a single stream, no bidi handling.
+   * Server sends: text chunks, tool call start/end, final response (or error)
+   */
+  askStream(request: AskStreamRequest, options?: Partial<CallOptions>): ClientReadableStream<AskStreamResponse>;
+  askStream(
+    request: AskStreamRequest,
+    metadata?: Metadata,
+    options?: Partial<CallOptions>,
+  ): ClientReadableStream<AskStreamResponse>;
   /** Unary RPCs (backward compatibility, non-streaming) */
   ask(request: AskRequest, callback: (error: ServiceError | null, response: AskResponse) => void): ClientUnaryCall;
   ask(
@@ -3822,6 +8621,26 @@ export interface AgentServiceClient extends Client {
     options: Partial<CallOptions>,
     callback: (error: ServiceError | null, response: AskWithHistoryResponse) => void,
   ): ClientUnaryCall;
+  /**
+   * Cancels a running Ask, AskWithHistory or AskStream by the request_id it
+   * was started with; the call fails with CANCELLED and the agent emits a
+   * conversation_end event with status "cancelled"
+   */
+  cancelRequest(
+    request: CancelRequestRequest,
+    callback: (error: ServiceError | null, response: CancelRequestResponse) => void,
+  ): ClientUnaryCall;
+  cancelRequest(
+    request: CancelRequestRequest,
+    metadata: Metadata,
+    callback: (error: ServiceError | null, response: CancelRequestResponse) => void,
+  ): ClientUnaryCall;
+  cancelRequest(
+    request: CancelRequestRequest,
+    metadata: Metadata,
+    options: Partial<CallOptions>,
+    callback: (error: ServiceError | null, response: CancelRequestResponse) => void,
+  ): ClientUnaryCall;
   /** Health Check */
   healthCheck(
     request: HealthCheckRequest,
@@ -3838,6 +8657,45 @@ export interface AgentServiceClient extends Client {
     options: Partial<CallOptions>,
     callback: (error: ServiceError | null, response: HealthCheckResponse) => void,
   ): ClientUnaryCall;
+  /**
+   * Readiness, per-MCP-server connection health of the managed agents, MCP
+   * cache status and activity counts. Liveness and readiness probes should
+   * use the standard grpc.health.v1.Health service instead.
+   */
+  healthCheckDetailed(
+    request: HealthCheckDetailedRequest,
+    callback: (error: ServiceError | null, response: HealthCheckDetailedResponse) => void,
+  ): ClientUnaryCall;
+  healthCheckDetailed(
+    request: HealthCheckDetailedRequest,
+    metadata: Metadata,
+    callback: (error: ServiceError | null, response: HealthCheckDetailedResponse) => void,
+  ): ClientUnaryCall;
+  healthCheckDetailed(
+    request: HealthCheckDetailedRequest,
+    metadata: Metadata,
+    options: Partial<CallOptions>,
+    callback: (error: ServiceError | null, response: HealthCheckDetailedResponse) => void,
+  ): ClientUnaryCall;
+  /**
+   * Crash Recovery
+   * Lists autosaved conversations that did not finish (requires autosave on the server)
+   */
+  listRecoverableConversations(
+    request: ListRecoverableConversationsRequest,
+    callback: (error: ServiceError | null, response: ListRecoverableConversationsResponse) => void,
+  ): ClientUnaryCall;
+  listRecoverableConversations(
+    request: ListRecoverableConversationsRequest,
+    metadata: Metadata,
+    callback: (error: ServiceError | null, response: ListRecoverableConversationsResponse) => void,
+  ): ClientUnaryCall;
+  listRecoverableConversations(
+    request: ListRecoverableConversationsRequest,
+    metadata: Metadata,
+    options: Partial<CallOptions>,
+    callback: (error: ServiceError | null, response: ListRecoverableConversationsResponse) => void,
+  ): ClientUnaryCall;
 }
 
 export const AgentServiceClient = makeGenericClientConstructor(
@@ -3849,6 +8707,31 @@ export const AgentServiceClient = makeGenericClientConstructor(
   serviceName: string;
 };
 
+function bytesFromBase64(b64: string): Uint8Array {
+  if ((globalThis as any).Buffer) {
+    return Uint8Array.from(globalThis.Buffer.from(b64, "base64"));
+  } else {
+    const bin = globalThis.atob(b64);
+    const arr = new Uint8Array(bin.length);
+    for (let i = 0; i < bin.length; ++i) {
+      arr[i] = bin.charCodeAt(i);
+    }
+    return arr;
+  }
+}
+
+function base64FromBytes(arr: Uint8Array): string {
+  if ((globalThis as any).Buffer) {
+    return globalThis.Buffer.from(arr).toString("base64");
+  } else {
+    const bin: string[] = [];
+    arr.forEach((byte) => {
+      bin.push(globalThis.String.fromCharCode(byte));
+    });
+    return globalThis.btoa(bin.join(""));
+  }
+}
+
 type Builtin = Date | Function | Uint8Array | string | number | boolean | undefined;
 
 type DeepPartial<T> = T extends Builtin ? T
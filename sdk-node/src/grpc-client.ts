@@ -1,7 +1,8 @@
-import { credentials, ChannelCredentials, ClientDuplexStream } from '@grpc/grpc-js';
+import { credentials, ChannelCredentials, ClientDuplexStream, ClientReadableStream, InterceptingCall, Interceptor } from '@grpc/grpc-js';
 import {
   AgentServiceClient,
   CreateAgentRequest,
+  CreateAgentFromPresetRequest,
   CreateAgentResponse,
   GetAgentRequest,
   GetAgentResponse,
@@ -19,9 +20,16 @@ import {
   HealthCheckResponse,
   ConversationRequest,
   ConversationResponse,
+  WatchConversationRequest,
+  AskStreamRequest,
+  AskStreamResponse,
   AgentConfig as ProtoAgentConfig,
   CustomToolDefinition as ProtoCustomToolDefinition,
   Message as ProtoMessage,
+  ToolInfo as ProtoToolInfo,
+  AgentFilters as ProtoAgentFilters,
+  TokenUsage as ProtoTokenUsage,
+  ToolCallStats as ProtoToolCallStats,
 } from './generated/agent';
 import type {
   AgentConfig,
@@ -30,6 +38,17 @@ import type {
   AskWithHistoryResponse as SdkAskWithHistoryResponse,
   TokenUsageWithPricing,
   AgentSummary,
+  AgentFilters,
+  TokenUsage,
+  RecoverableConversation,
+  PostMortemBundle,
+  ToolStatsReport,
+  ToolCallStats,
+  ServerHealthReport,
+  ToolInfo,
+  ToolSchema,
+  ServerInfo,
+  PromptInfo,
   Message,
   CustomToolDefinition,
 } from './types';
@@ -45,11 +64,24 @@ export class GrpcClient {
   /**
    * Create a new gRPC client
    * @param socketPath - Unix socket path (e.g., '/tmp/mcpagent-grpc-1234.sock')
+   * @param authToken - Client token or JWT sent as "authorization: Bearer <token>"
+   *   to servers started with --auth-config
    */
-  constructor(socketPath: string) {
+  constructor(socketPath: string, authToken?: string) {
     // gRPC uses 'unix://' prefix for Unix sockets
     const target = `unix://${socketPath}`;
-    this.client = new AgentServiceClient(target, credentials.createInsecure());
+    const interceptors: Interceptor[] = [];
+    if (authToken) {
+      interceptors.push((options, nextCall) =>
+        new InterceptingCall(nextCall(options), {
+          start(metadata, listener, next) {
+            metadata.set('authorization', `Bearer ${authToken}`);
+            next(metadata, listener);
+          },
+        })
+      );
+    }
+    this.client = new AgentServiceClient(target, credentials.createInsecure(), { interceptors });
   }
 
   /**
@@ -83,6 +115,49 @@ export class GrpcClient {
     });
   }
 
+  /**
+   * Detailed health: readiness, per-MCP-server connection health of the
+   * agents, MCP cache status and active agent and conversation counts
+   */
+  async healthCheckDetailed(
+    options: { agentId?: string; skipConnections?: boolean; timeoutMs?: number } = {}
+  ): Promise<ServerHealthReport> {
+    const request = {
+      agentId: options.agentId || '',
+      skipConnections: options.skipConnections || false,
+      timeoutMs: options.timeoutMs || 0,
+    };
+    return new Promise((resolve, reject) => {
+      this.client.healthCheckDetailed(request, (err, response) => {
+        if (err) {
+          reject(this.wrapError(err));
+          return;
+        }
+        this._connected = true;
+        resolve({
+          status: response!.status as ServerHealthReport['status'],
+          ready: response!.ready,
+          checks: response!.checks,
+          draining: response!.draining,
+          activeAgents: response!.activeAgents,
+          activeConversations: response!.activeConversations,
+          agents: response!.agents.map((a) => ({
+            agentId: a.agentId,
+            sessionId: a.sessionId,
+            servers: a.servers.map((s) => ({ name: s.name, healthy: s.healthy, error: s.error })),
+          })),
+          cache: {
+            totalEntries: response!.cache?.totalEntries || 0,
+            validEntries: response!.cache?.validEntries || 0,
+            expiredEntries: response!.cache?.expiredEntries || 0,
+            ttlMinutes: response!.cache?.ttlMinutes || 0,
+            directory: response!.cache?.directory || '',
+          },
+        });
+      });
+    });
+  }
+
   /**
    * Create a new agent
    */
@@ -94,6 +169,7 @@ export class GrpcClient {
     const request: CreateAgentRequest = {
       sessionId,
       config: this.convertAgentConfig(config, customTools),
+      warmPool: config.warmPool ?? '',
     };
 
     return new Promise((resolve, reject) => {
@@ -108,6 +184,34 @@ export class GrpcClient {
     });
   }
 
+  /**
+   * Create a new agent from a preset registered on the server
+   */
+  async createAgentFromPreset(
+    sessionId: string,
+    preset: string,
+    config: AgentConfig,
+    customTools?: CustomToolDefinition[]
+  ): Promise<SdkCreateAgentResponse> {
+    const request: CreateAgentFromPresetRequest = {
+      sessionId,
+      preset,
+      variables: config.presetVariables ?? {},
+      config: this.convertAgentConfig(config, customTools),
+    };
+
+    return new Promise((resolve, reject) => {
+      this.client.createAgentFromPreset(request, (err, response) => {
+        if (err) {
+          reject(this.wrapError(err));
+          return;
+        }
+        this._connected = true;
+        resolve(this.convertCreateAgentResponse(response!));
+      });
+    });
+  }
+
   /**
    * Get agent information
    */
@@ -117,14 +221,10 @@ export class GrpcClient {
     status: string;
     createdAt: string;
     capabilities: { tools: string[]; servers: string[] };
-    tokenUsage?: {
-      promptTokens: number;
-      completionTokens: number;
-      totalTokens: number;
-      cacheTokens: number;
-      reasoningTokens: number;
-      llmCallCount: number;
-    };
+    tokenUsage?: TokenUsage;
+    tools: ToolInfo[];
+    filters: AgentFilters;
+    codeExecutionMode: boolean;
   }> {
     return new Promise((resolve, reject) => {
       this.client.getAgent({ agentId }, (err, response) => {
@@ -141,14 +241,10 @@ export class GrpcClient {
             tools: response!.capabilities?.tools || [],
             servers: response!.capabilities?.servers || [],
           },
-          tokenUsage: response!.tokenUsage ? {
-            promptTokens: response!.tokenUsage.promptTokens,
-            completionTokens: response!.tokenUsage.completionTokens,
-            totalTokens: response!.tokenUsage.totalTokens,
-            cacheTokens: response!.tokenUsage.cacheTokens,
-            reasoningTokens: response!.tokenUsage.reasoningTokens,
-            llmCallCount: response!.tokenUsage.llmCallCount,
-          } : undefined,
+          tokenUsage: this.convertTokenUsage(response!.tokenUsage),
+          tools: response!.tools.map((t) => this.convertToolInfo(t)),
+          filters: this.convertAgentFilters(response!.filters),
+          codeExecutionMode: response!.codeExecutionMode,
         });
       });
     });
@@ -170,6 +266,41 @@ export class GrpcClient {
             sessionId: agent.sessionId,
             status: agent.status,
             createdAt: agent.createdAt?.toISOString() || new Date().toISOString(),
+            tokenUsage: this.convertTokenUsage(agent.tokenUsage),
+            tools: agent.tools.map((t) => this.convertToolInfo(t)),
+            filters: this.convertAgentFilters(agent.filters),
+            codeExecutionMode: agent.codeExecutionMode,
+          }))
+        );
+      });
+    });
+  }
+
+  /**
+   * List autosaved conversations that did not finish
+   */
+  async listRecoverableConversations(): Promise<RecoverableConversation[]> {
+    return new Promise((resolve, reject) => {
+      this.client.listRecoverableConversations({}, (err, response) => {
+        if (err) {
+          reject(this.wrapError(err));
+          return;
+        }
+        resolve(
+          response!.conversations.map((conv) => ({
+            id: conv.id,
+            sessionId: conv.sessionId,
+            userId: conv.userId,
+            provider: conv.provider,
+            modelId: conv.modelId,
+            question: conv.question,
+            turn: conv.turn,
+            lastError: conv.lastError,
+            updatedAt: conv.updatedAt?.toISOString() || new Date().toISOString(),
+            messages: conv.messages.map((m: ProtoMessage) => ({
+              role: m.role as 'user' | 'assistant' | 'system',
+              content: m.content,
+            })),
           }))
         );
       });
@@ -223,12 +354,132 @@ export class GrpcClient {
     });
   }
 
+  /**
+   * Get the diagnostic bundle of the agent's last failed conversation
+   */
+  async getPostMortemBundle(agentId: string): Promise<PostMortemBundle> {
+    return new Promise((resolve, reject) => {
+      this.client.getPostMortemBundle({ agentId }, (err, response) => {
+        if (err) {
+          reject(this.wrapError(err));
+          return;
+        }
+        resolve({
+          bundle: response!.bundle,
+          path: response!.path,
+          error: response!.error,
+          createdAt: response!.createdAt?.toISOString() || '',
+        });
+      });
+    });
+  }
+
+  /**
+   * Get per-tool and per-server call counts, error rates and latencies of an agent
+   */
+  async getToolStats(agentId: string): Promise<ToolStatsReport> {
+    return new Promise((resolve, reject) => {
+      this.client.getToolStats({ agentId }, (err, response) => {
+        if (err) {
+          reject(this.wrapError(err));
+          return;
+        }
+        resolve({
+          tools: response!.tools.map((t) => ({
+            toolName: t.toolName,
+            serverName: t.serverName,
+            ...this.convertToolCallStats(t.stats),
+          })),
+          servers: response!.servers.map((s) => ({
+            serverName: s.serverName,
+            ...this.convertToolCallStats(s.stats),
+          })),
+        });
+      });
+    });
+  }
+
+  /**
+   * List the tools available to an agent, optionally only those of one server
+   */
+  async listTools(agentId: string, server: string = ''): Promise<ToolInfo[]> {
+    return new Promise((resolve, reject) => {
+      this.client.listTools({ agentId, server }, (err, response) => {
+        if (err) {
+          reject(this.wrapError(err));
+          return;
+        }
+        resolve(response!.tools.map((t) => this.convertToolInfo(t)));
+      });
+    });
+  }
+
+  /**
+   * Get a tool with the JSON schema of its arguments
+   */
+  async getToolSchema(agentId: string, toolName: string): Promise<ToolSchema> {
+    return new Promise((resolve, reject) => {
+      this.client.getToolSchema({ agentId, toolName }, (err, response) => {
+        if (err) {
+          reject(this.wrapError(err));
+          return;
+        }
+        resolve({
+          ...this.convertToolInfo(response!.tool),
+          inputSchema: response!.inputSchema,
+        });
+      });
+    });
+  }
+
+  /**
+   * List the MCP servers an agent uses
+   */
+  async listServers(agentId: string): Promise<ServerInfo[]> {
+    return new Promise((resolve, reject) => {
+      this.client.listServers({ agentId }, (err, response) => {
+        if (err) {
+          reject(this.wrapError(err));
+          return;
+        }
+        resolve(response!.servers.map((s) => ({ ...s })));
+      });
+    });
+  }
+
+  /**
+   * List the prompts offered by an agent's MCP servers, optionally only one server's
+   */
+  async listPrompts(agentId: string, server: string = ''): Promise<PromptInfo[]> {
+    return new Promise((resolve, reject) => {
+      this.client.listPrompts({ agentId, server }, (err, response) => {
+        if (err) {
+          reject(this.wrapError(err));
+          return;
+        }
+        resolve(
+          response!.prompts.map((p) => ({
+            name: p.name,
+            description: p.description,
+            server: p.server,
+            arguments: p.arguments.map((a) => ({ ...a })),
+          }))
+        );
+      });
+    });
+  }
+
   /**
    * Ask a question (unary RPC - no streaming)
    */
-  async ask(agentId: string, question: string): Promise<SdkAskResponse> {
+  async ask(
+    agentId: string,
+    question: string,
+    priority: string = '',
+    requestId: string = ''
+  ): Promise<SdkAskResponse> {
     return new Promise((resolve, reject) => {
-      this.client.ask({ agentId, question }, (err, response) => {
+      this.client.ask({ agentId, question, priority, requestId }, (err, response) => {
         if (err) {
           reject(this.wrapError(err));
           return;
@@ -254,7 +505,9 @@ export class GrpcClient {
    */
   async askWithHistory(
     agentId: string,
-    messages: Message[]
+    messages: Message[],
+    priority: string = '',
+    requestId: string = ''
   ): Promise<SdkAskWithHistoryResponse> {
     const protoMessages: ProtoMessage[] = messages.map((msg) => ({
       role: msg.role,
@@ -262,7 +515,7 @@ export class GrpcClient {
     }));
 
     return new Promise((resolve, reject) => {
-      this.client.askWithHistory({ agentId, messages: protoMessages }, (err, response) => {
+      this.client.askWithHistory({ agentId, messages: protoMessages, priority, requestId }, (err, response) => {
         if (err) {
           reject(this.wrapError(err));
           return;
@@ -287,6 +540,22 @@ export class GrpcClient {
     });
   }
 
+  /**
+   * Cancel a running ask, askWithHistory or askStream by the request ID it was
+   * started with. Resolves false if no such request is running.
+   */
+  async cancelRequest(requestId: string, reason: string = ''): Promise<boolean> {
+    return new Promise((resolve, reject) => {
+      this.client.cancelRequest({ requestId, reason }, (err, response) => {
+        if (err) {
+          reject(this.wrapError(err));
+          return;
+        }
+        resolve(response!.cancelled);
+      });
+    });
+  }
+
   /**
    * Start a bidirectional streaming conversation
    * Returns the raw duplex stream for use by StreamHandler
@@ -295,6 +564,40 @@ export class GrpcClient {
     return this.client.converse();
   }
 
+  /**
+   * Open a read-only stream of an agent's conversation events
+   * Set replay to receive the events the server retained before going live;
+   * verbosity ('full', 'chunks' or 'milestones') selects the events delivered
+   */
+  createWatchStream(agentId: string, replay: boolean = false, verbosity: string = ''): ClientReadableStream<ConversationResponse> {
+    const request: WatchConversationRequest = { agentId, replay, verbosity };
+    return this.client.watchConversation(request);
+  }
+
+  /**
+   * Open a server-streaming ask: one question in, typed progress messages out
+   * Set includeEvents to also receive every other agent event. Priority is
+   * 'low', 'normal' or 'high' ('' = normal).
+   */
+  createAskStream(
+    agentId: string,
+    question: string,
+    history?: Message[],
+    includeEvents: boolean = false,
+    priority: string = '',
+    requestId: string = ''
+  ): ClientReadableStream<AskStreamResponse> {
+    const request: AskStreamRequest = {
+      agentId,
+      question,
+      history: (history || []).map((m) => ({ role: m.role, content: m.content })),
+      includeEvents,
+      priority,
+      requestId,
+    };
+    return this.client.askStream(request);
+  }
+
   /**
    * Convert SDK AgentConfig to proto AgentConfig
    */
@@ -323,6 +626,11 @@ export class GrpcClient {
       enableContextOffloading: config.enableContextOffloading || false,
       enableStreaming: config.enableStreaming || false,
       customTools: protoTools,
+      toolPermissions: Object.entries(config.toolPermissions || {}).map(([tool, permission]) => ({
+        tool,
+        disabled: permission.disabled || false,
+        paths: permission.paths || [],
+      })),
     };
   }
 
@@ -342,6 +650,61 @@ export class GrpcClient {
     };
   }
 
+  /**
+   * Convert proto TokenUsage to SDK type
+   */
+  private convertTokenUsage(usage: ProtoTokenUsage | undefined): TokenUsage | undefined {
+    if (!usage) {
+      return undefined;
+    }
+    return {
+      promptTokens: usage.promptTokens,
+      completionTokens: usage.completionTokens,
+      totalTokens: usage.totalTokens,
+      cacheTokens: usage.cacheTokens,
+      reasoningTokens: usage.reasoningTokens,
+      llmCallCount: usage.llmCallCount,
+    };
+  }
+
+  /**
+   * Convert proto AgentFilters to SDK type
+   */
+  private convertAgentFilters(filters: ProtoAgentFilters | undefined): AgentFilters {
+    return {
+      selectedServers: filters?.selectedServers || [],
+      selectedTools: filters?.selectedTools || [],
+    };
+  }
+
+  /**
+   * Convert proto ToolInfo to SDK type
+   */
+  private convertToolInfo(tool: ProtoToolInfo | undefined): ToolInfo {
+    return {
+      name: tool?.name || '',
+      description: tool?.description || '',
+      server: tool?.server || '',
+      type: (tool?.type || 'virtual') as ToolInfo['type'],
+    };
+  }
+
+  /**
+   * Convert proto ToolCallStats to SDK type
+   */
+  private convertToolCallStats(stats: ProtoToolCallStats | undefined): ToolCallStats {
+    return {
+      calls: stats?.calls || 0,
+      errors: stats?.errors || 0,
+      errorRate: stats?.errorRate || 0,
+      cacheHits: stats?.cacheHits || 0,
+      p50LatencyMs: stats?.p50LatencyMs || 0,
+      p95LatencyMs: stats?.p95LatencyMs || 0,
+      maxLatencyMs: stats?.maxLatencyMs || 0,
+      bytesReturned: stats?.bytesReturned || 0,
+    };
+  }
+
   /**
    * Wrap gRPC error as MCPAgentError
    */
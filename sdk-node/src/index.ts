@@ -58,6 +58,8 @@ export type {
   ConversationEvent,
   TextChunkConversationEvent,
   ToolCallConversationEvent,
+  ToolStartConversationEvent,
+  ToolEndConversationEvent,
   AgentEventConversationEvent,
   FinalConversationEvent,
   ErrorConversationEvent,
@@ -69,7 +71,10 @@ export type {
   AgentAPIKeys,
   AgentConfig,
   Message,
+  ConversationPriority,
+  StreamVerbosity,
   TokenUsage,
+  Artifact,
   Costs,
   TokenUsageWithPricing,
   Capabilities,
@@ -77,7 +82,18 @@ export type {
   AskResponse,
   AskWithHistoryResponse,
   AgentSummary,
+  AgentFilters,
+  RecoverableConversation,
+  PostMortemBundle,
+  ToolStatsReport,
+  ToolCallStats,
+  ServerHealthReport,
+  ToolInfo,
+  ToolSchema,
+  ServerInfo,
+  PromptInfo,
   ApiError,
   CustomToolDefinition,
+  ToolPermission,
   RegisterToolOptions,
 } from './types';
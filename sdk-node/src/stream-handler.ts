@@ -1,13 +1,14 @@
 import { ClientDuplexStream } from '@grpc/grpc-js';
 import { EventEmitter } from 'events';
 import {
+  AskStreamResponse,
   ConversationRequest,
   ConversationResponse,
   ToolCallEvent,
   Message as ProtoMessage,
 } from './generated/agent';
 import type { GrpcClient } from './grpc-client';
-import type { Message, AskResponse, AskWithHistoryResponse, TokenUsage } from './types';
+import type { Message, ConversationPriority, StreamVerbosity, AskResponse, AskWithHistoryResponse, TokenUsage, Artifact } from './types';
 import { MCPAgentError } from './agent';
 
 /**
@@ -19,7 +20,7 @@ export type ToolHandler = (args: Record<string, unknown>) => Promise<string> | s
  * Conversation event types emitted by the stream handler
  */
 export interface ConversationEvent {
-  type: 'chunk' | 'tool_call' | 'agent_event' | 'final' | 'error';
+  type: 'chunk' | 'tool_call' | 'tool_start' | 'tool_end' | 'agent_event' | 'final' | 'error';
 }
 
 export interface TextChunkConversationEvent extends ConversationEvent {
@@ -35,6 +36,32 @@ export interface ToolCallConversationEvent extends ConversationEvent {
   arguments: Record<string, unknown>;
 }
 
+/** A tool call started (server-streaming ask only) */
+export interface ToolStartConversationEvent extends ConversationEvent {
+  type: 'tool_start';
+  callId: string;
+  toolName: string;
+  serverName: string;
+  /** Tool arguments as a JSON string */
+  arguments: string;
+  turn: number;
+}
+
+/** A tool call finished (server-streaming ask only) */
+export interface ToolEndConversationEvent extends ConversationEvent {
+  type: 'tool_end';
+  callId: string;
+  toolName: string;
+  serverName: string;
+  success: boolean;
+  result: string;
+  error: string;
+  durationMs: number;
+  turn: number;
+  /** The result was served from the agent's tool result cache */
+  fromCache: boolean;
+}
+
 export interface AgentEventConversationEvent extends ConversationEvent {
   type: 'agent_event';
   eventType: string;
@@ -46,6 +73,8 @@ export interface AgentEventConversationEvent extends ConversationEvent {
   sessionId: string;
   component: string;
   data?: Record<string, unknown>;
+  /** Files referenced by this event (only when the artifact server is enabled) */
+  artifacts: Artifact[];
 }
 
 export interface FinalConversationEvent extends ConversationEvent {
@@ -54,18 +83,23 @@ export interface FinalConversationEvent extends ConversationEvent {
   updatedMessages: Message[];
   tokenUsage: TokenUsage;
   durationMs: number;
+  /** Files produced during the conversation (only when the artifact server is enabled) */
+  artifacts: Artifact[];
 }
 
 export interface ErrorConversationEvent extends ConversationEvent {
   type: 'error';
   code: string;
   message: string;
+  details?: Record<string, unknown>;
   fatal: boolean;
 }
 
 export type AnyConversationEvent =
   | TextChunkConversationEvent
   | ToolCallConversationEvent
+  | ToolStartConversationEvent
+  | ToolEndConversationEvent
   | AgentEventConversationEvent
   | FinalConversationEvent
   | ErrorConversationEvent;
@@ -167,6 +201,7 @@ export class StreamHandler extends EventEmitter {
       question: {
         text: question,
         history: historyProto,
+        priority: '',
       },
     });
 
@@ -196,6 +231,58 @@ export class StreamHandler extends EventEmitter {
     }
   }
 
+  /**
+   * Watch an agent's conversations without driving them.
+   * Yields chunks and agent events until the stream is cancelled or the server closes it.
+   */
+  async *watch(agentId: string, replay: boolean = false, verbosity: StreamVerbosity = 'full'): AsyncGenerator<AnyConversationEvent> {
+    const stream = this.grpcClient.createWatchStream(agentId, replay, verbosity);
+    try {
+      for await (const response of stream as AsyncIterable<ConversationResponse>) {
+        const event = this.convertResponse(response);
+        if (event) {
+          yield event;
+        }
+      }
+    } catch (err) {
+      throw new MCPAgentError('STREAM_ERROR', err instanceof Error ? err.message : String(err));
+    } finally {
+      stream.cancel();
+    }
+  }
+
+  /**
+   * Ask over the server-streaming AskStream RPC.
+   * Yields chunks, tool starts/ends and the final response (or a fatal error), then ends.
+   * Client-side custom tools need converse() and are not available here.
+   */
+  async *askServerStream(
+    agentId: string,
+    question: string,
+    history?: Message[],
+    includeEvents: boolean = false,
+    priority: ConversationPriority = 'normal',
+    requestId: string = ''
+  ): AsyncGenerator<AnyConversationEvent> {
+    const stream = this.grpcClient.createAskStream(agentId, question, history, includeEvents, priority, requestId);
+    try {
+      for await (const response of stream as AsyncIterable<AskStreamResponse>) {
+        const event = this.convertAskStreamResponse(response);
+        if (!event) {
+          continue;
+        }
+        yield event;
+        if (event.type === 'final' || (event.type === 'error' && event.fatal)) {
+          return;
+        }
+      }
+    } catch (err) {
+      throw new MCPAgentError('STREAM_ERROR', err instanceof Error ? err.message : String(err));
+    } finally {
+      stream.cancel();
+    }
+  }
+
   /**
    * Simple ask method that collects the final response from streaming
    */
@@ -209,7 +296,7 @@ export class StreamHandler extends EventEmitter {
       } else if (event.type === 'final') {
         finalResponse = event;
       } else if (event.type === 'error' && event.fatal) {
-        throw new MCPAgentError(event.code, event.message);
+        throw new MCPAgentError(event.code, event.message, event.details);
       }
     }
 
@@ -247,7 +334,7 @@ export class StreamHandler extends EventEmitter {
       } else if (event.type === 'final') {
         finalResponse = event;
       } else if (event.type === 'error' && event.fatal) {
-        throw new MCPAgentError(event.code, event.message);
+        throw new MCPAgentError(event.code, event.message, event.details);
       }
     }
 
@@ -285,6 +372,16 @@ export class StreamHandler extends EventEmitter {
     agentId: string,
     response: ConversationResponse
   ): Promise<AnyConversationEvent | null> {
+    if (response.toolCall) {
+      return this.handleToolCall(stream, agentId, response.toolCall);
+    }
+    return this.convertResponse(response);
+  }
+
+  /**
+   * Convert a server response that needs no reply into a conversation event
+   */
+  private convertResponse(response: ConversationResponse): AnyConversationEvent | null {
     // Check each field of the response (ts-proto generates optional fields, not oneof)
     if (response.textChunk) {
       return {
@@ -294,10 +391,6 @@ export class StreamHandler extends EventEmitter {
       };
     }
 
-    if (response.toolCall) {
-      return this.handleToolCall(stream, agentId, response.toolCall);
-    }
-
     if (response.agentEvent) {
       return {
         type: 'agent_event',
@@ -310,6 +403,7 @@ export class StreamHandler extends EventEmitter {
         sessionId: response.agentEvent.sessionId,
         component: response.agentEvent.component,
         data: response.agentEvent.data || undefined,
+        artifacts: response.agentEvent.artifacts.map((a) => ({ path: a.path, url: a.url })),
       };
     }
 
@@ -330,6 +424,7 @@ export class StreamHandler extends EventEmitter {
           llmCallCount: response.finalResponse.tokenUsage?.llmCallCount || 0,
         },
         durationMs: Number(response.finalResponse.durationMs),
+        artifacts: response.finalResponse.artifacts.map((a) => ({ path: a.path, url: a.url })),
       };
     }
 
@@ -338,6 +433,7 @@ export class StreamHandler extends EventEmitter {
         type: 'error',
         code: response.error.code,
         message: response.error.message,
+        details: response.error.details,
         fatal: response.error.fatal,
       };
     }
@@ -345,6 +441,40 @@ export class StreamHandler extends EventEmitter {
     return null;
   }
 
+  /**
+   * Convert an AskStream response into a conversation event
+   */
+  private convertAskStreamResponse(response: AskStreamResponse): AnyConversationEvent | null {
+    if (response.toolCallStart) {
+      return {
+        type: 'tool_start',
+        callId: response.toolCallStart.callId,
+        toolName: response.toolCallStart.toolName,
+        serverName: response.toolCallStart.serverName,
+        arguments: response.toolCallStart.arguments,
+        turn: response.toolCallStart.turn,
+      };
+    }
+
+    if (response.toolCallEnd) {
+      return {
+        type: 'tool_end',
+        callId: response.toolCallEnd.callId,
+        toolName: response.toolCallEnd.toolName,
+        serverName: response.toolCallEnd.serverName,
+        success: response.toolCallEnd.success,
+        result: response.toolCallEnd.result,
+        error: response.toolCallEnd.error,
+        durationMs: Number(response.toolCallEnd.durationMs),
+        turn: response.toolCallEnd.turn,
+        fromCache: response.toolCallEnd.fromCache,
+      };
+    }
+
+    // Chunks, agent events, the final response and errors share the Converse messages
+    return this.convertResponse(response);
+  }
+
   /**
    * Handle a tool call from the server
    */
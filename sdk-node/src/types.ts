@@ -61,8 +61,26 @@ export interface AgentConfig {
   enableContextOffloading?: boolean;
   /** Enable streaming responses */
   enableStreaming?: boolean;
+  /**
+   * Per-tool permissions for virtual and custom tools, keyed by tool name
+   * (e.g. { delete_workspace_file: { disabled: true }, git_commit: { paths: ['src'] } })
+   */
+  toolPermissions?: Record<string, ToolPermission>;
   /** Provider credentials injected into the spawned Go server environment */
   apiKeys?: AgentAPIKeys;
+  /**
+   * Create the agent from a preset registered on the server (--presets).
+   * Other fields that are set override the preset.
+   */
+  preset?: string;
+  /** Values for {{NAME}} placeholders in the preset's system prompt template */
+  presetVariables?: Record<string, string>;
+  /**
+   * Take a pre-created agent from this warm pool on the server (--warm-pools).
+   * Other fields that are set overlay the pool's configuration; the pool is
+   * bypassed when they change the provider, model, MCP servers or tools.
+   */
+  warmPool?: string;
 }
 
 /**
@@ -75,6 +93,19 @@ export interface Message {
   content: string;
 }
 
+/**
+ * Conversation priority. 'high' conversations are admitted and paced ahead of
+ * 'low' (batch) ones when the server is busy.
+ */
+export type ConversationPriority = 'low' | 'normal' | 'high';
+
+/**
+ * Events a watcher receives. 'full' delivers every event, 'chunks' the
+ * streamed text plus the conversation end, 'milestones' the conversation
+ * lifecycle and tool calls (e.g. for mobile clients).
+ */
+export type StreamVerbosity = 'full' | 'chunks' | 'milestones';
+
 /**
  * Token usage statistics
  */
@@ -143,6 +174,18 @@ export interface CreateAgentResponse {
   capabilities: Capabilities;
 }
 
+/**
+ * A file produced by the agent that can be downloaded from the server's
+ * artifact endpoint. Requests must send the artifact token as
+ * `Authorization: Bearer <token>`.
+ */
+export interface Artifact {
+  /** Local file path as reported by the agent */
+  path: string;
+  /** Download URL */
+  url: string;
+}
+
 /**
  * Response from asking a question
  */
@@ -175,6 +218,158 @@ export interface AgentSummary {
   status: string;
   /** Creation timestamp */
   createdAt: string;
+  /** Cumulative token usage */
+  tokenUsage?: TokenUsage;
+  /** Tools currently available to the agent */
+  tools: ToolInfo[];
+  /** Tool filters the agent was created with */
+  filters: AgentFilters;
+  /** Whether the agent runs in code execution mode */
+  codeExecutionMode: boolean;
+}
+
+/**
+ * Tool filters applied to an agent
+ */
+export interface AgentFilters {
+  /** Servers the agent's tools are limited to (empty = all) */
+  selectedServers: string[];
+  /** Tools the agent is limited to (format: "server:tool", empty = all) */
+  selectedTools: string[];
+}
+
+/**
+ * Autosaved conversation that did not finish (server started with autosave)
+ */
+export interface RecoverableConversation {
+  /** Checkpoint identifier */
+  id: string;
+  /** Session identifier of the agent that ran the conversation */
+  sessionId: string;
+  /** User the conversation belongs to */
+  userId: string;
+  provider: string;
+  modelId: string;
+  /** Latest user message */
+  question: string;
+  /** Turns completed when the checkpoint was taken */
+  turn: number;
+  /** Error that ended the conversation, empty if the server stopped mid-run */
+  lastError: string;
+  /** Checkpoint timestamp */
+  updatedAt: string;
+  /** Conversation history at the checkpoint */
+  messages: Message[];
+}
+
+/**
+ * Diagnostic bundle of an agent's last failed conversation
+ */
+export interface PostMortemBundle {
+  /** Zip archive: error.json, events.jsonl, messages.json, config.json, environment.json */
+  bundle: Uint8Array;
+  /** Path of the bundle on the server, empty when it was not written to disk */
+  path: string;
+  /** Redacted error the conversation ended with */
+  error: string;
+  /** When the bundle was built */
+  createdAt: string;
+}
+
+/**
+ * Usage statistics of a tool or a server
+ */
+export interface ToolCallStats {
+  calls: number;
+  errors: number;
+  /** errors / calls */
+  errorRate: number;
+  /** Calls answered from the tool result cache */
+  cacheHits: number;
+  p50LatencyMs: number;
+  p95LatencyMs: number;
+  maxLatencyMs: number;
+  bytesReturned: number;
+}
+
+/**
+ * Tool usage of an agent since it was created
+ */
+export interface ToolStatsReport {
+  /** Sorted by server, then tool name; serverName is empty for virtual and custom tools */
+  tools: Array<{ toolName: string; serverName: string } & ToolCallStats>;
+  /** Sorted by name */
+  servers: Array<{ serverName: string } & ToolCallStats>;
+}
+
+/**
+ * Detailed server health from HealthCheckDetailed
+ */
+export interface ServerHealthReport {
+  status: 'ok' | 'degraded' | 'not_ready';
+  ready: boolean;
+  /** Readiness check name → "ok" or the failure reason, as served by /readyz */
+  checks: Record<string, string>;
+  draining: boolean;
+  activeAgents: number;
+  activeConversations: number;
+  /** MCP connection health per agent; servers sorted by name */
+  agents: Array<{
+    agentId: string;
+    sessionId: string;
+    servers: Array<{ name: string; healthy: boolean; error: string }>;
+  }>;
+  /** MCP server metadata cache */
+  cache: {
+    totalEntries: number;
+    validEntries: number;
+    expiredEntries: number;
+    ttlMinutes: number;
+    directory: string;
+  };
+}
+
+/**
+ * Tool available to an agent
+ */
+export interface ToolInfo {
+  name: string;
+  description: string;
+  /** MCP server of an MCP tool, category of a custom tool, empty for virtual tools */
+  server: string;
+  type: 'mcp' | 'custom' | 'virtual';
+}
+
+/**
+ * Tool with the JSON schema of its arguments
+ */
+export interface ToolSchema extends ToolInfo {
+  inputSchema?: Record<string, unknown>;
+}
+
+/**
+ * MCP server an agent uses
+ */
+export interface ServerInfo {
+  name: string;
+  description: string;
+  /** False for servers connected lazily on their first tool call */
+  connected: boolean;
+  toolCount: number;
+  promptCount: number;
+  resourceCount: number;
+  /** Usage instructions the server sent on initialize */
+  instructions: string;
+}
+
+/**
+ * Prompt offered by an MCP server
+ */
+export interface PromptInfo {
+  name: string;
+  description: string;
+  server: string;
+  arguments: Array<{ name: string; description: string; required: boolean }>;
 }
 
 /**
@@ -189,6 +384,16 @@ export interface ApiError {
   details?: Record<string, unknown>;
 }
 
+/**
+ * Restricts one virtual or custom tool
+ */
+export interface ToolPermission {
+  /** Hide the tool from the LLM and reject calls to it */
+  disabled?: boolean;
+  /** Folders the tool's path arguments must stay within (default: unrestricted) */
+  paths?: string[];
+}
+
 /**
  * Custom tool definition with HTTP callback
  */